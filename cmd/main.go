@@ -28,9 +28,19 @@ func main() {
 		Version: "1.0.0",
 		Commands: []*cli.Command{
 			commands.StartCommand(logger),
+			commands.DemoCommand(logger),
 			commands.MigrateCommand(logger),
+			commands.BackupCommand(logger),
+			commands.RestoreCommand(logger),
+			commands.ArchiveCommand(logger),
+			commands.FsckCommand(logger),
+			commands.PromoteCommand(logger),
+			commands.SeedCommand(logger),
+			commands.BackfillCommand(logger),
+			commands.SnapshotCommand(logger),
 			commands.EncryptCommand(logger),
 			commands.DecryptCommand(logger),
+			commands.RotateKeyCommand(logger),
 			commands.GenerateKeyCommand(logger),
 			commands.HTTPCommand(logger),
 			commands.DocCommand(logger),
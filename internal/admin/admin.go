@@ -0,0 +1,195 @@
+// Package admin serves the embedded administration UI and its JSON API.
+//
+// The UI is a small static single-page app compiled into the binary so that small
+// deployments can manage cities, inspect forecasts, check providers, toggle feature
+// flags and inspect or retry background jobs without any extra tooling. All routes live
+// under /admin/ui and are protected by the shared admin token (see RequireToken).
+package admin
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"expvar"
+	"io/fs"
+	"net/http"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/idempotency"
+	"stormlightlabs.org/weather_api/internal/jobs"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Prefix is the path the admin UI is mounted under
+const Prefix = "/admin/ui"
+
+//go:embed ui
+var uiFiles embed.FS
+
+// healthChecker is implemented by providers that can cheaply report their own health
+type healthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// ProviderStatus describes a registered provider as shown in the admin UI
+type ProviderStatus struct {
+	Name      string   `json:"name"`
+	Kind      string   `json:"kind"` // weather or geocode
+	Regions   []string `json:"regions"`
+	Status    string   `json:"status"` // ok, error or unknown
+	Error     string   `json:"error,omitempty"`
+	LatencyMS int64    `json:"latency_ms,omitempty"`
+}
+
+// Config holds the dependencies of the admin handler
+type Config struct {
+	Token     string
+	Cities    repo.CityRepository
+	Forecasts repo.ForecastRepository
+	Providers *providers.ProviderManager
+	Flags     *FeatureFlags
+	JobRuns   repo.JobRunRepository
+	Scheduler *jobs.Scheduler
+	// Idempotency replays creates retried with the same Idempotency-Key; nil disables it
+	Idempotency *idempotency.Replayer
+}
+
+// Handler serves the admin UI and API
+type Handler struct {
+	providers *providers.ProviderManager
+	flags     *FeatureFlags
+	mux       *http.ServeMux
+}
+
+// NewHandler creates the admin handler, already wrapped with token authentication
+func NewHandler(cfg Config) http.Handler {
+	h := &Handler{
+		providers: cfg.Providers,
+		flags:     cfg.Flags,
+		mux:       http.NewServeMux(),
+	}
+	if h.providers == nil {
+		h.providers = providers.NewProviderManager()
+	}
+	if h.flags == nil {
+		h.flags = NewFeatureFlags()
+	}
+
+	static, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err) // the embedded directory is fixed at compile time
+	}
+	h.mux.Handle("GET "+Prefix+"/", http.StripPrefix(Prefix, http.FileServerFS(static)))
+	h.mux.Handle("GET "+Prefix, http.RedirectHandler(Prefix+"/", http.StatusMovedPermanently))
+
+	api := Prefix + "/api"
+	if cfg.Cities != nil {
+		cities := controllers.NewHTTPCityController(cfg.Cities)
+		h.mux.HandleFunc("GET "+api+"/cities", controllers.HandlerFunc(cities.List))
+		h.mux.HandleFunc("GET "+api+"/cities/search", controllers.HandlerFunc(cities.Search))
+		h.mux.HandleFunc("POST "+api+"/cities", cfg.Idempotency.Wrap(controllers.HandlerFunc(cities.Create)))
+		h.mux.HandleFunc("GET "+api+"/cities/{id}", controllers.IDHandlerFunc("id", cities.GetByID))
+		h.mux.HandleFunc("PUT "+api+"/cities/{id}", controllers.IDHandlerFunc("id", cities.Update))
+		h.mux.HandleFunc("DELETE "+api+"/cities/{id}", controllers.IDHandlerFunc("id", cities.Delete))
+	}
+	if cfg.Forecasts != nil {
+		forecasts := controllers.NewHTTPForecastController(cfg.Forecasts)
+		h.mux.HandleFunc("GET "+api+"/cities/{id}/forecasts", controllers.IDHandlerFunc("id", forecasts.GetByCityID))
+		h.mux.HandleFunc("GET "+api+"/forecasts/{id}", controllers.IDHandlerFunc("id", forecasts.GetByID))
+		h.mux.HandleFunc("DELETE "+api+"/forecasts/{id}", controllers.IDHandlerFunc("id", forecasts.Delete))
+		h.mux.HandleFunc("DELETE "+api+"/forecasts", controllers.HandlerFunc(forecasts.BulkDelete))
+	}
+	if cfg.JobRuns != nil && cfg.Scheduler != nil {
+		scheduled := controllers.NewHTTPJobController(cfg.JobRuns, cfg.Scheduler)
+		h.mux.HandleFunc("GET "+api+"/jobs", controllers.HandlerFunc(scheduled.List))
+		h.mux.HandleFunc("POST "+api+"/jobs/{name}/run", controllers.StringHandlerFunc("name", scheduled.Run))
+		h.mux.HandleFunc("GET "+api+"/jobs/runs", controllers.HandlerFunc(scheduled.ListRuns))
+		h.mux.HandleFunc("GET "+api+"/jobs/runs/{id}", controllers.IDHandlerFunc("id", scheduled.GetRun))
+		h.mux.HandleFunc("POST "+api+"/jobs/runs/{id}/retry", controllers.IDHandlerFunc("id", scheduled.Retry))
+	}
+	h.mux.HandleFunc("GET "+api+"/providers", h.listProviders)
+	h.mux.Handle("GET "+api+"/metrics", expvar.Handler())
+	h.mux.HandleFunc("GET "+api+"/queries", h.listQueries)
+	h.mux.HandleFunc("GET "+api+"/flags", h.listFlags)
+	h.mux.HandleFunc("PUT "+api+"/flags/{name}", h.setFlag)
+
+	return RequireToken(cfg.Token, h)
+}
+
+// ServeHTTP implements http.Handler
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// The admin UI must never be cached by shared proxies
+	w.Header().Set("Cache-Control", "no-store")
+	h.mux.ServeHTTP(w, r)
+}
+
+// listProviders handles GET /admin/ui/api/providers, probing providers that support it
+func (h *Handler) listProviders(w http.ResponseWriter, r *http.Request) {
+	statuses := make([]ProviderStatus, 0)
+	for _, p := range h.providers.GetWeatherProviders() {
+		statuses = append(statuses, probe(r.Context(), p.GetName(), "weather", p.SupportedRegions(), p))
+	}
+	for _, p := range h.providers.GetGeocodeProviders() {
+		statuses = append(statuses, probe(r.Context(), p.GetName(), "geocode", p.SupportedRegions(), p))
+	}
+
+	_ = controllers.WriteJSON(w, http.StatusOK, statuses)
+}
+
+func probe(ctx context.Context, name, kind string, regions []string, provider any) ProviderStatus {
+	status := ProviderStatus{Name: name, Kind: kind, Regions: regions, Status: "unknown"}
+
+	checker, ok := provider.(healthChecker)
+	if !ok {
+		return status
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.HealthCheck(ctx)
+	status.LatencyMS = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Status = "error"
+		status.Error = err.Error()
+	} else {
+		status.Status = "ok"
+	}
+	return status
+}
+
+// listQueries handles GET /admin/ui/api/queries, the repository query tuning report
+func (h *Handler) listQueries(w http.ResponseWriter, r *http.Request) {
+	_ = controllers.WriteJSON(w, http.StatusOK, repo.QueryReport())
+}
+
+// listFlags handles GET /admin/ui/api/flags
+func (h *Handler) listFlags(w http.ResponseWriter, r *http.Request) {
+	_ = controllers.WriteJSON(w, http.StatusOK, h.flags.All())
+}
+
+// setFlag handles PUT /admin/ui/api/flags/{name} with a body of {"enabled": bool}
+func (h *Handler) setFlag(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		_ = controllers.WriteError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		return
+	}
+	if body.Enabled == nil {
+		_ = controllers.WriteError(w, http.StatusBadRequest, "Missing fields", "enabled is required")
+		return
+	}
+
+	name := r.PathValue("name")
+	if !h.flags.Set(name, *body.Enabled) {
+		_ = controllers.WriteError(w, http.StatusNotFound, "Flag not found", "unknown feature flag: "+name)
+		return
+	}
+
+	_ = controllers.WriteJSON(w, http.StatusOK, FeatureFlag{Name: name, Enabled: *body.Enabled})
+}
@@ -0,0 +1,202 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+const testToken = "s3cret-admin-token"
+
+type stubGeocoder struct {
+	name string
+	err  error
+}
+
+func (s *stubGeocoder) GetName() string { return s.name }
+func (s *stubGeocoder) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	return nil, nil
+}
+func (s *stubGeocoder) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	return nil, nil
+}
+func (s *stubGeocoder) SupportedRegions() []string { return []string{"US"} }
+
+type checkedGeocoder struct{ stubGeocoder }
+
+func (c *checkedGeocoder) HealthCheck(ctx context.Context) error { return c.err }
+
+func newTestHandler(t *testing.T) (http.Handler, *FeatureFlags) {
+	t.Helper()
+
+	pm := providers.NewProviderManager()
+	pm.RegisterGeocodeProvider(&stubGeocoder{name: "Plain"})
+	pm.RegisterGeocodeProvider(&checkedGeocoder{stubGeocoder{name: "Healthy"}})
+	pm.RegisterGeocodeProvider(&checkedGeocoder{stubGeocoder{name: "Broken", err: errors.New("upstream down")}})
+
+	flags := NewFeatureFlags()
+	flags.Register("beta", "Beta features", false)
+
+	return NewHandler(Config{Token: testToken, Providers: pm, Flags: flags}), flags
+}
+
+func TestRequireToken(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	tests := []struct {
+		name       string
+		setAuth    func(r *http.Request)
+		wantStatus int
+	}{
+		{"no credentials", func(r *http.Request) {}, http.StatusUnauthorized},
+		{"wrong bearer", func(r *http.Request) { r.Header.Set("Authorization", "Bearer nope") }, http.StatusUnauthorized},
+		{"bearer token", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+testToken) }, http.StatusOK},
+		{"basic password", func(r *http.Request) { r.SetBasicAuth("admin", testToken) }, http.StatusOK},
+		{"basic wrong password", func(r *http.Request) { r.SetBasicAuth("admin", "nope") }, http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, Prefix+"/api/flags", nil)
+			test.setAuth(req)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("expected status %d, got %d", test.wantStatus, w.Code)
+			}
+			if test.wantStatus == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+				t.Error("expected WWW-Authenticate header on 401")
+			}
+		})
+	}
+}
+
+func TestRequireTokenDisabledWithoutToken(t *testing.T) {
+	handler := NewHandler(Config{})
+
+	req := httptest.NewRequest(http.MethodGet, Prefix+"/", nil)
+	req.Header.Set("Authorization", "Bearer ")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("expected status %d, got %d", http.StatusForbidden, w.Code)
+	}
+}
+
+func TestAuthorized(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/condition-check", nil)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	if !Authorized(req, testToken) {
+		t.Error("expected the admin token to be accepted")
+	}
+	if Authorized(req, "other") || Authorized(httptest.NewRequest(http.MethodGet, "/", nil), "") {
+		t.Error("expected a wrong or unconfigured token to be refused")
+	}
+}
+
+func TestServesUI(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	for _, path := range []string{Prefix + "/", Prefix + "/app.js"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.SetBasicAuth("admin", testToken)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", path, w.Code)
+		}
+		if w.Body.Len() == 0 {
+			t.Errorf("%s: expected non-empty body", path)
+		}
+		if w.Header().Get("Cache-Control") != "no-store" {
+			t.Errorf("%s: expected Cache-Control no-store", path)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, Prefix+"/", nil)
+	req.SetBasicAuth("admin", testToken)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if !strings.Contains(w.Body.String(), "Weather API Admin") {
+		t.Error("expected index page to be served")
+	}
+}
+
+func TestListProviders(t *testing.T) {
+	handler, _ := newTestHandler(t)
+
+	req := httptest.NewRequest(http.MethodGet, Prefix+"/api/providers", nil)
+	req.SetBasicAuth("admin", testToken)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+
+	var statuses []ProviderStatus
+	if err := json.NewDecoder(w.Body).Decode(&statuses); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	want := map[string]string{"Plain": "unknown", "Healthy": "ok", "Broken": "error"}
+	if len(statuses) != len(want) {
+		t.Fatalf("expected %d providers, got %d", len(want), len(statuses))
+	}
+	for _, s := range statuses {
+		if s.Status != want[s.Name] {
+			t.Errorf("%s: expected status %s, got %s", s.Name, want[s.Name], s.Status)
+		}
+		if s.Kind != "geocode" {
+			t.Errorf("%s: expected kind geocode, got %s", s.Name, s.Kind)
+		}
+	}
+}
+
+func TestSetFlag(t *testing.T) {
+	handler, flags := newTestHandler(t)
+
+	tests := []struct {
+		name       string
+		flag       string
+		body       string
+		wantStatus int
+	}{
+		{"enable flag", "beta", `{"enabled": true}`, http.StatusOK},
+		{"unknown flag", "missing", `{"enabled": true}`, http.StatusNotFound},
+		{"missing enabled", "beta", `{}`, http.StatusBadRequest},
+		{"invalid json", "beta", `{`, http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, Prefix+"/api/flags/"+test.flag, strings.NewReader(test.body))
+			req.SetBasicAuth("admin", testToken)
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != test.wantStatus {
+				t.Errorf("expected status %d, got %d", test.wantStatus, w.Code)
+			}
+		})
+	}
+
+	if !flags.Enabled("beta") {
+		t.Error("expected beta flag to be enabled")
+	}
+}
@@ -0,0 +1,48 @@
+package admin
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+)
+
+// RequireToken protects a handler with the shared admin token.
+//
+// The token is accepted either as a bearer token or as the password of HTTP Basic
+// auth (any username), which lets browsers use their native credential prompt for
+// the UI. An empty token disables access entirely rather than leaving it open.
+func RequireToken(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" {
+			_ = controllers.WriteError(w, http.StatusForbidden, "Admin access disabled", "WEATHER_API_ADMIN_TOKEN is not configured")
+			return
+		}
+
+		if !tokenMatches(r, token) {
+			w.Header().Set("WWW-Authenticate", `Basic realm="weather-api admin"`)
+			_ = controllers.WriteError(w, http.StatusUnauthorized, "Unauthorized", "valid admin credentials are required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func tokenMatches(r *http.Request, token string) bool {
+	var supplied string
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		supplied = strings.TrimSpace(bearer)
+	} else if _, password, ok := r.BasicAuth(); ok {
+		supplied = password
+	}
+
+	return supplied != "" && subtle.ConstantTimeCompare([]byte(supplied), []byte(token)) == 1
+}
+
+// Authorized reports whether r carries the admin token, for features that change
+// behaviour for admins on public routes. It is false when no token is configured.
+func Authorized(r *http.Request, token string) bool {
+	return token != "" && tokenMatches(r, token)
+}
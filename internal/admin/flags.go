@@ -0,0 +1,73 @@
+package admin
+
+import (
+	"sort"
+	"sync"
+)
+
+// FeatureFlag is a named runtime toggle
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// FeatureFlags is a concurrency-safe, in-memory registry of feature flags.
+// Flags must be registered before they can be toggled so the admin UI cannot
+// create arbitrary keys.
+type FeatureFlags struct {
+	mu    sync.RWMutex
+	flags map[string]*FeatureFlag
+}
+
+// NewFeatureFlags creates an empty feature flag registry
+func NewFeatureFlags() *FeatureFlags {
+	return &FeatureFlags{flags: make(map[string]*FeatureFlag)}
+}
+
+// Register adds a flag with its default state; re-registering keeps the current state
+func (f *FeatureFlags) Register(name, description string, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if existing, ok := f.flags[name]; ok {
+		existing.Description = description
+		return
+	}
+	f.flags[name] = &FeatureFlag{Name: name, Description: description, Enabled: enabled}
+}
+
+// Enabled reports whether a flag is on; unknown flags are off
+func (f *FeatureFlags) Enabled(name string) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	flag, ok := f.flags[name]
+	return ok && flag.Enabled
+}
+
+// Set toggles a registered flag and reports whether it exists
+func (f *FeatureFlags) Set(name string, enabled bool) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	flag, ok := f.flags[name]
+	if !ok {
+		return false
+	}
+	flag.Enabled = enabled
+	return true
+}
+
+// All returns a snapshot of every registered flag sorted by name
+func (f *FeatureFlags) All() []FeatureFlag {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	flags := make([]FeatureFlag, 0, len(f.flags))
+	for _, flag := range f.flags {
+		flags = append(flags, *flag)
+	}
+	sort.Slice(flags, func(i, j int) bool { return flags[i].Name < flags[j].Name })
+	return flags
+}
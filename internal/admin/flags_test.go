@@ -0,0 +1,46 @@
+package admin
+
+import "testing"
+
+func TestFeatureFlags(t *testing.T) {
+	flags := NewFeatureFlags()
+	flags.Register("stale_fallback", "Serve stale forecasts when providers fail", false)
+	flags.Register("alerts_stream", "Enable the live alert stream", true)
+
+	if flags.Enabled("stale_fallback") {
+		t.Error("expected stale_fallback to default to disabled")
+	}
+	if !flags.Enabled("alerts_stream") {
+		t.Error("expected alerts_stream to default to enabled")
+	}
+	if flags.Enabled("missing") {
+		t.Error("expected unknown flag to be disabled")
+	}
+
+	if !flags.Set("stale_fallback", true) {
+		t.Fatal("expected Set to succeed for a registered flag")
+	}
+	if !flags.Enabled("stale_fallback") {
+		t.Error("expected stale_fallback to be enabled after Set")
+	}
+	if flags.Set("missing", true) {
+		t.Error("expected Set to fail for an unregistered flag")
+	}
+
+	// Re-registering must not reset a flag toggled at runtime
+	flags.Register("stale_fallback", "updated description", false)
+	if !flags.Enabled("stale_fallback") {
+		t.Error("expected re-registering to keep the current state")
+	}
+
+	all := flags.All()
+	if len(all) != 2 {
+		t.Fatalf("expected 2 flags, got %d", len(all))
+	}
+	if all[0].Name != "alerts_stream" || all[1].Name != "stale_fallback" {
+		t.Errorf("expected flags sorted by name, got %s, %s", all[0].Name, all[1].Name)
+	}
+	if all[1].Description != "updated description" {
+		t.Errorf("expected description to be updated, got %q", all[1].Description)
+	}
+}
@@ -0,0 +1,335 @@
+"use strict";
+
+const api = "api";
+let cityPage = 1;
+
+async function request(method, path, body) {
+  const opts = { method, headers: {} };
+  if (body !== undefined) {
+    opts.headers["Content-Type"] = "application/json";
+    opts.body = JSON.stringify(body);
+  }
+  const res = await fetch(`${api}/${path}`, opts);
+  const data = await res.json().catch(() => null);
+  if (!res.ok) {
+    const msg = data ? `${data.message}${data.details ? ": " + data.details : ""}` : res.statusText;
+    throw new Error(msg);
+  }
+  return data;
+}
+
+function status(msg, isError) {
+  const el = document.getElementById("status");
+  el.textContent = msg;
+  el.className = isError ? "error" : "";
+}
+
+function cell(text) {
+  const td = document.createElement("td");
+  td.textContent = text ?? "";
+  return td;
+}
+
+function button(label, onClick) {
+  const td = document.createElement("td");
+  const b = document.createElement("button");
+  b.textContent = label;
+  b.addEventListener("click", onClick);
+  td.appendChild(b);
+  return td;
+}
+
+function fill(sectionId, rows, render) {
+  const tbody = document.querySelector(`#${sectionId} tbody`);
+  tbody.replaceChildren(...(rows || []).map(render));
+}
+
+// Cities
+
+async function loadCities(query) {
+  try {
+    let cities;
+    if (query) {
+      cities = await request("GET", `cities/search?q=${encodeURIComponent(query)}`);
+      document.getElementById("city-page").textContent = "";
+    } else {
+      const page = await request("GET", `cities?page=${cityPage}&limit=25`);
+      cities = page.data;
+      document.getElementById("city-page").textContent = `page ${page.page} of ${page.total_pages || 1}`;
+    }
+    fill("cities", cities, renderCity);
+    status("");
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+function renderCity(city) {
+  const tr = document.createElement("tr");
+  tr.append(
+    cell(city.id), cell(city.name), cell(city.country_code), cell(city.region),
+    cell(city.latitude), cell(city.longitude), cell(city.population), cell(city.is_active ? "yes" : "no"),
+  );
+  const actions = button("Edit", () => editCity(city));
+  const del = document.createElement("button");
+  del.textContent = "Delete";
+  del.addEventListener("click", () => deleteCity(city));
+  actions.appendChild(del);
+  tr.appendChild(actions);
+  return tr;
+}
+
+function editCity(city) {
+  const form = document.getElementById("city-form");
+  for (const input of form.elements) {
+    if (!input.name) continue;
+    if (input.type === "checkbox") input.checked = !!city[input.name];
+    else input.value = city[input.name] ?? "";
+  }
+  document.getElementById("city-form-title").textContent = `Edit city #${city.id}`;
+}
+
+async function deleteCity(city) {
+  if (!confirm(`Delete ${city.name}? Its forecasts will be removed too.`)) return;
+  try {
+    await request("DELETE", `cities/${city.id}`);
+    status(`Deleted ${city.name}`);
+    loadCities();
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+async function saveCity(event) {
+  event.preventDefault();
+  const form = event.target;
+  const city = {};
+  for (const input of form.elements) {
+    if (!input.name || input.name === "id") continue;
+    if (input.type === "checkbox") city[input.name] = input.checked;
+    else if (input.type === "number") city[input.name] = input.value === "" ? 0 : Number(input.value);
+    else city[input.name] = input.value;
+  }
+  const id = form.elements.id.value;
+  try {
+    if (id) await request("PUT", `cities/${id}`, city);
+    else await request("POST", "cities", city);
+    status(`Saved ${city.name}`);
+    form.reset();
+    document.getElementById("city-form-title").textContent = "Add city";
+    loadCities();
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+// Forecasts
+
+async function loadForecasts(cityId) {
+  try {
+    const forecasts = await request("GET", `cities/${cityId}/forecasts`);
+    fill("forecasts", forecasts, (f) => {
+      const tr = document.createElement("tr");
+      tr.append(
+        cell(f.id), cell(f.source_provider), cell(f.valid_time), cell(f.temperature),
+        cell(f.humidity), cell(`${f.wind_speed} @ ${f.wind_direction}°`), cell(f.description),
+        button("Delete", async () => {
+          await request("DELETE", `forecasts/${f.id}`).catch((err) => status(err.message, true));
+          loadForecasts(cityId);
+        }),
+      );
+      return tr;
+    });
+    status("");
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+// Providers
+
+async function loadProviders() {
+  try {
+    const providers = await request("GET", "providers");
+    fill("providers", providers, (p) => {
+      const tr = document.createElement("tr");
+      const state = cell(p.error ? `${p.status}: ${p.error}` : p.status);
+      state.className = p.status;
+      tr.append(cell(p.name), cell(p.kind), cell((p.regions || []).join(", ")), state,
+        cell(p.latency_ms ? `${p.latency_ms} ms` : ""));
+      return tr;
+    });
+    status("");
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+// Feature flags
+
+async function loadFlags() {
+  try {
+    const flags = await request("GET", "flags");
+    fill("flags", flags, (flag) => {
+      const tr = document.createElement("tr");
+      const td = document.createElement("td");
+      const toggle = document.createElement("input");
+      toggle.type = "checkbox";
+      toggle.checked = flag.enabled;
+      toggle.addEventListener("change", async () => {
+        try {
+          await request("PUT", `flags/${encodeURIComponent(flag.name)}`, { enabled: toggle.checked });
+          status(`${flag.name} ${toggle.checked ? "enabled" : "disabled"}`);
+        } catch (err) {
+          toggle.checked = !toggle.checked;
+          status(err.message, true);
+        }
+      });
+      td.appendChild(toggle);
+      tr.append(cell(flag.name), cell(flag.description), td);
+      return tr;
+    });
+    status("");
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+// Jobs
+
+let runFilter = "";
+
+function duration(ms) {
+  if (ms < 1000) return `${ms} ms`;
+  if (ms < 60000) return `${(ms / 1000).toFixed(1)} s`;
+  if (ms < 3600000) return `${Math.floor(ms / 60000)} min ${Math.round((ms % 60000) / 1000)} s`;
+  return `${Math.floor(ms / 3600000)} h ${Math.round((ms % 3600000) / 60000)} min`;
+}
+
+function runStatus(run) {
+  const td = cell(run.status);
+  td.className = run.status;
+  return td;
+}
+
+async function loadJobs() {
+  try {
+    const jobs = await request("GET", "jobs");
+    fill("job-list", jobs, (job) => {
+      const tr = document.createElement("tr");
+      const last = job.last_run;
+      const lastCell = last ? runStatus(last) : cell("never");
+      if (last) lastCell.textContent = `${last.status} at ${last.started_at}`;
+      tr.append(
+        cell(job.name), cell(job.description),
+        cell(job.interval_seconds ? duration(job.interval_seconds * 1000) : "on demand"),
+        cell(job.next_run), lastCell,
+        button(job.running ? "Running" : "Run now", () => runJob(job.name)),
+      );
+      tr.lastChild.firstChild.disabled = job.running;
+      return tr;
+    });
+    await loadRuns();
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+async function loadRuns() {
+  try {
+    const query = runFilter ? `&job=${encodeURIComponent(runFilter)}` : "";
+    const runs = await request("GET", `jobs/runs?limit=50${query}`);
+    fill("job-runs", runs, (run) => {
+      const tr = document.createElement("tr");
+      tr.append(
+        cell(run.id), cell(run.job), cell(run.retry_of ? `retry of #${run.retry_of}` : run.triggered_by),
+        cell(run.started_at), cell(run.finished_at ? duration(run.duration_ms) : ""), runStatus(run),
+        cell(run.processed), cell(run.skipped.length), cell(run.error),
+      );
+      const actions = button("Details", () => showRun(run.id));
+      if (run.status === "failed" || run.status === "partial") {
+        const retry = document.createElement("button");
+        retry.textContent = "Retry";
+        retry.addEventListener("click", () => retryRun(run));
+        actions.appendChild(retry);
+      }
+      tr.appendChild(actions);
+      return tr;
+    });
+    status("");
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+async function showRun(id) {
+  try {
+    const run = await request("GET", `jobs/runs/${id}`);
+    document.getElementById("run-detail").hidden = false;
+    document.getElementById("run-detail-title").textContent =
+      `Run #${run.id} of ${run.job}: ${run.status}, ${run.processed} processed, ${run.skipped.length} skipped`;
+    fill("run-detail", run.skipped, (skip) => {
+      const tr = document.createElement("tr");
+      tr.append(cell(skip.item), cell(skip.reason));
+      return tr;
+    });
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+async function runJob(name) {
+  try {
+    const run = await request("POST", `jobs/${encodeURIComponent(name)}/run`);
+    status(`Started run #${run.id} of ${name}`);
+    loadJobs();
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+async function retryRun(run) {
+  const scope = run.status === "partial" ? ` (${run.skipped.length} skipped items only)` : "";
+  if (!confirm(`Retry run #${run.id} of ${run.job}${scope}?`)) return;
+  try {
+    const retry = await request("POST", `jobs/runs/${run.id}/retry`);
+    status(`Started run #${retry.id} retrying #${run.id}`);
+    loadJobs();
+  } catch (err) {
+    status(err.message, true);
+  }
+}
+
+// Navigation
+
+const loaders = { cities: () => loadCities(), providers: loadProviders, flags: loadFlags, jobs: loadJobs };
+
+document.querySelectorAll("nav button").forEach((tab) => {
+  tab.addEventListener("click", () => {
+    document.querySelectorAll("nav button").forEach((b) => b.classList.toggle("active", b === tab));
+    document.querySelectorAll("main section").forEach((s) => { s.hidden = s.id !== tab.dataset.tab; });
+    loaders[tab.dataset.tab]?.();
+  });
+});
+
+document.getElementById("city-search").addEventListener("submit", (event) => {
+  event.preventDefault();
+  loadCities(event.target.elements.q.value.trim());
+});
+document.getElementById("city-prev").addEventListener("click", () => { if (cityPage > 1) { cityPage--; loadCities(); } });
+document.getElementById("city-next").addEventListener("click", () => { cityPage++; loadCities(); });
+document.getElementById("city-form").addEventListener("submit", saveCity);
+document.getElementById("city-form").addEventListener("reset", () => {
+  document.getElementById("city-form-title").textContent = "Add city";
+});
+document.getElementById("run-filter").addEventListener("submit", (event) => {
+  event.preventDefault();
+  runFilter = event.target.elements.job.value.trim();
+  loadRuns();
+});
+document.getElementById("forecast-lookup").addEventListener("submit", (event) => {
+  event.preventDefault();
+  loadForecasts(event.target.elements.city_id.value);
+});
+
+loadCities();
@@ -0,0 +1,185 @@
+// Package apiversion mounts the public API under version prefixes such as /v1.
+//
+// Each version registers its own handlers, so a later version can change response
+// shapes while clients of an earlier one keep the routes they were built against.
+// Retiring versions and routes announce it with the Deprecation (RFC 9745) and Sunset
+// (RFC 8594) headers, and answer 410 Gone once their sunset has passed.
+package apiversion
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+)
+
+// Header names the version that served a response
+const Header = "API-Version"
+
+// Lifecycle describes the retirement of a version or route. The zero value is a
+// current, supported API.
+type Lifecycle struct {
+	// Deprecated is when the API was deprecated; clients should migrate
+	Deprecated time.Time
+
+	// Sunset is when the API stops responding
+	Sunset time.Time
+
+	// Link documents the deprecation, e.g. a migration guide or the successor route
+	Link string
+}
+
+// merge returns l with the fields unset in l taken from parent
+func (l Lifecycle) merge(parent Lifecycle) Lifecycle {
+	if l.Deprecated.IsZero() {
+		l.Deprecated = parent.Deprecated
+	}
+	if l.Sunset.IsZero() {
+		l.Sunset = parent.Sunset
+	}
+	if l.Link == "" {
+		l.Link = parent.Link
+	}
+	return l
+}
+
+// Version is a mounted API version
+type Version struct {
+	Name      string // path segment, e.g. "v1"
+	Lifecycle Lifecycle
+
+	router *Router
+}
+
+// Router dispatches requests to the handlers registered on its versions
+type Router struct {
+	mux      *http.ServeMux
+	versions []*Version
+	now      func() time.Time
+}
+
+// NewRouter creates a router without versions
+func NewRouter() *Router {
+	return &Router{mux: http.NewServeMux(), now: time.Now}
+}
+
+// Version registers a version mounted at /{name}/ and returns it for its routes.
+// Registering the same name twice panics, like duplicate http.ServeMux patterns.
+func (rt *Router) Version(name string, lifecycle Lifecycle) *Version {
+	for _, v := range rt.versions {
+		if v.Name == name {
+			panic(fmt.Sprintf("apiversion: version %s registered twice", name))
+		}
+	}
+	v := &Version{Name: name, Lifecycle: lifecycle, router: rt}
+	rt.versions = append(rt.versions, v)
+	return v
+}
+
+// Versions returns the registered versions in registration order
+func (rt *Router) Versions() []*Version {
+	return rt.versions
+}
+
+// Mount registers the prefix of every version on mux, so requests for /v1/... reach
+// the router while other paths stay with mux
+func (rt *Router) Mount(mux *http.ServeMux) {
+	for _, v := range rt.versions {
+		mux.Handle("/"+v.Name+"/", rt)
+	}
+}
+
+// ServeHTTP dispatches r to the handler registered for its versioned path
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rt.mux.ServeHTTP(w, r)
+}
+
+// HandleFunc registers handler for pattern relative to the version, e.g.
+// "GET /cities/{id}" on v1 serves GET /v1/cities/{id}
+func (v *Version) HandleFunc(pattern string, handler http.HandlerFunc) {
+	v.Handle(pattern, handler)
+}
+
+// Handle registers handler for pattern relative to the version
+func (v *Version) Handle(pattern string, handler http.Handler) {
+	v.HandleDeprecated(pattern, handler, Lifecycle{})
+}
+
+// HandleDeprecated registers handler for pattern with its own lifecycle, for a route
+// retired ahead of its version. Fields left unset are inherited from the version.
+func (v *Version) HandleDeprecated(pattern string, handler http.Handler, lifecycle Lifecycle) {
+	v.router.mux.Handle(v.prefix(pattern), &route{
+		name:      v.Name,
+		handler:   handler,
+		lifecycle: func() Lifecycle { return lifecycle.merge(v.Lifecycle) },
+		now:       v.router.now,
+	})
+}
+
+// prefix inserts the version segment into a pattern's path, keeping its method and host
+func (v *Version) prefix(pattern string) string {
+	method, path, found := strings.Cut(pattern, " ")
+	if !found {
+		method, path = "", pattern
+	}
+	slash := strings.Index(path, "/")
+	if slash < 0 {
+		panic(fmt.Sprintf("apiversion: pattern %q has no path", pattern))
+	}
+	path = path[:slash] + "/" + v.Name + path[slash:]
+	if method == "" {
+		return path
+	}
+	return method + " " + path
+}
+
+// route serves one registered handler with the headers of its lifecycle
+type route struct {
+	name      string // version, empty for unversioned routes
+	handler   http.Handler
+	lifecycle func() Lifecycle
+	now       func() time.Time
+}
+
+func (rt *route) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	lifecycle := rt.lifecycle()
+	if rt.name != "" {
+		w.Header().Set(Header, rt.name)
+	}
+	SetHeaders(w.Header(), lifecycle)
+	if !lifecycle.Sunset.IsZero() && !rt.now().Before(lifecycle.Sunset) {
+		_ = controllers.WriteError(w, http.StatusGone, "API retired",
+			fmt.Sprintf("%s %s was retired on %s", r.Method, r.URL.Path, lifecycle.Sunset.UTC().Format(time.DateOnly)))
+		return
+	}
+	rt.handler.ServeHTTP(w, r)
+}
+
+// SetHeaders adds the Deprecation, Sunset and Link headers announcing lifecycle
+func SetHeaders(header http.Header, lifecycle Lifecycle) {
+	if !lifecycle.Deprecated.IsZero() {
+		header.Set("Deprecation", fmt.Sprintf("@%d", lifecycle.Deprecated.Unix()))
+	}
+	if !lifecycle.Sunset.IsZero() {
+		header.Set("Sunset", lifecycle.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if lifecycle.Link != "" {
+		rel := "deprecation"
+		if lifecycle.Deprecated.IsZero() && !lifecycle.Sunset.IsZero() {
+			rel = "sunset"
+		}
+		header.Add("Link", fmt.Sprintf(`<%s>; rel="%s"`, lifecycle.Link, rel))
+	}
+}
+
+// Deprecated wraps handler, typically an unversioned alias kept for existing clients,
+// so it announces lifecycle and answers 410 Gone after its sunset
+func Deprecated(handler http.Handler, lifecycle Lifecycle) http.Handler {
+	return &route{
+		handler:   handler,
+		lifecycle: func() Lifecycle { return lifecycle },
+		now:       time.Now,
+	}
+}
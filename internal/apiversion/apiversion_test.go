@@ -0,0 +1,115 @@
+package apiversion
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRouter(t *testing.T) {
+	now := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+	deprecated := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	echo := func(body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body + " " + r.PathValue("id")))
+		}
+	}
+	router := NewRouter()
+	router.now = func() time.Time { return now }
+	v1 := router.Version("v1", Lifecycle{Deprecated: deprecated, Sunset: sunset, Link: "https://example.com/migrate"})
+	v1.HandleFunc("GET /cities/{id}", echo("v1 city"))
+	v1.HandleDeprecated("GET /legacy", echo("legacy"), Lifecycle{Sunset: now.Add(-time.Hour)})
+	v2 := router.Version("v2", Lifecycle{})
+	v2.HandleFunc("GET /cities/{id}", echo("v2 city"))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /other", echo("other"))
+	router.Mount(mux)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		return w
+	}
+
+	w := get("/v1/cities/7")
+	if w.Code != http.StatusOK || w.Body.String() != "v1 city 7" {
+		t.Fatalf("Expected the v1 handler, got %d %q", w.Code, w.Body.String())
+	}
+	headers := map[string]string{
+		"API-Version": "v1",
+		"Deprecation": "@1754006400",
+		"Sunset":      "Sun, 01 Feb 2026 00:00:00 GMT",
+		"Link":        `<https://example.com/migrate>; rel="deprecation"`,
+	}
+	for name, want := range headers {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("Expected %s %q, got %q", name, want, got)
+		}
+	}
+
+	w = get("/v2/cities/7")
+	if w.Body.String() != "v2 city 7" || w.Header().Get("API-Version") != "v2" || w.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected a current v2 response, got %q %v", w.Body.String(), w.Header())
+	}
+
+	w = get("/v1/legacy")
+	if w.Code != http.StatusGone || w.Header().Get("Sunset") == "" || w.Header().Get("Deprecation") != "@1754006400" {
+		t.Errorf("Expected a retired route to inherit the version's deprecation and answer 410, got %d %v", w.Code, w.Header())
+	}
+
+	if w = get("/v3/cities/7"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected an unknown version to 404, got %d", w.Code)
+	}
+	if w = get("/v2/legacy"); w.Code != http.StatusNotFound {
+		t.Errorf("Expected routes to be registered per version, got %d", w.Code)
+	}
+	if w = get("/other"); w.Body.String() != "other " {
+		t.Errorf("Expected unversioned routes to stay with the mux, got %q", w.Body.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected registering a version twice to panic")
+		}
+	}()
+	router.Version("v1", Lifecycle{})
+}
+
+func TestDeprecated(t *testing.T) {
+	handler := Deprecated(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), Lifecycle{Deprecated: time.Unix(1754006400, 0), Link: "/v1/condition-check"})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/condition-check", nil))
+	if w.Code != http.StatusNoContent || w.Header().Get("API-Version") != "" {
+		t.Errorf("Expected the alias to be served without a version, got %d %v", w.Code, w.Header())
+	}
+	if w.Header().Get("Deprecation") != "@1754006400" || w.Header().Get("Link") != `</v1/condition-check>; rel="deprecation"` {
+		t.Errorf("Unexpected deprecation headers %v", w.Header())
+	}
+
+	header := http.Header{}
+	SetHeaders(header, Lifecycle{Sunset: time.Unix(0, 0), Link: "/docs"})
+	if header.Get("Link") != `</docs>; rel="sunset"` || header.Get("Deprecation") != "" {
+		t.Errorf("Expected a sunset link without a deprecation, got %v", header)
+	}
+}
+
+func TestVersionPrefix(t *testing.T) {
+	v := &Version{Name: "v1"}
+	tests := map[string]string{
+		"GET /cities/{id}":            "GET /v1/cities/{id}",
+		"/forecasts/":                 "/v1/forecasts/",
+		"POST api.example.com/places": "POST api.example.com/v1/places",
+	}
+	for pattern, want := range tests {
+		if got := v.prefix(pattern); got != want {
+			t.Errorf("prefix(%q) = %q, want %q", pattern, got, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Package audit keeps a bounded in-memory log of repository writes for the admin-only
+// audit endpoint.
+package audit
+
+import (
+	"sync"
+)
+
+// DefaultSize is the number of entries a log keeps by default
+const DefaultSize = 1000
+
+// Entry is one repository write
+type Entry struct {
+	Time      string `json:"time"`
+	RequestID string `json:"request_id,omitempty"` // empty for writes made by jobs
+	Actor     string `json:"actor,omitempty"`      // username of the caller, empty when anonymous
+	Role      string `json:"role,omitempty"`
+	Entity    string `json:"entity"`
+	Op        string `json:"op"`
+	ID        int    `json:"id,omitempty"`
+	Count     int64  `json:"count"`
+}
+
+// Log keeps the most recent entries, dropping the oldest once full. It is safe for
+// concurrent use.
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	next    int // index the next entry is written to once the log is full
+	size    int
+}
+
+// NewLog creates a log keeping up to size entries; non-positive sizes use DefaultSize
+func NewLog(size int) *Log {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	return &Log{size: size}
+}
+
+// Record appends an entry, replacing the oldest when the log is full
+func (l *Log) Record(entry Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if len(l.entries) < l.size {
+		l.entries = append(l.entries, entry)
+		return
+	}
+	l.entries[l.next] = entry
+	l.next = (l.next + 1) % l.size
+}
+
+// Recent returns up to limit entries, newest first; a non-positive limit returns all
+func (l *Log) Recent(limit int) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := len(l.entries)
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	recent := make([]Entry, 0, limit)
+	for i := range limit {
+		// The newest entry sits just before next, wrapping to the end
+		recent = append(recent, l.entries[(l.next-1-i+2*n)%n])
+	}
+	return recent
+}
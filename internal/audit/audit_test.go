@@ -0,0 +1,29 @@
+package audit
+
+import (
+	"testing"
+)
+
+func TestLog(t *testing.T) {
+	log := NewLog(3)
+	if got := log.Recent(10); len(got) != 0 {
+		t.Fatalf("Expected an empty log, got %+v", got)
+	}
+
+	for id := 1; id <= 5; id++ {
+		log.Record(Entry{Entity: "city", Op: "create", ID: id})
+	}
+
+	got := log.Recent(0)
+	if len(got) != 3 {
+		t.Fatalf("Expected the log to keep 3 entries, got %d", len(got))
+	}
+	for i, want := range []int{5, 4, 3} {
+		if got[i].ID != want {
+			t.Errorf("Expected entry %d to be ID %d, got %+v", i, want, got)
+		}
+	}
+	if got := log.Recent(1); len(got) != 1 || got[0].ID != 5 {
+		t.Errorf("Expected only the newest entry, got %+v", got)
+	}
+}
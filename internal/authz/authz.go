@@ -0,0 +1,156 @@
+// Package authz identifies API callers and restricts endpoints by role.
+//
+// Callers authenticate with a user's API key, sent as a bearer token or in the
+// X-API-Key header, or with the shared admin token, which acts as an admin. Requests
+// without credentials are anonymous: they can read public data but not write it.
+package authz
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"stormlightlabs.org/weather_api/internal/admin"
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// APIKeyHeader carries an API key for clients that cannot send a bearer token
+const APIKeyHeader = "X-API-Key"
+
+// Principal is the caller a request was authenticated as. It is defined with the
+// controllers, which read it to check ownership.
+type Principal = controllers.Principal
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return controllers.WithPrincipal(ctx, p)
+}
+
+// FromContext returns the caller stored by Middleware, or nil for anonymous requests
+func FromContext(ctx context.Context) *Principal {
+	return controllers.PrincipalFromContext(ctx)
+}
+
+// HashAPIKey returns the hex SHA-256 of an API key, as stored in users.api_key_hash
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Authenticator resolves request credentials to a Principal
+type Authenticator struct {
+	users      repo.UserRepository
+	adminToken string
+}
+
+// NewAuthenticator creates an authenticator. users may be nil when storage is
+// unavailable, in which case only the admin token is accepted.
+func NewAuthenticator(users repo.UserRepository, adminToken string) *Authenticator {
+	return &Authenticator{users: users, adminToken: adminToken}
+}
+
+// errInvalidCredentials is returned for keys that match no active user
+var errInvalidCredentials = errors.New("invalid API key")
+
+// Authenticate returns the caller of r, or nil when r carries no credentials
+func (a *Authenticator) Authenticate(r *http.Request) (*Principal, error) {
+	if admin.Authorized(r, a.adminToken) {
+		return &Principal{Username: "admin", Role: models.RoleAdmin}, nil
+	}
+
+	key := strings.TrimSpace(r.Header.Get(APIKeyHeader))
+	if bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		key = strings.TrimSpace(bearer)
+	}
+	if key == "" {
+		return nil, nil
+	}
+	if a.users == nil {
+		return nil, errInvalidCredentials
+	}
+
+	user, err := a.users.GetByAPIKeyHash(r.Context(), HashAPIKey(key))
+	if errors.Is(err, repo.ErrNotFound) || (err == nil && !user.IsActive) {
+		return nil, errInvalidCredentials
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	return &Principal{UserID: user.ID, Username: user.Username, Role: user.Role, Units: user.PreferredUnits}, nil
+}
+
+// Middleware stores the caller in the request context for Require and audit logging.
+// Requests with credentials that match nobody are rejected rather than served
+// anonymously, so a mistyped key fails loudly.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authenticate(r)
+		switch {
+		case errors.Is(err, errInvalidCredentials):
+			unauthorized(w, err.Error())
+			return
+		case err != nil:
+			_ = controllers.WriteError(w, http.StatusServiceUnavailable, "Authentication unavailable", err.Error())
+			return
+		case principal != nil:
+			r = r.WithContext(WithPrincipal(r.Context(), principal))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Preferences applies the stored preferences of the caller Middleware identified to
+// the request: responses without ?units= use the user's preferred unit system. It
+// must run inside Middleware.
+func Preferences(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if principal := FromContext(r.Context()); principal != nil && principal.Units != "" {
+			if system, err := units.ParseSystem(principal.Units); err == nil {
+				r = r.WithContext(units.WithPreference(r.Context(), system))
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Require allows a handler only to callers with at least role: anonymous requests get
+// 401 and callers with a lesser role 403
+func Require(role string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal := FromContext(r.Context())
+		if principal == nil {
+			unauthorized(w, "an API key is required")
+			return
+		}
+		if !models.HasRole(principal.Role, role) {
+			_ = controllers.WriteError(w, http.StatusForbidden, "Forbidden", fmt.Sprintf("the %s role is required", role))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// Write allows a handler to users and admins, the roles that may change data
+func Write(next http.HandlerFunc) http.HandlerFunc {
+	return Require(models.RoleUser, next)
+}
+
+// Admin allows a handler to admins only
+func Admin(next http.HandlerFunc) http.HandlerFunc {
+	return Require(models.RoleAdmin, next)
+}
+
+func unauthorized(w http.ResponseWriter, detail string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="weather-api"`)
+	_ = controllers.WriteError(w, http.StatusUnauthorized, "Unauthorized", detail)
+}
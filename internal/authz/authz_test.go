@@ -0,0 +1,83 @@
+package authz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestMiddleware(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := []*repo.User{
+		{Username: "alice", APIKeyHash: HashAPIKey("alice-key"), Role: models.RoleUser, IsActive: true},
+		{Username: "reader", APIKeyHash: HashAPIKey("reader-key"), Role: models.RoleReadOnly, IsActive: true},
+		{Username: "gone", APIKeyHash: HashAPIKey("gone-key"), Role: models.RoleAdmin, IsActive: false},
+	}
+	for _, user := range users {
+		if err := engine.Users().Create(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }
+	mux.HandleFunc("GET /public", ok)
+	mux.HandleFunc("POST /write", Write(ok))
+	mux.HandleFunc("GET /admin", Admin(ok))
+	handler := NewAuthenticator(engine.Users(), "admin-token").Middleware(mux)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		header string
+		value  string
+		want   int
+	}{
+		{"anonymous read", "GET", "/public", "", "", http.StatusNoContent},
+		{"anonymous write", "POST", "/write", "", "", http.StatusUnauthorized},
+		{"user write", "POST", "/write", "Authorization", "Bearer alice-key", http.StatusNoContent},
+		{"api key header", "POST", "/write", APIKeyHeader, "alice-key", http.StatusNoContent},
+		{"readonly write", "POST", "/write", APIKeyHeader, "reader-key", http.StatusForbidden},
+		{"user admin route", "GET", "/admin", APIKeyHeader, "alice-key", http.StatusForbidden},
+		{"admin token", "GET", "/admin", "Authorization", "Bearer admin-token", http.StatusNoContent},
+		{"unknown key", "GET", "/public", APIKeyHeader, "wrong", http.StatusUnauthorized},
+		{"inactive user", "GET", "/public", APIKeyHeader, "gone-key", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.header != "" {
+				r.Header.Set(tt.header, tt.value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Errorf("Expected status %d, got %d: %s", tt.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticate_WithoutStorage(t *testing.T) {
+	a := NewAuthenticator(nil, "admin-token")
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Authorization", "Bearer admin-token")
+	if p, err := a.Authenticate(r); err != nil || p.Role != models.RoleAdmin {
+		t.Errorf("Expected the admin token to authenticate without storage, got %+v (%v)", p, err)
+	}
+
+	r.Header.Set("Authorization", "Bearer some-key")
+	if _, err := a.Authenticate(r); err == nil {
+		t.Error("Expected API keys to be rejected without storage")
+	}
+}
@@ -0,0 +1,380 @@
+// Package backup dumps and restores application tables as a compressed archive.
+//
+// An archive is a zstd-compressed tar file holding a manifest.json followed by one
+// newline-delimited JSON file per table, where each line is a row produced by
+// row_to_json. Restores load rows back with json_populate_record, so the format
+// only depends on column names and survives column reordering between versions.
+// This keeps self-hosted backups free of pg_dump and other DBA tooling.
+package backup
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// FormatVersion is bumped whenever the archive layout changes incompatibly
+const FormatVersion = 1
+
+const manifestName = "manifest.json"
+
+// DefaultTables lists the tables included in a backup, in restore order so that
+// foreign keys are satisfied (parents before children)
+var DefaultTables = []string{"cities", "city_names", "places", "users", "forecasts", "forecast_archives", "alerts", "aviation_reports", "stations", "observations", "air_quality", "share_links", "digests", "user_locations", "alert_subscriptions", "alert_notifications"}
+
+// DB is the database handle needed for dumps and restores
+type DB interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}
+
+// Manifest describes the contents of an archive
+type Manifest struct {
+	Version      int            `json:"version"`
+	CreatedAt    time.Time      `json:"created_at"`
+	Tables       []string       `json:"tables"`
+	Rows         map[string]int `json:"rows"`
+	ForecastDays int            `json:"forecast_days,omitempty"`
+}
+
+// Options controls which data a backup or restore covers
+type Options struct {
+	// Tables limits the operation to these tables; empty means DefaultTables
+	Tables []string
+
+	// ForecastDays keeps only forecasts valid within the last N days; 0 keeps all
+	ForecastDays int
+
+	// Truncate empties each restored table before loading it, cascading to tables
+	// that reference it
+	Truncate bool
+}
+
+// tables returns the selected tables in DefaultTables order, rejecting unknown names
+func (o Options) tables() ([]string, error) {
+	if len(o.Tables) == 0 {
+		return DefaultTables, nil
+	}
+
+	for _, table := range o.Tables {
+		if !slices.Contains(DefaultTables, table) {
+			return nil, fmt.Errorf("unknown table %q (supported: %s)", table, strings.Join(DefaultTables, ", "))
+		}
+	}
+
+	var ordered []string
+	for _, table := range DefaultTables {
+		if slices.Contains(o.Tables, table) {
+			ordered = append(ordered, table)
+		}
+	}
+	return ordered, nil
+}
+
+// Dump writes the selected tables to w as a zstd-compressed tar archive.
+// Tables that do not exist in the database are skipped.
+func Dump(ctx context.Context, db DB, w io.Writer, opts Options) (*Manifest, error) {
+	tables, err := opts.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	zw, err := zstd.NewWriter(w)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd writer: %w", err)
+	}
+	tw := tar.NewWriter(zw)
+
+	manifest := &Manifest{
+		Version:      FormatVersion,
+		CreatedAt:    time.Now().UTC(),
+		Rows:         make(map[string]int),
+		ForecastDays: opts.ForecastDays,
+	}
+
+	// Table data is spooled to temp files so each tar entry can be written with its
+	// final size without holding large tables in memory
+	dumps := make(map[string]*os.File)
+	defer func() {
+		for _, f := range dumps {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	for _, table := range tables {
+		exists, err := tableExists(ctx, db, table)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			continue
+		}
+
+		f, err := os.CreateTemp("", "weather-backup-"+table+"-*.ndjson")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temp file: %w", err)
+		}
+		dumps[table] = f
+
+		count, err := dumpTable(ctx, db, f, table, opts.ForecastDays)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Tables = append(manifest.Tables, table)
+		manifest.Rows[table] = count
+	}
+
+	manifestJSON, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+	if err := writeEntry(tw, manifestName, bytes.NewReader(manifestJSON), int64(len(manifestJSON)), manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, table := range manifest.Tables {
+		f := dumps[table]
+		size, err := f.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to size %s dump: %w", table, err)
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("failed to rewind %s dump: %w", table, err)
+		}
+		if err := writeEntry(tw, table+".ndjson", f, size, manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish compression: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// Restore loads an archive written by Dump inside a single transaction.
+// Rows that conflict with existing rows are skipped, and serial sequences are
+// advanced past the restored IDs.
+func Restore(ctx context.Context, db DB, r io.Reader, opts Options) (*Manifest, error) {
+	selected, err := opts.tables()
+	if err != nil {
+		return nil, err
+	}
+
+	zr, err := zstd.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zstd reader: %w", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	header, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", err)
+	}
+	if header.Name != manifestName {
+		return nil, fmt.Errorf("invalid archive: expected %s first, found %s", manifestName, header.Name)
+	}
+
+	var manifest Manifest
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("failed to decode manifest: %w", err)
+	}
+	if manifest.Version != FormatVersion {
+		return nil, fmt.Errorf("unsupported backup format version %d (expected %d)", manifest.Version, FormatVersion)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	restored := &Manifest{
+		Version:      manifest.Version,
+		CreatedAt:    manifest.CreatedAt,
+		Rows:         make(map[string]int),
+		ForecastDays: manifest.ForecastDays,
+	}
+
+	if opts.Truncate {
+		var truncate []string
+		for _, table := range manifest.Tables {
+			if slices.Contains(selected, table) {
+				truncate = append(truncate, table)
+			}
+		}
+		if len(truncate) > 0 {
+			if _, err := tx.ExecContext(ctx, "TRUNCATE "+strings.Join(truncate, ", ")+" CASCADE"); err != nil {
+				return nil, fmt.Errorf("failed to truncate tables: %w", err)
+			}
+		}
+	}
+
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		table, ok := strings.CutSuffix(header.Name, ".ndjson")
+		if !ok || !slices.Contains(manifest.Tables, table) {
+			return nil, fmt.Errorf("invalid archive: unexpected entry %s", header.Name)
+		}
+		if !slices.Contains(selected, table) {
+			continue
+		}
+
+		count, err := restoreTable(ctx, tx, tr, table)
+		if err != nil {
+			return nil, err
+		}
+		restored.Tables = append(restored.Tables, table)
+		restored.Rows[table] = count
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit restore: %w", err)
+	}
+
+	return restored, nil
+}
+
+func tableExists(ctx context.Context, db DB, table string) (bool, error) {
+	var exists bool
+	err := db.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check table %s: %w", table, err)
+	}
+	return exists, nil
+}
+
+// dumpTable writes every row of table as a JSON line; table names are only ever
+// taken from DefaultTables so they are safe to interpolate
+func dumpTable(ctx context.Context, db DB, w io.Writer, table string, forecastDays int) (int, error) {
+	query := fmt.Sprintf(`SELECT row_to_json(t)::text FROM %s t`, table)
+	var args []any
+	if table == "forecasts" && forecastDays > 0 {
+		query += ` WHERE t.valid_time >= NOW() - make_interval(days => $1)`
+		args = append(args, forecastDays)
+	}
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to dump %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return 0, fmt.Errorf("failed to scan %s row: %w", table, err)
+		}
+		if _, err := io.WriteString(w, line+"\n"); err != nil {
+			return 0, err
+		}
+		count++
+	}
+
+	return count, rows.Err()
+}
+
+func restoreTable(ctx context.Context, tx *sql.Tx, r io.Reader, table string) (int, error) {
+	insert := fmt.Sprintf(
+		`INSERT INTO %[1]s SELECT * FROM json_populate_record(NULL::%[1]s, $1::json) ON CONFLICT DO NOTHING`,
+		table,
+	)
+	stmt, err := tx.PrepareContext(ctx, insert)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare restore of %s: %w", table, err)
+	}
+	defer stmt.Close()
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	count := 0
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		result, err := stmt.ExecContext(ctx, string(line))
+		if err != nil {
+			return 0, fmt.Errorf("failed to restore %s row %d: %w", table, count+1, err)
+		}
+		if n, err := result.RowsAffected(); err == nil {
+			count += int(n)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s rows: %w", table, err)
+	}
+
+	if err := resetSequence(ctx, tx, table); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// resetSequence advances a table's id sequence past the restored rows so new
+// inserts don't collide; tables without a serial id column are left alone
+func resetSequence(ctx context.Context, tx *sql.Tx, table string) error {
+	var sequence sql.NullString
+	err := tx.QueryRowContext(ctx, `
+		SELECT pg_get_serial_sequence($1, 'id')
+		FROM information_schema.columns
+		WHERE table_schema = current_schema() AND table_name = $1 AND column_name = 'id'`,
+		table,
+	).Scan(&sequence)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !sequence.Valid) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to find %s sequence: %w", table, err)
+	}
+
+	query := fmt.Sprintf(`SELECT setval($1, GREATEST((SELECT COALESCE(MAX(id), 0) FROM %s), 1))`, table)
+	if _, err := tx.ExecContext(ctx, query, sequence.String); err != nil {
+		return fmt.Errorf("failed to reset %s sequence: %w", table, err)
+	}
+	return nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data io.Reader, size int64, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    size,
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s header: %w", name, err)
+	}
+	if _, err := io.Copy(tw, data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestOptionsTables(t *testing.T) {
+	tests := []struct {
+		name     string
+		tables   []string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "default", tables: nil, expected: DefaultTables},
+		{name: "reordered for foreign keys", tables: []string{"forecasts", "cities"}, expected: []string{"cities", "forecasts"}},
+		{name: "unknown table", tables: []string{"cities", "pg_authid"}, wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tables, err := Options{Tables: test.tables}.tables()
+			if test.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tables, test.expected) {
+				t.Errorf("expected %v, got %v", test.expected, tables)
+			}
+		})
+	}
+}
+
+// buildArchive writes a zstd-compressed tar with the given entries in order
+func buildArchive(t *testing.T, entries map[string][]byte, order []string) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatalf("failed to create zstd writer: %v", err)
+	}
+	tw := tar.NewWriter(zw)
+	for _, name := range order {
+		data := entries[name]
+		if err := writeEntry(tw, name, bytes.NewReader(data), int64(len(data)), time.Now()); err != nil {
+			t.Fatalf("failed to write entry: %v", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to close tar: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zstd: %v", err)
+	}
+	return &buf
+}
+
+func TestRestoreRejectsInvalidArchives(t *testing.T) {
+	futureManifest, _ := json.Marshal(Manifest{Version: FormatVersion + 1})
+
+	tests := []struct {
+		name    string
+		archive *bytes.Buffer
+		errMsg  string
+	}{
+		{
+			name:    "not zstd",
+			archive: bytes.NewBufferString("plain text"),
+			errMsg:  "failed to read archive",
+		},
+		{
+			name:    "manifest not first",
+			archive: buildArchive(t, map[string][]byte{"cities.ndjson": []byte("{}\n")}, []string{"cities.ndjson"}),
+			errMsg:  "expected manifest.json first",
+		},
+		{
+			name:    "unsupported version",
+			archive: buildArchive(t, map[string][]byte{manifestName: futureManifest}, []string{manifestName}),
+			errMsg:  "unsupported backup format version",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// The archive is validated before the database is touched
+			_, err := Restore(context.Background(), nil, test.archive, Options{})
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), test.errMsg) {
+				t.Errorf("expected error containing %q, got %q", test.errMsg, err.Error())
+			}
+		})
+	}
+}
+
+func TestWriteEntryRoundTrip(t *testing.T) {
+	manifest, _ := json.Marshal(Manifest{Version: FormatVersion, Tables: []string{"cities"}})
+	rows := []byte(`{"id":1,"name":"Springfield"}` + "\n")
+	archive := buildArchive(t,
+		map[string][]byte{manifestName: manifest, "cities.ndjson": rows},
+		[]string{manifestName, "cities.ndjson"},
+	)
+
+	zr, err := zstd.NewReader(archive)
+	if err != nil {
+		t.Fatalf("failed to create zstd reader: %v", err)
+	}
+	defer zr.Close()
+	tr := tar.NewReader(zr)
+
+	var names []string
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			break
+		}
+		names = append(names, header.Name)
+	}
+
+	expected := []string{manifestName, "cities.ndjson"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected entries %v, got %v", expected, names)
+	}
+}
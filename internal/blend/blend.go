@@ -0,0 +1,252 @@
+// Package blend combines the forecasts of several weather providers for one point
+// into a consensus forecast.
+//
+// Providers publish periods of different lengths at different times, so forecasts are
+// aligned by the hour they are valid from and each hour is blended from the providers
+// that cover it. Every measurement is reported as its blended value together with the
+// lowest and highest provider value, whose difference (the spread) shows how far the
+// providers disagree.
+package blend
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+// Method is how provider values are combined
+type Method string
+
+const (
+	// Median takes the weighted median, which ignores a single outlying provider
+	Median Method = "median"
+	// Mean takes the weighted mean
+	Mean Method = "mean"
+)
+
+// ParseMethod parses a blend method name; an empty value is Median
+func ParseMethod(value string) (Method, error) {
+	switch Method(strings.ToLower(strings.TrimSpace(value))) {
+	case "", Median:
+		return Median, nil
+	case Mean:
+		return Mean, nil
+	default:
+		return "", fmt.Errorf("method must be %s or %s, got %q", Median, Mean, value)
+	}
+}
+
+// Weights are the relative weights of providers by name. Providers not listed weigh 1,
+// and a provider weighing 0 is left out of blends.
+type Weights map[string]float64
+
+// ParseWeights parses provider weights given as name=weight entries, each value
+// holding one entry or several separated by commas (e.g. "NWS=2,Met.no=0.5").
+// Names are matched without regard to case.
+func ParseWeights(values []string) (Weights, error) {
+	weights := Weights{}
+	for _, value := range values {
+		for entry := range strings.SplitSeq(value, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			name, weight, ok := strings.Cut(entry, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("weight %q must be name=weight", entry)
+			}
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil || parsed < 0 || math.IsInf(parsed, 0) || math.IsNaN(parsed) {
+				return nil, fmt.Errorf("weight of %s must be a non-negative number, got %q", name, weight)
+			}
+			weights[strings.ToLower(strings.TrimSpace(name))] = parsed
+		}
+	}
+	return weights, nil
+}
+
+// Of returns the weight of a provider
+func (w Weights) Of(provider string) float64 {
+	if weight, ok := w[strings.ToLower(provider)]; ok {
+		return weight
+	}
+	return 1
+}
+
+// Merge returns the weights with overrides applied on top
+func (w Weights) Merge(overrides Weights) Weights {
+	merged := make(Weights, len(w)+len(overrides))
+	for name, weight := range w {
+		merged[name] = weight
+	}
+	for name, weight := range overrides {
+		merged[name] = weight
+	}
+	return merged
+}
+
+// Source is one provider's forecast for the point being blended
+type Source struct {
+	Provider  string
+	Forecasts []*models.Forecast
+}
+
+// Stat is one blended measurement. Spread is Max minus Min, 0 when a single provider
+// covers the hour.
+type Stat struct {
+	Value  float64
+	Min    float64
+	Max    float64
+	Spread float64
+}
+
+// Period is the blend of every provider forecast valid from the same hour
+type Period struct {
+	ValidTime     time.Time
+	Providers     []string // contributing providers, in source order
+	Temperature   Stat
+	FeelsLike     Stat
+	Humidity      Stat
+	Pressure      Stat
+	WindSpeed     Stat
+	WindGust      Stat
+	WindDirection float64 // weighted circular mean, whatever the method
+	CloudCover    Stat
+	Precipitation Stat
+}
+
+// member is one provider's forecast for a period
+type member struct {
+	provider string
+	weight   float64
+	forecast *models.Forecast
+}
+
+// Combine aligns the sources by valid hour and blends each hour, returning the periods
+// in time order. A provider with several forecasts starting in the same hour
+// contributes its first.
+func Combine(sources []Source, weights Weights, method Method) []*Period {
+	hours := map[time.Time][]member{}
+	for _, source := range sources {
+		weight := weights.Of(source.Provider)
+		if weight == 0 {
+			continue
+		}
+		seen := map[time.Time]bool{}
+		for _, f := range source.Forecasts {
+			if f == nil {
+				continue
+			}
+			hour := f.ValidTime.UTC().Truncate(time.Hour)
+			if seen[hour] {
+				continue
+			}
+			seen[hour] = true
+			hours[hour] = append(hours[hour], member{provider: source.Provider, weight: weight, forecast: f})
+		}
+	}
+
+	periods := make([]*Period, 0, len(hours))
+	for hour, members := range hours {
+		periods = append(periods, combinePeriod(hour, members, method))
+	}
+	slices.SortFunc(periods, func(a, b *Period) int { return a.ValidTime.Compare(b.ValidTime) })
+	return periods
+}
+
+func combinePeriod(hour time.Time, members []member, method Method) *Period {
+	stat := func(value func(*models.Forecast) float64) Stat {
+		return combine(members, value, method)
+	}
+	period := &Period{
+		ValidTime:     hour,
+		Temperature:   stat(func(f *models.Forecast) float64 { return f.Temperature }),
+		FeelsLike:     stat(func(f *models.Forecast) float64 { return f.FeelsLike }),
+		Humidity:      stat(func(f *models.Forecast) float64 { return f.Humidity }),
+		Pressure:      stat(func(f *models.Forecast) float64 { return f.Pressure }),
+		WindSpeed:     stat(func(f *models.Forecast) float64 { return f.WindSpeed }),
+		WindGust:      stat(func(f *models.Forecast) float64 { return f.WindGust }),
+		WindDirection: circularMean(members),
+		CloudCover:    stat(func(f *models.Forecast) float64 { return f.CloudCover }),
+		Precipitation: stat(func(f *models.Forecast) float64 { return f.Precipitation }),
+	}
+	for _, m := range members {
+		period.Providers = append(period.Providers, m.provider)
+	}
+	return period
+}
+
+// combine blends one measurement across a period's members
+func combine(members []member, value func(*models.Forecast) float64, method Method) Stat {
+	values := make([]float64, len(members))
+	weights := make([]float64, len(members))
+	for i, m := range members {
+		values[i], weights[i] = value(m.forecast), m.weight
+	}
+
+	stat := Stat{Min: slices.Min(values), Max: slices.Max(values)}
+	stat.Spread = stat.Max - stat.Min
+	if method == Mean {
+		stat.Value = weightedMean(values, weights)
+	} else {
+		stat.Value = weightedMedian(values, weights)
+	}
+	return stat
+}
+
+func weightedMean(values, weights []float64) float64 {
+	var sum, total float64
+	for i, value := range values {
+		sum += value * weights[i]
+		total += weights[i]
+	}
+	return sum / total
+}
+
+// weightedMedian returns the value at which half the total weight lies on either side,
+// averaging the two middle values when the weight splits exactly between them
+func weightedMedian(values, weights []float64) float64 {
+	order := make([]int, len(values))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortFunc(order, func(a, b int) int { return cmp.Compare(values[a], values[b]) })
+
+	var total float64
+	for _, weight := range weights {
+		total += weight
+	}
+	half, cumulative := total/2, 0.0
+	for i, j := range order {
+		cumulative += weights[j]
+		if math.Abs(cumulative-half) < 1e-9 && i+1 < len(order) {
+			return (values[j] + values[order[i+1]]) / 2
+		}
+		if cumulative > half {
+			return values[j]
+		}
+	}
+	return values[order[len(order)-1]]
+}
+
+// circularMean averages wind directions as weighted unit vectors, so 350° and 10°
+// blend to 0° rather than 180°
+func circularMean(members []member) float64 {
+	var x, y float64
+	for _, m := range members {
+		radians := m.forecast.WindDirection * math.Pi / 180
+		x += math.Cos(radians) * m.weight
+		y += math.Sin(radians) * m.weight
+	}
+	degrees := math.Atan2(y, x) * 180 / math.Pi
+	if degrees < 0 {
+		degrees += 360
+	}
+	return degrees
+}
@@ -0,0 +1,95 @@
+package blend
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+func TestParseMethod(t *testing.T) {
+	for value, expected := range map[string]Method{"": Median, "median": Median, "MEAN": Mean} {
+		if method, err := ParseMethod(value); err != nil || method != expected {
+			t.Errorf("ParseMethod(%q) = %q, %v; expected %q", value, method, err, expected)
+		}
+	}
+	if _, err := ParseMethod("mode"); err == nil {
+		t.Error("Expected an error for an unknown method")
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	weights, err := ParseWeights([]string{"NWS=2, Met.no=0.5", "Open-Meteo=0"})
+	if err != nil {
+		t.Fatalf("ParseWeights failed: %v", err)
+	}
+	if weights.Of("nws") != 2 || weights.Of("Met.no") != 0.5 || weights.Of("Open-Meteo") != 0 || weights.Of("Other") != 1 {
+		t.Errorf("Unexpected weights %v", weights)
+	}
+	if merged := weights.Merge(Weights{"nws": 3}); merged.Of("NWS") != 3 || weights.Of("NWS") != 2 {
+		t.Errorf("Expected Merge to override without changing the receiver, got %v", merged)
+	}
+
+	for _, value := range []string{"NWS", "=2", "NWS=-1", "NWS=heavy", "NWS=Inf"} {
+		if _, err := ParseWeights([]string{value}); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestWeightedMedian(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		weights  []float64
+		expected float64
+	}{
+		{"single", []float64{4}, []float64{1}, 4},
+		{"odd", []float64{9, 1, 5}, []float64{1, 1, 1}, 5},
+		{"even averages the middle", []float64{1, 2, 3, 10}, []float64{1, 1, 1, 1}, 2.5},
+		{"weight pulls the median", []float64{1, 2, 3}, []float64{1, 1, 3}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := weightedMedian(tt.values, tt.weights); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	noon := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+	forecast := func(valid time.Time, temperature, direction float64) *models.Forecast {
+		return &models.Forecast{ValidTime: valid, Temperature: temperature, WindDirection: direction}
+	}
+	sources := []Source{
+		{Provider: "A", Forecasts: []*models.Forecast{forecast(noon, 20, 350), forecast(noon.Add(time.Hour), 22, 0)}},
+		{Provider: "B", Forecasts: []*models.Forecast{forecast(noon.Add(30*time.Minute), 24, 10), forecast(noon.Add(45*time.Minute), 99, 0)}},
+		{Provider: "C", Forecasts: []*models.Forecast{forecast(noon, 40, 180)}},
+	}
+
+	periods := Combine(sources, Weights{"c": 0}, Mean)
+	if len(periods) != 2 {
+		t.Fatalf("Expected 2 aligned hours, got %d", len(periods))
+	}
+	first := periods[0]
+	if !first.ValidTime.Equal(noon) || len(first.Providers) != 2 {
+		t.Fatalf("Expected A and B at noon, got %+v", first)
+	}
+	if first.Temperature != (Stat{Value: 22, Min: 20, Max: 24, Spread: 4}) {
+		t.Errorf("Expected a mean of 22 with a spread of 4, got %+v", first.Temperature)
+	}
+	if math.Abs(first.WindDirection) > 1e-9 && math.Abs(first.WindDirection-360) > 1e-9 {
+		t.Errorf("Expected 350° and 10° to blend to north, got %v", first.WindDirection)
+	}
+	if second := periods[1]; second.Temperature.Spread != 0 || second.Providers[0] != "A" {
+		t.Errorf("Expected A alone at 13:00, got %+v", second)
+	}
+
+	weighted := Combine(sources[:2], Weights{"a": 3}, Mean)
+	if weighted[0].Temperature.Value != 21 {
+		t.Errorf("Expected A weighted 3:1 to give 21, got %v", weighted[0].Temperature.Value)
+	}
+}
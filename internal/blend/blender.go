@@ -0,0 +1,87 @@
+package blend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// Failure is a provider left out of a blend because its forecast could not be fetched
+type Failure struct {
+	Provider string
+	Err      error
+}
+
+// Result is a blended forecast with the providers it was made from
+type Result struct {
+	Providers []string  // providers that returned a forecast, in registration order
+	Failures  []Failure // providers that failed, in registration order
+	Periods   []*Period
+}
+
+// Blender fetches one point's forecast from every weather provider concurrently and
+// blends the results
+type Blender struct {
+	providers []providers.WeatherProvider
+	weights   Weights
+}
+
+// NewBlender creates a blender over providers, weighting them by weights unless a
+// request overrides them
+func NewBlender(providers []providers.WeatherProvider, weights Weights) *Blender {
+	return &Blender{providers: providers, weights: weights}
+}
+
+// Blend fetches days of forecast for a point from every provider with a non-zero weight
+// and blends them. Providers that fail are reported in the result's Failures; when
+// every provider fails, the joined errors are returned instead.
+func (b *Blender) Blend(ctx context.Context, lat, lon float64, days int, method Method, overrides Weights) (*Result, error) {
+	weights := b.weights.Merge(overrides)
+
+	var active []providers.WeatherProvider
+	for _, provider := range b.providers {
+		if weights.Of(provider.GetName()) > 0 {
+			active = append(active, provider)
+		}
+	}
+	if len(active) == 0 {
+		return nil, fmt.Errorf("%w: no weather provider has a weight above 0", providers.ErrUnsupportedRegion)
+	}
+
+	sources := make([]Source, len(active))
+	errs := make([]error, len(active))
+	var wg sync.WaitGroup
+	for i, provider := range active {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			forecasts, err := provider.GetForecast(ctx, lat, lon, days)
+			sources[i], errs[i] = Source{Provider: provider.GetName(), Forecasts: forecasts}, err
+		}()
+	}
+	wg.Wait()
+
+	result := &Result{}
+	var succeeded []Source
+	for i, source := range sources {
+		if errs[i] != nil {
+			result.Failures = append(result.Failures, Failure{Provider: source.Provider, Err: errs[i]})
+			continue
+		}
+		result.Providers = append(result.Providers, source.Provider)
+		succeeded = append(succeeded, source)
+	}
+	if len(succeeded) == 0 {
+		failures := make([]error, 0, len(result.Failures))
+		for _, failure := range result.Failures {
+			failures = append(failures, fmt.Errorf("%s: %w", failure.Provider, failure.Err))
+		}
+		return nil, errors.Join(failures...)
+	}
+
+	result.Periods = Combine(succeeded, weights, method)
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package blend
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// stubProvider returns one forecast at a fixed temperature, or err
+type stubProvider struct {
+	name        string
+	temperature float64
+	err         error
+}
+
+func (s *stubProvider) GetName() string { return s.name }
+
+func (s *stubProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *stubProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	valid := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+	return []*models.Forecast{{SourceProvider: s.name, ValidTime: valid, Temperature: s.temperature}}, nil
+}
+
+func (s *stubProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]providers.WeatherAlert, error) {
+	return nil, nil
+}
+
+func (s *stubProvider) SupportedRegions() []string { return []string{"US"} }
+
+func TestBlender(t *testing.T) {
+	ctx := context.Background()
+	blender := NewBlender([]providers.WeatherProvider{
+		&stubProvider{name: "A", temperature: 10},
+		&stubProvider{name: "B", err: providers.ErrUpstreamUnavailable},
+		&stubProvider{name: "C", temperature: 20},
+		&stubProvider{name: "D", temperature: 90},
+	}, Weights{"d": 0})
+
+	result, err := blender.Blend(ctx, 40, -90, 1, Median, nil)
+	if err != nil {
+		t.Fatalf("Blend failed: %v", err)
+	}
+	if len(result.Providers) != 2 || result.Providers[0] != "A" || result.Providers[1] != "C" {
+		t.Errorf("Expected A and C to contribute, got %v", result.Providers)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].Provider != "B" {
+		t.Errorf("Expected B to be reported as failed, got %+v", result.Failures)
+	}
+	if len(result.Periods) != 1 || result.Periods[0].Temperature.Value != 15 {
+		t.Errorf("Expected a median of 15, got %+v", result.Periods)
+	}
+
+	t.Run("request weights override", func(t *testing.T) {
+		result, err := blender.Blend(ctx, 40, -90, 1, Mean, Weights{"d": 1, "c": 0})
+		if err != nil {
+			t.Fatalf("Blend failed: %v", err)
+		}
+		if result.Periods[0].Temperature.Value != 50 {
+			t.Errorf("Expected the mean of A and D, got %+v", result.Periods[0].Temperature)
+		}
+	})
+
+	t.Run("every provider fails", func(t *testing.T) {
+		failing := NewBlender([]providers.WeatherProvider{&stubProvider{name: "B", err: providers.ErrUpstreamUnavailable}}, nil)
+		if _, err := failing.Blend(ctx, 40, -90, 1, Median, nil); !errors.Is(err, providers.ErrUpstreamUnavailable) {
+			t.Errorf("Expected the provider's error, got %v", err)
+		}
+		if _, err := blender.Blend(ctx, 40, -90, 1, Median, Weights{"a": 0, "b": 0, "c": 0}); !errors.Is(err, providers.ErrUnsupportedRegion) {
+			t.Errorf("Expected ErrUnsupportedRegion when every weight is 0, got %v", err)
+		}
+	})
+}
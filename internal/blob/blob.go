@@ -0,0 +1,136 @@
+// Package blob stores large binary objects, such as export chunks, outside the
+// database. Objects are addressed by slash-separated keys and read back as seekable
+// streams so they can be served with HTTP Range support.
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("object not found")
+
+// Object is an open stored object
+type Object interface {
+	io.ReadSeekCloser
+
+	// Size returns the object size in bytes
+	Size() int64
+
+	// ModTime returns when the object was written
+	ModTime() time.Time
+}
+
+// Store is an object storage backend
+type Store interface {
+	// Put writes r under key, replacing any existing object, and returns the number of
+	// bytes written. A failed Put leaves no partial object behind.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+
+	// Open opens the object under key, returning ErrNotFound when there is none
+	Open(ctx context.Context, key string) (Object, error)
+
+	// Delete removes every object whose key starts with prefix followed by a slash, or
+	// the object named prefix itself
+	Delete(ctx context.Context, prefix string) error
+}
+
+// FileStore implements Store on a local directory, one file per object
+type FileStore struct {
+	root string
+}
+
+// NewFileStore creates a store rooted at dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+	return &FileStore{root: dir}, nil
+}
+
+// Put writes the object to a temporary file and renames it into place
+func (s *FileStore) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create blob directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), "."+filepath.Base(name)+"-*.tmp")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to write object %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return 0, fmt.Errorf("failed to store object %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// Open opens the object file
+func (s *FileStore) Open(ctx context.Context, key string) (Object, error) {
+	name, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return &fileObject{File: file, info: info}, nil
+}
+
+// Delete removes the object file or directory
+func (s *FileStore) Delete(ctx context.Context, prefix string) error {
+	name, err := s.path(prefix)
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(name)
+}
+
+// path maps a key to a file below the root, rejecting keys that would escape it
+func (s *FileStore) path(key string) (string, error) {
+	clean := path.Clean("/" + key)
+	if key == "" || clean == "/" || clean != "/"+key || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+type fileObject struct {
+	*os.File
+	info os.FileInfo
+}
+
+func (o *fileObject) Size() int64        { return o.info.Size() }
+func (o *fileObject) ModTime() time.Time { return o.info.ModTime() }
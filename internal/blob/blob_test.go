@@ -0,0 +1,54 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileStore failed: %v", err)
+	}
+
+	n, err := store.Put(ctx, "exports/job/part-0001.csv", strings.NewReader("hello world"))
+	if err != nil || n != 11 {
+		t.Fatalf("Put returned %d, %v", n, err)
+	}
+
+	obj, err := store.Open(ctx, "exports/job/part-0001.csv")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if obj.Size() != 11 || obj.ModTime().IsZero() {
+		t.Errorf("unexpected size %d or mod time", obj.Size())
+	}
+	if _, err := obj.Seek(6, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	rest, _ := io.ReadAll(obj)
+	obj.Close()
+	if string(rest) != "world" {
+		t.Errorf("expected to seek into the object, read %q", rest)
+	}
+
+	if err := store.Delete(ctx, "exports/job"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := store.Open(ctx, "exports/job/part-0001.csv"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestFileStore_InvalidKeys(t *testing.T) {
+	store, _ := NewFileStore(t.TempDir())
+	for _, key := range []string{"", "/", "../escape", "a/../../b", "a//b", `a\b`} {
+		if _, err := store.Put(context.Background(), key, strings.NewReader("x")); err == nil {
+			t.Errorf("expected key %q to be rejected", key)
+		}
+	}
+}
@@ -0,0 +1,290 @@
+// Package breaker stops calling providers that keep failing.
+//
+// Every provider's HTTP client passes its requests through a Breaker shared by all
+// clients of that provider (see NewTransport). The breaker opens after a run of
+// consecutive failures, or when too many of the recent requests timed out, and then
+// fails requests immediately with ErrOpen instead of letting each one wait out the
+// provider's timeout. After a cooldown it lets a single probe request through
+// (half-open): success closes the breaker, failure opens it for another cooldown.
+//
+// Breaker states are published as the "provider_breakers" expvar.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Breaker states
+const (
+	Closed   = "closed"
+	Open     = "open"
+	HalfOpen = "half_open"
+)
+
+// ErrOpen is returned for requests short-circuited by an open breaker
+var ErrOpen = errors.New("circuit breaker open")
+
+// OpenError is a request short-circuited by an open breaker. It wraps ErrOpen.
+type OpenError struct {
+	Provider   string
+	RetryAfter time.Duration // until the breaker lets a probe through
+}
+
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%s: %v, retry in %s", e.Provider, ErrOpen, e.RetryAfter.Round(time.Second))
+}
+
+func (e *OpenError) Unwrap() error { return ErrOpen }
+
+// Config sets when breakers open and for how long
+type Config struct {
+	// Failures is the number of consecutive failed requests that opens a breaker
+	Failures int
+
+	// TimeoutRatio opens a breaker once this share of the last Window requests timed out
+	TimeoutRatio float64
+
+	// Window is the number of recent requests TimeoutRatio is measured over; the ratio
+	// is not checked until that many requests were made
+	Window int
+
+	// Cooldown is how long a breaker stays open before letting a probe through
+	Cooldown time.Duration
+}
+
+// DefaultConfig opens after 5 consecutive failures or when half of the last 20 requests
+// timed out, and probes again after 30 seconds
+func DefaultConfig() Config {
+	return Config{Failures: 5, TimeoutRatio: 0.5, Window: 20, Cooldown: 30 * time.Second}
+}
+
+// Validate reports settings that would never open a breaker or never close one
+func (c Config) Validate() error {
+	switch {
+	case c.Failures <= 0:
+		return fmt.Errorf("breaker failures must be positive, got %d", c.Failures)
+	case c.TimeoutRatio <= 0 || c.TimeoutRatio > 1:
+		return fmt.Errorf("breaker timeout ratio must be in (0, 1], got %g", c.TimeoutRatio)
+	case c.Window <= 0:
+		return fmt.Errorf("breaker window must be positive, got %d", c.Window)
+	case c.Cooldown <= 0:
+		return fmt.Errorf("breaker cooldown must be positive, got %s", c.Cooldown)
+	}
+	return nil
+}
+
+// Stats is a snapshot of a breaker
+type Stats struct {
+	Provider            string    `json:"provider"`
+	State               string    `json:"state"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	TimeoutRatio        float64   `json:"timeout_ratio"` // over the recent window
+	Opened              int64     `json:"opened"`        // times the breaker opened
+	Rejected            int64     `json:"rejected"`      // requests short-circuited
+	Changed             time.Time `json:"changed"`       // last state change, zero if never
+}
+
+// Breaker tracks the outcomes of one provider's requests
+type Breaker struct {
+	provider string
+	now      func() time.Time
+
+	mu       sync.Mutex
+	config   Config
+	state    string
+	failures int
+	recent   []bool // timeouts of the last config.Window requests, oldest overwritten first
+	next     int
+	filled   bool
+	openedAt time.Time
+	probing  bool
+	opened   int64
+	rejected int64
+	changed  time.Time
+}
+
+// New creates a closed breaker for a provider
+func New(provider string, config Config) *Breaker {
+	return &Breaker{provider: provider, now: time.Now, config: config, state: Closed, recent: make([]bool, config.Window)}
+}
+
+// Allow reports whether a request may be made, returning an *OpenError when it may not.
+// A nil error must be followed by a call to Record or Abandon.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == Open {
+		wait := b.config.Cooldown - b.now().Sub(b.openedAt)
+		if wait > 0 {
+			b.rejected++
+			return &OpenError{Provider: b.provider, RetryAfter: wait}
+		}
+		b.setState(HalfOpen)
+	}
+	if b.state == HalfOpen {
+		if b.probing {
+			b.rejected++
+			return &OpenError{Provider: b.provider, RetryAfter: time.Second}
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record records the outcome of an allowed request
+func (b *Breaker) Record(failed, timedOut bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.recent[b.next] = timedOut
+	b.next = (b.next + 1) % len(b.recent)
+	b.filled = b.filled || b.next == 0
+
+	if b.state == HalfOpen {
+		b.probing = false
+		if failed {
+			b.open()
+		} else {
+			b.reset()
+		}
+		return
+	}
+
+	if !failed {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.config.Failures || (b.filled && b.timeoutRatio() >= b.config.TimeoutRatio) {
+		b.open()
+	}
+}
+
+// Abandon releases an allowed request whose outcome says nothing about the provider,
+// such as one the caller canceled
+func (b *Breaker) Abandon() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == HalfOpen {
+		b.probing = false
+	}
+}
+
+// Stats returns a snapshot of the breaker
+func (b *Breaker) Stats() Stats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state := b.state
+	if state == Open && b.now().Sub(b.openedAt) >= b.config.Cooldown {
+		state = HalfOpen // the next request will probe
+	}
+	return Stats{
+		Provider:            b.provider,
+		State:               state,
+		ConsecutiveFailures: b.failures,
+		TimeoutRatio:        b.timeoutRatio(),
+		Opened:              b.opened,
+		Rejected:            b.rejected,
+		Changed:             b.changed,
+	}
+}
+
+// configure replaces the breaker's settings and closes it
+func (b *Breaker) configure(config Config) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.config = config
+	b.recent = make([]bool, config.Window)
+	b.next, b.filled, b.probing, b.failures = 0, false, false, 0
+	b.state = Closed
+}
+
+func (b *Breaker) open() {
+	b.opened++
+	b.openedAt = b.now()
+	b.setState(Open)
+}
+
+// reset closes the breaker with a clean history
+func (b *Breaker) reset() {
+	b.failures = 0
+	clear(b.recent)
+	b.next, b.filled = 0, false
+	b.setState(Closed)
+}
+
+func (b *Breaker) setState(state string) {
+	if b.state != state {
+		b.state = state
+		b.changed = b.now()
+	}
+}
+
+// timeoutRatio is the share of the recorded window that timed out
+func (b *Breaker) timeoutRatio() float64 {
+	count := b.next
+	if b.filled {
+		count = len(b.recent)
+	}
+	if count == 0 {
+		return 0
+	}
+	timeouts := 0
+	for _, timedOut := range b.recent[:count] {
+		if timedOut {
+			timeouts++
+		}
+	}
+	return float64(timeouts) / float64(count)
+}
+
+// Transport is an http.RoundTripper passing requests through a provider's breaker.
+// Network errors, timeouts and 5xx responses count as failures; requests the caller
+// canceled count as neither failure nor success.
+type Transport struct {
+	Breaker *Breaker
+
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport using the shared breaker of the named provider
+func NewTransport(provider string, base http.RoundTripper) *Transport {
+	return &Transport{Breaker: For(provider), Base: base}
+}
+
+// RoundTrip makes the request unless the breaker is open
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if err := t.Breaker.Allow(); err != nil {
+		return nil, err
+	}
+
+	resp, err := base.RoundTrip(req)
+	switch {
+	case err != nil && errors.Is(err, context.Canceled) && req.Context().Err() != nil:
+		t.Breaker.Abandon()
+	case err != nil:
+		t.Breaker.Record(true, isTimeout(err))
+	default:
+		t.Breaker.Record(resp.StatusCode >= http.StatusInternalServerError, false)
+	}
+	return resp, err
+}
+
+// isTimeout reports whether a request failed by running out of time
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
@@ -0,0 +1,157 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestBreaker returns a breaker with a controllable clock
+func newTestBreaker(config Config) (*Breaker, *time.Time) {
+	now := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+	b := New("Test", config)
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected the default config to be valid, got %v", err)
+	}
+	for name, config := range map[string]Config{
+		"failures": {Failures: 0, TimeoutRatio: 0.5, Window: 20, Cooldown: time.Second},
+		"ratio":    {Failures: 5, TimeoutRatio: 1.5, Window: 20, Cooldown: time.Second},
+		"window":   {Failures: 5, TimeoutRatio: 0.5, Window: 0, Cooldown: time.Second},
+		"cooldown": {Failures: 5, TimeoutRatio: 0.5, Window: 20},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected an invalid %s to be rejected", name)
+		}
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	t.Run("opens after consecutive failures", func(t *testing.T) {
+		b, now := newTestBreaker(Config{Failures: 3, TimeoutRatio: 1, Window: 10, Cooldown: 30 * time.Second})
+		for _, failed := range []bool{true, true, false, true, true} {
+			if err := b.Allow(); err != nil {
+				t.Fatalf("Expected a closed breaker, got %v", err)
+			}
+			b.Record(failed, false)
+		}
+		if state := b.Stats().State; state != Closed {
+			t.Fatalf("Expected a success to reset the count, got %s", state)
+		}
+		b.Allow()
+		b.Record(true, false)
+
+		var openErr *OpenError
+		if err := b.Allow(); !errors.As(err, &openErr) || !errors.Is(err, ErrOpen) {
+			t.Fatalf("Expected an open breaker, got %v", err)
+		}
+		if openErr.RetryAfter != 30*time.Second {
+			t.Errorf("Expected a 30s retry hint, got %s", openErr.RetryAfter)
+		}
+		*now = now.Add(10 * time.Second)
+		if err := b.Allow(); !errors.As(err, &openErr) || openErr.RetryAfter != 20*time.Second {
+			t.Errorf("Expected a 20s retry hint, got %v", err)
+		}
+		if stats := b.Stats(); stats.State != Open || stats.Opened != 1 || stats.Rejected != 2 {
+			t.Errorf("Unexpected stats %+v", stats)
+		}
+	})
+
+	t.Run("opens on timeout ratio", func(t *testing.T) {
+		b, _ := newTestBreaker(Config{Failures: 100, TimeoutRatio: 0.5, Window: 4, Cooldown: time.Second})
+		for _, timedOut := range []bool{false, true, false} {
+			b.Allow()
+			b.Record(timedOut, timedOut)
+		}
+		if b.Stats().State != Closed {
+			t.Fatal("Expected the ratio to wait for a full window")
+		}
+		b.Allow()
+		b.Record(true, true)
+		if stats := b.Stats(); stats.State != Open || stats.TimeoutRatio != 0.5 {
+			t.Errorf("Expected 2 of 4 timeouts to open the breaker, got %+v", stats)
+		}
+	})
+
+	t.Run("half-open probe", func(t *testing.T) {
+		b, now := newTestBreaker(Config{Failures: 1, TimeoutRatio: 1, Window: 10, Cooldown: time.Minute})
+		b.Allow()
+		b.Record(true, false)
+
+		*now = now.Add(time.Minute)
+		if state := b.Stats().State; state != HalfOpen {
+			t.Fatalf("Expected half-open after the cooldown, got %s", state)
+		}
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected a probe to be let through, got %v", err)
+		}
+		if err := b.Allow(); !errors.Is(err, ErrOpen) {
+			t.Fatalf("Expected a single probe at a time, got %v", err)
+		}
+		b.Record(true, false)
+		if stats := b.Stats(); stats.State != Open || stats.Opened != 2 {
+			t.Fatalf("Expected a failed probe to reopen the breaker, got %+v", stats)
+		}
+
+		*now = now.Add(time.Minute)
+		b.Allow()
+		b.Abandon()
+		if err := b.Allow(); err != nil {
+			t.Fatalf("Expected an abandoned probe to free the slot, got %v", err)
+		}
+		b.Record(false, false)
+		if stats := b.Stats(); stats.State != Closed || stats.ConsecutiveFailures != 0 {
+			t.Errorf("Expected a successful probe to close the breaker, got %+v", stats)
+		}
+	})
+}
+
+func TestTransport(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	b, _ := newTestBreaker(Config{Failures: 2, TimeoutRatio: 1, Window: 10, Cooldown: time.Minute})
+	client := &http.Client{Transport: &Transport{Breaker: b}}
+	get := func(ctx context.Context) error {
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+		resp, err := client.Do(req)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	status = http.StatusNotFound
+	for range 3 {
+		if err := get(context.Background()); err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+	}
+	if b.Stats().ConsecutiveFailures != 0 {
+		t.Fatal("Expected client errors not to count as failures")
+	}
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = get(canceled)
+	if b.Stats().ConsecutiveFailures != 0 {
+		t.Fatal("Expected canceled requests not to count as failures")
+	}
+
+	status = http.StatusBadGateway
+	_ = get(context.Background())
+	_ = get(context.Background())
+	if err := get(context.Background()); !errors.Is(err, ErrOpen) {
+		t.Errorf("Expected the third request to be short-circuited, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package breaker
+
+import (
+	"expvar"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// registry holds the breaker of every provider by name
+var registry = struct {
+	sync.Mutex
+	config   Config
+	breakers map[string]*Breaker
+}{config: DefaultConfig(), breakers: map[string]*Breaker{}}
+
+func init() {
+	expvar.Publish("provider_breakers", expvar.Func(func() any { return All() }))
+}
+
+// For returns the breaker shared by every client of a provider, creating it on first use
+func For(provider string) *Breaker {
+	registry.Lock()
+	defer registry.Unlock()
+	b, ok := registry.breakers[provider]
+	if !ok {
+		b = New(provider, registry.config)
+		registry.breakers[provider] = b
+	}
+	return b
+}
+
+// Lookup returns the breaker of a provider, if any of its clients made one
+func Lookup(provider string) (*Breaker, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	b, ok := registry.breakers[provider]
+	return b, ok
+}
+
+// Configure validates config and applies it to every breaker, closing them
+func Configure(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.config = config
+	for _, b := range registry.breakers {
+		b.configure(config)
+	}
+	return nil
+}
+
+// All returns a snapshot of every breaker, ordered by provider
+func All() []Stats {
+	registry.Lock()
+	breakers := make([]*Breaker, 0, len(registry.breakers))
+	for _, b := range registry.breakers {
+		breakers = append(breakers, b)
+	}
+	registry.Unlock()
+
+	stats := make([]Stats, 0, len(breakers))
+	for _, b := range breakers {
+		stats = append(stats, b.Stats())
+	}
+	slices.SortFunc(stats, func(a, b Stats) int { return strings.Compare(a.Provider, b.Provider) })
+	return stats
+}
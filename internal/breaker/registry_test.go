@@ -0,0 +1,46 @@
+package breaker
+
+import (
+	"expvar"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	b := For("Registry Test")
+	if For("Registry Test") != b {
+		t.Fatal("Expected clients of a provider to share its breaker")
+	}
+	if _, ok := Lookup("Registry Unknown"); ok {
+		t.Error("Expected Lookup not to create breakers")
+	}
+
+	b.Allow()
+	b.Record(true, false)
+	if err := Configure(Config{Failures: 1, TimeoutRatio: 1, Window: 5, Cooldown: time.Minute}); err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+	defer Configure(DefaultConfig())
+	if stats := b.Stats(); stats.State != Closed || stats.ConsecutiveFailures != 0 {
+		t.Errorf("Expected Configure to reset existing breakers, got %+v", stats)
+	}
+	b.Allow()
+	b.Record(true, false)
+	if b.Stats().State != Open {
+		t.Error("Expected the new failure threshold to apply")
+	}
+	if err := Configure(Config{}); err == nil {
+		t.Error("Expected an invalid config to be rejected")
+	}
+
+	found := false
+	for _, stats := range All() {
+		found = found || stats.Provider == "Registry Test"
+	}
+	if !found {
+		t.Error("Expected All to list the breaker")
+	}
+	if expvar.Get("provider_breakers") == nil {
+		t.Error("Expected breakers to be published as an expvar")
+	}
+}
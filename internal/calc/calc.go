@@ -0,0 +1,82 @@
+// Package calc derives the meteorological quantities providers may leave out (dew
+// point, heat index, wind chill and the "feels like" temperature) from the ones every
+// forecast carries.
+//
+// Inputs and results are metric like stored forecasts: °C, relative humidity in
+// percent and wind speed in m/s. Each quantity is only defined under some conditions
+// (a heat index in the heat, a wind chill in the cold and wind); outside them the
+// functions report false rather than an extrapolated value.
+package calc
+
+import (
+	"math"
+
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+const (
+	// heatIndexMinimum is the temperature (80 °F) from which the NWS heat index applies
+	heatIndexMinimum = 26.7
+	// windChillMaximum is the temperature (50 °F) up to which wind chill applies
+	windChillMaximum = 10.0
+	// windChillMinimumWind is the wind speed (3 mph) from which wind chill applies
+	windChillMinimumWind = 4.8 / 3.6
+)
+
+// DewPoint returns the dew point in °C from the Magnus formula, accurate within 0.1 °C
+// between -40 and 50 °C. humidity must be above 0.
+func DewPoint(temperature, humidity float64) (float64, bool) {
+	if humidity <= 0 {
+		return 0, false
+	}
+	const a, b = 17.625, 243.04
+	gamma := math.Log(math.Min(humidity, 100)/100) + a*temperature/(b+temperature)
+	return b * gamma / (a - gamma), true
+}
+
+// HeatIndex returns the NWS heat index in °C: the Rothfusz regression with its
+// low- and high-humidity adjustments. It applies from 26.7 °C (80 °F) with a known
+// humidity.
+func HeatIndex(temperature, humidity float64) (float64, bool) {
+	if temperature < heatIndexMinimum || humidity <= 0 {
+		return 0, false
+	}
+	t := units.CelsiusToFahrenheit(temperature)
+	rh := math.Min(humidity, 100)
+
+	index := 0.5 * (t + 61 + (t-68)*1.2 + rh*0.094)
+	if (index+t)/2 >= 80 {
+		index = -42.379 + 2.04901523*t + 10.14333127*rh - 0.22475541*t*rh -
+			0.00683783*t*t - 0.05481717*rh*rh + 0.00122874*t*t*rh +
+			0.00085282*t*rh*rh - 0.00000199*t*t*rh*rh
+		switch {
+		case rh < 13 && t <= 112:
+			index -= (13 - rh) / 4 * math.Sqrt((17-math.Abs(t-95))/17)
+		case rh > 85 && t <= 87:
+			index += (rh - 85) / 10 * (87 - t) / 5
+		}
+	}
+	return (index - 32) * 5 / 9, true
+}
+
+// WindChill returns the wind chill in °C from the 2001 NWS and Environment Canada
+// formula. It applies up to 10 °C (50 °F) with winds above 4.8 km/h (3 mph).
+func WindChill(temperature, windSpeed float64) (float64, bool) {
+	if temperature > windChillMaximum || windSpeed <= windChillMinimumWind {
+		return 0, false
+	}
+	v := math.Pow(windSpeed*3.6, 0.16)
+	return 13.12 + 0.6215*temperature - 11.37*v + 0.3965*temperature*v, true
+}
+
+// FeelsLike returns the apparent temperature in °C: the heat index in the heat, the
+// wind chill in the cold and wind, and the temperature itself otherwise
+func FeelsLike(temperature, humidity, windSpeed float64) float64 {
+	if index, ok := HeatIndex(temperature, humidity); ok {
+		return index
+	}
+	if chill, ok := WindChill(temperature, windSpeed); ok {
+		return chill
+	}
+	return temperature
+}
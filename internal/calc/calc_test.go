@@ -0,0 +1,80 @@
+package calc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDewPoint(t *testing.T) {
+	tests := []struct {
+		temperature, humidity, want float64
+	}{
+		{20, 50, 9.3},
+		{30, 70, 23.9},
+		{-10, 80, -12.8},
+		{15, 100, 15},
+	}
+	for _, tt := range tests {
+		got, ok := DewPoint(tt.temperature, tt.humidity)
+		if !ok || math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("DewPoint(%v, %v) = %.2f, %v, want %v", tt.temperature, tt.humidity, got, ok, tt.want)
+		}
+	}
+	if _, ok := DewPoint(20, 0); ok {
+		t.Error("Expected no dew point without humidity")
+	}
+}
+
+func TestHeatIndex(t *testing.T) {
+	// Values from the NWS heat index chart, converted to °C
+	tests := []struct {
+		temperature, humidity, want float64
+	}{
+		{32.2, 70, 41.1}, // 90 °F, 70% → 106 °F
+		{35, 50, 41.1},   // 95 °F, 50% → 105 °F
+		{26.7, 40, 26.7}, // 80 °F, 40% → 80 °F
+	}
+	for _, tt := range tests {
+		got, ok := HeatIndex(tt.temperature, tt.humidity)
+		if !ok || math.Abs(got-tt.want) > 0.6 {
+			t.Errorf("HeatIndex(%v, %v) = %.2f, %v, want %v", tt.temperature, tt.humidity, got, ok, tt.want)
+		}
+	}
+	if _, ok := HeatIndex(25, 80); ok {
+		t.Error("Expected no heat index below 26.7 °C")
+	}
+}
+
+func TestWindChill(t *testing.T) {
+	tests := []struct {
+		temperature, windSpeed, want float64
+	}{
+		{-10, 30 / 3.6, -19.5},
+		{0, 20 / 3.6, -5.2},
+		{-30, 50 / 3.6, -49.0},
+	}
+	for _, tt := range tests {
+		got, ok := WindChill(tt.temperature, tt.windSpeed)
+		if !ok || math.Abs(got-tt.want) > 0.1 {
+			t.Errorf("WindChill(%v, %v) = %.2f, %v, want %v", tt.temperature, tt.windSpeed, got, ok, tt.want)
+		}
+	}
+	if _, ok := WindChill(15, 10); ok {
+		t.Error("Expected no wind chill above 10 °C")
+	}
+	if _, ok := WindChill(-5, 1); ok {
+		t.Error("Expected no wind chill in calm air")
+	}
+}
+
+func TestFeelsLike(t *testing.T) {
+	if got := FeelsLike(20, 50, 5); got != 20 {
+		t.Errorf("Expected the temperature in mild weather, got %v", got)
+	}
+	if index, _ := HeatIndex(32, 70); FeelsLike(32, 70, 5) != index {
+		t.Error("Expected the heat index in the heat")
+	}
+	if chill, _ := WindChill(-10, 8); FeelsLike(-10, 50, 8) != chill {
+		t.Error("Expected the wind chill in the cold")
+	}
+}
@@ -0,0 +1,34 @@
+package certs
+
+import (
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// renewBefore is how long before expiry a certificate is replaced
+const renewBefore = 30 * 24 * time.Hour
+
+// NewManager creates an autocert manager obtaining and renewing certificates for
+// domains from Let's Encrypt. Certificates and the account key are cached in cacheDir
+// so restarts don't count against the CA's rate limits. The manager solves TLS-ALPN-01
+// challenges on the HTTPS listener and HTTP-01 challenges through RedirectHandler on
+// port 80.
+func NewManager(cacheDir, email string, domains ...string) *autocert.Manager {
+	normalized := make([]string, len(domains))
+	for i, domain := range domains {
+		normalized[i] = normalizeHost(domain)
+	}
+	return &autocert.Manager{
+		Prompt:      autocert.AcceptTOS,
+		Cache:       autocert.DirCache(cacheDir),
+		HostPolicy:  autocert.HostWhitelist(normalized...),
+		Email:       email,
+		RenewBefore: renewBefore,
+	}
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+}
@@ -0,0 +1,66 @@
+package certs
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/acme"
+)
+
+func TestManager_GetCertificate(t *testing.T) {
+	dir := t.TempDir()
+	keyPEM, certPEM := writeCertificate(t, "api.example.com", time.Now().Add(60*24*time.Hour))
+	if err := os.WriteFile(filepath.Join(dir, "api.example.com"), append(keyPEM, certPEM...), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, manager, err := Config{Domains: []string{"API.example.com."}, CacheDir: dir}.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Unreachable CA: every certificate must come from the cache
+	manager.Client = &acme.Client{DirectoryURL: "http://127.0.0.1:1/directory"}
+	if config.MinVersion != tls.VersionTLS12 || !slices.Contains(config.NextProtos, acme.ALPNProto) {
+		t.Errorf("Expected the modern defaults with the TLS-ALPN-01 protocol, got %+v", config)
+	}
+
+	hello := func(name string, protos ...string) *tls.ClientHelloInfo {
+		return &tls.ClientHelloInfo{
+			ServerName:      name,
+			CipherSuites:    []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+			SupportedProtos: protos,
+		}
+	}
+	cert, err := config.GetCertificate(hello("api.example.com"))
+	if err != nil || cert.Leaf.Subject.CommonName != "api.example.com" {
+		t.Fatalf("Expected the cached certificate, got %v", err)
+	}
+	if _, err := config.GetCertificate(hello("other.example.com")); err == nil {
+		t.Error("Expected a host outside the configured domains to be refused")
+	}
+	if _, err := config.GetCertificate(hello("api.example.com", acme.ALPNProto)); err == nil {
+		t.Error("Expected a TLS-ALPN-01 handshake without a pending challenge to be refused")
+	}
+}
+
+func TestRedirectHandler_Challenges(t *testing.T) {
+	handler := RedirectHandler("443", NewManager(t.TempDir(), "", "api.example.com"))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "http://api.example.com/.well-known/acme-challenge/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected unknown challenges to be 404, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "http://api.example.com/forecasts", nil))
+	if w.Code != http.StatusPermanentRedirect || w.Header().Get("Location") != "https://api.example.com/forecasts" {
+		t.Errorf("Expected other requests to be redirected, got %d to %s", w.Code, w.Header().Get("Location"))
+	}
+}
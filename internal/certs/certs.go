@@ -0,0 +1,124 @@
+// Package certs lets the API server terminate HTTPS itself, either with a certificate
+// and key from disk or with certificates issued by an ACME CA such as Let's Encrypt.
+//
+// TLS listeners use modern defaults: TLS 1.2 or newer, forward-secret AEAD cipher
+// suites only, and X25519/P-256 key exchange. Plain HTTP requests are redirected to
+// HTTPS, except for ACME HTTP-01 challenges which are answered on the redirect listener.
+package certs
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// Config selects how the server obtains its certificate. A zero Config serves plain HTTP.
+type Config struct {
+	CertFile string
+	KeyFile  string
+
+	// Domains enables ACME issuance for these host names when no certificate file is set
+	Domains  []string
+	Email    string // ACME account contact, optional
+	CacheDir string // where issued certificates and the account key are kept
+}
+
+// Enabled reports whether the server should serve HTTPS
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.Domains) > 0
+}
+
+// Validate reports a Config that mixes or half-specifies the certificate sources
+func (c Config) Validate() error {
+	switch {
+	case (c.CertFile == "") != (c.KeyFile == ""):
+		return errors.New("--tls-cert and --tls-key must be set together")
+	case c.CertFile != "" && len(c.Domains) > 0:
+		return errors.New("--tls-cert/--tls-key and --autocert-domains are mutually exclusive")
+	case len(c.Domains) > 0 && c.CacheDir == "":
+		return errors.New("--autocert-cache is required with --autocert-domains")
+	}
+	for _, domain := range c.Domains {
+		if domain == "" || strings.ContainsAny(domain, "/:* ") {
+			return fmt.Errorf("invalid autocert domain %q", domain)
+		}
+	}
+	return nil
+}
+
+// TLSConfig builds the server TLS configuration. With ACME, the returned manager must
+// also answer HTTP-01 challenges through RedirectHandler.
+func (c Config) TLSConfig() (*tls.Config, *autocert.Manager, error) {
+	if err := c.Validate(); err != nil {
+		return nil, nil, err
+	}
+	config := DefaultTLSConfig()
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+		return config, nil, nil
+	}
+
+	// The manager's configuration carries its certificate lookup and the TLS-ALPN-01
+	// protocol; the version, curve and cipher suite limits are ours
+	manager := NewManager(c.CacheDir, c.Email, c.Domains...)
+	acmeConfig := manager.TLSConfig()
+	acmeConfig.MinVersion = config.MinVersion
+	acmeConfig.CurvePreferences = config.CurvePreferences
+	acmeConfig.CipherSuites = config.CipherSuites
+	return acmeConfig, manager, nil
+}
+
+// DefaultTLSConfig returns the modern defaults used for every TLS listener. TLS 1.3
+// suites are not configurable in Go and are always secure.
+func DefaultTLSConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		NextProtos: []string{"h2", "http/1.1"},
+	}
+}
+
+// RedirectHandler redirects plain HTTP requests to HTTPS on httpsPort ("443" keeps
+// the default port out of the URL). When manager is set, ACME HTTP-01 challenges are
+// answered by its HTTPHandler instead of redirected.
+func RedirectHandler(httpsPort string, manager *autocert.Manager) http.Handler {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if httpsPort != "" && httpsPort != "443" {
+			host = net.JoinHostPort(host, httpsPort)
+		}
+		target := "https://" + host + r.URL.RequestURI()
+
+		// Only idempotent requests may be repeated silently; 308 keeps the method and
+		// body of anything else instead of turning it into a GET
+		status := http.StatusMovedPermanently
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			status = http.StatusPermanentRedirect
+		}
+		http.Redirect(w, r, target, status)
+	})
+	if manager != nil {
+		return manager.HTTPHandler(redirect)
+	}
+	return redirect
+}
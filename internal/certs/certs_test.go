@@ -0,0 +1,113 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate writes a self-signed certificate for host valid until notAfter and
+// returns the PEM encoded key and certificate
+func writeCertificate(t *testing.T, host string, notAfter time.Time) (keyPEM, certPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		valid  bool
+	}{
+		{"plain HTTP", Config{}, true},
+		{"key pair", Config{CertFile: "cert.pem", KeyFile: "key.pem"}, true},
+		{"autocert", Config{Domains: []string{"api.example.com"}, CacheDir: "certs"}, true},
+		{"cert without key", Config{CertFile: "cert.pem"}, false},
+		{"both sources", Config{CertFile: "cert.pem", KeyFile: "key.pem", Domains: []string{"api.example.com"}, CacheDir: "certs"}, false},
+		{"autocert without cache", Config{Domains: []string{"api.example.com"}}, false},
+		{"wildcard domain", Config{Domains: []string{"*.example.com"}, CacheDir: "certs"}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.config.Validate(); (err == nil) != test.valid {
+				t.Errorf("Expected valid=%v, got %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestConfig_TLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	keyPEM, certPEM := writeCertificate(t, "localhost", time.Now().Add(24*time.Hour))
+	config := Config{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")}
+	_ = os.WriteFile(config.CertFile, certPEM, 0o600)
+	_ = os.WriteFile(config.KeyFile, keyPEM, 0o600)
+
+	tlsConfig, manager, err := config.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manager != nil || len(tlsConfig.Certificates) != 1 || tlsConfig.MinVersion != tls.VersionTLS12 {
+		t.Errorf("Unexpected TLS configuration %+v", tlsConfig)
+	}
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	client := server.Client()
+	client.Transport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true, MaxVersion: tls.VersionTLS12, CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA}}
+	if _, err := client.Get(server.URL); err == nil {
+		t.Error("Expected a CBC cipher suite to be refused")
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	tests := []struct {
+		method, url, port string
+		status            int
+		location          string
+	}{
+		{"GET", "http://api.example.com/forecasts?city_id=1", "443", http.StatusMovedPermanently, "https://api.example.com/forecasts?city_id=1"},
+		{"GET", "http://api.example.com:8080/cities", "8443", http.StatusMovedPermanently, "https://api.example.com:8443/cities"},
+		{"POST", "http://api.example.com/forecasts", "443", http.StatusPermanentRedirect, "https://api.example.com/forecasts"},
+	}
+
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		RedirectHandler(test.port, nil).ServeHTTP(w, httptest.NewRequest(test.method, test.url, nil))
+		if w.Code != test.status || w.Header().Get("Location") != test.location {
+			t.Errorf("%s %s: expected %d to %s, got %d to %s", test.method, test.url, test.status, test.location, w.Code, w.Header().Get("Location"))
+		}
+	}
+}
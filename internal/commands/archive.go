@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func runArchive(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	days := int(cmd.Int("older-than"))
+	if days <= 0 {
+		return fmt.Errorf("--older-than must be a positive number of days")
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+	logger.Info("Archiving forecasts", "valid_before", cutoff.Format(time.RFC3339))
+
+	result, err := repo.NewPostgreSQLForecastArchive(db).ArchiveBefore(ctx, cutoff)
+	if err != nil {
+		if result != nil && result.Days > 0 {
+			logger.Warn("Archive interrupted; re-run to continue", "days", result.Days, "rows", result.Rows)
+		}
+		return fmt.Errorf("archive failed: %w", err)
+	}
+
+	logger.Info("Archive completed successfully", "days", result.Days, "rows", result.Rows)
+	return nil
+}
@@ -0,0 +1,177 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+// backfillPageSize is how many stored forecasts are read per page when looking for
+// hours that were already backfilled
+const backfillPageSize = 1000
+
+// backfillOptions selects the city and UTC days to backfill
+type backfillOptions struct {
+	CityID    int
+	Start     time.Time
+	End       time.Time // inclusive
+	ChunkDays int       // days fetched and stored per request
+}
+
+// backfillResult counts the hours a backfill stored and skipped
+type backfillResult struct {
+	Stored  int
+	Skipped int // already stored from the same provider
+}
+
+func runBackfill(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	opts := backfillOptions{CityID: int(cmd.Int("city-id")), ChunkDays: int(cmd.Int("chunk-days"))}
+	var err error
+	if opts.Start, err = time.Parse(time.DateOnly, cmd.String("start")); err != nil {
+		return fmt.Errorf("--start must be a date (YYYY-MM-DD): %w", err)
+	}
+	if opts.End, err = time.Parse(time.DateOnly, cmd.String("end")); err != nil {
+		return fmt.Errorf("--end must be a date (YYYY-MM-DD): %w", err)
+	}
+	if opts.End.Before(opts.Start) {
+		return fmt.Errorf("--end cannot be before --start")
+	}
+	if opts.ChunkDays <= 0 {
+		return fmt.Errorf("--chunk-days must be positive")
+	}
+
+	config, err := secrets.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	engine, err := openEngine(config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	archive := providers.NewOpenMeteoArchiveProvider()
+	endpoint, err := providers.LoadEndpoint(providers.ArchiveEnvPrefix)
+	if err != nil {
+		return err
+	}
+	archive.Configure(endpoint)
+
+	logger.Info("Backfilling historical weather", "engine", engine.Name(), "city_id", opts.CityID,
+		"start", opts.Start.Format(time.DateOnly), "end", opts.End.Format(time.DateOnly))
+	result, err := backfill(ctx, engine, archive, opts, logger)
+	if err != nil {
+		return fmt.Errorf("backfill failed: %w", err)
+	}
+
+	logger.Info("Backfill completed successfully", "stored", result.Stored, "skipped", result.Skipped)
+	return nil
+}
+
+// backfill fetches a city's historical hours chunk by chunk and stores them with
+// CreateBatch. Hours already stored from the same provider are skipped, so an
+// interrupted backfill can be rerun over the same range.
+func backfill(ctx context.Context, engine repo.Engine, provider providers.HistoricalProvider, opts backfillOptions, logger *log.Logger) (*backfillResult, error) {
+	city, err := engine.Cities().GetByID(ctx, opts.CityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get city %d: %w", opts.CityID, err)
+	}
+
+	result := &backfillResult{}
+	for from := opts.Start; !from.After(opts.End); from = from.AddDate(0, 0, opts.ChunkDays) {
+		to := from.AddDate(0, 0, opts.ChunkDays-1)
+		if to.After(opts.End) {
+			to = opts.End
+		}
+
+		fetched, err := provider.GetHistoricalHourly(ctx, city.Latitude, city.Longitude, from, to)
+		if err != nil {
+			return result, err
+		}
+		stored, err := storedHours(ctx, engine.Forecasts(), city.ID, provider.GetName(), from, to.AddDate(0, 0, 1))
+		if err != nil {
+			return result, err
+		}
+
+		forecasts := make([]*repo.Forecast, 0, len(fetched))
+		for _, f := range fetched {
+			if stored[f.ValidTime.Unix()] {
+				result.Skipped++
+				continue
+			}
+			f.CityID = city.ID
+			if err := f.Validate(); err != nil {
+				return result, fmt.Errorf("invalid hour %s: %w", f.ValidTime.Format(time.RFC3339), err)
+			}
+			forecasts = append(forecasts, toRepoForecast(f))
+		}
+		if err := engine.Forecasts().CreateBatch(ctx, forecasts); err != nil {
+			return result, fmt.Errorf("failed to store %s to %s: %w", from.Format(time.DateOnly), to.Format(time.DateOnly), err)
+		}
+		result.Stored += len(forecasts)
+
+		logger.Info("Backfilled chunk", "start", from.Format(time.DateOnly), "end", to.Format(time.DateOnly), "stored", len(forecasts))
+	}
+	return result, nil
+}
+
+// storedHours returns the valid times, as Unix seconds, of a city's forecasts from
+// source in [from, until)
+func storedHours(ctx context.Context, forecasts repo.ForecastRepository, cityID int, source string, from, until time.Time) (map[int64]bool, error) {
+	hours := map[int64]bool{}
+	cursor := &repo.ForecastCursor{ValidTime: until.Format(time.RFC3339)}
+	for {
+		page, err := forecasts.GetByCityIDAfter(ctx, cityID, cursor, backfillPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list stored forecasts: %w", err)
+		}
+		for _, f := range page {
+			valid, err := time.Parse(time.RFC3339, f.ValidTime)
+			if err != nil {
+				continue
+			}
+			if valid.Before(from) {
+				return hours, nil
+			}
+			if f.SourceProvider == source {
+				hours[valid.Unix()] = true
+			}
+		}
+		if len(page) < backfillPageSize {
+			return hours, nil
+		}
+		last := page[len(page)-1]
+		cursor = &repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+	}
+}
+
+func toRepoForecast(f *models.Forecast) *repo.Forecast {
+	return &repo.Forecast{
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime.UTC().Format(time.RFC3339),
+		ValidTime:       f.ValidTime.UTC().Format(time.RFC3339),
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+	}
+}
@@ -0,0 +1,90 @@
+package commands
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// stubHistoricalProvider returns a row for every hour of the requested days
+type stubHistoricalProvider struct {
+	requests int
+}
+
+func (s *stubHistoricalProvider) GetName() string { return "Archive" }
+
+func (s *stubHistoricalProvider) GetHistoricalHourly(_ context.Context, _, _ float64, start, end time.Time) ([]*models.Forecast, error) {
+	s.requests++
+	var forecasts []*models.Forecast
+	for hour := start; hour.Before(end.AddDate(0, 0, 1)); hour = hour.Add(time.Hour) {
+		forecasts = append(forecasts, &models.Forecast{
+			SourceProvider: s.GetName(),
+			ForecastTime:   hour,
+			ValidTime:      hour,
+			Temperature:    15,
+			Humidity:       50,
+			Pressure:       1013,
+		})
+	}
+	return forecasts, nil
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard)
+
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	city := &repo.City{Name: "Oslo", Country: "Norway", CountryCode: "NO", Latitude: 59.91, Longitude: 10.75, IsActive: true}
+	if err := engine.Cities().Create(ctx, city); err != nil {
+		t.Fatalf("Create city failed: %v", err)
+	}
+
+	start := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC)
+	opts := backfillOptions{CityID: city.ID, Start: start, End: start.AddDate(0, 0, 4), ChunkDays: 2}
+	provider := &stubHistoricalProvider{}
+
+	result, err := backfill(ctx, engine, provider, opts, logger)
+	if err != nil {
+		t.Fatalf("backfill failed: %v", err)
+	}
+	if result.Stored != 5*24 || result.Skipped != 0 {
+		t.Errorf("Expected 120 stored hours, got %+v", result)
+	}
+	if provider.requests != 3 {
+		t.Errorf("Expected 3 chunked requests for 5 days, got %d", provider.requests)
+	}
+	if count, _ := engine.Forecasts().Count(ctx); count != 5*24 {
+		t.Errorf("Expected 120 stored forecasts, got %d", count)
+	}
+
+	t.Run("Rerun skips stored hours", func(t *testing.T) {
+		opts := opts
+		opts.End = start.AddDate(0, 0, 5)
+		result, err := backfill(ctx, engine, provider, opts, logger)
+		if err != nil {
+			t.Fatalf("backfill failed: %v", err)
+		}
+		if result.Stored != 24 || result.Skipped != 5*24 {
+			t.Errorf("Expected only the new day to be stored, got %+v", result)
+		}
+	})
+
+	t.Run("Unknown city", func(t *testing.T) {
+		opts := opts
+		opts.CityID = city.ID + 100
+		if _, err := backfill(ctx, engine, provider, opts, logger); err == nil {
+			t.Error("Expected an error for an unknown city")
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/backup"
+)
+
+func runBackup(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	output := cmd.String("output")
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	opts := backup.Options{
+		Tables:       splitList(cmd.String("tables")),
+		ForecastDays: int(cmd.Int("forecast-days")),
+	}
+
+	// Write to a temp file first so a failed backup never clobbers a previous one. It
+	// sits next to the output so the rename stays on one filesystem.
+	tmp, err := os.CreateTemp(filepath.Dir(output), "."+filepath.Base(output)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	logger.Info("Creating backup", "output", output, "forecast_days", opts.ForecastDays)
+
+	manifest, err := backup.Dump(ctx, db, tmp, opts)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("backup failed: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return fmt.Errorf("failed to write backup file: %w", err)
+	}
+	if err := os.Chmod(output, 0600); err != nil {
+		logger.Warn("Could not restrict backup file permissions", "error", err)
+	}
+
+	for _, table := range manifest.Tables {
+		logger.Info("Backed up table", "table", table, "rows", manifest.Rows[table])
+	}
+	logger.Info("Backup completed successfully", "output", output)
+	return nil
+}
+
+func runRestore(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	input := cmd.String("input")
+
+	file, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open backup file: %w", err)
+	}
+	defer file.Close()
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	opts := backup.Options{
+		Tables:   splitList(cmd.String("tables")),
+		Truncate: cmd.Bool("truncate"),
+	}
+
+	logger.Info("Restoring backup", "input", input, "truncate", opts.Truncate)
+
+	manifest, err := backup.Restore(ctx, db, file, opts)
+	if err != nil {
+		return fmt.Errorf("restore failed: %w", err)
+	}
+
+	for _, table := range manifest.Tables {
+		logger.Info("Restored table", "table", table, "rows", manifest.Rows[table])
+	}
+	logger.Info("Restore completed successfully", "backup_created_at", manifest.CreatedAt)
+	return nil
+}
+
+func splitList(value string) []string {
+	var tables []string
+	for _, table := range strings.Split(value, ",") {
+		if table = strings.TrimSpace(table); table != "" {
+			tables = append(tables, table)
+		}
+	}
+	return tables
+}
@@ -2,9 +2,15 @@ package commands
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/idempotency"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
 )
 
 // StartCommand creates the server start command
@@ -12,30 +18,238 @@ func StartCommand(logger *log.Logger) *cli.Command {
 	return &cli.Command{
 		Name:  "start",
 		Usage: "Start the weather API server",
-		Flags: []cli.Flag{
-			&cli.StringFlag{
-				Name:  "port",
-				Value: "8080",
-				Usage: "Server port",
+		Flags: append(serverFlags(),
+			&cli.BoolFlag{
+				Name:    "auto-migrate",
+				Usage:   "Apply pending database migrations before serving, one replica at a time",
+				Sources: cli.EnvVars("WEATHER_API_AUTO_MIGRATE"),
 			},
-			&cli.StringFlag{
-				Name:  "host",
-				Value: "localhost",
-				Usage: "Server host",
+			&cli.DurationFlag{
+				Name:  "migrate-lock-timeout",
+				Value: 5 * time.Minute,
+				Usage: "Time --auto-migrate waits for another replica's migrations to finish",
 			},
-		},
+		),
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return startServer(ctx, cmd, logger)
 		},
 	}
 }
 
-// MigrateCommand creates the database migration command
+// DemoCommand creates the self-contained demo command
+func DemoCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "demo",
+		Usage: "Run the full API on seeded in-memory storage with synthetic providers and the docs UI, without any configuration",
+		Flags: append(serverFlags(),
+			&cli.IntFlag{
+				Name:  "seed",
+				Value: 1,
+				Usage: "Random seed for the synthetic forecasts seeded at startup",
+			},
+		),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runDemo(ctx, cmd, logger)
+		},
+	}
+}
+
+// serverFlags are the flags of the commands that run the API server
+func serverFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "port",
+			Value: "8080",
+			Usage: "Server port",
+		},
+		&cli.StringFlag{
+			Name:  "host",
+			Value: "localhost",
+			Usage: "Server host",
+		},
+		&cli.DurationFlag{
+			Name:  "ingest-interval",
+			Value: 6 * time.Hour,
+			Usage: "Time between scheduled forecast ingestion runs (0 = on demand only)",
+		},
+		&cli.IntFlag{
+			Name:  "forecast-days",
+			Value: 7,
+			Usage: "Number of forecast days fetched per city by ingestion",
+		},
+		&cli.IntFlag{
+			Name:  "retention-days",
+			Value: 30,
+			Usage: "Delete forecasts, aviation reports, station observations and air quality readings older than this many days",
+		},
+		&cli.DurationFlag{
+			Name:  "idempotency-ttl",
+			Value: idempotency.DefaultTTL,
+			Usage: "Time responses to requests with an Idempotency-Key are kept for replay",
+		},
+		&cli.StringFlag{
+			Name:  "export-dir",
+			Value: "exports",
+			Usage: "Directory where forecast export chunks and manifests are kept",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-private-webhooks",
+			Usage: "Allow http webhook URLs and ones reaching loopback or private addresses, for local development",
+		},
+		&cli.DurationFlag{
+			Name:  "shutdown-timeout",
+			Value: 30 * time.Second,
+			Usage: "Time allowed for in-flight requests to finish on SIGINT/SIGTERM",
+		},
+		&cli.StringFlag{
+			Name:  "tls-cert",
+			Usage: "PEM certificate chain to serve HTTPS with (requires --tls-key)",
+		},
+		&cli.StringFlag{
+			Name:  "tls-key",
+			Usage: "PEM private key for --tls-cert",
+		},
+		&cli.StringSliceFlag{
+			Name:  "autocert-domains",
+			Usage: "Serve HTTPS with Let's Encrypt certificates issued for these host names",
+		},
+		&cli.StringFlag{
+			Name:  "autocert-email",
+			Usage: "Contact address for the Let's Encrypt account",
+		},
+		&cli.StringFlag{
+			Name:  "autocert-cache",
+			Value: "autocert",
+			Usage: "Directory where issued certificates and the account key are kept",
+		},
+		&cli.StringFlag{
+			Name:  "redirect-port",
+			Value: "80",
+			Usage: "Port redirecting HTTP to HTTPS when TLS is enabled (empty disables; autocert may need 80)",
+		},
+		&cli.StringFlag{
+			Name:  "grpc-port",
+			Usage: "Port serving the gRPC API, over TLS when HTTPS is enabled (empty disables; requires a database)",
+		},
+		&cli.BoolFlag{
+			Name:  "compression",
+			Value: true,
+			Usage: "Compress JSON, CSV and GeoJSON responses for clients that accept it",
+		},
+		&cli.StringSliceFlag{
+			Name:  "compression-encodings",
+			Value: []string{"gzip", "deflate"},
+			Usage: "Encodings offered, most preferred first (zstd, gzip, deflate)",
+		},
+		&cli.IntFlag{
+			Name:  "compression-level",
+			Usage: "gzip/deflate compression level from 1 (fastest) to 9 (smallest); 0 uses the default",
+		},
+		&cli.IntFlag{
+			Name:  "compression-min-size",
+			Value: 1024,
+			Usage: "Smallest response body compressed, in bytes",
+		},
+		&cli.IntFlag{
+			Name:  "coordinate-precision",
+			Value: 4,
+			Usage: "Decimal places kept of request coordinates before they reach providers, caches, storage or logs (2 = about 1 km, 4 = about 11 m; -1 keeps them as given)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "stale-routes",
+			Value: []string{"/v1/forecasts/hourly=6h", "/v1/air-quality=3h", "/v1/stations/=6h", "/v1/cities/=24h"},
+			Usage: "Routes answering with their last good response, marked stale, when providers or the database fail (path[=max age]; a trailing / matches a prefix)",
+		},
+		&cli.StringFlag{
+			Name:  "ttl-policy",
+			Usage: "JSON file overriding cache lifetimes for current conditions, forecasts and geocodes",
+		},
+		&cli.StringSliceFlag{
+			Name:  "page-sizes",
+			Usage: "Default and maximum page sizes per resource (resource=default:max; forecasts, cities, places, alerts, job_runs)",
+		},
+		&cli.Int64Flag{
+			Name:  "count-estimate-above",
+			Usage: "Answer listing totals with PostgreSQL's row estimate once it is above this many rows (0 always counts exactly)",
+		},
+		&cli.DurationFlag{
+			Name:  "count-cache-ttl",
+			Usage: "Time exact listing totals are cached before counting again (0 counts on every request)",
+		},
+		&cli.IntFlag{
+			Name:  "breaker-failures",
+			Value: 5,
+			Usage: "Consecutive failed requests to a provider that open its circuit breaker",
+		},
+		&cli.FloatFlag{
+			Name:  "breaker-timeout-ratio",
+			Value: 0.5,
+			Usage: "Share of a provider's last --breaker-window requests timing out that opens its circuit breaker",
+		},
+		&cli.IntFlag{
+			Name:  "breaker-window",
+			Value: 20,
+			Usage: "Recent requests per provider over which --breaker-timeout-ratio is measured",
+		},
+		&cli.DurationFlag{
+			Name:  "breaker-cooldown",
+			Value: 30 * time.Second,
+			Usage: "Time an open circuit breaker fails requests immediately before letting a probe through",
+		},
+		&cli.DurationFlag{
+			Name:  "provider-check-interval",
+			Value: time.Minute,
+			Usage: "Time between background health checks of each provider",
+		},
+		&cli.IntFlag{
+			Name:  "retry-attempts",
+			Value: 3,
+			Usage: "Attempts per provider request, the first included, when it fails with a network error, 429 or 5xx (1 disables retries)",
+		},
+		&cli.DurationFlag{
+			Name:  "retry-base-delay",
+			Value: 250 * time.Millisecond,
+			Usage: "Backoff ceiling before the first retry of a provider request, doubling with each retry; waits are jittered below it",
+		},
+		&cli.DurationFlag{
+			Name:  "retry-max-delay",
+			Value: 5 * time.Second,
+			Usage: "Largest backoff ceiling between retries of a provider request",
+		},
+		&cli.DurationFlag{
+			Name:  "retry-budget",
+			Value: 10 * time.Second,
+			Usage: "Most time one provider request may spend waiting between retries, Retry-After hints included",
+		},
+		&cli.StringSliceFlag{
+			Name:  "retry-budgets",
+			Usage: "Retry budgets per provider overriding --retry-budget (name=duration, e.g. Census=0s)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "rate-limits",
+			Value: []string{"Nominatim=1"},
+			Usage: "Outbound requests per second per provider, shared by all its clients (name=rate[:burst]; 0 lifts a limit, unlisted providers are not paced)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "blend-weights",
+			Usage: "Provider weights in blended forecasts (name=weight; unlisted providers weigh 1 and 0 leaves a provider out)",
+		},
+	}
+}
+
+// MigrateCommand creates the database migration command. Migrations are embedded in
+// the binary; --path reads them from a directory instead.
 func MigrateCommand(logger *log.Logger) *cli.Command {
+	pathFlag := &cli.StringFlag{
+		Name:  "path",
+		Usage: "Read migrations from this directory instead of the ones built into the binary",
+	}
+
 	return &cli.Command{
 		Name:  "migrate",
 		Usage: "Run database migrations",
 		Flags: []cli.Flag{
+			pathFlag,
 			&cli.StringFlag{
 				Name:  "direction",
 				Value: "up",
@@ -50,6 +264,295 @@ func MigrateCommand(logger *log.Logger) *cli.Command {
 		Action: func(ctx context.Context, cmd *cli.Command) error {
 			return runMigrations(ctx, cmd, logger)
 		},
+		Commands: []*cli.Command{
+			{
+				Name:  "status",
+				Usage: "List applied and pending migrations and the current version",
+				Flags: []cli.Flag{pathFlag},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return migrationStatus(ctx, cmd, logger)
+				},
+			},
+			{
+				Name:      "create",
+				Usage:     "Create empty up and down files for a new migration",
+				ArgsUsage: "<name>",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "dir",
+						Value: "migrations",
+						Usage: "Directory to write the migration files to",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return runCreateMigration(ctx, cmd, logger)
+				},
+			},
+			{
+				Name:      "force",
+				Usage:     "Record a version as applied and clear the dirty flag after fixing a failed migration by hand (-1 for none)",
+				ArgsUsage: "<version>",
+				Flags:     []cli.Flag{pathFlag},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return forceMigration(ctx, cmd, logger)
+				},
+			},
+		},
+	}
+}
+
+// BackupCommand creates the database backup command
+func BackupCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "backup",
+		Usage: "Back up cities, places, users and recent forecasts to a compressed archive",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "output",
+				Value: "backup.tar.zst",
+				Usage: "Backup archive to write",
+			},
+			&cli.StringFlag{
+				Name:  "tables",
+				Usage: "Comma-separated tables to include (default: all)",
+			},
+			&cli.IntFlag{
+				Name:  "forecast-days",
+				Value: 30,
+				Usage: "Only include forecasts valid within the last N days (0 = all)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runBackup(ctx, cmd, logger)
+		},
+	}
+}
+
+// RestoreCommand creates the database restore command
+func RestoreCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Restore a backup archive created by the backup command",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "input",
+				Value: "backup.tar.zst",
+				Usage: "Backup archive to read",
+			},
+			&cli.StringFlag{
+				Name:  "tables",
+				Usage: "Comma-separated tables to restore (default: all in the archive)",
+			},
+			&cli.BoolFlag{
+				Name:  "truncate",
+				Usage: "Empty restored tables before loading (otherwise existing rows are kept)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runRestore(ctx, cmd, logger)
+		},
+	}
+}
+
+// ArchiveCommand creates the forecast archival command
+func ArchiveCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "archive",
+		Usage: "Compress forecasts older than N days into per city-day archive blobs",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:  "older-than",
+				Value: 30,
+				Usage: "Archive forecasts valid more than N days ago",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runArchive(ctx, cmd, logger)
+		},
+	}
+}
+
+// FsckCommand creates the data integrity check command
+func FsckCommand(logger *log.Logger) *cli.Command {
+	usage := "Checks to run: all"
+	fixUsage := "Checks whose issues to repair (all for every check):"
+	for _, check := range repo.IntegrityChecks {
+		usage += ", " + check.Name
+		fixUsage += "\n  " + check.Name + ": " + check.Fix
+	}
+
+	return &cli.Command{
+		Name:  "fsck",
+		Usage: "Check stored data for orphaned, invalid and duplicate rows and optionally repair them",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "check",
+				Usage: usage,
+			},
+			&cli.StringSliceFlag{
+				Name:  "fix",
+				Usage: fixUsage,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Value: "text",
+				Usage: "Report format (text/json)",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Value: 20,
+				Usage: "Issues listed per check (0 = all)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runFsck(ctx, cmd, logger)
+		},
+	}
+}
+
+// PromoteCommand creates the user role command
+func PromoteCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "promote",
+		Usage: "Promote a user to admin, or set another role with --role",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "user",
+				Usage:    "Username or ID of the user",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "role",
+				Value: models.RoleAdmin,
+				Usage: "Role to grant (" + strings.Join(models.Roles, "/") + ")",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runPromote(ctx, cmd, logger)
+		},
+	}
+}
+
+// SeedCommand creates the fixture seeding command
+func SeedCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "seed",
+		Usage: "Load fixture cities and places with synthetic forecasts for development and demos",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "reset",
+				Usage: "Delete all cities, places, forecasts, alerts and aviation reports first",
+			},
+			&cli.IntFlag{
+				Name:  "forecast-days",
+				Value: 3,
+				Usage: "Days of hourly synthetic forecasts per seeded city (0 = none)",
+			},
+			&cli.IntFlag{
+				Name:  "seed",
+				Value: 1,
+				Usage: "Random seed for the synthetic forecasts",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runSeed(ctx, cmd, logger)
+		},
+	}
+}
+
+// BackfillCommand creates the historical weather backfill command
+func BackfillCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "backfill",
+		Usage: "Store a city's historical hourly weather from the Open-Meteo archive as forecasts",
+		Flags: []cli.Flag{
+			&cli.IntFlag{
+				Name:     "city-id",
+				Usage:    "ID of the city to backfill",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "start",
+				Usage:    "First UTC day to backfill (YYYY-MM-DD)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "end",
+				Usage:    "Last UTC day to backfill (YYYY-MM-DD); the archive lags about five days",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "chunk-days",
+				Value: 31,
+				Usage: "Days fetched and stored per archive request",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runBackfill(ctx, cmd, logger)
+		},
+	}
+}
+
+// SnapshotCommand creates the reference data snapshot commands used to sync
+// cities and places between separate deployments
+func SnapshotCommand(logger *log.Logger) *cli.Command {
+	nodeFlag := &cli.StringFlag{
+		Name:     "node-id",
+		Usage:    "Unique ID of this deployment",
+		Sources:  cli.EnvVars("WEATHER_API_NODE_ID"),
+		Required: true,
+	}
+
+	return &cli.Command{
+		Name:  "snapshot",
+		Usage: "Export and import signed reference data snapshots",
+		Commands: []*cli.Command{
+			{
+				Name:  "export",
+				Usage: "Export cities and places as a signed snapshot bundle",
+				Flags: []cli.Flag{
+					nodeFlag,
+					&cli.StringFlag{
+						Name:  "output",
+						Value: "snapshot.json",
+						Usage: "Snapshot bundle to write",
+					},
+					&cli.StringFlag{
+						Name:  "signing-key",
+						Usage: "Hex-encoded Ed25519 signing key (optional, defaults to WEATHER_API_SNAPSHOT_KEY)",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return exportSnapshot(ctx, cmd, logger)
+				},
+			},
+			{
+				Name:  "import",
+				Usage: "Verify and merge a snapshot bundle from another deployment",
+				Flags: []cli.Flag{
+					nodeFlag,
+					&cli.StringFlag{
+						Name:  "input",
+						Value: "snapshot.json",
+						Usage: "Snapshot bundle to read",
+					},
+					&cli.StringFlag{
+						Name:  "trusted-keys",
+						Usage: "Comma-separated hex public keys to accept (optional, defaults to WEATHER_API_SNAPSHOT_TRUSTED_KEYS)",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return importSnapshot(ctx, cmd, logger)
+				},
+			},
+			{
+				Name:  "keygen",
+				Usage: "Generate an Ed25519 key pair for signing snapshots",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return generateSnapshotKey(ctx, cmd, logger)
+				},
+			},
+		},
 	}
 }
 
@@ -57,16 +560,16 @@ func MigrateCommand(logger *log.Logger) *cli.Command {
 func EncryptCommand(logger *log.Logger) *cli.Command {
 	return &cli.Command{
 		Name:  "encrypt",
-		Usage: "Encrypt env.local file values",
+		Usage: "Encrypt .env.local file values",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "file",
-				Value: "env.local",
+				Value: ".env.local",
 				Usage: "Environment file to encrypt",
 			},
 			&cli.StringFlag{
 				Name:  "key",
-				Usage: "Encryption key (optional, will prompt if not provided)",
+				Usage: "Encryption key (optional, falls back to WEATHER_API_ENCRYPTION_KEY, then a prompt)",
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
@@ -79,16 +582,16 @@ func EncryptCommand(logger *log.Logger) *cli.Command {
 func DecryptCommand(logger *log.Logger) *cli.Command {
 	return &cli.Command{
 		Name:  "decrypt",
-		Usage: "Decrypt env.local file values",
+		Usage: "Decrypt .env.local file values",
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:  "file",
-				Value: "env.local",
+				Value: ".env.local",
 				Usage: "Environment file to decrypt",
 			},
 			&cli.StringFlag{
 				Name:  "key",
-				Usage: "Decryption key (optional, will prompt if not provided)",
+				Usage: "Decryption key (optional, falls back to WEATHER_API_ENCRYPTION_KEY, then a prompt)",
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
@@ -97,6 +600,32 @@ func DecryptCommand(logger *log.Logger) *cli.Command {
 	}
 }
 
+// RotateKeyCommand creates the encryption key rotation command
+func RotateKeyCommand(logger *log.Logger) *cli.Command {
+	return &cli.Command{
+		Name:  "rotate-key",
+		Usage: "Re-encrypt .env.local file values with a new key",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "file",
+				Value: ".env.local",
+				Usage: "Encrypted environment file to rotate",
+			},
+			&cli.StringFlag{
+				Name:  "old-key",
+				Usage: "Current encryption key (optional, will prompt if not provided)",
+			},
+			&cli.StringFlag{
+				Name:  "new-key",
+				Usage: "Replacement encryption key (optional, will prompt if not provided)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return rotateKey(ctx, cmd, logger)
+		},
+	}
+}
+
 // HTTPCommand creates the HTTP request command
 func HTTPCommand(logger *log.Logger) *cli.Command {
 	return &cli.Command{
@@ -0,0 +1,73 @@
+package commands
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/admin"
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/demo"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+	"stormlightlabs.org/weather_api/internal/seed"
+)
+
+// runDemo serves the full API on in-memory storage seeded with the fixtures, with demo
+// providers in place of the upstream services and the API docs under /docs. Nothing is
+// read from the environment and nothing outlives the process. The storage is the
+// embedded file engine; there is no SQLite engine.
+func runDemo(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	adminToken := rand.Text()
+	config := &secrets.Config{
+		AdminToken:    adminToken,
+		ShareSecret:   rand.Text(),
+		StorageEngine: repo.EngineFile,
+	}
+
+	base := fmt.Sprintf("http://%s:%s", cmd.String("host"), cmd.String("port"))
+	logger.Info("Starting demo: in-memory storage, synthetic weather, no upstream calls",
+		"api", base+"/v1", "docs", base+"/docs/", "admin", base+admin.Prefix+"/", "admin_token", adminToken)
+
+	return runServer(ctx, cmd, logger, serverSetup{
+		config: config,
+		providers: func(*secrets.Config, *log.Logger) (*providers.ProviderManager, error) {
+			return demo.NewProviders()
+		},
+		prepare: func(ctx context.Context, engine repo.Engine) error {
+			result, err := seed.Run(ctx, engine, seed.Options{
+				ForecastHours: int(cmd.Int("forecast-days")) * 24,
+				Seed:          uint64(cmd.Int("seed")),
+			})
+			if err != nil {
+				return fmt.Errorf("failed to seed demo data: %w", err)
+			}
+			logger.Info("Demo data seeded", "cities", result.Cities, "places", result.Places, "forecasts", result.Forecasts)
+			return nil
+		},
+		mount: mountDocs,
+	})
+}
+
+// mountDocs serves the Swagger UI under /docs/ for a spec built from the documented
+// examples, and redirects / to it
+func mountDocs(mux *http.ServeMux) {
+	spec := map[string]any{
+		"swagger":  "2.0",
+		"info":     map[string]any{"title": "Weather API", "version": "v1"},
+		"basePath": "/v1",
+		"paths":    map[string]any{},
+	}
+	mergeExamples(spec, controllers.Examples())
+
+	mux.HandleFunc("GET /docs/swagger.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = controllers.WriteJSON(w, http.StatusOK, spec)
+	})
+	mux.HandleFunc("GET /docs/{$}", swaggerUI("/docs/swagger.json"))
+	mux.Handle("GET /{$}", http.RedirectHandler("/docs/", http.StatusFound))
+}
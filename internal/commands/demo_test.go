@@ -0,0 +1,40 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMountDocs(t *testing.T) {
+	mux := http.NewServeMux()
+	mountDocs(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/docs/swagger.json", nil))
+	var spec struct {
+		Swagger  string                    `json:"swagger"`
+		BasePath string                    `json:"basePath"`
+		Paths    map[string]map[string]any `json:"paths"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&spec); err != nil {
+		t.Fatalf("Invalid spec: %v", err)
+	}
+	if spec.Swagger != "2.0" || spec.BasePath != "/v1" || spec.Paths["/forecasts"]["get"] == nil {
+		t.Errorf("Expected a spec documenting the examples, got %+v", spec)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/docs/", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "url: '/docs/swagger.json'") {
+		t.Errorf("Expected the Swagger UI for the spec, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/docs/" {
+		t.Errorf("Expected / to redirect to the docs, got %d %s", w.Code, w.Header().Get("Location"))
+	}
+}
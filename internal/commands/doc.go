@@ -2,14 +2,19 @@ package commands
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
 )
 
 func generateDocs(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
@@ -35,11 +40,16 @@ func generateDocs(ctx context.Context, cmd *cli.Command, logger *log.Logger) err
 	cmdE.Stdout = os.Stdout
 	cmdE.Stderr = os.Stderr
 
-	args := cmd.Args().Slice()
-	if err := cmd.Run(ctx, args); err != nil {
+	cmdE.Args = append(cmdE.Args, cmd.Args().Slice()...)
+	if err := cmdE.Run(); err != nil {
 		return fmt.Errorf("failed to generate docs: %w", err)
 	}
 
+	swaggerFile := filepath.Join(outputDir, "swagger.json")
+	if err := addExamples(swaggerFile, controllers.Examples()); err != nil {
+		return fmt.Errorf("failed to add examples: %w", err)
+	}
+
 	logger.Info("Documentation generated successfully", "location", outputDir)
 
 	if serve {
@@ -49,6 +59,71 @@ func generateDocs(ctx context.Context, cmd *cli.Command, logger *log.Logger) err
 	return nil
 }
 
+// addExamples merges request and response examples into a Swagger 2.0 spec file
+func addExamples(swaggerFile string, examples []controllers.Example) error {
+	data, err := os.ReadFile(swaggerFile)
+	if err != nil {
+		return err
+	}
+	var spec map[string]any
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("invalid %s: %w", swaggerFile, err)
+	}
+
+	mergeExamples(spec, examples)
+	data, err = json.MarshalIndent(spec, "", "    ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(swaggerFile, data, 0644)
+}
+
+// mergeExamples adds request and response examples to a Swagger 2.0 spec. Operations
+// swag did not document are added with the example's summary, so every example shows up
+// in the Swagger UI.
+func mergeExamples(spec map[string]any, examples []controllers.Example) {
+	paths := childObject(spec, "paths")
+	for _, example := range examples {
+		operation := childObject(childObject(paths, example.Path), strings.ToLower(example.Method))
+		if _, ok := operation["summary"]; !ok {
+			operation["summary"] = example.Summary
+		}
+
+		response := childObject(childObject(operation, "responses"), strconv.Itoa(example.Status))
+		if _, ok := response["description"]; !ok {
+			response["description"] = http.StatusText(example.Status)
+		}
+		childObject(response, "examples")["application/json"] = example.Response
+
+		if example.Request != nil {
+			childObject(requestBody(operation), "schema")["example"] = example.Request
+		}
+	}
+}
+
+// childObject returns the JSON object stored under key, creating it when missing
+func childObject(parent map[string]any, key string) map[string]any {
+	if child, ok := parent[key].(map[string]any); ok {
+		return child
+	}
+	child := make(map[string]any)
+	parent[key] = child
+	return child
+}
+
+// requestBody returns the body parameter of an operation, adding one when missing
+func requestBody(operation map[string]any) map[string]any {
+	params, _ := operation["parameters"].([]any)
+	for _, p := range params {
+		if param, ok := p.(map[string]any); ok && param["in"] == "body" {
+			return param
+		}
+	}
+	param := map[string]any{"in": "body", "name": "body", "required": true}
+	operation["parameters"] = append(params, param)
+	return param
+}
+
 func serveDocs(docsDir, port string, logger *log.Logger) error {
 	swaggerFile := filepath.Join(docsDir, "swagger.json")
 	if _, err := os.Stat(swaggerFile); os.IsNotExist(err) {
@@ -61,10 +136,15 @@ func serveDocs(docsDir, port string, logger *log.Logger) error {
 	fs := http.FileServer(http.Dir(docsDir))
 	http.Handle("/", fs)
 
-	// Custom handler for swagger UI
-	http.HandleFunc("/swagger/", func(w http.ResponseWriter, r *http.Request) {
-		// Simple swagger UI HTML
-		html := `<!DOCTYPE html>
+	http.HandleFunc("/swagger/", swaggerUI("/swagger.json"))
+
+	logger.Info("Documentation server started", "url", fmt.Sprintf("http://localhost:%s/swagger/", port))
+	return http.ListenAndServe(":"+port, nil)
+}
+
+// swaggerUI serves a Swagger UI page rendering the spec at specURL
+func swaggerUI(specURL string) http.HandlerFunc {
+	page := fmt.Sprintf(`<!DOCTYPE html>
 <html>
 <head>
     <title>Weather API Documentation</title>
@@ -75,7 +155,7 @@ func serveDocs(docsDir, port string, logger *log.Logger) error {
     <script src="https://unpkg.com/swagger-ui-dist@4.15.5/swagger-ui-bundle.js"></script>
     <script>
         SwaggerUIBundle({
-            url: '/swagger.json',
+            url: '%s',
             dom_id: '#swagger-ui',
             presets: [
                 SwaggerUIBundle.presets.apis,
@@ -84,11 +164,9 @@ func serveDocs(docsDir, port string, logger *log.Logger) error {
         });
     </script>
 </body>
-</html>`
+</html>`, specURL)
+	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/html")
-		w.Write([]byte(html))
-	})
-
-	logger.Info("Documentation server started", "url", fmt.Sprintf("http://localhost:%s/swagger/", port))
-	return http.ListenAndServe(":"+port, nil)
+		w.Write([]byte(page))
+	}
 }
@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+)
+
+func TestAddExamples(t *testing.T) {
+	swaggerFile := filepath.Join(t.TempDir(), "swagger.json")
+	spec := `{"swagger": "2.0", "paths": {"/forecasts": {"post": {
+		"summary": "Documented by swag",
+		"parameters": [{"in": "body", "name": "forecast", "schema": {"$ref": "#/definitions/Forecast"}}],
+		"responses": {"201": {"description": "Created forecast"}}
+	}}}}`
+	if err := os.WriteFile(swaggerFile, []byte(spec), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := addExamples(swaggerFile, controllers.Examples()); err != nil {
+		t.Fatalf("addExamples failed: %v", err)
+	}
+
+	data, err := os.ReadFile(swaggerFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Paths map[string]map[string]struct {
+			Summary    string `json:"summary"`
+			Parameters []struct {
+				In     string         `json:"in"`
+				Name   string         `json:"name"`
+				Schema map[string]any `json:"schema"`
+			} `json:"parameters"`
+			Responses map[string]struct {
+				Description string         `json:"description"`
+				Examples    map[string]any `json:"examples"`
+			} `json:"responses"`
+		} `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid spec written: %v", err)
+	}
+
+	create := got.Paths["/forecasts"]["post"]
+	if create.Summary != "Documented by swag" || create.Responses["201"].Description != "Created forecast" {
+		t.Errorf("expected swag annotations to be kept, got %+v", create)
+	}
+	if len(create.Parameters) != 1 || create.Parameters[0].Name != "forecast" || create.Parameters[0].Schema["example"] == nil {
+		t.Errorf("expected the request example on the existing body parameter, got %+v", create.Parameters)
+	}
+	for _, status := range []string{"201", "422"} {
+		if create.Responses[status].Examples["application/json"] == nil {
+			t.Errorf("expected a %s response example", status)
+		}
+	}
+
+	get := got.Paths["/forecasts/{id}"]["get"]
+	if get.Summary != "Get a forecast" || get.Responses["404"].Description != "Not Found" {
+		t.Errorf("expected the undocumented operation to be added, got %+v", get)
+	}
+	if len(get.Parameters) != 0 {
+		t.Errorf("expected no body parameter on GET, got %+v", get.Parameters)
+	}
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"stormlightlabs.org/weather_api/internal/database"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+// openEngine opens the storage engine selected by the configuration
+func openEngine(config *secrets.Config) (repo.Engine, error) {
+	name, err := repo.ParseEngineName(config.StorageEngine)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case repo.EngineFile:
+		engine, err := repo.OpenFileEngine(config.StoragePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open file storage: %w", err)
+		}
+		return engine, nil
+	default:
+		db, err := openReplicatedPool(config.DatabaseURL)
+		if err != nil {
+			return nil, err
+		}
+		return repo.NewPostgreSQLEngine(repo.NewTracedDB(db)), nil
+	}
+}
+
+// openReplicatedPool opens the pool of url (see openPool) and one per read replica of
+// DATABASE_REPLICA_URLS, routing the repositories' reads to the replicas. Pools connect
+// on first use, so a replica that is down does not stop startup.
+func openReplicatedPool(url string) (repo.DB, error) {
+	dbConfig, err := databaseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	primary, err := database.Open(context.Background(), dbConfig)
+	if err != nil {
+		return nil, err
+	}
+	if len(dbConfig.ReplicaURLs) == 0 {
+		return primary, nil
+	}
+
+	replicas := make([]repo.DB, 0, len(dbConfig.ReplicaURLs))
+	for i, replicaConfig := range dbConfig.Replicas() {
+		replica, err := database.Open(context.Background(), replicaConfig)
+		if err != nil {
+			repo.NewReplicatedDB(primary, replicas, 0).Close()
+			return nil, fmt.Errorf("read replica %d: %w", i+1, err)
+		}
+		replicas = append(replicas, replica)
+	}
+	return repo.NewReplicatedDB(primary, replicas, dbConfig.ReplicaCooldown), nil
+}
+
+// openDatabase opens the connection pool of DATABASE_URL for commands that work on
+// PostgreSQL directly
+func openDatabase() (*database.Pool, error) {
+	return openPool("")
+}
+
+// openPool opens the PostgreSQL connection pool configured by database.LoadConfig,
+// connecting to url when it is set
+func openPool(url string) (*database.Pool, error) {
+	dbConfig, err := databaseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	return database.Open(context.Background(), dbConfig)
+}
+
+// databaseConfig loads the pool configuration, connecting to url when it is set
+func databaseConfig(url string) (database.Config, error) {
+	dbConfig, err := database.LoadConfig()
+	if err != nil {
+		return dbConfig, err
+	}
+	if url != "" {
+		dbConfig.URL = url
+	}
+	return dbConfig, nil
+}
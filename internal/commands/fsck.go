@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+// fsckOptions selects the checks fsck runs and repairs
+type fsckOptions struct {
+	Checks []string
+	Fix    []string
+	Limit  int
+}
+
+// fsckResult is the outcome of one check
+type fsckResult struct {
+	Check       string                `json:"check"`
+	Description string                `json:"description"`
+	Found       int                   `json:"found"`
+	Fixed       int64                 `json:"fixed"`
+	Issues      []repo.IntegrityIssue `json:"issues"`
+}
+
+// fsckReport is the machine-readable report written by --format json
+type fsckReport struct {
+	Engine string       `json:"engine"`
+	Checks []fsckResult `json:"checks"`
+	// Remaining counts issues found by checks that were not fixed
+	Remaining int `json:"remaining"`
+}
+
+func runFsck(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	format := cmd.String("format")
+	if format != "text" && format != "json" {
+		return fmt.Errorf("--format must be text or json")
+	}
+	opts := fsckOptions{
+		Checks: cmd.StringSlice("check"),
+		Fix:    cmd.StringSlice("fix"),
+		Limit:  int(cmd.Int("limit")),
+	}
+
+	config, err := secrets.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	engine, err := openEngine(config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	checker, err := repo.NewIntegrityChecker(engine)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Fix) > 0 {
+		logger.Warn("Repairing storage", "engine", engine.Name(), "fix", opts.Fix)
+	}
+	report, err := fsck(ctx, checker, opts)
+	if err != nil {
+		return fmt.Errorf("fsck failed: %w", err)
+	}
+	report.Engine = engine.Name()
+
+	if err := writeFsckReport(os.Stdout, report, format); err != nil {
+		return err
+	}
+	if report.Remaining > 0 {
+		return fmt.Errorf("fsck found %d unfixed issues", report.Remaining)
+	}
+	return nil
+}
+
+// fsck runs the selected checks in order. Checks named in opts.Fix (or all of them
+// for "all") are repaired after being reported, so the report shows what was changed.
+func fsck(ctx context.Context, checker repo.IntegrityChecker, opts fsckOptions) (*fsckReport, error) {
+	checks, err := selectChecks(opts.Checks)
+	if err != nil {
+		return nil, err
+	}
+	fixes, err := selectChecks(opts.Fix)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Fix) == 0 {
+		fixes = nil
+	}
+
+	report := &fsckReport{}
+	for _, check := range checks {
+		issues, found, err := checker.Find(ctx, check.Name, opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		result := fsckResult{Check: check.Name, Description: check.Description, Found: found, Issues: issues}
+		if result.Issues == nil {
+			result.Issues = []repo.IntegrityIssue{}
+		}
+
+		if found > 0 && slices.Contains(fixes, check) {
+			if result.Fixed, err = checker.Fix(ctx, check.Name); err != nil {
+				return nil, err
+			}
+		} else {
+			report.Remaining += found
+		}
+		report.Checks = append(report.Checks, result)
+	}
+	return report, nil
+}
+
+// selectChecks resolves check names, where none or "all" selects every check
+func selectChecks(names []string) ([]repo.IntegrityCheck, error) {
+	if len(names) == 0 || slices.Contains(names, "all") {
+		return repo.IntegrityChecks, nil
+	}
+	var checks []repo.IntegrityCheck
+	for _, check := range repo.IntegrityChecks {
+		if slices.Contains(names, check.Name) {
+			checks = append(checks, check)
+		}
+	}
+	for _, name := range names {
+		if !slices.ContainsFunc(checks, func(check repo.IntegrityCheck) bool { return check.Name == name }) {
+			return nil, fmt.Errorf("unknown check %q", name)
+		}
+	}
+	return checks, nil
+}
+
+func writeFsckReport(w io.Writer, report *fsckReport, format string) error {
+	if format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	for _, result := range report.Checks {
+		status := "ok"
+		switch {
+		case result.Fixed > 0:
+			status = fmt.Sprintf("%d found, %d rows fixed", result.Found, result.Fixed)
+		case result.Found > 0:
+			status = fmt.Sprintf("%d found", result.Found)
+		}
+		fmt.Fprintf(w, "%-20s %s (%s)\n", result.Check, status, result.Description)
+		for _, issue := range result.Issues {
+			fmt.Fprintf(w, "  %s %d: %s\n", issue.Table, issue.ID, issue.Detail)
+		}
+		if hidden := result.Found - len(result.Issues); hidden > 0 {
+			fmt.Fprintf(w, "  ... and %d more\n", hidden)
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d unfixed issues\n", report.Remaining)
+	return err
+}
@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestFsck(t *testing.T) {
+	ctx := context.Background()
+
+	open := func(t *testing.T) repo.IntegrityChecker {
+		engine, err := repo.OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		t.Cleanup(func() { engine.Close() })
+
+		for _, place := range []*repo.Place{
+			{DisplayName: "Broken box", Latitude: 10, Longitude: 10, BoundingBox: "[1]"},
+			{DisplayName: "Off the map", Latitude: -91, Longitude: 10},
+		} {
+			if err := engine.Places().Create(ctx, place); err != nil {
+				t.Fatalf("Create place failed: %v", err)
+			}
+		}
+		checker, err := repo.NewIntegrityChecker(engine)
+		if err != nil {
+			t.Fatalf("NewIntegrityChecker failed: %v", err)
+		}
+		return checker
+	}
+
+	t.Run("Reports without fixing", func(t *testing.T) {
+		report, err := fsck(ctx, open(t), fsckOptions{Limit: 20})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		if len(report.Checks) != len(repo.IntegrityChecks) {
+			t.Errorf("Expected every check to run, got %d", len(report.Checks))
+		}
+		if report.Remaining != 2 {
+			t.Errorf("Expected 2 unfixed issues, got %d", report.Remaining)
+		}
+	})
+
+	t.Run("Fixes selected checks", func(t *testing.T) {
+		checker := open(t)
+		report, err := fsck(ctx, checker, fsckOptions{Fix: []string{repo.CheckBoundingBoxes}})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		if report.Remaining != 1 {
+			t.Errorf("Expected only the coordinates issue to remain, got %d", report.Remaining)
+		}
+
+		report, err = fsck(ctx, checker, fsckOptions{Checks: []string{repo.CheckBoundingBoxes}})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		if len(report.Checks) != 1 || report.Checks[0].Found != 0 {
+			t.Errorf("Expected the bounding box to be repaired, got %+v", report.Checks)
+		}
+	})
+
+	t.Run("Fix all", func(t *testing.T) {
+		report, err := fsck(ctx, open(t), fsckOptions{Fix: []string{"all"}})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		if report.Remaining != 0 {
+			t.Errorf("Expected no unfixed issues, got %d", report.Remaining)
+		}
+	})
+
+	t.Run("Rejects unknown checks", func(t *testing.T) {
+		if _, err := fsck(ctx, open(t), fsckOptions{Fix: []string{"everything"}}); err == nil {
+			t.Error("Expected an error for an unknown check")
+		}
+	})
+
+	t.Run("JSON report", func(t *testing.T) {
+		report, err := fsck(ctx, open(t), fsckOptions{Checks: []string{repo.CheckInvalidCoordinates}})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := writeFsckReport(&buf, report, "json"); err != nil {
+			t.Fatalf("writeFsckReport failed: %v", err)
+		}
+		var decoded fsckReport
+		if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+			t.Fatalf("Report is not JSON: %v", err)
+		}
+		if len(decoded.Checks) != 1 || len(decoded.Checks[0].Issues) != 1 || decoded.Checks[0].Issues[0].Table != "places" {
+			t.Errorf("Unexpected report: %s", buf.String())
+		}
+	})
+
+	t.Run("Text report", func(t *testing.T) {
+		report, err := fsck(ctx, open(t), fsckOptions{Limit: 1})
+		if err != nil {
+			t.Fatalf("fsck failed: %v", err)
+		}
+		var buf bytes.Buffer
+		if err := writeFsckReport(&buf, report, "text"); err != nil {
+			t.Fatalf("writeFsckReport failed: %v", err)
+		}
+		for _, want := range []string{"bounding-boxes", "1 found", "places 1:", "2 unfixed issues"} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("Expected %q in report:\n%s", want, buf.String())
+			}
+		}
+	})
+}
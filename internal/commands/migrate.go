@@ -2,53 +2,90 @@ package commands
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
 	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/database"
+	"stormlightlabs.org/weather_api/migrations"
 )
 
-func runMigrations(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
-	direction := cmd.String("direction")
-	steps := cmd.Int("steps")
+// migrationVersionLayout formats the version of a new migration from its creation time
+const migrationVersionLayout = "20060102150405"
+
+// migrationSource reads the migrations of dir, or the ones embedded in the binary when
+// dir is empty
+func migrationSource(dir string) (source.Driver, error) {
+	var fsys fs.FS = migrations.FS
+	if dir != "" {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("migrations directory not found: %w", err)
+		}
+		fsys = os.DirFS(dir)
+	}
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+	return src, nil
+}
 
-	databaseURL := os.Getenv("DATABASE_URL")
+// newMigrate opens the migrations of dir (see migrationSource) against databaseURL
+func newMigrate(dir, databaseURL string) (*migrate.Migrate, error) {
 	if databaseURL == "" {
-		return fmt.Errorf("DATABASE_URL environment variable is required")
+		return nil, fmt.Errorf("DATABASE_URL environment variable is required")
 	}
 
-	migrationsPath := "file://migrations"
-	if _, err := os.Stat("migrations"); os.IsNotExist(err) {
-		return fmt.Errorf("migrations directory not found")
+	src, err := migrationSource(dir)
+	if err != nil {
+		return nil, err
 	}
+	m, err := migrate.NewWithSourceInstance("iofs", src, databaseURL)
+	if err != nil {
+		src.Close()
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	return m, nil
+}
 
-	m, err := migrate.New(migrationsPath, databaseURL)
+func runMigrations(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
+	direction := cmd.String("direction")
+	steps := cmd.Int("steps")
+	if direction != "up" && direction != "down" {
+		return fmt.Errorf("invalid direction: %s (use 'up' or 'down')", direction)
+	}
+
+	m, err := newMigrate(cmd.String("path"), os.Getenv("DATABASE_URL"))
 	if err != nil {
-		return fmt.Errorf("failed to create migrate instance: %w", err)
+		return err
 	}
 	defer m.Close()
 
 	logger.Info("Running migrations", "direction", direction, "steps", steps)
 
-	switch direction {
-	case "up":
-		if steps == 0 {
-			err = m.Up()
-		} else {
-			err = m.Steps(steps)
-		}
-	case "down":
-		if steps == 0 {
-			err = m.Down()
-		} else {
-			err = m.Steps(-steps)
-		}
+	switch {
+	case direction == "up" && steps == 0:
+		err = m.Up()
+	case direction == "up":
+		err = m.Steps(steps)
+	case steps == 0:
+		err = m.Down()
 	default:
-		return fmt.Errorf("invalid direction: %s (use 'up' or 'down')", direction)
+		err = m.Steps(-steps)
 	}
 
 	if err != nil && err != migrate.ErrNoChange {
@@ -63,3 +100,195 @@ func runMigrations(_ context.Context, cmd *cli.Command, logger *log.Logger) erro
 
 	return nil
 }
+
+// migrationLockName names the advisory lock serializing migrate-on-start between
+// replicas
+const migrationLockName = "weather_api migrations"
+
+// autoMigrate applies the pending embedded migrations to databaseURL before the server
+// starts. Replicas starting together take turns through an advisory lock, waiting up to
+// lockTimeout for it, so one applies the migrations and the rest find nothing to do.
+func autoMigrate(ctx context.Context, databaseURL string, lockTimeout time.Duration, logger *log.Logger) error {
+	pool, err := openPool(databaseURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	logger.Info("Acquiring migration lock", "timeout", lockTimeout)
+	return database.WithAdvisoryLock(lockCtx, pool.DB, database.AdvisoryLockKey(migrationLockName), func() error {
+		m, err := newMigrate("", databaseURL)
+		if err != nil {
+			return err
+		}
+		defer m.Close()
+
+		err = m.Up()
+		switch {
+		case err == migrate.ErrNoChange:
+			logger.Info("Database schema is up to date")
+		case errors.As(err, new(migrate.ErrDirty)):
+			return fmt.Errorf("database schema is dirty; fix it and run migrate force: %w", err)
+		case err != nil:
+			return fmt.Errorf("migration failed: %w", err)
+		default:
+			version, _, _ := m.Version()
+			logger.Info("Migrations applied", "version", version)
+		}
+		return nil
+	})
+}
+
+// migrationFile is one migration of a source
+type migrationFile struct {
+	Version uint
+	Name    string
+}
+
+// listMigrations returns the migrations of src in version order
+func listMigrations(src source.Driver) ([]migrationFile, error) {
+	var files []migrationFile
+	version, err := src.First()
+	for err == nil {
+		r, identifier, readErr := src.ReadUp(version)
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read migration %d: %w", version, readErr)
+		}
+		r.Close()
+		files = append(files, migrationFile{Version: version, Name: identifier})
+		version, err = src.Next(version)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	return files, nil
+}
+
+func migrationStatus(_ context.Context, cmd *cli.Command, _ *log.Logger) error {
+	src, err := migrationSource(cmd.String("path"))
+	if err != nil {
+		return err
+	}
+	files, err := listMigrations(src)
+	src.Close()
+	if err != nil {
+		return err
+	}
+
+	m, err := newMigrate(cmd.String("path"), os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	current, dirty, err := m.Version()
+	if err != nil && err != migrate.ErrNilVersion {
+		return fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return writeMigrationStatus(os.Stdout, files, current, dirty)
+}
+
+// writeMigrationStatus lists files as applied up to current (0 when none has been) and
+// pending after it
+func writeMigrationStatus(w io.Writer, files []migrationFile, current uint, dirty bool) error {
+	pending := 0
+	for _, file := range files {
+		status := "applied"
+		switch {
+		case file.Version == current && dirty:
+			status = "dirty"
+		case file.Version > current:
+			status = "pending"
+			pending++
+		}
+		fmt.Fprintf(w, "%-8s %d %s\n", status, file.Version, file.Name)
+	}
+
+	if current == 0 {
+		fmt.Fprintln(w, "\nNo migrations applied")
+	} else {
+		fmt.Fprintf(w, "\nCurrent version %d", current)
+		if dirty {
+			fmt.Fprint(w, " (dirty: fix the schema, then run migrate force)")
+		}
+		fmt.Fprintln(w)
+	}
+	_, err := fmt.Fprintf(w, "%d pending migrations\n", pending)
+	return err
+}
+
+var migrationNameSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// createMigration writes empty up and down files for a migration called name to dir,
+// versioned by now, and returns their paths
+func createMigration(dir, name string, now time.Time) ([]string, error) {
+	name = strings.Trim(migrationNameSeparators.ReplaceAllString(strings.ToLower(name), "_"), "_")
+	if name == "" {
+		return nil, errors.New("migration name is required (e.g. add_forecasts_snow_depth)")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	base := filepath.Join(dir, now.UTC().Format(migrationVersionLayout)+"_"+name)
+	paths := []string{base + ".up.sql", base + ".down.sql"}
+	for i, path := range paths {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if err != nil {
+			for _, created := range paths[:i] {
+				os.Remove(created)
+			}
+			return nil, fmt.Errorf("failed to create migration: %w", err)
+		}
+		file.Close()
+	}
+	return paths, nil
+}
+
+func runCreateMigration(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
+	if cmd.Args().Len() != 1 {
+		return errors.New("usage: migrate create <name>")
+	}
+	paths, err := createMigration(cmd.String("dir"), cmd.Args().First(), time.Now())
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		logger.Info("Created migration", "file", path)
+	}
+	return nil
+}
+
+func forceMigration(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
+	if cmd.Args().Len() != 1 {
+		return errors.New("usage: migrate force <version>")
+	}
+	version, err := parseForceVersion(cmd.Args().First())
+	if err != nil {
+		return err
+	}
+
+	m, err := newMigrate(cmd.String("path"), os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	if err := m.Force(version); err != nil {
+		return fmt.Errorf("failed to force version %d: %w", version, err)
+	}
+	logger.Info("Forced migration version", "version", version)
+	return nil
+}
+
+// parseForceVersion parses the version migrate force records: a migration version, or
+// -1 to record that none has been applied
+func parseForceVersion(value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < -1 {
+		return 0, fmt.Errorf("invalid version %q: use a migration version or -1 for none", value)
+	}
+	return version, nil
+}
@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	src, err := migrationSource("")
+	if err != nil {
+		t.Fatalf("migrationSource failed: %v", err)
+	}
+	defer src.Close()
+
+	files, err := listMigrations(src)
+	if err != nil {
+		t.Fatalf("listMigrations failed: %v", err)
+	}
+	if len(files) == 0 || files[0].Version != 20250731000000 || files[0].Name != "create_initial_schema" {
+		t.Fatalf("Expected the initial schema first, got %+v", files)
+	}
+	for _, file := range files {
+		r, _, err := src.ReadDown(file.Version)
+		if err != nil {
+			t.Errorf("Expected a down migration for %d %s: %v", file.Version, file.Name, err)
+			continue
+		}
+		r.Close()
+	}
+}
+
+func TestMigrationSourceDirectory(t *testing.T) {
+	if _, err := migrationSource(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+
+	dir := t.TempDir()
+	if _, err := createMigration(dir, "create widgets", time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatal(err)
+	}
+	src, err := migrationSource(dir)
+	if err != nil {
+		t.Fatalf("migrationSource failed: %v", err)
+	}
+	defer src.Close()
+	if files, err := listMigrations(src); err != nil || len(files) != 1 || files[0].Version != 20250901120000 {
+		t.Errorf("Expected the created migration, got %+v (%v)", files, err)
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Date(2025, 9, 1, 14, 30, 5, 0, time.FixedZone("CEST", 2*60*60))
+
+	paths, err := createMigration(dir, "Add Forecasts snow-depth", now)
+	if err != nil {
+		t.Fatalf("createMigration failed: %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "20250901123005_add_forecasts_snow_depth.up.sql"),
+		filepath.Join(dir, "20250901123005_add_forecasts_snow_depth.down.sql"),
+	}
+	for i, path := range want {
+		if paths[i] != path {
+			t.Errorf("Expected %s, got %s", path, paths[i])
+		}
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("Expected %s to exist: %v", path, err)
+		}
+	}
+
+	if _, err := createMigration(dir, "add forecasts snow depth", now); err == nil {
+		t.Error("Expected an error when the migration already exists")
+	}
+	if _, err := createMigration(dir, " -- ", now); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+}
+
+func TestWriteMigrationStatus(t *testing.T) {
+	files := []migrationFile{{1, "one"}, {2, "two"}, {3, "three"}}
+
+	var buf bytes.Buffer
+	if err := writeMigrationStatus(&buf, files, 2, true); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"applied  1 one", "dirty    2 two", "pending  3 three", "Current version 2 (dirty", "1 pending migrations"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected the status to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	writeMigrationStatus(&buf, files, 0, false)
+	if !strings.Contains(buf.String(), "No migrations applied") || !strings.Contains(buf.String(), "3 pending migrations") {
+		t.Errorf("Expected every migration pending, got:\n%s", buf.String())
+	}
+}
+
+func TestParseForceVersion(t *testing.T) {
+	for value, want := range map[string]int{"20250731000000": 20250731000000, "-1": -1} {
+		if got, err := parseForceVersion(value); err != nil || got != want {
+			t.Errorf("parseForceVersion(%q) = %d, %v; want %d", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"-2", "12abc", ""} {
+		if _, err := parseForceVersion(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+func runPromote(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	role := cmd.String("role")
+	if models.RoleRank(role) < 0 {
+		return fmt.Errorf("--role must be one of %s", strings.Join(models.Roles, ", "))
+	}
+
+	config, err := secrets.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	engine, err := openEngine(config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	user, err := setRole(ctx, engine.Users(), cmd.String("user"), role)
+	if err != nil {
+		return err
+	}
+
+	logger.Info("User role updated", "user", user.Username, "id", user.ID, "role", role)
+	return nil
+}
+
+// setRole gives the user named by a username or ID a role, returning the user
+func setRole(ctx context.Context, users repo.UserRepository, name, role string) (*repo.User, error) {
+	user, err := users.GetByUsername(ctx, name)
+	if id, convErr := strconv.Atoi(name); errors.Is(err, repo.ErrNotFound) && convErr == nil {
+		user, err = users.GetByID(ctx, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user %q: %w", name, err)
+	}
+
+	if err := users.SetRole(ctx, user.ID, role); err != nil {
+		return nil, err
+	}
+	user.Role = role
+	return user, nil
+}
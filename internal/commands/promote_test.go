@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestSetRole(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	users := engine.Users()
+	alice := &repo.User{Username: "alice", IsActive: true}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := setRole(ctx, users, "alice", models.RoleAdmin); err != nil {
+		t.Fatalf("setRole by username failed: %v", err)
+	}
+	if got, _ := users.GetByID(ctx, alice.ID); got.Role != models.RoleAdmin {
+		t.Errorf("Expected alice to be an admin, got %q", got.Role)
+	}
+
+	if _, err := setRole(ctx, users, "1", models.RoleReadOnly); err != nil {
+		t.Fatalf("setRole by ID failed: %v", err)
+	}
+	if got, _ := users.GetByID(ctx, alice.ID); got.Role != models.RoleReadOnly {
+		t.Errorf("Expected alice to be readonly, got %q", got.Role)
+	}
+
+	if _, err := setRole(ctx, users, "bob", models.RoleAdmin); !errors.Is(err, repo.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing user, got %v", err)
+	}
+}
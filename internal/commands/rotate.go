@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+func rotateKey(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
+	filePath := cmd.String("file")
+	oldKey, newKey := cmd.String("old-key"), cmd.String("new-key")
+
+	var err error
+	if oldKey == "" {
+		if oldKey, err = secrets.PromptForKey("Enter current encryption key: "); err != nil {
+			return fmt.Errorf("failed to read key: %w", err)
+		}
+	}
+	if newKey == "" {
+		if newKey, err = secrets.PromptForKey("Enter new encryption key: "); err != nil {
+			return fmt.Errorf("failed to read key: %w", err)
+		}
+	}
+	if err := secrets.NewKeyValidator().ValidateKey(newKey); err != nil {
+		return fmt.Errorf("new key validation failed: %w", err)
+	}
+	if newKey == oldKey {
+		return fmt.Errorf("the new key must differ from the current key")
+	}
+
+	logger.Info("Rotating encryption key", "file", filePath)
+	rotated, err := rotateEnvFile(filePath, oldKey, newKey)
+	if err != nil {
+		return err
+	}
+	if rotated == 0 {
+		logger.Warn("No encrypted values found; file left unchanged", "file", filePath)
+		return nil
+	}
+
+	// The database holds no values encrypted with this key (digest signing secrets are
+	// stored as generated), so the env file is all there is to rotate
+	logger.Info("Key rotation completed successfully", "file", filePath, "values", rotated, "backup", filePath+".backup")
+	return nil
+}
+
+// errNothingToRotate stops the rewrite of an env file without encrypted values
+var errNothingToRotate = errors.New("no encrypted values")
+
+// rotateEnvFile re-encrypts every encrypted value in an env file with newKey, returning
+// how many were rotated. Values are read with the dotenv rules, so quoted values and
+// comments are kept. Each value is checked to decrypt with newKey before the rewritten
+// file replaces the old one, which is kept as <file>.backup; nothing is written when a
+// value fails to decrypt with oldKey.
+func rotateEnvFile(filePath, oldKey, newKey string) (int, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	plaintexts := map[string]string{}
+	rotated, err := secrets.RewriteFile(filePath, func(w io.Writer, r io.Reader) (int, error) {
+		var original bytes.Buffer
+		count, err := secrets.TransformEnv(w, io.TeeReader(r, &original), func(name, value string) (string, error) {
+			if !secrets.IsEncrypted(value) {
+				return value, nil
+			}
+			plaintext, err := secrets.DecryptValue(value, oldKey)
+			if err != nil {
+				return "", fmt.Errorf("failed to decrypt %s with the current key: %w", name, err)
+			}
+			encrypted, err := secrets.EncryptValue(plaintext, newKey)
+			if err != nil {
+				return "", fmt.Errorf("failed to encrypt %s: %w", name, err)
+			}
+			if check, err := secrets.DecryptValue(encrypted, newKey); err != nil || check != plaintext {
+				return "", fmt.Errorf("round-trip check failed for %s", name)
+			}
+			plaintexts[name] = plaintext
+			return encrypted, nil
+		})
+		if err != nil {
+			return 0, err
+		}
+		if count == 0 {
+			return 0, errNothingToRotate
+		}
+		if err := os.WriteFile(filePath+".backup", original.Bytes(), info.Mode().Perm()); err != nil {
+			return 0, fmt.Errorf("failed to create backup: %w", err)
+		}
+		return count, nil
+	})
+	if errors.Is(err, errNothingToRotate) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// Check what reached the disk, not just what was meant to
+	written, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read back rotated file: %w", err)
+	}
+	if err := verifyRotation(written, plaintexts, newKey); err != nil {
+		return 0, fmt.Errorf("%w; the original is in %s", err, filePath+".backup")
+	}
+	return rotated, nil
+}
+
+// verifyRotation checks that the named values of an env file decrypt with key to their
+// plaintexts
+func verifyRotation(content []byte, plaintexts map[string]string, key string) error {
+	values, err := secrets.ParseDotenv(content)
+	if err != nil {
+		return fmt.Errorf("round-trip check failed: %w", err)
+	}
+	for name, want := range plaintexts {
+		got, err := secrets.DecryptValue(values[name], key)
+		if err != nil || got != want {
+			return fmt.Errorf("round-trip check failed for %s", name)
+		}
+	}
+	return nil
+}
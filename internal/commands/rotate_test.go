@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/secrets"
+)
+
+func TestRotateEnvFile(t *testing.T) {
+	const oldKey, newKey = "OldRotationKey1", "NewRotationKey2"
+	encrypted, err := secrets.EncryptValue("postgres://user:pass@db/weather", oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "env.local")
+	original := "# Weather API\nDATABASE_URL=" + encrypted + "\nNWS_AGENT=plain-agent\nexport QUOTED=\"" + encrypted + "\" # primary\n"
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := rotateEnvFile(path, "WrongRotationKey3", newKey); err == nil {
+		t.Fatal("Expected an error rotating with the wrong key")
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Fatal("Expected a failed rotation to leave the file alone")
+	}
+
+	rotated, err := rotateEnvFile(path, oldKey, newKey)
+	if err != nil || rotated != 2 {
+		t.Fatalf("Expected two rotated values, got %d (%v)", rotated, err)
+	}
+
+	content, _ := os.ReadFile(path)
+	lines := strings.Split(string(content), "\n")
+	if lines[0] != "# Weather API" || lines[2] != "NWS_AGENT=plain-agent" || lines[4] != "" {
+		t.Errorf("Expected comments and plain values to be kept as they were, got %q", content)
+	}
+	value := strings.TrimPrefix(lines[1], "DATABASE_URL=")
+	if got, err := secrets.DecryptValue(value, newKey); err != nil || got != "postgres://user:pass@db/weather" {
+		t.Errorf("Expected the value to decrypt with the new key, got %q (%v)", got, err)
+	}
+	if _, err := secrets.DecryptValue(value, oldKey); err == nil {
+		t.Error("Expected the old key to no longer decrypt the value")
+	}
+	quoted, ok := strings.CutPrefix(lines[3], `export QUOTED="`)
+	quoted, comment, _ := strings.Cut(quoted, `"`)
+	if !ok || comment != " # primary" {
+		t.Errorf("Expected the quotes, export prefix and comment to be kept, got %q", lines[3])
+	}
+	if got, err := secrets.DecryptValue(quoted, newKey); err != nil || got != "postgres://user:pass@db/weather" {
+		t.Errorf("Expected the quoted value to decrypt with the new key, got %q (%v)", got, err)
+	}
+
+	if backup, _ := os.ReadFile(path + ".backup"); string(backup) != original {
+		t.Errorf("Expected the original file as the backup, got %q", backup)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("Expected permissions to be kept, got %v", info.Mode().Perm())
+	}
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) != 0 {
+		t.Errorf("Expected no temporary files left behind, got %v", matches)
+	}
+
+	plain := filepath.Join(t.TempDir(), "env.plain")
+	if err := os.WriteFile(plain, []byte("NWS_AGENT=plain-agent\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if rotated, err := rotateEnvFile(plain, oldKey, newKey); err != nil || rotated != 0 {
+		t.Errorf("Expected nothing to rotate, got %d (%v)", rotated, err)
+	}
+	if _, err := os.Stat(plain + ".backup"); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup without encrypted values, got %v", err)
+	}
+}
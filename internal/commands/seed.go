@@ -0,0 +1,48 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/secrets"
+	"stormlightlabs.org/weather_api/internal/seed"
+)
+
+func runSeed(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	days := int(cmd.Int("forecast-days"))
+	if days < 0 {
+		return fmt.Errorf("--forecast-days cannot be negative")
+	}
+
+	config, err := secrets.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	engine, err := openEngine(config)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	opts := seed.Options{
+		Reset:         cmd.Bool("reset"),
+		ForecastHours: days * 24,
+		Seed:          uint64(cmd.Int("seed")),
+	}
+	if opts.Reset {
+		logger.Warn("Resetting storage before seeding", "engine", engine.Name())
+	}
+	logger.Info("Seeding fixtures", "engine", engine.Name(), "forecast_days", days)
+
+	result, err := seed.Run(ctx, engine, opts)
+	if err != nil {
+		return fmt.Errorf("seed failed: %w", err)
+	}
+
+	logger.Info("Seed completed successfully",
+		"cities", result.Cities, "places", result.Places, "forecasts", result.Forecasts, "skipped", result.Skipped)
+	return nil
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/replication"
+)
+
+func exportSnapshot(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	node := cmd.String("node-id")
+	output := cmd.String("output")
+
+	keyValue := cmd.String("signing-key")
+	if keyValue == "" {
+		keyValue = os.Getenv("WEATHER_API_SNAPSHOT_KEY")
+	}
+	if keyValue == "" {
+		return fmt.Errorf("a signing key is required (--signing-key or WEATHER_API_SNAPSHOT_KEY)")
+	}
+	key, err := replication.ParsePrivateKey(keyValue)
+	if err != nil {
+		return err
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	bundle, err := replication.Export(ctx, replication.NewPostgreSQLStore(db), node)
+	if err != nil {
+		return fmt.Errorf("export failed: %w", err)
+	}
+	if err := bundle.Sign(key); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	if err := replication.WriteBundle(file, bundle); err != nil {
+		return err
+	}
+
+	logger.Info("Snapshot exported",
+		"output", output, "node", node, "vector", bundle.Vector,
+		"cities", len(bundle.Cities), "places", len(bundle.Places))
+	return nil
+}
+
+func importSnapshot(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	node := cmd.String("node-id")
+	input := cmd.String("input")
+
+	trusted := splitList(cmd.String("trusted-keys"))
+	if len(trusted) == 0 {
+		trusted = splitList(os.Getenv("WEATHER_API_SNAPSHOT_TRUSTED_KEYS"))
+	}
+	if len(trusted) == 0 {
+		return fmt.Errorf("at least one trusted public key is required (--trusted-keys or WEATHER_API_SNAPSHOT_TRUSTED_KEYS)")
+	}
+
+	file, err := os.Open(input)
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot file: %w", err)
+	}
+	defer file.Close()
+
+	bundle, err := replication.ReadBundle(file)
+	if err != nil {
+		return err
+	}
+	if err := bundle.Verify(trusted); err != nil {
+		return fmt.Errorf("snapshot rejected: %w", err)
+	}
+
+	db, err := openDatabase()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	result, err := replication.Import(ctx, replication.NewPostgreSQLStore(db), node, bundle)
+	if err != nil {
+		return fmt.Errorf("import failed: %w", err)
+	}
+
+	if result.AlreadyApplied {
+		logger.Info("Snapshot already applied, nothing to do", "origin", bundle.Origin, "ordering", result.Ordering)
+		return nil
+	}
+
+	logger.Info("Snapshot imported",
+		"origin", bundle.Origin, "ordering", result.Ordering,
+		"cities", result.CitiesApplied, "places", result.PlacesApplied,
+		"conflicts_kept_local", result.ConflictsLost, "vector", result.Vector)
+	return nil
+}
+
+func generateSnapshotKey(_ context.Context, _ *cli.Command, logger *log.Logger) error {
+	pub, priv, err := replication.GenerateKey()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Snapshot signing key generated")
+	fmt.Printf("Private signing key (keep secret, set on the exporting deployment):\n")
+	fmt.Printf("  export WEATHER_API_SNAPSHOT_KEY=\"%s\"\n", priv)
+	fmt.Printf("\nPublic key (add to WEATHER_API_SNAPSHOT_TRUSTED_KEYS on importing deployments):\n")
+	fmt.Printf("  %s\n", pub)
+	return nil
+}
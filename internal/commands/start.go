@@ -3,26 +3,802 @@ package commands
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/urfave/cli/v3"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"stormlightlabs.org/weather_api/internal/admin"
+	"stormlightlabs.org/weather_api/internal/apiversion"
+	"stormlightlabs.org/weather_api/internal/audit"
+	"stormlightlabs.org/weather_api/internal/authz"
+	"stormlightlabs.org/weather_api/internal/blend"
+	"stormlightlabs.org/weather_api/internal/blob"
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/certs"
+	"stormlightlabs.org/weather_api/internal/compression"
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/degrade"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/health"
+	"stormlightlabs.org/weather_api/internal/idempotency"
+	"stormlightlabs.org/weather_api/internal/jobs"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/notify"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/search"
+	"stormlightlabs.org/weather_api/internal/secrets"
+	"stormlightlabs.org/weather_api/internal/share"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/tsdb"
+	"stormlightlabs.org/weather_api/internal/ttl"
+	"stormlightlabs.org/weather_api/internal/webhook"
+	weatherv1 "stormlightlabs.org/weather_api/proto/weather/v1"
 )
 
-func startServer(_ context.Context, cmd *cli.Command, logger *log.Logger) error {
+// unversionedDeprecated is when the routes served before /v1 became aliases of their
+// /v1 successors
+var unversionedDeprecated = time.Date(2025, time.August, 11, 0, 0, 0, 0, time.UTC)
+
+// cleanupInterval is the time between runs of the retention and cleanup jobs
+const cleanupInterval = 24 * time.Hour
+
+// partitionMonthsAhead is how many months past the current one the forecast partition
+// job keeps created, ahead of the longest forecasts ingested
+const partitionMonthsAhead = 2
+
+// digestInterval is the time between checks for due forecast digests
+const digestInterval = time.Minute
+
+// providerCheckTimeout bounds each background health check of a provider
+const providerCheckTimeout = 5 * time.Second
+
+// idempotencyEntries bounds the in-memory store of responses kept for Idempotency-Key
+// retries, used when the storage engine has no shared store
+const idempotencyEntries = 10000
+
+// geocodeCacheEntries bounds the in-memory cache of addresses resolved by /weather
+const geocodeCacheEntries = 10000
+
+// serverSetup is what runServer runs the API on besides its flags
+type serverSetup struct {
+	config *secrets.Config
+
+	// providers creates the upstream providers
+	providers func(*secrets.Config, *log.Logger) (*providers.ProviderManager, error)
+
+	// prepare, when set, runs on the storage engine before serving, e.g. to seed it
+	prepare func(context.Context, repo.Engine) error
+
+	// mount, when set, adds routes beyond the API's own
+	mount func(*http.ServeMux)
+}
+
+func startServer(ctx context.Context, cmd *cli.Command, logger *log.Logger) error {
+	config, err := secrets.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if cmd.Bool("auto-migrate") {
+		if engine, _ := repo.ParseEngineName(config.StorageEngine); engine != repo.EnginePostgres {
+			logger.Warn("Skipping --auto-migrate: migrations apply to PostgreSQL only", "engine", config.StorageEngine)
+		} else if err := autoMigrate(ctx, config.DatabaseURL, cmd.Duration("migrate-lock-timeout"), logger); err != nil {
+			return err
+		}
+	}
+	return runServer(ctx, cmd, logger, serverSetup{config: config, providers: newProviders})
+}
+
+// runServer serves the API until ctx ends or a signal arrives, then drains in-flight
+// requests
+func runServer(ctx context.Context, cmd *cli.Command, logger *log.Logger, setup serverSetup) error {
 	host := cmd.String("host")
 	port := cmd.String("port")
 	addr := fmt.Sprintf("%s:%s", host, port)
 
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	config := setup.config
+
+	tracingConfig, err := tracing.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load tracing configuration: %w", err)
+	}
+	tracerProvider, err := tracing.Setup(tracingConfig, func(err error) {
+		logger.Warn("Trace export failed", "error", err)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	if tracerProvider != nil {
+		defer tracerProvider.Shutdown(context.Background())
+		logger.Info("Tracing enabled", "endpoint", tracingConfig.Endpoint, "service", tracingConfig.ServiceName)
+	}
+
+	certConfig := certs.Config{
+		CertFile: cmd.String("tls-cert"),
+		KeyFile:  cmd.String("tls-key"),
+		Domains:  cmd.StringSlice("autocert-domains"),
+		Email:    cmd.String("autocert-email"),
+		CacheDir: cmd.String("autocert-cache"),
+	}
+	if err := certConfig.Validate(); err != nil {
+		return err
+	}
+
+	compressionConfig := compression.DefaultConfig()
+	compressionConfig.Encodings = cmd.StringSlice("compression-encodings")
+	compressionConfig.Level = int(cmd.Int("compression-level"))
+	compressionConfig.MinSize = int(cmd.Int("compression-min-size"))
+	if err := compressionConfig.Validate(); err != nil {
+		return err
+	}
+
+	degradeConfig := degrade.DefaultConfig()
+	if degradeConfig.Routes, err = degrade.ParseRoutes(cmd.StringSlice("stale-routes")); err != nil {
+		return err
+	}
+	if err := degradeConfig.Validate(); err != nil {
+		return err
+	}
+
+	searchConfig, err := search.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load search configuration: %w", err)
+	}
+
+	tsdbConfig, err := tsdb.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load time-series configuration: %w", err)
+	}
+
+	notifyConfig, err := notify.LoadConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load email configuration: %w", err)
+	}
+
+	if err := geo.SetPrecision(int(cmd.Int("coordinate-precision"))); err != nil {
+		return err
+	}
+
+	ttlPolicy, err := ttl.Load(cmd.String("ttl-policy"))
+	if err != nil {
+		return err
+	}
+
+	pageSizes, err := controllers.ParsePageSizes(cmd.StringSlice("page-sizes"))
+	if err != nil {
+		return err
+	}
+	controllers.SetPageSizes(pageSizes)
+
+	if err := breaker.Configure(breaker.Config{
+		Failures:     int(cmd.Int("breaker-failures")),
+		TimeoutRatio: cmd.Float("breaker-timeout-ratio"),
+		Window:       int(cmd.Int("breaker-window")),
+		Cooldown:     cmd.Duration("breaker-cooldown"),
+	}); err != nil {
+		return err
+	}
+
+	retryBudgets, err := retry.ParseBudgets(cmd.StringSlice("retry-budgets"))
+	if err != nil {
+		return err
+	}
+	if err := retry.Configure(retry.Policy{
+		Attempts:  int(cmd.Int("retry-attempts")),
+		BaseDelay: cmd.Duration("retry-base-delay"),
+		MaxDelay:  cmd.Duration("retry-max-delay"),
+		Budget:    cmd.Duration("retry-budget"),
+	}, retryBudgets); err != nil {
+		return err
+	}
+
+	rateLimits, err := ratelimit.ParseLimits(cmd.StringSlice("rate-limits"))
+	if err != nil {
+		return err
+	}
+	if err := ratelimit.Configure(rateLimits); err != nil {
+		return err
+	}
+
+	blendWeights, err := blend.ParseWeights(cmd.StringSlice("blend-weights"))
+	if err != nil {
+		return fmt.Errorf("invalid --blend-weights: %w", err)
+	}
+
 	logger.Info("Starting weather API server", "address", addr)
 
-	// TODO: Replace with actual server implementation
-	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		fmt.Fprintf(w, `{"status":"ok","service":"weather-api"}`)
+	manager, err := setup.providers(config, logger)
+	if err != nil {
+		return err
+	}
+	aviationProvider, err := newAviationProvider(config, logger)
+	if err != nil {
+		return err
+	}
+	if interval := cmd.Duration("provider-check-interval"); interval <= 0 {
+		return fmt.Errorf("provider check interval must be positive, got %s", interval)
+	}
+	monitor := providers.NewHealthMonitor(manager, cmd.Duration("provider-check-interval"), providerCheckTimeout)
+	go monitor.Run(ctx)
+	checks := health.Providers(manager, monitor)
+
+	adminConfig := admin.Config{Token: config.AdminToken, Providers: manager}
+	auditLog := audit.NewLog(audit.DefaultSize)
+	// Idempotency keys go in the database when it is shared between instances, so a
+	// retry reaching another instance is still replayed
+	var idempotencyStore repo.KVStore = repo.NewMemoryStore(idempotencyEntries)
+	engine, err := openEngine(config)
+	if err != nil {
+		logger.Warn("Storage unavailable, serving without repositories", "engine", config.StorageEngine, "error", err)
+		storageErr := err
+		checks = append(checks, health.Check{Name: "database", Probe: func(context.Context) error { return storageErr }})
+	} else {
+		defer engine.Close()
+		logger.Info("Storage engine ready", "engine", engine.Name())
+		if setup.prepare != nil {
+			if err := setup.prepare(ctx, engine); err != nil {
+				return err
+			}
+		}
+		// The partitioner and count estimates need the PostgreSQL engine itself, not the
+		// wrappers below
+		partitioner, _ := repo.NewForecastPartitioner(engine)
+		shared, err := repo.NewSharedStore(engine)
+		if err == nil {
+			idempotencyStore = shared
+		} else {
+			logger.Info("Idempotency keys kept in memory", "reason", err)
+		}
+		engine = repo.NewCountingEngine(engine, repo.CountConfig{
+			EstimateAbove: cmd.Int64("count-estimate-above"),
+			CacheTTL:      cmd.Duration("count-cache-ttl"),
+		})
+		hooks := auditHooks(auditLog)
+		engine = repo.NewHookedEngine(engine, hooks)
+		if mirror := openMirror(ctx, tsdbConfig, hooks, logger); mirror != nil {
+			defer mirror.Close(context.Background())
+		}
+		engine = openSearch(ctx, searchConfig, engine, hooks, logger)
+		if notifyConfig.Enabled() {
+			notify.NewQueuer(engine.AlertSubscriptions(), func(err error) {
+				logger.Warn("Alert notification queueing failed", "error", err)
+			}).Register(hooks)
+		} else {
+			logger.Warn("Alert emails disabled: WEATHER_API_SMTP_URL is not set")
+		}
+		adminConfig.Cities = engine.Cities()
+		adminConfig.Forecasts = engine.Forecasts()
+		checks = append(checks, health.Database(engine))
+
+		scheduler := newScheduler(cmd, engine, partitioner, shared, manager, notifyConfig, logger)
+		ctx, cancel := context.WithCancel(ctx)
+		defer scheduler.Wait()
+		defer cancel()
+		if err := scheduler.Start(ctx); err != nil {
+			logger.Warn("Job scheduler unavailable", "error", err)
+		} else {
+			adminConfig.JobRuns = engine.JobRuns()
+			adminConfig.Scheduler = scheduler
+		}
+	}
+
+	idempotent := idempotency.NewReplayer(idempotencyStore, cmd.Duration("idempotency-ttl"))
+	adminConfig.Idempotency = idempotent
+
+	mux := http.NewServeMux()
+	probes := health.NewProbes("weather-api", checks...)
+	mux.HandleFunc("GET /healthz", probes.Live)
+	mux.HandleFunc("GET /health", probes.Live)
+	mux.HandleFunc("GET /readyz", probes.Ready)
+
+	conditionCheck := controllers.HandlerFunc(controllers.NewHTTPConditionController(manager, nil, ttlPolicy).Check)
+	api := apiversion.NewRouter()
+	v1 := api.Version("v1", apiversion.Lifecycle{})
+	v1.HandleFunc("GET /condition-check", conditionCheck)
+	providerController := controllers.NewHTTPProviderController(manager, monitor)
+	v1.HandleFunc("GET /providers", controllers.HandlerFunc(providerController.List))
+	v1.HandleFunc("GET /providers/status", authz.Admin(controllers.HandlerFunc(providerController.Status)))
+	v1.HandleFunc("GET /audit", authz.Admin(controllers.HandlerFunc(controllers.NewHTTPAuditController(auditLog).List)))
+	var users repo.UserRepository
+	var places repo.PlaceRepository
+	if engine != nil {
+		places = engine.Places()
+		users = engine.Users()
+	}
+	weather := controllers.NewHTTPWeatherController(manager, places, repo.NewRequestCache(repo.NewMemoryStore(geocodeCacheEntries), "weather"), ttlPolicy)
+	v1.HandleFunc("GET /weather", controllers.HandlerFunc(weather.GetByAddress))
+	v1.HandleFunc("GET /forecasts/hourly", controllers.HandlerFunc(weather.GetHourlyForecast))
+	v1.HandleFunc("GET /nowcast", controllers.HandlerFunc(weather.GetNowcast))
+	v1.HandleFunc("GET /forecasts/blend", controllers.HandlerFunc(controllers.NewHTTPBlendController(blend.NewBlender(manager.GetWeatherProviders(), blendWeights)).GetBlend))
+	var airQualityReadings repo.AirQualityRepository
+	if engine != nil {
+		airQualityReadings = engine.AirQuality()
+	}
+	v1.HandleFunc("GET /air-quality", controllers.HandlerFunc(controllers.NewHTTPAirQualityController(manager, airQualityReadings, ttlPolicy).GetAirQuality))
+	if engine != nil {
+		grafana := controllers.NewHTTPGrafanaController(engine)
+		v1.HandleFunc("GET /grafana/{$}", controllers.HandlerFunc(grafana.TestConnection))
+		v1.HandleFunc("POST /grafana/search", controllers.HandlerFunc(grafana.Search))
+		v1.HandleFunc("POST /grafana/query", controllers.HandlerFunc(grafana.Query))
+		v1.HandleFunc("POST /grafana/annotations", controllers.HandlerFunc(grafana.Annotations))
+
+		cities := controllers.NewHTTPCityController(engine.Cities())
+		v1.HandleFunc("GET /cities", controllers.HandlerFunc(cities.List))
+		v1.HandleFunc("GET /cities/search", controllers.HandlerFunc(cities.Search))
+		v1.HandleFunc("GET /cities/nearby", controllers.HandlerFunc(cities.GetByCoordinates))
+		v1.HandleFunc("GET /cities/{id}", controllers.IDHandlerFunc("id", cities.GetByID))
+
+		forecasts := controllers.NewHTTPForecastController(engine.Forecasts())
+		v1.HandleFunc("POST /forecasts", authz.Admin(idempotent.Wrap(controllers.HandlerFunc(forecasts.Create))))
+		v1.HandleFunc("POST /forecasts/bulk", authz.Admin(idempotent.Wrap(controllers.HandlerFunc(forecasts.CreateBatch))))
+		v1.HandleFunc("DELETE /forecasts/expired", authz.Admin(controllers.HandlerFunc(forecasts.CleanupOldForecasts)))
+		v1.HandleFunc("DELETE /forecasts", authz.Admin(controllers.HandlerFunc(forecasts.BulkDelete)))
+		v1.HandleFunc("GET /forecasts", controllers.HandlerFunc(forecasts.List))
+		v1.HandleFunc("GET /forecasts/range", controllers.HandlerFunc(forecasts.GetByTimeRange))
+		v1.HandleFunc("GET /forecasts/{id}", controllers.IDHandlerFunc("id", forecasts.GetByID))
+		v1.HandleFunc("GET /cities/{id}/forecasts", controllers.IDHandlerFunc("id", forecasts.GetByCityID))
+		v1.HandleFunc("GET /cities/{id}/forecasts/latest", controllers.IDHandlerFunc("id", forecasts.GetLatestByCityID))
+		v1.HandleFunc("GET /cities/{id}/wind-rose", controllers.IDHandlerFunc("id", forecasts.GetWindRose))
+		v1.HandleFunc("GET /cities/{id}/forecasts/daily", controllers.IDHandlerFunc("id", forecasts.GetDailySummaries))
+		v1.HandleFunc("GET /cities/{id}/stats", controllers.IDHandlerFunc("id", forecasts.GetStats))
+
+		var exports controllers.ExportController
+		if store, err := blob.NewFileStore(cmd.String("export-dir")); err != nil {
+			logger.Warn("Forecast exports disabled", "dir", cmd.String("export-dir"), "error", err)
+		} else {
+			exports = controllers.NewHTTPExportController(engine.Forecasts(), store)
+			v1.HandleFunc("POST /exports", authz.Write(controllers.HandlerFunc(exports.Create)))
+			v1.HandleFunc("GET /exports/{id}", controllers.StringHandlerFunc("id", exports.Get))
+			v1.HandleFunc("GET /exports/{id}/download", controllers.StringHandlerFunc("id", exports.Download))
+		}
+
+		placeController := controllers.NewHTTPPlaceController(engine.Places())
+		v1.HandleFunc("GET /places", controllers.HandlerFunc(placeController.List))
+		v1.HandleFunc("GET /places/search", controllers.HandlerFunc(placeController.Search))
+		v1.HandleFunc("GET /places/nearby", controllers.HandlerFunc(placeController.GetByCoordinates))
+		v1.HandleFunc("GET /places/{id}", controllers.IDHandlerFunc("id", placeController.GetByID))
+		v1.HandleFunc("POST /places", authz.Admin(idempotent.Wrap(controllers.HandlerFunc(placeController.Create))))
+
+		alerts := controllers.NewHTTPAlertController(engine.Alerts(), nil)
+		v1.HandleFunc("GET /alerts", controllers.HandlerFunc(alerts.List))
+		v1.HandleFunc("GET /alerts/active", controllers.HandlerFunc(alerts.GetActiveByCoordinates))
+		v1.HandleFunc("GET /alerts/stream", controllers.HandlerFunc(alerts.Stream))
+		v1.HandleFunc("DELETE /alerts/expired", authz.Admin(controllers.HandlerFunc(alerts.CleanupExpired)))
+		v1.HandleFunc("POST /alerts/cap", authz.Admin(controllers.HandlerFunc(alerts.IngestCAP)))
+
+		observations := controllers.NewHTTPObservationController(manager, engine.Stations(), engine.Observations(), ttlPolicy)
+		v1.HandleFunc("GET /stations/{station}", controllers.StringHandlerFunc("station", observations.GetStation))
+		v1.HandleFunc("GET /stations/{station}/observations", controllers.StringHandlerFunc("station", observations.GetObservations))
+		v1.HandleFunc("GET /stations/{station}/observations/latest", controllers.StringHandlerFunc("station", observations.GetLatestObservation))
+
+		// User-owned rows need an API key to read; the controllers then keep each user
+		// to their own, answering 404 for anyone else's
+		readOwn := func(next http.HandlerFunc) http.HandlerFunc {
+			return authz.Require(models.RoleReadOnly, next)
+		}
+
+		digests := controllers.NewHTTPDigestController(engine.Digests(), engine.Cities(), webhook.Destinations{AllowPrivate: cmd.Bool("allow-private-webhooks")})
+		v1.HandleFunc("POST /digests", authz.Write(idempotent.Wrap(controllers.HandlerFunc(digests.Create))))
+		v1.HandleFunc("GET /digests/{id}", readOwn(controllers.IDHandlerFunc("id", digests.Get)))
+		v1.HandleFunc("DELETE /digests/{id}", authz.Write(controllers.IDHandlerFunc("id", digests.Delete)))
+		v1.HandleFunc("GET /users/{id}/digests", readOwn(controllers.IDHandlerFunc("id", digests.ListByUser)))
+
+		subscriptions := controllers.NewHTTPAlertSubscriptionController(engine.AlertSubscriptions())
+		v1.HandleFunc("POST /alert-subscriptions", authz.Write(idempotent.Wrap(controllers.HandlerFunc(subscriptions.Create))))
+		v1.HandleFunc("GET /alert-subscriptions/{id}", readOwn(controllers.IDHandlerFunc("id", subscriptions.Get)))
+		v1.HandleFunc("DELETE /alert-subscriptions/{id}", authz.Write(controllers.IDHandlerFunc("id", subscriptions.Delete)))
+		v1.HandleFunc("GET /alert-subscriptions/unsubscribe/{token}", controllers.StringHandlerFunc("token", subscriptions.Unsubscribe))
+		v1.HandleFunc("POST /alert-subscriptions/unsubscribe/{token}", controllers.StringHandlerFunc("token", subscriptions.Unsubscribe))
+		v1.HandleFunc("GET /users/{id}/alert-subscriptions", readOwn(controllers.IDHandlerFunc("id", subscriptions.ListByUser)))
+
+		locations := controllers.NewHTTPLocationController(engine.UserLocations(), engine.Cities(), manager)
+		v1.HandleFunc("POST /locations", authz.Write(idempotent.Wrap(controllers.HandlerFunc(locations.Create))))
+		v1.HandleFunc("GET /locations/{id}", readOwn(controllers.IDHandlerFunc("id", locations.Get)))
+		v1.HandleFunc("PUT /locations/{id}", authz.Write(controllers.IDHandlerFunc("id", locations.Update)))
+		v1.HandleFunc("PUT /locations/{id}/default", authz.Write(controllers.IDHandlerFunc("id", locations.SetDefault)))
+		v1.HandleFunc("DELETE /locations/{id}", authz.Write(controllers.IDHandlerFunc("id", locations.Delete)))
+		v1.HandleFunc("GET /users/{id}/locations", readOwn(controllers.IDHandlerFunc("id", locations.ListByUser)))
+		v1.HandleFunc("GET /users/{id}/locations/weather", readOwn(controllers.IDHandlerFunc("id", locations.WeatherByUser)))
+
+		if config.ShareSecret == "" {
+			logger.Warn("Share links disabled: WEATHER_API_SHARE_SECRET is not set")
+		} else if signer, err := share.NewSigner(config.ShareSecret); err != nil {
+			logger.Warn("Share links disabled", "error", err)
+		} else {
+			shares := controllers.NewHTTPShareController(engine.Shares(), engine.Cities(), engine.Forecasts(), exports, signer)
+			v1.HandleFunc("POST /shares", authz.Write(controllers.HandlerFunc(shares.Create)))
+			v1.HandleFunc("DELETE /shares/{id}", authz.Write(controllers.IDHandlerFunc("id", shares.Revoke)))
+			v1.HandleFunc("GET /users/{id}/shares", readOwn(controllers.IDHandlerFunc("id", shares.ListByUser)))
+			v1.HandleFunc("DELETE /users/{id}/shares", authz.Write(controllers.IDHandlerFunc("id", shares.RevokeByUser)))
+			// Viewers follow the link without an API key, so it stays outside /v1
+			mux.HandleFunc("GET /share/{token}", controllers.StringHandlerFunc("token", shares.View))
+		}
+
+		aviation := controllers.NewHTTPAviationController(aviationProvider, engine.Aviation())
+		v1.HandleFunc("GET /aviation/{icao}", controllers.StringHandlerFunc("icao", aviation.GetByICAO))
+		v1.HandleFunc("GET /aviation/{icao}/metar", controllers.StringHandlerFunc("icao", aviation.GetMETARs))
+		v1.HandleFunc("GET /aviation/{icao}/taf", controllers.StringHandlerFunc("icao", aviation.GetTAF))
+
+		countries := controllers.NewHTTPCountryController(engine.Cities(), engine.Forecasts(), engine.Alerts())
+		v1.HandleFunc("GET /countries/{code}/summary", controllers.StringHandlerFunc("code", countries.GetSummary))
+
+		risk := controllers.NewHTTPRiskController(engine.Cities(), engine.Forecasts(), engine.Alerts())
+		v1.HandleFunc("GET /risk", controllers.HandlerFunc(risk.GetRisk))
+
+		graphQL := controllers.NewHTTPGraphQLController(engine.Cities(), engine.Places(), engine.Forecasts(), engine.Alerts())
+		mux.HandleFunc("GET /graphql", controllers.HandlerFunc(graphQL.Query))
+		mux.HandleFunc("POST /graphql", controllers.HandlerFunc(graphQL.Query))
+		mux.HandleFunc("GET /graphql/schema", controllers.HandlerFunc(graphQL.Schema))
+	}
+	api.Mount(mux)
+	if setup.mount != nil {
+		setup.mount(mux)
+	}
+	mux.Handle("GET /condition-check", apiversion.Deprecated(conditionCheck, apiversion.Lifecycle{
+		Deprecated: unversionedDeprecated,
+		Link:       "/v1/condition-check",
+	}))
+
+	adminHandler := admin.NewHandler(adminConfig)
+	mux.Handle(admin.Prefix, adminHandler)
+	mux.Handle(admin.Prefix+"/", adminHandler)
+	if config.AdminToken == "" {
+		logger.Warn("Admin UI disabled: WEATHER_API_ADMIN_TOKEN is not set", "path", admin.Prefix)
+	}
+
+	authorizedTiming := func(r *http.Request) bool {
+		return admin.Authorized(r, config.AdminToken)
+	}
+	// Stale responses are remembered inside authentication, so their keys can include
+	// the caller's preferred units and a failed API key lookup is never answered with one
+	authenticator := authz.NewAuthenticator(users, config.AdminToken)
+	authenticated := authenticator.Middleware(authz.Preferences(mux))
+	var handler http.Handler = timing.Middleware(authorizedTiming, authenticator.Middleware(authz.Preferences(degrade.Middleware(degradeConfig, mux))))
+	if cmd.Bool("compression") {
+		handler = compression.Middleware(compressionConfig, handler)
+	}
+
+	// Event streams skip compression and the stale-response fallback, which would hold
+	// events back or replay a finished stream, and end when shutdown begins so draining
+	// does not wait out their connections
+	streamsDone, closeStreams := context.WithCancel(context.Background())
+	defer closeStreams()
+	root := http.NewServeMux()
+	root.Handle("/", handler)
+	root.Handle("GET /v1/alerts/stream", timing.Middleware(authorizedTiming, endWith(streamsDone, authenticated)))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           tracing.Middleware(requestlog.Middleware(logger, root)),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	server.RegisterOnShutdown(closeStreams)
+	servers := []*http.Server{server}
+	serveErr := make(chan error, 3)
+	if certConfig.Enabled() {
+		tlsConfig, manager, err := certConfig.TLSConfig()
+		if err != nil {
+			return err
+		}
+		server.TLSConfig = tlsConfig
+		go func() {
+			serveErr <- server.ListenAndServeTLS("", "")
+		}()
+		logger.Info("Server listening", "address", addr, "tls", true, "autocert", manager != nil)
+
+		if redirectPort := cmd.String("redirect-port"); redirectPort != "" {
+			redirect := &http.Server{
+				Addr:              fmt.Sprintf("%s:%s", host, redirectPort),
+				Handler:           certs.RedirectHandler(port, manager),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			servers = append(servers, redirect)
+			go func() {
+				serveErr <- redirect.ListenAndServe()
+			}()
+			logger.Info("Redirecting HTTP to HTTPS", "address", redirect.Addr)
+		}
+	} else {
+		go func() {
+			serveErr <- server.ListenAndServe()
+		}()
+		logger.Info("Server listening", "address", addr)
+	}
+	var grpcServer *grpc.Server
+	if grpcPort := cmd.String("grpc-port"); grpcPort != "" {
+		if engine == nil {
+			logger.Warn("gRPC API disabled: it requires a database", "port", grpcPort)
+		} else {
+			options := []grpc.ServerOption{
+				grpc.StatsHandler(otelgrpc.NewServerHandler()),
+				grpc.ChainUnaryInterceptor(requestlog.UnaryServerInterceptor(logger)),
+				grpc.ChainStreamInterceptor(requestlog.StreamServerInterceptor(logger)),
+			}
+			if server.TLSConfig != nil {
+				options = append(options, grpc.Creds(credentials.NewTLS(server.TLSConfig.Clone())))
+			}
+			grpcServer = grpc.NewServer(options...)
+			weatherv1.RegisterWeatherServiceServer(grpcServer, controllers.NewGRPCServer(engine.Cities(), engine.Places(), engine.Forecasts(), engine.Alerts()))
+			grpcAddr := fmt.Sprintf("%s:%s", host, grpcPort)
+			go func() {
+				listener, err := net.Listen("tcp", grpcAddr)
+				if err != nil {
+					serveErr <- err
+					return
+				}
+				serveErr <- grpcServer.Serve(listener)
+			}()
+			logger.Info("gRPC server listening", "address", grpcAddr, "service", weatherv1.WeatherService_ServiceDesc.ServiceName, "tls", server.TLSConfig != nil)
+		}
+	}
+
+	select {
+	case err := <-serveErr:
+		for _, srv := range servers {
+			srv.Close()
+		}
+		if grpcServer != nil {
+			grpcServer.Stop()
+		}
+		return fmt.Errorf("server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	// Fail readiness first so load balancers stop routing here, then drain in-flight
+	// requests. A second signal skips the wait.
+	stop()
+	timeout := cmd.Duration("shutdown-timeout")
+	logger.Info("Shutting down, draining in-flight requests", "timeout", timeout)
+	probes.Drain()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	shutdownCtx, stopWait := signal.NotifyContext(shutdownCtx, os.Interrupt, syscall.SIGTERM)
+	defer stopWait()
+	for _, srv := range servers {
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			for _, srv := range servers {
+				srv.Close()
+			}
+			if grpcServer != nil {
+				grpcServer.Stop()
+			}
+			return fmt.Errorf("graceful shutdown incomplete: %w", err)
+		}
+	}
+	if grpcServer != nil {
+		if err := stopGRPC(shutdownCtx, grpcServer); err != nil {
+			return fmt.Errorf("graceful shutdown incomplete: %w", err)
+		}
+	}
+
+	logger.Info("Server stopped")
+	return nil
+}
+
+// stopGRPC stops server once its in-flight calls finish, cutting them off when ctx
+// ends first
+func stopGRPC(ctx context.Context, server *grpc.Server) error {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
+
+// endWith cancels the requests served by next when done ends, for long-lived
+// responses such as event streams
+func endWith(done context.Context, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stop := context.AfterFunc(done, cancel)
+		defer stop()
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
+}
+
+// newProviders creates the upstream providers, applying the base URL and timeout
+// overrides from the environment
+func newProviders(config *secrets.Config, logger *log.Logger) (*providers.ProviderManager, error) {
+	nws := providers.NewNWSProvider()
+	nws.UserAgent = config.NWSAgent
+	ec := providers.NewECWeatherProvider()
+	ec.UserAgent = config.NWSAgent
+	ec.Language = config.ECLanguage
+	tomorrowIO := providers.NewTomorrowIOProvider(config.TomorrowIOAPIKey)
+	tomorrowIO.UserAgent = config.NWSAgent
+	census := providers.NewCensusProvider()
+	mapbox := providers.NewMapboxProvider(config.MapboxAccessToken)
+	google := providers.NewGoogleProvider(config.GoogleGeocodingAPIKey)
+	google.Filter.Country = config.GoogleGeocodingCountry
+	if config.GoogleGeocodingBounds != "" {
+		bounds, err := providers.ParseGoogleBounds(config.GoogleGeocodingBounds)
+		if err != nil {
+			return nil, fmt.Errorf("GOOGLE_GEOCODING_BOUNDS: %w", err)
+		}
+		google.Filter.Bounds = bounds
+	}
+	airQuality := providers.NewOpenMeteoAirQualityProvider()
+
+	for prefix, configure := range map[string]func(providers.Endpoint){
+		providers.NWSEnvPrefix:        nws.Configure,
+		providers.ECEnvPrefix:         ec.Configure,
+		providers.TomorrowIOEnvPrefix: tomorrowIO.Configure,
+		providers.CensusEnvPrefix:     census.Configure,
+		providers.MapboxEnvPrefix:     mapbox.Configure,
+		providers.GoogleEnvPrefix:     google.Configure,
+		providers.AirQualityEnvPrefix: airQuality.Configure,
+	} {
+		endpoint, err := providers.LoadEndpoint(prefix)
+		if err != nil {
+			return nil, err
+		}
+		if !endpoint.IsZero() {
+			logger.Info("Provider endpoint overridden", "provider", prefix, "base_url", endpoint.BaseURL, "timeout", endpoint.Timeout)
+		}
+		configure(endpoint)
+	}
+
+	manager := providers.NewProviderManager()
+	manager.RegisterWeatherProvider(nws)
+	manager.RegisterWeatherProvider(ec)
+	if config.TomorrowIOAPIKey != "" {
+		manager.RegisterWeatherProvider(tomorrowIO)
+	}
+	manager.RegisterGeocodeProvider(census)
+	if config.MapboxAccessToken != "" {
+		manager.RegisterGeocodeProvider(mapbox)
+	}
+	if config.GoogleGeocodingAPIKey != "" {
+		manager.RegisterGeocodeProvider(google)
+	}
+	manager.RegisterAirQualityProvider(airQuality)
+	return manager, nil
+}
 
-	logger.Info("Server listening", "address", addr)
-	return http.ListenAndServe(addr, nil)
+// newAviationProvider creates the METAR and TAF provider, applying the ADDS base URL
+// and timeout overrides from the environment
+func newAviationProvider(config *secrets.Config, logger *log.Logger) (*providers.ADDSProvider, error) {
+	adds := providers.NewADDSProvider()
+	adds.UserAgent = config.NWSAgent
+	endpoint, err := providers.LoadEndpoint(providers.ADDSEnvPrefix)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.IsZero() {
+		logger.Info("Provider endpoint overridden", "provider", providers.ADDSEnvPrefix, "base_url", endpoint.BaseURL, "timeout", endpoint.Timeout)
+	}
+	adds.Configure(endpoint)
+	return adds, nil
+}
+
+// openSearch attaches the configured search index to engine, keeping it in sync through
+// hooks and rebuilding it in the background. Searches use SQL until the rebuild
+// completes, and entirely when the index cannot be opened.
+func openSearch(ctx context.Context, config search.Config, engine repo.Engine, hooks *repo.EngineHooks, logger *log.Logger) repo.Engine {
+	index, err := search.Open(ctx, config)
+	if err != nil {
+		logger.Warn("Search index unavailable, using SQL search", "backend", config.Backend, "error", err)
+		return engine
+	}
+	if index == nil {
+		return engine
+	}
+
+	indexer := search.NewIndexer(index, engine, config.Languages, func(err error) {
+		logger.Warn("Search index error", "backend", index.Name(), "error", err)
+	})
+	indexer.Register(hooks)
+	go func() {
+		start := time.Now()
+		if err := indexer.Rebuild(ctx); err != nil {
+			logger.Warn("Search index rebuild failed, using SQL search", "backend", index.Name(), "error", err)
+			return
+		}
+		logger.Info("Search index ready", "backend", index.Name(), "duration", time.Since(start))
+	}()
+	return search.NewEngine(engine, indexer)
+}
+
+// openMirror starts mirroring forecasts and observations to the configured time-series
+// database. It returns nil when none is configured or it cannot be reached, which
+// leaves the primary database unaffected.
+func openMirror(ctx context.Context, config tsdb.Config, hooks *repo.EngineHooks, logger *log.Logger) *tsdb.Mirror {
+	sink, err := tsdb.Open(ctx, config)
+	if err != nil {
+		logger.Warn("Time-series sink unavailable", "error", err)
+		return nil
+	}
+	if sink == nil {
+		return nil
+	}
+
+	mirror := tsdb.NewMirror(sink, config, func(err error) {
+		logger.Warn("Time-series write failed", "backend", sink.Name(), "error", err)
+	})
+	mirror.Register(hooks)
+	logger.Info("Mirroring forecasts and observations", "backend", sink.Name())
+	return mirror
+}
+
+// auditHooks records every repository write in log, with the request ID and caller of
+// the request that made it, and logs it at debug level
+func auditHooks(log *audit.Log) *repo.EngineHooks {
+	hooks := &repo.EngineHooks{}
+	hooks.Forecasts.Register(auditHook[repo.Forecast](log, "forecast"))
+	hooks.Cities.Register(auditHook[repo.City](log, "city"))
+	hooks.Places.Register(auditHook[repo.Place](log, "place"))
+	hooks.Alerts.Register(auditHook[repo.Alert](log, "alert"))
+	hooks.Aviation.Register(auditHook[repo.AviationReport](log, "aviation_report"))
+	return hooks
+}
+
+func auditHook[T any](log *audit.Log, entity string) repo.Hook[T] {
+	return repo.Hook[T]{
+		Name: "audit",
+		After: func(ctx context.Context, m repo.Mutation[T]) {
+			entry := audit.Entry{
+				Time:      time.Now().UTC().Format(time.RFC3339),
+				RequestID: requestlog.ID(ctx),
+				Entity:    entity,
+				Op:        string(m.Op),
+				ID:        m.ID,
+				Count:     m.Count,
+			}
+			if principal := authz.FromContext(ctx); principal != nil {
+				entry.Actor, entry.Role = principal.Username, principal.Role
+			}
+			log.Record(entry)
+			requestlog.Logger(ctx).Debug("Repository write", "entity", entity, "op", m.Op, "id", m.ID, "count", m.Count, "actor", entry.Actor)
+		},
+	}
+}
+
+// newScheduler registers the background jobs on engine
+func newScheduler(cmd *cli.Command, engine repo.Engine, partitioner repo.ForecastPartitioner, shared repo.SharedStore, manager *providers.ProviderManager, notifyConfig notify.Config, logger *log.Logger) *jobs.Scheduler {
+	retention := int(cmd.Int("retention-days"))
+	scheduler := jobs.NewScheduler(engine.JobRuns(), func(err error) {
+		logger.Warn("Job scheduler error", "error", err)
+	})
+	scheduler.Register(jobs.ForecastIngestion(engine.Cities(), engine.Forecasts(), manager, int(cmd.Int("forecast-days")), cmd.Duration("ingest-interval")))
+	scheduler.Register(jobs.ForecastRetention(engine.Forecasts(), retention, cleanupInterval))
+	if partitioner != nil {
+		scheduler.Register(jobs.ForecastPartitions(partitioner, partitionMonthsAhead, cleanupInterval))
+	}
+	scheduler.Register(jobs.AviationRetention(engine.Aviation(), retention, cleanupInterval))
+	scheduler.Register(jobs.ObservationRetention(engine.Observations(), retention, cleanupInterval))
+	scheduler.Register(jobs.AirQualityRetention(engine.AirQuality(), retention, cleanupInterval))
+	scheduler.Register(jobs.AlertCleanup(engine.Alerts(), cleanupInterval))
+	scheduler.Register(jobs.ShareCleanup(engine.Shares(), cleanupInterval))
+	if shared != nil {
+		scheduler.Register(jobs.KVCleanup(shared, cleanupInterval))
+	}
+	scheduler.Register(jobs.DigestDelivery(engine.Digests(), engine.Cities(), engine.Forecasts(), webhook.NewClient(webhook.Destinations{AllowPrivate: cmd.Bool("allow-private-webhooks")}), digestInterval))
+	if notifyConfig.Enabled() {
+		scheduler.Register(jobs.AlertNotifications(engine.AlertSubscriptions(), notify.NewSMTPSender(notifyConfig), notifyConfig, digestInterval))
+	}
+	return scheduler
 }
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/urfave/cli/v3"
+
+	"stormlightlabs.org/weather_api/internal/authz"
+	"stormlightlabs.org/weather_api/internal/demo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/secrets"
+	"stormlightlabs.org/weather_api/internal/share"
+)
+
+// testAdminToken is the admin token of the servers started by serveTestAPI
+const testAdminToken = "test-admin-token"
+
+// serveTestAPI runs the API on a free local port with in-memory file storage and the
+// demo providers until the test ends, returning its base URL. prepare, when set, runs
+// on the storage engine before serving; args are extra server flags.
+func serveTestAPI(t *testing.T, prepare func(context.Context, repo.Engine) error, args ...string) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	listener.Close()
+
+	cmd := &cli.Command{
+		Name:  "test",
+		Flags: serverFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			return runServer(ctx, cmd, log.New(io.Discard), serverSetup{
+				config: &secrets.Config{AdminToken: testAdminToken, ShareSecret: strings.Repeat("s", share.MinSecretLength), StorageEngine: repo.EngineFile},
+				providers: func(*secrets.Config, *log.Logger) (*providers.ProviderManager, error) {
+					return demo.NewProviders()
+				},
+				prepare: prepare,
+			})
+		},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Run(ctx, append([]string{"test", "--host", "127.0.0.1", "--port", port, "--ingest-interval", "0", "--export-dir", t.TempDir()}, args...))
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	base := "http://127.0.0.1:" + port
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		select {
+		case err := <-done:
+			t.Fatalf("Server stopped: %v", err)
+		default:
+		}
+		if resp, err := http.Get(base + "/healthz"); err == nil {
+			resp.Body.Close()
+			return base
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Server did not start")
+		}
+	}
+}
+
+// callTestAPI sends a request with an optional bearer token and JSON body, returning
+// the response status
+func callTestAPI(t *testing.T, method, url, token, body string) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestServerRoutes(t *testing.T) {
+	base := serveTestAPI(t, nil)
+
+	tests := []struct {
+		method, path, token, body string
+		want                      int
+	}{
+		{"POST", "/v1/forecasts/bulk", "", `[]`, http.StatusUnauthorized},
+		{"POST", "/v1/forecasts/bulk", testAdminToken, `[]`, http.StatusBadRequest},
+		{"POST", "/v1/forecasts/bulk", testAdminToken, `[{"city_id": 1}]`, http.StatusUnprocessableEntity},
+		{"POST", "/v1/exports", "", `{"format": "csv"}`, http.StatusUnauthorized},
+		{"POST", "/v1/exports", testAdminToken, `{"format": "csv"}`, http.StatusAccepted},
+		{"DELETE", "/v1/forecasts?city_id=1", "", "", http.StatusUnauthorized},
+		{"DELETE", "/v1/forecasts", testAdminToken, "", http.StatusBadRequest},
+		{"DELETE", "/v1/forecasts?city_id=1", testAdminToken, "", http.StatusOK},
+		{"GET", "/v1/forecasts?embed=city&cursor=", "", "", http.StatusOK},
+		{"GET", "/v1/forecasts?embed=station", "", "", http.StatusBadRequest},
+		{"GET", "/v1/forecasts/999", "", "", http.StatusNotFound},
+		{"GET", "/v1/forecasts/range?start_time=2024-01-01T00:00:00Z&end_time=2024-01-02T00:00:00Z", "", "", http.StatusOK},
+		{"GET", "/v1/forecasts/range?start_time=2024-01-02T00:00:00Z&end_time=2024-01-01T00:00:00Z", "", "", http.StatusBadRequest},
+		{"GET", "/v1/cities/1/forecasts?embed=city", "", "", http.StatusOK},
+		{"GET", "/v1/cities/1/forecasts/latest", "", "", http.StatusNotFound},
+		{"GET", "/v1/cities", "", "", http.StatusOK},
+		{"GET", "/v1/cities/search", "", "", http.StatusBadRequest},
+		{"GET", "/v1/cities/nearby?lat=41.88&lon=-87.63", "", "", http.StatusOK},
+		{"GET", "/v1/cities/999", "", "", http.StatusNotFound},
+		{"GET", "/v1/places", "", "", http.StatusOK},
+		{"GET", "/v1/places/search?q=Chicago", "", "", http.StatusOK},
+		{"GET", "/v1/places/nearby?lat=41.88&lon=-87.63", "", "", http.StatusOK},
+		{"GET", "/v1/places/999", "", "", http.StatusNotFound},
+		{"GET", "/v1/exports/0123456789abcdef0123456789abcdef", "", "", http.StatusNotFound},
+		{"GET", "/v1/exports/0123456789abcdef0123456789abcdef/download", "", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if got := callTestAPI(t, tt.method, base+tt.path, tt.token, tt.body); got != tt.want {
+			t.Errorf("%s %s (token %q): expected %d, got %d", tt.method, tt.path, tt.token, tt.want, got)
+		}
+	}
+}
+
+func TestServerAlertStream(t *testing.T) {
+	base := serveTestAPI(t, nil, "--stale-routes", "/v1/alerts/", "--compression-min-size", "0")
+
+	req, _ := http.NewRequest("GET", base+"/v1/alerts/stream?city_id=1", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	client := &http.Client{Transport: &http.Transport{DisableCompression: true}}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" || resp.Header.Get("Vary") != "" {
+		t.Fatalf("Expected an event stream outside the compression middleware, got %d %v", resp.StatusCode, resp.Header)
+	}
+
+	// The reconnection delay is flushed at once rather than held in a buffer
+	line := make(chan string, 1)
+	go func() {
+		first, _ := bufio.NewReader(resp.Body).ReadString('\n')
+		line <- first
+	}()
+	select {
+	case first := <-line:
+		if !strings.HasPrefix(first, "retry:") {
+			t.Errorf("Expected the retry field first, got %q", first)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the stream to start without buffering")
+	}
+}
+
+// createTestUsers stores a user per API key, with the user role, in key order
+func createTestUsers(keys ...string) func(context.Context, repo.Engine) error {
+	return func(ctx context.Context, engine repo.Engine) error {
+		for _, key := range keys {
+			user := &repo.User{Username: key, Email: key + "@example.com", APIKeyHash: authz.HashAPIKey(key), Role: models.RoleUser, IsActive: true}
+			if err := engine.Users().Create(ctx, user); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func TestServerOwnership(t *testing.T) {
+	base := serveTestAPI(t, createTestUsers("alice-key", "bob-key"))
+
+	// The body cannot save a location for someone else
+	if got := callTestAPI(t, "POST", base+"/v1/locations", "alice-key", `{"user_id": 2, "name": "Home", "latitude": 41.88, "longitude": -87.63}`); got != http.StatusCreated {
+		t.Fatalf("Expected alice to save a location, got %d", got)
+	}
+	if got := callTestAPI(t, "POST", base+"/v1/shares", "alice-key", `{"user_id": 2, "resource": "export", "export_id": "0123456789abcdef0123456789abcdef"}`); got != http.StatusCreated {
+		t.Fatalf("Expected alice to share an export, got %d", got)
+	}
+
+	tests := []struct {
+		method, path, token, body string
+		want                      int
+	}{
+		{"GET", "/v1/locations/1", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/locations/1", "bob-key", "", http.StatusNotFound},
+		{"PUT", "/v1/locations/1", "bob-key", `{"name": "Mine", "latitude": 1, "longitude": 1}`, http.StatusNotFound},
+		{"PUT", "/v1/locations/1/default", "bob-key", "", http.StatusNotFound},
+		{"DELETE", "/v1/locations/1", "bob-key", "", http.StatusNotFound},
+		{"GET", "/v1/users/1/locations", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/users/1/locations", "bob-key", "", http.StatusNotFound},
+		{"GET", "/v1/users/1/locations/weather", "bob-key", "", http.StatusNotFound},
+		{"GET", "/v1/users/2/locations", "bob-key", "", http.StatusOK},
+		{"GET", "/v1/users/1/digests", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/users/1/digests", "bob-key", "", http.StatusNotFound},
+		{"GET", "/v1/digests/1", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/users/1/alert-subscriptions", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/users/1/alert-subscriptions", "bob-key", "", http.StatusNotFound},
+		{"GET", "/v1/alert-subscriptions/1", "", "", http.StatusUnauthorized},
+		{"POST", "/v1/shares", "", `{"resource": "forecast", "city_id": 1}`, http.StatusUnauthorized},
+		{"GET", "/v1/users/1/shares", "", "", http.StatusUnauthorized},
+		{"GET", "/v1/users/1/shares", "bob-key", "", http.StatusNotFound},
+		{"DELETE", "/v1/shares/1", "bob-key", "", http.StatusNotFound},
+		{"DELETE", "/v1/users/1/shares", "bob-key", "", http.StatusNotFound},
+		{"GET", "/share/not-a-token", "", "", http.StatusNotFound},
+		{"GET", "/v1/users/1/shares", "alice-key", "", http.StatusOK},
+		{"DELETE", "/v1/shares/1", "alice-key", "", http.StatusOK},
+		{"GET", "/v1/locations/1", "alice-key", "", http.StatusOK},
+		{"GET", "/v1/users/1/locations", testAdminToken, "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		if got := callTestAPI(t, tt.method, base+tt.path, tt.token, tt.body); got != tt.want {
+			t.Errorf("%s %s (token %q): expected %d, got %d", tt.method, tt.path, tt.token, tt.want, got)
+		}
+	}
+}
+
+func TestServerShareLinks(t *testing.T) {
+	base := serveTestAPI(t, func(ctx context.Context, engine repo.Engine) error {
+		city := &repo.City{Name: "Chicago", Country: "United States", CountryCode: "US", Latitude: 41.88, Longitude: -87.63, Timezone: "America/Chicago", IsActive: true}
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			return err
+		}
+		return createTestUsers("alice-key")(ctx, engine)
+	})
+
+	req, _ := http.NewRequest("POST", base+"/v1/shares", strings.NewReader(`{"resource": "forecast", "city_id": 1}`))
+	req.Header.Set("Authorization", "Bearer alice-key")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created struct {
+		Data struct {
+			URL string `json:"url"`
+		} `json:"data"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&created)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || err != nil || !strings.HasPrefix(created.Data.URL, "/share/") {
+		t.Fatalf("Expected a share link, got %d (%v)", resp.StatusCode, err)
+	}
+
+	resp, err = http.Get(base + created.Data.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Cache-Control") != "no-store" {
+		t.Errorf("Expected anyone to view the link, got %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestServerUnitPreference(t *testing.T) {
+	base := serveTestAPI(t, func(ctx context.Context, engine repo.Engine) error {
+		city := &repo.City{Name: "Chicago", CountryCode: "US", Latitude: 41.88, Longitude: -87.63, Timezone: "America/Chicago", IsActive: true}
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			return err
+		}
+		forecast := &repo.Forecast{CityID: city.ID, ForecastTime: "2024-01-15T12:00:00Z", ValidTime: "2024-01-15T12:00:00Z", Temperature: 20, SourceProvider: "test"}
+		if err := engine.Forecasts().Create(ctx, forecast); err != nil {
+			return err
+		}
+		imperial := &repo.User{Username: "imperial", APIKeyHash: authz.HashAPIKey("imperial-key"), Role: models.RoleUser, IsActive: true, PreferredUnits: "imperial"}
+		if err := engine.Users().Create(ctx, imperial); err != nil {
+			return err
+		}
+		return createTestUsers("metric-key")(ctx, engine)
+	})
+
+	tests := []struct {
+		token, language, query, want string
+	}{
+		{"imperial-key", "", "", "imperial"},
+		{"imperial-key", "", "?units=metric", "metric"},
+		{"metric-key", "en-US", "", "metric"},
+		{"", "en-US", "", "imperial"},
+		{"", "", "", "metric"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", base+"/v1/cities/1/forecasts/latest"+tt.query, nil)
+		if tt.token != "" {
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		if tt.language != "" {
+			req.Header.Set("Accept-Language", tt.language)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var body struct {
+			Data struct {
+				Temperature float64 `json:"temperature"`
+				Units       string  `json:"units"`
+			} `json:"data"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if err != nil || body.Data.Units != tt.want {
+			t.Errorf("Key %q, language %q, query %q: expected %s units, got %d %+v (%v)", tt.token, tt.language, tt.query, tt.want, resp.StatusCode, body.Data, err)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+// Package compression compresses HTTP responses for clients that accept it.
+//
+// Only responses whose Content-Type is listed in Config.ContentTypes (JSON, CSV and
+// GeoJSON by default) and whose body reaches Config.MinSize are compressed: small
+// bodies grow once framing is added, and images or event streams either are already
+// compressed or must reach the client unbuffered.
+package compression
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported encodings, in the order preferred when a client accepts several equally
+const (
+	Zstd    = "zstd"
+	Gzip    = "gzip"
+	Deflate = "deflate"
+)
+
+// Config controls which responses are compressed and how
+type Config struct {
+	// Encodings offered to clients, most preferred first
+	Encodings []string
+
+	// Level is the gzip/deflate level (1-9); zero uses the default level
+	Level int
+
+	// MinSize is the smallest body worth compressing, in bytes
+	MinSize int
+
+	// ContentTypes are the media types eligible for compression
+	ContentTypes []string
+}
+
+// DefaultConfig compresses JSON, CSV and GeoJSON bodies of 1 KiB or more with gzip or
+// deflate
+func DefaultConfig() Config {
+	return Config{
+		Encodings: []string{Gzip, Deflate},
+		MinSize:   1024,
+		ContentTypes: []string{
+			"application/json",
+			"application/geo+json",
+			"application/problem+json",
+			"application/x-ndjson",
+			"text/csv",
+		},
+	}
+}
+
+// Validate reports unsupported encodings and out of range levels
+func (c Config) Validate() error {
+	for _, encoding := range c.Encodings {
+		if !slices.Contains([]string{Zstd, Gzip, Deflate}, encoding) {
+			return fmt.Errorf("unsupported compression encoding %q (supported: %s, %s, %s)", encoding, Zstd, Gzip, Deflate)
+		}
+	}
+	if c.Level < 0 || c.Level > 9 {
+		return fmt.Errorf("compression level must be between 1 and 9, got %d", c.Level)
+	}
+	if c.MinSize < 0 {
+		return fmt.Errorf("compression minimum size must not be negative, got %d", c.MinSize)
+	}
+	return nil
+}
+
+// encoder is a pooled compressor for one encoding
+type encoder interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+	Flush() error
+}
+
+// Middleware compresses the responses of next according to config
+func Middleware(config Config, next http.Handler) http.Handler {
+	level := config.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	pools := make(map[string]*sync.Pool, len(config.Encodings))
+	for _, encoding := range config.Encodings {
+		pools[encoding] = &sync.Pool{New: func() any {
+			switch encoding {
+			case Zstd:
+				w, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1))
+				return w
+			case Deflate:
+				w, _ := flate.NewWriter(nil, level)
+				return w
+			default:
+				w, _ := gzip.NewWriterLevel(nil, level)
+				return w
+			}
+		}}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := Negotiate(r.Header.Get("Accept-Encoding"), config.Encodings)
+		if encoding == "" || r.Method == http.MethodHead || r.Header.Get("Range") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, config: &config, encoding: encoding, pool: pools[encoding]}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// Negotiate picks the encoding to use for an Accept-Encoding header: the offered
+// encoding with the highest quality, ties going to the earlier offer. It returns ""
+// when nothing offered is acceptable.
+func Negotiate(header string, offered []string) string {
+	best, bestQ := "", 0.0
+	wildcard := -1.0
+	qualities := make(map[string]float64)
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if name == "*" {
+			wildcard = q
+		} else if name != "" {
+			qualities[name] = q
+		}
+	}
+
+	for _, encoding := range offered {
+		q, ok := qualities[encoding]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = encoding, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a body until it knows whether compressing is
+// worthwhile, then either streams it through a pooled encoder or writes it as is
+type compressWriter struct {
+	http.ResponseWriter
+	config   *Config
+	encoding string
+	pool     *sync.Pool
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     encoder // nil when passing through
+}
+
+// WriteHeader delays the status until the encoding is decided, since the headers
+// depend on it
+func (c *compressWriter) WriteHeader(status int) {
+	if c.status == 0 && !c.decided {
+		c.status = status
+		if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+			c.decide(false)
+		}
+	}
+}
+
+func (c *compressWriter) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	if !c.decided {
+		if !c.eligible() {
+			c.decide(false)
+		} else if len(c.buf)+len(p) < c.config.MinSize {
+			c.buf = append(c.buf, p...)
+			return len(p), nil
+		} else {
+			c.decide(true)
+		}
+	}
+	if c.enc != nil {
+		return c.enc.Write(p)
+	}
+	return c.ResponseWriter.Write(p)
+}
+
+// Flush forces a decision so streaming handlers are not held back by buffering
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		c.decide(c.status != 0 && c.eligible())
+	}
+	if c.enc != nil {
+		_ = c.enc.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (c *compressWriter) Unwrap() http.ResponseWriter { return c.ResponseWriter }
+
+// eligible reports whether the response headers allow compression
+func (c *compressWriter) eligible() bool {
+	header := c.Header()
+	if header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+	return err == nil && slices.Contains(c.config.ContentTypes, mediaType)
+}
+
+// decide writes the headers, starting the encoder when compress is set, then writes
+// anything buffered so far
+func (c *compressWriter) decide(compress bool) {
+	c.decided = true
+	if compress {
+		header := c.Header()
+		header.Set("Content-Encoding", c.encoding)
+		header.Del("Content-Length")
+		// A strong validator names the exact bytes, which compression changes
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+		c.enc = c.pool.Get().(encoder)
+		c.enc.Reset(c.ResponseWriter)
+	}
+	if c.status != 0 {
+		c.ResponseWriter.WriteHeader(c.status)
+	}
+	if len(c.buf) > 0 {
+		buf := c.buf
+		c.buf = nil
+		if c.enc != nil {
+			_, _ = c.enc.Write(buf)
+		} else {
+			_, _ = c.ResponseWriter.Write(buf)
+		}
+	}
+}
+
+// close flushes a body that never reached MinSize uncompressed and finishes the
+// encoder
+func (c *compressWriter) close() {
+	if !c.decided {
+		c.decide(false)
+	}
+	if c.enc != nil {
+		_ = c.enc.Close()
+		c.pool.Put(c.enc)
+		c.enc = nil
+	}
+}
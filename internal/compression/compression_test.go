@@ -0,0 +1,132 @@
+package compression
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+func TestNegotiate(t *testing.T) {
+	offered := []string{Zstd, Gzip, Deflate}
+	tests := []struct {
+		header, want string
+	}{
+		{"", ""},
+		{"gzip, deflate, br", Gzip},
+		{"deflate;q=0.5, gzip;q=0.8", Gzip},
+		{"zstd, gzip", Zstd},
+		{"gzip;q=0, *", Zstd},
+		{"*;q=0", ""},
+		{"br", ""},
+		{"GZIP;q=1.0", Gzip},
+	}
+
+	for _, test := range tests {
+		if got := Negotiate(test.header, offered); got != test.want {
+			t.Errorf("Negotiate(%q) = %q, expected %q", test.header, got, test.want)
+		}
+	}
+	if got := Negotiate("zstd", []string{Gzip}); got != "" {
+		t.Errorf("Expected encodings that are not offered to be ignored, got %q", got)
+	}
+}
+
+func serve(t *testing.T, config Config, contentType, body, acceptEncoding string) *httptest.ResponseRecorder {
+	t.Helper()
+	handler := Middleware(config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusCreated)
+		// Written in pieces so buffering across writes is exercised
+		for chunk := range strings.SplitSeq(body, "\n") {
+			_, _ = io.WriteString(w, chunk+"\n")
+		}
+	}))
+	req := httptest.NewRequest("GET", "/forecasts", nil)
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestMiddleware(t *testing.T) {
+	config := DefaultConfig()
+	config.Encodings = []string{Zstd, Gzip, Deflate}
+	large := strings.Repeat(`{"temperature":21.5,"humidity":40}`+"\n", 100)
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		Gzip:    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		Deflate: func(r io.Reader) (io.Reader, error) { return flate.NewReader(r), nil },
+		Zstd:    func(r io.Reader) (io.Reader, error) { return zstd.NewReader(r) },
+	}
+	for encoding, decode := range decoders {
+		t.Run(encoding, func(t *testing.T) {
+			w := serve(t, config, "application/json; charset=utf-8", large, encoding)
+			if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != encoding || w.Header().Get("ETag") != `W/"v1"` {
+				t.Fatalf("Expected a %s response, got %d %v", encoding, w.Code, w.Header())
+			}
+			if w.Body.Len() >= len(large) {
+				t.Errorf("Expected the body to shrink, got %d bytes from %d", w.Body.Len(), len(large))
+			}
+			reader, err := decode(w.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if decoded, _ := io.ReadAll(reader); string(decoded) != large+"\n" {
+				t.Errorf("Expected the body to round trip, got %d bytes", len(decoded))
+			}
+		})
+	}
+
+	skipped := []struct {
+		name, contentType, body, acceptEncoding string
+	}{
+		{"small body", "application/json", `{"ok":true}`, "gzip"},
+		{"ineligible type", "image/png", large, "gzip"},
+		{"identity client", "text/csv", large, ""},
+	}
+	for _, test := range skipped {
+		t.Run(test.name, func(t *testing.T) {
+			w := serve(t, config, test.contentType, test.body, test.acceptEncoding)
+			if w.Code != http.StatusCreated || w.Header().Get("Content-Encoding") != "" || w.Body.String() != test.body+"\n" {
+				t.Errorf("Expected the body to pass through, got %d %v", w.Code, w.Header())
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Expected Vary on every response, got %q", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestMiddleware_Flush(t *testing.T) {
+	handler := Middleware(DefaultConfig(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = io.WriteString(w, `{"id":1}`+"\n")
+		w.(http.Flusher).Flush()
+	}))
+	req := httptest.NewRequest("GET", "/forecasts/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if !w.Flushed || w.Header().Get("Content-Encoding") != Gzip {
+		t.Errorf("Expected a flushed stream to start compressing below the minimum size, got %v", w.Header())
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("Expected the defaults to be valid, got %v", err)
+	}
+	for _, config := range []Config{{Encodings: []string{"br"}}, {Level: 10}, {MinSize: -1}} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
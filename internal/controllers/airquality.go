@@ -0,0 +1,159 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/ttl"
+)
+
+// airQualityPlaces rounds coordinates (about 1 km, well inside the model grid) so
+// nearby requests share a reading
+const airQualityPlaces = 2
+
+// AirQualityController handles air quality requests
+type AirQualityController interface {
+	// GetAirQuality handles requests for the current air quality at a point
+	GetAirQuality(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// AirQuality represents an air quality reading for controllers, with the US EPA
+// category of its AQI. Pollutants the provider did not report are null.
+type AirQuality struct {
+	Latitude       float64  `json:"latitude"`
+	Longitude      float64  `json:"longitude"`
+	SourceProvider string   `json:"source_provider"`
+	ObservedAt     string   `json:"observed_at"`
+	AQI            int      `json:"aqi"`
+	Category       string   `json:"category"`
+	Color          string   `json:"color"`
+	HealthGuidance string   `json:"health_guidance"`
+	PM25           *float64 `json:"pm2_5"` // µg/m³
+	PM10           *float64 `json:"pm10"`  // µg/m³
+	Ozone          *float64 `json:"ozone"` // µg/m³
+}
+
+// HTTPAirQualityController implements AirQualityController for HTTP requests
+type HTTPAirQualityController struct {
+	providers *providers.ProviderManager
+	readings  repo.AirQualityRepository
+	policy    *ttl.Policy
+}
+
+// NewHTTPAirQualityController creates a new HTTP air quality controller.
+//
+// Readings come from the first registered air quality provider. When readings is
+// non-nil they are stored and served from storage for the policy's current conditions
+// lifetime (ttl.Default when policy is nil), and when the provider fails the last
+// stored reading is returned instead.
+func NewHTTPAirQualityController(manager *providers.ProviderManager, readings repo.AirQualityRepository, policy *ttl.Policy) AirQualityController {
+	if policy == nil {
+		policy = ttl.Default()
+	}
+	return &HTTPAirQualityController{providers: manager, readings: readings, policy: policy}
+}
+
+// GetAirQuality handles GET /air-quality?lat=&lon= requests
+func (c *HTTPAirQualityController) GetAirQuality(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	lat, lon = geo.Round(lat, airQualityPlaces), geo.Round(lon, airQualityPlaces)
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+
+	reading, err := c.latest(ctx, lat, lon)
+	if err != nil {
+		return writeProviderError(w, "Failed to retrieve air quality", err)
+	}
+
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(c.policy.Current().Seconds())))
+	return writeSuccess(w, http.StatusOK, fromRepoAirQuality(reading), "")
+}
+
+// latest returns the newest reading at a point, refreshing it from the provider when
+// the stored copy is missing or older than the current conditions lifetime
+func (c *HTTPAirQualityController) latest(ctx context.Context, lat, lon float64) (*repo.AirQuality, error) {
+	var stored *repo.AirQuality
+	if c.readings != nil {
+		if reading, err := c.readings.GetLatest(ctx, lat, lon); err == nil {
+			stored = reading
+		}
+	}
+	if stored != nil && time.Since(parseRepoTime(stored.UpdatedAt)) < c.policy.Current() {
+		return stored, nil
+	}
+
+	reading, err := c.fetch(ctx, lat, lon)
+	if err != nil {
+		if stored != nil {
+			return stored, nil
+		}
+		return nil, err
+	}
+	return reading, nil
+}
+
+// fetch retrieves and stores the current reading at a point. Storage failures are not
+// fatal: the unsaved copy is returned so the request can still be answered.
+func (c *HTTPAirQualityController) fetch(ctx context.Context, lat, lon float64) (*repo.AirQuality, error) {
+	var provider providers.AirQualityProvider
+	if c.providers != nil {
+		if registered := c.providers.GetAirQualityProviders(); len(registered) > 0 {
+			provider = registered[0]
+		}
+	}
+	if provider == nil {
+		return nil, fmt.Errorf("%w: no air quality provider is registered", providers.ErrUnsupportedRegion)
+	}
+
+	fetched, err := provider.GetAirQuality(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+	reading := toRepoAirQuality(fetched)
+	if c.readings != nil {
+		_ = c.readings.Upsert(ctx, reading)
+	}
+	return reading, nil
+}
+
+func toRepoAirQuality(a *models.AirQuality) *repo.AirQuality {
+	return &repo.AirQuality{
+		ID:             a.ID,
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		SourceProvider: a.SourceProvider,
+		ObservedAt:     formatTime(a.ObservedAt),
+		AQI:            a.AQI,
+		PM25:           a.PM25,
+		PM10:           a.PM10,
+		Ozone:          a.Ozone,
+	}
+}
+
+func fromRepoAirQuality(a *repo.AirQuality) *AirQuality {
+	category := models.CategorizeAQI(a.AQI)
+	return &AirQuality{
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		SourceProvider: a.SourceProvider,
+		ObservedAt:     a.ObservedAt,
+		AQI:            a.AQI,
+		Category:       category.Name,
+		Color:          category.Color,
+		HealthGuidance: category.Guidance,
+		PM25:           a.PM25,
+		PM10:           a.PM10,
+		Ozone:          a.Ozone,
+	}
+}
@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+type stubAirQualityProvider struct {
+	err   error
+	calls int
+}
+
+func (s *stubAirQualityProvider) GetName() string { return "Open-Meteo" }
+
+func (s *stubAirQualityProvider) GetAirQuality(ctx context.Context, lat, lon float64) (*models.AirQuality, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	pm25 := 38.2
+	return &models.AirQuality{
+		Latitude:       lat,
+		Longitude:      lon,
+		SourceProvider: s.GetName(),
+		ObservedAt:     time.Now().Truncate(time.Hour),
+		AQI:            108,
+		PM25:           &pm25,
+	}, nil
+}
+
+func TestHTTPAirQualityController(t *testing.T) {
+	get := func(controller AirQualityController, query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		if err := controller.GetAirQuality(context.Background(), w, httptest.NewRequest("GET", "/air-quality?"+query, nil)); err != nil {
+			t.Fatalf("GetAirQuality failed: %v", err)
+		}
+		return w
+	}
+
+	t.Run("reading is fetched, stored and then served", func(t *testing.T) {
+		engine, err := repo.OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		defer engine.Close()
+		provider := &stubAirQualityProvider{}
+		pm := providers.NewProviderManager()
+		pm.RegisterAirQualityProvider(provider)
+		controller := NewHTTPAirQualityController(pm, engine.AirQuality(), nil)
+
+		for _, query := range []string{"lat=40.7128&lon=-74.0060", "lat=40.714&lon=-74.008"} {
+			w := get(controller, query)
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response struct {
+				Data AirQuality `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Data.AQI != 108 || response.Data.Category != "Unhealthy for Sensitive Groups" || response.Data.HealthGuidance == "" {
+				t.Errorf("Unexpected reading %+v", response.Data)
+			}
+			if response.Data.Latitude != 40.71 || response.Data.Longitude != -74.01 {
+				t.Errorf("Expected the rounded point, got %v,%v", response.Data.Latitude, response.Data.Longitude)
+			}
+		}
+		if provider.calls != 1 {
+			t.Errorf("Expected nearby requests to share the stored reading, got %d provider calls", provider.calls)
+		}
+	})
+
+	t.Run("provider errors", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterAirQualityProvider(&stubAirQualityProvider{err: providers.ErrUpstreamUnavailable})
+		if w := get(NewHTTPAirQualityController(pm, nil, nil), "lat=40.71&lon=-74.01"); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected status 503, got %d", w.Code)
+		}
+		if w := get(NewHTTPAirQualityController(providers.NewProviderManager(), nil, nil), "lat=40.71&lon=-74.01"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422 without a provider, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects invalid coordinates", func(t *testing.T) {
+		controller := NewHTTPAirQualityController(providers.NewProviderManager(), nil, nil)
+		for _, query := range []string{"lat=91&lon=0", "lon=0", "lat=x&lon=0"} {
+			if w := get(controller, query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %q, got %d", query, w.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// maxPointAlerts bounds the alerts GET /alerts?lat=&lon= pages through; more than a
+// few dozen in effect at one point is already exceptional
+const maxPointAlerts = 500
+
+// HTTPAlertController implements AlertController for HTTP requests
+type HTTPAlertController struct {
+	repo              repo.AlertRepository
+	broker            *AlertBroker
+	heartbeatInterval time.Duration
+}
+
+// NewHTTPAlertController creates a new HTTP alert controller.
+//
+// Alerts stored through the controller are published to broker for the live stream;
+// a nil broker gives the controller a private one.
+func NewHTTPAlertController(repo repo.AlertRepository, broker *AlertBroker) AlertController {
+	if broker == nil {
+		broker = NewAlertBroker()
+	}
+	return &HTTPAlertController{repo: repo, broker: broker, heartbeatInterval: heartbeatInterval}
+}
+
+// Create handles POST /alerts requests.
+//
+// Alerts are deduplicated by (source_provider, provider_alert_id): posting an alert that
+// already exists refreshes the stored copy instead of inserting a new row.
+func (c *HTTPAlertController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var alert Alert
+	if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+
+	if errs := alert.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	repoAlert := toRepoAlert(&alert)
+	if err := c.repo.Upsert(ctx, repoAlert); err != nil {
+		return writeRepoError(w, err, "Alert", "Failed to store alert")
+	}
+
+	response := fromRepoAlert(repoAlert)
+	c.broker.Publish(response)
+	return writeCommitted(w, http.StatusCreated, response, "Alert stored successfully")
+}
+
+// GetByID handles GET /alerts/{id} requests
+func (c *HTTPAlertController) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	alert, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return writeRepoError(w, err, "Alert", "Failed to retrieve alert")
+	}
+
+	response := fromRepoAlert(alert)
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// Update handles PUT /alerts/{id} requests
+func (c *HTTPAlertController) Update(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	var alert Alert
+	if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+
+	if errs := alert.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	alert.ID = id
+	repoAlert := toRepoAlert(&alert)
+	if err := c.repo.Update(ctx, repoAlert); err != nil {
+		return writeRepoError(w, err, "Alert", "Failed to update alert")
+	}
+
+	response := fromRepoAlert(repoAlert)
+	return writeCommitted(w, http.StatusOK, response, "Alert updated successfully")
+}
+
+// Delete handles DELETE /alerts/{id} requests
+func (c *HTTPAlertController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if err := c.repo.Delete(ctx, id); err != nil {
+		return writeRepoError(w, err, "Alert", "Failed to delete alert")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Alert deleted successfully")
+}
+
+// List handles GET /alerts requests with pagination.
+//
+// With lat and lon it lists the alerts in effect at the point instead: those whose
+// geometry covers it, and those without geometry within radius km (25) of it.
+func (c *HTTPAlertController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, limit := getPagination(r, ResourceAlerts)
+	offset := (page - 1) * limit
+	withTotal, err := includeTotal(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	query := r.URL.Query()
+	if query.Has("lat") || query.Has("lon") {
+		return c.listAtPoint(ctx, w, r, page, limit, withTotal)
+	}
+
+	alerts, err := c.repo.List(ctx, limit, offset)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve alerts", err.Error())
+	}
+
+	var total *int
+	if withTotal {
+		count, err := c.repo.Count(ctx)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to count alerts", err.Error())
+		}
+		total = &count
+	}
+
+	var response []*Alert
+	for _, a := range alerts {
+		response = append(response, fromRepoAlert(a))
+	}
+
+	paginated := newPaginatedResponse(response, total, page, limit, ResourceAlerts)
+
+	return writePaginated(w, paginated)
+}
+
+// listAtPoint answers List for the alerts in effect at the lat and lon of r
+func (c *HTTPAlertController) listAtPoint(ctx context.Context, w http.ResponseWriter, r *http.Request, page, limit int, withTotal bool) error {
+	lat, lon, err := geo.ParseCoordinates(r.URL.Query().Get("lat"), r.URL.Query().Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	radius, err := queryRadius(r, 25.0)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	alerts, err := c.repo.GetActiveByCoordinates(ctx, lat, lon, radius, maxPointAlerts)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to find alerts", err.Error())
+	}
+
+	var total *int
+	if withTotal {
+		count := len(alerts)
+		total = &count
+	}
+	response := []*Alert{}
+	for _, a := range alerts[min((page-1)*limit, len(alerts)):min(page*limit, len(alerts))] {
+		response = append(response, fromRepoAlert(a))
+	}
+
+	return writePaginated(w, newPaginatedResponse(response, total, page, limit, ResourceAlerts))
+}
+
+// GetActiveByCityID handles GET /cities/{id}/alerts requests
+func (c *HTTPAlertController) GetActiveByCityID(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	alerts, err := c.repo.GetActiveByCityID(ctx, cityID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve alerts", err.Error())
+	}
+
+	var response []*Alert
+	for _, a := range alerts {
+		response = append(response, fromRepoAlert(a))
+	}
+
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// GetActiveByCoordinates handles GET /alerts/active?lat=&lon=&radius= requests
+func (c *HTTPAlertController) GetActiveByCoordinates(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	lat, lon, err := geo.ParseCoordinates(r.URL.Query().Get("lat"), r.URL.Query().Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	radius, err := queryRadius(r, 25.0)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	_, limit := getPagination(r, ResourceAlerts)
+
+	alerts, err := c.repo.GetActiveByCoordinates(ctx, lat, lon, radius, limit)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to find alerts", err.Error())
+	}
+
+	var response []*Alert
+	for _, a := range alerts {
+		response = append(response, fromRepoAlert(a))
+	}
+
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// CleanupExpired handles DELETE /alerts/expired requests
+func (c *HTTPAlertController) CleanupExpired(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	deleted, err := c.repo.DeleteExpired(ctx)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to cleanup alerts", err.Error())
+	}
+
+	return writeCommitted(w, http.StatusOK, map[string]int64{"deleted": deleted}, fmt.Sprintf("Cleaned up %d expired alerts", deleted))
+}
+
+func toRepoAlert(a *Alert) *repo.Alert {
+	return &repo.Alert{
+		ID:              a.ID,
+		SourceProvider:  a.SourceProvider,
+		ProviderAlertID: a.ProviderAlertID,
+		CityID:          a.CityID,
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		Title:           a.Title,
+		Description:     a.Description,
+		Severity:        a.Severity,
+		Urgency:         a.Urgency,
+		Category:        a.Category,
+		AreaDesc:        a.AreaDesc,
+		Geometry:        alertGeometry(a.Geometry),
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+}
+
+func fromRepoAlert(a *repo.Alert) *Alert {
+	alert := &Alert{
+		ID:              a.ID,
+		SourceProvider:  a.SourceProvider,
+		ProviderAlertID: a.ProviderAlertID,
+		CityID:          a.CityID,
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		Title:           a.Title,
+		Description:     a.Description,
+		Severity:        a.Severity,
+		Urgency:         a.Urgency,
+		Category:        a.Category,
+		AreaDesc:        a.AreaDesc,
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+	if a.Geometry != "" {
+		alert.Geometry = json.RawMessage(a.Geometry)
+	}
+	return alert
+}
+
+// alertGeometry returns the GeoJSON of an alert payload's geometry, empty for a
+// missing or null one
+func alertGeometry(geometry json.RawMessage) string {
+	if trimmed := bytes.TrimSpace(geometry); len(trimmed) > 0 && !bytes.Equal(trimmed, []byte("null")) {
+		return string(trimmed)
+	}
+	return ""
+}
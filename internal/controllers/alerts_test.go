@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// MockAlertRepository implements repo.AlertRepository for testing
+type MockAlertRepository struct {
+	shouldError bool
+	errorMsg    string
+	alerts      []*repo.Alert
+	alert       *repo.Alert
+	count       int
+	upserted    int
+	deleted     int64
+}
+
+func (m *MockAlertRepository) Create(ctx context.Context, alert *repo.Alert) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	alert.ID = 321
+	return nil
+}
+
+func (m *MockAlertRepository) Upsert(ctx context.Context, alert *repo.Alert) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	m.upserted++
+	alert.ID = 321
+	return nil
+}
+
+func (m *MockAlertRepository) GetByID(ctx context.Context, id int) (*repo.Alert, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.alert, nil
+}
+
+func (m *MockAlertRepository) GetByProviderAlertID(ctx context.Context, sourceProvider, providerAlertID string) (*repo.Alert, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.alert, nil
+}
+
+func (m *MockAlertRepository) Update(ctx context.Context, alert *repo.Alert) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	return nil
+}
+
+func (m *MockAlertRepository) Delete(ctx context.Context, id int) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	return nil
+}
+
+func (m *MockAlertRepository) List(ctx context.Context, limit, offset int) ([]*repo.Alert, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.alerts, nil
+}
+
+func (m *MockAlertRepository) Count(ctx context.Context) (int, error) {
+	if m.shouldError {
+		return 0, &repoError{msg: m.errorMsg}
+	}
+	return m.count, nil
+}
+
+func (m *MockAlertRepository) GetActiveByCityID(ctx context.Context, cityID int) ([]*repo.Alert, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.alerts, nil
+}
+
+func (m *MockAlertRepository) GetActiveByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*repo.Alert, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.alerts, nil
+}
+
+// boundedAlertRepository records the radius and limit of point queries
+type boundedAlertRepository struct {
+	MockAlertRepository
+	radius float64
+	limit  int
+}
+
+func (m *boundedAlertRepository) GetActiveByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*repo.Alert, error) {
+	m.radius, m.limit = radiusKm, limit
+	return nil, nil
+}
+
+func (m *MockAlertRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	if m.shouldError {
+		return 0, &repoError{msg: m.errorMsg}
+	}
+	return m.deleted, nil
+}
+
+func createTestRepoAlert() *repo.Alert {
+	return toRepoAlert(exampleStoredAlert())
+}
+
+func TestAlertController(t *testing.T) {
+	t.Run("interface compliance", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{}, nil)
+
+		var _ AlertController = controller
+		var _ Controller[Alert] = controller
+	})
+
+	t.Run("Create upserts", func(t *testing.T) {
+		mockRepo := &MockAlertRepository{}
+		controller := NewHTTPAlertController(mockRepo, nil)
+
+		body, _ := json.Marshal(fromRepoAlert(createTestRepoAlert()))
+		req := httptest.NewRequest("POST", "/alerts", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		if err := controller.Create(context.Background(), w, req); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+		}
+		if mockRepo.upserted != 1 {
+			t.Errorf("Expected alert to be upserted once, got %d", mockRepo.upserted)
+		}
+	})
+
+	t.Run("Create missing provider alert ID", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{}, nil)
+
+		body, _ := json.Marshal(&Alert{SourceProvider: "NWS", Title: "Wind Advisory"})
+		req := httptest.NewRequest("POST", "/alerts", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		_ = controller.Create(context.Background(), w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+		}
+	})
+
+	t.Run("GetActiveByCityID", func(t *testing.T) {
+		mockRepo := &MockAlertRepository{alerts: []*repo.Alert{createTestRepoAlert()}}
+		controller := NewHTTPAlertController(mockRepo, nil)
+
+		req := httptest.NewRequest("GET", "/cities/123/alerts", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.GetActiveByCityID(context.Background(), w, req, 123); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+
+		var response []*Alert
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response) != 1 || response[0].Title != "Wind Advisory" {
+			t.Errorf("Unexpected response: %+v", response)
+		}
+	})
+
+	t.Run("GetActiveByCoordinates invalid lon", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{}, nil)
+
+		req := httptest.NewRequest("GET", "/alerts/active?lat=37.7&lon=invalid", nil)
+		w := httptest.NewRecorder()
+
+		_ = controller.GetActiveByCoordinates(context.Background(), w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("GetActiveByCoordinates bounds limit and radius", func(t *testing.T) {
+		mock := &boundedAlertRepository{}
+		controller := NewHTTPAlertController(mock, nil)
+
+		req := httptest.NewRequest("GET", "/alerts/active?lat=37.7&lon=-122.4&radius=100000&limit=100000", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.GetActiveByCoordinates(context.Background(), w, req); err != nil {
+			t.Fatalf("GetActiveByCoordinates failed: %v", err)
+		}
+		if mock.radius != maxRadiusKm || mock.limit != pageSize(ResourceAlerts).Max {
+			t.Errorf("Expected radius %v and limit %d, got %v and %d", maxRadiusKm, pageSize(ResourceAlerts).Max, mock.radius, mock.limit)
+		}
+	})
+
+	t.Run("Create invalid geometry", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{}, nil)
+
+		alert := fromRepoAlert(createTestRepoAlert())
+		alert.Geometry = json.RawMessage(`{"type": "Point", "coordinates": [-122.4, 37.7]}`)
+		body, _ := json.Marshal(alert)
+		req := httptest.NewRequest("POST", "/alerts", bytes.NewReader(body))
+		w := httptest.NewRecorder()
+
+		_ = controller.Create(context.Background(), w, req)
+
+		if w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+		}
+	})
+
+	t.Run("List at a point", func(t *testing.T) {
+		covering := createTestRepoAlert()
+		covering.Geometry = `{"type":"Polygon","coordinates":[[[-123,37],[-122,37],[-122,38],[-123,38],[-123,37]]]}`
+		mockRepo := &MockAlertRepository{alerts: []*repo.Alert{covering, createTestRepoAlert(), createTestRepoAlert()}}
+		controller := NewHTTPAlertController(mockRepo, nil)
+
+		req := httptest.NewRequest("GET", "/alerts?lat=37.7&lon=-122.4&limit=2&page=2", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.List(context.Background(), w, req); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+
+		var response struct {
+			Data  []*Alert `json:"data"`
+			Total *int     `json:"total"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Data) != 1 || response.Total == nil || *response.Total != 3 {
+			t.Errorf("Expected the last of 3 alerts on page 2, got %d of %v", len(response.Data), response.Total)
+		}
+
+		req = httptest.NewRequest("GET", "/alerts?page=1", nil)
+		w = httptest.NewRecorder()
+		_ = controller.List(context.Background(), w, req)
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if string(response.Data[0].Geometry) != covering.Geometry {
+			t.Errorf("Expected the stored geometry in responses, got %s", response.Data[0].Geometry)
+		}
+
+		req = httptest.NewRequest("GET", "/alerts?lat=37.7", nil)
+		w = httptest.NewRecorder()
+		_ = controller.List(context.Background(), w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d without lon, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("CleanupExpired", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{deleted: 4}, nil)
+
+		req := httptest.NewRequest("DELETE", "/alerts/expired", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.CleanupExpired(context.Background(), w, req); err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+}
@@ -0,0 +1,35 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"stormlightlabs.org/weather_api/internal/audit"
+)
+
+// AuditController handles requests for the log of recent repository writes
+type AuditController interface {
+	// List handles requests for the most recent writes
+	List(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// HTTPAuditController implements AuditController for HTTP requests
+type HTTPAuditController struct {
+	log *audit.Log
+}
+
+// NewHTTPAuditController creates a new HTTP audit log controller
+func NewHTTPAuditController(log *audit.Log) AuditController {
+	return &HTTPAuditController{log: log}
+}
+
+// List handles GET /audit?limit= requests, newest writes first
+func (c *HTTPAuditController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+
+	return writeJSON(w, http.StatusOK, c.log.Recent(limit))
+}
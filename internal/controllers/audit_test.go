@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/audit"
+)
+
+func TestHTTPAuditController_List(t *testing.T) {
+	log := audit.NewLog(10)
+	log.Record(audit.Entry{Entity: "city", Op: "create", ID: 1, Actor: "alice"})
+	log.Record(audit.Entry{Entity: "city", Op: "delete", ID: 1, Actor: "admin"})
+
+	w := httptest.NewRecorder()
+	if err := NewHTTPAuditController(log).List(context.Background(), w, httptest.NewRequest("GET", "/audit?limit=1", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	var entries []audit.Entry
+	if err := json.Unmarshal(w.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Op != "delete" {
+		t.Errorf("Expected only the newest write, got %+v", entries)
+	}
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+const (
+	// aviationRefreshInterval is how long stored reports are served before the provider
+	// is asked again; METARs are issued hourly and TAFs every six hours
+	aviationRefreshInterval = 15 * time.Minute
+
+	defaultMETARHours = 3
+	maxMETARHours     = 24
+)
+
+// AviationController handles METAR and TAF requests for airports
+type AviationController interface {
+	// GetByICAO handles requests for the latest METAR and TAF of an airport
+	GetByICAO(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error
+
+	// GetMETARs handles requests for the recent METAR history of an airport
+	GetMETARs(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error
+
+	// GetTAF handles requests for the current TAF of an airport
+	GetTAF(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error
+}
+
+// AviationReport represents a METAR or TAF for controllers: the raw report, the
+// measurements normalized to the units used by forecasts, and the decoded groups
+type AviationReport struct {
+	ID             int      `json:"id"`
+	StationID      string   `json:"station_id"`
+	ReportType     string   `json:"report_type"`
+	SourceProvider string   `json:"source_provider"`
+	RawText        string   `json:"raw_text"`
+	ObservedAt     string   `json:"observed_at"`
+	ValidFrom      string   `json:"valid_from,omitempty"`
+	ValidTo        string   `json:"valid_to,omitempty"`
+	Latitude       float64  `json:"latitude"`
+	Longitude      float64  `json:"longitude"`
+	Temperature    *float64 `json:"temperature"`
+	Dewpoint       *float64 `json:"dewpoint"`
+	WindDirection  *float64 `json:"wind_direction"`
+	WindSpeed      *float64 `json:"wind_speed"`
+	WindGust       *float64 `json:"wind_gust"`
+	Visibility     *float64 `json:"visibility"`
+	Pressure       *float64 `json:"pressure"`
+	FlightCategory string   `json:"flight_category"`
+	Decoded        any      `json:"decoded,omitempty"` // *providers.METAR or *providers.TAF
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// AviationResponse is the latest METAR and TAF for an airport; either may be nil when
+// the station does not issue it
+type AviationResponse struct {
+	StationID string          `json:"station_id"`
+	METAR     *AviationReport `json:"metar"`
+	TAF       *AviationReport `json:"taf"`
+}
+
+// HTTPAviationController implements AviationController for HTTP requests
+type HTTPAviationController struct {
+	provider providers.AviationProvider
+	reports  repo.AviationReportRepository
+}
+
+// NewHTTPAviationController creates a new HTTP aviation controller.
+//
+// Reports are fetched from provider and stored through reports, which then serves them
+// until aviationRefreshInterval has passed. When the provider fails, the last stored
+// report is returned instead.
+func NewHTTPAviationController(provider providers.AviationProvider, reports repo.AviationReportRepository) AviationController {
+	return &HTTPAviationController{provider: provider, reports: reports}
+}
+
+// GetByICAO handles GET /aviation/{icao} requests
+func (c *HTTPAviationController) GetByICAO(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error {
+	icao = strings.ToUpper(icao)
+	if !models.ValidICAO(icao) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "icao must be a 4-character ICAO airport code")
+	}
+
+	metar, err := c.latest(ctx, icao, models.ReportTypeMETAR)
+	if err != nil {
+		return writeProviderError(w, "Failed to retrieve METAR", err)
+	}
+	taf, err := c.latest(ctx, icao, models.ReportTypeTAF)
+	if err != nil {
+		return writeProviderError(w, "Failed to retrieve TAF", err)
+	}
+	if metar == nil && taf == nil {
+		return writeError(w, http.StatusNotFound, "Station not found", fmt.Sprintf("no METAR or TAF available for %s", icao))
+	}
+
+	return writeSuccess(w, http.StatusOK, &AviationResponse{
+		StationID: icao,
+		METAR:     fromRepoAviationReport(metar),
+		TAF:       fromRepoAviationReport(taf),
+	}, "")
+}
+
+// GetMETARs handles GET /aviation/{icao}/metar?hours= requests, newest first
+func (c *HTTPAviationController) GetMETARs(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error {
+	icao = strings.ToUpper(icao)
+	if !models.ValidICAO(icao) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "icao must be a 4-character ICAO airport code")
+	}
+
+	hours, err := strconv.Atoi(r.URL.Query().Get("hours"))
+	if err != nil || hours <= 0 {
+		hours = defaultMETARHours
+	}
+	if hours > maxMETARHours {
+		hours = maxMETARHours
+	}
+
+	fetched, fetchErr := c.provider.GetMETARs(ctx, icao, hours)
+	if fetchErr == nil {
+		c.store(ctx, fetched)
+	}
+
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339)
+	stored, err := c.reports.GetByStation(ctx, icao, models.ReportTypeMETAR, since, hours*12)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve METARs", err.Error())
+	}
+	if len(stored) == 0 && fetchErr != nil {
+		return writeProviderError(w, "Failed to retrieve METARs", fetchErr)
+	}
+
+	response := make([]*AviationReport, len(stored))
+	for i, report := range stored {
+		response[i] = fromRepoAviationReport(report)
+	}
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// GetTAF handles GET /aviation/{icao}/taf requests
+func (c *HTTPAviationController) GetTAF(ctx context.Context, w http.ResponseWriter, r *http.Request, icao string) error {
+	icao = strings.ToUpper(icao)
+	if !models.ValidICAO(icao) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "icao must be a 4-character ICAO airport code")
+	}
+
+	taf, err := c.latest(ctx, icao, models.ReportTypeTAF)
+	if err != nil {
+		return writeProviderError(w, "Failed to retrieve TAF", err)
+	}
+	if taf == nil {
+		return writeError(w, http.StatusNotFound, "TAF not found", fmt.Sprintf("no TAF available for %s", icao))
+	}
+
+	return writeSuccess(w, http.StatusOK, fromRepoAviationReport(taf), "")
+}
+
+// latest returns the newest report of a type for a station, refreshing it from the
+// provider when the stored copy is missing or older than aviationRefreshInterval.
+// A nil report with a nil error means the station does not issue that report.
+func (c *HTTPAviationController) latest(ctx context.Context, icao, reportType string) (*repo.AviationReport, error) {
+	stored, err := c.reports.GetLatest(ctx, icao, reportType)
+	if err != nil {
+		stored = nil
+	}
+	if stored != nil && time.Since(parseRepoTime(stored.UpdatedAt)) < aviationRefreshInterval {
+		return stored, nil
+	}
+
+	var fetched []*models.AviationReport
+	if reportType == models.ReportTypeTAF {
+		var taf *models.AviationReport
+		if taf, err = c.provider.GetTAF(ctx, icao); taf != nil {
+			fetched = append(fetched, taf)
+		}
+	} else {
+		fetched, err = c.provider.GetMETARs(ctx, icao, 1)
+	}
+	if err != nil {
+		if stored != nil {
+			return stored, nil
+		}
+		return nil, err
+	}
+
+	for _, report := range c.store(ctx, fetched) {
+		if stored == nil || !parseRepoTime(report.ObservedAt).Before(parseRepoTime(stored.ObservedAt)) {
+			stored = report
+		}
+	}
+	return stored, nil
+}
+
+// store persists fetched reports, returning the stored copies. Storage failures are not
+// fatal: the unsaved report is returned so the request can still be answered.
+func (c *HTTPAviationController) store(ctx context.Context, reports []*models.AviationReport) []*repo.AviationReport {
+	stored := make([]*repo.AviationReport, 0, len(reports))
+	for _, report := range reports {
+		repoReport := toRepoAviationReport(report)
+		_ = c.reports.Upsert(ctx, repoReport)
+		stored = append(stored, repoReport)
+	}
+	return stored
+}
+
+// parseRepoTime parses a stored RFC 3339 timestamp, returning the zero time on failure
+func parseRepoTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
+
+func toRepoAviationReport(a *models.AviationReport) *repo.AviationReport {
+	return &repo.AviationReport{
+		ID:             a.ID,
+		StationID:      a.StationID,
+		ReportType:     a.ReportType,
+		SourceProvider: a.SourceProvider,
+		RawText:        a.RawText,
+		ObservedAt:     formatTime(a.ObservedAt),
+		ValidFrom:      formatTime(a.ValidFrom),
+		ValidTo:        formatTime(a.ValidTo),
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		Temperature:    a.Temperature,
+		Dewpoint:       a.Dewpoint,
+		WindDirection:  a.WindDirection,
+		WindSpeed:      a.WindSpeed,
+		WindGust:       a.WindGust,
+		Visibility:     a.Visibility,
+		Pressure:       a.Pressure,
+		FlightCategory: a.FlightCategory,
+	}
+}
+
+func fromRepoAviationReport(a *repo.AviationReport) *AviationReport {
+	if a == nil {
+		return nil
+	}
+
+	report := &AviationReport{
+		ID:             a.ID,
+		StationID:      a.StationID,
+		ReportType:     a.ReportType,
+		SourceProvider: a.SourceProvider,
+		RawText:        a.RawText,
+		ObservedAt:     a.ObservedAt,
+		ValidFrom:      a.ValidFrom,
+		ValidTo:        a.ValidTo,
+		Latitude:       a.Latitude,
+		Longitude:      a.Longitude,
+		Temperature:    a.Temperature,
+		Dewpoint:       a.Dewpoint,
+		WindDirection:  a.WindDirection,
+		WindSpeed:      a.WindSpeed,
+		WindGust:       a.WindGust,
+		Visibility:     a.Visibility,
+		Pressure:       a.Pressure,
+		FlightCategory: a.FlightCategory,
+		CreatedAt:      a.CreatedAt,
+		UpdatedAt:      a.UpdatedAt,
+	}
+
+	// Stored reports are decoded on the way out so decoder improvements apply to history
+	if a.ReportType == models.ReportTypeTAF {
+		if taf, err := providers.DecodeTAF(a.RawText); err == nil {
+			report.Decoded = taf
+		}
+	} else if metar, err := providers.DecodeMETAR(a.RawText); err == nil {
+		report.Decoded = metar
+	}
+	return report
+}
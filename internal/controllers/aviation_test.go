@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+type stubAviationProvider struct {
+	metars []string
+	taf    string
+	err    error
+	calls  int
+}
+
+func (s *stubAviationProvider) GetName() string { return "stub" }
+
+func (s *stubAviationProvider) GetMETARs(ctx context.Context, icao string, hours int) ([]*models.AviationReport, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	var reports []*models.AviationReport
+	for i, raw := range s.metars {
+		observed := time.Now().UTC().Truncate(time.Hour).Add(-time.Duration(i) * time.Hour)
+		report, err := providers.METARToReport(raw, observed, s.GetName())
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+func (s *stubAviationProvider) GetTAF(ctx context.Context, icao string) (*models.AviationReport, error) {
+	s.calls++
+	if s.err != nil || s.taf == "" {
+		return nil, s.err
+	}
+	return providers.TAFToReport(s.taf, time.Now().UTC(), s.GetName())
+}
+
+func newAviationController(t *testing.T, provider *stubAviationProvider) AviationController {
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	return NewHTTPAviationController(provider, engine.Aviation())
+}
+
+func TestAviationController_GetByICAO(t *testing.T) {
+	provider := &stubAviationProvider{
+		metars: []string{"KJFK 121651Z 31015G25KT 10SM FEW250 M02/M14 A3012", "KJFK 121551Z 31012KT 10SM FEW250 M02/M14 A3010"},
+		taf:    "TAF KJFK 121730Z 1218/1324 31015KT P6SM SCT035",
+	}
+	controller := newAviationController(t, provider)
+
+	get := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/aviation/kjfk", nil)
+		w := httptest.NewRecorder()
+		if err := controller.GetByICAO(context.Background(), w, req, "kjfk"); err != nil {
+			t.Fatalf("GetByICAO failed: %v", err)
+		}
+		return w
+	}
+
+	w := get()
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response struct {
+		Data struct {
+			StationID string `json:"station_id"`
+			METAR     struct {
+				RawText        string          `json:"raw_text"`
+				FlightCategory string          `json:"flight_category"`
+				WindGust       *float64        `json:"wind_gust"`
+				Decoded        json.RawMessage `json:"decoded"`
+			} `json:"metar"`
+			TAF struct {
+				ReportType string `json:"report_type"`
+				Decoded    struct {
+					Periods []providers.TAFPeriod `json:"periods"`
+				} `json:"decoded"`
+			} `json:"taf"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if response.Data.StationID != "KJFK" || response.Data.METAR.RawText != provider.metars[0] {
+		t.Errorf("Expected newest KJFK METAR, got %+v", response.Data)
+	}
+	if response.Data.METAR.FlightCategory != "VFR" || response.Data.METAR.WindGust == nil {
+		t.Errorf("Expected normalized VFR METAR with gust, got %+v", response.Data.METAR)
+	}
+	if len(response.Data.METAR.Decoded) == 0 {
+		t.Error("Expected decoded METAR")
+	}
+	if response.Data.TAF.ReportType != "TAF" || len(response.Data.TAF.Decoded.Periods) != 1 {
+		t.Errorf("Expected decoded TAF, got %+v", response.Data.TAF)
+	}
+
+	calls := provider.calls
+	get()
+	if provider.calls != calls {
+		t.Errorf("Expected stored reports to be served without calling the provider, got %d extra calls", provider.calls-calls)
+	}
+}
+
+func TestAviationController_Errors(t *testing.T) {
+	t.Run("invalid ICAO", func(t *testing.T) {
+		controller := newAviationController(t, &stubAviationProvider{})
+		w := httptest.NewRecorder()
+		_ = controller.GetByICAO(context.Background(), w, httptest.NewRequest("GET", "/aviation/JFK", nil), "JFK")
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("unknown station", func(t *testing.T) {
+		controller := newAviationController(t, &stubAviationProvider{})
+		w := httptest.NewRecorder()
+		_ = controller.GetByICAO(context.Background(), w, httptest.NewRequest("GET", "/aviation/KXXX", nil), "KXXX")
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		controller := newAviationController(t, &stubAviationProvider{err: errors.New("upstream down")})
+		w := httptest.NewRecorder()
+		_ = controller.GetTAF(context.Background(), w, httptest.NewRequest("GET", "/aviation/KJFK/taf", nil), "KJFK")
+		if w.Code != http.StatusBadGateway {
+			t.Errorf("Expected status %d, got %d", http.StatusBadGateway, w.Code)
+		}
+	})
+}
+
+func TestAviationController_GetMETARs(t *testing.T) {
+	provider := &stubAviationProvider{metars: []string{
+		"KJFK 121651Z 31015KT 10SM FEW250 M02/M14 A3012",
+		"KJFK 121551Z 31012KT 10SM FEW250 M02/M14 A3010",
+		"KJFK 121451Z 31010KT 10SM FEW250 M02/M14 A3008",
+	}}
+	controller := newAviationController(t, provider)
+
+	req := httptest.NewRequest("GET", "/aviation/KJFK/metar?hours=2", nil)
+	w := httptest.NewRecorder()
+	if err := controller.GetMETARs(context.Background(), w, req, "KJFK"); err != nil {
+		t.Fatalf("GetMETARs failed: %v", err)
+	}
+
+	var response struct {
+		Data []AviationReport `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 2 || response.Data[0].RawText != provider.metars[0] {
+		t.Errorf("Expected the 2 METARs of the last 2 hours newest first, got %+v", response.Data)
+	}
+
+	// History stays available while the provider is down
+	provider.err = errors.New("upstream down")
+	w = httptest.NewRecorder()
+	_ = controller.GetMETARs(context.Background(), w, req, "KJFK")
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected stored METARs with status %d, got %d", http.StatusOK, w.Code)
+	}
+}
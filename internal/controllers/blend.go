@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/blend"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// BlendController handles consensus forecasts blended from every weather provider
+type BlendController interface {
+	// GetBlend handles requests for a blended forecast at a point
+	GetBlend(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// BlendResponse is a forecast blended from several providers. Failures lists the
+// providers left out because their forecast could not be fetched.
+type BlendResponse struct {
+	Latitude  float64        `json:"latitude"`
+	Longitude float64        `json:"longitude"`
+	Method    string         `json:"method"`
+	Units     string         `json:"units"`
+	WindUnits string         `json:"wind_units"`
+	Providers []string       `json:"providers"`
+	Failures  []BlendFailure `json:"failures"`
+	Periods   []*BlendPeriod `json:"periods"`
+}
+
+// BlendFailure is a provider missing from a blend and why
+type BlendFailure struct {
+	Provider string `json:"provider"`
+	Error    string `json:"error"`
+}
+
+// BlendPeriod is the blend of the provider forecasts valid from one hour
+type BlendPeriod struct {
+	ValidTime     string    `json:"valid_time"`
+	Providers     []string  `json:"providers"`
+	Temperature   BlendStat `json:"temperature"`
+	FeelsLike     BlendStat `json:"feels_like"`
+	Humidity      BlendStat `json:"humidity"`
+	Pressure      BlendStat `json:"pressure"`
+	WindSpeed     BlendStat `json:"wind_speed"`
+	WindGust      BlendStat `json:"wind_gust"`
+	WindDirection float64   `json:"wind_direction"`
+	CloudCover    BlendStat `json:"cloud_cover"`
+	Precipitation BlendStat `json:"precipitation"`
+}
+
+// BlendStat is a blended measurement with the range of the provider values; spread is
+// max minus min
+type BlendStat struct {
+	Value  float64 `json:"value"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Spread float64 `json:"spread"`
+}
+
+// HTTPBlendController implements BlendController for HTTP requests
+type HTTPBlendController struct {
+	blender *blend.Blender
+}
+
+// NewHTTPBlendController creates a new HTTP blend controller
+func NewHTTPBlendController(blender *blend.Blender) BlendController {
+	return &HTTPBlendController{blender: blender}
+}
+
+// GetBlend handles GET /forecasts/blend?lat=&lon=&days=&method=&weights=&units= requests.
+// method is median (the default) or mean, and weights (name=weight, comma separated)
+// override the server's provider weights for the request.
+func (c *HTTPBlendController) GetBlend(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	method, err := blend.ParseMethod(query.Get("method"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	weights, err := blend.ParseWeights(query["weights"])
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	days, err := strconv.Atoi(query.Get("days"))
+	if err != nil || days <= 0 {
+		days = defaultForecastDays
+	}
+	days = min(days, maxForecastDays)
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+
+	result, err := c.blender.Blend(ctx, lat, lon, days, method, weights)
+	if err != nil {
+		return writeProviderError(w, "Failed to blend forecasts", err)
+	}
+
+	response := &BlendResponse{
+		Latitude:  lat,
+		Longitude: lon,
+		Method:    string(method),
+		Units:     string(opts.System),
+		WindUnits: string(opts.Wind),
+		Providers: result.Providers,
+		Failures:  make([]BlendFailure, 0, len(result.Failures)),
+		Periods:   make([]*BlendPeriod, 0, len(result.Periods)),
+	}
+	for _, failure := range result.Failures {
+		response.Failures = append(response.Failures, BlendFailure{Provider: failure.Provider, Error: failure.Err.Error()})
+	}
+	for _, period := range result.Periods {
+		response.Periods = append(response.Periods, fromBlendPeriod(period, opts))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// fromBlendPeriod converts a blended period from metric to the requested units
+func fromBlendPeriod(p *blend.Period, opts unitOptions) *BlendPeriod {
+	temperature, precipitation, pressure := metricStat(1), metricStat(1), metricStat(1)
+	if opts.System == units.Imperial {
+		temperature = convertedStat(units.CelsiusToFahrenheit, 1)
+		precipitation = convertedStat(units.MillimetersToInches, 2)
+		pressure = convertedStat(units.HectopascalsToInchesOfMercury, 2)
+	}
+	wind := convertedStat(func(ms float64) float64 { return units.ConvertWindSpeed(ms, opts.Wind) }, 1)
+
+	return &BlendPeriod{
+		ValidTime:     p.ValidTime.Format(time.RFC3339),
+		Providers:     p.Providers,
+		Temperature:   temperature(p.Temperature),
+		FeelsLike:     temperature(p.FeelsLike),
+		Humidity:      metricStat(1)(p.Humidity),
+		Pressure:      pressure(p.Pressure),
+		WindSpeed:     wind(p.WindSpeed),
+		WindGust:      wind(p.WindGust),
+		WindDirection: units.Round(p.WindDirection, 0),
+		CloudCover:    metricStat(1)(p.CloudCover),
+		Precipitation: precipitation(p.Precipitation),
+	}
+}
+
+// metricStat returns a function rounding a stat to places
+func metricStat(places int) func(blend.Stat) BlendStat {
+	return convertedStat(func(v float64) float64 { return v }, places)
+}
+
+// convertedStat returns a function converting a stat with convert and rounding it to
+// places. The spread is taken after conversion, so it stays max minus min.
+func convertedStat(convert func(float64) float64, places int) func(blend.Stat) BlendStat {
+	return func(s blend.Stat) BlendStat {
+		stat := BlendStat{
+			Value: units.Round(convert(s.Value), places),
+			Min:   units.Round(convert(s.Min), places),
+			Max:   units.Round(convert(s.Max), places),
+		}
+		stat.Spread = units.Round(stat.Max-stat.Min, places)
+		return stat
+	}
+}
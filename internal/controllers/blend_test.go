@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/blend"
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+func TestHTTPBlendController_GetBlend(t *testing.T) {
+	blender := blend.NewBlender([]providers.WeatherProvider{
+		&stubWeatherProvider{name: "A", regions: []string{"US"}},
+		&stubWeatherProvider{name: "B", regions: []string{"US"}},
+	}, nil)
+	controller := NewHTTPBlendController(blender)
+
+	get := func(t *testing.T, query string) (*httptest.ResponseRecorder, *BlendResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		if err := controller.GetBlend(context.Background(), w, httptest.NewRequest("GET", "/forecasts/blend?"+query, nil)); err != nil {
+			t.Fatalf("GetBlend failed: %v", err)
+		}
+		var response BlendResponse
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return w, &response
+	}
+
+	t.Run("blends every provider", func(t *testing.T) {
+		w, response := get(t, "lat=40&lon=-90&units=imperial&method=mean")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Method != "mean" || len(response.Providers) != 2 || len(response.Failures) != 0 {
+			t.Errorf("Expected a mean of both providers, got %+v", response)
+		}
+		if len(response.Periods) != 1 {
+			t.Fatalf("Expected the providers' forecasts aligned into 1 period, got %d", len(response.Periods))
+		}
+		if temperature := response.Periods[0].Temperature; temperature.Value != 68 || temperature.Spread != 0 {
+			t.Errorf("Expected 68°F with no spread, got %+v", temperature)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"lat=40", "lat=40&lon=-90&method=mode", "lat=40&lon=-90&weights=A"} {
+			if w, _ := get(t, query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %q, got %d", query, w.Code)
+			}
+		}
+	})
+
+	t.Run("no provider weighted", func(t *testing.T) {
+		if w, _ := get(t, "lat=40&lon=-90&weights=A=0,B=0"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status 422, got %d", w.Code)
+		}
+	})
+}
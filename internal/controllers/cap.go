@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// maxCAPBody caps the size of a POSTed CAP message
+const maxCAPBody = 1 << 20
+
+// CAPIngestResult reports what POST /alerts/cap did with a CAP message
+type CAPIngestResult struct {
+	Identifier string `json:"identifier"`
+	MsgType    string `json:"msg_type"`
+	Ignored    string `json:"ignored,omitempty"` // why the message was not applied
+	Alert      *Alert `json:"alert,omitempty"`   // the alert stored from an Alert or Update
+	Expired    []int  `json:"expired"`           // IDs of the referenced alerts an Update or Cancel ended
+}
+
+// IngestCAP handles POST /alerts/cap?source=&language=&lat=&lon= requests: the body is
+// one CAP 1.2 alert message, from a source such as MeteoAlarm or Environment Canada.
+//
+// Alert and Update messages are stored like POST /alerts under source, deduplicated by
+// the message identifier. Update and Cancel messages end the alerts they reference
+// (stored from the same source) at the time they were sent. The info block in language
+// (default English) is used, and the alert is placed at lat and lon when given, else at
+// the center of its area; a message with neither is rejected. Exercises, tests and
+// drafts are acknowledged but not stored.
+func (c *HTTPAlertController) IngestCAP(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	source := query.Get("source")
+	if source == "" {
+		return writeError(w, http.StatusBadRequest, "Missing parameter", "source is required, e.g. MeteoAlarm")
+	}
+	var lat, lon float64
+	placed := query.Has("lat") || query.Has("lon")
+	if placed {
+		var err error
+		if lat, lon, err = geo.ParseCoordinates(query.Get("lat"), query.Get("lon")); err != nil {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+		}
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCAPBody+1))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid CAP document", err.Error())
+	}
+	if len(body) > maxCAPBody {
+		return writeError(w, http.StatusRequestEntityTooLarge, "Invalid CAP document", fmt.Sprintf("body exceeds %d bytes", maxCAPBody))
+	}
+	message, err := providers.DecodeCAP(body, query.Get("language"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid CAP document", err.Error())
+	}
+
+	result := &CAPIngestResult{Identifier: message.Identifier, MsgType: message.MsgType, Expired: []int{}}
+	switch {
+	case !message.Actual():
+		result.Ignored = "status is " + message.Status + ", not Actual"
+		return writeSuccess(w, http.StatusOK, result, "CAP message ignored")
+	case message.MsgType != providers.CAPAlert && message.MsgType != providers.CAPUpdate && message.MsgType != providers.CAPCancel:
+		result.Ignored = "msgType " + message.MsgType + " carries no alert"
+		return writeSuccess(w, http.StatusOK, result, "CAP message ignored")
+	}
+
+	var alert *Alert
+	if message.MsgType != providers.CAPCancel {
+		if !placed {
+			if len(message.Alert.Geometry) == 0 {
+				return writeError(w, http.StatusUnprocessableEntity, "Invalid CAP document",
+					"the message has no polygon or circle; pass lat and lon to place it")
+			}
+			area, err := geo.ParseArea(message.Alert.Geometry)
+			if err != nil {
+				return writeError(w, http.StatusUnprocessableEntity, "Invalid CAP document", err.Error())
+			}
+			minLat, minLon, maxLat, maxLon := area.Bounds()
+			lat, lon = geo.Coarsen((minLat+maxLat)/2, (minLon+maxLon)/2)
+		}
+		alert = fromModelAlert(message.Alert.ToModel(source, lat, lon))
+		if errs := alert.validate(); errs != nil {
+			return writeValidationError(w, errs)
+		}
+	}
+
+	for _, reference := range message.References {
+		id, err := c.expireAlert(ctx, source, reference, message.Sent)
+		if err != nil {
+			return writeRepoError(w, err, "Alert", "Failed to expire referenced alert")
+		}
+		if id != 0 {
+			result.Expired = append(result.Expired, id)
+		}
+	}
+
+	if alert == nil {
+		return writeCommitted(w, http.StatusOK, result, fmt.Sprintf("Cancelled %d alerts", len(result.Expired)))
+	}
+	repoAlert := toRepoAlert(alert)
+	if err := c.repo.Upsert(ctx, repoAlert); err != nil {
+		return writeRepoError(w, err, "Alert", "Failed to store alert")
+	}
+	result.Alert = fromRepoAlert(repoAlert)
+	c.broker.Publish(result.Alert)
+	return writeCommitted(w, http.StatusCreated, result, "Alert stored successfully")
+}
+
+// expireAlert ends the stored alert with the provider ID identifier at end, unless it
+// already ended earlier. It returns the alert's ID, or 0 when none is stored.
+func (c *HTTPAlertController) expireAlert(ctx context.Context, source, identifier string, end time.Time) (int, error) {
+	stored, err := c.repo.GetByProviderAlertID(ctx, source, identifier)
+	if errors.Is(err, repo.ErrNotFound) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	if current := parseRepoTime(stored.EndTime); current.IsZero() || current.After(end) {
+		stored.EndTime = end.UTC().Format(time.RFC3339)
+		if err := c.repo.Update(ctx, stored); err != nil {
+			return 0, err
+		}
+	}
+	return stored.ID, nil
+}
+
+// fromModelAlert converts a provider alert's model into the alert payload POST /alerts
+// takes
+func fromModelAlert(a *models.Alert) *Alert {
+	alert := &Alert{
+		SourceProvider:  a.SourceProvider,
+		ProviderAlertID: a.ProviderAlertID,
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		Title:           a.Title,
+		Description:     a.Description,
+		Severity:        a.Severity,
+		Urgency:         a.Urgency,
+		Category:        a.Category,
+		AreaDesc:        a.AreaDesc,
+		Geometry:        a.Geometry,
+		StartTime:       formatTime(a.StartTime),
+		EndTime:         formatTime(a.EndTime),
+	}
+	if a.CityID != nil {
+		alert.CityID = *a.CityID
+	}
+	return alert
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// capMessage builds a CAP message with one English info block over a square around
+// Ottawa; area is left out when polygon is false
+func capMessage(identifier, status, msgType, references string, polygon bool) string {
+	area := `<area><areaDesc>City of Ottawa</areaDesc></area>`
+	if polygon {
+		area = `<area><areaDesc>City of Ottawa</areaDesc><polygon>45.2,-76.0 45.2,-75.4 45.5,-75.4 45.5,-76.0 45.2,-76.0</polygon></area>`
+	}
+	return `<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+		<identifier>` + identifier + `</identifier>
+		<sender>cap-pac@canada.ca</sender>
+		<sent>2025-07-14T18:00:00Z</sent>
+		<status>` + status + `</status>
+		<msgType>` + msgType + `</msgType>
+		<references>` + references + `</references>
+		<info>
+			<language>en-CA</language>
+			<category>Met</category>
+			<event>severe thunderstorm</event>
+			<urgency>Immediate</urgency>
+			<severity>Severe</severity>
+			<effective>2025-07-14T18:00:00Z</effective>
+			<expires>2099-07-15T02:00:00Z</expires>
+			<description>Conditions are favourable for severe thunderstorms.</description>
+			` + area + `
+		</info>
+	</alert>`
+}
+
+func ingestCAP(t *testing.T, controller AlertController, query, body string) (*httptest.ResponseRecorder, *CAPIngestResult) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/alerts/cap"+query, strings.NewReader(body))
+	if err := controller.IngestCAP(context.Background(), w, req); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data *CAPIngestResult `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(w.Body.String())).Decode(&response)
+	return w, response.Data
+}
+
+func TestAlertControllerIngestCAP(t *testing.T) {
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	controller := NewHTTPAlertController(engine.Alerts(), nil)
+	ctx := context.Background()
+
+	w, result := ingestCAP(t, controller, "?source=EnvironmentCanada", capMessage("ec-1", "Actual", "Alert", "", true))
+	if w.Code != http.StatusCreated || result == nil || result.Alert == nil {
+		t.Fatalf("Expected a stored alert, got %d: %s", w.Code, w.Body.String())
+	}
+	first := result.Alert
+	if first.SourceProvider != "EnvironmentCanada" || first.ProviderAlertID != "ec-1" || first.Severity != "severe" ||
+		first.AreaDesc != "City of Ottawa" || len(first.Geometry) == 0 {
+		t.Errorf("Unexpected alert %+v", first)
+	}
+	if first.Latitude < 45.2 || first.Latitude > 45.5 || first.Longitude < -76 || first.Longitude > -75.4 {
+		t.Errorf("Expected the alert placed inside its polygon, got %v,%v", first.Latitude, first.Longitude)
+	}
+
+	t.Run("Update expires the referenced alert", func(t *testing.T) {
+		w, result := ingestCAP(t, controller, "?source=EnvironmentCanada",
+			capMessage("ec-2", "Actual", "Update", "cap-pac@canada.ca,ec-1,2025-07-14T15:00:00Z", true))
+		if w.Code != http.StatusCreated || result == nil || result.Alert == nil || len(result.Expired) != 1 || result.Expired[0] != first.ID {
+			t.Fatalf("Expected ec-2 stored and ec-1 expired, got %d: %s", w.Code, w.Body.String())
+		}
+		stored, err := engine.Alerts().GetByProviderAlertID(ctx, "EnvironmentCanada", "ec-1")
+		if err != nil || parseRepoTime(stored.EndTime).Format("2006-01-02T15:04") != "2025-07-14T18:00" {
+			t.Errorf("Expected ec-1 to end when ec-2 was sent, got %+v (%v)", stored, err)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		w, result := ingestCAP(t, controller, "?source=EnvironmentCanada",
+			capMessage("ec-3", "Actual", "Cancel", "cap-pac@canada.ca,ec-2,2025-07-14T18:00:00Z cap-pac@canada.ca,ec-0,2025-07-14T12:00:00Z", true))
+		if w.Code != http.StatusOK || result == nil || result.Alert != nil || len(result.Expired) != 1 {
+			t.Fatalf("Expected ec-2 cancelled and no alert stored, got %d: %s", w.Code, w.Body.String())
+		}
+		if _, err := engine.Alerts().GetByProviderAlertID(ctx, "EnvironmentCanada", "ec-3"); err == nil {
+			t.Error("Expected the Cancel message not to be stored")
+		}
+	})
+
+	t.Run("Exercises are ignored", func(t *testing.T) {
+		w, result := ingestCAP(t, controller, "?source=EnvironmentCanada", capMessage("ec-4", "Exercise", "Alert", "", true))
+		if w.Code != http.StatusOK || result == nil || result.Ignored == "" || result.Alert != nil {
+			t.Errorf("Expected the exercise ignored, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Placed by lat and lon", func(t *testing.T) {
+		body := capMessage("ec-5", "Actual", "Alert", "", false)
+		if w, _ := ingestCAP(t, controller, "?source=EnvironmentCanada", body); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d without an area or lat and lon, got %d", http.StatusUnprocessableEntity, w.Code)
+		}
+		w, result := ingestCAP(t, controller, "?source=EnvironmentCanada&lat=45.42&lon=-75.69", body)
+		if w.Code != http.StatusCreated || result == nil || result.Alert == nil || result.Alert.Latitude != 45.42 {
+			t.Errorf("Expected the alert placed at the given point, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Bad requests", func(t *testing.T) {
+		if w, _ := ingestCAP(t, controller, "", capMessage("ec-6", "Actual", "Alert", "", true)); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d without a source, got %d", http.StatusBadRequest, w.Code)
+		}
+		if w, _ := ingestCAP(t, controller, "?source=EnvironmentCanada", `{"not": "cap"}`); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for a non-CAP body, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
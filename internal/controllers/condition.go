@@ -0,0 +1,186 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/ttl"
+)
+
+// conditionPlaces rounds coordinates (about 1 km) so nearby checks share a lookup
+const conditionPlaces = 2
+
+// ConditionRule is a yes/no question about current conditions
+type ConditionRule struct {
+	Name        string
+	Description string
+	Match       func(f *models.Forecast) bool
+}
+
+// describes reports whether a provider description mentions any of words
+func describes(f *models.Forecast, words ...string) bool {
+	description := strings.ToLower(f.Description)
+	return slices.ContainsFunc(words, func(word string) bool { return strings.Contains(description, word) })
+}
+
+// ConditionRules are the rules accepted by GET /condition-check. Thresholds use the
+// stored metric units.
+var ConditionRules = []ConditionRule{
+	{"raining", "Rain, drizzle or showers, or liquid precipitation above freezing", func(f *models.Forecast) bool {
+		return describes(f, "rain", "drizzle", "shower") || (f.Precipitation > 0 && f.Temperature > 0 && !describes(f, "snow", "sleet"))
+	}},
+	{"snowing", "Snow or sleet, or precipitation at or below freezing", func(f *models.Forecast) bool {
+		return describes(f, "snow", "sleet", "flurr") || (f.Precipitation > 0 && f.Temperature <= 0)
+	}},
+	{"stormy", "Thunderstorms", func(f *models.Forecast) bool {
+		return describes(f, "thunder", "storm")
+	}},
+	{"freezing", "Temperature at or below 0°C", func(f *models.Forecast) bool {
+		return f.Temperature <= 0
+	}},
+	{"hot", "Temperature at or above 30°C", func(f *models.Forecast) bool {
+		return f.Temperature >= 30
+	}},
+	{"windy", "Sustained wind of 10 m/s or gusts of 15 m/s and above", func(f *models.Forecast) bool {
+		return f.WindSpeed >= 10 || f.WindGust >= 15
+	}},
+	{"cloudy", "Cloud cover of 70% and above", func(f *models.Forecast) bool {
+		return f.CloudCover >= 70 || describes(f, "cloudy", "overcast")
+	}},
+	{"clear", "Cloud cover of 20% and below without precipitation", func(f *models.Forecast) bool {
+		return f.CloudCover <= 20 && f.Precipitation == 0 && !describes(f, "cloudy", "overcast", "rain", "snow", "fog")
+	}},
+	{"foggy", "Fog or visibility below 1 km", func(f *models.Forecast) bool {
+		return describes(f, "fog", "mist") || (f.Visibility > 0 && f.Visibility < 1)
+	}},
+}
+
+// ConditionController answers yes/no questions about current conditions
+type ConditionController interface {
+	// Check handles GET /condition-check?lat=&lon=&rule=
+	Check(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// HTTPConditionController implements ConditionController for HTTP requests
+type HTTPConditionController struct {
+	providers *providers.ProviderManager
+	cache     repo.Cache
+	policy    *ttl.Policy
+}
+
+// NewHTTPConditionController creates a new HTTP condition controller. When cache is
+// non-nil, current conditions are cached per rounded point for the policy's current
+// conditions lifetime; a nil policy uses ttl.Default.
+func NewHTTPConditionController(pm *providers.ProviderManager, cache repo.Cache, policy *ttl.Policy) ConditionController {
+	if policy == nil {
+		policy = ttl.Default()
+	}
+	return &HTTPConditionController{providers: pm, cache: cache, policy: policy}
+}
+
+// Check answers whether rule holds at lat/lon with an empty response, so edge logic
+// (CDN workers, shell scripts using curl -f) can branch on the status alone: 204 when
+// the rule holds and 404 when it does not. The answer is repeated in the
+// X-Condition-Result header and responses may be cached for as long as the current
+// conditions behind them.
+// Malformed requests and provider failures return the usual JSON errors.
+func (c *HTTPConditionController) Check(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	name := strings.ToLower(strings.TrimSpace(query.Get("rule")))
+	index := slices.IndexFunc(ConditionRules, func(rule ConditionRule) bool { return rule.Name == name })
+	if index < 0 {
+		names := make([]string, len(ConditionRules))
+		for i, rule := range ConditionRules {
+			names[i] = rule.Name
+		}
+		return writeError(w, http.StatusBadRequest, "Invalid parameter",
+			fmt.Sprintf("rule must be one of %s", strings.Join(names, ", ")))
+	}
+	rule := ConditionRules[index]
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+
+	ctx, tracker := freshness.WithTracker(ctx)
+	current, provider, err := c.current(ctx, lat, lon)
+	if err != nil {
+		return writeProviderError(w, "Failed to retrieve current weather", err)
+	}
+
+	matched := rule.Match(current)
+	header := w.Header()
+	writeFreshness(w, tracker, c.policy.Current(), time.Now())
+	header.Set("X-Condition-Rule", rule.Name)
+	header.Set("X-Condition-Result", strconv.FormatBool(matched))
+	header.Set("X-Condition-Provider", provider)
+	if !current.ValidTime.IsZero() {
+		header.Set("Last-Modified", current.ValidTime.UTC().Format(http.TimeFormat))
+	}
+
+	if matched {
+		w.WriteHeader(http.StatusNoContent)
+	} else {
+		w.WriteHeader(http.StatusNotFound)
+	}
+	return nil
+}
+
+// cachedConditions is the cache entry for a point's current conditions
+type cachedConditions struct {
+	Provider string           `json:"provider"`
+	Current  *models.Forecast `json:"current"`
+}
+
+// current returns the conditions at a point and the provider that reported them,
+// trying each weather provider in turn until one covers the point
+func (c *HTTPConditionController) current(ctx context.Context, lat, lon float64) (*models.Forecast, string, error) {
+	key := "condition:" + geo.FormatPoint(lat, lon, conditionPlaces)
+	if c.cache != nil {
+		if data, err := c.cache.Get(ctx, key); err == nil && data != nil {
+			var cached cachedConditions
+			if err := json.Unmarshal(data, &cached); err == nil && cached.Current != nil {
+				return cached.Current, cached.Provider, nil
+			}
+		}
+	}
+
+	err := fmt.Errorf("%w: no weather provider is registered", providers.ErrUnsupportedRegion)
+	for _, provider := range c.providers.GetWeatherProviders() {
+		current, lookupErr := provider.GetCurrentWeather(ctx, lat, lon)
+		if lookupErr != nil {
+			err = fmt.Errorf("%s: %w", provider.GetName(), lookupErr)
+			if errors.Is(lookupErr, providers.ErrUnsupportedRegion) {
+				continue
+			}
+			return nil, "", err
+		}
+		if current == nil {
+			continue
+		}
+
+		if c.cache != nil {
+			if data, err := json.Marshal(&cachedConditions{Provider: provider.GetName(), Current: current}); err == nil {
+				_ = c.cache.Set(ctx, key, data, c.policy.Current())
+			}
+		}
+		return current, provider.GetName(), nil
+	}
+	return nil, "", err
+}
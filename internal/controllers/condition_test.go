@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// conditionProvider reports fixed current conditions, or fails with err. It is called
+// concurrently when weather is fetched for several locations.
+type conditionProvider struct {
+	stubWeatherProvider
+	current *models.Forecast
+	err     error
+	calls   atomic.Int32
+}
+
+func (p *conditionProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	p.calls.Add(1)
+	return p.current, p.err
+}
+
+func checkCondition(controller ConditionController, query string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	_ = controller.Check(context.Background(), w, httptest.NewRequest("GET", "/condition-check?"+query, nil))
+	return w
+}
+
+func TestConditionRules(t *testing.T) {
+	tests := []struct {
+		rule    string
+		current models.Forecast
+		matched bool
+	}{
+		{"raining", models.Forecast{Description: "Light Rain", Temperature: 12}, true},
+		{"raining", models.Forecast{Precipitation: 2, Temperature: -3}, false},
+		{"snowing", models.Forecast{Precipitation: 2, Temperature: -3}, true},
+		{"freezing", models.Forecast{Temperature: 0}, true},
+		{"hot", models.Forecast{Temperature: 29.9}, false},
+		{"windy", models.Forecast{WindSpeed: 4, WindGust: 16}, true},
+		{"clear", models.Forecast{CloudCover: 10, Description: "Sunny"}, true},
+		{"clear", models.Forecast{CloudCover: 10, Description: "Patchy Fog"}, false},
+		{"foggy", models.Forecast{Visibility: 0.4}, true},
+		{"stormy", models.Forecast{Description: "Chance Showers And Thunderstorms"}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.rule, func(t *testing.T) {
+			provider := &conditionProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}, current: &test.current}
+			pm := providers.NewProviderManager()
+			pm.RegisterWeatherProvider(provider)
+
+			w := checkCondition(NewHTTPConditionController(pm, nil, nil), "lat=40.7&lon=-74&rule="+test.rule)
+			want := http.StatusNotFound
+			if test.matched {
+				want = http.StatusNoContent
+			}
+			if w.Code != want || w.Body.Len() != 0 || w.Header().Get("X-Condition-Result") != fmt.Sprint(test.matched) {
+				t.Errorf("%+v: expected %d, got %d %q", test.current, want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestConditionController_Check(t *testing.T) {
+	uncovered := &conditionProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}, err: providers.ErrUnsupportedRegion}
+	global := &conditionProvider{
+		stubWeatherProvider: stubWeatherProvider{name: "Met.no"},
+		current:             &models.Forecast{Description: "Rain", ValidTime: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)},
+	}
+	pm := providers.NewProviderManager()
+	pm.RegisterWeatherProvider(uncovered)
+	pm.RegisterWeatherProvider(global)
+	controller := NewHTTPConditionController(pm, newMemoryCache(), nil)
+
+	for _, query := range []string{"lat=91&lon=0&rule=raining", "lat=59.9&lon=10.7", "lat=59.9&lon=10.7&rule=humid"} {
+		if w := checkCondition(controller, query); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected %q to be rejected, got %d", query, w.Code)
+		}
+	}
+
+	w := checkCondition(controller, "lat=59.9131&lon=10.7522&rule=Raining")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("Expected the rule to hold, got %d %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Cache-Control") != "public, max-age=300" || w.Header().Get("X-Condition-Provider") != "Met.no" ||
+		w.Header().Get("Last-Modified") != "Mon, 15 Jan 2024 12:00:00 GMT" {
+		t.Errorf("Unexpected headers %v", w.Header())
+	}
+
+	// A nearby point rounds to the same cache entry
+	if w := checkCondition(controller, "lat=59.9129&lon=10.7518&rule=clear"); w.Code != http.StatusNotFound || global.calls.Load() != 1 {
+		t.Errorf("Expected a cached miss, got %d after %d lookups", w.Code, global.calls.Load())
+	}
+
+	global.err = fmt.Errorf("%w: maintenance", providers.ErrUpstreamUnavailable)
+	if w := checkCondition(controller, "lat=10&lon=10&rule=raining"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected provider failures to surface, got %d", w.Code)
+	}
+}
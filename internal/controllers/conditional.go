@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/freshness"
+)
+
+// weakETag derives a weak validator from a row's identity and updated_at. variant
+// holds whatever else shapes the representation (unit system, localized name), so
+// two renderings of the same row never share a tag. The tag is weak because
+// equivalent JSON may differ byte for byte, e.g. after compression.
+func weakETag(kind string, id int, updatedAt string, variant ...string) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s", kind, id, updatedAt)
+	for _, v := range variant {
+		fmt.Fprintf(h, "\x00%s", v)
+	}
+	return fmt.Sprintf(`W/"%016x"`, h.Sum64())
+}
+
+// writeNotModified sets the ETag and Last-Modified validators of a GET response and
+// reports whether the request's If-None-Match or If-Modified-Since shows the client's
+// copy is current, in which case it has written a 304 and the caller must stop.
+// If-None-Match takes precedence when both are sent (RFC 9110 §13.2.2).
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag, updatedAt string) bool {
+	header := w.Header()
+	header.Set("ETag", etag)
+	modified, err := time.Parse(time.RFC3339, updatedAt)
+	if err == nil {
+		modified = modified.UTC().Truncate(time.Second)
+		header.Set("Last-Modified", modified.Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		if !etagMatches(match, etag) {
+			return false
+		}
+	} else if since := r.Header.Get("If-Modified-Since"); since != "" && err == nil {
+		t, parseErr := http.ParseTime(since)
+		if parseErr != nil || modified.After(t) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	// A 304 carries the validators but no body or content headers
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// etagMatches applies the weak comparison of an If-None-Match list to etag
+func etagMatches(list, etag string) bool {
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// writeFreshness sets Cache-Control, Expires and Age on a response built from provider
+// data. When the providers stated how long their answers stay fresh, as collected by
+// tracker, the response keeps their lifetime and carries their age, so shared caches
+// expire it together with its source; otherwise it is fresh for fallback from now.
+func writeFreshness(w http.ResponseWriter, tracker *freshness.Tracker, fallback time.Duration, now time.Time) {
+	generated, expires, ok := tracker.Window()
+	if !ok {
+		generated, expires = now, now.Add(fallback)
+	}
+	maxAge := max(expires.Sub(generated), 0)
+	age := min(max(now.Sub(generated), 0), maxAge)
+
+	header := w.Header()
+	header.Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(maxAge.Seconds())))
+	header.Set("Expires", expires.UTC().Format(http.TimeFormat))
+	header.Set("Age", strconv.Itoa(int(age.Seconds())))
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestConditionalGet(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecast: &repo.Forecast{ID: 7, CityID: 3, UpdatedAt: "2024-01-15T12:00:30Z"}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	get := func(url string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", url, nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		_ = controller.GetByID(context.Background(), w, req, 7)
+		return w
+	}
+
+	first := get("/forecasts/7", nil)
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" || etag[:2] != "W/" {
+		t.Fatalf("Expected a weak ETag on the first response, got %d %q", first.Code, etag)
+	}
+	if first.Header().Get("Last-Modified") != "Mon, 15 Jan 2024 12:00:30 GMT" {
+		t.Errorf("Unexpected Last-Modified %q", first.Header().Get("Last-Modified"))
+	}
+
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		status  int
+	}{
+		{"matching tag", "/forecasts/7", map[string]string{"If-None-Match": etag}, http.StatusNotModified},
+		{"strong form of the tag", "/forecasts/7", map[string]string{"If-None-Match": `"x", ` + etag[2:]}, http.StatusNotModified},
+		{"wildcard", "/forecasts/7", map[string]string{"If-None-Match": "*"}, http.StatusNotModified},
+		{"other units", "/forecasts/7?units=imperial", map[string]string{"If-None-Match": etag}, http.StatusOK},
+		{"not modified since", "/forecasts/7", map[string]string{"If-Modified-Since": "Mon, 15 Jan 2024 12:00:30 GMT"}, http.StatusNotModified},
+		{"modified since", "/forecasts/7", map[string]string{"If-Modified-Since": "Mon, 15 Jan 2024 12:00:00 GMT"}, http.StatusOK},
+		{"tag wins over date", "/forecasts/7", map[string]string{"If-None-Match": `W/"stale"`, "If-Modified-Since": "Tue, 16 Jan 2024 00:00:00 GMT"}, http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			w := get(test.url, test.headers)
+			if w.Code != test.status {
+				t.Errorf("Expected %d, got %d", test.status, w.Code)
+			}
+			if w.Code == http.StatusNotModified && (w.Body.Len() != 0 || w.Header().Get("ETag") != etag) {
+				t.Errorf("Expected an empty 304 carrying the ETag, got %q %v", w.Body.String(), w.Header())
+			}
+		})
+	}
+
+	// A newer latest forecast changes the tag of /cities/{id}/forecasts/latest
+	latest := httptest.NewRecorder()
+	mockRepo.forecast = &repo.Forecast{ID: 8, CityID: 3, UpdatedAt: "2024-01-15T12:00:30Z"}
+	req := httptest.NewRequest("GET", "/cities/3/forecasts/latest", nil)
+	req.Header.Set("If-None-Match", etag)
+	_ = controller.GetLatestByCityID(context.Background(), latest, req, 3)
+	if latest.Code != http.StatusOK {
+		t.Errorf("Expected a new latest forecast to be returned, got %d", latest.Code)
+	}
+}
+
+func TestWriteFreshness(t *testing.T) {
+	now := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+
+	w := httptest.NewRecorder()
+	writeFreshness(w, nil, 5*time.Minute, now)
+	if w.Header().Get("Cache-Control") != "public, max-age=300" || w.Header().Get("Age") != "0" || w.Header().Get("Expires") != "Thu, 14 Aug 2025 12:05:00 GMT" {
+		t.Errorf("Expected the fallback lifetime from now, got %v", w.Header())
+	}
+
+	_, tracker := freshness.WithTracker(context.Background())
+	tracker.Observe(http.Header{"Date": {"Thu, 14 Aug 2025 11:58:00 GMT"}, "Cache-Control": {"public, max-age=600"}}, now)
+	tracker.Observe(http.Header{"Date": {"Thu, 14 Aug 2025 11:59:00 GMT"}, "Expires": {"Thu, 14 Aug 2025 12:06:00 GMT"}}, now)
+	w = httptest.NewRecorder()
+	writeFreshness(w, tracker, 5*time.Minute, now)
+	if w.Header().Get("Cache-Control") != "public, max-age=480" || w.Header().Get("Age") != "120" || w.Header().Get("Expires") != "Thu, 14 Aug 2025 12:06:00 GMT" {
+		t.Errorf("Expected the stalest provider answer's lifetime and age, got %v", w.Header())
+	}
+}
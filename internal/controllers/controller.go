@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 )
 
@@ -27,6 +28,9 @@ type Controller[T any] interface {
 type ForecastController interface {
 	Controller[Forecast]
 
+	// CreateBatch handles POST requests creating many forecasts at once
+	CreateBatch(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
 	// GetByCityID handles requests to get forecasts for a specific city
 	GetByCityID(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error
 
@@ -38,6 +42,18 @@ type ForecastController interface {
 
 	// CleanupOldForecasts handles administrative requests to remove old forecasts
 	CleanupOldForecasts(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// BulkDelete handles administrative requests to remove forecasts matching filters
+	BulkDelete(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// GetWindRose handles requests for the distribution of a city's winds by direction
+	GetWindRose(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error
+
+	// GetDailySummaries handles requests for a city's forecasts aggregated by day
+	GetDailySummaries(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error
+
+	// GetStats handles requests for the distribution of a metric over a city's forecasts
+	GetStats(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error
 }
 
 // CityController extends the base controller with city-specific methods
@@ -77,27 +93,68 @@ type PlaceController interface {
 	GetBySourcePlaceID(ctx context.Context, w http.ResponseWriter, r *http.Request) error
 }
 
+// AlertController extends the base controller with alert-specific methods
+type AlertController interface {
+	Controller[Alert]
+
+	// GetActiveByCityID handles requests to get alerts currently in effect for a city
+	GetActiveByCityID(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error
+
+	// GetActiveByCoordinates handles requests to get alerts currently in effect near coordinates
+	GetActiveByCoordinates(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// CleanupExpired handles administrative requests to remove expired alerts
+	CleanupExpired(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// IngestCAP handles administrative requests to store a CAP 1.2 alert message
+	IngestCAP(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Stream handles requests for a Server-Sent Events feed of newly ingested alerts
+	Stream(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
 // Forecast represents the forecast model for controllers
 type Forecast struct {
-	ID             int     `json:"id"`
-	CityID         int     `json:"city_id"`
-	SourceProvider string  `json:"source_provider"`
-	ForecastTime   string  `json:"forecast_time"`
-	ValidTime      string  `json:"valid_time"`
-	Temperature    float64 `json:"temperature"`
-	FeelsLike      float64 `json:"feels_like"`
-	Humidity       float64 `json:"humidity"`
-	Pressure       float64 `json:"pressure"`
-	WindSpeed      float64 `json:"wind_speed"`
-	WindDirection  float64 `json:"wind_direction"`
-	Visibility     float64 `json:"visibility"`
-	CloudCover     float64 `json:"cloud_cover"`
-	Precipitation  float64 `json:"precipitation"`
-	WeatherCode    string  `json:"weather_code"`
-	Description    string  `json:"description"`
-	UVIndex        float64 `json:"uv_index"`
-	CreatedAt      string  `json:"created_at"`
-	UpdatedAt      string  `json:"updated_at"`
+	ID              int     `json:"id"`
+	CityID          int     `json:"city_id"`
+	SourceProvider  string  `json:"source_provider"`
+	ForecastTime    string  `json:"forecast_time"`
+	ValidTime       string  `json:"valid_time"`
+	Temperature     float64 `json:"temperature"`
+	FeelsLike       float64 `json:"feels_like"`
+	Humidity        float64 `json:"humidity"`
+	Pressure        float64 `json:"pressure"`         // mean sea-level pressure
+	StationPressure float64 `json:"station_pressure"` // pressure at station elevation, 0 when unknown
+	WindSpeed       float64 `json:"wind_speed"`
+	WindDirection   float64 `json:"wind_direction"`
+	WindGust        float64 `json:"wind_gust"` // 0 when not reported
+	Visibility      float64 `json:"visibility"`
+	CloudCover      float64 `json:"cloud_cover"`
+	Precipitation   float64 `json:"precipitation"`
+	WeatherCode     string  `json:"weather_code"`              // as the provider reported it
+	NormalizedCode  string  `json:"normalized_code,omitempty"` // weather_code in the canonical set; responses only
+	Icon            string  `json:"icon,omitempty"`            // icon name of normalized_code; responses only
+	Description     string  `json:"description"`
+	UVIndex         float64 `json:"uv_index"`
+	Units           string  `json:"units,omitempty"`            // unit system of the measurements; responses only
+	WindUnits       string  `json:"wind_units,omitempty"`       // unit of wind_speed; responses only
+	WindDescription string  `json:"wind_description,omitempty"` // Beaufort descriptor, e.g. "Gentle breeze"; responses only
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+
+	// Derived where they apply, unless the provider reports them; responses only
+	Dewpoint  *float64 `json:"dewpoint,omitempty"`
+	HeatIndex *float64 `json:"heat_index,omitempty"` // from 26.7 °C (80 °F) with a known humidity
+	WindChill *float64 `json:"wind_chill,omitempty"` // up to 10 °C (50 °F) in winds above 4.8 km/h
+
+	City *ForecastCity `json:"city,omitempty"` // with ?embed=city; responses only
+}
+
+// ForecastCity is the city embedded in a forecast with ?embed=city
+type ForecastCity struct {
+	Name        string `json:"name"`
+	CountryCode string `json:"country_code"`
+	Timezone    string `json:"timezone"`
 }
 
 // City represents the city model for controllers
@@ -117,6 +174,9 @@ type City struct {
 	IsActive    bool    `json:"is_active"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
+
+	// CanonicalName is set when Name has been replaced by a localized name
+	CanonicalName string `json:"canonical_name,omitempty"`
 }
 
 // Place represents the place model for controllers
@@ -141,6 +201,28 @@ type Place struct {
 	UpdatedAt     string  `json:"updated_at"`
 }
 
+// Alert represents the alert model for controllers
+type Alert struct {
+	ID              int     `json:"id"`
+	SourceProvider  string  `json:"source_provider"`
+	ProviderAlertID string  `json:"provider_alert_id"`
+	CityID          int     `json:"city_id,omitempty"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Title           string  `json:"title"`
+	Description     string  `json:"description"`
+	Severity        string  `json:"severity"`
+	Urgency         string  `json:"urgency"`
+	Category        string  `json:"category"`
+	AreaDesc        string  `json:"area_desc"`
+	StartTime       string  `json:"start_time,omitempty"`
+	EndTime         string  `json:"end_time,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+
+	Geometry json.RawMessage `json:"geometry,omitempty"` // GeoJSON Polygon or MultiPolygon of the area
+}
+
 // HTTPError represents a structured HTTP error response
 type HTTPError struct {
 	Status  int    `json:"status"`
@@ -148,13 +230,27 @@ type HTTPError struct {
 	Details string `json:"details,omitempty"`
 }
 
-// PaginatedResponse represents a paginated response structure
+// PaginatedResponse represents a paginated response structure. Limit is the page size
+// applied (the same as PerPage) and MaxLimit the largest the resource allows. Total and
+// TotalPages are left out when the request passes ?include_total=false.
 type PaginatedResponse[T any] struct {
 	Data       []*T `json:"data"`
-	Total      int  `json:"total"`
+	Total      *int `json:"total,omitempty"`
 	Page       int  `json:"page"`
 	PerPage    int  `json:"per_page"`
-	TotalPages int  `json:"total_pages"`
+	Limit      int  `json:"limit"`
+	MaxLimit   int  `json:"max_limit"`
+	TotalPages *int `json:"total_pages,omitempty"`
+}
+
+// CursorResponse represents a keyset-paginated response; NextCursor is passed back as
+// ?cursor= for the following page and is empty on the last one
+type CursorResponse[T any] struct {
+	Data       []*T   `json:"data"`
+	PerPage    int    `json:"per_page"`
+	Limit      int    `json:"limit"`
+	MaxLimit   int    `json:"max_limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 // SuccessResponse represents a standard success response
@@ -3,11 +3,23 @@ package controllers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
 	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+	"stormlightlabs.org/weather_api/internal/weathercode"
 )
 
 // HTTPForecastController implements ForecastController for HTTP requests
@@ -27,26 +39,138 @@ func (c *HTTPForecastController) Create(ctx context.Context, w http.ResponseWrit
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := forecast.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	repoForecast := toRepoForecast(&forecast)
 	if err := c.repo.Create(ctx, repoForecast); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to create forecast", err.Error())
+		return writeRepoError(w, err, "Forecast", "Failed to create forecast")
 	}
 
 	response := fromRepoForecast(repoForecast)
-	return writeSuccess(w, http.StatusCreated, response, "Forecast created successfully")
+	return writeCommitted(w, http.StatusCreated, response, "Forecast created successfully")
+}
+
+// maxBulkForecasts caps how many forecasts one bulk request may create
+const maxBulkForecasts = 10000
+
+// BulkCreateResponse reports the forecasts created by a bulk request, in request order
+type BulkCreateResponse struct {
+	Created int   `json:"created"`
+	IDs     []int `json:"ids"`
+}
+
+// CreateBatch handles POST /forecasts/bulk requests with a JSON array of forecasts.
+// Every forecast is validated before any is stored; a single invalid entry rejects the
+// whole request with its errors keyed by array index.
+func (c *HTTPForecastController) CreateBatch(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var forecasts []Forecast
+	if err := json.NewDecoder(r.Body).Decode(&forecasts); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if len(forecasts) == 0 {
+		return writeError(w, http.StatusBadRequest, "Invalid request", "at least one forecast is required")
+	}
+	if len(forecasts) > maxBulkForecasts {
+		return writeError(w, http.StatusRequestEntityTooLarge, "Too many forecasts",
+			fmt.Sprintf("a bulk request may create at most %d forecasts", maxBulkForecasts))
+	}
+
+	var errs models.ValidationErrors
+	repoForecasts := make([]*repo.Forecast, len(forecasts))
+	for i := range forecasts {
+		for _, e := range forecasts[i].validate() {
+			e.Field = fmt.Sprintf("[%d].%s", i, e.Field)
+			errs = append(errs, e)
+		}
+		repoForecasts[i] = toRepoForecast(&forecasts[i])
+	}
+	if errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	if err := c.repo.CreateBatch(ctx, repoForecasts); err != nil {
+		return writeRepoError(w, err, "Forecast", "Failed to create forecasts")
+	}
+
+	response := &BulkCreateResponse{Created: len(repoForecasts), IDs: make([]int, len(repoForecasts))}
+	for i, f := range repoForecasts {
+		response.IDs[i] = f.ID
+	}
+	return writeCommitted(w, http.StatusCreated, response, "Forecasts created successfully")
 }
 
-// GetByID handles GET requests to retrieve a forecast by ID
+// GetByID handles GET requests to retrieve a forecast by ID, with its city under
+// ?embed=city
 func (c *HTTPForecastController) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	withCity, err := embedCity(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
 	forecast, err := c.repo.GetByID(ctx, id)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "Forecast not found", err.Error())
+		return writeRepoError(w, err, "Forecast", "Failed to retrieve forecast")
+	}
+	if writeNotModified(w, r, forecastETag(forecast, opts, withCity), forecast.UpdatedAt) {
+		return nil
 	}
 
 	response := fromRepoForecast(forecast)
+	if withCity {
+		if err := c.embedCities(ctx, []*Forecast{response}); err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
+		}
+	}
+	convertForecasts(opts, response)
 	return writeSuccess(w, http.StatusOK, response, "")
 }
 
+// forecastETag identifies a forecast rendered in opts' units, with or without its city
+func forecastETag(forecast *repo.Forecast, opts unitOptions, withCity bool) string {
+	return weakETag("forecast", forecast.ID, forecast.UpdatedAt, fmt.Sprint(opts.System), fmt.Sprint(opts.Wind), strconv.FormatBool(withCity))
+}
+
+// embedCities sets the city of forecasts read without one, looking every city of the
+// page up in one query
+func (c *HTTPForecastController) embedCities(ctx context.Context, forecasts []*Forecast) error {
+	var ids []int
+	for _, f := range forecasts {
+		if !slices.Contains(ids, f.CityID) {
+			ids = append(ids, f.CityID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	cities, err := c.repo.GetCities(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, f := range forecasts {
+		if city, ok := cities[f.CityID]; ok {
+			f.City = &ForecastCity{Name: city.Name, CountryCode: city.CountryCode, Timezone: city.Timezone}
+		}
+	}
+	return nil
+}
+
+// cityEmbedder returns embedCities bound to ctx when withCity is set, for
+// writeForecastPage
+func (c *HTTPForecastController) cityEmbedder(ctx context.Context, withCity bool) func([]*Forecast) error {
+	if !withCity {
+		return nil
+	}
+	return func(forecasts []*Forecast) error {
+		return c.embedCities(ctx, forecasts)
+	}
+}
+
 // Update handles PUT requests to update a forecast
 func (c *HTTPForecastController) Update(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	var forecast Forecast
@@ -54,59 +178,119 @@ func (c *HTTPForecastController) Update(ctx context.Context, w http.ResponseWrit
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := forecast.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	forecast.ID = id
 	repoForecast := toRepoForecast(&forecast)
 	if err := c.repo.Update(ctx, repoForecast); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to update forecast", err.Error())
+		return writeRepoError(w, err, "Forecast", "Failed to update forecast")
 	}
 
 	response := fromRepoForecast(repoForecast)
-	return writeSuccess(w, http.StatusOK, response, "Forecast updated successfully")
+	return writeCommitted(w, http.StatusOK, response, "Forecast updated successfully")
 }
 
 // Delete handles DELETE requests to remove a forecast
 func (c *HTTPForecastController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	if err := c.repo.Delete(ctx, id); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to delete forecast", err.Error())
+		return writeRepoError(w, err, "Forecast", "Failed to delete forecast")
 	}
 
-	return writeSuccess(w, http.StatusOK, nil, "Forecast deleted successfully")
+	return writeCommitted(w, http.StatusOK, nil, "Forecast deleted successfully")
 }
 
-// List handles GET requests to retrieve forecasts with pagination
+// List handles GET requests to retrieve forecasts with pagination.
+// With ?format=csv or ?format=ndjson every forecast is streamed instead, and with
+// ?cursor= pages are keyset-paginated by (valid_time, id), newest first. With
+// ?embed=city each forecast of a page carries its city's name and timezone, joined in
+// the list query for numbered pages.
 func (c *HTTPForecastController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	page, limit := getPagination(r)
-	offset := (page - 1) * limit
-
-	forecasts, err := c.repo.List(ctx, limit, offset)
+	opts, err := requestUnits(w, r)
 	if err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
-
-	total, err := c.repo.Count(ctx)
+	withCity, err := embedCity(r)
 	if err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to count forecasts", err.Error())
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	if withCity && exportFormat(r) != "" {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "embed=city is not supported with exports")
 	}
 
-	var response []*Forecast
-	for _, f := range forecasts {
-		response = append(response, fromRepoForecast(f))
+	if format := exportFormat(r); format != "" {
+		return streamForecasts(ctx, w, format, "forecasts", opts, c.repo.ListAfter)
+	}
+	if r.URL.Query().Has("cursor") {
+		return writeForecastPage(w, r, opts, func(cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+			return c.repo.ListAfter(ctx, cursor, limit)
+		}, c.cityEmbedder(ctx, withCity))
 	}
 
-	paginated := &PaginatedResponse[Forecast]{
-		Data:       response,
-		Total:      total,
-		Page:       page,
-		PerPage:    limit,
-		TotalPages: (total + limit - 1) / limit,
+	page, limit := getPagination(r, ResourceForecasts)
+	offset := (page - 1) * limit
+	withTotal, err := includeTotal(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
 
+	var response []*Forecast
+	if withCity {
+		forecasts, err := c.repo.ListWithCities(ctx, limit, offset)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+		}
+		for _, f := range forecasts {
+			response = append(response, fromRepoCityForecast(f))
+		}
+	} else {
+		forecasts, err := c.repo.List(ctx, limit, offset)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+		}
+		for _, f := range forecasts {
+			response = append(response, fromRepoForecast(f))
+		}
+	}
+
+	var total *int
+	if withTotal {
+		count, err := c.repo.Count(ctx)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to count forecasts", err.Error())
+		}
+		total = &count
+	}
+	convertForecasts(opts, response...)
+
+	paginated := newPaginatedResponse(response, total, page, limit, ResourceForecasts)
+
 	return writePaginated(w, paginated)
 }
 
-// GetByCityID handles requests to get forecasts for a specific city
+// GetByCityID handles requests to get forecasts for a specific city. With ?cursor= the
+// response is a keyset-paginated CursorResponse instead of a plain array, and with
+// ?embed=city each forecast carries the city.
 func (c *HTTPForecastController) GetByCityID(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
-	page, limit := getPagination(r)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	withCity, err := embedCity(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	if r.URL.Query().Has("cursor") {
+		return writeForecastPage(w, r, opts, func(cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+			return c.repo.GetByCityIDAfter(ctx, cityID, cursor, limit)
+		}, c.cityEmbedder(ctx, withCity))
+	}
+
+	page, limit := getPagination(r, ResourceForecasts)
 	offset := (page - 1) * limit
 
 	forecasts, err := c.repo.GetByCityID(ctx, cityID, limit, offset)
@@ -118,22 +302,55 @@ func (c *HTTPForecastController) GetByCityID(ctx context.Context, w http.Respons
 	for _, f := range forecasts {
 		response = append(response, fromRepoForecast(f))
 	}
+	if withCity {
+		if err := c.embedCities(ctx, response); err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
+		}
+	}
+	convertForecasts(opts, response...)
 
 	return writeJSON(w, http.StatusOK, response)
 }
 
-// GetLatestByCityID handles requests to get the latest forecast for a city
+// GetLatestByCityID handles requests to get the latest forecast for a city, with the
+// city under ?embed=city
 func (c *HTTPForecastController) GetLatestByCityID(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	withCity, err := embedCity(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
 	forecast, err := c.repo.GetLatestByCityID(ctx, cityID)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "Latest forecast not found", err.Error())
+		return writeRepoError(w, err, "Latest forecast", "Failed to retrieve latest forecast")
+	}
+	// The tag names the forecast, so it changes when a newer one becomes the latest
+	if writeNotModified(w, r, forecastETag(forecast, opts, withCity), forecast.UpdatedAt) {
+		return nil
 	}
 
 	response := fromRepoForecast(forecast)
+	if withCity {
+		if err := c.embedCities(ctx, []*Forecast{response}); err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
+		}
+	}
+	convertForecasts(opts, response)
 	return writeSuccess(w, http.StatusOK, response, "")
 }
 
-// GetByTimeRange handles requests to get forecasts within a time range
+// maxTimeRangeDays caps the span between start_time and end_time of a time range query
+const maxTimeRangeDays = 366
+
+// GetByTimeRange handles GET /forecasts/range?start_time=&end_time= requests for the
+// forecasts valid within a time range, newest first. With ?format=csv or
+// ?format=ndjson the whole range is streamed in the same order instead of one page.
 func (c *HTTPForecastController) GetByTimeRange(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	startTime := r.URL.Query().Get("start_time")
 	endTime := r.URL.Query().Get("end_time")
@@ -141,8 +358,30 @@ func (c *HTTPForecastController) GetByTimeRange(ctx context.Context, w http.Resp
 	if startTime == "" || endTime == "" {
 		return writeError(w, http.StatusBadRequest, "Missing parameters", "start_time and end_time are required")
 	}
+	start, startErr := time.Parse(time.RFC3339, startTime)
+	end, endErr := time.Parse(time.RFC3339, endTime)
+	switch {
+	case startErr != nil || endErr != nil:
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "start_time and end_time must be RFC 3339 timestamps")
+	case start.After(end):
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "start_time must not be after end_time")
+	case end.Sub(start) > maxTimeRangeDays*24*time.Hour:
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", fmt.Sprintf("the range between start_time and end_time must not exceed %d days", maxTimeRangeDays))
+	}
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	if format := exportFormat(r); format != "" {
+		fetch := func(ctx context.Context, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+			return c.repo.GetByTimeRangeAfter(ctx, startTime, endTime, cursor, limit)
+		}
+		return streamForecasts(ctx, w, format, "forecasts", opts, fetch)
+	}
 
-	page, limit := getPagination(r)
+	page, limit := getPagination(r, ResourceForecasts)
 	offset := (page - 1) * limit
 
 	forecasts, err := c.repo.GetByTimeRange(ctx, startTime, endTime, limit, offset)
@@ -154,23 +393,106 @@ func (c *HTTPForecastController) GetByTimeRange(ctx context.Context, w http.Resp
 	for _, f := range forecasts {
 		response = append(response, fromRepoForecast(f))
 	}
+	convertForecasts(opts, response...)
 
 	return writeJSON(w, http.StatusOK, response)
 }
 
-// CleanupOldForecasts handles administrative requests to remove old forecasts
+// CleanupResult is the body of a forecast cleanup response
+type CleanupResult struct {
+	Days    int   `json:"days"`
+	Deleted int64 `json:"deleted"`
+}
+
+// CleanupOldForecasts handles DELETE /forecasts/expired?days=&city_id=&provider=
+// requests, removing forecasts valid more than days (default 30) ago, optionally only
+// those of one city or provider
 func (c *HTTPForecastController) CleanupOldForecasts(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	daysStr := r.URL.Query().Get("days")
+	query := r.URL.Query()
+	daysStr := query.Get("days")
 	days, err := strconv.Atoi(daysStr)
 	if err != nil || days <= 0 {
 		days = 30 // Default to 30 days
 	}
 
-	if err := c.repo.DeleteOldForecasts(ctx, days); err != nil {
+	filter := repo.ForecastFilter{SourceProvider: query.Get("provider")}
+	if value := query.Get("city_id"); value != "" {
+		cityID, err := strconv.Atoi(value)
+		if err != nil || cityID <= 0 {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "city_id must be a positive integer")
+		}
+		filter.CityID = cityID
+	}
+
+	deleted, err := c.repo.DeleteOldForecasts(ctx, days, filter)
+	if err != nil {
 		return writeError(w, http.StatusInternalServerError, "Failed to cleanup forecasts", err.Error())
 	}
 
-	return writeSuccess(w, http.StatusOK, nil, fmt.Sprintf("Cleaned up forecasts older than %d days", days))
+	return writeCommitted(w, http.StatusOK, &CleanupResult{Days: days, Deleted: deleted},
+		fmt.Sprintf("Cleaned up %d forecasts older than %d days", deleted, days))
+}
+
+// bulkDeleteBatchSize is the number of forecasts removed per statement by BulkDelete
+const bulkDeleteBatchSize = 1000
+
+// BulkDeleteResult is the body of a bulk delete response
+type BulkDeleteResult struct {
+	DryRun  bool  `json:"dry_run"`
+	Matched int64 `json:"matched,omitempty"`
+	Deleted int64 `json:"deleted,omitempty"`
+}
+
+// BulkDelete handles DELETE /forecasts?city_id=&provider=&before=. At least one filter
+// is required. Requests are a dry run reporting how many forecasts match unless they
+// pass dry_run=false, so a mistyped filter can be previewed before anything is removed.
+func (c *HTTPForecastController) BulkDelete(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	filter := repo.ForecastFilter{SourceProvider: query.Get("provider")}
+
+	if value := query.Get("city_id"); value != "" {
+		cityID, err := strconv.Atoi(value)
+		if err != nil || cityID <= 0 {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "city_id must be a positive integer")
+		}
+		filter.CityID = cityID
+	}
+	if value := query.Get("before"); value != "" {
+		before, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "before must be an RFC3339 timestamp")
+		}
+		filter.Before = before.UTC().Format(time.RFC3339)
+	}
+	if filter.IsEmpty() {
+		return writeError(w, http.StatusBadRequest, "Missing parameters", "at least one of city_id, provider or before is required")
+	}
+
+	dryRun := true
+	if value := query.Get("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "dry_run must be true or false")
+		}
+		dryRun = parsed
+	}
+
+	if dryRun {
+		matched, err := c.repo.CountMatching(ctx, filter)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to count forecasts", err.Error())
+		}
+		return writeSuccess(w, http.StatusOK, &BulkDeleteResult{DryRun: true, Matched: matched},
+			fmt.Sprintf("%d forecasts would be deleted; repeat with dry_run=false to delete them", matched))
+	}
+
+	deleted, err := c.repo.DeleteMatching(ctx, filter, bulkDeleteBatchSize)
+	if err != nil {
+		// Batches that completed stay deleted, so report how far the delete got
+		return writeError(w, http.StatusInternalServerError, "Failed to delete forecasts",
+			fmt.Sprintf("%s (%d deleted before the failure)", err.Error(), deleted))
+	}
+	return writeCommitted(w, http.StatusOK, &BulkDeleteResult{Deleted: deleted}, fmt.Sprintf("Deleted %d forecasts", deleted))
 }
 
 // HTTPCityController implements CityController for HTTP requests
@@ -190,23 +512,33 @@ func (c *HTTPCityController) Create(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := city.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	repoCity := toRepoCity(&city)
 	if err := c.repo.Create(ctx, repoCity); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to create city", err.Error())
+		return writeRepoError(w, err, "City", "Failed to create city")
 	}
 
 	response := fromRepoCity(repoCity)
-	return writeSuccess(w, http.StatusCreated, response, "City created successfully")
+	return writeCommitted(w, http.StatusCreated, response, "City created successfully")
 }
 
 // GetByID handles GET requests to retrieve a city by ID
 func (c *HTTPCityController) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	city, err := c.repo.GetByID(ctx, id)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "City not found", err.Error())
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
 	}
 
 	response := fromRepoCity(city)
+	c.localizeCities(ctx, w, r, []*City{response})
+	// Localized names are stored apart from the city, so the name in the response is
+	// part of the tag
+	if writeNotModified(w, r, weakETag("city", city.ID, city.UpdatedAt, response.Name), city.UpdatedAt) {
+		return nil
+	}
 	return writeSuccess(w, http.StatusOK, response, "")
 }
 
@@ -217,53 +549,64 @@ func (c *HTTPCityController) Update(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := city.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	city.ID = id
 	repoCity := toRepoCity(&city)
 	if err := c.repo.Update(ctx, repoCity); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to update city", err.Error())
+		return writeRepoError(w, err, "City", "Failed to update city")
 	}
 
 	response := fromRepoCity(repoCity)
-	return writeSuccess(w, http.StatusOK, response, "City updated successfully")
+	return writeCommitted(w, http.StatusOK, response, "City updated successfully")
 }
 
 // Delete handles DELETE requests to remove a city
 func (c *HTTPCityController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	if err := c.repo.Delete(ctx, id); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to delete city", err.Error())
+		return writeRepoError(w, err, "City", "Failed to delete city")
 	}
 
-	return writeSuccess(w, http.StatusOK, nil, "City deleted successfully")
+	return writeCommitted(w, http.StatusOK, nil, "City deleted successfully")
 }
 
 // List handles GET requests to retrieve cities with pagination
 func (c *HTTPCityController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	page, limit := getPagination(r)
+	page, limit := getPagination(r, ResourceCities)
 	offset := (page - 1) * limit
+	withTotal, err := includeTotal(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
 
 	cities, err := c.repo.List(ctx, limit, offset)
 	if err != nil {
 		return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
 	}
 
-	total, err := c.repo.Count(ctx)
-	if err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to count cities", err.Error())
+	var total *int
+	if withTotal {
+		count, err := c.repo.Count(ctx)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to count cities", err.Error())
+		}
+		total = &count
 	}
 
 	var response []*City
 	for _, city := range cities {
 		response = append(response, fromRepoCity(city))
 	}
+	c.localizeCities(ctx, w, r, response)
 
-	paginated := &PaginatedResponse[City]{
-		Data:       response,
-		Total:      total,
-		Page:       page,
-		PerPage:    limit,
-		TotalPages: (total + limit - 1) / limit,
-	}
+	paginated := newPaginatedResponse(response, total, page, limit, ResourceCities)
 
+	if wantsGeoJSON(w, r) {
+		collection := withPagination(newFeatureCollection(response), paginated)
+		return writeGeoJSON(w, http.StatusOK, collection)
+	}
 	return writePaginated(w, paginated)
 }
 
@@ -274,11 +617,7 @@ func (c *HTTPCityController) Search(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Missing parameter", "q (query) parameter is required")
 	}
 
-	limitStr := r.URL.Query().Get("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 20
-	}
+	_, limit := getPagination(r, ResourceCities)
 
 	cities, err := c.repo.Search(ctx, query, limit)
 	if err != nil {
@@ -289,7 +628,11 @@ func (c *HTTPCityController) Search(ctx context.Context, w http.ResponseWriter,
 	for _, city := range cities {
 		response = append(response, fromRepoCity(city))
 	}
+	c.localizeCities(ctx, w, r, response)
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
@@ -304,13 +647,17 @@ func (c *HTTPCityController) GetByName(ctx context.Context, w http.ResponseWrite
 	for _, city := range cities {
 		response = append(response, fromRepoCity(city))
 	}
+	c.localizeCities(ctx, w, r, response)
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
 // GetByCountry handles requests to get cities in a specific country
 func (c *HTTPCityController) GetByCountry(ctx context.Context, w http.ResponseWriter, r *http.Request, countryCode string) error {
-	page, limit := getPagination(r)
+	page, limit := getPagination(r, ResourceCities)
 	offset := (page - 1) * limit
 
 	cities, err := c.repo.GetByCountry(ctx, countryCode, limit, offset)
@@ -322,36 +669,27 @@ func (c *HTTPCityController) GetByCountry(ctx context.Context, w http.ResponseWr
 	for _, city := range cities {
 		response = append(response, fromRepoCity(city))
 	}
+	c.localizeCities(ctx, w, r, response)
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
 // GetByCoordinates handles requests to find cities near coordinates
 func (c *HTTPCityController) GetByCoordinates(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	latStr := r.URL.Query().Get("lat")
-	lonStr := r.URL.Query().Get("lon")
-	radiusStr := r.URL.Query().Get("radius")
-
-	lat, err := strconv.ParseFloat(latStr, 64)
+	lat, lon, err := geo.ParseCoordinates(r.URL.Query().Get("lat"), r.URL.Query().Get("lon"))
 	if err != nil {
-		return writeError(w, http.StatusBadRequest, "Invalid parameter", "lat must be a valid float")
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
 
-	lon, err := strconv.ParseFloat(lonStr, 64)
+	radius, err := queryRadius(r, 50.0)
 	if err != nil {
-		return writeError(w, http.StatusBadRequest, "Invalid parameter", "lon must be a valid float")
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
 
-	radius, err := strconv.ParseFloat(radiusStr, 64)
-	if err != nil || radius <= 0 {
-		radius = 50.0 // Default 50km radius
-	}
-
-	limitStr := r.URL.Query().Get("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10
-	}
+	_, limit := getPagination(r, ResourceCities)
 
 	cities, err := c.repo.GetByCoordinates(ctx, lat, lon, radius, limit)
 	if err != nil {
@@ -362,7 +700,11 @@ func (c *HTTPCityController) GetByCoordinates(ctx context.Context, w http.Respon
 	for _, city := range cities {
 		response = append(response, fromRepoCity(city))
 	}
+	c.localizeCities(ctx, w, r, response)
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
@@ -370,10 +712,16 @@ func (c *HTTPCityController) GetByCoordinates(ctx context.Context, w http.Respon
 func (c *HTTPCityController) GetByGeonameID(ctx context.Context, w http.ResponseWriter, r *http.Request, geonameID int) error {
 	city, err := c.repo.GetByGeonameID(ctx, geonameID)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "City not found", err.Error())
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
 	}
 
 	response := fromRepoCity(city)
+	c.localizeCities(ctx, w, r, []*City{response})
+	// Localized names are stored apart from the city, so the name in the response is
+	// part of the tag
+	if writeNotModified(w, r, weakETag("city", city.ID, city.UpdatedAt, response.Name), city.UpdatedAt) {
+		return nil
+	}
 	return writeSuccess(w, http.StatusOK, response, "")
 }
 
@@ -394,20 +742,24 @@ func (c *HTTPPlaceController) Create(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := place.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	repoPlace := toRepoPlace(&place)
 	if err := c.repo.Create(ctx, repoPlace); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to create place", err.Error())
+		return writeRepoError(w, err, "Place", "Failed to create place")
 	}
 
 	response := fromRepoPlace(repoPlace)
-	return writeSuccess(w, http.StatusCreated, response, "Place created successfully")
+	return writeCommitted(w, http.StatusCreated, response, "Place created successfully")
 }
 
 // GetByID handles GET requests to retrieve a place by ID
 func (c *HTTPPlaceController) GetByID(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	place, err := c.repo.GetByID(ctx, id)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "Place not found", err.Error())
+		return writeRepoError(w, err, "Place", "Failed to retrieve place")
 	}
 
 	response := fromRepoPlace(place)
@@ -421,38 +773,50 @@ func (c *HTTPPlaceController) Update(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
 	}
 
+	if errs := place.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
 	place.ID = id
 	repoPlace := toRepoPlace(&place)
 	if err := c.repo.Update(ctx, repoPlace); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to update place", err.Error())
+		return writeRepoError(w, err, "Place", "Failed to update place")
 	}
 
 	response := fromRepoPlace(repoPlace)
-	return writeSuccess(w, http.StatusOK, response, "Place updated successfully")
+	return writeCommitted(w, http.StatusOK, response, "Place updated successfully")
 }
 
 // Delete handles DELETE requests to remove a place
 func (c *HTTPPlaceController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
 	if err := c.repo.Delete(ctx, id); err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to delete place", err.Error())
+		return writeRepoError(w, err, "Place", "Failed to delete place")
 	}
 
-	return writeSuccess(w, http.StatusOK, nil, "Place deleted successfully")
+	return writeCommitted(w, http.StatusOK, nil, "Place deleted successfully")
 }
 
 // List handles GET requests to retrieve places with pagination
 func (c *HTTPPlaceController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	page, limit := getPagination(r)
+	page, limit := getPagination(r, ResourcePlaces)
 	offset := (page - 1) * limit
+	withTotal, err := includeTotal(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
 
 	places, err := c.repo.List(ctx, limit, offset)
 	if err != nil {
 		return writeError(w, http.StatusInternalServerError, "Failed to retrieve places", err.Error())
 	}
 
-	total, err := c.repo.Count(ctx)
-	if err != nil {
-		return writeError(w, http.StatusInternalServerError, "Failed to count places", err.Error())
+	var total *int
+	if withTotal {
+		count, err := c.repo.Count(ctx)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to count places", err.Error())
+		}
+		total = &count
 	}
 
 	var response []*Place
@@ -460,14 +824,12 @@ func (c *HTTPPlaceController) List(ctx context.Context, w http.ResponseWriter, r
 		response = append(response, fromRepoPlace(place))
 	}
 
-	paginated := &PaginatedResponse[Place]{
-		Data:       response,
-		Total:      total,
-		Page:       page,
-		PerPage:    limit,
-		TotalPages: (total + limit - 1) / limit,
-	}
+	paginated := newPaginatedResponse(response, total, page, limit, ResourcePlaces)
 
+	if wantsGeoJSON(w, r) {
+		collection := withPagination(newFeatureCollection(response), paginated)
+		return writeGeoJSON(w, http.StatusOK, collection)
+	}
 	return writePaginated(w, paginated)
 }
 
@@ -478,11 +840,7 @@ func (c *HTTPPlaceController) Search(ctx context.Context, w http.ResponseWriter,
 		return writeError(w, http.StatusBadRequest, "Missing parameter", "q (query) parameter is required")
 	}
 
-	limitStr := r.URL.Query().Get("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 20
-	}
+	_, limit := getPagination(r, ResourcePlaces)
 
 	places, err := c.repo.Search(ctx, query, limit)
 	if err != nil {
@@ -494,35 +852,25 @@ func (c *HTTPPlaceController) Search(ctx context.Context, w http.ResponseWriter,
 		response = append(response, fromRepoPlace(place))
 	}
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
 // GetByCoordinates handles requests to find places near coordinates
 func (c *HTTPPlaceController) GetByCoordinates(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
-	latStr := r.URL.Query().Get("lat")
-	lonStr := r.URL.Query().Get("lon")
-	radiusStr := r.URL.Query().Get("radius")
-
-	lat, err := strconv.ParseFloat(latStr, 64)
+	lat, lon, err := geo.ParseCoordinates(r.URL.Query().Get("lat"), r.URL.Query().Get("lon"))
 	if err != nil {
-		return writeError(w, http.StatusBadRequest, "Invalid parameter", "lat must be a valid float")
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
 
-	lon, err := strconv.ParseFloat(lonStr, 64)
+	radius, err := queryRadius(r, 10.0)
 	if err != nil {
-		return writeError(w, http.StatusBadRequest, "Invalid parameter", "lon must be a valid float")
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
 	}
 
-	radius, err := strconv.ParseFloat(radiusStr, 64)
-	if err != nil || radius <= 0 {
-		radius = 10.0 // Default 10km radius for places
-	}
-
-	limitStr := r.URL.Query().Get("limit")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 10
-	}
+	_, limit := getPagination(r, ResourcePlaces)
 
 	places, err := c.repo.GetByCoordinates(ctx, lat, lon, radius, limit)
 	if err != nil {
@@ -534,12 +882,15 @@ func (c *HTTPPlaceController) GetByCoordinates(ctx context.Context, w http.Respo
 		response = append(response, fromRepoPlace(place))
 	}
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
 // GetBySource handles requests to get places from a specific geocoding source
 func (c *HTTPPlaceController) GetBySource(ctx context.Context, w http.ResponseWriter, r *http.Request, source string) error {
-	page, limit := getPagination(r)
+	page, limit := getPagination(r, ResourcePlaces)
 	offset := (page - 1) * limit
 
 	places, err := c.repo.GetBySource(ctx, source, limit, offset)
@@ -552,6 +903,9 @@ func (c *HTTPPlaceController) GetBySource(ctx context.Context, w http.ResponseWr
 		response = append(response, fromRepoPlace(place))
 	}
 
+	if wantsGeoJSON(w, r) {
+		return writeGeoJSON(w, http.StatusOK, newFeatureCollection(response))
+	}
 	return writeJSON(w, http.StatusOK, response)
 }
 
@@ -566,7 +920,7 @@ func (c *HTTPPlaceController) GetBySourcePlaceID(ctx context.Context, w http.Res
 
 	place, err := c.repo.GetBySourcePlaceID(ctx, source, sourcePlaceID)
 	if err != nil {
-		return writeError(w, http.StatusNotFound, "Place not found", err.Error())
+		return writeRepoError(w, err, "Place", "Failed to retrieve place")
 	}
 
 	response := fromRepoPlace(place)
@@ -576,50 +930,106 @@ func (c *HTTPPlaceController) GetBySourcePlaceID(ctx context.Context, w http.Res
 // Helper functions for model conversion
 func toRepoForecast(f *Forecast) *repo.Forecast {
 	return &repo.Forecast{
-		ID:             f.ID,
-		CityID:         f.CityID,
-		SourceProvider: f.SourceProvider,
-		ForecastTime:   f.ForecastTime,
-		ValidTime:      f.ValidTime,
-		Temperature:    f.Temperature,
-		FeelsLike:      f.FeelsLike,
-		Humidity:       f.Humidity,
-		Pressure:       f.Pressure,
-		WindSpeed:      f.WindSpeed,
-		WindDirection:  f.WindDirection,
-		Visibility:     f.Visibility,
-		CloudCover:     f.CloudCover,
-		Precipitation:  f.Precipitation,
-		WeatherCode:    f.WeatherCode,
-		Description:    f.Description,
-		UVIndex:        f.UVIndex,
-		CreatedAt:      f.CreatedAt,
-		UpdatedAt:      f.UpdatedAt,
+		ID:              f.ID,
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime,
+		ValidTime:       f.ValidTime,
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+		CreatedAt:       f.CreatedAt,
+		UpdatedAt:       f.UpdatedAt,
 	}
 }
 
 func fromRepoForecast(f *repo.Forecast) *Forecast {
-	return &Forecast{
-		ID:             f.ID,
-		CityID:         f.CityID,
-		SourceProvider: f.SourceProvider,
-		ForecastTime:   f.ForecastTime,
-		ValidTime:      f.ValidTime,
-		Temperature:    f.Temperature,
-		FeelsLike:      f.FeelsLike,
-		Humidity:       f.Humidity,
-		Pressure:       f.Pressure,
-		WindSpeed:      f.WindSpeed,
-		WindDirection:  f.WindDirection,
-		Visibility:     f.Visibility,
-		CloudCover:     f.CloudCover,
-		Precipitation:  f.Precipitation,
-		WeatherCode:    f.WeatherCode,
-		Description:    f.Description,
-		UVIndex:        f.UVIndex,
-		CreatedAt:      f.CreatedAt,
-		UpdatedAt:      f.UpdatedAt,
+	return normalizeWeatherCode(deriveMetrics(&Forecast{
+		ID:              f.ID,
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime,
+		ValidTime:       f.ValidTime,
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+		CreatedAt:       f.CreatedAt,
+		UpdatedAt:       f.UpdatedAt,
+	}))
+}
+
+// deriveMetrics sets the dew point, heat index and wind chill of a metric forecast
+// where they apply
+func deriveMetrics(f *Forecast) *Forecast {
+	derived := func(value float64, ok bool) *float64 {
+		if !ok {
+			return nil
+		}
+		value = units.Round(value, 1)
+		return &value
+	}
+	f.Dewpoint = derived(calc.DewPoint(f.Temperature, f.Humidity))
+	f.HeatIndex = derived(calc.HeatIndex(f.Temperature, f.Humidity))
+	f.WindChill = derived(calc.WindChill(f.Temperature, f.WindSpeed))
+	return f
+}
+
+// normalizeWeatherCode sets the canonical code and icon of a forecast's weather code,
+// and its description when the provider gave none
+func normalizeWeatherCode(f *Forecast) *Forecast {
+	condition, ok := weathercode.Normalize(f.WeatherCode)
+	if !ok {
+		return f
+	}
+	f.NormalizedCode, f.Icon = condition.Code, condition.Icon
+	if f.Description == "" {
+		f.Description = condition.Description
+	}
+	return f
+}
+
+// embedCity reports whether a forecast listing embeds cities: ?embed= is a
+// comma-separated list of related resources, of which only city exists
+func embedCity(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("embed")
+	if value == "" {
+		return false, nil
+	}
+	for name := range strings.SplitSeq(value, ",") {
+		if strings.TrimSpace(name) != "city" {
+			return false, fmt.Errorf("embed must be city, got %q", name)
+		}
 	}
+	return true, nil
+}
+
+// fromRepoCityForecast converts a forecast read with its city
+func fromRepoCityForecast(f *repo.CityForecast) *Forecast {
+	forecast := fromRepoForecast(&f.Forecast)
+	forecast.City = &ForecastCity{Name: f.City.Name, CountryCode: f.City.CountryCode, Timezone: f.City.Timezone}
+	return forecast
 }
 
 func toRepoCity(c *City) *repo.City {
@@ -724,32 +1134,44 @@ func writeError(w http.ResponseWriter, status int, message, details string) erro
 	return writeJSON(w, status, err)
 }
 
+// writeRepoError writes a repository error with the status its kind maps to: 404 when
+// the resource is missing, 409 for a duplicate and 422 for other constraint violations,
+// such as a reference to a missing city. Anything else is a 500 with failure as the
+// message.
+func writeRepoError(w http.ResponseWriter, err error, resource, failure string) error {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		return writeError(w, http.StatusNotFound, resource+" not found", err.Error())
+	case errors.Is(err, repo.ErrDuplicate):
+		return writeError(w, http.StatusConflict, resource+" already exists", err.Error())
+	case errors.Is(err, repo.ErrConstraint):
+		return writeError(w, http.StatusUnprocessableEntity, failure, err.Error())
+	default:
+		return writeError(w, http.StatusInternalServerError, failure, err.Error())
+	}
+}
+
 func writeSuccess(w http.ResponseWriter, status int, data any, message string) error {
-	response := map[string]any{
+	return writeJSON(w, status, successBody(data, message))
+}
+
+// writeCommitted writes a success response for a request that changed stored data. It
+// carries a consistency token that clients pass back on later reads so those reads
+// observe the write.
+func writeCommitted(w http.ResponseWriter, status int, data any, message string) error {
+	w.Header().Set(repo.ConsistencyHeader, repo.NewConsistencyToken(time.Now()))
+	return writeSuccess(w, status, data, message)
+}
+
+// successBody is the envelope written by writeSuccess
+func successBody(data any, message string) map[string]any {
+	return map[string]any{
 		"success": true,
 		"data":    data,
 		"message": message,
 	}
-	return writeJSON(w, status, response)
 }
 
 func writePaginated(w http.ResponseWriter, data any) error {
 	return writeJSON(w, http.StatusOK, data)
 }
-
-func getPagination(r *http.Request) (page, limit int) {
-	pageStr := r.URL.Query().Get("page")
-	limitStr := r.URL.Query().Get("limit")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page <= 0 {
-		page = 1
-	}
-
-	limit, err = strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 || limit > 100 {
-		limit = 20 // Default limit with max of 100
-	}
-
-	return page, limit
-}
@@ -4,9 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"stormlightlabs.org/weather_api/internal/repo"
 )
@@ -18,6 +21,9 @@ type MockForecastRepository struct {
 	forecasts   []*repo.Forecast
 	forecast    *repo.Forecast
 	count       int
+	cursor      *repo.ForecastCursor // last cursor passed to ListAfter
+
+	cleanupFilter repo.ForecastFilter // last filter passed to DeleteOldForecasts
 }
 
 func (m *MockForecastRepository) Create(ctx context.Context, forecast *repo.Forecast) error {
@@ -28,6 +34,17 @@ func (m *MockForecastRepository) Create(ctx context.Context, forecast *repo.Fore
 	return nil
 }
 
+func (m *MockForecastRepository) CreateBatch(ctx context.Context, forecasts []*repo.Forecast) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	for i, f := range forecasts {
+		f.ID = i + 1
+	}
+	m.forecasts = append(m.forecasts, forecasts...)
+	return nil
+}
+
 func (m *MockForecastRepository) GetByID(ctx context.Context, id int) (*repo.Forecast, error) {
 	if m.shouldError {
 		return nil, &repoError{msg: m.errorMsg}
@@ -56,6 +73,28 @@ func (m *MockForecastRepository) List(ctx context.Context, limit, offset int) ([
 	return m.forecasts, nil
 }
 
+func (m *MockForecastRepository) ListWithCities(ctx context.Context, limit, offset int) ([]*repo.CityForecast, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	var forecasts []*repo.CityForecast
+	for _, f := range m.forecasts {
+		forecasts = append(forecasts, &repo.CityForecast{Forecast: *f, City: repo.ForecastCity{Name: "Test City", CountryCode: "US", Timezone: "America/New_York"}})
+	}
+	return forecasts, nil
+}
+
+func (m *MockForecastRepository) GetCities(ctx context.Context, cityIDs []int) (map[int]repo.ForecastCity, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	cities := make(map[int]repo.ForecastCity, len(cityIDs))
+	for _, id := range cityIDs {
+		cities[id] = repo.ForecastCity{Name: "Test City", CountryCode: "US", Timezone: "America/New_York"}
+	}
+	return cities, nil
+}
+
 func (m *MockForecastRepository) Count(ctx context.Context) (int, error) {
 	if m.shouldError {
 		return 0, &repoError{msg: m.errorMsg}
@@ -70,6 +109,25 @@ func (m *MockForecastRepository) GetByCityID(ctx context.Context, cityID int, li
 	return m.forecasts, nil
 }
 
+func (m *MockForecastRepository) ListAfter(ctx context.Context, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	m.cursor = cursor
+	if limit < len(m.forecasts) {
+		return m.forecasts[:limit], nil
+	}
+	return m.forecasts, nil
+}
+
+func (m *MockForecastRepository) GetByCityIDAfter(ctx context.Context, cityID int, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+	return m.ListAfter(ctx, cursor, limit)
+}
+
+func (m *MockForecastRepository) GetByTimeRangeAfter(ctx context.Context, startTime, endTime string, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+	return m.ListAfter(ctx, cursor, limit)
+}
+
 func (m *MockForecastRepository) GetByTimeRange(ctx context.Context, startTime, endTime string, limit, offset int) ([]*repo.Forecast, error) {
 	if m.shouldError {
 		return nil, &repoError{msg: m.errorMsg}
@@ -84,11 +142,110 @@ func (m *MockForecastRepository) GetLatestByCityID(ctx context.Context, cityID i
 	return m.forecast, nil
 }
 
-func (m *MockForecastRepository) DeleteOldForecasts(ctx context.Context, days int) error {
+func (m *MockForecastRepository) DeleteOldForecasts(ctx context.Context, days int, filter repo.ForecastFilter) (int64, error) {
 	if m.shouldError {
-		return &repoError{msg: m.errorMsg}
+		return 0, &repoError{msg: m.errorMsg}
 	}
-	return nil
+	m.cleanupFilter = filter
+	return int64(len(m.forecasts)), nil
+}
+
+func (m *MockForecastRepository) CountMatching(ctx context.Context, filter repo.ForecastFilter) (int64, error) {
+	if m.shouldError {
+		return 0, &repoError{msg: m.errorMsg}
+	}
+	var count int64
+	for _, f := range m.forecasts {
+		if filter.CityID == 0 || f.CityID == filter.CityID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockForecastRepository) DeleteMatching(ctx context.Context, filter repo.ForecastFilter, batchSize int) (int64, error) {
+	if m.shouldError {
+		return 0, &repoError{msg: m.errorMsg}
+	}
+	count, _ := m.CountMatching(ctx, filter)
+	var kept []*repo.Forecast
+	for _, f := range m.forecasts {
+		if filter.CityID != 0 && f.CityID != filter.CityID {
+			kept = append(kept, f)
+		}
+	}
+	m.forecasts = kept
+	return count, nil
+}
+
+func (m *MockForecastRepository) WindRose(ctx context.Context, cityID int, startTime, endTime string) (*repo.WindRose, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	rose := &repo.WindRose{}
+	for _, f := range m.forecasts {
+		if f.CityID != cityID {
+			continue
+		}
+		rose.Total++
+		if f.WindSpeed < repo.CalmWindSpeed {
+			rose.Calm++
+			continue
+		}
+		sector := &rose.Sectors[int(f.WindDirection/22.5+0.5)%repo.WindRoseSectors]
+		sector.MeanSpeed = (sector.MeanSpeed*float64(sector.Count) + f.WindSpeed) / float64(sector.Count+1)
+		sector.Count++
+	}
+	return rose, nil
+}
+
+func (m *MockForecastRepository) DailySummaries(ctx context.Context, cityID int, startTime, endTime, timezone string) ([]*repo.DailySummary, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	var days []*repo.DailySummary
+	for _, f := range m.forecasts {
+		if f.CityID != cityID {
+			continue
+		}
+		date := f.ValidTime[:10]
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &repo.DailySummary{Date: date, MinTemperature: f.Temperature, MaxTemperature: f.Temperature, WeatherCode: f.WeatherCode})
+		}
+		day := days[len(days)-1]
+		day.Periods++
+		day.MinTemperature = min(day.MinTemperature, f.Temperature)
+		day.MaxTemperature = max(day.MaxTemperature, f.Temperature)
+		day.Precipitation += f.Precipitation
+		day.MaxWindSpeed = max(day.MaxWindSpeed, f.WindSpeed)
+	}
+	return days, nil
+}
+
+func (m *MockForecastRepository) Stats(ctx context.Context, cityID int, metric, startTime, endTime, bucket string) ([]*repo.MetricStats, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	var stats *repo.MetricStats
+	for _, f := range m.forecasts {
+		if f.CityID != cityID {
+			continue
+		}
+		if stats == nil {
+			stats = &repo.MetricStats{Start: startTime, Min: f.Temperature, Max: f.Temperature}
+		}
+		stats.Count++
+		stats.Min = min(stats.Min, f.Temperature)
+		stats.Max = max(stats.Max, f.Temperature)
+		stats.Mean += (f.Temperature - stats.Mean) / float64(stats.Count)
+	}
+	if stats == nil {
+		return nil, nil
+	}
+	for range repo.StatsPercentiles {
+		stats.Percentiles = append(stats.Percentiles, stats.Mean)
+	}
+	return []*repo.MetricStats{stats}, nil
 }
 
 // MockCityRepository implements repo.CityRepository for testing
@@ -98,6 +255,8 @@ type MockCityRepository struct {
 	cities      []*repo.City
 	city        *repo.City
 	count       int
+
+	localizedNames map[int]map[string]string
 }
 
 func (m *MockCityRepository) Create(ctx context.Context, city *repo.City) error {
@@ -115,6 +274,13 @@ func (m *MockCityRepository) GetByID(ctx context.Context, id int) (*repo.City, e
 	return m.city, nil
 }
 
+func (m *MockCityRepository) GetByIDs(ctx context.Context, ids []int) ([]*repo.City, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.cities, nil
+}
+
 func (m *MockCityRepository) Update(ctx context.Context, city *repo.City) error {
 	if m.shouldError {
 		return &repoError{msg: m.errorMsg}
@@ -178,6 +344,20 @@ func (m *MockCityRepository) Search(ctx context.Context, query string, limit int
 	return m.cities, nil
 }
 
+func (m *MockCityRepository) SetLocalizedName(ctx context.Context, name *repo.CityName) error {
+	if m.shouldError {
+		return &repoError{msg: m.errorMsg}
+	}
+	return nil
+}
+
+func (m *MockCityRepository) GetLocalizedNames(ctx context.Context, cityIDs []int, languages []string) (map[int]map[string]string, error) {
+	if m.shouldError {
+		return nil, &repoError{msg: m.errorMsg}
+	}
+	return m.localizedNames, nil
+}
+
 // MockPlaceRepository implements repo.PlaceRepository for testing
 type MockPlaceRepository struct {
 	shouldError bool
@@ -267,79 +447,19 @@ func (e *repoError) Error() string {
 }
 
 func createTestRepoForecast() *repo.Forecast {
-	return &repo.Forecast{
-		ID:             1,
-		CityID:         123,
-		SourceProvider: "NOAA",
-		ForecastTime:   "2024-01-15T12:00:00Z",
-		ValidTime:      "2024-01-15T15:00:00Z",
-		Temperature:    20.5,
-		Humidity:       65.0,
-		Pressure:       1013.25,
-		WindSpeed:      5.5,
-		WindDirection:  180.0,
-		CloudCover:     25.0,
-		Precipitation:  0.0,
-		WeatherCode:    "partly_cloudy",
-		Description:    "Partly cloudy",
-		UVIndex:        3.0,
-		CreatedAt:      "2024-01-15T12:00:00Z",
-		UpdatedAt:      "2024-01-15T12:00:00Z",
-	}
+	return toRepoForecast(exampleStoredForecast())
 }
 
 func createTestControllerForecast() *Forecast {
-	return &Forecast{
-		CityID:         123,
-		SourceProvider: "NOAA",
-		ForecastTime:   "2024-01-15T12:00:00Z",
-		ValidTime:      "2024-01-15T15:00:00Z",
-		Temperature:    20.5,
-		Humidity:       65.0,
-		Pressure:       1013.25,
-		WindSpeed:      5.5,
-		WindDirection:  180.0,
-		CloudCover:     25.0,
-		Precipitation:  0.0,
-		WeatherCode:    "partly_cloudy",
-		Description:    "Partly cloudy",
-		UVIndex:        3.0,
-	}
+	return exampleForecast()
 }
 
 func createTestRepoCity() *repo.City {
-	return &repo.City{
-		ID:          1,
-		Name:        "San Francisco",
-		Country:     "United States",
-		CountryCode: "US",
-		Region:      "California",
-		Latitude:    37.7749,
-		Longitude:   -122.4194,
-		Population:  884363,
-		Timezone:    "America/Los_Angeles",
-		IsActive:    true,
-		CreatedAt:   "2024-01-15T12:00:00Z",
-		UpdatedAt:   "2024-01-15T12:00:00Z",
-	}
+	return toRepoCity(exampleStoredCity())
 }
 
 func createTestRepoPlace() *repo.Place {
-	return &repo.Place{
-		ID:           1,
-		DisplayName:  "Golden Gate Bridge",
-		AddressLine1: "Golden Gate Bridge",
-		City:         "San Francisco",
-		Region:       "California",
-		Country:      "United States",
-		CountryCode:  "US",
-		Latitude:     37.8199,
-		Longitude:    -122.4783,
-		Confidence:   0.95,
-		Source:       "Nominatim",
-		CreatedAt:    "2024-01-15T12:00:00Z",
-		UpdatedAt:    "2024-01-15T12:00:00Z",
-	}
+	return toRepoPlace(exampleStoredPlace())
 }
 
 func TestControllers(t *testing.T) {
@@ -370,6 +490,9 @@ func TestControllers(t *testing.T) {
 			if w.Code != http.StatusCreated {
 				t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
 			}
+			if _, err := repo.ParseConsistencyToken(w.Header().Get(repo.ConsistencyHeader)); err != nil {
+				t.Errorf("Expected a consistency token, got %v", err)
+			}
 		})
 
 		t.Run("Create error", func(t *testing.T) {
@@ -387,6 +510,94 @@ func TestControllers(t *testing.T) {
 			if w.Code != http.StatusInternalServerError {
 				t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
 			}
+			if w.Header().Get(repo.ConsistencyHeader) != "" {
+				t.Error("Expected no consistency token for a failed write")
+			}
+		})
+
+		t.Run("consistency token reaches reads", func(t *testing.T) {
+			var pinned bool
+			handler := HandlerFunc(func(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+				pinned = repo.RequiresPrimary(ctx)
+				return nil
+			})
+
+			req := httptest.NewRequest("GET", "/forecasts", nil)
+			req.Header.Set(repo.ConsistencyHeader, repo.NewConsistencyToken(time.Now()))
+			handler(httptest.NewRecorder(), req)
+			if !pinned {
+				t.Error("Expected the read to require the primary")
+			}
+
+			handler(httptest.NewRecorder(), httptest.NewRequest("GET", "/forecasts", nil))
+			if pinned {
+				t.Error("Expected reads without a token to use any source")
+			}
+		})
+
+		t.Run("CreateBatch success", func(t *testing.T) {
+			mockRepo := &MockForecastRepository{}
+			controller := NewHTTPForecastController(mockRepo)
+
+			body, _ := json.Marshal([]*Forecast{createTestControllerForecast(), createTestControllerForecast()})
+			req := httptest.NewRequest("POST", "/forecasts/bulk", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			if err := controller.CreateBatch(context.Background(), w, req); err != nil {
+				t.Errorf("Expected no error, got: %v", err)
+			}
+			if w.Code != http.StatusCreated {
+				t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+			}
+
+			var response struct {
+				Data BulkCreateResponse `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Data.Created != 2 || len(response.Data.IDs) != 2 || len(mockRepo.forecasts) != 2 {
+				t.Errorf("Expected 2 forecasts created, got %+v", response.Data)
+			}
+		})
+
+		t.Run("CreateBatch rejects invalid entries", func(t *testing.T) {
+			mockRepo := &MockForecastRepository{}
+			controller := NewHTTPForecastController(mockRepo)
+
+			invalid := createTestControllerForecast()
+			invalid.Humidity = 150
+			body, _ := json.Marshal([]*Forecast{createTestControllerForecast(), invalid})
+			req := httptest.NewRequest("POST", "/forecasts/bulk", bytes.NewReader(body))
+			w := httptest.NewRecorder()
+
+			_ = controller.CreateBatch(context.Background(), w, req)
+
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+			}
+			var response ValidationErrorResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Errors) != 1 || response.Errors[0].Field != "[1].humidity" {
+				t.Errorf("Expected error for [1].humidity, got %+v", response.Errors)
+			}
+			if len(mockRepo.forecasts) != 0 {
+				t.Error("Expected nothing to be stored")
+			}
+		})
+
+		t.Run("CreateBatch empty", func(t *testing.T) {
+			controller := NewHTTPForecastController(&MockForecastRepository{})
+			req := httptest.NewRequest("POST", "/forecasts/bulk", bytes.NewReader([]byte("[]")))
+			w := httptest.NewRecorder()
+
+			_ = controller.CreateBatch(context.Background(), w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
 		})
 
 		t.Run("GetByID success", func(t *testing.T) {
@@ -424,6 +635,67 @@ func TestControllers(t *testing.T) {
 			}
 		})
 
+		t.Run("List with embedded cities", func(t *testing.T) {
+			mockRepo := &MockForecastRepository{forecast: createTestRepoForecast(), forecasts: []*repo.Forecast{createTestRepoForecast()}, count: 1}
+			controller := NewHTTPForecastController(mockRepo)
+
+			w := httptest.NewRecorder()
+			if err := controller.List(context.Background(), w, httptest.NewRequest("GET", "/forecasts?embed=city", nil)); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			var response PaginatedResponse[Forecast]
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatal(err)
+			}
+			if len(response.Data) != 1 || response.Data[0].City == nil || response.Data[0].City.Timezone != "America/New_York" {
+				t.Errorf("Expected the city embedded in each forecast, got %+v", response.Data)
+			}
+
+			for _, query := range []string{"?embed=station", "?embed=city&format=csv"} {
+				w := httptest.NewRecorder()
+				_ = controller.List(context.Background(), w, httptest.NewRequest("GET", "/forecasts"+query, nil))
+				if w.Code != http.StatusBadRequest {
+					t.Errorf("%s: expected status %d, got %d", query, http.StatusBadRequest, w.Code)
+				}
+			}
+
+			pages := map[string]func(w http.ResponseWriter, r *http.Request) error{
+				"List": func(w http.ResponseWriter, r *http.Request) error { return controller.List(context.Background(), w, r) },
+				"GetByCityID": func(w http.ResponseWriter, r *http.Request) error {
+					return controller.GetByCityID(context.Background(), w, r, 1)
+				},
+			}
+			for name, handler := range pages {
+				w := httptest.NewRecorder()
+				if err := handler(w, httptest.NewRequest("GET", "/forecasts?embed=city&cursor=", nil)); err != nil {
+					t.Fatal(err)
+				}
+				var page CursorResponse[Forecast]
+				if err := json.NewDecoder(w.Body).Decode(&page); err != nil {
+					t.Fatal(err)
+				}
+				if len(page.Data) != 1 || page.Data[0].City == nil || page.Data[0].City.Name != "Test City" {
+					t.Errorf("%s: expected the city embedded in cursor pages, got %d %+v", name, w.Code, page.Data)
+				}
+			}
+
+			w = httptest.NewRecorder()
+			_ = controller.GetByCityID(context.Background(), w, httptest.NewRequest("GET", "/cities/1/forecasts?embed=city", nil), 1)
+			var byCity []*Forecast
+			if err := json.NewDecoder(w.Body).Decode(&byCity); err != nil || len(byCity) != 1 || byCity[0].City == nil {
+				t.Errorf("Expected the city embedded in a city's forecasts, got %s", w.Body.String())
+			}
+
+			w = httptest.NewRecorder()
+			_ = controller.GetByID(context.Background(), w, httptest.NewRequest("GET", "/forecasts/1?embed=city", nil), 1)
+			var single struct {
+				Data Forecast `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&single); err != nil || single.Data.City == nil || single.Data.City.CountryCode != "US" {
+				t.Errorf("Expected the city embedded in a single forecast, got %s", w.Body.String())
+			}
+		})
+
 		t.Run("GetByCityID", func(t *testing.T) {
 			forecasts := []*repo.Forecast{createTestRepoForecast()}
 			mockRepo := &MockForecastRepository{forecasts: forecasts}
@@ -457,6 +729,56 @@ func TestControllers(t *testing.T) {
 			if w.Code != http.StatusOK {
 				t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
 			}
+
+			mockRepo.forecasts = []*repo.Forecast{{ID: 1}, {ID: 2}}
+			w = httptest.NewRecorder()
+			_ = controller.CleanupOldForecasts(context.Background(), w, httptest.NewRequest("DELETE", "/forecasts/expired?days=7&city_id=5&provider=NWS", nil))
+			var body struct{ Data CleanupResult }
+			_ = json.NewDecoder(w.Body).Decode(&body)
+			if body.Data.Days != 7 || body.Data.Deleted != 2 {
+				t.Errorf("Expected 2 deleted over 7 days, got %+v", body.Data)
+			}
+			if mockRepo.cleanupFilter != (repo.ForecastFilter{CityID: 5, SourceProvider: "NWS"}) {
+				t.Errorf("Expected the city and provider to narrow the cleanup, got %+v", mockRepo.cleanupFilter)
+			}
+
+			w = httptest.NewRecorder()
+			_ = controller.CleanupOldForecasts(context.Background(), w, httptest.NewRequest("DELETE", "/forecasts/expired?city_id=x", nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d for an invalid city_id, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+
+		t.Run("BulkDelete", func(t *testing.T) {
+			mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{{ID: 1, CityID: 7}, {ID: 2, CityID: 7}, {ID: 3, CityID: 8}}}
+			controller := NewHTTPForecastController(mockRepo)
+
+			bulkDelete := func(query string) (*httptest.ResponseRecorder, BulkDeleteResult) {
+				w := httptest.NewRecorder()
+				_ = controller.BulkDelete(context.Background(), w, httptest.NewRequest("DELETE", "/forecasts?"+query, nil))
+				var body struct{ Data BulkDeleteResult }
+				_ = json.NewDecoder(w.Body).Decode(&body)
+				return w, body.Data
+			}
+
+			for _, query := range []string{"", "city_id=-1", "before=yesterday", "city_id=7&dry_run=maybe"} {
+				if w, _ := bulkDelete(query); w.Code != http.StatusBadRequest {
+					t.Errorf("Expected %q to be rejected, got %d", query, w.Code)
+				}
+			}
+
+			w, result := bulkDelete("city_id=7")
+			if w.Code != http.StatusOK || !result.DryRun || result.Matched != 2 || len(mockRepo.forecasts) != 3 {
+				t.Errorf("Expected a dry run matching 2 forecasts by default, got %d %+v", w.Code, result)
+			}
+
+			w, result = bulkDelete("city_id=7&dry_run=false")
+			if w.Code != http.StatusOK || result.DryRun || result.Deleted != 2 || len(mockRepo.forecasts) != 1 {
+				t.Errorf("Expected 2 forecasts deleted, got %d %+v", w.Code, result)
+			}
+			if w.Header().Get(repo.ConsistencyHeader) == "" {
+				t.Error("Expected a consistency token after deleting")
+			}
 		})
 	})
 
@@ -623,3 +945,30 @@ func BenchmarkControllers(b *testing.B) {
 		}
 	})
 }
+
+func TestWriteRepoError(t *testing.T) {
+	tests := []struct {
+		err         error
+		wantStatus  int
+		wantMessage string
+	}{
+		{fmt.Errorf("city lookup: %w", repo.ErrNotFound), http.StatusNotFound, "City not found"},
+		{fmt.Errorf("failed to create city: %w", repo.ErrDuplicate), http.StatusConflict, "City already exists"},
+		{fmt.Errorf("failed to create city: %w", repo.ErrConstraint), http.StatusUnprocessableEntity, "Failed to create city"},
+		{errors.New("connection refused"), http.StatusInternalServerError, "Failed to create city"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		_ = writeRepoError(w, tt.err, "City", "Failed to create city")
+		if w.Code != tt.wantStatus {
+			t.Errorf("Expected status %d for %v, got %d", tt.wantStatus, tt.err, w.Code)
+		}
+		var body HTTPError
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if body.Message != tt.wantMessage {
+			t.Errorf("Expected message %q for %v, got %q", tt.wantMessage, tt.err, body.Message)
+		}
+	}
+}
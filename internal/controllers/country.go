@@ -0,0 +1,217 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+const (
+	// countrySummaryPageSize is the number of a country's cities loaded at a time
+	countrySummaryPageSize = 500
+
+	// currentConditionsMaxAge is how old a city's newest stored forecast may be and
+	// still count as its current conditions
+	currentConditionsMaxAge = 3 * time.Hour
+)
+
+// CountryController handles country-level aggregates over stored cities
+type CountryController interface {
+	// GetSummary handles requests for a country's current conditions summary
+	GetSummary(ctx context.Context, w http.ResponseWriter, r *http.Request, countryCode string) error
+}
+
+// CountrySummary aggregates the current conditions of a country's active cities.
+// Temperature is weighted by population; cities without a recorded population count
+// once, as do all cities when none has one.
+type CountrySummary struct {
+	CountryCode     string       `json:"country_code"`
+	Country         string       `json:"country"`
+	Units           string       `json:"units"`
+	WindUnits       string       `json:"wind_units"`
+	Cities          int          `json:"cities"`
+	CitiesReporting int          `json:"cities_reporting"` // cities with a current forecast
+	Population      int          `json:"population"`       // of the reporting cities
+	Temperature     *float64     `json:"temperature"`      // null when no city reports
+	Warmest         *CityExtreme `json:"warmest,omitempty"`
+	Coldest         *CityExtreme `json:"coldest,omitempty"`
+	Windiest        *CityExtreme `json:"windiest,omitempty"`
+	Wettest         *CityExtreme `json:"wettest,omitempty"` // omitted when it is dry everywhere
+	SevereAlerts    int          `json:"severe_alerts"`     // active Severe or Extreme alerts
+	AlertedCities   int          `json:"alerted_cities"`    // cities under a severe alert
+	GeneratedAt     string       `json:"generated_at"`
+}
+
+// CityExtreme is the city holding one of a summary's extremes
+type CityExtreme struct {
+	CityID    int     `json:"city_id"`
+	Name      string  `json:"name"`
+	Value     float64 `json:"value"`
+	ValidTime string  `json:"valid_time"`
+}
+
+// HTTPCountryController implements CountryController for HTTP requests
+type HTTPCountryController struct {
+	cities    repo.CityRepository
+	forecasts repo.ForecastRepository
+	alerts    repo.AlertRepository
+	now       func() time.Time
+}
+
+// NewHTTPCountryController creates a new HTTP country controller
+func NewHTTPCountryController(cities repo.CityRepository, forecasts repo.ForecastRepository, alerts repo.AlertRepository) CountryController {
+	return &HTTPCountryController{cities: cities, forecasts: forecasts, alerts: alerts, now: time.Now}
+}
+
+// GetSummary handles GET /countries/{code}/summary requests. A city's current
+// conditions are its newest stored forecast valid before the end of the current hour,
+// ignored once older than currentConditionsMaxAge.
+func (c *HTTPCountryController) GetSummary(ctx context.Context, w http.ResponseWriter, r *http.Request, countryCode string) error {
+	countryCode = strings.ToUpper(countryCode)
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("country_code", countryCode))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	cities, err := c.activeCities(ctx, countryCode)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
+	}
+	if len(cities) == 0 {
+		return writeError(w, http.StatusNotFound, "Country not found", "no active cities are stored for "+countryCode)
+	}
+
+	now := c.now().UTC()
+	summary := &CountrySummary{
+		CountryCode: countryCode,
+		Country:     cities[0].Country,
+		Units:       string(opts.System),
+		WindUnits:   string(opts.Wind),
+		Cities:      len(cities),
+		GeneratedAt: now.Format(time.RFC3339),
+	}
+
+	var weighted, unweighted, weights float64
+	severe := map[int]bool{}
+	for _, city := range cities {
+		current, err := c.currentForecast(ctx, city.ID, now)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+		}
+		if current != nil {
+			summary.CitiesReporting++
+			summary.Population += city.Population
+			weighted += current.Temperature * float64(max(city.Population, 1))
+			weights += float64(max(city.Population, 1))
+			unweighted += current.Temperature
+
+			summary.Warmest = extreme(summary.Warmest, city, current, current.Temperature, func(a, b float64) bool { return a > b })
+			summary.Coldest = extreme(summary.Coldest, city, current, current.Temperature, func(a, b float64) bool { return a < b })
+			summary.Windiest = extreme(summary.Windiest, city, current, max(current.WindSpeed, current.WindGust), func(a, b float64) bool { return a > b })
+			if current.Precipitation > 0 {
+				summary.Wettest = extreme(summary.Wettest, city, current, current.Precipitation, func(a, b float64) bool { return a > b })
+			}
+		}
+
+		alerts, err := c.alerts.GetActiveByCityID(ctx, city.ID)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve alerts", err.Error())
+		}
+		alerted := false
+		for _, alert := range alerts {
+			if alert.Severity == "Severe" || alert.Severity == "Extreme" {
+				severe[alert.ID] = true
+				alerted = true
+			}
+		}
+		if alerted {
+			summary.AlertedCities++
+		}
+	}
+	summary.SevereAlerts = len(severe)
+
+	if summary.CitiesReporting > 0 {
+		mean := unweighted / float64(summary.CitiesReporting)
+		if summary.Population > 0 {
+			mean = weighted / weights
+		}
+		summary.Temperature = &mean
+	}
+	convertCountrySummary(summary, opts)
+
+	return writeJSON(w, http.StatusOK, summary)
+}
+
+// activeCities loads every active city stored for a country
+func (c *HTTPCountryController) activeCities(ctx context.Context, countryCode string) ([]*repo.City, error) {
+	var active []*repo.City
+	for offset := 0; ; offset += countrySummaryPageSize {
+		page, err := c.cities.GetByCountry(ctx, countryCode, countrySummaryPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, city := range page {
+			if city.IsActive {
+				active = append(active, city)
+			}
+		}
+		if len(page) < countrySummaryPageSize {
+			return active, nil
+		}
+	}
+}
+
+// currentForecast returns a city's current conditions, or nil when none are stored
+func (c *HTTPCountryController) currentForecast(ctx context.Context, cityID int, now time.Time) (*repo.Forecast, error) {
+	cursor := &repo.ForecastCursor{ValidTime: now.Truncate(time.Hour).Add(time.Hour).Format(time.RFC3339)}
+	newest, err := c.forecasts.GetByCityIDAfter(ctx, cityID, cursor, 1)
+	if err != nil || len(newest) == 0 {
+		return nil, err
+	}
+	if now.Sub(parseRepoTime(newest[0].ValidTime)) > currentConditionsMaxAge {
+		return nil, nil
+	}
+	return newest[0], nil
+}
+
+// extreme returns the city holding value when it beats the current extreme
+func extreme(current *CityExtreme, city *repo.City, f *repo.Forecast, value float64, beats func(a, b float64) bool) *CityExtreme {
+	if current != nil && !beats(value, current.Value) {
+		return current
+	}
+	return &CityExtreme{CityID: city.ID, Name: city.Name, Value: value, ValidTime: formatTime(parseRepoTime(f.ValidTime))}
+}
+
+// convertCountrySummary rounds a summary and converts it from metric to the requested units
+func convertCountrySummary(s *CountrySummary, opts unitOptions) {
+	temperature := func(c float64) float64 {
+		if opts.System == units.Imperial {
+			c = units.CelsiusToFahrenheit(c)
+		}
+		return units.Round(c, 1)
+	}
+	if s.Temperature != nil {
+		value := temperature(*s.Temperature)
+		s.Temperature = &value
+	}
+	for _, e := range []*CityExtreme{s.Warmest, s.Coldest} {
+		if e != nil {
+			e.Value = temperature(e.Value)
+		}
+	}
+	if s.Windiest != nil {
+		s.Windiest.Value = units.ConvertWindSpeed(s.Windiest.Value, opts.Wind)
+	}
+	if s.Wettest != nil && opts.System == units.Imperial {
+		s.Wettest.Value = units.Round(units.MillimetersToInches(s.Wettest.Value), 2)
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPCountryController_GetSummary(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+
+	cities := []*repo.City{
+		{Name: "London", Country: "United Kingdom", CountryCode: "GB", Population: 9000000, IsActive: true},
+		{Name: "Inverness", Country: "United Kingdom", CountryCode: "GB", Population: 1000000, IsActive: true},
+		{Name: "Stale", Country: "United Kingdom", CountryCode: "GB", Population: 500000, IsActive: true},
+		{Name: "Retired", Country: "United Kingdom", CountryCode: "GB", Population: 100, IsActive: false},
+		{Name: "Paris", Country: "France", CountryCode: "FR", Population: 2000000, IsActive: true},
+	}
+	for _, city := range cities {
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := func(city *repo.City, age time.Duration, temperature, wind, precipitation float64) {
+		valid := now.Add(-age).Format(time.RFC3339)
+		f := &repo.Forecast{CityID: city.ID, SourceProvider: "Stub", ForecastTime: valid, ValidTime: valid,
+			Temperature: temperature, WindSpeed: wind, Precipitation: precipitation}
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store(cities[0], 5*time.Hour, 30, 1, 0)
+	store(cities[0], 0, 20, 4, 0)
+	store(cities[1], 0, 10, 12, 2.5)
+	store(cities[2], 6*time.Hour, 40, 30, 9)
+	store(cities[4], 0, 35, 1, 0)
+
+	end := now.Add(time.Hour).Format(time.RFC3339)
+	for _, alert := range []*repo.Alert{
+		{SourceProvider: "Stub", ProviderAlertID: "1", CityID: cities[1].ID, Severity: "Severe", EndTime: end},
+		{SourceProvider: "Stub", ProviderAlertID: "2", CityID: cities[1].ID, Severity: "Minor", EndTime: end},
+		{SourceProvider: "Stub", ProviderAlertID: "3", CityID: cities[4].ID, Severity: "Extreme", EndTime: end},
+	} {
+		if err := engine.Alerts().Upsert(ctx, alert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	controller := NewHTTPCountryController(engine.Cities(), engine.Forecasts(), engine.Alerts())
+	summary := func(t *testing.T, code, query string) (*httptest.ResponseRecorder, *CountrySummary) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/countries/"+code+"/summary"+query, nil)
+		if err := controller.GetSummary(ctx, w, r, code); err != nil {
+			t.Fatalf("GetSummary failed: %v", err)
+		}
+		var response CountrySummary
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return w, &response
+	}
+
+	t.Run("aggregates active cities", func(t *testing.T) {
+		w, s := summary(t, "gb", "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if s.CountryCode != "GB" || s.Cities != 3 || s.CitiesReporting != 2 || s.Population != 10000000 {
+			t.Errorf("Expected 3 active cities with 2 reporting, got %+v", s)
+		}
+		if s.Temperature == nil || *s.Temperature != 19 {
+			t.Errorf("Expected a population-weighted 19°C, got %v", s.Temperature)
+		}
+		if s.Warmest == nil || s.Warmest.Name != "London" || s.Warmest.Value != 20 {
+			t.Errorf("Expected London warmest at 20°C, got %+v", s.Warmest)
+		}
+		if s.Coldest == nil || s.Coldest.Name != "Inverness" || s.Windiest == nil || s.Windiest.Name != "Inverness" {
+			t.Errorf("Expected Inverness coldest and windiest, got %+v and %+v", s.Coldest, s.Windiest)
+		}
+		if s.Wettest == nil || s.Wettest.Value != 2.5 {
+			t.Errorf("Expected Inverness wettest at 2.5mm, got %+v", s.Wettest)
+		}
+		if s.SevereAlerts != 1 || s.AlertedCities != 1 {
+			t.Errorf("Expected 1 severe alert over 1 city, got %d over %d", s.SevereAlerts, s.AlertedCities)
+		}
+	})
+
+	t.Run("converts units", func(t *testing.T) {
+		_, s := summary(t, "GB", "?units=imperial")
+		if s.Units != "imperial" || s.Temperature == nil || *s.Temperature != 66.2 || s.Warmest.Value != 68 {
+			t.Errorf("Expected Fahrenheit, got %+v", s)
+		}
+	})
+
+	t.Run("unknown country", func(t *testing.T) {
+		if w, _ := summary(t, "ZZ", ""); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
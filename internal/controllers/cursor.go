@@ -0,0 +1,120 @@
+package controllers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// errInvalidCursor is returned for cursors that were not produced by encodeForecastCursor
+var errInvalidCursor = errors.New("cursor is invalid; pass back next_cursor from a previous page")
+
+// encodeForecastCursor renders a keyset position as an opaque URL-safe token
+func encodeForecastCursor(cursor *repo.ForecastCursor) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor.ValidTime + "|" + strconv.Itoa(cursor.ID)))
+}
+
+// decodeForecastCursor parses a ?cursor= token; an empty token is the first page and
+// decodes to nil
+func decodeForecastCursor(token string) (*repo.ForecastCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errInvalidCursor
+	}
+	validTime, idText, ok := strings.Cut(string(data), "|")
+	if !ok {
+		return nil, errInvalidCursor
+	}
+	if _, err := time.Parse(time.RFC3339, validTime); err != nil {
+		return nil, errInvalidCursor
+	}
+	id, err := strconv.Atoi(idText)
+	if err != nil || id <= 0 {
+		return nil, errInvalidCursor
+	}
+	return &repo.ForecastCursor{ValidTime: validTime, ID: id}, nil
+}
+
+// writeForecastPage writes one keyset page of forecasts from fetch, passing it through
+// embed when set. One extra row is requested to tell whether a next page exists
+// without counting.
+func writeForecastPage(w http.ResponseWriter, r *http.Request, opts unitOptions, fetch func(*repo.ForecastCursor, int) ([]*repo.Forecast, error),
+	embed func([]*Forecast) error) error {
+	cursor, err := decodeForecastCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	_, limit := getPagination(r, ResourceForecasts)
+
+	forecasts, err := fetch(cursor, limit+1)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+	}
+
+	page := &CursorResponse[Forecast]{
+		Data:     make([]*Forecast, 0, limit),
+		PerPage:  limit,
+		Limit:    limit,
+		MaxLimit: pageSize(ResourceForecasts).Max,
+	}
+	if len(forecasts) > limit {
+		forecasts = forecasts[:limit]
+		last := forecasts[limit-1]
+		page.NextCursor = encodeForecastCursor(&repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID})
+	}
+	for _, f := range forecasts {
+		page.Data = append(page.Data, fromRepoForecast(f))
+	}
+	if embed != nil {
+		if err := embed(page.Data); err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve cities", err.Error())
+		}
+	}
+	convertForecasts(opts, page.Data...)
+
+	return writeJSON(w, http.StatusOK, page)
+}
+
+// ForecastPage is one keyset page of forecasts, for the GraphQL and gRPC APIs
+type ForecastPage struct {
+	Nodes      []*Forecast
+	NextCursor string
+}
+
+// fetchForecastPage reads the limit forecasts following token, from every city or from
+// cityID when it is positive. Like writeForecastPage it fetches one extra row to tell
+// whether a next page exists.
+func fetchForecastPage(ctx context.Context, forecasts repo.ForecastRepository, cityID int, token string, limit int) (*ForecastPage, error) {
+	cursor, err := decodeForecastCursor(token)
+	if err != nil {
+		return nil, err
+	}
+	var rows []*repo.Forecast
+	if cityID > 0 {
+		rows, err = forecasts.GetByCityIDAfter(ctx, cityID, cursor, limit+1)
+	} else {
+		rows, err = forecasts.ListAfter(ctx, cursor, limit+1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	page := &ForecastPage{Nodes: make([]*Forecast, 0, limit)}
+	if len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		page.NextCursor = encodeForecastCursor(&repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID})
+	}
+	for _, f := range rows {
+		page.Nodes = append(page.Nodes, fromRepoForecast(f))
+	}
+	return page, nil
+}
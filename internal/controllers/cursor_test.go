@@ -0,0 +1,69 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestForecastCursorEncoding(t *testing.T) {
+	cursor := &repo.ForecastCursor{ValidTime: "2024-01-15T12:00:00Z", ID: 42}
+	decoded, err := decodeForecastCursor(encodeForecastCursor(cursor))
+	if err != nil || *decoded != *cursor {
+		t.Errorf("Round trip mismatch: %+v (%v)", decoded, err)
+	}
+
+	if decoded, err := decodeForecastCursor(""); decoded != nil || err != nil {
+		t.Errorf("Expected an empty cursor to decode to the first page, got %+v (%v)", decoded, err)
+	}
+	for _, token := range []string{"!!", "bm8tc2VwYXJhdG9y", encodeForecastCursor(&repo.ForecastCursor{ValidTime: "yesterday", ID: 1})} {
+		if _, err := decodeForecastCursor(token); err == nil {
+			t.Errorf("Expected %q to be rejected", token)
+		}
+	}
+}
+
+func TestForecastCursorPagination(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{
+		{ID: 3, CityID: 1, ValidTime: "2024-01-15T14:00:00Z"},
+		{ID: 2, CityID: 1, ValidTime: "2024-01-15T13:00:00Z"},
+		{ID: 1, CityID: 1, ValidTime: "2024-01-15T12:00:00Z"},
+	}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	get := func(target string) (int, CursorResponse[Forecast]) {
+		w := httptest.NewRecorder()
+		if err := controller.List(context.Background(), w, httptest.NewRequest("GET", target, nil)); err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		var page CursorResponse[Forecast]
+		_ = json.NewDecoder(w.Body).Decode(&page)
+		return w.Code, page
+	}
+
+	code, page := get("/forecasts?cursor=&limit=2")
+	if code != http.StatusOK || len(page.Data) != 2 || page.NextCursor == "" {
+		t.Fatalf("Expected a first page of 2 with a next cursor, got %d %+v", code, page)
+	}
+	if mockRepo.cursor != nil {
+		t.Errorf("Expected the first page to start without a cursor, got %+v", mockRepo.cursor)
+	}
+
+	code, page = get("/forecasts?limit=2&cursor=" + page.NextCursor)
+	if code != http.StatusOK || mockRepo.cursor == nil || mockRepo.cursor.ID != 2 {
+		t.Errorf("Expected the cursor of forecast 2 to be passed to the repository, got %d %+v", code, mockRepo.cursor)
+	}
+
+	code, page = get("/forecasts?cursor=&limit=5")
+	if code != http.StatusOK || len(page.Data) != 3 || page.NextCursor != "" {
+		t.Errorf("Expected a single last page without a next cursor, got %+v", page)
+	}
+
+	if code, _ := get("/forecasts?cursor=garbage!"); code != http.StatusBadRequest {
+		t.Errorf("Expected status %d for a malformed cursor, got %d", http.StatusBadRequest, code)
+	}
+}
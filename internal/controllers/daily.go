@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+	"stormlightlabs.org/weather_api/internal/weathercode"
+)
+
+const (
+	defaultDailyDays = 7
+	maxDailyDays     = 16
+)
+
+// DailySummariesResponse is a city's stored forecasts aggregated by local day
+type DailySummariesResponse struct {
+	CityID   int            `json:"city_id"`
+	Timezone string         `json:"timezone"`
+	Units    units.Labels   `json:"units"`
+	Days     []DailySummary `json:"days"`
+}
+
+// DailySummary is one local day of forecasts. Days without stored forecasts are
+// omitted rather than reported as zeros.
+type DailySummary struct {
+	Date           string  `json:"date"`
+	Periods        int     `json:"periods"`
+	MinTemperature float64 `json:"min_temperature"`
+	MaxTemperature float64 `json:"max_temperature"`
+	Precipitation  float64 `json:"precipitation"`
+	MaxWindSpeed   float64 `json:"max_wind_speed"`
+	WeatherCode    string  `json:"weather_code,omitempty"`
+	NormalizedCode string  `json:"normalized_code,omitempty"`
+}
+
+// GetDailySummaries handles GET /cities/{id}/forecasts/daily?days=&timezone= requests,
+// aggregating the stored forecasts for today and the following days (7 by default) in
+// SQL. Days run midnight to midnight in timezone, an IANA name defaulting to UTC.
+func (c *HTTPForecastController) GetDailySummaries(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	timezone := r.URL.Query().Get("timezone")
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "timezone must be an IANA time zone name")
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = defaultDailyDays
+	}
+	if days > maxDailyDays {
+		days = maxDailyDays
+	}
+
+	now := time.Now().In(loc)
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	end := start.AddDate(0, 0, days)
+	summaries, err := c.repo.DailySummaries(ctx, cityID, start.UTC().Format(time.RFC3339), end.UTC().Format(time.RFC3339), timezone)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve daily summaries", err.Error())
+	}
+
+	response := &DailySummariesResponse{
+		CityID:   cityID,
+		Timezone: timezone,
+		Units:    opts.System.Labels(),
+		Days:     make([]DailySummary, 0, len(summaries)),
+	}
+	response.Units.WindSpeed = opts.Wind.Label()
+	for _, day := range summaries {
+		response.Days = append(response.Days, fromRepoDailySummary(day, opts))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// fromRepoDailySummary converts a daily summary from metric to the requested units
+func fromRepoDailySummary(day *repo.DailySummary, opts unitOptions) DailySummary {
+	summary := DailySummary{
+		Date:           day.Date,
+		Periods:        day.Periods,
+		MinTemperature: units.Round(day.MinTemperature, 1),
+		MaxTemperature: units.Round(day.MaxTemperature, 1),
+		Precipitation:  units.Round(day.Precipitation, 1),
+		MaxWindSpeed:   units.ConvertWindSpeed(day.MaxWindSpeed, opts.Wind),
+		WeatherCode:    day.WeatherCode,
+	}
+	if condition, ok := weathercode.Normalize(day.WeatherCode); ok {
+		summary.NormalizedCode = condition.Code
+	}
+	if opts.System == units.Imperial {
+		summary.MinTemperature = units.Round(units.CelsiusToFahrenheit(day.MinTemperature), 1)
+		summary.MaxTemperature = units.Round(units.CelsiusToFahrenheit(day.MaxTemperature), 1)
+		summary.Precipitation = units.Round(units.MillimetersToInches(day.Precipitation), 2)
+	}
+	return summary
+}
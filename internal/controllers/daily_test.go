@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPForecastController_GetDailySummaries(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{
+		{CityID: 7, ValidTime: "2025-08-01T06:00:00Z", Temperature: 15, Precipitation: 1.2, WindSpeed: 4, WeatherCode: "61"},
+		{CityID: 7, ValidTime: "2025-08-01T15:00:00Z", Temperature: 25, Precipitation: 0.8, WindSpeed: 10, WeatherCode: "61"},
+		{CityID: 7, ValidTime: "2025-08-02T12:00:00Z", Temperature: 20, WeatherCode: "0"},
+		{CityID: 8, ValidTime: "2025-08-01T12:00:00Z", Temperature: 5},
+	}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	get := func(t *testing.T, target string) (*httptest.ResponseRecorder, DailySummariesResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		if err := controller.GetDailySummaries(context.Background(), w, httptest.NewRequest("GET", target, nil), 7); err != nil {
+			t.Fatalf("GetDailySummaries failed: %v", err)
+		}
+		var response DailySummariesResponse
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return w, response
+	}
+
+	t.Run("aggregates by day", func(t *testing.T) {
+		w, response := get(t, "/cities/7/forecasts/daily?timezone=America/Chicago")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Timezone != "America/Chicago" || len(response.Days) != 2 {
+			t.Fatalf("Expected 2 Chicago days, got %+v", response)
+		}
+		first := response.Days[0]
+		if first.MinTemperature != 15 || first.MaxTemperature != 25 || first.Precipitation != 2 || first.MaxWindSpeed != 10 || first.WeatherCode != "61" {
+			t.Errorf("Unexpected first day %+v", first)
+		}
+		if response.Units.Temperature != "°C" {
+			t.Errorf("Expected metric units, got %+v", response.Units)
+		}
+	})
+
+	t.Run("converts units", func(t *testing.T) {
+		_, response := get(t, "/cities/7/forecasts/daily?units=imperial")
+		first := response.Days[0]
+		if first.MinTemperature != 59 || first.MaxTemperature != 77 || first.Precipitation != 0.08 || first.MaxWindSpeed != 22.4 {
+			t.Errorf("Expected imperial values, got %+v", first)
+		}
+		if response.Timezone != "UTC" || response.Units.WindSpeed != "mph" {
+			t.Errorf("Expected UTC days in mph, got %+v", response)
+		}
+	})
+
+	t.Run("rejects an unknown time zone", func(t *testing.T) {
+		if w, _ := get(t, "/cities/7/forecasts/daily?timezone=Mars/Olympus"); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		errorController := NewHTTPForecastController(&MockForecastRepository{shouldError: true, errorMsg: "database error"})
+		if err := errorController.GetDailySummaries(context.Background(), w, httptest.NewRequest("GET", "/cities/7/forecasts/daily", nil), 7); err != nil {
+			t.Fatalf("GetDailySummaries failed: %v", err)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
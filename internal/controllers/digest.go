@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/jobs"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/webhook"
+)
+
+const (
+	// defaultDigestDays is the forecast length of a digest when the request omits days
+	defaultDigestDays = 7
+
+	// maxDigestDays bounds the forecast length of a digest
+	maxDigestDays = 7
+)
+
+// DigestController handles forecast digest subscriptions: a city's forecast POSTed
+// to a webhook every day at a local time, signed with a per-digest secret
+type DigestController interface {
+	// Create handles requests to subscribe to a digest
+	Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Get handles requests for a digest and the outcome of its last delivery
+	Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// ListByUser handles requests to list a user's digests
+	ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+
+	// Delete handles requests to unsubscribe from a digest
+	Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+}
+
+// DigestRequest is the body of a request to subscribe to a digest. The digest belongs
+// to the user making the request.
+type DigestRequest struct {
+	CityID   int    `json:"city_id"`
+	URL      string `json:"url"`            // https webhook receiving the digest
+	Time     string `json:"time"`           // HH:MM in Timezone
+	Timezone string `json:"timezone"`       // IANA name, defaults to UTC
+	Days     int    `json:"days,omitempty"` // forecast days, defaults to 7
+}
+
+// validate checks the request fields, allowing webhook URLs in destinations
+func (d *DigestRequest) validate(destinations webhook.Destinations) models.ValidationErrors {
+	var errs models.ValidationErrors
+	if d.CityID <= 0 {
+		errs = append(errs, models.FieldError{Field: "city_id", Message: "city_id is required"})
+	}
+	if err := destinations.CheckURL(d.URL); err != nil {
+		message := "url must be an absolute https URL of a public host"
+		if destinations.AllowPrivate {
+			message = "url must be an absolute http(s) URL"
+		}
+		errs = append(errs, models.FieldError{Field: "url", Message: message})
+	}
+	if _, err := time.Parse("15:04", d.Time); err != nil {
+		errs = append(errs, models.FieldError{Field: "time", Message: "time must be HH:MM"})
+	}
+	if _, err := time.LoadLocation(d.Timezone); err != nil {
+		errs = append(errs, models.FieldError{Field: "timezone", Message: "timezone must be an IANA time zone name"})
+	}
+	if d.Days < 0 || d.Days > maxDigestDays {
+		errs = append(errs, models.FieldError{Field: "days", Message: fmt.Sprintf("days must be between 1 and %d", maxDigestDays)})
+	}
+	return errs
+}
+
+// Digest is a digest subscription as returned to its owner. The secret is only
+// returned when the digest is created.
+type Digest struct {
+	ID         int    `json:"id"`
+	UserID     int    `json:"user_id"`
+	CityID     int    `json:"city_id"`
+	URL        string `json:"url"`
+	Time       string `json:"time"`
+	Timezone   string `json:"timezone"`
+	Days       int    `json:"days"`
+	Secret     string `json:"secret,omitempty"`
+	NextRunAt  string `json:"next_run_at"`
+	LastRunAt  string `json:"last_run_at,omitempty"`
+	LastStatus string `json:"last_status,omitempty"` // delivered or failed
+	LastError  string `json:"last_error,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// HTTPDigestController implements DigestController for HTTP requests
+type HTTPDigestController struct {
+	digests      repo.DigestRepository
+	cities       repo.CityRepository
+	destinations webhook.Destinations
+	now          func() time.Time
+}
+
+// NewHTTPDigestController creates a new HTTP digest controller. Digests are delivered
+// by the jobs.DigestDelivery job; their URLs must be allowed by destinations.
+func NewHTTPDigestController(digests repo.DigestRepository, cities repo.CityRepository, destinations webhook.Destinations) DigestController {
+	return &HTTPDigestController{digests: digests, cities: cities, destinations: destinations, now: time.Now}
+}
+
+// Create handles POST /digests requests, subscribing the caller
+func (c *HTTPDigestController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := requireOwner(ctx, w, "Digests")
+	if userID == 0 {
+		return nil
+	}
+	var req DigestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if req.Timezone == "" {
+		req.Timezone = "UTC"
+	}
+	if errs := req.validate(c.destinations); errs != nil {
+		return writeValidationError(w, errs)
+	}
+	if req.Days == 0 {
+		req.Days = defaultDigestDays
+	}
+
+	if _, err := c.cities.GetByID(ctx, req.CityID); err != nil {
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
+	}
+	next, err := jobs.NextDigestRun(req.Time, req.Timezone, c.now())
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to create digest", err.Error())
+	}
+
+	digest := &repo.Digest{
+		UserID:    userID,
+		CityID:    req.CityID,
+		URL:       req.URL,
+		Secret:    secret,
+		LocalTime: req.Time,
+		Timezone:  req.Timezone,
+		Days:      req.Days,
+		NextRunAt: next.UTC().Format(time.RFC3339),
+	}
+	if err := c.digests.Create(ctx, digest); err != nil {
+		return writeRepoError(w, err, "Digest", "Failed to create digest")
+	}
+
+	response := fromRepoDigest(digest)
+	response.Secret = digest.Secret
+	w.Header().Set("Location", fmt.Sprintf("/digests/%d", digest.ID))
+	return writeCommitted(w, http.StatusCreated, response, "Digest created successfully")
+}
+
+// Get handles GET /digests/{id} requests
+func (c *HTTPDigestController) Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	digest, ok := c.owned(ctx, w, id)
+	if !ok {
+		return nil
+	}
+
+	return writeJSON(w, http.StatusOK, fromRepoDigest(digest))
+}
+
+// ListByUser handles GET /users/{id}/digests requests
+func (c *HTTPDigestController) ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	digests, err := c.digests.ListByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve digests", err.Error())
+	}
+
+	response := make([]*Digest, 0, len(digests))
+	for _, digest := range digests {
+		response = append(response, fromRepoDigest(digest))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// Delete handles DELETE /digests/{id} requests
+func (c *HTTPDigestController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if _, ok := c.owned(ctx, w, id); !ok {
+		return nil
+	}
+	if err := c.digests.Delete(ctx, id); err != nil {
+		return writeRepoError(w, err, "Digest", "Failed to delete digest")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Digest deleted successfully")
+}
+
+// owned returns a digest the caller of ctx may see or change, answering 404 for a
+// missing digest or another user's
+func (c *HTTPDigestController) owned(ctx context.Context, w http.ResponseWriter, id int) (*repo.Digest, bool) {
+	digest, err := c.digests.GetByID(ctx, id)
+	if err != nil {
+		_ = writeRepoError(w, err, "Digest", "Failed to retrieve digest")
+		return nil, false
+	}
+	if !canAccess(ctx, digest.UserID) {
+		_ = writeNotOwned(w, "Digest")
+		return nil, false
+	}
+	return digest, true
+}
+
+func fromRepoDigest(d *repo.Digest) *Digest {
+	return &Digest{
+		ID:         d.ID,
+		UserID:     d.UserID,
+		CityID:     d.CityID,
+		URL:        d.URL,
+		Time:       d.LocalTime,
+		Timezone:   d.Timezone,
+		Days:       d.Days,
+		NextRunAt:  d.NextRunAt,
+		LastRunAt:  d.LastRunAt,
+		LastStatus: d.LastStatus,
+		LastError:  d.LastError,
+		CreatedAt:  d.CreatedAt,
+	}
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/webhook"
+)
+
+func newDigestController(t *testing.T) (*HTTPDigestController, int) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	city := toRepoCity(exampleCity())
+	if err := engine.Cities().Create(context.Background(), city); err != nil {
+		t.Fatal(err)
+	}
+	controller := NewHTTPDigestController(engine.Digests(), engine.Cities(), webhook.Destinations{}).(*HTTPDigestController)
+	controller.now = func() time.Time { return time.Date(2025, 8, 13, 12, 0, 0, 0, time.UTC) }
+	return controller, city.ID
+}
+
+func createDigest(t *testing.T, controller DigestController, body string) (*httptest.ResponseRecorder, *Digest) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := controller.Create(asUser(1), w, httptest.NewRequest("POST", "/digests", strings.NewReader(body))); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data *Digest `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(w.Body.String())).Decode(&response)
+	return w, response.Data
+}
+
+func TestDigestController(t *testing.T) {
+	controller, cityID := newDigestController(t)
+	ctx := asUser(1)
+
+	w, digest := createDigest(t, controller, `{"city_id":`+strconv.Itoa(cityID)+`,"url":"https://hooks.example.com/weather","time":"06:00","timezone":"America/Chicago"}`)
+	if w.Code != http.StatusCreated || digest == nil {
+		t.Fatalf("Expected a created digest, got %d: %s", w.Code, w.Body.String())
+	}
+	if digest.Secret == "" || digest.Days != defaultDigestDays {
+		t.Errorf("Expected a secret and %d days, got %+v", defaultDigestDays, digest)
+	}
+	if digest.NextRunAt != "2025-08-14T11:00:00Z" {
+		t.Errorf("Expected the next run at 06:00 Chicago time tomorrow, got %s", digest.NextRunAt)
+	}
+	if w.Header().Get("Location") != "/digests/"+strconv.Itoa(digest.ID) {
+		t.Errorf("Unexpected Location %q", w.Header().Get("Location"))
+	}
+
+	t.Run("Get hides the secret", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.Get(ctx, w, httptest.NewRequest("GET", "/digests/1", nil), digest.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), digest.Secret) {
+			t.Errorf("Expected the digest without its secret, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/digests", nil), 1); err != nil {
+			t.Fatal(err)
+		}
+		var digests []*Digest
+		if err := json.NewDecoder(w.Body).Decode(&digests); err != nil {
+			t.Fatal(err)
+		}
+		if len(digests) != 1 || digests[0].ID != digest.ID {
+			t.Errorf("Expected the user's digest, got %+v", digests)
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		expectHidden(t, "Get", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Get(ctx, w, httptest.NewRequest("GET", "/digests/1", nil), digest.ID)
+		})
+		expectHidden(t, "ListByUser", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/digests", nil), 1)
+		})
+		expectHidden(t, "Delete", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/digests/1", nil), digest.ID)
+		})
+		w := httptest.NewRecorder()
+		if err := controller.Get(asAdmin(), w, httptest.NewRequest("GET", "/digests/1", nil), digest.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected an admin to see the digest, got %d", w.Code)
+		}
+	})
+
+	t.Run("Rejects invalid requests", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"url":      `{"city_id":1,"url":"ftp://example.com","time":"06:00"}`,
+			"http url": `{"city_id":1,"url":"http://example.com","time":"06:00"}`,
+			"loopback": `{"city_id":1,"url":"https://127.0.0.1:8080/hook","time":"06:00"}`,
+			"private":  `{"city_id":1,"url":"https://[fd00::1]/hook","time":"06:00"}`,
+			"time":     `{"city_id":1,"url":"https://example.com","time":"6am"}`,
+			"timezone": `{"city_id":1,"url":"https://example.com","time":"06:00","timezone":"Mars/Olympus"}`,
+			"days":     `{"city_id":1,"url":"https://example.com","time":"06:00","days":8}`,
+		} {
+			if w, _ := createDigest(t, controller, body); w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected invalid %s to be rejected, got %d", name, w.Code)
+			}
+		}
+		if w, _ := createDigest(t, controller, `{"city_id":999,"url":"https://example.com","time":"06:00"}`); w.Code != http.StatusNotFound {
+			t.Errorf("Expected an unknown city to answer 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/digests/1", nil), digest.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected 200, got %d", w.Code)
+		}
+		w = httptest.NewRecorder()
+		if err := controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/digests/1", nil), digest.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 deleting twice, got %d", w.Code)
+		}
+	})
+}
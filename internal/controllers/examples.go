@@ -0,0 +1,268 @@
+package controllers
+
+import (
+	"net/http"
+
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// Example is a documented request and response for one endpoint. The doc command merges
+// Examples into the generated OpenAPI spec, and the controller tests run the same
+// fixtures, so what integrators see in the Swagger UI is what the tests exercise.
+type Example struct {
+	Method   string // HTTP method, e.g. "POST"
+	Path     string // OpenAPI path template, e.g. "/forecasts/{id}"
+	Summary  string
+	Status   int // response status the example documents
+	Request  any // JSON request body, nil for endpoints without one
+	Response any // JSON response body
+}
+
+// Examples returns the documented examples of every endpoint, in route order
+func Examples() []Example {
+	forecast := exampleStoredForecast()
+	served := exampleServedForecast()
+	invalid := &Forecast{SourceProvider: "NOAA", ForecastTime: "2024-01-15T12:00:00Z", ValidTime: "2024-01-15T15:00:00Z", Humidity: 150}
+	city := exampleStoredCity()
+	place := exampleStoredPlace()
+	alert := exampleStoredAlert()
+	one := 1
+
+	return []Example{
+		{
+			Method: http.MethodPost, Path: "/forecasts", Summary: "Create a forecast",
+			Status: http.StatusCreated, Request: exampleForecast(),
+			Response: successBody(forecast, "Forecast created successfully"),
+		},
+		{
+			Method: http.MethodPost, Path: "/forecasts", Summary: "Create a forecast",
+			Status: http.StatusUnprocessableEntity, Request: invalid,
+			Response: &ValidationErrorResponse{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "Validation failed",
+				Errors:  invalid.validate(),
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/forecasts/bulk", Summary: "Create many forecasts",
+			Status: http.StatusCreated, Request: []*Forecast{exampleForecast()},
+			Response: successBody(&BulkCreateResponse{Created: 1, IDs: []int{forecast.ID}}, "Forecasts created successfully"),
+		},
+		{
+			Method: http.MethodGet, Path: "/forecasts", Summary: "List forecasts",
+			Status: http.StatusOK,
+			Response: &PaginatedResponse[Forecast]{
+				Data: []*Forecast{served}, Total: &one, Page: 1, PerPage: 20, Limit: 20, MaxLimit: 500, TotalPages: &one,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/forecasts/{id}", Summary: "Get a forecast",
+			Status: http.StatusOK, Response: successBody(served, ""),
+		},
+		{
+			Method: http.MethodGet, Path: "/forecasts/{id}", Summary: "Get a forecast",
+			Status:   http.StatusNotFound,
+			Response: &HTTPError{Status: http.StatusNotFound, Message: "Forecast not found", Details: "forecast with id 42 not found"},
+		},
+		{
+			Method: http.MethodPut, Path: "/forecasts/{id}", Summary: "Update a forecast",
+			Status: http.StatusOK, Request: exampleForecast(),
+			Response: successBody(forecast, "Forecast updated successfully"),
+		},
+		{
+			Method: http.MethodDelete, Path: "/forecasts/{id}", Summary: "Delete a forecast",
+			Status: http.StatusOK, Response: successBody(nil, "Forecast deleted successfully"),
+		},
+		{
+			Method: http.MethodGet, Path: "/cities/{id}/forecasts", Summary: "List forecasts for a city",
+			Status: http.StatusOK, Response: []*Forecast{served},
+		},
+		{
+			Method: http.MethodPost, Path: "/exports", Summary: "Start a chunked forecast export",
+			Status: http.StatusAccepted, Request: &ExportRequest{Format: FormatCSV, CityID: 123},
+			Response: &ExportManifest{
+				ID: "5f0c6a3e9b2d4c1f8a7e6d5c4b3a2910", Status: ExportRunning, Format: FormatCSV, CityID: 123,
+				Units: "metric", WindUnits: "ms", Chunks: []ExportChunk{}, CreatedAt: "2024-01-15T12:00:00Z",
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/cities", Summary: "Create a city",
+			Status: http.StatusCreated, Request: exampleCity(),
+			Response: successBody(city, "City created successfully"),
+		},
+		{
+			Method: http.MethodGet, Path: "/cities", Summary: "List cities",
+			Status: http.StatusOK,
+			Response: &PaginatedResponse[City]{
+				Data: []*City{city}, Total: &one, Page: 1, PerPage: 20, Limit: 20, MaxLimit: 100, TotalPages: &one,
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/cities/search", Summary: "Search cities by name",
+			Status: http.StatusOK, Response: []*City{city},
+		},
+		{
+			Method: http.MethodGet, Path: "/cities/{id}", Summary: "Get a city",
+			Status: http.StatusOK, Response: successBody(city, ""),
+		},
+		{
+			Method: http.MethodPost, Path: "/places", Summary: "Create a place",
+			Status: http.StatusCreated, Request: examplePlace(),
+			Response: successBody(place, "Place created successfully"),
+		},
+		{
+			Method: http.MethodGet, Path: "/places/{id}", Summary: "Get a place",
+			Status: http.StatusOK, Response: successBody(place, ""),
+		},
+		{
+			Method: http.MethodPost, Path: "/alerts", Summary: "Store an alert",
+			Status: http.StatusCreated, Request: exampleAlert(),
+			Response: successBody(alert, "Alert stored successfully"),
+		},
+		{
+			Method: http.MethodGet, Path: "/alerts/active", Summary: "List alerts in effect near coordinates",
+			Status: http.StatusOK, Response: []*Alert{alert},
+		},
+		{
+			Method: http.MethodGet, Path: "/cities/{id}/alerts", Summary: "List alerts in effect for a city",
+			Status: http.StatusOK, Response: []*Alert{alert},
+		},
+		{
+			Method: http.MethodGet, Path: "/weather", Summary: "Get current weather and a forecast for an address",
+			Status: http.StatusOK, Response: exampleWeatherResponse(),
+		},
+		{
+			Method: http.MethodGet, Path: "/weather", Summary: "Get current weather and a forecast for an address",
+			Status:   http.StatusTooManyRequests,
+			Response: &HTTPError{Status: http.StatusTooManyRequests, Message: "Failed to retrieve current weather", Details: "NWS: provider rate limit exceeded"},
+		},
+	}
+}
+
+// exampleForecast is a valid forecast request payload
+func exampleForecast() *Forecast {
+	return &Forecast{
+		CityID:         123,
+		SourceProvider: "NOAA",
+		ForecastTime:   "2024-01-15T12:00:00Z",
+		ValidTime:      "2024-01-15T15:00:00Z",
+		Temperature:    20.5,
+		Humidity:       65.0,
+		Pressure:       1013.25,
+		WindSpeed:      5.5,
+		WindDirection:  180.0,
+		CloudCover:     25.0,
+		Precipitation:  0.0,
+		WeatherCode:    "partly_cloudy",
+		Description:    "Partly cloudy",
+		UVIndex:        3.0,
+	}
+}
+
+// exampleStoredForecast is exampleForecast as returned once stored
+func exampleStoredForecast() *Forecast {
+	forecast := exampleForecast()
+	forecast.ID = 1
+	forecast.CreatedAt = "2024-01-15T12:00:00Z"
+	forecast.UpdatedAt = "2024-01-15T12:00:00Z"
+	return forecast
+}
+
+// exampleServedForecast is exampleStoredForecast as returned by read endpoints, which derive
+// its dew point, normalize its weather code and convert to the requested units; metric here
+func exampleServedForecast() *Forecast {
+	forecast := normalizeWeatherCode(deriveMetrics(exampleStoredForecast()))
+	convertForecasts(unitOptions{System: units.Metric, Wind: units.MetersPerSecond}, forecast)
+	return forecast
+}
+
+// exampleCity is a valid city request payload
+func exampleCity() *City {
+	return &City{
+		Name:        "San Francisco",
+		Country:     "United States",
+		CountryCode: "US",
+		Region:      "California",
+		Latitude:    37.7749,
+		Longitude:   -122.4194,
+		Population:  884363,
+		Timezone:    "America/Los_Angeles",
+		IsActive:    true,
+	}
+}
+
+// exampleStoredCity is exampleCity as returned once stored
+func exampleStoredCity() *City {
+	city := exampleCity()
+	city.ID = 1
+	city.CreatedAt = "2024-01-15T12:00:00Z"
+	city.UpdatedAt = "2024-01-15T12:00:00Z"
+	return city
+}
+
+// examplePlace is a valid place request payload
+func examplePlace() *Place {
+	return &Place{
+		DisplayName:  "Golden Gate Bridge",
+		AddressLine1: "Golden Gate Bridge",
+		City:         "San Francisco",
+		Region:       "California",
+		Country:      "United States",
+		CountryCode:  "US",
+		Latitude:     37.8199,
+		Longitude:    -122.4783,
+		Confidence:   0.95,
+		Source:       "Nominatim",
+	}
+}
+
+// exampleStoredPlace is examplePlace as returned once stored
+func exampleStoredPlace() *Place {
+	place := examplePlace()
+	place.ID = 1
+	place.CreatedAt = "2024-01-15T12:00:00Z"
+	place.UpdatedAt = "2024-01-15T12:00:00Z"
+	return place
+}
+
+// exampleAlert is a valid alert request payload
+func exampleAlert() *Alert {
+	return &Alert{
+		SourceProvider:  "NWS",
+		ProviderAlertID: "urn:oid:2.49.0.1.840.0.1",
+		CityID:          123,
+		Latitude:        37.7749,
+		Longitude:       -122.4194,
+		Title:           "Wind Advisory",
+		Severity:        "moderate",
+		Urgency:         "expected",
+		Category:        "met",
+		AreaDesc:        "San Francisco",
+		StartTime:       "2024-01-15T12:00:00Z",
+		EndTime:         "2024-01-15T18:00:00Z",
+	}
+}
+
+// exampleStoredAlert is exampleAlert as returned once stored
+func exampleStoredAlert() *Alert {
+	alert := exampleAlert()
+	alert.ID = 1
+	alert.CreatedAt = "2024-01-15T12:00:00Z"
+	alert.UpdatedAt = "2024-01-15T12:00:00Z"
+	return alert
+}
+
+// exampleWeatherResponse is a composite weather lookup for examplePlace
+func exampleWeatherResponse() *WeatherResponse {
+	current := exampleForecast()
+	convertForecasts(unitOptions{System: units.Metric, Wind: units.MetersPerSecond}, current)
+
+	return &WeatherResponse{
+		Place:    exampleStoredPlace(),
+		Provider: "NWS",
+		Current:  current,
+		Forecast: []*Forecast{current},
+		Alerts:   []providers.WeatherAlert{},
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+func TestExamples(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, example := range Examples() {
+		key := example.Method + " " + example.Path + " " + http.StatusText(example.Status)
+		if seen[key] {
+			t.Errorf("Duplicate example %s", key)
+		}
+		seen[key] = true
+
+		if example.Summary == "" || http.StatusText(example.Status) == "" {
+			t.Errorf("%s: expected a summary and a known status", key)
+		}
+		if _, err := json.Marshal(example.Response); err != nil {
+			t.Errorf("%s: response does not marshal: %v", key, err)
+		}
+
+		// Request bodies of successful examples must pass the same validation as the API
+		if example.Status >= 300 {
+			continue
+		}
+		var errs models.ValidationErrors
+		switch request := example.Request.(type) {
+		case *Forecast:
+			errs = request.validate()
+		case []*Forecast:
+			for _, f := range request {
+				errs = append(errs, f.validate()...)
+			}
+		case *City:
+			errs = request.validate()
+		case *Place:
+			errs = request.validate()
+		case *Alert:
+			errs = request.validate()
+		}
+		if len(errs) > 0 {
+			t.Errorf("%s: example request fails validation on %v", key, errs)
+		}
+	}
+}
+
+// TestExamplesMatchResponses runs example requests through the controllers so the
+// documented bodies cannot drift from what the API writes
+func TestExamplesMatchResponses(t *testing.T) {
+	examples := make(map[string]Example)
+	for _, example := range Examples() {
+		examples[example.Method+" "+example.Path+" "+http.StatusText(example.Status)] = example
+	}
+	controller := NewHTTPForecastController(&MockForecastRepository{forecast: createTestRepoForecast()})
+
+	tests := []struct {
+		key string
+		run func(w http.ResponseWriter, body []byte) error
+	}{
+		{"GET /forecasts/{id} OK", func(w http.ResponseWriter, body []byte) error {
+			return controller.GetByID(context.Background(), w, httptest.NewRequest("GET", "/forecasts/1", nil), 1)
+		}},
+		{"POST /forecasts Unprocessable Entity", func(w http.ResponseWriter, body []byte) error {
+			return controller.Create(context.Background(), w, httptest.NewRequest("POST", "/forecasts", bytes.NewReader(body)))
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			example, ok := examples[tt.key]
+			if !ok {
+				t.Fatalf("No example for %s", tt.key)
+			}
+			var body []byte
+			if example.Request != nil {
+				body, _ = json.Marshal(example.Request)
+			}
+
+			w := httptest.NewRecorder()
+			_ = tt.run(w, body)
+			if w.Code != example.Status {
+				t.Fatalf("Expected status %d, got %d: %s", example.Status, w.Code, w.Body.String())
+			}
+
+			want, _ := json.Marshal(example.Response)
+			if !jsonEqual(t, w.Body.Bytes(), want) {
+				t.Errorf("Response drifted from example:\n got: %s\nwant: %s", w.Body.String(), want)
+			}
+		})
+	}
+}
+
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal(a, &va); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		t.Fatalf("Invalid JSON %s: %v", b, err)
+	}
+	ja, _ := json.Marshal(va)
+	jb, _ := json.Marshal(vb)
+	return bytes.Equal(ja, jb)
+}
@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Export formats accepted by ?format= on forecast list endpoints
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// exportBatchSize is how many rows are read from the repository per round trip while
+// streaming an export
+const exportBatchSize = 500
+
+// forecastCSVHeader lists the CSV columns, named after the JSON fields
+var forecastCSVHeader = []string{
+	"id", "city_id", "source_provider", "forecast_time", "valid_time",
+	"temperature", "feels_like", "humidity", "pressure", "station_pressure", "wind_speed", "wind_direction", "wind_gust",
+	"visibility", "cloud_cover", "precipitation", "weather_code", "description",
+	"uv_index", "units", "wind_units", "wind_description", "created_at", "updated_at",
+}
+
+// exportFormat returns the streaming export format requested with ?format=, or "" for
+// the default JSON response
+func exportFormat(r *http.Request) string {
+	switch format := strings.ToLower(r.URL.Query().Get("format")); format {
+	case FormatCSV, FormatNDJSON:
+		return format
+	default:
+		return ""
+	}
+}
+
+// forecastFetcher reads the batch of up to limit forecasts following cursor in
+// (valid_time, id) keyset order, from the start when cursor is nil
+type forecastFetcher func(ctx context.Context, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error)
+
+// streamForecasts writes every forecast returned by fetch as CSV or NDJSON, reading the
+// repository in keyset batches, newest first, so the full result set is never held in
+// memory. Pagination parameters are ignored: an export always covers the whole query.
+//
+// The first batch is read before any output so that a failing query still produces a
+// regular JSON error response.
+func streamForecasts(ctx context.Context, w http.ResponseWriter, format, filename string, opts unitOptions, fetch forecastFetcher) error {
+	batch, err := fetch(ctx, nil, exportBatchSize)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+	}
+
+	var encode func(f *Forecast) error
+	flushEncoder := func() error { return nil }
+
+	if format == FormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.csv"`)
+		w.WriteHeader(http.StatusOK)
+
+		cw := csv.NewWriter(w)
+		if err := cw.Write(forecastCSVHeader); err != nil {
+			return err
+		}
+		encode = func(f *Forecast) error { return cw.Write(forecastCSVRow(f)) }
+		flushEncoder = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`.ndjson"`)
+		w.WriteHeader(http.StatusOK)
+
+		enc := json.NewEncoder(w)
+		encode = func(f *Forecast) error { return enc.Encode(f) }
+	}
+
+	flusher, _ := w.(http.Flusher)
+	for {
+		for _, f := range batch {
+			response := fromRepoForecast(f)
+			convertForecasts(opts, response)
+			if err := encode(response); err != nil {
+				return err
+			}
+		}
+		if err := flushEncoder(); err != nil {
+			return err
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		if len(batch) < exportBatchSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// Each batch resumes after the last row sent, so deep batches cost no more than
+		// the first. Headers are already sent, so a failure here can only truncate the
+		// stream.
+		last := batch[len(batch)-1]
+		if batch, err = fetch(ctx, &repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}, exportBatchSize); err != nil {
+			return err
+		}
+	}
+}
+
+// forecastCSVRow renders a forecast in forecastCSVHeader order
+func forecastCSVRow(f *Forecast) []string {
+	float := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	return []string{
+		strconv.Itoa(f.ID), strconv.Itoa(f.CityID), f.SourceProvider, f.ForecastTime, f.ValidTime,
+		float(f.Temperature), float(f.FeelsLike), float(f.Humidity), float(f.Pressure), float(f.StationPressure),
+		float(f.WindSpeed), float(f.WindDirection), float(f.WindGust), float(f.Visibility), float(f.CloudCover),
+		float(f.Precipitation), f.WeatherCode, f.Description, float(f.UVIndex), f.Units,
+		f.WindUnits, f.WindDescription,
+		f.CreatedAt, f.UpdatedAt,
+	}
+}
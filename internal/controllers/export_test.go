@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+func TestExportFormat(t *testing.T) {
+	tests := map[string]string{
+		"/forecasts":                "",
+		"/forecasts?format=csv":     FormatCSV,
+		"/forecasts?format=NDJSON":  FormatNDJSON,
+		"/forecasts?format=geojson": "",
+	}
+
+	for url, expected := range tests {
+		req := httptest.NewRequest("GET", url, nil)
+		if got := exportFormat(req); got != expected {
+			t.Errorf("exportFormat(%s) = %q, expected %q", url, got, expected)
+		}
+	}
+}
+
+func TestStreamForecasts(t *testing.T) {
+	// pagedFetcher serves total rows valid at the same time in keyset pages, newest ID
+	// first, recording how many calls were made
+	pagedFetcher := func(total int, calls *int) forecastFetcher {
+		return func(ctx context.Context, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+			*calls++
+			from := total
+			if cursor != nil {
+				if cursor.ValidTime != "2024-01-15T12:00:00Z" {
+					t.Fatalf("Expected the cursor of the last row sent, got %+v", cursor)
+				}
+				from = cursor.ID - 1
+			}
+			var batch []*repo.Forecast
+			for id := from; id >= 1 && len(batch) < limit; id-- {
+				batch = append(batch, &repo.Forecast{ID: id, CityID: 1, ValidTime: "2024-01-15T12:00:00Z", Temperature: 20})
+			}
+			return batch, nil
+		}
+	}
+
+	t.Run("NDJSON reads in batches", func(t *testing.T) {
+		calls := 0
+		w := httptest.NewRecorder()
+		err := streamForecasts(context.Background(), w, FormatNDJSON, "forecasts", unitOptions{System: units.Metric, Wind: units.MetersPerSecond}, pagedFetcher(exportBatchSize*2+7, &calls))
+		if err != nil {
+			t.Fatalf("streamForecasts failed: %v", err)
+		}
+
+		if w.Header().Get("Content-Type") != "application/x-ndjson" {
+			t.Errorf("Unexpected content type %q", w.Header().Get("Content-Type"))
+		}
+		if calls != 3 {
+			t.Errorf("Expected 3 repository calls, got %d", calls)
+		}
+
+		lines := 0
+		scanner := bufio.NewScanner(w.Body)
+		for scanner.Scan() {
+			var f Forecast
+			if err := json.Unmarshal(scanner.Bytes(), &f); err != nil {
+				t.Fatalf("Line %d is not JSON: %v", lines+1, err)
+			}
+			lines++
+			if want := exportBatchSize*2 + 8 - lines; f.ID != want {
+				t.Fatalf("Expected forecast %d, got %d", want, f.ID)
+			}
+		}
+		if lines != exportBatchSize*2+7 {
+			t.Errorf("Expected %d lines, got %d", exportBatchSize*2+7, lines)
+		}
+	})
+
+	t.Run("CSV converts units", func(t *testing.T) {
+		calls := 0
+		w := httptest.NewRecorder()
+		err := streamForecasts(context.Background(), w, FormatCSV, "forecasts", unitOptions{System: units.Imperial, Wind: units.MilesPerHour}, pagedFetcher(2, &calls))
+		if err != nil {
+			t.Fatalf("streamForecasts failed: %v", err)
+		}
+
+		if !strings.Contains(w.Header().Get("Content-Disposition"), `filename="forecasts.csv"`) {
+			t.Errorf("Unexpected Content-Disposition %q", w.Header().Get("Content-Disposition"))
+		}
+
+		records, err := csv.NewReader(w.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("Invalid CSV: %v", err)
+		}
+		if len(records) != 3 {
+			t.Fatalf("Expected header and 2 rows, got %d records", len(records))
+		}
+		if strings.Join(records[0], ",") != strings.Join(forecastCSVHeader, ",") {
+			t.Errorf("Unexpected header %v", records[0])
+		}
+		if records[1][5] != "68" || records[1][19] != string(units.Imperial) {
+			t.Errorf("Expected 68 imperial, got %s %s", records[1][5], records[1][19])
+		}
+	})
+
+	t.Run("Query error before streaming", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		fetch := func(ctx context.Context, cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+			return nil, &repoError{msg: "database error"}
+		}
+		_ = streamForecasts(context.Background(), w, FormatCSV, "forecasts", unitOptions{System: units.Metric, Wind: units.MetersPerSecond}, fetch)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+	})
+}
+
+func TestForecastController_Export(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{createTestRepoForecast()}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	req := httptest.NewRequest("GET", "/forecasts/range?start_time=2024-01-01T00:00:00Z&end_time=2024-01-02T00:00:00Z&format=ndjson", nil)
+	w := httptest.NewRecorder()
+	if err := controller.GetByTimeRange(context.Background(), w, req); err != nil {
+		t.Fatalf("GetByTimeRange failed: %v", err)
+	}
+
+	if w.Header().Get("Content-Type") != "application/x-ndjson" {
+		t.Errorf("Unexpected content type %q", w.Header().Get("Content-Type"))
+	}
+	if lines := strings.Count(w.Body.String(), "\n"); lines != 1 {
+		t.Errorf("Expected 1 line, got %d", lines)
+	}
+}
+
+func TestForecastController_GetByTimeRangeValidation(t *testing.T) {
+	controller := NewHTTPForecastController(&MockForecastRepository{})
+
+	for name, query := range map[string]string{
+		"missing end":     "start_time=2024-01-01T00:00:00Z",
+		"malformed start": "start_time=2024-01-01&end_time=2024-01-02T00:00:00Z",
+		"start after end": "start_time=2024-01-02T00:00:00Z&end_time=2024-01-01T00:00:00Z",
+		"range too long":  "start_time=2020-01-01T00:00:00Z&end_time=2024-01-01T00:00:00Z&format=csv",
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_ = controller.GetByTimeRange(context.Background(), w, httptest.NewRequest("GET", "/forecasts/range?"+query, nil))
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,476 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/blob"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Export job statuses
+const (
+	ExportRunning  = "running"
+	ExportComplete = "complete"
+	ExportFailed   = "failed"
+)
+
+const (
+	// exportChunkRows is how many forecasts go into each stored chunk; at roughly 200
+	// bytes a CSV row a chunk is a few megabytes, small enough to retry cheaply
+	exportChunkRows = 20000
+
+	// exportJobTimeout bounds a whole background export
+	exportJobTimeout = time.Hour
+)
+
+// ExportController handles long-running forecast exports. A job writes the export to
+// object storage in checksummed chunks, and the finished export can be downloaded
+// whole or chunk by chunk with HTTP Range requests, so interrupted downloads resume
+// where they stopped.
+type ExportController interface {
+	// Create handles requests to start an export job
+	Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Get handles requests for the manifest of an export job
+	Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) error
+
+	// Download handles requests for a finished export or one of its chunks
+	Download(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) error
+}
+
+// ExportRequest is the body of a request to start an export
+type ExportRequest struct {
+	Format string `json:"format"`            // csv (default) or ndjson
+	CityID int    `json:"city_id,omitempty"` // restrict to one city, 0 for all forecasts
+}
+
+// ExportChunk describes one stored chunk of an export. RangeStart and RangeEnd are the
+// inclusive byte offsets of the chunk within the full download.
+type ExportChunk struct {
+	Index      int    `json:"index"`
+	Rows       int    `json:"rows"`
+	Size       int64  `json:"size"`
+	RangeStart int64  `json:"range_start"`
+	RangeEnd   int64  `json:"range_end"`
+	SHA256     string `json:"sha256"`
+}
+
+// ExportManifest is the state of an export job. Chunks grow while the job runs; the
+// full download is the chunks concatenated in order, and SHA256 is its checksum.
+type ExportManifest struct {
+	ID          string        `json:"id"`
+	Status      string        `json:"status"`
+	Format      string        `json:"format"`
+	CityID      int           `json:"city_id,omitempty"`
+	Units       string        `json:"units"`
+	WindUnits   string        `json:"wind_units"`
+	Rows        int           `json:"rows"`
+	Size        int64         `json:"size"`
+	SHA256      string        `json:"sha256,omitempty"`
+	Chunks      []ExportChunk `json:"chunks"`
+	Error       string        `json:"error,omitempty"`
+	CreatedAt   string        `json:"created_at"`
+	CompletedAt string        `json:"completed_at,omitempty"`
+}
+
+// HTTPExportController implements ExportController for HTTP requests
+type HTTPExportController struct {
+	repo      repo.ForecastRepository
+	store     blob.Store
+	chunkRows int
+
+	mu   sync.Mutex
+	jobs map[string]*ExportManifest // running jobs; finished manifests live in store
+	wg   sync.WaitGroup
+}
+
+// NewHTTPExportController creates a new HTTP export controller.
+//
+// Chunks and finished manifests are written to store under exports/{id}/, so finished
+// exports stay downloadable across restarts. Jobs run in the background, detached from
+// the request that started them.
+func NewHTTPExportController(repo repo.ForecastRepository, store blob.Store) ExportController {
+	return &HTTPExportController{
+		repo:      repo,
+		store:     store,
+		chunkRows: exportChunkRows,
+		jobs:      make(map[string]*ExportManifest),
+	}
+}
+
+// Create handles POST /exports requests, answering 202 with the new job's manifest
+func (c *HTTPExportController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var req ExportRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+		}
+	}
+	switch req.Format {
+	case "":
+		req.Format = FormatCSV
+	case FormatCSV, FormatNDJSON:
+	default:
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "format must be csv or ndjson")
+	}
+	if req.CityID < 0 {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "city_id must be positive")
+	}
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	id, err := newExportID()
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to start export", err.Error())
+	}
+	manifest := &ExportManifest{
+		ID:        id,
+		Status:    ExportRunning,
+		Format:    req.Format,
+		CityID:    req.CityID,
+		Units:     string(opts.System),
+		WindUnits: string(opts.Wind),
+		Chunks:    []ExportChunk{},
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+
+	c.mu.Lock()
+	c.jobs[id] = manifest
+	snapshot := *manifest
+	c.mu.Unlock()
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		jobCtx, cancel := context.WithTimeout(context.Background(), exportJobTimeout)
+		defer cancel()
+		c.run(jobCtx, manifest, opts)
+	}()
+
+	w.Header().Set("Location", "/exports/"+id)
+	return writeJSON(w, http.StatusAccepted, &snapshot)
+}
+
+// Get handles GET /exports/{id} requests
+func (c *HTTPExportController) Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) error {
+	manifest, err := c.manifest(ctx, id)
+	if err != nil {
+		return writeExportLookupError(w, id, err)
+	}
+	return writeJSON(w, http.StatusOK, manifest)
+}
+
+// Download handles GET /exports/{id}/download[?chunk=] requests. Both the full export
+// and single chunks honour Range and If-Range, with the SHA-256 checksum as ETag.
+func (c *HTTPExportController) Download(ctx context.Context, w http.ResponseWriter, r *http.Request, id string) error {
+	manifest, err := c.manifest(ctx, id)
+	if err != nil {
+		return writeExportLookupError(w, id, err)
+	}
+	if manifest.Status != ExportComplete {
+		return writeError(w, http.StatusConflict, "Export not ready", fmt.Sprintf("export %s is %s", id, manifest.Status))
+	}
+
+	chunks := manifest.Chunks
+	checksum := manifest.SHA256
+	name := "forecasts-" + id + "." + manifest.Format
+	if value := r.URL.Query().Get("chunk"); value != "" {
+		index, err := strconv.Atoi(value)
+		if err != nil || index < 0 || index >= len(manifest.Chunks) {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", fmt.Sprintf("chunk must be between 0 and %d", len(manifest.Chunks)-1))
+		}
+		chunks = manifest.Chunks[index : index+1]
+		checksum = chunks[0].SHA256
+		name = fmt.Sprintf("forecasts-%s.part%04d.%s", id, index, manifest.Format)
+	}
+
+	objects := make([]blob.Object, 0, len(chunks))
+	defer func() {
+		for _, obj := range objects {
+			obj.Close()
+		}
+	}()
+	for _, chunk := range chunks {
+		obj, err := c.store.Open(ctx, exportChunkKey(id, chunk.Index))
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to open export", err.Error())
+		}
+		objects = append(objects, obj)
+	}
+
+	completed, _ := time.Parse(time.RFC3339, manifest.CompletedAt)
+	w.Header().Set("ETag", `"`+checksum+`"`)
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	if manifest.Format == FormatCSV {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+	http.ServeContent(w, r, name, completed, newConcatReader(objects))
+	return nil
+}
+
+// Wait blocks until every running export job has finished
+func (c *HTTPExportController) Wait() {
+	c.wg.Wait()
+}
+
+// manifest returns a copy of a running job's manifest, or the stored one of a
+// finished job
+func (c *HTTPExportController) manifest(ctx context.Context, id string) (*ExportManifest, error) {
+	c.mu.Lock()
+	if job, ok := c.jobs[id]; ok {
+		snapshot := *job
+		snapshot.Chunks = append([]ExportChunk{}, job.Chunks...)
+		c.mu.Unlock()
+		return &snapshot, nil
+	}
+	c.mu.Unlock()
+
+	if !validExportID(id) {
+		return nil, blob.ErrNotFound
+	}
+	obj, err := c.store.Open(ctx, exportManifestKey(id))
+	if err != nil {
+		return nil, err
+	}
+	defer obj.Close()
+
+	var manifest ExportManifest
+	if err := json.NewDecoder(obj).Decode(&manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// run writes the export chunk by chunk, then stores the final manifest and drops the
+// job from memory
+func (c *HTTPExportController) run(ctx context.Context, manifest *ExportManifest, opts unitOptions) {
+	err := c.writeChunks(ctx, manifest, opts)
+
+	c.mu.Lock()
+	if err != nil {
+		manifest.Status = ExportFailed
+		manifest.Error = err.Error()
+	} else {
+		manifest.Status = ExportComplete
+	}
+	manifest.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	data, _ := json.Marshal(manifest)
+	c.mu.Unlock()
+
+	if _, err := c.store.Put(ctx, exportManifestKey(manifest.ID), bytes.NewReader(data)); err != nil {
+		// Without a stored manifest the job can only be reported from memory
+		c.mu.Lock()
+		manifest.Status = ExportFailed
+		manifest.Error = err.Error()
+		c.mu.Unlock()
+		return
+	}
+
+	c.mu.Lock()
+	delete(c.jobs, manifest.ID)
+	c.mu.Unlock()
+}
+
+// writeChunks pages through the forecasts in keyset order, so rows written while the
+// export runs cannot shift pages, and stores every exportChunkRows rows as a chunk
+func (c *HTTPExportController) writeChunks(ctx context.Context, manifest *ExportManifest, opts unitOptions) error {
+	fetch := func(cursor *repo.ForecastCursor, limit int) ([]*repo.Forecast, error) {
+		if manifest.CityID > 0 {
+			return c.repo.GetByCityIDAfter(ctx, manifest.CityID, cursor, limit)
+		}
+		return c.repo.ListAfter(ctx, cursor, limit)
+	}
+
+	total := sha256.New()
+	var buf bytes.Buffer
+	// The header opens the first chunk so the concatenated chunks form one CSV file
+	if manifest.Format == FormatCSV {
+		cw := csv.NewWriter(&buf)
+		cw.Write(forecastCSVHeader)
+		cw.Flush()
+	}
+
+	rows := 0
+	var cursor *repo.ForecastCursor
+	for {
+		batch, err := fetch(cursor, exportBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to read forecasts: %w", err)
+		}
+		for _, f := range batch {
+			if err := encodeExportRow(&buf, manifest.Format, f, opts); err != nil {
+				return err
+			}
+			rows++
+			if rows == c.chunkRows {
+				if err := c.storeChunk(ctx, manifest, &buf, rows, total); err != nil {
+					return err
+				}
+				rows = 0
+			}
+		}
+		if len(batch) < exportBatchSize {
+			break
+		}
+		last := batch[len(batch)-1]
+		cursor = &repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+	}
+
+	// The last partial chunk; an empty export still gets one (possibly header-only) chunk
+	if rows > 0 || len(manifest.Chunks) == 0 {
+		if err := c.storeChunk(ctx, manifest, &buf, rows, total); err != nil {
+			return err
+		}
+	}
+
+	c.mu.Lock()
+	manifest.SHA256 = hex.EncodeToString(total.Sum(nil))
+	c.mu.Unlock()
+	return nil
+}
+
+// storeChunk writes buf as the next chunk, records it in the manifest and resets buf
+func (c *HTTPExportController) storeChunk(ctx context.Context, manifest *ExportManifest, buf *bytes.Buffer, rows int, total io.Writer) error {
+	sum := sha256.Sum256(buf.Bytes())
+	total.Write(buf.Bytes())
+
+	index := len(manifest.Chunks)
+	size, err := c.store.Put(ctx, exportChunkKey(manifest.ID, index), bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+	buf.Reset()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	manifest.Chunks = append(manifest.Chunks, ExportChunk{
+		Index:      index,
+		Rows:       rows,
+		Size:       size,
+		RangeStart: manifest.Size,
+		RangeEnd:   manifest.Size + size - 1,
+		SHA256:     hex.EncodeToString(sum[:]),
+	})
+	manifest.Rows += rows
+	manifest.Size += size
+	return nil
+}
+
+// encodeExportRow appends one forecast to buf in the export format
+func encodeExportRow(buf *bytes.Buffer, format string, f *repo.Forecast, opts unitOptions) error {
+	response := fromRepoForecast(f)
+	convertForecasts(opts, response)
+	if format == FormatNDJSON {
+		return json.NewEncoder(buf).Encode(response)
+	}
+
+	cw := csv.NewWriter(buf)
+	if err := cw.Write(forecastCSVRow(response)); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeExportLookupError(w http.ResponseWriter, id string, err error) error {
+	if errors.Is(err, blob.ErrNotFound) {
+		return writeError(w, http.StatusNotFound, "Export not found", fmt.Sprintf("export %s not found", id))
+	}
+	return writeError(w, http.StatusInternalServerError, "Failed to retrieve export", err.Error())
+}
+
+func newExportID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// validExportID reports whether id could have come from newExportID, so arbitrary
+// path values never reach the object store
+func validExportID(id string) bool {
+	decoded, err := hex.DecodeString(id)
+	return err == nil && len(decoded) == 16
+}
+
+func exportManifestKey(id string) string {
+	return "exports/" + id + "/manifest.json"
+}
+
+func exportChunkKey(id string, index int) string {
+	return fmt.Sprintf("exports/%s/part-%04d", id, index)
+}
+
+// concatReader is a seekable view of several objects laid end to end, letting
+// http.ServeContent answer Range requests across chunk boundaries
+type concatReader struct {
+	parts  []blob.Object
+	starts []int64 // offset of each part in the whole
+	size   int64
+	pos    int64
+}
+
+func newConcatReader(parts []blob.Object) *concatReader {
+	r := &concatReader{parts: parts, starts: make([]int64, len(parts))}
+	for i, part := range parts {
+		r.starts[i] = r.size
+		r.size += part.Size()
+	}
+	return r
+}
+
+func (r *concatReader) Read(p []byte) (int, error) {
+	for i := len(r.parts) - 1; i >= 0; i-- {
+		if r.pos < r.starts[i] {
+			continue
+		}
+		if r.pos >= r.starts[i]+r.parts[i].Size() {
+			break
+		}
+		if _, err := r.parts[i].Seek(r.pos-r.starts[i], io.SeekStart); err != nil {
+			return 0, err
+		}
+		n, err := r.parts[i].Read(p[:min(int64(len(p)), r.starts[i]+r.parts[i].Size()-r.pos)])
+		r.pos += int64(n)
+		if errors.Is(err, io.EOF) && r.pos < r.size {
+			err = nil
+		}
+		return n, err
+	}
+	return 0, io.EOF
+}
+
+func (r *concatReader) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += r.pos
+	case io.SeekEnd:
+		offset += r.size
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("negative position")
+	}
+	r.pos = offset
+	return offset, nil
+}
@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/blob"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newExportController(t *testing.T, forecasts int) (*HTTPExportController, blob.Store, repo.ForecastRepository) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range forecasts {
+		f := toRepoForecast(exampleForecast())
+		f.ValidTime = fmt.Sprintf("2024-01-15T%02d:00:00Z", i)
+		if err := engine.Forecasts().Create(context.Background(), f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewHTTPExportController(engine.Forecasts(), store).(*HTTPExportController)
+	controller.chunkRows = 2
+	return controller, store, engine.Forecasts()
+}
+
+// runExport starts an export, waits for it and returns the finished manifest
+func runExport(t *testing.T, controller *HTTPExportController, body string) *ExportManifest {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/exports", strings.NewReader(body))
+	if err := controller.Create(context.Background(), w, req); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusAccepted, w.Code, w.Body.String())
+	}
+	var started ExportManifest
+	if err := json.NewDecoder(w.Body).Decode(&started); err != nil {
+		t.Fatal(err)
+	}
+	if started.Status != ExportRunning || w.Header().Get("Location") != "/exports/"+started.ID {
+		t.Errorf("Unexpected start response %+v", started)
+	}
+	controller.Wait()
+
+	w = httptest.NewRecorder()
+	_ = controller.Get(context.Background(), w, httptest.NewRequest("GET", "/exports/"+started.ID, nil), started.ID)
+	var manifest ExportManifest
+	if err := json.NewDecoder(w.Body).Decode(&manifest); err != nil {
+		t.Fatal(err)
+	}
+	return &manifest
+}
+
+func download(t *testing.T, controller ExportController, id, query string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest("GET", "/exports/"+id+"/download"+query, nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	if err := controller.Download(context.Background(), w, req, id); err != nil {
+		t.Fatalf("Download failed: %v", err)
+	}
+	return w
+}
+
+func TestExportController_Chunks(t *testing.T) {
+	controller, store, _ := newExportController(t, 5)
+	manifest := runExport(t, controller, `{"format":"csv"}`)
+
+	if manifest.Status != ExportComplete || manifest.Rows != 5 || len(manifest.Chunks) != 3 {
+		t.Fatalf("Expected 5 rows in 3 chunks, got %+v", manifest)
+	}
+	var offset int64
+	for i, chunk := range manifest.Chunks {
+		if chunk.Index != i || chunk.RangeStart != offset || chunk.RangeEnd != offset+chunk.Size-1 {
+			t.Errorf("Expected contiguous ranges, got %+v at offset %d", chunk, offset)
+		}
+		offset += chunk.Size
+
+		w := download(t, controller, manifest.ID, fmt.Sprintf("?chunk=%d", i), nil)
+		sum := sha256.Sum256(w.Body.Bytes())
+		if hex.EncodeToString(sum[:]) != chunk.SHA256 || w.Header().Get("ETag") != `"`+chunk.SHA256+`"` {
+			t.Errorf("Chunk %d does not match its checksum", i)
+		}
+	}
+	if offset != manifest.Size {
+		t.Errorf("Expected chunk sizes to add up to %d, got %d", manifest.Size, offset)
+	}
+
+	full := download(t, controller, manifest.ID, "", nil)
+	sum := sha256.Sum256(full.Body.Bytes())
+	if full.Code != http.StatusOK || hex.EncodeToString(sum[:]) != manifest.SHA256 {
+		t.Fatalf("Full download does not match the manifest checksum (status %d)", full.Code)
+	}
+	records, err := csv.NewReader(bytes.NewReader(full.Body.Bytes())).ReadAll()
+	if err != nil || len(records) != 6 || records[0][0] != "id" {
+		t.Fatalf("Expected a header and 5 rows, got %d records (%v)", len(records), err)
+	}
+	// Keyset order: newest valid_time first
+	if records[1][4] != "2024-01-15T04:00:00Z" {
+		t.Errorf("Expected newest forecast first, got %v", records[1])
+	}
+
+	t.Run("range resumes across chunks", func(t *testing.T) {
+		start := manifest.Chunks[0].RangeEnd - 3
+		w := download(t, controller, manifest.ID, "", http.Header{
+			"Range":    {fmt.Sprintf("bytes=%d-", start)},
+			"If-Range": {`"` + manifest.SHA256 + `"`},
+		})
+		if w.Code != http.StatusPartialContent {
+			t.Fatalf("Expected status %d, got %d", http.StatusPartialContent, w.Code)
+		}
+		if !bytes.Equal(w.Body.Bytes(), full.Body.Bytes()[start:]) {
+			t.Error("Expected the remainder of the export")
+		}
+	})
+
+	t.Run("stale If-Range sends everything", func(t *testing.T) {
+		w := download(t, controller, manifest.ID, "", http.Header{
+			"Range":    {"bytes=10-"},
+			"If-Range": {`"stale"`},
+		})
+		if w.Code != http.StatusOK || w.Body.Len() != int(manifest.Size) {
+			t.Errorf("Expected the full export, got status %d with %d bytes", w.Code, w.Body.Len())
+		}
+	})
+
+	t.Run("survives restart", func(t *testing.T) {
+		restarted := NewHTTPExportController(controller.repo, store)
+		w := download(t, restarted, manifest.ID, "", nil)
+		if w.Code != http.StatusOK || w.Body.Len() != int(manifest.Size) {
+			t.Errorf("Expected the stored export after restart, got status %d", w.Code)
+		}
+	})
+}
+
+func TestExportController_NDJSONByCity(t *testing.T) {
+	controller, _, forecasts := newExportController(t, 3)
+	other := toRepoForecast(exampleForecast())
+	other.CityID = 999
+	if err := forecasts.Create(context.Background(), other); err != nil {
+		t.Fatal(err)
+	}
+
+	manifest := runExport(t, controller, `{"format":"ndjson","city_id":123}`)
+	if manifest.Status != ExportComplete || manifest.Rows != 3 || manifest.CityID != 123 {
+		t.Fatalf("Expected the 3 forecasts of city 123, got %+v", manifest)
+	}
+
+	body, _ := io.ReadAll(download(t, controller, manifest.ID, "", nil).Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 NDJSON lines, got %d", len(lines))
+	}
+	var f Forecast
+	if err := json.Unmarshal([]byte(lines[0]), &f); err != nil || f.CityID != 123 || f.Units != "metric" {
+		t.Errorf("Unexpected NDJSON row %s (%v)", lines[0], err)
+	}
+}
+
+func TestExportController_Errors(t *testing.T) {
+	controller, _, _ := newExportController(t, 0)
+
+	t.Run("invalid format", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_ = controller.Create(context.Background(), w, httptest.NewRequest("POST", "/exports", strings.NewReader(`{"format":"xml"}`)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("unknown export", func(t *testing.T) {
+		for _, id := range []string{"0123456789abcdef0123456789abcdef", "../etc"} {
+			w := download(t, controller, id, "", nil)
+			if w.Code != http.StatusNotFound {
+				t.Errorf("Expected status %d for %q, got %d", http.StatusNotFound, id, w.Code)
+			}
+		}
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		controller.jobs["running"] = &ExportManifest{ID: "running", Status: ExportRunning}
+		w := download(t, controller, "running", "", nil)
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status %d, got %d", http.StatusConflict, w.Code)
+		}
+	})
+
+	t.Run("empty export", func(t *testing.T) {
+		manifest := runExport(t, controller, "")
+		if manifest.Status != ExportComplete || manifest.Rows != 0 || len(manifest.Chunks) != 1 {
+			t.Fatalf("Expected a header-only export, got %+v", manifest)
+		}
+		w := download(t, controller, manifest.ID, "", nil)
+		if !strings.HasPrefix(w.Body.String(), "id,city_id,") {
+			t.Errorf("Expected the CSV header, got %q", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// GeoJSONContentType is the RFC 7946 media type
+const GeoJSONContentType = "application/geo+json"
+
+// Geometry is a GeoJSON geometry; only Points are produced
+type Geometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"` // [longitude, latitude] per RFC 7946
+}
+
+// Feature is a GeoJSON Feature
+type Feature struct {
+	Type       string         `json:"type"`
+	ID         int            `json:"id,omitempty"`
+	Geometry   *Geometry      `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON FeatureCollection. Pagination fields are RFC 7946
+// foreign members and are only present on paginated endpoints.
+type FeatureCollection struct {
+	Type       string     `json:"type"`
+	Features   []*Feature `json:"features"`
+	Total      int        `json:"total,omitempty"`
+	Page       int        `json:"page,omitempty"`
+	PerPage    int        `json:"per_page,omitempty"`
+	MaxLimit   int        `json:"max_limit,omitempty"`
+	TotalPages int        `json:"total_pages,omitempty"`
+}
+
+// locatable is implemented by response types that can be rendered as Point features
+type locatable interface {
+	featureID() int
+	coordinates() (lat, lon float64)
+}
+
+func (c *City) featureID() int                  { return c.ID }
+func (c *City) coordinates() (lat, lon float64) { return c.Latitude, c.Longitude }
+
+func (p *Place) featureID() int                  { return p.ID }
+func (p *Place) coordinates() (lat, lon float64) { return p.Latitude, p.Longitude }
+
+// wantsGeoJSON reports whether the client asked for GeoJSON via ?format=geojson or an
+// Accept header naming application/geo+json. It also marks the response as varying on
+// Accept, since the same URL can return either representation.
+func wantsGeoJSON(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Add("Vary", "Accept")
+
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, "geojson")
+	}
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		if strings.EqualFold(strings.TrimSpace(mediaType), GeoJSONContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFeatureCollection renders items as Point features. Every other JSON field of an item
+// becomes a feature property, so properties always match the plain JSON representation.
+func newFeatureCollection[T any, PT interface {
+	*T
+	locatable
+}](items []PT) *FeatureCollection {
+	collection := &FeatureCollection{Type: "FeatureCollection", Features: make([]*Feature, 0, len(items))}
+
+	for _, item := range items {
+		lat, lon := item.coordinates()
+
+		properties := make(map[string]any)
+		if data, err := json.Marshal(item); err == nil {
+			_ = json.Unmarshal(data, &properties)
+		}
+		delete(properties, "latitude")
+		delete(properties, "longitude")
+
+		collection.Features = append(collection.Features, &Feature{
+			Type:       "Feature",
+			ID:         item.featureID(),
+			Geometry:   &Geometry{Type: "Point", Coordinates: []float64{lon, lat}},
+			Properties: properties,
+		})
+	}
+
+	return collection
+}
+
+// withPagination copies pagination metadata onto the collection
+func withPagination[T any](fc *FeatureCollection, paginated *PaginatedResponse[T]) *FeatureCollection {
+	fc.Page, fc.PerPage, fc.MaxLimit = paginated.Page, paginated.PerPage, paginated.MaxLimit
+	if paginated.Total != nil {
+		fc.Total, fc.TotalPages = *paginated.Total, *paginated.TotalPages
+	}
+	return fc
+}
+
+func writeGeoJSON(w http.ResponseWriter, status int, data *FeatureCollection) error {
+	w.Header().Set("Content-Type", GeoJSONContentType)
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestWantsGeoJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		accept   string
+		expected bool
+	}{
+		{"default", "/cities", "", false},
+		{"format param", "/cities?format=geojson", "", true},
+		{"accept header", "/cities", "application/geo+json", true},
+		{"accept list with params", "/cities", "text/html, application/geo+json;q=0.9", true},
+		{"plain json", "/cities", "application/json", false},
+		{"format overrides accept", "/cities?format=json", "application/geo+json", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", test.url, nil)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			w := httptest.NewRecorder()
+
+			if got := wantsGeoJSON(w, req); got != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+			if w.Header().Get("Vary") != "Accept" {
+				t.Error("Expected Vary: Accept header")
+			}
+		})
+	}
+}
+
+func TestCityGeoJSON(t *testing.T) {
+	city := createTestRepoCity()
+	controller := NewHTTPCityController(&MockCityRepository{cities: []*repo.City{city}, count: 1})
+
+	req := httptest.NewRequest("GET", "/cities?format=geojson", nil)
+	w := httptest.NewRecorder()
+
+	if err := controller.List(context.Background(), w, req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != GeoJSONContentType {
+		t.Errorf("Expected content type %s, got %s", GeoJSONContentType, ct)
+	}
+
+	var collection FeatureCollection
+	if err := json.NewDecoder(w.Body).Decode(&collection); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+		t.Fatalf("Expected a FeatureCollection with 1 feature, got %+v", collection)
+	}
+	if collection.Total != 1 || collection.Page != 1 {
+		t.Errorf("Expected pagination members, got total=%d page=%d", collection.Total, collection.Page)
+	}
+
+	feature := collection.Features[0]
+	if feature.Geometry.Type != "Point" {
+		t.Errorf("Expected Point geometry, got %s", feature.Geometry.Type)
+	}
+	if feature.Geometry.Coordinates[0] != city.Longitude || feature.Geometry.Coordinates[1] != city.Latitude {
+		t.Errorf("Expected [lon, lat] coordinates, got %v", feature.Geometry.Coordinates)
+	}
+	if feature.Properties["name"] != city.Name {
+		t.Errorf("Expected name property %q, got %v", city.Name, feature.Properties["name"])
+	}
+	if _, ok := feature.Properties["latitude"]; ok {
+		t.Error("Expected coordinates to be removed from properties")
+	}
+}
+
+func TestPlaceGeoJSON(t *testing.T) {
+	place := createTestRepoPlace()
+	controller := NewHTTPPlaceController(&MockPlaceRepository{places: []*repo.Place{place}})
+
+	req := httptest.NewRequest("GET", "/places/search?q=main", nil)
+	req.Header.Set("Accept", GeoJSONContentType)
+	w := httptest.NewRecorder()
+
+	if err := controller.Search(context.Background(), w, req); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var collection FeatureCollection
+	if err := json.NewDecoder(w.Body).Decode(&collection); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(collection.Features) != 1 || collection.Features[0].ID != place.ID {
+		t.Fatalf("Expected one feature with id %d, got %+v", place.ID, collection.Features)
+	}
+	if collection.Total != 0 {
+		t.Error("Expected no pagination members on search results")
+	}
+}
+
+func TestEmptyFeatureCollection(t *testing.T) {
+	collection := newFeatureCollection([]*City(nil))
+	data, _ := json.Marshal(collection)
+	if string(data) != `{"type":"FeatureCollection","features":[]}` {
+		t.Errorf("Expected empty features array, got %s", data)
+	}
+}
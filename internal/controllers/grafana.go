@@ -0,0 +1,408 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/tsdb"
+)
+
+const (
+	// grafanaPageSize is the number of rows read per page while collecting a series
+	grafanaPageSize = 500
+
+	// grafanaMaxRows bounds the rows read for one target, so a wide dashboard range
+	// cannot scan a city's whole history
+	grafanaMaxRows = 20000
+
+	// grafanaSearchCities is the most cities offered by one metric search
+	grafanaSearchCities = 20
+)
+
+// GrafanaController implements the Grafana JSON datasource protocol over stored
+// forecasts, METAR observations and alerts.
+//
+// Targets name one field of one series: "forecast.<field>:<city ID>" or
+// "observation.<field>:<ICAO>", with fields as in tsdb.ForecastFields and
+// tsdb.ObservationFields. Annotation queries name a city ID.
+type GrafanaController interface {
+	// TestConnection handles the datasource health check
+	TestConnection(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Search handles requests listing the targets matching a city name or ICAO code
+	Search(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Query handles requests for the datapoints of targets within a time range
+	Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Annotations handles requests for a city's alerts within a time range
+	Annotations(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// HTTPGrafanaController implements GrafanaController for HTTP requests
+type HTTPGrafanaController struct {
+	forecasts repo.ForecastRepository
+	cities    repo.CityRepository
+	reports   repo.AviationReportRepository
+	alerts    repo.AlertRepository
+}
+
+// NewHTTPGrafanaController creates a new Grafana datasource controller
+func NewHTTPGrafanaController(engine repo.Engine) GrafanaController {
+	return &HTTPGrafanaController{
+		forecasts: engine.Forecasts(),
+		cities:    engine.Cities(),
+		reports:   engine.Aviation(),
+		alerts:    engine.Alerts(),
+	}
+}
+
+// GrafanaRange is the dashboard time range of a query or annotation request
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaTarget is one query of a panel
+type GrafanaTarget struct {
+	Target string `json:"target"`
+	RefID  string `json:"refId"`
+	Type   string `json:"type"` // "timeserie" (default) or "table"
+}
+
+// GrafanaQueryRequest is the body of POST /query
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange    `json:"range"`
+	MaxDataPoints int             `json:"maxDataPoints"`
+	Targets       []GrafanaTarget `json:"targets"`
+}
+
+// GrafanaSeries is a time series response; datapoints are [value, unix milliseconds]
+type GrafanaSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// GrafanaColumn describes a column of a table response
+type GrafanaColumn struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// GrafanaTable is a table response
+type GrafanaTable struct {
+	Type    string          `json:"type"`
+	Columns []GrafanaColumn `json:"columns"`
+	Rows    [][]any         `json:"rows"`
+}
+
+// GrafanaMetric is a search result: a readable label and the target it selects
+type GrafanaMetric struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// GrafanaAnnotationRequest is the body of POST /annotations
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange `json:"range"`
+	Annotation struct {
+		Name  string `json:"name"`
+		Query string `json:"query"`
+	} `json:"annotation"`
+}
+
+// GrafanaAnnotation marks an alert on a dashboard; times are unix milliseconds
+type GrafanaAnnotation struct {
+	Annotation any      `json:"annotation"`
+	Time       int64    `json:"time"`
+	TimeEnd    int64    `json:"timeEnd,omitempty"`
+	Title      string   `json:"title"`
+	Text       string   `json:"text"`
+	Tags       []string `json:"tags"`
+}
+
+// grafanaTarget is a parsed target
+type grafanaTarget struct {
+	measurement string
+	field       string
+	series      string // city ID or ICAO code
+}
+
+// parseGrafanaTarget parses "forecast.<field>:<city ID>" or "observation.<field>:<ICAO>"
+func parseGrafanaTarget(target string) (grafanaTarget, error) {
+	metric, series, _ := strings.Cut(strings.TrimSpace(target), ":")
+	measurement, field, _ := strings.Cut(metric, ".")
+	parsed := grafanaTarget{measurement: measurement, field: field, series: series}
+	switch measurement {
+	case tsdb.MeasurementForecast:
+		if !slices.Contains(tsdb.ForecastFields, field) {
+			return parsed, fmt.Errorf("unknown forecast field %q", field)
+		}
+		if id, err := strconv.Atoi(series); err != nil || id <= 0 {
+			return parsed, fmt.Errorf("forecast targets end with a city ID, got %q", series)
+		}
+	case tsdb.MeasurementObservation:
+		if !slices.Contains(tsdb.ObservationFields, field) {
+			return parsed, fmt.Errorf("unknown observation field %q", field)
+		}
+		parsed.series = strings.ToUpper(series)
+		if !models.ValidICAO(parsed.series) {
+			return parsed, fmt.Errorf("observation targets end with an ICAO code, got %q", series)
+		}
+	default:
+		return parsed, fmt.Errorf("target %q must start with %s. or %s.", target, tsdb.MeasurementForecast, tsdb.MeasurementObservation)
+	}
+	return parsed, nil
+}
+
+// TestConnection handles GET / requests from the datasource settings page
+func (c *HTTPGrafanaController) TestConnection(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	return writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// Search handles POST /search requests. The target is a city name, matched like city
+// search, or an ICAO code; an empty target lists the first cities.
+func (c *HTTPGrafanaController) Search(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request struct {
+		Target string `json:"target"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	query := strings.TrimSpace(request.Target)
+
+	var cities []*repo.City
+	var err error
+	if query == "" {
+		cities, err = c.cities.List(ctx, grafanaSearchCities, 0)
+	} else {
+		cities, err = c.cities.Search(ctx, query, grafanaSearchCities)
+	}
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to search cities", err.Error())
+	}
+
+	metrics := []GrafanaMetric{}
+	for _, city := range cities {
+		for _, field := range tsdb.ForecastFields {
+			metrics = append(metrics, GrafanaMetric{
+				Text:  fmt.Sprintf("%s, %s forecast %s", city.Name, city.CountryCode, field),
+				Value: fmt.Sprintf("%s.%s:%d", tsdb.MeasurementForecast, field, city.ID),
+			})
+		}
+	}
+	if icao := strings.ToUpper(query); models.ValidICAO(icao) {
+		for _, field := range tsdb.ObservationFields {
+			metrics = append(metrics, GrafanaMetric{
+				Text:  fmt.Sprintf("%s observed %s", icao, field),
+				Value: fmt.Sprintf("%s.%s:%s", tsdb.MeasurementObservation, field, icao),
+			})
+		}
+	}
+	return writeJSON(w, http.StatusOK, metrics)
+}
+
+// Query handles POST /query requests. Forecast series hold the most recently issued
+// value for each valid time; observation series hold every METAR.
+func (c *HTTPGrafanaController) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request GrafanaQueryRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if request.Range.From.IsZero() || request.Range.To.IsZero() || request.Range.To.Before(request.Range.From) {
+		return writeError(w, http.StatusBadRequest, "Invalid range", "range.from and range.to are required and from must not be after to")
+	}
+
+	responses := []any{}
+	for _, target := range request.Targets {
+		if target.Target == "" {
+			continue
+		}
+		parsed, err := parseGrafanaTarget(target.Target)
+		if err != nil {
+			return writeError(w, http.StatusBadRequest, "Invalid target", err.Error())
+		}
+		var points []tsdb.Point
+		if parsed.measurement == tsdb.MeasurementForecast {
+			points, err = c.forecastPoints(ctx, parsed.series, request.Range)
+		} else {
+			points, err = c.observationPoints(ctx, parsed.series, request.Range)
+		}
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to load series", err.Error())
+		}
+
+		datapoints := [][2]float64{}
+		for _, point := range points {
+			if value, ok := point.Fields[parsed.field]; ok {
+				datapoints = append(datapoints, [2]float64{value, float64(point.Time.UnixMilli())})
+			}
+		}
+		datapoints = downsample(datapoints, request.MaxDataPoints)
+
+		if target.Type == "table" {
+			table := GrafanaTable{
+				Type:    "table",
+				Columns: []GrafanaColumn{{Text: "Time", Type: "time"}, {Text: target.Target, Type: "number"}},
+				Rows:    [][]any{},
+			}
+			for _, datapoint := range datapoints {
+				table.Rows = append(table.Rows, []any{int64(datapoint[1]), datapoint[0]})
+			}
+			responses = append(responses, table)
+		} else {
+			responses = append(responses, GrafanaSeries{Target: target.Target, Datapoints: datapoints})
+		}
+	}
+	return writeJSON(w, http.StatusOK, responses)
+}
+
+// forecastPoints reads a city's forecasts valid within span, oldest first, keeping the
+// most recently issued forecast for each valid time
+func (c *HTTPGrafanaController) forecastPoints(ctx context.Context, series string, span GrafanaRange) ([]tsdb.Point, error) {
+	cityID, _ := strconv.Atoi(series)
+	cursor := &repo.ForecastCursor{ValidTime: span.To.UTC().Format(time.RFC3339), ID: math.MaxInt32}
+	latest := make(map[int64]tsdb.Point)
+	issued := make(map[int64]string)
+
+	for read := 0; read < grafanaMaxRows; read += grafanaPageSize {
+		forecasts, err := c.forecasts.GetByCityIDAfter(ctx, cityID, cursor, grafanaPageSize)
+		if err != nil {
+			return nil, err
+		}
+		done := len(forecasts) < grafanaPageSize
+		for _, forecast := range forecasts {
+			point, ok := tsdb.ForecastPoint(forecast)
+			if !ok {
+				continue
+			}
+			if point.Time.Before(span.From) {
+				done = true
+				break
+			}
+			key := point.Time.Unix()
+			if at, seen := issued[key]; !seen || point.Tags["issued_at"] > at {
+				latest[key], issued[key] = point, point.Tags["issued_at"]
+			}
+		}
+		if done || len(forecasts) == 0 {
+			break
+		}
+		last := forecasts[len(forecasts)-1]
+		cursor = &repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+	}
+
+	points := make([]tsdb.Point, 0, len(latest))
+	for _, point := range latest {
+		points = append(points, point)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Time.Before(points[j].Time) })
+	return points, nil
+}
+
+// observationPoints reads a station's METARs observed within span, oldest first
+func (c *HTTPGrafanaController) observationPoints(ctx context.Context, station string, span GrafanaRange) ([]tsdb.Point, error) {
+	since := span.From.UTC().Format(time.RFC3339)
+	reports, err := c.reports.GetByStation(ctx, station, models.ReportTypeMETAR, since, grafanaMaxRows)
+	if err != nil {
+		return nil, err
+	}
+	points := []tsdb.Point{}
+	for i := len(reports) - 1; i >= 0; i-- {
+		if point, ok := tsdb.ObservationPoint(reports[i]); ok && !point.Time.After(span.To) {
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+// downsample reduces datapoints to at most limit by averaging consecutive buckets, so a
+// panel never receives more points than it can draw. A non-positive limit keeps all.
+func downsample(datapoints [][2]float64, limit int) [][2]float64 {
+	if limit <= 0 || len(datapoints) <= limit {
+		return datapoints
+	}
+	size := (len(datapoints) + limit - 1) / limit
+	reduced := make([][2]float64, 0, limit)
+	for start := 0; start < len(datapoints); start += size {
+		end := min(start+size, len(datapoints))
+		var sum float64
+		for _, datapoint := range datapoints[start:end] {
+			sum += datapoint[0]
+		}
+		reduced = append(reduced, [2]float64{sum / float64(end-start), datapoints[start][1]})
+	}
+	return reduced
+}
+
+// Annotations handles POST /annotations requests. The annotation query is a city ID;
+// every stored alert for the city overlapping the range is returned.
+func (c *HTTPGrafanaController) Annotations(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	var request GrafanaAnnotationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	cityID, err := strconv.Atoi(strings.TrimSpace(request.Annotation.Query))
+	if err != nil || cityID <= 0 {
+		return writeError(w, http.StatusBadRequest, "Invalid annotation query", "the query must be a city ID")
+	}
+
+	annotations := []GrafanaAnnotation{}
+	for offset := 0; offset < grafanaMaxRows; offset += grafanaPageSize {
+		alerts, err := c.alerts.List(ctx, grafanaPageSize, offset)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to load alerts", err.Error())
+		}
+		for _, alert := range alerts {
+			if alert.CityID != cityID {
+				continue
+			}
+			start, end := alertSpan(alert)
+			if start.After(request.Range.To) || (!end.IsZero() && end.Before(request.Range.From)) {
+				continue
+			}
+			annotation := GrafanaAnnotation{
+				Annotation: request.Annotation,
+				Time:       start.UnixMilli(),
+				Title:      alert.Title,
+				Text:       alert.Description,
+				Tags:       []string{},
+			}
+			if !end.IsZero() {
+				annotation.TimeEnd = end.UnixMilli()
+			}
+			for _, tag := range []string{alert.Severity, alert.Urgency, alert.Category} {
+				if tag != "" {
+					annotation.Tags = append(annotation.Tags, strings.ToLower(tag))
+				}
+			}
+			annotations = append(annotations, annotation)
+		}
+		if len(alerts) < grafanaPageSize {
+			break
+		}
+	}
+	sort.Slice(annotations, func(i, j int) bool { return annotations[i].Time < annotations[j].Time })
+	return writeJSON(w, http.StatusOK, annotations)
+}
+
+// alertSpan returns when an alert takes effect, falling back to when it was stored, and
+// when it ends, zero when open-ended
+func alertSpan(alert *repo.Alert) (time.Time, time.Time) {
+	start, err := time.Parse(time.RFC3339, alert.StartTime)
+	if err != nil {
+		start, _ = time.Parse(time.RFC3339, alert.CreatedAt)
+	}
+	end, _ := time.Parse(time.RFC3339, alert.EndTime)
+	return start, end
+}
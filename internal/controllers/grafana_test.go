@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newGrafanaTestController(t *testing.T) (GrafanaController, *repo.City) {
+	t.Helper()
+	ctx := context.Background()
+	engine, _ := repo.OpenFileEngine("")
+	city := &repo.City{Name: "Portland", CountryCode: "US"}
+	_ = engine.Cities().Create(ctx, city)
+
+	base := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	at := func(hours int) string { return base.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339) }
+	_ = engine.Forecasts().CreateBatch(ctx, []*repo.Forecast{
+		{CityID: city.ID, ForecastTime: at(-12), ValidTime: at(0), Temperature: 5},
+		{CityID: city.ID, ForecastTime: at(-6), ValidTime: at(0), Temperature: 6}, // reissued
+		{CityID: city.ID, ForecastTime: at(-6), ValidTime: at(1), Temperature: 7},
+		{CityID: city.ID, ForecastTime: at(-6), ValidTime: at(30), Temperature: 9}, // outside range
+		{CityID: city.ID + 1, ForecastTime: at(-6), ValidTime: at(1), Temperature: 20},
+	})
+	temperature := 4.5
+	_ = engine.Aviation().Upsert(ctx, &repo.AviationReport{StationID: "KPDX", ReportType: models.ReportTypeMETAR, ObservedAt: at(2), Temperature: &temperature})
+	_ = engine.Alerts().Create(ctx, &repo.Alert{CityID: city.ID, Title: "Wind Advisory", Severity: "Moderate", StartTime: at(3), EndTime: at(9)})
+	_ = engine.Alerts().Create(ctx, &repo.Alert{CityID: city.ID, Title: "Old Advisory", StartTime: at(-50), EndTime: at(-40)})
+	return NewHTTPGrafanaController(engine), city
+}
+
+func grafanaPost(fn func(ctx context.Context, w http.ResponseWriter, r *http.Request) error, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	_ = fn(context.Background(), w, httptest.NewRequest("POST", "/v1/grafana", strings.NewReader(body)))
+	return w
+}
+
+const grafanaRange = `"range": {"from": "2024-01-14T00:00:00Z", "to": "2024-01-15T12:00:00Z"}`
+
+func TestGrafanaController_Search(t *testing.T) {
+	controller, city := newGrafanaTestController(t)
+
+	var metrics []GrafanaMetric
+	w := grafanaPost(controller.Search, `{"target": "portl"}`)
+	if err := json.Unmarshal(w.Body.Bytes(), &metrics); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if len(metrics) != 12 || metrics[0].Value != "forecast.temperature:"+strconv.Itoa(city.ID) || metrics[0].Text != "Portland, US forecast temperature" {
+		t.Errorf("Expected the city's forecast metrics, got %+v", metrics)
+	}
+
+	w = grafanaPost(controller.Search, `{"target": "kpdx"}`)
+	if !strings.Contains(w.Body.String(), `"observation.temperature:KPDX"`) {
+		t.Errorf("Expected observation metrics for an ICAO code, got %s", w.Body.String())
+	}
+}
+
+func TestGrafanaController_Query(t *testing.T) {
+	controller, city := newGrafanaTestController(t)
+	id := strconv.Itoa(city.ID)
+
+	w := grafanaPost(controller.Query, `{`+grafanaRange+`, "targets": [
+		{"target": "forecast.temperature:`+id+`", "refId": "A"},
+		{"target": "observation.temperature:kpdx", "refId": "B", "type": "table"}
+	]}`)
+	var response []json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil || len(response) != 2 {
+		t.Fatalf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+
+	var series GrafanaSeries
+	_ = json.Unmarshal(response[0], &series)
+	want := [][2]float64{{6, 1705276800000}, {7, 1705280400000}}
+	if len(series.Datapoints) != 2 || series.Datapoints[0] != want[0] || series.Datapoints[1] != want[1] {
+		t.Errorf("Expected the latest issue per valid time within range %v, got %v", want, series.Datapoints)
+	}
+
+	var table GrafanaTable
+	_ = json.Unmarshal(response[1], &table)
+	if table.Type != "table" || len(table.Rows) != 1 || table.Rows[0][1] != 4.5 {
+		t.Errorf("Unexpected table %+v", table)
+	}
+
+	for _, body := range []string{
+		`{` + grafanaRange + `, "targets": [{"target": "forecast.snow:1"}]}`,
+		`{` + grafanaRange + `, "targets": [{"target": "observation.temperature:portland"}]}`,
+		`{"targets": [{"target": "forecast.temperature:1"}]}`,
+	} {
+		if w := grafanaPost(controller.Query, body); w.Code != http.StatusBadRequest {
+			t.Errorf("Expected 400 for %s, got %d", body, w.Code)
+		}
+	}
+}
+
+func TestGrafanaController_Annotations(t *testing.T) {
+	controller, city := newGrafanaTestController(t)
+
+	w := grafanaPost(controller.Annotations, `{`+grafanaRange+`, "annotation": {"name": "Alerts", "query": "`+strconv.Itoa(city.ID)+`"}}`)
+	var annotations []GrafanaAnnotation
+	if err := json.Unmarshal(w.Body.Bytes(), &annotations); err != nil {
+		t.Fatalf("Unexpected response %d %s", w.Code, w.Body.String())
+	}
+	if len(annotations) != 1 || annotations[0].Title != "Wind Advisory" || annotations[0].TimeEnd-annotations[0].Time != 6*3600*1000 {
+		t.Errorf("Expected the overlapping alert only, got %+v", annotations)
+	}
+	if len(annotations) == 1 && (len(annotations[0].Tags) != 1 || annotations[0].Tags[0] != "moderate") {
+		t.Errorf("Expected the severity as a tag, got %v", annotations[0].Tags)
+	}
+
+	if w := grafanaPost(controller.Annotations, `{`+grafanaRange+`, "annotation": {"query": "Portland"}}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected a non-numeric query to be rejected, got %d", w.Code)
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	datapoints := [][2]float64{{1, 0}, {3, 1}, {5, 2}, {7, 3}, {9, 4}}
+	reduced := downsample(datapoints, 2)
+	if len(reduced) != 2 || reduced[0] != [2]float64{3, 0} || reduced[1] != [2]float64{8, 3} {
+		t.Errorf("Unexpected downsample %v", reduced)
+	}
+	if len(downsample(datapoints, 0)) != 5 {
+		t.Error("Expected a zero limit to keep every datapoint")
+	}
+}
@@ -0,0 +1,438 @@
+package controllers
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"github.com/graph-gophers/dataloader/v7"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+const (
+	// maxGraphQLBody caps the size of a POSTed GraphQL request
+	maxGraphQLBody = 1 << 20
+	// maxGraphQLDepth caps how deeply selections nest
+	maxGraphQLDepth = 8
+	// cityLoaderWait is how long the city loader collects IDs before fetching them in one
+	// query
+	cityLoaderWait = 2 * time.Millisecond
+)
+
+// graphQLSDL describes cities, places, forecasts and alerts. Lists are paged like their
+// REST counterparts: first defaults to the resource's page size and is capped at its
+// maximum, and forecasts page by cursor.
+//
+//go:embed schema.graphql
+var graphQLSDL string
+
+// GraphQLController serves the read API as a GraphQL schema, so dashboards can fetch a
+// city, its latest forecast and its alerts in one round trip
+type GraphQLController interface {
+	// Query handles GraphQL requests sent as GET query parameters or a POSTed body
+	Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Schema handles requests for the schema in the schema definition language
+	Schema(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// HTTPGraphQLController implements GraphQLController for HTTP requests
+type HTTPGraphQLController struct {
+	schema   *graphql.Schema
+	analysis *ast.Schema // the same schema, for estimating the cost of queries
+	cities   repo.CityRepository
+}
+
+// NewHTTPGraphQLController creates a GraphQL controller over the given repositories
+func NewHTTPGraphQLController(cities repo.CityRepository, places repo.PlaceRepository, forecasts repo.ForecastRepository, alerts repo.AlertRepository) GraphQLController {
+	resolver := &graphQLResolver{cities: cities, places: places, forecasts: forecasts, alerts: alerts}
+	return &HTTPGraphQLController{
+		schema:   graphql.MustParseSchema(graphQLSDL, resolver, graphql.UseStringDescriptions(), graphql.UseFieldResolvers(), graphql.MaxDepth(maxGraphQLDepth)),
+		analysis: mustLoadGraphQLAnalysis(graphQLSDL),
+		cities:   cities,
+	}
+}
+
+// graphQLRequest is a GraphQL request as sent over HTTP
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Query handles GET and POST /graphql requests. GET takes query, variables (JSON) and
+// operationName parameters; POST takes a JSON body with the same fields, or the query
+// alone as application/graphql. Requests that fail to parse or validate, or would cost
+// more than the limits of checkCost, answer 400; once execution starts the answer is
+// 200, with field errors listed under errors.
+func (c *HTTPGraphQLController) Query(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	req, err := readGraphQLRequest(r)
+	if err != nil {
+		return writeGraphQLErrors(w, gqlerrors.Errorf("%s", err))
+	}
+	if errs := c.schema.ValidateWithVariables(req.Query, req.Variables); len(errs) > 0 {
+		return writeGraphQLErrors(w, errs...)
+	}
+	if err := c.checkCost(req); err != nil {
+		return writeGraphQLErrors(w, gqlerrors.Errorf("%s", err))
+	}
+
+	ctx = context.WithValue(ctx, cityLoaderKey{}, newCityLoader(c.cities))
+	response := c.schema.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	status := http.StatusOK
+	if response.Data == nil {
+		status = http.StatusBadRequest
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	return writeJSON(w, status, response)
+}
+
+// writeGraphQLErrors answers 400 with errors and no data
+func writeGraphQLErrors(w http.ResponseWriter, errs ...*gqlerrors.QueryError) error {
+	w.Header().Set("Cache-Control", "no-store")
+	return writeJSON(w, http.StatusBadRequest, &graphql.Response{Errors: errs})
+}
+
+// readGraphQLRequest decodes a GraphQL request from the URL or body of r
+func readGraphQLRequest(r *http.Request) (graphQLRequest, error) {
+	var req graphQLRequest
+	if r.Method == http.MethodGet {
+		query := r.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return req, fmt.Errorf("variables must be a JSON object: %w", err)
+			}
+		}
+	} else {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxGraphQLBody+1))
+		if err != nil {
+			return req, fmt.Errorf("failed to read request body: %w", err)
+		}
+		if len(body) > maxGraphQLBody {
+			return req, fmt.Errorf("request body exceeds %d bytes", maxGraphQLBody)
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/graphql" {
+			req.Query = string(body)
+		} else if err := json.Unmarshal(body, &req); err != nil {
+			return req, fmt.Errorf("body must be a JSON object with a query: %w", err)
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("query is required")
+	}
+	return req, nil
+}
+
+// Schema handles GET /graphql/schema requests
+func (c *HTTPGraphQLController) Schema(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, err := io.WriteString(w, graphQLSDL)
+	return err
+}
+
+// cityLoaderKey is the context key of the request's city loader
+type cityLoaderKey struct{}
+
+// newCityLoader creates a loader batching the city lookups of one request, so the city
+// of every forecast or alert in a list is fetched in one query rather than one each
+func newCityLoader(cities repo.CityRepository) *dataloader.Loader[int, *City] {
+	return dataloader.NewBatchedLoader(func(ctx context.Context, ids []int) []*dataloader.Result[*City] {
+		rows, err := cities.GetByIDs(ctx, ids)
+		byID := make(map[int]*City, len(rows))
+		for _, row := range rows {
+			byID[row.ID] = fromRepoCity(row)
+		}
+		results := make([]*dataloader.Result[*City], len(ids))
+		for i, id := range ids {
+			results[i] = &dataloader.Result[*City]{Data: byID[id], Error: err}
+		}
+		return results
+	}, dataloader.WithWait[int, *City](cityLoaderWait))
+}
+
+// graphQLResolver resolves the Query type over the repositories
+type graphQLResolver struct {
+	cities    repo.CityRepository
+	places    repo.PlaceRepository
+	forecasts repo.ForecastRepository
+	alerts    repo.AlertRepository
+}
+
+// graphQLArgs holds the arguments of the list and page fields; each field reads those it
+// declares. Offset declares a default, so it is never null.
+type graphQLArgs struct {
+	Search  *string
+	Country *string
+	CityID  *int32
+	First   *int32
+	Offset  int32
+	After   *string
+}
+
+// limit returns the page size of a list field of resource
+func (a graphQLArgs) limit(resource string) int {
+	if a.First == nil {
+		return clampLimit(0, resource)
+	}
+	return clampLimit(int(*a.First), resource)
+}
+
+// offset returns the offset, treating negative offsets as 0
+func (a graphQLArgs) offset() int {
+	return max(int(a.Offset), 0)
+}
+
+func (q *graphQLResolver) City(ctx context.Context, args struct{ ID int32 }) (*graphQLCity, error) {
+	return q.cityByID(ctx, int(args.ID))
+}
+
+func (q *graphQLResolver) Cities(ctx context.Context, args graphQLArgs) ([]*graphQLCity, error) {
+	limit, offset := args.limit(ResourceCities), args.offset()
+	var rows []*repo.City
+	var err error
+	switch {
+	case args.Search != nil:
+		rows, err = q.cities.Search(ctx, *args.Search, offset+limit)
+		rows = rows[min(offset, len(rows)):]
+	case args.Country != nil:
+		rows, err = q.cities.GetByCountry(ctx, *args.Country, limit, offset)
+	default:
+		rows, err = q.cities.List(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return q.wrapCities(convertAll(rows, fromRepoCity)), nil
+}
+
+func (q *graphQLResolver) Place(ctx context.Context, args struct{ ID int32 }) (*graphQLPlace, error) {
+	place, err := q.places.GetByID(ctx, int(args.ID))
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &graphQLPlace{*fromRepoPlace(place)}, nil
+}
+
+func (q *graphQLResolver) Places(ctx context.Context, args graphQLArgs) ([]*graphQLPlace, error) {
+	limit, offset := args.limit(ResourcePlaces), args.offset()
+	var rows []*repo.Place
+	var err error
+	if args.Search != nil {
+		rows, err = q.places.Search(ctx, *args.Search, offset+limit)
+		rows = rows[min(offset, len(rows)):]
+	} else {
+		rows, err = q.places.List(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, err
+	}
+	places := make([]*graphQLPlace, len(rows))
+	for i, row := range rows {
+		places[i] = &graphQLPlace{*fromRepoPlace(row)}
+	}
+	return places, nil
+}
+
+func (q *graphQLResolver) Forecast(ctx context.Context, args struct{ ID int32 }) (*graphQLForecast, error) {
+	forecast, err := q.forecasts.GetByID(ctx, int(args.ID))
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &graphQLForecast{*fromRepoForecast(forecast), q}, nil
+}
+
+func (q *graphQLResolver) Forecasts(ctx context.Context, args graphQLArgs) (*graphQLForecastPage, error) {
+	cityID := 0
+	if args.CityID != nil {
+		cityID = int(*args.CityID)
+	}
+	return q.forecastPage(ctx, cityID, args)
+}
+
+func (q *graphQLResolver) Alert(ctx context.Context, args struct{ ID int32 }) (*graphQLAlert, error) {
+	alert, err := q.alerts.GetByID(ctx, int(args.ID))
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &graphQLAlert{*fromRepoAlert(alert), q}, nil
+}
+
+func (q *graphQLResolver) Alerts(ctx context.Context, args graphQLArgs) ([]*graphQLAlert, error) {
+	rows, err := q.alerts.List(ctx, args.limit(ResourceAlerts), args.offset())
+	if err != nil {
+		return nil, err
+	}
+	return q.wrapAlerts(convertAll(rows, fromRepoAlert)), nil
+}
+
+// cityByID loads a city through the request's loader; a missing city is null
+func (q *graphQLResolver) cityByID(ctx context.Context, id int) (*graphQLCity, error) {
+	loader := ctx.Value(cityLoaderKey{}).(*dataloader.Loader[int, *City])
+	city, err := loader.Load(ctx, id)()
+	if err != nil || city == nil {
+		return nil, err
+	}
+	return &graphQLCity{*city, q}, nil
+}
+
+// forecastPage fetches a page of forecasts of every city, or of cityID when positive
+func (q *graphQLResolver) forecastPage(ctx context.Context, cityID int, args graphQLArgs) (*graphQLForecastPage, error) {
+	after := ""
+	if args.After != nil {
+		after = *args.After
+	}
+	page, err := fetchForecastPage(ctx, q.forecasts, cityID, after, args.limit(ResourceForecasts))
+	if err != nil {
+		return nil, err
+	}
+	return &graphQLForecastPage{page, q}, nil
+}
+
+// activeAlerts returns the alerts in effect in a city
+func (q *graphQLResolver) activeAlerts(ctx context.Context, cityID int) ([]*graphQLAlert, error) {
+	rows, err := q.alerts.GetActiveByCityID(ctx, cityID)
+	if err != nil {
+		return nil, err
+	}
+	return q.wrapAlerts(convertAll(rows, fromRepoAlert)), nil
+}
+
+func (q *graphQLResolver) wrapCities(cities []*City) []*graphQLCity {
+	wrapped := make([]*graphQLCity, len(cities))
+	for i, city := range cities {
+		wrapped[i] = &graphQLCity{*city, q}
+	}
+	return wrapped
+}
+
+func (q *graphQLResolver) wrapAlerts(alerts []*Alert) []*graphQLAlert {
+	wrapped := make([]*graphQLAlert, len(alerts))
+	for i, alert := range alerts {
+		wrapped[i] = &graphQLAlert{*alert, q}
+	}
+	return wrapped
+}
+
+// graphQLCity resolves the City type. Fields without a method resolve to the embedded
+// City's field of the same name.
+type graphQLCity struct {
+	City
+	root *graphQLResolver
+}
+
+func (c *graphQLCity) ID() int32         { return int32(c.City.ID) }
+func (c *graphQLCity) Population() int32 { return int32(c.City.Population) }
+func (c *graphQLCity) GeonameID() int32  { return int32(c.City.GeonameID) }
+
+func (c *graphQLCity) LatestForecast(ctx context.Context) (*graphQLForecast, error) {
+	forecast, err := c.root.forecasts.GetLatestByCityID(ctx, c.City.ID)
+	if err != nil {
+		return nil, ignoreNotFound(err)
+	}
+	return &graphQLForecast{*fromRepoForecast(forecast), c.root}, nil
+}
+
+func (c *graphQLCity) Forecasts(ctx context.Context, args graphQLArgs) (*graphQLForecastPage, error) {
+	return c.root.forecastPage(ctx, c.City.ID, args)
+}
+
+func (c *graphQLCity) Alerts(ctx context.Context) ([]*graphQLAlert, error) {
+	return c.root.activeAlerts(ctx, c.City.ID)
+}
+
+// graphQLForecast resolves the Forecast type
+type graphQLForecast struct {
+	Forecast
+	root *graphQLResolver
+}
+
+func (f *graphQLForecast) ID() int32     { return int32(f.Forecast.ID) }
+func (f *graphQLForecast) CityID() int32 { return int32(f.Forecast.CityID) }
+
+func (f *graphQLForecast) City(ctx context.Context) (*graphQLCity, error) {
+	return f.root.cityByID(ctx, f.Forecast.CityID)
+}
+
+func (f *graphQLForecast) Alerts(ctx context.Context) ([]*graphQLAlert, error) {
+	return f.root.activeAlerts(ctx, f.Forecast.CityID)
+}
+
+// graphQLForecastPage resolves the ForecastPage type
+type graphQLForecastPage struct {
+	page *ForecastPage
+	root *graphQLResolver
+}
+
+func (p *graphQLForecastPage) Nodes() []*graphQLForecast {
+	nodes := make([]*graphQLForecast, len(p.page.Nodes))
+	for i, forecast := range p.page.Nodes {
+		nodes[i] = &graphQLForecast{*forecast, p.root}
+	}
+	return nodes
+}
+
+func (p *graphQLForecastPage) NextCursor() *string {
+	return optionalString(p.page.NextCursor)
+}
+
+// graphQLPlace resolves the Place type
+type graphQLPlace struct {
+	Place
+}
+
+func (p *graphQLPlace) ID() int32 { return int32(p.Place.ID) }
+
+// graphQLAlert resolves the Alert type
+type graphQLAlert struct {
+	Alert
+	root *graphQLResolver
+}
+
+func (a *graphQLAlert) ID() int32          { return int32(a.Alert.ID) }
+func (a *graphQLAlert) StartTime() *string { return optionalString(a.Alert.StartTime) }
+func (a *graphQLAlert) EndTime() *string   { return optionalString(a.Alert.EndTime) }
+
+// City is null when the alert is not tied to a city
+func (a *graphQLAlert) City(ctx context.Context) (*graphQLCity, error) {
+	if a.Alert.CityID <= 0 {
+		return nil, nil
+	}
+	return a.root.cityByID(ctx, a.Alert.CityID)
+}
+
+// convertAll converts repository rows to their controller types
+func convertAll[R, T any](rows []*R, convert func(*R) *T) []*T {
+	converted := make([]*T, len(rows))
+	for i, row := range rows {
+		converted[i] = convert(row)
+	}
+	return converted
+}
+
+// ignoreNotFound turns a missing row into null, leaving other errors as field errors
+func ignoreNotFound(err error) error {
+	if errors.Is(err, repo.ErrNotFound) {
+		return nil
+	}
+	return err
+}
+
+// optionalString returns nil for an empty string, so it is null in the response
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,242 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPGraphQLController_Query(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+
+	london := &repo.City{Name: "London", Country: "United Kingdom", CountryCode: "GB", IsActive: true}
+	paris := &repo.City{Name: "Paris", Country: "France", CountryCode: "FR", IsActive: true}
+	for _, city := range []*repo.City{london, paris} {
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for hours, temperature := range []float64{21, 18, 15} {
+		valid := now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339)
+		f := &repo.Forecast{CityID: london.ID, SourceProvider: "Stub", ForecastTime: valid, ValidTime: valid, Temperature: temperature}
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	end := now.Add(time.Hour).Format(time.RFC3339)
+	if err := engine.Alerts().Upsert(ctx, &repo.Alert{SourceProvider: "Stub", ProviderAlertID: "1", CityID: london.ID, Title: "Fog", EndTime: end}); err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Places().Create(ctx, &repo.Place{DisplayName: "10 Downing Street, London", Source: "census", SourcePlaceID: "1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	controller := NewHTTPGraphQLController(engine.Cities(), engine.Places(), engine.Forecasts(), engine.Alerts())
+	post := func(t *testing.T, body string) (*httptest.ResponseRecorder, map[string]any) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		if err := controller.Query(r.Context(), w, r); err != nil {
+			t.Fatal(err)
+		}
+		var response map[string]any
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Fatalf("Failed to decode %s: %v", w.Body.String(), err)
+		}
+		return w, response
+	}
+
+	t.Run("nested city, latest forecast and alerts", func(t *testing.T) {
+		query, _ := json.Marshal(map[string]any{
+			"query":     `query($id: Int!) { city(id: $id) { name latestForecast { temperature alerts { title city { name } } } } }`,
+			"variables": map[string]any{"id": london.ID},
+		})
+		w, response := post(t, string(query))
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		want := `{"data":{"city":{"name":"London","latestForecast":{"temperature":21,"alerts":[{"title":"Fog","city":{"name":"London"}}]}}}}`
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Errorf("Expected %s\ngot      %s", want, got)
+		}
+		if response["errors"] != nil {
+			t.Errorf("Expected no errors, got %v", response["errors"])
+		}
+	})
+
+	t.Run("forecast pages follow the cursor", func(t *testing.T) {
+		var temperatures []float64
+		after := ""
+		for pages := 0; pages < 5; pages++ {
+			query, _ := json.Marshal(map[string]any{
+				"query":     `query($after: String) { forecasts(cityId: ` + strconv.Itoa(london.ID) + `, first: 2, after: $after) { nodes { temperature } nextCursor } }`,
+				"variables": map[string]any{"after": after},
+			})
+			_, response := post(t, string(query))
+			page := response["data"].(map[string]any)["forecasts"].(map[string]any)
+			for _, node := range page["nodes"].([]any) {
+				temperatures = append(temperatures, node.(map[string]any)["temperature"].(float64))
+			}
+			if page["nextCursor"] == nil {
+				break
+			}
+			after = page["nextCursor"].(string)
+		}
+		if len(temperatures) != 3 || temperatures[0] != 21 || temperatures[2] != 15 {
+			t.Errorf("Expected the three forecasts latest first, got %v", temperatures)
+		}
+	})
+
+	t.Run("lists and missing objects", func(t *testing.T) {
+		w, _ := post(t, `{"query":"{ cities(country: \"FR\") { name } places(search: \"Downing\") { displayName } city(id: 999) { name } }"}`)
+		want := `{"data":{"cities":[{"name":"Paris"}],"places":[{"displayName":"10 Downing Street, London"}],"city":null}}`
+		if got := strings.TrimSpace(w.Body.String()); got != want {
+			t.Errorf("Expected %s\ngot      %s", want, got)
+		}
+	})
+
+	t.Run("invalid cursor is a field error", func(t *testing.T) {
+		w, response := post(t, `{"query":"{ forecasts(after: \"bogus\") { nextCursor } }"}`)
+		if w.Code != http.StatusOK || response["errors"] == nil {
+			t.Errorf("Expected 200 with errors, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("invalid queries are rejected", func(t *testing.T) {
+		for _, body := range []string{`{"query":"{ city(id: 1) { email } }"}`, `{"query":""}`, `not json`} {
+			w, response := post(t, body)
+			if w.Code != http.StatusBadRequest || response["errors"] == nil || response["data"] != nil {
+				t.Errorf("Expected 400 without data for %s, got %d: %s", body, w.Code, w.Body.String())
+			}
+		}
+	})
+
+	t.Run("GET", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		params := url.Values{"query": {`query($id: Int!) { city(id: $id) { countryCode } }`}, "variables": {`{"id":` + strconv.Itoa(paris.ID) + `}`}}
+		r := httptest.NewRequest(http.MethodGet, "/graphql?"+params.Encode(), nil)
+		if err := controller.Query(r.Context(), w, r); err != nil {
+			t.Fatal(err)
+		}
+		if want := `{"data":{"city":{"countryCode":"FR"}}}`; strings.TrimSpace(w.Body.String()) != want {
+			t.Errorf("Expected %s, got %s", want, w.Body.String())
+		}
+	})
+}
+
+func TestHTTPGraphQLController_Schema(t *testing.T) {
+	controller := NewHTTPGraphQLController(&MockCityRepository{}, &MockPlaceRepository{}, &MockForecastRepository{}, &MockAlertRepository{})
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/graphql/schema", nil)
+	if err := controller.Schema(r.Context(), w, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"type Query {", "  latestForecast: Forecast\n", "  forecasts(cityId: Int, first: Int, after: String): ForecastPage!\n"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("Expected the schema to contain %q, got:\n%s", want, w.Body.String())
+		}
+	}
+}
+
+func TestHTTPGraphQLController_Limits(t *testing.T) {
+	controller := NewHTTPGraphQLController(&MockCityRepository{}, &MockPlaceRepository{}, &MockForecastRepository{}, &MockAlertRepository{})
+	aliases := make([]string, maxGraphQLFields)
+	for i := range aliases {
+		aliases[i] = "n" + strconv.Itoa(i) + ": name"
+	}
+	for name, query := range map[string]string{
+		"depth":      `{ city(id: 1) { latestForecast { city { latestForecast { city { latestForecast { city { latestForecast { city { name } } } } } } } } } }`,
+		"complexity": `{ cities(first: 100) { forecasts(first: 500) { nodes { temperature } } } }`,
+		"fields":     `{ city(id: 1) { ` + strings.Join(aliases, " ") + ` } }`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"query": query})
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+			if err := controller.Query(r.Context(), w, r); err != nil {
+				t.Fatal(err)
+			}
+			if w.Code != http.StatusBadRequest || strings.Contains(w.Body.String(), `"data"`) {
+				t.Errorf("Expected 400 without data, got %d: %s", w.Code, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("default page sizes fit", func(t *testing.T) {
+		body := `{"query":"{ cities { name forecasts { nodes { temperature city { name alerts { title } } } } } }"}`
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+		if err := controller.Query(r.Context(), w, r); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
+
+// countingCityRepository counts the city lookups made through it
+type countingCityRepository struct {
+	repo.CityRepository
+	byID, byIDs atomic.Int32
+}
+
+func (r *countingCityRepository) GetByID(ctx context.Context, id int) (*repo.City, error) {
+	r.byID.Add(1)
+	return r.CityRepository.GetByID(ctx, id)
+}
+
+func (r *countingCityRepository) GetByIDs(ctx context.Context, ids []int) ([]*repo.City, error) {
+	r.byIDs.Add(1)
+	return r.CityRepository.GetByIDs(ctx, ids)
+}
+
+func TestHTTPGraphQLController_BatchesCities(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := time.Now().UTC().Format(time.RFC3339)
+	names := []string{"Oslo", "Bergen", "Tromsø", "Stavanger"}
+	for _, name := range names {
+		city := &repo.City{Name: name, Country: "Norway", CountryCode: "NO", IsActive: true}
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Forecasts().Create(ctx, &repo.Forecast{CityID: city.ID, SourceProvider: "Stub", ForecastTime: valid, ValidTime: valid}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cities := &countingCityRepository{CityRepository: engine.Cities()}
+	controller := NewHTTPGraphQLController(cities, engine.Places(), engine.Forecasts(), engine.Alerts())
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query":"{ forecasts { nodes { city { name } } } }"}`))
+	if err := controller.Query(r.Context(), w, r); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if !strings.Contains(w.Body.String(), `"name":"`+name+`"`) {
+			t.Errorf("Expected %s in %s", name, w.Body.String())
+		}
+	}
+	if cities.byID.Load() != 0 || cities.byIDs.Load() >= int32(len(names)) {
+		t.Errorf("Expected the cities to be fetched in batches, got %d single and %d batched lookups", cities.byID.Load(), cities.byIDs.Load())
+	}
+}
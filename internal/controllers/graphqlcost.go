@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"cmp"
+	"fmt"
+	"strings"
+
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+)
+
+const (
+	// maxGraphQLFields caps the fields a query selects once its fragments are expanded
+	maxGraphQLFields = 500
+	// maxGraphQLComplexity caps the objects a query is estimated to resolve
+	maxGraphQLComplexity = 5000
+	// graphQLListEstimate is the assumed length of lists without a page size, such as a
+	// city's active alerts
+	graphQLListEstimate = 10
+)
+
+// graphQLResources maps object types to the resource whose page sizes bound their lists
+var graphQLResources = map[string]string{
+	"City":         ResourceCities,
+	"Place":        ResourcePlaces,
+	"Forecast":     ResourceForecasts,
+	"ForecastPage": ResourceForecasts,
+	"Alert":        ResourceAlerts,
+}
+
+// mustLoadGraphQLAnalysis loads the schema for estimating the cost of queries
+func mustLoadGraphQLAnalysis(sdl string) *ast.Schema {
+	return gqlparser.MustLoadSchema(&ast.Source{Name: "schema.graphql", Input: sdl})
+}
+
+// checkCost rejects queries that select more than maxGraphQLFields fields or are
+// estimated to resolve more than maxGraphQLComplexity objects, before they run. A list
+// counts as its page size (first, or the resource's default) or graphQLListEstimate, and
+// multiplies the cost of everything selected under it. Introspection is served from
+// memory and costs nothing.
+func (c *HTTPGraphQLController) checkCost(req graphQLRequest) error {
+	doc, errs := gqlparser.LoadQueryWithRules(c.analysis, req.Query, nil)
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	operation := doc.Operations.ForName(req.OperationName)
+	if operation == nil {
+		return fmt.Errorf("unknown operation %q", req.OperationName)
+	}
+
+	cost := &graphQLCost{variables: req.Variables}
+	cost.add(operation.SelectionSet, 1, 0)
+	switch {
+	case cost.fields > maxGraphQLFields:
+		return fmt.Errorf("query selects more than %d fields", maxGraphQLFields)
+	case cost.objects > maxGraphQLComplexity:
+		return fmt.Errorf("query is estimated to resolve more than %d objects; request smaller pages or fewer nested lists", maxGraphQLComplexity)
+	}
+	return nil
+}
+
+// graphQLCost tallies the fields and estimated objects of a query
+type graphQLCost struct {
+	variables map[string]any
+	fields    int
+	objects   int
+}
+
+// add tallies a selection set resolved multiplier times. pageSize is the page size of
+// the enclosing page field, which its nodes list takes as its length.
+func (c *graphQLCost) add(set ast.SelectionSet, multiplier, pageSize int) {
+	for _, selection := range set {
+		// Stop early, so expanding nested fragments cannot itself be expensive
+		if c.fields > maxGraphQLFields || c.objects > maxGraphQLComplexity {
+			return
+		}
+		switch s := selection.(type) {
+		case *ast.Field:
+			c.fields++
+			if len(s.SelectionSet) == 0 || strings.HasPrefix(s.Name, "__") {
+				continue
+			}
+			size, limit := 1, pageSize
+			if s.Definition.Arguments.ForName("first") != nil {
+				first := graphQLInt(s.ArgumentMap(c.variables)["first"])
+				limit = clampLimit(first, graphQLResources[s.Definition.Type.Name()])
+			}
+			if s.Definition.Type.Elem != nil {
+				size, limit = cmp.Or(limit, graphQLListEstimate), 0
+			}
+			c.objects += multiplier * size
+			c.add(s.SelectionSet, multiplier*size, limit)
+		case *ast.InlineFragment:
+			c.add(s.SelectionSet, multiplier, pageSize)
+		case *ast.FragmentSpread:
+			c.add(s.Definition.SelectionSet, multiplier, pageSize)
+		}
+	}
+}
+
+// graphQLInt returns an Int argument given as a literal or a decoded JSON variable, or 0
+func graphQLInt(value any) int {
+	switch v := value.(type) {
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
+	}
+	return 0
+}
@@ -0,0 +1,235 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	weatherv1 "stormlightlabs.org/weather_api/proto/weather/v1"
+)
+
+// weatherService implements weather.v1.WeatherService over the repositories
+type weatherService struct {
+	weatherv1.UnimplementedWeatherServiceServer
+
+	cities    repo.CityRepository
+	places    repo.PlaceRepository
+	forecasts repo.ForecastRepository
+	alerts    repo.AlertRepository
+}
+
+// NewGRPCServer serves WeatherService over the given repositories, for internal
+// services that prefer gRPC stubs and streaming to the REST API. Lists are paged like
+// their REST counterparts.
+func NewGRPCServer(cities repo.CityRepository, places repo.PlaceRepository, forecasts repo.ForecastRepository, alerts repo.AlertRepository) weatherv1.WeatherServiceServer {
+	return &weatherService{cities: cities, places: places, forecasts: forecasts, alerts: alerts}
+}
+
+func (s *weatherService) GetCity(ctx context.Context, req *weatherv1.GetCityRequest) (*weatherv1.City, error) {
+	city, err := s.cities.GetByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(err, "city")
+	}
+	return protoCity(fromRepoCity(city)), nil
+}
+
+func (s *weatherService) ListCities(ctx context.Context, req *weatherv1.ListCitiesRequest) (*weatherv1.ListCitiesResponse, error) {
+	limit, offset := clampLimit(int(req.GetPageSize()), ResourceCities), max(int(req.GetOffset()), 0)
+	var rows []*repo.City
+	var err error
+	switch {
+	case req.GetSearch() != "":
+		rows, err = s.cities.Search(ctx, req.GetSearch(), offset+limit)
+		rows = rows[min(offset, len(rows)):]
+	case req.GetCountryCode() != "":
+		rows, err = s.cities.GetByCountry(ctx, req.GetCountryCode(), limit, offset)
+	default:
+		rows, err = s.cities.List(ctx, limit, offset)
+	}
+	if err != nil {
+		return nil, grpcError(err, "cities")
+	}
+	return &weatherv1.ListCitiesResponse{Cities: convertAll(convertAll(rows, fromRepoCity), protoCity)}, nil
+}
+
+func (s *weatherService) GetPlace(ctx context.Context, req *weatherv1.GetPlaceRequest) (*weatherv1.Place, error) {
+	place, err := s.places.GetByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(err, "place")
+	}
+	return protoPlace(fromRepoPlace(place)), nil
+}
+
+func (s *weatherService) SearchPlaces(ctx context.Context, req *weatherv1.SearchPlacesRequest) (*weatherv1.SearchPlacesResponse, error) {
+	if req.GetQuery() == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	rows, err := s.places.Search(ctx, req.GetQuery(), clampLimit(int(req.GetPageSize()), ResourcePlaces))
+	if err != nil {
+		return nil, grpcError(err, "places")
+	}
+	return &weatherv1.SearchPlacesResponse{Places: convertAll(convertAll(rows, fromRepoPlace), protoPlace)}, nil
+}
+
+func (s *weatherService) GetForecast(ctx context.Context, req *weatherv1.GetForecastRequest) (*weatherv1.Forecast, error) {
+	forecast, err := s.forecasts.GetByID(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, grpcError(err, "forecast")
+	}
+	return protoForecast(fromRepoForecast(forecast)), nil
+}
+
+func (s *weatherService) GetLatestForecast(ctx context.Context, req *weatherv1.GetLatestForecastRequest) (*weatherv1.Forecast, error) {
+	forecast, err := s.forecasts.GetLatestByCityID(ctx, int(req.GetCityId()))
+	if err != nil {
+		return nil, grpcError(err, "forecast")
+	}
+	return protoForecast(fromRepoForecast(forecast)), nil
+}
+
+func (s *weatherService) ListForecasts(ctx context.Context, req *weatherv1.ListForecastsRequest) (*weatherv1.ListForecastsResponse, error) {
+	page, err := fetchForecastPage(ctx, s.forecasts, int(req.GetCityId()), req.GetPageToken(), clampLimit(int(req.GetPageSize()), ResourceForecasts))
+	if err != nil {
+		return nil, grpcError(err, "forecasts")
+	}
+	return &weatherv1.ListForecastsResponse{Forecasts: convertAll(page.Nodes, protoForecast), NextPageToken: page.NextCursor}, nil
+}
+
+func (s *weatherService) StreamForecasts(req *weatherv1.StreamForecastsRequest, stream weatherv1.WeatherService_StreamForecastsServer) error {
+	ctx := stream.Context()
+	token := req.GetPageToken()
+	for {
+		page, err := fetchForecastPage(ctx, s.forecasts, int(req.GetCityId()), token, pageSize(ResourceForecasts).Max)
+		if err != nil {
+			return grpcError(err, "forecasts")
+		}
+		for _, forecast := range page.Nodes {
+			if err := stream.Send(protoForecast(forecast)); err != nil {
+				return err
+			}
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		token = page.NextCursor
+	}
+}
+
+func (s *weatherService) ListActiveAlerts(ctx context.Context, req *weatherv1.ListActiveAlertsRequest) (*weatherv1.ListActiveAlertsResponse, error) {
+	rows, err := s.alerts.GetActiveByCityID(ctx, int(req.GetCityId()))
+	if err != nil {
+		return nil, grpcError(err, "alerts")
+	}
+	return &weatherv1.ListActiveAlertsResponse{Alerts: convertAll(convertAll(rows, fromRepoAlert), protoAlert)}, nil
+}
+
+// grpcError maps a repository error to the status clients see
+func grpcError(err error, resource string) error {
+	switch {
+	case errors.Is(err, repo.ErrNotFound):
+		return status.Errorf(codes.NotFound, "%s not found", resource)
+	case errors.Is(err, errInvalidCursor):
+		return status.Error(codes.InvalidArgument, "page_token is invalid; pass back next_page_token from a previous page")
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Errorf(codes.Internal, "failed to retrieve %s: %v", resource, err)
+	}
+}
+
+// protoCity converts the city to a weather.v1.City
+func protoCity(c *City) *weatherv1.City {
+	return &weatherv1.City{
+		Id:          int64(c.ID),
+		Name:        c.Name,
+		Country:     c.Country,
+		CountryCode: c.CountryCode,
+		Region:      c.Region,
+		Latitude:    c.Latitude,
+		Longitude:   c.Longitude,
+		Elevation:   c.Elevation,
+		Population:  int64(c.Population),
+		Timezone:    c.Timezone,
+		GeonameId:   int64(c.GeonameID),
+		IsCapital:   c.IsCapital,
+		IsActive:    c.IsActive,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+	}
+}
+
+// protoPlace converts the place to a weather.v1.Place
+func protoPlace(p *Place) *weatherv1.Place {
+	return &weatherv1.Place{
+		Id:            int64(p.ID),
+		DisplayName:   p.DisplayName,
+		AddressLine1:  p.AddressLine1,
+		AddressLine2:  p.AddressLine2,
+		City:          p.City,
+		Region:        p.Region,
+		PostalCode:    p.PostalCode,
+		Country:       p.Country,
+		CountryCode:   p.CountryCode,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+		PlaceType:     p.PlaceType,
+		Confidence:    p.Confidence,
+		Source:        p.Source,
+		SourcePlaceId: p.SourcePlaceID,
+		BoundingBox:   p.BoundingBox,
+		CreatedAt:     p.CreatedAt,
+		UpdatedAt:     p.UpdatedAt,
+	}
+}
+
+// protoForecast converts the forecast to a weather.v1.Forecast
+func protoForecast(f *Forecast) *weatherv1.Forecast {
+	return &weatherv1.Forecast{
+		Id:              int64(f.ID),
+		CityId:          int64(f.CityID),
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime,
+		ValidTime:       f.ValidTime,
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UvIndex:         f.UVIndex,
+		CreatedAt:       f.CreatedAt,
+		UpdatedAt:       f.UpdatedAt,
+	}
+}
+
+// protoAlert converts the alert to a weather.v1.Alert
+func protoAlert(a *Alert) *weatherv1.Alert {
+	return &weatherv1.Alert{
+		Id:              int64(a.ID),
+		SourceProvider:  a.SourceProvider,
+		ProviderAlertId: a.ProviderAlertID,
+		CityId:          int64(a.CityID),
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		Title:           a.Title,
+		Description:     a.Description,
+		Severity:        a.Severity,
+		Urgency:         a.Urgency,
+		Category:        a.Category,
+		AreaDesc:        a.AreaDesc,
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+		CreatedAt:       a.CreatedAt,
+		UpdatedAt:       a.UpdatedAt,
+	}
+}
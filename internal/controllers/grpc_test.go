@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	weatherv1 "stormlightlabs.org/weather_api/proto/weather/v1"
+)
+
+func TestGRPCServer(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+
+	london := &repo.City{Name: "London", Country: "United Kingdom", CountryCode: "GB", IsActive: true}
+	paris := &repo.City{Name: "Paris", Country: "France", CountryCode: "FR", IsActive: true}
+	for _, city := range []*repo.City{london, paris} {
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for hours, temperature := range []float64{21, 18, 15} {
+		valid := now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339)
+		f := &repo.Forecast{CityID: london.ID, SourceProvider: "Stub", ForecastTime: valid, ValidTime: valid, Temperature: temperature}
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	end := now.Add(time.Hour).Format(time.RFC3339)
+	if err := engine.Alerts().Upsert(ctx, &repo.Alert{SourceProvider: "Stub", ProviderAlertID: "1", CityID: london.ID, Title: "Fog", EndTime: end}); err != nil {
+		t.Fatal(err)
+	}
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	weatherv1.RegisterWeatherServiceServer(server, NewGRPCServer(engine.Cities(), engine.Places(), engine.Forecasts(), engine.Alerts()))
+	go server.Serve(listener)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := weatherv1.NewWeatherServiceClient(conn)
+
+	t.Run("GetCity", func(t *testing.T) {
+		city, err := client.GetCity(ctx, &weatherv1.GetCityRequest{Id: int64(paris.ID)})
+		if err != nil || city.GetName() != "Paris" || city.GetCountryCode() != "FR" {
+			t.Errorf("Expected Paris, got %v, %v", city, err)
+		}
+
+		_, err = client.GetCity(ctx, &weatherv1.GetCityRequest{Id: 999})
+		if status.Code(err) != codes.NotFound {
+			t.Errorf("Expected NOT_FOUND, got %v", err)
+		}
+	})
+
+	t.Run("ListCities", func(t *testing.T) {
+		resp, err := client.ListCities(ctx, &weatherv1.ListCitiesRequest{CountryCode: "GB"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cities := resp.GetCities(); len(cities) != 1 || cities[0].GetName() != "London" {
+			t.Errorf("Expected London, got %v", cities)
+		}
+	})
+
+	t.Run("ListForecasts pages", func(t *testing.T) {
+		page, err := client.ListForecasts(ctx, &weatherv1.ListForecastsRequest{CityId: int64(london.ID), PageSize: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		token := page.GetNextPageToken()
+		if forecasts := page.GetForecasts(); len(forecasts) != 2 || forecasts[0].GetTemperature() != 21 || token == "" {
+			t.Fatalf("Expected the two latest forecasts and a token, got %v", page)
+		}
+
+		page, err = client.ListForecasts(ctx, &weatherv1.ListForecastsRequest{CityId: int64(london.ID), PageSize: 2, PageToken: token})
+		if err != nil || len(page.GetForecasts()) != 1 || page.GetNextPageToken() != "" {
+			t.Errorf("Expected the last forecast without a token, got %v, %v", page, err)
+		}
+
+		_, err = client.ListForecasts(ctx, &weatherv1.ListForecastsRequest{PageToken: "bogus"})
+		if status.Code(err) != codes.InvalidArgument {
+			t.Errorf("Expected INVALID_ARGUMENT for a bad token, got %v", err)
+		}
+	})
+
+	t.Run("StreamForecasts", func(t *testing.T) {
+		stream, err := client.StreamForecasts(ctx, &weatherv1.StreamForecastsRequest{CityId: int64(london.ID)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var temperatures []float64
+		for {
+			forecast, err := stream.Recv()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			temperatures = append(temperatures, forecast.GetTemperature())
+		}
+		if len(temperatures) != 3 || temperatures[0] != 21 || temperatures[2] != 15 {
+			t.Errorf("Expected three forecasts latest first, got %v", temperatures)
+		}
+	})
+
+	t.Run("ListActiveAlerts", func(t *testing.T) {
+		resp, err := client.ListActiveAlerts(ctx, &weatherv1.ListActiveAlertsRequest{CityId: int64(london.ID)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if alerts := resp.GetAlerts(); len(alerts) != 1 || alerts[0].GetTitle() != "Fog" {
+			t.Errorf("Expected the fog alert, got %v", alerts)
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// HandlerFunc adapts a controller method to an http.HandlerFunc.
+//
+// The adapters attach the request's consistency token (see repo.ConsistencyHeader) to
+// the context handed to the controller, so reads following a write observe it.
+func HandlerFunc(fn func(ctx context.Context, w http.ResponseWriter, r *http.Request) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_ = fn(requestContext(r), w, r)
+	}
+}
+
+// IDHandlerFunc adapts a controller method taking a numeric path parameter to an
+// http.HandlerFunc. The parameter is read with r.PathValue(name), so the route must
+// be registered with a matching wildcard (e.g. "GET /cities/{id}").
+func IDHandlerFunc(name string, fn func(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue(name))
+		if err != nil || id <= 0 {
+			_ = writeError(w, http.StatusBadRequest, "Invalid parameter", name+" must be a positive integer")
+			return
+		}
+		_ = fn(requestContext(r), w, r, id)
+	}
+}
+
+// StringHandlerFunc adapts a controller method taking a string path parameter to an
+// http.HandlerFunc
+func StringHandlerFunc(name string, fn func(ctx context.Context, w http.ResponseWriter, r *http.Request, value string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		value := r.PathValue(name)
+		if value == "" {
+			_ = writeError(w, http.StatusBadRequest, "Missing parameter", name+" is required")
+			return
+		}
+		_ = fn(requestContext(r), w, r, value)
+	}
+}
+
+// requestContext returns the context for a controller call on r
+func requestContext(r *http.Request) context.Context {
+	return repo.WithConsistencyToken(r.Context(), r.Header.Get(repo.ConsistencyHeader))
+}
+
+// WriteJSON writes data as a JSON response with the given status
+func WriteJSON(w http.ResponseWriter, status int, data any) error {
+	return writeJSON(w, status, data)
+}
+
+// WriteError writes a structured HTTPError response
+func WriteError(w http.ResponseWriter, status int, message, details string) error {
+	return writeError(w, status, message, details)
+}
@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// requestLanguages returns the languages a client asked for, most preferred first.
+//
+// An explicit ?lang= override wins over the Accept-Language header. Regional tags are
+// followed by their base language ("pt-BR" also matches "pt") so that a region-specific
+// request still falls back to the generic translation before the canonical name.
+func requestLanguages(r *http.Request) []string {
+	if lang := strings.TrimSpace(r.URL.Query().Get("lang")); lang != "" {
+		return withBaseLanguages([]string{lang})
+	}
+	return acceptLanguages(r.Header.Get("Accept-Language"))
+}
+
+// acceptLanguages returns the tags of an Accept-Language header by preference, each
+// followed by its base language
+func acceptLanguages(header string) []string {
+	if header == "" {
+		return nil
+	}
+
+	type weighted struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, ok := strings.CutPrefix(param, "q="); ok {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		tags = append(tags, weighted{tag: tag, q: q})
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	languages := make([]string, 0, len(tags))
+	for _, t := range tags {
+		languages = append(languages, t.tag)
+	}
+	return withBaseLanguages(languages)
+}
+
+// withBaseLanguages lowercases tags and inserts each tag's base language right after it
+func withBaseLanguages(tags []string) []string {
+	seen := make(map[string]bool)
+	var languages []string
+	add := func(tag string) {
+		if tag != "" && !seen[tag] {
+			seen[tag] = true
+			languages = append(languages, tag)
+		}
+	}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.ReplaceAll(tag, "_", "-"))
+		add(tag)
+		if base, _, found := strings.Cut(tag, "-"); found {
+			add(base)
+		}
+	}
+	return languages
+}
+
+// localizeCities replaces each city's name with the best match for the request's
+// languages, keeping the canonical name in CanonicalName. Cities without a matching
+// translation, or any lookup failure, leave the canonical name untouched.
+func (c *HTTPCityController) localizeCities(ctx context.Context, w http.ResponseWriter, r *http.Request, cities []*City) {
+	w.Header().Add("Vary", "Accept-Language")
+
+	languages := requestLanguages(r)
+	if len(languages) == 0 || len(cities) == 0 {
+		return
+	}
+
+	cityIDs := make([]int, 0, len(cities))
+	for _, city := range cities {
+		cityIDs = append(cityIDs, city.ID)
+	}
+
+	names, err := c.repo.GetLocalizedNames(ctx, cityIDs, languages)
+	if err != nil {
+		return
+	}
+
+	for _, city := range cities {
+		for _, lang := range languages {
+			if name, ok := names[city.ID][lang]; ok && name != "" {
+				city.CanonicalName = city.Name
+				city.Name = name
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestRequestLanguages(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		header   string
+		expected []string
+	}{
+		{"no preference", "/cities/1", "", nil},
+		{"single tag", "/cities/1", "de", []string{"de"}},
+		{"regional tag adds base", "/cities/1", "pt-BR", []string{"pt-br", "pt"}},
+		{"ordered by quality", "/cities/1", "en;q=0.5, fr-CA, de;q=0.8", []string{"fr-ca", "fr", "de", "en"}},
+		{"wildcard and q=0 ignored", "/cities/1", "*, es;q=0", nil},
+		{"lang override wins", "/cities/1?lang=ja", "de", []string{"ja"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.url, nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+
+			got := requestLanguages(req)
+			if len(got) == 0 && len(tt.expected) == 0 {
+				return
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestCityLocalization(t *testing.T) {
+	city := createTestRepoCity()
+	city.Name = "Munich"
+	city.ID = 7
+
+	t.Run("localized name with canonical fallback field", func(t *testing.T) {
+		mockRepo := &MockCityRepository{
+			cities:         []*repo.City{city},
+			localizedNames: map[int]map[string]string{7: {"de": "München"}},
+		}
+		controller := NewHTTPCityController(mockRepo)
+
+		req := httptest.NewRequest("GET", "/cities/search?q=Mun", nil)
+		req.Header.Set("Accept-Language", "de-AT, en;q=0.5")
+		w := httptest.NewRecorder()
+
+		if err := controller.Search(context.Background(), w, req); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		var response []*City
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response[0].Name != "München" || response[0].CanonicalName != "Munich" {
+			t.Errorf("expected localized name, got %+v", response[0])
+		}
+	})
+
+	t.Run("falls back to canonical name", func(t *testing.T) {
+		mockRepo := &MockCityRepository{
+			cities:         []*repo.City{city},
+			localizedNames: map[int]map[string]string{},
+		}
+		controller := NewHTTPCityController(mockRepo)
+
+		req := httptest.NewRequest("GET", "/cities/search?q=Mun&lang=it", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.Search(context.Background(), w, req); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		var response []*City
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response[0].Name != "Munich" || response[0].CanonicalName != "" {
+			t.Errorf("expected canonical name, got %+v", response[0])
+		}
+	})
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/jobs"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// JobController exposes the background job scheduler: registered jobs with their last
+// run, the run history, and actions to run a job now or retry a failed run
+type JobController interface {
+	// List handles requests to list registered jobs
+	List(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Run handles requests to start a job immediately
+	Run(ctx context.Context, w http.ResponseWriter, r *http.Request, name string) error
+
+	// ListRuns handles requests to list job runs
+	ListRuns(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// GetRun handles requests to get a job run
+	GetRun(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// Retry handles requests to retry a failed or partial job run
+	Retry(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+}
+
+// Job describes a registered job
+type Job struct {
+	Name            string  `json:"name"`
+	Description     string  `json:"description"`
+	IntervalSeconds int64   `json:"interval_seconds"` // 0 for jobs that only run on demand
+	Running         bool    `json:"running"`
+	NextRun         string  `json:"next_run,omitempty"`
+	LastRun         *JobRun `json:"last_run,omitempty"`
+}
+
+// JobRun is one recorded run of a job
+type JobRun struct {
+	ID          int         `json:"id"`
+	Job         string      `json:"job"`
+	TriggeredBy string      `json:"triggered_by"`       // schedule, manual or retry
+	RetryOf     int         `json:"retry_of,omitempty"` // run retried by this one
+	Status      string      `json:"status"`             // running, succeeded, partial or failed
+	StartedAt   string      `json:"started_at"`
+	FinishedAt  string      `json:"finished_at,omitempty"`
+	DurationMS  int64       `json:"duration_ms"`
+	Processed   int         `json:"processed"`
+	Skipped     []jobs.Skip `json:"skipped"`
+	Error       string      `json:"error,omitempty"`
+}
+
+// HTTPJobController implements JobController for HTTP requests
+type HTTPJobController struct {
+	runs      repo.JobRunRepository
+	scheduler *jobs.Scheduler
+}
+
+// NewHTTPJobController creates a new HTTP job controller
+func NewHTTPJobController(runs repo.JobRunRepository, scheduler *jobs.Scheduler) JobController {
+	return &HTTPJobController{runs: runs, scheduler: scheduler}
+}
+
+// List handles GET /jobs requests
+func (c *HTTPJobController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	infos := c.scheduler.Jobs()
+	response := make([]*Job, 0, len(infos))
+	for _, info := range infos {
+		job := &Job{
+			Name:            info.Name,
+			Description:     info.Description,
+			IntervalSeconds: int64(info.Interval / time.Second),
+			Running:         info.Running,
+		}
+		if !info.NextRun.IsZero() {
+			job.NextRun = info.NextRun.UTC().Format(time.RFC3339)
+		}
+
+		last, err := c.runs.List(ctx, info.Name, 1, 0)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve job runs", err.Error())
+		}
+		if len(last) > 0 {
+			job.LastRun = fromRepoJobRun(last[0])
+		}
+		response = append(response, job)
+	}
+
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// Run handles POST /jobs/{name}/run requests, answering 202 with the started run
+func (c *HTTPJobController) Run(ctx context.Context, w http.ResponseWriter, r *http.Request, name string) error {
+	run, err := c.scheduler.Trigger(ctx, name)
+	if err != nil {
+		return writeJobTriggerError(w, err)
+	}
+
+	w.Header().Set("Location", "/jobs/runs/"+strconv.Itoa(run.ID))
+	return writeJSON(w, http.StatusAccepted, fromRepoJobRun(run))
+}
+
+// ListRuns handles GET /jobs/runs[?job=] requests, newest first
+func (c *HTTPJobController) ListRuns(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	page, limit := getPagination(r, ResourceJobRuns)
+	runs, err := c.runs.List(ctx, r.URL.Query().Get("job"), limit, (page-1)*limit)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve job runs", err.Error())
+	}
+
+	response := make([]*JobRun, 0, len(runs))
+	for _, run := range runs {
+		response = append(response, fromRepoJobRun(run))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// GetRun handles GET /jobs/runs/{id} requests
+func (c *HTTPJobController) GetRun(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	run, err := c.runs.GetByID(ctx, id)
+	if err != nil {
+		return writeRepoError(w, err, "Job run", "Failed to retrieve job run")
+	}
+	return writeJSON(w, http.StatusOK, fromRepoJobRun(run))
+}
+
+// Retry handles POST /jobs/runs/{id}/retry requests, answering 202 with the new run.
+// Retrying a partial run only revisits the items it skipped.
+func (c *HTTPJobController) Retry(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if _, err := c.runs.GetByID(ctx, id); err != nil {
+		return writeRepoError(w, err, "Job run", "Failed to retrieve job run")
+	}
+
+	run, err := c.scheduler.Retry(ctx, id)
+	if err != nil {
+		return writeJobTriggerError(w, err)
+	}
+
+	w.Header().Set("Location", "/jobs/runs/"+strconv.Itoa(run.ID))
+	return writeJSON(w, http.StatusAccepted, fromRepoJobRun(run))
+}
+
+// writeJobTriggerError maps scheduler errors to responses
+func writeJobTriggerError(w http.ResponseWriter, err error) error {
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob):
+		return writeError(w, http.StatusNotFound, "Job not found", err.Error())
+	case errors.Is(err, jobs.ErrJobRunning), errors.Is(err, jobs.ErrNotRetryable):
+		return writeError(w, http.StatusConflict, "Job cannot be started", err.Error())
+	default:
+		return writeError(w, http.StatusInternalServerError, "Failed to start job", err.Error())
+	}
+}
+
+func fromRepoJobRun(run *repo.JobRun) *JobRun {
+	skipped, err := jobs.DecodeSkipped(run.Skipped)
+	if err != nil {
+		skipped = []jobs.Skip{}
+	}
+	return &JobRun{
+		ID:          run.ID,
+		Job:         run.JobName,
+		TriggeredBy: run.TriggeredBy,
+		RetryOf:     run.RetryOf,
+		Status:      run.Status,
+		StartedAt:   run.StartedAt,
+		FinishedAt:  run.FinishedAt,
+		DurationMS:  run.DurationMS,
+		Processed:   run.Processed,
+		Skipped:     skipped,
+		Error:       run.Error,
+	}
+}
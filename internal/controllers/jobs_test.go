@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/jobs"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newJobController(t *testing.T) (JobController, *jobs.Scheduler) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scheduler := jobs.NewScheduler(engine.JobRuns(), nil)
+	scheduler.Register(jobs.Job{Name: "ingest", Description: "Fetch forecasts", Run: func(ctx context.Context, report *jobs.Report) error {
+		report.Processed(1)
+		report.Skip("Paris, FR (#3)", "no weather provider covers FR")
+		return nil
+	}})
+	return NewHTTPJobController(engine.JobRuns(), scheduler), scheduler
+}
+
+func TestJobController_RunAndRetry(t *testing.T) {
+	controller, scheduler := newJobController(t)
+	ctx := context.Background()
+
+	w := httptest.NewRecorder()
+	_ = controller.Run(ctx, w, httptest.NewRequest("POST", "/jobs/ingest/run", nil), "ingest")
+	if w.Code != http.StatusAccepted || w.Header().Get("Location") != "/jobs/runs/1" {
+		t.Fatalf("Expected an accepted run, got %d: %s", w.Code, w.Body.String())
+	}
+	scheduler.Wait()
+
+	w = httptest.NewRecorder()
+	_ = controller.GetRun(ctx, w, httptest.NewRequest("GET", "/jobs/runs/1", nil), 1)
+	var run JobRun
+	if err := json.NewDecoder(w.Body).Decode(&run); err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != jobs.StatusPartial || len(run.Skipped) != 1 || run.Skipped[0].Reason != "no weather provider covers FR" {
+		t.Errorf("Expected a partial run naming the skipped city, got %+v", run)
+	}
+
+	w = httptest.NewRecorder()
+	_ = controller.Retry(ctx, w, httptest.NewRequest("POST", "/jobs/runs/1/retry", nil), 1)
+	if w.Code != http.StatusAccepted || !json.Valid(w.Body.Bytes()) {
+		t.Fatalf("Expected an accepted retry, got %d: %s", w.Code, w.Body.String())
+	}
+	scheduler.Wait()
+
+	w = httptest.NewRecorder()
+	_ = controller.List(ctx, w, httptest.NewRequest("GET", "/jobs", nil))
+	var list []*Job
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].LastRun == nil || list[0].LastRun.RetryOf != 1 || list[0].LastRun.TriggeredBy != jobs.TriggerRetry {
+		t.Errorf("Expected the retry as the last run, got %+v", list)
+	}
+
+	w = httptest.NewRecorder()
+	_ = controller.ListRuns(ctx, w, httptest.NewRequest("GET", "/jobs/runs?job=ingest", nil))
+	var runs []*JobRun
+	if err := json.NewDecoder(w.Body).Decode(&runs); err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != 2 {
+		t.Errorf("Expected both runs newest first, got %+v", runs)
+	}
+}
+
+func TestJobController_Errors(t *testing.T) {
+	controller, _ := newJobController(t)
+	ctx := context.Background()
+
+	w := httptest.NewRecorder()
+	_ = controller.Run(ctx, w, httptest.NewRequest("POST", "/jobs/missing/run", nil), "missing")
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown job, got %d", http.StatusNotFound, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	_ = controller.Retry(ctx, w, httptest.NewRequest("POST", "/jobs/runs/9/retry", nil), 9)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d for an unknown run, got %d", http.StatusNotFound, w.Code)
+	}
+}
@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// maxLocationName bounds the length of a saved location's name
+const maxLocationName = 64
+
+// LocationController handles the locations users save (home, work, ...) and the
+// weather at all of them at once
+type LocationController interface {
+	// Create handles requests to save a location
+	Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Get handles requests for a saved location
+	Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// Update handles requests to rename or move a saved location
+	Update(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// SetDefault handles requests to make a saved location its user's default
+	SetDefault(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// Delete handles requests to remove a saved location
+	Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// ListByUser handles requests to list a user's saved locations
+	ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+
+	// WeatherByUser handles requests for the weather at every saved location of a user
+	WeatherByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+}
+
+// LocationRequest is the body of a request to save or change a location. A location
+// refers either to a stored city or to a point, not both. It is saved for the user
+// making the request.
+type LocationRequest struct {
+	Name      string   `json:"name"`
+	CityID    int      `json:"city_id,omitempty"`
+	Latitude  *float64 `json:"latitude,omitempty"`
+	Longitude *float64 `json:"longitude,omitempty"`
+	Default   bool     `json:"default,omitempty"` // ignored on update
+}
+
+// validate checks the request fields, normalizing the name and coordinates
+func (l *LocationRequest) validate() models.ValidationErrors {
+	var errs models.ValidationErrors
+	l.Name = strings.TrimSpace(l.Name)
+	if l.Name == "" || len(l.Name) > maxLocationName {
+		errs = append(errs, models.FieldError{Field: "name", Message: fmt.Sprintf("name is required and at most %d characters", maxLocationName)})
+	}
+
+	point := l.Latitude != nil || l.Longitude != nil
+	switch {
+	case l.CityID < 0:
+		errs = append(errs, models.FieldError{Field: "city_id", Message: "city_id must be a positive integer"})
+	case l.CityID > 0 && point:
+		errs = append(errs, models.FieldError{Field: "city_id", Message: "give either city_id or latitude and longitude, not both"})
+	case l.CityID == 0 && (l.Latitude == nil || l.Longitude == nil):
+		errs = append(errs, models.FieldError{Field: "city_id", Message: "city_id or latitude and longitude are required"})
+	case l.CityID == 0:
+		lat, lon, err := geo.Normalize(*l.Latitude, *l.Longitude)
+		if errors.Is(err, geo.ErrInvalidLatitude) {
+			errs = append(errs, models.FieldError{Field: "latitude", Message: err.Error()})
+		} else if err != nil {
+			errs = append(errs, models.FieldError{Field: "longitude", Message: err.Error()})
+		} else {
+			lat, lon = geo.Coarsen(lat, lon)
+			l.Latitude, l.Longitude = &lat, &lon
+		}
+	}
+	return errs
+}
+
+// Location is a saved location. Locations saved by city carry the city's coordinates
+// as of when they were saved.
+type Location struct {
+	ID        int     `json:"id"`
+	UserID    int     `json:"user_id"`
+	Name      string  `json:"name"`
+	CityID    int     `json:"city_id,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Default   bool    `json:"default"`
+	CreatedAt string  `json:"created_at"`
+	UpdatedAt string  `json:"updated_at"`
+}
+
+// LocationWeather is the weather at one saved location. Error is set instead of the
+// weather when no provider could answer for it.
+type LocationWeather struct {
+	Location *Location                `json:"location"`
+	Provider string                   `json:"provider,omitempty"`
+	Current  *Forecast                `json:"current,omitempty"`
+	Forecast []*Forecast              `json:"forecast,omitempty"`
+	Alerts   []providers.WeatherAlert `json:"alerts,omitempty"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+// HTTPLocationController implements LocationController for HTTP requests
+type HTTPLocationController struct {
+	locations repo.UserLocationRepository
+	cities    repo.CityRepository
+	providers *providers.ProviderManager
+}
+
+// NewHTTPLocationController creates a new HTTP saved location controller
+func NewHTTPLocationController(locations repo.UserLocationRepository, cities repo.CityRepository, pm *providers.ProviderManager) LocationController {
+	return &HTTPLocationController{locations: locations, cities: cities, providers: pm}
+}
+
+// Create handles POST /locations requests, saving the location for the caller
+func (c *HTTPLocationController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := requireOwner(ctx, w, "Locations")
+	if userID == 0 {
+		return nil
+	}
+	var req LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if errs := req.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	location := &repo.UserLocation{UserID: userID, IsDefault: req.Default}
+	if err := c.place(ctx, location, &req); err != nil {
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
+	}
+	if err := c.locations.Create(ctx, location); err != nil {
+		return writeRepoError(w, err, "Location", "Failed to save location")
+	}
+
+	w.Header().Set("Location", fmt.Sprintf("/locations/%d", location.ID))
+	return writeCommitted(w, http.StatusCreated, fromRepoLocation(location), "Location saved successfully")
+}
+
+// Get handles GET /locations/{id} requests
+func (c *HTTPLocationController) Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	location, ok := c.owned(ctx, w, id)
+	if !ok {
+		return nil
+	}
+
+	return writeJSON(w, http.StatusOK, fromRepoLocation(location))
+}
+
+// Update handles PUT /locations/{id} requests
+func (c *HTTPLocationController) Update(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	var req LocationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if errs := req.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	location, ok := c.owned(ctx, w, id)
+	if !ok {
+		return nil
+	}
+	if err := c.place(ctx, location, &req); err != nil {
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
+	}
+	if err := c.locations.Update(ctx, location); err != nil {
+		return writeRepoError(w, err, "Location", "Failed to update location")
+	}
+
+	return writeCommitted(w, http.StatusOK, fromRepoLocation(location), "Location updated successfully")
+}
+
+// SetDefault handles PUT /locations/{id}/default requests
+func (c *HTTPLocationController) SetDefault(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if _, ok := c.owned(ctx, w, id); !ok {
+		return nil
+	}
+	if err := c.locations.SetDefault(ctx, id); err != nil {
+		return writeRepoError(w, err, "Location", "Failed to set default location")
+	}
+
+	location, err := c.locations.GetByID(ctx, id)
+	if err != nil {
+		return writeRepoError(w, err, "Location", "Failed to retrieve location")
+	}
+	return writeCommitted(w, http.StatusOK, fromRepoLocation(location), "Default location set successfully")
+}
+
+// Delete handles DELETE /locations/{id} requests
+func (c *HTTPLocationController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if _, ok := c.owned(ctx, w, id); !ok {
+		return nil
+	}
+	if err := c.locations.Delete(ctx, id); err != nil {
+		return writeRepoError(w, err, "Location", "Failed to delete location")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Location deleted successfully")
+}
+
+// ListByUser handles GET /users/{id}/locations requests
+func (c *HTTPLocationController) ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	locations, err := c.locations.ListByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve locations", err.Error())
+	}
+
+	response := make([]*Location, 0, len(locations))
+	for _, location := range locations {
+		response = append(response, fromRepoLocation(location))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// WeatherByUser handles GET /users/{id}/locations/weather?days=&units= requests. The
+// locations are looked up in parallel, the default first, and one that fails carries
+// its error without failing the others.
+func (c *HTTPLocationController) WeatherByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = defaultForecastDays
+	}
+	days = min(days, maxForecastDays)
+
+	locations, err := c.locations.ListByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve locations", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+
+	response := make([]*LocationWeather, len(locations))
+	var wg sync.WaitGroup
+	for i, location := range locations {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response[i] = c.weather(ctx, location, days)
+			convertForecasts(opts, response[i].Current)
+			convertForecasts(opts, response[i].Forecast...)
+		}()
+	}
+	wg.Wait()
+
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// owned returns a saved location the caller of ctx may see or change, answering 404
+// for a missing location or another user's
+func (c *HTTPLocationController) owned(ctx context.Context, w http.ResponseWriter, id int) (*repo.UserLocation, bool) {
+	location, err := c.locations.GetByID(ctx, id)
+	if err != nil {
+		_ = writeRepoError(w, err, "Location", "Failed to retrieve location")
+		return nil, false
+	}
+	if !canAccess(ctx, location.UserID) {
+		_ = writeNotOwned(w, "Location")
+		return nil, false
+	}
+	return location, true
+}
+
+// weather fetches current conditions, forecast and alerts at a location from the first
+// weather provider covering it. Alerts are best effort, as for GET /weather.
+func (c *HTTPLocationController) weather(ctx context.Context, location *repo.UserLocation, days int) *LocationWeather {
+	result := &LocationWeather{Location: fromRepoLocation(location)}
+	lat, lon := location.Latitude, location.Longitude
+
+	err := fmt.Errorf("%w: no weather provider is registered", providers.ErrUnsupportedRegion)
+	for _, provider := range c.providers.GetWeatherProviders() {
+		current, lookupErr := provider.GetCurrentWeather(ctx, lat, lon)
+		if lookupErr != nil {
+			err = fmt.Errorf("%s: %w", provider.GetName(), lookupErr)
+			if errors.Is(lookupErr, providers.ErrUnsupportedRegion) {
+				continue
+			}
+			break
+		}
+
+		forecasts, lookupErr := provider.GetForecast(ctx, lat, lon, days)
+		if lookupErr != nil {
+			err = fmt.Errorf("%s: %w", provider.GetName(), lookupErr)
+			break
+		}
+		alerts, _ := provider.GetAlerts(ctx, lat, lon)
+
+		result.Provider = provider.GetName()
+		result.Current = fromModelForecast(current)
+		result.Forecast = make([]*Forecast, 0, len(forecasts))
+		for _, f := range forecasts {
+			result.Forecast = append(result.Forecast, fromModelForecast(f))
+		}
+		result.Alerts = alerts
+		return result
+	}
+
+	result.Error = err.Error()
+	return result
+}
+
+// place points location at the city or coordinates of a validated request, copying the
+// city's coordinates so the location can be looked up without it
+func (c *HTTPLocationController) place(ctx context.Context, location *repo.UserLocation, req *LocationRequest) error {
+	location.Name = req.Name
+	location.CityID = req.CityID
+	if req.CityID == 0 {
+		location.Latitude, location.Longitude = *req.Latitude, *req.Longitude
+		return nil
+	}
+
+	city, err := c.cities.GetByID(ctx, req.CityID)
+	if err != nil {
+		return err
+	}
+	location.Latitude, location.Longitude = city.Latitude, city.Longitude
+	return nil
+}
+
+func fromRepoLocation(l *repo.UserLocation) *Location {
+	return &Location{
+		ID:        l.ID,
+		UserID:    l.UserID,
+		Name:      l.Name,
+		CityID:    l.CityID,
+		Latitude:  l.Latitude,
+		Longitude: l.Longitude,
+		Default:   l.IsDefault,
+		CreatedAt: l.CreatedAt,
+		UpdatedAt: l.UpdatedAt,
+	}
+}
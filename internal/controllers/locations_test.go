@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newLocationController(t *testing.T, pm *providers.ProviderManager) (LocationController, int) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	city := toRepoCity(exampleCity())
+	if err := engine.Cities().Create(context.Background(), city); err != nil {
+		t.Fatal(err)
+	}
+	return NewHTTPLocationController(engine.UserLocations(), engine.Cities(), pm), city.ID
+}
+
+func saveLocation(t *testing.T, controller LocationController, body string) (*httptest.ResponseRecorder, *Location) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := controller.Create(asUser(1), w, httptest.NewRequest("POST", "/locations", strings.NewReader(body))); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data *Location `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(w.Body.String())).Decode(&response)
+	return w, response.Data
+}
+
+func TestLocationController(t *testing.T) {
+	controller, cityID := newLocationController(t, providers.NewProviderManager())
+	ctx := asUser(1)
+	if err := geo.SetPrecision(4); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = geo.SetPrecision(-1) })
+
+	w, home := saveLocation(t, controller, `{"name":" Home ","latitude":41.878113,"longitude":-87.629799}`)
+	if w.Code != http.StatusCreated || home == nil {
+		t.Fatalf("Expected a saved location, got %d: %s", w.Code, w.Body.String())
+	}
+	if home.Name != "Home" || !home.Default || home.Latitude != 41.8781 {
+		t.Errorf("Expected the trimmed name, coarsened point and first location as default, got %+v", home)
+	}
+	if w.Header().Get("Location") != "/locations/"+strconv.Itoa(home.ID) {
+		t.Errorf("Unexpected Location %q", w.Header().Get("Location"))
+	}
+
+	_, work := saveLocation(t, controller, `{"name":"Work","city_id":`+strconv.Itoa(cityID)+`}`)
+	if work == nil || work.Default || work.CityID != cityID || work.Latitude != exampleCity().Latitude {
+		t.Errorf("Expected a location at the city's coordinates, got %+v", work)
+	}
+
+	t.Run("Validation", func(t *testing.T) {
+		for _, body := range []string{
+			`{"name":"Gym"}`,
+			`{"name":"Gym","city_id":1,"latitude":1,"longitude":1}`,
+			`{"name":"Gym","latitude":91,"longitude":0}`,
+		} {
+			if w, _ := saveLocation(t, controller, body); w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected 422 for %s, got %d", body, w.Code)
+			}
+		}
+		if w, _ := saveLocation(t, controller, `{"name":"Gym","city_id":999}`); w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for a missing city, got %d", w.Code)
+		}
+		if w, _ := saveLocation(t, controller, `{"name":"home","latitude":1,"longitude":1}`); w.Code != http.StatusConflict {
+			t.Errorf("Expected 409 for a duplicate name, got %d", w.Code)
+		}
+	})
+
+	t.Run("SetDefault", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.SetDefault(ctx, w, httptest.NewRequest("PUT", "/locations/2/default", nil), work.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		w = httptest.NewRecorder()
+		if err := controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/locations", nil), 1); err != nil {
+			t.Fatal(err)
+		}
+		var locations []*Location
+		if err := json.NewDecoder(w.Body).Decode(&locations); err != nil {
+			t.Fatal(err)
+		}
+		if len(locations) != 2 || locations[0].ID != work.ID || !locations[0].Default || locations[1].Default {
+			t.Errorf("Expected work as the only default, listed first, got %+v", locations)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"name":"Apartment","latitude":41.9,"longitude":-87.65}`)
+		if err := controller.Update(ctx, w, httptest.NewRequest("PUT", "/locations/1", body), home.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Apartment"`) {
+			t.Errorf("Expected the renamed location, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		expectHidden(t, "Get", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Get(ctx, w, httptest.NewRequest("GET", "/locations/1", nil), home.ID)
+		})
+		expectHidden(t, "Update", func(ctx context.Context, w http.ResponseWriter) error {
+			body := strings.NewReader(`{"name":"Taken","latitude":1,"longitude":1}`)
+			return controller.Update(ctx, w, httptest.NewRequest("PUT", "/locations/1", body), home.ID)
+		})
+		expectHidden(t, "SetDefault", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.SetDefault(ctx, w, httptest.NewRequest("PUT", "/locations/1/default", nil), home.ID)
+		})
+		expectHidden(t, "Delete", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/locations/1", nil), home.ID)
+		})
+		expectHidden(t, "ListByUser", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/locations", nil), 1)
+		})
+		expectHidden(t, "WeatherByUser", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.WeatherByUser(ctx, w, httptest.NewRequest("GET", "/users/1/locations/weather", nil), 1)
+		})
+
+		// The user's location is untouched, and admins see it
+		w := httptest.NewRecorder()
+		if err := controller.Get(asAdmin(), w, httptest.NewRequest("GET", "/locations/1", nil), home.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"name":"Apartment"`) {
+			t.Errorf("Expected an admin to see the unchanged location, got %d: %s", w.Code, w.Body.String())
+		}
+
+		// A location saved by another user is theirs, whatever the body says
+		w = httptest.NewRecorder()
+		body := strings.NewReader(`{"user_id":1,"name":"Home","latitude":1,"longitude":1}`)
+		if err := controller.Create(asUser(2), w, httptest.NewRequest("POST", "/locations", body)); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"user_id":2`) {
+			t.Errorf("Expected the location saved for its creator, got %d: %s", w.Code, w.Body.String())
+		}
+		w = httptest.NewRecorder()
+		body = strings.NewReader(`{"name":"Office","latitude":1,"longitude":1}`)
+		if err := controller.Create(asAdmin(), w, httptest.NewRequest("POST", "/locations", body)); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected the admin token, which is no user, to be refused, got %d", w.Code)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/locations/1", nil), home.ID); err != nil {
+			t.Fatal(err)
+		}
+		w = httptest.NewRecorder()
+		if err := controller.Get(ctx, w, httptest.NewRequest("GET", "/locations/1", nil), home.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 after delete, got %d", w.Code)
+		}
+	})
+}
+
+func TestLocationWeather(t *testing.T) {
+	pm := providers.NewProviderManager()
+	pm.RegisterWeatherProvider(&conditionProvider{
+		stubWeatherProvider: stubWeatherProvider{name: "Regional"},
+		err:                 providers.ErrUnsupportedRegion,
+	})
+	pm.RegisterWeatherProvider(&stubWeatherProvider{name: "Global", regions: []string{providers.GlobalRegion}})
+	controller, cityID := newLocationController(t, pm)
+	saveLocation(t, controller, `{"name":"Home","latitude":41.88,"longitude":-87.63}`)
+	saveLocation(t, controller, `{"name":"Away","city_id":`+strconv.Itoa(cityID)+`,"default":true}`)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/users/1/locations/weather?days=2&units=imperial", nil)
+	if err := controller.WeatherByUser(asUser(1), w, r, 1); err != nil {
+		t.Fatal(err)
+	}
+	var response []*LocationWeather
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response) != 2 || response[0].Location.Name != "Away" {
+		t.Fatalf("Expected both locations, the default first, got %+v", response)
+	}
+	for _, weather := range response {
+		if weather.Provider != "Global" || weather.Current == nil || len(weather.Forecast) != 2 {
+			t.Errorf("Expected the global provider's weather, got %+v", weather)
+		}
+		if weather.Current != nil && weather.Current.Units != "imperial" {
+			t.Errorf("Expected imperial units, got %q", weather.Current.Units)
+		}
+	}
+
+	t.Run("Failures are per location", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&conditionProvider{err: errors.New("upstream down")})
+		controller, _ := newLocationController(t, pm)
+		saveLocation(t, controller, `{"name":"Home","latitude":41.88,"longitude":-87.63}`)
+
+		w := httptest.NewRecorder()
+		if err := controller.WeatherByUser(asUser(1), w, httptest.NewRequest("GET", "/users/1/locations/weather", nil), 1); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "upstream down") {
+			t.Errorf("Expected 200 with the location's error, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,321 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/ttl"
+)
+
+const (
+	defaultObservationHours = 24
+	maxObservationHours     = 168
+)
+
+// ObservationController handles requests for weather stations and their observations
+type ObservationController interface {
+	// GetStation handles requests for a station's metadata
+	GetStation(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error
+
+	// GetLatestObservation handles requests for a station's most recent observation
+	GetLatestObservation(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error
+
+	// GetObservations handles requests for a station's recent observation history
+	GetObservations(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error
+}
+
+// Station represents a weather station for controllers
+type Station struct {
+	ID             int      `json:"id"`
+	StationID      string   `json:"station_id"`
+	Name           string   `json:"name"`
+	SourceProvider string   `json:"source_provider"`
+	Latitude       float64  `json:"latitude"`
+	Longitude      float64  `json:"longitude"`
+	Elevation      *float64 `json:"elevation"`
+	TimeZone       string   `json:"time_zone"`
+	CreatedAt      string   `json:"created_at"`
+	UpdatedAt      string   `json:"updated_at"`
+}
+
+// Observation represents a station observation for controllers, in the units used by
+// forecasts. Measurements the station did not report are null.
+type Observation struct {
+	ID              int               `json:"id"`
+	StationID       string            `json:"station_id"`
+	SourceProvider  string            `json:"source_provider"`
+	ObservedAt      string            `json:"observed_at"`
+	Temperature     *float64          `json:"temperature"`
+	Dewpoint        *float64          `json:"dewpoint"`
+	Humidity        *float64          `json:"humidity"`
+	WindDirection   *float64          `json:"wind_direction"`
+	WindSpeed       *float64          `json:"wind_speed"`
+	WindGust        *float64          `json:"wind_gust"`
+	Pressure        *float64          `json:"pressure"`
+	StationPressure *float64          `json:"station_pressure"`
+	Visibility      *float64          `json:"visibility"`
+	Description     string            `json:"description"`
+	QualityFlags    map[string]string `json:"quality_flags"`
+	CreatedAt       string            `json:"created_at"`
+	UpdatedAt       string            `json:"updated_at"`
+}
+
+// HTTPObservationController implements ObservationController for HTTP requests
+type HTTPObservationController struct {
+	providers    *providers.ProviderManager
+	stations     repo.StationRepository
+	observations repo.ObservationRepository
+	policy       *ttl.Policy
+}
+
+// NewHTTPObservationController creates a new HTTP observation controller.
+//
+// Stations and observations are fetched from the first registered provider that
+// implements providers.StationObserver and stored, so history builds up as stations
+// are polled. Observations are then served from storage for the policy's current
+// conditions lifetime (ttl.Default when policy is nil), and when the provider fails
+// the last stored observation is returned instead.
+func NewHTTPObservationController(manager *providers.ProviderManager, stations repo.StationRepository, observations repo.ObservationRepository, policy *ttl.Policy) ObservationController {
+	if policy == nil {
+		policy = ttl.Default()
+	}
+	return &HTTPObservationController{providers: manager, stations: stations, observations: observations, policy: policy}
+}
+
+// GetStation handles GET /stations/{station} requests
+func (c *HTTPObservationController) GetStation(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error {
+	stationID = strings.ToUpper(stationID)
+	if !models.ValidStationID(stationID) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "station must be 3 to 10 letters or digits")
+	}
+
+	station, err := c.station(ctx, stationID)
+	if err != nil {
+		return writeStationError(w, "Failed to retrieve station", stationID, err)
+	}
+	return writeSuccess(w, http.StatusOK, fromRepoStation(station), "")
+}
+
+// GetLatestObservation handles GET /stations/{station}/observations/latest requests
+func (c *HTTPObservationController) GetLatestObservation(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error {
+	stationID = strings.ToUpper(stationID)
+	if !models.ValidStationID(stationID) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "station must be 3 to 10 letters or digits")
+	}
+
+	observation, err := c.latest(ctx, stationID)
+	if err != nil {
+		return writeStationError(w, "Failed to retrieve observation", stationID, err)
+	}
+	return writeSuccess(w, http.StatusOK, fromRepoObservation(observation), "")
+}
+
+// GetObservations handles GET /stations/{station}/observations?hours= requests, newest
+// first. The latest observation is refreshed first, so polling this endpoint records
+// a station's history.
+func (c *HTTPObservationController) GetObservations(ctx context.Context, w http.ResponseWriter, r *http.Request, stationID string) error {
+	stationID = strings.ToUpper(stationID)
+	if !models.ValidStationID(stationID) {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "station must be 3 to 10 letters or digits")
+	}
+
+	hours, err := strconv.Atoi(r.URL.Query().Get("hours"))
+	if err != nil || hours <= 0 {
+		hours = defaultObservationHours
+	}
+	if hours > maxObservationHours {
+		hours = maxObservationHours
+	}
+
+	_, fetchErr := c.latest(ctx, stationID)
+
+	since := time.Now().UTC().Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339)
+	stored, err := c.observations.GetByStation(ctx, stationID, since, hours*12)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve observations", err.Error())
+	}
+	if len(stored) == 0 && fetchErr != nil {
+		return writeStationError(w, "Failed to retrieve observations", stationID, fetchErr)
+	}
+
+	response := make([]*Observation, len(stored))
+	for i, observation := range stored {
+		response[i] = fromRepoObservation(observation)
+	}
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// observer returns the provider stations are fetched from
+func (c *HTTPObservationController) observer() (providers.StationObserver, error) {
+	if c.providers != nil {
+		for _, provider := range c.providers.GetWeatherProviders() {
+			if observer, ok := provider.(providers.StationObserver); ok {
+				return observer, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("%w: no weather provider publishes station observations", providers.ErrUnsupportedRegion)
+}
+
+// station returns a stored station, fetching and storing it on first use
+func (c *HTTPObservationController) station(ctx context.Context, stationID string) (*repo.Station, error) {
+	stored, err := c.stations.GetByStationID(ctx, stationID)
+	if err == nil {
+		return stored, nil
+	}
+	if !errors.Is(err, repo.ErrNotFound) {
+		return nil, err
+	}
+
+	observer, err := c.observer()
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := observer.GetStation(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	station := toRepoStation(fetched)
+	if err := c.stations.Upsert(ctx, station); err != nil {
+		return nil, err
+	}
+	return station, nil
+}
+
+// latest returns a station's newest observation, refreshing it from the provider when
+// the stored copy is missing or older than the current conditions lifetime
+func (c *HTTPObservationController) latest(ctx context.Context, stationID string) (*repo.Observation, error) {
+	stored, err := c.observations.GetLatest(ctx, stationID)
+	if err != nil {
+		stored = nil
+	}
+	if stored != nil && time.Since(parseRepoTime(stored.UpdatedAt)) < c.policy.Current() {
+		return stored, nil
+	}
+
+	observation, err := c.fetch(ctx, stationID)
+	if err != nil {
+		if stored != nil {
+			return stored, nil
+		}
+		return nil, err
+	}
+	return observation, nil
+}
+
+// fetch retrieves and stores a station's latest observation, storing the station
+// first since observations reference it. Storage failures of the observation itself
+// are not fatal: the unsaved copy is returned so the request can still be answered.
+func (c *HTTPObservationController) fetch(ctx context.Context, stationID string) (*repo.Observation, error) {
+	if _, err := c.station(ctx, stationID); err != nil {
+		return nil, err
+	}
+	observer, err := c.observer()
+	if err != nil {
+		return nil, err
+	}
+	fetched, err := observer.GetLatestObservation(ctx, stationID)
+	if err != nil {
+		return nil, err
+	}
+	observation := toRepoObservation(fetched)
+	_ = c.observations.Upsert(ctx, observation)
+	return observation, nil
+}
+
+// writeStationError maps a failed station lookup to a response: unknown stations are
+// a 404 whether the repository or the provider says so
+func writeStationError(w http.ResponseWriter, message, stationID string, err error) error {
+	var providerErr *providers.ProviderError
+	if errors.Is(err, repo.ErrNotFound) || errors.As(err, &providerErr) && providerErr.StatusCode == http.StatusNotFound {
+		return writeError(w, http.StatusNotFound, "Station not found", fmt.Sprintf("no station %s", stationID))
+	}
+	return writeProviderError(w, message, err)
+}
+
+func toRepoStation(s *models.Station) *repo.Station {
+	return &repo.Station{
+		ID:             s.ID,
+		StationID:      s.StationID,
+		Name:           s.Name,
+		SourceProvider: s.SourceProvider,
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		Elevation:      s.Elevation,
+		TimeZone:       s.TimeZone,
+	}
+}
+
+func fromRepoStation(s *repo.Station) *Station {
+	return &Station{
+		ID:             s.ID,
+		StationID:      s.StationID,
+		Name:           s.Name,
+		SourceProvider: s.SourceProvider,
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		Elevation:      s.Elevation,
+		TimeZone:       s.TimeZone,
+		CreatedAt:      s.CreatedAt,
+		UpdatedAt:      s.UpdatedAt,
+	}
+}
+
+func toRepoObservation(o *models.Observation) *repo.Observation {
+	flags := "{}"
+	if len(o.QualityFlags) > 0 {
+		if encoded, err := json.Marshal(o.QualityFlags); err == nil {
+			flags = string(encoded)
+		}
+	}
+	return &repo.Observation{
+		ID:              o.ID,
+		StationID:       o.StationID,
+		SourceProvider:  o.SourceProvider,
+		ObservedAt:      formatTime(o.ObservedAt),
+		Temperature:     o.Temperature,
+		Dewpoint:        o.Dewpoint,
+		Humidity:        o.Humidity,
+		WindDirection:   o.WindDirection,
+		WindSpeed:       o.WindSpeed,
+		WindGust:        o.WindGust,
+		Pressure:        o.Pressure,
+		StationPressure: o.StationPressure,
+		Visibility:      o.Visibility,
+		Description:     o.Description,
+		QualityFlags:    flags,
+	}
+}
+
+func fromRepoObservation(o *repo.Observation) *Observation {
+	flags := map[string]string{}
+	_ = json.Unmarshal([]byte(o.QualityFlags), &flags)
+	return &Observation{
+		ID:              o.ID,
+		StationID:       o.StationID,
+		SourceProvider:  o.SourceProvider,
+		ObservedAt:      o.ObservedAt,
+		Temperature:     o.Temperature,
+		Dewpoint:        o.Dewpoint,
+		Humidity:        o.Humidity,
+		WindDirection:   o.WindDirection,
+		WindSpeed:       o.WindSpeed,
+		WindGust:        o.WindGust,
+		Pressure:        o.Pressure,
+		StationPressure: o.StationPressure,
+		Visibility:      o.Visibility,
+		Description:     o.Description,
+		QualityFlags:    flags,
+		CreatedAt:       o.CreatedAt,
+		UpdatedAt:       o.UpdatedAt,
+	}
+}
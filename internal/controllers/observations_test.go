@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// stubObserverProvider is a stubWeatherProvider publishing one station's observations
+type stubObserverProvider struct {
+	stubWeatherProvider
+	err   error
+	calls int
+}
+
+func (s *stubObserverProvider) GetStation(ctx context.Context, stationID string) (*models.Station, error) {
+	if stationID != "KNYC" {
+		return nil, &providers.ProviderError{Provider: s.name, StatusCode: http.StatusNotFound}
+	}
+	return &models.Station{StationID: "KNYC", Name: "Central Park", SourceProvider: s.name, Latitude: 40.78, Longitude: -73.97}, nil
+}
+
+func (s *stubObserverProvider) GetLatestObservation(ctx context.Context, stationID string) (*models.Observation, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	temperature := 3.9
+	return &models.Observation{
+		StationID:      stationID,
+		SourceProvider: s.name,
+		ObservedAt:     time.Now().Add(-10 * time.Minute),
+		Temperature:    &temperature,
+		QualityFlags:   map[string]string{"temperature": "V"},
+	}, nil
+}
+
+func TestHTTPObservationController(t *testing.T) {
+	setup := func(t *testing.T, observer *stubObserverProvider) (ObservationController, *repo.FileEngine) {
+		engine, err := repo.OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		t.Cleanup(func() { engine.Close() })
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "Met.no"})
+		pm.RegisterWeatherProvider(observer)
+		return NewHTTPObservationController(pm, engine.Stations(), engine.Observations(), nil), engine
+	}
+
+	t.Run("latest observation is fetched, stored and then served", func(t *testing.T) {
+		observer := &stubObserverProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}}
+		controller, engine := setup(t, observer)
+
+		for range 2 {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest("GET", "/stations/knyc/observations/latest", nil)
+			if err := controller.GetLatestObservation(context.Background(), w, r, "knyc"); err != nil {
+				t.Fatalf("GetLatestObservation failed: %v", err)
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+			}
+			var response struct {
+				Data Observation `json:"data"`
+			}
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Data.StationID != "KNYC" || response.Data.QualityFlags["temperature"] != "V" {
+				t.Errorf("Unexpected observation %+v", response.Data)
+			}
+		}
+		if observer.calls != 1 {
+			t.Errorf("Expected the stored observation to be served the second time, got %d provider calls", observer.calls)
+		}
+		if _, err := engine.Stations().GetByStationID(context.Background(), "KNYC"); err != nil {
+			t.Errorf("Expected the station to be stored, got %v", err)
+		}
+	})
+
+	t.Run("history falls back to stored observations", func(t *testing.T) {
+		observer := &stubObserverProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}}
+		controller, _ := setup(t, observer)
+		w := httptest.NewRecorder()
+		if err := controller.GetLatestObservation(context.Background(), w, httptest.NewRequest("GET", "/", nil), "KNYC"); err != nil {
+			t.Fatalf("GetLatestObservation failed: %v", err)
+		}
+
+		observer.err = providers.ErrUpstreamUnavailable
+		w = httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/stations/KNYC/observations?hours=6", nil)
+		if err := controller.GetObservations(context.Background(), w, r, "KNYC"); err != nil {
+			t.Fatalf("GetObservations failed: %v", err)
+		}
+		var response struct {
+			Data []Observation `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if w.Code != http.StatusOK || len(response.Data) != 1 {
+			t.Errorf("Expected 1 stored observation, got %d with status %d", len(response.Data), w.Code)
+		}
+	})
+
+	t.Run("unknown station", func(t *testing.T) {
+		controller, _ := setup(t, &stubObserverProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}})
+		w := httptest.NewRecorder()
+		if err := controller.GetStation(context.Background(), w, httptest.NewRequest("GET", "/", nil), "KLGA"); err != nil {
+			t.Fatalf("GetStation failed: %v", err)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+
+		w = httptest.NewRecorder()
+		if err := controller.GetStation(context.Background(), w, httptest.NewRequest("GET", "/", nil), "K!"); err != nil {
+			t.Fatalf("GetStation failed: %v", err)
+		}
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Paginated resources. Each has its own page sizes because their rows differ widely in
+// weight: an hour of forecast is a few numbers, a city carries names and metadata.
+const (
+	ResourceForecasts = "forecasts"
+	ResourceCities    = "cities"
+	ResourcePlaces    = "places"
+	ResourceAlerts    = "alerts"
+	ResourceJobRuns   = "job_runs"
+)
+
+// PageSize is the page size used when a request gives no limit, and the largest limit
+// a request may ask for
+type PageSize struct {
+	Default int
+	Max     int
+}
+
+// DefaultPageSizes returns the built-in page sizes: 20 rows by default and at most 100,
+// except forecasts, which may be listed 500 at a time
+func DefaultPageSizes() map[string]PageSize {
+	return map[string]PageSize{
+		ResourceForecasts: {Default: 20, Max: 500},
+		ResourceCities:    {Default: 20, Max: 100},
+		ResourcePlaces:    {Default: 20, Max: 100},
+		ResourceAlerts:    {Default: 20, Max: 100},
+		ResourceJobRuns:   {Default: 20, Max: 100},
+	}
+}
+
+// pageSizes holds the page sizes in effect, set with SetPageSizes
+var pageSizes = struct {
+	sync.RWMutex
+	sizes map[string]PageSize
+}{sizes: DefaultPageSizes()}
+
+// ParsePageSizes parses page size overrides of the form "resource=default:max", e.g.
+// "forecasts=48:1000" or "cities=10:50", on top of DefaultPageSizes
+func ParsePageSizes(specs []string) (map[string]PageSize, error) {
+	sizes := DefaultPageSizes()
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		resource, value, found := strings.Cut(spec, "=")
+		defaultValue, maxValue, ok := strings.Cut(value, ":")
+		if !found || !ok {
+			return nil, fmt.Errorf("invalid page size %q: expected resource=default:max", spec)
+		}
+		if _, known := sizes[resource]; !known {
+			resources := slices.Sorted(maps.Keys(sizes))
+			return nil, fmt.Errorf("invalid page size %q: resource must be one of %s", spec, strings.Join(resources, ", "))
+		}
+		defaultSize, err := strconv.Atoi(defaultValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page size %q: %w", spec, err)
+		}
+		maxSize, err := strconv.Atoi(maxValue)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page size %q: %w", spec, err)
+		}
+		if defaultSize <= 0 || maxSize < defaultSize {
+			return nil, fmt.Errorf("invalid page size %q: need 0 < default <= max", spec)
+		}
+		sizes[resource] = PageSize{Default: defaultSize, Max: maxSize}
+	}
+	return sizes, nil
+}
+
+// SetPageSizes replaces the page sizes of every resource; resources left out keep
+// their DefaultPageSizes
+func SetPageSizes(sizes map[string]PageSize) {
+	merged := DefaultPageSizes()
+	maps.Copy(merged, sizes)
+
+	pageSizes.Lock()
+	defer pageSizes.Unlock()
+	pageSizes.sizes = merged
+}
+
+// pageSize returns the page sizes of a resource
+func pageSize(resource string) PageSize {
+	pageSizes.RLock()
+	defer pageSizes.RUnlock()
+	return pageSizes.sizes[resource]
+}
+
+// getPagination returns the requested page (from 1) and limit of a resource. A missing
+// or invalid limit is the resource's default and one above its maximum is capped.
+func getPagination(r *http.Request, resource string) (page, limit int) {
+	page, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+
+	limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	return page, clampLimit(limit, resource)
+}
+
+// clampLimit returns the page size to apply for a requested limit: the resource's
+// default when it is not positive, capped at the resource's maximum
+func clampLimit(limit int, resource string) int {
+	size := pageSize(resource)
+	if limit <= 0 {
+		limit = size.Default
+	}
+	return min(limit, size.Max)
+}
+
+// maxRadiusKm caps the ?radius= of nearby searches, whose cost grows with the area
+const maxRadiusKm = 500.0
+
+// queryRadius returns the ?radius= of r in km: fallback when it is missing, capped at
+// maxRadiusKm like limits are at their maximum
+func queryRadius(r *http.Request, fallback float64) (float64, error) {
+	value := r.URL.Query().Get("radius")
+	if value == "" {
+		return fallback, nil
+	}
+	radius, err := strconv.ParseFloat(value, 64)
+	if err != nil || !(radius > 0) {
+		return 0, fmt.Errorf("radius must be a positive number of km")
+	}
+	return min(radius, maxRadiusKm), nil
+}
+
+// includeTotal reports whether a listing counts its rows: unless the request passes
+// ?include_total=false, which spares the count on big tables
+func includeTotal(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("include_total")
+	if value == "" {
+		return true, nil
+	}
+	include, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("include_total must be true or false")
+	}
+	return include, nil
+}
+
+// newPaginatedResponse builds one page of a resource listing; a nil total leaves the
+// total and page count out
+func newPaginatedResponse[T any](data []*T, total *int, page, limit int, resource string) *PaginatedResponse[T] {
+	paginated := &PaginatedResponse[T]{
+		Data:     data,
+		Total:    total,
+		Page:     page,
+		PerPage:  limit,
+		Limit:    limit,
+		MaxLimit: pageSize(resource).Max,
+	}
+	if total != nil {
+		totalPages := (*total + limit - 1) / limit
+		paginated.TotalPages = &totalPages
+	}
+	return paginated
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestParsePageSizes(t *testing.T) {
+	sizes, err := ParsePageSizes([]string{"forecasts=48:1000", " cities=10:50 "})
+	if err != nil {
+		t.Fatalf("ParsePageSizes failed: %v", err)
+	}
+	if sizes[ResourceForecasts] != (PageSize{Default: 48, Max: 1000}) || sizes[ResourceCities] != (PageSize{Default: 10, Max: 50}) {
+		t.Errorf("Expected the overrides to apply, got %+v", sizes)
+	}
+	if sizes[ResourcePlaces] != DefaultPageSizes()[ResourcePlaces] {
+		t.Errorf("Expected resources left out to keep their defaults, got %+v", sizes[ResourcePlaces])
+	}
+
+	for _, spec := range []string{"forecasts", "forecasts=48", "stations=10:50", "cities=x:50", "cities=0:50", "cities=60:50"} {
+		if _, err := ParsePageSizes([]string{spec}); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestGetPagination(t *testing.T) {
+	SetPageSizes(map[string]PageSize{ResourceCities: {Default: 5, Max: 10}})
+	defer SetPageSizes(nil)
+
+	tests := []struct {
+		query    string
+		resource string
+		page     int
+		limit    int
+	}{
+		{"", ResourceCities, 1, 5},
+		{"?page=3&limit=7", ResourceCities, 3, 7},
+		{"?page=0&limit=-1", ResourceCities, 1, 5},
+		{"?limit=1000", ResourceCities, 1, 10},
+		{"?limit=1000", ResourceForecasts, 1, 500},
+	}
+	for _, tt := range tests {
+		page, limit := getPagination(httptest.NewRequest("GET", "/"+tt.query, nil), tt.resource)
+		if page != tt.page || limit != tt.limit {
+			t.Errorf("%s %q: expected page %d limit %d, got %d and %d", tt.resource, tt.query, tt.page, tt.limit, page, limit)
+		}
+	}
+}
+
+func TestQueryRadius(t *testing.T) {
+	tests := []struct {
+		query  string
+		radius float64
+		valid  bool
+	}{
+		{"", 25, true},
+		{"?radius=10.5", 10.5, true},
+		{"?radius=20000", maxRadiusKm, true},
+		{"?radius=0", 0, false},
+		{"?radius=NaN", 0, false},
+		{"?radius=far", 0, false},
+	}
+	for _, tt := range tests {
+		radius, err := queryRadius(httptest.NewRequest("GET", "/"+tt.query, nil), 25)
+		if (err == nil) != tt.valid || radius != tt.radius {
+			t.Errorf("%q: expected %v (valid %v), got %v (%v)", tt.query, tt.radius, tt.valid, radius, err)
+		}
+	}
+}
+
+func TestPaginatedResponseLimits(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{createTestRepoForecast()}, count: 1}
+	controller := NewHTTPForecastController(mockRepo)
+
+	w := httptest.NewRecorder()
+	if err := controller.List(context.Background(), w, httptest.NewRequest("GET", "/forecasts?limit=5000", nil)); err != nil {
+		t.Fatal(err)
+	}
+	var response PaginatedResponse[Forecast]
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Limit != 500 || response.MaxLimit != 500 || response.PerPage != 500 {
+		t.Errorf("Expected the limit capped at 500 and reported, got %+v", response)
+	}
+}
+
+func TestListIncludeTotal(t *testing.T) {
+	controller := NewHTTPCityController(&MockCityRepository{cities: []*repo.City{createTestRepoCity()}, count: 41})
+	list := func(query string) (int, map[string]any) {
+		w := httptest.NewRecorder()
+		if err := controller.List(context.Background(), w, httptest.NewRequest("GET", "/cities"+query, nil)); err != nil {
+			t.Fatal(err)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, body
+	}
+
+	if _, body := list("?limit=10"); body["total"] != 41.0 || body["total_pages"] != 5.0 {
+		t.Errorf("Expected the total and page count by default, got %v", body)
+	}
+	_, body := list("?include_total=false")
+	if _, ok := body["total"]; ok {
+		t.Errorf("Expected no total with include_total=false, got %v", body)
+	}
+	if _, ok := body["total_pages"]; ok || body["page"] != 1.0 {
+		t.Errorf("Expected the page without a page count, got %v", body)
+	}
+	if code, _ := list("?include_total=maybe"); code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an invalid include_total, got %d", code)
+	}
+}
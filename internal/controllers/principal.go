@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Principal is the caller a request was authenticated as. The authz middleware stores
+// it in the request context; controllers read it to attribute what a caller creates
+// and to keep users to their own saved locations, digests and subscriptions.
+type Principal struct {
+	UserID   int // 0 for the shared admin token
+	Username string
+	Role     string
+	Units    string // the user's preferred unit system; empty for the shared admin token
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying p
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the caller stored by WithPrincipal, or nil for
+// anonymous requests
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// canAccess reports whether the caller of ctx may see or change what userID owns: the
+// user itself, or an admin
+func canAccess(ctx context.Context, userID int) bool {
+	p := PrincipalFromContext(ctx)
+	if p == nil {
+		return false
+	}
+	return p.Role == models.RoleAdmin || (p.UserID > 0 && p.UserID == userID)
+}
+
+// requireOwner returns the user the caller of ctx creates rows for. Callers that are
+// not a user, such as the shared admin token, get a 403 naming resource and 0.
+func requireOwner(ctx context.Context, w http.ResponseWriter, resource string) int {
+	if p := PrincipalFromContext(ctx); p != nil && p.UserID > 0 {
+		return p.UserID
+	}
+	_ = writeError(w, http.StatusForbidden, "Forbidden", resource+" belong to a user: authenticate with the user's API key")
+	return 0
+}
+
+// writeNotOwned answers a request for another user's row as though the row did not
+// exist, so IDs cannot be probed
+func writeNotOwned(w http.ResponseWriter, resource string) error {
+	return writeRepoError(w, repo.ErrNotFound, resource, "")
+}
@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+// asUser returns a context authenticated as the user with id and the user role
+func asUser(id int) context.Context {
+	return WithPrincipal(context.Background(), &Principal{UserID: id, Username: "user" + strconv.Itoa(id), Role: models.RoleUser})
+}
+
+// asAdmin returns a context authenticated with the shared admin token
+func asAdmin() context.Context {
+	return WithPrincipal(context.Background(), &Principal{Username: "admin", Role: models.RoleAdmin})
+}
+
+// strangers are callers that must not see user 1's rows
+var strangers = map[string]context.Context{
+	"another user": asUser(2),
+	"anonymous":    context.Background(),
+}
+
+// expectHidden calls handler as every stranger, expecting the 404 of a missing row
+func expectHidden(t *testing.T, name string, handler func(ctx context.Context, w http.ResponseWriter) error) {
+	t.Helper()
+	for who, ctx := range strangers {
+		w := httptest.NewRecorder()
+		if err := handler(ctx, w); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s as %s: expected 404, got %d: %s", name, who, w.Code, w.Body.String())
+		}
+	}
+}
+
+func TestCanAccess(t *testing.T) {
+	tests := []struct {
+		ctx  context.Context
+		want bool
+	}{
+		{asUser(1), true},
+		{asUser(2), false},
+		{asAdmin(), true},
+		{context.Background(), false},
+		{WithPrincipal(context.Background(), &Principal{Role: models.RoleUser}), false},
+	}
+	for i, tt := range tests {
+		if got := canAccess(tt.ctx, 1); got != tt.want {
+			t.Errorf("%d: canAccess = %v, want %v", i, got, tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	if owner := requireOwner(asAdmin(), w, "Locations"); owner != 0 || w.Code != http.StatusForbidden {
+		t.Errorf("Expected the admin token to own nothing, got %d (%d)", owner, w.Code)
+	}
+	if owner := requireOwner(asUser(3), httptest.NewRecorder(), "Locations"); owner != 3 {
+		t.Errorf("Expected user 3 to own what it creates, got %d", owner)
+	}
+}
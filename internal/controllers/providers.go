@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// ProviderController handles provider capability discovery
+type ProviderController interface {
+	// List handles requests for the registered providers and their capabilities
+	List(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Status handles requests for the health check history of every provider
+	Status(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// Provider describes a registered provider for controllers. Priority is the order in
+// which providers of the same type are tried, starting at 1; for weather, providers
+// covering a country are still preferred there over global ones.
+type Provider struct {
+	Name       string         `json:"name"`
+	Type       string         `json:"type"`
+	Regions    []string       `json:"regions"`
+	Operations []string       `json:"operations"`
+	Priority   int            `json:"priority"`
+	Health     ProviderHealth `json:"health"`
+	Circuit    string         `json:"circuit,omitempty"` // breaker state: closed, open or half_open
+}
+
+// ProviderHealth is the outcome of a provider's latest health check
+type ProviderHealth struct {
+	Status    string `json:"status"` // ok, degraded, error or unknown
+	Error     string `json:"error,omitempty"`
+	CheckedAt string `json:"checked_at,omitempty"`
+}
+
+// ProviderStatus is a provider's health check history for operators
+type ProviderStatus struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Status      string   `json:"status"`
+	Checks      int64    `json:"checks"`
+	Failures    int64    `json:"failures"`
+	SuccessRate *float64 `json:"success_rate"` // over the latest checks; null until checked
+	LatencyMS   int64    `json:"latency_ms"`
+	LastError   string   `json:"last_error,omitempty"`
+	LastErrorAt string   `json:"last_error_at,omitempty"`
+	CheckedAt   string   `json:"checked_at,omitempty"`
+	LastOKAt    string   `json:"last_ok_at,omitempty"`
+	Circuit     string   `json:"circuit,omitempty"`
+}
+
+// HTTPProviderController implements ProviderController for HTTP requests
+type HTTPProviderController struct {
+	providers *providers.ProviderManager
+	monitor   *providers.HealthMonitor
+}
+
+// NewHTTPProviderController creates a new HTTP provider controller reporting the health
+// recorded by monitor
+func NewHTTPProviderController(manager *providers.ProviderManager, monitor *providers.HealthMonitor) ProviderController {
+	return &HTTPProviderController{providers: manager, monitor: monitor}
+}
+
+// List handles GET /providers requests
+func (c *HTTPProviderController) List(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	descriptions := c.providers.Describe()
+	response := make([]*Provider, len(descriptions))
+	for i, description := range descriptions {
+		regions := description.Regions
+		if regions == nil {
+			regions = []string{}
+		}
+		response[i] = &Provider{
+			Name:       description.Name,
+			Type:       description.Type,
+			Regions:    regions,
+			Operations: description.Operations,
+			Priority:   description.Priority,
+			Health:     ProviderHealth{Status: providers.HealthUnknown},
+			Circuit:    circuit(description.Name),
+		}
+		if status, ok := c.monitor.Status(description.Type, description.Name); ok {
+			response[i].Health = ProviderHealth{Status: status.Status, CheckedAt: formatTime(status.CheckedAt)}
+			if status.Status == providers.HealthDown {
+				response[i].Health.Error = status.LastError
+			}
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// Status handles GET /providers/status requests
+func (c *HTTPProviderController) Status(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	statuses := c.monitor.Statuses()
+	response := make([]*ProviderStatus, len(statuses))
+	for i, status := range statuses {
+		response[i] = &ProviderStatus{
+			Name:        status.Name,
+			Type:        status.Type,
+			Status:      status.Status,
+			Checks:      status.Checks,
+			Failures:    status.Failures,
+			LatencyMS:   status.Latency.Milliseconds(),
+			LastError:   status.LastError,
+			LastErrorAt: formatTime(status.LastErrorAt),
+			CheckedAt:   formatTime(status.CheckedAt),
+			LastOKAt:    formatTime(status.LastOKAt),
+			Circuit:     circuit(status.Name),
+		}
+		if status.Checks > 0 {
+			response[i].SuccessRate = &status.SuccessRate
+		}
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// circuit returns the breaker state of the named provider, or "" when it has none
+func circuit(provider string) string {
+	if b, ok := breaker.Lookup(provider); ok {
+		return b.Stats().State
+	}
+	return ""
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// checkedGeocodeProvider is a stubGeocodeProvider with a health check
+type checkedGeocodeProvider struct {
+	stubGeocodeProvider
+	checks int
+}
+
+func (c *checkedGeocodeProvider) HealthCheck(ctx context.Context) error {
+	c.checks++
+	return errors.New("connection refused")
+}
+
+func TestHTTPProviderController(t *testing.T) {
+	geocoder := &checkedGeocodeProvider{stubGeocodeProvider: stubGeocodeProvider{name: "Census"}}
+	pm := providers.NewProviderManager()
+	pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+	pm.RegisterWeatherProvider(&stubHourlyProvider{stubWeatherProvider: stubWeatherProvider{name: "Met.no", regions: []string{providers.GlobalRegion}}})
+	pm.RegisterGeocodeProvider(geocoder)
+	monitor := providers.NewHealthMonitor(pm, time.Minute, time.Second)
+	controller := NewHTTPProviderController(pm, monitor)
+
+	list := func() []Provider {
+		w := httptest.NewRecorder()
+		if err := controller.List(context.Background(), w, httptest.NewRequest("GET", "/providers", nil)); err != nil {
+			t.Fatalf("List failed: %v", err)
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		var response struct {
+			Data []Provider `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return response.Data
+	}
+
+	if listed := list(); listed[2].Health.Status != providers.HealthUnknown {
+		t.Errorf("Expected an unchecked provider before the monitor runs, got %+v", listed[2])
+	}
+
+	monitor.CheckAll(context.Background())
+	listed := list()
+	if len(listed) != 3 {
+		t.Fatalf("Expected 3 providers, got %d", len(listed))
+	}
+	if nws := listed[0]; nws.Type != providers.TypeWeather || nws.Priority != 1 || nws.Health.Status != providers.HealthUnknown || slices.Contains(nws.Operations, providers.OperationHourly) {
+		t.Errorf("Unexpected NWS entry %+v", nws)
+	}
+	if metno := listed[1]; metno.Priority != 2 || !slices.Contains(metno.Operations, providers.OperationHourly) {
+		t.Errorf("Expected Met.no second with hourly forecasts, got %+v", metno)
+	}
+	census := listed[2]
+	if census.Type != providers.TypeGeocode || census.Health.Status != providers.HealthDown || census.Health.Error != "connection refused" || census.Health.CheckedAt == "" {
+		t.Errorf("Unexpected Census entry %+v", census)
+	}
+
+	list()
+	if geocoder.checks != 1 {
+		t.Errorf("Expected listing to reuse the monitor's result, got %d checks", geocoder.checks)
+	}
+
+	w := httptest.NewRecorder()
+	if err := controller.Status(context.Background(), w, httptest.NewRequest("GET", "/providers/status", nil)); err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	var response struct {
+		Data []ProviderStatus `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Data) != 3 || response.Data[0].SuccessRate != nil {
+		t.Fatalf("Expected every provider with no success rate for unchecked ones, got %+v", response.Data)
+	}
+	status := response.Data[2]
+	if status.Checks != 1 || status.Failures != 1 || status.SuccessRate == nil || *status.SuccessRate != 0 || status.LastError != "connection refused" || status.LastErrorAt == "" {
+		t.Errorf("Unexpected Census status %+v", status)
+	}
+}
@@ -0,0 +1,219 @@
+package controllers
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+const (
+	// defaultRiskHours and maxRiskHours bound the forecast window a risk score covers
+	defaultRiskHours = 24
+	maxRiskHours     = 72
+
+	// defaultRiskRadius is the distance in km within which cities and alerts count
+	defaultRiskRadius = 25.0
+
+	// riskForecastLimit and riskAlertLimit bound the forecasts and alerts scored for one
+	// request
+	riskForecastLimit = 500
+	riskAlertLimit    = 50
+
+	// Wind (the stronger of speed and gust, m/s) scores from a strong breeze up to
+	// hurricane force
+	riskWindLow  = 10.8
+	riskWindHigh = 32.7
+
+	// Precipitation (mm in a forecast period) scores from moderate rain up to a
+	// cloudburst
+	riskPrecipitationLow  = 2.5
+	riskPrecipitationHigh = 50.0
+)
+
+// riskSeverityScores are the alert scores of the CAP severities, by SeverityRank
+var riskSeverityScores = []int{10, 20, 45, 75, 100}
+
+// RiskController handles severe weather risk scores
+type RiskController interface {
+	// GetRisk handles requests for the risk score of a location
+	GetRisk(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// RiskScore is a 0-100 "should I worry" number for a location, combining its stored
+// forecasts and the alerts in effect around it
+type RiskScore struct {
+	Latitude    float64        `json:"latitude"`
+	Longitude   float64        `json:"longitude"`
+	Score       int            `json:"score"`
+	Level       string         `json:"level"` // low, moderate, high or extreme
+	Components  RiskComponents `json:"components"`
+	City        *RiskCity      `json:"city,omitempty"` // whose forecasts were scored; omitted when none is near
+	Forecasts   int            `json:"forecasts"`      // forecasts scored within the window
+	Alerts      []*RiskAlert   `json:"alerts"`
+	Hours       int            `json:"hours"`
+	GeneratedAt string         `json:"generated_at"`
+}
+
+// RiskComponents are the 0-100 scores a RiskScore combines
+type RiskComponents struct {
+	Wind          int `json:"wind"`
+	Precipitation int `json:"precipitation"`
+	Alerts        int `json:"alerts"`
+}
+
+// RiskCity is the city whose forecasts a RiskScore covers
+type RiskCity struct {
+	ID         int     `json:"id"`
+	Name       string  `json:"name"`
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// RiskAlert is an alert counted in a RiskScore
+type RiskAlert struct {
+	ID       int    `json:"id"`
+	Title    string `json:"title"`
+	Severity string `json:"severity"`
+	Score    int    `json:"score"`
+	EndTime  string `json:"end_time,omitempty"`
+}
+
+// HTTPRiskController implements RiskController for HTTP requests
+type HTTPRiskController struct {
+	cities    repo.CityRepository
+	forecasts repo.ForecastRepository
+	alerts    repo.AlertRepository
+	now       func() time.Time
+}
+
+// NewHTTPRiskController creates a new HTTP risk controller
+func NewHTTPRiskController(cities repo.CityRepository, forecasts repo.ForecastRepository, alerts repo.AlertRepository) RiskController {
+	return &HTTPRiskController{cities: cities, forecasts: forecasts, alerts: alerts, now: time.Now}
+}
+
+// GetRisk handles GET /risk?lat=&lon=&hours=&radius= requests.
+//
+// The forecasts scored are those of the nearest stored city within radius km (25)
+// valid over the next hours (24, at most 72); the alerts are those in effect within
+// radius. Each of wind, precipitation and alerts scores 0-100 from its worst value, and
+// the score is the highest of them plus a quarter of the other two, capped at 100: one
+// hazard alone scores as it would by itself, and several at once score higher.
+func (c *HTTPRiskController) GetRisk(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	hours := defaultRiskHours
+	if value := query.Get("hours"); value != "" {
+		if hours, err = strconv.Atoi(value); err != nil || hours <= 0 {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "hours must be a positive integer")
+		}
+	}
+	hours = min(hours, maxRiskHours)
+	radius, err := queryRadius(r, defaultRiskRadius)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Float64("lat", lat), attribute.Float64("lon", lon))
+
+	now := c.now().UTC()
+	risk := &RiskScore{
+		Latitude:    lat,
+		Longitude:   lon,
+		Alerts:      []*RiskAlert{},
+		Hours:       hours,
+		GeneratedAt: now.Format(time.RFC3339),
+	}
+
+	cities, err := c.cities.GetByCoordinates(ctx, lat, lon, radius, 1)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to find cities", err.Error())
+	}
+	if len(cities) > 0 {
+		city := cities[0]
+		risk.City = &RiskCity{ID: city.ID, Name: city.Name, DistanceKm: geo.Round(geo.DistanceKm(lat, lon, city.Latitude, city.Longitude), 1)}
+
+		forecasts, err := c.windowForecasts(ctx, city.ID, now, time.Duration(hours)*time.Hour)
+		if err != nil {
+			return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+		}
+		risk.Forecasts = len(forecasts)
+		for _, f := range forecasts {
+			risk.Components.Wind = max(risk.Components.Wind, scaleRisk(max(f.WindSpeed, f.WindGust), riskWindLow, riskWindHigh))
+			risk.Components.Precipitation = max(risk.Components.Precipitation, scaleRisk(f.Precipitation, riskPrecipitationLow, riskPrecipitationHigh))
+		}
+	}
+
+	alerts, err := c.alerts.GetActiveByCoordinates(ctx, lat, lon, radius, riskAlertLimit)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to find alerts", err.Error())
+	}
+	for _, alert := range alerts {
+		score := riskSeverityScores[max(models.SeverityRank(alert.Severity), 0)]
+		risk.Components.Alerts = max(risk.Components.Alerts, score)
+		risk.Alerts = append(risk.Alerts, &RiskAlert{ID: alert.ID, Title: alert.Title, Severity: alert.Severity, Score: score, EndTime: alert.EndTime})
+	}
+
+	risk.Score = combineRisk(risk.Components.Wind, risk.Components.Precipitation, risk.Components.Alerts)
+	risk.Level = riskLevel(risk.Score)
+	return writeJSON(w, http.StatusOK, risk)
+}
+
+// windowForecasts returns a city's stored forecasts valid between now and now+window
+func (c *HTTPRiskController) windowForecasts(ctx context.Context, cityID int, now time.Time, window time.Duration) ([]*repo.Forecast, error) {
+	cursor := &repo.ForecastCursor{ValidTime: now.Add(window).Format(time.RFC3339)}
+	newest, err := c.forecasts.GetByCityIDAfter(ctx, cityID, cursor, riskForecastLimit)
+	if err != nil {
+		return nil, err
+	}
+	var forecasts []*repo.Forecast
+	start := now.Truncate(time.Hour)
+	for _, f := range newest {
+		if parseRepoTime(f.ValidTime).Before(start) {
+			break
+		}
+		forecasts = append(forecasts, f)
+	}
+	return forecasts, nil
+}
+
+// scaleRisk scores value linearly from 0 at low to 100 at high
+func scaleRisk(value, low, high float64) int {
+	if value <= low {
+		return 0
+	}
+	return int(math.Round(math.Min((value-low)/(high-low), 1) * 100))
+}
+
+// combineRisk returns the highest component plus a quarter of the others, capped at 100
+func combineRisk(components ...int) int {
+	highest, sum := 0, 0
+	for _, c := range components {
+		highest = max(highest, c)
+		sum += c
+	}
+	return min(100, highest+int(math.Round(float64(sum-highest)/4)))
+}
+
+// riskLevel names the band of a risk score
+func riskLevel(score int) string {
+	switch {
+	case score >= 75:
+		return "extreme"
+	case score >= 50:
+		return "high"
+	case score >= 25:
+		return "moderate"
+	default:
+		return "low"
+	}
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPRiskController_GetRisk(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+
+	city := &repo.City{Name: "Tulsa", CountryCode: "US", Latitude: 36.15, Longitude: -95.99, IsActive: true}
+	if err := engine.Cities().Create(ctx, city); err != nil {
+		t.Fatal(err)
+	}
+	store := func(offset time.Duration, wind, gust, precipitation float64) {
+		valid := now.Add(offset).Format(time.RFC3339)
+		f := &repo.Forecast{CityID: city.ID, SourceProvider: "Stub", ForecastTime: now.Format(time.RFC3339), ValidTime: valid,
+			WindSpeed: wind, WindGust: gust, Precipitation: precipitation}
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store(2*time.Hour, 12, 21.75, 5) // wind 50, precipitation 5
+	store(6*time.Hour, 5, 0, 26.25)  // precipitation 50
+	store(48*time.Hour, 40, 40, 80)  // beyond the default window
+	store(-5*time.Hour, 40, 40, 80)  // past
+
+	controller := NewHTTPRiskController(engine.Cities(), engine.Forecasts(), engine.Alerts())
+	get := func(t *testing.T, query string) (*httptest.ResponseRecorder, *RiskScore) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/risk"+query, nil)
+		if err := controller.GetRisk(ctx, w, r); err != nil {
+			t.Fatalf("GetRisk failed: %v", err)
+		}
+		var response RiskScore
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return w, &response
+	}
+
+	t.Run("scores forecasts in the window", func(t *testing.T) {
+		w, risk := get(t, "?lat=36.16&lon=-95.99")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if risk.City == nil || risk.City.ID != city.ID || risk.Forecasts != 2 {
+			t.Fatalf("Expected Tulsa's 2 forecasts in the window, got %+v", risk)
+		}
+		if risk.Components != (RiskComponents{Wind: 50, Precipitation: 50}) {
+			t.Errorf("Expected wind and precipitation of 50, got %+v", risk.Components)
+		}
+		if risk.Score != 63 || risk.Level != "high" {
+			t.Errorf("Expected a high score of 63, got %d (%s)", risk.Score, risk.Level)
+		}
+	})
+
+	t.Run("widens the window with hours", func(t *testing.T) {
+		_, risk := get(t, "?lat=36.16&lon=-95.99&hours=72")
+		if risk.Forecasts != 3 || risk.Score != 100 || risk.Level != "extreme" {
+			t.Errorf("Expected the 48h forecast to max the score, got %+v", risk)
+		}
+	})
+
+	t.Run("weights alerts by severity", func(t *testing.T) {
+		end := now.Add(time.Hour).Format(time.RFC3339)
+		for _, alert := range []*repo.Alert{
+			{SourceProvider: "Stub", ProviderAlertID: "1", Latitude: 36.15, Longitude: -95.99, Severity: "Severe", Title: "Severe Thunderstorm Warning", EndTime: end},
+			{SourceProvider: "Stub", ProviderAlertID: "2", Latitude: 36.15, Longitude: -95.99, Severity: "Minor", EndTime: end},
+			{SourceProvider: "Stub", ProviderAlertID: "3", Latitude: 40, Longitude: -90, Severity: "Extreme", EndTime: end},
+		} {
+			if err := engine.Alerts().Upsert(ctx, alert); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_, risk := get(t, "?lat=36.16&lon=-95.99")
+		if len(risk.Alerts) != 2 || risk.Components.Alerts != 75 {
+			t.Fatalf("Expected the 2 nearby alerts scored 75, got %+v", risk)
+		}
+		if risk.Score != 100 {
+			t.Errorf("Expected the alert and the forecasts to max the score, got %d", risk.Score)
+		}
+	})
+
+	t.Run("scores zero away from stored cities", func(t *testing.T) {
+		_, risk := get(t, "?lat=0&lon=0")
+		if risk.City != nil || risk.Score != 0 || risk.Level != "low" || risk.Alerts == nil {
+			t.Errorf("Expected a low score without a city, got %+v", risk)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, query := range []string{"?lat=100&lon=0", "?lat=0&lon=0&hours=0", "?lat=0&lon=0&radius=-1"} {
+			if w, _ := get(t, query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", query, w.Code)
+			}
+		}
+	})
+}
+
+func TestCombineRisk(t *testing.T) {
+	tests := []struct {
+		components []int
+		want       int
+	}{
+		{[]int{0, 0, 0}, 0},
+		{[]int{80, 0, 0}, 80},
+		{[]int{40, 40, 0}, 50},
+		{[]int{100, 100, 100}, 100},
+	}
+	for _, tt := range tests {
+		if got := combineRisk(tt.components...); got != tt.want {
+			t.Errorf("combineRisk(%v) = %d, want %d", tt.components, got, tt.want)
+		}
+	}
+}
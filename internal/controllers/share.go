@@ -0,0 +1,308 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/share"
+)
+
+// Shared resource types
+const (
+	ShareForecast = "forecast"
+	ShareExport   = "export"
+)
+
+const (
+	// defaultShareTTL is how long a share link stays valid when the request omits a TTL
+	defaultShareTTL = 24 * time.Hour
+
+	// maxShareTTL bounds how long a share link may stay valid
+	maxShareTTL = 30 * 24 * time.Hour
+)
+
+// ShareController handles public share links: signed, time-limited URLs under
+// /share/{token} that let unauthenticated viewers see one city's forecast or download
+// one export. Links are owned by the user who created them, who alone (with admins)
+// can list and revoke them.
+type ShareController interface {
+	// Create handles requests to create a share link
+	Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// ListByUser handles requests to list a user's share links
+	ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+
+	// Revoke handles requests to revoke a share link
+	Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// RevokeByUser handles requests to revoke every share link of a user
+	RevokeByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+
+	// View handles unauthenticated requests for a shared resource
+	View(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) error
+}
+
+// ShareRequest is the body of a request to create a share link
+type ShareRequest struct {
+	Resource   string `json:"resource"`              // forecast or export
+	CityID     int    `json:"city_id,omitempty"`     // city to share, for forecast links
+	ExportID   string `json:"export_id,omitempty"`   // export job to share, for export links
+	TTLSeconds int    `json:"ttl_seconds,omitempty"` // defaults to a day, at most 30 days
+}
+
+// validate checks the request fields
+func (s *ShareRequest) validate() models.ValidationErrors {
+	var errs models.ValidationErrors
+	switch s.Resource {
+	case ShareForecast:
+		if s.CityID <= 0 {
+			errs = append(errs, models.FieldError{Field: "city_id", Message: "city_id is required for forecast links"})
+		}
+	case ShareExport:
+		if !validExportID(s.ExportID) {
+			errs = append(errs, models.FieldError{Field: "export_id", Message: "export_id must be an export job ID"})
+		}
+	default:
+		errs = append(errs, models.FieldError{Field: "resource", Message: "resource must be forecast or export"})
+	}
+	if s.TTLSeconds < 0 || time.Duration(s.TTLSeconds)*time.Second > maxShareTTL {
+		errs = append(errs, models.FieldError{Field: "ttl_seconds", Message: fmt.Sprintf("ttl_seconds must be between 1 and %d", int(maxShareTTL.Seconds()))})
+	}
+	return errs
+}
+
+// ShareLink is a share link as returned to its owner
+type ShareLink struct {
+	ID        int    `json:"id"`
+	UserID    int    `json:"user_id"`
+	Resource  string `json:"resource"`
+	CityID    int    `json:"city_id,omitempty"`
+	ExportID  string `json:"export_id,omitempty"`
+	URL       string `json:"url"` // public path, /share/{token}
+	ExpiresAt string `json:"expires_at"`
+	RevokedAt string `json:"revoked_at,omitempty"`
+	CreatedAt string `json:"created_at"`
+}
+
+// SharedForecast is the body served to viewers of a forecast share link
+type SharedForecast struct {
+	City      *City       `json:"city"`
+	Forecasts []*Forecast `json:"forecasts"`
+	ExpiresAt string      `json:"expires_at"`
+}
+
+// HTTPShareController implements ShareController for HTTP requests
+type HTTPShareController struct {
+	links     repo.ShareLinkRepository
+	cities    repo.CityRepository
+	forecasts repo.ForecastRepository
+	exports   ExportController
+	signer    *share.Signer
+	now       func() time.Time
+}
+
+// NewHTTPShareController creates a new HTTP share controller. Export links are served
+// through exports, so shared downloads keep chunk and Range support; they answer 404
+// when exports is nil.
+func NewHTTPShareController(links repo.ShareLinkRepository, cities repo.CityRepository, forecasts repo.ForecastRepository,
+	exports ExportController, signer *share.Signer) ShareController {
+	return &HTTPShareController{
+		links:     links,
+		cities:    cities,
+		forecasts: forecasts,
+		exports:   exports,
+		signer:    signer,
+		now:       time.Now,
+	}
+}
+
+// Create handles POST /shares requests. The link belongs to the caller.
+func (c *HTTPShareController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := requireOwner(ctx, w, "Share links")
+	if userID == 0 {
+		return nil
+	}
+	var req ShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if errs := req.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	link := &repo.ShareLink{UserID: userID, ResourceType: req.Resource}
+	switch req.Resource {
+	case ShareForecast:
+		if _, err := c.cities.GetByID(ctx, req.CityID); err != nil {
+			return writeRepoError(w, err, "City", "Failed to retrieve city")
+		}
+		link.ResourceID = strconv.Itoa(req.CityID)
+	case ShareExport:
+		link.ResourceID = req.ExportID
+	}
+
+	ttl := defaultShareTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	link.ExpiresAt = c.now().Add(ttl).UTC().Format(time.RFC3339)
+
+	if err := c.links.Create(ctx, link); err != nil {
+		return writeRepoError(w, err, "Share link", "Failed to create share link")
+	}
+
+	response := c.fromRepoShareLink(link)
+	w.Header().Set("Location", response.URL)
+	return writeCommitted(w, http.StatusCreated, response, "Share link created successfully")
+}
+
+// ListByUser handles GET /users/{id}/shares requests
+func (c *HTTPShareController) ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	links, err := c.links.ListByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve share links", err.Error())
+	}
+
+	response := make([]*ShareLink, 0, len(links))
+	for _, link := range links {
+		response = append(response, c.fromRepoShareLink(link))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// Revoke handles DELETE /shares/{id} requests
+func (c *HTTPShareController) Revoke(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	link, err := c.links.GetByID(ctx, id)
+	if err != nil {
+		return writeRepoError(w, err, "Share link", "Failed to retrieve share link")
+	}
+	if !canAccess(ctx, link.UserID) {
+		return writeNotOwned(w, "Share link")
+	}
+	if err := c.links.Revoke(ctx, id); err != nil {
+		return writeRepoError(w, err, "Share link", "Failed to revoke share link")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Share link revoked successfully")
+}
+
+// RevokeByUser handles DELETE /users/{id}/shares requests
+func (c *HTTPShareController) RevokeByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	revoked, err := c.links.RevokeByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to revoke share links", err.Error())
+	}
+
+	return writeCommitted(w, http.StatusOK, map[string]int64{"revoked": revoked}, fmt.Sprintf("Revoked %d share links", revoked))
+}
+
+// View handles GET /share/{token} requests. The token's signature is checked before
+// the link is looked up, and expired or revoked links answer 410 Gone. Forecast links
+// honour the usual pagination and unit parameters; export links the download
+// parameters (?chunk=, Range).
+func (c *HTTPShareController) View(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) error {
+	// Shared pages are public but revocable: keep them out of caches, search engines
+	// and the Referer header of outgoing links
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Robots-Tag", "noindex")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	now := c.now()
+	id, err := c.signer.Verify(token, now)
+	if errors.Is(err, share.ErrExpired) {
+		return writeError(w, http.StatusGone, "Share link expired", err.Error())
+	}
+	if err != nil {
+		return writeError(w, http.StatusNotFound, "Share link not found", err.Error())
+	}
+
+	link, err := c.links.GetByID(ctx, id)
+	if err != nil {
+		return writeRepoError(w, err, "Share link", "Failed to retrieve share link")
+	}
+	if link.RevokedAt != "" {
+		return writeError(w, http.StatusGone, "Share link revoked", "the owner has revoked this link")
+	}
+	expires, err := time.Parse(time.RFC3339, link.ExpiresAt)
+	if err != nil || !now.Before(expires) {
+		return writeError(w, http.StatusGone, "Share link expired", share.ErrExpired.Error())
+	}
+
+	switch link.ResourceType {
+	case ShareExport:
+		if c.exports == nil {
+			return writeError(w, http.StatusNotFound, "Share link not found", "exports are disabled")
+		}
+		return c.exports.Download(ctx, w, r, link.ResourceID)
+	case ShareForecast:
+		return c.viewForecast(ctx, w, r, link)
+	default:
+		return writeError(w, http.StatusNotFound, "Share link not found", fmt.Sprintf("unknown resource type %q", link.ResourceType))
+	}
+}
+
+// viewForecast serves the shared city and a page of its forecasts
+func (c *HTTPShareController) viewForecast(ctx context.Context, w http.ResponseWriter, r *http.Request, link *repo.ShareLink) error {
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+	cityID, err := strconv.Atoi(link.ResourceID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Invalid share link", err.Error())
+	}
+
+	city, err := c.cities.GetByID(ctx, cityID)
+	if err != nil {
+		return writeRepoError(w, err, "City", "Failed to retrieve city")
+	}
+
+	page, limit := getPagination(r, ResourceForecasts)
+	forecasts, err := c.forecasts.GetByCityID(ctx, cityID, limit, (page-1)*limit)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecasts", err.Error())
+	}
+
+	response := &SharedForecast{City: fromRepoCity(city), Forecasts: []*Forecast{}, ExpiresAt: link.ExpiresAt}
+	for _, f := range forecasts {
+		response.Forecasts = append(response.Forecasts, fromRepoForecast(f))
+	}
+	convertForecasts(opts, response.Forecasts...)
+	return writeSuccess(w, http.StatusOK, response, "")
+}
+
+// fromRepoShareLink converts a stored link, signing its public URL. Signatures are
+// deterministic, so listing links reproduces the URLs handed out at creation.
+func (c *HTTPShareController) fromRepoShareLink(link *repo.ShareLink) *ShareLink {
+	response := &ShareLink{
+		ID:        link.ID,
+		UserID:    link.UserID,
+		Resource:  link.ResourceType,
+		ExpiresAt: link.ExpiresAt,
+		RevokedAt: link.RevokedAt,
+		CreatedAt: link.CreatedAt,
+	}
+	switch link.ResourceType {
+	case ShareForecast:
+		response.CityID, _ = strconv.Atoi(link.ResourceID)
+	case ShareExport:
+		response.ExportID = link.ResourceID
+	}
+	if expires, err := time.Parse(time.RFC3339, link.ExpiresAt); err == nil {
+		response.URL = "/share/" + c.signer.Sign(link.ID, expires)
+	}
+	return response
+}
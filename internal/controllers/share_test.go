@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/blob"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/share"
+)
+
+func newShareController(t *testing.T) (*HTTPShareController, *HTTPExportController, int) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	city := toRepoCity(exampleCity())
+	if err := engine.Cities().Create(ctx, city); err != nil {
+		t.Fatal(err)
+	}
+	for _, valid := range []string{"2024-01-15T12:00:00Z", "2024-01-15T13:00:00Z"} {
+		f := toRepoForecast(exampleForecast())
+		f.CityID, f.ValidTime = city.ID, valid
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	store, err := blob.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := share.NewSigner(strings.Repeat("s", share.MinSecretLength))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exports := NewHTTPExportController(engine.Forecasts(), store).(*HTTPExportController)
+	controller := NewHTTPShareController(engine.Shares(), engine.Cities(), engine.Forecasts(), exports, signer).(*HTTPShareController)
+	return controller, exports, city.ID
+}
+
+func createShare(t *testing.T, ctx context.Context, controller ShareController, body string) (*httptest.ResponseRecorder, *ShareLink) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := controller.Create(ctx, w, httptest.NewRequest("POST", "/shares", strings.NewReader(body))); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data *ShareLink `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(w.Body.String())).Decode(&response)
+	return w, response.Data
+}
+
+func viewShare(t *testing.T, controller ShareController, url string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	path, _, _ := strings.Cut(url, "?")
+	token := strings.TrimPrefix(path, "/share/")
+	if err := controller.View(context.Background(), w, httptest.NewRequest("GET", url, nil), token); err != nil {
+		t.Fatal(err)
+	}
+	return w
+}
+
+func TestShareController_Forecast(t *testing.T) {
+	controller, _, cityID := newShareController(t)
+
+	w, link := createShare(t, asUser(1), controller, `{"user_id":2,"resource":"forecast","city_id":`+strconv.Itoa(cityID)+`,"ttl_seconds":3600}`)
+	if w.Code != http.StatusCreated || link == nil || link.CityID != cityID || link.UserID != 1 || w.Header().Get("Location") != link.URL {
+		t.Fatalf("Expected a created link, got %d: %s", w.Code, w.Body.String())
+	}
+	if w.Header().Get(repo.ConsistencyHeader) == "" {
+		t.Error("Expected a consistency token on creation")
+	}
+
+	w = viewShare(t, controller, link.URL+"?units=imperial")
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Expected the shared forecast, got %d: %s", w.Code, w.Body.String())
+	}
+	var response struct {
+		Data SharedForecast `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if response.Data.City.ID != cityID || len(response.Data.Forecasts) != 2 || response.Data.Forecasts[0].Units != "imperial" {
+		t.Errorf("Unexpected shared forecast %+v", response.Data)
+	}
+
+	t.Run("expired", func(t *testing.T) {
+		controller.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		defer func() { controller.now = time.Now }()
+		if w := viewShare(t, controller, link.URL); w.Code != http.StatusGone {
+			t.Errorf("Expected status %d, got %d", http.StatusGone, w.Code)
+		}
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		if w := viewShare(t, controller, link.URL+"x"); w.Code != http.StatusNotFound {
+			t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+		}
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		_ = controller.Revoke(asUser(1), w, httptest.NewRequest("DELETE", "/shares/1", nil), link.ID)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+		if w := viewShare(t, controller, link.URL); w.Code != http.StatusGone {
+			t.Errorf("Expected status %d, got %d", http.StatusGone, w.Code)
+		}
+	})
+}
+
+func TestShareController_Export(t *testing.T) {
+	controller, exports, _ := newShareController(t)
+	manifest := runExport(t, exports, `{"format":"csv"}`)
+
+	_, link := createShare(t, asUser(1), controller, `{"resource":"export","export_id":"`+manifest.ID+`"}`)
+	if link == nil || link.ExportID != manifest.ID {
+		t.Fatal("Expected an export link")
+	}
+
+	req := httptest.NewRequest("GET", link.URL, nil)
+	req.Header.Set("Range", "bytes=0-1")
+	w := httptest.NewRecorder()
+	_ = controller.View(context.Background(), w, req, strings.TrimPrefix(link.URL, "/share/"))
+	if w.Code != http.StatusPartialContent || w.Body.String() != "id" {
+		t.Errorf("Expected a ranged export download, got %d: %q", w.Code, w.Body.String())
+	}
+}
+
+func TestShareController_RevokeByUser(t *testing.T) {
+	controller, _, cityID := newShareController(t)
+	body := `{"resource":"forecast","city_id":` + strconv.Itoa(cityID) + `}`
+	_, first := createShare(t, asUser(1), controller, body)
+	_, second := createShare(t, asUser(1), controller, body)
+	_, other := createShare(t, asUser(2), controller, body)
+
+	w := httptest.NewRecorder()
+	_ = controller.RevokeByUser(asUser(1), w, httptest.NewRequest("DELETE", "/users/1/shares", nil), 1)
+	if !strings.Contains(w.Body.String(), `"revoked":2`) {
+		t.Fatalf("Expected 2 links revoked, got %s", w.Body.String())
+	}
+	for _, link := range []*ShareLink{first, second} {
+		if w := viewShare(t, controller, link.URL); w.Code != http.StatusGone {
+			t.Errorf("Expected link %d to be revoked, got %d", link.ID, w.Code)
+		}
+	}
+	if w := viewShare(t, controller, other.URL); w.Code != http.StatusOK {
+		t.Errorf("Expected another user's link to stay active, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	_ = controller.ListByUser(asUser(1), w, httptest.NewRequest("GET", "/users/1/shares", nil), 1)
+	var links []*ShareLink
+	if err := json.NewDecoder(w.Body).Decode(&links); err != nil {
+		t.Fatal(err)
+	}
+	if len(links) != 2 || links[0].ID != second.ID || links[0].RevokedAt == "" || links[0].URL != second.URL {
+		t.Errorf("Expected both revoked links newest first with stable URLs, got %+v", links)
+	}
+}
+
+func TestShareController_Validation(t *testing.T) {
+	controller, _, _ := newShareController(t)
+	tests := map[string]int{
+		`{"resource":"city"}`:                                        http.StatusUnprocessableEntity,
+		`{"resource":"export","export_id":"../x"}`:                   http.StatusUnprocessableEntity,
+		`{"resource":"forecast","city_id":999}`:                      http.StatusNotFound,
+		`{"resource":"forecast","city_id":1,"ttl_seconds":99999999}`: http.StatusUnprocessableEntity,
+		`not json`: http.StatusBadRequest,
+	}
+	for body, status := range tests {
+		if w, _ := createShare(t, asUser(1), controller, body); w.Code != status {
+			t.Errorf("Expected status %d for %s, got %d", status, body, w.Code)
+		}
+	}
+}
+
+func TestShareController_OtherUsers(t *testing.T) {
+	controller, _, cityID := newShareController(t)
+	_, link := createShare(t, asUser(1), controller, `{"resource":"forecast","city_id":`+strconv.Itoa(cityID)+`}`)
+	if link == nil {
+		t.Fatal("Expected a link")
+	}
+
+	expectHidden(t, "ListByUser", func(ctx context.Context, w http.ResponseWriter) error {
+		return controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/shares", nil), 1)
+	})
+	expectHidden(t, "Revoke", func(ctx context.Context, w http.ResponseWriter) error {
+		return controller.Revoke(ctx, w, httptest.NewRequest("DELETE", "/shares/1", nil), link.ID)
+	})
+	expectHidden(t, "RevokeByUser", func(ctx context.Context, w http.ResponseWriter) error {
+		return controller.RevokeByUser(ctx, w, httptest.NewRequest("DELETE", "/users/1/shares", nil), 1)
+	})
+	if w := viewShare(t, controller, link.URL); w.Code != http.StatusOK {
+		t.Fatalf("Expected the link to survive other users, got %d", w.Code)
+	}
+
+	for who, ctx := range map[string]context.Context{"the admin token": asAdmin(), "anonymous": context.Background()} {
+		if w, _ := createShare(t, ctx, controller, `{"resource":"forecast","city_id":`+strconv.Itoa(cityID)+`}`); w.Code != http.StatusForbidden {
+			t.Errorf("Expected %s to own no links, got %d", who, w.Code)
+		}
+	}
+	w := httptest.NewRecorder()
+	_ = controller.Revoke(asAdmin(), w, httptest.NewRequest("DELETE", "/shares/1", nil), link.ID)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected an admin to revoke any link, got %d", w.Code)
+	}
+}
@@ -0,0 +1,148 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+const (
+	defaultStatsPeriodDays = 30
+	maxStatsPeriodDays     = 3650
+)
+
+// StatsResponse is the distribution of one metric over a city's stored forecasts
+type StatsResponse struct {
+	CityID   int           `json:"city_id"`
+	Metric   string        `json:"metric"`
+	Units    string        `json:"units,omitempty"`
+	Start    string        `json:"start"`
+	End      string        `json:"end"`
+	Interval string        `json:"interval,omitempty"`
+	Windows  []MetricStats `json:"windows"`
+}
+
+// MetricStats summarizes the metric over one window. Percentiles are keyed p10, p25,
+// p50, p75 and p90.
+type MetricStats struct {
+	Start       string             `json:"start"`
+	Count       int                `json:"count"`
+	Min         float64            `json:"min"`
+	Max         float64            `json:"max"`
+	Mean        float64            `json:"mean"`
+	Percentiles map[string]float64 `json:"percentiles"`
+}
+
+// GetStats handles GET /cities/{id}/stats?metric=temperature&period=30d&interval=
+// requests. period is a number of days back from now (30d by default); interval
+// splits it into day, week or month windows, and without it the whole period is one
+// window.
+func (c *HTTPForecastController) GetStats(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	query := r.URL.Query()
+	metric := query.Get("metric")
+	if metric == "" {
+		metric = "temperature"
+	}
+	if _, ok := repo.StatsMetrics[metric]; !ok {
+		metrics := strings.Join(slices.Sorted(maps.Keys(repo.StatsMetrics)), ", ")
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "metric must be one of "+metrics)
+	}
+
+	days := defaultStatsPeriodDays
+	if period := query.Get("period"); period != "" {
+		days, err = strconv.Atoi(strings.TrimSuffix(period, "d"))
+		if err != nil || !strings.HasSuffix(period, "d") || days <= 0 || days > maxStatsPeriodDays {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", fmt.Sprintf("period must be a number of days such as 30d, at most %dd", maxStatsPeriodDays))
+		}
+	}
+
+	interval := query.Get("interval")
+	switch interval {
+	case repo.BucketNone, repo.BucketDay, repo.BucketWeek, repo.BucketMonth:
+	default:
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", "interval must be day, week or month")
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	start := end.AddDate(0, 0, -days)
+	windows, err := c.repo.Stats(ctx, cityID, metric, start.Format(time.RFC3339), end.Format(time.RFC3339), interval)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve forecast stats", err.Error())
+	}
+
+	convert, label := metricConversion(metric, opts)
+	response := &StatsResponse{
+		CityID:   cityID,
+		Metric:   metric,
+		Units:    label,
+		Start:    start.Format(time.RFC3339),
+		End:      end.Format(time.RFC3339),
+		Interval: interval,
+		Windows:  make([]MetricStats, 0, len(windows)),
+	}
+	for _, window := range windows {
+		stats := MetricStats{
+			Start:       window.Start,
+			Count:       window.Count,
+			Min:         convert(window.Min),
+			Max:         convert(window.Max),
+			Mean:        convert(window.Mean),
+			Percentiles: make(map[string]float64, len(window.Percentiles)),
+		}
+		for i, value := range window.Percentiles {
+			stats.Percentiles["p"+strconv.Itoa(int(math.Round(repo.StatsPercentiles[i]*100)))] = convert(value)
+		}
+		response.Windows = append(response.Windows, stats)
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// metricConversion returns how to convert a metric from its stored metric unit to the
+// requested units, rounded, and the resulting unit label
+func metricConversion(metric string, opts unitOptions) (func(float64) float64, string) {
+	labels := opts.System.Labels()
+	imperial := opts.System == units.Imperial
+	rounded := func(convert func(float64) float64, places int) func(float64) float64 {
+		return func(v float64) float64 {
+			if imperial && convert != nil {
+				v = convert(v)
+			}
+			return units.Round(v, places)
+		}
+	}
+	switch metric {
+	case "temperature", "feels_like":
+		return rounded(units.CelsiusToFahrenheit, 1), labels.Temperature
+	case "pressure":
+		return rounded(units.HectopascalsToInchesOfMercury, 2), labels.Pressure
+	case "precipitation":
+		return rounded(units.MillimetersToInches, 2), labels.Precipitation
+	case "visibility":
+		return rounded(units.KilometersToMiles, 1), labels.Visibility
+	case "wind_speed":
+		return func(v float64) float64 { return units.ConvertWindSpeed(v, opts.Wind) }, opts.Wind.Label()
+	case "humidity", "cloud_cover":
+		return rounded(nil, 1), "%"
+	default:
+		return rounded(nil, 1), ""
+	}
+}
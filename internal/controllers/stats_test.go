@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPForecastController_GetStats(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{
+		{CityID: 7, Temperature: 10},
+		{CityID: 7, Temperature: 30},
+		{CityID: 8, Temperature: -5},
+	}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	get := func(t *testing.T, target string) (*httptest.ResponseRecorder, StatsResponse) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		if err := controller.GetStats(context.Background(), w, httptest.NewRequest("GET", target, nil), 7); err != nil {
+			t.Fatalf("GetStats failed: %v", err)
+		}
+		var response StatsResponse
+		_ = json.NewDecoder(w.Body).Decode(&response)
+		return w, response
+	}
+
+	t.Run("summarizes the metric", func(t *testing.T) {
+		w, response := get(t, "/cities/7/stats?metric=temperature&period=30d")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		if response.Metric != "temperature" || response.Units != "°C" || len(response.Windows) != 1 {
+			t.Fatalf("Unexpected response %+v", response)
+		}
+		window := response.Windows[0]
+		if window.Count != 2 || window.Min != 10 || window.Max != 30 || window.Mean != 20 || window.Percentiles["p50"] != 20 {
+			t.Errorf("Unexpected window %+v", window)
+		}
+		if len(window.Percentiles) != 5 {
+			t.Errorf("Expected 5 percentiles, got %v", window.Percentiles)
+		}
+	})
+
+	t.Run("converts units", func(t *testing.T) {
+		_, response := get(t, "/cities/7/stats?units=imperial")
+		if response.Units != "°F" || response.Windows[0].Min != 50 || response.Windows[0].Max != 86 {
+			t.Errorf("Expected Fahrenheit stats, got %+v", response)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/cities/7/stats?metric=dewpoint",
+			"/cities/7/stats?period=30",
+			"/cities/7/stats?period=0d",
+			"/cities/7/stats?interval=year",
+		} {
+			if w, _ := get(t, target); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status 400 for %s, got %d", target, w.Code)
+			}
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		errorController := NewHTTPForecastController(&MockForecastRepository{shouldError: true, errorMsg: "database error"})
+		if err := errorController.GetStats(context.Background(), w, httptest.NewRequest("GET", "/cities/7/stats", nil), 7); err != nil {
+			t.Fatalf("GetStats failed: %v", err)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+)
+
+const (
+	// alertReplaySize is how many recent events are kept for Last-Event-ID replay
+	alertReplaySize = 256
+
+	// alertSubscriberBuffer is the per-client queue length; slow clients drop events
+	// rather than block ingestion
+	alertSubscriberBuffer = 32
+
+	// heartbeatInterval keeps idle connections open through proxies
+	heartbeatInterval = 15 * time.Second
+)
+
+// AlertEvent is a published alert tagged with its stream sequence number
+type AlertEvent struct {
+	ID    uint64
+	Alert *Alert
+}
+
+// AlertBroker fans newly ingested alerts out to connected stream clients.
+//
+// Every published alert gets a monotonically increasing event ID. A bounded history
+// of recent events lets reconnecting clients resume from their Last-Event-ID without
+// missing alerts published while they were disconnected.
+type AlertBroker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []AlertEvent
+	subscribers map[chan AlertEvent]struct{}
+}
+
+// NewAlertBroker creates an empty alert broker
+func NewAlertBroker() *AlertBroker {
+	return &AlertBroker{subscribers: make(map[chan AlertEvent]struct{})}
+}
+
+// Publish assigns the alert an event ID and delivers it to all subscribers
+func (b *AlertBroker) Publish(alert *Alert) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	event := AlertEvent{ID: b.nextID, Alert: alert}
+
+	b.history = append(b.history, event)
+	if len(b.history) > alertReplaySize {
+		b.history = b.history[len(b.history)-alertReplaySize:]
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribe registers a new subscriber and returns its channel together with every
+// buffered event newer than lastEventID. The returned cancel func must be called to
+// release the subscription.
+func (b *AlertBroker) Subscribe(lastEventID uint64) (<-chan AlertEvent, []AlertEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan AlertEvent, alertSubscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	var replay []AlertEvent
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers, ch)
+	}
+	return ch, replay, cancel
+}
+
+// alertScope filters stream events to a city or an area around a coordinate
+type alertScope struct {
+	cityID   int
+	lat, lon float64
+	radiusKm float64
+	hasPoint bool
+}
+
+func (s alertScope) matches(alert *Alert) bool {
+	if s.cityID > 0 {
+		return alert.CityID == s.cityID
+	}
+	if s.hasPoint {
+		return geo.DistanceKm(s.lat, s.lon, alert.Latitude, alert.Longitude) <= s.radiusKm
+	}
+	return true
+}
+
+func parseAlertScope(r *http.Request) (alertScope, error) {
+	query := r.URL.Query()
+	var scope alertScope
+
+	if cityIDStr := query.Get("city_id"); cityIDStr != "" {
+		cityID, err := strconv.Atoi(cityIDStr)
+		if err != nil || cityID <= 0 {
+			return scope, fmt.Errorf("city_id must be a positive integer")
+		}
+		scope.cityID = cityID
+		return scope, nil
+	}
+
+	latStr, lonStr := query.Get("lat"), query.Get("lon")
+	if latStr == "" && lonStr == "" {
+		return scope, fmt.Errorf("either city_id or lat and lon are required")
+	}
+
+	lat, lon, err := geo.ParseCoordinates(latStr, lonStr)
+	if err != nil {
+		return scope, err
+	}
+
+	radius, err := strconv.ParseFloat(query.Get("radius"), 64)
+	if err != nil || radius <= 0 {
+		radius = 25.0 // Same default as GetActiveByCoordinates
+	}
+
+	scope.lat, scope.lon, scope.radiusKm, scope.hasPoint = lat, lon, radius, true
+	return scope, nil
+}
+
+// Stream handles GET /alerts/stream?city_id= or ?lat=&lon=&radius= requests.
+//
+// Newly ingested alerts matching the scope are pushed as Server-Sent Events of type
+// "alert". A comment heartbeat is sent periodically so idle connections survive
+// proxies, and clients reconnecting with Last-Event-ID receive any buffered alerts
+// they missed.
+func (c *HTTPAlertController) Stream(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	scope, err := parseAlertScope(r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return writeError(w, http.StatusInternalServerError, "Streaming unsupported", "response writer does not support flushing")
+	}
+
+	var lastEventID uint64
+	if header := r.Header.Get("Last-Event-ID"); header != "" {
+		lastEventID, _ = strconv.ParseUint(header, 10, 64)
+	}
+
+	events, replay, cancel := c.broker.Subscribe(lastEventID)
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Tell clients how long to wait before reconnecting
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", (5 * time.Second).Milliseconds()); err != nil {
+		return err
+	}
+	for _, event := range replay {
+		if err := writeAlertEvent(w, scope, event); err != nil {
+			return err
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(c.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return err
+			}
+			flusher.Flush()
+		case event := <-events:
+			if err := writeAlertEvent(w, scope, event); err != nil {
+				return err
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeAlertEvent(w http.ResponseWriter, scope alertScope, event AlertEvent) error {
+	if !scope.matches(event.Alert) {
+		return nil
+	}
+
+	data, err := json.Marshal(event.Alert)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: alert\ndata: %s\n\n", event.ID, data)
+	return err
+}
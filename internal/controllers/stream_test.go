@@ -0,0 +1,236 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// streamRecorder is a concurrency-safe http.ResponseWriter that supports flushing
+type streamRecorder struct {
+	mu      sync.Mutex
+	header  http.Header
+	code    int
+	body    bytes.Buffer
+	flushed chan struct{}
+}
+
+func newStreamRecorder() *streamRecorder {
+	return &streamRecorder{header: make(http.Header), flushed: make(chan struct{}, 64)}
+}
+
+func (s *streamRecorder) Header() http.Header { return s.header }
+
+func (s *streamRecorder) WriteHeader(code int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.code = code
+}
+
+func (s *streamRecorder) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.body.Write(p)
+}
+
+func (s *streamRecorder) Flush() {
+	select {
+	case s.flushed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *streamRecorder) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.body.String()
+}
+
+func waitForFlush(t *testing.T, rec *streamRecorder) {
+	t.Helper()
+	select {
+	case <-rec.flushed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for stream flush")
+	}
+}
+
+func TestAlertBroker(t *testing.T) {
+	broker := NewAlertBroker()
+	broker.Publish(&Alert{ProviderAlertID: "a"})
+	broker.Publish(&Alert{ProviderAlertID: "b"})
+
+	events, replay, cancel := broker.Subscribe(1)
+	defer cancel()
+
+	if len(replay) != 1 || replay[0].Alert.ProviderAlertID != "b" {
+		t.Fatalf("expected replay of event after ID 1, got %+v", replay)
+	}
+
+	broker.Publish(&Alert{ProviderAlertID: "c"})
+	select {
+	case event := <-events:
+		if event.ID != 3 || event.Alert.ProviderAlertID != "c" {
+			t.Errorf("expected event 3 for alert c, got %d for %s", event.ID, event.Alert.ProviderAlertID)
+		}
+	default:
+		t.Fatal("expected published event to be delivered")
+	}
+
+	_, replay, cancelNew := broker.Subscribe(0)
+	defer cancelNew()
+	if len(replay) != 0 {
+		t.Errorf("expected no replay without Last-Event-ID, got %d events", len(replay))
+	}
+}
+
+func TestAlertBrokerHistoryBounded(t *testing.T) {
+	broker := NewAlertBroker()
+	for i := 0; i < alertReplaySize+10; i++ {
+		broker.Publish(&Alert{})
+	}
+
+	_, replay, cancel := broker.Subscribe(1)
+	defer cancel()
+
+	if len(replay) != alertReplaySize {
+		t.Errorf("expected %d replayed events, got %d", alertReplaySize, len(replay))
+	}
+}
+
+func TestAlertScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		alert   Alert
+		wantErr bool
+		matches bool
+	}{
+		{name: "city match", query: "city_id=7", alert: Alert{CityID: 7}, matches: true},
+		{name: "city mismatch", query: "city_id=7", alert: Alert{CityID: 8}, matches: false},
+		{name: "within radius", query: "lat=40.7128&lon=-74.0060", alert: Alert{Latitude: 40.73, Longitude: -73.99}, matches: true},
+		{name: "outside radius", query: "lat=40.7128&lon=-74.0060", alert: Alert{Latitude: 42.36, Longitude: -71.06}, matches: false},
+		{name: "custom radius", query: "lat=40.7128&lon=-74.0060&radius=400", alert: Alert{Latitude: 42.36, Longitude: -71.06}, matches: true},
+		{name: "no scope", query: "", wantErr: true},
+		{name: "invalid city", query: "city_id=abc", wantErr: true},
+		{name: "missing lon", query: "lat=40.7", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/alerts/stream?"+test.query, nil)
+			scope, err := parseAlertScope(req)
+			if test.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := scope.matches(&test.alert); got != test.matches {
+				t.Errorf("expected matches=%v, got %v", test.matches, got)
+			}
+		})
+	}
+}
+
+func TestAlertStream(t *testing.T) {
+	t.Run("rejects missing scope", func(t *testing.T) {
+		controller := NewHTTPAlertController(&MockAlertRepository{}, nil)
+		req := httptest.NewRequest("GET", "/alerts/stream", nil)
+		w := httptest.NewRecorder()
+
+		_ = controller.Stream(context.Background(), w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("pushes matching alerts and replays missed ones", func(t *testing.T) {
+		broker := NewAlertBroker()
+		broker.Publish(&Alert{ProviderAlertID: "missed", CityID: 1})
+		broker.Publish(&Alert{ProviderAlertID: "other-city", CityID: 2})
+
+		controller := &HTTPAlertController{repo: &MockAlertRepository{}, broker: broker, heartbeatInterval: 10 * time.Millisecond}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/alerts/stream?city_id=1", nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", "0")
+		rec := newStreamRecorder()
+
+		done := make(chan error, 1)
+		go func() { done <- controller.Stream(ctx, rec, req) }()
+
+		waitForFlush(t, rec)
+
+		alert := createTestRepoAlert()
+		alert.CityID = 1
+		body, _ := json.Marshal(fromRepoAlert(alert))
+		w := httptest.NewRecorder()
+		_ = controller.Create(context.Background(), w, httptest.NewRequest("POST", "/alerts", bytes.NewReader(body)))
+
+		deadline := time.After(2 * time.Second)
+		for !strings.Contains(rec.String(), alert.ProviderAlertID) || !strings.Contains(rec.String(), ": heartbeat") {
+			select {
+			case <-rec.flushed:
+			case <-deadline:
+				t.Fatalf("timed out waiting for alert and heartbeat, got:\n%s", rec.String())
+			}
+		}
+
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("Expected no error, got: %v", err)
+		}
+
+		out := rec.String()
+		if rec.Header().Get("Content-Type") != "text/event-stream" {
+			t.Errorf("Expected text/event-stream content type, got %s", rec.Header().Get("Content-Type"))
+		}
+		if strings.Contains(out, "missed") {
+			t.Error("Expected no replay when Last-Event-ID is 0")
+		}
+		if strings.Contains(out, "other-city") {
+			t.Error("Expected alerts for other cities to be filtered out")
+		}
+		if !strings.Contains(out, "id: 3\nevent: alert\n") {
+			t.Errorf("Expected alert event with id 3, got:\n%s", out)
+		}
+	})
+
+	t.Run("resumes from Last-Event-ID", func(t *testing.T) {
+		broker := NewAlertBroker()
+		broker.Publish(&Alert{ProviderAlertID: "seen", CityID: 1})
+		broker.Publish(&Alert{ProviderAlertID: "missed", CityID: 1})
+
+		controller := NewHTTPAlertController(&MockAlertRepository{}, broker)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequest("GET", "/alerts/stream?city_id=1", nil).WithContext(ctx)
+		req.Header.Set("Last-Event-ID", "1")
+		rec := newStreamRecorder()
+
+		done := make(chan error, 1)
+		go func() { done <- controller.Stream(ctx, rec, req) }()
+		waitForFlush(t, rec)
+		cancel()
+		<-done
+
+		out := rec.String()
+		if strings.Contains(out, `"seen"`) {
+			t.Error("Expected already-seen alert not to be replayed")
+		}
+		if !strings.Contains(out, "id: 2\nevent: alert\n") || !strings.Contains(out, `"missed"`) {
+			t.Errorf("Expected missed alert to be replayed, got:\n%s", out)
+		}
+	})
+}
@@ -0,0 +1,243 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strings"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/notify"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+const (
+	// defaultSubscriptionRadiusKm is the area of a subscription when the request omits radius_km
+	defaultSubscriptionRadiusKm = 25
+
+	// maxSubscriptionRadiusKm bounds the area of a subscription
+	maxSubscriptionRadiusKm = 500
+)
+
+// AlertSubscriptionController handles alert subscriptions: an email sent when an alert
+// at or above a severity is ingested within a radius of a point, either at once or as
+// a daily digest
+type AlertSubscriptionController interface {
+	// Create handles requests to subscribe to alerts
+	Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// Get handles requests for a subscription
+	Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// ListByUser handles requests to list a user's subscriptions
+	ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error
+
+	// Delete handles requests to remove a subscription
+	Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error
+
+	// Unsubscribe handles the link in alert emails, removing a subscription by its token
+	Unsubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) error
+}
+
+// AlertSubscriptionRequest is the body of a request to subscribe to alerts. The
+// subscription belongs to the user making the request.
+type AlertSubscriptionRequest struct {
+	Email       string   `json:"email"`
+	Latitude    *float64 `json:"latitude"`
+	Longitude   *float64 `json:"longitude"`
+	RadiusKm    float64  `json:"radius_km,omitempty"`    // defaults to 25
+	MinSeverity string   `json:"min_severity,omitempty"` // CAP severity, defaults to Severe
+	Mode        string   `json:"mode,omitempty"`         // immediate (default) or digest
+}
+
+// validate checks the request fields, normalizing coordinates and severity
+func (s *AlertSubscriptionRequest) validate() models.ValidationErrors {
+	var errs models.ValidationErrors
+	if address, err := mail.ParseAddress(s.Email); err != nil || address.Address != s.Email {
+		errs = append(errs, models.FieldError{Field: "email", Message: "email must be a plain email address"})
+	}
+	if s.Latitude == nil || s.Longitude == nil {
+		errs = append(errs, models.FieldError{Field: "latitude", Message: "latitude and longitude are required"})
+	} else {
+		lat, lon, err := geo.Normalize(*s.Latitude, *s.Longitude)
+		if errors.Is(err, geo.ErrInvalidLatitude) {
+			errs = append(errs, models.FieldError{Field: "latitude", Message: err.Error()})
+		} else if err != nil {
+			errs = append(errs, models.FieldError{Field: "longitude", Message: err.Error()})
+		} else {
+			lat, lon = geo.Coarsen(lat, lon)
+			s.Latitude, s.Longitude = &lat, &lon
+		}
+	}
+	if s.RadiusKm <= 0 || s.RadiusKm > maxSubscriptionRadiusKm {
+		errs = append(errs, models.FieldError{Field: "radius_km", Message: fmt.Sprintf("radius_km must be between 0 and %d", maxSubscriptionRadiusKm)})
+	}
+	if rank := models.SeverityRank(s.MinSeverity); rank < 0 {
+		errs = append(errs, models.FieldError{Field: "min_severity", Message: "min_severity must be one of " + strings.Join(models.AlertSeverities, ", ")})
+	} else {
+		s.MinSeverity = models.AlertSeverities[rank]
+	}
+	if s.Mode != repo.SubscriptionImmediate && s.Mode != repo.SubscriptionDigest {
+		errs = append(errs, models.FieldError{Field: "mode", Message: "mode must be immediate or digest"})
+	}
+	return errs
+}
+
+// AlertSubscription is an alert subscription as returned to its owner. The unsubscribe
+// token is only returned when the subscription is created.
+type AlertSubscription struct {
+	ID             int     `json:"id"`
+	UserID         int     `json:"user_id"`
+	Email          string  `json:"email"`
+	Latitude       float64 `json:"latitude"`
+	Longitude      float64 `json:"longitude"`
+	RadiusKm       float64 `json:"radius_km"`
+	MinSeverity    string  `json:"min_severity"`
+	Mode           string  `json:"mode"`
+	Token          string  `json:"unsubscribe_token,omitempty"`
+	LastNotifiedAt string  `json:"last_notified_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// HTTPAlertSubscriptionController implements AlertSubscriptionController for HTTP requests
+type HTTPAlertSubscriptionController struct {
+	subscriptions repo.AlertSubscriptionRepository
+}
+
+// NewHTTPAlertSubscriptionController creates a new HTTP alert subscription controller.
+// Matching alerts are queued by a notify.Queuer and emailed by the
+// jobs.AlertNotifications job.
+func NewHTTPAlertSubscriptionController(subscriptions repo.AlertSubscriptionRepository) AlertSubscriptionController {
+	return &HTTPAlertSubscriptionController{subscriptions: subscriptions}
+}
+
+// Create handles POST /alert-subscriptions requests, subscribing the caller
+func (c *HTTPAlertSubscriptionController) Create(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	userID := requireOwner(ctx, w, "Alert subscriptions")
+	if userID == 0 {
+		return nil
+	}
+	var req AlertSubscriptionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid JSON", err.Error())
+	}
+	if req.RadiusKm == 0 {
+		req.RadiusKm = defaultSubscriptionRadiusKm
+	}
+	if req.MinSeverity == "" {
+		req.MinSeverity = "Severe"
+	}
+	if req.Mode == "" {
+		req.Mode = repo.SubscriptionImmediate
+	}
+	if errs := req.validate(); errs != nil {
+		return writeValidationError(w, errs)
+	}
+
+	token, err := notify.GenerateToken()
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to create alert subscription", err.Error())
+	}
+
+	subscription := &repo.AlertSubscription{
+		UserID:      userID,
+		Email:       req.Email,
+		Latitude:    *req.Latitude,
+		Longitude:   *req.Longitude,
+		RadiusKm:    req.RadiusKm,
+		MinSeverity: req.MinSeverity,
+		Mode:        req.Mode,
+		Token:       token,
+	}
+	if err := c.subscriptions.Create(ctx, subscription); err != nil {
+		return writeRepoError(w, err, "Alert subscription", "Failed to create alert subscription")
+	}
+
+	response := fromRepoAlertSubscription(subscription)
+	response.Token = subscription.Token
+	w.Header().Set("Location", fmt.Sprintf("/alert-subscriptions/%d", subscription.ID))
+	return writeCommitted(w, http.StatusCreated, response, "Alert subscription created successfully")
+}
+
+// Get handles GET /alert-subscriptions/{id} requests
+func (c *HTTPAlertSubscriptionController) Get(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	subscription, ok := c.owned(ctx, w, id)
+	if !ok {
+		return nil
+	}
+
+	return writeJSON(w, http.StatusOK, fromRepoAlertSubscription(subscription))
+}
+
+// ListByUser handles GET /users/{id}/alert-subscriptions requests
+func (c *HTTPAlertSubscriptionController) ListByUser(ctx context.Context, w http.ResponseWriter, r *http.Request, userID int) error {
+	if !canAccess(ctx, userID) {
+		return writeNotOwned(w, "User")
+	}
+	subscriptions, err := c.subscriptions.ListByUser(ctx, userID)
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve alert subscriptions", err.Error())
+	}
+
+	response := make([]*AlertSubscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		response = append(response, fromRepoAlertSubscription(subscription))
+	}
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// Delete handles DELETE /alert-subscriptions/{id} requests
+func (c *HTTPAlertSubscriptionController) Delete(ctx context.Context, w http.ResponseWriter, r *http.Request, id int) error {
+	if _, ok := c.owned(ctx, w, id); !ok {
+		return nil
+	}
+	if err := c.subscriptions.Delete(ctx, id); err != nil {
+		return writeRepoError(w, err, "Alert subscription", "Failed to delete alert subscription")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Alert subscription deleted successfully")
+}
+
+// owned returns an alert subscription the caller of ctx may see or change, answering
+// 404 for a missing subscription or another user's
+func (c *HTTPAlertSubscriptionController) owned(ctx context.Context, w http.ResponseWriter, id int) (*repo.AlertSubscription, bool) {
+	subscription, err := c.subscriptions.GetByID(ctx, id)
+	if err != nil {
+		_ = writeRepoError(w, err, "Alert subscription", "Failed to retrieve alert subscription")
+		return nil, false
+	}
+	if !canAccess(ctx, subscription.UserID) {
+		_ = writeNotOwned(w, "Alert subscription")
+		return nil, false
+	}
+	return subscription, true
+}
+
+// Unsubscribe handles GET and POST /alert-subscriptions/unsubscribe/{token} requests.
+// GET is accepted so the link works when clicked in a mail client.
+func (c *HTTPAlertSubscriptionController) Unsubscribe(ctx context.Context, w http.ResponseWriter, r *http.Request, token string) error {
+	if err := c.subscriptions.DeleteByToken(ctx, token); err != nil {
+		return writeRepoError(w, err, "Alert subscription", "Failed to unsubscribe")
+	}
+
+	return writeCommitted(w, http.StatusOK, nil, "Unsubscribed from weather alerts")
+}
+
+func fromRepoAlertSubscription(s *repo.AlertSubscription) *AlertSubscription {
+	return &AlertSubscription{
+		ID:             s.ID,
+		UserID:         s.UserID,
+		Email:          s.Email,
+		Latitude:       s.Latitude,
+		Longitude:      s.Longitude,
+		RadiusKm:       s.RadiusKm,
+		MinSeverity:    s.MinSeverity,
+		Mode:           s.Mode,
+		LastNotifiedAt: s.LastNotifiedAt,
+		CreatedAt:      s.CreatedAt,
+	}
+}
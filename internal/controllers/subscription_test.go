@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func createAlertSubscription(t *testing.T, controller AlertSubscriptionController, body string) (*httptest.ResponseRecorder, *AlertSubscription) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	if err := controller.Create(asUser(1), w, httptest.NewRequest("POST", "/alert-subscriptions", strings.NewReader(body))); err != nil {
+		t.Fatal(err)
+	}
+	var response struct {
+		Data *AlertSubscription `json:"data"`
+	}
+	_ = json.NewDecoder(strings.NewReader(w.Body.String())).Decode(&response)
+	return w, response.Data
+}
+
+func TestAlertSubscriptionController(t *testing.T) {
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	controller := NewHTTPAlertSubscriptionController(engine.AlertSubscriptions())
+	ctx := asUser(1)
+
+	w, subscription := createAlertSubscription(t, controller, `{"email":"a@example.com","latitude":41.88,"longitude":-87.63,"min_severity":"extreme"}`)
+	if w.Code != http.StatusCreated || subscription == nil {
+		t.Fatalf("Expected a created subscription, got %d: %s", w.Code, w.Body.String())
+	}
+	if subscription.Token == "" || subscription.RadiusKm != defaultSubscriptionRadiusKm || subscription.Mode != repo.SubscriptionImmediate {
+		t.Errorf("Expected a token and defaults, got %+v", subscription)
+	}
+	if subscription.MinSeverity != "Extreme" {
+		t.Errorf("Expected the severity in CAP case, got %q", subscription.MinSeverity)
+	}
+	if w.Header().Get("Location") != "/alert-subscriptions/"+strconv.Itoa(subscription.ID) {
+		t.Errorf("Unexpected Location %q", w.Header().Get("Location"))
+	}
+
+	t.Run("Get hides the token", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.Get(ctx, w, httptest.NewRequest("GET", "/alert-subscriptions/1", nil), subscription.ID); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), subscription.Token) {
+			t.Errorf("Expected the subscription without its token, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/alert-subscriptions", nil), 1); err != nil {
+			t.Fatal(err)
+		}
+		var subscriptions []*AlertSubscription
+		if err := json.NewDecoder(w.Body).Decode(&subscriptions); err != nil {
+			t.Fatal(err)
+		}
+		if len(subscriptions) != 1 || subscriptions[0].ID != subscription.ID {
+			t.Errorf("Expected the user's subscription, got %+v", subscriptions)
+		}
+	})
+
+	t.Run("Other users", func(t *testing.T) {
+		expectHidden(t, "Get", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Get(ctx, w, httptest.NewRequest("GET", "/alert-subscriptions/1", nil), subscription.ID)
+		})
+		expectHidden(t, "ListByUser", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.ListByUser(ctx, w, httptest.NewRequest("GET", "/users/1/alert-subscriptions", nil), 1)
+		})
+		expectHidden(t, "Delete", func(ctx context.Context, w http.ResponseWriter) error {
+			return controller.Delete(ctx, w, httptest.NewRequest("DELETE", "/alert-subscriptions/1", nil), subscription.ID)
+		})
+		w := httptest.NewRecorder()
+		if err := controller.ListByUser(asAdmin(), w, httptest.NewRequest("GET", "/users/1/alert-subscriptions", nil), 1); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"user_id":1`) {
+			t.Errorf("Expected an admin to list the user's subscriptions, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("Rejects invalid requests", func(t *testing.T) {
+		for name, body := range map[string]string{
+			"email":        `{"email":"Someone <a@example.com>","latitude":41.88,"longitude":-87.63}`,
+			"coordinates":  `{"email":"a@example.com","latitude":91,"longitude":-87.63}`,
+			"radius":       `{"email":"a@example.com","latitude":41.88,"longitude":-87.63,"radius_km":5000}`,
+			"min_severity": `{"email":"a@example.com","latitude":41.88,"longitude":-87.63,"min_severity":"Bad"}`,
+			"mode":         `{"email":"a@example.com","latitude":41.88,"longitude":-87.63,"mode":"weekly"}`,
+		} {
+			if w, _ := createAlertSubscription(t, controller, body); w.Code != http.StatusUnprocessableEntity {
+				t.Errorf("Expected invalid %s to be rejected, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("Unsubscribe", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		if err := controller.Unsubscribe(ctx, w, httptest.NewRequest("GET", "/alert-subscriptions/unsubscribe/x", nil), subscription.Token); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected 200, got %d", w.Code)
+		}
+		w = httptest.NewRecorder()
+		if err := controller.Unsubscribe(ctx, w, httptest.NewRequest("GET", "/alert-subscriptions/unsubscribe/x", nil), subscription.Token); err != nil {
+			t.Fatal(err)
+		}
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected 404 for a used token, got %d", w.Code)
+		}
+	})
+}
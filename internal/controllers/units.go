@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"net/http"
+
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// unitOptions are the output units requested for a response
+type unitOptions struct {
+	System units.System
+	Wind   units.WindUnit
+}
+
+// requestUnits returns the output units for a response. For the system an explicit
+// ?units= wins, then the caller's stored preference, then the locale of the most
+// preferred Accept-Language tag (imperial for en-US), then metric; ?lang= only picks
+// the language of names. Wind speeds follow the system unless ?wind_units= selects
+// knots, beaufort, kmh, mph or ms.
+//
+// When the header decides, the response is marked Vary: Accept-Language so shared
+// caches keep the metric and imperial renderings of a URL apart.
+func requestUnits(w http.ResponseWriter, r *http.Request) (unitOptions, error) {
+	system, err := requestSystem(w, r)
+	if err != nil {
+		return unitOptions{}, err
+	}
+	wind, err := units.ParseWindUnit(r.URL.Query().Get("wind_units"))
+	if err != nil {
+		return unitOptions{}, err
+	}
+	if wind == "" {
+		wind = system.WindUnit()
+	}
+	return unitOptions{System: system, Wind: wind}, nil
+}
+
+// requestSystem resolves the unit system for requestUnits
+func requestSystem(w http.ResponseWriter, r *http.Request) (units.System, error) {
+	if value := r.URL.Query().Get("units"); value != "" {
+		return units.ParseSystem(value)
+	}
+	if system, ok := units.PreferenceFromContext(r.Context()); ok {
+		return system, nil
+	}
+	w.Header().Add("Vary", "Accept-Language")
+	if languages := acceptLanguages(r.Header.Get("Accept-Language")); len(languages) > 0 {
+		return units.ForLocale(languages[0]), nil
+	}
+	return units.Metric, nil
+}
+
+// convertForecasts rewrites forecast measurements in place from stored metric values to
+// the requested units, recording the units and the Beaufort descriptor on each forecast
+func convertForecasts(opts unitOptions, forecasts ...*Forecast) {
+	for _, f := range forecasts {
+		if f == nil {
+			continue
+		}
+		f.Units = string(opts.System)
+		f.WindUnits = string(opts.Wind)
+		f.WindDescription = units.BeaufortDescription(units.BeaufortForce(f.WindSpeed))
+		if opts.Wind != units.MetersPerSecond {
+			f.WindSpeed = units.ConvertWindSpeed(f.WindSpeed, opts.Wind)
+			f.WindGust = units.ConvertWindSpeed(f.WindGust, opts.Wind)
+		}
+		if opts.System != units.Imperial {
+			continue
+		}
+
+		f.Temperature = units.Round(units.CelsiusToFahrenheit(f.Temperature), 1)
+		f.FeelsLike = units.Round(units.CelsiusToFahrenheit(f.FeelsLike), 1)
+		for _, derived := range []*float64{f.Dewpoint, f.HeatIndex, f.WindChill} {
+			if derived != nil {
+				*derived = units.Round(units.CelsiusToFahrenheit(*derived), 1)
+			}
+		}
+		f.Visibility = units.Round(units.KilometersToMiles(f.Visibility), 2)
+		f.Pressure = units.Round(units.HectopascalsToInchesOfMercury(f.Pressure), 2)
+		f.StationPressure = units.Round(units.HectopascalsToInchesOfMercury(f.StationPressure), 2)
+		f.Precipitation = units.Round(units.MillimetersToInches(f.Precipitation), 2)
+	}
+}
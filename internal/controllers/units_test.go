@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+func TestRequestUnits(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		language   string
+		preference units.System
+		expected   units.System
+		wantErr    bool
+	}{
+		{name: "default metric", expected: units.Metric},
+		{name: "query imperial", query: "?units=imperial", expected: units.Imperial},
+		{name: "user preference", preference: units.Imperial, expected: units.Imperial},
+		{name: "query overrides preference", query: "?units=metric", preference: units.Imperial, expected: units.Metric},
+		{name: "invalid", query: "?units=kelvin", wantErr: true},
+		{name: "en-US locale", language: "en-US,en;q=0.9", expected: units.Imperial},
+		{name: "en-GB locale", language: "en-GB", expected: units.Metric},
+		{name: "most preferred locale wins", language: "fr-FR;q=0.5, en-US", expected: units.Imperial},
+		{name: "preference overrides locale", language: "en-US", preference: units.Metric, expected: units.Metric},
+		{name: "query overrides locale", query: "?units=metric", language: "en-US", expected: units.Metric},
+		{name: "lang does not pick units", query: "?lang=en-US", expected: units.Metric},
+		{name: "lang does not override locale", query: "?lang=de", language: "en-US", expected: units.Imperial},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/forecasts"+test.query, nil)
+			if test.language != "" {
+				req.Header.Set("Accept-Language", test.language)
+			}
+			if test.preference != "" {
+				req = req.WithContext(units.WithPreference(req.Context(), test.preference))
+			}
+
+			w := httptest.NewRecorder()
+			opts, err := requestUnits(w, req)
+			if test.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if opts.System != test.expected {
+				t.Errorf("Expected %s, got %s", test.expected, opts.System)
+			}
+			// Only responses the header could have changed vary by it
+			headerDecides := !req.URL.Query().Has("units") && test.preference == ""
+			if vary := w.Header().Get("Vary") == "Accept-Language"; vary != headerDecides {
+				t.Errorf("Expected Vary: Accept-Language %v, got %q", headerDecides, w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestRequestWindUnits(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected units.WindUnit
+		wantErr  bool
+	}{
+		{query: "", expected: units.MetersPerSecond},
+		{query: "?units=imperial", expected: units.MilesPerHour},
+		{query: "?units=imperial&wind_units=knots", expected: units.Knots},
+		{query: "?wind_units=kmh", expected: units.KilometersPerHour},
+		{query: "?wind_units=Beaufort", expected: units.Beaufort},
+		{query: "?wind_units=furlongs", wantErr: true},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", "/forecasts"+test.query, nil)
+		opts, err := requestUnits(httptest.NewRecorder(), req)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected error", test.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.query, err)
+		}
+		if opts.Wind != test.expected {
+			t.Errorf("%s: expected %s, got %s", test.query, test.expected, opts.Wind)
+		}
+	}
+}
+
+func TestForecastUnitsConversion(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecast: createTestRepoForecast()}
+	controller := NewHTTPForecastController(mockRepo)
+
+	t.Run("imperial", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/forecasts/1?units=imperial", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.GetByID(context.Background(), w, req, 1); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		var response struct {
+			Data Forecast `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		metric := fromRepoForecast(createTestRepoForecast())
+		expected := units.Round(units.CelsiusToFahrenheit(metric.Temperature), 1)
+		if response.Data.Temperature != expected {
+			t.Errorf("Expected temperature %f°F, got %f", expected, response.Data.Temperature)
+		}
+		if response.Data.Units != "imperial" {
+			t.Errorf("Expected units imperial, got %q", response.Data.Units)
+		}
+		if response.Data.Humidity != metric.Humidity {
+			t.Errorf("Expected humidity to be unchanged, got %f", response.Data.Humidity)
+		}
+	})
+
+	t.Run("beaufort", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/forecasts/1?wind_units=beaufort", nil)
+		w := httptest.NewRecorder()
+
+		if err := controller.GetByID(context.Background(), w, req, 1); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+
+		var response struct {
+			Data Forecast `json:"data"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		metric := fromRepoForecast(createTestRepoForecast())
+		force := units.BeaufortForce(metric.WindSpeed)
+		if response.Data.WindSpeed != float64(force) {
+			t.Errorf("Expected force %d, got %f", force, response.Data.WindSpeed)
+		}
+		if response.Data.WindUnits != "beaufort" {
+			t.Errorf("Expected wind units beaufort, got %q", response.Data.WindUnits)
+		}
+		if response.Data.WindDescription != units.BeaufortDescription(force) {
+			t.Errorf("Unexpected wind description %q", response.Data.WindDescription)
+		}
+		if response.Data.Units != "metric" {
+			t.Errorf("Expected units metric, got %q", response.Data.Units)
+		}
+	})
+
+	t.Run("stored data is not modified", func(t *testing.T) {
+		if mockRepo.forecast.Temperature != createTestRepoForecast().Temperature {
+			t.Error("Expected repository forecast to stay metric")
+		}
+	})
+
+	t.Run("invalid units", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/forecasts/1?units=kelvin", nil)
+		w := httptest.NewRecorder()
+
+		_ = controller.GetByID(context.Background(), w, req, 1)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+// ValidationErrorResponse is the 422 body returned when a request payload fails model
+// validation
+type ValidationErrorResponse struct {
+	Status  int                 `json:"status"`
+	Message string              `json:"message"`
+	Errors  []models.FieldError `json:"errors"`
+}
+
+// writeValidationError writes a 422 listing every invalid field
+func writeValidationError(w http.ResponseWriter, errs models.ValidationErrors) error {
+	return writeJSON(w, http.StatusUnprocessableEntity, &ValidationErrorResponse{
+		Status:  http.StatusUnprocessableEntity,
+		Message: "Validation failed",
+		Errors:  errs,
+	})
+}
+
+// validateModel runs a model's Validate and merges its errors after fieldErrs, which hold
+// problems found while converting the request (e.g. unparseable timestamps). Model
+// errors for a field that already failed conversion are dropped as redundant.
+func validateModel(model models.Model, fieldErrs models.ValidationErrors) models.ValidationErrors {
+	errs := fieldErrs
+
+	var modelErrs models.ValidationErrors
+	if err := model.Validate(); errors.As(err, &modelErrs) {
+		for _, e := range modelErrs {
+			if !fieldErrs.HasField(e.Field) {
+				errs = append(errs, e)
+			}
+		}
+	} else if err != nil {
+		errs = append(errs, models.FieldError{Message: err.Error()})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// parseTimeField parses an optional RFC 3339 request field; an empty value is the zero
+// time so the model's required checks apply
+func parseTimeField(errs *models.ValidationErrors, field, value string) time.Time {
+	if value == "" {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		*errs = append(*errs, models.FieldError{Field: field, Message: field + " must be an RFC 3339 timestamp"})
+		return time.Time{}
+	}
+	return t
+}
+
+// validate checks a forecast payload against models.Forecast
+func (f *Forecast) validate() models.ValidationErrors {
+	var errs models.ValidationErrors
+	model := &models.Forecast{
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    parseTimeField(&errs, "forecast_time", f.ForecastTime),
+		ValidTime:       parseTimeField(&errs, "valid_time", f.ValidTime),
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		UVIndex:         f.UVIndex,
+	}
+	return validateModel(model, errs)
+}
+
+// validate checks a city payload against models.City, normalizing the country code
+func (c *City) validate() models.ValidationErrors {
+	model := &models.City{
+		Name:        c.Name,
+		Country:     c.Country,
+		CountryCode: c.CountryCode,
+		Latitude:    c.Latitude,
+		Longitude:   c.Longitude,
+		Population:  c.Population,
+	}
+	errs := validateModel(model, nil)
+	c.CountryCode = model.CountryCode
+	return errs
+}
+
+// validate checks a place payload against models.Place, normalizing the country code
+func (p *Place) validate() models.ValidationErrors {
+	model := &models.Place{
+		DisplayName: p.DisplayName,
+		CountryCode: p.CountryCode,
+		Latitude:    p.Latitude,
+		Longitude:   p.Longitude,
+		Confidence:  p.Confidence,
+		Source:      p.Source,
+	}
+	errs := validateModel(model, nil)
+	p.CountryCode = model.CountryCode
+	return errs
+}
+
+// validate checks an alert payload against models.Alert
+func (a *Alert) validate() models.ValidationErrors {
+	var errs models.ValidationErrors
+	model := &models.Alert{
+		SourceProvider:  a.SourceProvider,
+		ProviderAlertID: a.ProviderAlertID,
+		Title:           a.Title,
+		Latitude:        a.Latitude,
+		Longitude:       a.Longitude,
+		StartTime:       parseTimeField(&errs, "start_time", a.StartTime),
+		EndTime:         parseTimeField(&errs, "end_time", a.EndTime),
+		Geometry:        json.RawMessage(alertGeometry(a.Geometry)),
+	}
+	if a.CityID != 0 {
+		model.CityID = &a.CityID
+	}
+	return validateModel(model, errs)
+}
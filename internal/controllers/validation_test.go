@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestForecastValidate(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		if errs := createTestControllerForecast().validate(); errs != nil {
+			t.Errorf("Expected no errors, got %v", errs)
+		}
+	})
+
+	t.Run("aggregates field errors", func(t *testing.T) {
+		forecast := createTestControllerForecast()
+		forecast.CityID = 0
+		forecast.Humidity = 150
+		forecast.ValidTime = "tomorrow"
+
+		errs := forecast.validate()
+		if len(errs) != 3 {
+			t.Fatalf("Expected 3 errors, got %d: %v", len(errs), errs)
+		}
+		// Conversion errors come first and replace the model's required check
+		if errs[0].Field != "valid_time" || errs[0].Message != "valid_time must be an RFC 3339 timestamp" {
+			t.Errorf("Unexpected first error %+v", errs[0])
+		}
+		for _, field := range []string{"city_id", "humidity"} {
+			if !errs.HasField(field) {
+				t.Errorf("Expected an error for %s", field)
+			}
+		}
+	})
+}
+
+func TestCityValidateNormalizesCountryCode(t *testing.T) {
+	city := &City{Name: "Paris", Country: "France", CountryCode: "fr"}
+	if errs := city.validate(); errs != nil {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if city.CountryCode != "FR" {
+		t.Errorf("Expected FR, got %s", city.CountryCode)
+	}
+}
+
+func TestCreateReturns422(t *testing.T) {
+	mockRepo := &MockCityRepository{}
+	controller := NewHTTPCityController(mockRepo)
+
+	body, _ := json.Marshal(&City{Latitude: 120})
+	req := httptest.NewRequest("POST", "/cities", bytes.NewReader(body))
+	w := httptest.NewRecorder()
+	_ = controller.Create(context.Background(), w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+	}
+
+	var response ValidationErrorResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	fields := make(map[string]bool)
+	for _, e := range response.Errors {
+		fields[e.Field] = true
+	}
+	for _, field := range []string{"name", "country", "latitude"} {
+		if !fields[field] {
+			t.Errorf("Expected an error for %s, got %+v", field, response.Errors)
+		}
+	}
+}
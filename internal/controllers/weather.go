@@ -0,0 +1,575 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/ttl"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+const (
+	// weatherDeadline bounds a whole composite request: geocoding plus the parallel
+	// current, forecast and alert lookups
+	weatherDeadline = 10 * time.Second
+
+	defaultForecastDays = 3
+	maxForecastDays     = 7
+
+	defaultForecastHours = 24
+	maxForecastHours     = 156
+
+	// nowcastMinutes is how far ahead nowcasts look
+	nowcastMinutes = 60
+)
+
+// WeatherController handles combined geocode + weather requests
+type WeatherController interface {
+	// GetByAddress handles requests for current conditions and a short forecast at an address
+	GetByAddress(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// GetHourlyForecast handles requests for an hour-by-hour forecast at a point
+	GetHourlyForecast(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+
+	// GetNowcast handles requests for the next hour of precipitation at a point
+	GetNowcast(ctx context.Context, w http.ResponseWriter, r *http.Request) error
+}
+
+// WeatherResponse is the combined result of geocoding an address and fetching its weather.
+// Alerts are best effort: they are empty when the provider's alert lookup fails.
+type WeatherResponse struct {
+	Place    *Place                   `json:"place"`
+	Provider string                   `json:"provider"`
+	Current  *Forecast                `json:"current"`
+	Forecast []*Forecast              `json:"forecast"`
+	Alerts   []providers.WeatherAlert `json:"alerts"`
+}
+
+// HourlyForecastResponse is a provider's hourly forecast for a point
+type HourlyForecastResponse struct {
+	Provider  string            `json:"provider"`
+	Latitude  float64           `json:"latitude"`
+	Longitude float64           `json:"longitude"`
+	Hours     []*HourlyForecast `json:"hours"`
+}
+
+// HourlyForecast is one hour of forecast with the values only hourly forecasts carry,
+// null when the provider does not report them
+type HourlyForecast struct {
+	Forecast
+	PrecipitationProbability *float64 `json:"precipitation_probability"` // percent
+}
+
+// NowcastResponse is a provider's minute-by-minute precipitation for the next hour at a
+// point, with intensities in Units ("mm/h", or "in/h" for imperial requests)
+type NowcastResponse struct {
+	Provider  string           `json:"provider"`
+	Latitude  float64          `json:"latitude"`
+	Longitude float64          `json:"longitude"`
+	Units     string           `json:"units"`
+	Minutes   []*NowcastMinute `json:"minutes"`
+}
+
+// NowcastMinute is the precipitation expected in one minute
+type NowcastMinute struct {
+	Time                     time.Time `json:"time"`
+	PrecipitationIntensity   float64   `json:"precipitation_intensity"`
+	PrecipitationProbability *float64  `json:"precipitation_probability"` // percent, null when not reported
+	PrecipitationType        string    `json:"precipitation_type,omitempty"`
+}
+
+// HTTPWeatherController implements WeatherController for HTTP requests
+type HTTPWeatherController struct {
+	providers *providers.ProviderManager
+	places    repo.PlaceRepository
+	cache     repo.Cache
+	policy    *ttl.Policy
+
+	mu       sync.Mutex
+	geocodes map[string]*geocodeCall // in-flight geocodes by cache key
+}
+
+// geocodeCall is a geocode shared by concurrent requests for the same address
+type geocodeCall struct {
+	done  chan struct{}
+	place *Place
+	err   error
+}
+
+// NewHTTPWeatherController creates a new HTTP weather controller.
+//
+// Geocoded places are stored through places and, when cache is non-nil, the address
+// lookup itself is cached for the policy's geocode lifetime so repeated requests skip
+// the geocoder. Concurrent requests for the same address share a single geocode.
+// A nil policy uses ttl.Default.
+func NewHTTPWeatherController(pm *providers.ProviderManager, places repo.PlaceRepository, cache repo.Cache, policy *ttl.Policy) WeatherController {
+	if policy == nil {
+		policy = ttl.Default()
+	}
+	return &HTTPWeatherController{
+		providers: pm,
+		places:    places,
+		cache:     cache,
+		policy:    policy,
+		geocodes:  make(map[string]*geocodeCall),
+	}
+}
+
+// GetByAddress handles GET /weather?address=...&days=&units=&geocoder= requests. A
+// geocoder named by ?geocoder= (e.g. "Google") is used alone instead of trying each in
+// turn.
+func (c *HTTPWeatherController) GetByAddress(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	address := strings.TrimSpace(r.URL.Query().Get("address"))
+	if address == "" {
+		return writeError(w, http.StatusBadRequest, "Missing parameter", "address parameter is required")
+	}
+
+	var geocoder providers.GeocodeProvider
+	if name := strings.TrimSpace(r.URL.Query().Get("geocoder")); name != "" {
+		if geocoder = c.providers.GetGeocodeProviderByName(name); geocoder == nil {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", fmt.Sprintf("unknown geocoder %q", name))
+		}
+	}
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = defaultForecastDays
+	}
+	if days > maxForecastDays {
+		days = maxForecastDays
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+	ctx, tracker := freshness.WithTracker(ctx)
+
+	place, err := c.resolvePlace(ctx, address, geocoder)
+	if err != nil {
+		return writeProviderError(w, "Geocoding failed", err)
+	}
+	if place == nil {
+		return writeError(w, http.StatusNotFound, "Address not found", "no geocoder returned a match for the address")
+	}
+
+	provider := c.providers.GetWeatherProviderForRegion(place.CountryCode)
+	if provider == nil {
+		return writeError(w, http.StatusUnprocessableEntity, "Unsupported region",
+			fmt.Sprintf("no weather provider covers country %q", place.CountryCode))
+	}
+
+	// The lookups only depend on the place, so they run in parallel
+	var (
+		wg                      sync.WaitGroup
+		current                 *models.Forecast
+		forecasts               []*models.Forecast
+		alerts                  []providers.WeatherAlert
+		currentErr, forecastErr error
+	)
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		current, currentErr = provider.GetCurrentWeather(ctx, place.Latitude, place.Longitude)
+	}()
+	go func() {
+		defer wg.Done()
+		forecasts, forecastErr = provider.GetForecast(ctx, place.Latitude, place.Longitude, days)
+	}()
+	go func() {
+		defer wg.Done()
+		alerts, _ = provider.GetAlerts(ctx, place.Latitude, place.Longitude)
+	}()
+	wg.Wait()
+
+	if currentErr != nil {
+		return writeProviderError(w, "Failed to retrieve current weather", currentErr)
+	}
+	if forecastErr != nil {
+		return writeProviderError(w, "Failed to retrieve forecast", forecastErr)
+	}
+
+	response := &WeatherResponse{
+		Place:    place,
+		Provider: provider.GetName(),
+		Current:  fromModelForecast(current),
+		Forecast: make([]*Forecast, 0, len(forecasts)),
+		Alerts:   alerts,
+	}
+	if response.Alerts == nil {
+		response.Alerts = []providers.WeatherAlert{}
+	}
+	for _, f := range forecasts {
+		response.Forecast = append(response.Forecast, fromModelForecast(f))
+	}
+	convertForecasts(opts, response.Current)
+	convertForecasts(opts, response.Forecast...)
+
+	// Current conditions change fastest, so they set the fallback lifetime
+	writeFreshness(w, tracker, c.policy.Current(), time.Now())
+	return writeJSON(w, http.StatusOK, response)
+}
+
+// GetHourlyForecast handles GET /forecasts/hourly?lat=&lon=&hours=&units= requests.
+// The first provider with an hourly forecast covering the point answers; hours defaults
+// to 24 and is capped at 156.
+func (c *HTTPWeatherController) GetHourlyForecast(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	hours := defaultForecastHours
+	if value := query.Get("hours"); value != "" {
+		if hours, err = strconv.Atoi(value); err != nil || hours <= 0 {
+			return writeError(w, http.StatusBadRequest, "Invalid parameter", "hours must be a positive integer")
+		}
+	}
+	hours = min(hours, maxForecastHours)
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+	ctx, tracker := freshness.WithTracker(ctx)
+
+	err = fmt.Errorf("%w: no weather provider publishes hourly forecasts", providers.ErrUnsupportedRegion)
+	for _, provider := range c.providers.GetWeatherProviders() {
+		hourly, ok := provider.(providers.HourlyForecaster)
+		if !ok {
+			continue
+		}
+		forecasts, lookupErr := hourly.GetHourlyForecast(ctx, lat, lon, hours)
+		if lookupErr != nil {
+			err = fmt.Errorf("%s: %w", provider.GetName(), lookupErr)
+			if errors.Is(lookupErr, providers.ErrUnsupportedRegion) {
+				continue
+			}
+			return writeProviderError(w, "Failed to retrieve hourly forecast", err)
+		}
+
+		response := &HourlyForecastResponse{
+			Provider:  provider.GetName(),
+			Latitude:  lat,
+			Longitude: lon,
+			Hours:     make([]*HourlyForecast, 0, len(forecasts)),
+		}
+		for _, f := range forecasts {
+			response.Hours = append(response.Hours, fromProviderHourly(f, opts))
+		}
+		// Without a lifetime from the provider, the response is only as fresh as its
+		// nearest hour, which is revised most often
+		now := time.Now()
+		fallback := c.policy.ForecastWithin(0)
+		if len(forecasts) > 0 {
+			fallback = c.policy.ForecastAt(forecasts[0].ValidTime, now)
+		}
+		writeFreshness(w, tracker, fallback, now)
+		return writeJSON(w, http.StatusOK, response)
+	}
+	return writeProviderError(w, "Failed to retrieve hourly forecast", err)
+}
+
+// GetNowcast handles GET /nowcast?lat=&lon=&units= requests, answered by the first
+// provider publishing nowcasts that covers the point
+func (c *HTTPWeatherController) GetNowcast(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
+	query := r.URL.Query()
+	lat, lon, err := geo.ParseCoordinates(query.Get("lat"), query.Get("lon"))
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, weatherDeadline)
+	defer cancel()
+	ctx, tracker := freshness.WithTracker(ctx)
+
+	err = fmt.Errorf("%w: no weather provider publishes nowcasts", providers.ErrUnsupportedRegion)
+	for _, provider := range c.providers.GetWeatherProviders() {
+		nowcaster, ok := provider.(providers.Nowcaster)
+		if !ok {
+			continue
+		}
+		minutes, lookupErr := nowcaster.GetNowcast(ctx, lat, lon, nowcastMinutes)
+		if lookupErr != nil {
+			err = fmt.Errorf("%s: %w", provider.GetName(), lookupErr)
+			if errors.Is(lookupErr, providers.ErrUnsupportedRegion) {
+				continue
+			}
+			return writeProviderError(w, "Failed to retrieve nowcast", err)
+		}
+
+		response := &NowcastResponse{
+			Provider:  provider.GetName(),
+			Latitude:  lat,
+			Longitude: lon,
+			Units:     "mm/h",
+			Minutes:   make([]*NowcastMinute, 0, len(minutes)),
+		}
+		if opts.System == units.Imperial {
+			response.Units = "in/h"
+		}
+		for _, m := range minutes {
+			minute := &NowcastMinute{
+				Time:                     m.Time,
+				PrecipitationIntensity:   m.PrecipitationIntensity,
+				PrecipitationProbability: m.PrecipitationProbability,
+				PrecipitationType:        m.PrecipitationType,
+			}
+			if opts.System == units.Imperial {
+				minute.PrecipitationIntensity = units.Round(units.MillimetersToInches(m.PrecipitationIntensity), 3)
+			}
+			response.Minutes = append(response.Minutes, minute)
+		}
+		// Nowcasts are revised as often as current conditions
+		writeFreshness(w, tracker, c.policy.Current(), time.Now())
+		return writeJSON(w, http.StatusOK, response)
+	}
+	return writeProviderError(w, "Failed to retrieve nowcast", err)
+}
+
+// fromProviderHourly converts an hourly forecast to the requested units
+func fromProviderHourly(f *providers.HourlyForecast, opts unitOptions) *HourlyForecast {
+	hour := &HourlyForecast{
+		Forecast:                 *fromModelForecast(&f.Forecast),
+		PrecipitationProbability: f.PrecipitationProbability,
+	}
+	// A reported dewpoint is preferred over the derived one
+	if f.Dewpoint != nil {
+		dewpoint := *f.Dewpoint
+		hour.Dewpoint = &dewpoint
+	}
+	convertForecasts(opts, &hour.Forecast)
+	return hour
+}
+
+// resolvePlace returns the place for an address from the cache, or geocodes it with the
+// first provider that finds a match, or only with geocoder when it is non-nil, and stores
+// the result. A nil place with a nil error means no provider matched.
+func (c *HTTPWeatherController) resolvePlace(ctx context.Context, address string, geocoder providers.GeocodeProvider) (*Place, error) {
+	key := "geocode:" + strings.ToLower(address)
+	geocoders := c.providers.GetGeocodeProviders()
+	if geocoder != nil {
+		// Each geocoder's answer is cached apart from the default one
+		key = "geocode/" + strings.ToLower(geocoder.GetName()) + ":" + strings.ToLower(address)
+		geocoders = []providers.GeocodeProvider{geocoder}
+	}
+	if place, ok := c.cachedPlace(ctx, key); ok {
+		return place, nil
+	}
+
+	c.mu.Lock()
+	if call, ok := c.geocodes[key]; ok {
+		c.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.place, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	call := &geocodeCall{done: make(chan struct{})}
+	c.geocodes[key] = call
+	c.mu.Unlock()
+
+	call.place, call.err = c.geocode(ctx, geocoders, address, key)
+
+	c.mu.Lock()
+	delete(c.geocodes, key)
+	c.mu.Unlock()
+	close(call.done)
+	return call.place, call.err
+}
+
+// cachedPlace returns the place an address was last geocoded to. The cache remembers
+// which place that was, while the stored row is served, so edits to it show at once;
+// a place deleted from storage is geocoded again. Without storage, or when it fails,
+// the cached copy is served.
+func (c *HTTPWeatherController) cachedPlace(ctx context.Context, key string) (*Place, bool) {
+	if c.cache == nil {
+		return nil, false
+	}
+	data, err := c.cache.Get(ctx, key)
+	if err != nil || data == nil {
+		return nil, false
+	}
+	var place Place
+	if err := json.Unmarshal(data, &place); err != nil {
+		return nil, false
+	}
+	if c.places == nil || place.SourcePlaceID == "" {
+		return &place, true
+	}
+
+	stored, err := c.places.GetBySourcePlaceID(ctx, place.Source, place.SourcePlaceID)
+	switch {
+	case err == nil && stored != nil:
+		return fromRepoPlace(stored), true
+	case errors.Is(err, repo.ErrNotFound):
+		_ = c.cache.Delete(ctx, key)
+		return nil, false
+	default:
+		return &place, true
+	}
+}
+
+// geocode looks an address up with each of geocoders in turn, storing and caching the
+// first match under key
+func (c *HTTPWeatherController) geocode(ctx context.Context, geocoders []providers.GeocodeProvider, address, key string) (*Place, error) {
+	var lastErr error
+	for _, geocoder := range geocoders {
+		places, err := geocoder.GeocodeAddress(ctx, address)
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", geocoder.GetName(), err)
+			continue
+		}
+		if len(places) == 0 {
+			continue
+		}
+
+		place := c.storePlace(ctx, fromModelPlace(places[0]))
+		if c.cache != nil {
+			if data, err := json.Marshal(place); err == nil {
+				_ = c.cache.Set(ctx, key, data, c.policy.Geocode())
+			}
+		}
+		return place, nil
+	}
+
+	return nil, lastErr
+}
+
+// storePlace persists a geocoded place unless it is already known, returning the stored
+// copy. Storage failures are not fatal: the request can still be answered.
+func (c *HTTPWeatherController) storePlace(ctx context.Context, place *Place) *Place {
+	if c.places == nil {
+		return place
+	}
+
+	if place.SourcePlaceID != "" {
+		if existing, err := c.places.GetBySourcePlaceID(ctx, place.Source, place.SourcePlaceID); err == nil && existing != nil {
+			return fromRepoPlace(existing)
+		}
+	}
+
+	repoPlace := toRepoPlace(place)
+	if err := c.places.Create(ctx, repoPlace); err != nil {
+		return place
+	}
+	return fromRepoPlace(repoPlace)
+}
+
+// defaultRetryAfter is the Retry-After hint sent for throttled or unavailable providers
+// that did not give one
+const defaultRetryAfter = 30 * time.Second
+
+// writeProviderError writes a failed provider call with the status its kind maps to:
+// 429 when rate limited, 422 for unsupported regions and bad coordinates, 503 when the
+// provider is unavailable, 504 when the request deadline passed and 502 otherwise.
+// Rate limited and unavailable responses carry a Retry-After header.
+func writeProviderError(w http.ResponseWriter, message string, err error) error {
+	status := http.StatusBadGateway
+	switch {
+	case errors.Is(err, providers.ErrRateLimited):
+		status = http.StatusTooManyRequests
+	case errors.Is(err, providers.ErrUnsupportedRegion), errors.Is(err, providers.ErrBadCoordinates):
+		status = http.StatusUnprocessableEntity
+	case errors.Is(err, providers.ErrUpstreamUnavailable):
+		status = http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	}
+
+	if status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable {
+		retry := providers.RetryAfter(err)
+		if retry <= 0 {
+			retry = defaultRetryAfter
+		}
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	}
+	return writeError(w, status, message, err.Error())
+}
+
+func fromModelForecast(f *models.Forecast) *Forecast {
+	if f == nil {
+		return nil
+	}
+	return normalizeWeatherCode(deriveMetrics(&Forecast{
+		ID:              f.ID,
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    formatTime(f.ForecastTime),
+		ValidTime:       formatTime(f.ValidTime),
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+		CreatedAt:       formatTime(f.CreatedAt),
+		UpdatedAt:       formatTime(f.UpdatedAt),
+	}))
+}
+
+func fromModelPlace(p *models.Place) *Place {
+	return &Place{
+		ID:            p.ID,
+		DisplayName:   p.DisplayName,
+		AddressLine1:  p.AddressLine1,
+		AddressLine2:  p.AddressLine2,
+		City:          p.City,
+		Region:        p.Region,
+		PostalCode:    p.PostalCode,
+		Country:       p.Country,
+		CountryCode:   p.CountryCode,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+		PlaceType:     p.PlaceType,
+		Confidence:    p.Confidence,
+		Source:        p.Source,
+		SourcePlaceID: p.SourcePlaceID,
+		BoundingBox:   p.BoundingBox,
+		CreatedAt:     formatTime(p.CreatedAt),
+		UpdatedAt:     formatTime(p.UpdatedAt),
+	}
+}
+
+// formatTime renders a time as RFC 3339, leaving zero times empty
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,649 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+type stubWeatherProvider struct {
+	name    string
+	regions []string
+	mu      sync.Mutex
+	days    int
+	alerts  []providers.WeatherAlert
+	delay   time.Duration // applied to every lookup, honoring the context
+}
+
+func (s *stubWeatherProvider) GetName() string { return s.name }
+
+func (s *stubWeatherProvider) wait(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *stubWeatherProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return &models.Forecast{SourceProvider: s.name, Temperature: 21.5, ValidTime: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)}, nil
+}
+
+func (s *stubWeatherProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	s.days = days
+	s.mu.Unlock()
+	forecasts := make([]*models.Forecast, days)
+	for i := range forecasts {
+		forecasts[i] = &models.Forecast{SourceProvider: s.name, Temperature: float64(20 + i)}
+	}
+	return forecasts, nil
+}
+
+func (s *stubWeatherProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]providers.WeatherAlert, error) {
+	if err := s.wait(ctx); err != nil {
+		return nil, err
+	}
+	return s.alerts, nil
+}
+
+func (s *stubWeatherProvider) SupportedRegions() []string { return s.regions }
+
+// stubHourlyProvider is a stubWeatherProvider with an hourly forecast, or err
+type stubHourlyProvider struct {
+	stubWeatherProvider
+	err error
+}
+
+func (s *stubHourlyProvider) GetHourlyForecast(ctx context.Context, lat, lon float64, hours int) ([]*providers.HourlyForecast, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	probability, dewpoint := 40.0, 10.0
+	forecasts := make([]*providers.HourlyForecast, hours)
+	for i := range forecasts {
+		forecasts[i] = &providers.HourlyForecast{
+			Forecast: models.Forecast{SourceProvider: s.name, Temperature: 20, ValidTime: time.Date(2024, 1, 15, i, 0, 0, 0, time.UTC)},
+		}
+	}
+	forecasts[0].PrecipitationProbability = &probability
+	forecasts[0].Dewpoint = &dewpoint
+	return forecasts, nil
+}
+
+// stubNowcastProvider is a stubWeatherProvider with a nowcast of light rain, or err
+type stubNowcastProvider struct {
+	stubWeatherProvider
+	err     error
+	minutes int
+}
+
+func (s *stubNowcastProvider) GetNowcast(ctx context.Context, lat, lon float64, minutes int) ([]*providers.NowcastMinute, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.minutes = minutes
+	probability := 80.0
+	nowcast := make([]*providers.NowcastMinute, minutes)
+	for i := range nowcast {
+		nowcast[i] = &providers.NowcastMinute{Time: time.Date(2024, 1, 15, 12, i, 0, 0, time.UTC)}
+	}
+	nowcast[0].PrecipitationIntensity, nowcast[0].PrecipitationType = 2.54, "rain"
+	nowcast[0].PrecipitationProbability = &probability
+	return nowcast, nil
+}
+
+type stubGeocodeProvider struct {
+	name   string
+	places []*models.Place
+	err    error
+	calls  int
+}
+
+func (s *stubGeocodeProvider) GetName() string { return s.name }
+
+func (s *stubGeocodeProvider) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	s.calls++
+	return s.places, s.err
+}
+
+func (s *stubGeocodeProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	return nil, nil
+}
+
+func (s *stubGeocodeProvider) SupportedRegions() []string { return []string{"US"} }
+
+// gatedGeocodeProvider blocks every geocode until release is closed
+type gatedGeocodeProvider struct {
+	stubGeocodeProvider
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (g *gatedGeocodeProvider) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	g.calls.Add(1)
+	<-g.release
+	return []*models.Place{newTestPlace()}, nil
+}
+
+// memoryCache is a minimal in-memory repo.Cache for tests
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+func newMemoryCache() *memoryCache { return &memoryCache{data: make(map[string][]byte)} }
+
+func (m *memoryCache) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	value, ok := m.data[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return value, nil
+}
+
+func (m *memoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[key] = value
+	return nil
+}
+
+func (m *memoryCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.data, key)
+	return nil
+}
+
+func (m *memoryCache) Exists(ctx context.Context, key string) (bool, error) {
+	_, err := m.Get(ctx, key)
+	return err == nil, nil
+}
+
+func (m *memoryCache) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	if exists, _ := m.Exists(ctx, key); exists {
+		return false, nil
+	}
+	return true, m.Set(ctx, key, value, ttl)
+}
+
+func (m *memoryCache) GetTTL(ctx context.Context, key string) (time.Duration, error) { return 0, nil }
+func (m *memoryCache) Clear(ctx context.Context) error                               { return nil }
+func (m *memoryCache) Close() error                                                  { return nil }
+
+func newTestPlace() *models.Place {
+	return &models.Place{
+		DisplayName:   "1600 Pennsylvania Ave NW, Washington, DC",
+		CountryCode:   "US",
+		Latitude:      38.8977,
+		Longitude:     -77.0365,
+		Source:        "Census",
+		SourcePlaceID: "census-1",
+	}
+}
+
+func TestWeatherController(t *testing.T) {
+	t.Run("interface compliance", func(t *testing.T) {
+		var _ WeatherController = NewHTTPWeatherController(providers.NewProviderManager(), nil, nil, nil)
+	})
+
+	t.Run("requires address", func(t *testing.T) {
+		controller := NewHTTPWeatherController(providers.NewProviderManager(), nil, nil, nil)
+		req := httptest.NewRequest("GET", "/weather", nil)
+		w := httptest.NewRecorder()
+
+		_ = controller.GetByAddress(context.Background(), w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+		}
+	})
+
+	t.Run("geocodes, stores and caches the place", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		geocoder := &stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}}
+		weather := &stubWeatherProvider{name: "NWS", regions: []string{"US"}}
+		pm.RegisterGeocodeProvider(geocoder)
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "Global", regions: []string{providers.GlobalRegion}})
+		pm.RegisterWeatherProvider(weather)
+
+		controller := NewHTTPWeatherController(pm, &MockPlaceRepository{}, newMemoryCache(), nil)
+
+		for i := 0; i < 2; i++ {
+			req := httptest.NewRequest("GET", "/weather?address=1600+Pennsylvania+Ave&days=2", nil)
+			w := httptest.NewRecorder()
+
+			if err := controller.GetByAddress(context.Background(), w, req); err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+			}
+
+			var response WeatherResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.Provider != "NWS" {
+				t.Errorf("Expected regional provider NWS, got %s", response.Provider)
+			}
+			if response.Place.ID != 789 {
+				t.Errorf("Expected stored place ID 789, got %d", response.Place.ID)
+			}
+			if response.Current == nil || response.Current.ValidTime != "2024-01-15T12:00:00Z" {
+				t.Errorf("Expected current conditions with valid time, got %+v", response.Current)
+			}
+			if len(response.Forecast) != 2 {
+				t.Errorf("Expected 2 forecast entries, got %d", len(response.Forecast))
+			}
+		}
+
+		if geocoder.calls != 1 {
+			t.Errorf("Expected geocoder to be called once thanks to the cache, got %d", geocoder.calls)
+		}
+		if weather.days != 2 {
+			t.Errorf("Expected forecast for 2 days, got %d", weather.days)
+		}
+	})
+
+	t.Run("runs the weather lookups in parallel", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterGeocodeProvider(&stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}})
+		pm.RegisterWeatherProvider(&stubWeatherProvider{
+			name: "NWS", regions: []string{"US"}, delay: 100 * time.Millisecond,
+			alerts: []providers.WeatherAlert{{ID: "alert-1", Title: "Winter Storm Warning"}},
+		})
+
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+		req := httptest.NewRequest("GET", "/weather?address=somewhere", nil)
+		w := httptest.NewRecorder()
+
+		start := time.Now()
+		_ = controller.GetByAddress(context.Background(), w, req)
+		elapsed := time.Since(start)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		if elapsed >= 250*time.Millisecond {
+			t.Errorf("Expected current, forecast and alerts to be fetched concurrently, took %s", elapsed)
+		}
+
+		var response WeatherResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if len(response.Alerts) != 1 || response.Alerts[0].ID != "alert-1" {
+			t.Errorf("Expected the provider's alert, got %+v", response.Alerts)
+		}
+	})
+
+	t.Run("times out at the deadline", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterGeocodeProvider(&stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}})
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}, delay: time.Minute})
+
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		w := httptest.NewRecorder()
+
+		_ = controller.GetByAddress(ctx, w, httptest.NewRequest("GET", "/weather?address=somewhere", nil))
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected status %d, got %d", http.StatusGatewayTimeout, w.Code)
+		}
+	})
+
+	t.Run("serves cached addresses from storage", func(t *testing.T) {
+		engine, err := repo.OpenFileEngine("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pm := providers.NewProviderManager()
+		geocoder := &stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}}
+		pm.RegisterGeocodeProvider(geocoder)
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+		controller := NewHTTPWeatherController(pm, engine.Places(), repo.NewRequestCache(repo.NewMemoryStore(10), "weather"), nil)
+
+		lookup := func() *Place {
+			t.Helper()
+			w := httptest.NewRecorder()
+			_ = controller.GetByAddress(context.Background(), w, httptest.NewRequest("GET", "/weather?address=1600+Pennsylvania+Ave", nil))
+			var response WeatherResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil || response.Place == nil {
+				t.Fatalf("Expected a place, got %d: %v", w.Code, err)
+			}
+			return response.Place
+		}
+
+		first := lookup()
+		stored, err := engine.Places().GetBySourcePlaceID(context.Background(), "Census", "census-1")
+		if err != nil || stored.ID != first.ID {
+			t.Fatalf("Expected the geocoded place stored as %d, got %+v, %v", first.ID, stored, err)
+		}
+
+		stored.DisplayName = "The White House"
+		if err := engine.Places().Update(context.Background(), stored); err != nil {
+			t.Fatal(err)
+		}
+		if place := lookup(); place.DisplayName != "The White House" || geocoder.calls != 1 {
+			t.Errorf("Expected the stored row without geocoding again, got %q after %d geocodes", place.DisplayName, geocoder.calls)
+		}
+
+		if err := engine.Places().Delete(context.Background(), stored.ID); err != nil {
+			t.Fatal(err)
+		}
+		if place := lookup(); place.ID == stored.ID || geocoder.calls != 2 {
+			t.Errorf("Expected a deleted place to be geocoded and stored again, got ID %d after %d geocodes", place.ID, geocoder.calls)
+		}
+	})
+
+	t.Run("coalesces concurrent geocodes of an address", func(t *testing.T) {
+		geocoder := &gatedGeocodeProvider{stubGeocodeProvider: stubGeocodeProvider{name: "Census"}, release: make(chan struct{})}
+		pm := providers.NewProviderManager()
+		pm.RegisterGeocodeProvider(geocoder)
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+
+		var wg sync.WaitGroup
+		codes := make([]int, 5)
+		for i := range codes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				w := httptest.NewRecorder()
+				_ = controller.GetByAddress(context.Background(), w, httptest.NewRequest("GET", "/weather?address=Somewhere", nil))
+				codes[i] = w.Code
+			}()
+		}
+
+		// Let every request reach the geocoder before it answers
+		time.Sleep(50 * time.Millisecond)
+		close(geocoder.release)
+		wg.Wait()
+
+		if calls := geocoder.calls.Load(); calls != 1 {
+			t.Errorf("Expected a single geocode, got %d", calls)
+		}
+		for i, code := range codes {
+			if code != http.StatusOK {
+				t.Errorf("Request %d: expected status %d, got %d", i, http.StatusOK, code)
+			}
+		}
+	})
+
+	t.Run("falls back to the next geocoder", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterGeocodeProvider(&stubGeocodeProvider{name: "Broken", err: errors.New("timeout")})
+		pm.RegisterGeocodeProvider(&stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}})
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+		req := httptest.NewRequest("GET", "/weather?address=somewhere", nil)
+		w := httptest.NewRecorder()
+
+		_ = controller.GetByAddress(context.Background(), w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+		}
+	})
+
+	t.Run("uses the geocoder named by the request", func(t *testing.T) {
+		census := &stubGeocodeProvider{name: "Census", places: []*models.Place{newTestPlace()}}
+		google := &stubGeocodeProvider{name: "Google", places: []*models.Place{newTestPlace()}}
+		pm := providers.NewProviderManager()
+		pm.RegisterGeocodeProvider(census)
+		pm.RegisterGeocodeProvider(google)
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+
+		controller := NewHTTPWeatherController(pm, nil, newMemoryCache(), nil)
+		get := func(query string) int {
+			w := httptest.NewRecorder()
+			_ = controller.GetByAddress(context.Background(), w, httptest.NewRequest("GET", "/weather?address=somewhere"+query, nil))
+			return w.Code
+		}
+
+		if code := get("&geocoder=google"); code != http.StatusOK || google.calls != 1 || census.calls != 0 {
+			t.Errorf("Expected only Google to geocode, got status %d and %d Google, %d Census calls", code, google.calls, census.calls)
+		}
+		if code := get(""); code != http.StatusOK || census.calls != 1 {
+			t.Errorf("Expected the default order not to reuse Google's cached answer, got status %d and %d Census calls", code, census.calls)
+		}
+		if code := get("&geocoder=Mapbox"); code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for an unknown geocoder, got %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("error statuses", func(t *testing.T) {
+		foreign := newTestPlace()
+		foreign.CountryCode = "NO"
+
+		tests := []struct {
+			name     string
+			geocoder *stubGeocodeProvider
+			expected int
+		}{
+			{"no match", &stubGeocodeProvider{name: "Census"}, http.StatusNotFound},
+			{"geocoder failure", &stubGeocodeProvider{name: "Census", err: errors.New("boom")}, http.StatusBadGateway},
+			{"unsupported region", &stubGeocodeProvider{name: "Census", places: []*models.Place{foreign}}, http.StatusUnprocessableEntity},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				pm := providers.NewProviderManager()
+				pm.RegisterGeocodeProvider(test.geocoder)
+				pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+
+				controller := NewHTTPWeatherController(pm, nil, nil, nil)
+				req := httptest.NewRequest("GET", "/weather?address=somewhere", nil)
+				w := httptest.NewRecorder()
+
+				_ = controller.GetByAddress(context.Background(), w, req)
+
+				if w.Code != test.expected {
+					t.Errorf("Expected status %d, got %d", test.expected, w.Code)
+				}
+			})
+		}
+	})
+}
+
+func TestWeatherController_GetHourlyForecast(t *testing.T) {
+	get := func(pm *providers.ProviderManager, query string) *httptest.ResponseRecorder {
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+		w := httptest.NewRecorder()
+		_ = controller.GetHourlyForecast(context.Background(), w, httptest.NewRequest("GET", "/forecasts/hourly?"+query, nil))
+		return w
+	}
+
+	t.Run("uses the first provider with hourly forecasts", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "Met.no", regions: []string{providers.GlobalRegion}})
+		pm.RegisterWeatherProvider(&stubHourlyProvider{err: fmt.Errorf("%w: outside the grid", providers.ErrUnsupportedRegion)})
+		pm.RegisterWeatherProvider(&stubHourlyProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}})
+
+		w := get(pm, "lat=39.05&lon=-76.64&hours=3&units=imperial")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response HourlyForecastResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Provider != "NWS" || len(response.Hours) != 3 {
+			t.Fatalf("Expected 3 hours from NWS, got %d from %q", len(response.Hours), response.Provider)
+		}
+		first := response.Hours[0]
+		if first.Temperature != 68 || first.Dewpoint == nil || *first.Dewpoint != 50 {
+			t.Errorf("Expected temperature and dewpoint in Fahrenheit, got %v and %v", first.Temperature, first.Dewpoint)
+		}
+		if first.PrecipitationProbability == nil || *first.PrecipitationProbability != 40 {
+			t.Errorf("Expected a 40%% probability of precipitation, got %v", first.PrecipitationProbability)
+		}
+		if response.Hours[1].PrecipitationProbability != nil {
+			t.Errorf("Expected a null probability when not reported")
+		}
+		if got := w.Header().Get("Cache-Control"); got != "public, max-age=1800" {
+			t.Errorf("Expected the nearest-hour forecast lifetime, got %q", got)
+		}
+	})
+
+	t.Run("defaults and caps hours", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubHourlyProvider{stubWeatherProvider: stubWeatherProvider{name: "NWS"}})
+		for query, want := range map[string]int{"": defaultForecastHours, "&hours=1000": maxForecastHours} {
+			var response HourlyForecastResponse
+			if err := json.NewDecoder(get(pm, "lat=39.05&lon=-76.64"+query).Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(response.Hours) != want {
+				t.Errorf("Expected %d hours for %q, got %d", want, query, len(response.Hours))
+			}
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		for _, query := range []string{"lat=91&lon=0", "lon=0", "lat=39.05&lon=-76.64&hours=0", "lat=39.05&lon=-76.64&hours=x"} {
+			if w := get(pm, query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, query, w.Code)
+			}
+		}
+	})
+
+	t.Run("no provider covers the point", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "Met.no"})
+		if w := get(pm, "lat=59.91&lon=10.75"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+		}
+	})
+}
+
+func TestWeatherController_GetNowcast(t *testing.T) {
+	get := func(pm *providers.ProviderManager, query string) *httptest.ResponseRecorder {
+		controller := NewHTTPWeatherController(pm, nil, nil, nil)
+		w := httptest.NewRecorder()
+		_ = controller.GetNowcast(context.Background(), w, httptest.NewRequest("GET", "/nowcast?"+query, nil))
+		return w
+	}
+
+	t.Run("uses the first provider with nowcasts", func(t *testing.T) {
+		nowcaster := &stubNowcastProvider{stubWeatherProvider: stubWeatherProvider{name: "Tomorrow.io"}}
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS", regions: []string{"US"}})
+		pm.RegisterWeatherProvider(&stubNowcastProvider{err: fmt.Errorf("%w: outside the radar", providers.ErrUnsupportedRegion)})
+		pm.RegisterWeatherProvider(nowcaster)
+
+		w := get(pm, "lat=39.05&lon=-76.64")
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+		}
+		var response NowcastResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if nowcaster.minutes != nowcastMinutes || response.Provider != "Tomorrow.io" || len(response.Minutes) != nowcastMinutes || response.Units != "mm/h" {
+			t.Fatalf("Expected %d minutes in mm/h from Tomorrow.io, got %d in %q from %q", nowcastMinutes, len(response.Minutes), response.Units, response.Provider)
+		}
+		first := response.Minutes[0]
+		if first.PrecipitationIntensity != 2.54 || first.PrecipitationType != "rain" || first.PrecipitationProbability == nil || *first.PrecipitationProbability != 80 {
+			t.Errorf("Unexpected first minute %+v", first)
+		}
+		if response.Minutes[1].PrecipitationProbability != nil || response.Minutes[1].PrecipitationType != "" {
+			t.Errorf("Expected a dry minute without a probability, got %+v", response.Minutes[1])
+		}
+		if got := w.Header().Get("Cache-Control"); got != "public, max-age=300" {
+			t.Errorf("Expected the current conditions lifetime, got %q", got)
+		}
+	})
+
+	t.Run("imperial intensities", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubNowcastProvider{stubWeatherProvider: stubWeatherProvider{name: "Tomorrow.io"}})
+		var response NowcastResponse
+		if err := json.NewDecoder(get(pm, "lat=39.05&lon=-76.64&units=imperial").Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Units != "in/h" || response.Minutes[0].PrecipitationIntensity != 0.1 {
+			t.Errorf("Expected 0.1 in/h, got %v %s", response.Minutes[0].PrecipitationIntensity, response.Units)
+		}
+	})
+
+	t.Run("rejects invalid coordinates", func(t *testing.T) {
+		for _, query := range []string{"lat=91&lon=0", "lon=0"} {
+			if w := get(providers.NewProviderManager(), query); w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status %d for %q, got %d", http.StatusBadRequest, query, w.Code)
+			}
+		}
+	})
+
+	t.Run("no provider publishes nowcasts", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubWeatherProvider{name: "NWS"})
+		if w := get(pm, "lat=39.05&lon=-76.64"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d, got %d", http.StatusUnprocessableEntity, w.Code)
+		}
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		pm := providers.NewProviderManager()
+		pm.RegisterWeatherProvider(&stubNowcastProvider{err: &providers.ProviderError{Provider: "Tomorrow.io", Kind: providers.ErrRateLimited}})
+		if w := get(pm, "lat=39.05&lon=-76.64"); w.Code != http.StatusTooManyRequests {
+			t.Errorf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+		}
+	})
+}
+
+func TestWriteProviderError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		status     int
+		retryAfter string
+	}{
+		{"rate limited", &providers.ProviderError{Provider: "NWS", Kind: providers.ErrRateLimited, RetryAfter: 90 * time.Second}, http.StatusTooManyRequests, "90"},
+		{"unsupported region", &providers.ProviderError{Provider: "NWS", Kind: providers.ErrUnsupportedRegion}, http.StatusUnprocessableEntity, ""},
+		{"bad coordinates", &providers.ProviderError{Provider: "NWS", Kind: providers.ErrBadCoordinates}, http.StatusUnprocessableEntity, ""},
+		{"unavailable", fmt.Errorf("failed to get forecast: %w", &providers.ProviderError{Provider: "NWS", Kind: providers.ErrUpstreamUnavailable}), http.StatusServiceUnavailable, "30"},
+		{"deadline", context.DeadlineExceeded, http.StatusGatewayTimeout, ""},
+		{"unclassified", errors.New("boom"), http.StatusBadGateway, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			_ = writeProviderError(w, "Failed", tt.err)
+
+			if w.Code != tt.status {
+				t.Errorf("Expected status %d, got %d", tt.status, w.Code)
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("Expected Retry-After %q, got %q", tt.retryAfter, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+const (
+	defaultWindRoseDays = 30
+	maxWindRoseDays     = 365
+)
+
+// windRoseDirections names the sectors of a wind rose clockwise from north
+var windRoseDirections = [repo.WindRoseSectors]string{
+	"N", "NNE", "NE", "ENE", "E", "ESE", "SE", "SSE",
+	"S", "SSW", "SW", "WSW", "W", "WNW", "NW", "NNW",
+}
+
+// WindRoseResponse is the distribution of a city's forecast winds by direction
+type WindRoseResponse struct {
+	CityID    int              `json:"city_id"`
+	Start     string           `json:"start"`
+	End       string           `json:"end"`
+	WindUnits string           `json:"wind_units"`
+	Total     int              `json:"total"`
+	Calm      int              `json:"calm"`
+	Sectors   []WindRoseSector `json:"sectors"`
+}
+
+// WindRoseSector is one 22.5° direction bin. Frequency is the percentage of all
+// hours in the window, calm ones included, so the sectors and calm sum to 100.
+type WindRoseSector struct {
+	Direction string  `json:"direction"`
+	Degrees   float64 `json:"degrees"`
+	Count     int     `json:"count"`
+	Frequency float64 `json:"frequency"`
+	MeanSpeed float64 `json:"mean_speed"`
+}
+
+// GetWindRose handles GET /cities/{id}/wind-rose?days= requests, binning the stored
+// forecasts valid over the last days (30 by default) into 16 compass sectors
+func (c *HTTPForecastController) GetWindRose(ctx context.Context, w http.ResponseWriter, r *http.Request, cityID int) error {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int("city_id", cityID))
+
+	opts, err := requestUnits(w, r)
+	if err != nil {
+		return writeError(w, http.StatusBadRequest, "Invalid parameter", err.Error())
+	}
+
+	days, err := strconv.Atoi(r.URL.Query().Get("days"))
+	if err != nil || days <= 0 {
+		days = defaultWindRoseDays
+	}
+	if days > maxWindRoseDays {
+		days = maxWindRoseDays
+	}
+
+	end := time.Now().UTC().Truncate(time.Hour).Add(time.Hour)
+	start := end.AddDate(0, 0, -days)
+	rose, err := c.repo.WindRose(ctx, cityID, start.Format(time.RFC3339), end.Format(time.RFC3339))
+	if err != nil {
+		return writeError(w, http.StatusInternalServerError, "Failed to retrieve wind rose", err.Error())
+	}
+
+	return writeJSON(w, http.StatusOK, fromRepoWindRose(rose, cityID, start, end, opts))
+}
+
+func fromRepoWindRose(rose *repo.WindRose, cityID int, start, end time.Time, opts unitOptions) *WindRoseResponse {
+	response := &WindRoseResponse{
+		CityID:    cityID,
+		Start:     start.Format(time.RFC3339),
+		End:       end.Format(time.RFC3339),
+		WindUnits: string(opts.Wind),
+		Total:     rose.Total,
+		Calm:      rose.Calm,
+		Sectors:   make([]WindRoseSector, 0, repo.WindRoseSectors),
+	}
+	for i, sector := range rose.Sectors {
+		out := WindRoseSector{
+			Direction: windRoseDirections[i],
+			Degrees:   float64(i) * 360 / repo.WindRoseSectors,
+			Count:     sector.Count,
+			MeanSpeed: units.Round(sector.MeanSpeed, 1),
+		}
+		if rose.Total > 0 {
+			out.Frequency = units.Round(float64(sector.Count)*100/float64(rose.Total), 1)
+		}
+		if opts.Wind != units.MetersPerSecond {
+			out.MeanSpeed = units.ConvertWindSpeed(sector.MeanSpeed, opts.Wind)
+		}
+		response.Sectors = append(response.Sectors, out)
+	}
+	return response
+}
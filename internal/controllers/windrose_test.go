@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestHTTPForecastController_GetWindRose(t *testing.T) {
+	mockRepo := &MockForecastRepository{forecasts: []*repo.Forecast{
+		{CityID: 7, WindSpeed: 4, WindDirection: 2},
+		{CityID: 7, WindSpeed: 6, WindDirection: 358},
+		{CityID: 7, WindSpeed: 10, WindDirection: 225},
+		{CityID: 7, WindSpeed: 0.1, WindDirection: 90},
+		{CityID: 8, WindSpeed: 5, WindDirection: 90},
+	}}
+	controller := NewHTTPForecastController(mockRepo)
+
+	t.Run("bins by sector", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/cities/7/wind-rose?days=7", nil)
+		if err := controller.GetWindRose(context.Background(), w, r, 7); err != nil {
+			t.Fatalf("GetWindRose failed: %v", err)
+		}
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var response WindRoseResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.Total != 4 || response.Calm != 1 || len(response.Sectors) != 16 {
+			t.Fatalf("Expected 4 hours, 1 calm and 16 sectors, got %+v", response)
+		}
+		north := response.Sectors[0]
+		if north.Direction != "N" || north.Count != 2 || north.Frequency != 50 || north.MeanSpeed != 5 {
+			t.Errorf("Unexpected north sector %+v", north)
+		}
+		southwest := response.Sectors[10]
+		if southwest.Direction != "SW" || southwest.Degrees != 225 || southwest.Frequency != 25 {
+			t.Errorf("Unexpected southwest sector %+v", southwest)
+		}
+	})
+
+	t.Run("converts wind units", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/cities/7/wind-rose?wind_units=kmh", nil)
+		if err := controller.GetWindRose(context.Background(), w, r, 7); err != nil {
+			t.Fatalf("GetWindRose failed: %v", err)
+		}
+
+		var response WindRoseResponse
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if response.WindUnits != "kmh" || response.Sectors[10].MeanSpeed != 36 {
+			t.Errorf("Expected 36 km/h from the southwest, got %+v", response.Sectors[10])
+		}
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/cities/7/wind-rose", nil)
+		errorController := NewHTTPForecastController(&MockForecastRepository{shouldError: true, errorMsg: "database error"})
+		if err := errorController.GetWindRose(context.Background(), w, r, 7); err != nil {
+			t.Fatalf("GetWindRose failed: %v", err)
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
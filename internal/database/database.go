@@ -0,0 +1,206 @@
+// Package database opens the PostgreSQL connection pool shared by the repositories.
+//
+// Connections are managed by pgxpool and exposed through database/sql (see
+// stdlib.OpenDBFromPool), so the pool satisfies repo.DB and the backup package while
+// its size, lifetimes and health checks are configured in one place.
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Config configures the connection pool. Zero values keep the pgxpool defaults.
+type Config struct {
+	// URL is the PostgreSQL connection string (DATABASE_URL)
+	URL string
+
+	// ReplicaURLs are the connection strings of read replicas (DATABASE_REPLICA_URLS),
+	// each opened with the same pool settings
+	ReplicaURLs []string
+
+	// ReplicaCooldown is how long a replica that failed is left out of reads
+	ReplicaCooldown time.Duration
+
+	// MaxConns is the most connections open at once
+	MaxConns int32
+
+	// MinConns is the number of idle connections kept open
+	MinConns int32
+
+	// MaxConnLifetime is the longest a connection is reused before it is replaced
+	MaxConnLifetime time.Duration
+
+	// MaxConnIdleTime is the longest an idle connection is kept open
+	MaxConnIdleTime time.Duration
+
+	// HealthCheckPeriod is how often idle connections are checked and replaced
+	HealthCheckPeriod time.Duration
+
+	// ConnectTimeout bounds establishing a connection
+	ConnectTimeout time.Duration
+
+	// StatementTimeout is the PostgreSQL statement_timeout of every connection, which
+	// cancels queries running longer on the server
+	StatementTimeout time.Duration
+}
+
+// LoadConfig reads DATABASE_URL, the comma-separated DATABASE_REPLICA_URLS and the pool
+// settings WEATHER_API_DB_MAX_CONNS (default 10), WEATHER_API_DB_MIN_CONNS,
+// WEATHER_API_DB_MAX_CONN_LIFETIME (1h), WEATHER_API_DB_MAX_CONN_IDLE_TIME (30m),
+// WEATHER_API_DB_HEALTH_CHECK_PERIOD (1m), WEATHER_API_DB_CONNECT_TIMEOUT (5s),
+// WEATHER_API_DB_STATEMENT_TIMEOUT (30s, 0 disables it) and
+// WEATHER_API_DB_REPLICA_COOLDOWN (30s)
+func LoadConfig() (Config, error) {
+	config := Config{
+		URL:               os.Getenv("DATABASE_URL"),
+		MaxConns:          10,
+		MaxConnLifetime:   time.Hour,
+		MaxConnIdleTime:   30 * time.Minute,
+		HealthCheckPeriod: time.Minute,
+		ConnectTimeout:    5 * time.Second,
+		StatementTimeout:  30 * time.Second,
+		ReplicaCooldown:   30 * time.Second,
+	}
+	for _, url := range strings.Split(os.Getenv("DATABASE_REPLICA_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			config.ReplicaURLs = append(config.ReplicaURLs, url)
+		}
+	}
+
+	var err error
+	if config.MaxConns, err = envCount("WEATHER_API_DB_MAX_CONNS", config.MaxConns, 1); err != nil {
+		return config, err
+	}
+	if config.MinConns, err = envCount("WEATHER_API_DB_MIN_CONNS", config.MinConns, 0); err != nil {
+		return config, err
+	}
+	if config.MinConns > config.MaxConns {
+		return config, fmt.Errorf("WEATHER_API_DB_MIN_CONNS (%d) must not exceed WEATHER_API_DB_MAX_CONNS (%d)", config.MinConns, config.MaxConns)
+	}
+
+	durations := []struct {
+		name  string
+		value *time.Duration
+	}{
+		{"WEATHER_API_DB_MAX_CONN_LIFETIME", &config.MaxConnLifetime},
+		{"WEATHER_API_DB_MAX_CONN_IDLE_TIME", &config.MaxConnIdleTime},
+		{"WEATHER_API_DB_HEALTH_CHECK_PERIOD", &config.HealthCheckPeriod},
+		{"WEATHER_API_DB_CONNECT_TIMEOUT", &config.ConnectTimeout},
+		{"WEATHER_API_DB_STATEMENT_TIMEOUT", &config.StatementTimeout},
+		{"WEATHER_API_DB_REPLICA_COOLDOWN", &config.ReplicaCooldown},
+	}
+	for _, d := range durations {
+		value := os.Getenv(d.name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.ParseDuration(value)
+		if err != nil || parsed < 0 {
+			return config, fmt.Errorf("invalid %s %q: must be a non-negative duration", d.name, value)
+		}
+		*d.value = parsed
+	}
+	return config, nil
+}
+
+func envCount(name string, fallback, min int32) (int32, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 32)
+	if err != nil || int32(n) < min {
+		return 0, fmt.Errorf("invalid %s %q: must be an integer of at least %d", name, value, min)
+	}
+	return int32(n), nil
+}
+
+// PoolConfig returns the pgxpool configuration for config
+func PoolConfig(config Config) (*pgxpool.Config, error) {
+	if config.URL == "" {
+		return nil, errors.New("DATABASE_URL environment variable is required")
+	}
+	if !strings.HasPrefix(config.URL, "postgres://") && !strings.HasPrefix(config.URL, "postgresql://") {
+		return nil, errors.New("DATABASE_URL must be a valid PostgreSQL connection string")
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DATABASE_URL: %w", err)
+	}
+	if config.MaxConns > 0 {
+		poolConfig.MaxConns = config.MaxConns
+	}
+	if config.MinConns > 0 {
+		poolConfig.MinConns = config.MinConns
+	}
+	if config.MaxConnLifetime > 0 {
+		poolConfig.MaxConnLifetime = config.MaxConnLifetime
+	}
+	if config.MaxConnIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = config.MaxConnIdleTime
+	}
+	if config.HealthCheckPeriod > 0 {
+		poolConfig.HealthCheckPeriod = config.HealthCheckPeriod
+	}
+	if config.ConnectTimeout > 0 {
+		poolConfig.ConnConfig.ConnectTimeout = config.ConnectTimeout
+	}
+	if config.StatementTimeout > 0 {
+		poolConfig.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(config.StatementTimeout.Milliseconds(), 10)
+	}
+	return poolConfig, nil
+}
+
+// Replicas returns the configuration of each read replica: config with the replica's URL
+func (config Config) Replicas() []Config {
+	replicas := make([]Config, len(config.ReplicaURLs))
+	for i, url := range config.ReplicaURLs {
+		replicas[i] = config
+		replicas[i].URL = url
+		replicas[i].ReplicaURLs = nil
+	}
+	return replicas
+}
+
+// Pool is a pgxpool connection pool used through database/sql. It satisfies repo.DB.
+type Pool struct {
+	*sql.DB
+	pool *pgxpool.Pool
+}
+
+// Open creates the connection pool. Connections are opened on first use, so a database
+// that is down does not fail Open; use Ping to check it.
+func Open(ctx context.Context, config Config) (*Pool, error) {
+	poolConfig, err := PoolConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	return &Pool{DB: stdlib.OpenDBFromPool(pool), pool: pool}, nil
+}
+
+// Stats reports the pool's connection counts
+func (p *Pool) Stats() *pgxpool.Stat {
+	return p.pool.Stat()
+}
+
+// Close closes the database/sql handle and then every pooled connection
+func (p *Pool) Close() error {
+	err := p.DB.Close()
+	p.pool.Close()
+	return err
+}
@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// lockPollInterval is how often WithAdvisoryLock retries a lock held elsewhere
+var lockPollInterval = time.Second
+
+// AdvisoryLockKey returns the advisory lock key of name, so processes coordinating
+// through a lock agree on its key by agreeing on its name
+func AdvisoryLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
+
+// WithAdvisoryLock runs fn while holding the PostgreSQL session advisory lock key,
+// retrying until ctx ends while another session holds it. The lock is tried rather than
+// waited on so statement_timeout does not cut the wait short. It is held by one
+// connection for the duration and released when fn returns; a connection that fails to
+// release it is discarded, which ends its session and the lock with it.
+func WithAdvisoryLock(ctx context.Context, db *sql.DB, key int64, fn func() error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire a connection: %w", err)
+	}
+	defer conn.Close()
+
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", key).Scan(&locked); err != nil {
+			return fmt.Errorf("failed to acquire advisory lock %d: %w", key, err)
+		}
+		if locked {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for advisory lock %d: %w", key, ctx.Err())
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	fnErr := fn()
+
+	var unlocked bool
+	if err := conn.QueryRowContext(context.Background(), "SELECT pg_advisory_unlock($1)", key).Scan(&unlocked); err != nil || !unlocked {
+		conn.Raw(func(any) error { return driver.ErrBadConn })
+		if err == nil {
+			err = errors.New("lock was not held")
+		}
+		return errors.Join(fnErr, fmt.Errorf("failed to release advisory lock %d: %w", key, err))
+	}
+	return fnErr
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockDriver is a database/sql driver answering the advisory lock functions from a
+// lock table shared by its connections
+type lockDriver struct {
+	mu    sync.Mutex
+	locks map[int64]*lockConn
+}
+
+func (d *lockDriver) Open(string) (driver.Conn, error) { return &lockConn{driver: d}, nil }
+
+type lockConn struct{ driver *lockDriver }
+
+func (c *lockConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *lockConn) Close() error                        { return nil }
+func (c *lockConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *lockConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	d := c.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := args[0].Value.(int64)
+	owner := d.locks[key]
+	switch {
+	case strings.Contains(query, "pg_try_advisory_lock"):
+		if owner == nil {
+			d.locks[key] = c
+		}
+		return &boolRows{value: owner == nil || owner == c}, nil
+	case strings.Contains(query, "pg_advisory_unlock"):
+		if owner == c {
+			delete(d.locks, key)
+		}
+		return &boolRows{value: owner == c}, nil
+	}
+	return nil, errors.New("unexpected query " + query)
+}
+
+type boolRows struct {
+	value bool
+	done  bool
+}
+
+func (r *boolRows) Columns() []string { return []string{"locked"} }
+func (r *boolRows) Close() error      { return nil }
+func (r *boolRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+func TestWithAdvisoryLock(t *testing.T) {
+	lockPollInterval = 5 * time.Millisecond
+	t.Cleanup(func() { lockPollInterval = time.Second })
+
+	lockDB := &lockDriver{locks: map[int64]*lockConn{}}
+	sql.Register("advisorylock", lockDB)
+	open := func() *sql.DB {
+		db, err := sql.Open("advisorylock", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	}
+	first, second := open(), open()
+	key := AdvisoryLockKey("weather_api test")
+
+	t.Run("Runs holding the lock and releases it", func(t *testing.T) {
+		ran := false
+		err := WithAdvisoryLock(context.Background(), first, key, func() error {
+			ran = true
+			if lockDB.locks[key] == nil {
+				t.Error("Expected the lock to be held while fn runs")
+			}
+			return nil
+		})
+		if err != nil || !ran {
+			t.Fatalf("Expected fn to run, got ran=%v err=%v", ran, err)
+		}
+		if len(lockDB.locks) != 0 {
+			t.Errorf("Expected the lock to be released, got %v", lockDB.locks)
+		}
+	})
+
+	t.Run("Returns the error of fn", func(t *testing.T) {
+		failure := errors.New("migration failed")
+		if err := WithAdvisoryLock(context.Background(), first, key, func() error { return failure }); !errors.Is(err, failure) {
+			t.Errorf("Expected the error of fn, got %v", err)
+		}
+		if len(lockDB.locks) != 0 {
+			t.Errorf("Expected the lock to be released after a failure, got %v", lockDB.locks)
+		}
+	})
+
+	t.Run("Waits for another holder", func(t *testing.T) {
+		holding, release := make(chan struct{}), make(chan struct{})
+		go WithAdvisoryLock(context.Background(), first, key, func() error {
+			close(holding)
+			<-release
+			return nil
+		})
+		<-holding
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		if err := WithAdvisoryLock(ctx, second, key, func() error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected a timeout while the lock is held, got %v", err)
+		}
+
+		time.AfterFunc(20*time.Millisecond, func() { close(release) })
+		ran := false
+		if err := WithAdvisoryLock(context.Background(), second, key, func() error { ran = true; return nil }); err != nil || !ran {
+			t.Errorf("Expected the lock once released, got ran=%v err=%v", ran, err)
+		}
+	})
+}
+
+func TestAdvisoryLockKey(t *testing.T) {
+	if AdvisoryLockKey("a") != AdvisoryLockKey("a") || AdvisoryLockKey("a") == AdvisoryLockKey("b") {
+		t.Error("Expected keys to be stable per name and differ between names")
+	}
+}
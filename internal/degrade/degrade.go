@@ -0,0 +1,320 @@
+// Package degrade keeps selected routes answering while providers or the database are
+// down.
+//
+// Every successful JSON response of a configured route is remembered per path, query,
+// negotiated headers and preferred units. When the route later fails with a server error (5xx), the last good response
+// is served instead, provided it is younger than the route's MaxStale. The replayed
+// object gains "stale": true and "as_of" (when it was produced) so clients can tell it
+// apart from a fresh answer.
+package degrade
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// Route is the degradation policy of one route
+type Route struct {
+	// Path matches a request path exactly, or as a prefix when it ends in "/"
+	Path string
+
+	// MaxStale is the age beyond which a remembered response is no longer served
+	MaxStale time.Duration
+}
+
+// Config lists the routes allowed to serve stale responses
+type Config struct {
+	Routes []Route
+
+	// MaxEntries bounds the remembered responses; the least recently used are
+	// forgotten first
+	MaxEntries int
+}
+
+// DefaultMaxStale is the MaxStale of routes given without one
+const DefaultMaxStale = 24 * time.Hour
+
+// DefaultConfig remembers up to 1000 responses and allows no routes
+func DefaultConfig() Config {
+	return Config{MaxEntries: 1000}
+}
+
+// ParseRoutes parses route specs of the form "path" or "path=maxStale", e.g.
+// "/v1/forecasts/hourly=6h" or "/v1/stations/"
+func ParseRoutes(specs []string) ([]Route, error) {
+	routes := make([]Route, 0, len(specs))
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		path, age, found := strings.Cut(spec, "=")
+		route := Route{Path: path, MaxStale: DefaultMaxStale}
+		if found {
+			maxStale, err := time.ParseDuration(age)
+			if err != nil {
+				return nil, fmt.Errorf("invalid stale route %q: %w", spec, err)
+			}
+			route.MaxStale = maxStale
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// Validate reports malformed routes and a non-positive entry limit
+func (c Config) Validate() error {
+	for _, route := range c.Routes {
+		if !strings.HasPrefix(route.Path, "/") {
+			return fmt.Errorf("stale route path must start with /, got %q", route.Path)
+		}
+		if route.MaxStale <= 0 {
+			return fmt.Errorf("stale route %s: maximum age must be positive, got %s", route.Path, route.MaxStale)
+		}
+	}
+	if c.MaxEntries <= 0 {
+		return fmt.Errorf("stale response limit must be positive, got %d", c.MaxEntries)
+	}
+	return nil
+}
+
+// match returns the route covering path, preferring exact paths and then the longest
+// prefix
+func (c Config) match(path string) (Route, bool) {
+	var best Route
+	found := false
+	for _, route := range c.Routes {
+		if route.Path == path {
+			return route, true
+		}
+		if strings.HasSuffix(route.Path, "/") && strings.HasPrefix(path, route.Path) && len(route.Path) > len(best.Path) {
+			best, found = route, true
+		}
+	}
+	return best, found
+}
+
+// Middleware serves the last good response of a configured GET route when next fails
+// with a 5xx status. Requests to other routes, and event streams, pass through
+// untouched. It must run inside authentication so a caller's preferred units are known.
+func Middleware(config Config, next http.Handler) http.Handler {
+	if len(config.Routes) == 0 {
+		return next
+	}
+	store := newStore(config.MaxEntries)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, ok := config.match(r.URL.Path)
+		if !ok || r.Method != http.MethodGet {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := responseKey(r)
+		buffer := &bufferedWriter{ResponseWriter: w, header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(buffer, r)
+		if buffer.streaming {
+			return
+		}
+
+		body := buffer.body.Bytes()
+		switch {
+		case buffer.status == http.StatusOK && isJSON(buffer.header.Get("Content-Type")) && keyedVary(buffer.header):
+			store.put(key, body, time.Now())
+		case buffer.status >= 500:
+			if entry, ok := store.get(key); ok && time.Since(entry.asOf) <= route.MaxStale {
+				if stale, ok := markStale(entry.body, entry.asOf); ok {
+					writeStale(w, stale, entry.asOf)
+					return
+				}
+			}
+		}
+
+		for name, values := range buffer.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(buffer.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// keyedHeaders are the request headers responses may vary on, content negotiation and
+// localization; each is part of a remembered response's key
+var keyedHeaders = []string{"Accept", "Accept-Language"}
+
+// responseKey identifies the response to r among those remembered: its path and query,
+// its keyedHeaders and the caller's preferred unit system, which overrides the
+// Accept-Language default for authenticated users
+func responseKey(r *http.Request) string {
+	var key strings.Builder
+	key.WriteString(r.URL.Path + "?" + r.URL.Query().Encode())
+	for _, name := range keyedHeaders {
+		key.WriteString("\n" + name + ": " + strings.Join(r.Header.Values(name), ", "))
+	}
+	if system, ok := units.PreferenceFromContext(r.Context()); ok {
+		key.WriteString("\nunits: " + string(system))
+	}
+	return key.String()
+}
+
+// keyedVary reports whether a response varies on keyedHeaders at most. Others, and
+// "Vary: *", are not part of the key, so such responses are not remembered.
+func keyedVary(header http.Header) bool {
+	for _, value := range header.Values("Vary") {
+		for name := range strings.SplitSeq(value, ",") {
+			name = strings.TrimSpace(name)
+			if name != "" && !slices.ContainsFunc(keyedHeaders, func(keyed string) bool { return strings.EqualFold(keyed, name) }) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// writeStale sends a replayed response. It must not be cached downstream, since a
+// fresh answer may be available on the next request.
+func writeStale(w http.ResponseWriter, body []byte, asOf time.Time) {
+	header := w.Header()
+	header.Set("Content-Type", "application/json")
+	header.Set("Cache-Control", "no-store")
+	header.Set("Age", strconv.Itoa(int(time.Since(asOf).Seconds())))
+	header.Set("X-Stale", "true")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(body)
+}
+
+// markStale adds "stale": true and "as_of" to a JSON object body. Other bodies, such
+// as arrays, cannot carry the markers and are not replayed.
+func markStale(body []byte, asOf time.Time) ([]byte, bool) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil || object == nil {
+		return nil, false
+	}
+	object["stale"] = json.RawMessage("true")
+	object["as_of"], _ = json.Marshal(asOf.UTC().Format(time.RFC3339))
+	marked, err := json.Marshal(object)
+	if err != nil {
+		return nil, false
+	}
+	return append(marked, '\n'), true
+}
+
+// isJSON reports whether a Content-Type is JSON, including +json types
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// entry is a remembered response
+type entry struct {
+	key  string
+	body []byte
+	asOf time.Time
+}
+
+// store is a least recently used set of responses
+type store struct {
+	mu      sync.Mutex
+	limit   int
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+func newStore(limit int) *store {
+	return &store{limit: limit, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+func (s *store) get(key string) (*entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return element.Value.(*entry), true
+}
+
+func (s *store) put(key string, body []byte, asOf time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := &entry{key: key, body: bytes.Clone(body), asOf: asOf}
+	if element, ok := s.entries[key]; ok {
+		element.Value = stored
+		s.order.MoveToFront(element)
+		return
+	}
+	s.entries[key] = s.order.PushFront(stored)
+	for s.order.Len() > s.limit {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// bufferedWriter holds a response until its status is known. Event streams cannot
+// wait, so the first Flush sends what was buffered and streams the rest.
+type bufferedWriter struct {
+	http.ResponseWriter
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	streaming   bool
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	if b.streaming {
+		return b.ResponseWriter.Header()
+	}
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.streaming {
+		b.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.ResponseWriter.Write(p)
+	}
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// Flush switches to streaming: the buffered status, headers and body are sent and
+// later writes go straight through
+func (b *bufferedWriter) Flush() {
+	if !b.streaming {
+		b.streaming = true
+		for name, values := range b.header {
+			b.ResponseWriter.Header()[name] = values
+		}
+		b.ResponseWriter.WriteHeader(b.status)
+		_, _ = b.ResponseWriter.Write(b.body.Bytes())
+		b.body.Reset()
+	}
+	if f, ok := b.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (b *bufferedWriter) Unwrap() http.ResponseWriter { return b.ResponseWriter }
@@ -0,0 +1,168 @@
+package degrade
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+func TestParseRoutes(t *testing.T) {
+	routes, err := ParseRoutes([]string{"/v1/forecasts/hourly=6h", " /v1/stations/ ", ""})
+	if err != nil {
+		t.Fatalf("ParseRoutes failed: %v", err)
+	}
+	if len(routes) != 2 || routes[0].MaxStale != 6*time.Hour || routes[1].Path != "/v1/stations/" || routes[1].MaxStale != DefaultMaxStale {
+		t.Errorf("Unexpected routes %+v", routes)
+	}
+	if _, err := ParseRoutes([]string{"/v1/air-quality=soon"}); err == nil {
+		t.Error("Expected an invalid duration to be rejected")
+	}
+
+	for _, config := range []Config{
+		{Routes: []Route{{Path: "v1/air-quality", MaxStale: time.Hour}}, MaxEntries: 10},
+		{Routes: []Route{{Path: "/v1/air-quality", MaxStale: 0}}, MaxEntries: 10},
+		{MaxEntries: 0},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", config)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	failing := false
+	calls := 0
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error": "Failed to retrieve air quality"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"data": {"aqi": %d}}`, calls)
+	})
+	config := DefaultConfig()
+	config.Routes = []Route{{Path: "/v1/air-quality", MaxStale: time.Hour}, {Path: "/v1/stations/", MaxStale: time.Hour}}
+	handler := Middleware(config, next)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+		return w
+	}
+
+	if w := get("/v1/air-quality?lon=-74&lat=40.7"); w.Code != http.StatusOK || w.Header().Get("X-Stale") != "" {
+		t.Fatalf("Expected a fresh response, got %d %v", w.Code, w.Header())
+	}
+
+	failing = true
+	w := get("/v1/air-quality?lat=40.7&lon=-74")
+	if w.Code != http.StatusOK || w.Header().Get("X-Stale") != "true" || w.Header().Get("Cache-Control") != "no-store" {
+		t.Fatalf("Expected a stale response, got %d %v", w.Code, w.Header())
+	}
+	var response struct {
+		Data  map[string]int `json:"data"`
+		Stale bool           `json:"stale"`
+		AsOf  time.Time      `json:"as_of"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", w.Body.String(), err)
+	}
+	if !response.Stale || response.Data["aqi"] != 1 || time.Since(response.AsOf) > time.Minute {
+		t.Errorf("Expected the first response marked stale, got %s", w.Body.String())
+	}
+
+	if w := get("/v1/air-quality?lat=51.5&lon=0"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the error without a remembered response, got %d", w.Code)
+	}
+	if w := get("/v1/stations/KNYC"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected the error without a remembered response, got %d", w.Code)
+	}
+
+	t.Run("too old", func(t *testing.T) {
+		config.Routes[0].MaxStale = time.Nanosecond
+		failing = false
+		handler = Middleware(config, next)
+		get("/v1/air-quality?lat=40.7&lon=-74")
+		failing = true
+		time.Sleep(time.Millisecond)
+		if w := get("/v1/air-quality?lat=40.7&lon=-74"); w.Code != http.StatusServiceUnavailable {
+			t.Errorf("Expected the error once the response is too old, got %d", w.Code)
+		}
+	})
+
+	t.Run("keyed by negotiated headers and units", func(t *testing.T) {
+		vary := "Accept-Language"
+		failing = false
+		handler = Middleware(config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", vary)
+			next(w, r)
+		}))
+		request := func(language string, system units.System) *http.Request {
+			r := httptest.NewRequest("GET", "/v1/stations/KNYC", nil)
+			r.Header.Set("Accept-Language", language)
+			if system != "" {
+				r = r.WithContext(units.WithPreference(r.Context(), system))
+			}
+			return r
+		}
+		serve := func(r *http.Request) int {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			return w.Code
+		}
+
+		serve(request("en-US", ""))
+		vary = "Accept-Language, Authorization"
+		serve(request("de-DE", ""))
+		failing = true
+		if code := serve(request("en-US", "")); code != http.StatusOK {
+			t.Errorf("Expected the remembered en-US response, got %d", code)
+		}
+		if code := serve(request("en-US", units.Metric)); code != http.StatusServiceUnavailable {
+			t.Errorf("Expected no response remembered for metric users, got %d", code)
+		}
+		if code := serve(request("de-DE", "")); code != http.StatusServiceUnavailable {
+			t.Errorf("Expected responses varying on other headers not to be remembered, got %d", code)
+		}
+	})
+
+	t.Run("other routes pass through", func(t *testing.T) {
+		calls := 0
+		untouched := Middleware(config, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		for _, target := range []string{"/v1/cities", "/v1/stations"} {
+			w := httptest.NewRecorder()
+			untouched.ServeHTTP(w, httptest.NewRequest("GET", target, nil))
+			if w.Code != http.StatusInternalServerError {
+				t.Errorf("Expected %s to pass through, got %d", target, w.Code)
+			}
+		}
+		if calls != 2 {
+			t.Errorf("Expected 2 calls, got %d", calls)
+		}
+	})
+}
+
+func TestStore(t *testing.T) {
+	s := newStore(2)
+	now := time.Now()
+	s.put("a", []byte("1"), now)
+	s.put("b", []byte("2"), now)
+	s.get("a")
+	s.put("c", []byte("3"), now)
+	if _, ok := s.get("b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok := s.get("a"); !ok {
+		t.Error("Expected a recently read entry to be kept")
+	}
+}
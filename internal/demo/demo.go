@@ -0,0 +1,300 @@
+// Package demo provides stand-in providers for demo mode: weather from the synthetic
+// generator, geocoding against the seed fixtures and a modeled air quality index. They
+// make no network calls and answer for any location on Earth, so a demo instance works
+// offline and with zero configuration.
+//
+// Answers are deterministic for a location and day: repeated requests agree with each
+// other, and the hourly, daily and current endpoints tell the same story.
+package demo
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/seed"
+	"stormlightlabs.org/weather_api/internal/synth"
+)
+
+// ProviderName is the provider name of every demo provider
+const ProviderName = "Demo"
+
+// advisoryGust is the wind gust (m/s) above which the weather provider issues a wind
+// advisory, so demo users see alerts without waiting for real weather
+const advisoryGust = 15
+
+// maxMatches caps the places returned by a geocode
+const maxMatches = 10
+
+// NewProviders returns a provider manager holding the demo weather, geocode and air
+// quality providers
+func NewProviders() (*providers.ProviderManager, error) {
+	geocoder, err := NewGeocodeProvider()
+	if err != nil {
+		return nil, err
+	}
+	manager := providers.NewProviderManager()
+	manager.RegisterWeatherProvider(NewWeatherProvider())
+	manager.RegisterGeocodeProvider(geocoder)
+	manager.RegisterAirQualityProvider(NewAirQualityProvider())
+	return manager, nil
+}
+
+// WeatherProvider serves synthetic forecasts and observations
+type WeatherProvider struct {
+	now func() time.Time
+}
+
+// NewWeatherProvider creates the demo weather provider
+func NewWeatherProvider() *WeatherProvider {
+	return &WeatherProvider{now: time.Now}
+}
+
+func (w *WeatherProvider) GetName() string {
+	return ProviderName
+}
+
+func (w *WeatherProvider) SupportedRegions() []string {
+	return []string{providers.GlobalRegion}
+}
+
+// hours returns the synthetic forecasts of a location from the start of the current
+// UTC day through the given number of hours past the current hour. The generator is
+// seeded by the location and day, so every call on a day draws the same weather.
+func (w *WeatherProvider) hours(lat, lon float64, ahead int) ([]*models.Forecast, int, error) {
+	if err := geo.Validate(lat, lon); err != nil {
+		return nil, 0, &providers.ProviderError{Provider: ProviderName, Kind: providers.ErrBadCoordinates, Err: err}
+	}
+	now := w.now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	current := int(now.Sub(day) / time.Hour)
+
+	city := &models.City{Latitude: lat, Longitude: lon}
+	forecasts := synth.NewGenerator(locationSeed(lat, lon, day)).Forecasts(city, day, current+ahead)
+	for _, f := range forecasts {
+		f.SourceProvider = ProviderName
+		f.ForecastTime = now.Truncate(time.Hour)
+		f.CreatedAt, f.UpdatedAt = now, now
+	}
+	return forecasts, current, nil
+}
+
+// GetCurrentWeather returns the forecast of the current hour as the observation
+func (w *WeatherProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	forecasts, current, err := w.hours(lat, lon, 1)
+	if err != nil {
+		return nil, err
+	}
+	return forecasts[current], nil
+}
+
+// GetForecast returns hourly forecasts for the given number of days from the current
+// hour
+func (w *WeatherProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	forecasts, current, err := w.hours(lat, lon, max(days, 1)*24)
+	if err != nil {
+		return nil, err
+	}
+	return forecasts[current:], nil
+}
+
+// GetHourlyForecast returns up to hours hourly forecasts from the current hour
+func (w *WeatherProvider) GetHourlyForecast(ctx context.Context, lat, lon float64, hours int) ([]*providers.HourlyForecast, error) {
+	forecasts, current, err := w.hours(lat, lon, hours)
+	if err != nil {
+		return nil, err
+	}
+	hourly := make([]*providers.HourlyForecast, 0, hours)
+	for _, f := range forecasts[current:] {
+		chance := math.Min(100, math.Round(f.CloudCover*0.6+f.Precipitation*20))
+		dewpoint := math.Round((f.Temperature-(100-f.Humidity)/5)*10) / 10
+		hourly = append(hourly, &providers.HourlyForecast{Forecast: *f, PrecipitationProbability: &chance, Dewpoint: &dewpoint})
+	}
+	return hourly, nil
+}
+
+// GetAlerts issues a wind advisory for the first stretch of the next day whose gusts
+// exceed advisoryGust, and nothing otherwise
+func (w *WeatherProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]providers.WeatherAlert, error) {
+	forecasts, current, err := w.hours(lat, lon, 24)
+	if err != nil {
+		return nil, err
+	}
+	var alert *providers.WeatherAlert
+	for _, f := range forecasts[current:] {
+		switch {
+		case f.WindGust >= advisoryGust && alert == nil:
+			alert = &providers.WeatherAlert{
+				ID:          fmt.Sprintf("demo-wind-%s-%s", geo.FormatPoint(lat, lon, 2), f.ValidTime.Format("2006010215")),
+				Title:       "Wind Advisory",
+				Description: fmt.Sprintf("Gusts up to %.0f m/s expected. This is a demo alert generated from synthetic data.", f.WindGust),
+				Severity:    "Moderate",
+				Urgency:     "Expected",
+				Category:    "Met",
+				StartTime:   f.ValidTime,
+				EndTime:     f.ValidTime.Add(time.Hour),
+				Areas:       []string{geo.FormatPoint(lat, lon, 2)},
+			}
+		case f.WindGust >= advisoryGust:
+			alert.EndTime = f.ValidTime.Add(time.Hour)
+		case alert != nil:
+			return []providers.WeatherAlert{*alert}, nil
+		}
+	}
+	if alert == nil {
+		return []providers.WeatherAlert{}, nil
+	}
+	return []providers.WeatherAlert{*alert}, nil
+}
+
+// GeocodeProvider resolves names and coordinates against the seeded cities and places
+type GeocodeProvider struct {
+	places []*models.Place
+}
+
+// NewGeocodeProvider creates the demo geocoder from the seed fixtures. Cities are
+// geocodable by name alongside the fixture places.
+func NewGeocodeProvider() (*GeocodeProvider, error) {
+	places, err := seed.Places()
+	if err != nil {
+		return nil, err
+	}
+	cities, err := seed.Cities()
+	if err != nil {
+		return nil, err
+	}
+	for _, city := range cities {
+		places = append(places, &models.Place{
+			DisplayName:   strings.Join(slices.DeleteFunc([]string{city.Name, city.Region, city.Country}, func(s string) bool { return s == "" }), ", "),
+			City:          city.Name,
+			Region:        city.Region,
+			Country:       city.Country,
+			CountryCode:   city.CountryCode,
+			Latitude:      city.Latitude,
+			Longitude:     city.Longitude,
+			PlaceType:     "city",
+			Confidence:    1,
+			SourcePlaceID: fmt.Sprintf("geonames:%d", city.GeonameID),
+		})
+	}
+	for _, place := range places {
+		place.Source = ProviderName
+	}
+	return &GeocodeProvider{places: places}, nil
+}
+
+func (g *GeocodeProvider) GetName() string {
+	return ProviderName
+}
+
+func (g *GeocodeProvider) SupportedRegions() []string {
+	return []string{providers.GlobalRegion}
+}
+
+// GeocodeAddress returns the places whose name contains the address, ignoring case,
+// best matches first: exact names, then names starting with the address
+func (g *GeocodeProvider) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	query := strings.ToLower(strings.TrimSpace(address))
+	if query == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	var matches []*models.Place
+	for _, place := range g.places {
+		if strings.Contains(strings.ToLower(place.DisplayName), query) {
+			matches = append(matches, place)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no geocoding results found for address: %s", address)
+	}
+	rank := func(p *models.Place) int {
+		name := strings.ToLower(p.DisplayName)
+		switch {
+		case name == query || strings.ToLower(p.City) == query:
+			return 0
+		case strings.HasPrefix(name, query):
+			return 1
+		}
+		return 2
+	}
+	slices.SortStableFunc(matches, func(a, b *models.Place) int { return rank(a) - rank(b) })
+
+	results := make([]*models.Place, 0, min(len(matches), maxMatches))
+	for _, place := range matches[:min(len(matches), maxMatches)] {
+		copied := *place
+		results = append(results, &copied)
+	}
+	return results, nil
+}
+
+// ReverseGeocode returns the nearest seeded place
+func (g *GeocodeProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	if err := geo.Validate(lat, lon); err != nil {
+		return nil, &providers.ProviderError{Provider: ProviderName, Kind: providers.ErrBadCoordinates, Err: err}
+	}
+	nearest := slices.MinFunc(g.places, func(a, b *models.Place) int {
+		return cmp.Compare(geo.DistanceKm(lat, lon, a.Latitude, a.Longitude), geo.DistanceKm(lat, lon, b.Latitude, b.Longitude))
+	})
+	copied := *nearest
+	return &copied, nil
+}
+
+// AirQualityProvider models the air quality index from the location and hour: cities
+// near the equator and mid-latitude afternoons get more ozone, and particulates vary by
+// location
+type AirQualityProvider struct {
+	now func() time.Time
+}
+
+// NewAirQualityProvider creates the demo air quality provider
+func NewAirQualityProvider() *AirQualityProvider {
+	return &AirQualityProvider{now: time.Now}
+}
+
+func (a *AirQualityProvider) GetName() string {
+	return ProviderName
+}
+
+// GetAirQuality returns the modeled readings of the current hour
+func (a *AirQualityProvider) GetAirQuality(ctx context.Context, lat, lon float64) (*models.AirQuality, error) {
+	if err := geo.Validate(lat, lon); err != nil {
+		return nil, &providers.ProviderError{Provider: ProviderName, Kind: providers.ErrBadCoordinates, Err: err}
+	}
+	now := a.now().UTC()
+	hour := now.Truncate(time.Hour)
+	variation := float64(locationSeed(lat, lon, hour)%1000) / 1000
+
+	pm25 := math.Round((5+20*variation)*10) / 10
+	pm10 := math.Round(pm25*1.6*10) / 10
+	solar := math.Max(0, math.Cos(2*math.Pi*(float64(hour.Hour())+lon/15-14)/24))
+	ozone := math.Round((40+50*solar*math.Cos(lat*math.Pi/180))*10) / 10
+
+	return &models.AirQuality{
+		Latitude:       lat,
+		Longitude:      lon,
+		SourceProvider: ProviderName,
+		ObservedAt:     hour,
+		AQI:            int(math.Round(max(pm25*50/12, pm10*50/54, ozone*50/108))),
+		PM25:           &pm25,
+		PM10:           &pm10,
+		Ozone:          &ozone,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// locationSeed hashes a location, to about a kilometer, and a time into a generator seed
+func locationSeed(lat, lon float64, at time.Time) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s|%d", geo.FormatPoint(lat, lon, 2), at.Unix())
+	return h.Sum64()
+}
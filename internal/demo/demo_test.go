@@ -0,0 +1,135 @@
+package demo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/providers"
+)
+
+// fixedNow is the clock of the tests, mid-afternoon UTC
+var fixedNow = time.Date(2025, 8, 14, 15, 30, 0, 0, time.UTC)
+
+func TestWeatherProvider(t *testing.T) {
+	w := NewWeatherProvider()
+	w.now = func() time.Time { return fixedNow }
+	ctx := context.Background()
+
+	current, err := w.GetCurrentWeather(ctx, 40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if current.SourceProvider != ProviderName || !current.ValidTime.Equal(fixedNow.Truncate(time.Hour)) {
+		t.Errorf("Expected the current hour from the demo provider, got %s at %s", current.SourceProvider, current.ValidTime)
+	}
+
+	forecasts, err := w.GetForecast(ctx, 40.71, -74.01, 2)
+	if err != nil {
+		t.Fatalf("GetForecast failed: %v", err)
+	}
+	if len(forecasts) != 48 || forecasts[0].Temperature != current.Temperature {
+		t.Errorf("Expected 48 hours starting with the current conditions, got %d starting at %v", len(forecasts), forecasts[0].Temperature)
+	}
+
+	hourly, err := w.GetHourlyForecast(ctx, 40.71, -74.01, 6)
+	if err != nil {
+		t.Fatalf("GetHourlyForecast failed: %v", err)
+	}
+	if len(hourly) != 6 || hourly[0].Temperature != current.Temperature || hourly[0].PrecipitationProbability == nil || hourly[0].Dewpoint == nil {
+		t.Errorf("Expected 6 hours agreeing with the current conditions, got %d", len(hourly))
+	}
+
+	again, _ := w.GetForecast(ctx, 40.71, -74.01, 2)
+	if again[10].Temperature != forecasts[10].Temperature {
+		t.Error("Expected repeated requests to agree")
+	}
+	other, _ := w.GetForecast(ctx, 51.51, -0.13, 2)
+	if other[0].Temperature == forecasts[0].Temperature && other[0].Pressure == forecasts[0].Pressure {
+		t.Error("Expected other locations to get other weather")
+	}
+
+	if _, err := w.GetCurrentWeather(ctx, 91, 0); !errors.Is(err, providers.ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates, got %v", err)
+	}
+}
+
+func TestWeatherProviderAlerts(t *testing.T) {
+	w := NewWeatherProvider()
+	w.now = func() time.Time { return fixedNow }
+
+	// Scan locations until the synthetic weather produces an advisory
+	for lat := -60.0; lat <= 60; lat += 2.5 {
+		alerts, err := w.GetAlerts(context.Background(), lat, 10)
+		if err != nil {
+			t.Fatalf("GetAlerts failed: %v", err)
+		}
+		if len(alerts) == 0 {
+			continue
+		}
+		alert := alerts[0]
+		if alert.Title != "Wind Advisory" || !alert.EndTime.After(alert.StartTime) || alert.StartTime.Before(fixedNow.Truncate(time.Hour)) {
+			t.Errorf("Unexpected alert %+v", alert)
+		}
+		return
+	}
+	t.Skip("No location had gusts above the advisory threshold")
+}
+
+func TestGeocodeProvider(t *testing.T) {
+	g, err := NewGeocodeProvider()
+	if err != nil {
+		t.Fatalf("NewGeocodeProvider failed: %v", err)
+	}
+	ctx := context.Background()
+
+	places, err := g.GeocodeAddress(ctx, "new york")
+	if err != nil {
+		t.Fatalf("GeocodeAddress failed: %v", err)
+	}
+	if places[0].City != "New York" || places[0].Source != ProviderName {
+		t.Errorf("Expected New York first, got %+v", places[0])
+	}
+	places[0].DisplayName = "changed"
+	if again, _ := g.GeocodeAddress(ctx, "new york"); again[0].DisplayName == "changed" {
+		t.Error("Expected results to be copies")
+	}
+	if _, err := g.GeocodeAddress(ctx, "Atlantis"); err == nil {
+		t.Error("Expected no results for an unknown place")
+	}
+
+	place, err := g.ReverseGeocode(ctx, 40.7, -74.0)
+	if err != nil {
+		t.Fatalf("ReverseGeocode failed: %v", err)
+	}
+	if place.CountryCode != "US" {
+		t.Errorf("Expected a US place nearest New York, got %+v", place)
+	}
+}
+
+func TestAirQualityProvider(t *testing.T) {
+	a := NewAirQualityProvider()
+	a.now = func() time.Time { return fixedNow }
+
+	reading, err := a.GetAirQuality(context.Background(), 51.51, -0.13)
+	if err != nil {
+		t.Fatalf("GetAirQuality failed: %v", err)
+	}
+	if err := reading.Validate(); err != nil {
+		t.Errorf("Expected a valid reading, got %v", err)
+	}
+	if reading.AQI <= 0 || reading.PM25 == nil || !reading.ObservedAt.Equal(fixedNow.Truncate(time.Hour)) {
+		t.Errorf("Unexpected reading %+v", reading)
+	}
+}
+
+func TestNewProviders(t *testing.T) {
+	manager, err := NewProviders()
+	if err != nil {
+		t.Fatalf("NewProviders failed: %v", err)
+	}
+	if manager.GetWeatherProviderForRegion("JP") == nil || len(manager.GetGeocodeProviders()) != 1 || len(manager.GetAirQualityProviders()) != 1 {
+		t.Error("Expected global weather, geocode and air quality providers")
+	}
+}
@@ -0,0 +1,135 @@
+// Package freshness carries how long upstream provider answers stay fresh to the API
+// responses built from them, so CDNs and clients cache a forecast for as long as its
+// source does instead of asking again.
+//
+// Controllers attach a Tracker to the context of their provider lookups (see
+// WithTracker). Provider clients pass their responses through a Transport that reads
+// the Cache-Control max-age, Expires, Date and Age headers into the Tracker found in
+// the request context. A response combining several upstream answers is only as fresh
+// as the stalest of them, so the Tracker keeps the earliest generation and expiry
+// times. Requests without a Tracker pay only for a context lookup.
+package freshness
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tracker collects the freshness of the upstream responses of one request. It is safe
+// for concurrent use, as providers may be queried in parallel. A nil Tracker records
+// nothing.
+type Tracker struct {
+	mu        sync.Mutex
+	generated time.Time // when the oldest upstream answer was produced
+	expires   time.Time // when the first upstream answer goes stale
+	observed  bool
+}
+
+type contextKey struct{}
+
+// WithTracker returns ctx carrying a new tracker
+func WithTracker(ctx context.Context) (context.Context, *Tracker) {
+	tracker := &Tracker{}
+	return context.WithValue(ctx, contextKey{}, tracker), tracker
+}
+
+// FromContext returns the tracker in ctx, or nil
+func FromContext(ctx context.Context) *Tracker {
+	tracker, _ := ctx.Value(contextKey{}).(*Tracker)
+	return tracker
+}
+
+// Observe records the freshness stated by the headers of an upstream response received
+// at now. Responses stating no lifetime are ignored; no-store and no-cache count as
+// already stale.
+func (t *Tracker) Observe(header http.Header, now time.Time) {
+	if t == nil {
+		return
+	}
+	generated, expires, ok := lifetime(header, now)
+	if !ok {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.observed || generated.Before(t.generated) {
+		t.generated = generated
+	}
+	if !t.observed || expires.Before(t.expires) {
+		t.expires = expires
+	}
+	t.observed = true
+}
+
+// Window returns when the oldest observed answer was produced and when the first one
+// goes stale, or false when no upstream response stated a lifetime
+func (t *Tracker) Window() (generated, expires time.Time, ok bool) {
+	if t == nil {
+		return time.Time{}, time.Time{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.generated, t.expires, t.observed
+}
+
+// lifetime derives when a response was produced upstream, from its Date less its Age,
+// and when it expires, from its max-age (preferred, per RFC 9111 §5.3) or Expires
+func lifetime(header http.Header, now time.Time) (generated, expires time.Time, ok bool) {
+	generated = now
+	if date, err := http.ParseTime(header.Get("Date")); err == nil && !date.After(now) {
+		generated = date
+	}
+	if age, err := strconv.Atoi(strings.TrimSpace(header.Get("Age"))); err == nil && age > 0 {
+		generated = generated.Add(-time.Duration(age) * time.Second)
+	}
+
+	for directive := range strings.SplitSeq(header.Get("Cache-Control"), ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			return generated, generated, true
+		case "max-age", "s-maxage":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				return generated, generated.Add(time.Duration(seconds) * time.Second), true
+			}
+		}
+	}
+	if value := header.Get("Expires"); value != "" {
+		expires, err := http.ParseTime(value)
+		if err != nil {
+			// An invalid Expires means already expired (RFC 9111 §5.3)
+			return generated, generated, true
+		}
+		return generated, expires, true
+	}
+	return time.Time{}, time.Time{}, false
+}
+
+// Transport reports the freshness of successful responses to the request's tracker
+type Transport struct {
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport observing responses for the request's tracker
+func NewTransport(base http.RoundTripper) *Transport {
+	return &Transport{Base: base}
+}
+
+// RoundTrip makes the request and records the freshness of a 200 response
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(req)
+	if tracker := FromContext(req.Context()); tracker != nil && err == nil && resp.StatusCode == http.StatusOK {
+		tracker.Observe(resp.Header, time.Now())
+	}
+	return resp, err
+}
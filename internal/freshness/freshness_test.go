@@ -0,0 +1,71 @@
+package freshness
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLifetime(t *testing.T) {
+	now := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+	date := "Thu, 14 Aug 2025 11:59:00 GMT"
+
+	tests := []struct {
+		name      string
+		header    http.Header
+		generated time.Time
+		expires   time.Time
+		ok        bool
+	}{
+		{"max-age", http.Header{"Date": {date}, "Cache-Control": {"public, max-age=600"}}, now.Add(-time.Minute), now.Add(9 * time.Minute), true},
+		{"max-age over Expires", http.Header{"Cache-Control": {"max-age=60"}, "Expires": {date}}, now, now.Add(time.Minute), true},
+		{"age", http.Header{"Date": {date}, "Age": {"30"}, "Cache-Control": {"s-maxage=120"}}, now.Add(-90 * time.Second), now.Add(30 * time.Second), true},
+		{"expires", http.Header{"Expires": {"Thu, 14 Aug 2025 13:00:00 GMT"}}, now, now.Add(time.Hour), true},
+		{"invalid expires", http.Header{"Expires": {"0"}}, now, now, true},
+		{"no-store", http.Header{"Cache-Control": {"no-store"}, "Expires": {"Thu, 14 Aug 2025 13:00:00 GMT"}}, now, now, true},
+		{"future date", http.Header{"Date": {"Thu, 14 Aug 2025 12:10:00 GMT"}, "Cache-Control": {"max-age=60"}}, now, now.Add(time.Minute), true},
+		{"none", http.Header{"Date": {date}}, time.Time{}, time.Time{}, false},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			generated, expires, ok := lifetime(test.header, now)
+			if ok != test.ok || !generated.Equal(test.generated) || !expires.Equal(test.expires) {
+				t.Errorf("Expected %s to %s (%t), got %s to %s (%t)", test.generated, test.expires, test.ok, generated, expires, ok)
+			}
+		})
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.Header().Set("Cache-Control", "max-age=5")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age="+r.URL.Query().Get("max-age"))
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: NewTransport(nil)}
+
+	ctx, tracker := WithTracker(context.Background())
+	for _, path := range []string{"/?max-age=600", "/?max-age=60", "/missing"} {
+		req, _ := http.NewRequestWithContext(ctx, "GET", server.URL+path, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	generated, expires, ok := tracker.Window()
+	if lifetime := expires.Sub(generated); !ok || lifetime < 59*time.Second || lifetime > 61*time.Second {
+		t.Errorf("Expected the shortest successful lifetime, got %s (%t)", lifetime, ok)
+	}
+
+	if _, _, ok := FromContext(context.Background()).Window(); ok {
+		t.Error("Expected no window without a tracker")
+	}
+}
@@ -0,0 +1,161 @@
+// Package geo validates, normalizes and measures geographic coordinates in decimal
+// degrees (WGS 84), so controllers, providers and repositories share one set of rules.
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// EarthRadiusKm is the mean Earth radius used for distances, matching the PostgreSQL
+// repository queries
+const EarthRadiusKm = 6371.0
+
+// NWSPrecision is the number of decimal places the NWS API accepts in a point; longer
+// coordinates are answered with a redirect
+const NWSPrecision = 4
+
+// MaxPrecision is the finest coordinate precision SetPrecision accepts: 6 decimal
+// places are about 11 cm, already beyond what a phone's GPS resolves
+const MaxPrecision = 6
+
+// precision is the number of decimal places user-supplied coordinates keep, or -1 to
+// keep them as given
+var precision atomic.Int32
+
+func init() {
+	precision.Store(-1)
+}
+
+var (
+	// ErrInvalidLatitude means a latitude is outside [-90, 90] or not a number
+	ErrInvalidLatitude = errors.New("latitude must be between -90 and 90")
+	// ErrInvalidLongitude means a longitude is outside [-180, 180] or not a number
+	ErrInvalidLongitude = errors.New("longitude must be between -180 and 180")
+)
+
+// ValidLatitude reports whether lat is within [-90, 90]
+func ValidLatitude(lat float64) bool {
+	return lat >= -90 && lat <= 90
+}
+
+// ValidLongitude reports whether lon is within [-180, 180]
+func ValidLongitude(lon float64) bool {
+	return lon >= -180 && lon <= 180
+}
+
+// Validate checks that lat and lon are within range, returning ErrInvalidLatitude or
+// ErrInvalidLongitude otherwise
+func Validate(lat, lon float64) error {
+	if !ValidLatitude(lat) {
+		return ErrInvalidLatitude
+	}
+	if !ValidLongitude(lon) {
+		return ErrInvalidLongitude
+	}
+	return nil
+}
+
+// NormalizeLongitude wraps a longitude into [-180, 180), so 190 becomes -170 and 540
+// becomes -180. NaN and infinities are returned unchanged.
+func NormalizeLongitude(lon float64) float64 {
+	if lon >= -180 && lon < 180 {
+		return lon
+	}
+	if math.IsNaN(lon) || math.IsInf(lon, 0) {
+		return lon
+	}
+	wrapped := math.Mod(lon+180, 360)
+	if wrapped < 0 {
+		wrapped += 360
+	}
+	return wrapped - 180
+}
+
+// Normalize wraps the longitude and validates the result. Latitudes are never wrapped:
+// one beyond a pole is an error rather than a point on the other side of the globe.
+func Normalize(lat, lon float64) (float64, float64, error) {
+	lon = NormalizeLongitude(lon)
+	if err := Validate(lat, lon); err != nil {
+		return 0, 0, err
+	}
+	return lat, lon, nil
+}
+
+// Round rounds a coordinate to places decimal places
+func Round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
+
+// FormatPoint renders a point as "lat,lon" with places decimal places, trimming
+// trailing zeros, as used in provider URLs
+func FormatPoint(lat, lon float64, places int) string {
+	format := func(v float64) string {
+		return strconv.FormatFloat(Round(v, places), 'f', -1, 64)
+	}
+	return format(lat) + "," + format(lon)
+}
+
+// SetPrecision sets how many decimal places (0 to MaxPrecision) user-supplied
+// coordinates keep once parsed; a negative value keeps them as given. Rounding at parse
+// time means the precise location never reaches providers, caches, stored rows or logs.
+// 2 places are about 1.1 km and 4 about 11 m.
+func SetPrecision(places int) error {
+	if places > MaxPrecision {
+		return fmt.Errorf("coordinate precision must be at most %d decimal places, got %d", MaxPrecision, places)
+	}
+	precision.Store(int32(max(places, -1)))
+	return nil
+}
+
+// Precision returns the number of decimal places user-supplied coordinates keep, or -1
+// when they are kept as given
+func Precision() int {
+	return int(precision.Load())
+}
+
+// Coarsen rounds a user-supplied point to the precision set with SetPrecision
+func Coarsen(lat, lon float64) (float64, float64) {
+	places := Precision()
+	if places < 0 {
+		return lat, lon
+	}
+	return Round(lat, places), Round(lon, places)
+}
+
+// ParseCoordinates parses and normalizes lat and lon query values, then coarsens them
+// (see SetPrecision). The error names the parameter so it can be returned to clients
+// as is.
+func ParseCoordinates(latStr, lonStr string) (float64, float64, error) {
+	lat, err := strconv.ParseFloat(strings.TrimSpace(latStr), 64)
+	if err != nil || math.IsNaN(lat) {
+		return 0, 0, fmt.Errorf("lat must be a valid float")
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(lonStr), 64)
+	if err != nil || math.IsNaN(lon) {
+		return 0, 0, fmt.Errorf("lon must be a valid float")
+	}
+	lat, lon, err = Normalize(lat, lon)
+	if err != nil {
+		return 0, 0, err
+	}
+	lat, lon = Coarsen(lat, lon)
+	return lat, lon, nil
+}
+
+// DistanceKm returns the great-circle distance between two points using the haversine
+// formula
+func DistanceKm(lat1, lon1, lat2, lon2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Sqrt(a))
+}
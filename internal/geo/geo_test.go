@@ -0,0 +1,104 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestNormalizeLongitude(t *testing.T) {
+	tests := []struct{ in, want float64 }{
+		{0, 0},
+		{-180, -180},
+		{179.5, 179.5},
+		{180, -180},
+		{190, -170},
+		{-190, 170},
+		{540, -180},
+		{-725, -5},
+	}
+	for _, tt := range tests {
+		if got := NormalizeLongitude(tt.in); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("NormalizeLongitude(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	lat, lon, err := Normalize(39.0458, 283.3587)
+	if err != nil || lat != 39.0458 || math.Abs(lon-(-76.6413)) > 1e-9 {
+		t.Errorf("expected 39.0458,-76.6413, got %v,%v (%v)", lat, lon, err)
+	}
+	if _, _, err := Normalize(91, 0); !errors.Is(err, ErrInvalidLatitude) {
+		t.Errorf("expected ErrInvalidLatitude, got %v", err)
+	}
+	if err := Validate(0, math.NaN()); !errors.Is(err, ErrInvalidLongitude) {
+		t.Errorf("expected ErrInvalidLongitude for NaN, got %v", err)
+	}
+}
+
+func TestFormatPoint(t *testing.T) {
+	if got := FormatPoint(39.045812, -76.641309, NWSPrecision); got != "39.0458,-76.6413" {
+		t.Errorf("expected 39.0458,-76.6413, got %s", got)
+	}
+	if got := FormatPoint(10, -5.5, NWSPrecision); got != "10,-5.5" {
+		t.Errorf("expected trailing zeros trimmed, got %s", got)
+	}
+}
+
+func TestParseCoordinates(t *testing.T) {
+	lat, lon, err := ParseCoordinates(" 40.7128", "-74.0060")
+	if err != nil || lat != 40.7128 || lon != -74.006 {
+		t.Errorf("unexpected %v,%v (%v)", lat, lon, err)
+	}
+
+	for _, tt := range []struct{ lat, lon, msg string }{
+		{"abc", "0", "lat must be a valid float"},
+		{"0", "", "lon must be a valid float"},
+		{"NaN", "0", "lat must be a valid float"},
+		{"-95", "0", ErrInvalidLatitude.Error()},
+	} {
+		if _, _, err := ParseCoordinates(tt.lat, tt.lon); err == nil || err.Error() != tt.msg {
+			t.Errorf("ParseCoordinates(%q, %q): expected %q, got %v", tt.lat, tt.lon, tt.msg, err)
+		}
+	}
+}
+
+func TestSetPrecision(t *testing.T) {
+	t.Cleanup(func() { _ = SetPrecision(-1) })
+
+	if err := SetPrecision(2); err != nil {
+		t.Fatalf("SetPrecision failed: %v", err)
+	}
+	lat, lon, err := ParseCoordinates("40.712776", "-74.005974")
+	if err != nil || lat != 40.71 || lon != -74.01 {
+		t.Errorf("expected 40.71,-74.01, got %v,%v (%v)", lat, lon, err)
+	}
+	if lat, lon := Coarsen(89.999, 179.996); lat != 90 || lon != 180 {
+		t.Errorf("expected rounding to stay within range, got %v,%v", lat, lon)
+	}
+
+	if err := SetPrecision(MaxPrecision + 1); err == nil {
+		t.Error("expected precision beyond MaxPrecision to be rejected")
+	}
+	if err := SetPrecision(-5); err != nil || Precision() != -1 {
+		t.Errorf("expected a negative precision to turn coarsening off, got %d (%v)", Precision(), err)
+	}
+	if lat, _, _ := ParseCoordinates("40.712776", "-74.005974"); lat != 40.712776 {
+		t.Errorf("expected coordinates as given, got %v", lat)
+	}
+}
+
+func TestDistanceKm(t *testing.T) {
+	// New York to Los Angeles
+	if d := DistanceKm(40.7128, -74.0060, 34.0522, -118.2437); math.Abs(d-3936) > 5 {
+		t.Errorf("expected about 3936 km, got %f", d)
+	}
+	if d := DistanceKm(10, 20, 10, 20); d != 0 {
+		t.Errorf("expected 0 for the same point, got %f", d)
+	}
+	// Across the antimeridian
+	if d := DistanceKm(0, 179.9, 0, -179.9); d > 23 {
+		t.Errorf("expected about 22 km across the antimeridian, got %f", d)
+	}
+}
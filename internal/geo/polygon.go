@@ -0,0 +1,112 @@
+package geo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrInvalidGeometry means a geometry is not a GeoJSON Polygon or MultiPolygon with
+// closed rings of valid coordinates
+var ErrInvalidGeometry = errors.New("geometry must be a GeoJSON Polygon or MultiPolygon")
+
+// Area is a parsed GeoJSON Polygon or MultiPolygon, the shapes alert areas come in.
+// Each polygon is an outer ring followed by its holes; positions are [lon, lat] as in
+// GeoJSON.
+type Area struct {
+	polygons [][][][2]float64
+}
+
+// ParseArea parses a GeoJSON Polygon or MultiPolygon geometry object
+func ParseArea(data []byte) (*Area, error) {
+	var geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	}
+	if err := json.Unmarshal(data, &geometry); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+	}
+
+	area := &Area{}
+	switch geometry.Type {
+	case "Polygon":
+		var polygon [][][2]float64
+		if err := json.Unmarshal(geometry.Coordinates, &polygon); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+		area.polygons = [][][][2]float64{polygon}
+	case "MultiPolygon":
+		if err := json.Unmarshal(geometry.Coordinates, &area.polygons); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidGeometry, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w, got %q", ErrInvalidGeometry, geometry.Type)
+	}
+
+	if len(area.polygons) == 0 {
+		return nil, fmt.Errorf("%w: no polygons", ErrInvalidGeometry)
+	}
+	for _, polygon := range area.polygons {
+		if len(polygon) == 0 {
+			return nil, fmt.Errorf("%w: polygon without rings", ErrInvalidGeometry)
+		}
+		for _, ring := range polygon {
+			if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+				return nil, fmt.Errorf("%w: rings need 4 or more positions and must be closed", ErrInvalidGeometry)
+			}
+			for _, position := range ring {
+				if !ValidLongitude(position[0]) || !ValidLatitude(position[1]) {
+					return nil, fmt.Errorf("%w: position %v out of range", ErrInvalidGeometry, position)
+				}
+			}
+		}
+	}
+	return area, nil
+}
+
+// Contains reports whether the point lies inside one of the area's polygons and outside
+// its holes. Points on an edge may go either way.
+func (a *Area) Contains(lat, lon float64) bool {
+	for _, polygon := range a.polygons {
+		if !ringContains(polygon[0], lat, lon) {
+			continue
+		}
+		inHole := false
+		for _, hole := range polygon[1:] {
+			if ringContains(hole, lat, lon) {
+				inHole = true
+				break
+			}
+		}
+		if !inHole {
+			return true
+		}
+	}
+	return false
+}
+
+// Bounds returns the area's bounding box
+func (a *Area) Bounds() (minLat, minLon, maxLat, maxLon float64) {
+	minLat, minLon, maxLat, maxLon = math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, polygon := range a.polygons {
+		for _, position := range polygon[0] {
+			minLon, maxLon = math.Min(minLon, position[0]), math.Max(maxLon, position[0])
+			minLat, maxLat = math.Min(minLat, position[1]), math.Max(maxLat, position[1])
+		}
+	}
+	return minLat, minLon, maxLat, maxLon
+}
+
+// ringContains casts a ray from the point and counts the ring edges it crosses
+func ringContains(ring [][2]float64, lat, lon float64) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		lonI, latI := ring[i][0], ring[i][1]
+		lonJ, latJ := ring[j][0], ring[j][1]
+		if (latI > lat) != (latJ > lat) && lon < (lonJ-lonI)*(lat-latI)/(latJ-latI)+lonI {
+			inside = !inside
+		}
+	}
+	return inside
+}
@@ -0,0 +1,55 @@
+package geo
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestArea(t *testing.T) {
+	// A square around Oklahoma City with a hole over the downtown, and a second square
+	// further east
+	area, err := ParseArea([]byte(`{"type": "MultiPolygon", "coordinates": [
+		[[[-98, 35], [-97, 35], [-97, 36], [-98, 36], [-98, 35]],
+		 [[-97.6, 35.4], [-97.4, 35.4], [-97.4, 35.6], [-97.6, 35.6], [-97.6, 35.4]]],
+		[[[-95, 35], [-94, 35], [-94, 36], [-95, 36], [-95, 35]]]
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		lat, lon float64
+		want     bool
+	}{
+		{"inside the first polygon", 35.2, -97.8, true},
+		{"inside the hole", 35.5, -97.5, false},
+		{"inside the second polygon", 35.5, -94.5, true},
+		{"between the polygons", 35.5, -96, false},
+		{"north of both", 37, -97.5, false},
+	}
+	for _, tt := range tests {
+		if got := area.Contains(tt.lat, tt.lon); got != tt.want {
+			t.Errorf("%s: Contains(%v, %v) = %v, want %v", tt.name, tt.lat, tt.lon, got, tt.want)
+		}
+	}
+
+	if minLat, minLon, maxLat, maxLon := area.Bounds(); minLat != 35 || minLon != -98 || maxLat != 36 || maxLon != -94 {
+		t.Errorf("Expected bounds 35,-98 to 36,-94, got %v,%v to %v,%v", minLat, minLon, maxLat, maxLon)
+	}
+}
+
+func TestParseAreaRejects(t *testing.T) {
+	for _, geometry := range []string{
+		`{"type": "Point", "coordinates": [-97, 35]}`,
+		`{"type": "Polygon", "coordinates": []}`,
+		`{"type": "Polygon", "coordinates": [[[-98, 35], [-97, 35], [-97, 36]]]}`,
+		`{"type": "Polygon", "coordinates": [[[-98, 35], [-97, 35], [-97, 36], [-98, 36]]]}`,
+		`{"type": "Polygon", "coordinates": [[[-98, 95], [-97, 35], [-97, 36], [-98, 95]]]}`,
+		`not json`,
+	} {
+		if _, err := ParseArea([]byte(geometry)); !errors.Is(err, ErrInvalidGeometry) {
+			t.Errorf("Expected ErrInvalidGeometry for %s, got %v", geometry, err)
+		}
+	}
+}
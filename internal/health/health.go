@@ -0,0 +1,166 @@
+// Package health serves the liveness and readiness probes used by orchestrators.
+//
+// /healthz only reports that the process is serving HTTP. /readyz probes the
+// dependencies registered as checks (database, cache, upstream providers) and answers
+// 503 when a required one fails, or once the server has started draining for shutdown,
+// so load balancers stop routing to an instance before it stops accepting requests.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Readiness statuses
+const (
+	StatusReady       = "ready"
+	StatusDegraded    = "degraded" // an optional check failed
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+)
+
+// checkTimeout bounds each check of a readiness probe
+const checkTimeout = 2 * time.Second
+
+// Check probes one dependency
+type Check struct {
+	Name string
+
+	// Optional checks are reported but never make the instance unready. Upstream
+	// providers are optional: every instance shares them, so failing readiness would
+	// take the whole fleet out of rotation instead of falling back.
+	Optional bool
+
+	Probe func(ctx context.Context) error
+}
+
+// Result is the outcome of one check
+type Result struct {
+	Status    string `json:"status"` // ok or error
+	Optional  bool   `json:"optional,omitempty"`
+	Error     string `json:"error,omitempty"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the body of a readiness response
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+// Probes serves the liveness and readiness endpoints
+type Probes struct {
+	service  string
+	checks   []Check
+	draining atomic.Bool
+}
+
+// NewProbes creates probes for service running checks on readiness requests
+func NewProbes(service string, checks ...Check) *Probes {
+	return &Probes{service: service, checks: checks}
+}
+
+// Drain makes readiness fail from now on, ahead of a graceful shutdown
+func (p *Probes) Drain() {
+	p.draining.Store(true)
+}
+
+// Live handles GET /healthz
+func (p *Probes) Live(w http.ResponseWriter, r *http.Request) {
+	_ = controllers.WriteJSON(w, http.StatusOK, map[string]string{"status": "ok", "service": p.service})
+}
+
+// Ready handles GET /readyz, answering 503 when a required check fails or the
+// instance is draining
+func (p *Probes) Ready(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "no-store")
+	if p.draining.Load() {
+		_ = controllers.WriteJSON(w, http.StatusServiceUnavailable, &Report{Status: StatusDraining, Checks: map[string]Result{}})
+		return
+	}
+
+	report := p.Run(r.Context())
+	status := http.StatusOK
+	if report.Status == StatusUnavailable {
+		status = http.StatusServiceUnavailable
+	}
+	_ = controllers.WriteJSON(w, status, report)
+}
+
+// Run runs every check concurrently and summarizes the results
+func (p *Probes) Run(ctx context.Context) *Report {
+	report := &Report{Status: StatusReady, Checks: make(map[string]Result, len(p.checks))}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range p.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := run(ctx, check)
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[check.Name] = result
+			if result.Status == "ok" {
+				return
+			}
+			if !check.Optional {
+				report.Status = StatusUnavailable
+			} else if report.Status == StatusReady {
+				report.Status = StatusDegraded
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
+
+func run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Probe(ctx)
+	result := Result{Status: "ok", Optional: check.Optional, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status, result.Error = "error", err.Error()
+	}
+	return result
+}
+
+// Database checks that the storage engine can serve queries
+func Database(engine repo.Engine) Check {
+	return Check{Name: "database", Probe: engine.Ping}
+}
+
+// Cache checks that the cache answers a lookup
+func Cache(cache repo.Cache) Check {
+	return Check{Name: "cache", Probe: func(ctx context.Context) error {
+		_, err := cache.Exists(ctx, "health:ping")
+		return err
+	}}
+}
+
+// Provider checks that an upstream provider is reachable. Provider checks are
+// optional.
+func Provider(name string, probe func(ctx context.Context) error) Check {
+	return Check{Name: "provider:" + name, Optional: true, Probe: probe}
+}
+
+// Providers checks every registered provider that has a health check, reporting the
+// latest result recorded by monitor rather than calling the provider per probe
+func Providers(manager *providers.ProviderManager, monitor *providers.HealthMonitor) []Check {
+	var checks []Check
+	for _, description := range manager.Describe() {
+		if description.Health != nil {
+			checks = append(checks, Provider(description.Name, monitor.Probe(description.Type, description.Name)))
+		}
+	}
+	return checks
+}
@@ -0,0 +1,124 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/demo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func ready(t *testing.T, probes *Probes) (int, *Report) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	probes.Ready(w, httptest.NewRequest("GET", "/readyz", nil))
+	var report Report
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, &report
+}
+
+func TestProbes_Ready(t *testing.T) {
+	engine, _ := repo.OpenFileEngine("")
+	down := func(context.Context) error { return errors.New("connection refused") }
+
+	tests := []struct {
+		name   string
+		checks []Check
+		code   int
+		status string
+	}{
+		{"all ok", []Check{Database(engine), Provider("NWS", func(context.Context) error { return nil })}, http.StatusOK, StatusReady},
+		{"provider down", []Check{Database(engine), Provider("NWS", down)}, http.StatusOK, StatusDegraded},
+		{"database down", []Check{{Name: "database", Probe: down}, Provider("NWS", down)}, http.StatusServiceUnavailable, StatusUnavailable},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, report := ready(t, NewProbes("weather-api", test.checks...))
+			if code != test.code || report.Status != test.status || len(report.Checks) != len(test.checks) {
+				t.Errorf("Expected %d %s, got %d %+v", test.code, test.status, code, report)
+			}
+		})
+	}
+
+	t.Run("check details", func(t *testing.T) {
+		_, report := ready(t, NewProbes("weather-api", Provider("NWS", down)))
+		result := report.Checks["provider:NWS"]
+		if result.Status != "error" || !result.Optional || result.Error != "connection refused" {
+			t.Errorf("Unexpected provider result %+v", result)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		slow := Check{Name: "cache", Probe: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}
+		if code, _ := ready(t, NewProbes("weather-api", slow)); code != http.StatusServiceUnavailable {
+			t.Errorf("Expected a hung check to time out as unavailable, got %d", code)
+		}
+	})
+}
+
+func TestProbes_Drain(t *testing.T) {
+	probes := NewProbes("weather-api")
+	if code, _ := ready(t, probes); code != http.StatusOK {
+		t.Fatalf("Expected ready before draining, got %d", code)
+	}
+
+	probes.Drain()
+	if code, report := ready(t, probes); code != http.StatusServiceUnavailable || report.Status != StatusDraining {
+		t.Errorf("Expected draining to fail readiness, got %d %+v", code, report)
+	}
+
+	w := httptest.NewRecorder()
+	probes.Live(w, httptest.NewRequest("GET", "/healthz", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected liveness to keep passing while draining, got %d", w.Code)
+	}
+}
+
+// checkedAirQuality is an air quality provider whose health check fails
+type checkedAirQuality struct {
+	name string
+}
+
+func (c *checkedAirQuality) GetName() string {
+	return c.name
+}
+
+func (c *checkedAirQuality) GetAirQuality(ctx context.Context, lat, lon float64) (*models.AirQuality, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *checkedAirQuality) HealthCheck(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestProviders(t *testing.T) {
+	manager := providers.NewProviderManager()
+	manager.RegisterAirQualityProvider(&checkedAirQuality{name: "Open-Meteo"})
+	manager.RegisterAirQualityProvider(demo.NewAirQualityProvider())
+	monitor := providers.NewHealthMonitor(manager, time.Minute, time.Second)
+
+	checks := Providers(manager, monitor)
+	if len(checks) != 1 || checks[0].Name != "provider:Open-Meteo" || !checks[0].Optional {
+		t.Fatalf("Expected an optional check of the checkable provider only, got %+v", checks)
+	}
+	code, report := ready(t, NewProbes("weather-api", checks...))
+	if code != http.StatusOK || report.Status != StatusDegraded || report.Checks["provider:Open-Meteo"].Error != "connection refused" {
+		t.Errorf("Expected a degraded report, got %d %+v", code, report)
+	}
+	if status, _ := monitor.Status(providers.TypeAirQuality, "Open-Meteo"); status.Checks != 1 {
+		t.Errorf("Expected the readiness probe to record its check, got %+v", status)
+	}
+}
@@ -0,0 +1,239 @@
+// Package idempotency lets clients retry create requests safely.
+//
+// A client sends the same Idempotency-Key header with every attempt of one request.
+// The first attempt runs and its response is stored with a fingerprint of the request;
+// later attempts with the key get the stored response back, marked with
+// Idempotent-Replayed, instead of creating a second record. Keys are scoped to the
+// caller's credentials and the request path, so two clients picking the same key do not
+// see each other's responses.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/controllers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+const (
+	// Header carries the client's key for a request and its retries
+	Header = "Idempotency-Key"
+
+	// ReplayedHeader is set to "true" on responses replayed from the store
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultTTL is how long a response is kept for replay
+	DefaultTTL = 24 * time.Hour
+
+	// maxKeyLength bounds keys; UUIDs and similar tokens fit comfortably
+	maxKeyLength = 255
+
+	// maxBodyBytes bounds the request bodies fingerprinted
+	maxBodyBytes = 1 << 20
+
+	// pendingTTL bounds how long a key stays locked by an attempt that never finishes;
+	// the lock is renewed at half this interval while the attempt runs
+	pendingTTL = time.Minute
+)
+
+// record is the stored state of a key: pending while its first attempt runs, then the
+// response to replay
+type record struct {
+	Fingerprint string      `json:"fingerprint"`
+	Pending     bool        `json:"pending,omitempty"`
+	Status      int         `json:"status,omitempty"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+}
+
+// Replayer stores the responses of requests carrying an Idempotency-Key and replays
+// them on retries
+type Replayer struct {
+	store      repo.KVStore
+	ttl        time.Duration
+	pendingTTL time.Duration
+}
+
+// NewReplayer creates a replayer keeping responses in store for ttl; non-positive ttls
+// use DefaultTTL. Instances behind a load balancer need a store they share (see
+// repo.NewSharedStore), or a retry reaching another instance runs the request again.
+func NewReplayer(store repo.KVStore, ttl time.Duration) *Replayer {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Replayer{store: store, ttl: ttl, pendingTTL: pendingTTL}
+}
+
+// Wrap makes next idempotent for requests carrying an Idempotency-Key; requests without
+// one run as usual. Retries with a different body get 422, and retries arriving while
+// the first attempt still runs get 409. Server errors are not stored, so a retry after
+// one runs the request again. A nil Replayer returns next unchanged.
+func (p *Replayer) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if p == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(Header))
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			_ = controllers.WriteError(w, http.StatusBadRequest, "Invalid Idempotency-Key", "keys are limited to 255 characters")
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodyBytes+1))
+		if err != nil {
+			_ = controllers.WriteError(w, http.StatusBadRequest, "Failed to read request body", err.Error())
+			return
+		}
+		if len(body) > maxBodyBytes {
+			_ = controllers.WriteError(w, http.StatusRequestEntityTooLarge, "Request body too large", "idempotent requests are limited to 1 MiB")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := r.Context()
+		storeKey := scopedKey(r, key)
+		pending, _ := json.Marshal(record{Fingerprint: fingerprint(r, body), Pending: true})
+		acquired, err := p.store.SetNX(ctx, storeKey, pending, p.pendingTTL)
+		if err != nil {
+			_ = controllers.WriteError(w, http.StatusServiceUnavailable, "Idempotency store unavailable", err.Error())
+			return
+		}
+		if !acquired {
+			p.replay(w, r, storeKey, fingerprint(r, body))
+			return
+		}
+
+		recorder := &recorder{ResponseWriter: w, before: w.Header().Clone(), status: http.StatusOK}
+		release := p.hold(ctx, storeKey, pending)
+		next(recorder, r)
+		release()
+
+		// Store the outcome even if the client went away, so its retry finds it
+		ctx = context.WithoutCancel(ctx)
+		if recorder.status >= http.StatusInternalServerError || recorder.status == http.StatusTooManyRequests {
+			_ = p.store.Delete(ctx, storeKey)
+			return
+		}
+		done, _ := json.Marshal(record{
+			Fingerprint: fingerprint(r, body),
+			Status:      recorder.status,
+			Header:      recorder.header,
+			Body:        recorder.body.Bytes(),
+		})
+		_ = p.store.Set(ctx, storeKey, done, p.ttl)
+	}
+}
+
+// hold renews the pending lock on storeKey until the returned release is called, so a
+// request running longer than pendingTTL keeps its key. Release returns once renewal
+// has stopped.
+func (p *Replayer) hold(ctx context.Context, storeKey string, pending []byte) (release func()) {
+	ctx = context.WithoutCancel(ctx)
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(p.pendingTTL / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				_ = p.store.Set(ctx, storeKey, pending, p.pendingTTL)
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// replay answers a retry from the stored record of its key
+func (p *Replayer) replay(w http.ResponseWriter, r *http.Request, storeKey, fingerprint string) {
+	value, err := p.store.Get(r.Context(), storeKey)
+	if err != nil {
+		_ = controllers.WriteError(w, http.StatusServiceUnavailable, "Idempotency store unavailable", err.Error())
+		return
+	}
+	var stored record
+	if value == nil || json.Unmarshal(value, &stored) != nil || stored.Pending {
+		// A missing value means the first attempt just failed and released the key
+		_ = controllers.WriteError(w, http.StatusConflict, "Request in progress", "a request with this Idempotency-Key is still being processed; retry shortly")
+		return
+	}
+	if stored.Fingerprint != fingerprint {
+		_ = controllers.WriteError(w, http.StatusUnprocessableEntity, "Idempotency-Key reused", "the key was already used for a different request")
+		return
+	}
+
+	maps.Copy(w.Header(), stored.Header)
+	w.Header().Set(ReplayedHeader, "true")
+	w.WriteHeader(stored.Status)
+	_, _ = w.Write(stored.Body)
+}
+
+// scopedKey returns the store key of an Idempotency-Key, scoped to the caller's
+// credentials and the request path
+func scopedKey(r *http.Request, key string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{
+		r.Header.Get("Authorization"), r.Header.Get("X-API-Key"), r.Method, r.URL.Path, key,
+	}, "\x00")))
+	return "idempotency:" + hex.EncodeToString(sum[:])
+}
+
+// fingerprint identifies a request by its query and body, so a key reused for another
+// request is caught
+func fingerprint(r *http.Request, body []byte) string {
+	sum := sha256.Sum256(append([]byte(r.URL.RawQuery+"\x00"), body...))
+	return hex.EncodeToString(sum[:])
+}
+
+// recorder passes a response through while keeping a copy of its status, the headers
+// the handler set and its body
+type recorder struct {
+	http.ResponseWriter
+	before      http.Header
+	header      http.Header
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (r *recorder) WriteHeader(status int) {
+	if r.wroteHeader {
+		return
+	}
+	r.wroteHeader = true
+	r.status = status
+	r.header = http.Header{}
+	for name, values := range r.ResponseWriter.Header() {
+		if !slices.Equal(r.before[name], values) {
+			r.header[name] = slices.Clone(values)
+		}
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *recorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,155 @@
+package idempotency
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestReplayer(t *testing.T) {
+	created := 0
+	status := http.StatusCreated
+	handler := NewReplayer(repo.NewMemoryStore(100), 0).Wrap(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		created++
+		w.Header().Set("Location", fmt.Sprintf("/places/%d", created))
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"id":%d,"name":%q}`, created, body)
+	})
+	send := func(key, body, auth string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/places", strings.NewReader(body))
+		if key != "" {
+			r.Header.Set(Header, key)
+		}
+		if auth != "" {
+			r.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		return w
+	}
+
+	first := send("k1", "Chicago", "alice")
+	retry := send("k1", "Chicago", "alice")
+	if created != 1 {
+		t.Fatalf("Expected the retry to be replayed, but the handler ran %d times", created)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get("Location") != "/places/1" {
+		t.Errorf("Expected the stored response, got %d %s %v", retry.Code, retry.Body.String(), retry.Header())
+	}
+	if retry.Header().Get(ReplayedHeader) != "true" || first.Header().Get(ReplayedHeader) != "" {
+		t.Errorf("Expected only the replay to be marked, got %v and %v", first.Header(), retry.Header())
+	}
+
+	if w := send("k1", "Denver", "alice"); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a key reused with another body, got %d", w.Code)
+	}
+	if send("k1", "Chicago", "bob"); created != 2 {
+		t.Errorf("Expected keys to be scoped to the caller, handler ran %d times", created)
+	}
+	send("", "Chicago", "alice")
+	send("", "Chicago", "alice")
+	if created != 4 {
+		t.Errorf("Expected requests without a key to always run, handler ran %d times", created)
+	}
+
+	status = http.StatusInternalServerError
+	send("k2", "Austin", "alice")
+	status = http.StatusCreated
+	if w := send("k2", "Austin", "alice"); w.Code != http.StatusCreated || created != 6 {
+		t.Errorf("Expected a retry after a server error to run again, got %d after %d runs", w.Code, created)
+	}
+
+	if w := send(strings.Repeat("k", 256), "Austin", "alice"); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for an overlong key, got %d", w.Code)
+	}
+}
+
+func TestReplayer_InProgress(t *testing.T) {
+	store := repo.NewMemoryStore(10)
+	replayer := NewReplayer(store, 0)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := replayer.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	request := func() *http.Request {
+		r := httptest.NewRequest("POST", "/digests", strings.NewReader("{}"))
+		r.Header.Set(Header, "k")
+		return r
+	}
+	done := make(chan struct{})
+	go func() {
+		handler(httptest.NewRecorder(), request())
+		close(done)
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	handler(w, request())
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the first attempt runs, got %d", w.Code)
+	}
+	close(release)
+	<-done
+}
+
+func TestReplayer_LongRequest(t *testing.T) {
+	replayer := NewReplayer(repo.NewMemoryStore(10), 0)
+	replayer.pendingTTL = 20 * time.Millisecond
+	runs := 0
+	release := make(chan struct{})
+	started := make(chan struct{})
+	handler := replayer.Wrap(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		close(started)
+		<-release
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	request := func() *http.Request {
+		r := httptest.NewRequest("POST", "/digests", strings.NewReader("{}"))
+		r.Header.Set(Header, "k")
+		return r
+	}
+	done := make(chan struct{})
+	go func() {
+		handler(httptest.NewRecorder(), request())
+		close(done)
+	}()
+	<-started
+
+	// Well past pendingTTL, the first attempt still holds the key
+	time.Sleep(5 * replayer.pendingTTL)
+	w := httptest.NewRecorder()
+	handler(w, request())
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while a long first attempt runs, got %d", w.Code)
+	}
+	close(release)
+	<-done
+
+	w = httptest.NewRecorder()
+	handler(w, request())
+	if w.Code != http.StatusCreated || w.Header().Get(ReplayedHeader) != "true" || runs != 1 {
+		t.Errorf("Expected the stored response replayed after one run, got %d after %d runs", w.Code, runs)
+	}
+}
+
+func TestReplayer_Nil(t *testing.T) {
+	var replayer *Replayer
+	ran := false
+	replayer.Wrap(func(w http.ResponseWriter, r *http.Request) { ran = true })(httptest.NewRecorder(), httptest.NewRequest("POST", "/", nil))
+	if !ran {
+		t.Error("Expected a nil Replayer to pass requests through")
+	}
+}
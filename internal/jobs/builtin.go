@@ -0,0 +1,241 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Built-in job names
+const (
+	ForecastIngestionJob    = "forecast-ingestion"
+	ForecastRetentionJob    = "forecast-retention"
+	ForecastPartitionJob    = "forecast-partitions"
+	AlertCleanupJob         = "alert-cleanup"
+	ShareCleanupJob         = "share-cleanup"
+	AviationRetentionJob    = "aviation-retention"
+	ObservationRetentionJob = "observation-retention"
+	AirQualityRetentionJob  = "air-quality-retention"
+	DigestDeliveryJob       = "digest-delivery"
+	AlertNotificationJob    = "alert-notification"
+	KVCleanupJob            = "kv-cleanup"
+)
+
+// ingestionPageSize is the number of cities loaded at a time by forecast ingestion
+const ingestionPageSize = 500
+
+// ForecastIngestion fetches a days-long forecast for every active city from the
+// provider covering its country. Cities without a provider, or whose fetch or insert
+// fails, are skipped and named in the run's report.
+func ForecastIngestion(cities repo.CityRepository, forecasts repo.ForecastRepository, manager *providers.ProviderManager, days int, interval time.Duration) Job {
+	return Job{
+		Name:        ForecastIngestionJob,
+		Description: fmt.Sprintf("Fetch %d-day forecasts for every active city", days),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			for offset := 0; ; offset += ingestionPageSize {
+				page, err := cities.List(ctx, ingestionPageSize, offset)
+				if err != nil {
+					return fmt.Errorf("failed to list cities: %w", err)
+				}
+				for _, city := range page {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					item := cityItem(city)
+					if !city.IsActive || !report.Selected(item) {
+						continue
+					}
+					if reason := ingestCity(ctx, forecasts, manager, city, days); reason != "" {
+						report.Skip(item, reason)
+						continue
+					}
+					report.Processed(1)
+				}
+				if len(page) < ingestionPageSize {
+					return nil
+				}
+			}
+		},
+	}
+}
+
+// ingestCity fetches and stores one city's forecast, returning why it was skipped
+func ingestCity(ctx context.Context, forecasts repo.ForecastRepository, manager *providers.ProviderManager, city *repo.City, days int) string {
+	provider := manager.GetWeatherProviderForRegion(city.CountryCode)
+	if provider == nil {
+		return fmt.Sprintf("no weather provider covers %s", city.CountryCode)
+	}
+
+	fetched, err := provider.GetForecast(ctx, city.Latitude, city.Longitude, days)
+	if err != nil {
+		return fmt.Sprintf("%s: %v", provider.GetName(), err)
+	}
+	if len(fetched) == 0 {
+		return fmt.Sprintf("%s returned no forecasts", provider.GetName())
+	}
+
+	batch := make([]*repo.Forecast, 0, len(fetched))
+	for _, f := range fetched {
+		row := toRepoForecast(f)
+		row.CityID = city.ID
+		if row.SourceProvider == "" {
+			row.SourceProvider = provider.GetName()
+		}
+		batch = append(batch, row)
+	}
+	if err := forecasts.CreateBatch(ctx, batch); err != nil {
+		return fmt.Sprintf("failed to store forecasts: %v", err)
+	}
+	return ""
+}
+
+// cityItem names a city in run reports. The ID keeps the name stable for retries even
+// when two cities share a name.
+func cityItem(city *repo.City) string {
+	return fmt.Sprintf("%s, %s (#%d)", city.Name, city.CountryCode, city.ID)
+}
+
+// ForecastRetention deletes forecasts older than days
+func ForecastRetention(forecasts repo.ForecastRepository, days int, interval time.Duration) Job {
+	return Job{
+		Name:        ForecastRetentionJob,
+		Description: fmt.Sprintf("Delete forecasts older than %d days", days),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			deleted, err := forecasts.DeleteOldForecasts(ctx, days, repo.ForecastFilter{})
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// ForecastPartitions creates the monthly forecast partitions of the current month and
+// the months ahead of it, so forecasts land in their month's partition rather than the
+// default one
+func ForecastPartitions(partitioner repo.ForecastPartitioner, monthsAhead int, interval time.Duration) Job {
+	return Job{
+		Name:        ForecastPartitionJob,
+		Description: fmt.Sprintf("Create forecast partitions through %d months ahead", monthsAhead),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			created, err := partitioner.EnsurePartitions(ctx, time.Now(), monthsAhead)
+			report.Processed(created)
+			return err
+		},
+	}
+}
+
+// AlertCleanup deletes alerts whose end time has passed
+func AlertCleanup(alerts repo.AlertRepository, interval time.Duration) Job {
+	return Job{
+		Name:        AlertCleanupJob,
+		Description: "Delete expired alerts",
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			deleted, err := alerts.DeleteExpired(ctx)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// ShareCleanup deletes share links past their expiry
+func ShareCleanup(shares repo.ShareLinkRepository, interval time.Duration) Job {
+	return Job{
+		Name:        ShareCleanupJob,
+		Description: "Delete expired share links",
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			deleted, err := shares.DeleteExpired(ctx)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// KVCleanup deletes the expired keys of a shared store, such as idempotency keys
+func KVCleanup(store repo.SharedStore, interval time.Duration) Job {
+	return Job{
+		Name:        KVCleanupJob,
+		Description: "Delete expired keys of the shared store",
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			deleted, err := store.DeleteExpired(ctx)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// AviationRetention deletes METAR and TAF reports observed more than days ago
+func AviationRetention(aviation repo.AviationReportRepository, days int, interval time.Duration) Job {
+	return Job{
+		Name:        AviationRetentionJob,
+		Description: fmt.Sprintf("Delete METAR/TAF reports older than %d days", days),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(time.RFC3339)
+			deleted, err := aviation.DeleteOlderThan(ctx, cutoff)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// ObservationRetention deletes station observations made more than days ago
+func ObservationRetention(observations repo.ObservationRepository, days int, interval time.Duration) Job {
+	return Job{
+		Name:        ObservationRetentionJob,
+		Description: fmt.Sprintf("Delete station observations older than %d days", days),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(time.RFC3339)
+			deleted, err := observations.DeleteOlderThan(ctx, cutoff)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+// AirQualityRetention deletes air quality readings observed more than days ago
+func AirQualityRetention(readings repo.AirQualityRepository, days int, interval time.Duration) Job {
+	return Job{
+		Name:        AirQualityRetentionJob,
+		Description: fmt.Sprintf("Delete air quality readings older than %d days", days),
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			cutoff := time.Now().UTC().AddDate(0, 0, -days).Format(time.RFC3339)
+			deleted, err := readings.DeleteOlderThan(ctx, cutoff)
+			report.Processed(int(deleted))
+			return err
+		},
+	}
+}
+
+func toRepoForecast(f *models.Forecast) *repo.Forecast {
+	return &repo.Forecast{
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime.UTC().Format(time.RFC3339),
+		ValidTime:       f.ValidTime.UTC().Format(time.RFC3339),
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+	}
+}
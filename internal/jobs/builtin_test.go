@@ -0,0 +1,126 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/providers"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+type stubWeather struct {
+	failing map[float64]bool // latitudes that fail
+}
+
+func (s *stubWeather) GetName() string { return "Stub" }
+func (s *stubWeather) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	return nil, nil
+}
+func (s *stubWeather) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	if s.failing[lat] {
+		return nil, errors.New("gateway timeout")
+	}
+	now := time.Now().UTC()
+	forecasts := make([]*models.Forecast, days)
+	for i := range forecasts {
+		forecasts[i] = &models.Forecast{ForecastTime: now, ValidTime: now.AddDate(0, 0, i), Temperature: 20}
+	}
+	return forecasts, nil
+}
+func (s *stubWeather) GetAlerts(ctx context.Context, lat, lon float64) ([]providers.WeatherAlert, error) {
+	return nil, nil
+}
+func (s *stubWeather) SupportedRegions() []string { return []string{"US"} }
+
+func TestForecastIngestion(t *testing.T) {
+	engine, _ := repo.OpenFileEngine("")
+	ctx := context.Background()
+	cities := []*repo.City{
+		{Name: "Boston", CountryCode: "US", Latitude: 42.36, IsActive: true},
+		{Name: "Denver", CountryCode: "US", Latitude: 39.74, IsActive: true},
+		{Name: "Paris", CountryCode: "FR", Latitude: 48.85, IsActive: true},
+		{Name: "Dormant", CountryCode: "US", Latitude: 40, IsActive: false},
+	}
+	for _, city := range cities {
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stub := &stubWeather{failing: map[float64]bool{39.74: true}}
+	manager := providers.NewProviderManager()
+	manager.RegisterWeatherProvider(stub)
+	job := ForecastIngestion(engine.Cities(), engine.Forecasts(), manager, 3, 0)
+
+	report := &Report{}
+	if err := job.Run(ctx, report); err != nil {
+		t.Fatal(err)
+	}
+	if report.processed != 1 || len(report.skipped) != 2 {
+		t.Fatalf("Expected 1 city ingested and 2 skipped, got %d and %+v", report.processed, report.skipped)
+	}
+	if skip := report.skipped[0]; skip.Item != "Denver, US (#2)" || !strings.Contains(skip.Reason, "gateway timeout") {
+		t.Errorf("Expected Denver skipped with the provider error, got %+v", skip)
+	}
+	if skip := report.skipped[1]; skip.Item != "Paris, FR (#3)" || skip.Reason != "no weather provider covers FR" {
+		t.Errorf("Expected Paris skipped for lack of a provider, got %+v", skip)
+	}
+
+	stored, _ := engine.Forecasts().GetByCityID(ctx, cities[0].ID, 10, 0)
+	if len(stored) != 3 || stored[0].SourceProvider != "Stub" {
+		t.Errorf("Expected 3 forecasts stored for Boston, got %+v", stored)
+	}
+
+	// A retry limited to the skipped cities leaves Boston alone
+	delete(stub.failing, 39.74)
+	retry := &Report{only: map[string]bool{"Denver, US (#2)": true, "Paris, FR (#3)": true}}
+	if err := job.Run(ctx, retry); err != nil {
+		t.Fatal(err)
+	}
+	if retry.processed != 1 || len(retry.skipped) != 1 {
+		t.Errorf("Expected Denver ingested and Paris skipped again, got %d and %+v", retry.processed, retry.skipped)
+	}
+	if count, _ := engine.Forecasts().Count(ctx); count != 6 {
+		t.Errorf("Expected 6 forecasts in total, got %d", count)
+	}
+}
+
+func TestCleanupJobs(t *testing.T) {
+	engine, _ := repo.OpenFileEngine("")
+	ctx := context.Background()
+	past := time.Now().UTC().Add(-time.Hour).Format(time.RFC3339)
+	_ = engine.Alerts().Create(ctx, &repo.Alert{SourceProvider: "nws", ProviderAlertID: "a1", EndTime: past})
+	_ = engine.Shares().Create(ctx, &repo.ShareLink{UserID: 1, ResourceType: "forecast", ResourceID: "1", ExpiresAt: past})
+
+	for _, job := range []Job{AlertCleanup(engine.Alerts(), 0), ShareCleanup(engine.Shares(), 0)} {
+		report := &Report{}
+		if err := job.Run(ctx, report); err != nil || report.processed != 1 {
+			t.Errorf("Expected %s to delete 1 row, got %d (%v)", job.Name, report.processed, err)
+		}
+	}
+}
+
+type stubPartitioner struct {
+	from   time.Time
+	months int
+}
+
+func (s *stubPartitioner) EnsurePartitions(ctx context.Context, from time.Time, months int) (int, error) {
+	s.from, s.months = from, months
+	return 1, nil
+}
+
+func TestForecastPartitions(t *testing.T) {
+	partitioner := &stubPartitioner{}
+	report := &Report{}
+	if err := ForecastPartitions(partitioner, 2, 0).Run(context.Background(), report); err != nil || report.processed != 1 {
+		t.Fatalf("Expected one partition created, got %d (%v)", report.processed, err)
+	}
+	if partitioner.months != 2 || time.Since(partitioner.from) > time.Minute {
+		t.Errorf("Expected partitions from now through 2 months ahead, got %v + %d", partitioner.from, partitioner.months)
+	}
+}
@@ -0,0 +1,241 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/webhook"
+)
+
+// Digest delivery statuses
+const (
+	DigestDelivered = "delivered"
+	DigestFailed    = "failed"
+)
+
+// DigestEvent is the webhook event name of forecast digests
+const DigestEvent = "forecast.digest"
+
+// digestBatchSize is the number of due digests loaded at a time
+const digestBatchSize = 100
+
+// DigestPayload is the JSON body delivered to a digest's webhook
+type DigestPayload struct {
+	DigestID    int              `json:"digest_id"`
+	City        DigestCity       `json:"city"`
+	Days        int              `json:"days"`
+	GeneratedAt string           `json:"generated_at"`
+	Forecasts   []DigestForecast `json:"forecasts"` // ordered by valid time
+}
+
+// DigestCity identifies the city a digest covers
+type DigestCity struct {
+	ID          int     `json:"id"`
+	Name        string  `json:"name"`
+	CountryCode string  `json:"country_code"`
+	Latitude    float64 `json:"latitude"`
+	Longitude   float64 `json:"longitude"`
+}
+
+// DigestForecast is one stored forecast in a digest, in metric units
+type DigestForecast struct {
+	SourceProvider string  `json:"source_provider"`
+	ValidTime      string  `json:"valid_time"`
+	Temperature    float64 `json:"temperature"`
+	FeelsLike      float64 `json:"feels_like"`
+	Humidity       float64 `json:"humidity"`
+	WindSpeed      float64 `json:"wind_speed"`
+	WindDirection  float64 `json:"wind_direction"`
+	Precipitation  float64 `json:"precipitation"`
+	CloudCover     float64 `json:"cloud_cover"`
+	WeatherCode    string  `json:"weather_code"`
+	Description    string  `json:"description"`
+}
+
+// NextDigestRun returns the first instant after after at which the wall clock in
+// timezone reads localTime (HH:MM). On the day daylight saving skips localTime, it is
+// read with the offset in effect before the change.
+func NextDigestRun(localTime, timezone string, after time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", localTime)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("time must be HH:MM, got %q", localTime)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		return time.Time{}, fmt.Errorf("unknown timezone %q", timezone)
+	}
+
+	local := after.In(loc)
+	next := time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	for !next.After(after) {
+		local = local.AddDate(0, 0, 1)
+		next = time.Date(local.Year(), local.Month(), local.Day(), clock.Hour(), clock.Minute(), 0, 0, loc)
+	}
+	return next, nil
+}
+
+// digestItem names a digest in run reports
+func digestItem(digest *repo.Digest) string {
+	return fmt.Sprintf("digest %d", digest.ID)
+}
+
+// DigestDelivery sends every due digest its city's stored forecasts for the coming
+// days and schedules its next delivery for the following day. Deliveries that still
+// fail after the client's retries are skipped and named in the run's report; they are
+// not resent until their next scheduled time.
+func DigestDelivery(digests repo.DigestRepository, cities repo.CityRepository, forecasts repo.ForecastRepository, client *webhook.Client, interval time.Duration) Job {
+	return Job{
+		Name:        DigestDeliveryJob,
+		Description: "Deliver scheduled forecast digests to their webhooks",
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			seen := map[int]bool{}
+			for {
+				now := time.Now().UTC()
+				due, err := digests.ListDue(ctx, now.Format(time.RFC3339), digestBatchSize)
+				if err != nil {
+					return fmt.Errorf("failed to list due digests: %w", err)
+				}
+				fresh := 0
+				for _, digest := range due {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					// A digest whose run could not be recorded stays due; leave it to the
+					// next run rather than sending it twice
+					if seen[digest.ID] {
+						continue
+					}
+					seen[digest.ID] = true
+					fresh++
+
+					item := digestItem(digest)
+					reason := deliverDigest(ctx, cities, forecasts, client, digest, now)
+					if reason == "" {
+						report.Processed(1)
+					} else {
+						report.Skip(item, reason)
+					}
+					if err := scheduleDigest(ctx, digests, digest, now, reason); err != nil {
+						report.Skip(item, err.Error())
+					}
+				}
+				if len(due) < digestBatchSize || fresh == 0 {
+					return nil
+				}
+			}
+		},
+	}
+}
+
+// deliverDigest builds and sends one digest, returning why it failed
+func deliverDigest(ctx context.Context, cities repo.CityRepository, forecasts repo.ForecastRepository, client *webhook.Client, digest *repo.Digest, now time.Time) string {
+	city, err := cities.GetByID(ctx, digest.CityID)
+	if errors.Is(err, repo.ErrNotFound) {
+		return fmt.Sprintf("city %d no longer exists", digest.CityID)
+	}
+	if err != nil {
+		return err.Error()
+	}
+	upcoming, err := upcomingForecasts(ctx, forecasts, city.ID, now, now.AddDate(0, 0, digest.Days))
+	if err != nil {
+		return err.Error()
+	}
+
+	payload := DigestPayload{
+		DigestID: digest.ID,
+		City: DigestCity{
+			ID:          city.ID,
+			Name:        city.Name,
+			CountryCode: city.CountryCode,
+			Latitude:    city.Latitude,
+			Longitude:   city.Longitude,
+		},
+		Days:        digest.Days,
+		GeneratedAt: now.Format(time.RFC3339),
+		Forecasts:   upcoming,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Sprintf("failed to encode digest: %v", err)
+	}
+
+	delivery := webhook.Delivery{
+		ID:     strconv.Itoa(digest.ID) + "-" + strconv.FormatInt(now.Unix(), 10),
+		URL:    digest.URL,
+		Secret: digest.Secret,
+		Event:  DigestEvent,
+		Body:   body,
+	}
+	if attempts, err := client.Deliver(ctx, delivery); err != nil {
+		return fmt.Sprintf("%v after %d attempts", err, attempts)
+	}
+	return ""
+}
+
+// scheduleDigest records a delivery's outcome and moves the digest to its next day.
+// The next run is computed from now rather than the missed time, so a server that was
+// down for days sends one digest instead of a burst.
+func scheduleDigest(ctx context.Context, digests repo.DigestRepository, digest *repo.Digest, now time.Time, reason string) error {
+	next, err := NextDigestRun(digest.LocalTime, digest.Timezone, now)
+	if err != nil {
+		return err
+	}
+	digest.NextRunAt = next.UTC().Format(time.RFC3339)
+	digest.LastRunAt = now.Format(time.RFC3339)
+	digest.LastStatus, digest.LastError = DigestDelivered, ""
+	if reason != "" {
+		digest.LastStatus, digest.LastError = DigestFailed, reason
+	}
+	if err := digests.RecordRun(context.WithoutCancel(ctx), digest); err != nil {
+		return fmt.Errorf("failed to reschedule: %v", err)
+	}
+	return nil
+}
+
+// upcomingForecasts returns a city's forecasts valid in [from, until), oldest first
+func upcomingForecasts(ctx context.Context, forecasts repo.ForecastRepository, cityID int, from, until time.Time) ([]DigestForecast, error) {
+	upcoming := []DigestForecast{}
+	cursor := &repo.ForecastCursor{ValidTime: until.Format(time.RFC3339)}
+	for {
+		page, err := forecasts.GetByCityIDAfter(ctx, cityID, cursor, ingestionPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list forecasts: %w", err)
+		}
+		for _, f := range page {
+			valid, err := time.Parse(time.RFC3339, f.ValidTime)
+			if err != nil {
+				continue
+			}
+			if valid.Before(from) {
+				slices.Reverse(upcoming)
+				return upcoming, nil
+			}
+			upcoming = append(upcoming, DigestForecast{
+				SourceProvider: f.SourceProvider,
+				ValidTime:      valid.UTC().Format(time.RFC3339),
+				Temperature:    f.Temperature,
+				FeelsLike:      f.FeelsLike,
+				Humidity:       f.Humidity,
+				WindSpeed:      f.WindSpeed,
+				WindDirection:  f.WindDirection,
+				Precipitation:  f.Precipitation,
+				CloudCover:     f.CloudCover,
+				WeatherCode:    f.WeatherCode,
+				Description:    f.Description,
+			})
+		}
+		if len(page) < ingestionPageSize {
+			slices.Reverse(upcoming)
+			return upcoming, nil
+		}
+		last := page[len(page)-1]
+		cursor = &repo.ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+	}
+}
@@ -0,0 +1,137 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/webhook"
+)
+
+func TestNextDigestRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		localTime string
+		timezone  string
+		after     time.Time
+		expected  time.Time
+	}{
+		{"later today", "06:00", "UTC", time.Date(2025, 8, 13, 5, 0, 0, 0, time.UTC), time.Date(2025, 8, 13, 6, 0, 0, 0, time.UTC)},
+		{"tomorrow", "06:00", "UTC", time.Date(2025, 8, 13, 6, 0, 0, 0, time.UTC), time.Date(2025, 8, 14, 6, 0, 0, 0, time.UTC)},
+		{"local zone", "06:00", "America/Chicago", time.Date(2025, 8, 13, 12, 0, 0, 0, time.UTC), time.Date(2025, 8, 14, 11, 0, 0, 0, time.UTC)},
+		{"local date ahead of UTC", "06:00", "Asia/Tokyo", time.Date(2025, 8, 13, 22, 0, 0, 0, time.UTC), time.Date(2025, 8, 14, 21, 0, 0, 0, time.UTC)},
+		{"daylight saving gap", "02:30", "America/New_York", time.Date(2025, 3, 9, 5, 0, 0, 0, time.UTC), time.Date(2025, 3, 9, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next, err := NextDigestRun(tt.localTime, tt.timezone, tt.after)
+			if err != nil {
+				t.Fatalf("NextDigestRun failed: %v", err)
+			}
+			if !next.Equal(tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, next.UTC())
+			}
+		})
+	}
+
+	if _, err := NextDigestRun("6am", "UTC", time.Now()); err == nil {
+		t.Error("Expected an error for a malformed time")
+	}
+	if _, err := NextDigestRun("06:00", "Mars/Olympus", time.Now()); err == nil {
+		t.Error("Expected an error for an unknown timezone")
+	}
+}
+
+func TestDigestDelivery(t *testing.T) {
+	ctx := context.Background()
+	engine, _ := repo.OpenFileEngine("")
+	city := &repo.City{Name: "Chicago", CountryCode: "US", Latitude: 41.88, Longitude: -87.63, IsActive: true}
+	if err := engine.Cities().Create(ctx, city); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().UTC()
+	for _, hours := range []int{-2, 1, 30, 200} {
+		valid := now.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)
+		if err := engine.Forecasts().Create(ctx, &repo.Forecast{CityID: city.ID, SourceProvider: "Stub", ForecastTime: valid, ValidTime: valid}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var received []DigestPayload
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify("secret", r.Header.Get(webhook.SignatureHeader), body, time.Now(), time.Minute); err != nil {
+			t.Errorf("Expected a signed delivery, got %v", err)
+		}
+		if r.URL.Path == "/gone" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		var payload DigestPayload
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("Invalid payload: %v", err)
+		}
+		received = append(received, payload)
+	}))
+	defer server.Close()
+
+	due := now.Add(-time.Minute).Format(time.RFC3339)
+	digests := []*repo.Digest{
+		{UserID: 1, CityID: city.ID, URL: server.URL + "/ok", Secret: "secret", LocalTime: "06:00", Timezone: "America/Chicago", Days: 2, NextRunAt: due},
+		{UserID: 1, CityID: city.ID, URL: server.URL + "/gone", Secret: "secret", LocalTime: "06:00", Timezone: "UTC", Days: 1, NextRunAt: due},
+		{UserID: 1, CityID: city.ID + 1, URL: server.URL + "/ok", Secret: "secret", LocalTime: "06:00", Timezone: "UTC", Days: 1, NextRunAt: due},
+		{UserID: 1, CityID: city.ID, URL: server.URL + "/ok", Secret: "secret", LocalTime: "06:00", Timezone: "UTC", Days: 1, NextRunAt: now.Add(time.Hour).Format(time.RFC3339)},
+	}
+	for _, digest := range digests {
+		if err := engine.Digests().Create(ctx, digest); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client := webhook.NewClient(webhook.Destinations{AllowPrivate: true})
+	client.Backoff = time.Millisecond
+	job := DigestDelivery(engine.Digests(), engine.Cities(), engine.Forecasts(), client, time.Minute)
+	report := &Report{}
+	if err := job.Run(ctx, report); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.processed != 1 || len(report.skipped) != 2 {
+		t.Errorf("Expected 1 delivered and 2 skipped digests, got %d and %+v", report.processed, report.skipped)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("Expected one digest delivered, got %d", len(received))
+	}
+	if payload := received[0]; payload.City.Name != "Chicago" || len(payload.Forecasts) != 2 {
+		t.Errorf("Expected Chicago's 2 forecasts in the next 2 days, got %+v", payload)
+	} else if payload.Forecasts[0].ValidTime > payload.Forecasts[1].ValidTime {
+		t.Errorf("Expected forecasts oldest first, got %+v", payload.Forecasts)
+	}
+
+	delivered, _ := engine.Digests().GetByID(ctx, digests[0].ID)
+	if delivered.LastStatus != DigestDelivered || parseTime(t, delivered.NextRunAt).Before(now) {
+		t.Errorf("Expected a delivered digest rescheduled ahead, got %+v", delivered)
+	}
+	failed, _ := engine.Digests().GetByID(ctx, digests[1].ID)
+	if failed.LastStatus != DigestFailed || failed.LastError == "" || parseTime(t, failed.NextRunAt).Before(now) {
+		t.Errorf("Expected a failed digest rescheduled ahead, got %+v", failed)
+	}
+	pending, _ := engine.Digests().GetByID(ctx, digests[3].ID)
+	if pending.LastStatus != "" {
+		t.Errorf("Expected a digest not yet due to be left alone, got %+v", pending)
+	}
+}
+
+func parseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatalf("Invalid time %q: %v", value, err)
+	}
+	return parsed
+}
@@ -0,0 +1,358 @@
+// Package jobs runs the server's background jobs (forecast ingestion, retention and
+// cleanup) on fixed intervals and records every run in a JobRunRepository.
+//
+// Each run stores when it started, how long it took, how many items it processed and
+// which items it skipped and why, so operators can see from the admin UI why a run
+// left cities out. Failed and partial runs can be retried; retrying a partial run only
+// revisits the items the original run skipped.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Run statuses
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusPartial   = "partial" // finished, but skipped some items
+	StatusFailed    = "failed"
+)
+
+// Run triggers
+const (
+	TriggerSchedule = "schedule"
+	TriggerManual   = "manual"
+	TriggerRetry    = "retry"
+)
+
+// defaultTimeout bounds a run when its job does not set a timeout
+const defaultTimeout = time.Hour
+
+var (
+	// ErrUnknownJob is returned when triggering a job that is not registered
+	ErrUnknownJob = errors.New("unknown job")
+
+	// ErrJobRunning is returned when triggering a job that is already running
+	ErrJobRunning = errors.New("job is already running")
+
+	// ErrNotRetryable is returned when retrying a run that neither failed nor skipped items
+	ErrNotRetryable = errors.New("only failed or partial runs can be retried")
+)
+
+// Job is a unit of background work
+type Job struct {
+	Name        string
+	Description string
+	Interval    time.Duration // time between scheduled runs; 0 runs the job on demand only
+	Timeout     time.Duration // bound on a single run; 0 means an hour
+
+	// Run does the work, recording processed and skipped items in report. Returning an
+	// error marks the run as failed.
+	Run func(ctx context.Context, report *Report) error
+}
+
+// Skip is an item a run left out, with the reason
+type Skip struct {
+	Item   string `json:"item"`
+	Reason string `json:"reason"`
+}
+
+// DecodeSkipped decodes the skipped items stored on a run
+func DecodeSkipped(skipped string) ([]Skip, error) {
+	items := []Skip{}
+	if skipped == "" {
+		return items, nil
+	}
+	if err := json.Unmarshal([]byte(skipped), &items); err != nil {
+		return nil, fmt.Errorf("failed to decode skipped items: %w", err)
+	}
+	return items, nil
+}
+
+// Report collects the outcome of a run. It is safe for concurrent use.
+type Report struct {
+	mu        sync.Mutex
+	processed int
+	skipped   []Skip
+	only      map[string]bool // items to revisit when retrying a partial run
+}
+
+// Processed records n items as done
+func (r *Report) Processed(n int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.processed += n
+}
+
+// Skip records an item the run left out
+func (r *Report) Skip(item, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.skipped = append(r.skipped, Skip{Item: item, Reason: reason})
+}
+
+// Selected reports whether the run should handle item. It is false only when retrying
+// a partial run, for items the original run did not skip.
+func (r *Report) Selected(item string) bool {
+	return r.only == nil || r.only[item]
+}
+
+// JobInfo describes a registered job
+type JobInfo struct {
+	Name        string
+	Description string
+	Interval    time.Duration
+	Running     bool
+	NextRun     time.Time // zero for on-demand jobs and before Start
+}
+
+// Scheduler runs registered jobs and records their runs
+type Scheduler struct {
+	runs    repo.JobRunRepository
+	onError func(error)
+	now     func() time.Time
+
+	mu      sync.Mutex
+	jobs    map[string]*Job
+	order   []string
+	running map[string]bool
+	next    map[string]time.Time
+	ctx     context.Context // parent of every run; canceled when the scheduler stops
+	wg      sync.WaitGroup
+}
+
+// NewScheduler creates a scheduler recording runs in runs. onError, if non-nil, is
+// called when a run's outcome cannot be recorded.
+func NewScheduler(runs repo.JobRunRepository, onError func(error)) *Scheduler {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Scheduler{
+		runs:    runs,
+		onError: onError,
+		now:     time.Now,
+		jobs:    make(map[string]*Job),
+		running: make(map[string]bool),
+		next:    make(map[string]time.Time),
+		ctx:     context.Background(),
+	}
+}
+
+// Register adds a job, replacing any job with the same name. Jobs registered after
+// Start only run on demand.
+func (s *Scheduler) Register(job Job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[job.Name]; !ok {
+		s.order = append(s.order, job.Name)
+	}
+	s.jobs[job.Name] = &job
+}
+
+// Jobs describes the registered jobs in registration order
+func (s *Scheduler) Jobs() []JobInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	infos := make([]JobInfo, 0, len(s.order))
+	for _, name := range s.order {
+		job := s.jobs[name]
+		infos = append(infos, JobInfo{
+			Name:        job.Name,
+			Description: job.Description,
+			Interval:    job.Interval,
+			Running:     s.running[name],
+			NextRun:     s.next[name],
+		})
+	}
+	return infos
+}
+
+// Start marks runs left running by a previous process as failed and schedules every
+// job with an interval. Scheduling stops and running jobs are canceled when ctx is
+// done; use Wait to block until they have been recorded.
+func (s *Scheduler) Start(ctx context.Context) error {
+	if _, err := s.runs.FailRunning(ctx, "interrupted by a restart"); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ctx = ctx
+	for _, name := range s.order {
+		job := s.jobs[name]
+		if job.Interval <= 0 {
+			continue
+		}
+		s.next[name] = s.now().Add(job.Interval)
+		s.wg.Add(1)
+		go s.schedule(ctx, job)
+	}
+	return nil
+}
+
+// schedule triggers job every interval until ctx is done. Ticks that find the job
+// still running are skipped.
+func (s *Scheduler) schedule(ctx context.Context, job *Job) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			s.next[job.Name] = s.now().Add(job.Interval)
+			s.mu.Unlock()
+			if _, err := s.start(ctx, job, TriggerSchedule, 0, nil); err != nil && !errors.Is(err, ErrJobRunning) {
+				s.onError(fmt.Errorf("failed to start job %s: %w", job.Name, err))
+			}
+		}
+	}
+}
+
+// Wait blocks until scheduling has stopped and every run has been recorded
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Trigger starts a run of the named job in the background and returns it as created
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*repo.JobRun, error) {
+	s.mu.Lock()
+	job, ok := s.jobs[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, name)
+	}
+	return s.start(ctx, job, TriggerManual, 0, nil)
+}
+
+// Retry starts a new run of a failed or partial run's job. Retrying a partial run
+// only revisits the items it skipped.
+func (s *Scheduler) Retry(ctx context.Context, runID int) (*repo.JobRun, error) {
+	previous, err := s.runs.GetByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+
+	var only map[string]bool
+	switch previous.Status {
+	case StatusFailed:
+	case StatusPartial:
+		skipped, err := DecodeSkipped(previous.Skipped)
+		if err != nil {
+			return nil, err
+		}
+		only = make(map[string]bool, len(skipped))
+		for _, skip := range skipped {
+			only[skip.Item] = true
+		}
+	default:
+		return nil, fmt.Errorf("%w: run %d is %s", ErrNotRetryable, runID, previous.Status)
+	}
+
+	s.mu.Lock()
+	job, ok := s.jobs[previous.JobName]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownJob, previous.JobName)
+	}
+	return s.start(ctx, job, TriggerRetry, runID, only)
+}
+
+// start records a new run and executes it in the background
+func (s *Scheduler) start(ctx context.Context, job *Job, trigger string, retryOf int, only map[string]bool) (*repo.JobRun, error) {
+	s.mu.Lock()
+	if s.running[job.Name] {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrJobRunning, job.Name)
+	}
+	s.running[job.Name] = true
+	parent := s.ctx
+	s.mu.Unlock()
+
+	run := &repo.JobRun{
+		JobName:     job.Name,
+		TriggeredBy: trigger,
+		RetryOf:     retryOf,
+		Status:      StatusRunning,
+		StartedAt:   s.now().UTC().Format(time.RFC3339),
+	}
+	if err := s.runs.Create(ctx, run); err != nil {
+		s.release(job.Name)
+		return nil, err
+	}
+	snapshot := *run
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.release(job.Name)
+		s.execute(parent, job, run, &Report{only: only})
+	}()
+	return &snapshot, nil
+}
+
+// execute runs job and records its outcome on run
+func (s *Scheduler) execute(parent context.Context, job *Job, run *repo.JobRun, report *Report) {
+	timeout := job.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, timeout)
+	defer cancel()
+
+	started := s.now()
+	err := func() (err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("panic: %v", p)
+			}
+		}()
+		return job.Run(ctx, report)
+	}()
+
+	report.mu.Lock()
+	run.Processed = report.processed
+	skipped := slices.Clone(report.skipped)
+	report.mu.Unlock()
+
+	finished := s.now()
+	run.FinishedAt = finished.UTC().Format(time.RFC3339)
+	run.DurationMS = finished.Sub(started).Milliseconds()
+	switch {
+	case err != nil:
+		run.Status, run.Error = StatusFailed, err.Error()
+	case len(skipped) > 0:
+		run.Status = StatusPartial
+	default:
+		run.Status = StatusSucceeded
+	}
+	if skipped == nil {
+		skipped = []Skip{}
+	}
+	if data, err := json.Marshal(skipped); err == nil {
+		run.Skipped = string(data)
+	}
+
+	// Record the outcome even when the run was canceled by shutdown
+	if err := s.runs.Finish(context.WithoutCancel(ctx), run); err != nil {
+		s.onError(fmt.Errorf("failed to record run %d of job %s: %w", run.ID, job.Name, err))
+	}
+}
+
+// release marks a job as no longer running
+func (s *Scheduler) release(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, name)
+}
@@ -0,0 +1,186 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newTestScheduler(t *testing.T) (*Scheduler, repo.JobRunRepository) {
+	t.Helper()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewScheduler(engine.JobRuns(), func(err error) { t.Error(err) }), engine.JobRuns()
+}
+
+func finished(t *testing.T, s *Scheduler, runs repo.JobRunRepository, id int) *repo.JobRun {
+	t.Helper()
+	s.Wait()
+	run, err := runs.GetByID(context.Background(), id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return run
+}
+
+func TestScheduler_Outcomes(t *testing.T) {
+	tests := []struct {
+		name      string
+		run       func(ctx context.Context, report *Report) error
+		status    string
+		processed int
+		skipped   int
+		err       string
+	}{
+		{"succeeded", func(ctx context.Context, report *Report) error { report.Processed(3); return nil }, StatusSucceeded, 3, 0, ""},
+		{"partial", func(ctx context.Context, report *Report) error {
+			report.Processed(1)
+			report.Skip("Paris", "no provider")
+			return nil
+		}, StatusPartial, 1, 1, ""},
+		{"failed", func(ctx context.Context, report *Report) error { return errors.New("database down") }, StatusFailed, 0, 0, "database down"},
+		{"panic", func(ctx context.Context, report *Report) error { panic("boom") }, StatusFailed, 0, 0, "panic: boom"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, runs := newTestScheduler(t)
+			s.Register(Job{Name: "job", Run: test.run})
+
+			started, err := s.Trigger(context.Background(), "job")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if started.Status != StatusRunning || started.TriggeredBy != TriggerManual {
+				t.Errorf("Expected a running manual run, got %+v", started)
+			}
+
+			run := finished(t, s, runs, started.ID)
+			skipped, _ := DecodeSkipped(run.Skipped)
+			if run.Status != test.status || run.Processed != test.processed || len(skipped) != test.skipped || run.Error != test.err {
+				t.Errorf("Unexpected run %+v", run)
+			}
+			if run.FinishedAt == "" {
+				t.Error("Expected a finish time")
+			}
+		})
+	}
+}
+
+func TestScheduler_Trigger(t *testing.T) {
+	s, _ := newTestScheduler(t)
+	release := make(chan struct{})
+	s.Register(Job{Name: "slow", Run: func(ctx context.Context, report *Report) error {
+		<-release
+		return nil
+	}})
+
+	if _, err := s.Trigger(context.Background(), "missing"); !errors.Is(err, ErrUnknownJob) {
+		t.Errorf("Expected ErrUnknownJob, got %v", err)
+	}
+	if _, err := s.Trigger(context.Background(), "slow"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Trigger(context.Background(), "slow"); !errors.Is(err, ErrJobRunning) {
+		t.Errorf("Expected ErrJobRunning, got %v", err)
+	}
+	if infos := s.Jobs(); len(infos) != 1 || !infos[0].Running {
+		t.Errorf("Expected the job to be reported running, got %+v", infos)
+	}
+
+	close(release)
+	s.Wait()
+	if _, err := s.Trigger(context.Background(), "slow"); err != nil {
+		t.Errorf("Expected the job to run again once finished, got %v", err)
+	}
+	s.Wait()
+}
+
+func TestScheduler_Retry(t *testing.T) {
+	s, runs := newTestScheduler(t)
+	var visited []string
+	s.Register(Job{Name: "ingest", Run: func(ctx context.Context, report *Report) error {
+		for _, city := range []string{"Boston", "Paris", "Lyon"} {
+			if !report.Selected(city) {
+				continue
+			}
+			visited = append(visited, city)
+			if city != "Boston" && len(visited) <= 3 {
+				report.Skip(city, "provider timeout")
+				continue
+			}
+			report.Processed(1)
+		}
+		return nil
+	}})
+
+	first, _ := s.Trigger(context.Background(), "ingest")
+	if run := finished(t, s, runs, first.ID); run.Status != StatusPartial {
+		t.Fatalf("Expected a partial run, got %+v", run)
+	}
+
+	retry, err := s.Retry(context.Background(), first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := finished(t, s, runs, retry.ID)
+	if run.TriggeredBy != TriggerRetry || run.RetryOf != first.ID || run.Status != StatusSucceeded || run.Processed != 2 {
+		t.Errorf("Expected a successful retry of run %d, got %+v", first.ID, run)
+	}
+	if len(visited) != 5 || visited[3] != "Paris" || visited[4] != "Lyon" {
+		t.Errorf("Expected the retry to revisit only the skipped cities, got %v", visited)
+	}
+
+	if _, err := s.Retry(context.Background(), retry.ID); !errors.Is(err, ErrNotRetryable) {
+		t.Errorf("Expected ErrNotRetryable for a successful run, got %v", err)
+	}
+}
+
+func TestScheduler_Start(t *testing.T) {
+	s, runs := newTestScheduler(t)
+	ctx := context.Background()
+	stale := &repo.JobRun{JobName: "ingest", TriggeredBy: TriggerSchedule, Status: StatusRunning, StartedAt: time.Now().UTC().Format(time.RFC3339)}
+	if err := runs.Create(ctx, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	ticks := make(chan struct{}, 1)
+	s.Register(Job{Name: "tick", Interval: 10 * time.Millisecond, Run: func(ctx context.Context, report *Report) error {
+		select {
+		case ticks <- struct{}{}:
+		default:
+		}
+		return nil
+	}})
+	s.Register(Job{Name: "manual", Run: func(ctx context.Context, report *Report) error { return nil }})
+
+	ctx, cancel := context.WithCancel(ctx)
+	if err := s.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if run, _ := runs.GetByID(ctx, stale.ID); run.Status != StatusFailed || run.Error == "" {
+		t.Errorf("Expected the stale run to be marked failed, got %+v", run)
+	}
+	infos := s.Jobs()
+	if infos[0].NextRun.IsZero() || !infos[1].NextRun.IsZero() {
+		t.Errorf("Expected only the interval job to be scheduled, got %+v", infos)
+	}
+
+	select {
+	case <-ticks:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the interval job to run")
+	}
+	cancel()
+	s.Wait()
+
+	scheduled, _ := runs.List(context.Background(), "tick", 1, 0)
+	if len(scheduled) != 1 || scheduled[0].TriggeredBy != TriggerSchedule {
+		t.Errorf("Expected a scheduled run, got %+v", scheduled)
+	}
+}
@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/notify"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// AlertDigestPeriod is the least time between two digest emails to one subscription
+const AlertDigestPeriod = 24 * time.Hour
+
+// notificationBatchSize is the number of due subscriptions loaded at a time
+const notificationBatchSize = 100
+
+// AlertNotifications emails every subscription with due queued alerts: immediate
+// subscriptions on every run and digests once AlertDigestPeriod has passed since their
+// last email. Sends that fail are skipped and named in the run's report; their alerts
+// stay queued and are retried on the next run.
+func AlertNotifications(subscriptions repo.AlertSubscriptionRepository, sender notify.Sender, config notify.Config, interval time.Duration) Job {
+	return Job{
+		Name:        AlertNotificationJob,
+		Description: "Email queued weather alerts to their subscribers",
+		Interval:    interval,
+		Run: func(ctx context.Context, report *Report) error {
+			seen := map[int]bool{}
+			for {
+				now := time.Now().UTC()
+				due, err := subscriptions.ListDue(ctx, now.Add(-AlertDigestPeriod).Format(time.RFC3339), notificationBatchSize)
+				if err != nil {
+					return fmt.Errorf("failed to list due alert subscriptions: %w", err)
+				}
+				fresh := 0
+				for _, subscription := range due {
+					if err := ctx.Err(); err != nil {
+						return err
+					}
+					// A failed send stays due; leave it to the next run rather than
+					// retrying it in a loop
+					if seen[subscription.ID] {
+						continue
+					}
+					seen[subscription.ID] = true
+					fresh++
+
+					item := fmt.Sprintf("alert subscription %d", subscription.ID)
+					if sent, err := sendAlerts(ctx, subscriptions, sender, config, subscription, now); err != nil {
+						report.Skip(item, err.Error())
+					} else {
+						report.Processed(sent)
+					}
+				}
+				if len(due) < notificationBatchSize || fresh == 0 {
+					return nil
+				}
+			}
+		},
+	}
+}
+
+// sendAlerts emails a subscription its pending alerts and marks them sent, returning
+// how many were sent
+func sendAlerts(ctx context.Context, subscriptions repo.AlertSubscriptionRepository, sender notify.Sender, config notify.Config, subscription *repo.AlertSubscription, now time.Time) (int, error) {
+	pending, err := subscriptions.ListPending(ctx, subscription.ID)
+	if err != nil {
+		return 0, err
+	}
+	if len(pending) == 0 {
+		return 0, nil
+	}
+
+	msg := notify.AlertEmail(subscription, pending, config.UnsubscribeURL(subscription.Token))
+	if err := sender.Send(ctx, msg); err != nil {
+		return 0, err
+	}
+
+	ids := make([]int, len(pending))
+	for i, alert := range pending {
+		ids[i] = alert.ID
+	}
+	if err := subscriptions.MarkSent(context.WithoutCancel(ctx), subscription.ID, ids, now.Format(time.RFC3339)); err != nil {
+		return 0, fmt.Errorf("sent but failed to record: %v", err)
+	}
+	return len(pending), nil
+}
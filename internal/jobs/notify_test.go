@@ -0,0 +1,76 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/notify"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// recordingSender records sent emails, failing those to failTo
+type recordingSender struct {
+	sent   []notify.Message
+	failTo string
+}
+
+func (s *recordingSender) Send(ctx context.Context, msg notify.Message) error {
+	if msg.To == s.failTo {
+		return errors.New("mailbox unavailable")
+	}
+	s.sent = append(s.sent, msg)
+	return nil
+}
+
+func TestAlertNotifications(t *testing.T) {
+	ctx := context.Background()
+	engine, _ := repo.OpenFileEngine("")
+	subscriptions := engine.AlertSubscriptions()
+
+	immediate := &repo.AlertSubscription{UserID: 1, Email: "a@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 25, MinSeverity: "Severe", Mode: repo.SubscriptionImmediate, Token: "t1"}
+	digest := &repo.AlertSubscription{UserID: 2, Email: "b@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 25, MinSeverity: "Severe", Mode: repo.SubscriptionDigest, Token: "t2"}
+	failing := &repo.AlertSubscription{UserID: 3, Email: "c@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 25, MinSeverity: "Severe", Mode: repo.SubscriptionImmediate, Token: "t3"}
+	for _, subscription := range []*repo.AlertSubscription{immediate, digest, failing} {
+		if err := subscriptions.Create(ctx, subscription); err != nil {
+			t.Fatal(err)
+		}
+	}
+	alert := &repo.Alert{SourceProvider: "NWS", ProviderAlertID: "a1", Title: "Tornado Warning", Severity: "Extreme", Latitude: 41.9, Longitude: -87.7}
+	if err := engine.Alerts().Create(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	if err := subscriptions.Queue(ctx, alert.ID, []int{immediate.ID, digest.ID, failing.ID}); err != nil {
+		t.Fatal(err)
+	}
+
+	sender := &recordingSender{failTo: "c@example.com"}
+	config := notify.Config{PublicURL: "https://weather.example.com"}
+	job := AlertNotifications(subscriptions, sender, config, 0)
+	report := &Report{}
+	if err := job.Run(ctx, report); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(sender.sent) != 1 || sender.sent[0].To != "a@example.com" {
+		t.Fatalf("Expected only the immediate subscription to be emailed, got %+v", sender.sent)
+	}
+	if !strings.Contains(sender.sent[0].Body, config.UnsubscribeURL("t1")) {
+		t.Errorf("Expected an unsubscribe link, got %s", sender.sent[0].Body)
+	}
+	if report.processed != 1 || len(report.skipped) != 1 {
+		t.Errorf("Expected 1 processed and the failed send skipped, got %+v", report)
+	}
+	if pending, _ := subscriptions.ListPending(ctx, failing.ID); len(pending) != 1 {
+		t.Errorf("Expected the failed send to stay queued, got %+v", pending)
+	}
+
+	sender.sent = nil
+	if err := job.Run(ctx, &Report{}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(sender.sent) != 0 {
+		t.Errorf("Expected sent alerts not to be emailed again, got %+v", sender.sent)
+	}
+}
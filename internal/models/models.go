@@ -1,10 +1,13 @@
 package models
 
 import (
-	"fmt"
+	"encoding/json"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
 )
 
 // Model represents the base interface for all data models
@@ -23,9 +26,11 @@ type Forecast struct {
 	Temperature     float64   `json:"temperature" db:"temperature"`         // Celsius
 	FeelsLike       float64   `json:"feels_like" db:"feels_like"`           // Celsius
 	Humidity        float64   `json:"humidity" db:"humidity"`               // Percentage
-	Pressure        float64   `json:"pressure" db:"pressure"`               // hPa
+	Pressure        float64   `json:"pressure" db:"pressure"`               // hPa, reduced to mean sea level
+	StationPressure float64   `json:"station_pressure" db:"station_pressure"` // hPa at station elevation, 0 when unknown
 	WindSpeed       float64   `json:"wind_speed" db:"wind_speed"`           // m/s
 	WindDirection   float64   `json:"wind_direction" db:"wind_direction"`   // degrees
+	WindGust        float64   `json:"wind_gust" db:"wind_gust"`             // m/s, 0 when not reported
 	Visibility      float64   `json:"visibility" db:"visibility"`           // km
 	CloudCover      float64   `json:"cloud_cover" db:"cloud_cover"`         // percentage
 	Precipitation   float64   `json:"precipitation" db:"precipitation"`     // mm
@@ -47,6 +52,7 @@ type User struct {
 	PreferredLanguage string    `json:"preferred_language" db:"preferred_language"`
 	DefaultCityID     *int      `json:"default_city_id" db:"default_city_id"`
 	APIKeyHash        string    `json:"-" db:"api_key_hash"` // hashed API key for programmatic access
+	Role              string    `json:"role" db:"role"`       // admin, user or readonly
 	IsActive          bool      `json:"is_active" db:"is_active"`
 	CreatedAt         time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
@@ -96,43 +102,23 @@ type Place struct {
 
 // Forecast Model interface implementation
 func (f *Forecast) Validate() error {
-	if f.CityID <= 0 {
-		return fmt.Errorf("city_id must be positive")
-	}
-	if f.SourceProvider == "" {
-		return fmt.Errorf("source_provider is required")
-	}
-	if f.ForecastTime.IsZero() {
-		return fmt.Errorf("forecast_time is required")
-	}
-	if f.ValidTime.IsZero() {
-		return fmt.Errorf("valid_time is required")
-	}
-	if f.Temperature < -273.15 { // absolute zero in Celsius
-		return fmt.Errorf("temperature cannot be below absolute zero")
-	}
-	if f.Humidity < 0 || f.Humidity > 100 {
-		return fmt.Errorf("humidity must be between 0 and 100")
-	}
-	if f.Pressure < 0 {
-		return fmt.Errorf("pressure cannot be negative")
-	}
-	if f.WindSpeed < 0 {
-		return fmt.Errorf("wind_speed cannot be negative")
-	}
-	if f.WindDirection < 0 || f.WindDirection >= 360 {
-		return fmt.Errorf("wind_direction must be between 0 and 359 degrees")
-	}
-	if f.CloudCover < 0 || f.CloudCover > 100 {
-		return fmt.Errorf("cloud_cover must be between 0 and 100")
-	}
-	if f.Precipitation < 0 {
-		return fmt.Errorf("precipitation cannot be negative")
-	}
-	if f.UVIndex < 0 {
-		return fmt.Errorf("uv_index cannot be negative")
-	}
-	return nil
+	v := &validator{}
+	v.check(f.CityID > 0, "city_id", "city_id must be positive")
+	v.check(f.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(!f.ForecastTime.IsZero(), "forecast_time", "forecast_time is required")
+	v.check(!f.ValidTime.IsZero(), "valid_time", "valid_time is required")
+	v.check(f.Temperature >= -273.15, "temperature", "temperature cannot be below absolute zero")
+	v.check(f.Humidity >= 0 && f.Humidity <= 100, "humidity", "humidity must be between 0 and 100")
+	v.check(f.Pressure >= 0, "pressure", "pressure cannot be negative")
+	v.check(f.StationPressure >= 0, "station_pressure", "station_pressure cannot be negative")
+	v.check(f.WindSpeed >= 0, "wind_speed", "wind_speed cannot be negative")
+	v.check(f.WindGust >= 0, "wind_gust", "wind_gust cannot be negative")
+	v.check(f.WindGust == 0 || f.WindGust >= f.WindSpeed, "wind_gust", "wind_gust cannot be lower than wind_speed")
+	v.check(f.WindDirection >= 0 && f.WindDirection < 360, "wind_direction", "wind_direction must be between 0 and 359 degrees")
+	v.check(f.CloudCover >= 0 && f.CloudCover <= 100, "cloud_cover", "cloud_cover must be between 0 and 100")
+	v.check(f.Precipitation >= 0, "precipitation", "precipitation cannot be negative")
+	v.check(f.UVIndex >= 0, "uv_index", "uv_index cannot be negative")
+	return v.err()
 }
 
 func (f *Forecast) TableName() string {
@@ -141,60 +127,63 @@ func (f *Forecast) TableName() string {
 
 // User Model interface implementation
 func (u *User) Validate() error {
-	if u.GitHubID <= 0 {
-		return fmt.Errorf("github_id must be positive")
-	}
-	if u.Username == "" {
-		return fmt.Errorf("username is required")
-	}
-	if len(u.Username) < 3 || len(u.Username) > 50 {
-		return fmt.Errorf("username must be between 3 and 50 characters")
-	}
-	if u.Email == "" {
-		return fmt.Errorf("email is required")
-	}
-	// Simple email validation
-	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
-	if !emailRegex.MatchString(u.Email) {
-		return fmt.Errorf("invalid email format")
-	}
-	if u.PreferredUnits != "" && u.PreferredUnits != "metric" && u.PreferredUnits != "imperial" {
-		return fmt.Errorf("preferred_units must be 'metric' or 'imperial'")
-	}
-	return nil
+	v := &validator{}
+	v.check(u.GitHubID > 0, "github_id", "github_id must be positive")
+	if v.check(u.Username != "", "username", "username is required") {
+		v.check(len(u.Username) >= 3 && len(u.Username) <= 50, "username", "username must be between 3 and 50 characters")
+	}
+	if v.check(u.Email != "", "email", "email is required") {
+		// Simple email validation
+		emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
+		v.check(emailRegex.MatchString(u.Email), "email", "invalid email format")
+	}
+	v.check(u.PreferredUnits == "" || u.PreferredUnits == "metric" || u.PreferredUnits == "imperial",
+		"preferred_units", "preferred_units must be 'metric' or 'imperial'")
+	v.check(u.Role == "" || RoleRank(u.Role) >= 0, "role", "role must be one of "+strings.Join(Roles, ", "))
+	return v.err()
 }
 
 func (u *User) TableName() string {
 	return "users"
 }
 
+// User roles, from most to least privileged
+const (
+	RoleAdmin    = "admin"    // every endpoint, including cleanup, provider status and the audit log
+	RoleUser     = "user"     // reads and writes to the user's own resources
+	RoleReadOnly = "readonly" // reads only
+)
+
+// Roles are the user roles, least privileged first
+var Roles = []string{RoleReadOnly, RoleUser, RoleAdmin}
+
+// RoleRank returns the position of a role in Roles, or -1 for unknown roles
+func RoleRank(role string) int {
+	return slices.Index(Roles, role)
+}
+
+// HasRole reports whether role grants at least the privileges of required
+func HasRole(role, required string) bool {
+	rank := RoleRank(role)
+	return rank >= 0 && rank >= RoleRank(required)
+}
+
 // City Model interface implementation
 func (c *City) Validate() error {
-	if c.Name == "" {
-		return fmt.Errorf("name is required")
-	}
-	if len(c.Name) > 255 {
-		return fmt.Errorf("name must be 255 characters or less")
-	}
-	if c.Country == "" {
-		return fmt.Errorf("country is required")
+	v := &validator{}
+	if v.check(c.Name != "", "name", "name is required") {
+		v.check(len(c.Name) <= 255, "name", "name must be 255 characters or less")
 	}
+	v.check(c.Country != "", "country", "country is required")
 	if c.CountryCode != "" {
-		if len(c.CountryCode) != 2 {
-			return fmt.Errorf("country_code must be 2 characters (ISO 3166-1 alpha-2)")
+		if v.check(len(c.CountryCode) == 2, "country_code", "country_code must be 2 characters (ISO 3166-1 alpha-2)") {
+			c.CountryCode = strings.ToUpper(c.CountryCode)
 		}
-		c.CountryCode = strings.ToUpper(c.CountryCode)
-	}
-	if c.Latitude < -90 || c.Latitude > 90 {
-		return fmt.Errorf("latitude must be between -90 and 90")
-	}
-	if c.Longitude < -180 || c.Longitude > 180 {
-		return fmt.Errorf("longitude must be between -180 and 180")
 	}
-	if c.Population < 0 {
-		return fmt.Errorf("population cannot be negative")
-	}
-	return nil
+	v.check(geo.ValidLatitude(c.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(c.Longitude), "longitude", "longitude must be between -180 and 180")
+	v.check(c.Population >= 0, "population", "population cannot be negative")
+	return v.err()
 }
 
 func (c *City) TableName() string {
@@ -203,33 +192,284 @@ func (c *City) TableName() string {
 
 // Place Model interface implementation
 func (p *Place) Validate() error {
-	if p.DisplayName == "" {
-		return fmt.Errorf("display_name is required")
-	}
-	if len(p.DisplayName) > 500 {
-		return fmt.Errorf("display_name must be 500 characters or less")
-	}
-	if p.Latitude < -90 || p.Latitude > 90 {
-		return fmt.Errorf("latitude must be between -90 and 90")
-	}
-	if p.Longitude < -180 || p.Longitude > 180 {
-		return fmt.Errorf("longitude must be between -180 and 180")
-	}
-	if p.Confidence < 0 || p.Confidence > 1 {
-		return fmt.Errorf("confidence must be between 0 and 1")
+	v := &validator{}
+	if v.check(p.DisplayName != "", "display_name", "display_name is required") {
+		v.check(len(p.DisplayName) <= 500, "display_name", "display_name must be 500 characters or less")
 	}
+	v.check(geo.ValidLatitude(p.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(p.Longitude), "longitude", "longitude must be between -180 and 180")
+	v.check(p.Confidence >= 0 && p.Confidence <= 1, "confidence", "confidence must be between 0 and 1")
 	if p.CountryCode != "" {
-		if len(p.CountryCode) != 2 {
-			return fmt.Errorf("country_code must be 2 characters (ISO 3166-1 alpha-2)")
+		if v.check(len(p.CountryCode) == 2, "country_code", "country_code must be 2 characters (ISO 3166-1 alpha-2)") {
+			p.CountryCode = strings.ToUpper(p.CountryCode)
 		}
-		p.CountryCode = strings.ToUpper(p.CountryCode)
-	}
-	if p.Source == "" {
-		return fmt.Errorf("source is required")
 	}
-	return nil
+	v.check(p.Source != "", "source", "source is required")
+	return v.err()
 }
 
 func (p *Place) TableName() string {
 	return "places"
 }
+
+// Alert represents a persisted weather alert/warning issued by a provider
+type Alert struct {
+	ID              int             `json:"id" db:"id"`
+	SourceProvider  string          `json:"source_provider" db:"source_provider"`
+	ProviderAlertID string          `json:"provider_alert_id" db:"provider_alert_id"` // upstream identifier, used for dedup
+	CityID          *int            `json:"city_id" db:"city_id"`
+	Latitude        float64         `json:"latitude" db:"latitude"`
+	Longitude       float64         `json:"longitude" db:"longitude"`
+	Title           string          `json:"title" db:"title"`
+	Description     string          `json:"description" db:"description"`
+	Severity        string          `json:"severity" db:"severity"` // minor, moderate, severe, extreme
+	Urgency         string          `json:"urgency" db:"urgency"`
+	Category        string          `json:"category" db:"category"`
+	AreaDesc        string          `json:"area_desc" db:"area_desc"`
+	Geometry        json.RawMessage `json:"geometry,omitempty" db:"geometry"` // GeoJSON Polygon or MultiPolygon of the area
+	StartTime       time.Time       `json:"start_time" db:"start_time"`
+	EndTime         time.Time       `json:"end_time" db:"end_time"`
+	CreatedAt       time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at" db:"updated_at"`
+}
+
+// AlertSeverities are the CAP severity levels, least severe first
+var AlertSeverities = []string{"Unknown", "Minor", "Moderate", "Severe", "Extreme"}
+
+// SeverityRank returns the position of a severity in AlertSeverities, ignoring case, or
+// -1 for values outside CAP's levels
+func SeverityRank(severity string) int {
+	for i, level := range AlertSeverities {
+		if strings.EqualFold(level, severity) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Alert Model interface implementation
+func (a *Alert) Validate() error {
+	v := &validator{}
+	v.check(a.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(a.ProviderAlertID != "", "provider_alert_id", "provider_alert_id is required")
+	v.check(a.Title != "", "title", "title is required")
+	v.check(a.CityID == nil || *a.CityID > 0, "city_id", "city_id must be positive")
+	v.check(geo.ValidLatitude(a.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(a.Longitude), "longitude", "longitude must be between -180 and 180")
+	v.check(a.StartTime.IsZero() || a.EndTime.IsZero() || !a.EndTime.Before(a.StartTime),
+		"end_time", "end_time must not be before start_time")
+	if len(a.Geometry) > 0 {
+		_, err := geo.ParseArea(a.Geometry)
+		v.check(err == nil, "geometry", "geometry must be a GeoJSON Polygon or MultiPolygon")
+	}
+	return v.err()
+}
+
+func (a *Alert) TableName() string {
+	return "alerts"
+}
+
+// Aviation report types
+const (
+	ReportTypeMETAR = "METAR"
+	ReportTypeTAF   = "TAF"
+)
+
+// AviationReport is a METAR observation or TAF forecast for an airport, kept verbatim
+// along with the measurements normalized to the units used by Forecast. Measurements
+// a report does not carry are nil.
+type AviationReport struct {
+	ID             int       `json:"id" db:"id"`
+	StationID      string    `json:"station_id" db:"station_id"` // ICAO airport code, e.g. KJFK
+	ReportType     string    `json:"report_type" db:"report_type"`
+	SourceProvider string    `json:"source_provider" db:"source_provider"`
+	RawText        string    `json:"raw_text" db:"raw_text"`
+	ObservedAt     time.Time `json:"observed_at" db:"observed_at"` // observation time (METAR) or issue time (TAF)
+	ValidFrom      time.Time `json:"valid_from" db:"valid_from"`   // TAF only
+	ValidTo        time.Time `json:"valid_to" db:"valid_to"`       // TAF only
+	Latitude       float64   `json:"latitude" db:"latitude"`
+	Longitude      float64   `json:"longitude" db:"longitude"`
+	Temperature    *float64  `json:"temperature" db:"temperature"`       // Celsius
+	Dewpoint       *float64  `json:"dewpoint" db:"dewpoint"`             // Celsius
+	WindDirection  *float64  `json:"wind_direction" db:"wind_direction"` // degrees, nil when variable
+	WindSpeed      *float64  `json:"wind_speed" db:"wind_speed"`         // m/s
+	WindGust       *float64  `json:"wind_gust" db:"wind_gust"`           // m/s
+	Visibility     *float64  `json:"visibility" db:"visibility"`         // km
+	Pressure       *float64  `json:"pressure" db:"pressure"`             // hPa (altimeter setting)
+	FlightCategory string    `json:"flight_category" db:"flight_category"` // VFR, MVFR, IFR, LIFR
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// icaoPattern matches a four-character ICAO location indicator
+var icaoPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{3}$`)
+
+// ValidICAO reports whether code is a well-formed ICAO location indicator
+func ValidICAO(code string) bool {
+	return icaoPattern.MatchString(code)
+}
+
+// AviationReport Model interface implementation
+func (a *AviationReport) Validate() error {
+	v := &validator{}
+	v.check(ValidICAO(a.StationID), "station_id", "station_id must be a 4-character ICAO code")
+	v.check(a.ReportType == ReportTypeMETAR || a.ReportType == ReportTypeTAF,
+		"report_type", "report_type must be METAR or TAF")
+	v.check(a.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(a.RawText != "", "raw_text", "raw_text is required")
+	v.check(!a.ObservedAt.IsZero(), "observed_at", "observed_at is required")
+	v.check(a.ValidFrom.IsZero() || a.ValidTo.IsZero() || !a.ValidTo.Before(a.ValidFrom),
+		"valid_to", "valid_to must not be before valid_from")
+	v.check(geo.ValidLatitude(a.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(a.Longitude), "longitude", "longitude must be between -180 and 180")
+	return v.err()
+}
+
+func (a *AviationReport) TableName() string {
+	return "aviation_reports"
+}
+
+// Station is a surface weather station that reports observations, identified by the
+// code its network assigns (e.g. KNYC for NWS)
+type Station struct {
+	ID             int       `json:"id" db:"id"`
+	StationID      string    `json:"station_id" db:"station_id"`
+	Name           string    `json:"name" db:"name"`
+	SourceProvider string    `json:"source_provider" db:"source_provider"`
+	Latitude       float64   `json:"latitude" db:"latitude"`
+	Longitude      float64   `json:"longitude" db:"longitude"`
+	Elevation      *float64  `json:"elevation" db:"elevation"` // meters
+	TimeZone       string    `json:"time_zone" db:"time_zone"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// stationPattern matches station codes: ICAO indicators and the longer codes of
+// cooperative and mesonet networks
+var stationPattern = regexp.MustCompile(`^[A-Z0-9]{3,10}$`)
+
+// ValidStationID reports whether id is a well-formed station code
+func ValidStationID(id string) bool {
+	return stationPattern.MatchString(id)
+}
+
+// Station Model interface implementation
+func (s *Station) Validate() error {
+	v := &validator{}
+	v.check(ValidStationID(s.StationID), "station_id", "station_id must be 3 to 10 uppercase letters or digits")
+	v.check(s.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(geo.ValidLatitude(s.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(s.Longitude), "longitude", "longitude must be between -180 and 180")
+	return v.err()
+}
+
+func (s *Station) TableName() string {
+	return "stations"
+}
+
+// Observation is a measurement reported by a station, kept apart from forecasts.
+// Measurements the station did not report are nil. QualityFlags holds the provider's
+// quality-control code for each measurement that has one, keyed by field name
+// (for NWS: V verified, C coarse pass, S screened, Z preliminary, Q questioned, X rejected).
+type Observation struct {
+	ID              int               `json:"id" db:"id"`
+	StationID       string            `json:"station_id" db:"station_id"`
+	SourceProvider  string            `json:"source_provider" db:"source_provider"`
+	ObservedAt      time.Time         `json:"observed_at" db:"observed_at"`
+	Temperature     *float64          `json:"temperature" db:"temperature"`           // Celsius
+	Dewpoint        *float64          `json:"dewpoint" db:"dewpoint"`                 // Celsius
+	Humidity        *float64          `json:"humidity" db:"humidity"`                 // Percentage
+	WindDirection   *float64          `json:"wind_direction" db:"wind_direction"`     // degrees
+	WindSpeed       *float64          `json:"wind_speed" db:"wind_speed"`             // m/s
+	WindGust        *float64          `json:"wind_gust" db:"wind_gust"`               // m/s
+	Pressure        *float64          `json:"pressure" db:"pressure"`                 // hPa, reduced to mean sea level
+	StationPressure *float64          `json:"station_pressure" db:"station_pressure"` // hPa at station elevation
+	Visibility      *float64          `json:"visibility" db:"visibility"`             // km
+	Description     string            `json:"description" db:"description"`
+	QualityFlags    map[string]string `json:"quality_flags" db:"qc_flags"`
+	CreatedAt       time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// Observation Model interface implementation
+func (o *Observation) Validate() error {
+	v := &validator{}
+	v.check(ValidStationID(o.StationID), "station_id", "station_id must be 3 to 10 uppercase letters or digits")
+	v.check(o.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(!o.ObservedAt.IsZero(), "observed_at", "observed_at is required")
+	v.check(o.Humidity == nil || *o.Humidity >= 0 && *o.Humidity <= 100, "humidity", "humidity must be between 0 and 100")
+	v.check(o.WindDirection == nil || *o.WindDirection >= 0 && *o.WindDirection <= 360,
+		"wind_direction", "wind_direction must be between 0 and 360")
+	return v.err()
+}
+
+func (o *Observation) TableName() string {
+	return "observations"
+}
+
+// AirQuality is a reading of air pollution at a point. AQI is the US EPA Air Quality
+// Index, the highest of the pollutants' sub-indices; concentrations the provider did
+// not report are nil.
+type AirQuality struct {
+	ID             int       `json:"id" db:"id"`
+	Latitude       float64   `json:"latitude" db:"latitude"`
+	Longitude      float64   `json:"longitude" db:"longitude"`
+	SourceProvider string    `json:"source_provider" db:"source_provider"`
+	ObservedAt     time.Time `json:"observed_at" db:"observed_at"`
+	AQI            int       `json:"aqi" db:"aqi"`
+	PM25           *float64  `json:"pm2_5" db:"pm2_5"` // µg/m³
+	PM10           *float64  `json:"pm10" db:"pm10"`   // µg/m³
+	Ozone          *float64  `json:"ozone" db:"ozone"` // µg/m³
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// AirQuality Model interface implementation
+func (a *AirQuality) Validate() error {
+	v := &validator{}
+	v.check(geo.ValidLatitude(a.Latitude), "latitude", "latitude must be between -90 and 90")
+	v.check(geo.ValidLongitude(a.Longitude), "longitude", "longitude must be between -180 and 180")
+	v.check(a.SourceProvider != "", "source_provider", "source_provider is required")
+	v.check(!a.ObservedAt.IsZero(), "observed_at", "observed_at is required")
+	v.check(a.AQI >= 0, "aqi", "aqi must not be negative")
+	return v.err()
+}
+
+func (a *AirQuality) TableName() string {
+	return "air_quality"
+}
+
+// AQICategory is a US EPA AQI band with its health guidance
+type AQICategory struct {
+	Name     string `json:"name"`
+	Color    string `json:"color"`
+	Guidance string `json:"guidance"`
+	maxAQI   int
+}
+
+// aqiCategories are the EPA bands in ascending order. The scale ends at 500 but
+// providers extrapolate beyond it during extreme events, which are Hazardous too.
+var aqiCategories = []AQICategory{
+	{Name: "Good", Color: "green", maxAQI: 50,
+		Guidance: "Air quality is satisfactory and poses little or no risk."},
+	{Name: "Moderate", Color: "yellow", maxAQI: 100,
+		Guidance: "Unusually sensitive people should consider reducing prolonged or heavy exertion outdoors."},
+	{Name: "Unhealthy for Sensitive Groups", Color: "orange", maxAQI: 150,
+		Guidance: "People with heart or lung disease, older adults, children and teens should reduce prolonged or heavy exertion outdoors."},
+	{Name: "Unhealthy", Color: "red", maxAQI: 200,
+		Guidance: "Everyone should reduce prolonged or heavy exertion outdoors; sensitive groups should avoid it."},
+	{Name: "Very Unhealthy", Color: "purple", maxAQI: 300,
+		Guidance: "Everyone should avoid prolonged or heavy exertion outdoors; sensitive groups should stay indoors."},
+	{Name: "Hazardous", Color: "maroon", maxAQI: 500,
+		Guidance: "Health warning of emergency conditions: everyone should avoid all physical activity outdoors."},
+}
+
+// CategorizeAQI returns the EPA category an AQI value falls in
+func CategorizeAQI(aqi int) AQICategory {
+	for _, category := range aqiCategories {
+		if aqi <= category.maxAQI {
+			return category
+		}
+	}
+	return aqiCategories[len(aqiCategories)-1]
+}
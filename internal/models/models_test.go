@@ -93,6 +93,19 @@ func TestForecastValidate(t *testing.T) {
 			expectError: true,
 			errorMsg:    "wind_direction must be between 0 and 359 degrees",
 		},
+		{
+			name: "wind gust below wind speed",
+			forecast: Forecast{
+				CityID:         1,
+				SourceProvider: "NOAA",
+				ForecastTime:   now,
+				ValidTime:      now.Add(time.Hour),
+				WindSpeed:      8.0,
+				WindGust:       5.0,
+			},
+			expectError: true,
+			errorMsg:    "wind_gust cannot be lower than wind_speed",
+		},
 	}
 
 	for _, tt := range tests {
@@ -207,6 +220,17 @@ func TestUserValidate(t *testing.T) {
 			expectError: true,
 			errorMsg:    "preferred_units must be 'metric' or 'imperial'",
 		},
+		{
+			name: "invalid role",
+			user: User{
+				GitHubID: 12345,
+				Username: "testuser",
+				Email:    "test@example.com",
+				Role:     "root",
+			},
+			expectError: true,
+			errorMsg:    "role must be one of readonly, user, admin",
+		},
 	}
 
 	for _, tt := range tests {
@@ -227,6 +251,25 @@ func TestUserValidate(t *testing.T) {
 	}
 }
 
+func TestHasRole(t *testing.T) {
+	tests := []struct {
+		role, required string
+		want           bool
+	}{
+		{RoleAdmin, RoleUser, true},
+		{RoleUser, RoleUser, true},
+		{RoleReadOnly, RoleUser, false},
+		{RoleUser, RoleAdmin, false},
+		{"", RoleReadOnly, false},
+		{"root", RoleReadOnly, false},
+	}
+	for _, tt := range tests {
+		if got := HasRole(tt.role, tt.required); got != tt.want {
+			t.Errorf("HasRole(%q, %q) = %v, want %v", tt.role, tt.required, got, tt.want)
+		}
+	}
+}
+
 func TestUserTableName(t *testing.T) {
 	u := &User{}
 	if got := u.TableName(); got != "users" {
@@ -487,11 +530,238 @@ func TestPlaceTableName(t *testing.T) {
 	}
 }
 
+func TestAlertValidate(t *testing.T) {
+	now := time.Now()
+	cityID := 0
+
+	tests := []struct {
+		name        string
+		alert       Alert
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "valid alert",
+			alert: Alert{
+				SourceProvider:  "NWS",
+				ProviderAlertID: "urn:oid:2.49.0.1.840.0.1",
+				Title:           "Flood Warning",
+				Latitude:        39.0,
+				Longitude:       -95.0,
+				StartTime:       now,
+				EndTime:         now.Add(time.Hour),
+			},
+			expectError: false,
+		},
+		{
+			name:        "missing source_provider",
+			alert:       Alert{ProviderAlertID: "abc", Title: "Flood Warning"},
+			expectError: true,
+			errorMsg:    "source_provider is required",
+		},
+		{
+			name:        "missing provider_alert_id",
+			alert:       Alert{SourceProvider: "NWS", Title: "Flood Warning"},
+			expectError: true,
+			errorMsg:    "provider_alert_id is required",
+		},
+		{
+			name:        "invalid city_id",
+			alert:       Alert{SourceProvider: "NWS", ProviderAlertID: "abc", Title: "Flood Warning", CityID: &cityID},
+			expectError: true,
+			errorMsg:    "city_id must be positive",
+		},
+		{
+			name: "end before start",
+			alert: Alert{
+				SourceProvider:  "NWS",
+				ProviderAlertID: "abc",
+				Title:           "Flood Warning",
+				StartTime:       now,
+				EndTime:         now.Add(-time.Hour),
+			},
+			expectError: true,
+			errorMsg:    "end_time must not be before start_time",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.alert.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestAlertTableName(t *testing.T) {
+	a := &Alert{}
+	if got := a.TableName(); got != "alerts" {
+		t.Errorf("expected 'alerts', got '%s'", got)
+	}
+}
+
+func TestAviationReportValidate(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		report      AviationReport
+		expectError bool
+		errorMsg    string
+	}{
+		{
+			name: "valid METAR",
+			report: AviationReport{
+				StationID:      "KJFK",
+				ReportType:     ReportTypeMETAR,
+				SourceProvider: "NOAA ADDS",
+				RawText:        "KJFK 121651Z 31015G25KT 10SM FEW250 M02/M14 A3012",
+				ObservedAt:     now,
+			},
+			expectError: false,
+		},
+		{
+			name:        "invalid station",
+			report:      AviationReport{StationID: "JFK", ReportType: ReportTypeMETAR, SourceProvider: "NOAA ADDS", RawText: "x", ObservedAt: now},
+			expectError: true,
+			errorMsg:    "station_id must be a 4-character ICAO code",
+		},
+		{
+			name:        "unknown report type",
+			report:      AviationReport{StationID: "EGLL", ReportType: "SPECI", SourceProvider: "NOAA ADDS", RawText: "x", ObservedAt: now},
+			expectError: true,
+			errorMsg:    "report_type must be METAR or TAF",
+		},
+		{
+			name: "TAF valid period reversed",
+			report: AviationReport{
+				StationID:      "EGLL",
+				ReportType:     ReportTypeTAF,
+				SourceProvider: "NOAA ADDS",
+				RawText:        "TAF EGLL ...",
+				ObservedAt:     now,
+				ValidFrom:      now,
+				ValidTo:        now.Add(-time.Hour),
+			},
+			expectError: true,
+			errorMsg:    "valid_to must not be before valid_from",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.report.Validate()
+			if tt.expectError {
+				if err == nil {
+					t.Errorf("expected error but got none")
+				} else if err.Error() != tt.errorMsg {
+					t.Errorf("expected error '%s', got '%s'", tt.errorMsg, err.Error())
+				}
+			} else if err != nil {
+				t.Errorf("expected no error but got: %v", err)
+			}
+		})
+	}
+}
+
+func TestObservationValidate(t *testing.T) {
+	now := time.Now()
+	humidity, direction := 105.0, 90.0
+
+	tests := []struct {
+		name        string
+		observation Observation
+		errorMsg    string
+	}{
+		{
+			name:        "valid",
+			observation: Observation{StationID: "KNYC", SourceProvider: "NWS", ObservedAt: now, WindDirection: &direction},
+		},
+		{
+			name:        "cooperative station code",
+			observation: Observation{StationID: "CO100", SourceProvider: "NWS", ObservedAt: now},
+		},
+		{
+			name:        "lowercase station",
+			observation: Observation{StationID: "knyc", SourceProvider: "NWS", ObservedAt: now},
+			errorMsg:    "station_id must be 3 to 10 uppercase letters or digits",
+		},
+		{
+			name:        "missing observation time",
+			observation: Observation{StationID: "KNYC", SourceProvider: "NWS"},
+			errorMsg:    "observed_at is required",
+		},
+		{
+			name:        "humidity out of range",
+			observation: Observation{StationID: "KNYC", SourceProvider: "NWS", ObservedAt: now, Humidity: &humidity},
+			errorMsg:    "humidity must be between 0 and 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.observation.Validate()
+			if tt.errorMsg == "" {
+				if err != nil {
+					t.Errorf("expected no error but got: %v", err)
+				}
+			} else if err == nil || err.Error() != tt.errorMsg {
+				t.Errorf("expected error '%s', got '%v'", tt.errorMsg, err)
+			}
+		})
+	}
+
+	station := Station{StationID: "KNYC", SourceProvider: "NWS", Latitude: 40.78, Longitude: 200}
+	if err := station.Validate(); err == nil || err.Error() != "longitude must be between -180 and 180" {
+		t.Errorf("expected a longitude error, got %v", err)
+	}
+}
+
+func TestAirQuality(t *testing.T) {
+	reading := AirQuality{Latitude: 40.71, Longitude: -74.01, SourceProvider: "Open-Meteo", ObservedAt: time.Now(), AQI: 42}
+	if err := reading.Validate(); err != nil {
+		t.Errorf("expected no error but got: %v", err)
+	}
+	reading.AQI = -1
+	if err := reading.Validate(); err == nil || err.Error() != "aqi must not be negative" {
+		t.Errorf("expected an aqi error, got %v", err)
+	}
+
+	tests := map[int]string{
+		0:   "Good",
+		50:  "Good",
+		51:  "Moderate",
+		101: "Unhealthy for Sensitive Groups",
+		200: "Unhealthy",
+		250: "Very Unhealthy",
+		301: "Hazardous",
+		650: "Hazardous",
+	}
+	for aqi, expected := range tests {
+		if got := CategorizeAQI(aqi); got.Name != expected {
+			t.Errorf("CategorizeAQI(%d) = %q, expected %q", aqi, got.Name, expected)
+		}
+	}
+}
+
 func TestModelInterface(t *testing.T) {
 	var _ Model = &Forecast{}
 	var _ Model = &User{}
 	var _ Model = &City{}
 	var _ Model = &Place{}
+	var _ Model = &Alert{}
+	var _ Model = &AviationReport{}
+	var _ Model = &Station{}
+	var _ Model = &Observation{}
+	var _ Model = &AirQuality{}
 }
 
 func TestCountryCodeNormalization(t *testing.T) {
@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// FieldError describes a single invalid field
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error returns the message
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationErrors collects every field error found by a Validate call, so callers can
+// report all problems at once instead of one per request
+type ValidationErrors []FieldError
+
+// Error joins the messages of all field errors
+func (v ValidationErrors) Error() string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// HasField reports whether any error is for field
+func (v ValidationErrors) HasField(field string) bool {
+	for _, e := range v {
+		if e.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// validator accumulates field errors for a Validate implementation
+type validator struct {
+	errs ValidationErrors
+}
+
+// check records message for field unless ok holds, and returns ok
+func (v *validator) check(ok bool, field, message string) bool {
+	if !ok {
+		v.errs = append(v.errs, FieldError{Field: field, Message: message})
+	}
+	return ok
+}
+
+// err returns the collected errors, or nil when there are none
+func (v *validator) err() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidationErrorsAggregate(t *testing.T) {
+	place := Place{Latitude: 95, Confidence: 2}
+
+	err := place.Validate()
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected ValidationErrors, got %T", err)
+	}
+
+	expected := []string{"display_name", "latitude", "confidence", "source"}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, field := range expected {
+		if errs[i].Field != field {
+			t.Errorf("error %d: expected field %s, got %s", i, field, errs[i].Field)
+		}
+	}
+
+	if err.Error() != "display_name is required; latitude must be between -90 and 90; confidence must be between 0 and 1; source is required" {
+		t.Errorf("unexpected message %q", err.Error())
+	}
+}
+
+func TestValidateReturnsNilInterface(t *testing.T) {
+	city := City{Name: "Oslo", Country: "Norway"}
+	if err := city.Validate(); err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
@@ -0,0 +1,254 @@
+// Package notify emails alert subscribers.
+//
+// Matching is done when an alert is written: a repository hook (see Queuer) queues the
+// alert for every subscription whose area and severity threshold it meets. A scheduled
+// job later drains the queue, sending immediate subscriptions one email per run and
+// digest subscriptions at most one per digest period. Every email carries a link that
+// removes its subscription without signing in.
+package notify
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// TokenLength is the length in bytes of generated unsubscribe tokens
+const TokenLength = 24
+
+// Message is one plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Config selects the SMTP server alerts are sent through
+type Config struct {
+	// URL is smtp://[user:password@]host[:port] (STARTTLS when offered, port 587 by
+	// default) or smtps:// (implicit TLS, port 465 by default); email is disabled when
+	// empty.
+	URL string
+
+	// From is the sender address
+	From string
+
+	// PublicURL is the externally reachable base URL unsubscribe links point at
+	PublicURL string
+}
+
+// Enabled reports whether an SMTP server is configured
+func (c Config) Enabled() bool { return c.URL != "" }
+
+// LoadConfig reads WEATHER_API_SMTP_URL, WEATHER_API_SMTP_FROM and
+// WEATHER_API_PUBLIC_URL (default http://localhost:8080)
+func LoadConfig() (Config, error) {
+	config := Config{
+		URL:       os.Getenv("WEATHER_API_SMTP_URL"),
+		From:      os.Getenv("WEATHER_API_SMTP_FROM"),
+		PublicURL: strings.TrimRight(os.Getenv("WEATHER_API_PUBLIC_URL"), "/"),
+	}
+	if config.PublicURL == "" {
+		config.PublicURL = "http://localhost:8080"
+	}
+	if !config.Enabled() {
+		return config, nil
+	}
+	if _, _, err := server(config.URL); err != nil {
+		return config, err
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return config, fmt.Errorf("invalid WEATHER_API_SMTP_FROM %q: must be an email address", config.From)
+	}
+	return config, nil
+}
+
+// UnsubscribeURL returns the link that removes the subscription with token
+func (c Config) UnsubscribeURL(token string) string {
+	return c.PublicURL + "/v1/alert-subscriptions/unsubscribe/" + url.PathEscape(token)
+}
+
+// GenerateToken returns a new random unsubscribe token, hex encoded
+func GenerateToken() (string, error) {
+	token := make([]byte, TokenLength)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("failed to generate unsubscribe token: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// server parses an SMTP URL into its address and whether it uses implicit TLS
+func server(raw string) (*url.URL, bool, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" {
+		return nil, false, fmt.Errorf("invalid WEATHER_API_SMTP_URL: expected smtp://host[:port] or smtps://host[:port]")
+	}
+	switch u.Scheme {
+	case "smtp":
+		return u, false, nil
+	case "smtps":
+		return u, true, nil
+	default:
+		return nil, false, fmt.Errorf("unsupported WEATHER_API_SMTP_URL scheme %q (supported: smtp, smtps)", u.Scheme)
+	}
+}
+
+// SMTPSender sends emails through an SMTP server
+type SMTPSender struct {
+	config  Config
+	timeout time.Duration
+}
+
+// NewSMTPSender creates a sender for config. Each send opens its own connection, as
+// sends are rare and a long-lived one would be dropped by most servers between them.
+func NewSMTPSender(config Config) *SMTPSender {
+	return &SMTPSender{config: config, timeout: 30 * time.Second}
+}
+
+// Send delivers msg, authenticating with the URL's credentials when present
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	u, implicitTLS, err := server(s.config.URL)
+	if err != nil {
+		return err
+	}
+	host, port := u.Hostname(), u.Port()
+	if port == "" {
+		port = "587"
+		if implicitTLS {
+			port = "465"
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	var conn net.Conn
+	dialer := &net.Dialer{}
+	if implicitTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: host}}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to SMTP server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("failed to start SMTP session: %w", err)
+	}
+	defer client.Close()
+
+	if !implicitTLS {
+		if ok, _ := client.Extension("STARTTLS"); ok {
+			if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+				return fmt.Errorf("failed to start TLS: %w", err)
+			}
+		}
+	}
+	if user := u.User.Username(); user != "" {
+		password, _ := u.User.Password()
+		if err := client.Auth(smtp.PlainAuth("", user, password, host)); err != nil {
+			return fmt.Errorf("SMTP authentication failed: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.config.From); err != nil {
+		return fmt.Errorf("SMTP server rejected sender: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("SMTP server rejected recipient: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if _, err := w.Write(Format(s.config.From, msg, time.Now())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return client.Quit()
+}
+
+// Format renders msg as an RFC 5322 message with CRLF line endings
+func Format(from string, msg Message, date time.Time) []byte {
+	var b strings.Builder
+	header := func(name, value string) {
+		b.WriteString(name + ": " + value + "\r\n")
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	header("Date", date.Format(time.RFC1123Z))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=utf-8")
+	header("Content-Transfer-Encoding", "8bit")
+	b.WriteString("\r\n")
+
+	body := strings.ReplaceAll(msg.Body, "\r\n", "\n")
+	for _, line := range strings.Split(body, "\n") {
+		b.WriteString(line + "\r\n")
+	}
+	return []byte(b.String())
+}
+
+// AlertEmail builds the email telling a subscriber about alerts. Digests list every
+// alert queued since the last email; immediate emails usually carry one.
+func AlertEmail(subscription *repo.AlertSubscription, alerts []*repo.Alert, unsubscribeURL string) Message {
+	subject := fmt.Sprintf("Weather alert: %s", alerts[0].Title)
+	if len(alerts) > 1 {
+		subject = fmt.Sprintf("%d weather alerts near you", len(alerts))
+	}
+	if subscription.Mode == repo.SubscriptionDigest {
+		subject = fmt.Sprintf("Weather alert digest: %d alert(s) near you", len(alerts))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Alerts within %g km of %.4f, %.4f at or above %s severity:\n",
+		subscription.RadiusKm, subscription.Latitude, subscription.Longitude, subscription.MinSeverity)
+	for _, alert := range alerts {
+		fmt.Fprintf(&b, "\n%s (%s)\n", alert.Title, alert.Severity)
+		if alert.AreaDesc != "" {
+			fmt.Fprintf(&b, "Area: %s\n", alert.AreaDesc)
+		}
+		if alert.StartTime != "" || alert.EndTime != "" {
+			fmt.Fprintf(&b, "In effect: %s to %s\n", orUnknown(alert.StartTime), orUnknown(alert.EndTime))
+		}
+		if alert.Description != "" {
+			b.WriteString(alert.Description + "\n")
+		}
+	}
+	fmt.Fprintf(&b, "\nTo stop these emails, visit %s\n", unsubscribeURL)
+
+	return Message{To: subscription.Email, Subject: subject, Body: b.String()}
+}
+
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
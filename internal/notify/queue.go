@@ -0,0 +1,81 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Queuer queues written alerts for the subscriptions they match
+type Queuer struct {
+	subscriptions repo.AlertSubscriptionRepository
+	onError       func(error)
+}
+
+// NewQueuer creates a queuer over subscriptions. onError, when not nil, is called with
+// failures, which never fail the alert write itself.
+func NewQueuer(subscriptions repo.AlertSubscriptionRepository, onError func(error)) *Queuer {
+	return &Queuer{subscriptions: subscriptions, onError: onError}
+}
+
+// Register subscribes the queuer to alert creates, upserts and updates. Each alert is
+// queued once per subscription, so an alert refreshed by later ingestion is not sent
+// again.
+func (q *Queuer) Register(hooks *repo.EngineHooks) {
+	hooks.Alerts.Register(repo.Hook[repo.Alert]{
+		Name: "notify",
+		After: func(ctx context.Context, mutation repo.Mutation[repo.Alert]) {
+			if mutation.Entity == nil || mutation.Entity.ID == 0 {
+				return
+			}
+			switch mutation.Op {
+			case repo.OpCreate, repo.OpUpsert, repo.OpUpdate:
+			default:
+				return
+			}
+			if _, err := q.Queue(ctx, mutation.Entity, time.Now()); err != nil && q.onError != nil {
+				q.onError(err)
+			}
+		},
+	})
+}
+
+// Queue queues alert for every subscription around it whose minimum severity it meets,
+// returning how many matched. Alerts that have ended are not queued.
+func (q *Queuer) Queue(ctx context.Context, alert *repo.Alert, now time.Time) (int, error) {
+	if end, err := time.Parse(time.RFC3339, alert.EndTime); err == nil && !end.After(now) {
+		return 0, nil
+	}
+	severities := Thresholds(alert.Severity)
+	if len(severities) == 0 {
+		return 0, nil
+	}
+
+	matching, err := q.subscriptions.ListMatching(ctx, alert.Latitude, alert.Longitude, severities)
+	if err != nil {
+		return 0, fmt.Errorf("failed to match alert %d to subscriptions: %w", alert.ID, err)
+	}
+	ids := make([]int, len(matching))
+	for i, subscription := range matching {
+		ids[i] = subscription.ID
+	}
+	if err := q.subscriptions.Queue(ctx, alert.ID, ids); err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// Thresholds returns the minimum severities an alert of severity satisfies: every
+// severity up to and including it. An unrecognised severity satisfies none.
+func Thresholds(severity string) []string {
+	rank := models.SeverityRank(strings.TrimSpace(severity))
+	if rank < 0 {
+		return nil
+	}
+	return slices.Clone(models.AlertSeverities[:rank+1])
+}
@@ -0,0 +1,50 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func TestQueuer(t *testing.T) {
+	ctx := context.Background()
+	base, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hooks := &repo.EngineHooks{}
+	engine := repo.NewHookedEngine(base, hooks)
+
+	var failures []error
+	NewQueuer(engine.AlertSubscriptions(), func(err error) { failures = append(failures, err) }).Register(hooks)
+
+	severe := &repo.AlertSubscription{UserID: 1, Email: "a@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 25, MinSeverity: "Severe", Mode: repo.SubscriptionImmediate, Token: "t1"}
+	minor := &repo.AlertSubscription{UserID: 2, Email: "b@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 25, MinSeverity: "Minor", Mode: repo.SubscriptionImmediate, Token: "t2"}
+	for _, subscription := range []*repo.AlertSubscription{severe, minor} {
+		if err := engine.AlertSubscriptions().Create(ctx, subscription); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moderate := &repo.Alert{SourceProvider: "NWS", ProviderAlertID: "a1", Title: "Heat Advisory", Severity: "Moderate", Latitude: 41.9, Longitude: -87.7}
+	if err := engine.Alerts().Upsert(ctx, moderate); err != nil {
+		t.Fatal(err)
+	}
+	ended := &repo.Alert{SourceProvider: "NWS", ProviderAlertID: "a2", Title: "Old Warning", Severity: "Extreme", Latitude: 41.9, Longitude: -87.7,
+		EndTime: time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)}
+	if err := engine.Alerts().Upsert(ctx, ended); err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 0 {
+		t.Fatalf("Unexpected queueing failures: %v", failures)
+	}
+
+	if pending, _ := engine.AlertSubscriptions().ListPending(ctx, minor.ID); len(pending) != 1 || pending[0].ID != moderate.ID {
+		t.Errorf("Expected the moderate alert queued for the Minor subscription, got %+v", pending)
+	}
+	if pending, _ := engine.AlertSubscriptions().ListPending(ctx, severe.ID); len(pending) != 0 {
+		t.Errorf("Expected nothing queued below the Severe threshold or already ended, got %+v", pending)
+	}
+}
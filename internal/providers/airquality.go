@@ -0,0 +1,157 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+)
+
+// AirQualityProvider fetches current air pollution readings for a location
+type AirQualityProvider interface {
+	// GetName returns the provider name
+	GetName() string
+
+	// GetAirQuality retrieves the current AQI and pollutant concentrations for a location
+	GetAirQuality(ctx context.Context, lat, lon float64) (*models.AirQuality, error)
+}
+
+// OpenMeteoAirQualityProvider implements AirQualityProvider for the Open-Meteo Air
+// Quality API, which covers the whole globe from CAMS model data and needs no API key
+type OpenMeteoAirQualityProvider struct {
+	BaseURL    string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// NewOpenMeteoAirQualityProvider creates a new Open-Meteo air quality provider
+func NewOpenMeteoAirQualityProvider() *OpenMeteoAirQualityProvider {
+	return &OpenMeteoAirQualityProvider{
+		BaseURL:   "https://air-quality-api.open-meteo.com",
+		UserAgent: "weather-api/1.0 (contact@example.com)",
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("Open-Meteo", requestlog.NewTransport("Open-Meteo", retry.NewTransport("Open-Meteo", ratelimit.NewTransport("Open-Meteo", breaker.NewTransport("Open-Meteo", nil))))))),
+		},
+	}
+}
+
+func (o *OpenMeteoAirQualityProvider) GetName() string {
+	return "Open-Meteo"
+}
+
+func (o *OpenMeteoAirQualityProvider) SupportedRegions() []string {
+	return []string{GlobalRegion}
+}
+
+// HealthCheck checks that the air quality API answers a request for a fixed point
+func (o *OpenMeteoAirQualityProvider) HealthCheck(ctx context.Context) error {
+	var response OpenMeteoAirQualityResponse
+	return o.makeRequest(ctx, o.currentURL(0, 0), &response)
+}
+
+// Open-Meteo Air Quality API response structures. Concentrations are in µg/m³ and
+// null where the model has no value.
+type OpenMeteoAirQualityResponse struct {
+	Latitude  float64                    `json:"latitude"`
+	Longitude float64                    `json:"longitude"`
+	Current   OpenMeteoAirQualityCurrent `json:"current"`
+}
+
+type OpenMeteoAirQualityCurrent struct {
+	Time  string   `json:"time"` // ISO 8601 in UTC, without seconds or offset
+	USAQI *float64 `json:"us_aqi"`
+	PM25  *float64 `json:"pm2_5"`
+	PM10  *float64 `json:"pm10"`
+	Ozone *float64 `json:"ozone"`
+}
+
+// openMeteoTimeLayout is the layout of Open-Meteo's "iso8601" time format
+const openMeteoTimeLayout = "2006-01-02T15:04"
+
+func (o *OpenMeteoAirQualityProvider) GetAirQuality(ctx context.Context, lat, lon float64) (*models.AirQuality, error) {
+	if err := validateCoordinates(o.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response OpenMeteoAirQualityResponse
+	if err := o.makeRequest(ctx, o.currentURL(lat, lon), &response); err != nil {
+		return nil, fmt.Errorf("failed to get air quality: %w", err)
+	}
+
+	current := response.Current
+	if current.USAQI == nil {
+		return nil, &ProviderError{Provider: o.GetName(), Kind: ErrUnsupportedRegion, Err: errors.New("no AQI for location")}
+	}
+	observed, err := time.Parse(openMeteoTimeLayout, current.Time)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse air quality time %q: %w", current.Time, err)
+	}
+
+	return &models.AirQuality{
+		Latitude:       lat,
+		Longitude:      lon,
+		SourceProvider: o.GetName(),
+		ObservedAt:     observed,
+		AQI:            int(math.Round(*current.USAQI)),
+		PM25:           current.PM25,
+		PM10:           current.PM10,
+		Ozone:          current.Ozone,
+	}, nil
+}
+
+func (o *OpenMeteoAirQualityProvider) currentURL(lat, lon float64) string {
+	query := url.Values{
+		"latitude":  {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"longitude": {strconv.FormatFloat(lon, 'f', 4, 64)},
+		"current":   {"us_aqi,pm2_5,pm10,ozone"},
+		"timezone":  {"GMT"},
+	}
+	return o.BaseURL + "/v1/air-quality?" + query.Encode()
+}
+
+func (o *OpenMeteoAirQualityProvider) makeRequest(ctx context.Context, url string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", o.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(o.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	// Open-Meteo answers 400 with a reason for coordinates it rejects
+	if resp.StatusCode == http.StatusBadRequest {
+		providerErr := statusError(o.GetName(), resp)
+		providerErr.Kind = ErrBadCoordinates
+		return providerErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(o.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenMeteoAirQualityProvider_MockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/v1/air-quality" || query.Get("current") != "us_aqi,pm2_5,pm10,ozone" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		switch query.Get("latitude") {
+		case "40.7128":
+			w.Write([]byte(`{"latitude": 40.7, "longitude": -74.0, "current": {
+				"time": "2025-08-11T14:00", "interval": 3600,
+				"us_aqi": 57.4, "pm2_5": 14.2, "pm10": 21.0, "ozone": null}}`))
+		case "0.0000":
+			w.Write([]byte(`{"latitude": 0, "longitude": 0, "current": {"time": "2025-08-11T14:00", "us_aqi": null}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": true, "reason": "Latitude must be in range of -90 to 90°."}`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewOpenMeteoAirQualityProvider()
+	provider.Configure(Endpoint{BaseURL: server.URL})
+
+	reading, err := provider.GetAirQuality(context.Background(), 40.7128, -74.0060)
+	if err != nil {
+		t.Fatalf("GetAirQuality failed: %v", err)
+	}
+	if reading.AQI != 57 || reading.SourceProvider != "Open-Meteo" {
+		t.Errorf("Expected AQI 57 from Open-Meteo, got %d from %s", reading.AQI, reading.SourceProvider)
+	}
+	if !reading.ObservedAt.Equal(time.Date(2025, time.August, 11, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the reading at 14:00 UTC, got %v", reading.ObservedAt)
+	}
+	if reading.PM25 == nil || *reading.PM25 != 14.2 || reading.Ozone != nil {
+		t.Errorf("Expected PM2.5 14.2 and no ozone, got %v and %v", reading.PM25, reading.Ozone)
+	}
+	if err := reading.Validate(); err != nil {
+		t.Errorf("Expected a valid reading, got %v", err)
+	}
+
+	if _, err := provider.GetAirQuality(context.Background(), 0, 0); !errors.Is(err, ErrUnsupportedRegion) {
+		t.Errorf("Expected ErrUnsupportedRegion without an AQI, got %v", err)
+	}
+	if _, err := provider.GetAirQuality(context.Background(), 95, 0); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates, got %v", err)
+	}
+	if _, err := provider.GetAirQuality(context.Background(), 45, 0); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates from a 400, got %v", err)
+	}
+}
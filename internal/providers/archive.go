@@ -0,0 +1,213 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// HistoricalProvider fetches past hourly weather for a location
+type HistoricalProvider interface {
+	// GetName returns the provider name
+	GetName() string
+
+	// GetHistoricalHourly retrieves hourly conditions for the UTC days from start to end,
+	// both inclusive. Hours the provider has no data for yet are left out.
+	GetHistoricalHourly(ctx context.Context, lat, lon float64, start, end time.Time) ([]*models.Forecast, error)
+}
+
+// OpenMeteoArchiveProvider implements HistoricalProvider for the Open-Meteo Historical
+// Weather API, which serves ERA5 reanalysis from 1940 onwards with a delay of about
+// five days and needs no API key
+type OpenMeteoArchiveProvider struct {
+	BaseURL    string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// NewOpenMeteoArchiveProvider creates a new Open-Meteo archive provider
+func NewOpenMeteoArchiveProvider() *OpenMeteoArchiveProvider {
+	return &OpenMeteoArchiveProvider{
+		BaseURL:   "https://archive-api.open-meteo.com",
+		UserAgent: "weather-api/1.0 (contact@example.com)",
+		HTTPClient: &http.Client{
+			Timeout:   60 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("Open-Meteo", requestlog.NewTransport("Open-Meteo", retry.NewTransport("Open-Meteo Archive", ratelimit.NewTransport("Open-Meteo Archive", breaker.NewTransport("Open-Meteo Archive", nil))))))),
+		},
+	}
+}
+
+func (o *OpenMeteoArchiveProvider) GetName() string {
+	return "Open-Meteo"
+}
+
+func (o *OpenMeteoArchiveProvider) SupportedRegions() []string {
+	return []string{GlobalRegion}
+}
+
+// HealthCheck checks that the archive API answers a request for one day at a fixed point
+func (o *OpenMeteoArchiveProvider) HealthCheck(ctx context.Context) error {
+	day := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var response OpenMeteoArchiveResponse
+	return o.makeRequest(ctx, o.archiveURL(0, 0, day, day), &response)
+}
+
+// Open-Meteo archive response structures. Each hourly variable is an array parallel to
+// Time, with null where the reanalysis has no value yet.
+type OpenMeteoArchiveResponse struct {
+	Latitude  float64                `json:"latitude"`
+	Longitude float64                `json:"longitude"`
+	Hourly    OpenMeteoArchiveHourly `json:"hourly"`
+}
+
+type OpenMeteoArchiveHourly struct {
+	Time            []string   `json:"time"` // ISO 8601 in UTC, without seconds or offset
+	Temperature     []*float64 `json:"temperature_2m"`
+	Humidity        []*float64 `json:"relative_humidity_2m"`
+	FeelsLike       []*float64 `json:"apparent_temperature"`
+	Pressure        []*float64 `json:"pressure_msl"`
+	StationPressure []*float64 `json:"surface_pressure"`
+	Precipitation   []*float64 `json:"precipitation"`
+	CloudCover      []*float64 `json:"cloud_cover"`
+	WindSpeed       []*float64 `json:"wind_speed_10m"`     // m/s
+	WindDirection   []*float64 `json:"wind_direction_10m"` // degrees
+	WindGust        []*float64 `json:"wind_gusts_10m"`     // m/s
+	WeatherCode     []*float64 `json:"weather_code"`       // WMO code
+}
+
+// archiveHourlyVariables are the hourly variables requested from the archive API
+const archiveHourlyVariables = "temperature_2m,relative_humidity_2m,apparent_temperature,pressure_msl," +
+	"surface_pressure,precipitation,cloud_cover,wind_speed_10m,wind_direction_10m,wind_gusts_10m,weather_code"
+
+// GetHistoricalHourly returns one forecast row per hour. Reanalysis has no issue time,
+// so each row's forecast time is its valid time.
+func (o *OpenMeteoArchiveProvider) GetHistoricalHourly(ctx context.Context, lat, lon float64, start, end time.Time) ([]*models.Forecast, error) {
+	if err := validateCoordinates(o.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date %s is before start date %s", end.Format(time.DateOnly), start.Format(time.DateOnly))
+	}
+
+	var response OpenMeteoArchiveResponse
+	if err := o.makeRequest(ctx, o.archiveURL(lat, lon, start, end), &response); err != nil {
+		return nil, fmt.Errorf("failed to get historical weather: %w", err)
+	}
+
+	hourly := response.Hourly
+	forecasts := make([]*models.Forecast, 0, len(hourly.Time))
+	for i, value := range hourly.Time {
+		temperature := archiveValue(hourly.Temperature, i)
+		if temperature == nil {
+			continue
+		}
+		valid, err := time.Parse(openMeteoTimeLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse archive time %q: %w", value, err)
+		}
+
+		forecast := &models.Forecast{
+			SourceProvider:  o.GetName(),
+			ForecastTime:    valid,
+			ValidTime:       valid,
+			Temperature:     *temperature,
+			Humidity:        archiveFloat(hourly.Humidity, i, 0),
+			Pressure:        archiveFloat(hourly.Pressure, i, 0),
+			StationPressure: archiveFloat(hourly.StationPressure, i, 0),
+			WindSpeed:       archiveFloat(hourly.WindSpeed, i, 0),
+			WindDirection:   math.Mod(archiveFloat(hourly.WindDirection, i, 0), 360),
+			WindGust:        archiveFloat(hourly.WindGust, i, 0),
+			CloudCover:      archiveFloat(hourly.CloudCover, i, 0),
+			Precipitation:   archiveFloat(hourly.Precipitation, i, 0),
+		}
+		forecast.FeelsLike = archiveFloat(hourly.FeelsLike, i,
+			units.Round(calc.FeelsLike(forecast.Temperature, forecast.Humidity, forecast.WindSpeed), 1))
+		// Reanalysis gusts are modelled separately and can come out below the mean wind
+		if forecast.WindGust < forecast.WindSpeed {
+			forecast.WindGust = 0
+		}
+		if code := archiveValue(hourly.WeatherCode, i); code != nil {
+			forecast.WeatherCode = strconv.Itoa(int(*code))
+		}
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// archiveValue returns the i-th value of an hourly variable, or nil when it is missing
+func archiveValue(values []*float64, i int) *float64 {
+	if i >= len(values) {
+		return nil
+	}
+	return values[i]
+}
+
+// archiveFloat returns the i-th value of an hourly variable, or fallback when it is
+// missing
+func archiveFloat(values []*float64, i int, fallback float64) float64 {
+	if value := archiveValue(values, i); value != nil {
+		return *value
+	}
+	return fallback
+}
+
+func (o *OpenMeteoArchiveProvider) archiveURL(lat, lon float64, start, end time.Time) string {
+	query := url.Values{
+		"latitude":        {strconv.FormatFloat(lat, 'f', 4, 64)},
+		"longitude":       {strconv.FormatFloat(lon, 'f', 4, 64)},
+		"start_date":      {start.UTC().Format(time.DateOnly)},
+		"end_date":        {end.UTC().Format(time.DateOnly)},
+		"hourly":          {archiveHourlyVariables},
+		"wind_speed_unit": {"ms"},
+		"timezone":        {"GMT"},
+	}
+	return o.BaseURL + "/v1/archive?" + query.Encode()
+}
+
+func (o *OpenMeteoArchiveProvider) makeRequest(ctx context.Context, url string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", o.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := o.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(o.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	// Open-Meteo answers 400 with a reason for coordinates and dates it rejects
+	if resp.StatusCode == http.StatusBadRequest {
+		providerErr := statusError(o.GetName(), resp)
+		providerErr.Kind = ErrBadCoordinates
+		return providerErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(o.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestOpenMeteoArchiveProvider_MockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != "/v1/archive" || query.Get("hourly") != archiveHourlyVariables || query.Get("wind_speed_unit") != "ms" {
+			t.Errorf("Unexpected request %s", r.URL)
+		}
+		if query.Get("start_date") != "2025-07-01" || query.Get("end_date") != "2025-07-02" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"error": true, "reason": "Parameter 'start_date' is out of allowed range"}`))
+			return
+		}
+		w.Write([]byte(`{"latitude": 40.7, "longitude": -74.0, "hourly": {
+			"time": ["2025-07-01T00:00", "2025-07-01T01:00", "2025-07-02T23:00"],
+			"temperature_2m": [24.1, 23.5, null],
+			"relative_humidity_2m": [61, 64, null],
+			"apparent_temperature": [25.3, null, null],
+			"pressure_msl": [1014.2, 1014.0, null],
+			"surface_pressure": [1012.9, 1012.7, null],
+			"precipitation": [0.0, 0.4, null],
+			"cloud_cover": [20, 100, null],
+			"wind_speed_10m": [3.2, 4.1, null],
+			"wind_direction_10m": [360, 200, null],
+			"wind_gusts_10m": [7.5, 3.9, null],
+			"weather_code": [1, 61, null]}}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenMeteoArchiveProvider()
+	provider.Configure(Endpoint{BaseURL: server.URL})
+
+	start := time.Date(2025, time.July, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	forecasts, err := provider.GetHistoricalHourly(context.Background(), 40.7128, -74.0060, start, end)
+	if err != nil {
+		t.Fatalf("GetHistoricalHourly failed: %v", err)
+	}
+	if len(forecasts) != 2 {
+		t.Fatalf("Expected the hour without data to be skipped, got %d forecasts", len(forecasts))
+	}
+
+	first, second := forecasts[0], forecasts[1]
+	if !first.ValidTime.Equal(start) || !first.ForecastTime.Equal(first.ValidTime) {
+		t.Errorf("Expected the first hour at %v for both times, got %v and %v", start, first.ForecastTime, first.ValidTime)
+	}
+	if first.Temperature != 24.1 || first.WindDirection != 0 || first.WeatherCode != "1" {
+		t.Errorf("Unexpected first hour %+v", first)
+	}
+	if second.FeelsLike != second.Temperature {
+		t.Errorf("Expected a missing feels-like to fall back to the temperature, got %v", second.FeelsLike)
+	}
+	if second.WindGust != 0 {
+		t.Errorf("Expected a gust below the mean wind to be dropped, got %v", second.WindGust)
+	}
+	for _, forecast := range forecasts {
+		forecast.CityID = 1
+		if err := forecast.Validate(); err != nil {
+			t.Errorf("Expected a valid forecast at %v, got %v", forecast.ValidTime, err)
+		}
+	}
+
+	if _, err := provider.GetHistoricalHourly(context.Background(), 40.7128, -74.0060, start.AddDate(-100, 0, 0), end); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates from a 400, got %v", err)
+	}
+	if _, err := provider.GetHistoricalHourly(context.Background(), 40.7128, -74.0060, end, start); err == nil {
+		t.Error("Expected an error for an end before the start")
+	}
+}
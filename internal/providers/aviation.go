@@ -0,0 +1,657 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// AviationProvider fetches routine aviation weather reports for airports identified by
+// ICAO code
+type AviationProvider interface {
+	// GetName returns the provider name
+	GetName() string
+
+	// GetMETARs retrieves the METARs (and SPECIs) issued for a station over the past hours
+	GetMETARs(ctx context.Context, icao string, hours int) ([]*models.AviationReport, error)
+
+	// GetTAF retrieves the current TAF for a station, or nil if the station issues none
+	GetTAF(ctx context.Context, icao string) (*models.AviationReport, error)
+}
+
+// ADDSProvider implements AviationProvider for the NOAA Aviation Weather Center's
+// Aviation Digital Data Service (ADDS) data API
+type ADDSProvider struct {
+	BaseURL    string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// NewADDSProvider creates a new NOAA ADDS aviation weather provider
+func NewADDSProvider() *ADDSProvider {
+	return &ADDSProvider{
+		BaseURL:   "https://aviationweather.gov",
+		UserAgent: "weather-api/1.0 (contact@example.com)",
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("NOAA ADDS", requestlog.NewTransport("NOAA ADDS", retry.NewTransport("NOAA ADDS", ratelimit.NewTransport("NOAA ADDS", breaker.NewTransport("NOAA ADDS", nil))))))),
+		},
+	}
+}
+
+func (a *ADDSProvider) GetName() string {
+	return "NOAA ADDS"
+}
+
+// ADDS API response structures. Only identifying fields are read: measurements are
+// decoded from the raw report text so METARs from every source are normalized the same way.
+type ADDSMETAR struct {
+	ICAOID  string  `json:"icaoId"`
+	ObsTime int64   `json:"obsTime"` // Unix seconds
+	RawOb   string  `json:"rawOb"`
+	Lat     float64 `json:"lat"`
+	Lon     float64 `json:"lon"`
+}
+
+type ADDSTAF struct {
+	ICAOID        string  `json:"icaoId"`
+	IssueTime     string  `json:"issueTime"`
+	ValidTimeFrom int64   `json:"validTimeFrom"` // Unix seconds
+	ValidTimeTo   int64   `json:"validTimeTo"`   // Unix seconds
+	RawTAF        string  `json:"rawTAF"`
+	Lat           float64 `json:"lat"`
+	Lon           float64 `json:"lon"`
+}
+
+func (a *ADDSProvider) GetMETARs(ctx context.Context, icao string, hours int) ([]*models.AviationReport, error) {
+	if hours <= 0 {
+		hours = 1
+	}
+	query := url.Values{"ids": {strings.ToUpper(icao)}, "format": {"json"}, "hours": {strconv.Itoa(hours)}}
+
+	var metars []ADDSMETAR
+	if err := a.makeRequest(ctx, a.BaseURL+"/api/data/metar?"+query.Encode(), &metars); err != nil {
+		return nil, fmt.Errorf("failed to get METARs: %w", err)
+	}
+
+	reports := make([]*models.AviationReport, 0, len(metars))
+	for _, m := range metars {
+		var observed time.Time
+		if m.ObsTime > 0 {
+			observed = time.Unix(m.ObsTime, 0).UTC()
+		}
+		report, err := METARToReport(m.RawOb, observed, a.GetName())
+		if err != nil {
+			continue // Skip reports we cannot decode
+		}
+		report.Latitude, report.Longitude = m.Lat, m.Lon
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
+
+func (a *ADDSProvider) GetTAF(ctx context.Context, icao string) (*models.AviationReport, error) {
+	query := url.Values{"ids": {strings.ToUpper(icao)}, "format": {"json"}}
+
+	var tafs []ADDSTAF
+	if err := a.makeRequest(ctx, a.BaseURL+"/api/data/taf?"+query.Encode(), &tafs); err != nil {
+		return nil, fmt.Errorf("failed to get TAF: %w", err)
+	}
+	if len(tafs) == 0 {
+		return nil, nil
+	}
+
+	t := tafs[0]
+	var issued time.Time
+	if t.IssueTime != "" {
+		if parsed, err := time.Parse(time.RFC3339, t.IssueTime); err == nil {
+			issued = parsed
+		}
+	}
+
+	report, err := TAFToReport(t.RawTAF, issued, a.GetName())
+	if err != nil {
+		return nil, err
+	}
+	if t.ValidTimeFrom > 0 && t.ValidTimeTo > 0 {
+		report.ValidFrom = time.Unix(t.ValidTimeFrom, 0).UTC()
+		report.ValidTo = time.Unix(t.ValidTimeTo, 0).UTC()
+	}
+	report.Latitude, report.Longitude = t.Lat, t.Lon
+	return report, nil
+}
+
+func (a *ADDSProvider) makeRequest(ctx context.Context, url string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", a.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(a.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	// ADDS answers 204 when a station has no data
+	if resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(a.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// Flight categories, from the ceiling and visibility
+const (
+	FlightCategoryVFR  = "VFR"
+	FlightCategoryMVFR = "MVFR"
+	FlightCategoryIFR  = "IFR"
+	FlightCategoryLIFR = "LIFR"
+)
+
+// AviationWind is a decoded wind group such as 31015G25KT or VRB03KT
+type AviationWind struct {
+	Direction    *int   `json:"direction"` // degrees true, nil when variable
+	Variable     bool   `json:"variable"`
+	Speed        int    `json:"speed"`
+	Gust         int    `json:"gust,omitempty"`
+	Unit         string `json:"unit"`                    // KT, MPS or KMH
+	VariableFrom *int   `json:"variable_from,omitempty"` // e.g. 280 in 280V350
+	VariableTo   *int   `json:"variable_to,omitempty"`
+}
+
+// AviationVisibility is a decoded prevailing visibility group such as 10SM, 1 1/2SM or 9999
+type AviationVisibility struct {
+	Value       float64 `json:"value"`
+	Unit        string  `json:"unit"` // SM or M
+	GreaterThan bool    `json:"greater_than,omitempty"`
+	LessThan    bool    `json:"less_than,omitempty"`
+}
+
+// AviationCloudLayer is a decoded sky condition group such as BKN035CB
+type AviationCloudLayer struct {
+	Cover string `json:"cover"`          // SKC, CLR, NSC, NCD, FEW, SCT, BKN, OVC or VV
+	Base  *int   `json:"base,omitempty"` // feet above ground level
+	Type  string `json:"type,omitempty"` // CB or TCU
+}
+
+// AviationConditions holds the groups shared by METARs and TAF forecast periods
+type AviationConditions struct {
+	Wind       *AviationWind        `json:"wind,omitempty"`
+	Visibility *AviationVisibility  `json:"visibility,omitempty"`
+	CAVOK      bool                 `json:"cavok,omitempty"`
+	Weather    []string             `json:"weather,omitempty"` // present weather, e.g. -RA, +TSRA, BR
+	Clouds     []AviationCloudLayer `json:"clouds,omitempty"`
+}
+
+// METAR is a decoded METAR or SPECI
+type METAR struct {
+	Station   string `json:"station"`
+	Type      string `json:"type"` // METAR or SPECI
+	Time      string `json:"time"` // DDHHMMZ group as issued
+	Auto      bool   `json:"auto,omitempty"`
+	Corrected bool   `json:"corrected,omitempty"`
+	AviationConditions
+	RunwayVisualRange []string `json:"runway_visual_range,omitempty"`
+	Temperature       *float64 `json:"temperature"` // Celsius
+	Dewpoint          *float64 `json:"dewpoint"`    // Celsius
+	Altimeter         *float64 `json:"altimeter"`
+	AltimeterUnit     string   `json:"altimeter_unit,omitempty"` // inHg or hPa
+	Remarks           string   `json:"remarks,omitempty"`
+}
+
+// TAF is a decoded terminal aerodrome forecast
+type TAF struct {
+	Station   string      `json:"station"`
+	Time      string      `json:"time"`       // DDHHMMZ issue time as issued
+	ValidFrom string      `json:"valid_from"` // DDHH
+	ValidTo   string      `json:"valid_to"`   // DDHH
+	Amended   bool        `json:"amended,omitempty"`
+	Corrected bool        `json:"corrected,omitempty"`
+	Periods   []TAFPeriod `json:"periods"`
+	Remarks   string      `json:"remarks,omitempty"`
+}
+
+// TAFPeriod is the base forecast or one change group of a TAF
+type TAFPeriod struct {
+	Change string `json:"change,omitempty"` // "", FM, BECMG, TEMPO, PROB30, PROB40, PROB30 TEMPO, ...
+	From   string `json:"from"`             // DDHHMM for FM groups, DDHH otherwise
+	To     string `json:"to,omitempty"`
+	AviationConditions
+}
+
+var (
+	windPattern       = regexp.MustCompile(`^(\d{3}|VRB)(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)$`)
+	windVarPattern    = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	visSMPattern      = regexp.MustCompile(`^([PM])?(\d+)?(?:(\d)/(\d{1,2}))?SM$`)
+	visMetersPattern  = regexp.MustCompile(`^(\d{4})(NDV)?$`)
+	cloudPattern      = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(CB|TCU)?$`)
+	weatherPattern    = regexp.MustCompile(`^(?:(\+|-|VC)?(MI|PR|BC|DR|BL|SH|TS|FZ)?(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)+|(?:VC)?(?:TS|SH))$`)
+	tempPattern       = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?$`)
+	altimeterPattern  = regexp.MustCompile(`^([AQ])(\d{4})$`)
+	rvrPattern        = regexp.MustCompile(`^R\d{2}[LCR]?/`)
+	dayTimePattern    = regexp.MustCompile(`^\d{6}Z$`)
+	validityPattern   = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+	fromPattern       = regexp.MustCompile(`^FM(\d{6})$`)
+	probPattern       = regexp.MustCompile(`^PROB\d{2}$`)
+	wholeMilesPattern = regexp.MustCompile(`^\d$`)
+)
+
+// parseGroup decodes one token into c, reporting whether it was recognized. next is the
+// following token, needed for split visibilities such as "1 1/2SM"; consumed reports
+// whether it was used.
+func (c *AviationConditions) parseGroup(token, next string) (ok, consumed bool) {
+	switch {
+	case token == "CAVOK":
+		c.CAVOK = true
+	case windPattern.MatchString(token):
+		m := windPattern.FindStringSubmatch(token)
+		wind := &AviationWind{Unit: m[4]}
+		wind.Speed, _ = strconv.Atoi(m[2])
+		if m[3] != "" {
+			wind.Gust, _ = strconv.Atoi(m[3])
+		}
+		if m[1] == "VRB" {
+			wind.Variable = true
+		} else {
+			direction, _ := strconv.Atoi(m[1])
+			wind.Direction = &direction
+		}
+		c.Wind = wind
+	case windVarPattern.MatchString(token) && c.Wind != nil:
+		m := windVarPattern.FindStringSubmatch(token)
+		from, _ := strconv.Atoi(m[1])
+		to, _ := strconv.Atoi(m[2])
+		c.Wind.VariableFrom, c.Wind.VariableTo = &from, &to
+	case wholeMilesPattern.MatchString(token) && visSMPattern.MatchString(next) && strings.Contains(next, "/"):
+		whole, _ := strconv.Atoi(token)
+		c.Visibility = parseStatuteMiles(next)
+		c.Visibility.Value += float64(whole)
+		return true, true
+	case visSMPattern.MatchString(token):
+		c.Visibility = parseStatuteMiles(token)
+	case visMetersPattern.MatchString(token) && c.Visibility == nil:
+		meters, _ := strconv.Atoi(visMetersPattern.FindStringSubmatch(token)[1])
+		c.Visibility = &AviationVisibility{Value: float64(meters), Unit: "M", GreaterThan: meters == 9999}
+	case token == "SKC" || token == "CLR" || token == "NSC" || token == "NCD":
+		c.Clouds = append(c.Clouds, AviationCloudLayer{Cover: token})
+	case cloudPattern.MatchString(token):
+		m := cloudPattern.FindStringSubmatch(token)
+		layer := AviationCloudLayer{Cover: m[1], Type: m[3]}
+		if hundreds, err := strconv.Atoi(m[2]); err == nil {
+			base := hundreds * 100
+			layer.Base = &base
+		}
+		c.Clouds = append(c.Clouds, layer)
+	case token == "NSW" || weatherPattern.MatchString(token):
+		c.Weather = append(c.Weather, token)
+	default:
+		return false, false
+	}
+	return true, false
+}
+
+// parseStatuteMiles decodes a visibility group known to match visSMPattern
+func parseStatuteMiles(token string) *AviationVisibility {
+	m := visSMPattern.FindStringSubmatch(token)
+	vis := &AviationVisibility{Unit: "SM", GreaterThan: m[1] == "P", LessThan: m[1] == "M"}
+	if m[2] != "" {
+		whole, _ := strconv.Atoi(m[2])
+		vis.Value = float64(whole)
+	}
+	if m[3] != "" {
+		num, _ := strconv.Atoi(m[3])
+		den, _ := strconv.Atoi(m[4])
+		if den > 0 {
+			vis.Value += float64(num) / float64(den)
+		}
+	}
+	return vis
+}
+
+// DecodeMETAR decodes a raw METAR or SPECI. Groups that are not understood are skipped
+// rather than rejected, since national practices add many optional groups.
+func DecodeMETAR(raw string) (*METAR, error) {
+	body, remarks, _ := strings.Cut(strings.TrimSuffix(strings.TrimSpace(raw), "="), " RMK")
+	tokens := strings.Fields(body)
+
+	metar := &METAR{Type: models.ReportTypeMETAR, Remarks: strings.TrimSpace(remarks)}
+	if len(tokens) > 0 && (tokens[0] == "METAR" || tokens[0] == "SPECI") {
+		metar.Type = tokens[0]
+		tokens = tokens[1:]
+	}
+	if len(tokens) > 0 && tokens[0] == "COR" {
+		metar.Corrected = true
+		tokens = tokens[1:]
+	}
+	if len(tokens) < 2 || !models.ValidICAO(tokens[0]) || !dayTimePattern.MatchString(tokens[1]) {
+		return nil, fmt.Errorf("not a METAR: %q", raw)
+	}
+	metar.Station, metar.Time = tokens[0], tokens[1]
+
+	for i := 2; i < len(tokens); i++ {
+		token := tokens[i]
+		next := ""
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch {
+		case token == "AUTO":
+			metar.Auto = true
+		case token == "COR":
+			metar.Corrected = true
+		case rvrPattern.MatchString(token):
+			metar.RunwayVisualRange = append(metar.RunwayVisualRange, token)
+		case tempPattern.MatchString(token):
+			m := tempPattern.FindStringSubmatch(token)
+			metar.Temperature = parseSignedCelsius(m[1])
+			metar.Dewpoint = parseSignedCelsius(m[2])
+		case altimeterPattern.MatchString(token):
+			m := altimeterPattern.FindStringSubmatch(token)
+			value, _ := strconv.ParseFloat(m[2], 64)
+			if m[1] == "A" {
+				value /= 100
+				metar.AltimeterUnit = "inHg"
+			} else {
+				metar.AltimeterUnit = "hPa"
+			}
+			metar.Altimeter = &value
+		default:
+			if _, consumed := metar.parseGroup(token, next); consumed {
+				i++
+			}
+		}
+	}
+
+	return metar, nil
+}
+
+// parseSignedCelsius parses a METAR temperature such as 12 or M05
+func parseSignedCelsius(value string) *float64 {
+	if value == "" {
+		return nil
+	}
+	negative := strings.HasPrefix(value, "M")
+	degrees, err := strconv.ParseFloat(strings.TrimPrefix(value, "M"), 64)
+	if err != nil {
+		return nil
+	}
+	if negative {
+		degrees = -degrees
+	}
+	return &degrees
+}
+
+// DecodeTAF decodes a raw TAF into its base forecast and change groups
+func DecodeTAF(raw string) (*TAF, error) {
+	body, remarks, _ := strings.Cut(strings.TrimSuffix(strings.TrimSpace(raw), "="), " RMK")
+	tokens := strings.Fields(body)
+
+	taf := &TAF{Remarks: strings.TrimSpace(remarks)}
+	for len(tokens) > 0 && (tokens[0] == "TAF" || tokens[0] == "AMD" || tokens[0] == "COR") {
+		taf.Amended = taf.Amended || tokens[0] == "AMD"
+		taf.Corrected = taf.Corrected || tokens[0] == "COR"
+		tokens = tokens[1:]
+	}
+	if len(tokens) < 2 || !models.ValidICAO(tokens[0]) {
+		return nil, fmt.Errorf("not a TAF: %q", raw)
+	}
+	taf.Station = tokens[0]
+	tokens = tokens[1:]
+	if dayTimePattern.MatchString(tokens[0]) {
+		taf.Time = tokens[0]
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 || !validityPattern.MatchString(tokens[0]) {
+		return nil, fmt.Errorf("TAF for %s has no valid period", taf.Station)
+	}
+	m := validityPattern.FindStringSubmatch(tokens[0])
+	taf.ValidFrom, taf.ValidTo = m[1], m[2]
+
+	period := &TAFPeriod{From: taf.ValidFrom, To: taf.ValidTo}
+	for i := 1; i < len(tokens); i++ {
+		token := tokens[i]
+		next := ""
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+
+		switch {
+		case fromPattern.MatchString(token):
+			taf.Periods = append(taf.Periods, *period)
+			period = &TAFPeriod{Change: "FM", From: fromPattern.FindStringSubmatch(token)[1]}
+		case token == "BECMG" || token == "TEMPO" || probPattern.MatchString(token):
+			change := token
+			// PROB30 TEMPO is a single change indicator
+			if probPattern.MatchString(token) && next == "TEMPO" {
+				change += " TEMPO"
+				i++
+			}
+			taf.Periods = append(taf.Periods, *period)
+			period = &TAFPeriod{Change: change}
+			if i+1 < len(tokens) && validityPattern.MatchString(tokens[i+1]) {
+				m := validityPattern.FindStringSubmatch(tokens[i+1])
+				period.From, period.To = m[1], m[2]
+				i++
+			}
+		default:
+			if _, consumed := period.parseGroup(token, next); consumed {
+				i++
+			}
+		}
+	}
+	taf.Periods = append(taf.Periods, *period)
+
+	return taf, nil
+}
+
+// METARToReport decodes a raw METAR into a normalized models.AviationReport. observed is
+// the observation time from the provider; when zero it is resolved from the report's
+// day/time group relative to now.
+func METARToReport(raw string, observed time.Time, provider string) (*models.AviationReport, error) {
+	metar, err := DecodeMETAR(raw)
+	if err != nil {
+		return nil, err
+	}
+	if observed.IsZero() {
+		observed = resolveDayTime(metar.Time, time.Now().UTC())
+	}
+
+	report := &models.AviationReport{
+		StationID:      metar.Station,
+		ReportType:     models.ReportTypeMETAR,
+		SourceProvider: provider,
+		RawText:        strings.TrimSpace(raw),
+		ObservedAt:     observed,
+		Temperature:    metar.Temperature,
+		Dewpoint:       metar.Dewpoint,
+		FlightCategory: metar.FlightCategory(),
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	report.WindDirection, report.WindSpeed, report.WindGust = metar.Wind.normalize()
+	report.Visibility = metar.visibilityKm()
+	if metar.Altimeter != nil {
+		hpa := *metar.Altimeter
+		if metar.AltimeterUnit == "inHg" {
+			hpa = units.Round(hpa/units.HectopascalsToInchesOfMercury(1), 1)
+		}
+		report.Pressure = &hpa
+	}
+
+	return report, nil
+}
+
+// TAFToReport decodes a raw TAF into a models.AviationReport whose normalized fields
+// describe the base forecast. issued is the issue time from the provider; when zero it
+// is resolved from the report relative to now.
+func TAFToReport(raw string, issued time.Time, provider string) (*models.AviationReport, error) {
+	taf, err := DecodeTAF(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	validFrom := resolveDayTime(taf.ValidFrom+"00Z", now)
+	// A TAF covers at most 30 hours, so its end is resolved relative to that bound
+	validTo := resolveDayTime(taf.ValidTo+"00Z", validFrom.Add(30*time.Hour))
+	if issued.IsZero() {
+		if taf.Time != "" {
+			issued = resolveDayTime(taf.Time, now)
+		} else {
+			issued = validFrom
+		}
+	}
+
+	report := &models.AviationReport{
+		StationID:      taf.Station,
+		ReportType:     models.ReportTypeTAF,
+		SourceProvider: provider,
+		RawText:        strings.TrimSpace(raw),
+		ObservedAt:     issued,
+		ValidFrom:      validFrom,
+		ValidTo:        validTo,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if len(taf.Periods) > 0 {
+		base := taf.Periods[0].AviationConditions
+		report.WindDirection, report.WindSpeed, report.WindGust = base.Wind.normalize()
+		report.Visibility = base.visibilityKm()
+		report.FlightCategory = base.FlightCategory()
+	}
+
+	return report, nil
+}
+
+// normalize returns the wind direction in degrees and the speed and gust in m/s
+func (w *AviationWind) normalize() (direction, speed, gust *float64) {
+	if w == nil {
+		return nil, nil, nil
+	}
+	toMS := func(v int) float64 {
+		switch w.Unit {
+		case "KT":
+			return units.Round(float64(v)/units.MetersPerSecondToKnots(1), 1)
+		case "KMH":
+			return units.Round(float64(v)/units.MetersPerSecondToKilometersPerHour(1), 1)
+		default:
+			return float64(v)
+		}
+	}
+
+	if w.Direction != nil {
+		d := float64(*w.Direction)
+		direction = &d
+	}
+	s := toMS(w.Speed)
+	speed = &s
+	if w.Gust > 0 {
+		g := toMS(w.Gust)
+		gust = &g
+	}
+	return direction, speed, gust
+}
+
+// visibilityKm returns the prevailing visibility in kilometers, treating CAVOK as 10 km
+func (c *AviationConditions) visibilityKm() *float64 {
+	var km float64
+	switch {
+	case c.Visibility != nil && c.Visibility.Unit == "SM":
+		km = units.Round(c.Visibility.Value/units.KilometersToMiles(1), 2)
+	case c.Visibility != nil:
+		km = c.Visibility.Value / 1000
+		if c.Visibility.GreaterThan {
+			km = 10
+		}
+	case c.CAVOK:
+		km = 10
+	default:
+		return nil
+	}
+	return &km
+}
+
+// FlightCategory classifies the conditions as VFR, MVFR, IFR or LIFR using the FAA
+// ceiling and visibility thresholds. It returns "" when visibility is unknown.
+func (c *AviationConditions) FlightCategory() string {
+	var miles float64
+	switch {
+	case c.CAVOK:
+		return FlightCategoryVFR
+	case c.Visibility == nil:
+		return ""
+	case c.Visibility.Unit == "SM":
+		miles = c.Visibility.Value
+	default:
+		miles = c.Visibility.Value / 1609.344
+	}
+
+	// The ceiling is the lowest broken, overcast or obscured layer
+	ceiling := -1
+	for _, layer := range c.Clouds {
+		if layer.Base == nil || (layer.Cover != "BKN" && layer.Cover != "OVC" && layer.Cover != "VV") {
+			continue
+		}
+		if ceiling < 0 || *layer.Base < ceiling {
+			ceiling = *layer.Base
+		}
+	}
+	below := func(feet int) bool { return ceiling >= 0 && ceiling < feet }
+
+	switch {
+	case below(500) || miles < 1:
+		return FlightCategoryLIFR
+	case below(1000) || miles < 3:
+		return FlightCategoryIFR
+	case below(3000) || miles <= 5:
+		return FlightCategoryMVFR
+	default:
+		return FlightCategoryVFR
+	}
+}
+
+// resolveDayTime resolves a DDHHMMZ group to the latest matching time not more than a
+// day after ref, since reports only carry the day of the month
+func resolveDayTime(group string, ref time.Time) time.Time {
+	if len(group) < 6 {
+		return time.Time{}
+	}
+	day, _ := strconv.Atoi(group[0:2])
+	hour, _ := strconv.Atoi(group[2:4])
+	minute, _ := strconv.Atoi(group[4:6])
+
+	t := time.Date(ref.Year(), ref.Month(), day, hour, minute, 0, 0, time.UTC)
+	if t.After(ref.Add(24 * time.Hour)) {
+		t = time.Date(ref.Year(), ref.Month()-1, day, hour, minute, 0, 0, time.UTC)
+	}
+	return t
+}
@@ -0,0 +1,202 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+func TestADDSProvider_GetName(t *testing.T) {
+	adds := NewADDSProvider()
+	if adds.GetName() != "NOAA ADDS" {
+		t.Errorf("expected name 'NOAA ADDS', got '%s'", adds.GetName())
+	}
+}
+
+func TestDecodeMETAR(t *testing.T) {
+	metar, err := DecodeMETAR("METAR KJFK 121651Z 31015G25KT 280V340 1 1/2SM R04R/2200FT -SN BR BKN008 OVC015 M02/M04 A2992 RMK AO2 SLP133")
+	if err != nil {
+		t.Fatalf("DecodeMETAR failed: %v", err)
+	}
+
+	if metar.Station != "KJFK" || metar.Time != "121651Z" || metar.Type != "METAR" {
+		t.Errorf("unexpected header %s %s %s", metar.Type, metar.Station, metar.Time)
+	}
+	if metar.Wind == nil || *metar.Wind.Direction != 310 || metar.Wind.Speed != 15 || metar.Wind.Gust != 25 || metar.Wind.Unit != "KT" {
+		t.Errorf("unexpected wind %+v", metar.Wind)
+	}
+	if metar.Wind.VariableFrom == nil || *metar.Wind.VariableFrom != 280 || *metar.Wind.VariableTo != 340 {
+		t.Errorf("expected variable wind 280V340, got %+v", metar.Wind)
+	}
+	if metar.Visibility == nil || metar.Visibility.Value != 1.5 || metar.Visibility.Unit != "SM" {
+		t.Errorf("expected 1.5SM visibility, got %+v", metar.Visibility)
+	}
+	if !slices.Equal(metar.Weather, []string{"-SN", "BR"}) {
+		t.Errorf("expected weather [-SN BR], got %v", metar.Weather)
+	}
+	if len(metar.Clouds) != 2 || metar.Clouds[0].Cover != "BKN" || *metar.Clouds[0].Base != 800 {
+		t.Errorf("unexpected clouds %+v", metar.Clouds)
+	}
+	if *metar.Temperature != -2 || *metar.Dewpoint != -4 {
+		t.Errorf("expected -2/-4, got %v/%v", *metar.Temperature, *metar.Dewpoint)
+	}
+	if *metar.Altimeter != 29.92 || metar.AltimeterUnit != "inHg" {
+		t.Errorf("expected altimeter 29.92 inHg, got %v %s", *metar.Altimeter, metar.AltimeterUnit)
+	}
+	if len(metar.RunwayVisualRange) != 1 || metar.Remarks != "AO2 SLP133" {
+		t.Errorf("unexpected RVR %v or remarks %q", metar.RunwayVisualRange, metar.Remarks)
+	}
+	if metar.FlightCategory() != FlightCategoryIFR {
+		t.Errorf("expected IFR, got %s", metar.FlightCategory())
+	}
+}
+
+func TestDecodeMETAR_International(t *testing.T) {
+	metar, err := DecodeMETAR("EGLL 121650Z AUTO VRB03KT CAVOK 08/03 Q1021 NOSIG=")
+	if err != nil {
+		t.Fatalf("DecodeMETAR failed: %v", err)
+	}
+	if !metar.Auto || !metar.CAVOK || !metar.Wind.Variable || metar.Wind.Direction != nil {
+		t.Errorf("unexpected decode %+v", metar)
+	}
+	if *metar.Altimeter != 1021 || metar.AltimeterUnit != "hPa" {
+		t.Errorf("expected Q1021, got %v %s", *metar.Altimeter, metar.AltimeterUnit)
+	}
+	if metar.FlightCategory() != FlightCategoryVFR {
+		t.Errorf("expected VFR, got %s", metar.FlightCategory())
+	}
+
+	if _, err := DecodeMETAR("not a report"); err == nil {
+		t.Error("expected error for garbage input")
+	}
+}
+
+func TestDecodeTAF(t *testing.T) {
+	raw := "TAF AMD KJFK 121730Z 1218/1324 31015G25KT P6SM SCT035 " +
+		"TEMPO 1218/1222 3SM -SHSN BKN025 " +
+		"FM130200 32010KT P6SM FEW250 " +
+		"PROB30 TEMPO 1310/1314 1SM BR OVC004"
+
+	taf, err := DecodeTAF(raw)
+	if err != nil {
+		t.Fatalf("DecodeTAF failed: %v", err)
+	}
+
+	if !taf.Amended || taf.Station != "KJFK" || taf.ValidFrom != "1218" || taf.ValidTo != "1324" {
+		t.Errorf("unexpected header %+v", taf)
+	}
+
+	var changes []string
+	for _, p := range taf.Periods {
+		changes = append(changes, p.Change)
+	}
+	if !slices.Equal(changes, []string{"", "TEMPO", "FM", "PROB30 TEMPO"}) {
+		t.Fatalf("unexpected periods %v", changes)
+	}
+
+	if base := taf.Periods[0]; base.Wind.Speed != 15 || !base.Visibility.GreaterThan || base.FlightCategory() != FlightCategoryVFR {
+		t.Errorf("unexpected base period %+v", base)
+	}
+	if tempo := taf.Periods[1]; tempo.From != "1218" || tempo.To != "1222" || tempo.FlightCategory() != FlightCategoryMVFR {
+		t.Errorf("unexpected TEMPO period %+v", tempo)
+	}
+	if fm := taf.Periods[2]; fm.From != "130200" || fm.Wind.Speed != 10 {
+		t.Errorf("unexpected FM period %+v", fm)
+	}
+	if prob := taf.Periods[3]; prob.FlightCategory() != FlightCategoryLIFR {
+		t.Errorf("expected LIFR PROB30 period, got %s", prob.FlightCategory())
+	}
+}
+
+func TestMETARToReport(t *testing.T) {
+	observed := time.Date(2024, 1, 12, 16, 51, 0, 0, time.UTC)
+	report, err := METARToReport("KJFK 121651Z 31015G25KT 10SM FEW250 M02/M14 A3012", observed, "NOAA ADDS")
+	if err != nil {
+		t.Fatalf("METARToReport failed: %v", err)
+	}
+	if err := report.Validate(); err != nil {
+		t.Errorf("report failed validation: %v", err)
+	}
+
+	if report.ReportType != models.ReportTypeMETAR || !report.ObservedAt.Equal(observed) {
+		t.Errorf("unexpected report %+v", report)
+	}
+	if *report.WindSpeed != 7.7 || *report.WindGust != 12.9 || *report.WindDirection != 310 {
+		t.Errorf("expected wind 310 at 7.7 m/s gusting 12.9, got %v %v %v", *report.WindDirection, *report.WindSpeed, *report.WindGust)
+	}
+	if *report.Visibility != 16.09 {
+		t.Errorf("expected 16.09 km visibility, got %v", *report.Visibility)
+	}
+	if *report.Pressure != 1020 {
+		t.Errorf("expected 1020 hPa, got %v", *report.Pressure)
+	}
+	if report.FlightCategory != FlightCategoryVFR {
+		t.Errorf("expected VFR, got %s", report.FlightCategory)
+	}
+}
+
+func TestResolveDayTime(t *testing.T) {
+	ref := time.Date(2024, 3, 1, 6, 0, 0, 0, time.UTC)
+
+	if got := resolveDayTime("010551Z", ref); !got.Equal(time.Date(2024, 3, 1, 5, 51, 0, 0, time.UTC)) {
+		t.Errorf("same day resolved to %v", got)
+	}
+	if got := resolveDayTime("291800Z", ref); !got.Equal(time.Date(2024, 2, 29, 18, 0, 0, 0, time.UTC)) {
+		t.Errorf("previous month resolved to %v", got)
+	}
+}
+
+func TestADDSProvider_MockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("ids") != "KJFK" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/data/metar":
+			_, _ = w.Write([]byte(`[{"icaoId":"KJFK","obsTime":1705078260,"wdir":"VRB",
+				"rawOb":"KJFK 121651Z VRB03KT 10SM FEW250 M02/M14 A3012","lat":40.64,"lon":-73.76}]`))
+		case "/api/data/taf":
+			_, _ = w.Write([]byte(`[{"icaoId":"KJFK","issueTime":"2024-01-12T17:30:00Z",
+				"validTimeFrom":1705082400,"validTimeTo":1705191840,
+				"rawTAF":"TAF KJFK 121730Z 1218/1324 31015KT P6SM SCT035","lat":40.64,"lon":-73.76}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	adds := NewADDSProvider()
+	adds.BaseURL = server.URL
+	ctx := context.Background()
+
+	metars, err := adds.GetMETARs(ctx, "kjfk", 2)
+	if err != nil {
+		t.Fatalf("GetMETARs failed: %v", err)
+	}
+	if len(metars) != 1 || metars[0].ObservedAt.Unix() != 1705078260 || metars[0].Latitude != 40.64 {
+		t.Errorf("unexpected METARs %+v", metars)
+	}
+	if metars[0].WindDirection != nil {
+		t.Errorf("expected nil direction for variable wind, got %v", *metars[0].WindDirection)
+	}
+
+	taf, err := adds.GetTAF(ctx, "KJFK")
+	if err != nil {
+		t.Fatalf("GetTAF failed: %v", err)
+	}
+	if taf == nil || taf.ValidFrom.Unix() != 1705082400 || taf.ObservedAt.Hour() != 17 {
+		t.Errorf("unexpected TAF %+v", taf)
+	}
+
+	none, err := adds.GetTAF(ctx, "KXXX")
+	if err != nil || none != nil {
+		t.Errorf("expected no TAF for unknown station, got %+v (%v)", none, err)
+	}
+}
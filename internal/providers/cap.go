@@ -0,0 +1,314 @@
+package providers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+// CAP message types, from the msgType element
+const (
+	CAPAlert  = "Alert"
+	CAPUpdate = "Update"
+	CAPCancel = "Cancel"
+)
+
+// capCirclePoints is the number of vertices a CAP circle is drawn with as a polygon
+const capCirclePoints = 32
+
+// CAPMessage is a decoded CAP 1.2 (Common Alerting Protocol) alert message, the format
+// non-NWS sources such as MeteoAlarm and Environment Canada publish warnings in
+type CAPMessage struct {
+	Identifier string
+	Sender     string
+	Sent       time.Time
+	Status     string // Actual, Exercise, System, Test or Draft
+	MsgType    string // Alert, Update, Cancel, Ack or Error
+
+	// References are the identifiers of the earlier messages an Update or Cancel
+	// replaces
+	References []string
+
+	// Alert is the message's info block in the chosen language mapped onto a
+	// WeatherAlert, with ID set to the message identifier
+	Alert WeatherAlert
+}
+
+// Actual reports whether the message is a real alert rather than an exercise, test or
+// draft
+func (m *CAPMessage) Actual() bool {
+	return m.Status == "Actual"
+}
+
+// CAP 1.2 document structures; encoding/xml matches the local names, so the
+// urn:oasis:names:tc:emergency:cap:1.2 namespace need not be spelled out
+type capAlert struct {
+	XMLName    xml.Name  `xml:"alert"`
+	Identifier string    `xml:"identifier"`
+	Sender     string    `xml:"sender"`
+	Sent       string    `xml:"sent"`
+	Status     string    `xml:"status"`
+	MsgType    string    `xml:"msgType"`
+	References string    `xml:"references"`
+	Info       []capInfo `xml:"info"`
+}
+
+type capInfo struct {
+	Language    string         `xml:"language"`
+	Category    []string       `xml:"category"`
+	Event       string         `xml:"event"`
+	Urgency     string         `xml:"urgency"`
+	Severity    string         `xml:"severity"`
+	Effective   string         `xml:"effective"`
+	Onset       string         `xml:"onset"`
+	Expires     string         `xml:"expires"`
+	Headline    string         `xml:"headline"`
+	Description string         `xml:"description"`
+	Parameters  []capParameter `xml:"parameter"`
+	Areas       []capArea      `xml:"area"`
+}
+
+type capParameter struct {
+	Name  string `xml:"valueName"`
+	Value string `xml:"value"`
+}
+
+type capArea struct {
+	AreaDesc string   `xml:"areaDesc"`
+	Polygons []string `xml:"polygon"`
+	Circles  []string `xml:"circle"`
+}
+
+// DecodeCAP decodes a CAP 1.2 alert message. Of its info blocks, the first in language
+// (matched by prefix, so "en" takes "en-CA") is mapped, else the first in English, else
+// the first of all. Severity is normalized with NormalizeCAPSeverity, and the polygons
+// and circles of its areas become one GeoJSON geometry.
+func DecodeCAP(data []byte, language string) (*CAPMessage, error) {
+	var doc capAlert
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid CAP document: %w", err)
+	}
+	if doc.Identifier == "" {
+		return nil, errors.New("invalid CAP document: identifier is required")
+	}
+	sent, err := time.Parse(time.RFC3339, strings.TrimSpace(doc.Sent))
+	if err != nil {
+		return nil, fmt.Errorf("invalid CAP document: sent: %w", err)
+	}
+
+	message := &CAPMessage{
+		Identifier: doc.Identifier,
+		Sender:     doc.Sender,
+		Sent:       sent,
+		Status:     doc.Status,
+		MsgType:    doc.MsgType,
+		References: capReferences(doc.References),
+		Alert:      WeatherAlert{ID: doc.Identifier},
+	}
+	info := chooseCAPInfo(doc.Info, language)
+	if info == nil {
+		if doc.MsgType == CAPCancel {
+			return message, nil
+		}
+		return nil, errors.New("invalid CAP document: no info block")
+	}
+
+	alert := &message.Alert
+	alert.Title = info.Event
+	if alert.Title == "" {
+		alert.Title = info.Headline
+	}
+	alert.Description = info.Description
+	alert.Severity = NormalizeCAPSeverity(info.Severity, info.parameter("awareness_level"))
+	alert.Urgency = strings.ToLower(info.Urgency)
+	if len(info.Category) > 0 {
+		alert.Category = strings.ToLower(info.Category[0])
+	}
+	for _, value := range []string{info.Onset, info.Effective} {
+		if start, err := time.Parse(time.RFC3339, strings.TrimSpace(value)); err == nil {
+			alert.StartTime = start
+			break
+		}
+	}
+	if alert.StartTime.IsZero() {
+		alert.StartTime = sent
+	}
+	if end, err := time.Parse(time.RFC3339, strings.TrimSpace(info.Expires)); err == nil {
+		alert.EndTime = end
+	}
+
+	var polygons [][][][2]float64
+	for _, area := range info.Areas {
+		if area.AreaDesc != "" {
+			alert.Areas = append(alert.Areas, area.AreaDesc)
+		}
+		for _, value := range area.Polygons {
+			ring, err := capPolygon(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CAP document: %w", err)
+			}
+			polygons = append(polygons, [][][2]float64{ring})
+		}
+		for _, value := range area.Circles {
+			ring, err := capCircle(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CAP document: %w", err)
+			}
+			if ring != nil {
+				polygons = append(polygons, [][][2]float64{ring})
+			}
+		}
+	}
+	if len(polygons) > 0 {
+		geometry, err := json.Marshal(map[string]any{"type": "MultiPolygon", "coordinates": polygons})
+		if err != nil {
+			return nil, err
+		}
+		alert.Geometry = geometry
+	}
+	return message, nil
+}
+
+// chooseCAPInfo picks the info block DecodeCAP maps
+func chooseCAPInfo(infos []capInfo, language string) *capInfo {
+	if len(infos) == 0 {
+		return nil
+	}
+	for _, want := range []string{language, "en"} {
+		if want == "" {
+			continue
+		}
+		for i := range infos {
+			// CAP's language defaults to en-US
+			have := infos[i].Language
+			if have == "" {
+				have = "en-US"
+			}
+			if strings.HasPrefix(strings.ToLower(have), strings.ToLower(want)) {
+				return &infos[i]
+			}
+		}
+	}
+	return &infos[0]
+}
+
+// capReferences returns the identifiers of a references element, a space-separated
+// list of sender,identifier,sent triples
+func capReferences(references string) []string {
+	var identifiers []string
+	for _, reference := range strings.Fields(references) {
+		if parts := strings.Split(reference, ","); len(parts) == 3 && parts[1] != "" {
+			identifiers = append(identifiers, parts[1])
+		}
+	}
+	return identifiers
+}
+
+// capAwarenessSeverities maps the MeteoAlarm awareness levels onto CAP severities
+var capAwarenessSeverities = map[string]string{
+	"green":  "minor",
+	"yellow": "moderate",
+	"orange": "severe",
+	"red":    "extreme",
+}
+
+// NormalizeCAPSeverity returns a CAP severity in the lowercase form stored alerts use
+// ("minor", "moderate", "severe", "extreme" or "unknown"). A missing or unknown
+// severity falls back to the color of a MeteoAlarm awareness level such as
+// "3; orange; Severe" when one is given.
+func NormalizeCAPSeverity(severity, awarenessLevel string) string {
+	if rank := models.SeverityRank(strings.TrimSpace(severity)); rank > 0 {
+		return strings.ToLower(models.AlertSeverities[rank])
+	}
+	for _, field := range strings.Split(awarenessLevel, ";") {
+		if level, ok := capAwarenessSeverities[strings.ToLower(strings.TrimSpace(field))]; ok {
+			return level
+		}
+	}
+	return "unknown"
+}
+
+// parameter returns the value of the info block's parameter called name, empty when
+// it has none
+func (i *capInfo) parameter(name string) string {
+	for _, parameter := range i.Parameters {
+		if parameter.Name == name {
+			return parameter.Value
+		}
+	}
+	return ""
+}
+
+// capPolygon parses a CAP polygon, space-separated "lat,lon" pairs whose first and last
+// are the same, into a GeoJSON ring
+func capPolygon(value string) ([][2]float64, error) {
+	var ring [][2]float64
+	for _, pair := range strings.Fields(value) {
+		lat, lon, err := capPoint(pair)
+		if err != nil {
+			return nil, fmt.Errorf("polygon: %w", err)
+		}
+		ring = append(ring, [2]float64{lon, lat})
+	}
+	if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+		return nil, errors.New("polygon: needs 4 or more points, the first repeated last")
+	}
+	return ring, nil
+}
+
+// capCircle draws a CAP circle, "lat,lon radius" with the radius in km, as a GeoJSON
+// ring. A circle of radius 0 marks a point and has no ring.
+func capCircle(value string) ([][2]float64, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return nil, fmt.Errorf("circle: want \"lat,lon radius\", got %q", value)
+	}
+	lat, lon, err := capPoint(fields[0])
+	if err != nil {
+		return nil, fmt.Errorf("circle: %w", err)
+	}
+	radius, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil || radius < 0 {
+		return nil, fmt.Errorf("circle: invalid radius %q", fields[1])
+	}
+	if radius == 0 {
+		return nil, nil
+	}
+
+	latRadius := radius / geo.EarthRadiusKm * 180 / math.Pi
+	lonRadius := latRadius / math.Max(math.Cos(lat*math.Pi/180), 0.01)
+	ring := make([][2]float64, 0, capCirclePoints+1)
+	for i := range capCirclePoints {
+		angle := 2 * math.Pi * float64(i) / capCirclePoints
+		ring = append(ring, [2]float64{
+			geo.NormalizeLongitude(lon + lonRadius*math.Cos(angle)),
+			math.Max(-90, math.Min(90, lat+latRadius*math.Sin(angle))),
+		})
+	}
+	return append(ring, ring[0]), nil
+}
+
+// capPoint parses a CAP "lat,lon" pair
+func capPoint(pair string) (float64, float64, error) {
+	latStr, lonStr, ok := strings.Cut(pair, ",")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid point %q", pair)
+	}
+	lat, latErr := strconv.ParseFloat(latStr, 64)
+	lon, lonErr := strconv.ParseFloat(lonStr, 64)
+	if latErr != nil || lonErr != nil {
+		return 0, 0, fmt.Errorf("invalid point %q", pair)
+	}
+	if err := geo.Validate(lat, lon); err != nil {
+		return 0, 0, fmt.Errorf("invalid point %q: %w", pair, err)
+	}
+	return lat, lon, nil
+}
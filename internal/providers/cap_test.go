@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+)
+
+// capEnvironmentCanada is a trimmed Environment Canada CAP-CP warning with English and
+// French info blocks
+const capEnvironmentCanada = `<?xml version="1.0" encoding="UTF-8"?>
+<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+  <identifier>urn:oid:2.49.0.1.124.1234567890.2025</identifier>
+  <sender>cap-pac@canada.ca</sender>
+  <sent>2025-07-14T18:05:00-00:00</sent>
+  <status>Actual</status>
+  <msgType>Update</msgType>
+  <scope>Public</scope>
+  <references>cap-pac@canada.ca,urn:oid:2.49.0.1.124.0987654321.2025,2025-07-14T15:00:00-00:00</references>
+  <info>
+    <language>fr-CA</language>
+    <category>Met</category>
+    <event>orages violents</event>
+    <urgency>Immediate</urgency>
+    <severity>Moderate</severity>
+    <certainty>Likely</certainty>
+    <expires>2025-07-15T02:00:00-00:00</expires>
+    <description>Des orages violents sont possibles.</description>
+    <area><areaDesc>Ville d'Ottawa</areaDesc></area>
+  </info>
+  <info>
+    <language>en-CA</language>
+    <category>Met</category>
+    <event>severe thunderstorm</event>
+    <urgency>Immediate</urgency>
+    <severity>Moderate</severity>
+    <certainty>Likely</certainty>
+    <effective>2025-07-14T18:05:00-00:00</effective>
+    <expires>2025-07-15T02:00:00-00:00</expires>
+    <headline>severe thunderstorm watch in effect</headline>
+    <description>Conditions are favourable for severe thunderstorms.</description>
+    <area>
+      <areaDesc>City of Ottawa</areaDesc>
+      <polygon>45.2,-76.0 45.2,-75.4 45.5,-75.4 45.5,-76.0 45.2,-76.0</polygon>
+    </area>
+  </info>
+</alert>`
+
+// capMeteoAlarm is a trimmed MeteoAlarm warning placed by a circle, with its severity
+// only in the awareness level
+const capMeteoAlarm = `<alert xmlns="urn:oasis:names:tc:emergency:cap:1.2">
+  <identifier>2.49.0.0.276.0.DWD.PVW.1752500000000</identifier>
+  <sender>opendata@dwd.de</sender>
+  <sent>2025-07-14T12:00:00+02:00</sent>
+  <status>Actual</status>
+  <msgType>Alert</msgType>
+  <info>
+    <language>de-DE</language>
+    <category>Met</category>
+    <event>STARKREGEN</event>
+    <urgency>Future</urgency>
+    <severity>Unknown</severity>
+    <onset>2025-07-14T14:00:00+02:00</onset>
+    <expires>2025-07-14T20:00:00+02:00</expires>
+    <parameter><valueName>awareness_level</valueName><value>3; orange; Severe</value></parameter>
+    <area><areaDesc>Stadt Berlin</areaDesc><circle>52.52,13.405 10</circle></area>
+  </info>
+</alert>`
+
+func TestDecodeCAP(t *testing.T) {
+	t.Run("Environment Canada", func(t *testing.T) {
+		message, err := DecodeCAP([]byte(capEnvironmentCanada), "")
+		if err != nil {
+			t.Fatalf("DecodeCAP failed: %v", err)
+		}
+		if !message.Actual() || message.MsgType != CAPUpdate || len(message.References) != 1 ||
+			message.References[0] != "urn:oid:2.49.0.1.124.0987654321.2025" {
+			t.Errorf("Unexpected message header: %+v", message)
+		}
+
+		alert := message.Alert
+		if alert.ID != message.Identifier || alert.Title != "severe thunderstorm" || alert.Severity != "moderate" ||
+			alert.Urgency != "immediate" || alert.Category != "met" {
+			t.Errorf("Expected the English info block mapped, got %+v", alert)
+		}
+		if !alert.StartTime.Equal(time.Date(2025, 7, 14, 18, 5, 0, 0, time.UTC)) || !alert.EndTime.Equal(time.Date(2025, 7, 15, 2, 0, 0, 0, time.UTC)) {
+			t.Errorf("Unexpected times %v to %v", alert.StartTime, alert.EndTime)
+		}
+		area, err := geo.ParseArea(alert.Geometry)
+		if err != nil || !area.Contains(45.42, -75.69) || area.Contains(45.42, -75.2) {
+			t.Errorf("Expected the polygon to cover Ottawa, got %s (%v)", alert.Geometry, err)
+		}
+
+		french, err := DecodeCAP([]byte(capEnvironmentCanada), "fr")
+		if err != nil || french.Alert.Title != "orages violents" || len(french.Alert.Geometry) != 0 {
+			t.Errorf("Expected the French info block, got %+v (%v)", french, err)
+		}
+	})
+
+	t.Run("MeteoAlarm", func(t *testing.T) {
+		message, err := DecodeCAP([]byte(capMeteoAlarm), "en")
+		if err != nil {
+			t.Fatalf("DecodeCAP failed: %v", err)
+		}
+		alert := message.Alert
+		if alert.Title != "STARKREGEN" || alert.Severity != "severe" || alert.StartTime.Hour() != 14 {
+			t.Errorf("Expected the only info block with the awareness level's severity, got %+v", alert)
+		}
+		area, err := geo.ParseArea(alert.Geometry)
+		if err != nil || !area.Contains(52.52, 13.405) || !area.Contains(52.57, 13.405) || area.Contains(52.62, 13.405) {
+			t.Errorf("Expected a 10 km circle around Berlin, got %s (%v)", alert.Geometry, err)
+		}
+	})
+
+	t.Run("Invalid documents", func(t *testing.T) {
+		for _, doc := range []string{
+			`not xml`,
+			`<alert><sent>2025-07-14T12:00:00Z</sent></alert>`,
+			`<alert><identifier>x</identifier><sent>yesterday</sent></alert>`,
+			`<alert><identifier>x</identifier><sent>2025-07-14T12:00:00Z</sent><msgType>Alert</msgType></alert>`,
+			`<alert><identifier>x</identifier><sent>2025-07-14T12:00:00Z</sent><info><area><polygon>45,-76 45,-75 46,-75</polygon></area></info></alert>`,
+		} {
+			if _, err := DecodeCAP([]byte(doc), ""); err == nil {
+				t.Errorf("Expected an error for %s", doc)
+			}
+		}
+	})
+}
+
+func TestNormalizeCAPSeverity(t *testing.T) {
+	tests := []struct {
+		severity, awareness, want string
+	}{
+		{"Extreme", "", "extreme"},
+		{"minor", "", "minor"},
+		{"Unknown", "2; yellow; Moderate", "moderate"},
+		{"", "4; red; Extreme", "extreme"},
+		{"Catastrophic", "", "unknown"},
+		{"", "", "unknown"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCAPSeverity(tt.severity, tt.awareness); got != tt.want {
+			t.Errorf("NormalizeCAPSeverity(%q, %q) = %q, want %q", tt.severity, tt.awareness, got, tt.want)
+		}
+	}
+}
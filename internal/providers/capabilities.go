@@ -0,0 +1,78 @@
+package providers
+
+import "context"
+
+// Provider types reported by Describe
+const (
+	TypeWeather    = "weather"
+	TypeGeocode    = "geocode"
+	TypeAirQuality = "air_quality"
+)
+
+// Operations reported by Describe. Weather providers always serve current conditions,
+// forecasts and alerts; the others depend on the optional interfaces they implement.
+const (
+	OperationCurrent        = "current"
+	OperationForecast       = "forecast"
+	OperationAlerts         = "alerts"
+	OperationHourly         = "hourly"
+	OperationNowcast        = "nowcast"
+	OperationObservations   = "observations"
+	OperationGeocode        = "geocode"
+	OperationReverseGeocode = "reverse_geocode"
+	OperationAirQuality     = "air_quality"
+)
+
+// HealthChecker is implemented by providers that can check their upstream is reachable
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// Description is what a registered provider offers
+type Description struct {
+	Name       string
+	Type       string
+	Regions    []string // ISO 3166-1 alpha-2 codes or GlobalRegion; nil when unknown
+	Operations []string
+	Priority   int           // 1 for the first provider of its type to be tried
+	Health     HealthChecker // nil when the provider cannot be checked
+}
+
+// Describe lists every registered provider by type (weather, geocode, then air
+// quality) and in the order each type's providers are tried
+func (pm *ProviderManager) Describe() []Description {
+	var descriptions []Description
+	for i, provider := range pm.weatherProviders {
+		operations := []string{OperationCurrent, OperationForecast, OperationAlerts}
+		if _, ok := provider.(HourlyForecaster); ok {
+			operations = append(operations, OperationHourly)
+		}
+		if _, ok := provider.(Nowcaster); ok {
+			operations = append(operations, OperationNowcast)
+		}
+		if _, ok := provider.(StationObserver); ok {
+			operations = append(operations, OperationObservations)
+		}
+		descriptions = append(descriptions, describe(provider.GetName(), TypeWeather, provider.SupportedRegions(), operations, i, provider))
+	}
+	for i, provider := range pm.geocodeProviders {
+		operations := []string{OperationGeocode, OperationReverseGeocode}
+		descriptions = append(descriptions, describe(provider.GetName(), TypeGeocode, provider.SupportedRegions(), operations, i, provider))
+	}
+	for i, provider := range pm.airQualityProviders {
+		var regions []string
+		if regional, ok := provider.(interface{ SupportedRegions() []string }); ok {
+			regions = regional.SupportedRegions()
+		}
+		descriptions = append(descriptions, describe(provider.GetName(), TypeAirQuality, regions, []string{OperationAirQuality}, i, provider))
+	}
+	return descriptions
+}
+
+func describe(name, kind string, regions, operations []string, index int, provider any) Description {
+	description := Description{Name: name, Type: kind, Regions: regions, Operations: operations, Priority: index + 1}
+	if checker, ok := provider.(HealthChecker); ok {
+		description.Health = checker
+	}
+	return description
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestProviderManager_Describe(t *testing.T) {
+	pm := NewProviderManager()
+	pm.RegisterWeatherProvider(NewNWSProvider())
+	pm.RegisterWeatherProvider(&MockWeatherProvider{name: "Met.no"})
+	pm.RegisterWeatherProvider(NewTomorrowIOProvider("key"))
+	pm.RegisterGeocodeProvider(NewCensusProvider())
+	pm.RegisterAirQualityProvider(NewOpenMeteoAirQualityProvider())
+
+	descriptions := pm.Describe()
+	if len(descriptions) != 5 {
+		t.Fatalf("Expected 5 providers, got %d", len(descriptions))
+	}
+
+	nws := descriptions[0]
+	if nws.Name != "NWS" || nws.Type != TypeWeather || nws.Priority != 1 || nws.Health == nil {
+		t.Errorf("Unexpected NWS description %+v", nws)
+	}
+	if !slices.Contains(nws.Operations, OperationHourly) || !slices.Contains(nws.Operations, OperationObservations) || slices.Contains(nws.Operations, OperationNowcast) {
+		t.Errorf("Expected NWS to offer hourly forecasts and observations, got %v", nws.Operations)
+	}
+
+	mock := descriptions[1]
+	if mock.Priority != 2 || mock.Health != nil || slices.Contains(mock.Operations, OperationHourly) {
+		t.Errorf("Unexpected second weather provider %+v", mock)
+	}
+
+	if tomorrowIO := descriptions[2]; !slices.Contains(tomorrowIO.Operations, OperationNowcast) || tomorrowIO.Health != nil {
+		t.Errorf("Expected Tomorrow.io to offer nowcasts without a health check, got %+v", tomorrowIO)
+	}
+
+	if census := descriptions[3]; census.Type != TypeGeocode || census.Priority != 1 || !slices.Equal(census.Operations, []string{OperationGeocode, OperationReverseGeocode}) {
+		t.Errorf("Unexpected geocoder %+v", census)
+	}
+	if airQuality := descriptions[4]; airQuality.Type != TypeAirQuality || !slices.Equal(airQuality.Regions, []string{GlobalRegion}) {
+		t.Errorf("Unexpected air quality provider %+v", airQuality)
+	}
+}
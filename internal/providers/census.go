@@ -10,7 +10,13 @@ import (
 	"strings"
 	"time"
 
+	"stormlightlabs.org/weather_api/internal/breaker"
 	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
 )
 
 // CensusProvider implements GeocodeProvider for the US Census Geocoding API
@@ -24,7 +30,8 @@ func NewCensusProvider() *CensusProvider {
 	return &CensusProvider{
 		BaseURL: "https://geocoding.geo.census.gov/geocoder",
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Geocode, tracing.NewTransport("Census", requestlog.NewTransport("Census", retry.NewTransport("Census", ratelimit.NewTransport("Census", breaker.NewTransport("Census", nil)))))),
 		},
 	}
 }
@@ -37,6 +44,12 @@ func (c *CensusProvider) SupportedRegions() []string {
 	return []string{"US"} // US Census only covers United States
 }
 
+// HealthCheck checks that the geocoder answers its benchmark listing
+func (c *CensusProvider) HealthCheck(ctx context.Context) error {
+	_, err := c.makeRequest(ctx, c.BaseURL+"/benchmarks")
+	return err
+}
+
 // Census API Response structures
 type CensusGeocodeResponse struct {
 	Result CensusResult `json:"result"`
@@ -151,6 +164,10 @@ func (c *CensusProvider) GeocodeAddress(ctx context.Context, address string) ([]
 }
 
 func (c *CensusProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	if err := validateCoordinates(c.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
 	// Build the reverse geocoding request URL
 	params := url.Values{
 		"x":         {fmt.Sprintf("%.6f", lon)},
@@ -192,12 +209,12 @@ func (c *CensusProvider) makeRequest(ctx context.Context, requestURL string) ([]
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, requestError(c.GetName(), err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+		return nil, statusError(c.GetName(), resp)
 	}
 
 	var result json.RawMessage
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// Environment prefixes of the providers whose upstream can be overridden, e.g.
+// NWS_BASE_URL and NWS_TIMEOUT
+const (
+	NWSEnvPrefix        = "NWS"
+	CensusEnvPrefix     = "CENSUS"
+	ADDSEnvPrefix       = "ADDS"
+	AirQualityEnvPrefix = "AIR_QUALITY"
+	ArchiveEnvPrefix    = "ARCHIVE"
+	ECEnvPrefix         = "EC"
+	TomorrowIOEnvPrefix = "TOMORROW_IO"
+	MapboxEnvPrefix     = "MAPBOX"
+	GoogleEnvPrefix     = "GOOGLE_GEOCODING"
+)
+
+// Endpoint overrides where a provider sends its requests, so staging environments can
+// point providers at recorded-response mock servers and contract tests can run
+// against the real binary. Zero fields keep the provider's defaults.
+type Endpoint struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+// LoadEndpoint reads the {prefix}_BASE_URL and {prefix}_TIMEOUT environment variables.
+// Timeouts are Go durations ("5s", "1m30s").
+func LoadEndpoint(prefix string) (Endpoint, error) {
+	var e Endpoint
+	if value := strings.TrimSpace(os.Getenv(prefix + "_BASE_URL")); value != "" {
+		u, err := url.Parse(value)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return e, fmt.Errorf("%s_BASE_URL must be an absolute http(s) URL, got %q", prefix, value)
+		}
+		e.BaseURL = strings.TrimRight(value, "/")
+	}
+	if value := strings.TrimSpace(os.Getenv(prefix + "_TIMEOUT")); value != "" {
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			return e, fmt.Errorf("%s_TIMEOUT must be a positive duration, got %q", prefix, value)
+		}
+		e.Timeout = timeout
+	}
+	return e, nil
+}
+
+// IsZero reports whether the endpoint overrides nothing
+func (e Endpoint) IsZero() bool {
+	return e.BaseURL == "" && e.Timeout == 0
+}
+
+// apply overrides a provider's base URL and client timeout
+func (e Endpoint) apply(baseURL *string, client *http.Client) {
+	if e.BaseURL != "" {
+		*baseURL = e.BaseURL
+	}
+	if e.Timeout > 0 {
+		client.Timeout = e.Timeout
+	}
+}
+
+// Configure overrides the NWS base URL and timeout
+func (n *NWSProvider) Configure(e Endpoint) {
+	e.apply(&n.BaseURL, n.HTTPClient)
+}
+
+// Configure overrides the Census geocoder base URL and timeout
+func (c *CensusProvider) Configure(e Endpoint) {
+	e.apply(&c.BaseURL, c.HTTPClient)
+}
+
+// Configure overrides the ADDS base URL and timeout
+func (a *ADDSProvider) Configure(e Endpoint) {
+	e.apply(&a.BaseURL, a.HTTPClient)
+}
+
+// Configure overrides the Open-Meteo air quality base URL and timeout
+func (o *OpenMeteoAirQualityProvider) Configure(e Endpoint) {
+	e.apply(&o.BaseURL, o.HTTPClient)
+}
+
+func (o *OpenMeteoArchiveProvider) Configure(e Endpoint) {
+	e.apply(&o.BaseURL, o.HTTPClient)
+}
+
+// Configure overrides the Environment Canada Datamart base URL and timeout
+func (e *ECWeatherProvider) Configure(endpoint Endpoint) {
+	endpoint.apply(&e.BaseURL, e.HTTPClient)
+}
+
+// Configure overrides the Tomorrow.io base URL and timeout
+func (t *TomorrowIOProvider) Configure(e Endpoint) {
+	e.apply(&t.BaseURL, t.HTTPClient)
+}
+
+// Configure overrides the Mapbox base URL and timeout
+func (m *MapboxProvider) Configure(e Endpoint) {
+	e.apply(&m.BaseURL, m.HTTPClient)
+}
+
+// Configure overrides the Google Geocoding base URL and timeout
+func (g *GoogleProvider) Configure(e Endpoint) {
+	e.apply(&g.BaseURL, g.HTTPClient)
+}
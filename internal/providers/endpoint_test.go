@@ -0,0 +1,57 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLoadEndpoint(t *testing.T) {
+	t.Setenv("NWS_BASE_URL", "http://mock.staging:8081/nws/")
+	t.Setenv("NWS_TIMEOUT", "5s")
+
+	e, err := LoadEndpoint(NWSEnvPrefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.BaseURL != "http://mock.staging:8081/nws" || e.Timeout != 5*time.Second {
+		t.Errorf("Unexpected endpoint %+v", e)
+	}
+
+	if e, err := LoadEndpoint(CensusEnvPrefix); err != nil || !e.IsZero() {
+		t.Errorf("Expected no override without variables, got %+v (%v)", e, err)
+	}
+
+	for name, value := range map[string]string{"ADDS_BASE_URL": "aviationweather.gov", "ADDS_TIMEOUT": "-1s"} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := LoadEndpoint(ADDSEnvPrefix); err == nil {
+				t.Errorf("Expected %s=%q to be rejected", name, value)
+			}
+		})
+	}
+}
+
+func TestEndpoint_Configure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+
+	nws := NewNWSProvider()
+	nws.Configure(Endpoint{BaseURL: server.URL, Timeout: time.Second})
+	if nws.HTTPClient.Timeout != time.Second {
+		t.Errorf("Expected the timeout override, got %v", nws.HTTPClient.Timeout)
+	}
+	if err := nws.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Expected requests to reach the override, got %v", err)
+	}
+
+	census := NewCensusProvider()
+	census.Configure(Endpoint{})
+	if census.BaseURL != "https://geocoding.geo.census.gov/geocoder" || census.HTTPClient.Timeout != 30*time.Second {
+		t.Errorf("Expected an empty endpoint to keep the defaults, got %s %v", census.BaseURL, census.HTTPClient.Timeout)
+	}
+}
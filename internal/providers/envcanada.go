@@ -0,0 +1,602 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+	"stormlightlabs.org/weather_api/internal/weathercode"
+)
+
+// Languages of Environment Canada forecast texts
+const (
+	ECEnglish = "en"
+	ECFrench  = "fr"
+)
+
+// maxECSiteDistanceKm is how far a point may be from the nearest citypage site to be
+// covered; sites are sparse in the north, so it is generous
+const maxECSiteDistanceKm = 200
+
+// ecHealthCheckSite is the citypage checked by HealthCheck, Ottawa (Kanata - Orléans)
+const ecHealthCheckSite = "ON/s0000430"
+
+// ECWeatherProvider implements WeatherProvider for Environment and Climate Change
+// Canada's citypage weather, the XML behind weather.gc.ca published on the MSC
+// Datamart. Each of its ~850 sites has current conditions, a 7-day forecast and its
+// warnings; a point is answered by the nearest site. Values are metric at the source
+// and texts come in English or French.
+type ECWeatherProvider struct {
+	BaseURL    string
+	UserAgent  string
+	Language   string // ECEnglish (default) or ECFrench, the language of descriptions and alerts
+	HTTPClient *http.Client
+
+	mu    sync.Mutex
+	sites []ecSite // loaded from the site list on first use
+}
+
+// NewECWeatherProvider creates a new Environment Canada weather provider
+func NewECWeatherProvider() *ECWeatherProvider {
+	return &ECWeatherProvider{
+		BaseURL:   "https://dd.weather.gc.ca",
+		UserAgent: "weather-api/1.0 (contact@example.com)",
+		Language:  ECEnglish,
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("EnvironmentCanada", requestlog.NewTransport("EnvironmentCanada", retry.NewTransport("EnvironmentCanada", ratelimit.NewTransport("EnvironmentCanada", breaker.NewTransport("EnvironmentCanada", nil))))))),
+		},
+	}
+}
+
+func (e *ECWeatherProvider) GetName() string {
+	return "EnvironmentCanada"
+}
+
+func (e *ECWeatherProvider) SupportedRegions() []string {
+	return []string{"CA"}
+}
+
+// HealthCheck checks that the Datamart serves a known citypage
+func (e *ECWeatherProvider) HealthCheck(ctx context.Context) error {
+	var page ecCitypage
+	return e.makeRequest(ctx, e.pageURL(ecHealthCheckSite), &page)
+}
+
+// Environment Canada citypage structures. Numeric elements are strings because the
+// XML leaves unreported values empty rather than omitting them.
+type ecSiteList struct {
+	Sites []ecSite `xml:"site"`
+}
+
+type ecSite struct {
+	Code         string `xml:"code,attr"`
+	NameEn       string `xml:"nameEn"`
+	NameFr       string `xml:"nameFr"`
+	ProvinceCode string `xml:"provinceCode"`
+	Latitude     string `xml:"latitude"`  // e.g. "45.33N"
+	Longitude    string `xml:"longitude"` // e.g. "75.58W"
+
+	lat, lon float64
+}
+
+type ecCitypage struct {
+	Location struct {
+		Name   string `xml:"name"`
+		Region string `xml:"region"`
+	} `xml:"location"`
+	Warnings struct {
+		Events []ecWarning `xml:"event"`
+	} `xml:"warnings"`
+	Current  ecCurrentConditions `xml:"currentConditions"`
+	Forecast struct {
+		DateTimes []ecDateTime       `xml:"dateTime"`
+		Periods   []ecForecastPeriod `xml:"forecast"`
+	} `xml:"forecastGroup"`
+}
+
+type ecDateTime struct {
+	Name      string `xml:"name,attr"`
+	Zone      string `xml:"zone,attr"`
+	UTCOffset string `xml:"UTCOffset,attr"`
+	TimeStamp string `xml:"timeStamp"` // YYYYMMDDhhmmss in the zone
+}
+
+type ecWarning struct {
+	Type        string       `xml:"type,attr"` // warning, watch, advisory, statement or ended
+	Priority    string       `xml:"priority,attr"`
+	Description string       `xml:"description,attr"`
+	DateTimes   []ecDateTime `xml:"dateTime"`
+}
+
+type ecCurrentConditions struct {
+	DateTimes        []ecDateTime `xml:"dateTime"`
+	Condition        string       `xml:"condition"`
+	IconCode         string       `xml:"iconCode"`
+	Temperature      string       `xml:"temperature"`      // °C
+	Humidex          string       `xml:"humidex"`          // °C, reported in summer heat
+	WindChill        string       `xml:"windChill"`        // °C, reported in winter cold
+	Pressure         string       `xml:"pressure"`         // kPa at sea level
+	Visibility       string       `xml:"visibility"`       // km
+	RelativeHumidity string       `xml:"relativeHumidity"` // percent
+	Wind             ecWind       `xml:"wind"`
+}
+
+type ecWind struct {
+	Speed     string `xml:"speed"` // km/h
+	Gust      string `xml:"gust"`  // km/h
+	Direction string `xml:"direction"`
+	Bearing   string `xml:"bearing"` // degrees in current conditions
+}
+
+type ecForecastPeriod struct {
+	Period      string `xml:"period"`
+	TextSummary string `xml:"textSummary"`
+	Abbreviated struct {
+		IconCode    string `xml:"iconCode"`
+		TextSummary string `xml:"textSummary"`
+	} `xml:"abbreviatedForecast"`
+	Temperatures []struct {
+		Class string `xml:"class,attr"` // high for day periods, low for night periods
+		Value string `xml:",chardata"`
+	} `xml:"temperatures>temperature"`
+	Winds         []ecWind `xml:"winds>wind"`
+	Accumulations []struct {
+		Amount struct {
+			Units string `xml:"units,attr"` // mm, or cm for snow
+			Value string `xml:",chardata"`
+		} `xml:"amount"`
+	} `xml:"precipitation>accumulation"`
+	UVIndex          string `xml:"uv>index"`
+	RelativeHumidity string `xml:"relativeHumidity"`
+	Humidex          string `xml:"humidex>calculated"`
+	WindChill        string `xml:"windChill>calculated"`
+}
+
+// ecTimeLayout is the layout of citypage timeStamp elements
+const ecTimeLayout = "20060102150405"
+
+func (e *ECWeatherProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	_, page, err := e.getCitypage(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	current := page.Current
+	observed, ok := ecTime(current.DateTimes)
+	if !ok {
+		return nil, &ProviderError{Provider: e.GetName(), Kind: ErrUnsupportedRegion, Err: errors.New("site reports no current conditions")}
+	}
+	forecast := &models.Forecast{
+		SourceProvider: e.GetName(),
+		ForecastTime:   observed,
+		ValidTime:      observed,
+		Description:    current.Condition,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	forecast.WeatherCode = ecWeatherCode(current.IconCode)
+	forecast.Temperature, _ = ecNumber(current.Temperature)
+	forecast.Humidity, _ = ecNumber(current.RelativeHumidity)
+	if pressure, ok := ecNumber(current.Pressure); ok {
+		forecast.Pressure = pressure * 10 // kPa to hPa
+	}
+	forecast.Visibility, _ = ecNumber(current.Visibility)
+	applyECWind(forecast, current.Wind)
+	if bearing, ok := ecNumber(current.Wind.Bearing); ok {
+		forecast.WindDirection = bearing
+	}
+	forecast.FeelsLike = ecFeelsLike(forecast, current.Humidex, current.WindChill)
+	return forecast, nil
+}
+
+func (e *ECWeatherProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	_, page, err := e.getCitypage(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	issued, ok := ecLocalTime(page.Forecast.DateTimes)
+	if !ok {
+		return nil, fmt.Errorf("forecast has no issue time")
+	}
+
+	// Periods carry no times of their own: the first starts when the forecast was
+	// issued, later ones at 06:00 (days, with a high) or 18:00 (nights, with a low)
+	var forecasts []*models.Forecast
+	start := issued
+	for i, period := range page.Forecast.Periods {
+		if len(forecasts) == days*2 { // day and night periods
+			break
+		}
+		temperature, night, ok := period.temperature()
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			hour := 6
+			if night {
+				hour = 18
+			}
+			start = nextECPeriodStart(start, hour)
+		}
+		forecasts = append(forecasts, e.periodToForecast(&period, issued, start, temperature))
+	}
+	return forecasts, nil
+}
+
+// periodToForecast converts one day or night period of the 7-day forecast
+func (e *ECWeatherProvider) periodToForecast(period *ecForecastPeriod, issued, start time.Time, temperature float64) *models.Forecast {
+	forecast := &models.Forecast{
+		SourceProvider: e.GetName(),
+		ForecastTime:   issued.UTC(),
+		ValidTime:      start.UTC(),
+		Temperature:    temperature,
+		WeatherCode:    ecWeatherCode(period.Abbreviated.IconCode),
+		Description:    period.TextSummary,
+		CreatedAt:      time.Now(),
+		UpdatedAt:      time.Now(),
+	}
+	if forecast.Description == "" {
+		forecast.Description = period.Abbreviated.TextSummary
+	}
+	forecast.Humidity, _ = ecNumber(period.RelativeHumidity)
+	forecast.UVIndex, _ = ecNumber(period.UVIndex)
+	if len(period.Winds) > 0 {
+		applyECWind(forecast, period.Winds[0])
+		forecast.WindDirection = parseCompassDirection(period.Winds[0].Direction)
+	}
+	for _, accumulation := range period.Accumulations {
+		// Snowfall in cm is taken at the usual 10:1 ratio, 1 cm to 1 mm of water
+		if amount, ok := ecNumber(accumulation.Amount.Value); ok {
+			forecast.Precipitation += amount
+		}
+	}
+	forecast.FeelsLike = ecFeelsLike(forecast, period.Humidex, period.WindChill)
+	return forecast
+}
+
+// temperature returns the period's high or low and whether it is a night period
+func (p *ecForecastPeriod) temperature() (float64, bool, bool) {
+	for _, temperature := range p.Temperatures {
+		if value, ok := ecNumber(temperature.Value); ok {
+			return value, temperature.Class == "low", true
+		}
+	}
+	return 0, false, false
+}
+
+// nextECPeriodStart returns the first time after after at hour o'clock in its zone
+func nextECPeriodStart(after time.Time, hour int) time.Time {
+	start := time.Date(after.Year(), after.Month(), after.Day(), hour, 0, 0, 0, after.Location())
+	if !start.After(after) {
+		start = start.AddDate(0, 0, 1)
+	}
+	return start
+}
+
+// ecWarningSeverities maps citypage warning types onto CAP severities
+var ecWarningSeverities = map[string]string{
+	"warning":   "severe",
+	"watch":     "moderate",
+	"advisory":  "minor",
+	"statement": "minor",
+}
+
+// GetAlerts returns the warnings in effect at the nearest site. Citypages only carry a
+// warning's headline and issue time; the full text and area polygons are in the CAP
+// messages, which POST /alerts/cap ingests.
+func (e *ECWeatherProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]WeatherAlert, error) {
+	site, page, err := e.getCitypage(ctx, lat, lon)
+	if err != nil {
+		return nil, err
+	}
+
+	area := page.Location.Region
+	if area == "" {
+		area = site.name(e.Language)
+	}
+	var alerts []WeatherAlert
+	for _, warning := range page.Warnings.Events {
+		severity, ok := ecWarningSeverities[strings.ToLower(warning.Type)]
+		if !ok {
+			continue // ended warnings
+		}
+		issued, _ := ecTime(warning.DateTimes)
+		alerts = append(alerts, WeatherAlert{
+			ID:        fmt.Sprintf("%s-%s-%s", site.Code, strings.ToLower(warning.Type), issued.Format(ecTimeLayout)),
+			Title:     warning.Description,
+			Severity:  severity,
+			Urgency:   strings.ToLower(warning.Priority),
+			Category:  "met",
+			StartTime: issued,
+			Areas:     []string{area},
+		})
+	}
+	return alerts, nil
+}
+
+// getCitypage fetches the citypage of the site nearest the point
+func (e *ECWeatherProvider) getCitypage(ctx context.Context, lat, lon float64) (*ecSite, *ecCitypage, error) {
+	site, err := e.nearestSite(ctx, lat, lon)
+	if err != nil {
+		return nil, nil, err
+	}
+	var page ecCitypage
+	if err := e.makeRequest(ctx, e.pageURL(site.ProvinceCode+"/"+site.Code), &page); err != nil {
+		return nil, nil, fmt.Errorf("failed to get citypage: %w", err)
+	}
+	return site, &page, nil
+}
+
+// nearestSite returns the citypage site closest to the point, loading the site list on
+// first use. Points further than maxECSiteDistanceKm from every site are not covered.
+func (e *ECWeatherProvider) nearestSite(ctx context.Context, lat, lon float64) (*ecSite, error) {
+	if err := validateCoordinates(e.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+	sites, err := e.loadSites(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var nearest *ecSite
+	best := math.Inf(1)
+	for i := range sites {
+		if distance := geo.DistanceKm(lat, lon, sites[i].lat, sites[i].lon); distance < best {
+			nearest, best = &sites[i], distance
+		}
+	}
+	if nearest == nil || best > maxECSiteDistanceKm {
+		return nil, &ProviderError{Provider: e.GetName(), Kind: ErrUnsupportedRegion,
+			Err: fmt.Errorf("no citypage site within %d km", maxECSiteDistanceKm)}
+	}
+	return nearest, nil
+}
+
+// loadSites returns the citypage sites, fetching the site list once. A failed fetch is
+// retried on the next call.
+func (e *ECWeatherProvider) loadSites(ctx context.Context) ([]ecSite, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.sites != nil {
+		return e.sites, nil
+	}
+
+	var list ecSiteList
+	if err := e.makeRequest(ctx, e.BaseURL+"/citypage_weather/xml/siteList.xml", &list); err != nil {
+		return nil, fmt.Errorf("failed to get site list: %w", err)
+	}
+	sites := make([]ecSite, 0, len(list.Sites))
+	for _, site := range list.Sites {
+		lat, latOK := ecCoordinate(site.Latitude, "N", "S")
+		lon, lonOK := ecCoordinate(site.Longitude, "E", "W")
+		if !latOK || !lonOK || site.ProvinceCode == "" {
+			continue
+		}
+		site.lat, site.lon = lat, lon
+		sites = append(sites, site)
+	}
+	e.sites = sites
+	return sites, nil
+}
+
+// name returns the site's name in language
+func (s *ecSite) name(language string) string {
+	if language == ECFrench && s.NameFr != "" {
+		return s.NameFr
+	}
+	return s.NameEn
+}
+
+// pageURL returns the URL of a citypage such as "ON/s0000430" in the provider's language
+func (e *ECWeatherProvider) pageURL(site string) string {
+	suffix := "e"
+	if e.Language == ECFrench {
+		suffix = "f"
+	}
+	return fmt.Sprintf("%s/citypage_weather/xml/%s_%s.xml", e.BaseURL, site, suffix)
+}
+
+func (e *ECWeatherProvider) makeRequest(ctx context.Context, url string, result any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", e.UserAgent)
+	req.Header.Set("Accept", "application/xml")
+
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(e.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	// The Datamart answers 404 for sites it does not publish
+	if resp.StatusCode == http.StatusNotFound {
+		providerErr := statusError(e.GetName(), resp)
+		providerErr.Kind = ErrUnsupportedRegion
+		return providerErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(e.GetName(), resp)
+	}
+
+	decoder := xml.NewDecoder(resp.Body)
+	decoder.CharsetReader = ecCharsetReader
+	if err := decoder.Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// ecCharsetReader decodes the ISO-8859-1 the citypages are published in, which
+// encoding/xml does not read by itself
+func ecCharsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		return &latin1Reader{r: bufio.NewReader(input)}, nil
+	case "utf-8", "us-ascii":
+		return input, nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
+
+// latin1Reader converts ISO-8859-1 to UTF-8; every byte is the code point of its value
+type latin1Reader struct {
+	r       *bufio.Reader
+	pending []byte
+}
+
+func (l *latin1Reader) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(l.pending) > 0 {
+			copied := copy(p[n:], l.pending)
+			l.pending = l.pending[copied:]
+			n += copied
+			continue
+		}
+		b, err := l.r.ReadByte()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+		if b < 0x80 {
+			p[n] = b
+			n++
+			continue
+		}
+		l.pending = []byte(string(rune(b)))
+	}
+	return n, nil
+}
+
+// ecTime returns the UTC time of the first dateTime element in UTC
+func ecTime(dateTimes []ecDateTime) (time.Time, bool) {
+	for _, dateTime := range dateTimes {
+		if dateTime.Zone != "UTC" {
+			continue
+		}
+		if t, err := time.Parse(ecTimeLayout, strings.TrimSpace(dateTime.TimeStamp)); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ecLocalTime returns the time of the first dateTime element in the site's local zone,
+// in a fixed zone of its UTC offset
+func ecLocalTime(dateTimes []ecDateTime) (time.Time, bool) {
+	for _, dateTime := range dateTimes {
+		if dateTime.Zone == "UTC" {
+			continue
+		}
+		offset, err := strconv.ParseFloat(dateTime.UTCOffset, 64)
+		if err != nil {
+			continue
+		}
+		zone := time.FixedZone(dateTime.Zone, int(offset*3600))
+		if t, err := time.ParseInLocation(ecTimeLayout, strings.TrimSpace(dateTime.TimeStamp), zone); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// ecNumber parses a numeric element, reporting false when it is empty
+func ecNumber(value string) (float64, bool) {
+	number, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	return number, err == nil
+}
+
+// ecCoordinate parses a site list coordinate such as "45.33N" or "75.58W"
+func ecCoordinate(value, positive, negative string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	sign := 1.0
+	switch {
+	case strings.HasSuffix(value, positive):
+		value = strings.TrimSuffix(value, positive)
+	case strings.HasSuffix(value, negative):
+		value, sign = strings.TrimSuffix(value, negative), -1
+	default:
+		return 0, false
+	}
+	number, ok := ecNumber(value)
+	return sign * number, ok
+}
+
+// ecWeatherCode returns the canonical code of an icon code, as the numbers themselves
+// would be read as WMO codes
+func ecWeatherCode(icon string) string {
+	code, err := strconv.Atoi(strings.TrimSpace(icon))
+	if err != nil {
+		return ""
+	}
+	condition, ok := weathercode.FromECIcon(code)
+	if !ok {
+		return ""
+	}
+	return condition.Code
+}
+
+// applyECWind sets the wind speed and gust, reported in km/h
+func applyECWind(forecast *models.Forecast, wind ecWind) {
+	if speed, ok := ecNumber(wind.Speed); ok {
+		forecast.WindSpeed = units.Round(speed/3.6, 2)
+	}
+	if gust, ok := ecNumber(wind.Gust); ok {
+		forecast.WindGust = units.Round(gust/3.6, 2)
+	}
+}
+
+// ecFeelsLike prefers the humidex or wind chill Environment Canada reports, which are
+// its official feels-like figures, to the derived one
+func ecFeelsLike(forecast *models.Forecast, humidex, windChill string) float64 {
+	if value, ok := ecNumber(humidex); ok {
+		return value
+	}
+	if value, ok := ecNumber(windChill); ok {
+		return value
+	}
+	return units.Round(calc.FeelsLike(forecast.Temperature, forecast.Humidity, forecast.WindSpeed), 1)
+}
+
+// compassDirections are the degrees of the 16 compass points, as abbreviated in English
+// and French ("O" for ouest)
+var compassDirections = map[string]float64{
+	"N": 0, "NNE": 22.5, "NE": 45, "ENE": 67.5,
+	"E": 90, "ESE": 112.5, "SE": 135, "SSE": 157.5,
+	"S": 180, "SSW": 202.5, "SW": 225, "WSW": 247.5,
+	"W": 270, "WNW": 292.5, "NW": 315, "NNW": 337.5,
+	"SSO": 202.5, "SO": 225, "OSO": 247.5, "O": 270, "ONO": 292.5, "NO": 315, "NNO": 337.5,
+}
+
+// parseCompassDirection returns the degrees of a compass point, 0 when unknown
+func parseCompassDirection(direction string) float64 {
+	return compassDirections[strings.ToUpper(strings.TrimSpace(direction))]
+}
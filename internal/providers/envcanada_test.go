@@ -0,0 +1,251 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/weathercode"
+)
+
+const ecSiteListXML = `<?xml version="1.0" encoding="UTF-8"?>
+<siteList>
+  <site code="s0000430"><nameEn>Ottawa (Kanata - Orléans)</nameEn><nameFr>Ottawa (Kanata - Orléans)</nameFr><provinceCode>ON</provinceCode><latitude>45.33N</latitude><longitude>75.58W</longitude></site>
+  <site code="s0000141"><nameEn>Vancouver</nameEn><nameFr>Vancouver</nameFr><provinceCode>BC</provinceCode><latitude>49.25N</latitude><longitude>123.12W</longitude></site>
+  <site code="s0000999"><nameEn>Unplaced</nameEn><provinceCode>ON</provinceCode><latitude></latitude><longitude></longitude></site>
+</siteList>`
+
+// ecCitypageXML is a trimmed Ottawa citypage issued at 15:30 EDT, whose first period is
+// "Tonight". The %NAME% placeholders hold the texts ecTexts fills in English or French.
+const ecCitypageXML = `<?xml version="1.0" encoding="ISO-8859-1"?>
+<siteData>
+  <location><name code="s0000430">Ottawa (Kanata - Orléans)</name><region>%REGION%</region></location>
+  <warnings>
+    <event type="warning" priority="high" description="%WARNING%">
+      <dateTime name="eventIssue" zone="UTC" UTCOffset="0"><timeStamp>20250714180500</timeStamp></dateTime>
+    </event>
+    <event type="ended" priority="low" description="HEAT WARNING ENDED">
+      <dateTime name="eventIssue" zone="UTC" UTCOffset="0"><timeStamp>20250714120000</timeStamp></dateTime>
+    </event>
+  </warnings>
+  <currentConditions>
+    <dateTime name="observation" zone="UTC" UTCOffset="0"><timeStamp>20250714190000</timeStamp></dateTime>
+    <dateTime name="observation" zone="EDT" UTCOffset="-4"><timeStamp>20250714150000</timeStamp></dateTime>
+    <condition>%CONDITION%</condition>
+    <iconCode format="gif">03</iconCode>
+    <temperature unitType="metric" units="C">31.2</temperature>
+    <dewpoint unitType="metric" units="C">21.0</dewpoint>
+    <humidex unitType="metric">39</humidex>
+    <pressure unitType="metric" units="kPa" change="0.10" tendency="falling">100.9</pressure>
+    <visibility unitType="metric" units="km">24.1</visibility>
+    <relativeHumidity units="%">54</relativeHumidity>
+    <wind><speed unitType="metric" units="km/h">18</speed><gust unitType="metric" units="km/h">36</gust><direction>SW</direction><bearing units="degrees">225.0</bearing></wind>
+  </currentConditions>
+  <forecastGroup>
+    <dateTime name="forecastIssue" zone="UTC" UTCOffset="0"><timeStamp>20250714193000</timeStamp></dateTime>
+    <dateTime name="forecastIssue" zone="EDT" UTCOffset="-4"><timeStamp>20250714153000</timeStamp></dateTime>
+    <forecast>
+      <period textForecastName="Tonight">Monday night</period>
+      <textSummary>%TONIGHT%</textSummary>
+      <abbreviatedForecast><iconCode format="gif">39</iconCode><pop units="%">60</pop><textSummary>Showers</textSummary></abbreviatedForecast>
+      <temperatures><temperature unitType="metric" units="C" class="low">19</temperature></temperatures>
+      <winds><wind index="1" rank="major"><speed unitType="metric" units="km/h">20</speed><gust unitType="metric" units="km/h">50</gust><direction>O</direction></wind></winds>
+      <precipitation><accumulation><name>rain</name><amount unitType="metric" units="mm">10</amount></accumulation></precipitation>
+      <relativeHumidity units="%">90</relativeHumidity>
+    </forecast>
+    <forecast>
+      <period textForecastName="Tuesday">Tuesday</period>
+      <textSummary>Sunny. High 27. UV index 8 or very high.</textSummary>
+      <abbreviatedForecast><iconCode format="gif">00</iconCode><textSummary>Sunny</textSummary></abbreviatedForecast>
+      <temperatures><temperature unitType="metric" units="C" class="high">27</temperature></temperatures>
+      <uv category="very high"><index>8</index></uv>
+      <relativeHumidity units="%">45</relativeHumidity>
+    </forecast>
+    <forecast>
+      <period textForecastName="Tuesday night">Tuesday night</period>
+      <textSummary>Clear. Low 15.</textSummary>
+      <abbreviatedForecast><iconCode format="gif">30</iconCode></abbreviatedForecast>
+      <temperatures><temperature unitType="metric" units="C" class="low">15</temperature></temperatures>
+    </forecast>
+  </forecastGroup>
+</siteData>`
+
+// ecTexts fills the citypage in English or French
+func ecTexts(language string) string {
+	texts := map[string]string{
+		"%REGION%":    "Ottawa North - Kanata - Orléans",
+		"%WARNING%":   "SEVERE THUNDERSTORM WARNING IN EFFECT",
+		"%CONDITION%": "Mostly Cloudy",
+		"%TONIGHT%":   "Showers with thunderstorms. Low 19.",
+	}
+	if language == ECFrench {
+		texts = map[string]string{
+			"%REGION%":    "Ottawa Nord - Kanata - Orléans",
+			"%WARNING%":   "AVERTISSEMENT D'ORAGES VIOLENTS EN VIGUEUR",
+			"%CONDITION%": "Généralement nuageux",
+			"%TONIGHT%":   "Averses et orages. Minimum 19.",
+		}
+	}
+	page := ecCitypageXML
+	for placeholder, text := range texts {
+		page = strings.ReplaceAll(page, placeholder, text)
+	}
+	return page
+}
+
+// latin1 encodes UTF-8 text with only ISO-8859-1 characters as ISO-8859-1
+func latin1(text string) []byte {
+	encoded := make([]byte, 0, len(text))
+	for _, r := range text {
+		encoded = append(encoded, byte(r))
+	}
+	return encoded
+}
+
+func newECTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var siteListRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/citypage_weather/xml/siteList.xml":
+			siteListRequests.Add(1)
+			w.Write([]byte(ecSiteListXML))
+		case "/citypage_weather/xml/ON/s0000430_e.xml":
+			w.Write(latin1(ecTexts(ECEnglish)))
+		case "/citypage_weather/xml/ON/s0000430_f.xml":
+			w.Write(latin1(ecTexts(ECFrench)))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &siteListRequests
+}
+
+func TestECWeatherProvider_MockServer(t *testing.T) {
+	server, siteListRequests := newECTestServer(t)
+	provider := NewECWeatherProvider()
+	provider.Configure(Endpoint{BaseURL: server.URL})
+	ctx := context.Background()
+
+	current, err := provider.GetCurrentWeather(ctx, 45.42, -75.69)
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if !current.ValidTime.Equal(time.Date(2025, 7, 14, 19, 0, 0, 0, time.UTC)) || current.Description != "Mostly Cloudy" ||
+		current.WeatherCode != weathercode.MostlyCloudy {
+		t.Errorf("Unexpected current conditions %+v", current)
+	}
+	if current.Temperature != 31.2 || current.Pressure != 1009 || current.WindSpeed != 5 || current.WindGust != 10 ||
+		current.WindDirection != 225 || current.FeelsLike != 39 {
+		t.Errorf("Expected metric values converted to m/s and hPa with the humidex as feels-like, got %+v", current)
+	}
+
+	forecasts, err := provider.GetForecast(ctx, 45.42, -75.69, 7)
+	if err != nil {
+		t.Fatalf("GetForecast failed: %v", err)
+	}
+	if len(forecasts) != 3 {
+		t.Fatalf("Expected 3 periods, got %d", len(forecasts))
+	}
+	tonight, tuesday, tuesdayNight := forecasts[0], forecasts[1], forecasts[2]
+	if !tonight.ValidTime.Equal(time.Date(2025, 7, 14, 19, 30, 0, 0, time.UTC)) ||
+		!tuesday.ValidTime.Equal(time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)) ||
+		!tuesdayNight.ValidTime.Equal(time.Date(2025, 7, 15, 22, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected periods from the issue time, then 06:00 and 18:00 EDT, got %v, %v, %v",
+			tonight.ValidTime, tuesday.ValidTime, tuesdayNight.ValidTime)
+	}
+	if tonight.Temperature != 19 || tonight.WeatherCode != weathercode.Thunderstorm || tonight.Precipitation != 10 ||
+		tonight.WindDirection != 270 || tonight.WindGust != 13.89 {
+		t.Errorf("Unexpected first period %+v", tonight)
+	}
+	if tuesday.UVIndex != 8 || tuesday.WeatherCode != weathercode.Clear || tuesdayNight.WeatherCode != weathercode.Clear {
+		t.Errorf("Unexpected later periods %+v, %+v", tuesday, tuesdayNight)
+	}
+	for _, forecast := range forecasts {
+		forecast.CityID = 1
+		if err := forecast.Validate(); err != nil {
+			t.Errorf("Expected a valid forecast at %v, got %v", forecast.ValidTime, err)
+		}
+	}
+	if short, _ := provider.GetForecast(ctx, 45.42, -75.69, 1); len(short) != 2 {
+		t.Errorf("Expected a day and a night for 1 day, got %d periods", len(short))
+	}
+
+	alerts, err := provider.GetAlerts(ctx, 45.42, -75.69)
+	if err != nil {
+		t.Fatalf("GetAlerts failed: %v", err)
+	}
+	if len(alerts) != 1 || alerts[0].Severity != "severe" || alerts[0].ID != "s0000430-warning-20250714180500" ||
+		alerts[0].Areas[0] != "Ottawa North - Kanata - Orléans" {
+		t.Errorf("Expected the warning in effect and not the ended one, got %+v", alerts)
+	}
+
+	if requests := siteListRequests.Load(); requests != 1 {
+		t.Errorf("Expected the site list fetched once, got %d requests", requests)
+	}
+
+	if _, err := provider.GetCurrentWeather(ctx, 60.0, -100.0); !errors.Is(err, ErrUnsupportedRegion) {
+		t.Errorf("Expected ErrUnsupportedRegion far from every site, got %v", err)
+	}
+	if _, err := provider.GetForecast(ctx, 49.28, -123.12, 7); !errors.Is(err, ErrUnsupportedRegion) {
+		t.Errorf("Expected ErrUnsupportedRegion for an unpublished citypage, got %v", err)
+	}
+	if err := provider.HealthCheck(ctx); err != nil {
+		t.Errorf("HealthCheck failed: %v", err)
+	}
+}
+
+func TestECWeatherProvider_French(t *testing.T) {
+	server, _ := newECTestServer(t)
+	provider := NewECWeatherProvider()
+	provider.Configure(Endpoint{BaseURL: server.URL})
+	provider.Language = ECFrench
+	ctx := context.Background()
+
+	current, err := provider.GetCurrentWeather(ctx, 45.42, -75.69)
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if current.Description != "Généralement nuageux" || current.WeatherCode != weathercode.MostlyCloudy {
+		t.Errorf("Expected the French description decoded from ISO-8859-1, got %q (%s)", current.Description, current.WeatherCode)
+	}
+
+	forecasts, err := provider.GetForecast(ctx, 45.42, -75.69, 1)
+	if err != nil || len(forecasts) == 0 || forecasts[0].Description != "Averses et orages. Minimum 19." {
+		t.Errorf("Expected the French forecast text, got %v (%v)", forecasts, err)
+	}
+
+	alerts, err := provider.GetAlerts(ctx, 45.42, -75.69)
+	if err != nil || len(alerts) != 1 || alerts[0].Title != "AVERTISSEMENT D'ORAGES VIOLENTS EN VIGUEUR" {
+		t.Errorf("Expected the French warning, got %+v (%v)", alerts, err)
+	}
+}
+
+func TestECCoordinate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  float64
+		ok    bool
+	}{
+		{"45.33N", 45.33, true},
+		{"75.58W", -75.58, true},
+		{"49.25S", -49.25, true},
+		{"75.58", 0, false},
+		{"", 0, false},
+	}
+	for _, tt := range tests {
+		positive, negative := "N", "S"
+		if strings.HasSuffix(tt.value, "W") || strings.HasSuffix(tt.value, "E") {
+			positive, negative = "E", "W"
+		}
+		if got, ok := ecCoordinate(tt.value, positive, negative); got != tt.want || ok != tt.ok {
+			t.Errorf("ecCoordinate(%q) = %v, %v; want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/retry"
+)
+
+// Provider error kinds. Providers return errors wrapping one of these, usually through a
+// *ProviderError, so callers can branch with errors.Is instead of matching messages.
+var (
+	// ErrRateLimited means the provider throttled the request; retry after RetryAfter
+	ErrRateLimited = errors.New("provider rate limit exceeded")
+	// ErrUnsupportedRegion means the provider has no data for the location
+	ErrUnsupportedRegion = errors.New("location not covered by provider")
+	// ErrUpstreamUnavailable means the provider could not be reached or failed internally
+	ErrUpstreamUnavailable = errors.New("provider unavailable")
+	// ErrBadCoordinates means the coordinates are out of range or rejected by the provider
+	ErrBadCoordinates = errors.New("invalid coordinates")
+)
+
+// ProviderError is a failed provider call
+type ProviderError struct {
+	Provider   string
+	Kind       error         // one of the Err* kinds above, nil when unclassified
+	StatusCode int           // upstream HTTP status, 0 when no response was received
+	RetryAfter time.Duration // upstream retry hint, 0 when none was given
+	Err        error         // underlying cause
+}
+
+func (e *ProviderError) Error() string {
+	switch {
+	case e.Kind != nil && e.Err != nil:
+		return fmt.Sprintf("%s: %v: %v", e.Provider, e.Kind, e.Err)
+	case e.Kind != nil:
+		return fmt.Sprintf("%s: %v", e.Provider, e.Kind)
+	default:
+		return fmt.Sprintf("%s: %v", e.Provider, e.Err)
+	}
+}
+
+// Unwrap exposes both the kind and the cause to errors.Is and errors.As
+func (e *ProviderError) Unwrap() []error {
+	var errs []error
+	if e.Kind != nil {
+		errs = append(errs, e.Kind)
+	}
+	if e.Err != nil {
+		errs = append(errs, e.Err)
+	}
+	return errs
+}
+
+// RetryAfter returns the retry hint carried by err, or 0 when there is none
+func RetryAfter(err error) time.Duration {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.RetryAfter
+	}
+	return 0
+}
+
+// statusError classifies a non-OK upstream response: 429 is a rate limit and 5xx an
+// unavailable upstream, both keeping the Retry-After hint. Other statuses are left
+// unclassified for the provider to refine.
+func statusError(provider string, resp *http.Response) *ProviderError {
+	providerErr := &ProviderError{
+		Provider:   provider,
+		StatusCode: resp.StatusCode,
+		Err:        fmt.Errorf("API request failed with status %d", resp.StatusCode),
+	}
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		providerErr.Kind = ErrRateLimited
+	case resp.StatusCode >= 500:
+		providerErr.Kind = ErrUpstreamUnavailable
+	}
+	if providerErr.Kind != nil {
+		providerErr.RetryAfter = retry.ParseRetryAfter(resp.Header.Get("Retry-After"))
+	}
+	return providerErr
+}
+
+// requestError classifies a request that got no response. Cancellation and deadlines
+// are the caller's doing and pass through; anything else means the provider is
+// unreachable. A request short-circuited by an open circuit breaker carries the time
+// until the breaker probes again as its retry hint. The request's query is dropped
+// from the error, as from request logs, since it can carry an API key.
+func requestError(provider string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		if u, parseErr := url.Parse(urlErr.URL); parseErr == nil {
+			u.RawQuery = ""
+			urlErr.URL = u.String()
+		}
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("HTTP request failed: %w", err)
+	}
+	providerErr := &ProviderError{Provider: provider, Kind: ErrUpstreamUnavailable, Err: err}
+	var openErr *breaker.OpenError
+	if errors.As(err, &openErr) {
+		providerErr.RetryAfter = openErr.RetryAfter
+	}
+	return providerErr
+}
+
+// validateCoordinates rejects coordinates outside the valid latitude and longitude ranges
+func validateCoordinates(provider string, lat, lon float64) error {
+	if err := geo.Validate(lat, lon); err != nil {
+		return &ProviderError{Provider: provider, Kind: ErrBadCoordinates, Err: err}
+	}
+	return nil
+}
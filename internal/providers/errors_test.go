@@ -0,0 +1,103 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryAfter string
+		kind       error
+		retry      time.Duration
+	}{
+		{http.StatusTooManyRequests, "120", ErrRateLimited, 2 * time.Minute},
+		{http.StatusServiceUnavailable, "", ErrUpstreamUnavailable, 0},
+		{http.StatusBadGateway, "5", ErrUpstreamUnavailable, 5 * time.Second},
+		{http.StatusForbidden, "5", nil, 0},
+	}
+
+	for _, tt := range tests {
+		resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+		resp.Header.Set("Retry-After", tt.retryAfter)
+
+		err := statusError("NWS", resp)
+		if tt.kind != nil && !errors.Is(err, tt.kind) {
+			t.Errorf("status %d: expected %v, got %v", tt.status, tt.kind, err)
+		}
+		if tt.kind == nil && err.Kind != nil {
+			t.Errorf("status %d: expected an unclassified error, got %v", tt.status, err.Kind)
+		}
+		if got := RetryAfter(err); got != tt.retry {
+			t.Errorf("status %d: expected retry after %s, got %s", tt.status, tt.retry, got)
+		}
+	}
+}
+
+func TestRequestError(t *testing.T) {
+	if err := requestError("NWS", errors.New("connection refused")); !errors.Is(err, ErrUpstreamUnavailable) {
+		t.Errorf("expected network failures to be ErrUpstreamUnavailable, got %v", err)
+	}
+
+	err := requestError("NWS", context.DeadlineExceeded)
+	if errors.Is(err, ErrUpstreamUnavailable) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadlines to pass through, got %v", err)
+	}
+
+	err = requestError("NWS", fmt.Errorf("Get: %w", &breaker.OpenError{Provider: "NWS", RetryAfter: 20 * time.Second}))
+	if !errors.Is(err, ErrUpstreamUnavailable) || !errors.Is(err, breaker.ErrOpen) || RetryAfter(err) != 20*time.Second {
+		t.Errorf("expected an open breaker to be ErrUpstreamUnavailable with its retry hint, got %v", err)
+	}
+
+	err = requestError("Mapbox", &url.Error{Op: "Get", URL: "https://api.mapbox.com/geocoding/v5/mapbox.places/x.json?access_token=secret", Err: errors.New("connection refused")})
+	if strings.Contains(err.Error(), "secret") || !strings.Contains(err.Error(), "mapbox.places/x.json") {
+		t.Errorf("expected the query dropped from the error, got %v", err)
+	}
+}
+
+func TestValidateCoordinates(t *testing.T) {
+	if err := validateCoordinates("NWS", 39.04, -76.64); err != nil {
+		t.Errorf("expected valid coordinates, got %v", err)
+	}
+	if err := validateCoordinates("NWS", 91, 0); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("expected ErrBadCoordinates, got %v", err)
+	}
+}
+
+func TestNWSProvider_PointErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/points/10,10":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	nws := NewNWSProvider()
+	nws.BaseURL = server.URL
+	ctx := context.Background()
+
+	if _, err := nws.GetForecast(ctx, 10, 10, 1); !errors.Is(err, ErrUnsupportedRegion) {
+		t.Errorf("expected ErrUnsupportedRegion outside the grid, got %v", err)
+	}
+	_, err := nws.GetCurrentWeather(ctx, 39.0458, -76.6413)
+	if !errors.Is(err, ErrRateLimited) || RetryAfter(err) != time.Minute {
+		t.Errorf("expected ErrRateLimited with a 1m hint, got %v", err)
+	}
+	if _, err := nws.GetAlerts(ctx, 0, 200); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("expected ErrBadCoordinates, got %v", err)
+	}
+}
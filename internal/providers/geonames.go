@@ -0,0 +1,84 @@
+package providers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// GeoNamesAlternateName is a single row of the GeoNames alternateNamesV2 dump
+// (https://download.geonames.org/export/dump/alternatenames/) reduced to the
+// fields needed for localized city names
+type GeoNamesAlternateName struct {
+	GeonameID   int
+	Language    string // lowercase ISO 639 code, optionally with region
+	Name        string
+	IsPreferred bool
+	IsShort     bool
+}
+
+// geoNamesPseudoLanguages are "language" codes GeoNames uses for non-name data
+var geoNamesPseudoLanguages = map[string]bool{
+	"link": true, "post": true, "iata": true, "icao": true, "faac": true,
+	"abbr": true, "wkdt": true, "fr_1793": true, "unlc": true,
+}
+
+// ParseGeoNamesAlternateNames reads tab-separated GeoNames alternate names and returns
+// the rows that are real, current names in a language. Colloquial and historic names,
+// rows without a language code, and pseudo-languages (links, postcodes, airport codes)
+// are skipped. If languages is non-empty only those language codes are kept.
+func ParseGeoNamesAlternateNames(r io.Reader, languages ...string) ([]GeoNamesAlternateName, error) {
+	wanted := make(map[string]bool, len(languages))
+	for _, lang := range languages {
+		wanted[strings.ToLower(lang)] = true
+	}
+
+	var names []GeoNamesAlternateName
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) < 4 {
+			continue
+		}
+
+		language := strings.ToLower(strings.ReplaceAll(fields[2], "_", "-"))
+		if language == "" || geoNamesPseudoLanguages[strings.ToLower(fields[2])] {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[language] {
+			continue
+		}
+		if geoNamesFlag(fields, 6) || geoNamesFlag(fields, 7) { // colloquial, historic
+			continue
+		}
+
+		geonameID, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid geonameid on line %d: %w", line, err)
+		}
+
+		names = append(names, GeoNamesAlternateName{
+			GeonameID:   geonameID,
+			Language:    language,
+			Name:        fields[3],
+			IsPreferred: geoNamesFlag(fields, 4),
+			IsShort:     geoNamesFlag(fields, 5),
+		})
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read alternate names: %w", err)
+	}
+
+	return names, nil
+}
+
+func geoNamesFlag(fields []string, index int) bool {
+	return index < len(fields) && fields[index] == "1"
+}
@@ -0,0 +1,47 @@
+package providers
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseGeoNamesAlternateNames(t *testing.T) {
+	data := strings.Join([]string{
+		"1\t2950159\tde\tBerlin\t1\t\t\t",
+		"2\t2950159\tfr\tBerlin\t\t\t\t",
+		"3\t2950159\tlink\thttps://en.wikipedia.org/wiki/Berlin\t\t\t\t",
+		"4\t2950159\tpl\tBerlinek\t\t\t1\t",
+		"5\t2950159\tpt_BR\tBerlim\t\t\t\t",
+		"6\t2950159\t\tBerolina\t\t\t\t1",
+	}, "\n")
+
+	names, err := ParseGeoNamesAlternateNames(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(names) != 3 {
+		t.Fatalf("expected 3 names, got %d: %+v", len(names), names)
+	}
+	if !names[0].IsPreferred || names[0].Language != "de" {
+		t.Errorf("expected preferred German name first, got %+v", names[0])
+	}
+	if names[2].Language != "pt-br" || names[2].Name != "Berlim" {
+		t.Errorf("expected normalized pt-br name, got %+v", names[2])
+	}
+
+	filtered, err := ParseGeoNamesAlternateNames(strings.NewReader(data), "fr")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].Language != "fr" {
+		t.Errorf("expected only the French name, got %+v", filtered)
+	}
+}
+
+func TestParseGeoNamesAlternateNames_InvalidID(t *testing.T) {
+	_, err := ParseGeoNamesAlternateNames(strings.NewReader("1\tabc\tde\tBerlin\t\t\t\t"))
+	if err == nil {
+		t.Error("expected error for invalid geonameid")
+	}
+}
@@ -0,0 +1,349 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// GoogleProvider implements GeocodeProvider for the Google Maps Geocoding API. Requests
+// are billed per call, so it has no health check.
+type GoogleProvider struct {
+	BaseURL    string
+	APIKey     string
+	HTTPClient *http.Client
+
+	// Filter applies to every GeocodeAddress lookup; Geocode takes its own
+	Filter GoogleFilter
+}
+
+// GoogleFilter narrows a forward geocode. Country and PostalCode are component filters,
+// which only return results inside them; Bounds biases results towards a viewport
+// without excluding others.
+type GoogleFilter struct {
+	Country    string // ISO 3166-1 alpha-2 code
+	PostalCode string
+	Bounds     *GoogleBounds
+}
+
+// GoogleBounds is a viewport given by its south-west and north-east corners
+type GoogleBounds struct {
+	South, West, North, East float64
+}
+
+// ParseGoogleBounds parses a viewport in Google's "south,west|north,east" form, e.g.
+// "38.8,-77.2|39.0,-76.9"
+func ParseGoogleBounds(value string) (*GoogleBounds, error) {
+	corners := strings.FieldsFunc(value, func(r rune) bool { return r == '|' || r == ',' })
+	if len(corners) != 4 {
+		return nil, fmt.Errorf("bounds must be south,west|north,east, got %q", value)
+	}
+	south, west, err := geo.ParseCoordinates(corners[0], corners[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid south-west corner: %w", err)
+	}
+	north, east, err := geo.ParseCoordinates(corners[2], corners[3])
+	if err != nil {
+		return nil, fmt.Errorf("invalid north-east corner: %w", err)
+	}
+	if south > north {
+		return nil, fmt.Errorf("bounds south %v is north of %v", south, north)
+	}
+	return &GoogleBounds{South: south, West: west, North: north, East: east}, nil
+}
+
+// String formats the viewport as Google's bounds parameter
+func (b *GoogleBounds) String() string {
+	return geo.FormatPoint(b.South, b.West, 6) + "|" + geo.FormatPoint(b.North, b.East, 6)
+}
+
+// NewGoogleProvider creates a new Google geocoding provider using apiKey
+func NewGoogleProvider(apiKey string) *GoogleProvider {
+	return &GoogleProvider{
+		BaseURL: "https://maps.googleapis.com",
+		APIKey:  apiKey,
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Geocode, tracing.NewTransport("Google", requestlog.NewTransport("Google", retry.NewTransport("Google", ratelimit.NewTransport("Google", breaker.NewTransport("Google", nil)))))),
+		},
+	}
+}
+
+func (g *GoogleProvider) GetName() string {
+	return "Google"
+}
+
+func (g *GoogleProvider) SupportedRegions() []string {
+	return []string{GlobalRegion}
+}
+
+// Google API response structures
+type GoogleGeocodeResponse struct {
+	Results      []GoogleResult `json:"results"`
+	Status       string         `json:"status"` // OK, ZERO_RESULTS, OVER_QUERY_LIMIT, REQUEST_DENIED, ...
+	ErrorMessage string         `json:"error_message"`
+}
+
+type GoogleResult struct {
+	PlaceID           string                   `json:"place_id"`
+	FormattedAddress  string                   `json:"formatted_address"`
+	Types             []string                 `json:"types"`
+	PartialMatch      bool                     `json:"partial_match"`
+	AddressComponents []GoogleAddressComponent `json:"address_components"`
+	Geometry          GoogleGeometry           `json:"geometry"`
+}
+
+type GoogleAddressComponent struct {
+	LongName  string   `json:"long_name"`
+	ShortName string   `json:"short_name"`
+	Types     []string `json:"types"`
+}
+
+type GoogleGeometry struct {
+	Location     GoogleLatLng `json:"location"`
+	LocationType string       `json:"location_type"` // ROOFTOP, RANGE_INTERPOLATED, GEOMETRIC_CENTER or APPROXIMATE
+	Viewport     struct {
+		Northeast GoogleLatLng `json:"northeast"`
+		Southwest GoogleLatLng `json:"southwest"`
+	} `json:"viewport"`
+}
+
+type GoogleLatLng struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// googleLocationConfidence is the confidence of a result by how precisely it is placed:
+// an exact address, one interpolated along its street, the center of a street or area,
+// or an approximation
+var googleLocationConfidence = map[string]float64{
+	"ROOFTOP":            1.0,
+	"RANGE_INTERPOLATED": 0.8,
+	"GEOMETRIC_CENTER":   0.6,
+	"APPROXIMATE":        0.4,
+}
+
+// googlePartialMatchFactor lowers the confidence of results that match only part of
+// the address
+const googlePartialMatchFactor = 0.75
+
+// googlePlaceTypes map Google result types to place types, in order of preference
+var googlePlaceTypes = []struct{ google, place string }{
+	{"street_address", "address"},
+	{"premise", "address"},
+	{"subpremise", "address"},
+	{"point_of_interest", "poi"},
+	{"establishment", "poi"},
+	{"route", "street"},
+	{"intersection", "street"},
+	{"neighborhood", "neighborhood"},
+	{"sublocality", "neighborhood"},
+	{"locality", "city"},
+	{"postal_town", "city"},
+	{"postal_code", "postcode"},
+	{"administrative_area_level_2", "district"},
+	{"administrative_area_level_1", "region"},
+	{"country", "country"},
+}
+
+func (g *GoogleProvider) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	return g.Geocode(ctx, address, g.Filter)
+}
+
+// Geocode geocodes an address within filter's components, biased towards its bounds.
+// The address may be empty when a component, such as a postal code, is given.
+func (g *GoogleProvider) Geocode(ctx context.Context, address string, filter GoogleFilter) ([]*models.Place, error) {
+	address = strings.TrimSpace(address)
+	params := url.Values{}
+	if address != "" {
+		params.Set("address", address)
+	}
+	var components []string
+	if filter.Country != "" {
+		components = append(components, "country:"+strings.ToUpper(filter.Country))
+	}
+	if filter.PostalCode != "" {
+		components = append(components, "postal_code:"+filter.PostalCode)
+	}
+	if len(components) > 0 {
+		params.Set("components", strings.Join(components, "|"))
+	}
+	if address == "" && len(components) == 0 {
+		return nil, fmt.Errorf("address or a component filter is required")
+	}
+	if filter.Bounds != nil {
+		params.Set("bounds", filter.Bounds.String())
+	}
+
+	var response GoogleGeocodeResponse
+	if err := g.makeRequest(ctx, params, &response); err != nil {
+		return nil, fmt.Errorf("geocoding request failed: %w", err)
+	}
+
+	places := make([]*models.Place, 0, len(response.Results))
+	for _, result := range response.Results {
+		place := g.resultToPlace(&result)
+		place.Latitude, place.Longitude = result.Geometry.Location.Lat, result.Geometry.Location.Lng
+		places = append(places, place)
+	}
+
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no geocoding results found for address: %s", strings.TrimSpace(address+" "+params.Get("components")))
+	}
+
+	return places, nil
+}
+
+// ReverseGeocode returns the most specific result at a point
+func (g *GoogleProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	if err := validateCoordinates(g.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response GoogleGeocodeResponse
+	if err := g.makeRequest(ctx, url.Values{"latlng": {geo.FormatPoint(lat, lon, 6)}}, &response); err != nil {
+		return nil, fmt.Errorf("reverse geocoding request failed: %w", err)
+	}
+
+	if len(response.Results) == 0 {
+		return nil, fmt.Errorf("no reverse geocoding results found for coordinates: %f, %f", lat, lon)
+	}
+
+	place := g.resultToPlace(&response.Results[0])
+	place.Latitude, place.Longitude = lat, lon
+	return place, nil
+}
+
+// makeRequest calls the geocode endpoint with params. Google answers errors with a 200
+// and a status, which are classified like HTTP errors; ZERO_RESULTS is a success with
+// no results.
+func (g *GoogleProvider) makeRequest(ctx context.Context, params url.Values, result *GoogleGeocodeResponse) error {
+	params.Set("key", g.APIKey)
+	req, err := http.NewRequestWithContext(ctx, "GET", g.BaseURL+"/maps/api/geocode/json?"+params.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "weather-api/1.0")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(g.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(g.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	providerErr := &ProviderError{Provider: g.GetName(), StatusCode: resp.StatusCode, Err: fmt.Errorf("geocoding status %s", result.Status)}
+	if result.ErrorMessage != "" {
+		providerErr.Err = fmt.Errorf("geocoding status %s: %s", result.Status, result.ErrorMessage)
+	}
+	switch result.Status {
+	case "OK", "ZERO_RESULTS":
+		return nil
+	case "OVER_QUERY_LIMIT", "OVER_DAILY_LIMIT":
+		providerErr.Kind = ErrRateLimited
+	case "UNKNOWN_ERROR":
+		providerErr.Kind = ErrUpstreamUnavailable
+	}
+	return providerErr
+}
+
+// resultToPlace converts a result, filling the address fields from its components. The
+// caller sets the coordinates.
+func (g *GoogleProvider) resultToPlace(result *GoogleResult) *models.Place {
+	place := &models.Place{
+		DisplayName:   result.FormattedAddress,
+		PlaceType:     googlePlaceType(result.Types),
+		Confidence:    googleConfidence(result),
+		Source:        g.GetName(),
+		SourcePlaceID: result.PlaceID,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	var streetNumber, route, postalTown string
+	for _, component := range result.AddressComponents {
+		for _, kind := range component.Types {
+			switch kind {
+			case "street_number":
+				streetNumber = component.LongName
+			case "route":
+				route = component.LongName
+			case "subpremise":
+				place.AddressLine2 = component.LongName
+			case "locality":
+				place.City = component.LongName
+			case "postal_town":
+				postalTown = component.LongName
+			case "administrative_area_level_1":
+				place.Region = component.LongName
+			case "postal_code":
+				place.PostalCode = component.LongName
+			case "country":
+				place.Country = component.LongName
+				place.CountryCode = component.ShortName
+			}
+		}
+	}
+	place.AddressLine1 = strings.TrimSpace(streetNumber + " " + route)
+	if viewport := result.Geometry.Viewport; viewport.Northeast != (GoogleLatLng{}) {
+		place.BoundingBox = placeBoundingBox(viewport.Southwest.Lat, viewport.Northeast.Lat, viewport.Southwest.Lng, viewport.Northeast.Lng)
+	}
+	// British and Swedish addresses name their town only as the postal town
+	if place.City == "" {
+		place.City = postalTown
+	}
+
+	return place
+}
+
+// googlePlaceType returns the place type for the most specific of a result's types,
+// the first of them when none is known
+func googlePlaceType(types []string) string {
+	for _, t := range googlePlaceTypes {
+		for _, kind := range types {
+			if kind == t.google {
+				return t.place
+			}
+		}
+	}
+	if len(types) > 0 {
+		return types[0]
+	}
+	return ""
+}
+
+// googleConfidence is the confidence for a result's location type, lowered for partial
+// matches; unknown location types count as approximate
+func googleConfidence(result *GoogleResult) float64 {
+	confidence, ok := googleLocationConfidence[result.Geometry.LocationType]
+	if !ok {
+		confidence = googleLocationConfidence["APPROXIMATE"]
+	}
+	if result.PartialMatch {
+		confidence *= googlePartialMatchFactor
+	}
+	return units.Round(confidence, 2)
+}
@@ -0,0 +1,181 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const googleForwardJSON = `{"status": "OK", "results": [
+	{"place_id": "ChIJ2eUgeAK6j4ARbn5u_wAGqWA", "formatted_address": "1600 Amphitheatre Pkwy, Mountain View, CA 94043, USA",
+	 "types": ["street_address"],
+	 "address_components": [
+		{"long_name": "1600", "short_name": "1600", "types": ["street_number"]},
+		{"long_name": "Amphitheatre Parkway", "short_name": "Amphitheatre Pkwy", "types": ["route"]},
+		{"long_name": "Mountain View", "short_name": "Mountain View", "types": ["locality", "political"]},
+		{"long_name": "California", "short_name": "CA", "types": ["administrative_area_level_1", "political"]},
+		{"long_name": "United States", "short_name": "US", "types": ["country", "political"]},
+		{"long_name": "94043", "short_name": "94043", "types": ["postal_code"]}],
+	 "geometry": {"location": {"lat": 37.4224428, "lng": -122.0842467}, "location_type": "ROOFTOP",
+	              "viewport": {"northeast": {"lat": 37.4239, "lng": -122.0829}, "southwest": {"lat": 37.4212, "lng": -122.0856}}}},
+	{"place_id": "ChIJiQHsW0m3j4ARm69rRkrUF3w", "formatted_address": "Mountain View, CA, USA", "types": ["locality", "political"],
+	 "partial_match": true,
+	 "address_components": [{"long_name": "Mountain View", "short_name": "Mountain View", "types": ["locality", "political"]}],
+	 "geometry": {"location": {"lat": 37.3861, "lng": -122.0839}, "location_type": "APPROXIMATE"}}
+]}`
+
+const googleReverseJSON = `{"status": "OK", "results": [
+	{"place_id": "ChIJdd4hrwug2EcRmSrV3Vo6llI", "formatted_address": "10 Downing St, London SW1A 2AA, UK", "types": ["premise"],
+	 "address_components": [
+		{"long_name": "10", "short_name": "10", "types": ["street_number"]},
+		{"long_name": "Downing Street", "short_name": "Downing St", "types": ["route"]},
+		{"long_name": "London", "short_name": "London", "types": ["postal_town"]},
+		{"long_name": "England", "short_name": "England", "types": ["administrative_area_level_1", "political"]},
+		{"long_name": "United Kingdom", "short_name": "GB", "types": ["country", "political"]},
+		{"long_name": "SW1A 2AA", "short_name": "SW1A 2AA", "types": ["postal_code"]}],
+	 "geometry": {"location": {"lat": 51.5033, "lng": -0.1276}, "location_type": "RANGE_INTERPOLATED"}}
+]}`
+
+func newGoogleTestServer(t *testing.T, lastQuery *url.Values) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		*lastQuery = query
+		switch {
+		case r.URL.Path != "/maps/api/geocode/json":
+			w.WriteHeader(http.StatusNotFound)
+		case query.Get("key") == "exhausted":
+			w.Write([]byte(`{"status": "OVER_QUERY_LIMIT", "results": [], "error_message": "You have exceeded your daily request quota."}`))
+		case query.Get("key") != "test-key":
+			w.Write([]byte(`{"status": "REQUEST_DENIED", "results": [], "error_message": "The provided API key is invalid."}`))
+		case query.Get("latlng") == "51.5033,-0.1276":
+			w.Write([]byte(googleReverseJSON))
+		case strings.HasPrefix(query.Get("address"), "1600 Amphitheatre"):
+			w.Write([]byte(googleForwardJSON))
+		default:
+			w.Write([]byte(`{"status": "ZERO_RESULTS", "results": []}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGoogleProvider_MockServer(t *testing.T) {
+	var query url.Values
+	provider := NewGoogleProvider("test-key")
+	provider.Configure(Endpoint{BaseURL: newGoogleTestServer(t, &query).URL})
+	ctx := context.Background()
+
+	places, err := provider.GeocodeAddress(ctx, "1600 Amphitheatre Parkway")
+	if err != nil {
+		t.Fatalf("GeocodeAddress failed: %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(places))
+	}
+	if query.Has("components") || query.Has("bounds") {
+		t.Errorf("Expected no filters without a default filter, got %v", query)
+	}
+
+	address := places[0]
+	if address.PlaceType != "address" || address.AddressLine1 != "1600 Amphitheatre Parkway" || address.City != "Mountain View" ||
+		address.Region != "California" || address.PostalCode != "94043" || address.CountryCode != "US" || address.Confidence != 1 {
+		t.Errorf("Unexpected address %+v", address)
+	}
+	if address.Latitude != 37.4224428 || address.Source != "Google" || address.SourcePlaceID != "ChIJ2eUgeAK6j4ARbn5u_wAGqWA" ||
+		address.BoundingBox != "[37.4212,37.4239,-122.0856,-122.0829]" {
+		t.Errorf("Unexpected address location or source %+v", address)
+	}
+	if city := places[1]; city.PlaceType != "city" || city.Confidence != 0.3 || city.BoundingBox != "" {
+		t.Errorf("Expected an approximate partial match without a viewport, got %+v", city)
+	}
+	for _, place := range places {
+		if err := place.Validate(); err != nil {
+			t.Errorf("Expected a valid place for %s, got %v", place.SourcePlaceID, err)
+		}
+	}
+
+	t.Run("filters", func(t *testing.T) {
+		provider.Filter = GoogleFilter{Country: "us", Bounds: &GoogleBounds{South: 37.3, West: -122.2, North: 37.5, East: -121.9}}
+		t.Cleanup(func() { provider.Filter = GoogleFilter{} })
+		if _, err := provider.GeocodeAddress(ctx, "1600 Amphitheatre Parkway"); err != nil {
+			t.Fatalf("GeocodeAddress failed: %v", err)
+		}
+		if query.Get("components") != "country:US" || query.Get("bounds") != "37.3,-122.2|37.5,-121.9" {
+			t.Errorf("Expected the default country filter and bounds, got %v", query)
+		}
+
+		_, err := provider.Geocode(ctx, "", GoogleFilter{Country: "GB", PostalCode: "SW1A 2AA"})
+		if query.Get("components") != "country:GB|postal_code:SW1A 2AA" || query.Has("address") || query.Has("bounds") {
+			t.Errorf("Expected a components-only lookup, got %v", query)
+		}
+		if err == nil || !strings.Contains(err.Error(), "postal_code:SW1A 2AA") {
+			t.Errorf("Expected no results to name the filter, got %v", err)
+		}
+		if _, err := provider.Geocode(ctx, " ", GoogleFilter{}); err == nil {
+			t.Error("Expected an error without an address or a component")
+		}
+	})
+
+	place, err := provider.ReverseGeocode(ctx, 51.5033, -0.1276)
+	if err != nil {
+		t.Fatalf("ReverseGeocode failed: %v", err)
+	}
+	if place.PlaceType != "address" || place.City != "London" || place.AddressLine1 != "10 Downing Street" ||
+		place.CountryCode != "GB" || place.Confidence != 0.8 || place.Latitude != 51.5033 {
+		t.Errorf("Expected the postal town as the city of an interpolated address, got %+v", place)
+	}
+	if _, err := provider.ReverseGeocode(ctx, 0, 0); err == nil {
+		t.Error("Expected an error without results")
+	}
+	if _, err := provider.ReverseGeocode(ctx, 0, 181); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates, got %v", err)
+	}
+
+	provider.APIKey = "exhausted"
+	if _, err := provider.GeocodeAddress(ctx, "1600 Amphitheatre Parkway"); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited over the quota, got %v", err)
+	}
+	provider.APIKey = "wrong"
+	var providerErr *ProviderError
+	if _, err := provider.GeocodeAddress(ctx, "1600 Amphitheatre Parkway"); !errors.As(err, &providerErr) || providerErr.Kind != nil ||
+		!strings.Contains(err.Error(), "REQUEST_DENIED") {
+		t.Errorf("Expected an unclassified REQUEST_DENIED error for a bad key, got %v", err)
+	}
+}
+
+func TestParseGoogleBounds(t *testing.T) {
+	bounds, err := ParseGoogleBounds("38.8,-77.2|39.0,-76.9")
+	if err != nil || *bounds != (GoogleBounds{South: 38.8, West: -77.2, North: 39, East: -76.9}) {
+		t.Fatalf("Unexpected bounds %+v (%v)", bounds, err)
+	}
+	if bounds.String() != "38.8,-77.2|39,-76.9" {
+		t.Errorf("Expected the bounds parameter back, got %q", bounds.String())
+	}
+	for _, value := range []string{"", "38.8,-77.2", "38.8,-77.2|39.0", "91,0|92,1", "39.0,-77.2|38.8,-76.9", "a,b|c,d"} {
+		if _, err := ParseGoogleBounds(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestGooglePlaceType(t *testing.T) {
+	tests := map[string][]string{
+		"address":  {"street_address"},
+		"poi":      {"establishment", "point_of_interest", "university"},
+		"city":     {"locality", "political"},
+		"region":   {"administrative_area_level_1", "political"},
+		"airport":  {"airport"},
+		"":         nil,
+		"postcode": {"postal_code"},
+	}
+	for want, types := range tests {
+		if got := googlePlaceType(types); got != want {
+			t.Errorf("googlePlaceType(%v) = %q, want %q", types, got, want)
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// MapboxProvider implements GeocodeProvider for the Mapbox Geocoding API (v5,
+// mapbox.places), which geocodes addresses, points of interest and places worldwide.
+// Requests count against the access token's monthly quota, so it has no health check.
+type MapboxProvider struct {
+	BaseURL     string
+	AccessToken string
+	HTTPClient  *http.Client
+
+	// Types restricts results to these Mapbox place types (e.g. "address", "place");
+	// empty allows all of them
+	Types []string
+}
+
+// NewMapboxProvider creates a new Mapbox geocoding provider using accessToken
+func NewMapboxProvider(accessToken string) *MapboxProvider {
+	return &MapboxProvider{
+		BaseURL:     "https://api.mapbox.com",
+		AccessToken: accessToken,
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Geocode, tracing.NewTransport("Mapbox", requestlog.NewTransport("Mapbox", retry.NewTransport("Mapbox", ratelimit.NewTransport("Mapbox", breaker.NewTransport("Mapbox", nil)))))),
+		},
+	}
+}
+
+func (m *MapboxProvider) GetName() string {
+	return "Mapbox"
+}
+
+func (m *MapboxProvider) SupportedRegions() []string {
+	return []string{GlobalRegion}
+}
+
+// Mapbox API response structures
+type MapboxResponse struct {
+	Features []MapboxFeature `json:"features"`
+}
+
+type MapboxFeature struct {
+	ID         string           `json:"id"` // "{place type}.{id}"
+	PlaceType  []string         `json:"place_type"`
+	Relevance  float64          `json:"relevance"`
+	Text       string           `json:"text"`
+	PlaceName  string           `json:"place_name"`
+	Address    string           `json:"address"` // house number of address features
+	Center     []float64        `json:"center"`  // lon, lat
+	BBox       []float64        `json:"bbox"`    // min lon, min lat, max lon, max lat
+	Properties MapboxProperties `json:"properties"`
+	Context    []MapboxContext  `json:"context"`
+}
+
+type MapboxProperties struct {
+	Accuracy  string `json:"accuracy"`   // rooftop, parcel, point, interpolated, intersection or street
+	Address   string `json:"address"`    // street address of a point of interest
+	ShortCode string `json:"short_code"` // country code, or ISO 3166-2 region code
+}
+
+// MapboxContext is one of the features containing a result, e.g. its postcode, place,
+// region and country
+type MapboxContext struct {
+	ID        string `json:"id"`
+	Text      string `json:"text"`
+	ShortCode string `json:"short_code"`
+}
+
+// mapboxAccuracyFactors scale the relevance of address matches by how precisely they
+// are placed; rooftop, parcel and point matches are kept as they are
+var mapboxAccuracyFactors = map[string]float64{
+	"interpolated": 0.9,
+	"intersection": 0.8,
+	"street":       0.7,
+}
+
+func (m *MapboxProvider) GeocodeAddress(ctx context.Context, address string) ([]*models.Place, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return nil, fmt.Errorf("address is required")
+	}
+
+	var response MapboxResponse
+	if err := m.makeRequest(ctx, address, &response); err != nil {
+		return nil, fmt.Errorf("geocoding request failed: %w", err)
+	}
+
+	var places []*models.Place
+	for _, feature := range response.Features {
+		if len(feature.Center) != 2 {
+			continue
+		}
+		place := m.featureToPlace(&feature)
+		place.Latitude, place.Longitude = feature.Center[1], feature.Center[0]
+		places = append(places, place)
+	}
+
+	if len(places) == 0 {
+		return nil, fmt.Errorf("no geocoding results found for address: %s", address)
+	}
+
+	return places, nil
+}
+
+// ReverseGeocode returns the most specific feature at a point
+func (m *MapboxProvider) ReverseGeocode(ctx context.Context, lat, lon float64) (*models.Place, error) {
+	if err := validateCoordinates(m.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response MapboxResponse
+	if err := m.makeRequest(ctx, fmt.Sprintf("%.6f,%.6f", lon, lat), &response); err != nil {
+		return nil, fmt.Errorf("reverse geocoding request failed: %w", err)
+	}
+
+	if len(response.Features) == 0 {
+		return nil, fmt.Errorf("no reverse geocoding results found for coordinates: %f, %f", lat, lon)
+	}
+
+	place := m.featureToPlace(&response.Features[0])
+	place.Latitude, place.Longitude = lat, lon
+	return place, nil
+}
+
+// makeRequest looks query, an address or a "lon,lat" point, up in the mapbox.places
+// endpoint
+func (m *MapboxProvider) makeRequest(ctx context.Context, query string, result any) error {
+	params := url.Values{"access_token": {m.AccessToken}}
+	if len(m.Types) > 0 {
+		params.Set("types", strings.Join(m.Types, ","))
+	}
+	requestURL := fmt.Sprintf("%s/geocoding/v5/mapbox.places/%s.json?%s", m.BaseURL, url.PathEscape(query), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", requestURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", "weather-api/1.0")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := m.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(m.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(m.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// featureToPlace converts a feature, filling the address fields from the feature
+// itself and the features containing it. The caller sets the coordinates.
+func (m *MapboxProvider) featureToPlace(feature *MapboxFeature) *models.Place {
+	placeType := ""
+	if len(feature.PlaceType) > 0 {
+		placeType = feature.PlaceType[0]
+	}
+
+	place := &models.Place{
+		DisplayName:   feature.PlaceName,
+		PlaceType:     mapboxPlaceType(placeType),
+		Confidence:    mapboxConfidence(feature),
+		Source:        m.GetName(),
+		SourcePlaceID: feature.ID,
+		BoundingBox:   mapboxBoundingBox(feature.BBox),
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	switch placeType {
+	case "address":
+		place.AddressLine1 = strings.TrimSpace(feature.Address + " " + feature.Text)
+	case "poi":
+		place.AddressLine1 = feature.Properties.Address
+	}
+
+	self := MapboxContext{ID: feature.ID, Text: feature.Text, ShortCode: feature.Properties.ShortCode}
+	for _, c := range append([]MapboxContext{self}, feature.Context...) {
+		kind, _, _ := strings.Cut(c.ID, ".")
+		switch kind {
+		case "postcode":
+			place.PostalCode = c.Text
+		case "place":
+			place.City = c.Text
+		case "region":
+			place.Region = c.Text
+		case "country":
+			place.Country = c.Text
+			place.CountryCode = strings.ToUpper(c.ShortCode)
+		}
+	}
+
+	return place
+}
+
+// mapboxPlaceType returns the place type for a Mapbox one, which are kept except for
+// "place", Mapbox's term for cities, towns and villages
+func mapboxPlaceType(placeType string) string {
+	if placeType == "place" {
+		return "city"
+	}
+	return placeType
+}
+
+// mapboxConfidence is a feature's relevance (0-1, how well it matches the query),
+// lowered for addresses placed less precisely than their rooftop or parcel
+func mapboxConfidence(feature *MapboxFeature) float64 {
+	confidence := min(max(feature.Relevance, 0), 1)
+	if factor, ok := mapboxAccuracyFactors[feature.Properties.Accuracy]; ok {
+		confidence *= factor
+	}
+	return units.Round(confidence, 2)
+}
+
+// mapboxBoundingBox returns a Mapbox bbox (min lon, min lat, max lon, max lat) as the
+// stored bounding box, empty when the feature has none
+func mapboxBoundingBox(bbox []float64) string {
+	if len(bbox) != 4 {
+		return ""
+	}
+	return placeBoundingBox(bbox[1], bbox[3], bbox[0], bbox[2])
+}
@@ -0,0 +1,138 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const mapboxForwardJSON = `{"type": "FeatureCollection", "features": [
+	{"id": "address.7431", "place_type": ["address"], "relevance": 0.98, "text": "Karl Johans gate", "address": "22",
+	 "place_name": "Karl Johans gate 22, 0159 Oslo, Norway", "center": [10.7441, 59.9127],
+	 "properties": {"accuracy": "interpolated"},
+	 "context": [{"id": "postcode.1", "text": "0159"}, {"id": "place.2", "text": "Oslo"},
+	             {"id": "region.3", "text": "Oslo", "short_code": "NO-03"}, {"id": "country.4", "text": "Norway", "short_code": "no"}]},
+	{"id": "place.2", "place_type": ["place"], "relevance": 0.5, "text": "Oslo", "place_name": "Oslo, Norway",
+	 "center": [10.7528, 59.9139], "bbox": [10.4891, 59.8094, 10.9513, 60.1353],
+	 "context": [{"id": "country.4", "text": "Norway", "short_code": "no"}]},
+	{"id": "poi.9", "place_type": ["poi"], "relevance": 1.2, "text": "Nationaltheatret", "place_name": "Nationaltheatret, Oslo",
+	 "properties": {"address": "Johanne Dybwads plass 1"}}
+]}`
+
+const mapboxReverseJSON = `{"type": "FeatureCollection", "features": [
+	{"id": "address.7431", "place_type": ["address"], "relevance": 1, "text": "Karl Johans gate", "address": "22",
+	 "place_name": "Karl Johans gate 22, 0159 Oslo, Norway", "center": [10.7441, 59.9127], "properties": {"accuracy": "rooftop"},
+	 "context": [{"id": "place.2", "text": "Oslo"}, {"id": "country.4", "text": "Norway", "short_code": "no"}]},
+	{"id": "place.2", "place_type": ["place"], "relevance": 1, "text": "Oslo", "place_name": "Oslo, Norway", "center": [10.7528, 59.9139]}
+]}`
+
+func newMapboxTestServer(t *testing.T, types *string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("access_token") != "test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		*types = r.URL.Query().Get("types")
+		query, ok := strings.CutPrefix(r.URL.Path, "/geocoding/v5/mapbox.places/")
+		switch {
+		case !ok:
+			w.WriteHeader(http.StatusNotFound)
+		case query == "10.744100,59.912700.json":
+			w.Write([]byte(mapboxReverseJSON))
+		case query == "Karl Johans gate 22, Oslo.json":
+			w.Write([]byte(mapboxForwardJSON))
+		default:
+			w.Write([]byte(`{"type": "FeatureCollection", "features": []}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestMapboxProvider_MockServer(t *testing.T) {
+	var types string
+	provider := NewMapboxProvider("test-token")
+	provider.Configure(Endpoint{BaseURL: newMapboxTestServer(t, &types).URL})
+	ctx := context.Background()
+
+	places, err := provider.GeocodeAddress(ctx, "Karl Johans gate 22, Oslo")
+	if err != nil {
+		t.Fatalf("GeocodeAddress failed: %v", err)
+	}
+	if len(places) != 2 {
+		t.Fatalf("Expected the 2 features with a center, got %d", len(places))
+	}
+
+	address := places[0]
+	if address.PlaceType != "address" || address.AddressLine1 != "22 Karl Johans gate" || address.City != "Oslo" ||
+		address.Region != "Oslo" || address.PostalCode != "0159" || address.Country != "Norway" || address.CountryCode != "NO" {
+		t.Errorf("Unexpected address %+v", address)
+	}
+	if address.Latitude != 59.9127 || address.Longitude != 10.7441 || address.Source != "Mapbox" || address.SourcePlaceID != "address.7431" {
+		t.Errorf("Unexpected address location or source %+v", address)
+	}
+	if address.Confidence != 0.88 {
+		t.Errorf("Expected the relevance lowered for an interpolated address, got %v", address.Confidence)
+	}
+
+	city := places[1]
+	if city.PlaceType != "city" || city.City != "Oslo" || city.Confidence != 0.5 || city.BoundingBox != "[59.8094,60.1353,10.4891,10.9513]" {
+		t.Errorf("Unexpected city %+v", city)
+	}
+	for _, place := range places {
+		if err := place.Validate(); err != nil {
+			t.Errorf("Expected a valid place for %s, got %v", place.SourcePlaceID, err)
+		}
+	}
+
+	if _, err := provider.GeocodeAddress(ctx, "nowhere at all"); err == nil {
+		t.Error("Expected an error without results")
+	}
+
+	place, err := provider.ReverseGeocode(ctx, 59.9127, 10.7441)
+	if err != nil {
+		t.Fatalf("ReverseGeocode failed: %v", err)
+	}
+	if place.SourcePlaceID != "address.7431" || place.Confidence != 1 || place.Latitude != 59.9127 || place.CountryCode != "NO" {
+		t.Errorf("Expected the most specific feature at the point, got %+v", place)
+	}
+	if _, err := provider.ReverseGeocode(ctx, 91, 0); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates, got %v", err)
+	}
+
+	provider.Types = []string{"address", "poi"}
+	if _, err := provider.GeocodeAddress(ctx, "Karl Johans gate 22, Oslo"); err != nil || types != "address,poi" {
+		t.Errorf("Expected the types filter sent, got %q (%v)", types, err)
+	}
+
+	provider.AccessToken = "wrong"
+	var providerErr *ProviderError
+	if _, err := provider.GeocodeAddress(ctx, "Karl Johans gate 22, Oslo"); !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 provider error for a bad token, got %v", err)
+	}
+}
+
+func TestMapboxConfidence(t *testing.T) {
+	tests := []struct {
+		relevance float64
+		accuracy  string
+		want      float64
+	}{
+		{1, "rooftop", 1},
+		{0.9, "", 0.9},
+		{1, "street", 0.7},
+		{0.8, "intersection", 0.64},
+		{1.3, "", 1},
+		{-0.1, "", 0},
+	}
+	for _, tt := range tests {
+		feature := &MapboxFeature{Relevance: tt.relevance, Properties: MapboxProperties{Accuracy: tt.accuracy}}
+		if got := mapboxConfidence(feature); got != tt.want {
+			t.Errorf("mapboxConfidence(%v, %q) = %v, want %v", tt.relevance, tt.accuracy, got, tt.want)
+		}
+	}
+}
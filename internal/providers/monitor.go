@@ -0,0 +1,197 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Provider health statuses reported by HealthMonitor
+const (
+	HealthOK       = "ok"
+	HealthDegraded = "degraded" // the latest check passed but recent ones failed
+	HealthDown     = "error"    // the latest check failed
+	HealthUnknown  = "unknown"  // not checked yet, or the provider has no health check
+)
+
+// healthWindow is the number of latest checks a provider's success rate covers
+const healthWindow = 20
+
+// HealthStatus is what the health monitor knows of one provider
+type HealthStatus struct {
+	Name        string
+	Type        string
+	Status      string
+	Checks      int64   // checks run since startup
+	Failures    int64   // failed checks since startup
+	SuccessRate float64 // share of the last healthWindow checks that passed, 0 to 1
+	Latency     time.Duration
+	LastError   string
+	LastErrorAt time.Time
+	CheckedAt   time.Time
+	LastOKAt    time.Time
+}
+
+// monitored is a provider under watch and its check history
+type monitored struct {
+	description Description
+	status      HealthStatus
+	err         error
+	recent      [healthWindow]bool // ring buffer of check outcomes
+	next        int
+}
+
+// HealthMonitor runs the health checks of every registered provider on an interval and
+// keeps their outcomes, so status pages and readiness probes answer from the latest
+// results instead of calling upstreams per request. Providers without a health check
+// are listed with an unknown status.
+type HealthMonitor struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	mu      sync.Mutex
+	targets []*monitored // in Describe order
+	now     func() time.Time
+}
+
+// NewHealthMonitor creates a monitor of the providers registered with manager, checking
+// each every interval with at most timeout per check
+func NewHealthMonitor(manager *ProviderManager, interval, timeout time.Duration) *HealthMonitor {
+	m := &HealthMonitor{interval: interval, timeout: timeout, now: time.Now}
+	for _, description := range manager.Describe() {
+		m.targets = append(m.targets, &monitored{
+			description: description,
+			status:      HealthStatus{Name: description.Name, Type: description.Type, Status: HealthUnknown},
+		})
+	}
+	return m
+}
+
+// Run checks every provider at once and then every interval until ctx ends
+func (m *HealthMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.CheckAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll runs the health check of every checkable provider concurrently
+func (m *HealthMonitor) CheckAll(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, target := range m.targets {
+		if target.description.Health == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.check(ctx, target)
+		}()
+	}
+	wg.Wait()
+}
+
+// check runs one provider's health check and records its outcome
+func (m *HealthMonitor) check(ctx context.Context, target *monitored) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	start := m.now()
+	err := target.description.Health.HealthCheck(ctx)
+	if err != nil && ctx.Err() != nil && !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		// The monitor is stopping; a canceled check says nothing about the provider
+		return err
+	}
+	end := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	status := &target.status
+	status.Checks++
+	status.Latency = end.Sub(start)
+	status.CheckedAt = end
+	target.err = err
+	target.recent[target.next%healthWindow] = err == nil
+	target.next++
+	if err != nil {
+		status.Failures++
+		status.LastError = err.Error()
+		status.LastErrorAt = end
+	} else {
+		status.LastOKAt = end
+	}
+
+	window := min(target.next, healthWindow)
+	passed := 0
+	for _, ok := range target.recent[:window] {
+		if ok {
+			passed++
+		}
+	}
+	status.SuccessRate = float64(passed) / float64(window)
+	switch {
+	case err != nil:
+		status.Status = HealthDown
+	case passed < window:
+		status.Status = HealthDegraded
+	default:
+		status.Status = HealthOK
+	}
+	return err
+}
+
+// Statuses returns the health of every registered provider in Describe order
+func (m *HealthMonitor) Statuses() []HealthStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	statuses := make([]HealthStatus, len(m.targets))
+	for i, target := range m.targets {
+		statuses[i] = target.status
+	}
+	return statuses
+}
+
+// Status returns the health of the provider of the given type and name
+func (m *HealthMonitor) Status(kind, name string) (HealthStatus, bool) {
+	if target := m.find(kind, name); target != nil {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return target.status, true
+	}
+	return HealthStatus{}, false
+}
+
+// Probe returns a readiness probe reporting the latest check of the provider of the
+// given type and name. Until the monitor has checked it, the probe runs the check
+// itself. It returns nil for providers the monitor does not know or cannot check.
+func (m *HealthMonitor) Probe(kind, name string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		target := m.find(kind, name)
+		if target == nil || target.description.Health == nil {
+			return nil
+		}
+		m.mu.Lock()
+		checked, err := target.status.Checks > 0, target.err
+		m.mu.Unlock()
+		if checked {
+			return err
+		}
+		return m.check(ctx, target)
+	}
+}
+
+func (m *HealthMonitor) find(kind, name string) *monitored {
+	for _, target := range m.targets {
+		if target.description.Type == kind && target.description.Name == name {
+			return target
+		}
+	}
+	return nil
+}
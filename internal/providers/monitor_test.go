@@ -0,0 +1,76 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// flakyProvider is a geocoder whose health check returns the queued results in order
+type flakyProvider struct {
+	MockGeocodeProvider
+	results []error
+	checks  int
+}
+
+func (f *flakyProvider) HealthCheck(ctx context.Context) error {
+	err := f.results[min(f.checks, len(f.results)-1)]
+	f.checks++
+	return err
+}
+
+func TestHealthMonitor(t *testing.T) {
+	flaky := &flakyProvider{
+		MockGeocodeProvider: MockGeocodeProvider{name: "Census"},
+		results:             []error{nil, errors.New("connection refused"), nil},
+	}
+	manager := NewProviderManager()
+	manager.RegisterWeatherProvider(&MockWeatherProvider{name: "NWS", regions: []string{"US"}})
+	manager.RegisterGeocodeProvider(flaky)
+	monitor := NewHealthMonitor(manager, time.Minute, time.Second)
+
+	if status, ok := monitor.Status(TypeGeocode, "Census"); !ok || status.Status != HealthUnknown {
+		t.Fatalf("Expected an unknown status before checks, got %+v", status)
+	}
+
+	for _, want := range []string{HealthOK, HealthDown, HealthDegraded} {
+		monitor.CheckAll(context.Background())
+		if status, _ := monitor.Status(TypeGeocode, "Census"); status.Status != want {
+			t.Errorf("Check %d: expected %s, got %s", flaky.checks, want, status.Status)
+		}
+	}
+
+	status, _ := monitor.Status(TypeGeocode, "Census")
+	if status.Checks != 3 || status.Failures != 1 || status.LastError != "connection refused" || status.LastOKAt.IsZero() {
+		t.Errorf("Unexpected status %+v", status)
+	}
+	if status.SuccessRate < 0.66 || status.SuccessRate > 0.67 {
+		t.Errorf("Expected a success rate of 2/3, got %f", status.SuccessRate)
+	}
+
+	statuses := monitor.Statuses()
+	if len(statuses) != 2 || statuses[0].Name != "NWS" || statuses[0].Status != HealthUnknown || statuses[0].Checks != 0 {
+		t.Errorf("Expected the unchecked weather provider first, got %+v", statuses)
+	}
+}
+
+func TestHealthMonitorProbe(t *testing.T) {
+	flaky := &flakyProvider{MockGeocodeProvider: MockGeocodeProvider{name: "Census"}, results: []error{errors.New("timeout")}}
+	manager := NewProviderManager()
+	manager.RegisterGeocodeProvider(flaky)
+	monitor := NewHealthMonitor(manager, time.Minute, time.Second)
+
+	probe := monitor.Probe(TypeGeocode, "Census")
+	for range 2 {
+		if err := probe(context.Background()); err == nil || err.Error() != "timeout" {
+			t.Errorf("Expected the latest check's error, got %v", err)
+		}
+	}
+	if flaky.checks != 1 {
+		t.Errorf("Expected the probe to check once and then reuse the result, got %d checks", flaky.checks)
+	}
+	if err := monitor.Probe(TypeWeather, "NWS")(context.Background()); err != nil {
+		t.Errorf("Expected unknown providers to pass, got %v", err)
+	}
+}
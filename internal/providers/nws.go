@@ -3,13 +3,27 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/geo"
 	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
 )
 
 // NWSProvider implements WeatherProvider for the National Weather Service API
@@ -26,7 +40,8 @@ func NewNWSProvider() *NWSProvider {
 		// TODO: Replace with actual contact
 		UserAgent: "weather-api/1.0 (contact@example.com)",
 		HTTPClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("NWS", requestlog.NewTransport("NWS", retry.NewTransport("NWS", ratelimit.NewTransport("NWS", breaker.NewTransport("NWS", nil))))))),
 		},
 	}
 }
@@ -39,6 +54,12 @@ func (n *NWSProvider) SupportedRegions() []string {
 	return []string{"US"} // NWS only covers United States
 }
 
+// HealthCheck checks that the NWS API answers its status endpoint
+func (n *NWSProvider) HealthCheck(ctx context.Context) error {
+	_, err := n.makeRequest(ctx, n.BaseURL+"/")
+	return err
+}
+
 // NWS API Response structures
 type NWSPointResponse struct {
 	Properties NWSPointProperties `json:"properties"`
@@ -50,6 +71,7 @@ type NWSPointProperties struct {
 	GridY               int    `json:"gridY"`
 	Forecast            string `json:"forecast"`
 	ForecastHourly      string `json:"forecastHourly"`
+	ForecastGridData    string `json:"forecastGridData"`
 	ObservationStations string `json:"observationStations"`
 }
 
@@ -74,6 +96,46 @@ type NWSForecastPeriod struct {
 	Icon             string `json:"icon"`
 	ShortForecast    string `json:"shortForecast"`
 	DetailedForecast string `json:"detailedForecast"`
+
+	// Reported by the hourly forecast; probabilityOfPrecipitation also appears in the
+	// daily forecast
+	ProbabilityOfPrecipitation NWSQuantitativeValue `json:"probabilityOfPrecipitation"`
+	Dewpoint                   NWSQuantitativeValue `json:"dewpoint"`
+	RelativeHumidity           NWSQuantitativeValue `json:"relativeHumidity"`
+}
+
+// NWSGridpointResponse is the raw forecast grid data; only the layers used are decoded.
+// The grid has no UV index, so NWS forecasts never report one.
+type NWSGridpointResponse struct {
+	Properties struct {
+		WindGust                  NWSGridpointLayer `json:"windGust"`
+		ApparentTemperature       NWSGridpointLayer `json:"apparentTemperature"`
+		SkyCover                  NWSGridpointLayer `json:"skyCover"`
+		QuantitativePrecipitation NWSGridpointLayer `json:"quantitativePrecipitation"`
+	} `json:"properties"`
+}
+
+// NWSGridpointLayer is a time series of one gridpoint quantity. Each ValidTime is an
+// ISO 8601 interval such as "2024-01-15T12:00:00+00:00/PT3H".
+type NWSGridpointLayer struct {
+	UOM    string `json:"uom"`
+	Values []struct {
+		ValidTime string   `json:"validTime"`
+		Value     *float64 `json:"value"`
+	} `json:"values"`
+}
+
+// NWSStationResponse is an observation station; coordinates are [longitude, latitude]
+type NWSStationResponse struct {
+	Geometry struct {
+		Coordinates []float64 `json:"coordinates"`
+	} `json:"geometry"`
+	Properties struct {
+		StationIdentifier string               `json:"stationIdentifier"`
+		Name              string               `json:"name"`
+		TimeZone          string               `json:"timeZone"`
+		Elevation         NWSQuantitativeValue `json:"elevation"`
+	} `json:"properties"`
 }
 
 type NWSObservationResponse struct {
@@ -86,7 +148,10 @@ type NWSObservationProperties struct {
 	Dewpoint           NWSQuantitativeValue `json:"dewpoint"`
 	WindDirection      NWSQuantitativeValue `json:"windDirection"`
 	WindSpeed          NWSQuantitativeValue `json:"windSpeed"`
+	WindGust           NWSQuantitativeValue `json:"windGust"`
 	BarometricPressure NWSQuantitativeValue `json:"barometricPressure"`
+	SeaLevelPressure   NWSQuantitativeValue `json:"seaLevelPressure"`
+	Elevation          NWSQuantitativeValue `json:"elevation"`
 	RelativeHumidity   NWSQuantitativeValue `json:"relativeHumidity"`
 	Visibility         NWSQuantitativeValue `json:"visibility"`
 	TextDescription    string               `json:"textDescription"`
@@ -103,6 +168,7 @@ type NWSAlertsResponse struct {
 }
 
 type NWSAlert struct {
+	Geometry   json.RawMessage    `json:"geometry"` // null for alerts issued by zone
 	Properties NWSAlertProperties `json:"properties"`
 }
 
@@ -166,6 +232,48 @@ func (n *NWSProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (
 	return n.observationToForecast(&obsResp, lat, lon)
 }
 
+// GetStation retrieves an observation station by its identifier
+func (n *NWSProvider) GetStation(ctx context.Context, stationID string) (*models.Station, error) {
+	data, err := n.makeRequest(ctx, fmt.Sprintf("%s/stations/%s", n.BaseURL, stationID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get station: %w", err)
+	}
+
+	var stationResp NWSStationResponse
+	if err := json.Unmarshal(data, &stationResp); err != nil {
+		return nil, fmt.Errorf("failed to parse station response: %w", err)
+	}
+	if len(stationResp.Geometry.Coordinates) < 2 {
+		return nil, fmt.Errorf("station %s has no coordinates", stationID)
+	}
+
+	return &models.Station{
+		StationID:      stationResp.Properties.StationIdentifier,
+		Name:           stationResp.Properties.Name,
+		SourceProvider: n.GetName(),
+		Latitude:       stationResp.Geometry.Coordinates[1],
+		Longitude:      stationResp.Geometry.Coordinates[0],
+		Elevation:      stationResp.Properties.Elevation.Value,
+		TimeZone:       stationResp.Properties.TimeZone,
+	}, nil
+}
+
+// GetLatestObservation retrieves the most recent observation of a station, keeping
+// the quality-control code NWS attaches to each measurement
+func (n *NWSProvider) GetLatestObservation(ctx context.Context, stationID string) (*models.Observation, error) {
+	data, err := n.makeRequest(ctx, fmt.Sprintf("%s/stations/%s/observations/latest", n.BaseURL, stationID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest observation: %w", err)
+	}
+
+	var obsResp NWSObservationResponse
+	if err := json.Unmarshal(data, &obsResp); err != nil {
+		return nil, fmt.Errorf("failed to parse observation response: %w", err)
+	}
+
+	return n.toObservation(&obsResp, stationID)
+}
+
 func (n *NWSProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
 	// Get grid point info
 	point, err := n.getGridPoint(ctx, lat, lon)
@@ -191,20 +299,285 @@ func (n *NWSProvider) GetForecast(ctx context.Context, lat, lon float64, days in
 		maxPeriods = len(forecastResp.Properties.Periods)
 	}
 
+	grid := n.getGridData(ctx, point)
 	for i := 0; i < maxPeriods; i++ {
 		period := forecastResp.Properties.Periods[i]
 		forecast, err := n.periodToForecast(&period, lat, lon)
 		if err != nil {
 			continue // Skip invalid periods
 		}
+		if grid != nil {
+			applyGridData(forecast, grid, &period)
+		}
 		forecasts = append(forecasts, forecast)
 	}
 
 	return forecasts, nil
 }
 
+// maxHourlyPeriods is the length of the NWS hourly forecast, 6.5 days
+const maxHourlyPeriods = 156
+
+// GetHourlyForecast retrieves up to hours periods of the NWS hourly forecast
+func (n *NWSProvider) GetHourlyForecast(ctx context.Context, lat, lon float64, hours int) ([]*HourlyForecast, error) {
+	point, err := n.getGridPoint(ctx, lat, lon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get grid point: %w", err)
+	}
+	if point.Properties.ForecastHourly == "" {
+		return nil, fmt.Errorf("no hourly forecast published for this point")
+	}
+
+	forecastData, err := n.makeRequest(ctx, point.Properties.ForecastHourly)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hourly forecast: %w", err)
+	}
+
+	var forecastResp NWSForecastResponse
+	if err := json.Unmarshal(forecastData, &forecastResp); err != nil {
+		return nil, fmt.Errorf("failed to parse hourly forecast response: %w", err)
+	}
+
+	hours = min(hours, maxHourlyPeriods, len(forecastResp.Properties.Periods))
+	forecasts := make([]*HourlyForecast, 0, hours)
+	grid := n.getGridData(ctx, point)
+	for i := 0; i < hours; i++ {
+		period := forecastResp.Properties.Periods[i]
+		forecast, err := n.periodToForecast(&period, lat, lon)
+		if err != nil {
+			continue // Skip invalid periods
+		}
+		if grid != nil {
+			applyGridData(forecast, grid, &period)
+		}
+		// Hourly periods are too short for a detailed forecast
+		forecast.Description = period.ShortForecast
+
+		hourly := &HourlyForecast{Forecast: *forecast, PrecipitationProbability: period.ProbabilityOfPrecipitation.Value}
+		if dewpoint := period.Dewpoint.Value; dewpoint != nil {
+			celsius := *dewpoint
+			if period.Dewpoint.UnitCode == "wmoUnit:degF" {
+				celsius = (celsius - 32) * 5 / 9
+			}
+			celsius = units.Round(celsius, 1)
+			hourly.Dewpoint = &celsius
+		}
+		forecasts = append(forecasts, hourly)
+	}
+
+	return forecasts, nil
+}
+
+// getGridData fetches the raw grid data for a point. The values it adds to forecasts
+// are optional, so a failure to fetch it returns nil and leaves them unset.
+func (n *NWSProvider) getGridData(ctx context.Context, point *NWSPointResponse) *NWSGridpointResponse {
+	if point.Properties.ForecastGridData == "" {
+		return nil
+	}
+	gridData, err := n.makeRequest(ctx, point.Properties.ForecastGridData)
+	if err != nil {
+		return nil
+	}
+	var grid NWSGridpointResponse
+	if json.Unmarshal(gridData, &grid) != nil {
+		return nil
+	}
+	return &grid
+}
+
+// applyGridData fills in the forecast values the period endpoints lack from the grid
+// layers overlapping the period. Values without grid data are left at zero.
+func applyGridData(forecast *models.Forecast, grid *NWSGridpointResponse, period *NWSForecastPeriod) {
+	start, err := time.Parse(time.RFC3339, period.StartTime)
+	if err != nil {
+		return
+	}
+	end, err := time.Parse(time.RFC3339, period.EndTime)
+	if err != nil {
+		return
+	}
+
+	forecast.WindGust = periodGust(&grid.Properties.WindGust, period, forecast.WindSpeed)
+	if feelsLike, ok := periodFeelsLike(&grid.Properties.ApparentTemperature, start, end, period.IsDaytime); ok {
+		forecast.FeelsLike = feelsLike
+	}
+	if cover, ok := periodMean(&grid.Properties.SkyCover, start, end); ok {
+		forecast.CloudCover = units.Round(cover, 0)
+	}
+	if precipitation, ok := periodTotal(&grid.Properties.QuantitativePrecipitation, start, end); ok {
+		forecast.Precipitation = units.Round(precipitation, 1)
+	}
+}
+
+// overlaps calls fn with each value of the layer overlapping [start, end), along with
+// the overlap and the length of the value's interval
+func (l *NWSGridpointLayer) overlaps(start, end time.Time, fn func(value float64, overlap, interval time.Duration)) {
+	for _, v := range l.Values {
+		from, to, ok := parseValidInterval(v.ValidTime)
+		if !ok || v.Value == nil || !from.Before(end) || !to.After(start) {
+			continue
+		}
+		interval := to.Sub(from)
+		if to.After(end) {
+			to = end
+		}
+		if from.Before(start) {
+			from = start
+		}
+		fn(*v.Value, to.Sub(from), interval)
+	}
+}
+
+// periodFeelsLike returns the apparent temperature in °C for a period: the highest
+// during the day and the lowest at night, matching the period's temperature
+func periodFeelsLike(layer *NWSGridpointLayer, start, end time.Time, daytime bool) (float64, bool) {
+	var values []float64
+	layer.overlaps(start, end, func(value float64, _, _ time.Duration) {
+		values = append(values, value)
+	})
+	if len(values) == 0 {
+		return 0, false
+	}
+
+	feelsLike := slices.Min(values)
+	if daytime {
+		feelsLike = slices.Max(values)
+	}
+	if layer.UOM == "wmoUnit:degF" {
+		feelsLike = (feelsLike - 32) * 5 / 9
+	}
+	return units.Round(feelsLike, 1), true
+}
+
+// periodMean returns the mean of a layer over a period, weighting each value by how
+// long it overlaps the period
+func periodMean(layer *NWSGridpointLayer, start, end time.Time) (float64, bool) {
+	var sum float64
+	var total time.Duration
+	layer.overlaps(start, end, func(value float64, overlap, _ time.Duration) {
+		sum += value * overlap.Hours()
+		total += overlap
+	})
+	if total == 0 {
+		return 0, false
+	}
+	return sum / total.Hours(), true
+}
+
+// periodTotal returns the amount of an accumulating layer, such as precipitation,
+// falling within a period. Amounts are assumed to fall evenly over their interval, so
+// intervals straddling the period boundary count in proportion.
+func periodTotal(layer *NWSGridpointLayer, start, end time.Time) (float64, bool) {
+	var sum float64
+	found := false
+	layer.overlaps(start, end, func(value float64, overlap, interval time.Duration) {
+		sum += value * overlap.Hours() / interval.Hours()
+		found = true
+	})
+	if layer.UOM == "wmoUnit:in" {
+		sum *= 25.4
+	}
+	return sum, found
+}
+
+// gustPercentile is the percentile of the gridpoint gusts within a period reported as
+// its gust: the maximum over a 12-hour period overstates a single passing squall
+const gustPercentile = 90
+
+// periodGust returns the gust in m/s for a forecast period from the gridpoint windGust
+// layer, or 0 when no gust overlaps the period or it is below the sustained windSpeed
+func periodGust(layer *NWSGridpointLayer, period *NWSForecastPeriod, windSpeed float64) float64 {
+	start, err := time.Parse(time.RFC3339, period.StartTime)
+	if err != nil {
+		return 0
+	}
+	end, err := time.Parse(time.RFC3339, period.EndTime)
+	if err != nil {
+		return 0
+	}
+
+	var values []float64
+	layer.overlaps(start, end, func(value float64, _, _ time.Duration) {
+		values = append(values, value)
+	})
+	if len(values) == 0 {
+		return 0
+	}
+
+	gust := percentile(values, gustPercentile)
+	if layer.UOM == "wmoUnit:km_h-1" {
+		gust /= 3.6
+	}
+	gust = units.Round(gust, 1)
+	if gust < windSpeed {
+		return 0
+	}
+	return gust
+}
+
+// parseValidInterval parses an ISO 8601 "start/duration" interval as used by gridpoint
+// layers, e.g. "2024-01-15T12:00:00+00:00/PT3H" or ".../P1DT6H"
+func parseValidInterval(value string) (time.Time, time.Time, bool) {
+	startText, durationText, ok := strings.Cut(value, "/")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	start, err := time.Parse(time.RFC3339, startText)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	rest, ok := strings.CutPrefix(durationText, "P")
+	if !ok || rest == "" {
+		return time.Time{}, time.Time{}, false
+	}
+	var duration time.Duration
+	inTime := false
+	number := 0
+	digits := false
+	for _, r := range rest {
+		switch {
+		case r >= '0' && r <= '9':
+			number = number*10 + int(r-'0')
+			digits = true
+			continue
+		case r == 'T':
+			inTime = true
+			continue
+		case !digits:
+			return time.Time{}, time.Time{}, false
+		case r == 'D' && !inTime:
+			duration += time.Duration(number) * 24 * time.Hour
+		case r == 'H' && inTime:
+			duration += time.Duration(number) * time.Hour
+		case r == 'M' && inTime:
+			duration += time.Duration(number) * time.Minute
+		default:
+			return time.Time{}, time.Time{}, false
+		}
+		number, digits = 0, false
+	}
+	if digits || duration == 0 {
+		return time.Time{}, time.Time{}, false
+	}
+	return start, start.Add(duration), true
+}
+
+// percentile returns the nearest-rank pth percentile of values, reordering them
+func percentile(values []float64, p int) float64 {
+	sort.Float64s(values)
+	rank := (p*len(values) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
 func (n *NWSProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]WeatherAlert, error) {
-	alertsURL := fmt.Sprintf("%s/alerts/active?point=%f,%f", n.BaseURL, lat, lon)
+	if err := validateCoordinates(n.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+	alertsURL := fmt.Sprintf("%s/alerts/active?point=%s", n.BaseURL, geo.FormatPoint(lat, lon, geo.NWSPrecision))
 
 	alertData, err := n.makeRequest(ctx, alertsURL)
 	if err != nil {
@@ -229,10 +602,23 @@ func (n *NWSProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]Weathe
 }
 
 func (n *NWSProvider) getGridPoint(ctx context.Context, lat, lon float64) (*NWSPointResponse, error) {
-	url := fmt.Sprintf("%s/points/%f,%f", n.BaseURL, lat, lon)
+	if err := validateCoordinates(n.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+	url := fmt.Sprintf("%s/points/%s", n.BaseURL, geo.FormatPoint(lat, lon, geo.NWSPrecision))
 
 	data, err := n.makeRequest(ctx, url)
 	if err != nil {
+		// The points endpoint answers 404 outside the NWS grid and 400 for malformed points
+		var providerErr *ProviderError
+		if errors.As(err, &providerErr) && providerErr.Kind == nil {
+			switch providerErr.StatusCode {
+			case http.StatusNotFound:
+				providerErr.Kind = ErrUnsupportedRegion
+			case http.StatusBadRequest:
+				providerErr.Kind = ErrBadCoordinates
+			}
+		}
 		return nil, err
 	}
 
@@ -255,12 +641,12 @@ func (n *NWSProvider) makeRequest(ctx context.Context, url string) ([]byte, erro
 
 	resp, err := n.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, requestError(n.GetName(), err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API request failed with status %d", resp.StatusCode)
+		return nil, statusError(n.GetName(), resp)
 	}
 
 	var result json.RawMessage
@@ -303,9 +689,25 @@ func (n *NWSProvider) observationToForecast(obs *NWSObservationResponse, lat, lo
 		forecast.Humidity = *obs.Properties.RelativeHumidity.Value
 	}
 
-	// Convert pressure (hPa)
-	if obs.Properties.BarometricPressure.Value != nil {
-		forecast.Pressure = *obs.Properties.BarometricPressure.Value / 100 // Convert Pa to hPa
+	// Convert pressure (hPa). barometricPressure is measured at the station and
+	// seaLevelPressure is reduced to mean sea level; when only one is reported the other
+	// is derived from the station elevation, taken as sea level when unknown.
+	var elevation float64
+	if obs.Properties.Elevation.Value != nil {
+		elevation = *obs.Properties.Elevation.Value
+	}
+	station, seaLevel := obs.Properties.BarometricPressure.Value, obs.Properties.SeaLevelPressure.Value
+	if station != nil {
+		forecast.StationPressure = *station / 100 // Convert Pa to hPa
+	}
+	if seaLevel != nil {
+		forecast.Pressure = *seaLevel / 100
+	}
+	switch {
+	case station != nil && seaLevel == nil:
+		forecast.Pressure = units.Round(units.StationToSeaLevelPressure(forecast.StationPressure, elevation, forecast.Temperature), 2)
+	case station == nil && seaLevel != nil:
+		forecast.StationPressure = units.Round(units.SeaLevelToStationPressure(forecast.Pressure, elevation, forecast.Temperature), 2)
 	}
 
 	// Convert wind speed (m/s)
@@ -313,6 +715,11 @@ func (n *NWSProvider) observationToForecast(obs *NWSObservationResponse, lat, lo
 		forecast.WindSpeed = *obs.Properties.WindSpeed.Value
 	}
 
+	// Convert wind gust (m/s)
+	if obs.Properties.WindGust.Value != nil {
+		forecast.WindGust = *obs.Properties.WindGust.Value
+	}
+
 	// Convert wind direction (degrees)
 	if obs.Properties.WindDirection.Value != nil {
 		forecast.WindDirection = *obs.Properties.WindDirection.Value
@@ -323,9 +730,83 @@ func (n *NWSProvider) observationToForecast(obs *NWSObservationResponse, lat, lo
 		forecast.Visibility = *obs.Properties.Visibility.Value / 1000 // Convert m to km
 	}
 
+	forecast.FeelsLike = units.Round(calc.FeelsLike(forecast.Temperature, forecast.Humidity, forecast.WindSpeed), 1)
+
 	return forecast, nil
 }
 
+// toObservation converts an NWS observation to the units used by forecasts. Unlike
+// observationToForecast it keeps missing measurements nil and does not derive one
+// pressure from the other.
+func (n *NWSProvider) toObservation(obs *NWSObservationResponse, stationID string) (*models.Observation, error) {
+	if obs.Properties.Timestamp == "" {
+		return nil, fmt.Errorf("observation for station %s has no timestamp", stationID)
+	}
+	observedAt, err := time.Parse(time.RFC3339, obs.Properties.Timestamp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp: %w", err)
+	}
+
+	observation := &models.Observation{
+		StationID:      stationID,
+		SourceProvider: n.GetName(),
+		ObservedAt:     observedAt,
+		Description:    obs.Properties.TextDescription,
+		QualityFlags:   make(map[string]string),
+	}
+
+	// Each measurement is stored with its scale factor to the forecast units
+	fields := []struct {
+		name   string
+		value  NWSQuantitativeValue
+		target **float64
+		scale  float64
+	}{
+		{"temperature", obs.Properties.Temperature, &observation.Temperature, 1},
+		{"dewpoint", obs.Properties.Dewpoint, &observation.Dewpoint, 1},
+		{"humidity", obs.Properties.RelativeHumidity, &observation.Humidity, 1},
+		{"wind_direction", obs.Properties.WindDirection, &observation.WindDirection, 1},
+		{"wind_speed", obs.Properties.WindSpeed, &observation.WindSpeed, 1},
+		{"wind_gust", obs.Properties.WindGust, &observation.WindGust, 1},
+		{"pressure", obs.Properties.SeaLevelPressure, &observation.Pressure, 0.01},                  // Pa to hPa
+		{"station_pressure", obs.Properties.BarometricPressure, &observation.StationPressure, 0.01}, // Pa to hPa
+		{"visibility", obs.Properties.Visibility, &observation.Visibility, 0.001},                   // m to km
+	}
+	for _, field := range fields {
+		if field.value.Value != nil {
+			value := *field.value.Value * field.scale
+			// NWS usually reports wind speeds in km/h rather than m/s
+			if field.value.UnitCode == "wmoUnit:km_h-1" {
+				value /= 3.6
+			}
+			*field.target = &value
+		}
+		if flag := strings.TrimPrefix(field.value.QualityControl, "qc:"); flag != "" {
+			observation.QualityFlags[field.name] = flag
+		}
+	}
+
+	return observation, nil
+}
+
+// nwsIconCode returns the icon name of a forecast icon URL, e.g. "tsra_hi" for
+// https://api.weather.gov/icons/land/day/tsra_hi,40?size=medium. An icon changing
+// during the period lists two; the first, for its start, is returned.
+func nwsIconCode(icon string) string {
+	parsed, err := url.Parse(icon)
+	if err != nil {
+		return ""
+	}
+	segments := strings.Split(parsed.Path, "/")
+	for i, segment := range segments[:max(len(segments)-1, 0)] {
+		if segment == "day" || segment == "night" {
+			name, _, _ := strings.Cut(segments[i+1], ",")
+			return name
+		}
+	}
+	return ""
+}
+
 func (n *NWSProvider) periodToForecast(period *NWSForecastPeriod, lat, lon float64) (*models.Forecast, error) {
 	startTime, err := time.Parse(time.RFC3339, period.StartTime)
 	if err != nil {
@@ -341,6 +822,7 @@ func (n *NWSProvider) periodToForecast(period *NWSForecastPeriod, lat, lon float
 		SourceProvider: n.GetName(),
 		ForecastTime:   time.Now(),
 		ValidTime:      startTime,
+		WeatherCode:    nwsIconCode(period.Icon),
 		Description:    period.DetailedForecast,
 		CreatedAt:      time.Now(),
 		UpdatedAt:      time.Now(),
@@ -368,6 +850,12 @@ func (n *NWSProvider) periodToForecast(period *NWSForecastPeriod, lat, lon float
 	// Parse wind direction
 	forecast.WindDirection = n.parseWindDirection(period.WindDirection)
 
+	if period.RelativeHumidity.Value != nil {
+		forecast.Humidity = *period.RelativeHumidity.Value
+	}
+	// Replaced by the grid's apparent temperature when there is one
+	forecast.FeelsLike = units.Round(calc.FeelsLike(forecast.Temperature, forecast.Humidity, forecast.WindSpeed), 1)
+
 	return forecast, nil
 }
 
@@ -395,6 +883,9 @@ func (n *NWSProvider) nwsAlertToWeatherAlert(nwsAlert *NWSAlert) (*WeatherAlert,
 		Category:    strings.ToLower(nwsAlert.Properties.Category),
 		Areas:       []string{nwsAlert.Properties.AreaDesc},
 	}
+	if len(nwsAlert.Geometry) > 0 && string(nwsAlert.Geometry) != "null" {
+		alert.Geometry = nwsAlert.Geometry
+	}
 
 	// Parse timestamps
 	if nwsAlert.Properties.Onset != "" {
@@ -8,6 +8,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
 )
 
 func TestNWSProvider_GetName(t *testing.T) {
@@ -186,6 +188,8 @@ func TestNWSProvider_GetForecast_MockServer(t *testing.T) {
 			GridX:    31,
 			GridY:    80,
 			Forecast: server.URL + "/gridpoints/TOP/31,80/forecast",
+			// Gusts for the first period only
+			ForecastGridData: server.URL + "/gridpoints/TOP/31,80",
 		},
 	}
 
@@ -231,6 +235,12 @@ func TestNWSProvider_GetForecast_MockServer(t *testing.T) {
 			json.NewEncoder(w).Encode(pointResponse)
 		case strings.Contains(r.URL.Path, "/forecast"):
 			json.NewEncoder(w).Encode(forecastResponse)
+		case strings.HasSuffix(r.URL.Path, "/gridpoints/TOP/31,80"):
+			w.Write([]byte(`{"properties":{"windGust":{"uom":"wmoUnit:km_h-1","values":[
+				{"validTime":"2024-01-15T11:00:00+00:00/PT2H","value":18},
+				{"validTime":"2024-01-15T13:00:00+00:00/PT1H","value":36},
+				{"validTime":"2024-01-15T14:00:00+00:00/PT4H","value":25.2},
+				{"validTime":"2024-01-16T12:00:00+00:00/P1D","value":90}]}}}`))
 		default:
 			http.NotFound(w, r)
 		}
@@ -266,6 +276,9 @@ func TestNWSProvider_GetForecast_MockServer(t *testing.T) {
 	if first.WindDirection != 225.0 { // SW = 225 degrees
 		t.Errorf("expected wind direction 225.0, got %f", first.WindDirection)
 	}
+	if first.WindGust != 10 { // 90th percentile of 18, 36, 25.2 km/h
+		t.Errorf("expected wind gust 10, got %f", first.WindGust)
+	}
 
 	// Test second period (nighttime)
 	second := forecasts[1]
@@ -273,12 +286,16 @@ func TestNWSProvider_GetForecast_MockServer(t *testing.T) {
 	if abs(second.Temperature-expectedTemp2) > 0.1 {
 		t.Errorf("expected temperature ~%f, got %f", expectedTemp2, second.Temperature)
 	}
+	if second.WindGust != 0 {
+		t.Errorf("expected no wind gust, got %f", second.WindGust)
+	}
 }
 
 func TestNWSProvider_GetAlerts_MockServer(t *testing.T) {
 	alertsResponse := NWSAlertsResponse{
 		Features: []NWSAlert{
 			{
+				Geometry: json.RawMessage(`{"type":"Polygon","coordinates":[[[-77,39],[-76,39],[-76,40],[-77,40],[-77,39]]]}`),
 				Properties: NWSAlertProperties{
 					ID:          "test-alert-1",
 					Event:       "Severe Thunderstorm Warning",
@@ -331,6 +348,9 @@ func TestNWSProvider_GetAlerts_MockServer(t *testing.T) {
 	if len(alert.Areas) != 1 || alert.Areas[0] != "Test County" {
 		t.Errorf("expected areas ['Test County'], got %v", alert.Areas)
 	}
+	if model := alert.ToModel("NWS", 39.0458, -76.6413); model.Validate() != nil || len(model.Geometry) == 0 {
+		t.Errorf("expected the alert polygon carried to the model, got %s", model.Geometry)
+	}
 }
 
 func TestNWSProvider_ErrorHandling(t *testing.T) {
@@ -367,10 +387,283 @@ func TestNWSProvider_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestParseValidInterval(t *testing.T) {
+	start, end, ok := parseValidInterval("2024-01-15T12:00:00+00:00/P1DT6H30M")
+	if !ok || end.Sub(start) != 30*time.Hour+30*time.Minute {
+		t.Errorf("expected 30h30m interval, got %v (%v)", end.Sub(start), ok)
+	}
+	for _, invalid := range []string{"2024-01-15T12:00:00+00:00", "2024-01-15T12:00:00+00:00/PT", "2024-01-15T12:00:00+00:00/P3H"} {
+		if _, _, ok := parseValidInterval(invalid); ok {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestNWSProvider_observationPressure(t *testing.T) {
+	nws := NewNWSProvider()
+	temp, elevation := 15.0, 1609.0
+	station, seaLevel := 84000.0, 101700.0
+
+	// Only station pressure reported: sea-level pressure is derived from the elevation
+	forecast, err := nws.observationToForecast(&NWSObservationResponse{Properties: NWSObservationProperties{
+		Temperature:        NWSQuantitativeValue{Value: &temp},
+		Elevation:          NWSQuantitativeValue{Value: &elevation},
+		BarometricPressure: NWSQuantitativeValue{Value: &station},
+	}}, 39.74, -104.99)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if forecast.StationPressure != 840 {
+		t.Errorf("expected station pressure 840, got %f", forecast.StationPressure)
+	}
+	if abs(forecast.Pressure-1013) > 1 {
+		t.Errorf("expected sea-level pressure near 1013, got %f", forecast.Pressure)
+	}
+
+	// Both reported: each is taken as is
+	forecast, err = nws.observationToForecast(&NWSObservationResponse{Properties: NWSObservationProperties{
+		Temperature:        NWSQuantitativeValue{Value: &temp},
+		Elevation:          NWSQuantitativeValue{Value: &elevation},
+		BarometricPressure: NWSQuantitativeValue{Value: &station},
+		SeaLevelPressure:   NWSQuantitativeValue{Value: &seaLevel},
+	}}, 39.74, -104.99)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if forecast.StationPressure != 840 || forecast.Pressure != 1017 {
+		t.Errorf("expected 840/1017, got %f/%f", forecast.StationPressure, forecast.Pressure)
+	}
+}
+
 // Helper function for floating point comparison
 func abs(x float64) float64 {
 	if x < 0 {
 		return -x
 	}
 	return x
-}
\ No newline at end of file
+}
+func TestNWSProvider_HealthCheck(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy || r.URL.Path != "/" {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"status":"OK"}`))
+	}))
+	defer server.Close()
+
+	nws := NewNWSProvider()
+	nws.BaseURL = server.URL
+	if err := nws.HealthCheck(context.Background()); err != nil {
+		t.Errorf("expected healthy NWS, got %v", err)
+	}
+
+	healthy = false
+	if err := nws.HealthCheck(context.Background()); err == nil {
+		t.Error("expected error for 503 response, got nil")
+	}
+}
+
+func TestNWSProvider_GetHourlyForecast_MockServer(t *testing.T) {
+	var serverURL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "/points/"):
+			json.NewEncoder(w).Encode(NWSPointResponse{Properties: NWSPointProperties{
+				GridID:         "TOP",
+				GridX:          31,
+				GridY:          80,
+				Forecast:       serverURL + "/gridpoints/TOP/31,80/forecast",
+				ForecastHourly: serverURL + "/gridpoints/TOP/31,80/forecast/hourly",
+			}})
+		case strings.HasSuffix(r.URL.Path, "/forecast/hourly"):
+			w.Write([]byte(`{"properties":{"periods":[
+				{"number":1,"startTime":"2024-01-15T06:00:00-05:00","endTime":"2024-01-15T07:00:00-05:00",
+				 "temperature":50,"temperatureUnit":"F","windSpeed":"10 mph","windDirection":"SW",
+				 "shortForecast":"Chance Rain Showers",
+				 "probabilityOfPrecipitation":{"unitCode":"wmoUnit:percent","value":40},
+				 "dewpoint":{"unitCode":"wmoUnit:degC","value":7.2222222222},
+				 "relativeHumidity":{"unitCode":"wmoUnit:percent","value":89}},
+				{"number":2,"startTime":"2024-01-15T07:00:00-05:00","endTime":"2024-01-15T08:00:00-05:00",
+				 "temperature":52,"temperatureUnit":"F","windSpeed":"5 mph","windDirection":"W",
+				 "shortForecast":"Cloudy",
+				 "probabilityOfPrecipitation":{"unitCode":"wmoUnit:percent","value":null},
+				 "dewpoint":{"unitCode":"wmoUnit:degF","value":41}},
+				{"number":3,"startTime":"2024-01-15T08:00:00-05:00","endTime":"2024-01-15T09:00:00-05:00",
+				 "temperature":54,"temperatureUnit":"F","windSpeed":"5 mph","windDirection":"W"}
+			]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	serverURL = server.URL
+
+	nws := NewNWSProvider()
+	nws.BaseURL = server.URL
+
+	var _ HourlyForecaster = nws
+	hours, err := nws.GetHourlyForecast(context.Background(), 39.0458, -76.6413, 2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hours) != 2 {
+		t.Fatalf("expected 2 hourly periods, got %d", len(hours))
+	}
+
+	first := hours[0]
+	if first.PrecipitationProbability == nil || *first.PrecipitationProbability != 40 {
+		t.Errorf("expected a 40%% probability of precipitation, got %v", first.PrecipitationProbability)
+	}
+	if first.Dewpoint == nil || *first.Dewpoint != 7.2 {
+		t.Errorf("expected dewpoint 7.2, got %v", first.Dewpoint)
+	}
+	if first.Humidity != 89 {
+		t.Errorf("expected humidity 89, got %f", first.Humidity)
+	}
+	if first.Description != "Chance Rain Showers" {
+		t.Errorf("expected the short forecast as description, got %q", first.Description)
+	}
+	if !first.ValidTime.Equal(time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected valid time 11:00 UTC, got %v", first.ValidTime)
+	}
+
+	second := hours[1]
+	if second.PrecipitationProbability != nil {
+		t.Errorf("expected no probability of precipitation, got %v", *second.PrecipitationProbability)
+	}
+	if second.Dewpoint == nil || *second.Dewpoint != 5 {
+		t.Errorf("expected dewpoint converted to 5°C, got %v", second.Dewpoint)
+	}
+}
+
+func TestNWSProvider_applyGridData(t *testing.T) {
+	var grid NWSGridpointResponse
+	err := json.Unmarshal([]byte(`{"properties":{
+		"apparentTemperature":{"uom":"wmoUnit:degC","values":[
+			{"validTime":"2024-01-15T11:00:00+00:00/PT3H","value":20},
+			{"validTime":"2024-01-15T14:00:00+00:00/PT3H","value":24.25},
+			{"validTime":"2024-01-15T17:00:00+00:00/PT6H","value":15}]},
+		"skyCover":{"uom":"wmoUnit:percent","values":[
+			{"validTime":"2024-01-15T11:00:00+00:00/PT4H","value":100},
+			{"validTime":"2024-01-15T15:00:00+00:00/PT8H","value":40}]},
+		"quantitativePrecipitation":{"uom":"wmoUnit:mm","values":[
+			{"validTime":"2024-01-15T06:00:00+00:00/PT6H","value":6},
+			{"validTime":"2024-01-15T12:00:00+00:00/PT6H","value":2.5},
+			{"validTime":"2024-01-15T18:00:00+00:00/PT6H","value":10}]}
+	}}`), &grid)
+	if err != nil {
+		t.Fatalf("failed to parse grid: %v", err)
+	}
+
+	// 11:00 to 23:00 UTC
+	day := &NWSForecastPeriod{StartTime: "2024-01-15T06:00:00-05:00", EndTime: "2024-01-15T18:00:00-05:00", IsDaytime: true}
+	forecast := &models.Forecast{}
+	applyGridData(forecast, &grid, day)
+
+	if forecast.FeelsLike != 24.3 {
+		t.Errorf("expected the daytime high apparent temperature 24.3, got %f", forecast.FeelsLike)
+	}
+	if forecast.CloudCover != 60 { // 4h at 100% and 8h at 40%
+		t.Errorf("expected cloud cover 60, got %f", forecast.CloudCover)
+	}
+	if forecast.Precipitation != 11.8 { // 1h of 6mm/6h, all of 2.5mm and 5h of 10mm/6h
+		t.Errorf("expected precipitation 11.8, got %f", forecast.Precipitation)
+	}
+	if forecast.UVIndex != 0 {
+		t.Errorf("expected no UV index from NWS, got %f", forecast.UVIndex)
+	}
+
+	night := &NWSForecastPeriod{StartTime: "2024-01-15T06:00:00-05:00", EndTime: "2024-01-15T18:00:00-05:00"}
+	forecast = &models.Forecast{}
+	applyGridData(forecast, &grid, night)
+	if forecast.FeelsLike != 15 {
+		t.Errorf("expected the nighttime low apparent temperature 15, got %f", forecast.FeelsLike)
+	}
+
+	later := &NWSForecastPeriod{StartTime: "2024-01-20T06:00:00-05:00", EndTime: "2024-01-20T18:00:00-05:00", IsDaytime: true}
+	forecast = &models.Forecast{FeelsLike: 0}
+	applyGridData(forecast, &grid, later)
+	if forecast.FeelsLike != 0 || forecast.CloudCover != 0 || forecast.Precipitation != 0 {
+		t.Errorf("expected no values outside the grid, got %+v", forecast)
+	}
+}
+
+func TestNWSProvider_StationObservations_MockServer(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/stations/KNYC":
+			w.Write([]byte(`{"geometry":{"type":"Point","coordinates":[-73.96667,40.78333]},
+				"properties":{"stationIdentifier":"KNYC","name":"New York City, Central Park",
+				 "timeZone":"America/New_York","elevation":{"unitCode":"wmoUnit:m","value":47.85}}}`))
+		case "/stations/KNYC/observations/latest":
+			w.Write([]byte(`{"properties":{"timestamp":"2024-01-15T11:51:00+00:00","textDescription":"Cloudy",
+				"temperature":{"unitCode":"wmoUnit:degC","value":3.9,"qualityControl":"V"},
+				"dewpoint":{"unitCode":"wmoUnit:degC","value":-1.1,"qualityControl":"qc:V"},
+				"windSpeed":{"unitCode":"wmoUnit:km_h-1","value":18,"qualityControl":"V"},
+				"windGust":{"unitCode":"wmoUnit:km_h-1","value":null,"qualityControl":"Z"},
+				"seaLevelPressure":{"unitCode":"wmoUnit:Pa","value":101320,"qualityControl":"V"},
+				"visibility":{"unitCode":"wmoUnit:m","value":16090,"qualityControl":"C"}}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	nws := NewNWSProvider()
+	nws.BaseURL = server.URL
+	var _ StationObserver = nws
+
+	station, err := nws.GetStation(context.Background(), "KNYC")
+	if err != nil {
+		t.Fatalf("GetStation failed: %v", err)
+	}
+	if station.Latitude != 40.78333 || station.Longitude != -73.96667 || station.Elevation == nil || station.TimeZone != "America/New_York" {
+		t.Errorf("Unexpected station %+v", station)
+	}
+
+	obs, err := nws.GetLatestObservation(context.Background(), "KNYC")
+	if err != nil {
+		t.Fatalf("GetLatestObservation failed: %v", err)
+	}
+	if err := obs.Validate(); err != nil {
+		t.Errorf("Expected a valid observation, got %v", err)
+	}
+	if obs.Temperature == nil || *obs.Temperature != 3.9 || obs.Dewpoint == nil || *obs.Dewpoint != -1.1 {
+		t.Errorf("Expected temperature 3.9 and dewpoint -1.1, got %v and %v", obs.Temperature, obs.Dewpoint)
+	}
+	if obs.WindSpeed == nil || *obs.WindSpeed != 5 {
+		t.Errorf("Expected 18 km/h converted to 5 m/s, got %v", obs.WindSpeed)
+	}
+	if obs.WindGust != nil || obs.StationPressure != nil || obs.Humidity != nil {
+		t.Error("Expected unreported measurements to stay nil")
+	}
+	if obs.Pressure == nil || *obs.Pressure != 1013.2 || obs.Visibility == nil || *obs.Visibility != 16.09 {
+		t.Errorf("Expected 1013.2 hPa and 16.09 km, got %v and %v", obs.Pressure, obs.Visibility)
+	}
+	if obs.QualityFlags["dewpoint"] != "V" || obs.QualityFlags["wind_gust"] != "Z" || obs.QualityFlags["visibility"] != "C" {
+		t.Errorf("Unexpected QC flags %v", obs.QualityFlags)
+	}
+
+	if _, err := nws.GetStation(context.Background(), "XXXX"); err == nil {
+		t.Error("Expected an error for an unknown station")
+	}
+}
+
+func TestNWSIconCode(t *testing.T) {
+	tests := map[string]string{
+		"https://api.weather.gov/icons/land/day/tsra_hi,40?size=medium":       "tsra_hi",
+		"https://api.weather.gov/icons/land/night/rain_showers,30/tsra_hi,30": "rain_showers",
+		"https://api.weather.gov/icons/land/day/skc?size=small":               "skc",
+		"":                                   "",
+		"https://api.weather.gov/icons/land": "",
+	}
+	for icon, want := range tests {
+		if got := nwsIconCode(icon); got != want {
+			t.Errorf("nwsIconCode(%q) = %q, want %q", icon, got, want)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package providers
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"time"
 
 	"stormlightlabs.org/weather_api/internal/models"
@@ -25,6 +27,49 @@ type WeatherProvider interface {
 	SupportedRegions() []string
 }
 
+// HourlyForecaster is implemented by weather providers that publish hour-by-hour
+// forecasts
+type HourlyForecaster interface {
+	// GetHourlyForecast retrieves up to hours hourly forecasts for a location,
+	// starting with the current hour
+	GetHourlyForecast(ctx context.Context, lat, lon float64, hours int) ([]*HourlyForecast, error)
+}
+
+// HourlyForecast is one hour of forecast. The probability of precipitation and the
+// dewpoint are not part of stored forecasts, so they are carried alongside and are nil
+// when the provider does not report them.
+type HourlyForecast struct {
+	models.Forecast
+	PrecipitationProbability *float64 `json:"precipitation_probability"` // percent
+	Dewpoint                 *float64 `json:"dewpoint"`                  // Celsius
+}
+
+// Nowcaster is implemented by weather providers that publish minute-by-minute
+// precipitation nowcasts
+type Nowcaster interface {
+	// GetNowcast retrieves up to minutes one-minute precipitation nowcasts for a
+	// location, starting with the current minute
+	GetNowcast(ctx context.Context, lat, lon float64, minutes int) ([]*NowcastMinute, error)
+}
+
+// NowcastMinute is one minute of precipitation nowcast
+type NowcastMinute struct {
+	Time                     time.Time `json:"time"`
+	PrecipitationIntensity   float64   `json:"precipitation_intensity"`   // mm/h
+	PrecipitationProbability *float64  `json:"precipitation_probability"` // percent, nil when not reported
+	PrecipitationType        string    `json:"precipitation_type"`        // rain, snow, sleet or freezing_rain; empty when dry
+}
+
+// StationObserver is implemented by weather providers that publish the observations
+// of individual surface stations
+type StationObserver interface {
+	// GetStation retrieves a station's metadata by its network code
+	GetStation(ctx context.Context, stationID string) (*models.Station, error)
+
+	// GetLatestObservation retrieves the most recent observation of a station
+	GetLatestObservation(ctx context.Context, stationID string) (*models.Observation, error)
+}
+
 // GeocodeProvider defines the interface for geocoding providers
 type GeocodeProvider interface {
 	// GetName returns the provider name (e.g., "Census", "Nominatim")
@@ -40,6 +85,13 @@ type GeocodeProvider interface {
 	SupportedRegions() []string
 }
 
+// placeBoundingBox formats models.Place.BoundingBox in the south, north, west, east order
+// Nominatim uses
+func placeBoundingBox(south, north, west, east float64) string {
+	data, _ := json.Marshal([]float64{south, north, west, east})
+	return string(data)
+}
+
 // WeatherAlert represents a weather alert/warning
 type WeatherAlert struct {
 	ID          string    `json:"id"`
@@ -51,6 +103,28 @@ type WeatherAlert struct {
 	StartTime   time.Time `json:"start_time"`
 	EndTime     time.Time `json:"end_time"`
 	Areas       []string  `json:"areas"` // Affected geographic areas
+
+	Geometry json.RawMessage `json:"geometry,omitempty"` // GeoJSON Polygon or MultiPolygon of the area, when the provider sends one
+}
+
+// ToModel converts the alert into a persistable models.Alert, tagging it with the
+// issuing provider and the coordinates it was requested for
+func (a *WeatherAlert) ToModel(provider string, lat, lon float64) *models.Alert {
+	return &models.Alert{
+		SourceProvider:  provider,
+		ProviderAlertID: a.ID,
+		Latitude:        lat,
+		Longitude:       lon,
+		Title:           a.Title,
+		Description:     a.Description,
+		Severity:        a.Severity,
+		Urgency:         a.Urgency,
+		Category:        a.Category,
+		AreaDesc:        strings.Join(a.Areas, "; "),
+		Geometry:        a.Geometry,
+		StartTime:       a.StartTime,
+		EndTime:         a.EndTime,
+	}
 }
 
 // ProviderResponse wraps provider responses with metadata
@@ -65,15 +139,17 @@ type ProviderResponse struct {
 
 // ProviderManager manages multiple providers
 type ProviderManager struct {
-	weatherProviders []WeatherProvider
-	geocodeProviders []GeocodeProvider
+	weatherProviders    []WeatherProvider
+	geocodeProviders    []GeocodeProvider
+	airQualityProviders []AirQualityProvider
 }
 
 // NewProviderManager creates a new provider manager
 func NewProviderManager() *ProviderManager {
 	return &ProviderManager{
-		weatherProviders: make([]WeatherProvider, 0),
-		geocodeProviders: make([]GeocodeProvider, 0),
+		weatherProviders:    make([]WeatherProvider, 0),
+		geocodeProviders:    make([]GeocodeProvider, 0),
+		airQualityProviders: make([]AirQualityProvider, 0),
 	}
 }
 
@@ -87,6 +163,11 @@ func (pm *ProviderManager) RegisterGeocodeProvider(provider GeocodeProvider) {
 	pm.geocodeProviders = append(pm.geocodeProviders, provider)
 }
 
+// RegisterAirQualityProvider adds an air quality provider
+func (pm *ProviderManager) RegisterAirQualityProvider(provider AirQualityProvider) {
+	pm.airQualityProviders = append(pm.airQualityProviders, provider)
+}
+
 // GetWeatherProviders returns all registered weather providers
 func (pm *ProviderManager) GetWeatherProviders() []WeatherProvider {
 	return pm.weatherProviders
@@ -97,6 +178,11 @@ func (pm *ProviderManager) GetGeocodeProviders() []GeocodeProvider {
 	return pm.geocodeProviders
 }
 
+// GetAirQualityProviders returns all registered air quality providers
+func (pm *ProviderManager) GetAirQualityProviders() []AirQualityProvider {
+	return pm.airQualityProviders
+}
+
 // GetWeatherProviderByName returns a weather provider by name
 func (pm *ProviderManager) GetWeatherProviderByName(name string) WeatherProvider {
 	for _, provider := range pm.weatherProviders {
@@ -107,12 +193,33 @@ func (pm *ProviderManager) GetWeatherProviderByName(name string) WeatherProvider
 	return nil
 }
 
-// GetGeocodeProviderByName returns a geocode provider by name
+// GetGeocodeProviderByName returns a geocode provider by name, ignoring case, or nil
 func (pm *ProviderManager) GetGeocodeProviderByName(name string) GeocodeProvider {
 	for _, provider := range pm.geocodeProviders {
-		if provider.GetName() == name {
+		if strings.EqualFold(provider.GetName(), name) {
 			return provider
 		}
 	}
 	return nil
 }
+
+// GlobalRegion is the SupportedRegions entry for providers with worldwide coverage
+const GlobalRegion = "GLOBAL"
+
+// GetWeatherProviderForRegion returns the first registered weather provider that covers
+// the region (an ISO 3166-1 alpha-2 country code). Region-specific providers are preferred
+// over global ones so official national services win where they exist.
+func (pm *ProviderManager) GetWeatherProviderForRegion(region string) WeatherProvider {
+	var global WeatherProvider
+	for _, provider := range pm.weatherProviders {
+		for _, supported := range provider.SupportedRegions() {
+			if strings.EqualFold(supported, region) {
+				return provider
+			}
+			if supported == GlobalRegion && global == nil {
+				global = provider
+			}
+		}
+	}
+	return global
+}
@@ -2,12 +2,25 @@ package providers
 
 import (
 	"context"
+	"os"
 	"testing"
 	"time"
 
 	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/retry"
 )
 
+// TestMain turns retries off, so test servers see exactly the requests a provider makes
+// and failure responses are returned without backoff
+func TestMain(m *testing.M) {
+	policy := retry.DefaultPolicy()
+	policy.Attempts = 1
+	if err := retry.Configure(policy, nil); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
 func TestProviderManager(t *testing.T) {
 	pm := NewProviderManager()
 
@@ -18,6 +31,9 @@ func TestProviderManager(t *testing.T) {
 	if len(pm.GetGeocodeProviders()) != 0 {
 		t.Errorf("expected 0 geocode providers, got %d", len(pm.GetGeocodeProviders()))
 	}
+	if len(pm.GetAirQualityProviders()) != 0 {
+		t.Errorf("expected 0 air quality providers, got %d", len(pm.GetAirQualityProviders()))
+	}
 
 	// Create test providers
 	nws := NewNWSProvider()
@@ -26,6 +42,7 @@ func TestProviderManager(t *testing.T) {
 	// Register providers
 	pm.RegisterWeatherProvider(nws)
 	pm.RegisterGeocodeProvider(census)
+	pm.RegisterAirQualityProvider(NewOpenMeteoAirQualityProvider())
 
 	// Test registered providers
 	if len(pm.GetWeatherProviders()) != 1 {
@@ -34,6 +51,9 @@ func TestProviderManager(t *testing.T) {
 	if len(pm.GetGeocodeProviders()) != 1 {
 		t.Errorf("expected 1 geocode provider, got %d", len(pm.GetGeocodeProviders()))
 	}
+	if len(pm.GetAirQualityProviders()) != 1 {
+		t.Errorf("expected 1 air quality provider, got %d", len(pm.GetAirQualityProviders()))
+	}
 
 	// Test get by name
 	weatherProvider := pm.GetWeatherProviderByName("NWS")
@@ -51,6 +71,9 @@ func TestProviderManager(t *testing.T) {
 	if geocodeProvider.GetName() != "Census" {
 		t.Errorf("expected provider name 'Census', got '%s'", geocodeProvider.GetName())
 	}
+	if pm.GetGeocodeProviderByName("census") != geocodeProvider {
+		t.Error("expected geocode provider names to match regardless of case")
+	}
 
 	// Test non-existent provider
 	if pm.GetWeatherProviderByName("NonExistent") != nil {
@@ -85,6 +108,26 @@ func TestWeatherAlert(t *testing.T) {
 	}
 }
 
+func TestWeatherAlertToModel(t *testing.T) {
+	alert := WeatherAlert{
+		ID:       "test-alert-1",
+		Title:    "Flood Warning",
+		Severity: "moderate",
+		Areas:    []string{"Test County", "Another County"},
+	}
+
+	model := alert.ToModel("NWS", 39.0, -95.0)
+	if model.SourceProvider != "NWS" || model.ProviderAlertID != "test-alert-1" {
+		t.Errorf("unexpected identity fields: %s/%s", model.SourceProvider, model.ProviderAlertID)
+	}
+	if model.AreaDesc != "Test County; Another County" {
+		t.Errorf("expected joined areas, got '%s'", model.AreaDesc)
+	}
+	if err := model.Validate(); err != nil {
+		t.Errorf("expected converted alert to be valid, got: %v", err)
+	}
+}
+
 func TestProviderResponse(t *testing.T) {
 	now := time.Now()
 	response := ProviderResponse{
@@ -108,7 +151,8 @@ func TestProviderResponse(t *testing.T) {
 
 // Mock providers for testing interface compliance
 type MockWeatherProvider struct {
-	name string
+	name    string
+	regions []string
 }
 
 func (m *MockWeatherProvider) GetName() string {
@@ -146,6 +190,9 @@ func (m *MockWeatherProvider) GetAlerts(ctx context.Context, lat, lon float64) (
 }
 
 func (m *MockWeatherProvider) SupportedRegions() []string {
+	if m.regions != nil {
+		return m.regions
+	}
 	return []string{"TEST"}
 }
 
@@ -325,3 +372,34 @@ func TestProviderManagerWithMocks(t *testing.T) {
 		t.Errorf("expected 1 place, got %d", len(places))
 	}
 }
+
+func TestGetWeatherProviderForRegion(t *testing.T) {
+	pm := NewProviderManager()
+	if pm.GetWeatherProviderForRegion("US") != nil {
+		t.Error("expected no provider from an empty manager")
+	}
+
+	pm.RegisterWeatherProvider(&MockWeatherProvider{name: "Global", regions: []string{GlobalRegion}})
+	pm.RegisterWeatherProvider(&MockWeatherProvider{name: "National", regions: []string{"US"}})
+	pm.RegisterWeatherProvider(NewECWeatherProvider())
+
+	tests := []struct {
+		region   string
+		expected string
+	}{
+		{"US", "National"},
+		{"us", "National"},
+		{"CA", "EnvironmentCanada"},
+		{"NO", "Global"},
+	}
+
+	for _, test := range tests {
+		provider := pm.GetWeatherProviderForRegion(test.region)
+		if provider == nil {
+			t.Fatalf("expected a provider for region %s", test.region)
+		}
+		if provider.GetName() != test.expected {
+			t.Errorf("region %s: expected provider %s, got %s", test.region, test.expected, provider.GetName())
+		}
+	}
+}
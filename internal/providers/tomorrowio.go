@@ -0,0 +1,306 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/freshness"
+	"stormlightlabs.org/weather_api/internal/geo"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/ratelimit"
+	"stormlightlabs.org/weather_api/internal/requestlog"
+	"stormlightlabs.org/weather_api/internal/retry"
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// maxNowcastMinutes is the length of the Tomorrow.io minutely timeline
+const maxNowcastMinutes = 60
+
+// TomorrowIOProvider implements WeatherProvider and Nowcaster for the Tomorrow.io
+// Weather API, whose minutely timeline gives precipitation an hour ahead anywhere.
+// Every request counts against the API key's daily quota, so it has no health check
+// for the background prober to spend calls on.
+type TomorrowIOProvider struct {
+	BaseURL    string
+	APIKey     string
+	UserAgent  string
+	HTTPClient *http.Client
+}
+
+// NewTomorrowIOProvider creates a new Tomorrow.io weather provider using apiKey
+func NewTomorrowIOProvider(apiKey string) *TomorrowIOProvider {
+	return &TomorrowIOProvider{
+		BaseURL:   "https://api.tomorrow.io",
+		APIKey:    apiKey,
+		UserAgent: "weather-api/1.0 (contact@example.com)",
+		HTTPClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: timing.NewTransport(timing.Provider, freshness.NewTransport(tracing.NewTransport("Tomorrow.io", requestlog.NewTransport("Tomorrow.io", retry.NewTransport("Tomorrow.io", ratelimit.NewTransport("Tomorrow.io", breaker.NewTransport("Tomorrow.io", nil))))))),
+		},
+	}
+}
+
+func (t *TomorrowIOProvider) GetName() string {
+	return "Tomorrow.io"
+}
+
+func (t *TomorrowIOProvider) SupportedRegions() []string {
+	return []string{GlobalRegion}
+}
+
+// Tomorrow.io API response structures, requested in metric units: °C, %, hPa, m/s,
+// km and mm (mm/h for intensities). Values the model has no data for are left out.
+type TomorrowIORealtimeResponse struct {
+	Data TomorrowIOInterval `json:"data"`
+}
+
+type TomorrowIOForecastResponse struct {
+	Timelines struct {
+		Minutely []TomorrowIOInterval `json:"minutely"`
+		Daily    []TomorrowIOInterval `json:"daily"`
+	} `json:"timelines"`
+}
+
+type TomorrowIOInterval struct {
+	Time   time.Time        `json:"time"`
+	Values TomorrowIOValues `json:"values"`
+}
+
+// TomorrowIOValues holds the fields of realtime and minutely values, and the daily
+// aggregates (suffixed Avg, Max and Sum) of the same fields
+type TomorrowIOValues struct {
+	Temperature              *float64 `json:"temperature"`
+	TemperatureApparent      *float64 `json:"temperatureApparent"`
+	Humidity                 *float64 `json:"humidity"`
+	PressureSeaLevel         *float64 `json:"pressureSeaLevel"`
+	PressureSurfaceLevel     *float64 `json:"pressureSurfaceLevel"`
+	WindSpeed                *float64 `json:"windSpeed"`
+	WindGust                 *float64 `json:"windGust"`
+	WindDirection            *float64 `json:"windDirection"`
+	Visibility               *float64 `json:"visibility"`
+	CloudCover               *float64 `json:"cloudCover"`
+	UVIndex                  *float64 `json:"uvIndex"`
+	WeatherCode              *int     `json:"weatherCode"`
+	PrecipitationIntensity   *float64 `json:"precipitationIntensity"`
+	PrecipitationProbability *float64 `json:"precipitationProbability"`
+	RainIntensity            *float64 `json:"rainIntensity"`
+	SnowIntensity            *float64 `json:"snowIntensity"`
+	SleetIntensity           *float64 `json:"sleetIntensity"`
+	FreezingRainIntensity    *float64 `json:"freezingRainIntensity"`
+
+	TemperatureAvg          *float64 `json:"temperatureAvg"`
+	TemperatureApparentAvg  *float64 `json:"temperatureApparentAvg"`
+	HumidityAvg             *float64 `json:"humidityAvg"`
+	PressureSeaLevelAvg     *float64 `json:"pressureSeaLevelAvg"`
+	PressureSurfaceLevelAvg *float64 `json:"pressureSurfaceLevelAvg"`
+	WindSpeedAvg            *float64 `json:"windSpeedAvg"`
+	WindGustMax             *float64 `json:"windGustMax"`
+	WindDirectionAvg        *float64 `json:"windDirectionAvg"`
+	VisibilityAvg           *float64 `json:"visibilityAvg"`
+	CloudCoverAvg           *float64 `json:"cloudCoverAvg"`
+	UVIndexMax              *float64 `json:"uvIndexMax"`
+	WeatherCodeMax          *int     `json:"weatherCodeMax"`
+	RainAccumulationSum     *float64 `json:"rainAccumulationSum"`
+}
+
+func (t *TomorrowIOProvider) GetCurrentWeather(ctx context.Context, lat, lon float64) (*models.Forecast, error) {
+	if err := validateCoordinates(t.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response TomorrowIORealtimeResponse
+	if err := t.makeRequest(ctx, "/v4/weather/realtime", lat, lon, nil, &response); err != nil {
+		return nil, fmt.Errorf("failed to get current weather: %w", err)
+	}
+
+	v := response.Data.Values
+	forecast := &models.Forecast{
+		SourceProvider:  t.GetName(),
+		ForecastTime:    response.Data.Time,
+		ValidTime:       response.Data.Time,
+		Temperature:     tomorrowIOValue(v.Temperature),
+		Humidity:        tomorrowIOValue(v.Humidity),
+		Pressure:        tomorrowIOValue(v.PressureSeaLevel),
+		StationPressure: tomorrowIOValue(v.PressureSurfaceLevel),
+		WindSpeed:       tomorrowIOValue(v.WindSpeed),
+		WindDirection:   tomorrowIOValue(v.WindDirection),
+		WindGust:        tomorrowIOValue(v.WindGust),
+		Visibility:      tomorrowIOValue(v.Visibility),
+		CloudCover:      tomorrowIOValue(v.CloudCover),
+		UVIndex:         tomorrowIOValue(v.UVIndex),
+		WeatherCode:     tomorrowIOCode(v.WeatherCode),
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	forecast.FeelsLike = tomorrowIOFeelsLike(forecast, v.TemperatureApparent)
+	return forecast, nil
+}
+
+// GetForecast returns one forecast a day from the daily timeline (up to 5 days
+// including today), with daily means of the hourly values
+func (t *TomorrowIOProvider) GetForecast(ctx context.Context, lat, lon float64, days int) ([]*models.Forecast, error) {
+	if err := validateCoordinates(t.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response TomorrowIOForecastResponse
+	if err := t.makeRequest(ctx, "/v4/weather/forecast", lat, lon, url.Values{"timesteps": {"1d"}}, &response); err != nil {
+		return nil, fmt.Errorf("failed to get forecast: %w", err)
+	}
+
+	issued := time.Now()
+	var forecasts []*models.Forecast
+	for _, day := range response.Timelines.Daily {
+		if len(forecasts) == days {
+			break
+		}
+		if day.Values.TemperatureAvg == nil {
+			continue
+		}
+		v := day.Values
+		forecast := &models.Forecast{
+			SourceProvider:  t.GetName(),
+			ForecastTime:    issued,
+			ValidTime:       day.Time,
+			Temperature:     *v.TemperatureAvg,
+			Humidity:        tomorrowIOValue(v.HumidityAvg),
+			Pressure:        tomorrowIOValue(v.PressureSeaLevelAvg),
+			StationPressure: tomorrowIOValue(v.PressureSurfaceLevelAvg),
+			WindSpeed:       tomorrowIOValue(v.WindSpeedAvg),
+			WindDirection:   tomorrowIOValue(v.WindDirectionAvg),
+			WindGust:        tomorrowIOValue(v.WindGustMax),
+			Visibility:      tomorrowIOValue(v.VisibilityAvg),
+			CloudCover:      tomorrowIOValue(v.CloudCoverAvg),
+			Precipitation:   tomorrowIOValue(v.RainAccumulationSum),
+			UVIndex:         tomorrowIOValue(v.UVIndexMax),
+			WeatherCode:     tomorrowIOCode(v.WeatherCodeMax),
+			CreatedAt:       issued,
+			UpdatedAt:       issued,
+		}
+		forecast.FeelsLike = tomorrowIOFeelsLike(forecast, v.TemperatureApparentAvg)
+		forecasts = append(forecasts, forecast)
+	}
+	return forecasts, nil
+}
+
+// GetAlerts reports no alerts: Tomorrow.io serves them only to accounts with event
+// monitoring set up
+func (t *TomorrowIOProvider) GetAlerts(ctx context.Context, lat, lon float64) ([]WeatherAlert, error) {
+	return nil, validateCoordinates(t.GetName(), lat, lon)
+}
+
+// GetNowcast returns the minutely timeline, capped at the 60 minutes it covers
+func (t *TomorrowIOProvider) GetNowcast(ctx context.Context, lat, lon float64, minutes int) ([]*NowcastMinute, error) {
+	if err := validateCoordinates(t.GetName(), lat, lon); err != nil {
+		return nil, err
+	}
+
+	var response TomorrowIOForecastResponse
+	if err := t.makeRequest(ctx, "/v4/weather/forecast", lat, lon, url.Values{"timesteps": {"1m"}}, &response); err != nil {
+		return nil, fmt.Errorf("failed to get nowcast: %w", err)
+	}
+
+	minutes = min(minutes, maxNowcastMinutes)
+	nowcast := make([]*NowcastMinute, 0, minutes)
+	for _, interval := range response.Timelines.Minutely {
+		if len(nowcast) == minutes {
+			break
+		}
+		v := interval.Values
+		minute := &NowcastMinute{
+			Time:                     interval.Time,
+			PrecipitationIntensity:   tomorrowIOValue(v.PrecipitationIntensity),
+			PrecipitationProbability: v.PrecipitationProbability,
+		}
+		// The type is the one falling hardest, when any is
+		strongest := 0.0
+		for _, kind := range []struct {
+			name      string
+			intensity *float64
+		}{
+			{"rain", v.RainIntensity},
+			{"snow", v.SnowIntensity},
+			{"sleet", v.SleetIntensity},
+			{"freezing_rain", v.FreezingRainIntensity},
+		} {
+			if intensity := tomorrowIOValue(kind.intensity); intensity > strongest {
+				minute.PrecipitationType, strongest = kind.name, intensity
+			}
+		}
+		nowcast = append(nowcast, minute)
+	}
+	return nowcast, nil
+}
+
+func (t *TomorrowIOProvider) makeRequest(ctx context.Context, path string, lat, lon float64, query url.Values, result any) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("location", geo.FormatPoint(lat, lon, 4))
+	query.Set("units", "metric")
+	query.Set("apikey", t.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", t.BaseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("User-Agent", t.UserAgent)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := t.HTTPClient.Do(req)
+	if err != nil {
+		return requestError(t.GetName(), err)
+	}
+	defer resp.Body.Close()
+
+	// Tomorrow.io answers 400 for locations it cannot resolve
+	if resp.StatusCode == http.StatusBadRequest {
+		providerErr := statusError(t.GetName(), resp)
+		providerErr.Kind = ErrBadCoordinates
+		return providerErr
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(t.GetName(), resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// tomorrowIOValue returns a reported value, 0 when it is missing
+func tomorrowIOValue(value *float64) float64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// tomorrowIOCode returns a weather code as stored, empty when it is missing or unknown
+// (0)
+func tomorrowIOCode(code *int) string {
+	if code == nil || *code == 0 {
+		return ""
+	}
+	return strconv.Itoa(*code)
+}
+
+// tomorrowIOFeelsLike prefers the reported apparent temperature to the derived one
+func tomorrowIOFeelsLike(forecast *models.Forecast, apparent *float64) float64 {
+	if apparent != nil {
+		return *apparent
+	}
+	return units.Round(calc.FeelsLike(forecast.Temperature, forecast.Humidity, forecast.WindSpeed), 1)
+}
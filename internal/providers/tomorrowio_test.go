@@ -0,0 +1,136 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const tomorrowIORealtimeJSON = `{"data": {"time": "2025-07-14T18:00:00Z", "values": {
+	"temperature": 24.1, "temperatureApparent": 25.3, "humidity": 64, "pressureSeaLevel": 1012.5,
+	"pressureSurfaceLevel": 1002.1, "windSpeed": 4.2, "windGust": 7.9, "windDirection": 215,
+	"visibility": 16, "cloudCover": 40, "uvIndex": 6, "weatherCode": 1101}}}`
+
+const tomorrowIOMinutelyJSON = `{"timelines": {"minutely": [
+	{"time": "2025-07-14T18:00:00Z", "values": {"precipitationIntensity": 0, "precipitationProbability": 10, "rainIntensity": 0, "snowIntensity": 0}},
+	{"time": "2025-07-14T18:01:00Z", "values": {"precipitationIntensity": 1.8, "precipitationProbability": 75, "rainIntensity": 1.5, "sleetIntensity": 0.3, "snowIntensity": 0}},
+	{"time": "2025-07-14T18:02:00Z", "values": {"precipitationIntensity": 0.6, "snowIntensity": 0.6}}
+]}}`
+
+const tomorrowIODailyJSON = `{"timelines": {"daily": [
+	{"time": "2025-07-14T10:00:00Z", "values": {"temperatureAvg": 22.4, "humidityAvg": 70, "pressureSeaLevelAvg": 1011,
+		"windSpeedAvg": 3.1, "windGustMax": 9.4, "windDirectionAvg": 200, "rainAccumulationSum": 4.2, "uvIndexMax": 7, "weatherCodeMax": 4001}},
+	{"time": "2025-07-15T10:00:00Z", "values": {"temperatureAvg": 20.1, "temperatureApparentAvg": 19.5, "weatherCodeMax": 1000}},
+	{"time": "2025-07-16T10:00:00Z", "values": {}}
+]}}`
+
+func newTomorrowIOTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("apikey") != "test-key" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if query.Get("units") != "metric" {
+			t.Errorf("Expected metric units, got %q", query.Get("units"))
+		}
+		if query.Get("location") == "0,0" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case r.URL.Path == "/v4/weather/realtime":
+			w.Write([]byte(tomorrowIORealtimeJSON))
+		case r.URL.Path == "/v4/weather/forecast" && query.Get("timesteps") == "1m":
+			w.Write([]byte(tomorrowIOMinutelyJSON))
+		case r.URL.Path == "/v4/weather/forecast" && query.Get("timesteps") == "1d":
+			w.Write([]byte(tomorrowIODailyJSON))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestTomorrowIOProvider_MockServer(t *testing.T) {
+	provider := NewTomorrowIOProvider("test-key")
+	provider.Configure(Endpoint{BaseURL: newTomorrowIOTestServer(t).URL})
+	ctx := context.Background()
+
+	current, err := provider.GetCurrentWeather(ctx, 40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetCurrentWeather failed: %v", err)
+	}
+	if !current.ValidTime.Equal(time.Date(2025, 7, 14, 18, 0, 0, 0, time.UTC)) || current.Temperature != 24.1 ||
+		current.FeelsLike != 25.3 || current.Pressure != 1012.5 || current.StationPressure != 1002.1 ||
+		current.WindGust != 7.9 || current.WeatherCode != "1101" {
+		t.Errorf("Unexpected current conditions %+v", current)
+	}
+
+	forecasts, err := provider.GetForecast(ctx, 40.71, -74.01, 7)
+	if err != nil {
+		t.Fatalf("GetForecast failed: %v", err)
+	}
+	if len(forecasts) != 2 {
+		t.Fatalf("Expected the 2 days with values, got %d", len(forecasts))
+	}
+	if today := forecasts[0]; today.Temperature != 22.4 || today.Precipitation != 4.2 || today.WeatherCode != "4001" ||
+		today.UVIndex != 7 || today.FeelsLike == 0 {
+		t.Errorf("Unexpected first day %+v", today)
+	}
+	if forecasts[1].FeelsLike != 19.5 {
+		t.Errorf("Expected the reported apparent temperature, got %v", forecasts[1].FeelsLike)
+	}
+	for _, forecast := range forecasts {
+		forecast.CityID = 1
+		if err := forecast.Validate(); err != nil {
+			t.Errorf("Expected a valid forecast at %v, got %v", forecast.ValidTime, err)
+		}
+	}
+	if short, _ := provider.GetForecast(ctx, 40.71, -74.01, 1); len(short) != 1 {
+		t.Errorf("Expected 1 day, got %d", len(short))
+	}
+
+	var nowcaster Nowcaster = provider
+	nowcast, err := nowcaster.GetNowcast(ctx, 40.71, -74.01, 60)
+	if err != nil {
+		t.Fatalf("GetNowcast failed: %v", err)
+	}
+	if len(nowcast) != 3 {
+		t.Fatalf("Expected 3 minutes, got %d", len(nowcast))
+	}
+	if dry := nowcast[0]; dry.PrecipitationIntensity != 0 || dry.PrecipitationType != "" || *dry.PrecipitationProbability != 10 {
+		t.Errorf("Unexpected dry minute %+v", dry)
+	}
+	if wet := nowcast[1]; wet.PrecipitationIntensity != 1.8 || wet.PrecipitationType != "rain" || *wet.PrecipitationProbability != 75 {
+		t.Errorf("Expected rain, the heaviest type, got %+v", wet)
+	}
+	if snow := nowcast[2]; snow.PrecipitationType != "snow" || snow.PrecipitationProbability != nil {
+		t.Errorf("Expected snow without a probability, got %+v", snow)
+	}
+	if short, _ := provider.GetNowcast(ctx, 40.71, -74.01, 2); len(short) != 2 {
+		t.Errorf("Expected 2 minutes, got %d", len(short))
+	}
+
+	if alerts, err := provider.GetAlerts(ctx, 40.71, -74.01); err != nil || alerts != nil {
+		t.Errorf("Expected no alerts, got %v (%v)", alerts, err)
+	}
+
+	if _, err := provider.GetNowcast(ctx, 0, 0, 60); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates for a rejected location, got %v", err)
+	}
+	if _, err := provider.GetNowcast(ctx, 91, 0, 60); !errors.Is(err, ErrBadCoordinates) {
+		t.Errorf("Expected ErrBadCoordinates for out-of-range coordinates, got %v", err)
+	}
+
+	provider.APIKey = "wrong"
+	var providerErr *ProviderError
+	if _, err := provider.GetCurrentWeather(ctx, 40.71, -74.01); !errors.As(err, &providerErr) || providerErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected a 401 provider error for a bad key, got %v", err)
+	}
+}
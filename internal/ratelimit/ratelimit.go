@@ -0,0 +1,169 @@
+// Package ratelimit paces outbound requests so providers' usage policies are respected.
+//
+// Every provider's HTTP client passes its requests through a Transport (see
+// NewTransport) that waits for the provider's token bucket before sending. The bucket is
+// shared by every client of the provider, so batch work such as geocoding a city list
+// is spread out instead of getting the service banned. Providers without a Limit are not
+// paced. A request whose deadline would pass while waiting fails at once.
+//
+// Limiter states are published as the "provider_rate_limits" expvar.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Limit is the pace of one provider's requests
+type Limit struct {
+	// Rate is the sustained number of requests per second
+	Rate float64
+
+	// Burst is how many requests may be sent at once after a quiet period
+	Burst int
+}
+
+// Validate reports limits that would never let a request through
+func (l Limit) Validate() error {
+	switch {
+	case l.Rate <= 0:
+		return fmt.Errorf("rate must be positive, got %g", l.Rate)
+	case l.Burst <= 0:
+		return fmt.Errorf("burst must be positive, got %d", l.Burst)
+	}
+	return nil
+}
+
+func (l Limit) String() string {
+	return fmt.Sprintf("%g/s burst %d", l.Rate, l.Burst)
+}
+
+// Stats is a snapshot of a limiter
+type Stats struct {
+	Provider string  `json:"provider"`
+	Rate     float64 `json:"rate"` // requests per second
+	Burst    int     `json:"burst"`
+	Requests int64   `json:"requests"`
+	Delayed  int64   `json:"delayed"` // requests that had to wait for a token
+	WaitMS   float64 `json:"wait_ms"` // total time requests waited
+	Rejected int64   `json:"rejected"`
+}
+
+// Limiter is a token bucket. Waiting requests reserve their token up front, so they are
+// let through in arrival order.
+type Limiter struct {
+	provider string
+	limit    Limit
+
+	mu     sync.Mutex
+	tokens float64 // may go negative while requests are waiting
+	last   time.Time
+	stats  Stats
+
+	now func() time.Time
+}
+
+// New creates a full limiter for the named provider
+func New(provider string, limit Limit) *Limiter {
+	return &Limiter{
+		provider: provider,
+		limit:    limit,
+		tokens:   float64(limit.Burst),
+		last:     time.Now(),
+		stats:    Stats{Provider: provider, Rate: limit.Rate, Burst: limit.Burst},
+		now:      time.Now,
+	}
+}
+
+// reserve takes a token and returns how long to wait before using it. When ctx would
+// expire first nothing is taken and ok is false.
+func (l *Limiter) reserve(ctx context.Context) (wait time.Duration, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.tokens = min(float64(l.limit.Burst), l.tokens+now.Sub(l.last).Seconds()*l.limit.Rate)
+	l.last = now
+	l.stats.Requests++
+
+	if l.tokens < 1 {
+		wait = time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+	}
+	if deadline, hasDeadline := ctx.Deadline(); hasDeadline && deadline.Sub(now) < wait {
+		l.stats.Rejected++
+		return 0, false
+	}
+	l.tokens--
+	if wait > 0 {
+		l.stats.Delayed++
+		l.stats.WaitMS += float64(wait.Microseconds()) / 1000
+	}
+	return wait, true
+}
+
+// cancel returns a reserved token that was not used
+func (l *Limiter) cancel() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tokens = min(float64(l.limit.Burst), l.tokens+1)
+}
+
+// Wait blocks until a request may be sent. It fails without waiting when ctx would
+// expire first, and gives the token back when ctx ends while waiting.
+func (l *Limiter) Wait(ctx context.Context) error {
+	wait, ok := l.reserve(ctx)
+	if !ok {
+		return fmt.Errorf("%s: rate limit of %s would delay the request past its deadline: %w", l.provider, l.limit, context.DeadlineExceeded)
+	}
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		l.cancel()
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Stats returns a snapshot of the limiter
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.stats
+}
+
+// Transport paces the requests of a provider with its shared limiter
+type Transport struct {
+	Provider string
+
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport pacing requests with the limiter of the named
+// provider, looked up per request so Configure applies to existing clients
+func NewTransport(provider string, base http.RoundTripper) *Transport {
+	return &Transport{Provider: provider, Base: base}
+}
+
+// RoundTrip waits for the provider's limiter, if it has one, and makes the request
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if limiter, ok := Lookup(t.Provider); ok {
+		if err := limiter.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	return base.RoundTrip(req)
+}
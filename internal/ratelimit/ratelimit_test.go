@@ -0,0 +1,133 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestLimiter returns a limiter with a controllable clock
+func newTestLimiter(limit Limit) (*Limiter, *time.Time) {
+	now := time.Date(2025, 8, 14, 12, 0, 0, 0, time.UTC)
+	l := New("Test", limit)
+	l.now = func() time.Time { return now }
+	l.last = now
+	return l, &now
+}
+
+func TestLimitValidate(t *testing.T) {
+	if err := (Limit{Rate: 0.5, Burst: 1}).Validate(); err != nil {
+		t.Errorf("Expected a valid limit, got %v", err)
+	}
+	for _, limit := range []Limit{{Rate: 0, Burst: 1}, {Rate: 1, Burst: 0}} {
+		if err := limit.Validate(); err == nil {
+			t.Errorf("Expected %+v to be rejected", limit)
+		}
+	}
+}
+
+func TestLimiterReserve(t *testing.T) {
+	l, now := newTestLimiter(Limit{Rate: 2, Burst: 2})
+	ctx := context.Background()
+
+	for i, want := range []time.Duration{0, 0, 500 * time.Millisecond, time.Second} {
+		if wait, ok := l.reserve(ctx); !ok || wait != want {
+			t.Fatalf("Request %d: expected to wait %s, got %s", i, want, wait)
+		}
+	}
+
+	*now = now.Add(3 * time.Second)
+	if wait, _ := l.reserve(ctx); wait != 0 {
+		t.Errorf("Expected the bucket to refill after a quiet period, got a %s wait", wait)
+	}
+
+	l.cancel()
+	if stats := l.Stats(); stats.Requests != 5 || stats.Delayed != 2 || stats.WaitMS != 1500 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestLimiterWait(t *testing.T) {
+	l := New("Test", Limit{Rate: 1, Burst: 1})
+	if err := l.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected the first request through, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 50*time.Millisecond {
+		t.Errorf("Expected an immediate failure past the deadline, got %v after %s", err, time.Since(start))
+	}
+	if stats := l.Stats(); stats.Rejected != 1 || stats.Delayed != 0 {
+		t.Errorf("Expected the rejected request not to take a token, got %+v", stats)
+	}
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancelNow()
+	}()
+	if err := l.Wait(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the wait to end with its context, got %v", err)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits([]string{"NWS=5:10", " Census = 0.5 "})
+	if err != nil {
+		t.Fatalf("ParseLimits failed: %v", err)
+	}
+	if limits["NWS"] != (Limit{Rate: 5, Burst: 10}) || limits["Census"] != (Limit{Rate: 0.5, Burst: 1}) {
+		t.Errorf("Unexpected limits %v", limits)
+	}
+	if limits["Nominatim"] != (Limit{Rate: 1, Burst: 1}) {
+		t.Errorf("Expected the Nominatim default to be kept, got %v", limits["Nominatim"])
+	}
+
+	limits, err = ParseLimits([]string{"Nominatim=0"})
+	if _, ok := limits["Nominatim"]; err != nil || ok {
+		t.Errorf("Expected a zero rate to lift the limit, got %v %v", limits, err)
+	}
+
+	for _, spec := range []string{"NWS", "=1", "NWS=fast", "NWS=-1", "NWS=1:0", "NWS=1:x"} {
+		if _, err := ParseLimits([]string{spec}); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if err := Configure(map[string]Limit{"Paced": {Rate: 20, Burst: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure(DefaultLimits())
+
+	for provider, minimum := range map[string]time.Duration{"Paced": 90 * time.Millisecond, "Unpaced": 0} {
+		client := &http.Client{Transport: NewTransport(provider, nil)}
+		start := time.Now()
+		for range 3 {
+			resp, err := client.Get(server.URL)
+			if err != nil {
+				t.Fatalf("%s: request failed: %v", provider, err)
+			}
+			resp.Body.Close()
+		}
+		if elapsed := time.Since(start); elapsed < minimum {
+			t.Errorf("%s: expected 3 requests to take at least %s, took %s", provider, minimum, elapsed)
+		}
+	}
+
+	if err := Configure(map[string]Limit{"Paced": {Rate: 0}}); err == nil {
+		t.Error("Expected an invalid limit to be rejected")
+	}
+	if stats := All(); len(stats) != 1 || stats[0].Provider != "Paced" || stats[0].Delayed != 2 {
+		t.Errorf("Expected the paced provider's delays in the snapshot, got %+v", stats)
+	}
+}
@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"expvar"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// registry holds the limiter of every paced provider by name
+var registry = struct {
+	sync.Mutex
+	limiters map[string]*Limiter
+}{limiters: limitersFor(DefaultLimits())}
+
+func init() {
+	expvar.Publish("provider_rate_limits", expvar.Func(func() any { return All() }))
+}
+
+// DefaultLimits paces Nominatim to the single request per second its usage policy
+// allows; other providers are not paced unless configured
+func DefaultLimits() map[string]Limit {
+	return map[string]Limit{
+		"Nominatim": {Rate: 1, Burst: 1},
+	}
+}
+
+// limitersFor creates a limiter for every limit
+func limitersFor(limits map[string]Limit) map[string]*Limiter {
+	limiters := make(map[string]*Limiter, len(limits))
+	for provider, limit := range limits {
+		limiters[provider] = New(provider, limit)
+	}
+	return limiters
+}
+
+// Lookup returns the limiter of a provider, if it is paced
+func Lookup(provider string) (*Limiter, bool) {
+	registry.Lock()
+	defer registry.Unlock()
+	l, ok := registry.limiters[provider]
+	return l, ok
+}
+
+// Configure validates limits and replaces every limiter with fresh ones; providers left
+// out are no longer paced
+func Configure(limits map[string]Limit) error {
+	for provider, limit := range limits {
+		if err := limit.Validate(); err != nil {
+			return fmt.Errorf("rate limit of %s: %w", provider, err)
+		}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.limiters = limitersFor(limits)
+	return nil
+}
+
+// ParseLimits parses rate limits of the form "provider=rate[:burst]", in requests per
+// second with a burst of 1 by default, on top of DefaultLimits. A rate of 0 turns a
+// default limit off.
+func ParseLimits(specs []string) (map[string]Limit, error) {
+	limits := DefaultLimits()
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		provider, value, ok := strings.Cut(spec, "=")
+		provider = strings.TrimSpace(provider)
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid rate limit %q: expected provider=rate[:burst]", spec)
+		}
+		rateValue, burstValue, hasBurst := strings.Cut(strings.TrimSpace(value), ":")
+		rate, err := strconv.ParseFloat(rateValue, 64)
+		if err != nil || rate < 0 {
+			return nil, fmt.Errorf("invalid rate limit %q: rate must be a non-negative number", spec)
+		}
+		burst := 1
+		if hasBurst {
+			if burst, err = strconv.Atoi(burstValue); err != nil || burst <= 0 {
+				return nil, fmt.Errorf("invalid rate limit %q: burst must be a positive integer", spec)
+			}
+		}
+		if rate == 0 {
+			delete(limits, provider)
+			continue
+		}
+		limits[provider] = Limit{Rate: rate, Burst: burst}
+	}
+	return limits, nil
+}
+
+// All returns a snapshot of every limiter, ordered by provider
+func All() []Stats {
+	registry.Lock()
+	limiters := maps.Clone(registry.limiters)
+	registry.Unlock()
+
+	stats := make([]Stats, 0, len(limiters))
+	for _, provider := range slices.Sorted(maps.Keys(limiters)) {
+		stats = append(stats, limiters[provider].Stats())
+	}
+	return stats
+}
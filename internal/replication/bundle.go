@@ -0,0 +1,130 @@
+package replication
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// BundleFormat is bumped whenever the bundle layout changes incompatibly
+const BundleFormat = 1
+
+// Bundle is a signed snapshot of reference data exported by one deployment.
+//
+// Rows are keyed by stable natural keys rather than database IDs, which differ between
+// deployments: cities by GeoNames ID and places by (source, source_place_id).
+type Bundle struct {
+	Format    int           `json:"format"`
+	Origin    string        `json:"origin"`
+	CreatedAt time.Time     `json:"created_at"`
+	Vector    VersionVector `json:"vector"`
+	Cities    []*repo.City  `json:"cities"`
+	Places    []*repo.Place `json:"places"`
+
+	// PublicKey and Signature are hex-encoded Ed25519 values covering every other field
+	PublicKey string `json:"public_key,omitempty"`
+	Signature string `json:"signature,omitempty"`
+}
+
+// GenerateKey creates a new Ed25519 signing key pair, hex-encoded. The private key is
+// stored as its 32-byte seed.
+func GenerateKey() (publicKey, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return hex.EncodeToString(pub), hex.EncodeToString(priv.Seed()), nil
+}
+
+// ParsePrivateKey decodes a hex-encoded Ed25519 seed
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	seed, err := hex.DecodeString(value)
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("signing key must be a %d-byte hex-encoded Ed25519 seed", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// signedPayload returns the canonical bytes covered by the signature
+func (b *Bundle) signedPayload() ([]byte, error) {
+	unsigned := *b
+	unsigned.Signature = ""
+	return json.Marshal(&unsigned)
+}
+
+// Sign signs the bundle with the given private key, embedding the public key
+func (b *Bundle) Sign(key ed25519.PrivateKey) error {
+	b.PublicKey = hex.EncodeToString(key.Public().(ed25519.PublicKey))
+
+	payload, err := b.signedPayload()
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+
+	b.Signature = hex.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// Verify checks that the bundle was signed by one of the trusted hex-encoded public keys
+func (b *Bundle) Verify(trusted []string) error {
+	if b.Signature == "" {
+		return fmt.Errorf("bundle is not signed")
+	}
+
+	trustedKey := false
+	for _, key := range trusted {
+		if key == b.PublicKey {
+			trustedKey = true
+			break
+		}
+	}
+	if !trustedKey {
+		return fmt.Errorf("bundle signed by untrusted key %s", b.PublicKey)
+	}
+
+	pub, err := hex.DecodeString(b.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid bundle public key")
+	}
+	sig, err := hex.DecodeString(b.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid bundle signature encoding")
+	}
+
+	payload, err := b.signedPayload()
+	if err != nil {
+		return fmt.Errorf("failed to encode bundle: %w", err)
+	}
+	if !ed25519.Verify(pub, payload, sig) {
+		return fmt.Errorf("bundle signature does not match its contents")
+	}
+	return nil
+}
+
+// WriteBundle encodes a bundle as JSON
+func WriteBundle(w io.Writer, b *Bundle) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(b); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return nil
+}
+
+// ReadBundle decodes a JSON bundle and checks its format version
+func ReadBundle(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	if b.Format != BundleFormat {
+		return nil, fmt.Errorf("unsupported bundle format %d (expected %d)", b.Format, BundleFormat)
+	}
+	return &b, nil
+}
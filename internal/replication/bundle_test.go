@@ -0,0 +1,84 @@
+package replication
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+func newSignedBundle(t *testing.T) (*Bundle, string) {
+	t.Helper()
+
+	pub, priv, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	key, err := ParsePrivateKey(priv)
+	if err != nil {
+		t.Fatalf("failed to parse key: %v", err)
+	}
+
+	bundle := &Bundle{
+		Format:    BundleFormat,
+		Origin:    "eu-west",
+		CreatedAt: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC),
+		Vector:    VersionVector{"eu-west": 1},
+		Cities:    []*repo.City{{Name: "Oslo", GeonameID: 3143244, UpdatedAt: "2024-01-15T12:00:00Z"}},
+	}
+	if err := bundle.Sign(key); err != nil {
+		t.Fatalf("failed to sign bundle: %v", err)
+	}
+	return bundle, pub
+}
+
+func TestBundleSignVerify(t *testing.T) {
+	bundle, pub := newSignedBundle(t)
+
+	if err := bundle.Verify([]string{pub}); err != nil {
+		t.Fatalf("expected valid signature, got %v", err)
+	}
+
+	otherPub, _, _ := GenerateKey()
+	if err := bundle.Verify([]string{otherPub}); err == nil {
+		t.Error("expected untrusted key to be rejected")
+	}
+
+	bundle.Cities[0].Name = "Bergen"
+	if err := bundle.Verify([]string{pub}); err == nil {
+		t.Error("expected tampered bundle to be rejected")
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	bundle, pub := newSignedBundle(t)
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, bundle); err != nil {
+		t.Fatalf("failed to write bundle: %v", err)
+	}
+
+	decoded, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatalf("failed to read bundle: %v", err)
+	}
+	if err := decoded.Verify([]string{pub}); err != nil {
+		t.Errorf("expected signature to survive a round trip, got %v", err)
+	}
+
+	_, err = ReadBundle(strings.NewReader(`{"format": 99}`))
+	if err == nil || !strings.Contains(err.Error(), "unsupported bundle format") {
+		t.Errorf("expected unsupported format error, got %v", err)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	if _, err := ParsePrivateKey("not-hex"); err == nil {
+		t.Error("expected error for invalid key")
+	}
+	if _, err := ParsePrivateKey("abcd"); err == nil {
+		t.Error("expected error for short key")
+	}
+}
@@ -0,0 +1,210 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// PostgreSQLStore implements Store for PostgreSQL
+type PostgreSQLStore struct {
+	db repo.DB
+}
+
+// NewPostgreSQLStore creates a new PostgreSQL replication store
+func NewPostgreSQLStore(db repo.DB) Store {
+	return &PostgreSQLStore{db: db}
+}
+
+// LoadVector reads the local version vector from replication_vector
+func (s *PostgreSQLStore) LoadVector(ctx context.Context) (VersionVector, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT node_id, counter FROM replication_vector`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load version vector: %w", err)
+	}
+	defer rows.Close()
+
+	vector := make(VersionVector)
+	for rows.Next() {
+		var node string
+		var counter int64
+		if err := rows.Scan(&node, &counter); err != nil {
+			return nil, fmt.Errorf("failed to scan version vector: %w", err)
+		}
+		vector[node] = uint64(counter)
+	}
+
+	return vector, rows.Err()
+}
+
+// SaveVector upserts every node counter; counters never move backwards
+func (s *PostgreSQLStore) SaveVector(ctx context.Context, vector VersionVector) error {
+	query := `
+		INSERT INTO replication_vector (node_id, counter, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (node_id) DO UPDATE SET
+			counter = GREATEST(replication_vector.counter, EXCLUDED.counter),
+			updated_at = EXCLUDED.updated_at`
+
+	for node, counter := range vector {
+		if _, err := s.db.ExecContext(ctx, query, node, int64(counter)); err != nil {
+			return fmt.Errorf("failed to save version vector: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListCities returns every city with a GeoNames ID
+func (s *PostgreSQLStore) ListCities(ctx context.Context) ([]*repo.City, error) {
+	query := `
+		SELECT id, name, country, country_code, region, latitude, longitude,
+			   elevation, population, timezone, geoname_id, is_capital,
+			   is_active, created_at, updated_at
+		FROM cities WHERE geoname_id IS NOT NULL AND geoname_id <> 0
+		ORDER BY geoname_id`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cities: %w", err)
+	}
+	defer rows.Close()
+
+	var cities []*repo.City
+	for rows.Next() {
+		city := &repo.City{}
+		err := rows.Scan(
+			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
+			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
+			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
+			&city.CreatedAt, &city.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan city: %w", err)
+		}
+		cities = append(cities, city)
+	}
+
+	return cities, rows.Err()
+}
+
+// ListPlaces returns every place with a source place ID
+func (s *PostgreSQLStore) ListPlaces(ctx context.Context) ([]*repo.Place, error) {
+	query := `
+		SELECT id, display_name, address_line1, address_line2, city, region,
+			   postal_code, country, country_code, latitude, longitude, place_type,
+			   confidence, source, source_place_id, bounding_box, created_at, updated_at
+		FROM places WHERE source_place_id IS NOT NULL AND source_place_id <> ''
+		ORDER BY source, source_place_id`
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list places: %w", err)
+	}
+	defer rows.Close()
+
+	var places []*repo.Place
+	for rows.Next() {
+		place := &repo.Place{}
+		err := rows.Scan(
+			&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
+			&place.City, &place.Region, &place.PostalCode, &place.Country,
+			&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
+			&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
+			&place.CreatedAt, &place.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan place: %w", err)
+		}
+		places = append(places, place)
+	}
+
+	return places, rows.Err()
+}
+
+// ApplyCity overwrites the city with the same GeoNames ID, inserting it if missing
+func (s *PostgreSQLStore) ApplyCity(ctx context.Context, city *repo.City) error {
+	updatedAt := timestampOrNow(city.UpdatedAt)
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE cities SET
+			name = $2, country = $3, country_code = $4, region = $5,
+			latitude = $6, longitude = $7, elevation = $8, population = $9,
+			timezone = $10, is_capital = $11, is_active = $12, updated_at = $13
+		WHERE geoname_id = $1`,
+		city.GeonameID, city.Name, city.Country, city.CountryCode, city.Region,
+		city.Latitude, city.Longitude, city.Elevation, city.Population,
+		city.Timezone, city.IsCapital, city.IsActive, updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update city %d: %w", city.GeonameID, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO cities (
+			name, country, country_code, region, latitude, longitude,
+			elevation, population, timezone, geoname_id, is_capital,
+			is_active, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $13)`,
+		city.Name, city.Country, city.CountryCode, city.Region, city.Latitude, city.Longitude,
+		city.Elevation, city.Population, city.Timezone, city.GeonameID, city.IsCapital,
+		city.IsActive, updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert city %d: %w", city.GeonameID, err)
+	}
+	return nil
+}
+
+// ApplyPlace overwrites the place with the same source place ID, inserting it if missing
+func (s *PostgreSQLStore) ApplyPlace(ctx context.Context, place *repo.Place) error {
+	updatedAt := timestampOrNow(place.UpdatedAt)
+
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE places SET
+			display_name = $3, address_line1 = $4, address_line2 = $5, city = $6,
+			region = $7, postal_code = $8, country = $9, country_code = $10,
+			latitude = $11, longitude = $12, place_type = $13, confidence = $14,
+			bounding_box = $15, updated_at = $16
+		WHERE source = $1 AND source_place_id = $2`,
+		place.Source, place.SourcePlaceID, place.DisplayName, place.AddressLine1,
+		place.AddressLine2, place.City, place.Region, place.PostalCode, place.Country,
+		place.CountryCode, place.Latitude, place.Longitude, place.PlaceType,
+		place.Confidence, place.BoundingBox, updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update place %s/%s: %w", place.Source, place.SourcePlaceID, err)
+	}
+	if n, err := result.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO places (
+			display_name, address_line1, address_line2, city, region, postal_code,
+			country, country_code, latitude, longitude, place_type, confidence,
+			source, source_place_id, bounding_box, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)`,
+		place.DisplayName, place.AddressLine1, place.AddressLine2, place.City, place.Region,
+		place.PostalCode, place.Country, place.CountryCode, place.Latitude, place.Longitude,
+		place.PlaceType, place.Confidence, place.Source, place.SourcePlaceID, place.BoundingBox,
+		updatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert place %s/%s: %w", place.Source, place.SourcePlaceID, err)
+	}
+	return nil
+}
+
+// timestampOrNow keeps the originating updated_at so later last-writer-wins comparisons
+// see when the data actually changed, not when it was imported
+func timestampOrNow(value string) string {
+	if _, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return value
+	}
+	return time.Now().UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,184 @@
+// Package replication syncs reference data between independent deployments.
+//
+// Each deployment exports its cities and places as a signed Bundle stamped with a
+// version vector. Importing a bundle compares its vector with the local one:
+//
+//   - bundles the local deployment has already seen (equal or older) are skipped
+//   - bundles that strictly dominate local state overwrite the matching rows
+//   - concurrent bundles are merged row by row with last-writer-wins on updated_at,
+//     breaking exact ties by the lexicographically greater node ID so that every
+//     deployment converges on the same value regardless of import order
+//
+// Rows are matched on natural keys; rows without one (cities without a GeoNames ID,
+// places without a source place ID) are not replicated. Deletions are not propagated.
+package replication
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// Store persists reference data and the local version vector
+type Store interface {
+	// LoadVector returns the local version vector (empty if never synced)
+	LoadVector(ctx context.Context) (VersionVector, error)
+
+	// SaveVector replaces the local version vector
+	SaveVector(ctx context.Context, vector VersionVector) error
+
+	// ListCities returns every city with a GeoNames ID
+	ListCities(ctx context.Context) ([]*repo.City, error)
+
+	// ListPlaces returns every place with a source place ID
+	ListPlaces(ctx context.Context) ([]*repo.Place, error)
+
+	// ApplyCity inserts or overwrites the city with the same GeoNames ID, keeping its updated_at
+	ApplyCity(ctx context.Context, city *repo.City) error
+
+	// ApplyPlace inserts or overwrites the place with the same source and source place ID,
+	// keeping its updated_at
+	ApplyPlace(ctx context.Context, place *repo.Place) error
+}
+
+// ImportResult summarizes what an import changed
+type ImportResult struct {
+	Ordering       Ordering      `json:"ordering"`
+	CitiesApplied  int           `json:"cities_applied"`
+	PlacesApplied  int           `json:"places_applied"`
+	ConflictsLost  int           `json:"conflicts_lost"` // concurrent rows where the local copy won
+	Vector         VersionVector `json:"vector"`
+	AlreadyApplied bool          `json:"already_applied"`
+}
+
+// Export builds an unsigned bundle of the local reference data. The local node's counter
+// is advanced and saved so later exports are ordered after this one.
+func Export(ctx context.Context, store Store, node string) (*Bundle, error) {
+	if node == "" {
+		return nil, fmt.Errorf("node id is required")
+	}
+
+	vector, err := store.LoadVector(ctx)
+	if err != nil {
+		return nil, err
+	}
+	vector = vector.Increment(node)
+
+	cities, err := store.ListCities(ctx)
+	if err != nil {
+		return nil, err
+	}
+	places, err := store.ListPlaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := store.SaveVector(ctx, vector); err != nil {
+		return nil, err
+	}
+
+	return &Bundle{
+		Format:    BundleFormat,
+		Origin:    node,
+		CreatedAt: time.Now().UTC(),
+		Vector:    vector,
+		Cities:    cities,
+		Places:    places,
+	}, nil
+}
+
+// Import applies a verified bundle to the local store following the package's conflict
+// strategy, then merges the bundle's version vector into the local one
+func Import(ctx context.Context, store Store, node string, bundle *Bundle) (*ImportResult, error) {
+	if bundle.Origin == node {
+		return nil, fmt.Errorf("refusing to import a bundle exported by this node (%s)", node)
+	}
+
+	local, err := store.LoadVector(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ImportResult{Ordering: bundle.Vector.Compare(local)}
+	if result.Ordering == Equal || result.Ordering == Before {
+		result.AlreadyApplied = true
+		result.Vector = local
+		return result, nil
+	}
+
+	// Concurrent bundles need the local rows to resolve conflicts
+	var localCities map[int]*repo.City
+	var localPlaces map[string]*repo.Place
+	if result.Ordering == Concurrent {
+		cities, err := store.ListCities(ctx)
+		if err != nil {
+			return nil, err
+		}
+		localCities = make(map[int]*repo.City, len(cities))
+		for _, city := range cities {
+			localCities[city.GeonameID] = city
+		}
+
+		places, err := store.ListPlaces(ctx)
+		if err != nil {
+			return nil, err
+		}
+		localPlaces = make(map[string]*repo.Place, len(places))
+		for _, place := range places {
+			localPlaces[placeKey(place)] = place
+		}
+	}
+
+	for _, city := range bundle.Cities {
+		if city.GeonameID == 0 {
+			continue
+		}
+		if existing, ok := localCities[city.GeonameID]; ok && !remoteWins(existing.UpdatedAt, city.UpdatedAt, node, bundle.Origin) {
+			result.ConflictsLost++
+			continue
+		}
+		if err := store.ApplyCity(ctx, city); err != nil {
+			return nil, err
+		}
+		result.CitiesApplied++
+	}
+
+	for _, place := range bundle.Places {
+		if place.SourcePlaceID == "" {
+			continue
+		}
+		if existing, ok := localPlaces[placeKey(place)]; ok && !remoteWins(existing.UpdatedAt, place.UpdatedAt, node, bundle.Origin) {
+			result.ConflictsLost++
+			continue
+		}
+		if err := store.ApplyPlace(ctx, place); err != nil {
+			return nil, err
+		}
+		result.PlacesApplied++
+	}
+
+	result.Vector = local.Merge(bundle.Vector)
+	if err := store.SaveVector(ctx, result.Vector); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func placeKey(p *repo.Place) string {
+	return p.Source + "\x00" + p.SourcePlaceID
+}
+
+// remoteWins applies last-writer-wins between a local and remote updated_at. Identical or
+// unparseable timestamps fall back to comparing node IDs so the outcome is deterministic.
+func remoteWins(localUpdatedAt, remoteUpdatedAt, localNode, remoteNode string) bool {
+	localTime, localErr := time.Parse(time.RFC3339Nano, localUpdatedAt)
+	remoteTime, remoteErr := time.Parse(time.RFC3339Nano, remoteUpdatedAt)
+
+	if localErr == nil && remoteErr == nil && !localTime.Equal(remoteTime) {
+		return remoteTime.After(localTime)
+	}
+	return remoteNode > localNode
+}
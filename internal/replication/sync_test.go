@@ -0,0 +1,154 @@
+package replication
+
+import (
+	"context"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// memoryStore implements Store in memory for testing
+type memoryStore struct {
+	vector VersionVector
+	cities map[int]*repo.City
+	places map[string]*repo.Place
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{
+		vector: VersionVector{},
+		cities: make(map[int]*repo.City),
+		places: make(map[string]*repo.Place),
+	}
+}
+
+func (m *memoryStore) LoadVector(ctx context.Context) (VersionVector, error) {
+	return m.vector.Clone(), nil
+}
+
+func (m *memoryStore) SaveVector(ctx context.Context, vector VersionVector) error {
+	m.vector = vector.Clone()
+	return nil
+}
+
+func (m *memoryStore) ListCities(ctx context.Context) ([]*repo.City, error) {
+	var cities []*repo.City
+	for _, city := range m.cities {
+		copied := *city
+		cities = append(cities, &copied)
+	}
+	return cities, nil
+}
+
+func (m *memoryStore) ListPlaces(ctx context.Context) ([]*repo.Place, error) {
+	var places []*repo.Place
+	for _, place := range m.places {
+		copied := *place
+		places = append(places, &copied)
+	}
+	return places, nil
+}
+
+func (m *memoryStore) ApplyCity(ctx context.Context, city *repo.City) error {
+	copied := *city
+	m.cities[city.GeonameID] = &copied
+	return nil
+}
+
+func (m *memoryStore) ApplyPlace(ctx context.Context, place *repo.Place) error {
+	copied := *place
+	m.places[placeKey(place)] = &copied
+	return nil
+}
+
+func TestExportImport(t *testing.T) {
+	ctx := context.Background()
+
+	eu := newMemoryStore()
+	eu.cities[3143244] = &repo.City{Name: "Oslo", GeonameID: 3143244, UpdatedAt: "2024-01-15T12:00:00Z"}
+	eu.places["Census\x00p1"] = &repo.Place{DisplayName: "Somewhere", Source: "Census", SourcePlaceID: "p1", UpdatedAt: "2024-01-15T12:00:00Z"}
+
+	bundle, err := Export(ctx, eu, "eu")
+	if err != nil {
+		t.Fatalf("export failed: %v", err)
+	}
+	if bundle.Vector["eu"] != 1 || eu.vector["eu"] != 1 {
+		t.Fatalf("expected export to advance the local counter, got bundle %v local %v", bundle.Vector, eu.vector)
+	}
+
+	us := newMemoryStore()
+	result, err := Import(ctx, us, "us", bundle)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+	if result.Ordering != After || result.CitiesApplied != 1 || result.PlacesApplied != 1 {
+		t.Errorf("unexpected import result %+v", result)
+	}
+	if us.cities[3143244] == nil || us.vector["eu"] != 1 {
+		t.Errorf("expected city and vector to be imported, got %v %v", us.cities, us.vector)
+	}
+
+	// Re-importing the same bundle is a no-op
+	result, err = Import(ctx, us, "us", bundle)
+	if err != nil {
+		t.Fatalf("re-import failed: %v", err)
+	}
+	if !result.AlreadyApplied || result.CitiesApplied != 0 {
+		t.Errorf("expected re-import to be skipped, got %+v", result)
+	}
+
+	if _, err := Import(ctx, eu, "eu", bundle); err == nil {
+		t.Error("expected importing our own bundle to fail")
+	}
+}
+
+func TestImportConcurrentLastWriterWins(t *testing.T) {
+	ctx := context.Background()
+
+	local := newMemoryStore()
+	local.vector = VersionVector{"us": 1}
+	local.cities[1] = &repo.City{Name: "Local newer", GeonameID: 1, UpdatedAt: "2024-02-01T00:00:00Z"}
+	local.cities[2] = &repo.City{Name: "Local older", GeonameID: 2, UpdatedAt: "2024-01-01T00:00:00Z"}
+	local.cities[3] = &repo.City{Name: "Local tie", GeonameID: 3, UpdatedAt: "2024-01-01T00:00:00Z"}
+
+	bundle := &Bundle{
+		Format: BundleFormat,
+		Origin: "eu",
+		Vector: VersionVector{"eu": 1},
+		Cities: []*repo.City{
+			{Name: "Remote older", GeonameID: 1, UpdatedAt: "2024-01-15T00:00:00Z"},
+			{Name: "Remote newer", GeonameID: 2, UpdatedAt: "2024-01-15T00:00:00Z"},
+			{Name: "Remote tie", GeonameID: 3, UpdatedAt: "2024-01-01T00:00:00Z"},
+			{Name: "Remote only", GeonameID: 4, UpdatedAt: "2024-01-01T00:00:00Z"},
+			{Name: "No natural key", GeonameID: 0},
+		},
+	}
+
+	result, err := Import(ctx, local, "us", bundle)
+	if err != nil {
+		t.Fatalf("import failed: %v", err)
+	}
+
+	if result.Ordering != Concurrent {
+		t.Errorf("expected concurrent ordering, got %s", result.Ordering)
+	}
+	expected := map[int]string{1: "Local newer", 2: "Remote newer", 3: "Local tie", 4: "Remote only"}
+	for id, name := range expected {
+		if local.cities[id] == nil || local.cities[id].Name != name {
+			t.Errorf("city %d: expected %q, got %+v", id, name, local.cities[id])
+		}
+	}
+	if result.CitiesApplied != 2 || result.ConflictsLost != 2 {
+		t.Errorf("expected 2 applied and 2 lost, got %+v", result)
+	}
+	if local.vector.Compare(VersionVector{"us": 1, "eu": 1}) != Equal {
+		t.Errorf("expected merged vector, got %v", local.vector)
+	}
+}
+
+func TestRemoteWinsTieBreakIsSymmetric(t *testing.T) {
+	ts := "2024-01-01T00:00:00Z"
+	if remoteWins(ts, ts, "us", "eu") == remoteWins(ts, ts, "eu", "us") {
+		t.Error("expected exactly one side to win a tie")
+	}
+}
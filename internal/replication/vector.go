@@ -0,0 +1,89 @@
+package replication
+
+// Ordering is the causal relationship between two version vectors
+type Ordering int
+
+const (
+	// Equal means both vectors have seen exactly the same changes
+	Equal Ordering = iota
+	// Before means the first vector is strictly older than the second
+	Before
+	// After means the first vector strictly dominates the second
+	After
+	// Concurrent means each vector has seen changes the other has not
+	Concurrent
+)
+
+// String returns a human-readable ordering name
+func (o Ordering) String() string {
+	switch o {
+	case Equal:
+		return "equal"
+	case Before:
+		return "before"
+	case After:
+		return "after"
+	default:
+		return "concurrent"
+	}
+}
+
+// VersionVector maps a deployment (node) ID to the number of reference-data exports
+// that node has produced and that are reflected in the holder's data
+type VersionVector map[string]uint64
+
+// Clone returns an independent copy of the vector
+func (v VersionVector) Clone() VersionVector {
+	clone := make(VersionVector, len(v))
+	for node, counter := range v {
+		clone[node] = counter
+	}
+	return clone
+}
+
+// Increment returns a copy of the vector with node's counter advanced by one
+func (v VersionVector) Increment(node string) VersionVector {
+	next := v.Clone()
+	next[node]++
+	return next
+}
+
+// Merge returns the pointwise maximum of both vectors
+func (v VersionVector) Merge(other VersionVector) VersionVector {
+	merged := v.Clone()
+	for node, counter := range other {
+		if counter > merged[node] {
+			merged[node] = counter
+		}
+	}
+	return merged
+}
+
+// Compare reports how v relates to other
+func (v VersionVector) Compare(other VersionVector) Ordering {
+	var less, greater bool
+
+	for node, counter := range v {
+		if counter > other[node] {
+			greater = true
+		} else if counter < other[node] {
+			less = true
+		}
+	}
+	for node, counter := range other {
+		if _, ok := v[node]; !ok && counter > 0 {
+			less = true
+		}
+	}
+
+	switch {
+	case less && greater:
+		return Concurrent
+	case less:
+		return Before
+	case greater:
+		return After
+	default:
+		return Equal
+	}
+}
@@ -0,0 +1,46 @@
+package replication
+
+import "testing"
+
+func TestVersionVectorCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     VersionVector
+		expected Ordering
+	}{
+		{"both empty", VersionVector{}, VersionVector{}, Equal},
+		{"equal", VersionVector{"eu": 2, "us": 1}, VersionVector{"eu": 2, "us": 1}, Equal},
+		{"zero counter equals missing", VersionVector{"eu": 1, "us": 0}, VersionVector{"eu": 1}, Equal},
+		{"after", VersionVector{"eu": 3, "us": 1}, VersionVector{"eu": 2, "us": 1}, After},
+		{"after with new node", VersionVector{"eu": 2, "us": 1}, VersionVector{"eu": 2}, After},
+		{"before", VersionVector{"eu": 1}, VersionVector{"eu": 2, "us": 1}, Before},
+		{"concurrent", VersionVector{"eu": 2, "us": 1}, VersionVector{"eu": 1, "us": 2}, Concurrent},
+		{"concurrent disjoint", VersionVector{"eu": 1}, VersionVector{"us": 1}, Concurrent},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.a.Compare(test.b); got != test.expected {
+				t.Errorf("expected %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestVersionVectorIncrementAndMerge(t *testing.T) {
+	original := VersionVector{"eu": 1}
+	next := original.Increment("eu").Increment("us")
+
+	if original["eu"] != 1 || len(original) != 1 {
+		t.Errorf("expected Increment not to modify the receiver, got %v", original)
+	}
+	if next["eu"] != 2 || next["us"] != 1 {
+		t.Errorf("unexpected incremented vector %v", next)
+	}
+
+	merged := VersionVector{"eu": 5, "us": 1}.Merge(VersionVector{"us": 3, "ap": 1})
+	expected := VersionVector{"eu": 5, "us": 3, "ap": 1}
+	if merged.Compare(expected) != Equal {
+		t.Errorf("expected %v, got %v", expected, merged)
+	}
+}
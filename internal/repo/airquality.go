@@ -0,0 +1,94 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const airQualityColumns = `id, latitude, longitude, source_provider, observed_at, aqi, pm2_5, pm10,
+		   ozone, created_at, updated_at`
+
+// PostgreSQLAirQualityRepository implements AirQualityRepository for PostgreSQL
+type PostgreSQLAirQualityRepository struct {
+	db DB
+}
+
+// NewPostgreSQLAirQualityRepository creates a new PostgreSQL air quality repository
+func NewPostgreSQLAirQualityRepository(db DB) AirQualityRepository {
+	return &PostgreSQLAirQualityRepository{db: db}
+}
+
+// Upsert inserts a reading, or refreshes the existing row with the same
+// (latitude, longitude, observed_at): providers publish the current hour's values
+// before it ends, so later fetches of the same hour update them in place
+func (r *PostgreSQLAirQualityRepository) Upsert(ctx context.Context, reading *AirQuality) error {
+	query := `
+		INSERT INTO air_quality (
+			latitude, longitude, source_provider, observed_at, aqi, pm2_5, pm10, ozone,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $9)
+		ON CONFLICT (latitude, longitude, observed_at) DO UPDATE SET
+			source_provider = EXCLUDED.source_provider, aqi = EXCLUDED.aqi,
+			pm2_5 = EXCLUDED.pm2_5, pm10 = EXCLUDED.pm10, ozone = EXCLUDED.ozone,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		reading.Latitude, reading.Longitude, reading.SourceProvider, reading.ObservedAt,
+		reading.AQI, reading.PM25, reading.PM10, reading.Ozone, now,
+	).Scan(&reading.ID, &reading.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert air quality: %w", classify(err))
+	}
+
+	reading.UpdatedAt = now
+	return nil
+}
+
+// GetLatest retrieves the most recent reading at a point
+func (r *PostgreSQLAirQualityRepository) GetLatest(ctx context.Context, lat, lon float64) (*AirQuality, error) {
+	query := `SELECT ` + airQualityColumns + ` FROM air_quality
+		WHERE latitude = $1 AND longitude = $2 ORDER BY observed_at DESC LIMIT 1`
+
+	reading, err := scanAirQuality(reader(r.db).QueryRowContext(ctx, query, lat, lon))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("no air quality readings found at %g,%g", lat, lon)
+		}
+		return nil, fmt.Errorf("failed to get latest air quality: %w", err)
+	}
+
+	return reading, nil
+}
+
+// DeleteOlderThan removes readings observed before cutoff
+func (r *PostgreSQLAirQualityRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM air_quality WHERE observed_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old air quality readings: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func scanAirQuality(row rowScanner) (*AirQuality, error) {
+	reading := &AirQuality{}
+	err := row.Scan(
+		&reading.ID, &reading.Latitude, &reading.Longitude, &reading.SourceProvider,
+		&reading.ObservedAt, &reading.AQI, &reading.PM25, &reading.PM10, &reading.Ozone,
+		&reading.CreatedAt, &reading.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return reading, nil
+}
@@ -0,0 +1,71 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestAirQualityRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ AirQualityRepository = (*PostgreSQLAirQualityRepository)(nil)
+
+		if NewPostgreSQLAirQualityRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLAirQualityRepository returned nil")
+		}
+	})
+
+	t.Run("DeleteOlderThan", func(t *testing.T) {
+		repo := NewPostgreSQLAirQualityRepository(&MockDB{})
+		deleted, err := repo.DeleteOlderThan(context.Background(), "2024-01-01T00:00:00Z")
+		if err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("Expected 1 deleted row, got %d", deleted)
+		}
+
+		repo = NewPostgreSQLAirQualityRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if _, err := repo.DeleteOlderThan(context.Background(), "2024-01-01T00:00:00Z"); err == nil {
+			t.Error("Expected error from DeleteOlderThan, got nil")
+		}
+	})
+}
+
+func TestFileAirQualityRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	pm25 := 12.5
+	readings := []*AirQuality{
+		{Latitude: 40.71, Longitude: -74.01, SourceProvider: "Open-Meteo", ObservedAt: "2025-08-11T13:00:00Z", AQI: 38},
+		{Latitude: 40.71, Longitude: -74.01, SourceProvider: "Open-Meteo", ObservedAt: "2025-08-11T14:00:00Z", AQI: 45},
+		{Latitude: 40.71, Longitude: -74.01, SourceProvider: "Open-Meteo", ObservedAt: "2025-08-11T14:00:00Z", AQI: 52, PM25: &pm25},
+		{Latitude: 51.51, Longitude: -0.13, SourceProvider: "Open-Meteo", ObservedAt: "2025-08-11T15:00:00Z", AQI: 20},
+	}
+	for _, reading := range readings {
+		if err := engine.AirQuality().Upsert(ctx, reading); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+	}
+
+	latest, err := engine.AirQuality().GetLatest(ctx, 40.71, -74.01)
+	if err != nil {
+		t.Fatalf("GetLatest failed: %v", err)
+	}
+	if latest.ID != readings[1].ID || latest.AQI != 52 || latest.PM25 == nil {
+		t.Errorf("Expected the revised 14:00 reading, got %+v", latest)
+	}
+	if _, err := engine.AirQuality().GetLatest(ctx, 0, 0); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected not found for a point without readings, got %v", err)
+	}
+
+	deleted, err := engine.AirQuality().DeleteOlderThan(ctx, "2025-08-11T14:00:00Z")
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 reading deleted, got %d (%v)", deleted, err)
+	}
+}
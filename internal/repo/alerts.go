@@ -0,0 +1,350 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+)
+
+const alertColumns = `id, source_provider, provider_alert_id, COALESCE(city_id, 0), latitude, longitude,
+		   title, description, severity, urgency, category, area_desc, COALESCE(geometry::text, ''),
+		   start_time, end_time, created_at, updated_at`
+
+// activeAlertClause restricts queries to alerts currently in effect
+const activeAlertClause = `(start_time IS NULL OR start_time <= NOW()) AND (end_time IS NULL OR end_time > NOW())`
+
+// PostgreSQLAlertRepository implements AlertRepository for PostgreSQL
+type PostgreSQLAlertRepository struct {
+	db DB
+}
+
+// NewPostgreSQLAlertRepository creates a new PostgreSQL alert repository
+func NewPostgreSQLAlertRepository(db DB) AlertRepository {
+	return &PostgreSQLAlertRepository{db: db}
+}
+
+// Create inserts a new alert record
+func (r *PostgreSQLAlertRepository) Create(ctx context.Context, alert *Alert) error {
+	bounds, err := alertBounds(alert)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO alerts (
+			source_provider, provider_alert_id, city_id, latitude, longitude,
+			title, description, severity, urgency, category, area_desc,
+			start_time, end_time, created_at, updated_at,
+			geometry, min_latitude, min_longitude, max_latitude, max_longitude
+		) VALUES (
+			$1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10, $11,
+			NULLIF($12, '')::timestamptz, NULLIF($13, '')::timestamptz, $14, $15,
+			NULLIF($16, '')::jsonb, $17, $18, $19, $20
+		) RETURNING id`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err = r.db.QueryRowContext(ctx, query, append([]any{
+		alert.SourceProvider, alert.ProviderAlertID, alert.CityID, alert.Latitude, alert.Longitude,
+		alert.Title, alert.Description, alert.Severity, alert.Urgency, alert.Category, alert.AreaDesc,
+		alert.StartTime, alert.EndTime, now, now, alert.Geometry,
+	}, bounds...)...).Scan(&alert.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create alert: %w", classify(err))
+	}
+
+	alert.CreatedAt = now
+	alert.UpdatedAt = now
+	return nil
+}
+
+// Upsert inserts an alert, or updates the existing alert with the same
+// (source_provider, provider_alert_id) so repeated ingestion does not create duplicates
+func (r *PostgreSQLAlertRepository) Upsert(ctx context.Context, alert *Alert) error {
+	bounds, err := alertBounds(alert)
+	if err != nil {
+		return err
+	}
+	query := `
+		INSERT INTO alerts (
+			source_provider, provider_alert_id, city_id, latitude, longitude,
+			title, description, severity, urgency, category, area_desc,
+			start_time, end_time, created_at, updated_at,
+			geometry, min_latitude, min_longitude, max_latitude, max_longitude
+		) VALUES (
+			$1, $2, NULLIF($3, 0), $4, $5, $6, $7, $8, $9, $10, $11,
+			NULLIF($12, '')::timestamptz, NULLIF($13, '')::timestamptz, $14, $14,
+			NULLIF($15, '')::jsonb, $16, $17, $18, $19
+		)
+		ON CONFLICT (source_provider, provider_alert_id) DO UPDATE SET
+			city_id = COALESCE(EXCLUDED.city_id, alerts.city_id),
+			latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+			title = EXCLUDED.title, description = EXCLUDED.description,
+			severity = EXCLUDED.severity, urgency = EXCLUDED.urgency,
+			category = EXCLUDED.category, area_desc = EXCLUDED.area_desc,
+			start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+			geometry = EXCLUDED.geometry,
+			min_latitude = EXCLUDED.min_latitude, min_longitude = EXCLUDED.min_longitude,
+			max_latitude = EXCLUDED.max_latitude, max_longitude = EXCLUDED.max_longitude,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err = r.db.QueryRowContext(ctx, query, append([]any{
+		alert.SourceProvider, alert.ProviderAlertID, alert.CityID, alert.Latitude, alert.Longitude,
+		alert.Title, alert.Description, alert.Severity, alert.Urgency, alert.Category, alert.AreaDesc,
+		alert.StartTime, alert.EndTime, now, alert.Geometry,
+	}, bounds...)...).Scan(&alert.ID, &alert.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert alert: %w", classify(err))
+	}
+
+	alert.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves an alert by its ID
+func (r *PostgreSQLAlertRepository) GetByID(ctx context.Context, id int) (*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE id = $1`
+
+	alert, err := scanAlert(reader(r.db).QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("alert with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// GetByProviderAlertID retrieves an alert by its provider-specific ID
+func (r *PostgreSQLAlertRepository) GetByProviderAlertID(ctx context.Context, sourceProvider, providerAlertID string) (*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts WHERE source_provider = $1 AND provider_alert_id = $2`
+
+	alert, err := scanAlert(reader(r.db).QueryRowContext(ctx, query, sourceProvider, providerAlertID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("alert with source %s and provider_alert_id %s not found", sourceProvider, providerAlertID)
+		}
+		return nil, fmt.Errorf("failed to get alert by provider alert id: %w", err)
+	}
+
+	return alert, nil
+}
+
+// Update modifies an existing alert record
+func (r *PostgreSQLAlertRepository) Update(ctx context.Context, alert *Alert) error {
+	bounds, err := alertBounds(alert)
+	if err != nil {
+		return err
+	}
+	query := `
+		UPDATE alerts SET
+			source_provider = $2, provider_alert_id = $3, city_id = NULLIF($4, 0),
+			latitude = $5, longitude = $6, title = $7, description = $8,
+			severity = $9, urgency = $10, category = $11, area_desc = $12,
+			start_time = NULLIF($13, '')::timestamptz, end_time = NULLIF($14, '')::timestamptz,
+			updated_at = $15, geometry = NULLIF($16, '')::jsonb,
+			min_latitude = $17, min_longitude = $18, max_latitude = $19, max_longitude = $20
+		WHERE id = $1`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx, query, append([]any{
+		alert.ID, alert.SourceProvider, alert.ProviderAlertID, alert.CityID,
+		alert.Latitude, alert.Longitude, alert.Title, alert.Description,
+		alert.Severity, alert.Urgency, alert.Category, alert.AreaDesc,
+		alert.StartTime, alert.EndTime, now, alert.Geometry,
+	}, bounds...)...)
+
+	if err != nil {
+		return fmt.Errorf("failed to update alert: %w", classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("alert with id %d not found", alert.ID)
+	}
+
+	alert.UpdatedAt = now
+	return nil
+}
+
+// Delete removes an alert record by its ID
+func (r *PostgreSQLAlertRepository) Delete(ctx context.Context, id int) error {
+	query := `DELETE FROM alerts WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("alert with id %d not found", id)
+	}
+
+	return nil
+}
+
+// List retrieves alerts with pagination, most recently issued first
+func (r *PostgreSQLAlertRepository) List(ctx context.Context, limit, offset int) ([]*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts ORDER BY created_at DESC LIMIT $1 OFFSET $2`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list alerts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAlerts(rows)
+}
+
+// Count returns the total number of alert records
+func (r *PostgreSQLAlertRepository) Count(ctx context.Context) (int, error) {
+	query := `SELECT COUNT(*) FROM alerts`
+	var count int
+	err := reader(r.db).QueryRowContext(ctx, query).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count alerts: %w", err)
+	}
+	return count, nil
+}
+
+// GetActiveByCityID retrieves alerts currently in effect for a city
+func (r *PostgreSQLAlertRepository) GetActiveByCityID(ctx context.Context, cityID int) ([]*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts
+		WHERE city_id = $1 AND ` + activeAlertClause + `
+		ORDER BY end_time ASC NULLS LAST`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, cityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active alerts by city: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAlerts(rows)
+}
+
+// GetActiveByCoordinates retrieves alerts currently in effect at given coordinates: those
+// whose geometry covers the point, and those without geometry within a radius of it
+//
+//	Alerts with geometry are narrowed to the bounding boxes holding the point and
+//	tested in Go; the radius uses the haversine formula
+func (r *PostgreSQLAlertRepository) GetActiveByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts
+		WHERE ` + activeAlertClause + `
+		  AND ((geometry IS NULL
+		        AND (6371 * acos(LEAST(1, cos(radians($1)) * cos(radians(latitude)) *
+			    cos(radians(longitude) - radians($2)) + sin(radians($1)) *
+			    sin(radians(latitude))))) <= $3)
+		    OR (geometry IS NOT NULL
+		        AND $1 BETWEEN min_latitude AND max_latitude
+		        AND $2 BETWEEN min_longitude AND max_longitude))
+		ORDER BY end_time ASC NULLS LAST`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, lat, lon, radiusKm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active alerts by coordinates: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*Alert
+	for rows.Next() && len(alerts) < limit {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		if alertCovers(alert, lat, lon, radiusKm) {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, rows.Err()
+}
+
+// DeleteExpired removes alerts whose end time has passed
+func (r *PostgreSQLAlertRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM alerts WHERE end_time IS NOT NULL AND end_time <= NOW()`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired alerts: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanAlert scans a single alert row, mapping NULL start/end times to empty strings
+func scanAlert(row rowScanner) (*Alert, error) {
+	alert := &Alert{}
+	var startTime, endTime sql.NullString
+	err := row.Scan(
+		&alert.ID, &alert.SourceProvider, &alert.ProviderAlertID, &alert.CityID,
+		&alert.Latitude, &alert.Longitude, &alert.Title, &alert.Description,
+		&alert.Severity, &alert.Urgency, &alert.Category, &alert.AreaDesc, &alert.Geometry,
+		&startTime, &endTime, &alert.CreatedAt, &alert.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	alert.StartTime = startTime.String
+	alert.EndTime = endTime.String
+	return alert, nil
+}
+
+func scanAlerts(rows *sql.Rows) ([]*Alert, error) {
+	var alerts []*Alert
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// alertBounds returns the bounding box columns (min_latitude, min_longitude,
+// max_latitude, max_longitude) of an alert's geometry, NULL when it has none
+func alertBounds(alert *Alert) ([]any, error) {
+	if alert.Geometry == "" {
+		return []any{nil, nil, nil, nil}, nil
+	}
+	area, err := geo.ParseArea([]byte(alert.Geometry))
+	if err != nil {
+		return nil, &kindError{kind: ErrConstraint, message: "invalid alert geometry: " + err.Error(), cause: err}
+	}
+	minLat, minLon, maxLat, maxLon := area.Bounds()
+	return []any{minLat, minLon, maxLat, maxLon}, nil
+}
+
+// alertCovers reports whether an alert applies at a point: inside its geometry when it
+// has one, else within radiusKm of its coordinates
+func alertCovers(alert *Alert, lat, lon, radiusKm float64) bool {
+	if alert.Geometry == "" {
+		return geo.DistanceKm(lat, lon, alert.Latitude, alert.Longitude) <= radiusKm
+	}
+	area, err := geo.ParseArea([]byte(alert.Geometry))
+	return err == nil && area.Contains(lat, lon)
+}
@@ -0,0 +1,54 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAlertRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ Repository[Alert] = (*PostgreSQLAlertRepository)(nil)
+		var _ AlertRepository = (*PostgreSQLAlertRepository)(nil)
+
+		if NewPostgreSQLAlertRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLAlertRepository returned nil")
+		}
+	})
+
+	t.Run("Query errors", func(t *testing.T) {
+		repo := NewPostgreSQLAlertRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		ctx := context.Background()
+
+		alerts, err := repo.List(ctx, 10, 0)
+		if err == nil {
+			t.Error("Expected error from List, got nil")
+		}
+		if alerts != nil {
+			t.Error("Expected nil alerts on error")
+		}
+
+		if _, err := repo.GetActiveByCityID(ctx, 1); err == nil {
+			t.Error("Expected error from GetActiveByCityID, got nil")
+		}
+
+		if _, err := repo.GetActiveByCoordinates(ctx, 39.0, -95.0, 25, 10); err == nil {
+			t.Error("Expected error from GetActiveByCoordinates, got nil")
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		repo := NewPostgreSQLAlertRepository(&MockDB{})
+		deleted, err := repo.DeleteExpired(context.Background())
+		if err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("Expected 1 deleted row, got %d", deleted)
+		}
+
+		repo = NewPostgreSQLAlertRepository(&MockDB{shouldError: true, errorMsg: "delete failed"})
+		if _, err := repo.DeleteExpired(context.Background()); err == nil {
+			t.Error("Expected error from database, got nil")
+		}
+	})
+}
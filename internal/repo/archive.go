@@ -0,0 +1,487 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// ForecastArchive stores old forecasts as one compressed blob per city and UTC day.
+//
+// Archived forecasts keep their original IDs and timestamps. They are no longer
+// returned by List, but range, city and ID lookups through an
+// ArchivedForecastRepository fan in to the archive transparently.
+type ForecastArchive interface {
+	// ArchiveBefore moves every forecast valid before cutoff into the archive
+	ArchiveBefore(ctx context.Context, cutoff time.Time) (*ArchiveResult, error)
+
+	// GetByID retrieves an archived forecast, returning nil when it is not archived
+	GetByID(ctx context.Context, id int) (*Forecast, error)
+
+	// GetByCityID retrieves up to limit archived forecasts for a city, newest first
+	GetByCityID(ctx context.Context, cityID int, limit int) ([]*Forecast, error)
+
+	// GetByCityIDAfter retrieves up to limit archived forecasts for a city in
+	// (valid_time, id) descending order after cursor
+	GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error)
+
+	// GetByTimeRange retrieves archived forecasts valid within [start, end], oldest first
+	GetByTimeRange(ctx context.Context, start, end time.Time) ([]*Forecast, error)
+
+	// DeleteBefore removes archived days before cutoff
+	DeleteBefore(ctx context.Context, cutoff time.Time) error
+}
+
+// ArchiveResult summarizes an ArchiveBefore run
+type ArchiveResult struct {
+	Days int // city-days written
+	Rows int // forecast rows moved out of the forecasts table
+}
+
+var (
+	archiveEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedBetterCompression))
+	archiveDecoder, _ = zstd.NewReader(nil)
+)
+
+// encodeArchive serializes a city-day of forecasts as zstd-compressed JSON
+func encodeArchive(forecasts []*Forecast) ([]byte, error) {
+	data, err := json.Marshal(forecasts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode archive: %w", err)
+	}
+	return archiveEncoder.EncodeAll(data, nil), nil
+}
+
+func decodeArchive(payload []byte) ([]*Forecast, error) {
+	data, err := archiveDecoder.DecodeAll(payload, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress archive: %w", err)
+	}
+	var forecasts []*Forecast
+	if err := json.Unmarshal(data, &forecasts); err != nil {
+		return nil, fmt.Errorf("failed to decode archive: %w", err)
+	}
+	return forecasts, nil
+}
+
+// PostgreSQLForecastArchive implements ForecastArchive on the forecast_archives table
+type PostgreSQLForecastArchive struct {
+	db DB
+}
+
+// NewPostgreSQLForecastArchive creates a new PostgreSQL forecast archive
+func NewPostgreSQLForecastArchive(db DB) ForecastArchive {
+	return &PostgreSQLForecastArchive{db: db}
+}
+
+// ArchiveBefore rolls forecasts valid before cutoff into per city-day blobs.
+//
+// Each day is merged into any existing blob by forecast ID before its rows are
+// deleted, so re-running after an interrupted archive never duplicates rows.
+func (a *PostgreSQLForecastArchive) ArchiveBefore(ctx context.Context, cutoff time.Time) (*ArchiveResult, error) {
+	rows, err := a.db.QueryContext(ctx, `
+		SELECT DISTINCT city_id, (valid_time AT TIME ZONE 'UTC')::date
+		FROM forecasts WHERE valid_time < $1 ORDER BY 1, 2`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find forecasts to archive: %w", err)
+	}
+
+	type cityDay struct {
+		cityID int
+		day    time.Time
+	}
+	var days []cityDay
+	for rows.Next() {
+		var cd cityDay
+		if err := rows.Scan(&cd.cityID, &cd.day); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan archive day: %w", err)
+		}
+		days = append(days, cd)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating archive days: %w", err)
+	}
+
+	result := &ArchiveResult{}
+	for _, cd := range days {
+		moved, err := a.archiveDay(ctx, cd.cityID, cd.day, cutoff)
+		if err != nil {
+			return result, err
+		}
+		result.Days++
+		result.Rows += moved
+	}
+	return result, nil
+}
+
+func (a *PostgreSQLForecastArchive) archiveDay(ctx context.Context, cityID int, day, cutoff time.Time) (int, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 1)
+	if cutoff.Before(end) {
+		end = cutoff
+	}
+
+	live, err := a.liveForecasts(ctx, cityID, start, end)
+	if err != nil || len(live) == 0 {
+		return 0, err
+	}
+
+	merged := make(map[int]*Forecast)
+	var payload []byte
+	err = a.db.QueryRowContext(ctx,
+		`SELECT payload FROM forecast_archives WHERE city_id = $1 AND day = $2`, cityID, start,
+	).Scan(&payload)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		return 0, fmt.Errorf("failed to read archive for city %d on %s: %w", cityID, start.Format(time.DateOnly), err)
+	default:
+		existing, err := decodeArchive(payload)
+		if err != nil {
+			return 0, err
+		}
+		for _, f := range existing {
+			merged[f.ID] = f
+		}
+	}
+
+	ids := make([]any, 0, len(live))
+	for _, f := range live {
+		merged[f.ID] = f
+		ids = append(ids, f.ID)
+	}
+
+	forecasts := make([]*Forecast, 0, len(merged))
+	for _, f := range merged {
+		forecasts = append(forecasts, f)
+	}
+	slices.SortFunc(forecasts, func(x, y *Forecast) int { return cmp.Compare(x.ID, y.ID) })
+
+	if payload, err = encodeArchive(forecasts); err != nil {
+		return 0, err
+	}
+
+	_, err = a.db.ExecContext(ctx, `
+		INSERT INTO forecast_archives (city_id, day, row_count, min_id, max_id, payload, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), NOW())
+		ON CONFLICT (city_id, day) DO UPDATE SET
+			row_count = EXCLUDED.row_count, min_id = EXCLUDED.min_id, max_id = EXCLUDED.max_id,
+			payload = EXCLUDED.payload, updated_at = NOW()`,
+		cityID, start, len(forecasts), forecasts[0].ID, forecasts[len(forecasts)-1].ID, payload,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write archive for city %d on %s: %w", cityID, start.Format(time.DateOnly), err)
+	}
+
+	query := fmt.Sprintf(`DELETE FROM forecasts WHERE id IN (%s)`, placeholders(1, len(ids)))
+	if _, err := a.db.ExecContext(ctx, query, ids...); err != nil {
+		return 0, fmt.Errorf("failed to delete archived forecasts: %w", err)
+	}
+
+	return len(live), nil
+}
+
+func (a *PostgreSQLForecastArchive) liveForecasts(ctx context.Context, cityID int, start, end time.Time) ([]*Forecast, error) {
+	query := `
+		SELECT ` + forecastColumns + `
+		FROM forecasts WHERE city_id = $1 AND valid_time >= $2 AND valid_time < $3`
+
+	rows, err := a.db.QueryContext(ctx, query, cityID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read forecasts to archive: %w", err)
+	}
+	forecasts, err := scanRows[Forecast](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
+	}
+	return forecasts, nil
+}
+
+// GetByID retrieves an archived forecast using the per-day ID bounds to find its blob
+func (a *PostgreSQLForecastArchive) GetByID(ctx context.Context, id int) (*Forecast, error) {
+	rows, err := reader(a.db).QueryContext(ctx,
+		`SELECT payload FROM forecast_archives WHERE $1 BETWEEN min_id AND max_id`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived forecast: %w", err)
+	}
+	defer rows.Close()
+
+	var found *Forecast
+	err = eachArchive(rows, func(forecasts []*Forecast) bool {
+		for _, f := range forecasts {
+			if f.ID == id {
+				found = f
+				return false
+			}
+		}
+		return true
+	})
+	return found, err
+}
+
+// GetByCityID retrieves up to limit archived forecasts for a city, newest first,
+// decompressing only as many days as needed
+func (a *PostgreSQLForecastArchive) GetByCityID(ctx context.Context, cityID int, limit int) ([]*Forecast, error) {
+	rows, err := reader(a.db).QueryContext(ctx,
+		`SELECT payload FROM forecast_archives WHERE city_id = $1 ORDER BY day DESC`, cityID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived forecasts by city: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Forecast
+	err = eachArchive(rows, func(forecasts []*Forecast) bool {
+		slices.SortStableFunc(forecasts, byTimeDesc(func(f *Forecast) string { return f.ValidTime }))
+		result = append(result, forecasts...)
+		return len(result) < limit
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, err
+}
+
+// GetByCityIDAfter retrieves up to limit archived forecasts for a city in (valid_time, id)
+// descending order after cursor. Days after the cursor are skipped without decompressing.
+func (a *PostgreSQLForecastArchive) GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	query := `SELECT payload FROM forecast_archives WHERE city_id = $1 ORDER BY day DESC`
+	args := []any{cityID}
+	if cursor != nil {
+		query = `SELECT payload FROM forecast_archives
+			WHERE city_id = $1 AND day <= ($2::timestamptz AT TIME ZONE 'UTC')::date
+			ORDER BY day DESC`
+		args = append(args, cursor.ValidTime)
+	}
+
+	rows, err := reader(a.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to page archived forecasts by city: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Forecast
+	err = eachArchive(rows, func(forecasts []*Forecast) bool {
+		slices.SortStableFunc(forecasts, byKeysetDesc)
+		for _, f := range forecasts {
+			if cursor.precedes(f) {
+				result = append(result, f)
+			}
+		}
+		return len(result) < limit
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, err
+}
+
+// GetByTimeRange retrieves archived forecasts valid within [start, end], oldest first
+func (a *PostgreSQLForecastArchive) GetByTimeRange(ctx context.Context, start, end time.Time) ([]*Forecast, error) {
+	rows, err := reader(a.db).QueryContext(ctx, `
+		SELECT payload FROM forecast_archives
+		WHERE day BETWEEN ($1::timestamptz AT TIME ZONE 'UTC')::date AND ($2::timestamptz AT TIME ZONE 'UTC')::date
+		ORDER BY day`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archived forecasts by time range: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*Forecast
+	err = eachArchive(rows, func(forecasts []*Forecast) bool {
+		for _, f := range forecasts {
+			valid := parseStoredTime(f.ValidTime)
+			if !valid.Before(start) && !valid.After(end) {
+				result = append(result, f)
+			}
+		}
+		return true
+	})
+	slices.SortStableFunc(result, func(x, y *Forecast) int {
+		return parseStoredTime(x.ValidTime).Compare(parseStoredTime(y.ValidTime))
+	})
+	return result, err
+}
+
+// DeleteBefore removes archived days before cutoff
+func (a *PostgreSQLForecastArchive) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	_, err := a.db.ExecContext(ctx,
+		`DELETE FROM forecast_archives WHERE day < ($1::timestamptz AT TIME ZONE 'UTC')::date`, cutoff)
+	if err != nil {
+		return fmt.Errorf("failed to delete archived forecasts: %w", err)
+	}
+	return nil
+}
+
+// eachArchive decodes each payload row and passes it to fn until fn returns false
+func eachArchive(rows *sql.Rows, fn func([]*Forecast) bool) error {
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return fmt.Errorf("failed to scan archive: %w", err)
+		}
+		forecasts, err := decodeArchive(payload)
+		if err != nil {
+			return err
+		}
+		if !fn(forecasts) {
+			return nil
+		}
+	}
+	return rows.Err()
+}
+
+// ArchivedForecastRepository fans reads in across a live ForecastRepository and a
+// ForecastArchive so archived history stays queryable through the same interface.
+// List, ListAfter and Count only cover live rows.
+type ArchivedForecastRepository struct {
+	ForecastRepository
+	archive ForecastArchive
+}
+
+// NewArchivedForecastRepository wraps live with read fallbacks to archive
+func NewArchivedForecastRepository(live ForecastRepository, archive ForecastArchive) ForecastRepository {
+	return &ArchivedForecastRepository{ForecastRepository: live, archive: archive}
+}
+
+// GetByID retrieves a forecast, falling back to the archive when it is not live
+func (r *ArchivedForecastRepository) GetByID(ctx context.Context, id int) (*Forecast, error) {
+	forecast, liveErr := r.ForecastRepository.GetByID(ctx, id)
+	if liveErr == nil {
+		return forecast, nil
+	}
+
+	archived, err := r.archive.GetByID(ctx, id)
+	if err != nil || archived == nil {
+		return nil, liveErr
+	}
+	return archived, nil
+}
+
+// GetByCityID retrieves forecasts for a city, continuing into the archive once live
+// rows run out
+func (r *ArchivedForecastRepository) GetByCityID(ctx context.Context, cityID int, limit, offset int) ([]*Forecast, error) {
+	live, err := r.ForecastRepository.GetByCityID(ctx, cityID, limit+offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(live) >= limit+offset {
+		return paginate(live, limit, offset), nil
+	}
+
+	archived, err := r.archive.GetByCityID(ctx, cityID, limit+offset-len(live))
+	if err != nil {
+		return nil, err
+	}
+
+	merged := append(live, archived...)
+	slices.SortStableFunc(merged, byTimeDesc(func(f *Forecast) string { return f.ValidTime }))
+	return paginate(merged, limit, offset), nil
+}
+
+// GetByCityIDAfter pages through a city's forecasts, continuing into the archive once
+// live rows run out. Archived rows are all older than live ones, so the cursor carries
+// over unchanged.
+func (r *ArchivedForecastRepository) GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	live, err := r.ForecastRepository.GetByCityIDAfter(ctx, cityID, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(live) >= limit {
+		return live, nil
+	}
+
+	if len(live) > 0 {
+		last := live[len(live)-1]
+		cursor = &ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+	}
+	archived, err := r.archive.GetByCityIDAfter(ctx, cityID, cursor, limit-len(live))
+	if err != nil {
+		return nil, err
+	}
+	return append(live, archived...), nil
+}
+
+// GetByTimeRange retrieves live and archived forecasts within a time range, newest first
+func (r *ArchivedForecastRepository) GetByTimeRange(ctx context.Context, startTime, endTime string, limit, offset int) ([]*Forecast, error) {
+	start, startErr := time.Parse(time.RFC3339, startTime)
+	end, endErr := time.Parse(time.RFC3339, endTime)
+	if startErr != nil || endErr != nil {
+		// Let the live repository report the malformed range
+		return r.ForecastRepository.GetByTimeRange(ctx, startTime, endTime, limit, offset)
+	}
+
+	live, err := r.ForecastRepository.GetByTimeRange(ctx, startTime, endTime, limit+offset, 0)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := r.archive.GetByTimeRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := append(live, archived...)
+	slices.SortStableFunc(merged, byKeysetDesc)
+	return paginate(merged, limit, offset), nil
+}
+
+// GetByTimeRangeAfter pages through the live and archived forecasts within a time
+// range. The archive has no keyset index, so each page filters its rows in the range
+// by cursor.
+func (r *ArchivedForecastRepository) GetByTimeRangeAfter(ctx context.Context, startTime, endTime string, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	live, err := r.ForecastRepository.GetByTimeRangeAfter(ctx, startTime, endTime, cursor, limit)
+	if err != nil {
+		return nil, err
+	}
+	start, startErr := time.Parse(time.RFC3339, startTime)
+	end, endErr := time.Parse(time.RFC3339, endTime)
+	if startErr != nil || endErr != nil {
+		return live, nil
+	}
+	archived, err := r.archive.GetByTimeRange(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := live
+	for _, f := range archived {
+		if cursor.precedes(f) {
+			merged = append(merged, f)
+		}
+	}
+	slices.SortStableFunc(merged, byKeysetDesc)
+	return paginate(merged, limit, 0), nil
+}
+
+// GetLatestByCityID retrieves the most recent forecast for a city, live or archived
+func (r *ArchivedForecastRepository) GetLatestByCityID(ctx context.Context, cityID int) (*Forecast, error) {
+	forecast, liveErr := r.ForecastRepository.GetLatestByCityID(ctx, cityID)
+	if liveErr == nil {
+		return forecast, nil
+	}
+
+	archived, err := r.archive.GetByCityID(ctx, cityID, 1)
+	if err != nil || len(archived) == 0 {
+		return nil, liveErr
+	}
+	return archived[0], nil
+}
+
+// DeleteOldForecasts removes live forecasts matching filter that were valid more than
+// days ago, and archived days before then when filter is empty: an archived day holds
+// every provider of a city, so a filtered retention leaves the archive alone. The count
+// is of live forecasts only.
+func (r *ArchivedForecastRepository) DeleteOldForecasts(ctx context.Context, days int, filter ForecastFilter) (int64, error) {
+	deleted, err := r.ForecastRepository.DeleteOldForecasts(ctx, days, filter)
+	if err != nil || !filter.IsEmpty() {
+		return deleted, err
+	}
+	return deleted, r.archive.DeleteBefore(ctx, time.Now().AddDate(0, 0, -days))
+}
@@ -0,0 +1,204 @@
+package repo
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// memoryArchive is an in-memory ForecastArchive for testing the read-path fan-in
+type memoryArchive struct {
+	forecasts []*Forecast
+}
+
+func (m *memoryArchive) ArchiveBefore(ctx context.Context, cutoff time.Time) (*ArchiveResult, error) {
+	return &ArchiveResult{}, nil
+}
+
+func (m *memoryArchive) GetByID(ctx context.Context, id int) (*Forecast, error) {
+	for _, f := range m.forecasts {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memoryArchive) GetByCityID(ctx context.Context, cityID int, limit int) ([]*Forecast, error) {
+	var result []*Forecast
+	for _, f := range m.forecasts {
+		if f.CityID == cityID {
+			result = append(result, f)
+		}
+	}
+	slices.SortFunc(result, byTimeDesc(func(f *Forecast) string { return f.ValidTime }))
+	return paginate(result, limit, 0), nil
+}
+
+func (m *memoryArchive) GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	var result []*Forecast
+	for _, f := range m.forecasts {
+		if f.CityID == cityID && cursor.precedes(f) {
+			result = append(result, f)
+		}
+	}
+	slices.SortFunc(result, byKeysetDesc)
+	return paginate(result, limit, 0), nil
+}
+
+func (m *memoryArchive) GetByTimeRange(ctx context.Context, start, end time.Time) ([]*Forecast, error) {
+	var result []*Forecast
+	for _, f := range m.forecasts {
+		if valid := parseStoredTime(f.ValidTime); !valid.Before(start) && !valid.After(end) {
+			result = append(result, f)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryArchive) DeleteBefore(ctx context.Context, cutoff time.Time) error {
+	m.forecasts = slices.DeleteFunc(m.forecasts, func(f *Forecast) bool {
+		return parseStoredTime(f.ValidTime).Before(cutoff)
+	})
+	return nil
+}
+
+func TestArchiveEncoding(t *testing.T) {
+	forecasts := []*Forecast{
+		{ID: 1, CityID: 7, ValidTime: "2024-01-01T00:00:00Z", Temperature: 3.5, Description: "Cloudy"},
+		{ID: 2, CityID: 7, ValidTime: "2024-01-01T01:00:00Z", Temperature: 3.1, Description: "Cloudy"},
+	}
+
+	payload, err := encodeArchive(forecasts)
+	if err != nil {
+		t.Fatalf("encodeArchive failed: %v", err)
+	}
+	decoded, err := decodeArchive(payload)
+	if err != nil {
+		t.Fatalf("decodeArchive failed: %v", err)
+	}
+	if len(decoded) != 2 || *decoded[1] != *forecasts[1] {
+		t.Errorf("Round trip mismatch: %+v", decoded)
+	}
+
+	if _, err := decodeArchive([]byte("not zstd")); err == nil {
+		t.Error("Expected error decoding garbage, got nil")
+	}
+}
+
+func TestArchivedForecastRepository(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Hour)
+
+	newRepo := func() (ForecastRepository, *memoryArchive) {
+		engine, _ := OpenFileEngine("")
+		live := engine.Forecasts()
+		for _, hours := range []int{-2, -1} {
+			_ = live.Create(ctx, &Forecast{CityID: 1, ValidTime: now.Add(time.Duration(hours) * time.Hour).Format(time.RFC3339)})
+		}
+		archive := &memoryArchive{forecasts: []*Forecast{
+			{ID: 100, CityID: 1, ValidTime: now.AddDate(0, 0, -40).Format(time.RFC3339)},
+			{ID: 101, CityID: 1, ValidTime: now.AddDate(0, 0, -39).Format(time.RFC3339)},
+		}}
+		return NewArchivedForecastRepository(live, archive), archive
+	}
+
+	t.Run("GetByID falls back to archive", func(t *testing.T) {
+		forecasts, _ := newRepo()
+		if f, err := forecasts.GetByID(ctx, 101); err != nil || f.ID != 101 {
+			t.Errorf("Expected archived forecast 101, got %+v (%v)", f, err)
+		}
+		if _, err := forecasts.GetByID(ctx, 999); err == nil {
+			t.Error("Expected not found error, got nil")
+		}
+	})
+
+	t.Run("GetByCityID continues into archive", func(t *testing.T) {
+		forecasts, _ := newRepo()
+		page, err := forecasts.GetByCityID(ctx, 1, 2, 1)
+		if err != nil {
+			t.Fatalf("GetByCityID failed: %v", err)
+		}
+		if len(page) != 2 || page[0].ID != 1 || page[1].ID != 101 {
+			t.Errorf("Expected forecasts 1 and 101, got %+v", page)
+		}
+	})
+
+	t.Run("GetByCityIDAfter continues into archive", func(t *testing.T) {
+		forecasts, _ := newRepo()
+		first, err := forecasts.GetByCityIDAfter(ctx, 1, nil, 3)
+		if err != nil {
+			t.Fatalf("GetByCityIDAfter failed: %v", err)
+		}
+		last := first[len(first)-1]
+		second, err := forecasts.GetByCityIDAfter(ctx, 1, &ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}, 3)
+		if err != nil {
+			t.Fatalf("GetByCityIDAfter failed: %v", err)
+		}
+
+		var ids []int
+		for _, f := range append(first, second...) {
+			ids = append(ids, f.ID)
+		}
+		if !slices.Equal(ids, []int{2, 1, 101, 100}) {
+			t.Errorf("Expected [2 1 101 100] across both pages, got %v", ids)
+		}
+	})
+
+	t.Run("GetByTimeRange merges newest first", func(t *testing.T) {
+		forecasts, _ := newRepo()
+		result, err := forecasts.GetByTimeRange(ctx,
+			now.AddDate(0, 0, -45).Format(time.RFC3339), now.Format(time.RFC3339), 10, 0)
+		if err != nil {
+			t.Fatalf("GetByTimeRange failed: %v", err)
+		}
+		var ids []int
+		for _, f := range result {
+			ids = append(ids, f.ID)
+		}
+		if !slices.Equal(ids, []int{2, 1, 101, 100}) {
+			t.Errorf("Expected [2 1 101 100], got %v", ids)
+		}
+	})
+
+	t.Run("GetByTimeRangeAfter pages through both newest first", func(t *testing.T) {
+		forecasts, _ := newRepo()
+		start, end := now.AddDate(0, 0, -45).Format(time.RFC3339), now.Format(time.RFC3339)
+		var ids []int
+		var cursor *ForecastCursor
+		for range 3 {
+			page, err := forecasts.GetByTimeRangeAfter(ctx, start, end, cursor, 3)
+			if err != nil {
+				t.Fatalf("GetByTimeRangeAfter failed: %v", err)
+			}
+			for _, f := range page {
+				ids = append(ids, f.ID)
+			}
+			if len(page) < 3 {
+				break
+			}
+			last := page[len(page)-1]
+			cursor = &ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}
+		}
+		if !slices.Equal(ids, []int{2, 1, 101, 100}) {
+			t.Errorf("Expected [2 1 101 100] across the pages, got %v", ids)
+		}
+	})
+
+	t.Run("DeleteOldForecasts prunes archive", func(t *testing.T) {
+		forecasts, archive := newRepo()
+		if _, err := forecasts.DeleteOldForecasts(ctx, 30, ForecastFilter{CityID: 1}); err != nil {
+			t.Fatalf("DeleteOldForecasts failed: %v", err)
+		}
+		if len(archive.forecasts) == 0 {
+			t.Error("Expected a filtered retention to leave the archive alone")
+		}
+		if _, err := forecasts.DeleteOldForecasts(ctx, 30, ForecastFilter{}); err != nil {
+			t.Fatalf("DeleteOldForecasts failed: %v", err)
+		}
+		if len(archive.forecasts) != 0 {
+			t.Errorf("Expected archive to be emptied, got %d rows", len(archive.forecasts))
+		}
+	})
+}
@@ -0,0 +1,142 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const aviationReportColumns = `id, station_id, report_type, source_provider, raw_text, observed_at,
+		   valid_from, valid_to, latitude, longitude, temperature, dewpoint,
+		   wind_direction, wind_speed, wind_gust, visibility, pressure, flight_category,
+		   created_at, updated_at`
+
+// PostgreSQLAviationReportRepository implements AviationReportRepository for PostgreSQL
+type PostgreSQLAviationReportRepository struct {
+	db DB
+}
+
+// NewPostgreSQLAviationReportRepository creates a new PostgreSQL aviation report repository
+func NewPostgreSQLAviationReportRepository(db DB) AviationReportRepository {
+	return &PostgreSQLAviationReportRepository{db: db}
+}
+
+// Upsert inserts a report, or refreshes the existing row with the same
+// (station_id, report_type, observed_at) so polling a station does not create duplicates.
+// Amended TAFs carry a new issue time and are stored as new rows.
+func (r *PostgreSQLAviationReportRepository) Upsert(ctx context.Context, report *AviationReport) error {
+	query := `
+		INSERT INTO aviation_reports (
+			station_id, report_type, source_provider, raw_text, observed_at,
+			valid_from, valid_to, latitude, longitude, temperature, dewpoint,
+			wind_direction, wind_speed, wind_gust, visibility, pressure, flight_category,
+			created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, NULLIF($6, '')::timestamptz, NULLIF($7, '')::timestamptz,
+			$8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $18
+		)
+		ON CONFLICT (station_id, report_type, observed_at) DO UPDATE SET
+			source_provider = EXCLUDED.source_provider, raw_text = EXCLUDED.raw_text,
+			valid_from = EXCLUDED.valid_from, valid_to = EXCLUDED.valid_to,
+			latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+			temperature = EXCLUDED.temperature, dewpoint = EXCLUDED.dewpoint,
+			wind_direction = EXCLUDED.wind_direction, wind_speed = EXCLUDED.wind_speed,
+			wind_gust = EXCLUDED.wind_gust, visibility = EXCLUDED.visibility,
+			pressure = EXCLUDED.pressure, flight_category = EXCLUDED.flight_category,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		report.StationID, report.ReportType, report.SourceProvider, report.RawText, report.ObservedAt,
+		report.ValidFrom, report.ValidTo, report.Latitude, report.Longitude,
+		report.Temperature, report.Dewpoint, report.WindDirection, report.WindSpeed,
+		report.WindGust, report.Visibility, report.Pressure, report.FlightCategory, now,
+	).Scan(&report.ID, &report.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert aviation report: %w", classify(err))
+	}
+
+	report.UpdatedAt = now
+	return nil
+}
+
+// GetLatest retrieves the most recent report of a type for a station
+func (r *PostgreSQLAviationReportRepository) GetLatest(ctx context.Context, stationID, reportType string) (*AviationReport, error) {
+	query := `SELECT ` + aviationReportColumns + ` FROM aviation_reports
+		WHERE station_id = $1 AND report_type = $2
+		ORDER BY observed_at DESC LIMIT 1`
+
+	report, err := scanAviationReport(reader(r.db).QueryRowContext(ctx, query, stationID, reportType))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("no %s found for station %s", reportType, stationID)
+		}
+		return nil, fmt.Errorf("failed to get latest aviation report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetByStation retrieves reports of a type for a station observed at or after since,
+// newest first
+func (r *PostgreSQLAviationReportRepository) GetByStation(ctx context.Context, stationID, reportType, since string, limit int) ([]*AviationReport, error) {
+	query := `SELECT ` + aviationReportColumns + ` FROM aviation_reports
+		WHERE station_id = $1 AND report_type = $2 AND observed_at >= $3
+		ORDER BY observed_at DESC LIMIT $4`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, stationID, reportType, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get aviation reports by station: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*AviationReport
+	for rows.Next() {
+		report, err := scanAviationReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan aviation report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return reports, rows.Err()
+}
+
+// DeleteOlderThan removes reports observed before cutoff
+func (r *PostgreSQLAviationReportRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	query := `DELETE FROM aviation_reports WHERE observed_at < $1`
+	result, err := r.db.ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old aviation reports: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// scanAviationReport scans a single report row, mapping NULL validity times to empty strings
+func scanAviationReport(row rowScanner) (*AviationReport, error) {
+	report := &AviationReport{}
+	var validFrom, validTo sql.NullString
+	err := row.Scan(
+		&report.ID, &report.StationID, &report.ReportType, &report.SourceProvider,
+		&report.RawText, &report.ObservedAt, &validFrom, &validTo,
+		&report.Latitude, &report.Longitude, &report.Temperature, &report.Dewpoint,
+		&report.WindDirection, &report.WindSpeed, &report.WindGust, &report.Visibility,
+		&report.Pressure, &report.FlightCategory, &report.CreatedAt, &report.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	report.ValidFrom = validFrom.String
+	report.ValidTo = validTo.String
+	return report, nil
+}
@@ -0,0 +1,45 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAviationReportRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ AviationReportRepository = (*PostgreSQLAviationReportRepository)(nil)
+
+		if NewPostgreSQLAviationReportRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLAviationReportRepository returned nil")
+		}
+	})
+
+	t.Run("Query errors", func(t *testing.T) {
+		repo := NewPostgreSQLAviationReportRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		ctx := context.Background()
+
+		reports, err := repo.GetByStation(ctx, "KJFK", "METAR", "2024-01-01T00:00:00Z", 10)
+		if err == nil {
+			t.Error("Expected error from GetByStation, got nil")
+		}
+		if reports != nil {
+			t.Error("Expected nil reports on error")
+		}
+	})
+
+	t.Run("DeleteOlderThan", func(t *testing.T) {
+		repo := NewPostgreSQLAviationReportRepository(&MockDB{})
+		deleted, err := repo.DeleteOlderThan(context.Background(), "2024-01-01T00:00:00Z")
+		if err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("Expected 1 deleted row, got %d", deleted)
+		}
+
+		repo = NewPostgreSQLAviationReportRepository(&MockDB{shouldError: true, errorMsg: "delete failed"})
+		if _, err := repo.DeleteOlderThan(context.Background(), "2024-01-01T00:00:00Z"); err == nil {
+			t.Error("Expected error from database, got nil")
+		}
+	})
+}
@@ -3,6 +3,8 @@ package repo
 import (
 	"context"
 	"time"
+
+	"stormlightlabs.org/weather_api/internal/timing"
 )
 
 // Cache defines the interface for caching operations used by the weather API
@@ -58,9 +60,17 @@ func NewRequestCache(store KVStore, prefix string) Cache {
 	}
 }
 
-// Get retrieves a value from the cache
+// Get retrieves a value from the cache. Reads that must observe a recent write (see
+// RequiresPrimary) always miss. Hits and misses are counted in the request's timing
+// breakdown.
 func (c *RequestCache) Get(ctx context.Context, key string) ([]byte, error) {
-	return c.store.Get(ctx, c.prefixKey(key))
+	if RequiresPrimary(ctx) {
+		timing.FromContext(ctx).Cache(false)
+		return nil, nil
+	}
+	value, err := c.store.Get(ctx, c.prefixKey(key))
+	timing.FromContext(ctx).Cache(err == nil && value != nil)
+	return value, err
 }
 
 // Set stores a value in the cache with TTL
@@ -73,8 +83,12 @@ func (c *RequestCache) Delete(ctx context.Context, key string) error {
 	return c.store.Delete(ctx, c.prefixKey(key))
 }
 
-// Exists checks if a key exists in the cache
+// Exists checks if a key exists in the cache; like Get it misses for reads that must
+// observe a recent write
 func (c *RequestCache) Exists(ctx context.Context, key string) (bool, error) {
+	if RequiresPrimary(ctx) {
+		return false, nil
+	}
 	return c.store.Exists(ctx, c.prefixKey(key))
 }
 
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"testing"
 	"time"
+
+	"stormlightlabs.org/weather_api/internal/timing"
 )
 
 // MockKVStore implements KVStore interface for testing
@@ -491,3 +493,17 @@ func BenchmarkCache(b *testing.B) {
 		}
 	})
 }
+
+func TestCache_Timings(t *testing.T) {
+	recorder := timing.NewRecorder()
+	ctx := timing.WithRecorder(context.Background(), recorder)
+	cache := NewRequestCache(NewMockKVStore(), "weather")
+
+	_ = cache.Set(ctx, "current:45.52,-122.68", []byte("{}"), time.Minute)
+	_, _ = cache.Get(ctx, "current:45.52,-122.68")
+	_, _ = cache.Get(ctx, "current:0,0")
+
+	if timings := recorder.Snapshot(); timings.CacheHits != 1 || timings.CacheMisses != 1 {
+		t.Errorf("Expected one hit and one miss, got %+v", timings)
+	}
+}
@@ -0,0 +1,75 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ConsistencyHeader is the HTTP header carrying consistency tokens: write responses set
+// it, and clients send it back on the reads that must observe that write
+const ConsistencyHeader = "X-Consistency-Token"
+
+// ConsistencyWindow is how long after a write its token forces fresh reads. It bounds
+// replica lag and cache staleness; once it has passed every source has caught up and the
+// token is ignored.
+const ConsistencyWindow = 5 * time.Minute
+
+// ConsistencySkew is how far in the future a token's write time may lie, allowing for
+// clock differences between instances. Later tokens are rejected, or a forged token
+// could pin a client's reads to the primary indefinitely.
+const ConsistencySkew = 5 * time.Second
+
+// consistencyPrefix versions the token format
+const consistencyPrefix = "c1."
+
+// ErrInvalidConsistencyToken is returned for tokens not produced by NewConsistencyToken
+var ErrInvalidConsistencyToken = errors.New("invalid consistency token")
+
+type consistencyKey struct{}
+
+// NewConsistencyToken returns the token for a write made at the given time
+func NewConsistencyToken(at time.Time) string {
+	return consistencyPrefix + strconv.FormatInt(at.UnixMilli(), 36)
+}
+
+// ParseConsistencyToken returns the write time encoded in a token. Tokens dated more
+// than ConsistencySkew from now are invalid.
+func ParseConsistencyToken(token string) (time.Time, error) {
+	encoded, ok := strings.CutPrefix(token, consistencyPrefix)
+	if !ok {
+		return time.Time{}, ErrInvalidConsistencyToken
+	}
+	millis, err := strconv.ParseInt(encoded, 36, 64)
+	if err != nil || millis <= 0 {
+		return time.Time{}, ErrInvalidConsistencyToken
+	}
+	written := time.UnixMilli(millis).UTC()
+	if time.Until(written) > ConsistencySkew {
+		return time.Time{}, ErrInvalidConsistencyToken
+	}
+	return written, nil
+}
+
+// WithConsistencyToken attaches a client's consistency token to ctx. Empty and invalid
+// tokens leave ctx unchanged: a bad token costs the client freshness, not the request.
+func WithConsistencyToken(ctx context.Context, token string) context.Context {
+	if token == "" {
+		return ctx
+	}
+	written, err := ParseConsistencyToken(token)
+	if err != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, consistencyKey{}, written)
+}
+
+// RequiresPrimary reports whether reads made with ctx must observe a recent write: they
+// have to go to the primary rather than a replica, and skip caches. It is true while
+// the attached token is younger than ConsistencyWindow.
+func RequiresPrimary(ctx context.Context) bool {
+	written, ok := ctx.Value(consistencyKey{}).(time.Time)
+	return ok && time.Since(written) < ConsistencyWindow
+}
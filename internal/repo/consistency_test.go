@@ -0,0 +1,70 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestConsistencyToken(t *testing.T) {
+	written := time.Date(2024, 1, 15, 12, 30, 45, 123e6, time.UTC)
+	token := NewConsistencyToken(written)
+
+	parsed, err := ParseConsistencyToken(token)
+	if err != nil {
+		t.Fatalf("ParseConsistencyToken failed: %v", err)
+	}
+	if !parsed.Equal(written) {
+		t.Errorf("Expected %v, got %v", written, parsed)
+	}
+
+	future := NewConsistencyToken(time.Now().Add(ConsistencySkew + time.Minute))
+	for _, bad := range []string{"", "c1.", "c1.!!", "c2.abc", "c1.-5", "abc", future} {
+		if _, err := ParseConsistencyToken(bad); !errors.Is(err, ErrInvalidConsistencyToken) {
+			t.Errorf("Expected %q to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestRequiresPrimary(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		token string
+		want  bool
+	}{
+		{"no token", "", false},
+		{"invalid token", "garbage", false},
+		{"recent write", NewConsistencyToken(time.Now().Add(-time.Second)), true},
+		{"write within clock skew", NewConsistencyToken(time.Now().Add(ConsistencySkew / 2)), true},
+		{"future write", NewConsistencyToken(time.Now().Add(time.Hour)), false},
+		{"expired write", NewConsistencyToken(time.Now().Add(-ConsistencyWindow - time.Second)), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RequiresPrimary(WithConsistencyToken(ctx, tt.token)); got != tt.want {
+				t.Errorf("RequiresPrimary = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestCache_BypassedForConsistentReads(t *testing.T) {
+	ctx := context.Background()
+	cache := NewRequestCache(NewMockKVStore(), "test")
+	if err := cache.Set(ctx, "key", []byte("stale"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	consistent := WithConsistencyToken(ctx, NewConsistencyToken(time.Now()))
+	if data, err := cache.Get(consistent, "key"); err != nil || data != nil {
+		t.Errorf("Expected a miss, got %q (%v)", data, err)
+	}
+	if exists, _ := cache.Exists(consistent, "key"); exists {
+		t.Error("Expected Exists to miss")
+	}
+	if data, _ := cache.Get(ctx, "key"); string(data) != "stale" {
+		t.Errorf("Expected reads without a token to hit the cache, got %q", data)
+	}
+}
@@ -0,0 +1,157 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// countCacheEntries bounds the exact counts a CountConfig without a Cache keeps
+const countCacheEntries = 64
+
+// CountConfig chooses how the Count methods behind listing totals (forecasts, cities,
+// places and alerts) answer: SELECT COUNT(*) scans the whole table, which gets slow on
+// big ones.
+type CountConfig struct {
+	// EstimateAbove answers with the planner's row estimate (pg_class.reltuples, summed
+	// over partitions) once it is above this many rows. The estimate is as fresh as the
+	// last ANALYZE or autovacuum. 0 never estimates; only PostgreSQL estimates.
+	EstimateAbove int64
+
+	// CacheTTL keeps exact counts this long; 0 counts on every call
+	CacheTTL time.Duration
+
+	// Cache holds the cached counts, an in-memory store when nil
+	Cache Cache
+}
+
+// countingEngine answers the Count methods of the wrapped engine's listed repositories
+// as its CountConfig says
+type countingEngine struct {
+	Engine
+	counter *counter
+}
+
+// NewCountingEngine wraps engine so its forecast, city, place and alert counts are
+// estimated or cached as config says. Estimates need the PostgreSQL engine itself, so
+// wrap it before NewHookedEngine. A zero config returns engine as it is.
+func NewCountingEngine(engine Engine, config CountConfig) Engine {
+	if config.EstimateAbove <= 0 && config.CacheTTL <= 0 {
+		return engine
+	}
+	c := &counter{config: config}
+	if e, ok := engine.(*PostgreSQLEngine); ok && config.EstimateAbove > 0 {
+		c.db = e.db
+	}
+	if config.CacheTTL > 0 && c.config.Cache == nil {
+		c.config.Cache = NewRequestCache(NewMemoryStore(countCacheEntries), "counts")
+	}
+	return &countingEngine{Engine: engine, counter: c}
+}
+
+// Forecasts returns the forecast repository with estimated or cached counts
+func (e *countingEngine) Forecasts() ForecastRepository {
+	r := e.Engine.Forecasts()
+	return &countingForecastRepository{ForecastRepository: r, count: e.counter.of("forecasts", r.Count)}
+}
+
+// Cities returns the city repository with estimated or cached counts
+func (e *countingEngine) Cities() CityRepository {
+	r := e.Engine.Cities()
+	return &countingCityRepository{CityRepository: r, count: e.counter.of("cities", r.Count)}
+}
+
+// Places returns the place repository with estimated or cached counts
+func (e *countingEngine) Places() PlaceRepository {
+	r := e.Engine.Places()
+	return &countingPlaceRepository{PlaceRepository: r, count: e.counter.of("places", r.Count)}
+}
+
+// Alerts returns the alert repository with estimated or cached counts
+func (e *countingEngine) Alerts() AlertRepository {
+	r := e.Engine.Alerts()
+	return &countingAlertRepository{AlertRepository: r, count: e.counter.of("alerts", r.Count)}
+}
+
+type countingForecastRepository struct {
+	ForecastRepository
+	count func(ctx context.Context) (int, error)
+}
+
+func (r *countingForecastRepository) Count(ctx context.Context) (int, error) { return r.count(ctx) }
+
+type countingCityRepository struct {
+	CityRepository
+	count func(ctx context.Context) (int, error)
+}
+
+func (r *countingCityRepository) Count(ctx context.Context) (int, error) { return r.count(ctx) }
+
+type countingPlaceRepository struct {
+	PlaceRepository
+	count func(ctx context.Context) (int, error)
+}
+
+func (r *countingPlaceRepository) Count(ctx context.Context) (int, error) { return r.count(ctx) }
+
+type countingAlertRepository struct {
+	AlertRepository
+	count func(ctx context.Context) (int, error)
+}
+
+func (r *countingAlertRepository) Count(ctx context.Context) (int, error) { return r.count(ctx) }
+
+// counter estimates counts from db when it is set and caches exact counts
+type counter struct {
+	db     DB
+	config CountConfig
+}
+
+// of returns the Count of table, falling back to exact
+func (c *counter) of(table string, exact func(ctx context.Context) (int, error)) func(ctx context.Context) (int, error) {
+	return func(ctx context.Context) (int, error) {
+		if c.db != nil {
+			estimate, err := estimateRows(ctx, c.db, table)
+			if err != nil {
+				return 0, err
+			}
+			if estimate > c.config.EstimateAbove {
+				return int(estimate), nil
+			}
+		}
+		if c.config.CacheTTL <= 0 {
+			return exact(ctx)
+		}
+
+		// The cache is an optimization: its errors fall through to counting
+		key := "count:" + table
+		if value, err := c.config.Cache.Get(ctx, key); err == nil && value != nil {
+			if count, err := strconv.Atoi(string(value)); err == nil {
+				return count, nil
+			}
+		}
+		count, err := exact(ctx)
+		if err != nil {
+			return 0, err
+		}
+		_ = c.config.Cache.Set(ctx, key, []byte(strconv.Itoa(count)), c.config.CacheTTL)
+		return count, nil
+	}
+}
+
+// estimateRows returns the planner's estimate of the rows of table, summing the
+// partitions of a partitioned table. Tables never analyzed estimate -1, counted as 0.
+func estimateRows(ctx context.Context, db DB, table string) (int64, error) {
+	query := `
+		SELECT COALESCE(SUM(GREATEST(c.reltuples, 0)), 0)::bigint
+		FROM pg_class c
+		WHERE c.relkind <> 'p'
+		  AND (c.oid = $1::regclass
+		       OR c.oid IN (SELECT inhrelid FROM pg_inherits WHERE inhparent = $1::regclass))`
+	var estimate int64
+	if err := reader(db).QueryRowContext(ctx, query, table).Scan(&estimate); err != nil {
+		return 0, fmt.Errorf("failed to estimate %s: %w", table, err)
+	}
+	return estimate, nil
+}
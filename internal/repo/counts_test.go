@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountingEngineEstimates(t *testing.T) {
+	ctx := context.Background()
+
+	db := newScalarDB(t, "5000")
+	engine := NewCountingEngine(NewPostgreSQLEngine(db), CountConfig{EstimateAbove: 1000})
+	if count, err := engine.Forecasts().Count(ctx); err != nil || count != 5000 {
+		t.Fatalf("Expected the estimate, got %d (%v)", count, err)
+	}
+	if len(db.queries) != 1 || !strings.Contains(db.queries[0], "reltuples") || db.args[0][0] != "forecasts" {
+		t.Errorf("Expected only the estimate queried, got %q %v", db.queries, db.args)
+	}
+
+	small := newScalarDB(t, "500")
+	engine = NewCountingEngine(NewPostgreSQLEngine(small), CountConfig{EstimateAbove: 1000})
+	if count, err := engine.Alerts().Count(ctx); err != nil || count != 500 {
+		t.Fatalf("Expected the exact count, got %d (%v)", count, err)
+	}
+	if len(small.queries) != 2 || !strings.Contains(small.queries[1], "COUNT(*) FROM alerts") {
+		t.Errorf("Expected an exact count below the threshold, got %q", small.queries)
+	}
+}
+
+func TestCountingEngineCaches(t *testing.T) {
+	ctx := context.Background()
+	file, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	if NewCountingEngine(file, CountConfig{}) != Engine(file) {
+		t.Error("Expected a zero config to leave the engine unwrapped")
+	}
+
+	// Estimates are PostgreSQL only, so the file engine counts and caches
+	engine := NewCountingEngine(file, CountConfig{EstimateAbove: 1, CacheTTL: time.Minute})
+	if err := file.Cities().Create(ctx, &City{Name: "Portland", CountryCode: "US"}); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := engine.Cities().Count(ctx); err != nil || count != 1 {
+		t.Fatalf("Expected 1 city, got %d (%v)", count, err)
+	}
+	if err := file.Cities().Create(ctx, &City{Name: "Seattle", CountryCode: "US"}); err != nil {
+		t.Fatal(err)
+	}
+	if count, _ := engine.Cities().Count(ctx); count != 1 {
+		t.Errorf("Expected the cached count within the TTL, got %d", count)
+	}
+	if count, _ := engine.Places().Count(ctx); count != 0 {
+		t.Errorf("Expected counts cached per table, got %d places", count)
+	}
+
+	fresh := WithConsistencyToken(ctx, NewConsistencyToken(time.Now()))
+	if count, _ := engine.Cities().Count(fresh); count != 2 {
+		t.Errorf("Expected a fresh count for a read after a write, got %d", count)
+	}
+}
@@ -0,0 +1,33 @@
+package repo
+
+// ForecastCursor is a keyset pagination position: the valid time and ID of the last
+// forecast of the previous page. Keyset pages are ordered by (valid_time, id), newest
+// first, so a page costs the same however deep it is, unlike OFFSET.
+type ForecastCursor struct {
+	ValidTime string
+	ID        int
+}
+
+// precedes reports whether f sorts after the cursor position, i.e. belongs to a later
+// page. A nil cursor precedes every forecast.
+func (c *ForecastCursor) precedes(f *Forecast) bool {
+	if c == nil {
+		return true
+	}
+	switch parseStoredTime(f.ValidTime).Compare(parseStoredTime(c.ValidTime)) {
+	case -1:
+		return true
+	case 0:
+		return f.ID < c.ID
+	default:
+		return false
+	}
+}
+
+// byKeysetDesc orders forecasts by (valid_time, id) descending, the keyset page order
+func byKeysetDesc(a, b *Forecast) int {
+	if c := parseStoredTime(b.ValidTime).Compare(parseStoredTime(a.ValidTime)); c != 0 {
+		return c
+	}
+	return b.ID - a.ID
+}
@@ -0,0 +1,68 @@
+package repo
+
+import (
+	"maps"
+	"slices"
+	"time"
+)
+
+// DailySummary aggregates one local day of a city's forecasts. When several forecasts
+// are valid at the same time, only the most recently issued counts, so overlapping
+// issues and providers are not summed twice.
+type DailySummary struct {
+	Date           string // YYYY-MM-DD in the requested time zone
+	Periods        int    // forecast periods aggregated
+	MinTemperature float64
+	MaxTemperature float64
+	Precipitation  float64 // mm, summed over the day
+	MaxWindSpeed   float64 // m/s
+	WeatherCode    string  // most frequent non-empty code; ties go to the lowest code
+}
+
+// summarizeDays groups forecasts by their local date in loc, one forecast per valid
+// time, oldest day first
+func summarizeDays(forecasts []*Forecast, loc *time.Location) []*DailySummary {
+	latest := make(map[int64]*Forecast)
+	for _, f := range forecasts {
+		valid := parseStoredTime(f.ValidTime).Unix()
+		if current, ok := latest[valid]; ok {
+			issued := parseStoredTime(f.ForecastTime).Compare(parseStoredTime(current.ForecastTime))
+			if issued < 0 || issued == 0 && f.ID < current.ID {
+				continue
+			}
+		}
+		latest[valid] = f
+	}
+
+	days := make(map[string]*DailySummary)
+	codes := make(map[string]map[string]int)
+	for _, f := range latest {
+		date := parseStoredTime(f.ValidTime).In(loc).Format(time.DateOnly)
+		day, ok := days[date]
+		if !ok {
+			day = &DailySummary{Date: date, MinTemperature: f.Temperature, MaxTemperature: f.Temperature}
+			days[date] = day
+			codes[date] = make(map[string]int)
+		}
+		day.Periods++
+		day.MinTemperature = min(day.MinTemperature, f.Temperature)
+		day.MaxTemperature = max(day.MaxTemperature, f.Temperature)
+		day.Precipitation += f.Precipitation
+		day.MaxWindSpeed = max(day.MaxWindSpeed, f.WindSpeed)
+		if f.WeatherCode != "" {
+			codes[date][f.WeatherCode]++
+		}
+	}
+
+	summaries := make([]*DailySummary, 0, len(days))
+	for _, date := range slices.Sorted(maps.Keys(days)) {
+		day := days[date]
+		for _, code := range slices.Sorted(maps.Keys(codes[date])) {
+			if day.WeatherCode == "" || codes[date][code] > codes[date][day.WeatherCode] {
+				day.WeatherCode = code
+			}
+		}
+		summaries = append(summaries, day)
+	}
+	return summaries
+}
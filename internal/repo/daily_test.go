@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFileForecastRepository_DailySummaries(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	forecasts := []*Forecast{
+		// Superseded by the later issue for the same valid time
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 40, Precipitation: 9, WeatherCode: "95"},
+		{CityID: 1, SourceProvider: "Met.no", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 24, Precipitation: 1, WindSpeed: 5, WeatherCode: "61"},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T18:00:00Z", Temperature: 28, Precipitation: 2.5, WindSpeed: 8, WeatherCode: "61"},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T21:00:00Z", Temperature: 22, WindSpeed: 3, WeatherCode: "1"},
+		// 04:00 UTC on the 2nd is still the 1st in Chicago
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-02T04:00:00Z", Temperature: 19, WeatherCode: "1"},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-02T15:00:00Z", Temperature: 30, WeatherCode: "0"},
+		// Outside the window or for another city
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-04T12:00:00Z", Temperature: 10},
+		{CityID: 2, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 10},
+	}
+	for _, f := range forecasts {
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	days, err := engine.Forecasts().DailySummaries(ctx, 1, "2025-08-01T05:00:00Z", "2025-08-03T05:00:00Z", "America/Chicago")
+	if err != nil {
+		t.Fatalf("DailySummaries failed: %v", err)
+	}
+	if len(days) != 2 {
+		t.Fatalf("Expected 2 days, got %+v", days)
+	}
+	first := days[0]
+	if first.Date != "2025-08-01" || first.Periods != 4 || first.MinTemperature != 19 || first.MaxTemperature != 28 {
+		t.Errorf("Unexpected first day %+v", first)
+	}
+	if first.Precipitation != 3.5 || first.MaxWindSpeed != 8 || first.WeatherCode != "1" {
+		t.Errorf("Expected 3.5 mm, 8 m/s and the lowest of the tied codes, got %+v", first)
+	}
+	if days[1].Date != "2025-08-02" || days[1].Periods != 1 || days[1].WeatherCode != "0" {
+		t.Errorf("Unexpected second day %+v", days[1])
+	}
+
+	if _, err := engine.Forecasts().DailySummaries(ctx, 1, "2025-08-01T00:00:00Z", "2025-08-02T00:00:00Z", "Mars/Olympus"); err == nil {
+		t.Error("Expected an error for an unknown time zone")
+	}
+}
@@ -0,0 +1,149 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const digestColumns = `id, user_id, city_id, url, secret, local_time, timezone, days, next_run_at,
+		   last_run_at, last_status, last_error, created_at`
+
+// PostgreSQLDigestRepository implements DigestRepository for PostgreSQL
+type PostgreSQLDigestRepository struct {
+	db DB
+}
+
+// NewPostgreSQLDigestRepository creates a new PostgreSQL digest repository
+func NewPostgreSQLDigestRepository(db DB) DigestRepository {
+	return &PostgreSQLDigestRepository{db: db}
+}
+
+// Create inserts a new digest
+func (r *PostgreSQLDigestRepository) Create(ctx context.Context, digest *Digest) error {
+	query := `
+		INSERT INTO digests (user_id, city_id, url, secret, local_time, timezone, days, next_run_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		digest.UserID, digest.CityID, digest.URL, digest.Secret, digest.LocalTime,
+		digest.Timezone, digest.Days, digest.NextRunAt, now,
+	).Scan(&digest.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create digest: %w", classify(err))
+	}
+
+	digest.CreatedAt = now
+	return nil
+}
+
+// GetByID retrieves a digest by ID
+func (r *PostgreSQLDigestRepository) GetByID(ctx context.Context, id int) (*Digest, error) {
+	query := `SELECT ` + digestColumns + ` FROM digests WHERE id = $1`
+
+	digest, err := scanDigest(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("digest with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get digest: %w", err)
+	}
+
+	return digest, nil
+}
+
+// ListByUser retrieves a user's digests, newest first
+func (r *PostgreSQLDigestRepository) ListByUser(ctx context.Context, userID int) ([]*Digest, error) {
+	query := `SELECT ` + digestColumns + ` FROM digests WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+	return r.list(ctx, query, userID)
+}
+
+// ListDue retrieves digests whose next run is at or before now, oldest first
+func (r *PostgreSQLDigestRepository) ListDue(ctx context.Context, now string, limit int) ([]*Digest, error) {
+	query := `SELECT ` + digestColumns + ` FROM digests WHERE next_run_at <= $1 ORDER BY next_run_at, id LIMIT $2`
+	return r.list(ctx, query, now, limit)
+}
+
+func (r *PostgreSQLDigestRepository) list(ctx context.Context, query string, args ...any) ([]*Digest, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list digests: %w", err)
+	}
+	defer rows.Close()
+
+	var digests []*Digest
+	for rows.Next() {
+		digest, err := scanDigest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan digest: %w", err)
+		}
+		digests = append(digests, digest)
+	}
+
+	return digests, rows.Err()
+}
+
+// RecordRun stores the outcome of a delivery
+func (r *PostgreSQLDigestRepository) RecordRun(ctx context.Context, digest *Digest) error {
+	query := `
+		UPDATE digests SET next_run_at = $2, last_run_at = NULLIF($3, '')::timestamptz, last_status = $4, last_error = $5
+		WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query,
+		digest.ID, digest.NextRunAt, digest.LastRunAt, digest.LastStatus, digest.LastError,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record digest run: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("digest with id %d not found", digest.ID)
+	}
+
+	return nil
+}
+
+// Delete removes a digest
+func (r *PostgreSQLDigestRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM digests WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete digest: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("digest with id %d not found", id)
+	}
+
+	return nil
+}
+
+// scanDigest scans a single digest row, mapping a NULL last run time to an empty string
+func scanDigest(row rowScanner) (*Digest, error) {
+	digest := &Digest{}
+	var lastRunAt sql.NullString
+	err := row.Scan(
+		&digest.ID, &digest.UserID, &digest.CityID, &digest.URL, &digest.Secret,
+		&digest.LocalTime, &digest.Timezone, &digest.Days, &digest.NextRunAt,
+		&lastRunAt, &digest.LastStatus, &digest.LastError, &digest.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	digest.LastRunAt = lastRunAt.String
+	return digest, nil
+}
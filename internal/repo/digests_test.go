@@ -0,0 +1,99 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDigestRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ DigestRepository = (*PostgreSQLDigestRepository)(nil)
+
+		if NewPostgreSQLDigestRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLDigestRepository returned nil")
+		}
+	})
+
+	t.Run("RecordRun", func(t *testing.T) {
+		repo := NewPostgreSQLDigestRepository(&MockDB{})
+		digest := &Digest{ID: 1, NextRunAt: "2025-08-14T11:00:00Z", LastStatus: "delivered"}
+		if err := repo.RecordRun(context.Background(), digest); err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+
+		repo = NewPostgreSQLDigestRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if err := repo.RecordRun(context.Background(), digest); err == nil {
+			t.Error("Expected error from RecordRun, got nil")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := NewPostgreSQLDigestRepository(&MockDB{})
+		if err := repo.Delete(context.Background(), 1); err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+	})
+}
+
+func TestFileDigestRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	digests := []*Digest{
+		{UserID: 1, CityID: 1, URL: "https://example.com/a", LocalTime: "06:00", Timezone: "UTC", Days: 7, NextRunAt: "2025-08-13T06:00:00Z"},
+		{UserID: 1, CityID: 2, URL: "https://example.com/b", LocalTime: "05:00", Timezone: "UTC", Days: 3, NextRunAt: "2025-08-13T05:00:00Z"},
+		{UserID: 2, CityID: 1, URL: "https://example.com/c", LocalTime: "09:00", Timezone: "UTC", Days: 1, NextRunAt: "2025-08-13T09:00:00Z"},
+	}
+	for _, digest := range digests {
+		if err := engine.Digests().Create(ctx, digest); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	listed, err := engine.Digests().ListByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	if len(listed) != 2 || listed[0].ID != digests[1].ID {
+		t.Errorf("Expected user 1's 2 digests newest first, got %+v", listed)
+	}
+
+	due, err := engine.Digests().ListDue(ctx, "2025-08-13T06:00:00Z", 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 2 || due[0].ID != digests[1].ID || due[1].ID != digests[0].ID {
+		t.Errorf("Expected the 05:00 and 06:00 digests in order, got %+v", due)
+	}
+	if due, _ := engine.Digests().ListDue(ctx, "2025-08-13T23:00:00Z", 1); len(due) != 1 {
+		t.Errorf("Expected the limit to apply, got %d digests", len(due))
+	}
+
+	run := *digests[1]
+	run.NextRunAt, run.LastRunAt, run.LastStatus, run.LastError = "2025-08-14T05:00:00Z", "2025-08-13T05:00:02Z", "failed", "timeout"
+	if err := engine.Digests().RecordRun(ctx, &run); err != nil {
+		t.Fatalf("RecordRun failed: %v", err)
+	}
+	stored, err := engine.Digests().GetByID(ctx, run.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if stored.NextRunAt != run.NextRunAt || stored.LastStatus != "failed" || stored.LastError != "timeout" {
+		t.Errorf("Expected the run to be recorded, got %+v", stored)
+	}
+
+	if err := engine.Digests().Delete(ctx, run.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := engine.Digests().GetByID(ctx, run.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+	if err := engine.Digests().Delete(ctx, run.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound deleting twice, got %v", err)
+	}
+}
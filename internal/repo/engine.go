@@ -0,0 +1,178 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Storage engine names accepted by OpenEngine and the WEATHER_API_STORAGE_ENGINE setting
+const (
+	EnginePostgres = "postgres"
+	EngineFile     = "file"
+)
+
+// Engine bundles the repositories of a single storage backend so the rest of the
+// application can be wired without knowing whether data lives in PostgreSQL or in
+// an embedded store
+type Engine interface {
+	// Name returns the engine name (EnginePostgres, EngineFile)
+	Name() string
+
+	Forecasts() ForecastRepository
+	Cities() CityRepository
+	Places() PlaceRepository
+	Alerts() AlertRepository
+	Aviation() AviationReportRepository
+	Stations() StationRepository
+	Observations() ObservationRepository
+	AirQuality() AirQualityRepository
+	Shares() ShareLinkRepository
+	Digests() DigestRepository
+	UserLocations() UserLocationRepository
+	AlertSubscriptions() AlertSubscriptionRepository
+	Users() UserRepository
+	JobRuns() JobRunRepository
+
+	// Reset deletes every city, place, forecast (including archives), alert, aviation
+	// report, station, observation, air quality reading, share link, digest, saved
+	// location and alert subscription and restarts their IDs. Users and job run history
+	// are kept.
+	Reset(ctx context.Context) error
+
+	// Ping checks that the backend can serve queries
+	Ping(ctx context.Context) error
+
+	// Close releases the underlying connection or file
+	Close() error
+}
+
+// PostgreSQLEngine implements Engine on top of a PostgreSQL connection
+type PostgreSQLEngine struct {
+	db                 DB
+	forecasts          ForecastRepository
+	cities             CityRepository
+	places             PlaceRepository
+	alerts             AlertRepository
+	aviation           AviationReportRepository
+	stations           StationRepository
+	observations       ObservationRepository
+	airQuality         AirQualityRepository
+	shares             ShareLinkRepository
+	digests            DigestRepository
+	userLocations      UserLocationRepository
+	alertSubscriptions AlertSubscriptionRepository
+	users              UserRepository
+	jobRuns            JobRunRepository
+}
+
+// NewPostgreSQLEngine creates an engine whose repositories share db. Forecast reads
+// include rows moved to forecast_archives.
+// Close closes db when it implements io.Closer (e.g. *sql.DB).
+func NewPostgreSQLEngine(db DB) Engine {
+	return &PostgreSQLEngine{
+		db:                 db,
+		forecasts:          NewArchivedForecastRepository(NewPostgreSQLForecastRepository(db), NewPostgreSQLForecastArchive(db)),
+		cities:             NewPostgreSQLCityRepository(db),
+		places:             NewPostgreSQLPlaceRepository(db),
+		alerts:             NewPostgreSQLAlertRepository(db),
+		aviation:           NewPostgreSQLAviationReportRepository(db),
+		stations:           NewPostgreSQLStationRepository(db),
+		observations:       NewPostgreSQLObservationRepository(db),
+		airQuality:         NewPostgreSQLAirQualityRepository(db),
+		shares:             NewPostgreSQLShareLinkRepository(db),
+		digests:            NewPostgreSQLDigestRepository(db),
+		userLocations:      NewPostgreSQLUserLocationRepository(db),
+		alertSubscriptions: NewPostgreSQLAlertSubscriptionRepository(db),
+		users:              NewPostgreSQLUserRepository(db),
+		jobRuns:            NewPostgreSQLJobRunRepository(db),
+	}
+}
+
+// Name returns EnginePostgres
+func (e *PostgreSQLEngine) Name() string { return EnginePostgres }
+
+// Forecasts returns the forecast repository
+func (e *PostgreSQLEngine) Forecasts() ForecastRepository { return e.forecasts }
+
+// Cities returns the city repository
+func (e *PostgreSQLEngine) Cities() CityRepository { return e.cities }
+
+// Places returns the place repository
+func (e *PostgreSQLEngine) Places() PlaceRepository { return e.places }
+
+// Alerts returns the alert repository
+func (e *PostgreSQLEngine) Alerts() AlertRepository { return e.alerts }
+
+// Aviation returns the METAR/TAF repository
+func (e *PostgreSQLEngine) Aviation() AviationReportRepository { return e.aviation }
+
+// Stations returns the weather station repository
+func (e *PostgreSQLEngine) Stations() StationRepository { return e.stations }
+
+// Observations returns the station observation repository
+func (e *PostgreSQLEngine) Observations() ObservationRepository { return e.observations }
+
+// AirQuality returns the air quality repository
+func (e *PostgreSQLEngine) AirQuality() AirQualityRepository { return e.airQuality }
+
+// Shares returns the share link repository
+func (e *PostgreSQLEngine) Shares() ShareLinkRepository { return e.shares }
+
+// Digests returns the forecast digest repository
+func (e *PostgreSQLEngine) Digests() DigestRepository { return e.digests }
+
+// UserLocations returns the saved location repository
+func (e *PostgreSQLEngine) UserLocations() UserLocationRepository { return e.userLocations }
+
+// AlertSubscriptions returns the alert subscription repository
+func (e *PostgreSQLEngine) AlertSubscriptions() AlertSubscriptionRepository {
+	return e.alertSubscriptions
+}
+
+// Users returns the user account repository
+func (e *PostgreSQLEngine) Users() UserRepository { return e.users }
+
+// JobRuns returns the job run history repository
+func (e *PostgreSQLEngine) JobRuns() JobRunRepository { return e.jobRuns }
+
+// Reset truncates the data tables in one statement
+func (e *PostgreSQLEngine) Reset(ctx context.Context) error {
+	_, err := e.db.ExecContext(ctx, `TRUNCATE forecasts, forecast_archives, alerts, aviation_reports,
+		observations, stations, air_quality, share_links, digests, user_locations, alert_notifications,
+		alert_subscriptions, city_names, places, cities RESTART IDENTITY CASCADE`)
+	if err != nil {
+		return fmt.Errorf("failed to reset tables: %w", err)
+	}
+	return nil
+}
+
+// Ping runs a trivial query, so it checks the connection pool and the server
+func (e *PostgreSQLEngine) Ping(ctx context.Context) error {
+	var one int
+	if err := e.db.QueryRowContext(ctx, "SELECT 1").Scan(&one); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database handle
+func (e *PostgreSQLEngine) Close() error {
+	if closer, ok := e.db.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// ParseEngineName normalizes a configured engine name, defaulting to EnginePostgres
+func ParseEngineName(name string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", EnginePostgres:
+		return EnginePostgres, nil
+	case EngineFile:
+		return EngineFile, nil
+	default:
+		return "", fmt.Errorf("unknown storage engine %q (supported: %s, %s)", name, EnginePostgres, EngineFile)
+	}
+}
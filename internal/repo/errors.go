@@ -0,0 +1,82 @@
+package repo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// Kinds of repository failure, matched with errors.Is. Repositories keep their
+// descriptive messages ("city with id 7 not found") and wrap one of these, so callers
+// can tell a missing row from a database failure.
+var (
+	// ErrNotFound means the requested or targeted record does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrDuplicate means a record with the same unique key already exists
+	ErrDuplicate = errors.New("duplicate record")
+	// ErrConstraint means a write broke a constraint other than uniqueness, such as a
+	// reference to a missing city or a value outside a column's check
+	ErrConstraint = errors.New("constraint violation")
+)
+
+// kindError is an error of one of the kinds above with its own message
+type kindError struct {
+	kind    error
+	message string
+	cause   error
+}
+
+func (e *kindError) Error() string { return e.message }
+
+func (e *kindError) Unwrap() []error {
+	if e.cause == nil {
+		return []error{e.kind}
+	}
+	return []error{e.kind, e.cause}
+}
+
+// notFound formats an ErrNotFound error
+func notFound(format string, args ...any) error {
+	return &kindError{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+// duplicate formats an ErrDuplicate error
+func duplicate(format string, args ...any) error {
+	return &kindError{kind: ErrDuplicate, message: fmt.Sprintf(format, args...)}
+}
+
+// PostgreSQL error codes mapped to kinds; see
+// https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+	pgExclusionViolation  = "23P01"
+)
+
+// classify marks PostgreSQL integrity violations in err with ErrDuplicate or
+// ErrConstraint, keeping the driver error in the chain. Other errors are returned
+// unchanged.
+func classify(err error) error {
+	var code string
+	var pgErr *pgconn.PgError
+	var pqErr *pq.Error
+	switch {
+	case errors.As(err, &pgErr):
+		code = pgErr.Code
+	case errors.As(err, &pqErr):
+		code = string(pqErr.Code)
+	default:
+		return err
+	}
+	switch code {
+	case pgUniqueViolation, pgExclusionViolation:
+		return &kindError{kind: ErrDuplicate, message: err.Error(), cause: err}
+	case pgNotNullViolation, pgForeignKeyViolation, pgCheckViolation:
+		return &kindError{kind: ErrConstraint, message: err.Error(), cause: err}
+	}
+	return err
+}
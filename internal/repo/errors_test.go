@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+func TestErrorKinds(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Missing records are ErrNotFound", func(t *testing.T) {
+		engine, err := OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		defer engine.Close()
+
+		_, err = engine.Cities().GetByID(ctx, 42)
+		if !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+		if err.Error() != "city with id 42 not found" {
+			t.Errorf("Expected the record in the message, got %q", err.Error())
+		}
+		if err := engine.Forecasts().Delete(ctx, 42); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound deleting a missing forecast, got %v", err)
+		}
+	})
+
+	t.Run("Duplicate geoname IDs are ErrDuplicate", func(t *testing.T) {
+		engine, err := OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		defer engine.Close()
+
+		if err := engine.Cities().Create(ctx, &City{Name: "Paris", CountryCode: "FR", GeonameID: 2988507}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		err = engine.Cities().Create(ctx, &City{Name: "Paris", CountryCode: "FR", GeonameID: 2988507})
+		if !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Expected ErrDuplicate, got %v", err)
+		}
+	})
+
+	t.Run("Classifies PostgreSQL violations", func(t *testing.T) {
+		tests := []struct {
+			code pq.ErrorCode
+			want error
+		}{
+			{"23505", ErrDuplicate},
+			{"23503", ErrConstraint},
+			{"23502", ErrConstraint},
+			{"23514", ErrConstraint},
+		}
+		for _, tt := range tests {
+			cause := &pq.Error{Code: tt.code, Message: "violation"}
+			err := fmt.Errorf("failed to create city: %w", classify(cause))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v for %s, got %v", tt.want, tt.code, err)
+			}
+			var pqErr *pq.Error
+			if !errors.As(err, &pqErr) {
+				t.Errorf("Expected the driver error to remain in the chain for %s", tt.code)
+			}
+		}
+
+		other := &pq.Error{Code: "57014", Message: "canceling statement"}
+		if err := classify(other); errors.Is(err, ErrConstraint) || errors.Is(err, ErrDuplicate) {
+			t.Errorf("Expected other errors unchanged, got %v", err)
+		}
+	})
+
+	t.Run("Classifies pgx violations", func(t *testing.T) {
+		cause := &pgconn.PgError{Code: "23505", Message: "duplicate key value"}
+		err := fmt.Errorf("failed to create city: %w", classify(cause))
+		if !errors.Is(err, ErrDuplicate) {
+			t.Errorf("Expected ErrDuplicate, got %v", err)
+		}
+		var pgErr *pgconn.PgError
+		if !errors.As(err, &pgErr) {
+			t.Error("Expected the driver error to remain in the chain")
+		}
+		if err := classify(&pgconn.PgError{Code: "57014"}); errors.Is(err, ErrConstraint) || errors.Is(err, ErrDuplicate) {
+			t.Errorf("Expected other errors unchanged, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,2325 @@
+package repo
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+)
+
+// FileEngine is an embedded Engine for single-binary deployments (edge devices, kiosks)
+// that cannot run PostgreSQL.
+//
+// All records are held in memory and kept in a bbolt database, one bucket per table
+// and one JSON value per row. Each successful write commits only the rows it changed
+// in one bbolt transaction, so a crash leaves either the old or the new rows and never
+// a partial file. Queries are full scans, which is fine for the few thousand rows such
+// deployments hold but not for a full GeoNames import.
+type FileEngine struct {
+	mu   sync.RWMutex
+	db   *bolt.DB // nil when the dataset is kept in memory only
+	data fileData
+}
+
+// fileData is the dataset of a FileEngine. Each table is a bucket named by its JSON
+// tag; city names are kept per city in the city_names bucket.
+type fileData struct {
+	Forecasts     fileTable[Forecast]          `json:"forecasts"`
+	Cities        fileTable[City]              `json:"cities"`
+	CityNames     []*CityName                  `json:"city_names"`
+	Places        fileTable[Place]             `json:"places"`
+	Alerts        fileTable[Alert]             `json:"alerts"`
+	Aviation      fileTable[AviationReport]    `json:"aviation_reports"`
+	Stations      fileTable[Station]           `json:"stations"`
+	Observations  fileTable[Observation]       `json:"observations"`
+	AirQuality    fileTable[AirQuality]        `json:"air_quality"`
+	Shares        fileTable[ShareLink]         `json:"share_links"`
+	Digests       fileTable[Digest]            `json:"digests"`
+	UserLocations fileTable[UserLocation]      `json:"user_locations"`
+	Subscriptions fileTable[AlertSubscription] `json:"alert_subscriptions"`
+	Notifications fileTable[alertNotification] `json:"alert_notifications"`
+	Users         fileTable[User]              `json:"users"`
+	JobRuns       fileTable[JobRun]            `json:"job_runs"`
+
+	namesChanged map[int]bool // cities whose names changed since the last commit
+	rewrite      bool         // drop every bucket and write the whole dataset on commit
+}
+
+// touchNames marks the names of a city as changed
+func (d *fileData) touchNames(cityID int) {
+	if d.namesChanged == nil {
+		d.namesChanged = make(map[int]bool)
+	}
+	d.namesChanged[cityID] = true
+}
+
+// fileBucket is a table as stored in its bucket
+type fileBucket interface {
+	flush(b *bolt.Bucket) error
+	load(b *bolt.Bucket) error
+	touchAll()
+	committed()
+}
+
+// tables returns the tables of d by bucket name
+func (d *fileData) tables() []struct {
+	name  string
+	table fileBucket
+} {
+	return []struct {
+		name  string
+		table fileBucket
+	}{
+		{"forecasts", &d.Forecasts},
+		{"cities", &d.Cities},
+		{"places", &d.Places},
+		{"alerts", &d.Alerts},
+		{"aviation_reports", &d.Aviation},
+		{"stations", &d.Stations},
+		{"observations", &d.Observations},
+		{"air_quality", &d.AirQuality},
+		{"share_links", &d.Shares},
+		{"digests", &d.Digests},
+		{"user_locations", &d.UserLocations},
+		{"alert_subscriptions", &d.Subscriptions},
+		{"alert_notifications", &d.Notifications},
+		{"users", &d.Users},
+		{"job_runs", &d.JobRuns},
+	}
+}
+
+// cityNamesBucket holds the names of each city as one JSON array keyed by city ID
+const cityNamesBucket = "city_names"
+
+// alertNotification is an alert queued for a subscription
+type alertNotification struct {
+	SubscriptionID int    `json:"subscription_id"`
+	AlertID        int    `json:"alert_id"`
+	SentAt         string `json:"sent_at"`
+}
+
+// fileTable stores rows by ID along with the last assigned ID. Rows changed in place
+// must be marked with touch so the next commit stores them; put and remove mark them
+// already.
+type fileTable[T any] struct {
+	Seq  int        `json:"seq"`
+	Rows map[int]*T `json:"rows"`
+
+	changed map[int]bool // rows put, removed or touched since the last commit
+}
+
+// touch marks a row as changed
+func (t *fileTable[T]) touch(id int) {
+	if t.changed == nil {
+		t.changed = make(map[int]bool)
+	}
+	t.changed[id] = true
+}
+
+func (t *fileTable[T]) next() int {
+	t.Seq++
+	return t.Seq
+}
+
+func (t *fileTable[T]) get(id int) (*T, bool) {
+	row, ok := t.Rows[id]
+	if !ok {
+		return nil, false
+	}
+	c := *row
+	return &c, true
+}
+
+func (t *fileTable[T]) put(id int, row *T) {
+	if t.Rows == nil {
+		t.Rows = make(map[int]*T)
+	}
+	c := *row
+	t.Rows[id] = &c
+	t.touch(id)
+}
+
+func (t *fileTable[T]) remove(id int) bool {
+	if _, ok := t.Rows[id]; !ok {
+		return false
+	}
+	delete(t.Rows, id)
+	t.touch(id)
+	return true
+}
+
+// flush writes the changed rows and the ID sequence to b
+func (t *fileTable[T]) flush(b *bolt.Bucket) error {
+	for id := range t.changed {
+		row, ok := t.Rows[id]
+		if !ok {
+			if err := b.Delete(rowKey(id)); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(rowKey(id), data); err != nil {
+			return err
+		}
+	}
+	if b.Sequence() == uint64(t.Seq) {
+		return nil
+	}
+	return b.SetSequence(uint64(t.Seq))
+}
+
+// load reads the rows and ID sequence of b
+func (t *fileTable[T]) load(b *bolt.Bucket) error {
+	t.Seq = int(b.Sequence())
+	t.Rows = make(map[int]*T)
+	return b.ForEach(func(key, value []byte) error {
+		row := new(T)
+		if err := json.Unmarshal(value, row); err != nil {
+			return fmt.Errorf("row %d: %w", binary.BigEndian.Uint64(key), err)
+		}
+		t.Rows[int(binary.BigEndian.Uint64(key))] = row
+		return nil
+	})
+}
+
+// touchAll marks every row as changed
+func (t *fileTable[T]) touchAll() {
+	for id := range t.Rows {
+		t.touch(id)
+	}
+}
+
+// committed forgets the changes once they are stored
+func (t *fileTable[T]) committed() {
+	clear(t.changed)
+}
+
+// rowKey is the bucket key of a row ID, big endian so keys sort by ID
+func rowKey(id int) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(id))
+}
+
+// filter returns copies of the rows accepted by keep, in ID order
+func (t *fileTable[T]) filter(keep func(*T) bool) []*T {
+	ids := make([]int, 0, len(t.Rows))
+	for id := range t.Rows {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var rows []*T
+	for _, id := range ids {
+		if keep == nil || keep(t.Rows[id]) {
+			c := *t.Rows[id]
+			rows = append(rows, &c)
+		}
+	}
+	return rows
+}
+
+// OpenFileEngine opens the bbolt database at path, creating it if needed, and loads
+// the dataset. A JSON dataset written by earlier releases is converted in place and
+// kept as <path>.json.bak. An empty path keeps everything in memory, which is useful
+// for tests and demos.
+func OpenFileEngine(path string) (*FileEngine, error) {
+	e := &FileEngine{}
+	if path == "" {
+		return e, nil
+	}
+
+	legacy, err := readLegacyDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	if legacy != nil {
+		if err := os.Rename(path, path+".json.bak"); err != nil {
+			return nil, fmt.Errorf("failed to move aside storage file %s: %w", path, err)
+		}
+	}
+
+	// The file is locked while open; a second process waits a moment, then fails
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage file %s: %w", path, err)
+	}
+	e.db = db
+
+	if legacy != nil {
+		e.data = *legacy
+		e.data.rewrite = true
+		err = e.persist()
+	} else {
+		err = db.View(func(tx *bolt.Tx) error { return loadFileData(tx, &e.data) })
+	}
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to load storage file %s: %w", path, err)
+	}
+	return e, nil
+}
+
+// readLegacyDataset decodes the JSON file of earlier releases at path. It returns nil
+// when there is no file or it is not JSON.
+func readLegacyDataset(path string) (*fileData, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage file: %w", err)
+	}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, nil
+	}
+	var legacy fileData
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return nil, fmt.Errorf("failed to decode storage file %s: %w", path, err)
+	}
+	return &legacy, nil
+}
+
+// loadFileData reads every table and the city names from tx
+func loadFileData(tx *bolt.Tx, d *fileData) error {
+	for _, t := range d.tables() {
+		if b := tx.Bucket([]byte(t.name)); b != nil {
+			if err := t.table.load(b); err != nil {
+				return fmt.Errorf("%s: %w", t.name, err)
+			}
+		}
+	}
+	b := tx.Bucket([]byte(cityNamesBucket))
+	if b == nil {
+		return nil
+	}
+	return b.ForEach(func(key, value []byte) error {
+		var names []*CityName
+		if err := json.Unmarshal(value, &names); err != nil {
+			return fmt.Errorf("%s: city %d: %w", cityNamesBucket, binary.BigEndian.Uint64(key), err)
+		}
+		d.CityNames = append(d.CityNames, names...)
+		return nil
+	})
+}
+
+// Name returns EngineFile
+func (e *FileEngine) Name() string { return EngineFile }
+
+// Forecasts returns the forecast repository
+func (e *FileEngine) Forecasts() ForecastRepository { return &fileForecastRepository{e: e} }
+
+// Cities returns the city repository
+func (e *FileEngine) Cities() CityRepository { return &fileCityRepository{e: e} }
+
+// Places returns the place repository
+func (e *FileEngine) Places() PlaceRepository { return &filePlaceRepository{e: e} }
+
+// Alerts returns the alert repository
+func (e *FileEngine) Alerts() AlertRepository { return &fileAlertRepository{e: e} }
+
+// Aviation returns the METAR/TAF repository
+func (e *FileEngine) Aviation() AviationReportRepository { return &fileAviationReportRepository{e: e} }
+
+// Stations returns the weather station repository
+func (e *FileEngine) Stations() StationRepository { return &fileStationRepository{e: e} }
+
+// Observations returns the station observation repository
+func (e *FileEngine) Observations() ObservationRepository { return &fileObservationRepository{e: e} }
+
+// AirQuality returns the air quality repository
+func (e *FileEngine) AirQuality() AirQualityRepository { return &fileAirQualityRepository{e: e} }
+
+// Shares returns the share link repository
+func (e *FileEngine) Shares() ShareLinkRepository { return &fileShareLinkRepository{e: e} }
+
+// Digests returns the forecast digest repository
+func (e *FileEngine) Digests() DigestRepository { return &fileDigestRepository{e: e} }
+
+// UserLocations returns the saved location repository
+func (e *FileEngine) UserLocations() UserLocationRepository { return &fileUserLocationRepository{e: e} }
+
+// AlertSubscriptions returns the alert subscription repository
+func (e *FileEngine) AlertSubscriptions() AlertSubscriptionRepository {
+	return &fileAlertSubscriptionRepository{e: e}
+}
+
+// Users returns the user account repository
+func (e *FileEngine) Users() UserRepository { return &fileUserRepository{e: e} }
+
+// JobRuns returns the job run history repository
+func (e *FileEngine) JobRuns() JobRunRepository { return &fileJobRunRepository{e: e} }
+
+// Reset empties the dataset, keeping users and job run history
+func (e *FileEngine) Reset(ctx context.Context) error {
+	return e.write(func(d *fileData) error {
+		*d = fileData{Users: d.Users, JobRuns: d.JobRuns, rewrite: true}
+		return nil
+	})
+}
+
+// Ping always succeeds: the dataset is held in memory
+func (e *FileEngine) Ping(ctx context.Context) error { return nil }
+
+// Close stores any changes a failed commit left behind and closes the database
+func (e *FileEngine) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.db == nil {
+		return nil
+	}
+	err := e.persist()
+	if closeErr := e.db.Close(); err == nil {
+		err = closeErr
+	}
+	e.db = nil
+	return err
+}
+
+// read runs fn under the read lock
+func (e *FileEngine) read(fn func(d *fileData) error) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return fn(&e.data)
+}
+
+// write runs fn under the write lock and commits the rows it changed if fn succeeds
+func (e *FileEngine) write(fn func(d *fileData) error) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := fn(&e.data); err != nil {
+		return err
+	}
+	return e.persist()
+}
+
+// persist commits the changed rows in one transaction. Changes stay marked when the
+// commit fails, so the next write retries them.
+func (e *FileEngine) persist() error {
+	d := &e.data
+	if e.db == nil {
+		for _, t := range d.tables() {
+			t.table.committed()
+		}
+		clear(d.namesChanged)
+		d.rewrite = false
+		return nil
+	}
+
+	err := e.db.Update(func(tx *bolt.Tx) error {
+		if d.rewrite {
+			for _, t := range d.tables() {
+				if err := tx.DeleteBucket([]byte(t.name)); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+					return err
+				}
+				t.table.touchAll()
+			}
+			if err := tx.DeleteBucket([]byte(cityNamesBucket)); err != nil && !errors.Is(err, bolt.ErrBucketNotFound) {
+				return err
+			}
+			for _, n := range d.CityNames {
+				d.touchNames(n.CityID)
+			}
+		}
+		for _, t := range d.tables() {
+			b, err := tx.CreateBucketIfNotExists([]byte(t.name))
+			if err != nil {
+				return err
+			}
+			if err := t.table.flush(b); err != nil {
+				return fmt.Errorf("%s: %w", t.name, err)
+			}
+		}
+		return flushCityNames(tx, d)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write storage file: %w", err)
+	}
+	for _, t := range d.tables() {
+		t.table.committed()
+	}
+	clear(d.namesChanged)
+	d.rewrite = false
+	return nil
+}
+
+// flushCityNames writes the names of the cities whose names changed
+func flushCityNames(tx *bolt.Tx, d *fileData) error {
+	if len(d.namesChanged) == 0 {
+		return nil
+	}
+	b, err := tx.CreateBucketIfNotExists([]byte(cityNamesBucket))
+	if err != nil {
+		return err
+	}
+	byCity := make(map[int][]*CityName, len(d.namesChanged))
+	for _, n := range d.CityNames {
+		if d.namesChanged[n.CityID] {
+			byCity[n.CityID] = append(byCity[n.CityID], n)
+		}
+	}
+	for cityID := range d.namesChanged {
+		names, ok := byCity[cityID]
+		if !ok {
+			if err := b.Delete(rowKey(cityID)); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := json.Marshal(names)
+		if err != nil {
+			return err
+		}
+		if err := b.Put(rowKey(cityID), data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fileForecastRepository implements ForecastRepository for a FileEngine
+type fileForecastRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new forecast record
+func (r *fileForecastRepository) Create(ctx context.Context, forecast *Forecast) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		forecast.ID = d.Forecasts.next()
+		forecast.CreatedAt = now
+		forecast.UpdatedAt = now
+		d.Forecasts.put(forecast.ID, forecast)
+		return nil
+	})
+}
+
+// CreateBatch inserts forecasts in a single write
+func (r *fileForecastRepository) CreateBatch(ctx context.Context, forecasts []*Forecast) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		for _, forecast := range forecasts {
+			forecast.ID = d.Forecasts.next()
+			forecast.CreatedAt = now
+			forecast.UpdatedAt = now
+			d.Forecasts.put(forecast.ID, forecast)
+		}
+		return nil
+	})
+}
+
+// GetByID retrieves a forecast by its ID
+func (r *fileForecastRepository) GetByID(ctx context.Context, id int) (*Forecast, error) {
+	var forecast *Forecast
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if forecast, ok = d.Forecasts.get(id); !ok {
+			return notFound("forecast with id %d not found", id)
+		}
+		return nil
+	})
+	return forecast, err
+}
+
+// Update modifies an existing forecast record
+func (r *fileForecastRepository) Update(ctx context.Context, forecast *Forecast) error {
+	return r.e.write(func(d *fileData) error {
+		existing, ok := d.Forecasts.get(forecast.ID)
+		if !ok {
+			return notFound("forecast with id %d not found", forecast.ID)
+		}
+		forecast.CreatedAt = existing.CreatedAt
+		forecast.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Forecasts.put(forecast.ID, forecast)
+		return nil
+	})
+}
+
+// Delete removes a forecast record by its ID
+func (r *fileForecastRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Forecasts.remove(id) {
+			return notFound("forecast with id %d not found", id)
+		}
+		return nil
+	})
+}
+
+// List retrieves forecasts with pagination, newest first
+func (r *fileForecastRepository) List(ctx context.Context, limit, offset int) ([]*Forecast, error) {
+	return r.query(nil, byTimeDesc(func(f *Forecast) string { return f.CreatedAt }), limit, offset)
+}
+
+// ListWithCities retrieves forecasts with their cities, read under one lock
+func (r *fileForecastRepository) ListWithCities(ctx context.Context, limit, offset int) ([]*CityForecast, error) {
+	var forecasts []*CityForecast
+	err := r.e.read(func(d *fileData) error {
+		rows := d.Forecasts.filter(nil)
+		slices.SortStableFunc(rows, byTimeDesc(func(f *Forecast) string { return f.CreatedAt }))
+		for _, f := range paginate(rows, limit, offset) {
+			forecast := &CityForecast{Forecast: *f}
+			if city, ok := d.Cities.get(f.CityID); ok {
+				forecast.City = ForecastCity{Name: city.Name, CountryCode: city.CountryCode, Timezone: city.Timezone}
+			}
+			forecasts = append(forecasts, forecast)
+		}
+		return nil
+	})
+	return forecasts, err
+}
+
+// GetCities retrieves the cities with cityIDs, read under one lock
+func (r *fileForecastRepository) GetCities(ctx context.Context, cityIDs []int) (map[int]ForecastCity, error) {
+	cities := make(map[int]ForecastCity, len(cityIDs))
+	err := r.e.read(func(d *fileData) error {
+		for _, id := range cityIDs {
+			if city, ok := d.Cities.get(id); ok {
+				cities[id] = ForecastCity{Name: city.Name, CountryCode: city.CountryCode, Timezone: city.Timezone}
+			}
+		}
+		return nil
+	})
+	return cities, err
+}
+
+// Count returns the total number of forecast records
+func (r *fileForecastRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.e.read(func(d *fileData) error {
+		count = len(d.Forecasts.Rows)
+		return nil
+	})
+	return count, err
+}
+
+// GetByCityID retrieves forecasts for a specific city
+func (r *fileForecastRepository) GetByCityID(ctx context.Context, cityID int, limit, offset int) ([]*Forecast, error) {
+	return r.query(
+		func(f *Forecast) bool { return f.CityID == cityID },
+		byTimeDesc(func(f *Forecast) string { return f.ValidTime }),
+		limit, offset,
+	)
+}
+
+// ListAfter retrieves up to limit forecasts in (valid_time, id) descending order after cursor
+func (r *fileForecastRepository) ListAfter(ctx context.Context, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	return r.query(cursor.precedes, byKeysetDesc, limit, 0)
+}
+
+// GetByCityIDAfter retrieves up to limit of a city's forecasts in (valid_time, id)
+// descending order after cursor
+func (r *fileForecastRepository) GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	return r.query(
+		func(f *Forecast) bool { return f.CityID == cityID && cursor.precedes(f) },
+		byKeysetDesc, limit, 0,
+	)
+}
+
+// GetByTimeRange retrieves forecasts within a time range in (valid_time, id)
+// descending order
+func (r *fileForecastRepository) GetByTimeRange(ctx context.Context, startTime, endTime string, limit, offset int) ([]*Forecast, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecasts by time range: invalid start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecasts by time range: invalid end time: %w", err)
+	}
+
+	return r.query(validWithin(start, end), byKeysetDesc, limit, offset)
+}
+
+// GetByTimeRangeAfter retrieves up to limit forecasts valid within a time range in
+// (valid_time, id) descending order after cursor
+func (r *fileForecastRepository) GetByTimeRangeAfter(ctx context.Context, startTime, endTime string, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to page forecasts by time range: invalid start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to page forecasts by time range: invalid end time: %w", err)
+	}
+
+	within := validWithin(start, end)
+	return r.query(func(f *Forecast) bool { return within(f) && cursor.precedes(f) }, byKeysetDesc, limit, 0)
+}
+
+// validWithin matches the forecasts valid within [start, end]
+func validWithin(start, end time.Time) func(*Forecast) bool {
+	return func(f *Forecast) bool {
+		valid := parseStoredTime(f.ValidTime)
+		return !valid.IsZero() && !valid.Before(start) && !valid.After(end)
+	}
+}
+
+// GetLatestByCityID retrieves the most recent forecast for a city
+func (r *fileForecastRepository) GetLatestByCityID(ctx context.Context, cityID int) (*Forecast, error) {
+	forecasts, err := r.GetByCityID(ctx, cityID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(forecasts) == 0 {
+		return nil, notFound("no forecasts found for city %d", cityID)
+	}
+	return forecasts[0], nil
+}
+
+// DeleteOldForecasts removes the forecasts matching filter that were valid more than
+// days ago
+func (r *fileForecastRepository) DeleteOldForecasts(ctx context.Context, days int, filter ForecastFilter) (int64, error) {
+	if days < 0 {
+		return 0, fmt.Errorf("retention days must not be negative, got %d", days)
+	}
+	cutoff := time.Now().AddDate(0, 0, -days)
+	var deleted int64
+	err := r.e.write(func(d *fileData) error {
+		for id, f := range d.Forecasts.Rows {
+			if valid := parseStoredTime(f.ValidTime); !valid.IsZero() && valid.Before(cutoff) && filter.matches(f) {
+				d.Forecasts.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// CountMatching counts the forecasts matching filter
+func (r *fileForecastRepository) CountMatching(ctx context.Context, filter ForecastFilter) (int64, error) {
+	var count int64
+	err := r.e.read(func(d *fileData) error {
+		for _, f := range d.Forecasts.Rows {
+			if filter.matches(f) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// DeleteMatching removes the forecasts matching filter. The deletes are committed in one
+// transaction, so batchSize only needs to be positive.
+func (r *fileForecastRepository) DeleteMatching(ctx context.Context, filter ForecastFilter, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	var deleted int64
+	err := r.e.write(func(d *fileData) error {
+		for id, f := range d.Forecasts.Rows {
+			if filter.matches(f) {
+				d.Forecasts.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// WindRose bins the latest issue of each provider's forecast per valid hour
+func (r *fileForecastRepository) WindRose(ctx context.Context, cityID int, startTime, endTime string) (*WindRose, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wind rose: invalid start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wind rose: invalid end time: %w", err)
+	}
+
+	type hour struct {
+		provider string
+		valid    int64
+	}
+	latest := make(map[hour]*Forecast)
+	err = r.e.read(func(d *fileData) error {
+		for _, f := range d.Forecasts.Rows {
+			valid := parseStoredTime(f.ValidTime)
+			if f.CityID != cityID || valid.IsZero() || valid.Before(start) || !valid.Before(end) {
+				continue
+			}
+			key := hour{f.SourceProvider, valid.Unix()}
+			if current, ok := latest[key]; ok {
+				issued := parseStoredTime(f.ForecastTime).Compare(parseStoredTime(current.ForecastTime))
+				if issued < 0 || issued == 0 && f.ID < current.ID {
+					continue
+				}
+			}
+			latest[key] = f
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rose := &WindRose{}
+	for _, f := range latest {
+		rose.Total++
+		if f.WindSpeed < CalmWindSpeed {
+			rose.Calm++
+			continue
+		}
+		sector := &rose.Sectors[windSector(f.WindDirection)]
+		sector.MeanSpeed += f.WindSpeed
+		sector.Count++
+	}
+	for i := range rose.Sectors {
+		if rose.Sectors[i].Count > 0 {
+			rose.Sectors[i].MeanSpeed /= float64(rose.Sectors[i].Count)
+		}
+	}
+	return rose, nil
+}
+
+// DailySummaries aggregates the latest issue of each valid time by local date
+func (r *fileForecastRepository) DailySummaries(ctx context.Context, cityID int, startTime, endTime, timezone string) ([]*DailySummary, error) {
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: invalid start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: invalid end time: %w", err)
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+
+	var forecasts []*Forecast
+	err = r.e.read(func(d *fileData) error {
+		forecasts = d.Forecasts.filter(func(f *Forecast) bool {
+			valid := parseStoredTime(f.ValidTime)
+			return f.CityID == cityID && !valid.IsZero() && !valid.Before(start) && valid.Before(end)
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return summarizeDays(forecasts, loc), nil
+}
+
+// Stats summarizes the latest issue of each provider's forecast per valid time
+func (r *fileForecastRepository) Stats(ctx context.Context, cityID int, metric, startTime, endTime, bucket string) ([]*MetricStats, error) {
+	if _, err := statsMetric(metric, bucket); err != nil {
+		return nil, fmt.Errorf("failed to get forecast stats: %w", err)
+	}
+	start, err := time.Parse(time.RFC3339, startTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast stats: invalid start time: %w", err)
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast stats: invalid end time: %w", err)
+	}
+
+	type issue struct {
+		provider string
+		valid    int64
+	}
+	latest := make(map[issue]*Forecast)
+	err = r.e.read(func(d *fileData) error {
+		for _, f := range d.Forecasts.Rows {
+			valid := parseStoredTime(f.ValidTime)
+			if f.CityID != cityID || valid.IsZero() || valid.Before(start) || !valid.Before(end) {
+				continue
+			}
+			key := issue{f.SourceProvider, valid.Unix()}
+			if current, ok := latest[key]; ok {
+				issued := parseStoredTime(f.ForecastTime).Compare(parseStoredTime(current.ForecastTime))
+				if issued < 0 || issued == 0 && f.ID < current.ID {
+					continue
+				}
+			}
+			latest[key] = f
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[time.Time][]float64)
+	for _, f := range latest {
+		key := bucketStart(parseStoredTime(f.ValidTime), bucket, start)
+		values[key] = append(values[key], metricValue(f, metric))
+	}
+	buckets := make([]*MetricStats, 0, len(values))
+	for _, key := range slices.SortedFunc(maps.Keys(values), time.Time.Compare) {
+		buckets = append(buckets, summarizeMetric(key, values[key]))
+	}
+	return buckets, nil
+}
+
+func (r *fileForecastRepository) query(keep func(*Forecast) bool, order func(a, b *Forecast) int, limit, offset int) ([]*Forecast, error) {
+	var forecasts []*Forecast
+	err := r.e.read(func(d *fileData) error {
+		forecasts = d.Forecasts.filter(keep)
+		return nil
+	})
+	slices.SortStableFunc(forecasts, order)
+	return paginate(forecasts, limit, offset), err
+}
+
+// fileCityRepository implements CityRepository for a FileEngine
+type fileCityRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new city record
+func (r *fileCityRepository) Create(ctx context.Context, city *City) error {
+	return r.e.write(func(d *fileData) error {
+		if city.GeonameID != 0 {
+			for _, existing := range d.Cities.Rows {
+				if existing.GeonameID == city.GeonameID {
+					return duplicate("failed to create city: geoname_id %d already exists", city.GeonameID)
+				}
+			}
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		city.ID = d.Cities.next()
+		city.CreatedAt = now
+		city.UpdatedAt = now
+		d.Cities.put(city.ID, city)
+		return nil
+	})
+}
+
+// GetByID retrieves a city by its ID
+func (r *fileCityRepository) GetByID(ctx context.Context, id int) (*City, error) {
+	var city *City
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if city, ok = d.Cities.get(id); !ok {
+			return notFound("city with id %d not found", id)
+		}
+		return nil
+	})
+	return city, err
+}
+
+// Update modifies an existing city record
+func (r *fileCityRepository) Update(ctx context.Context, city *City) error {
+	return r.e.write(func(d *fileData) error {
+		existing, ok := d.Cities.get(city.ID)
+		if !ok {
+			return notFound("city with id %d not found", city.ID)
+		}
+		city.CreatedAt = existing.CreatedAt
+		city.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Cities.put(city.ID, city)
+		return nil
+	})
+}
+
+// Delete removes a city record and its localized names
+func (r *fileCityRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Cities.remove(id) {
+			return notFound("city with id %d not found", id)
+		}
+		d.CityNames = slices.DeleteFunc(d.CityNames, func(n *CityName) bool { return n.CityID == id })
+		d.touchNames(id)
+		return nil
+	})
+}
+
+// List retrieves cities with pagination, ordered by name
+func (r *fileCityRepository) List(ctx context.Context, limit, offset int) ([]*City, error) {
+	return r.query(nil, func(a, b *City) int { return cmp.Compare(a.Name, b.Name) }, limit, offset)
+}
+
+// Count returns the total number of city records
+func (r *fileCityRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.e.read(func(d *fileData) error {
+		count = len(d.Cities.Rows)
+		return nil
+	})
+	return count, err
+}
+
+// GetByIDs retrieves the cities with the given IDs
+func (r *fileCityRepository) GetByIDs(ctx context.Context, ids []int) ([]*City, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.query(func(c *City) bool { return slices.Contains(ids, c.ID) }, nil, 0, 0)
+}
+
+// GetByName retrieves cities by name
+func (r *fileCityRepository) GetByName(ctx context.Context, name string) ([]*City, error) {
+	return r.query(
+		func(c *City) bool { return strings.EqualFold(c.Name, name) },
+		byPopulationDesc, 0, 0,
+	)
+}
+
+// GetByCountry retrieves cities in a specific country
+func (r *fileCityRepository) GetByCountry(ctx context.Context, countryCode string, limit, offset int) ([]*City, error) {
+	return r.query(
+		func(c *City) bool { return c.CountryCode == countryCode },
+		byPopulationDesc, limit, offset,
+	)
+}
+
+// GetByCoordinates finds cities within a radius of given coordinates
+func (r *fileCityRepository) GetByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*City, error) {
+	distance := func(c *City) float64 { return geo.DistanceKm(lat, lon, c.Latitude, c.Longitude) }
+	return r.query(
+		func(c *City) bool { return distance(c) <= radiusKm },
+		func(a, b *City) int { return cmp.Compare(distance(a), distance(b)) },
+		limit, 0,
+	)
+}
+
+// GetByGeonameID retrieves a city by its GeoNames ID
+func (r *fileCityRepository) GetByGeonameID(ctx context.Context, geonameID int) (*City, error) {
+	cities, err := r.query(func(c *City) bool { return c.GeonameID == geonameID }, nil, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(cities) == 0 {
+		return nil, notFound("city with geoname_id %d not found", geonameID)
+	}
+	return cities[0], nil
+}
+
+// Search performs a case-insensitive substring search on city and country names
+func (r *fileCityRepository) Search(ctx context.Context, query string, limit int) ([]*City, error) {
+	q := strings.ToLower(query)
+	return r.query(
+		func(c *City) bool {
+			return strings.Contains(strings.ToLower(c.Name), q) || strings.Contains(strings.ToLower(c.Country), q)
+		},
+		byPopulationDesc, limit, 0,
+	)
+}
+
+// SetLocalizedName stores a city's name in the given language
+//
+//	A non-preferred name never replaces a preferred one for the same language
+func (r *fileCityRepository) SetLocalizedName(ctx context.Context, name *CityName) error {
+	return r.e.write(func(d *fileData) error {
+		stored := *name
+		stored.Language = strings.ToLower(name.Language)
+		d.touchNames(stored.CityID)
+
+		for i, existing := range d.CityNames {
+			if existing.CityID == stored.CityID && existing.Language == stored.Language {
+				if stored.IsPreferred || !existing.IsPreferred {
+					d.CityNames[i] = &stored
+				}
+				return nil
+			}
+		}
+		d.CityNames = append(d.CityNames, &stored)
+		return nil
+	})
+}
+
+// GetLocalizedNames retrieves names for the given cities restricted to the given languages
+func (r *fileCityRepository) GetLocalizedNames(ctx context.Context, cityIDs []int, languages []string) (map[int]map[string]string, error) {
+	names := make(map[int]map[string]string)
+	if len(cityIDs) == 0 || len(languages) == 0 {
+		return names, nil
+	}
+
+	wanted := make([]string, len(languages))
+	for i, lang := range languages {
+		wanted[i] = strings.ToLower(lang)
+	}
+
+	err := r.e.read(func(d *fileData) error {
+		for _, n := range d.CityNames {
+			if !slices.Contains(cityIDs, n.CityID) || !slices.Contains(wanted, n.Language) {
+				continue
+			}
+			if names[n.CityID] == nil {
+				names[n.CityID] = make(map[string]string)
+			}
+			names[n.CityID][n.Language] = n.Name
+		}
+		return nil
+	})
+	return names, err
+}
+
+func (r *fileCityRepository) query(keep func(*City) bool, order func(a, b *City) int, limit, offset int) ([]*City, error) {
+	var cities []*City
+	err := r.e.read(func(d *fileData) error {
+		cities = d.Cities.filter(keep)
+		return nil
+	})
+	if order != nil {
+		slices.SortStableFunc(cities, order)
+	}
+	return paginate(cities, limit, offset), err
+}
+
+func byPopulationDesc(a, b *City) int {
+	return cmp.Compare(b.Population, a.Population)
+}
+
+// filePlaceRepository implements PlaceRepository for a FileEngine
+type filePlaceRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new place record
+func (r *filePlaceRepository) Create(ctx context.Context, place *Place) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		place.ID = d.Places.next()
+		place.CreatedAt = now
+		place.UpdatedAt = now
+		d.Places.put(place.ID, place)
+		return nil
+	})
+}
+
+// GetByID retrieves a place by its ID
+func (r *filePlaceRepository) GetByID(ctx context.Context, id int) (*Place, error) {
+	var place *Place
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if place, ok = d.Places.get(id); !ok {
+			return notFound("place with id %d not found", id)
+		}
+		return nil
+	})
+	return place, err
+}
+
+// Update modifies an existing place record
+func (r *filePlaceRepository) Update(ctx context.Context, place *Place) error {
+	return r.e.write(func(d *fileData) error {
+		existing, ok := d.Places.get(place.ID)
+		if !ok {
+			return notFound("place with id %d not found", place.ID)
+		}
+		place.CreatedAt = existing.CreatedAt
+		place.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Places.put(place.ID, place)
+		return nil
+	})
+}
+
+// Delete removes a place record by its ID
+func (r *filePlaceRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Places.remove(id) {
+			return notFound("place with id %d not found", id)
+		}
+		return nil
+	})
+}
+
+// List retrieves places with pagination, most confident first
+func (r *filePlaceRepository) List(ctx context.Context, limit, offset int) ([]*Place, error) {
+	return r.query(nil, byConfidenceDesc, limit, offset)
+}
+
+// Count returns the total number of place records
+func (r *filePlaceRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.e.read(func(d *fileData) error {
+		count = len(d.Places.Rows)
+		return nil
+	})
+	return count, err
+}
+
+// GetByCoordinates finds places within a radius of given coordinates
+func (r *filePlaceRepository) GetByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*Place, error) {
+	distance := func(p *Place) float64 { return geo.DistanceKm(lat, lon, p.Latitude, p.Longitude) }
+	return r.query(
+		func(p *Place) bool { return distance(p) <= radiusKm },
+		func(a, b *Place) int { return cmp.Compare(distance(a), distance(b)) },
+		limit, 0,
+	)
+}
+
+// Search performs a case-insensitive substring search on place names and addresses
+func (r *filePlaceRepository) Search(ctx context.Context, query string, limit int) ([]*Place, error) {
+	q := strings.ToLower(query)
+	return r.query(
+		func(p *Place) bool {
+			return strings.Contains(strings.ToLower(p.DisplayName), q) ||
+				strings.Contains(strings.ToLower(p.AddressLine1), q) ||
+				strings.Contains(strings.ToLower(p.City), q)
+		},
+		byConfidenceDesc, limit, 0,
+	)
+}
+
+// GetBySource retrieves places by their geocoding source
+func (r *filePlaceRepository) GetBySource(ctx context.Context, source string, limit, offset int) ([]*Place, error) {
+	return r.query(func(p *Place) bool { return p.Source == source }, byConfidenceDesc, limit, offset)
+}
+
+// GetBySourcePlaceID retrieves a place by its source-specific ID
+func (r *filePlaceRepository) GetBySourcePlaceID(ctx context.Context, source, sourcePlaceID string) (*Place, error) {
+	places, err := r.query(
+		func(p *Place) bool { return p.Source == source && p.SourcePlaceID == sourcePlaceID },
+		nil, 1, 0,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if len(places) == 0 {
+		return nil, notFound("place with source %s and source_place_id %s not found", source, sourcePlaceID)
+	}
+	return places[0], nil
+}
+
+func (r *filePlaceRepository) query(keep func(*Place) bool, order func(a, b *Place) int, limit, offset int) ([]*Place, error) {
+	var places []*Place
+	err := r.e.read(func(d *fileData) error {
+		places = d.Places.filter(keep)
+		return nil
+	})
+	if order != nil {
+		slices.SortStableFunc(places, order)
+	}
+	return paginate(places, limit, offset), err
+}
+
+func byConfidenceDesc(a, b *Place) int {
+	return cmp.Compare(b.Confidence, a.Confidence)
+}
+
+// fileAlertRepository implements AlertRepository for a FileEngine
+type fileAlertRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new alert record
+func (r *fileAlertRepository) Create(ctx context.Context, alert *Alert) error {
+	if _, err := alertBounds(alert); err != nil {
+		return err
+	}
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		alert.ID = d.Alerts.next()
+		alert.CreatedAt = now
+		alert.UpdatedAt = now
+		d.Alerts.put(alert.ID, alert)
+		return nil
+	})
+}
+
+// Upsert inserts an alert, or updates the existing alert with the same
+// (source_provider, provider_alert_id) so repeated ingestion does not create duplicates
+func (r *fileAlertRepository) Upsert(ctx context.Context, alert *Alert) error {
+	if _, err := alertBounds(alert); err != nil {
+		return err
+	}
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		for id, existing := range d.Alerts.Rows {
+			if existing.SourceProvider != alert.SourceProvider || existing.ProviderAlertID != alert.ProviderAlertID {
+				continue
+			}
+			alert.ID = id
+			alert.CreatedAt = existing.CreatedAt
+			alert.UpdatedAt = now
+			if alert.CityID == 0 {
+				alert.CityID = existing.CityID
+			}
+			d.Alerts.put(id, alert)
+			return nil
+		}
+
+		alert.ID = d.Alerts.next()
+		alert.CreatedAt = now
+		alert.UpdatedAt = now
+		d.Alerts.put(alert.ID, alert)
+		return nil
+	})
+}
+
+// GetByID retrieves an alert by its ID
+func (r *fileAlertRepository) GetByID(ctx context.Context, id int) (*Alert, error) {
+	var alert *Alert
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if alert, ok = d.Alerts.get(id); !ok {
+			return notFound("alert with id %d not found", id)
+		}
+		return nil
+	})
+	return alert, err
+}
+
+// GetByProviderAlertID retrieves an alert by its provider-specific ID
+func (r *fileAlertRepository) GetByProviderAlertID(ctx context.Context, sourceProvider, providerAlertID string) (*Alert, error) {
+	alerts, err := r.query(func(a *Alert) bool {
+		return a.SourceProvider == sourceProvider && a.ProviderAlertID == providerAlertID
+	}, nil, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(alerts) == 0 {
+		return nil, notFound("alert with source %s and provider_alert_id %s not found", sourceProvider, providerAlertID)
+	}
+	return alerts[0], nil
+}
+
+// Update modifies an existing alert record
+func (r *fileAlertRepository) Update(ctx context.Context, alert *Alert) error {
+	if _, err := alertBounds(alert); err != nil {
+		return err
+	}
+	return r.e.write(func(d *fileData) error {
+		existing, ok := d.Alerts.get(alert.ID)
+		if !ok {
+			return notFound("alert with id %d not found", alert.ID)
+		}
+		alert.CreatedAt = existing.CreatedAt
+		alert.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Alerts.put(alert.ID, alert)
+		return nil
+	})
+}
+
+// Delete removes an alert record by its ID
+func (r *fileAlertRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Alerts.remove(id) {
+			return notFound("alert with id %d not found", id)
+		}
+		return nil
+	})
+}
+
+// List retrieves alerts with pagination, newest first
+func (r *fileAlertRepository) List(ctx context.Context, limit, offset int) ([]*Alert, error) {
+	return r.query(nil, byTimeDesc(func(a *Alert) string { return a.CreatedAt }), limit, offset)
+}
+
+// Count returns the total number of alert records
+func (r *fileAlertRepository) Count(ctx context.Context) (int, error) {
+	var count int
+	err := r.e.read(func(d *fileData) error {
+		count = len(d.Alerts.Rows)
+		return nil
+	})
+	return count, err
+}
+
+// GetActiveByCityID retrieves alerts currently in effect for a city
+func (r *fileAlertRepository) GetActiveByCityID(ctx context.Context, cityID int) ([]*Alert, error) {
+	now := time.Now()
+	return r.query(
+		func(a *Alert) bool { return a.CityID == cityID && alertActive(a, now) },
+		byEndTime, 0, 0,
+	)
+}
+
+// GetActiveByCoordinates retrieves alerts currently in effect at given coordinates: those
+// whose geometry covers the point, and those without geometry within a radius of it
+func (r *fileAlertRepository) GetActiveByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*Alert, error) {
+	now := time.Now()
+	return r.query(
+		func(a *Alert) bool {
+			return alertActive(a, now) && alertCovers(a, lat, lon, radiusKm)
+		},
+		byEndTime, limit, 0,
+	)
+}
+
+// DeleteExpired removes alerts whose end time has passed
+func (r *fileAlertRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	now := time.Now()
+	err := r.e.write(func(d *fileData) error {
+		for id, a := range d.Alerts.Rows {
+			if end := parseStoredTime(a.EndTime); !end.IsZero() && !end.After(now) {
+				d.Alerts.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+func (r *fileAlertRepository) query(keep func(*Alert) bool, order func(a, b *Alert) int, limit, offset int) ([]*Alert, error) {
+	var alerts []*Alert
+	err := r.e.read(func(d *fileData) error {
+		alerts = d.Alerts.filter(keep)
+		return nil
+	})
+	if order != nil {
+		slices.SortStableFunc(alerts, order)
+	}
+	return paginate(alerts, limit, offset), err
+}
+
+// alertActive mirrors activeAlertClause: a missing start or end time leaves that side open
+func alertActive(a *Alert, now time.Time) bool {
+	start, end := parseStoredTime(a.StartTime), parseStoredTime(a.EndTime)
+	return (start.IsZero() || !start.After(now)) && (end.IsZero() || end.After(now))
+}
+
+// byEndTime orders alerts by end time, with open-ended alerts last
+func byEndTime(a, b *Alert) int {
+	endA, endB := parseStoredTime(a.EndTime), parseStoredTime(b.EndTime)
+	switch {
+	case endA.IsZero() && endB.IsZero():
+		return 0
+	case endA.IsZero():
+		return 1
+	case endB.IsZero():
+		return -1
+	}
+	return endA.Compare(endB)
+}
+
+// fileAviationReportRepository implements AviationReportRepository for a FileEngine
+type fileAviationReportRepository struct {
+	e *FileEngine
+}
+
+// Upsert inserts a report, or refreshes the existing row with the same
+// (station_id, report_type, observed_at)
+func (r *fileAviationReportRepository) Upsert(ctx context.Context, report *AviationReport) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		observed := parseStoredTime(report.ObservedAt)
+		for id, existing := range d.Aviation.Rows {
+			if existing.StationID != report.StationID || existing.ReportType != report.ReportType ||
+				!parseStoredTime(existing.ObservedAt).Equal(observed) {
+				continue
+			}
+			report.ID = id
+			report.CreatedAt = existing.CreatedAt
+			report.UpdatedAt = now
+			d.Aviation.put(id, report)
+			return nil
+		}
+
+		report.ID = d.Aviation.next()
+		report.CreatedAt = now
+		report.UpdatedAt = now
+		d.Aviation.put(report.ID, report)
+		return nil
+	})
+}
+
+// GetLatest retrieves the most recent report of a type for a station
+func (r *fileAviationReportRepository) GetLatest(ctx context.Context, stationID, reportType string) (*AviationReport, error) {
+	reports, err := r.GetByStation(ctx, stationID, reportType, "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, notFound("no %s found for station %s", reportType, stationID)
+	}
+	return reports[0], nil
+}
+
+// GetByStation retrieves reports of a type for a station observed at or after since,
+// newest first
+func (r *fileAviationReportRepository) GetByStation(ctx context.Context, stationID, reportType, since string, limit int) ([]*AviationReport, error) {
+	sinceTime := parseStoredTime(since)
+	var reports []*AviationReport
+	err := r.e.read(func(d *fileData) error {
+		reports = d.Aviation.filter(func(a *AviationReport) bool {
+			return a.StationID == stationID && a.ReportType == reportType &&
+				!parseStoredTime(a.ObservedAt).Before(sinceTime)
+		})
+		return nil
+	})
+	slices.SortStableFunc(reports, byTimeDesc(func(a *AviationReport) string { return a.ObservedAt }))
+	return paginate(reports, limit, 0), err
+}
+
+// DeleteOlderThan removes reports observed before cutoff
+func (r *fileAviationReportRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	var deleted int64
+	cutoffTime := parseStoredTime(cutoff)
+	err := r.e.write(func(d *fileData) error {
+		for id, a := range d.Aviation.Rows {
+			if parseStoredTime(a.ObservedAt).Before(cutoffTime) {
+				d.Aviation.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// fileStationRepository implements StationRepository for a FileEngine
+type fileStationRepository struct {
+	e *FileEngine
+}
+
+// Upsert inserts a station, or refreshes the existing row with the same station_id
+func (r *fileStationRepository) Upsert(ctx context.Context, station *Station) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		for id, existing := range d.Stations.Rows {
+			if existing.StationID != station.StationID {
+				continue
+			}
+			station.ID = id
+			station.CreatedAt = existing.CreatedAt
+			station.UpdatedAt = now
+			d.Stations.put(id, station)
+			return nil
+		}
+
+		station.ID = d.Stations.next()
+		station.CreatedAt = now
+		station.UpdatedAt = now
+		d.Stations.put(station.ID, station)
+		return nil
+	})
+}
+
+// GetByStationID retrieves a station by its network code
+func (r *fileStationRepository) GetByStationID(ctx context.Context, stationID string) (*Station, error) {
+	var stations []*Station
+	err := r.e.read(func(d *fileData) error {
+		stations = d.Stations.filter(func(s *Station) bool { return s.StationID == stationID })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(stations) == 0 {
+		return nil, notFound("station %s not found", stationID)
+	}
+	return stations[0], nil
+}
+
+// List retrieves stations ordered by station ID
+func (r *fileStationRepository) List(ctx context.Context, limit, offset int) ([]*Station, error) {
+	var stations []*Station
+	err := r.e.read(func(d *fileData) error {
+		stations = d.Stations.filter(nil)
+		return nil
+	})
+	slices.SortFunc(stations, func(a, b *Station) int { return strings.Compare(a.StationID, b.StationID) })
+	return paginate(stations, limit, offset), err
+}
+
+// fileObservationRepository implements ObservationRepository for a FileEngine
+type fileObservationRepository struct {
+	e *FileEngine
+}
+
+// Upsert inserts an observation, or refreshes the existing row with the same
+// (station_id, observed_at)
+func (r *fileObservationRepository) Upsert(ctx context.Context, observation *Observation) error {
+	if observation.QualityFlags == "" {
+		observation.QualityFlags = "{}"
+	}
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		observed := parseStoredTime(observation.ObservedAt)
+		for id, existing := range d.Observations.Rows {
+			if existing.StationID != observation.StationID || !parseStoredTime(existing.ObservedAt).Equal(observed) {
+				continue
+			}
+			observation.ID = id
+			observation.CreatedAt = existing.CreatedAt
+			observation.UpdatedAt = now
+			d.Observations.put(id, observation)
+			return nil
+		}
+
+		observation.ID = d.Observations.next()
+		observation.CreatedAt = now
+		observation.UpdatedAt = now
+		d.Observations.put(observation.ID, observation)
+		return nil
+	})
+}
+
+// GetLatest retrieves the most recent observation of a station
+func (r *fileObservationRepository) GetLatest(ctx context.Context, stationID string) (*Observation, error) {
+	observations, err := r.GetByStation(ctx, stationID, "", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(observations) == 0 {
+		return nil, notFound("no observations found for station %s", stationID)
+	}
+	return observations[0], nil
+}
+
+// GetByStation retrieves a station's observations made at or after since, newest first
+func (r *fileObservationRepository) GetByStation(ctx context.Context, stationID, since string, limit int) ([]*Observation, error) {
+	sinceTime := parseStoredTime(since)
+	var observations []*Observation
+	err := r.e.read(func(d *fileData) error {
+		observations = d.Observations.filter(func(o *Observation) bool {
+			return o.StationID == stationID && !parseStoredTime(o.ObservedAt).Before(sinceTime)
+		})
+		return nil
+	})
+	slices.SortStableFunc(observations, byTimeDesc(func(o *Observation) string { return o.ObservedAt }))
+	return paginate(observations, limit, 0), err
+}
+
+// DeleteOlderThan removes observations made before cutoff
+func (r *fileObservationRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	var deleted int64
+	cutoffTime := parseStoredTime(cutoff)
+	err := r.e.write(func(d *fileData) error {
+		for id, o := range d.Observations.Rows {
+			if parseStoredTime(o.ObservedAt).Before(cutoffTime) {
+				d.Observations.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// fileAirQualityRepository implements AirQualityRepository for a FileEngine
+type fileAirQualityRepository struct {
+	e *FileEngine
+}
+
+// Upsert inserts a reading, or refreshes the existing row with the same
+// (latitude, longitude, observed_at)
+func (r *fileAirQualityRepository) Upsert(ctx context.Context, reading *AirQuality) error {
+	return r.e.write(func(d *fileData) error {
+		now := time.Now().UTC().Format(time.RFC3339)
+		observed := parseStoredTime(reading.ObservedAt)
+		for id, existing := range d.AirQuality.Rows {
+			if existing.Latitude != reading.Latitude || existing.Longitude != reading.Longitude ||
+				!parseStoredTime(existing.ObservedAt).Equal(observed) {
+				continue
+			}
+			reading.ID = id
+			reading.CreatedAt = existing.CreatedAt
+			reading.UpdatedAt = now
+			d.AirQuality.put(id, reading)
+			return nil
+		}
+
+		reading.ID = d.AirQuality.next()
+		reading.CreatedAt = now
+		reading.UpdatedAt = now
+		d.AirQuality.put(reading.ID, reading)
+		return nil
+	})
+}
+
+// GetLatest retrieves the most recent reading at a point
+func (r *fileAirQualityRepository) GetLatest(ctx context.Context, lat, lon float64) (*AirQuality, error) {
+	var readings []*AirQuality
+	err := r.e.read(func(d *fileData) error {
+		readings = d.AirQuality.filter(func(a *AirQuality) bool { return a.Latitude == lat && a.Longitude == lon })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(readings) == 0 {
+		return nil, notFound("no air quality readings found at %g,%g", lat, lon)
+	}
+	slices.SortStableFunc(readings, byTimeDesc(func(a *AirQuality) string { return a.ObservedAt }))
+	return readings[0], nil
+}
+
+// DeleteOlderThan removes readings observed before cutoff
+func (r *fileAirQualityRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	var deleted int64
+	cutoffTime := parseStoredTime(cutoff)
+	err := r.e.write(func(d *fileData) error {
+		for id, a := range d.AirQuality.Rows {
+			if parseStoredTime(a.ObservedAt).Before(cutoffTime) {
+				d.AirQuality.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// fileShareLinkRepository implements ShareLinkRepository for a FileEngine
+type fileShareLinkRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new share link
+func (r *fileShareLinkRepository) Create(ctx context.Context, link *ShareLink) error {
+	return r.e.write(func(d *fileData) error {
+		link.ID = d.Shares.next()
+		link.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Shares.put(link.ID, link)
+		return nil
+	})
+}
+
+// GetByID retrieves a share link by ID
+func (r *fileShareLinkRepository) GetByID(ctx context.Context, id int) (*ShareLink, error) {
+	var link *ShareLink
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if link, ok = d.Shares.get(id); !ok {
+			return notFound("share link with id %d not found", id)
+		}
+		return nil
+	})
+	return link, err
+}
+
+// ListByUser retrieves a user's share links, newest first
+func (r *fileShareLinkRepository) ListByUser(ctx context.Context, userID int) ([]*ShareLink, error) {
+	var links []*ShareLink
+	err := r.e.read(func(d *fileData) error {
+		links = d.Shares.filter(func(l *ShareLink) bool { return l.UserID == userID })
+		return nil
+	})
+	slices.Reverse(links)
+	slices.SortStableFunc(links, byTimeDesc(func(l *ShareLink) string { return l.CreatedAt }))
+	return links, err
+}
+
+// Revoke marks a share link as revoked
+func (r *fileShareLinkRepository) Revoke(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		link, ok := d.Shares.Rows[id]
+		if !ok {
+			return notFound("share link with id %d not found", id)
+		}
+		if link.RevokedAt == "" {
+			link.RevokedAt = time.Now().UTC().Format(time.RFC3339)
+			d.Shares.touch(id)
+		}
+		return nil
+	})
+}
+
+// RevokeByUser revokes every active share link of a user
+func (r *fileShareLinkRepository) RevokeByUser(ctx context.Context, userID int) (int64, error) {
+	var revoked int64
+	now := time.Now()
+	err := r.e.write(func(d *fileData) error {
+		for id, link := range d.Shares.Rows {
+			if link.UserID == userID && link.RevokedAt == "" && parseStoredTime(link.ExpiresAt).After(now) {
+				link.RevokedAt = now.UTC().Format(time.RFC3339)
+				d.Shares.touch(id)
+				revoked++
+			}
+		}
+		return nil
+	})
+	return revoked, err
+}
+
+// DeleteExpired removes share links past their expiry
+func (r *fileShareLinkRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	now := time.Now()
+	err := r.e.write(func(d *fileData) error {
+		for id, link := range d.Shares.Rows {
+			if !parseStoredTime(link.ExpiresAt).After(now) {
+				d.Shares.remove(id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// fileDigestRepository implements DigestRepository for a FileEngine
+type fileDigestRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new digest
+func (r *fileDigestRepository) Create(ctx context.Context, digest *Digest) error {
+	return r.e.write(func(d *fileData) error {
+		digest.ID = d.Digests.next()
+		digest.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Digests.put(digest.ID, digest)
+		return nil
+	})
+}
+
+// GetByID retrieves a digest by ID
+func (r *fileDigestRepository) GetByID(ctx context.Context, id int) (*Digest, error) {
+	var digest *Digest
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if digest, ok = d.Digests.get(id); !ok {
+			return notFound("digest with id %d not found", id)
+		}
+		return nil
+	})
+	return digest, err
+}
+
+// ListByUser retrieves a user's digests, newest first
+func (r *fileDigestRepository) ListByUser(ctx context.Context, userID int) ([]*Digest, error) {
+	var digests []*Digest
+	err := r.e.read(func(d *fileData) error {
+		digests = d.Digests.filter(func(g *Digest) bool { return g.UserID == userID })
+		return nil
+	})
+	slices.Reverse(digests)
+	slices.SortStableFunc(digests, byTimeDesc(func(g *Digest) string { return g.CreatedAt }))
+	return digests, err
+}
+
+// ListDue retrieves digests whose next run is at or before now, oldest first
+func (r *fileDigestRepository) ListDue(ctx context.Context, now string, limit int) ([]*Digest, error) {
+	nowTime := parseStoredTime(now)
+	var digests []*Digest
+	err := r.e.read(func(d *fileData) error {
+		digests = d.Digests.filter(func(g *Digest) bool { return !parseStoredTime(g.NextRunAt).After(nowTime) })
+		return nil
+	})
+	slices.SortStableFunc(digests, func(a, b *Digest) int {
+		return parseStoredTime(a.NextRunAt).Compare(parseStoredTime(b.NextRunAt))
+	})
+	if len(digests) > limit {
+		digests = digests[:limit]
+	}
+	return digests, err
+}
+
+// RecordRun stores the outcome of a delivery
+func (r *fileDigestRepository) RecordRun(ctx context.Context, digest *Digest) error {
+	return r.e.write(func(d *fileData) error {
+		stored, ok := d.Digests.Rows[digest.ID]
+		if !ok {
+			return notFound("digest with id %d not found", digest.ID)
+		}
+		stored.NextRunAt = digest.NextRunAt
+		stored.LastRunAt = digest.LastRunAt
+		stored.LastStatus = digest.LastStatus
+		stored.LastError = digest.LastError
+		d.Digests.touch(digest.ID)
+		return nil
+	})
+}
+
+// Delete removes a digest
+func (r *fileDigestRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Digests.remove(id) {
+			return notFound("digest with id %d not found", id)
+		}
+		return nil
+	})
+}
+
+// fileUserLocationRepository implements UserLocationRepository for a FileEngine
+type fileUserLocationRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new location
+func (r *fileUserLocationRepository) Create(ctx context.Context, location *UserLocation) error {
+	return r.e.write(func(d *fileData) error {
+		if err := checkLocationName(d, location); err != nil {
+			return fmt.Errorf("failed to create location: %w", err)
+		}
+		first := len(d.UserLocations.filter(func(l *UserLocation) bool { return l.UserID == location.UserID })) == 0
+		if location.IsDefault {
+			clearDefaultLocation(d, location.UserID)
+		}
+		location.IsDefault = location.IsDefault || first
+		location.ID = d.UserLocations.next()
+		location.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		location.UpdatedAt = location.CreatedAt
+		d.UserLocations.put(location.ID, location)
+		return nil
+	})
+}
+
+// GetByID retrieves a location by ID
+func (r *fileUserLocationRepository) GetByID(ctx context.Context, id int) (*UserLocation, error) {
+	var location *UserLocation
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if location, ok = d.UserLocations.get(id); !ok {
+			return notFound("location with id %d not found", id)
+		}
+		return nil
+	})
+	return location, err
+}
+
+// ListByUser retrieves a user's locations, the default first and then by name
+func (r *fileUserLocationRepository) ListByUser(ctx context.Context, userID int) ([]*UserLocation, error) {
+	var locations []*UserLocation
+	err := r.e.read(func(d *fileData) error {
+		locations = d.UserLocations.filter(func(l *UserLocation) bool { return l.UserID == userID })
+		return nil
+	})
+	slices.SortStableFunc(locations, func(a, b *UserLocation) int {
+		if a.IsDefault != b.IsDefault {
+			if a.IsDefault {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	})
+	return locations, err
+}
+
+// Update changes a location's name, city and coordinates
+func (r *fileUserLocationRepository) Update(ctx context.Context, location *UserLocation) error {
+	return r.e.write(func(d *fileData) error {
+		stored, ok := d.UserLocations.Rows[location.ID]
+		if !ok {
+			return notFound("location with id %d not found", location.ID)
+		}
+		location.UserID = stored.UserID
+		if err := checkLocationName(d, location); err != nil {
+			return fmt.Errorf("failed to update location: %w", err)
+		}
+		stored.Name = location.Name
+		stored.CityID = location.CityID
+		stored.Latitude = location.Latitude
+		stored.Longitude = location.Longitude
+		stored.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.UserLocations.touch(location.ID)
+		location.UpdatedAt = stored.UpdatedAt
+		return nil
+	})
+}
+
+// SetDefault makes a location its user's default
+func (r *fileUserLocationRepository) SetDefault(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		location, ok := d.UserLocations.Rows[id]
+		if !ok {
+			return notFound("location with id %d not found", id)
+		}
+		clearDefaultLocation(d, location.UserID)
+		location.IsDefault = true
+		location.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.UserLocations.touch(id)
+		return nil
+	})
+}
+
+// Delete removes a location
+func (r *fileUserLocationRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.UserLocations.remove(id) {
+			return notFound("location with id %d not found", id)
+		}
+		return nil
+	})
+}
+
+// checkLocationName rejects a location whose name another location of the same user
+// already has, ignoring case
+func checkLocationName(d *fileData, location *UserLocation) error {
+	for _, other := range d.UserLocations.Rows {
+		if other.ID != location.ID && other.UserID == location.UserID && strings.EqualFold(other.Name, location.Name) {
+			return duplicate("location %q already exists for user %d", location.Name, location.UserID)
+		}
+	}
+	return nil
+}
+
+// clearDefaultLocation unsets the default location of a user
+func clearDefaultLocation(d *fileData, userID int) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for id, location := range d.UserLocations.Rows {
+		if location.UserID == userID && location.IsDefault {
+			location.IsDefault = false
+			location.UpdatedAt = now
+			d.UserLocations.touch(id)
+		}
+	}
+}
+
+// fileAlertSubscriptionRepository implements AlertSubscriptionRepository for a FileEngine
+type fileAlertSubscriptionRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new subscription
+func (r *fileAlertSubscriptionRepository) Create(ctx context.Context, subscription *AlertSubscription) error {
+	return r.e.write(func(d *fileData) error {
+		for _, other := range d.Subscriptions.Rows {
+			if other.Token == subscription.Token {
+				return fmt.Errorf("failed to create alert subscription: %w", duplicate("alert subscription token already exists"))
+			}
+		}
+		subscription.ID = d.Subscriptions.next()
+		subscription.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Subscriptions.put(subscription.ID, subscription)
+		return nil
+	})
+}
+
+// GetByID retrieves a subscription by ID
+func (r *fileAlertSubscriptionRepository) GetByID(ctx context.Context, id int) (*AlertSubscription, error) {
+	var subscription *AlertSubscription
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if subscription, ok = d.Subscriptions.get(id); !ok {
+			return notFound("alert subscription with id %d not found", id)
+		}
+		return nil
+	})
+	return subscription, err
+}
+
+// ListByUser retrieves a user's subscriptions, newest first
+func (r *fileAlertSubscriptionRepository) ListByUser(ctx context.Context, userID int) ([]*AlertSubscription, error) {
+	var subscriptions []*AlertSubscription
+	err := r.e.read(func(d *fileData) error {
+		subscriptions = d.Subscriptions.filter(func(s *AlertSubscription) bool { return s.UserID == userID })
+		return nil
+	})
+	slices.Reverse(subscriptions)
+	slices.SortStableFunc(subscriptions, byTimeDesc(func(s *AlertSubscription) string { return s.CreatedAt }))
+	return subscriptions, err
+}
+
+// Delete removes a subscription and its queued notifications
+func (r *fileAlertSubscriptionRepository) Delete(ctx context.Context, id int) error {
+	return r.e.write(func(d *fileData) error {
+		if !d.Subscriptions.remove(id) {
+			return notFound("alert subscription with id %d not found", id)
+		}
+		removeAlertNotifications(d, id)
+		return nil
+	})
+}
+
+// DeleteByToken removes the subscription with an unsubscribe token
+func (r *fileAlertSubscriptionRepository) DeleteByToken(ctx context.Context, token string) error {
+	return r.e.write(func(d *fileData) error {
+		for id, subscription := range d.Subscriptions.Rows {
+			if subscription.Token == token {
+				d.Subscriptions.remove(id)
+				removeAlertNotifications(d, id)
+				return nil
+			}
+		}
+		return notFound("alert subscription not found")
+	})
+}
+
+// ListMatching retrieves the subscriptions whose radius contains a point
+func (r *fileAlertSubscriptionRepository) ListMatching(ctx context.Context, lat, lon float64, severities []string) ([]*AlertSubscription, error) {
+	var subscriptions []*AlertSubscription
+	err := r.e.read(func(d *fileData) error {
+		subscriptions = d.Subscriptions.filter(func(s *AlertSubscription) bool {
+			return slices.Contains(severities, s.MinSeverity) &&
+				geo.DistanceKm(lat, lon, s.Latitude, s.Longitude) <= s.RadiusKm
+		})
+		return nil
+	})
+	return subscriptions, err
+}
+
+// Queue records an alert to be sent to subscriptions, ignoring ones already queued
+func (r *fileAlertSubscriptionRepository) Queue(ctx context.Context, alertID int, subscriptionIDs []int) error {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+	return r.e.write(func(d *fileData) error {
+		for _, subscriptionID := range subscriptionIDs {
+			if _, ok := d.Subscriptions.Rows[subscriptionID]; !ok {
+				return fmt.Errorf("failed to queue alert notifications: %w", ErrConstraint)
+			}
+			queued := d.Notifications.filter(func(n *alertNotification) bool {
+				return n.SubscriptionID == subscriptionID && n.AlertID == alertID
+			})
+			if len(queued) == 0 {
+				d.Notifications.put(d.Notifications.next(), &alertNotification{SubscriptionID: subscriptionID, AlertID: alertID})
+			}
+		}
+		return nil
+	})
+}
+
+// ListDue retrieves up to limit subscriptions with unsent notifications that are due
+func (r *fileAlertSubscriptionRepository) ListDue(ctx context.Context, digestBefore string, limit int) ([]*AlertSubscription, error) {
+	before := parseStoredTime(digestBefore)
+	var subscriptions []*AlertSubscription
+	err := r.e.read(func(d *fileData) error {
+		pending := make(map[int]bool)
+		for _, n := range d.Notifications.Rows {
+			// Notifications of deleted alerts are left behind, unlike PostgreSQL's cascade
+			if _, ok := d.Alerts.Rows[n.AlertID]; ok && n.SentAt == "" {
+				pending[n.SubscriptionID] = true
+			}
+		}
+		subscriptions = d.Subscriptions.filter(func(s *AlertSubscription) bool {
+			if !pending[s.ID] {
+				return false
+			}
+			last := cmp.Or(s.LastNotifiedAt, s.CreatedAt)
+			return s.Mode == SubscriptionImmediate || !parseStoredTime(last).After(before)
+		})
+		return nil
+	})
+	return paginate(subscriptions, limit, 0), err
+}
+
+// ListPending retrieves the alerts queued for a subscription and not sent yet
+func (r *fileAlertSubscriptionRepository) ListPending(ctx context.Context, subscriptionID int) ([]*Alert, error) {
+	var alerts []*Alert
+	err := r.e.read(func(d *fileData) error {
+		for _, n := range d.Notifications.filter(func(n *alertNotification) bool {
+			return n.SubscriptionID == subscriptionID && n.SentAt == ""
+		}) {
+			if alert, ok := d.Alerts.get(n.AlertID); ok {
+				alerts = append(alerts, alert)
+			}
+		}
+		return nil
+	})
+	return alerts, err
+}
+
+// MarkSent records that alerts were sent to a subscription at sentAt
+func (r *fileAlertSubscriptionRepository) MarkSent(ctx context.Context, subscriptionID int, alertIDs []int, sentAt string) error {
+	return r.e.write(func(d *fileData) error {
+		subscription, ok := d.Subscriptions.Rows[subscriptionID]
+		if !ok {
+			return notFound("alert subscription with id %d not found", subscriptionID)
+		}
+		for id, n := range d.Notifications.Rows {
+			if n.SubscriptionID == subscriptionID && n.SentAt == "" && slices.Contains(alertIDs, n.AlertID) {
+				n.SentAt = sentAt
+				d.Notifications.touch(id)
+			}
+		}
+		subscription.LastNotifiedAt = sentAt
+		d.Subscriptions.touch(subscriptionID)
+		return nil
+	})
+}
+
+// removeAlertNotifications drops the notifications queued for a subscription
+func removeAlertNotifications(d *fileData, subscriptionID int) {
+	for id, n := range d.Notifications.Rows {
+		if n.SubscriptionID == subscriptionID {
+			d.Notifications.remove(id)
+		}
+	}
+}
+
+// fileUserRepository implements UserRepository for a FileEngine
+type fileUserRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new user
+func (r *fileUserRepository) Create(ctx context.Context, user *User) error {
+	return r.e.write(func(d *fileData) error {
+		for _, other := range d.Users.Rows {
+			switch {
+			case other.Username == user.Username:
+				return fmt.Errorf("failed to create user: %w", duplicate("user %q already exists", user.Username))
+			case user.APIKeyHash != "" && other.APIKeyHash == user.APIKeyHash:
+				return fmt.Errorf("failed to create user: %w", duplicate("API key already in use"))
+			}
+		}
+		if user.Role == "" {
+			user.Role = defaultUserRole
+		}
+		if user.PreferredUnits == "" {
+			user.PreferredUnits = defaultUserUnits
+		}
+		user.ID = d.Users.next()
+		user.CreatedAt = time.Now().UTC().Format(time.RFC3339)
+		user.UpdatedAt = user.CreatedAt
+		d.Users.put(user.ID, user)
+		return nil
+	})
+}
+
+// GetByID retrieves a user by ID
+func (r *fileUserRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	var user *User
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if user, ok = d.Users.get(id); !ok {
+			return notFound("user with id %d not found", id)
+		}
+		return nil
+	})
+	return user, err
+}
+
+// GetByUsername retrieves a user by username
+func (r *fileUserRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	return r.find(func(u *User) bool { return u.Username == username }, "user %q not found", username)
+}
+
+// GetByAPIKeyHash retrieves the user with a hashed API key
+func (r *fileUserRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*User, error) {
+	return r.find(func(u *User) bool { return hash != "" && u.APIKeyHash == hash }, "no user with this API key")
+}
+
+// SetRole changes a user's role
+func (r *fileUserRepository) SetRole(ctx context.Context, id int, role string) error {
+	return r.e.write(func(d *fileData) error {
+		user, ok := d.Users.Rows[id]
+		if !ok {
+			return notFound("user with id %d not found", id)
+		}
+		user.Role = role
+		user.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+		d.Users.touch(id)
+		return nil
+	})
+}
+
+// find returns the first user accepted by match, or a not found error built from format
+func (r *fileUserRepository) find(match func(*User) bool, format string, args ...any) (*User, error) {
+	var user *User
+	err := r.e.read(func(d *fileData) error {
+		if users := d.Users.filter(match); len(users) > 0 {
+			user = users[0]
+			return nil
+		}
+		return notFound(format, args...)
+	})
+	return user, err
+}
+
+// fileJobRunRepository implements JobRunRepository for a FileEngine
+type fileJobRunRepository struct {
+	e *FileEngine
+}
+
+// Create inserts a new job run
+func (r *fileJobRunRepository) Create(ctx context.Context, run *JobRun) error {
+	return r.e.write(func(d *fileData) error {
+		if run.Skipped == "" {
+			run.Skipped = "[]"
+		}
+		run.ID = d.JobRuns.next()
+		d.JobRuns.put(run.ID, run)
+		return nil
+	})
+}
+
+// Finish records the outcome of a job run
+func (r *fileJobRunRepository) Finish(ctx context.Context, run *JobRun) error {
+	return r.e.write(func(d *fileData) error {
+		stored, ok := d.JobRuns.Rows[run.ID]
+		if !ok {
+			return notFound("job run with id %d not found", run.ID)
+		}
+		if run.Skipped == "" {
+			run.Skipped = "[]"
+		}
+		stored.Status, stored.FinishedAt, stored.DurationMS = run.Status, run.FinishedAt, run.DurationMS
+		stored.Processed, stored.Skipped, stored.Error = run.Processed, run.Skipped, run.Error
+		d.JobRuns.touch(run.ID)
+		return nil
+	})
+}
+
+// GetByID retrieves a job run by ID
+func (r *fileJobRunRepository) GetByID(ctx context.Context, id int) (*JobRun, error) {
+	var run *JobRun
+	err := r.e.read(func(d *fileData) error {
+		var ok bool
+		if run, ok = d.JobRuns.get(id); !ok {
+			return notFound("job run with id %d not found", id)
+		}
+		return nil
+	})
+	return run, err
+}
+
+// List retrieves job runs, newest first
+func (r *fileJobRunRepository) List(ctx context.Context, jobName string, limit, offset int) ([]*JobRun, error) {
+	var runs []*JobRun
+	err := r.e.read(func(d *fileData) error {
+		runs = d.JobRuns.filter(func(run *JobRun) bool { return jobName == "" || run.JobName == jobName })
+		return nil
+	})
+	slices.Reverse(runs)
+	slices.SortStableFunc(runs, byTimeDesc(func(run *JobRun) string { return run.StartedAt }))
+	return paginate(runs, limit, offset), err
+}
+
+// FailRunning marks interrupted job runs as failed
+func (r *fileJobRunRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	var failed int64
+	now := time.Now().UTC()
+	err := r.e.write(func(d *fileData) error {
+		for id, run := range d.JobRuns.Rows {
+			if run.Status == "running" {
+				run.Status, run.Error = "failed", reason
+				run.FinishedAt = now.Format(time.RFC3339)
+				run.DurationMS = now.Sub(parseStoredTime(run.StartedAt)).Milliseconds()
+				d.JobRuns.touch(id)
+				failed++
+			}
+		}
+		return nil
+	})
+	return failed, err
+}
+
+// byTimeDesc orders rows by a timestamp field, newest first
+func byTimeDesc[T any](field func(*T) string) func(a, b *T) int {
+	return func(a, b *T) int {
+		return parseStoredTime(field(b)).Compare(parseStoredTime(field(a)))
+	}
+}
+
+// parseStoredTime parses an RFC 3339 timestamp, returning the zero time for empty or
+// malformed values
+func parseStoredTime(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// paginate applies LIMIT/OFFSET semantics; a non-positive limit returns everything
+func paginate[T any](rows []*T, limit, offset int) []*T {
+	if offset > 0 {
+		if offset >= len(rows) {
+			return nil
+		}
+		rows = rows[offset:]
+	}
+	if limit > 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows
+}
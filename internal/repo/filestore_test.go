@@ -0,0 +1,539 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileEngine(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ Engine = (*FileEngine)(nil)
+		var _ Engine = (*PostgreSQLEngine)(nil)
+		var _ ForecastRepository = (*fileForecastRepository)(nil)
+		var _ CityRepository = (*fileCityRepository)(nil)
+		var _ PlaceRepository = (*filePlaceRepository)(nil)
+		var _ AlertRepository = (*fileAlertRepository)(nil)
+		var _ AviationReportRepository = (*fileAviationReportRepository)(nil)
+		var _ JobRunRepository = (*fileJobRunRepository)(nil)
+	})
+
+	t.Run("Persists across reopen", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "weather.json")
+
+		engine, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		city := &City{Name: "Paris", CountryCode: "FR", GeonameID: 2988507, Population: 2138551}
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := engine.Cities().SetLocalizedName(ctx, &CityName{CityID: city.ID, Language: "DE", Name: "Paris"}); err != nil {
+			t.Fatalf("SetLocalizedName failed: %v", err)
+		}
+		if err := engine.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		reopened, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("reopen failed: %v", err)
+		}
+		got, err := reopened.Cities().GetByGeonameID(ctx, 2988507)
+		if err != nil {
+			t.Fatalf("GetByGeonameID failed: %v", err)
+		}
+		if got.ID != city.ID || got.Name != "Paris" {
+			t.Errorf("Expected city %d Paris, got %+v", city.ID, got)
+		}
+
+		names, err := reopened.Cities().GetLocalizedNames(ctx, []int{city.ID}, []string{"de"})
+		if err != nil {
+			t.Fatalf("GetLocalizedNames failed: %v", err)
+		}
+		if names[city.ID]["de"] != "Paris" {
+			t.Errorf("Expected localized name, got %v", names)
+		}
+
+		// IDs keep increasing after a reopen
+		next := &City{Name: "Lyon", CountryCode: "FR"}
+		if err := reopened.Cities().Create(ctx, next); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if next.ID <= city.ID {
+			t.Errorf("Expected ID greater than %d, got %d", city.ID, next.ID)
+		}
+
+		batch, err := reopened.Cities().GetByIDs(ctx, []int{next.ID, city.ID, 999})
+		if err != nil {
+			t.Fatalf("GetByIDs failed: %v", err)
+		}
+		if len(batch) != 2 {
+			t.Errorf("Expected Paris and Lyon, got %+v", batch)
+		}
+	})
+
+	t.Run("Persists in-place changes and deletes", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "weather.db")
+
+		engine, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		user := &User{Username: "ops", Role: "user"}
+		paris := &City{Name: "Paris", CountryCode: "FR"}
+		lyon := &City{Name: "Lyon", CountryCode: "FR"}
+		for _, err := range []error{
+			engine.Users().Create(ctx, user),
+			engine.Users().SetRole(ctx, user.ID, "admin"),
+			engine.Cities().Create(ctx, paris),
+			engine.Cities().Create(ctx, lyon),
+			engine.Cities().SetLocalizedName(ctx, &CityName{CityID: lyon.ID, Language: "de", Name: "Lyon"}),
+			engine.Cities().Delete(ctx, lyon.ID),
+		} {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := OpenFileEngine(path); err == nil {
+			t.Error("Expected a second open of a locked file to fail")
+		}
+		if err := engine.Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+
+		reopened, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("reopen failed: %v", err)
+		}
+		defer reopened.Close()
+		if got, err := reopened.Users().GetByID(ctx, user.ID); err != nil || got.Role != "admin" {
+			t.Errorf("Expected the role change to persist, got %+v (%v)", got, err)
+		}
+		if _, err := reopened.Cities().GetByID(ctx, lyon.ID); err == nil {
+			t.Error("Expected the deleted city to stay deleted")
+		}
+		if names, _ := reopened.Cities().GetLocalizedNames(ctx, []int{lyon.ID}, []string{"de"}); len(names[lyon.ID]) != 0 {
+			t.Errorf("Expected the deleted city's names to be gone, got %v", names)
+		}
+		if err := reopened.Reset(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := reopened.Cities().GetByID(ctx, paris.ID); err == nil {
+			t.Error("Expected Reset to remove the cities")
+		}
+	})
+
+	t.Run("Converts a JSON dataset", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "weather.json")
+		legacy := `{"cities":{"seq":4,"rows":{"4":{"id":4,"name":"Paris","country_code":"FR"}}},"city_names":[{"CityID":4,"Language":"de","Name":"Paris"}]}`
+		if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		engine, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		if err := engine.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if backup, _ := os.ReadFile(path + ".json.bak"); string(backup) != legacy {
+			t.Errorf("Expected the JSON dataset to be kept as a backup, got %q", backup)
+		}
+
+		reopened, err := OpenFileEngine(path)
+		if err != nil {
+			t.Fatalf("reopen failed: %v", err)
+		}
+		defer reopened.Close()
+		if city, err := reopened.Cities().GetByID(ctx, 4); err != nil || city.Name != "Paris" {
+			t.Errorf("Expected the converted city, got %+v (%v)", city, err)
+		}
+		if names, _ := reopened.Cities().GetLocalizedNames(ctx, []int{4}, []string{"de"}); names[4]["de"] != "Paris" {
+			t.Errorf("Expected the converted names, got %v", names)
+		}
+		next := &City{Name: "Lyon", CountryCode: "FR"}
+		if err := reopened.Cities().Create(ctx, next); err != nil || next.ID != 5 {
+			t.Errorf("Expected IDs to continue from the JSON sequence, got %d (%v)", next.ID, err)
+		}
+	})
+
+	t.Run("Not found errors", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+
+		if _, err := engine.Forecasts().GetByID(ctx, 1); err == nil {
+			t.Error("Expected error from GetByID, got nil")
+		}
+		if err := engine.Places().Update(ctx, &Place{ID: 7}); err == nil {
+			t.Error("Expected error from Update, got nil")
+		}
+		if err := engine.Alerts().Delete(ctx, 3); err == nil {
+			t.Error("Expected error from Delete, got nil")
+		}
+		if _, err := engine.Forecasts().GetLatestByCityID(ctx, 1); err == nil {
+			t.Error("Expected error from GetLatestByCityID, got nil")
+		}
+	})
+
+	t.Run("Returned rows are copies", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+
+		place := &Place{DisplayName: "Main St", Source: "census", SourcePlaceID: "1"}
+		_ = engine.Places().Create(ctx, place)
+		place.DisplayName = "changed"
+
+		got, err := engine.Places().GetBySourcePlaceID(ctx, "census", "1")
+		if err != nil {
+			t.Fatalf("GetBySourcePlaceID failed: %v", err)
+		}
+		if got.DisplayName != "Main St" {
+			t.Errorf("Stored row was modified through caller pointer: %q", got.DisplayName)
+		}
+	})
+
+	t.Run("City queries", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		cities := engine.Cities()
+
+		for _, c := range []*City{
+			{Name: "Springfield", Country: "United States", CountryCode: "US", Population: 100, Latitude: 39.8, Longitude: -89.6},
+			{Name: "Springfield", Country: "United States", CountryCode: "US", Population: 150, Latitude: 37.2, Longitude: -93.3},
+			{Name: "Toronto", Country: "Canada", CountryCode: "CA", Population: 2700, Latitude: 43.7, Longitude: -79.4},
+		} {
+			if err := cities.Create(ctx, c); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		byName, _ := cities.GetByName(ctx, "springfield")
+		if len(byName) != 2 || byName[0].Population != 150 {
+			t.Errorf("Expected 2 cities ordered by population, got %+v", byName)
+		}
+
+		search, _ := cities.Search(ctx, "CAN", 10)
+		if len(search) != 1 || search[0].Name != "Toronto" {
+			t.Errorf("Expected Toronto from search, got %+v", search)
+		}
+
+		nearby, _ := cities.GetByCoordinates(ctx, 39.78, -89.65, 50, 10)
+		if len(nearby) != 1 || nearby[0].Latitude != 39.8 {
+			t.Errorf("Expected the Illinois Springfield, got %+v", nearby)
+		}
+
+		page, _ := cities.List(ctx, 1, 1)
+		if len(page) != 1 || page[0].Name != "Springfield" {
+			t.Errorf("Expected second city by name, got %+v", page)
+		}
+
+		if err := cities.Create(ctx, &City{Name: "Dup", GeonameID: 1}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := cities.Create(ctx, &City{Name: "Dup", GeonameID: 1}); err == nil {
+			t.Error("Expected duplicate geoname_id error, got nil")
+		}
+	})
+
+	t.Run("Forecast queries", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		forecasts := engine.Forecasts()
+
+		now := time.Now().UTC()
+		var batch []*Forecast
+		for _, offset := range []time.Duration{-10 * 24 * time.Hour, -time.Hour, 2 * time.Hour} {
+			batch = append(batch, &Forecast{CityID: 1, ValidTime: now.Add(offset).Format(time.RFC3339)})
+		}
+		if err := forecasts.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch failed: %v", err)
+		}
+		if batch[0].ID != 1 || batch[2].ID != 3 || batch[2].CreatedAt == "" {
+			t.Errorf("Expected IDs 1-3 to be assigned in order, got %+v", batch)
+		}
+
+		latest, err := forecasts.GetLatestByCityID(ctx, 1)
+		if err != nil {
+			t.Fatalf("GetLatestByCityID failed: %v", err)
+		}
+		if latest.ID != 3 {
+			t.Errorf("Expected latest forecast 3, got %d", latest.ID)
+		}
+
+		inRange, err := forecasts.GetByTimeRange(ctx,
+			now.Add(-2*time.Hour).Format(time.RFC3339), now.Add(3*time.Hour).Format(time.RFC3339), 10, 0)
+		if err != nil {
+			t.Fatalf("GetByTimeRange failed: %v", err)
+		}
+		if len(inRange) != 2 || inRange[0].ID != 3 {
+			t.Errorf("Expected forecasts 3 and 2 in descending order, got %+v", inRange)
+		}
+		afterThree, err := forecasts.GetByTimeRangeAfter(ctx,
+			now.Add(-2*time.Hour).Format(time.RFC3339), now.Add(3*time.Hour).Format(time.RFC3339), &ForecastCursor{ValidTime: batch[2].ValidTime, ID: 3}, 10)
+		if err != nil {
+			t.Fatalf("GetByTimeRangeAfter failed: %v", err)
+		}
+		if len(afterThree) != 1 || afterThree[0].ID != 2 {
+			t.Errorf("Expected only forecast 2 in range after forecast 3, got %+v", afterThree)
+		}
+
+		// A forecast sharing forecast 2's valid time is ordered by ID within the keyset
+		tie := &Forecast{CityID: 1, ValidTime: batch[1].ValidTime}
+		if err := forecasts.Create(ctx, tie); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		first, err := forecasts.ListAfter(ctx, nil, 2)
+		if err != nil {
+			t.Fatalf("ListAfter failed: %v", err)
+		}
+		if len(first) != 2 || first[0].ID != 3 || first[1].ID != tie.ID {
+			t.Errorf("Expected forecasts 3 and %d on the first page, got %+v", tie.ID, first)
+		}
+		last := first[len(first)-1]
+		second, err := forecasts.GetByCityIDAfter(ctx, 1, &ForecastCursor{ValidTime: last.ValidTime, ID: last.ID}, 10)
+		if err != nil {
+			t.Fatalf("GetByCityIDAfter failed: %v", err)
+		}
+		if len(second) != 2 || second[0].ID != 2 || second[1].ID != 1 {
+			t.Errorf("Expected forecasts 2 and 1 after the cursor, got %+v", second)
+		}
+		_ = forecasts.Delete(ctx, tie.ID)
+
+		if deleted, err := forecasts.DeleteOldForecasts(ctx, 7, ForecastFilter{SourceProvider: "Elsewhere"}); err != nil || deleted != 0 {
+			t.Fatalf("Expected no forecasts of another provider deleted, got %d (%v)", deleted, err)
+		}
+		if deleted, err := forecasts.DeleteOldForecasts(ctx, 7, ForecastFilter{}); err != nil || deleted != 1 {
+			t.Fatalf("Expected one old forecast deleted, got %d (%v)", deleted, err)
+		}
+		if count, _ := forecasts.Count(ctx); count != 2 {
+			t.Errorf("Expected 2 forecasts after cleanup, got %d", count)
+		}
+	})
+
+	t.Run("Forecasts with cities", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		city := &City{Name: "Paris", CountryCode: "FR", Timezone: "Europe/Paris"}
+		if err := engine.Cities().Create(ctx, city); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+		if err := engine.Forecasts().Create(ctx, &Forecast{CityID: city.ID, Temperature: 21}); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+
+		forecasts, err := engine.Forecasts().ListWithCities(ctx, 10, 0)
+		if err != nil {
+			t.Fatalf("ListWithCities failed: %v", err)
+		}
+		want := ForecastCity{Name: "Paris", CountryCode: "FR", Timezone: "Europe/Paris"}
+		if len(forecasts) != 1 || forecasts[0].Temperature != 21 || forecasts[0].City != want {
+			t.Errorf("Expected the forecast with its city, got %+v", forecasts)
+		}
+	})
+
+	t.Run("Forecast bulk delete", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		forecasts := engine.Forecasts()
+
+		now := time.Now().UTC()
+		batch := []*Forecast{
+			{CityID: 1, SourceProvider: "nws", ValidTime: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+			{CityID: 1, SourceProvider: "nws", ValidTime: now.Add(time.Hour).Format(time.RFC3339)},
+			{CityID: 1, SourceProvider: "open-meteo", ValidTime: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+			{CityID: 2, SourceProvider: "nws", ValidTime: now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		}
+		if err := forecasts.CreateBatch(ctx, batch); err != nil {
+			t.Fatalf("CreateBatch failed: %v", err)
+		}
+
+		filter := ForecastFilter{CityID: 1, SourceProvider: "nws", Before: now.Format(time.RFC3339)}
+		if count, err := forecasts.CountMatching(ctx, filter); err != nil || count != 1 {
+			t.Errorf("Expected 1 matching forecast, got %d (%v)", count, err)
+		}
+		if _, err := forecasts.DeleteMatching(ctx, filter, 0); err == nil {
+			t.Error("Expected a non-positive batch size to be rejected")
+		}
+		if deleted, err := forecasts.DeleteMatching(ctx, ForecastFilter{SourceProvider: "nws"}, 1); err != nil || deleted != 3 {
+			t.Errorf("Expected 3 forecasts deleted, got %d (%v)", deleted, err)
+		}
+		if count, _ := forecasts.Count(ctx); count != 1 {
+			t.Errorf("Expected 1 forecast left, got %d", count)
+		}
+	})
+
+	t.Run("Alert upsert and expiry", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		alerts := engine.Alerts()
+
+		now := time.Now().UTC()
+		alert := &Alert{
+			SourceProvider: "nws", ProviderAlertID: "a1", CityID: 5,
+			EndTime: now.Add(time.Hour).Format(time.RFC3339),
+		}
+		if err := alerts.Upsert(ctx, alert); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+
+		refresh := &Alert{SourceProvider: "nws", ProviderAlertID: "a1", Title: "Updated"}
+		if err := alerts.Upsert(ctx, refresh); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+		if refresh.ID != alert.ID || refresh.CityID != 5 {
+			t.Errorf("Expected upsert to keep ID %d and city 5, got %+v", alert.ID, refresh)
+		}
+
+		expired := &Alert{SourceProvider: "nws", ProviderAlertID: "a2", CityID: 5, EndTime: now.Add(-time.Hour).Format(time.RFC3339)}
+		_ = alerts.Create(ctx, expired)
+
+		active, _ := alerts.GetActiveByCityID(ctx, 5)
+		if len(active) != 1 || active[0].Title != "Updated" {
+			t.Errorf("Expected only the refreshed alert to be active, got %+v", active)
+		}
+
+		deleted, err := alerts.DeleteExpired(ctx)
+		if err != nil || deleted != 1 {
+			t.Errorf("Expected 1 expired alert deleted, got %d (%v)", deleted, err)
+		}
+	})
+
+	t.Run("Alerts by geometry", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		alerts := engine.Alerts()
+
+		// A warning polygon west of Oklahoma City whose point is far from it, and an
+		// alert without geometry
+		polygon := &Alert{SourceProvider: "nws", ProviderAlertID: "polygon", Latitude: 30, Longitude: -90,
+			Geometry: `{"type": "Polygon", "coordinates": [[[-98, 35], [-97.6, 35], [-97.6, 35.6], [-98, 35.6], [-98, 35]]]}`}
+		point := &Alert{SourceProvider: "nws", ProviderAlertID: "point", Latitude: 35.47, Longitude: -97.52}
+		for _, alert := range []*Alert{polygon, point} {
+			if err := alerts.Create(ctx, alert); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		inside, _ := alerts.GetActiveByCoordinates(ctx, 35.3, -97.8, 50, 10)
+		if len(inside) != 2 {
+			t.Errorf("Expected the covering polygon and the nearby point, got %d alerts", len(inside))
+		}
+		outside, _ := alerts.GetActiveByCoordinates(ctx, 35.47, -97.5, 50, 10)
+		if len(outside) != 1 || outside[0].ProviderAlertID != "point" {
+			t.Errorf("Expected only the point alert outside the polygon, got %+v", outside)
+		}
+
+		invalid := &Alert{SourceProvider: "nws", ProviderAlertID: "invalid", Geometry: `{"type": "Point", "coordinates": [-97, 35]}`}
+		if err := alerts.Create(ctx, invalid); !errors.Is(err, ErrConstraint) {
+			t.Errorf("Expected ErrConstraint for a point geometry, got %v", err)
+		}
+	})
+
+	t.Run("Aviation reports", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		reports := engine.Aviation()
+
+		now := time.Now().UTC().Truncate(time.Hour)
+		for hours := range 3 {
+			observed := now.Add(-time.Duration(hours) * time.Hour).Format(time.RFC3339)
+			_ = reports.Upsert(ctx, &AviationReport{StationID: "KJFK", ReportType: "METAR", RawText: "old", ObservedAt: observed})
+		}
+		refresh := &AviationReport{StationID: "KJFK", ReportType: "METAR", RawText: "corrected", ObservedAt: now.Format(time.RFC3339)}
+		if err := reports.Upsert(ctx, refresh); err != nil {
+			t.Fatalf("Upsert failed: %v", err)
+		}
+
+		latest, err := reports.GetLatest(ctx, "KJFK", "METAR")
+		if err != nil || latest.ID != refresh.ID || latest.RawText != "corrected" {
+			t.Errorf("Expected refreshed METAR as latest, got %+v (%v)", latest, err)
+		}
+		if _, err := reports.GetLatest(ctx, "KJFK", "TAF"); err == nil {
+			t.Error("Expected error for missing TAF, got nil")
+		}
+
+		recent, _ := reports.GetByStation(ctx, "KJFK", "METAR", now.Add(-90*time.Minute).Format(time.RFC3339), 10)
+		if len(recent) != 2 {
+			t.Errorf("Expected 2 recent METARs, got %d", len(recent))
+		}
+
+		deleted, err := reports.DeleteOlderThan(ctx, now.Format(time.RFC3339))
+		if err != nil || deleted != 2 {
+			t.Errorf("Expected 2 reports deleted, got %d (%v)", deleted, err)
+		}
+	})
+}
+
+func TestParseEngineName(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{"", EnginePostgres, false},
+		{"Postgres", EnginePostgres, false},
+		{" file ", EngineFile, false},
+		{"bolt", "", true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseEngineName(test.input)
+		if (err != nil) != test.wantErr {
+			t.Errorf("ParseEngineName(%q) error = %v, wantErr %v", test.input, err, test.wantErr)
+		}
+		if got != test.expected {
+			t.Errorf("ParseEngineName(%q) = %q, expected %q", test.input, got, test.expected)
+		}
+	}
+
+	t.Run("Job runs", func(t *testing.T) {
+		engine, _ := OpenFileEngine("")
+		ctx := context.Background()
+		runs := engine.JobRuns()
+
+		started := time.Now().UTC().Add(-time.Minute)
+		first := &JobRun{JobName: "ingest", TriggeredBy: "schedule", Status: "running", StartedAt: started.Format(time.RFC3339)}
+		second := &JobRun{JobName: "ingest", TriggeredBy: "manual", Status: "running", StartedAt: started.Add(time.Second).Format(time.RFC3339)}
+		other := &JobRun{JobName: "cleanup", TriggeredBy: "manual", Status: "running", StartedAt: started.Format(time.RFC3339)}
+		for _, run := range []*JobRun{first, second, other} {
+			if err := runs.Create(ctx, run); err != nil {
+				t.Fatalf("Create failed: %v", err)
+			}
+		}
+
+		first.Status, first.Processed, first.Skipped = "partial", 3, `[{"item":"Paris","reason":"no provider"}]`
+		if err := runs.Finish(ctx, first); err != nil {
+			t.Fatalf("Finish failed: %v", err)
+		}
+		if err := runs.Finish(ctx, &JobRun{ID: 99}); err == nil {
+			t.Error("Expected error finishing a missing run, got nil")
+		}
+
+		listed, _ := runs.List(ctx, "ingest", 10, 0)
+		if len(listed) != 2 || listed[0].ID != second.ID || listed[1].Skipped != first.Skipped {
+			t.Errorf("Expected both ingest runs newest first, got %+v", listed)
+		}
+
+		failed, err := runs.FailRunning(ctx, "interrupted")
+		if err != nil || failed != 2 {
+			t.Errorf("Expected 2 running runs failed, got %d (%v)", failed, err)
+		}
+		if run, _ := runs.GetByID(ctx, other.ID); run.Status != "failed" || run.Error != "interrupted" || run.DurationMS <= 0 {
+			t.Errorf("Expected an interrupted run, got %+v", run)
+		}
+
+		_ = engine.Reset(ctx)
+		if all, _ := runs.List(ctx, "", 10, 0); len(all) != 3 {
+			t.Errorf("Expected Reset to keep job run history, got %d runs", len(all))
+		}
+	})
+}
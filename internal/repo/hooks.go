@@ -0,0 +1,315 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Operation names the kind of write seen by a hook
+type Operation string
+
+// Write operations
+const (
+	OpCreate Operation = "create"
+	OpUpdate Operation = "update"
+	OpUpsert Operation = "upsert"
+	OpDelete Operation = "delete"
+
+	// OpDeleteMany is a bulk delete such as retention cleanup. Mutations for it carry
+	// no entity or ID, and Count is only known after the write.
+	OpDeleteMany Operation = "delete_many"
+)
+
+// Mutation describes one write passed to hooks
+type Mutation[T any] struct {
+	Op     Operation
+	ID     int // populated after creates; zero for bulk deletes
+	Entity *T  // nil for deletes
+	Count  int64
+}
+
+// Hook subscribes to the writes of one repository.
+//
+// Before runs ahead of the write and may modify the entity; an error aborts the write
+// and is returned to the caller. After runs once the write succeeded, so it cannot
+// fail it: subscribers that need durability (audit, indexing) must handle their own
+// errors. Either may be nil.
+type Hook[T any] struct {
+	Name   string
+	Before func(ctx context.Context, m *Mutation[T]) error
+	After  func(ctx context.Context, m Mutation[T])
+}
+
+// Hooks holds the hooks registered for one entity type, run in registration order
+type Hooks[T any] struct {
+	mu    sync.RWMutex
+	hooks []Hook[T]
+}
+
+// Register adds a hook
+func (h *Hooks[T]) Register(hook Hook[T]) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hooks = append(h.hooks, hook)
+}
+
+// before runs the Before hooks, stopping at the first rejection
+func (h *Hooks[T]) before(ctx context.Context, m *Mutation[T]) error {
+	h.mu.RLock()
+	hooks := h.hooks
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		if hook.Before == nil {
+			continue
+		}
+		if err := hook.Before(ctx, m); err != nil {
+			return fmt.Errorf("%s hook rejected %s: %w", hook.Name, m.Op, err)
+		}
+	}
+	return nil
+}
+
+// after runs the After hooks
+func (h *Hooks[T]) after(ctx context.Context, m Mutation[T]) {
+	h.mu.RLock()
+	hooks := h.hooks
+	h.mu.RUnlock()
+	for _, hook := range hooks {
+		if hook.After != nil {
+			hook.After(ctx, m)
+		}
+	}
+}
+
+// run wraps write with the Before and After hooks. finish fills in what is only
+// known after the write, such as a created ID or a deleted count.
+func (h *Hooks[T]) run(ctx context.Context, m Mutation[T], write func() error, finish func(m *Mutation[T])) error {
+	if err := h.before(ctx, &m); err != nil {
+		return err
+	}
+	if err := write(); err != nil {
+		return err
+	}
+	if finish != nil {
+		finish(&m)
+	}
+	h.after(ctx, m)
+	return nil
+}
+
+// EngineHooks are the hooks applied by NewHookedEngine
+type EngineHooks struct {
+	Forecasts Hooks[Forecast]
+	Cities    Hooks[City]
+	Places    Hooks[Place]
+	Alerts    Hooks[Alert]
+	Aviation  Hooks[AviationReport]
+}
+
+// hookedEngine runs hooks around the writes of the wrapped engine's repositories
+type hookedEngine struct {
+	Engine
+	hooks *EngineHooks
+}
+
+// NewHookedEngine wraps engine so writes to its forecast, city, place, alert and
+// aviation report repositories run hooks, letting cross-cutting features (audit logging, cache
+// invalidation, event emission, search indexing) subscribe in one place instead of
+// every repository calling them. Hooks registered after wrapping still apply.
+func NewHookedEngine(engine Engine, hooks *EngineHooks) Engine {
+	return &hookedEngine{Engine: engine, hooks: hooks}
+}
+
+// Forecasts returns the forecast repository with hooks
+func (e *hookedEngine) Forecasts() ForecastRepository {
+	return &hookedForecastRepository{ForecastRepository: e.Engine.Forecasts(), hooks: &e.hooks.Forecasts}
+}
+
+// Cities returns the city repository with hooks
+func (e *hookedEngine) Cities() CityRepository {
+	return &hookedCityRepository{CityRepository: e.Engine.Cities(), hooks: &e.hooks.Cities}
+}
+
+// Places returns the place repository with hooks
+func (e *hookedEngine) Places() PlaceRepository {
+	return &hookedPlaceRepository{PlaceRepository: e.Engine.Places(), hooks: &e.hooks.Places}
+}
+
+// Alerts returns the alert repository with hooks
+func (e *hookedEngine) Alerts() AlertRepository {
+	return &hookedAlertRepository{AlertRepository: e.Engine.Alerts(), hooks: &e.hooks.Alerts}
+}
+
+// Aviation returns the aviation report repository with hooks
+func (e *hookedEngine) Aviation() AviationReportRepository {
+	return &hookedAviationReportRepository{AviationReportRepository: e.Engine.Aviation(), hooks: &e.hooks.Aviation}
+}
+
+type hookedForecastRepository struct {
+	ForecastRepository
+	hooks *Hooks[Forecast]
+}
+
+func (r *hookedForecastRepository) Create(ctx context.Context, forecast *Forecast) error {
+	return r.hooks.run(ctx, Mutation[Forecast]{Op: OpCreate, Entity: forecast},
+		func() error { return r.ForecastRepository.Create(ctx, forecast) },
+		func(m *Mutation[Forecast]) { m.ID = forecast.ID })
+}
+
+// CreateBatch runs the Before hooks of every forecast ahead of the batch and the After
+// hooks once it is stored, so one rejected forecast rejects the batch
+func (r *hookedForecastRepository) CreateBatch(ctx context.Context, forecasts []*Forecast) error {
+	for _, forecast := range forecasts {
+		if err := r.hooks.before(ctx, &Mutation[Forecast]{Op: OpCreate, Entity: forecast}); err != nil {
+			return err
+		}
+	}
+	if err := r.ForecastRepository.CreateBatch(ctx, forecasts); err != nil {
+		return err
+	}
+	for _, forecast := range forecasts {
+		r.hooks.after(ctx, Mutation[Forecast]{Op: OpCreate, ID: forecast.ID, Entity: forecast})
+	}
+	return nil
+}
+
+func (r *hookedForecastRepository) Update(ctx context.Context, forecast *Forecast) error {
+	return r.hooks.run(ctx, Mutation[Forecast]{Op: OpUpdate, ID: forecast.ID, Entity: forecast},
+		func() error { return r.ForecastRepository.Update(ctx, forecast) }, nil)
+}
+
+func (r *hookedForecastRepository) Delete(ctx context.Context, id int) error {
+	return r.hooks.run(ctx, Mutation[Forecast]{Op: OpDelete, ID: id},
+		func() error { return r.ForecastRepository.Delete(ctx, id) }, nil)
+}
+
+func (r *hookedForecastRepository) DeleteOldForecasts(ctx context.Context, days int, filter ForecastFilter) (int64, error) {
+	var deleted int64
+	err := r.hooks.run(ctx, Mutation[Forecast]{Op: OpDeleteMany},
+		func() (err error) {
+			deleted, err = r.ForecastRepository.DeleteOldForecasts(ctx, days, filter)
+			return err
+		},
+		func(m *Mutation[Forecast]) { m.Count = deleted })
+	return deleted, err
+}
+
+func (r *hookedForecastRepository) DeleteMatching(ctx context.Context, filter ForecastFilter, batchSize int) (int64, error) {
+	var deleted int64
+	err := r.hooks.run(ctx, Mutation[Forecast]{Op: OpDeleteMany},
+		func() (err error) {
+			deleted, err = r.ForecastRepository.DeleteMatching(ctx, filter, batchSize)
+			return err
+		},
+		func(m *Mutation[Forecast]) { m.Count = deleted })
+	return deleted, err
+}
+
+type hookedCityRepository struct {
+	CityRepository
+	hooks *Hooks[City]
+}
+
+func (r *hookedCityRepository) Create(ctx context.Context, city *City) error {
+	return r.hooks.run(ctx, Mutation[City]{Op: OpCreate, Entity: city},
+		func() error { return r.CityRepository.Create(ctx, city) },
+		func(m *Mutation[City]) { m.ID = city.ID })
+}
+
+func (r *hookedCityRepository) Update(ctx context.Context, city *City) error {
+	return r.hooks.run(ctx, Mutation[City]{Op: OpUpdate, ID: city.ID, Entity: city},
+		func() error { return r.CityRepository.Update(ctx, city) }, nil)
+}
+
+func (r *hookedCityRepository) Delete(ctx context.Context, id int) error {
+	return r.hooks.run(ctx, Mutation[City]{Op: OpDelete, ID: id},
+		func() error { return r.CityRepository.Delete(ctx, id) }, nil)
+}
+
+// SetLocalizedName is reported as an update of the city without an entity
+func (r *hookedCityRepository) SetLocalizedName(ctx context.Context, name *CityName) error {
+	return r.hooks.run(ctx, Mutation[City]{Op: OpUpdate, ID: name.CityID},
+		func() error { return r.CityRepository.SetLocalizedName(ctx, name) }, nil)
+}
+
+type hookedPlaceRepository struct {
+	PlaceRepository
+	hooks *Hooks[Place]
+}
+
+func (r *hookedPlaceRepository) Create(ctx context.Context, place *Place) error {
+	return r.hooks.run(ctx, Mutation[Place]{Op: OpCreate, Entity: place},
+		func() error { return r.PlaceRepository.Create(ctx, place) },
+		func(m *Mutation[Place]) { m.ID = place.ID })
+}
+
+func (r *hookedPlaceRepository) Update(ctx context.Context, place *Place) error {
+	return r.hooks.run(ctx, Mutation[Place]{Op: OpUpdate, ID: place.ID, Entity: place},
+		func() error { return r.PlaceRepository.Update(ctx, place) }, nil)
+}
+
+func (r *hookedPlaceRepository) Delete(ctx context.Context, id int) error {
+	return r.hooks.run(ctx, Mutation[Place]{Op: OpDelete, ID: id},
+		func() error { return r.PlaceRepository.Delete(ctx, id) }, nil)
+}
+
+type hookedAlertRepository struct {
+	AlertRepository
+	hooks *Hooks[Alert]
+}
+
+func (r *hookedAlertRepository) Create(ctx context.Context, alert *Alert) error {
+	return r.hooks.run(ctx, Mutation[Alert]{Op: OpCreate, Entity: alert},
+		func() error { return r.AlertRepository.Create(ctx, alert) },
+		func(m *Mutation[Alert]) { m.ID = alert.ID })
+}
+
+func (r *hookedAlertRepository) Upsert(ctx context.Context, alert *Alert) error {
+	return r.hooks.run(ctx, Mutation[Alert]{Op: OpUpsert, Entity: alert},
+		func() error { return r.AlertRepository.Upsert(ctx, alert) },
+		func(m *Mutation[Alert]) { m.ID = alert.ID })
+}
+
+func (r *hookedAlertRepository) Update(ctx context.Context, alert *Alert) error {
+	return r.hooks.run(ctx, Mutation[Alert]{Op: OpUpdate, ID: alert.ID, Entity: alert},
+		func() error { return r.AlertRepository.Update(ctx, alert) }, nil)
+}
+
+func (r *hookedAlertRepository) Delete(ctx context.Context, id int) error {
+	return r.hooks.run(ctx, Mutation[Alert]{Op: OpDelete, ID: id},
+		func() error { return r.AlertRepository.Delete(ctx, id) }, nil)
+}
+
+func (r *hookedAlertRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	err := r.hooks.run(ctx, Mutation[Alert]{Op: OpDeleteMany},
+		func() (err error) {
+			deleted, err = r.AlertRepository.DeleteExpired(ctx)
+			return err
+		},
+		func(m *Mutation[Alert]) { m.Count = deleted })
+	return deleted, err
+}
+
+type hookedAviationReportRepository struct {
+	AviationReportRepository
+	hooks *Hooks[AviationReport]
+}
+
+func (r *hookedAviationReportRepository) Upsert(ctx context.Context, report *AviationReport) error {
+	return r.hooks.run(ctx, Mutation[AviationReport]{Op: OpUpsert, Entity: report},
+		func() error { return r.AviationReportRepository.Upsert(ctx, report) },
+		func(m *Mutation[AviationReport]) { m.ID = report.ID })
+}
+
+func (r *hookedAviationReportRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	var deleted int64
+	err := r.hooks.run(ctx, Mutation[AviationReport]{Op: OpDeleteMany},
+		func() (err error) {
+			deleted, err = r.AviationReportRepository.DeleteOlderThan(ctx, cutoff)
+			return err
+		},
+		func(m *Mutation[AviationReport]) { m.Count = deleted })
+	return deleted, err
+}
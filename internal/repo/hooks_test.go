@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestHookedEngine(t *testing.T) {
+	file, _ := OpenFileEngine("")
+	hooks := &EngineHooks{}
+	engine := NewHookedEngine(file, hooks)
+	ctx := context.Background()
+
+	var seen []string
+	hooks.Cities.Register(Hook[City]{
+		Name: "audit",
+		After: func(ctx context.Context, m Mutation[City]) {
+			seen = append(seen, fmt.Sprintf("%s %d", m.Op, m.ID))
+		},
+	})
+	hooks.Cities.Register(Hook[City]{
+		Name: "defaults",
+		Before: func(ctx context.Context, m *Mutation[City]) error {
+			if m.Entity != nil && m.Entity.Name == "" {
+				return errors.New("name is required")
+			}
+			if m.Entity != nil {
+				m.Entity.CountryCode = "US"
+			}
+			return nil
+		},
+	})
+
+	city := &City{Name: "Portland"}
+	if err := engine.Cities().Create(ctx, city); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if stored, _ := file.Cities().GetByID(ctx, city.ID); stored.CountryCode != "US" {
+		t.Errorf("Expected the Before hook to modify the stored city, got %+v", stored)
+	}
+	if err := engine.Cities().Create(ctx, &City{}); err == nil {
+		t.Error("Expected the Before hook to reject the city")
+	}
+	if count, _ := file.Cities().Count(ctx); count != 1 {
+		t.Errorf("Expected a rejected write not to reach storage, got %d cities", count)
+	}
+	if err := engine.Cities().Delete(ctx, city.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := engine.Cities().Delete(ctx, city.ID); err == nil {
+		t.Error("Expected deleting a missing city to fail")
+	}
+
+	want := []string{fmt.Sprintf("create %d", city.ID), fmt.Sprintf("delete %d", city.ID)}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("Expected After hooks for successful writes only %v, got %v", want, seen)
+	}
+}
+
+func TestHookedEngine_Forecasts(t *testing.T) {
+	file, _ := OpenFileEngine("")
+	hooks := &EngineHooks{}
+	engine := NewHookedEngine(file, hooks)
+	ctx := context.Background()
+
+	var created []int
+	var deleted int64
+	hooks.Forecasts.Register(Hook[Forecast]{
+		Name: "index",
+		After: func(ctx context.Context, m Mutation[Forecast]) {
+			switch m.Op {
+			case OpCreate:
+				created = append(created, m.ID)
+			case OpDeleteMany:
+				deleted += m.Count
+			}
+		},
+	})
+
+	valid := time.Now().UTC().Format(time.RFC3339)
+	batch := []*Forecast{{CityID: 1, ValidTime: valid}, {CityID: 2, ValidTime: valid}}
+	if err := engine.Forecasts().CreateBatch(ctx, batch); err != nil {
+		t.Fatalf("CreateBatch failed: %v", err)
+	}
+	if len(created) != 2 || created[0] != batch[0].ID || created[1] != batch[1].ID {
+		t.Errorf("Expected an After hook per batched forecast, got %v", created)
+	}
+
+	if _, err := engine.Forecasts().DeleteMatching(ctx, ForecastFilter{CityID: 1}, 100); err != nil {
+		t.Fatalf("DeleteMatching failed: %v", err)
+	}
+	if deleted != 1 {
+		t.Errorf("Expected the bulk delete count in the hook, got %d", deleted)
+	}
+}
+
+func TestHookedEngine_Aviation(t *testing.T) {
+	file, _ := OpenFileEngine("")
+	hooks := &EngineHooks{}
+	engine := NewHookedEngine(file, hooks)
+	ctx := context.Background()
+
+	var seen []string
+	hooks.Aviation.Register(Hook[AviationReport]{
+		Name: "mirror",
+		After: func(ctx context.Context, m Mutation[AviationReport]) {
+			seen = append(seen, fmt.Sprintf("%s %d %d", m.Op, m.ID, m.Count))
+		},
+	})
+
+	observed := time.Now().UTC().Add(-48 * time.Hour).Format(time.RFC3339)
+	report := &AviationReport{StationID: "KPDX", ReportType: "METAR", ObservedAt: observed}
+	if err := engine.Aviation().Upsert(ctx, report); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if _, err := engine.Aviation().DeleteOlderThan(ctx, time.Now().UTC().Format(time.RFC3339)); err != nil {
+		t.Fatalf("DeleteOlderThan failed: %v", err)
+	}
+
+	want := []string{fmt.Sprintf("upsert %d 0", report.ID), "delete_many 0 1"}
+	if fmt.Sprint(seen) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, seen)
+	}
+}
@@ -0,0 +1,394 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/lib/pq"
+
+	"stormlightlabs.org/weather_api/internal/geo"
+)
+
+// Integrity check names accepted by IntegrityChecker
+const (
+	CheckOrphanedForecasts  = "orphaned-forecasts"
+	CheckInvalidCoordinates = "invalid-coordinates"
+	CheckBoundingBoxes      = "bounding-boxes"
+	CheckZeroValidTime      = "zero-valid-time"
+	CheckDuplicateForecasts = "duplicate-forecasts"
+)
+
+// IntegrityCheck describes one check and what fixing it does
+type IntegrityCheck struct {
+	Name        string
+	Description string
+	Fix         string
+}
+
+// IntegrityChecks lists every check in the order they run
+var IntegrityChecks = []IntegrityCheck{
+	{CheckOrphanedForecasts, "forecasts whose city no longer exists", "delete the forecasts"},
+	{CheckInvalidCoordinates, "active cities and places with a latitude or longitude out of range", "deactivate the cities and delete the places"},
+	{CheckBoundingBoxes, "places whose bounding box is not a JSON array of four coordinates", "clear the bounding boxes"},
+	{CheckZeroValidTime, "forecasts without a valid time (missing or at the zero time)", "delete the forecasts"},
+	{CheckDuplicateForecasts, "forecasts repeating a city, provider and valid time", "keep the most recently updated forecast of each set"},
+}
+
+// IntegrityIssue is one row found by a check
+type IntegrityIssue struct {
+	Check  string `json:"check"`
+	Table  string `json:"table"`
+	ID     int    `json:"id"`
+	Detail string `json:"detail"`
+}
+
+// IntegrityChecker finds and repairs rows the rest of the application assumes cannot
+// exist, such as those left behind by manual edits or by imports that bypassed
+// validation
+type IntegrityChecker interface {
+	// Find returns up to limit issues found by check (all when limit <= 0) and the
+	// total number found
+	Find(ctx context.Context, check string, limit int) ([]IntegrityIssue, int, error)
+
+	// Fix repairs every issue found by check and returns the number of rows changed
+	Fix(ctx context.Context, check string) (int64, error)
+}
+
+// NewIntegrityChecker returns the checker for engine's backend
+func NewIntegrityChecker(engine Engine) (IntegrityChecker, error) {
+	switch e := engine.(type) {
+	case *PostgreSQLEngine:
+		return &postgresIntegrityChecker{db: e.db}, nil
+	case *FileEngine:
+		return &fileIntegrityChecker{e: e}, nil
+	default:
+		return nil, fmt.Errorf("integrity checks are not supported by the %s engine", engine.Name())
+	}
+}
+
+func unknownCheck(check string) error {
+	return fmt.Errorf("unknown integrity check %q", check)
+}
+
+// validBoundingBox reports whether value is empty or a JSON array of four finite
+// coordinates, given as numbers or numeric strings as Nominatim returns them
+func validBoundingBox(value string) bool {
+	if value == "" {
+		return true
+	}
+	var coordinates []json.RawMessage
+	if err := json.Unmarshal([]byte(value), &coordinates); err != nil || len(coordinates) != 4 {
+		return false
+	}
+	for _, raw := range coordinates {
+		var text string
+		if json.Unmarshal(raw, &text) == nil {
+			raw = json.RawMessage(text)
+		}
+		n, err := strconv.ParseFloat(string(raw), 64)
+		if err != nil || math.IsNaN(n) || math.Abs(n) > 180 {
+			return false
+		}
+	}
+	return true
+}
+
+// validCoordinates reports whether a latitude and longitude are in range
+func validCoordinates(lat, lon float64) bool {
+	return geo.ValidLatitude(lat) && geo.ValidLongitude(lon)
+}
+
+// zeroValidTimeCutoff separates real valid times from zero values: Go's zero time and
+// the Unix epoch both fall before it
+var zeroValidTimeCutoff = time.Date(1970, 1, 2, 0, 0, 0, 0, time.UTC)
+
+// postgresIntegrityChecker runs the checks as SQL. Each query selects (table_name, id,
+// detail) rows.
+type postgresIntegrityChecker struct {
+	db DB
+}
+
+const (
+	orphanedForecastsWhere  = `NOT EXISTS (SELECT 1 FROM cities c WHERE c.id = f.city_id)`
+	invalidCoordinatesWhere = `NOT (latitude BETWEEN -90 AND 90 AND longitude BETWEEN -180 AND 180)`
+	zeroValidTimeWhere      = `valid_time IS NULL OR valid_time < '1970-01-02'`
+
+	// rankedForecasts ranks forecasts sharing a city, provider and valid time, the most
+	// recently updated first
+	rankedForecasts = `SELECT id, city_id, source_provider, valid_time,
+			ROW_NUMBER() OVER (
+				PARTITION BY city_id, source_provider, valid_time ORDER BY updated_at DESC, id DESC
+			) AS rank
+		FROM forecasts`
+)
+
+var integrityQueries = map[string]string{
+	CheckOrphanedForecasts: `SELECT 'forecasts' AS table_name, f.id, 'city ' || f.city_id || ' does not exist' AS detail
+		FROM forecasts f WHERE ` + orphanedForecastsWhere,
+	CheckInvalidCoordinates: `SELECT 'cities' AS table_name, id, 'latitude ' || latitude || ', longitude ' || longitude AS detail
+		FROM cities WHERE is_active AND ` + invalidCoordinatesWhere + `
+		UNION ALL
+		SELECT 'places', id, 'latitude ' || latitude || ', longitude ' || longitude
+		FROM places WHERE ` + invalidCoordinatesWhere,
+	CheckZeroValidTime: `SELECT 'forecasts' AS table_name, id, 'valid_time ' || COALESCE(valid_time::text, 'missing') AS detail
+		FROM forecasts WHERE ` + zeroValidTimeWhere,
+	CheckDuplicateForecasts: `SELECT 'forecasts' AS table_name, id,
+			'repeats city ' || city_id || ', provider ' || source_provider || ', valid_time ' || valid_time AS detail
+		FROM (` + rankedForecasts + `) ranked WHERE rank > 1`,
+}
+
+// Find runs the check's query, or scans bounding boxes in Go as their format is
+// easier to validate there
+func (c *postgresIntegrityChecker) Find(ctx context.Context, check string, limit int) ([]IntegrityIssue, int, error) {
+	if check == CheckBoundingBoxes {
+		issues, err := c.boundingBoxIssues(ctx)
+		if err != nil {
+			return nil, 0, err
+		}
+		return truncateIssues(issues, limit), len(issues), nil
+	}
+
+	query, ok := integrityQueries[check]
+	if !ok {
+		return nil, 0, unknownCheck(check)
+	}
+
+	var total int
+	if err := c.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM (`+query+`) issues`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count %s: %w", check, err)
+	}
+
+	listQuery := `SELECT table_name, id, detail FROM (` + query + `) issues ORDER BY table_name, id`
+	var args []any
+	if limit > 0 {
+		listQuery += ` LIMIT $1`
+		args = append(args, limit)
+	}
+	rows, err := c.db.QueryContext(ctx, listQuery, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to check %s: %w", check, err)
+	}
+	defer rows.Close()
+
+	var issues []IntegrityIssue
+	for rows.Next() {
+		issue := IntegrityIssue{Check: check}
+		if err := rows.Scan(&issue.Table, &issue.ID, &issue.Detail); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan %s: %w", check, err)
+		}
+		issues = append(issues, issue)
+	}
+	return issues, total, rows.Err()
+}
+
+func (c *postgresIntegrityChecker) boundingBoxIssues(ctx context.Context) ([]IntegrityIssue, error) {
+	rows, err := c.db.QueryContext(ctx,
+		`SELECT id, bounding_box FROM places WHERE bounding_box IS NOT NULL AND bounding_box <> '' ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s: %w", CheckBoundingBoxes, err)
+	}
+	defer rows.Close()
+
+	var issues []IntegrityIssue
+	for rows.Next() {
+		var id int
+		var box string
+		if err := rows.Scan(&id, &box); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", CheckBoundingBoxes, err)
+		}
+		if !validBoundingBox(box) {
+			issues = append(issues, boundingBoxIssue(id, box))
+		}
+	}
+	return issues, rows.Err()
+}
+
+// Fix applies the check's repair in a single statement per table
+func (c *postgresIntegrityChecker) Fix(ctx context.Context, check string) (int64, error) {
+	now := time.Now().UTC().Format(time.RFC3339)
+	var statements []string
+	var args [][]any
+	switch check {
+	case CheckOrphanedForecasts:
+		statements = []string{`DELETE FROM forecasts f WHERE ` + orphanedForecastsWhere}
+		args = [][]any{nil}
+	case CheckInvalidCoordinates:
+		statements = []string{
+			`UPDATE cities SET is_active = false, updated_at = $1 WHERE is_active AND ` + invalidCoordinatesWhere,
+			`DELETE FROM places WHERE ` + invalidCoordinatesWhere,
+		}
+		args = [][]any{{now}, nil}
+	case CheckBoundingBoxes:
+		issues, err := c.boundingBoxIssues(ctx)
+		if err != nil || len(issues) == 0 {
+			return 0, err
+		}
+		ids := make([]int64, len(issues))
+		for i, issue := range issues {
+			ids[i] = int64(issue.ID)
+		}
+		statements = []string{`UPDATE places SET bounding_box = '', updated_at = $1 WHERE id = ANY($2)`}
+		args = [][]any{{now, pq.Array(ids)}}
+	case CheckZeroValidTime:
+		statements = []string{`DELETE FROM forecasts WHERE ` + zeroValidTimeWhere}
+		args = [][]any{nil}
+	case CheckDuplicateForecasts:
+		statements = []string{`DELETE FROM forecasts WHERE id IN (SELECT id FROM (` + rankedForecasts + `) ranked WHERE rank > 1)`}
+		args = [][]any{nil}
+	default:
+		return 0, unknownCheck(check)
+	}
+
+	var fixed int64
+	for i, statement := range statements {
+		result, err := c.db.ExecContext(ctx, statement, args[i]...)
+		if err != nil {
+			return fixed, fmt.Errorf("failed to fix %s: %w", check, err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fixed, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		fixed += affected
+	}
+	return fixed, nil
+}
+
+// fileIntegrityChecker runs the checks over the file engine's dataset
+type fileIntegrityChecker struct {
+	e *FileEngine
+}
+
+// Find scans the dataset under the read lock
+func (c *fileIntegrityChecker) Find(ctx context.Context, check string, limit int) ([]IntegrityIssue, int, error) {
+	var issues []IntegrityIssue
+	err := c.e.read(func(d *fileData) error {
+		var err error
+		issues, err = fileIssues(d, check)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return truncateIssues(issues, limit), len(issues), nil
+}
+
+// Fix repairs the issues found under the write lock and persists the dataset once
+func (c *fileIntegrityChecker) Fix(ctx context.Context, check string) (int64, error) {
+	var fixed int64
+	err := c.e.write(func(d *fileData) error {
+		issues, err := fileIssues(d, check)
+		if err != nil {
+			return err
+		}
+		now := time.Now().UTC().Format(time.RFC3339)
+		for _, issue := range issues {
+			switch {
+			case issue.Table == "cities":
+				d.Cities.Rows[issue.ID].IsActive = false
+				d.Cities.Rows[issue.ID].UpdatedAt = now
+				d.Cities.touch(issue.ID)
+			case check == CheckBoundingBoxes:
+				d.Places.Rows[issue.ID].BoundingBox = ""
+				d.Places.Rows[issue.ID].UpdatedAt = now
+				d.Places.touch(issue.ID)
+			case issue.Table == "places":
+				d.Places.remove(issue.ID)
+			default:
+				d.Forecasts.remove(issue.ID)
+			}
+			fixed++
+		}
+		return nil
+	})
+	return fixed, err
+}
+
+// fileIssues returns the issues check finds in d, ordered by table and ID
+func fileIssues(d *fileData, check string) ([]IntegrityIssue, error) {
+	var issues []IntegrityIssue
+	add := func(table string, id int, detail string) {
+		issues = append(issues, IntegrityIssue{Check: check, Table: table, ID: id, Detail: detail})
+	}
+
+	switch check {
+	case CheckOrphanedForecasts:
+		for id, f := range d.Forecasts.Rows {
+			if _, ok := d.Cities.Rows[f.CityID]; !ok {
+				add("forecasts", id, fmt.Sprintf("city %d does not exist", f.CityID))
+			}
+		}
+	case CheckInvalidCoordinates:
+		for id, city := range d.Cities.Rows {
+			if city.IsActive && !validCoordinates(city.Latitude, city.Longitude) {
+				add("cities", id, coordinatesDetail(city.Latitude, city.Longitude))
+			}
+		}
+		for id, place := range d.Places.Rows {
+			if !validCoordinates(place.Latitude, place.Longitude) {
+				add("places", id, coordinatesDetail(place.Latitude, place.Longitude))
+			}
+		}
+	case CheckBoundingBoxes:
+		for id, place := range d.Places.Rows {
+			if !validBoundingBox(place.BoundingBox) {
+				issues = append(issues, boundingBoxIssue(id, place.BoundingBox))
+			}
+		}
+	case CheckZeroValidTime:
+		for id, f := range d.Forecasts.Rows {
+			if parseStoredTime(f.ValidTime).Before(zeroValidTimeCutoff) {
+				add("forecasts", id, "valid_time "+cmp.Or(f.ValidTime, "missing"))
+			}
+		}
+	case CheckDuplicateForecasts:
+		type key struct {
+			cityID   int
+			provider string
+			valid    time.Time
+		}
+		groups := map[key][]*Forecast{}
+		for _, f := range d.Forecasts.Rows {
+			k := key{f.CityID, f.SourceProvider, parseStoredTime(f.ValidTime)}
+			groups[k] = append(groups[k], f)
+		}
+		for k, group := range groups {
+			// The most recently updated forecast comes first and is kept
+			slices.SortFunc(group, func(a, b *Forecast) int {
+				return cmp.Or(parseStoredTime(b.UpdatedAt).Compare(parseStoredTime(a.UpdatedAt)), cmp.Compare(b.ID, a.ID))
+			})
+			for _, f := range group[1:] {
+				add("forecasts", f.ID, fmt.Sprintf("repeats city %d, provider %s, valid_time %s",
+					k.cityID, k.provider, f.ValidTime))
+			}
+		}
+	default:
+		return nil, unknownCheck(check)
+	}
+
+	slices.SortFunc(issues, func(a, b IntegrityIssue) int {
+		return cmp.Or(cmp.Compare(a.Table, b.Table), cmp.Compare(a.ID, b.ID))
+	})
+	return issues, nil
+}
+
+func coordinatesDetail(lat, lon float64) string {
+	return fmt.Sprintf("latitude %g, longitude %g", lat, lon)
+}
+
+func boundingBoxIssue(id int, box string) IntegrityIssue {
+	return IntegrityIssue{Check: CheckBoundingBoxes, Table: "places", ID: id, Detail: fmt.Sprintf("bounding_box %q", box)}
+}
+
+func truncateIssues(issues []IntegrityIssue, limit int) []IntegrityIssue {
+	if limit > 0 && len(issues) > limit {
+		return issues[:limit]
+	}
+	return issues
+}
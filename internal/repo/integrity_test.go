@@ -0,0 +1,150 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestIntegrityChecker(t *testing.T) {
+	ctx := context.Background()
+
+	seed := func(t *testing.T) (*FileEngine, IntegrityChecker) {
+		engine, err := OpenFileEngine("")
+		if err != nil {
+			t.Fatalf("OpenFileEngine failed: %v", err)
+		}
+		t.Cleanup(func() { engine.Close() })
+
+		cities := []*City{
+			{Name: "Oslo", CountryCode: "NO", Latitude: 59.91, Longitude: 10.75, IsActive: true},
+			{Name: "Nowhere", CountryCode: "NO", Latitude: 95, Longitude: 10, IsActive: true},
+		}
+		for _, city := range cities {
+			if err := engine.Cities().Create(ctx, city); err != nil {
+				t.Fatalf("Create city failed: %v", err)
+			}
+		}
+		places := []*Place{
+			{DisplayName: "Karl Johans gate", Latitude: 59.91, Longitude: 10.74, BoundingBox: `["59.9","59.92","10.7","10.8"]`},
+			{DisplayName: "Broken box", Latitude: 59.91, Longitude: 10.74, BoundingBox: `[1, 2]`},
+			{DisplayName: "Off the map", Latitude: 59.91, Longitude: 200},
+		}
+		for _, place := range places {
+			if err := engine.Places().Create(ctx, place); err != nil {
+				t.Fatalf("Create place failed: %v", err)
+			}
+		}
+		forecasts := []*Forecast{
+			{CityID: cities[0].ID, SourceProvider: "NOAA", ValidTime: "2025-08-01T12:00:00Z"},
+			{CityID: cities[0].ID, SourceProvider: "NOAA", ValidTime: "2025-08-01T12:00:00Z"},
+			{CityID: cities[0].ID, SourceProvider: "Met.no", ValidTime: "2025-08-01T12:00:00Z"},
+			{CityID: 999, SourceProvider: "NOAA", ValidTime: "2025-08-01T12:00:00Z"},
+			{CityID: cities[0].ID, SourceProvider: "NOAA", ValidTime: "0001-01-01T00:00:00Z"},
+		}
+		for _, forecast := range forecasts {
+			if err := engine.Forecasts().Create(ctx, forecast); err != nil {
+				t.Fatalf("Create forecast failed: %v", err)
+			}
+		}
+
+		checker, err := NewIntegrityChecker(engine)
+		if err != nil {
+			t.Fatalf("NewIntegrityChecker failed: %v", err)
+		}
+		return engine, checker
+	}
+
+	t.Run("Finds issues", func(t *testing.T) {
+		_, checker := seed(t)
+		tests := map[string][]string{
+			CheckOrphanedForecasts:  {"forecasts 4"},
+			CheckInvalidCoordinates: {"cities 2", "places 3"},
+			CheckBoundingBoxes:      {"places 2"},
+			CheckZeroValidTime:      {"forecasts 5"},
+			CheckDuplicateForecasts: {"forecasts 1"},
+		}
+		for _, check := range IntegrityChecks {
+			issues, total, err := checker.Find(ctx, check.Name, 0)
+			if err != nil {
+				t.Fatalf("Find %s failed: %v", check.Name, err)
+			}
+			want := tests[check.Name]
+			if total != len(want) || len(issues) != len(want) {
+				t.Errorf("Expected %d %s issues, got %d (%+v)", len(want), check.Name, total, issues)
+				continue
+			}
+			for i, issue := range issues {
+				if got := fmt.Sprintf("%s %d", issue.Table, issue.ID); got != want[i] {
+					t.Errorf("Expected %s issue %q, got %q", check.Name, want[i], got)
+				}
+			}
+		}
+	})
+
+	t.Run("Limit truncates issues but not the total", func(t *testing.T) {
+		_, checker := seed(t)
+		issues, total, err := checker.Find(ctx, CheckInvalidCoordinates, 1)
+		if err != nil {
+			t.Fatalf("Find failed: %v", err)
+		}
+		if len(issues) != 1 || total != 2 {
+			t.Errorf("Expected 1 of 2 issues, got %d of %d", len(issues), total)
+		}
+	})
+
+	t.Run("Fixes issues", func(t *testing.T) {
+		engine, checker := seed(t)
+		for _, check := range IntegrityChecks {
+			if _, err := checker.Fix(ctx, check.Name); err != nil {
+				t.Fatalf("Fix %s failed: %v", check.Name, err)
+			}
+			if _, total, err := checker.Find(ctx, check.Name, 0); err != nil || total != 0 {
+				t.Errorf("Expected no %s issues after fixing, got %d (%v)", check.Name, total, err)
+			}
+		}
+
+		city, err := engine.Cities().GetByID(ctx, 2)
+		if err != nil || city.IsActive {
+			t.Errorf("Expected the city with invalid coordinates to be deactivated, got %+v (%v)", city, err)
+		}
+		place, err := engine.Places().GetByID(ctx, 2)
+		if err != nil || place.BoundingBox != "" {
+			t.Errorf("Expected the malformed bounding box to be cleared, got %+v (%v)", place, err)
+		}
+		if _, err := engine.Places().GetByID(ctx, 3); err == nil {
+			t.Error("Expected the place with invalid coordinates to be deleted")
+		}
+		if count, _ := engine.Forecasts().Count(ctx); count != 2 {
+			t.Errorf("Expected 2 forecasts to remain, got %d", count)
+		}
+		if _, err := engine.Forecasts().GetByID(ctx, 2); err != nil {
+			t.Errorf("Expected the most recent duplicate to be kept: %v", err)
+		}
+	})
+
+	t.Run("Unknown check", func(t *testing.T) {
+		_, checker := seed(t)
+		if _, _, err := checker.Find(ctx, "everything", 0); err == nil {
+			t.Error("Expected an error for an unknown check")
+		}
+	})
+}
+
+func TestValidBoundingBox(t *testing.T) {
+	tests := map[string]bool{
+		"":                                true,
+		`[59.9, 59.92, 10.7, 10.8]`:       true,
+		`["59.9","59.92","10.7","10.8"]`:  true,
+		`[59.9, 59.92, 10.7]`:             false,
+		`{"south":59.9}`:                  false,
+		`["north","south","east","west"]`: false,
+		`[0, 0, 0, 500]`:                  false,
+		`59.9,59.92,10.7,10.8`:            false,
+	}
+	for box, want := range tests {
+		if got := validBoundingBox(box); got != want {
+			t.Errorf("validBoundingBox(%q) = %v, want %v", box, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const jobRunColumns = `id, job_name, triggered_by, retry_of, status, started_at, finished_at,
+	duration_ms, processed, skipped, error`
+
+// PostgreSQLJobRunRepository implements JobRunRepository for PostgreSQL
+type PostgreSQLJobRunRepository struct {
+	db DB
+}
+
+// NewPostgreSQLJobRunRepository creates a new PostgreSQL job run repository
+func NewPostgreSQLJobRunRepository(db DB) JobRunRepository {
+	return &PostgreSQLJobRunRepository{db: db}
+}
+
+// Create inserts a new job run
+func (r *PostgreSQLJobRunRepository) Create(ctx context.Context, run *JobRun) error {
+	query := `
+		INSERT INTO job_runs (job_name, triggered_by, retry_of, status, started_at, skipped)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5, $6::jsonb)
+		RETURNING id`
+
+	if run.Skipped == "" {
+		run.Skipped = "[]"
+	}
+	err := r.db.QueryRowContext(ctx, query,
+		run.JobName, run.TriggeredBy, run.RetryOf, run.Status, run.StartedAt, run.Skipped,
+	).Scan(&run.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create job run: %w", classify(err))
+	}
+
+	return nil
+}
+
+// Finish records the outcome of a job run
+func (r *PostgreSQLJobRunRepository) Finish(ctx context.Context, run *JobRun) error {
+	query := `
+		UPDATE job_runs
+		SET status = $2, finished_at = $3, duration_ms = $4, processed = $5, skipped = $6::jsonb, error = $7
+		WHERE id = $1`
+
+	if run.Skipped == "" {
+		run.Skipped = "[]"
+	}
+	result, err := r.db.ExecContext(ctx, query,
+		run.ID, run.Status, run.FinishedAt, run.DurationMS, run.Processed, run.Skipped, run.Error,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to finish job run: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("job run with id %d not found", run.ID)
+	}
+
+	return nil
+}
+
+// GetByID retrieves a job run by ID
+func (r *PostgreSQLJobRunRepository) GetByID(ctx context.Context, id int) (*JobRun, error) {
+	query := `SELECT ` + jobRunColumns + ` FROM job_runs WHERE id = $1`
+
+	run, err := scanJobRun(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("job run with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get job run: %w", err)
+	}
+
+	return run, nil
+}
+
+// List retrieves job runs, newest first
+func (r *PostgreSQLJobRunRepository) List(ctx context.Context, jobName string, limit, offset int) ([]*JobRun, error) {
+	query := `
+		SELECT ` + jobRunColumns + ` FROM job_runs
+		WHERE $1 = '' OR job_name = $1
+		ORDER BY started_at DESC, id DESC
+		LIMIT $2 OFFSET $3`
+
+	rows, err := r.db.QueryContext(ctx, query, jobName, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list job runs: %w", err)
+	}
+	defer rows.Close()
+
+	var runs []*JobRun
+	for rows.Next() {
+		run, err := scanJobRun(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan job run: %w", err)
+		}
+		runs = append(runs, run)
+	}
+
+	return runs, rows.Err()
+}
+
+// FailRunning marks interrupted job runs as failed
+func (r *PostgreSQLJobRunRepository) FailRunning(ctx context.Context, reason string) (int64, error) {
+	query := `
+		UPDATE job_runs
+		SET status = 'failed', finished_at = $1,
+			duration_ms = (EXTRACT(EPOCH FROM ($1::timestamptz - started_at)) * 1000)::bigint, error = $2
+		WHERE status = 'running'`
+
+	result, err := r.db.ExecContext(ctx, query, time.Now().UTC().Format(time.RFC3339), reason)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fail running job runs: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// scanJobRun scans a single job run row, mapping NULL columns to zero values
+func scanJobRun(row rowScanner) (*JobRun, error) {
+	run := &JobRun{}
+	var retryOf sql.NullInt64
+	var finishedAt sql.NullString
+	err := row.Scan(
+		&run.ID, &run.JobName, &run.TriggeredBy, &retryOf, &run.Status, &run.StartedAt, &finishedAt,
+		&run.DurationMS, &run.Processed, &run.Skipped, &run.Error,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	run.RetryOf = int(retryOf.Int64)
+	run.FinishedAt = finishedAt.String
+	return run, nil
+}
@@ -0,0 +1,143 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// SharedStore is a KVStore every instance of a deployment sees, for state such as
+// idempotency keys that must hold across instances. Expired keys stop being visible
+// at once but keep their rows until DeleteExpired removes them.
+type SharedStore interface {
+	KVStore
+
+	// DeleteExpired removes expired keys and returns how many it removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// NewSharedStore returns the shared store of engine's backend, kept in the kv_entries
+// table. Only PostgreSQL is shared between instances; the file engine is used by one
+// process, which can keep such state in a MemoryStore.
+func NewSharedStore(engine Engine) (SharedStore, error) {
+	e, ok := engine.(*PostgreSQLEngine)
+	if !ok {
+		return nil, fmt.Errorf("a shared store is not supported by the %s engine", engine.Name())
+	}
+	return &postgresStore{db: e.db}, nil
+}
+
+// postgresStore implements SharedStore on the kv_entries table. Expiry is computed with
+// the database clock, so instances with skewed clocks agree on it.
+type postgresStore struct {
+	db DB
+}
+
+// kvLive is the condition of rows that have not expired
+const kvLive = `(expires_at IS NULL OR expires_at > NOW())`
+
+// kvExpiry is the expires_at of a ttl in microseconds passed as the given parameter, NULL
+// for no expiry
+func kvExpiry(param string) string {
+	return `CASE WHEN ` + param + `::bigint > 0 THEN NOW() + ` + param + `::bigint * INTERVAL '1 microsecond' END`
+}
+
+// Get returns the value of key, or nil when it is missing or expired
+func (s *postgresStore) Get(ctx context.Context, key string) ([]byte, error) {
+	var value []byte
+	err := s.db.QueryRowContext(ctx, `SELECT value FROM kv_entries WHERE key = $1 AND `+kvLive, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get key: %w", err)
+	}
+	return value, nil
+}
+
+// Set stores value under key for ttl; a ttl of 0 never expires
+func (s *postgresStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO kv_entries (key, value, expires_at) VALUES ($1, $2, `+kvExpiry("$3")+`)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at`,
+		key, value, ttl.Microseconds())
+	if err != nil {
+		return fmt.Errorf("failed to set key: %w", err)
+	}
+	return nil
+}
+
+// SetNX stores value under key unless it already holds a live value. An expired row is
+// taken over in the same statement, so only one caller acquires a key.
+func (s *postgresStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO kv_entries (key, value, expires_at) VALUES ($1, $2, `+kvExpiry("$3")+`)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expires_at = EXCLUDED.expires_at
+		WHERE kv_entries.expires_at IS NOT NULL AND kv_entries.expires_at <= NOW()`,
+		key, value, ttl.Microseconds())
+	if err != nil {
+		return false, fmt.Errorf("failed to set key: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to set key: %w", err)
+	}
+	return affected == 1, nil
+}
+
+// Delete removes key
+func (s *postgresStore) Delete(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM kv_entries WHERE key = $1`, key); err != nil {
+		return fmt.Errorf("failed to delete key: %w", err)
+	}
+	return nil
+}
+
+// Exists reports whether key holds a live value
+func (s *postgresStore) Exists(ctx context.Context, key string) (bool, error) {
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM kv_entries WHERE key = $1 AND `+kvLive+`)`, key).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check key: %w", err)
+	}
+	return exists, nil
+}
+
+// GetTTL returns the time left before key expires, or -1 for missing keys and keys
+// without expiry
+func (s *postgresStore) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	var micros sql.NullInt64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT (EXTRACT(EPOCH FROM expires_at - NOW()) * 1000000)::bigint
+		FROM kv_entries WHERE key = $1 AND `+kvLive, key).Scan(&micros)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !micros.Valid) {
+		return -1, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get key expiry: %w", err)
+	}
+	return time.Duration(micros.Int64) * time.Microsecond, nil
+}
+
+// Clear removes every key
+func (s *postgresStore) Clear(ctx context.Context) error {
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM kv_entries`); err != nil {
+		return fmt.Errorf("failed to clear keys: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes expired keys
+func (s *postgresStore) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM kv_entries WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired keys: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// Close releases nothing; the engine owns the connection
+func (s *postgresStore) Close() error {
+	return nil
+}
@@ -0,0 +1,171 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const userLocationColumns = `id, user_id, name, COALESCE(city_id, 0), latitude, longitude, is_default,
+		   created_at, updated_at`
+
+// PostgreSQLUserLocationRepository implements UserLocationRepository for PostgreSQL
+type PostgreSQLUserLocationRepository struct {
+	db DB
+}
+
+// NewPostgreSQLUserLocationRepository creates a new PostgreSQL saved location repository
+func NewPostgreSQLUserLocationRepository(db DB) UserLocationRepository {
+	return &PostgreSQLUserLocationRepository{db: db}
+}
+
+// Create inserts a new location. Clearing the previous default and inserting happen in
+// one statement, so a user never has two defaults.
+func (r *PostgreSQLUserLocationRepository) Create(ctx context.Context, location *UserLocation) error {
+	query := `
+		WITH cleared AS (
+			UPDATE user_locations SET is_default = FALSE, updated_at = $7
+			WHERE user_id = $1 AND is_default AND $6
+		)
+		INSERT INTO user_locations (user_id, name, city_id, latitude, longitude, is_default, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, 0), $4, $5,
+			$6 OR NOT EXISTS (SELECT 1 FROM user_locations WHERE user_id = $1), $7, $7)
+		RETURNING id, is_default`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		location.UserID, location.Name, location.CityID, location.Latitude, location.Longitude,
+		location.IsDefault, now,
+	).Scan(&location.ID, &location.IsDefault)
+
+	if err != nil {
+		return fmt.Errorf("failed to create location: %w", classify(err))
+	}
+
+	location.CreatedAt = now
+	location.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a location by ID
+func (r *PostgreSQLUserLocationRepository) GetByID(ctx context.Context, id int) (*UserLocation, error) {
+	query := `SELECT ` + userLocationColumns + ` FROM user_locations WHERE id = $1`
+
+	location, err := scanUserLocation(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("location with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get location: %w", err)
+	}
+
+	return location, nil
+}
+
+// ListByUser retrieves a user's locations, the default first and then by name
+func (r *PostgreSQLUserLocationRepository) ListByUser(ctx context.Context, userID int) ([]*UserLocation, error) {
+	query := `SELECT ` + userLocationColumns + ` FROM user_locations WHERE user_id = $1
+		ORDER BY is_default DESC, LOWER(name), id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locations: %w", err)
+	}
+	defer rows.Close()
+
+	var locations []*UserLocation
+	for rows.Next() {
+		location, err := scanUserLocation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan location: %w", err)
+		}
+		locations = append(locations, location)
+	}
+
+	return locations, rows.Err()
+}
+
+// Update changes a location's name, city and coordinates
+func (r *PostgreSQLUserLocationRepository) Update(ctx context.Context, location *UserLocation) error {
+	query := `
+		UPDATE user_locations SET name = $2, city_id = NULLIF($3, 0), latitude = $4, longitude = $5, updated_at = $6
+		WHERE id = $1`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	result, err := r.db.ExecContext(ctx, query,
+		location.ID, location.Name, location.CityID, location.Latitude, location.Longitude, now,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update location: %w", classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("location with id %d not found", location.ID)
+	}
+
+	location.UpdatedAt = now
+	return nil
+}
+
+// SetDefault makes a location its user's default in one statement over the user's
+// locations
+func (r *PostgreSQLUserLocationRepository) SetDefault(ctx context.Context, id int) error {
+	query := `
+		UPDATE user_locations SET is_default = (id = $1), updated_at = $2
+		WHERE user_id = (SELECT user_id FROM user_locations WHERE id = $1) AND (is_default OR id = $1)`
+
+	result, err := r.db.ExecContext(ctx, query, id, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to set default location: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("location with id %d not found", id)
+	}
+
+	return nil
+}
+
+// Delete removes a location
+func (r *PostgreSQLUserLocationRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM user_locations WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete location: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("location with id %d not found", id)
+	}
+
+	return nil
+}
+
+// scanUserLocation scans a single saved location row
+func scanUserLocation(row rowScanner) (*UserLocation, error) {
+	location := &UserLocation{}
+	err := row.Scan(
+		&location.ID, &location.UserID, &location.Name, &location.CityID,
+		&location.Latitude, &location.Longitude, &location.IsDefault,
+		&location.CreatedAt, &location.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return location, nil
+}
@@ -0,0 +1,106 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUserLocationRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ UserLocationRepository = (*PostgreSQLUserLocationRepository)(nil)
+
+		if NewPostgreSQLUserLocationRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLUserLocationRepository returned nil")
+		}
+	})
+
+	t.Run("SetDefault", func(t *testing.T) {
+		repo := NewPostgreSQLUserLocationRepository(&MockDB{})
+		if err := repo.SetDefault(context.Background(), 1); err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+
+		repo = NewPostgreSQLUserLocationRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if err := repo.SetDefault(context.Background(), 1); err == nil {
+			t.Error("Expected error from SetDefault, got nil")
+		}
+	})
+}
+
+func TestFileUserLocationRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+	locations := engine.UserLocations()
+
+	home := &UserLocation{UserID: 1, Name: "Home", Latitude: 41.88, Longitude: -87.63}
+	work := &UserLocation{UserID: 1, Name: "Work", CityID: 3, Latitude: 41.9, Longitude: -87.6}
+	cabin := &UserLocation{UserID: 1, Name: "cabin", Latitude: 45.1, Longitude: -89.2, IsDefault: true}
+	for _, location := range []*UserLocation{home, work, cabin} {
+		if err := locations.Create(ctx, location); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := locations.Create(ctx, &UserLocation{UserID: 2, Name: "Home"}); err != nil {
+		t.Fatalf("Expected another user to reuse the name, got %v", err)
+	}
+	if err := locations.Create(ctx, &UserLocation{UserID: 1, Name: "HOME"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate for a name differing in case, got %v", err)
+	}
+
+	listed, err := locations.ListByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("ListByUser failed: %v", err)
+	}
+	if len(listed) != 3 || listed[0].ID != cabin.ID || listed[1].ID != home.ID || listed[2].ID != work.ID {
+		t.Fatalf("Expected the new default first, then by name, got %+v", listed)
+	}
+	if listed[1].IsDefault {
+		t.Error("Expected the first location to lose the default to the new one")
+	}
+
+	if err := locations.SetDefault(ctx, work.ID); err != nil {
+		t.Fatalf("SetDefault failed: %v", err)
+	}
+	listed, _ = locations.ListByUser(ctx, 1)
+	defaults := 0
+	for _, location := range listed {
+		if location.IsDefault {
+			defaults++
+		}
+	}
+	if listed[0].ID != work.ID || defaults != 1 {
+		t.Errorf("Expected work to be the only default, got %+v", listed)
+	}
+	if err := locations.SetDefault(ctx, 99); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	renamed := *home
+	renamed.Name = "Work"
+	if err := locations.Update(ctx, &renamed); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate renaming onto another location, got %v", err)
+	}
+	renamed.Name, renamed.Latitude = "Apartment", 41.95
+	if err := locations.Update(ctx, &renamed); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	stored, err := locations.GetByID(ctx, home.ID)
+	if err != nil {
+		t.Fatalf("GetByID failed: %v", err)
+	}
+	if stored.Name != "Apartment" || stored.Latitude != 41.95 || stored.IsDefault {
+		t.Errorf("Expected the rename and move to be stored, got %+v", stored)
+	}
+
+	if err := locations.Delete(ctx, home.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := locations.GetByID(ctx, home.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a KVStore held in process memory, for single-instance deployments
+// without an external cache. It holds at most a fixed number of keys: when full,
+// expired keys are dropped first, then the key closest to expiring.
+type MemoryStore struct {
+	mu         sync.Mutex
+	entries    map[string]memoryEntry
+	maxEntries int
+	now        func() time.Time
+}
+
+type memoryEntry struct {
+	value   []byte
+	expires time.Time // zero for no expiry
+}
+
+// NewMemoryStore creates a store holding at most maxEntries keys
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{entries: make(map[string]memoryEntry), maxEntries: max(maxEntries, 1), now: time.Now}
+}
+
+// get returns the live entry for key, dropping it when expired. The caller holds mu.
+func (s *MemoryStore) get(key string) (memoryEntry, bool) {
+	entry, ok := s.entries[key]
+	if ok && !entry.expires.IsZero() && !s.now().Before(entry.expires) {
+		delete(s.entries, key)
+		return memoryEntry{}, false
+	}
+	return entry, ok
+}
+
+// Get returns the value of key, or nil when it is missing or expired
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, _ := s.get(key)
+	return entry.value, nil
+}
+
+// Set stores value under key for ttl; a ttl of 0 never expires
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, ttl)
+	return nil
+}
+
+// set stores an entry, evicting one when the store is full. The caller holds mu.
+func (s *MemoryStore) set(key string, value []byte, ttl time.Duration) {
+	if _, ok := s.entries[key]; !ok && len(s.entries) >= s.maxEntries {
+		s.evict()
+	}
+	entry := memoryEntry{value: append([]byte(nil), value...)}
+	if ttl > 0 {
+		entry.expires = s.now().Add(ttl)
+	}
+	s.entries[key] = entry
+}
+
+// evict drops expired entries, or the one expiring soonest when none has expired
+func (s *MemoryStore) evict() {
+	var victim string
+	var soonest time.Time
+	now := s.now()
+	for key, entry := range s.entries {
+		if entry.expires.IsZero() {
+			continue
+		}
+		if !now.Before(entry.expires) {
+			delete(s.entries, key)
+			continue
+		}
+		if victim == "" || entry.expires.Before(soonest) {
+			victim, soonest = key, entry.expires
+		}
+	}
+	if len(s.entries) < s.maxEntries {
+		return
+	}
+	if victim == "" {
+		for key := range s.entries {
+			victim = key
+			break
+		}
+	}
+	delete(s.entries, victim)
+}
+
+// Delete removes key
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries, key)
+	return nil
+}
+
+// Exists reports whether key holds a live value
+func (s *MemoryStore) Exists(ctx context.Context, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.get(key)
+	return ok, nil
+}
+
+// SetNX stores value under key unless it already holds a live value
+func (s *MemoryStore) SetNX(ctx context.Context, key string, value []byte, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, value, ttl)
+	return true, nil
+}
+
+// GetTTL returns the time left before key expires, or -1 for missing keys and keys
+// without expiry
+func (s *MemoryStore) GetTTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.get(key)
+	if !ok || entry.expires.IsZero() {
+		return -1, nil
+	}
+	return entry.expires.Sub(s.now()), nil
+}
+
+// Clear removes every key
+func (s *MemoryStore) Clear(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	clear(s.entries)
+	return nil
+}
+
+// Close releases nothing; the store stays usable
+func (s *MemoryStore) Close() error {
+	return nil
+}
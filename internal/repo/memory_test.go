@@ -0,0 +1,67 @@
+package repo
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(3)
+	store.now = func() time.Time { return now }
+
+	_ = store.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = store.Set(ctx, "b", []byte("2"), 0)
+	if value, _ := store.Get(ctx, "a"); string(value) != "1" {
+		t.Errorf("Expected a = 1, got %q", value)
+	}
+	if ttl, _ := store.GetTTL(ctx, "a"); ttl != time.Minute {
+		t.Errorf("Expected a TTL of 1m, got %s", ttl)
+	}
+	if ttl, _ := store.GetTTL(ctx, "b"); ttl != -1 {
+		t.Errorf("Expected -1 for a key without expiry, got %s", ttl)
+	}
+	if ok, _ := store.SetNX(ctx, "a", []byte("x"), 0); ok {
+		t.Error("Expected SetNX to keep a live key")
+	}
+
+	now = now.Add(time.Minute)
+	if value, _ := store.Get(ctx, "a"); value != nil {
+		t.Errorf("Expected a to expire, got %q", value)
+	}
+	if ok, _ := store.Exists(ctx, "a"); ok {
+		t.Error("Expected a to no longer exist")
+	}
+	if ok, _ := store.SetNX(ctx, "a", []byte("3"), time.Hour); !ok {
+		t.Error("Expected SetNX to replace an expired key")
+	}
+
+	t.Run("evicts the entry expiring soonest when full", func(t *testing.T) {
+		_ = store.Set(ctx, "c", []byte("4"), time.Second)
+		_ = store.Set(ctx, "d", []byte("5"), time.Hour)
+		if ok, _ := store.Exists(ctx, "c"); ok {
+			t.Error("Expected c to be evicted")
+		}
+		for _, key := range []string{"a", "b", "d"} {
+			if ok, _ := store.Exists(ctx, key); !ok {
+				t.Errorf("Expected %s to be kept", key)
+			}
+		}
+	})
+
+	t.Run("copies values", func(t *testing.T) {
+		value := []byte("mutable")
+		_ = store.Set(ctx, "b", value, 0)
+		value[0] = 'M'
+		if got, _ := store.Get(ctx, "b"); string(got) != "mutable" {
+			t.Errorf("Expected the stored copy, got %q", got)
+		}
+	})
+
+	_ = store.Clear(ctx)
+	if ok, _ := store.Exists(ctx, "b"); ok {
+		t.Error("Expected Clear to remove every key")
+	}
+}
@@ -0,0 +1,224 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const stationColumns = `id, station_id, name, source_provider, latitude, longitude, elevation,
+		   time_zone, created_at, updated_at`
+
+const observationColumns = `id, station_id, source_provider, observed_at, temperature, dewpoint,
+		   humidity, wind_direction, wind_speed, wind_gust, pressure, station_pressure,
+		   visibility, description, qc_flags, created_at, updated_at`
+
+// PostgreSQLStationRepository implements StationRepository for PostgreSQL
+type PostgreSQLStationRepository struct {
+	db DB
+}
+
+// NewPostgreSQLStationRepository creates a new PostgreSQL station repository
+func NewPostgreSQLStationRepository(db DB) StationRepository {
+	return &PostgreSQLStationRepository{db: db}
+}
+
+// Upsert inserts a station, or refreshes the existing row with the same station_id
+func (r *PostgreSQLStationRepository) Upsert(ctx context.Context, station *Station) error {
+	query := `
+		INSERT INTO stations (
+			station_id, name, source_provider, latitude, longitude, elevation, time_zone,
+			created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
+		ON CONFLICT (station_id) DO UPDATE SET
+			name = EXCLUDED.name, source_provider = EXCLUDED.source_provider,
+			latitude = EXCLUDED.latitude, longitude = EXCLUDED.longitude,
+			elevation = EXCLUDED.elevation, time_zone = EXCLUDED.time_zone,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		station.StationID, station.Name, station.SourceProvider, station.Latitude,
+		station.Longitude, station.Elevation, station.TimeZone, now,
+	).Scan(&station.ID, &station.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert station: %w", classify(err))
+	}
+
+	station.UpdatedAt = now
+	return nil
+}
+
+// GetByStationID retrieves a station by its network code
+func (r *PostgreSQLStationRepository) GetByStationID(ctx context.Context, stationID string) (*Station, error) {
+	query := `SELECT ` + stationColumns + ` FROM stations WHERE station_id = $1`
+
+	station, err := scanStation(reader(r.db).QueryRowContext(ctx, query, stationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("station %s not found", stationID)
+		}
+		return nil, fmt.Errorf("failed to get station: %w", err)
+	}
+
+	return station, nil
+}
+
+// List retrieves stations ordered by station ID
+func (r *PostgreSQLStationRepository) List(ctx context.Context, limit, offset int) ([]*Station, error) {
+	query := `SELECT ` + stationColumns + ` FROM stations ORDER BY station_id LIMIT $1 OFFSET $2`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stations: %w", err)
+	}
+	defer rows.Close()
+
+	var stations []*Station
+	for rows.Next() {
+		station, err := scanStation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan station: %w", err)
+		}
+		stations = append(stations, station)
+	}
+
+	return stations, rows.Err()
+}
+
+func scanStation(row rowScanner) (*Station, error) {
+	station := &Station{}
+	err := row.Scan(
+		&station.ID, &station.StationID, &station.Name, &station.SourceProvider,
+		&station.Latitude, &station.Longitude, &station.Elevation, &station.TimeZone,
+		&station.CreatedAt, &station.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return station, nil
+}
+
+// PostgreSQLObservationRepository implements ObservationRepository for PostgreSQL
+type PostgreSQLObservationRepository struct {
+	db DB
+}
+
+// NewPostgreSQLObservationRepository creates a new PostgreSQL observation repository
+func NewPostgreSQLObservationRepository(db DB) ObservationRepository {
+	return &PostgreSQLObservationRepository{db: db}
+}
+
+// Upsert inserts an observation, or refreshes the existing row with the same
+// (station_id, observed_at): providers revise QC flags after the fact, so polling a
+// station picks up the latest assessment without creating duplicates
+func (r *PostgreSQLObservationRepository) Upsert(ctx context.Context, observation *Observation) error {
+	query := `
+		INSERT INTO observations (
+			station_id, source_provider, observed_at, temperature, dewpoint, humidity,
+			wind_direction, wind_speed, wind_gust, pressure, station_pressure, visibility,
+			description, qc_flags, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14::jsonb, $15, $15)
+		ON CONFLICT (station_id, observed_at) DO UPDATE SET
+			source_provider = EXCLUDED.source_provider,
+			temperature = EXCLUDED.temperature, dewpoint = EXCLUDED.dewpoint,
+			humidity = EXCLUDED.humidity, wind_direction = EXCLUDED.wind_direction,
+			wind_speed = EXCLUDED.wind_speed, wind_gust = EXCLUDED.wind_gust,
+			pressure = EXCLUDED.pressure, station_pressure = EXCLUDED.station_pressure,
+			visibility = EXCLUDED.visibility, description = EXCLUDED.description,
+			qc_flags = EXCLUDED.qc_flags, updated_at = EXCLUDED.updated_at
+		RETURNING id, created_at`
+
+	if observation.QualityFlags == "" {
+		observation.QualityFlags = "{}"
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		observation.StationID, observation.SourceProvider, observation.ObservedAt,
+		observation.Temperature, observation.Dewpoint, observation.Humidity,
+		observation.WindDirection, observation.WindSpeed, observation.WindGust,
+		observation.Pressure, observation.StationPressure, observation.Visibility,
+		observation.Description, observation.QualityFlags, now,
+	).Scan(&observation.ID, &observation.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("failed to upsert observation: %w", classify(err))
+	}
+
+	observation.UpdatedAt = now
+	return nil
+}
+
+// GetLatest retrieves the most recent observation of a station
+func (r *PostgreSQLObservationRepository) GetLatest(ctx context.Context, stationID string) (*Observation, error) {
+	query := `SELECT ` + observationColumns + ` FROM observations
+		WHERE station_id = $1 ORDER BY observed_at DESC LIMIT 1`
+
+	observation, err := scanObservation(reader(r.db).QueryRowContext(ctx, query, stationID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("no observations found for station %s", stationID)
+		}
+		return nil, fmt.Errorf("failed to get latest observation: %w", err)
+	}
+
+	return observation, nil
+}
+
+// GetByStation retrieves a station's observations made at or after since, newest first
+func (r *PostgreSQLObservationRepository) GetByStation(ctx context.Context, stationID, since string, limit int) ([]*Observation, error) {
+	query := `SELECT ` + observationColumns + ` FROM observations
+		WHERE station_id = $1 AND observed_at >= $2
+		ORDER BY observed_at DESC LIMIT $3`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, stationID, since, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get observations by station: %w", err)
+	}
+	defer rows.Close()
+
+	var observations []*Observation
+	for rows.Next() {
+		observation, err := scanObservation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan observation: %w", err)
+		}
+		observations = append(observations, observation)
+	}
+
+	return observations, rows.Err()
+}
+
+// DeleteOlderThan removes observations made before cutoff
+func (r *PostgreSQLObservationRepository) DeleteOlderThan(ctx context.Context, cutoff string) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM observations WHERE observed_at < $1`, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete old observations: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+func scanObservation(row rowScanner) (*Observation, error) {
+	observation := &Observation{}
+	err := row.Scan(
+		&observation.ID, &observation.StationID, &observation.SourceProvider,
+		&observation.ObservedAt, &observation.Temperature, &observation.Dewpoint,
+		&observation.Humidity, &observation.WindDirection, &observation.WindSpeed,
+		&observation.WindGust, &observation.Pressure, &observation.StationPressure,
+		&observation.Visibility, &observation.Description, &observation.QualityFlags,
+		&observation.CreatedAt, &observation.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return observation, nil
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestObservationRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ StationRepository = (*PostgreSQLStationRepository)(nil)
+		var _ ObservationRepository = (*PostgreSQLObservationRepository)(nil)
+
+		if NewPostgreSQLObservationRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLObservationRepository returned nil")
+		}
+	})
+
+	t.Run("Query errors", func(t *testing.T) {
+		repo := NewPostgreSQLObservationRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		observations, err := repo.GetByStation(context.Background(), "KNYC", "2024-01-01T00:00:00Z", 10)
+		if err == nil {
+			t.Error("Expected error from GetByStation, got nil")
+		}
+		if observations != nil {
+			t.Error("Expected nil observations on error")
+		}
+
+		stations := NewPostgreSQLStationRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if _, err := stations.List(context.Background(), 10, 0); err == nil {
+			t.Error("Expected error from List, got nil")
+		}
+	})
+
+	t.Run("DeleteOlderThan", func(t *testing.T) {
+		repo := NewPostgreSQLObservationRepository(&MockDB{})
+		deleted, err := repo.DeleteOlderThan(context.Background(), "2024-01-01T00:00:00Z")
+		if err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("Expected 1 deleted row, got %d", deleted)
+		}
+	})
+}
+
+func TestFileObservationRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	station := &Station{StationID: "KNYC", Name: "New York City, Central Park", SourceProvider: "NWS", Latitude: 40.78, Longitude: -73.97}
+	if err := engine.Stations().Upsert(ctx, station); err != nil {
+		t.Fatalf("Upsert station failed: %v", err)
+	}
+	renamed := &Station{StationID: "KNYC", Name: "Central Park", SourceProvider: "NWS", Latitude: 40.78, Longitude: -73.97}
+	if err := engine.Stations().Upsert(ctx, renamed); err != nil {
+		t.Fatalf("Upsert station failed: %v", err)
+	}
+	if renamed.ID != station.ID {
+		t.Errorf("Expected the second upsert to refresh station %d, got %d", station.ID, renamed.ID)
+	}
+	if got, err := engine.Stations().GetByStationID(ctx, "KNYC"); err != nil || got.Name != "Central Park" {
+		t.Errorf("Expected the refreshed station, got %+v (%v)", got, err)
+	}
+	if _, err := engine.Stations().GetByStationID(ctx, "KLGA"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected not found for an unknown station, got %v", err)
+	}
+
+	temperature := 21.5
+	observations := []*Observation{
+		{StationID: "KNYC", SourceProvider: "NWS", ObservedAt: "2025-08-01T10:51:00Z"},
+		{StationID: "KNYC", SourceProvider: "NWS", ObservedAt: "2025-08-01T11:51:00Z"},
+		{StationID: "KNYC", SourceProvider: "NWS", ObservedAt: "2025-08-01T11:51:00Z", Temperature: &temperature, QualityFlags: `{"temperature":"V"}`},
+	}
+	for _, o := range observations {
+		if err := engine.Observations().Upsert(ctx, o); err != nil {
+			t.Fatalf("Upsert observation failed: %v", err)
+		}
+	}
+	if observations[0].QualityFlags != "{}" {
+		t.Errorf("Expected empty QC flags to be stored as {}, got %q", observations[0].QualityFlags)
+	}
+
+	latest, err := engine.Observations().GetLatest(ctx, "KNYC")
+	if err != nil {
+		t.Fatalf("GetLatest failed: %v", err)
+	}
+	if latest.ID != observations[1].ID || latest.Temperature == nil || *latest.Temperature != 21.5 {
+		t.Errorf("Expected the revised 11:51 observation, got %+v", latest)
+	}
+
+	recent, err := engine.Observations().GetByStation(ctx, "KNYC", "2025-08-01T11:00:00Z", 10)
+	if err != nil || len(recent) != 1 {
+		t.Errorf("Expected 1 observation since 11:00, got %d (%v)", len(recent), err)
+	}
+
+	deleted, err := engine.Observations().DeleteOlderThan(ctx, "2025-08-01T11:00:00Z")
+	if err != nil || deleted != 1 {
+		t.Errorf("Expected 1 observation deleted, got %d (%v)", deleted, err)
+	}
+}
@@ -0,0 +1,66 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// The PostgreSQL forecasts table is range partitioned by the month (UTC) of valid_time
+// into forecasts_pYYYYMM tables, with forecasts_default holding rows of months not yet
+// created. The create_forecast_partition and drop_forecast_partitions functions added
+// by the partitioning migration do the work, each in one statement.
+
+// ForecastPartitioner manages the monthly partitions of the forecasts table
+type ForecastPartitioner interface {
+	// EnsurePartitions creates the partitions of the month of from and the months after
+	// it up to months later that do not exist yet, and returns how many it created
+	EnsurePartitions(ctx context.Context, from time.Time, months int) (int, error)
+}
+
+// NewForecastPartitioner returns the partitioner of engine's backend. Only PostgreSQL
+// partitions forecasts.
+func NewForecastPartitioner(engine Engine) (ForecastPartitioner, error) {
+	e, ok := engine.(*PostgreSQLEngine)
+	if !ok {
+		return nil, fmt.Errorf("forecast partitioning is not supported by the %s engine", engine.Name())
+	}
+	return &postgresPartitioner{db: e.db}, nil
+}
+
+type postgresPartitioner struct {
+	db DB
+}
+
+// EnsurePartitions creates the missing partitions one month at a time
+func (p *postgresPartitioner) EnsurePartitions(ctx context.Context, from time.Time, months int) (int, error) {
+	if months < 0 {
+		return 0, fmt.Errorf("months must not be negative, got %d", months)
+	}
+
+	from = from.UTC()
+	first := time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+	created := 0
+	for i := range months + 1 {
+		month := first.AddDate(0, i, 0)
+		var ok bool
+		if err := p.db.QueryRowContext(ctx, `SELECT create_forecast_partition($1::date)`, month.Format(time.DateOnly)).Scan(&ok); err != nil {
+			return created, fmt.Errorf("failed to create forecast partition for %s: %w", month.Format("2006-01"), err)
+		}
+		if ok {
+			created++
+		}
+	}
+	return created, nil
+}
+
+// dropForecastPartitions drops the monthly partitions holding only forecasts valid more
+// than days ago and returns the number of rows they held
+func dropForecastPartitions(ctx context.Context, db DB, days int) (int64, error) {
+	var dropped int64
+	err := db.QueryRowContext(ctx, `SELECT drop_forecast_partitions(NOW() - make_interval(days => $1))`, days).Scan(&dropped)
+	if err != nil {
+		return 0, fmt.Errorf("failed to drop forecast partitions: %w", err)
+	}
+	return dropped, nil
+}
@@ -0,0 +1,145 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func init() {
+	sql.Register("repo-scalar", scalarDriver{})
+}
+
+// scalarDriver answers every query with one row holding the value of its DSN: a number,
+// or true/false
+type scalarDriver struct{}
+
+func (scalarDriver) Open(dsn string) (driver.Conn, error) { return scalarConn(dsn), nil }
+
+type scalarConn string
+
+func (c scalarConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c scalarConn) Close() error                        { return nil }
+func (c scalarConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c scalarConn) QueryContext(context.Context, string, []driver.NamedValue) (driver.Rows, error) {
+	var value driver.Value = string(c)
+	if n, err := strconv.ParseInt(string(c), 10, 64); err == nil {
+		value = n
+	} else if b, err := strconv.ParseBool(string(c)); err == nil {
+		value = b
+	}
+	return &scalarRows{value: value}, nil
+}
+
+type scalarRows struct {
+	value driver.Value
+	done  bool
+}
+
+func (r *scalarRows) Columns() []string { return []string{"value"} }
+func (r *scalarRows) Close() error      { return nil }
+func (r *scalarRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	r.done = true
+	dest[0] = r.value
+	return nil
+}
+
+// scalarDB is an execRecorder whose single-row queries return value, recording them
+type scalarDB struct {
+	execRecorder
+	rows    *sql.DB
+	queries []string
+	args    [][]any
+}
+
+func newScalarDB(t *testing.T, value string) *scalarDB {
+	rows, err := sql.Open("repo-scalar", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rows.Close() })
+	return &scalarDB{rows: rows}
+}
+
+func (d *scalarDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args)
+	return d.rows.QueryRowContext(ctx, query, args...)
+}
+
+func TestEnsurePartitions(t *testing.T) {
+	db := newScalarDB(t, "true")
+	partitioner := &postgresPartitioner{db: db}
+
+	// Mid-month in a zone ahead of UTC is still the UTC month
+	from := time.Date(2025, 12, 1, 0, 30, 0, 0, time.FixedZone("CET", 60*60))
+	created, err := partitioner.EnsurePartitions(context.Background(), from, 2)
+	if err != nil || created != 3 {
+		t.Fatalf("Expected three partitions, got %d (%v)", created, err)
+	}
+	var months []any
+	for i, query := range db.queries {
+		if !strings.Contains(query, "create_forecast_partition($1::date)") {
+			t.Errorf("Unexpected query %q", query)
+		}
+		months = append(months, db.args[i]...)
+	}
+	if !slices.Equal(months, []any{"2025-11-01", "2025-12-01", "2026-01-01"}) {
+		t.Errorf("Expected November through January, got %v", months)
+	}
+
+	existing := &postgresPartitioner{db: newScalarDB(t, "false")}
+	if created, err := existing.EnsurePartitions(context.Background(), from, 1); err != nil || created != 0 {
+		t.Errorf("Expected existing partitions to be left alone, got %d (%v)", created, err)
+	}
+	if _, err := partitioner.EnsurePartitions(context.Background(), from, -1); err == nil {
+		t.Error("Expected an error for negative months")
+	}
+}
+
+func TestNewForecastPartitioner(t *testing.T) {
+	if _, err := NewForecastPartitioner(NewPostgreSQLEngine(&MockDB{})); err != nil {
+		t.Errorf("Expected a PostgreSQL partitioner, got %v", err)
+	}
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+	if _, err := NewForecastPartitioner(engine); err == nil {
+		t.Error("Expected the file engine to be unsupported")
+	}
+}
+
+func TestDeleteOldForecastsDropsPartitions(t *testing.T) {
+	db := newScalarDB(t, "5")
+	deleted, err := NewPostgreSQLForecastRepository(db).DeleteOldForecasts(context.Background(), 30, ForecastFilter{})
+	if err != nil || deleted != 6 {
+		t.Fatalf("Expected 5 dropped and 1 deleted, got %d (%v)", deleted, err)
+	}
+	if len(db.queries) != 1 || !strings.Contains(db.queries[0], "drop_forecast_partitions") || !slices.Equal(db.args[0], []any{30}) {
+		t.Errorf("Expected the partitions past 30 days dropped, got %q %v", db.queries, db.args)
+	}
+	if !strings.HasPrefix(db.query, "DELETE FROM forecasts") {
+		t.Errorf("Expected the remainder deleted, got %q", db.query)
+	}
+
+	filtered := newScalarDB(t, "5")
+	if deleted, err := NewPostgreSQLForecastRepository(filtered).DeleteOldForecasts(context.Background(), 30, ForecastFilter{CityID: 1}); err != nil || deleted != 1 {
+		t.Errorf("Expected only the DELETE for a filter, got %d (%v)", deleted, err)
+	}
+	if len(filtered.queries) != 0 {
+		t.Errorf("Expected no partitions dropped for a filter, got %q", filtered.queries)
+	}
+}
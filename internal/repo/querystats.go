@@ -0,0 +1,141 @@
+package repo
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"expvar"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QueryTuningThreshold is the share of a method's queries ending past their deadline
+// above which the tuning report flags it
+const QueryTuningThreshold = 0.01
+
+// QueryStat summarizes the queries of one repository method. The budget is how much
+// time the caller's context had left when a query started, averaged over the queries
+// that had a deadline.
+type QueryStat struct {
+	Method           string  `json:"method"` // e.g. ForecastRepository.GetByCityID
+	Calls            int64   `json:"calls"`
+	DeadlineExceeded int64   `json:"deadline_exceeded"`
+	Canceled         int64   `json:"canceled"`
+	CancelRate       float64 `json:"cancel_rate"` // (deadline_exceeded + canceled) / calls
+	MeanMS           float64 `json:"mean_ms"`
+	MaxMS            float64 `json:"max_ms"`
+	MeanBudgetMS     float64 `json:"mean_budget_ms,omitempty"`
+	NeedsTuning      bool    `json:"needs_tuning"` // deadline share above QueryTuningThreshold
+}
+
+// queryCounters accumulate one method's queries
+type queryCounters struct {
+	calls, deadlineExceeded, canceled int64
+	total, max                        time.Duration
+	budgets                           int64
+	budget                            time.Duration
+}
+
+// queryStats holds the counters of every method, recorded by the traced DB
+var queryStats = struct {
+	sync.Mutex
+	methods map[string]*queryCounters
+}{methods: map[string]*queryCounters{}}
+
+func init() {
+	expvar.Publish("repository_queries", expvar.Func(func() any { return QueryReport() }))
+}
+
+// recordQuery counts one query of the calling repository method. Canceled contexts are
+// the client going away and deadlines a budget running out; they are counted apart.
+func recordQuery(ctx context.Context, started time.Time, err error) {
+	elapsed := time.Since(started)
+	method := queryMethod()
+
+	queryStats.Lock()
+	defer queryStats.Unlock()
+	counters, ok := queryStats.methods[method]
+	if !ok {
+		counters = &queryCounters{}
+		queryStats.methods[method] = counters
+	}
+	counters.calls++
+	counters.total += elapsed
+	counters.max = max(counters.max, elapsed)
+	if deadline, ok := ctx.Deadline(); ok {
+		counters.budgets++
+		counters.budget += deadline.Sub(started)
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		counters.deadlineExceeded++
+	case errors.Is(err, context.Canceled):
+		counters.canceled++
+	}
+}
+
+// QueryReport returns the query statistics of every repository method, those whose
+// deadlines run out most often first
+func QueryReport() []QueryStat {
+	queryStats.Lock()
+	defer queryStats.Unlock()
+
+	report := make([]QueryStat, 0, len(queryStats.methods))
+	for method, c := range queryStats.methods {
+		stat := QueryStat{
+			Method:           method,
+			Calls:            c.calls,
+			DeadlineExceeded: c.deadlineExceeded,
+			Canceled:         c.canceled,
+			CancelRate:       float64(c.deadlineExceeded+c.canceled) / float64(c.calls),
+			MeanMS:           milliseconds(c.total / time.Duration(c.calls)),
+			MaxMS:            milliseconds(c.max),
+			NeedsTuning:      float64(c.deadlineExceeded)/float64(c.calls) > QueryTuningThreshold,
+		}
+		if c.budgets > 0 {
+			stat.MeanBudgetMS = milliseconds(c.budget / time.Duration(c.budgets))
+		}
+		report = append(report, stat)
+	}
+	slices.SortFunc(report, func(a, b QueryStat) int {
+		return cmp.Or(cmp.Compare(b.DeadlineExceeded, a.DeadlineExceeded), cmp.Compare(b.Canceled, a.Canceled), strings.Compare(a.Method, b.Method))
+	})
+	return report
+}
+
+// milliseconds converts d to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// ResetQueryStats forgets every recorded query
+func ResetQueryStats() {
+	queryStats.Lock()
+	defer queryStats.Unlock()
+	clear(queryStats.methods)
+}
+
+// repositoryMethod matches an exported PostgreSQL repository method on a call stack,
+// e.g. ".(*PostgreSQLForecastRepository).GetByCityID.func1". Unexported helpers such as
+// keysetPage are skipped so their queries count toward the method that called them.
+var repositoryMethod = regexp.MustCompile(`internal/repo\.\(\*PostgreSQL(\w+)\)\.([A-Z]\w*)`)
+
+// queryMethod names the repository method that issued the current query, or "other"
+// for queries made outside a repository (migrations, integrity checks)
+func queryMethod() string {
+	pcs := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		frame, more := frames.Next()
+		if match := repositoryMethod.FindStringSubmatch(frame.Function); match != nil {
+			return match[1] + "." + match[2]
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"testing"
+	"time"
+)
+
+// contextDB fails statements with their context's error, as the driver does once a
+// deadline passes or the caller goes away. Queries fail even on a live context since
+// there are no rows to return.
+type contextDB struct{}
+
+func (contextDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, sql.ErrConnDone
+}
+
+func (contextDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+func (contextDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return &MockResult{rowsAffected: 1}, nil
+}
+
+func TestQueryStats(t *testing.T) {
+	ResetQueryStats()
+	defer ResetQueryStats()
+
+	forecasts := NewPostgreSQLForecastRepository(NewTracedDB(contextDB{}))
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+	_, _ = forecasts.List(expired, 10, 0)
+
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	_, _ = forecasts.List(canceled, 10, 0)
+
+	budgeted, cancelLater := context.WithTimeout(context.Background(), time.Minute)
+	defer cancelLater()
+	_ = forecasts.Delete(budgeted, 1)
+	_ = forecasts.Delete(context.Background(), 2)
+
+	report := QueryReport()
+	if len(report) != 2 {
+		t.Fatalf("Expected stats for 2 methods, got %+v", report)
+	}
+
+	list := report[0]
+	if list.Method != "ForecastRepository.List" || list.Calls != 2 || list.DeadlineExceeded != 1 || list.Canceled != 1 {
+		t.Errorf("Expected List first with a deadline and a cancellation, got %+v", list)
+	}
+	if list.CancelRate != 1 || !list.NeedsTuning {
+		t.Errorf("Expected List to be flagged for tuning, got %+v", list)
+	}
+
+	remove := report[1]
+	if remove.Method != "ForecastRepository.Delete" || remove.Calls != 2 || remove.CancelRate != 0 || remove.NeedsTuning {
+		t.Errorf("Expected Delete to succeed untouched, got %+v", remove)
+	}
+	if remove.MeanBudgetMS <= 0 || remove.MeanBudgetMS > float64(time.Minute.Milliseconds()) {
+		t.Errorf("Expected the budget of the deadline-bound Delete, got %vms", remove.MeanBudgetMS)
+	}
+
+	if expvar.Get("repository_queries") == nil {
+		t.Error("Expected query stats to be published as an expvar")
+	}
+}
+
+func TestQueryMethodOutsideRepository(t *testing.T) {
+	ResetQueryStats()
+	defer ResetQueryStats()
+
+	_, _ = NewTracedDB(contextDB{}).ExecContext(context.Background(), "SELECT 1")
+	if report := QueryReport(); len(report) != 1 || report[0].Method != "other" {
+		t.Errorf("Expected queries outside a repository to be grouped as other, got %+v", report)
+	}
+}
+
+func TestQueryMethodSkipsHelpers(t *testing.T) {
+	ResetQueryStats()
+	defer ResetQueryStats()
+
+	forecasts := NewPostgreSQLForecastRepository(NewTracedDB(contextDB{}))
+	_, _ = forecasts.GetByCityIDAfter(context.Background(), 1, nil, 10)
+	if report := QueryReport(); len(report) != 1 || report[0].Method != "ForecastRepository.GetByCityIDAfter" {
+		t.Errorf("Expected the query of keysetPage to count toward its caller, got %+v", report)
+	}
+}
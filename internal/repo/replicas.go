@@ -0,0 +1,189 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// DefaultReplicaCooldown is how long a replica that failed a query is left out before
+// reads are sent to it again
+const DefaultReplicaCooldown = 30 * time.Second
+
+// ReplicatedDB is a DB backed by a primary and read replicas. Its own methods go to the
+// primary; Reader returns the DB that read-only repository methods query, which spreads
+// them over the replicas and falls back to the primary while none is available.
+//
+// Replicas lag the primary, so only reads that may be a moment stale are routed to
+// them: forecasts, cities, places, alerts, stations, observations, aviation reports and
+// air quality. User-owned data (accounts, saved locations, subscriptions, share links,
+// digests) and job runs are read from the primary so a change is seen by the next
+// request, as are all reads of a request carrying a fresh consistency token.
+type ReplicatedDB struct {
+	primary  DB
+	replicas []*replica
+	next     atomic.Uint64
+	cooldown time.Duration
+	now      func() time.Time
+}
+
+// replica is one read replica, skipped until downUntil (Unix nanoseconds) after a
+// failure
+type replica struct {
+	db        DB
+	downUntil atomic.Int64
+}
+
+// NewReplicatedDB routes the reads of primary to replicas. A replica failing with a
+// connection error is left out for cooldown (DefaultReplicaCooldown when 0). Close
+// closes every DB implementing io.Closer.
+func NewReplicatedDB(primary DB, replicas []DB, cooldown time.Duration) *ReplicatedDB {
+	if cooldown <= 0 {
+		cooldown = DefaultReplicaCooldown
+	}
+	d := &ReplicatedDB{primary: primary, cooldown: cooldown, now: time.Now}
+	for _, db := range replicas {
+		d.replicas = append(d.replicas, &replica{db: db})
+	}
+	return d
+}
+
+func (d *ReplicatedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return d.primary.QueryContext(ctx, query, args...)
+}
+
+func (d *ReplicatedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return d.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (d *ReplicatedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return d.primary.ExecContext(ctx, query, args...)
+}
+
+// Reader returns the DB of read-only queries, the primary when there are no replicas
+func (d *ReplicatedDB) Reader() DB {
+	if len(d.replicas) == 0 {
+		return d.primary
+	}
+	return replicaReader{d}
+}
+
+// Close closes the primary and every replica
+func (d *ReplicatedDB) Close() error {
+	var errs []error
+	for _, db := range append([]DB{d.primary}, d.replicaDBs()...) {
+		if closer, ok := db.(io.Closer); ok {
+			errs = append(errs, closer.Close())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *ReplicatedDB) replicaDBs() []DB {
+	dbs := make([]DB, len(d.replicas))
+	for i, r := range d.replicas {
+		dbs[i] = r.db
+	}
+	return dbs
+}
+
+// pick returns the next available replica in turn, or nil when every one is down
+func (d *ReplicatedDB) pick() *replica {
+	now := d.now().UnixNano()
+	start := d.next.Add(1)
+	for i := range uint64(len(d.replicas)) {
+		r := d.replicas[(start+i)%uint64(len(d.replicas))]
+		if r.downUntil.Load() <= now {
+			return r
+		}
+	}
+	return nil
+}
+
+// unavailable reports whether err means r cannot serve queries, leaving it out for the
+// cooldown when it does. Errors of the query itself (bad SQL, no rows) and of the
+// caller's context are returned to the caller as they are.
+func (d *ReplicatedDB) unavailable(ctx context.Context, r *replica, err error) bool {
+	if err == nil || ctx.Err() != nil || !replicaUnavailable(err) {
+		return false
+	}
+	r.downUntil.Store(d.now().Add(d.cooldown).UnixNano())
+	return true
+}
+
+// replicaUnavailable reports whether err is a connection failure rather than an error
+// of the query: a server error is one only for the connection exception (08) and
+// shutdown (57P) classes
+func replicaUnavailable(err error) bool {
+	if errors.Is(err, sql.ErrNoRows) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return unavailableCode(pgErr.Code)
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return unavailableCode(string(pqErr.Code))
+	}
+	return true
+}
+
+func unavailableCode(code string) bool {
+	return strings.HasPrefix(code, "08") || strings.HasPrefix(code, "57P")
+}
+
+// replicaReader sends queries to a replica, retrying them on the primary when the
+// replica is unavailable. Statements that write, and reads that must observe a recent
+// write (see RequiresPrimary), go to the primary.
+type replicaReader struct {
+	d *ReplicatedDB
+}
+
+// replica returns the replica to query with ctx, or nil for the primary
+func (r replicaReader) replica(ctx context.Context) *replica {
+	if RequiresPrimary(ctx) {
+		return nil
+	}
+	return r.d.pick()
+}
+
+func (r replicaReader) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	if rep := r.replica(ctx); rep != nil {
+		rows, err := rep.db.QueryContext(ctx, query, args...)
+		if !r.d.unavailable(ctx, rep, err) {
+			return rows, err
+		}
+	}
+	return r.d.primary.QueryContext(ctx, query, args...)
+}
+
+func (r replicaReader) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	if rep := r.replica(ctx); rep != nil {
+		row := rep.db.QueryRowContext(ctx, query, args...)
+		if row == nil || !r.d.unavailable(ctx, rep, row.Err()) {
+			return row
+		}
+	}
+	return r.d.primary.QueryRowContext(ctx, query, args...)
+}
+
+func (r replicaReader) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return r.d.primary.ExecContext(ctx, query, args...)
+}
+
+// reader returns the DB read-only repository methods query: db's replicas when it has
+// them (see ReplicatedDB), db itself otherwise
+func reader(db DB) DB {
+	if replicated, ok := db.(interface{ Reader() DB }); ok {
+		return replicated.Reader()
+	}
+	return db
+}
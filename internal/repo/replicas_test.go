@@ -0,0 +1,177 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// countingDB counts the statements sent to a scalarDriver database
+type countingDB struct {
+	*sql.DB
+	queries, execs int
+}
+
+func newCountingDB(t *testing.T, value string) *countingDB {
+	db, err := sql.Open("repo-scalar", value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &countingDB{DB: db}
+}
+
+func (c *countingDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	c.queries++
+	return c.DB.QueryContext(ctx, query, args...)
+}
+
+func (c *countingDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	c.queries++
+	return c.DB.QueryRowContext(ctx, query, args...)
+}
+
+func (c *countingDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	c.execs++
+	return &MockResult{rowsAffected: 1}, nil
+}
+
+func TestReplicatedDB(t *testing.T) {
+	ctx := context.Background()
+	scan := func(db DB) int64 {
+		var value int64
+		if err := db.QueryRowContext(ctx, "SELECT 1").Scan(&value); err != nil {
+			t.Fatalf("Scan failed: %v", err)
+		}
+		return value
+	}
+
+	t.Run("Without replicas reads go to the primary", func(t *testing.T) {
+		primary := newCountingDB(t, "1")
+		if got := NewReplicatedDB(primary, nil, 0).Reader(); got != DB(primary) {
+			t.Errorf("Expected the primary as reader, got %T", got)
+		}
+	})
+
+	t.Run("Spreads reads over the replicas", func(t *testing.T) {
+		primary, first, second := newCountingDB(t, "1"), newCountingDB(t, "2"), newCountingDB(t, "3")
+		db := NewReplicatedDB(primary, []DB{first, second}, 0)
+
+		seen := map[int64]int{}
+		for range 4 {
+			seen[scan(db.Reader())]++
+		}
+		if seen[2] != 2 || seen[3] != 2 || primary.queries != 0 {
+			t.Errorf("Expected reads to alternate between replicas, got %v and %d on the primary", seen, primary.queries)
+		}
+
+		if _, err := db.Reader().ExecContext(ctx, "UPDATE cities SET name = $1", "x"); err != nil || primary.execs != 1 || first.execs+second.execs != 0 {
+			t.Errorf("Expected writes through the reader on the primary (%v)", err)
+		}
+		if scan(db) != 1 {
+			t.Error("Expected the DB's own queries on the primary")
+		}
+	})
+
+	t.Run("Falls back to the primary while a replica is down", func(t *testing.T) {
+		primary, replica := newCountingDB(t, "1"), newCountingDB(t, "2")
+		replica.DB.Close()
+		db := NewReplicatedDB(primary, []DB{replica}, time.Minute)
+		now := time.Now()
+		db.now = func() time.Time { return now }
+
+		if scan(db.Reader()) != 1 || replica.queries != 1 {
+			t.Fatalf("Expected the failed read retried on the primary, replica tried %d times", replica.queries)
+		}
+		rows, err := db.Reader().QueryContext(ctx, "SELECT 1")
+		if err != nil {
+			t.Fatalf("QueryContext failed: %v", err)
+		}
+		rows.Close()
+		if replica.queries != 1 || primary.queries != 2 {
+			t.Errorf("Expected the replica left out during its cooldown, got %d replica and %d primary queries", replica.queries, primary.queries)
+		}
+
+		now = now.Add(2 * time.Minute)
+		scan(db.Reader())
+		if replica.queries != 2 {
+			t.Errorf("Expected the replica retried after its cooldown, got %d queries", replica.queries)
+		}
+	})
+
+	t.Run("Reads after a write skip the replicas", func(t *testing.T) {
+		// The replica lags: it has not seen the row counted on the primary
+		primary, replica := newCountingDB(t, "1"), newCountingDB(t, "0")
+		forecasts := NewPostgreSQLForecastRepository(NewReplicatedDB(primary, []DB{replica}, 0))
+		if count, err := forecasts.Count(ctx); err != nil || count != 0 {
+			t.Fatalf("Expected a plain read from the lagging replica, got %d (%v)", count, err)
+		}
+
+		written := WithConsistencyToken(ctx, NewConsistencyToken(time.Now()))
+		replica.queries = 0
+		if count, err := forecasts.Count(written); err != nil || count != 1 {
+			t.Errorf("Expected the written row read back from the primary, got %d (%v)", count, err)
+		}
+		rows, err := reader(NewReplicatedDB(primary, []DB{replica}, 0)).QueryContext(written, "SELECT 1")
+		if err != nil {
+			t.Fatalf("QueryContext failed: %v", err)
+		}
+		rows.Close()
+		if replica.queries != 0 {
+			t.Errorf("Expected no replica queries with a fresh consistency token, got %d", replica.queries)
+		}
+
+		stale := WithConsistencyToken(ctx, NewConsistencyToken(time.Now().Add(-2*ConsistencyWindow)))
+		if count, _ := forecasts.Count(stale); count != 0 || replica.queries != 1 {
+			t.Errorf("Expected an expired token to read from the replica again, got %d", count)
+		}
+	})
+
+	t.Run("Traced DBs route through their reader", func(t *testing.T) {
+		primary, replica := newCountingDB(t, "1"), newCountingDB(t, "2")
+		if scan(reader(NewTracedDB(NewReplicatedDB(primary, []DB{replica}, 0)))) != 2 {
+			t.Error("Expected the traced reader to query the replica")
+		}
+		if scan(reader(primary)) != 1 {
+			t.Error("Expected a plain DB to be its own reader")
+		}
+	})
+
+	t.Run("Close closes every pool", func(t *testing.T) {
+		primary, replica := newCountingDB(t, "1"), newCountingDB(t, "2")
+		if err := NewReplicatedDB(primary, []DB{replica}, 0).Close(); err != nil {
+			t.Fatalf("Close failed: %v", err)
+		}
+		for _, db := range []*countingDB{primary, replica} {
+			if err := db.DB.Ping(); err == nil {
+				t.Error("Expected the pool to be closed")
+			}
+		}
+	})
+}
+
+func TestReplicaUnavailable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("dial tcp 10.0.0.2:5432: connect: connection refused"), true},
+		{&pgconn.PgError{Code: "57P03"}, true},
+		{fmt.Errorf("query: %w", &pq.Error{Code: "08006"}), true},
+		{&pgconn.PgError{Code: "42P01"}, false},
+		{&pq.Error{Code: "23505"}, false},
+		{sql.ErrNoRows, false},
+		{context.DeadlineExceeded, false},
+	}
+	for _, tt := range tests {
+		if got := replicaUnavailable(tt.err); got != tt.want {
+			t.Errorf("replicaUnavailable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
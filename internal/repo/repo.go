@@ -3,6 +3,8 @@ package repo
 import (
 	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 )
 
 // Repository defines the common interface for all data repositories
@@ -30,23 +32,114 @@ type Repository[T any] interface {
 type ForecastRepository interface {
 	Repository[Forecast]
 
+	// CreateBatch inserts many forecasts in as few round trips as possible, populating
+	// their IDs
+	CreateBatch(ctx context.Context, forecasts []*Forecast) error
+
 	// GetByCityID retrieves forecasts for a specific city
 	GetByCityID(ctx context.Context, cityID int, limit, offset int) ([]*Forecast, error)
 
-	// GetByTimeRange retrieves forecasts within a time range
+	// ListAfter retrieves up to limit forecasts ordered by (valid_time, id) descending,
+	// starting after cursor, or with the newest forecast when cursor is nil
+	ListAfter(ctx context.Context, cursor *ForecastCursor, limit int) ([]*Forecast, error)
+
+	// GetByCityIDAfter retrieves a city's forecasts in ListAfter's keyset order
+	GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error)
+
+	// ListWithCities retrieves forecasts in List's order, each joined with its city in
+	// the same query
+	ListWithCities(ctx context.Context, limit, offset int) ([]*CityForecast, error)
+
+	// GetCities retrieves the cities with cityIDs in one query, keyed by ID, to embed in
+	// forecasts not read by ListWithCities; missing cities are left out
+	GetCities(ctx context.Context, cityIDs []int) (map[int]ForecastCity, error)
+
+	// GetByTimeRange retrieves forecasts within a time range in (valid_time, id)
+	// descending order
 	GetByTimeRange(ctx context.Context, startTime, endTime string, limit, offset int) ([]*Forecast, error)
 
+	// GetByTimeRangeAfter retrieves the forecasts valid within a time range in
+	// ListAfter's keyset order
+	GetByTimeRangeAfter(ctx context.Context, startTime, endTime string, cursor *ForecastCursor, limit int) ([]*Forecast, error)
+
 	// GetLatestByCityID retrieves the most recent forecast for a city
 	GetLatestByCityID(ctx context.Context, cityID int) (*Forecast, error)
 
-	// DeleteOldForecasts removes forecasts older than the specified number of days
-	DeleteOldForecasts(ctx context.Context, days int) error
+	// DeleteOldForecasts removes the forecasts matching filter that were valid more than
+	// days ago and returns the number of live forecasts removed. A zero filter applies
+	// the retention to every forecast.
+	DeleteOldForecasts(ctx context.Context, days int, filter ForecastFilter) (int64, error)
+
+	// CountMatching counts the live forecasts matching filter
+	CountMatching(ctx context.Context, filter ForecastFilter) (int64, error)
+
+	// DeleteMatching removes the live forecasts matching filter, at most batchSize rows
+	// per statement so no lock is held for long, and returns the number removed.
+	// Archived forecasts are not affected.
+	DeleteMatching(ctx context.Context, filter ForecastFilter, batchSize int) (int64, error)
+
+	// WindRose bins a city's forecast winds valid in [startTime, endTime) by compass
+	// sector. Only the latest issue of each provider's forecast for an hour counts.
+	WindRose(ctx context.Context, cityID int, startTime, endTime string) (*WindRose, error)
+
+	// DailySummaries aggregates a city's forecasts valid in [startTime, endTime) into one
+	// summary per local date in timezone (an IANA name), oldest first
+	DailySummaries(ctx context.Context, cityID int, startTime, endTime, timezone string) ([]*DailySummary, error)
+
+	// Stats computes the distribution of a metric (see StatsMetrics) over a city's
+	// forecasts valid in [startTime, endTime), one entry per bucket with data, oldest
+	// first. Only the latest issue of each provider's forecast for a time counts.
+	Stats(ctx context.Context, cityID int, metric, startTime, endTime, bucket string) ([]*MetricStats, error)
+}
+
+// ForecastFilter selects forecasts for bulk operations. Zero fields match everything.
+type ForecastFilter struct {
+	CityID         int
+	SourceProvider string
+	Before         string // valid_time strictly before this RFC3339 time
+}
+
+// IsEmpty reports whether the filter matches every forecast
+func (f ForecastFilter) IsEmpty() bool {
+	return f.CityID == 0 && f.SourceProvider == "" && f.Before == ""
+}
+
+// matches reports whether a forecast satisfies the filter
+func (f ForecastFilter) matches(forecast *Forecast) bool {
+	return (f.CityID == 0 || forecast.CityID == f.CityID) &&
+		(f.SourceProvider == "" || forecast.SourceProvider == f.SourceProvider) &&
+		(f.Before == "" || parseStoredTime(forecast.ValidTime).Before(parseStoredTime(f.Before)))
+}
+
+// where renders the filter as a SQL condition over forecasts, numbering its
+// placeholders from $1
+func (f ForecastFilter) where() (string, []any) {
+	conditions := []string{"TRUE"}
+	var args []any
+	add := func(condition string, arg any) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+	if f.CityID != 0 {
+		add("city_id = $%d", f.CityID)
+	}
+	if f.SourceProvider != "" {
+		add("source_provider = $%d", f.SourceProvider)
+	}
+	if f.Before != "" {
+		add("valid_time < $%d", f.Before)
+	}
+	return strings.Join(conditions, " AND "), args
 }
 
 // CityRepository extends the base repository with city-specific methods
 type CityRepository interface {
 	Repository[City]
 
+	// GetByIDs retrieves the cities with the given IDs in one query, in no particular
+	// order; IDs matching no city are skipped
+	GetByIDs(ctx context.Context, ids []int) ([]*City, error)
+
 	// GetByName retrieves cities by name
 	GetByName(ctx context.Context, name string) ([]*City, error)
 
@@ -61,6 +154,13 @@ type CityRepository interface {
 
 	// Search performs text search on city names
 	Search(ctx context.Context, query string, limit int) ([]*City, error)
+
+	// SetLocalizedName stores (or replaces) a city's name in the given language
+	SetLocalizedName(ctx context.Context, name *CityName) error
+
+	// GetLocalizedNames retrieves names for the given cities restricted to the given
+	// languages, keyed by city ID and then language code
+	GetLocalizedNames(ctx context.Context, cityIDs []int, languages []string) (map[int]map[string]string, error)
 }
 
 // PlaceRepository extends the base repository with place-specific methods
@@ -80,27 +180,264 @@ type PlaceRepository interface {
 	GetBySourcePlaceID(ctx context.Context, source, sourcePlaceID string) (*Place, error)
 }
 
+// AlertRepository extends the base repository with alert-specific methods
+type AlertRepository interface {
+	Repository[Alert]
+
+	// Upsert inserts an alert or refreshes the existing row with the same provider alert ID
+	Upsert(ctx context.Context, alert *Alert) error
+
+	// GetByProviderAlertID retrieves an alert by its provider-specific ID
+	GetByProviderAlertID(ctx context.Context, sourceProvider, providerAlertID string) (*Alert, error)
+
+	// GetActiveByCityID retrieves alerts currently in effect for a city
+	GetActiveByCityID(ctx context.Context, cityID int) ([]*Alert, error)
+
+	// GetActiveByCoordinates retrieves alerts currently in effect at given coordinates:
+	// those whose geometry covers the point, and those without geometry within a radius
+	GetActiveByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*Alert, error)
+
+	// DeleteExpired removes alerts whose end time has passed and returns the number removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// ShareLinkRepository stores public share links so they can be revoked
+type ShareLinkRepository interface {
+	// Create inserts a link, populating its ID and creation time
+	Create(ctx context.Context, link *ShareLink) error
+
+	// GetByID retrieves a link, including revoked and expired ones
+	GetByID(ctx context.Context, id int) (*ShareLink, error)
+
+	// ListByUser retrieves a user's links, newest first
+	ListByUser(ctx context.Context, userID int) ([]*ShareLink, error)
+
+	// Revoke marks a link as revoked; revoking it again keeps the first revocation time
+	Revoke(ctx context.Context, id int) error
+
+	// RevokeByUser revokes every active link of a user and returns the number revoked
+	RevokeByUser(ctx context.Context, userID int) (int64, error)
+
+	// DeleteExpired removes links whose expiry has passed and returns the number removed
+	DeleteExpired(ctx context.Context) (int64, error)
+}
+
+// DigestRepository stores scheduled forecast digest subscriptions
+type DigestRepository interface {
+	// Create inserts a digest, populating its ID and creation time
+	Create(ctx context.Context, digest *Digest) error
+
+	// GetByID retrieves a digest by ID
+	GetByID(ctx context.Context, id int) (*Digest, error)
+
+	// ListByUser retrieves a user's digests, newest first
+	ListByUser(ctx context.Context, userID int) ([]*Digest, error)
+
+	// ListDue retrieves up to limit digests whose next run is at or before now, oldest
+	// first
+	ListDue(ctx context.Context, now string, limit int) ([]*Digest, error)
+
+	// RecordRun stores the outcome of a delivery: next run, last run, status and error
+	RecordRun(ctx context.Context, digest *Digest) error
+
+	// Delete removes a digest
+	Delete(ctx context.Context, id int) error
+}
+
+// UserLocationRepository stores the locations users save, such as home and work
+type UserLocationRepository interface {
+	// Create inserts a location, populating its ID and timestamps. A user's first
+	// location becomes their default, as does one created with IsDefault set.
+	Create(ctx context.Context, location *UserLocation) error
+
+	// GetByID retrieves a location by ID
+	GetByID(ctx context.Context, id int) (*UserLocation, error)
+
+	// ListByUser retrieves a user's locations, the default first and then by name
+	ListByUser(ctx context.Context, userID int) ([]*UserLocation, error)
+
+	// Update changes a location's name, city and coordinates; IsDefault is ignored
+	Update(ctx context.Context, location *UserLocation) error
+
+	// SetDefault makes a location its user's default, clearing the previous one
+	SetDefault(ctx context.Context, id int) error
+
+	// Delete removes a location. Deleting the default leaves the user without one.
+	Delete(ctx context.Context, id int) error
+}
+
+// UserRepository stores user accounts and their roles
+type UserRepository interface {
+	// Create inserts a user, populating its ID and timestamps. An empty role is stored
+	// as "user".
+	Create(ctx context.Context, user *User) error
+
+	// GetByID retrieves a user by ID
+	GetByID(ctx context.Context, id int) (*User, error)
+
+	// GetByUsername retrieves a user by username
+	GetByUsername(ctx context.Context, username string) (*User, error)
+
+	// GetByAPIKeyHash retrieves the user whose hashed API key is hash
+	GetByAPIKeyHash(ctx context.Context, hash string) (*User, error)
+
+	// SetRole changes a user's role
+	SetRole(ctx context.Context, id int, role string) error
+}
+
+// AlertSubscriptionRepository stores users' subscriptions to alerts near a point and
+// the notifications queued for them
+type AlertSubscriptionRepository interface {
+	// Create inserts a subscription, populating its ID and creation time
+	Create(ctx context.Context, subscription *AlertSubscription) error
+
+	// GetByID retrieves a subscription by ID
+	GetByID(ctx context.Context, id int) (*AlertSubscription, error)
+
+	// ListByUser retrieves a user's subscriptions, newest first
+	ListByUser(ctx context.Context, userID int) ([]*AlertSubscription, error)
+
+	// Delete removes a subscription and its queued notifications
+	Delete(ctx context.Context, id int) error
+
+	// DeleteByToken removes the subscription with an unsubscribe token
+	DeleteByToken(ctx context.Context, token string) error
+
+	// ListMatching retrieves the subscriptions whose radius contains a point and whose
+	// minimum severity is one of severities
+	ListMatching(ctx context.Context, lat, lon float64, severities []string) ([]*AlertSubscription, error)
+
+	// Queue records an alert to be sent to subscriptions. An alert is queued once per
+	// subscription, so refreshing it does not notify again.
+	Queue(ctx context.Context, alertID int, subscriptionIDs []int) error
+
+	// ListDue retrieves up to limit subscriptions with unsent notifications that are due:
+	// immediate ones at once, digests once their last notification (or creation) is at
+	// or before digestBefore
+	ListDue(ctx context.Context, digestBefore string, limit int) ([]*AlertSubscription, error)
+
+	// ListPending retrieves the alerts queued for a subscription and not sent yet, oldest
+	// first
+	ListPending(ctx context.Context, subscriptionID int) ([]*Alert, error)
+
+	// MarkSent records that alerts were sent to a subscription at sentAt
+	MarkSent(ctx context.Context, subscriptionID int, alertIDs []int, sentAt string) error
+}
+
+// JobRunRepository stores the history of scheduled job runs
+type JobRunRepository interface {
+	// Create inserts a run, populating its ID
+	Create(ctx context.Context, run *JobRun) error
+
+	// Finish records the outcome of a run: status, finish time, duration, counts and error
+	Finish(ctx context.Context, run *JobRun) error
+
+	// GetByID retrieves a run by ID
+	GetByID(ctx context.Context, id int) (*JobRun, error)
+
+	// List retrieves runs of a job, or of every job when jobName is empty, newest first
+	List(ctx context.Context, jobName string, limit, offset int) ([]*JobRun, error)
+
+	// FailRunning marks runs still recorded as running as failed with reason and returns
+	// the number updated. It is called on startup for runs cut short by a restart.
+	FailRunning(ctx context.Context, reason string) (int64, error)
+}
+
+// StationRepository stores the weather stations observations come from
+type StationRepository interface {
+	// Upsert inserts a station or refreshes the existing row with the same station ID
+	Upsert(ctx context.Context, station *Station) error
+
+	// GetByStationID retrieves a station by its network code
+	GetByStationID(ctx context.Context, stationID string) (*Station, error)
+
+	// List retrieves stations ordered by station ID
+	List(ctx context.Context, limit, offset int) ([]*Station, error)
+}
+
+// ObservationRepository stores station observations, separately from forecasts
+type ObservationRepository interface {
+	// Upsert inserts an observation or refreshes the existing row for the same station
+	// and observation time. The station must already be stored.
+	Upsert(ctx context.Context, observation *Observation) error
+
+	// GetLatest retrieves the most recent observation of a station
+	GetLatest(ctx context.Context, stationID string) (*Observation, error)
+
+	// GetByStation retrieves a station's observations made at or after since, newest first
+	GetByStation(ctx context.Context, stationID, since string, limit int) ([]*Observation, error)
+
+	// DeleteOlderThan removes observations made before cutoff and returns the number removed
+	DeleteOlderThan(ctx context.Context, cutoff string) (int64, error)
+}
+
+// AirQualityRepository stores air quality readings by point. Callers round coordinates
+// so that nearby requests share readings.
+type AirQualityRepository interface {
+	// Upsert inserts a reading or refreshes the existing row for the same point and
+	// observation time
+	Upsert(ctx context.Context, reading *AirQuality) error
+
+	// GetLatest retrieves the most recent reading at a point
+	GetLatest(ctx context.Context, lat, lon float64) (*AirQuality, error)
+
+	// DeleteOlderThan removes readings observed before cutoff and returns the number removed
+	DeleteOlderThan(ctx context.Context, cutoff string) (int64, error)
+}
+
+// AviationReportRepository stores METAR and TAF reports by airport
+type AviationReportRepository interface {
+	// Upsert inserts a report or refreshes the existing row for the same station, report
+	// type and observation time
+	Upsert(ctx context.Context, report *AviationReport) error
+
+	// GetLatest retrieves the most recent report of a type for a station
+	GetLatest(ctx context.Context, stationID, reportType string) (*AviationReport, error)
+
+	// GetByStation retrieves reports of a type for a station observed at or after since,
+	// newest first
+	GetByStation(ctx context.Context, stationID, reportType, since string, limit int) ([]*AviationReport, error)
+
+	// DeleteOlderThan removes reports observed before cutoff and returns the number removed
+	DeleteOlderThan(ctx context.Context, cutoff string) (int64, error)
+}
+
 // Forecast represents the forecast model for the repository
 type Forecast struct {
-	ID             int     `db:"id"`
-	CityID         int     `db:"city_id"`
-	SourceProvider string  `db:"source_provider"`
-	ForecastTime   string  `db:"forecast_time"`
-	ValidTime      string  `db:"valid_time"`
-	Temperature    float64 `db:"temperature"`
-	FeelsLike      float64 `db:"feels_like"`
-	Humidity       float64 `db:"humidity"`
-	Pressure       float64 `db:"pressure"`
-	WindSpeed      float64 `db:"wind_speed"`
-	WindDirection  float64 `db:"wind_direction"`
-	Visibility     float64 `db:"visibility"`
-	CloudCover     float64 `db:"cloud_cover"`
-	Precipitation  float64 `db:"precipitation"`
-	WeatherCode    string  `db:"weather_code"`
-	Description    string  `db:"description"`
-	UVIndex        float64 `db:"uv_index"`
-	CreatedAt      string  `db:"created_at"`
-	UpdatedAt      string  `db:"updated_at"`
+	ID              int     `db:"id"`
+	CityID          int     `db:"city_id"`
+	SourceProvider  string  `db:"source_provider"`
+	ForecastTime    string  `db:"forecast_time"`
+	ValidTime       string  `db:"valid_time"`
+	Temperature     float64 `db:"temperature"`
+	FeelsLike       float64 `db:"feels_like"`
+	Humidity        float64 `db:"humidity"`
+	Pressure        float64 `db:"pressure"`         // mean sea-level pressure
+	StationPressure float64 `db:"station_pressure"` // pressure at station elevation; 0 when unknown
+	WindSpeed       float64 `db:"wind_speed"`
+	WindDirection   float64 `db:"wind_direction"`
+	WindGust        float64 `db:"wind_gust"` // 0 when not reported
+	Visibility      float64 `db:"visibility"`
+	CloudCover      float64 `db:"cloud_cover"`
+	Precipitation   float64 `db:"precipitation"`
+	WeatherCode     string  `db:"weather_code"`
+	Description     string  `db:"description"`
+	UVIndex         float64 `db:"uv_index"`
+	CreatedAt       string  `db:"created_at"`
+	UpdatedAt       string  `db:"updated_at"`
+}
+
+// ForecastCity is the part of a city read along with its forecasts
+type ForecastCity struct {
+	Name        string `db:"name"`
+	CountryCode string `db:"country_code"`
+	Timezone    string `db:"timezone"`
+}
+
+// CityForecast is a forecast with its city, as read by ListWithCities
+type CityForecast struct {
+	Forecast
+	City ForecastCity
 }
 
 // City represents the city model for the repository
@@ -122,6 +459,14 @@ type City struct {
 	UpdatedAt   string  `db:"updated_at"`
 }
 
+// CityName represents a localized (alternate) city name for the repository
+type CityName struct {
+	CityID      int    `db:"city_id"`
+	Language    string `db:"language"` // lowercase ISO 639 code, optionally with region (e.g. "pt-br")
+	Name        string `db:"name"`
+	IsPreferred bool   `db:"is_preferred"`
+}
+
 // Place represents the place model for the repository
 type Place struct {
 	ID            int     `db:"id"`
@@ -144,6 +489,195 @@ type Place struct {
 	UpdatedAt     string  `db:"updated_at"`
 }
 
+// Alert represents the alert model for the repository
+type Alert struct {
+	ID              int     `db:"id"`
+	SourceProvider  string  `db:"source_provider"`
+	ProviderAlertID string  `db:"provider_alert_id"`
+	CityID          int     `db:"city_id"` // 0 when the alert is not tied to a city
+	Latitude        float64 `db:"latitude"`
+	Longitude       float64 `db:"longitude"`
+	Title           string  `db:"title"`
+	Description     string  `db:"description"`
+	Severity        string  `db:"severity"`
+	Urgency         string  `db:"urgency"`
+	Category        string  `db:"category"`
+	AreaDesc        string  `db:"area_desc"`
+	Geometry        string  `db:"geometry"`   // GeoJSON Polygon or MultiPolygon, empty when the provider sends none
+	StartTime       string  `db:"start_time"` // empty when the provider omits it
+	EndTime         string  `db:"end_time"`   // empty when the provider omits it
+	CreatedAt       string  `db:"created_at"`
+	UpdatedAt       string  `db:"updated_at"`
+}
+
+// ShareLink represents a public, time-limited link to one resource
+type ShareLink struct {
+	ID           int    `db:"id"`
+	UserID       int    `db:"user_id"`
+	ResourceType string `db:"resource_type"` // forecast or export
+	ResourceID   string `db:"resource_id"`   // city ID for forecasts, job ID for exports
+	ExpiresAt    string `db:"expires_at"`
+	RevokedAt    string `db:"revoked_at"` // empty while the link is active
+	CreatedAt    string `db:"created_at"`
+}
+
+// Digest represents a subscription to a city's forecast, delivered by webhook every
+// day at a local time
+type Digest struct {
+	ID         int    `db:"id"`
+	UserID     int    `db:"user_id"`
+	CityID     int    `db:"city_id"`
+	URL        string `db:"url"`
+	Secret     string `db:"secret"`     // HMAC signing key
+	LocalTime  string `db:"local_time"` // HH:MM in Timezone
+	Timezone   string `db:"timezone"`   // IANA name, e.g. America/Chicago
+	Days       int    `db:"days"`       // forecast days included
+	NextRunAt  string `db:"next_run_at"`
+	LastRunAt  string `db:"last_run_at"` // empty before the first delivery
+	LastStatus string `db:"last_status"` // delivered or failed; empty before the first delivery
+	LastError  string `db:"last_error"`
+	CreatedAt  string `db:"created_at"`
+}
+
+// User represents a user account. Roles are the models.Role* values.
+type User struct {
+	ID         int    `db:"id"`
+	GitHubID   int    `db:"github_id"` // 0 for accounts not linked to GitHub
+	Username   string `db:"username"`
+	Email      string `db:"email"`
+	APIKeyHash string `db:"api_key_hash"` // hex SHA-256 of the API key, empty without one
+	Role       string `db:"role"`         // admin, user or readonly
+	IsActive   bool   `db:"is_active"`
+	CreatedAt  string `db:"created_at"`
+	UpdatedAt  string `db:"updated_at"`
+
+	PreferredUnits string `db:"preferred_units"` // metric or imperial, for responses without ?units=
+}
+
+// UserLocation represents a named location saved by a user
+type UserLocation struct {
+	ID        int     `db:"id"`
+	UserID    int     `db:"user_id"`
+	Name      string  `db:"name"`    // unique per user, ignoring case
+	CityID    int     `db:"city_id"` // 0 for a location saved by coordinates
+	Latitude  float64 `db:"latitude"`
+	Longitude float64 `db:"longitude"`
+	IsDefault bool    `db:"is_default"`
+	CreatedAt string  `db:"created_at"`
+	UpdatedAt string  `db:"updated_at"`
+}
+
+// Alert subscription modes
+const (
+	SubscriptionImmediate = "immediate" // one email as soon as matching alerts arrive
+	SubscriptionDigest    = "digest"    // at most one email per digest period
+)
+
+// AlertSubscription represents a user's request to be emailed about alerts within a
+// radius of a point
+type AlertSubscription struct {
+	ID             int     `db:"id"`
+	UserID         int     `db:"user_id"`
+	Email          string  `db:"email"`
+	Latitude       float64 `db:"latitude"`
+	Longitude      float64 `db:"longitude"`
+	RadiusKm       float64 `db:"radius_km"`
+	MinSeverity    string  `db:"min_severity"` // CAP severity, e.g. Severe
+	Mode           string  `db:"mode"`         // immediate or digest
+	Token          string  `db:"token"`        // unsubscribe token
+	LastNotifiedAt string  `db:"last_notified_at"`
+	CreatedAt      string  `db:"created_at"`
+}
+
+// JobRun represents one run of a scheduled job
+type JobRun struct {
+	ID          int    `db:"id"`
+	JobName     string `db:"job_name"`
+	TriggeredBy string `db:"triggered_by"` // schedule, manual or retry
+	RetryOf     int    `db:"retry_of"`     // run retried by this one, 0 otherwise
+	Status      string `db:"status"`       // running, succeeded, partial or failed
+	StartedAt   string `db:"started_at"`
+	FinishedAt  string `db:"finished_at"` // empty while running
+	DurationMS  int64  `db:"duration_ms"`
+	Processed   int    `db:"processed"`
+	Skipped     string `db:"skipped"` // JSON array of {"item", "reason"} objects
+	Error       string `db:"error"`
+}
+
+// AviationReport represents the METAR/TAF model for the repository
+type AviationReport struct {
+	ID             int      `db:"id"`
+	StationID      string   `db:"station_id"`
+	ReportType     string   `db:"report_type"`
+	SourceProvider string   `db:"source_provider"`
+	RawText        string   `db:"raw_text"`
+	ObservedAt     string   `db:"observed_at"`
+	ValidFrom      string   `db:"valid_from"` // empty for METARs
+	ValidTo        string   `db:"valid_to"`   // empty for METARs
+	Latitude       float64  `db:"latitude"`
+	Longitude      float64  `db:"longitude"`
+	Temperature    *float64 `db:"temperature"`
+	Dewpoint       *float64 `db:"dewpoint"`
+	WindDirection  *float64 `db:"wind_direction"`
+	WindSpeed      *float64 `db:"wind_speed"`
+	WindGust       *float64 `db:"wind_gust"`
+	Visibility     *float64 `db:"visibility"`
+	Pressure       *float64 `db:"pressure"`
+	FlightCategory string   `db:"flight_category"`
+	CreatedAt      string   `db:"created_at"`
+	UpdatedAt      string   `db:"updated_at"`
+}
+
+// Station represents the weather station model for the repository
+type Station struct {
+	ID             int      `db:"id"`
+	StationID      string   `db:"station_id"`
+	Name           string   `db:"name"`
+	SourceProvider string   `db:"source_provider"`
+	Latitude       float64  `db:"latitude"`
+	Longitude      float64  `db:"longitude"`
+	Elevation      *float64 `db:"elevation"`
+	TimeZone       string   `db:"time_zone"`
+	CreatedAt      string   `db:"created_at"`
+	UpdatedAt      string   `db:"updated_at"`
+}
+
+// Observation represents the station observation model for the repository
+type Observation struct {
+	ID              int      `db:"id"`
+	StationID       string   `db:"station_id"`
+	SourceProvider  string   `db:"source_provider"`
+	ObservedAt      string   `db:"observed_at"`
+	Temperature     *float64 `db:"temperature"`
+	Dewpoint        *float64 `db:"dewpoint"`
+	Humidity        *float64 `db:"humidity"`
+	WindDirection   *float64 `db:"wind_direction"`
+	WindSpeed       *float64 `db:"wind_speed"`
+	WindGust        *float64 `db:"wind_gust"`
+	Pressure        *float64 `db:"pressure"`
+	StationPressure *float64 `db:"station_pressure"`
+	Visibility      *float64 `db:"visibility"`
+	Description     string   `db:"description"`
+	QualityFlags    string   `db:"qc_flags"` // JSON object of field name to QC code
+	CreatedAt       string   `db:"created_at"`
+	UpdatedAt       string   `db:"updated_at"`
+}
+
+// AirQuality represents the air quality reading model for the repository
+type AirQuality struct {
+	ID             int      `db:"id"`
+	Latitude       float64  `db:"latitude"`
+	Longitude      float64  `db:"longitude"`
+	SourceProvider string   `db:"source_provider"`
+	ObservedAt     string   `db:"observed_at"`
+	AQI            int      `db:"aqi"`
+	PM25           *float64 `db:"pm2_5"`
+	PM10           *float64 `db:"pm10"`
+	Ozone          *float64 `db:"ozone"`
+	CreatedAt      string   `db:"created_at"`
+	UpdatedAt      string   `db:"updated_at"`
+}
+
 // DB interface abstracts database operations
 type DB interface {
 	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
@@ -4,7 +4,11 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // PostgreSQLForecastRepository implements ForecastRepository for PostgreSQL
@@ -24,9 +28,9 @@ func (r *PostgreSQLForecastRepository) Create(ctx context.Context, forecast *For
 			city_id, source_provider, forecast_time, valid_time, temperature,
 			feels_like, humidity, pressure, wind_speed, wind_direction,
 			visibility, cloud_cover, precipitation, weather_code, description,
-			uv_index, created_at, updated_at
+			uv_index, station_pressure, wind_gust, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
 		) RETURNING id`
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -35,11 +39,11 @@ func (r *PostgreSQLForecastRepository) Create(ctx context.Context, forecast *For
 		forecast.Temperature, forecast.FeelsLike, forecast.Humidity, forecast.Pressure,
 		forecast.WindSpeed, forecast.WindDirection, forecast.Visibility, forecast.CloudCover,
 		forecast.Precipitation, forecast.WeatherCode, forecast.Description, forecast.UVIndex,
-		now, now,
+		forecast.StationPressure, forecast.WindGust, now, now,
 	).Scan(&forecast.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to create forecast: %w", err)
+		return fmt.Errorf("failed to create forecast: %w", classify(err))
 	}
 
 	forecast.CreatedAt = now
@@ -47,27 +51,110 @@ func (r *PostgreSQLForecastRepository) Create(ctx context.Context, forecast *For
 	return nil
 }
 
+// forecastInsertColumns is the number of columns written per row by Create and CreateBatch
+const forecastInsertColumns = 20
+
+// maxForecastBatchRows keeps a multi-row INSERT under PostgreSQL's limit of 65535
+// bind parameters
+const maxForecastBatchRows = 65535 / forecastInsertColumns
+
+// CreateBatch inserts forecasts with one multi-row INSERT per maxForecastBatchRows rows.
+// Each statement is atomic, but a batch larger than one statement is not: if a later
+// chunk fails, earlier chunks stay inserted and keep their IDs.
+func (r *PostgreSQLForecastRepository) CreateBatch(ctx context.Context, forecasts []*Forecast) error {
+	now := time.Now().UTC().Format(time.RFC3339)
+	for start := 0; start < len(forecasts); start += maxForecastBatchRows {
+		chunk := forecasts[start:min(start+maxForecastBatchRows, len(forecasts))]
+		if err := r.insertChunk(ctx, chunk, now); err != nil {
+			return fmt.Errorf("failed to create forecasts %d-%d: %w", start, start+len(chunk)-1, classify(err))
+		}
+	}
+	return nil
+}
+
+func (r *PostgreSQLForecastRepository) insertChunk(ctx context.Context, forecasts []*Forecast, now string) error {
+	query, args := buildForecastInsert(forecasts, now)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, len(forecasts))
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(ids) != len(forecasts) {
+		return fmt.Errorf("expected %d ids, got %d", len(forecasts), len(ids))
+	}
+
+	// The serial sequence hands out IDs in VALUES order, whatever order RETURNING uses
+	slices.Sort(ids)
+	for i, f := range forecasts {
+		f.ID = ids[i]
+		f.CreatedAt = now
+		f.UpdatedAt = now
+	}
+	return nil
+}
+
+// buildForecastInsert returns a multi-row INSERT for forecasts and its arguments
+func buildForecastInsert(forecasts []*Forecast, now string) (string, []any) {
+	var query strings.Builder
+	query.WriteString(`
+		INSERT INTO forecasts (
+			city_id, source_provider, forecast_time, valid_time, temperature,
+			feels_like, humidity, pressure, wind_speed, wind_direction,
+			visibility, cloud_cover, precipitation, weather_code, description,
+			uv_index, station_pressure, wind_gust, created_at, updated_at
+		) VALUES `)
+
+	args := make([]any, 0, len(forecasts)*forecastInsertColumns)
+	for i, f := range forecasts {
+		if i > 0 {
+			query.WriteString(", ")
+		}
+		query.WriteString("(")
+		for col := range forecastInsertColumns {
+			if col > 0 {
+				query.WriteString(", ")
+			}
+			fmt.Fprintf(&query, "$%d", i*forecastInsertColumns+col+1)
+		}
+		query.WriteString(")")
+
+		args = append(args,
+			f.CityID, f.SourceProvider, f.ForecastTime, f.ValidTime,
+			f.Temperature, f.FeelsLike, f.Humidity, f.Pressure,
+			f.WindSpeed, f.WindDirection, f.Visibility, f.CloudCover,
+			f.Precipitation, f.WeatherCode, f.Description, f.UVIndex,
+			f.StationPressure, f.WindGust, now, now,
+		)
+	}
+	query.WriteString(" RETURNING id")
+
+	return query.String(), args
+}
+
 // GetByID retrieves a forecast by its ID
 func (r *PostgreSQLForecastRepository) GetByID(ctx context.Context, id int) (*Forecast, error) {
 	query := `
-		SELECT id, city_id, source_provider, forecast_time, valid_time, temperature,
-			   feels_like, humidity, pressure, wind_speed, wind_direction, visibility,
-			   cloud_cover, precipitation, weather_code, description, uv_index,
-			   created_at, updated_at
+		SELECT ` + forecastColumns + `
 		FROM forecasts WHERE id = $1`
 
 	forecast := &Forecast{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&forecast.ID, &forecast.CityID, &forecast.SourceProvider, &forecast.ForecastTime,
-		&forecast.ValidTime, &forecast.Temperature, &forecast.FeelsLike, &forecast.Humidity,
-		&forecast.Pressure, &forecast.WindSpeed, &forecast.WindDirection, &forecast.Visibility,
-		&forecast.CloudCover, &forecast.Precipitation, &forecast.WeatherCode, &forecast.Description,
-		&forecast.UVIndex, &forecast.CreatedAt, &forecast.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, id), forecast)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("forecast with id %d not found", id)
+			return nil, notFound("forecast with id %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get forecast: %w", err)
 	}
@@ -83,7 +170,7 @@ func (r *PostgreSQLForecastRepository) Update(ctx context.Context, forecast *For
 			temperature = $6, feels_like = $7, humidity = $8, pressure = $9,
 			wind_speed = $10, wind_direction = $11, visibility = $12, cloud_cover = $13,
 			precipitation = $14, weather_code = $15, description = $16, uv_index = $17,
-			updated_at = $18
+			station_pressure = $18, wind_gust = $19, updated_at = $20
 		WHERE id = $1`
 
 	now := time.Now().UTC().Format(time.RFC3339)
@@ -92,11 +179,11 @@ func (r *PostgreSQLForecastRepository) Update(ctx context.Context, forecast *For
 		forecast.ValidTime, forecast.Temperature, forecast.FeelsLike, forecast.Humidity,
 		forecast.Pressure, forecast.WindSpeed, forecast.WindDirection, forecast.Visibility,
 		forecast.CloudCover, forecast.Precipitation, forecast.WeatherCode, forecast.Description,
-		forecast.UVIndex, now,
+		forecast.UVIndex, forecast.StationPressure, forecast.WindGust, now,
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update forecast: %w", err)
+		return fmt.Errorf("failed to update forecast: %w", classify(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -105,7 +192,7 @@ func (r *PostgreSQLForecastRepository) Update(ctx context.Context, forecast *For
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("forecast with id %d not found", forecast.ID)
+		return notFound("forecast with id %d not found", forecast.ID)
 	}
 
 	forecast.UpdatedAt = now
@@ -126,7 +213,7 @@ func (r *PostgreSQLForecastRepository) Delete(ctx context.Context, id int) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("forecast with id %d not found", id)
+		return notFound("forecast with id %d not found", id)
 	}
 
 	return nil
@@ -135,46 +222,78 @@ func (r *PostgreSQLForecastRepository) Delete(ctx context.Context, id int) error
 // List retrieves forecasts with pagination
 func (r *PostgreSQLForecastRepository) List(ctx context.Context, limit, offset int) ([]*Forecast, error) {
 	query := `
-		SELECT id, city_id, source_provider, forecast_time, valid_time, temperature,
-			   feels_like, humidity, pressure, wind_speed, wind_direction, visibility,
-			   cloud_cover, precipitation, weather_code, description, uv_index,
-			   created_at, updated_at
+		SELECT ` + forecastColumns + `
 		FROM forecasts ORDER BY created_at DESC LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list forecasts: %w", err)
 	}
+	forecasts, err := scanRows[Forecast](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
+	}
+	return forecasts, nil
+}
+
+// ListWithCities retrieves forecasts with their cities, joining cities in the list query
+func (r *PostgreSQLForecastRepository) ListWithCities(ctx context.Context, limit, offset int) ([]*CityForecast, error) {
+	query := `
+		SELECT ` + columnList[Forecast]("f") + `, ` + columnList[ForecastCity]("c") + `
+		FROM forecasts f JOIN cities c ON c.id = f.city_id
+		ORDER BY f.created_at DESC LIMIT $1 OFFSET $2`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list forecasts with cities: %w", err)
+	}
 	defer rows.Close()
 
-	var forecasts []*Forecast
+	var forecasts []*CityForecast
 	for rows.Next() {
-		forecast := &Forecast{}
-		err := rows.Scan(
-			&forecast.ID, &forecast.CityID, &forecast.SourceProvider, &forecast.ForecastTime,
-			&forecast.ValidTime, &forecast.Temperature, &forecast.FeelsLike, &forecast.Humidity,
-			&forecast.Pressure, &forecast.WindSpeed, &forecast.WindDirection, &forecast.Visibility,
-			&forecast.CloudCover, &forecast.Precipitation, &forecast.WeatherCode, &forecast.Description,
-			&forecast.UVIndex, &forecast.CreatedAt, &forecast.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan forecast: %w", err)
+		forecast := &CityForecast{}
+		city := &forecast.City
+		if err := scanInto(rows, &forecast.Forecast, &city.Name, &city.CountryCode, &city.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan forecasts: %w", err)
 		}
 		forecasts = append(forecasts, forecast)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
+	}
+	return forecasts, nil
+}
+
+// GetCities retrieves the cities with cityIDs
+func (r *PostgreSQLForecastRepository) GetCities(ctx context.Context, cityIDs []int) (map[int]ForecastCity, error) {
+	query := `SELECT id, name, country_code, timezone FROM cities WHERE id = ANY($1)`
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating forecasts: %w", err)
+	rows, err := reader(r.db).QueryContext(ctx, query, pq.Array(cityIDs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast cities: %w", err)
 	}
+	defer rows.Close()
 
-	return forecasts, nil
+	cities := make(map[int]ForecastCity, len(cityIDs))
+	for rows.Next() {
+		var id int
+		var city ForecastCity
+		if err := rows.Scan(&id, &city.Name, &city.CountryCode, &city.Timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan forecast cities: %w", err)
+		}
+		cities[id] = city
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to scan forecast cities: %w", err)
+	}
+	return cities, nil
 }
 
 // Count returns the total number of forecast records
 func (r *PostgreSQLForecastRepository) Count(ctx context.Context) (int, error) {
 	query := `SELECT COUNT(*) FROM forecasts`
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := reader(r.db).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count forecasts: %w", err)
 	}
@@ -184,94 +303,97 @@ func (r *PostgreSQLForecastRepository) Count(ctx context.Context) (int, error) {
 // GetByCityID retrieves forecasts for a specific city
 func (r *PostgreSQLForecastRepository) GetByCityID(ctx context.Context, cityID int, limit, offset int) ([]*Forecast, error) {
 	query := `
-		SELECT id, city_id, source_provider, forecast_time, valid_time, temperature,
-			   feels_like, humidity, pressure, wind_speed, wind_direction, visibility,
-			   cloud_cover, precipitation, weather_code, description, uv_index,
-			   created_at, updated_at
+		SELECT ` + forecastColumns + `
 		FROM forecasts WHERE city_id = $1 ORDER BY valid_time DESC LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, cityID, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, cityID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get forecasts by city: %w", err)
 	}
-	defer rows.Close()
+	forecasts, err := scanRows[Forecast](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
+	}
+	return forecasts, nil
+}
 
-	var forecasts []*Forecast
-	for rows.Next() {
-		forecast := &Forecast{}
-		err := rows.Scan(
-			&forecast.ID, &forecast.CityID, &forecast.SourceProvider, &forecast.ForecastTime,
-			&forecast.ValidTime, &forecast.Temperature, &forecast.FeelsLike, &forecast.Humidity,
-			&forecast.Pressure, &forecast.WindSpeed, &forecast.WindDirection, &forecast.Visibility,
-			&forecast.CloudCover, &forecast.Precipitation, &forecast.WeatherCode, &forecast.Description,
-			&forecast.UVIndex, &forecast.CreatedAt, &forecast.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan forecast: %w", err)
-		}
-		forecasts = append(forecasts, forecast)
+// ListAfter retrieves up to limit forecasts in (valid_time, id) descending order after cursor
+func (r *PostgreSQLForecastRepository) ListAfter(ctx context.Context, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	return r.keysetPage(ctx, nil, nil, cursor, limit)
+}
+
+// GetByCityIDAfter retrieves up to limit of a city's forecasts in (valid_time, id)
+// descending order after cursor
+func (r *PostgreSQLForecastRepository) GetByCityIDAfter(ctx context.Context, cityID int, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	return r.keysetPage(ctx, []string{"city_id = $1"}, []any{cityID}, cursor, limit)
+}
+
+// keysetPage runs a keyset page query filtered by conditions, whose placeholders number
+// args. The row comparison is served by the (valid_time, id) indexes.
+func (r *PostgreSQLForecastRepository) keysetPage(ctx context.Context, conditions []string, args []any, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	if cursor != nil {
+		args = append(args, cursor.ValidTime, cursor.ID)
+		conditions = append(conditions, fmt.Sprintf("(valid_time, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
 	}
+	args = append(args, limit)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM forecasts %s ORDER BY valid_time DESC, id DESC LIMIT $%d`, forecastColumns, where, len(args))
+
+	rows, err := reader(r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to page forecasts: %w", err)
+	}
+	forecasts, err := scanRows[Forecast](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
+	}
+	return forecasts, nil
+}
 
-	return forecasts, rows.Err()
+// GetByTimeRangeAfter retrieves up to limit forecasts valid within a time range in
+// (valid_time, id) descending order after cursor
+func (r *PostgreSQLForecastRepository) GetByTimeRangeAfter(ctx context.Context, startTime, endTime string, cursor *ForecastCursor, limit int) ([]*Forecast, error) {
+	return r.keysetPage(ctx, []string{"valid_time >= $1", "valid_time <= $2"}, []any{startTime, endTime}, cursor, limit)
 }
 
-// GetByTimeRange retrieves forecasts within a time range
+// GetByTimeRange retrieves forecasts within a time range in (valid_time, id)
+// descending order, as GetByTimeRangeAfter pages them
 func (r *PostgreSQLForecastRepository) GetByTimeRange(ctx context.Context, startTime, endTime string, limit, offset int) ([]*Forecast, error) {
 	query := `
-		SELECT id, city_id, source_provider, forecast_time, valid_time, temperature,
-			   feels_like, humidity, pressure, wind_speed, wind_direction, visibility,
-			   cloud_cover, precipitation, weather_code, description, uv_index,
-			   created_at, updated_at
+		SELECT ` + forecastColumns + `
 		FROM forecasts
 		WHERE valid_time >= $1 AND valid_time <= $2
-		ORDER BY valid_time ASC LIMIT $3 OFFSET $4`
+		ORDER BY valid_time DESC, id DESC LIMIT $3 OFFSET $4`
 
-	rows, err := r.db.QueryContext(ctx, query, startTime, endTime, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, startTime, endTime, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get forecasts by time range: %w", err)
 	}
-	defer rows.Close()
-
-	var forecasts []*Forecast
-	for rows.Next() {
-		forecast := &Forecast{}
-		err := rows.Scan(
-			&forecast.ID, &forecast.CityID, &forecast.SourceProvider, &forecast.ForecastTime,
-			&forecast.ValidTime, &forecast.Temperature, &forecast.FeelsLike, &forecast.Humidity,
-			&forecast.Pressure, &forecast.WindSpeed, &forecast.WindDirection, &forecast.Visibility,
-			&forecast.CloudCover, &forecast.Precipitation, &forecast.WeatherCode, &forecast.Description,
-			&forecast.UVIndex, &forecast.CreatedAt, &forecast.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan forecast: %w", err)
-		}
-		forecasts = append(forecasts, forecast)
+	forecasts, err := scanRows[Forecast](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan forecasts: %w", err)
 	}
-
-	return forecasts, rows.Err()
+	return forecasts, nil
 }
 
 // GetLatestByCityID retrieves the most recent forecast for a city
 func (r *PostgreSQLForecastRepository) GetLatestByCityID(ctx context.Context, cityID int) (*Forecast, error) {
 	query := `
-		SELECT id, city_id, source_provider, forecast_time, valid_time, temperature,
-			   feels_like, humidity, pressure, wind_speed, wind_direction, visibility,
-			   cloud_cover, precipitation, weather_code, description, uv_index,
-			   created_at, updated_at
+		SELECT ` + forecastColumns + `
 		FROM forecasts WHERE city_id = $1 ORDER BY valid_time DESC LIMIT 1`
 
 	forecast := &Forecast{}
-	err := r.db.QueryRowContext(ctx, query, cityID).Scan(
-		&forecast.ID, &forecast.CityID, &forecast.SourceProvider, &forecast.ForecastTime,
-		&forecast.ValidTime, &forecast.Temperature, &forecast.FeelsLike, &forecast.Humidity,
-		&forecast.Pressure, &forecast.WindSpeed, &forecast.WindDirection, &forecast.Visibility,
-		&forecast.CloudCover, &forecast.Precipitation, &forecast.WeatherCode, &forecast.Description,
-		&forecast.UVIndex, &forecast.CreatedAt, &forecast.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, cityID), forecast)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("no forecasts found for city %d", cityID)
+			return nil, notFound("no forecasts found for city %d", cityID)
 		}
 		return nil, fmt.Errorf("failed to get latest forecast: %w", err)
 	}
@@ -279,14 +401,214 @@ func (r *PostgreSQLForecastRepository) GetLatestByCityID(ctx context.Context, ci
 	return forecast, nil
 }
 
-// DeleteOldForecasts removes forecasts older than the specified number of days
-func (r *PostgreSQLForecastRepository) DeleteOldForecasts(ctx context.Context, days int) error {
-	query := `DELETE FROM forecasts WHERE valid_time < NOW() - INTERVAL '%d days'`
-	_, err := r.db.ExecContext(ctx, fmt.Sprintf(query, days))
+// DeleteOldForecasts removes the forecasts matching filter that were valid more than
+// days ago. Without a filter, monthly partitions entirely past the cutoff are dropped
+// first, leaving the DELETE only the month the cutoff falls in and forecasts_default.
+func (r *PostgreSQLForecastRepository) DeleteOldForecasts(ctx context.Context, days int, filter ForecastFilter) (int64, error) {
+	if days < 0 {
+		return 0, fmt.Errorf("retention days must not be negative, got %d", days)
+	}
+	var dropped int64
+	if filter.IsEmpty() {
+		var err error
+		if dropped, err = dropForecastPartitions(ctx, r.db, days); err != nil {
+			return 0, err
+		}
+	}
+
+	where, args := filter.where()
+	args = append(args, days)
+	query := fmt.Sprintf(`DELETE FROM forecasts WHERE %s AND valid_time < NOW() - make_interval(days => $%d)`, where, len(args))
+
+	result, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
-		return fmt.Errorf("failed to delete old forecasts: %w", err)
+		return dropped, fmt.Errorf("failed to delete old forecasts: %w", err)
 	}
-	return nil
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return dropped, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return dropped + deleted, nil
+}
+
+// CountMatching counts the forecasts matching filter
+func (r *PostgreSQLForecastRepository) CountMatching(ctx context.Context, filter ForecastFilter) (int64, error) {
+	where, args := filter.where()
+	var count int64
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM forecasts WHERE `+where, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count forecasts: %w", err)
+	}
+	return count, nil
+}
+
+// DeleteMatching removes the forecasts matching filter in batches, each its own
+// statement, stopping between batches when ctx is canceled
+func (r *PostgreSQLForecastRepository) DeleteMatching(ctx context.Context, filter ForecastFilter, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		return 0, fmt.Errorf("batch size must be positive, got %d", batchSize)
+	}
+	where, args := filter.where()
+	query := fmt.Sprintf(`
+		DELETE FROM forecasts WHERE id IN (
+			SELECT id FROM forecasts WHERE %s ORDER BY id LIMIT $%d
+		)`, where, len(args)+1)
+	args = append(args, batchSize)
+
+	var deleted int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+		result, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete forecasts: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return deleted, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		deleted += rowsAffected
+		if rowsAffected < int64(batchSize) {
+			return deleted, nil
+		}
+	}
+}
+
+// WindRose bins wind in SQL so only the 16 sector rows leave the database. The
+// latest CTE keeps one row per provider and valid hour, the most recently issued.
+func (r *PostgreSQLForecastRepository) WindRose(ctx context.Context, cityID int, startTime, endTime string) (*WindRose, error) {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (source_provider, valid_time) wind_speed, wind_direction
+			FROM forecasts
+			WHERE city_id = $1 AND valid_time >= $2 AND valid_time < $3
+			ORDER BY source_provider, valid_time, forecast_time DESC, id DESC
+		)
+		SELECT CASE
+				   WHEN wind_speed < $4 THEN -1
+				   ELSE FLOOR(MOD(MOD(CAST(wind_direction AS NUMERIC) + $5, 360) + 360, 360) / $6)::int
+			   END AS sector,
+			   COUNT(*), COALESCE(AVG(wind_speed), 0)
+		FROM latest
+		GROUP BY sector`
+
+	width := 360.0 / WindRoseSectors
+	rows, err := reader(r.db).QueryContext(ctx, query, cityID, startTime, endTime, CalmWindSpeed, width/2, width)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wind rose: %w", err)
+	}
+	defer rows.Close()
+
+	rose := &WindRose{}
+	for rows.Next() {
+		var sector, count int
+		var mean float64
+		if err := rows.Scan(&sector, &count, &mean); err != nil {
+			return nil, fmt.Errorf("failed to scan wind rose: %w", err)
+		}
+		rose.Total += count
+		if sector < 0 || sector >= WindRoseSectors {
+			rose.Calm += count
+			continue
+		}
+		rose.Sectors[sector] = WindRoseSector{Count: count, MeanSpeed: mean}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate wind rose: %w", err)
+	}
+	return rose, nil
+}
+
+// DailySummaries aggregates in SQL so only one row per day leaves the database. The
+// latest CTE keeps one row per valid time, the most recently issued across providers.
+func (r *PostgreSQLForecastRepository) DailySummaries(ctx context.Context, cityID int, startTime, endTime, timezone string) ([]*DailySummary, error) {
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (valid_time) valid_time, temperature, precipitation, wind_speed, weather_code
+			FROM forecasts
+			WHERE city_id = $1 AND valid_time >= $2 AND valid_time < $3
+			ORDER BY valid_time, forecast_time DESC, id DESC
+		)
+		SELECT to_char((valid_time AT TIME ZONE $4)::date, 'YYYY-MM-DD') AS day, COUNT(*),
+			   MIN(temperature), MAX(temperature), COALESCE(SUM(precipitation), 0), MAX(wind_speed),
+			   COALESCE(mode() WITHIN GROUP (ORDER BY weather_code) FILTER (WHERE weather_code <> ''), '')
+		FROM latest
+		GROUP BY day
+		ORDER BY day`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, cityID, startTime, endTime, timezone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var days []*DailySummary
+	for rows.Next() {
+		day := &DailySummary{}
+		err := rows.Scan(&day.Date, &day.Periods, &day.MinTemperature, &day.MaxTemperature,
+			&day.Precipitation, &day.MaxWindSpeed, &day.WeatherCode)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan daily summary: %w", err)
+		}
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate daily summaries: %w", err)
+	}
+	return days, nil
+}
+
+// Stats aggregates in SQL so only one row per bucket leaves the database. The metric
+// column and bucket unit come from fixed lists, never from the caller's text.
+func (r *PostgreSQLForecastRepository) Stats(ctx context.Context, cityID int, metric, startTime, endTime, bucket string) ([]*MetricStats, error) {
+	column, err := statsMetric(metric, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast stats: %w", err)
+	}
+	key := `$2::timestamptz AT TIME ZONE 'UTC'`
+	if bucket != BucketNone {
+		key = `date_trunc('` + bucket + `', valid_time AT TIME ZONE 'UTC')`
+	}
+	percentiles := make([]string, len(StatsPercentiles))
+	for i, p := range StatsPercentiles {
+		percentiles[i] = fmt.Sprintf("percentile_cont(%g) WITHIN GROUP (ORDER BY value)", p)
+	}
+
+	query := `
+		WITH latest AS (
+			SELECT DISTINCT ON (source_provider, valid_time) valid_time, ` + column + ` AS value
+			FROM forecasts
+			WHERE city_id = $1 AND valid_time >= $2 AND valid_time < $3 AND ` + column + ` IS NOT NULL
+			ORDER BY source_provider, valid_time, forecast_time DESC, id DESC
+		)
+		SELECT to_char(` + key + `, 'YYYY-MM-DD"T"HH24:MI:SS"Z"') AS bucket, COUNT(*),
+			   MIN(value), MAX(value), AVG(value), ` + strings.Join(percentiles, ", ") + `
+		FROM latest
+		GROUP BY bucket
+		ORDER BY bucket`
+
+	rows, err := reader(r.db).QueryContext(ctx, query, cityID, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get forecast stats: %w", err)
+	}
+	defer rows.Close()
+
+	var buckets []*MetricStats
+	for rows.Next() {
+		stats := &MetricStats{Percentiles: make([]float64, len(StatsPercentiles))}
+		dest := []any{&stats.Start, &stats.Count, &stats.Min, &stats.Max, &stats.Mean}
+		for i := range stats.Percentiles {
+			dest = append(dest, &stats.Percentiles[i])
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan forecast stats: %w", err)
+		}
+		buckets = append(buckets, stats)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate forecast stats: %w", err)
+	}
+	return buckets, nil
 }
 
 // PostgreSQLCityRepository implements CityRepository for PostgreSQL
@@ -319,7 +641,7 @@ func (r *PostgreSQLCityRepository) Create(ctx context.Context, city *City) error
 	).Scan(&city.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to create city: %w", err)
+		return fmt.Errorf("failed to create city: %w", classify(err))
 	}
 
 	city.CreatedAt = now
@@ -330,22 +652,15 @@ func (r *PostgreSQLCityRepository) Create(ctx context.Context, city *City) error
 // GetByID retrieves a city by its ID
 func (r *PostgreSQLCityRepository) GetByID(ctx context.Context, id int) (*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities WHERE id = $1`
 
 	city := &City{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-		&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-		&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-		&city.CreatedAt, &city.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, id), city)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("city with id %d not found", id)
+			return nil, notFound("city with id %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get city: %w", err)
 	}
@@ -353,6 +668,30 @@ func (r *PostgreSQLCityRepository) GetByID(ctx context.Context, id int) (*City,
 	return city, nil
 }
 
+// GetByIDs retrieves the cities with the given IDs in one query
+func (r *PostgreSQLCityRepository) GetByIDs(ctx context.Context, ids []int) ([]*City, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM cities WHERE id IN (%s)`, cityColumns, placeholders(1, len(ids)))
+
+	rows, err := reader(r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cities: %w", err)
+	}
+	cities, err := scanRows[City](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cities: %w", err)
+	}
+	return cities, nil
+}
+
 // Update modifies an existing city record
 func (r *PostgreSQLCityRepository) Update(ctx context.Context, city *City) error {
 	query := `
@@ -371,7 +710,7 @@ func (r *PostgreSQLCityRepository) Update(ctx context.Context, city *City) error
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update city: %w", err)
+		return fmt.Errorf("failed to update city: %w", classify(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -380,7 +719,7 @@ func (r *PostgreSQLCityRepository) Update(ctx context.Context, city *City) error
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("city with id %d not found", city.ID)
+		return notFound("city with id %d not found", city.ID)
 	}
 
 	city.UpdatedAt = now
@@ -401,7 +740,7 @@ func (r *PostgreSQLCityRepository) Delete(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("city with id %d not found", id)
+		return notFound("city with id %d not found", id)
 	}
 
 	return nil
@@ -410,40 +749,25 @@ func (r *PostgreSQLCityRepository) Delete(ctx context.Context, id int) error {
 // List retrieves cities with pagination
 func (r *PostgreSQLCityRepository) List(ctx context.Context, limit, offset int) ([]*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities ORDER BY name ASC LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list cities: %w", err)
 	}
-	defer rows.Close()
-
-	var cities []*City
-	for rows.Next() {
-		city := &City{}
-		err := rows.Scan(
-			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-			&city.CreatedAt, &city.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan city: %w", err)
-		}
-		cities = append(cities, city)
+	cities, err := scanRows[City](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cities: %w", err)
 	}
-
-	return cities, rows.Err()
+	return cities, nil
 }
 
 // Count returns the total number of city records
 func (r *PostgreSQLCityRepository) Count(ctx context.Context) (int, error) {
 	query := `SELECT COUNT(*) FROM cities`
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := reader(r.db).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count cities: %w", err)
 	}
@@ -453,65 +777,35 @@ func (r *PostgreSQLCityRepository) Count(ctx context.Context) (int, error) {
 // GetByName retrieves cities by name
 func (r *PostgreSQLCityRepository) GetByName(ctx context.Context, name string) ([]*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities WHERE LOWER(name) = LOWER($1) ORDER BY population DESC`
 
-	rows, err := r.db.QueryContext(ctx, query, name)
+	rows, err := reader(r.db).QueryContext(ctx, query, name)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cities by name: %w", err)
 	}
-	defer rows.Close()
-
-	var cities []*City
-	for rows.Next() {
-		city := &City{}
-		err := rows.Scan(
-			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-			&city.CreatedAt, &city.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan city: %w", err)
-		}
-		cities = append(cities, city)
+	cities, err := scanRows[City](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cities: %w", err)
 	}
-
-	return cities, rows.Err()
+	return cities, nil
 }
 
 // GetByCountry retrieves cities in a specific country
 func (r *PostgreSQLCityRepository) GetByCountry(ctx context.Context, countryCode string, limit, offset int) ([]*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities WHERE country_code = $1 ORDER BY population DESC LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, countryCode, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, countryCode, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cities by country: %w", err)
 	}
-	defer rows.Close()
-
-	var cities []*City
-	for rows.Next() {
-		city := &City{}
-		err := rows.Scan(
-			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-			&city.CreatedAt, &city.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan city: %w", err)
-		}
-		cities = append(cities, city)
+	cities, err := scanRows[City](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cities: %w", err)
 	}
-
-	return cities, rows.Err()
+	return cities, nil
 }
 
 // GetByCoordinates finds cities within a radius of given coordinates
@@ -519,9 +813,7 @@ func (r *PostgreSQLCityRepository) GetByCountry(ctx context.Context, countryCode
 //	Uses the haversine formula to calculate distance
 func (r *PostgreSQLCityRepository) GetByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at,
+		SELECT ` + cityColumns + `,
 			   (6371 * acos(cos(radians($1)) * cos(radians(latitude)) *
 			   cos(radians(longitude) - radians($2)) + sin(radians($1)) *
 			   sin(radians(latitude)))) AS distance
@@ -531,7 +823,7 @@ func (r *PostgreSQLCityRepository) GetByCoordinates(ctx context.Context, lat, lo
 			  sin(radians(latitude)))) <= $3
 		ORDER BY distance ASC LIMIT $4`
 
-	rows, err := r.db.QueryContext(ctx, query, lat, lon, radiusKm, limit)
+	rows, err := reader(r.db).QueryContext(ctx, query, lat, lon, radiusKm, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cities by coordinates: %w", err)
 	}
@@ -541,12 +833,7 @@ func (r *PostgreSQLCityRepository) GetByCoordinates(ctx context.Context, lat, lo
 	for rows.Next() {
 		city := &City{}
 		var distance float64
-		err := rows.Scan(
-			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-			&city.CreatedAt, &city.UpdatedAt, &distance,
-		)
+		err := scanInto(rows, city, &distance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan city: %w", err)
 		}
@@ -559,22 +846,15 @@ func (r *PostgreSQLCityRepository) GetByCoordinates(ctx context.Context, lat, lo
 // GetByGeonameID retrieves a city by its GeoNames ID
 func (r *PostgreSQLCityRepository) GetByGeonameID(ctx context.Context, geonameID int) (*City, error) {
 	query := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities WHERE geoname_id = $1`
 
 	city := &City{}
-	err := r.db.QueryRowContext(ctx, query, geonameID).Scan(
-		&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-		&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-		&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-		&city.CreatedAt, &city.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, geonameID), city)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("city with geoname_id %d not found", geonameID)
+			return nil, notFound("city with geoname_id %d not found", geonameID)
 		}
 		return nil, fmt.Errorf("failed to get city by geoname_id: %w", err)
 	}
@@ -585,36 +865,80 @@ func (r *PostgreSQLCityRepository) GetByGeonameID(ctx context.Context, geonameID
 // Search performs text search on city names
 func (r *PostgreSQLCityRepository) Search(ctx context.Context, query string, limit int) ([]*City, error) {
 	searchQuery := `
-		SELECT id, name, country, country_code, region, latitude, longitude,
-			   elevation, population, timezone, geoname_id, is_capital,
-			   is_active, created_at, updated_at
+		SELECT ` + cityColumns + `
 		FROM cities
 		WHERE LOWER(name) LIKE LOWER($1) OR LOWER(country) LIKE LOWER($1)
 		ORDER BY population DESC LIMIT $2`
 
 	searchPattern := "%" + query + "%"
-	rows, err := r.db.QueryContext(ctx, searchQuery, searchPattern, limit)
+	rows, err := reader(r.db).QueryContext(ctx, searchQuery, searchPattern, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search cities: %w", err)
 	}
+	cities, err := scanRows[City](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan cities: %w", err)
+	}
+	return cities, nil
+}
+
+// SetLocalizedName stores a city's name in the given language
+//
+//	A non-preferred name never replaces a preferred one for the same language
+func (r *PostgreSQLCityRepository) SetLocalizedName(ctx context.Context, name *CityName) error {
+	query := `
+		INSERT INTO city_names (city_id, language, name, is_preferred)
+		VALUES ($1, LOWER($2), $3, $4)
+		ON CONFLICT (city_id, language) DO UPDATE SET
+			name = EXCLUDED.name, is_preferred = EXCLUDED.is_preferred
+		WHERE EXCLUDED.is_preferred OR NOT city_names.is_preferred`
+
+	_, err := r.db.ExecContext(ctx, query, name.CityID, name.Language, name.Name, name.IsPreferred)
+	if err != nil {
+		return fmt.Errorf("failed to set localized name: %w", err)
+	}
+	return nil
+}
+
+// GetLocalizedNames retrieves names for the given cities restricted to the given languages
+func (r *PostgreSQLCityRepository) GetLocalizedNames(ctx context.Context, cityIDs []int, languages []string) (map[int]map[string]string, error) {
+	names := make(map[int]map[string]string)
+	if len(cityIDs) == 0 || len(languages) == 0 {
+		return names, nil
+	}
+
+	args := make([]any, 0, len(cityIDs)+len(languages))
+	for _, id := range cityIDs {
+		args = append(args, id)
+	}
+	for _, lang := range languages {
+		args = append(args, strings.ToLower(lang))
+	}
+
+	query := fmt.Sprintf(`
+		SELECT city_id, language, name FROM city_names
+		WHERE city_id IN (%s) AND language IN (%s)`,
+		placeholders(1, len(cityIDs)), placeholders(len(cityIDs)+1, len(languages)))
+
+	rows, err := reader(r.db).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get localized names: %w", err)
+	}
 	defer rows.Close()
 
-	var cities []*City
 	for rows.Next() {
-		city := &City{}
-		err := rows.Scan(
-			&city.ID, &city.Name, &city.Country, &city.CountryCode, &city.Region,
-			&city.Latitude, &city.Longitude, &city.Elevation, &city.Population,
-			&city.Timezone, &city.GeonameID, &city.IsCapital, &city.IsActive,
-			&city.CreatedAt, &city.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan city: %w", err)
+		var cityID int
+		var language, name string
+		if err := rows.Scan(&cityID, &language, &name); err != nil {
+			return nil, fmt.Errorf("failed to scan localized name: %w", err)
 		}
-		cities = append(cities, city)
+		if names[cityID] == nil {
+			names[cityID] = make(map[string]string)
+		}
+		names[cityID][language] = name
 	}
 
-	return cities, rows.Err()
+	return names, rows.Err()
 }
 
 // PostgreSQLPlaceRepository implements PlaceRepository for PostgreSQL
@@ -648,7 +972,7 @@ func (r *PostgreSQLPlaceRepository) Create(ctx context.Context, place *Place) er
 	).Scan(&place.ID)
 
 	if err != nil {
-		return fmt.Errorf("failed to create place: %w", err)
+		return fmt.Errorf("failed to create place: %w", classify(err))
 	}
 
 	place.CreatedAt = now
@@ -659,24 +983,15 @@ func (r *PostgreSQLPlaceRepository) Create(ctx context.Context, place *Place) er
 // GetByID retrieves a place by its ID
 func (r *PostgreSQLPlaceRepository) GetByID(ctx context.Context, id int) (*Place, error) {
 	query := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at
+		SELECT ` + placeColumns + `
 		FROM places WHERE id = $1`
 
 	place := &Place{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-		&place.City, &place.Region, &place.PostalCode, &place.Country,
-		&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-		&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-		&place.CreatedAt, &place.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, id), place)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("place with id %d not found", id)
+			return nil, notFound("place with id %d not found", id)
 		}
 		return nil, fmt.Errorf("failed to get place: %w", err)
 	}
@@ -703,7 +1018,7 @@ func (r *PostgreSQLPlaceRepository) Update(ctx context.Context, place *Place) er
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update place: %w", err)
+		return fmt.Errorf("failed to update place: %w", classify(err))
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -712,7 +1027,7 @@ func (r *PostgreSQLPlaceRepository) Update(ctx context.Context, place *Place) er
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("place with id %d not found", place.ID)
+		return notFound("place with id %d not found", place.ID)
 	}
 
 	place.UpdatedAt = now
@@ -733,7 +1048,7 @@ func (r *PostgreSQLPlaceRepository) Delete(ctx context.Context, id int) error {
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("place with id %d not found", id)
+		return notFound("place with id %d not found", id)
 	}
 
 	return nil
@@ -742,42 +1057,25 @@ func (r *PostgreSQLPlaceRepository) Delete(ctx context.Context, id int) error {
 // List retrieves places with pagination
 func (r *PostgreSQLPlaceRepository) List(ctx context.Context, limit, offset int) ([]*Place, error) {
 	query := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at
+		SELECT ` + placeColumns + `
 		FROM places ORDER BY confidence DESC LIMIT $1 OFFSET $2`
 
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list places: %w", err)
 	}
-	defer rows.Close()
-
-	var places []*Place
-	for rows.Next() {
-		place := &Place{}
-		err := rows.Scan(
-			&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-			&place.City, &place.Region, &place.PostalCode, &place.Country,
-			&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-			&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-			&place.CreatedAt, &place.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan place: %w", err)
-		}
-		places = append(places, place)
+	places, err := scanRows[Place](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan places: %w", err)
 	}
-
-	return places, rows.Err()
+	return places, nil
 }
 
 // Count returns the total number of place records
 func (r *PostgreSQLPlaceRepository) Count(ctx context.Context) (int, error) {
 	query := `SELECT COUNT(*) FROM places`
 	var count int
-	err := r.db.QueryRowContext(ctx, query).Scan(&count)
+	err := reader(r.db).QueryRowContext(ctx, query).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to count places: %w", err)
 	}
@@ -787,10 +1085,7 @@ func (r *PostgreSQLPlaceRepository) Count(ctx context.Context) (int, error) {
 // GetByCoordinates finds places within a radius of given coordinates
 func (r *PostgreSQLPlaceRepository) GetByCoordinates(ctx context.Context, lat, lon, radiusKm float64, limit int) ([]*Place, error) {
 	query := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at,
+		SELECT ` + placeColumns + `,
 			   (6371 * acos(cos(radians($1)) * cos(radians(latitude)) *
 			   cos(radians(longitude) - radians($2)) + sin(radians($1)) *
 			   sin(radians(latitude)))) AS distance
@@ -800,7 +1095,7 @@ func (r *PostgreSQLPlaceRepository) GetByCoordinates(ctx context.Context, lat, l
 			  sin(radians(latitude)))) <= $3
 		ORDER BY distance ASC LIMIT $4`
 
-	rows, err := r.db.QueryContext(ctx, query, lat, lon, radiusKm, limit)
+	rows, err := reader(r.db).QueryContext(ctx, query, lat, lon, radiusKm, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get places by coordinates: %w", err)
 	}
@@ -810,13 +1105,7 @@ func (r *PostgreSQLPlaceRepository) GetByCoordinates(ctx context.Context, lat, l
 	for rows.Next() {
 		place := &Place{}
 		var distance float64
-		err := rows.Scan(
-			&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-			&place.City, &place.Region, &place.PostalCode, &place.Country,
-			&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-			&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-			&place.CreatedAt, &place.UpdatedAt, &distance,
-		)
+		err := scanInto(rows, place, &distance)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan place: %w", err)
 		}
@@ -829,10 +1118,7 @@ func (r *PostgreSQLPlaceRepository) GetByCoordinates(ctx context.Context, lat, l
 // Search performs text search on place names and addresses
 func (r *PostgreSQLPlaceRepository) Search(ctx context.Context, query string, limit int) ([]*Place, error) {
 	searchQuery := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at
+		SELECT ` + placeColumns + `
 		FROM places
 		WHERE LOWER(display_name) LIKE LOWER($1)
 		   OR LOWER(address_line1) LIKE LOWER($1)
@@ -840,89 +1126,58 @@ func (r *PostgreSQLPlaceRepository) Search(ctx context.Context, query string, li
 		ORDER BY confidence DESC LIMIT $2`
 
 	searchPattern := "%" + query + "%"
-	rows, err := r.db.QueryContext(ctx, searchQuery, searchPattern, limit)
+	rows, err := reader(r.db).QueryContext(ctx, searchQuery, searchPattern, limit)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search places: %w", err)
 	}
-	defer rows.Close()
-
-	var places []*Place
-	for rows.Next() {
-		place := &Place{}
-		err := rows.Scan(
-			&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-			&place.City, &place.Region, &place.PostalCode, &place.Country,
-			&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-			&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-			&place.CreatedAt, &place.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan place: %w", err)
-		}
-		places = append(places, place)
+	places, err := scanRows[Place](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan places: %w", err)
 	}
-
-	return places, rows.Err()
+	return places, nil
 }
 
 // GetBySource retrieves places by their geocoding source
 func (r *PostgreSQLPlaceRepository) GetBySource(ctx context.Context, source string, limit, offset int) ([]*Place, error) {
 	query := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at
+		SELECT ` + placeColumns + `
 		FROM places WHERE source = $1 ORDER BY confidence DESC LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, source, limit, offset)
+	rows, err := reader(r.db).QueryContext(ctx, query, source, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get places by source: %w", err)
 	}
-	defer rows.Close()
-
-	var places []*Place
-	for rows.Next() {
-		place := &Place{}
-		err := rows.Scan(
-			&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-			&place.City, &place.Region, &place.PostalCode, &place.Country,
-			&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-			&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-			&place.CreatedAt, &place.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan place: %w", err)
-		}
-		places = append(places, place)
+	places, err := scanRows[Place](rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan places: %w", err)
 	}
-
-	return places, rows.Err()
+	return places, nil
 }
 
 // GetBySourcePlaceID retrieves a place by its source-specific ID
 func (r *PostgreSQLPlaceRepository) GetBySourcePlaceID(ctx context.Context, source, sourcePlaceID string) (*Place, error) {
 	query := `
-		SELECT id, display_name, address_line1, address_line2, city, region,
-			   postal_code, country, country_code, latitude, longitude,
-			   place_type, confidence, source, source_place_id, bounding_box,
-			   created_at, updated_at
+		SELECT ` + placeColumns + `
 		FROM places WHERE source = $1 AND source_place_id = $2`
 
 	place := &Place{}
-	err := r.db.QueryRowContext(ctx, query, source, sourcePlaceID).Scan(
-		&place.ID, &place.DisplayName, &place.AddressLine1, &place.AddressLine2,
-		&place.City, &place.Region, &place.PostalCode, &place.Country,
-		&place.CountryCode, &place.Latitude, &place.Longitude, &place.PlaceType,
-		&place.Confidence, &place.Source, &place.SourcePlaceID, &place.BoundingBox,
-		&place.CreatedAt, &place.UpdatedAt,
-	)
+	err := scanInto(reader(r.db).QueryRowContext(ctx, query, source, sourcePlaceID), place)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("place with source %s and source_place_id %s not found", source, sourcePlaceID)
+			return nil, notFound("place with source %s and source_place_id %s not found", source, sourcePlaceID)
 		}
 		return nil, fmt.Errorf("failed to get place by source place id: %w", err)
 	}
 
 	return place, nil
 }
+
+// placeholders returns a comma-separated list of n positional parameters starting at $start
+func placeholders(start, n int) string {
+	params := make([]string, n)
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(params, ", ")
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -32,6 +34,18 @@ func (m *MockDB) ExecContext(ctx context.Context, query string, args ...any) (sq
 	return &MockResult{rowsAffected: 1, lastInsertID: 123}, nil
 }
 
+// execRecorder is a MockDB that records the last statement executed
+type execRecorder struct {
+	MockDB
+	query string
+	args  []any
+}
+
+func (r *execRecorder) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	r.query, r.args = query, args
+	return r.MockDB.ExecContext(ctx, query, args...)
+}
+
 // MockResult implements sql.Result for testing
 type MockResult struct {
 	lastInsertID int64
@@ -89,6 +103,21 @@ func TestRepository(t *testing.T) {
 		}
 	})
 
+	t.Run("CreateBatch", func(t *testing.T) {
+		query, args := buildForecastInsert([]*Forecast{{CityID: 1}, {CityID: 2}}, "2024-01-01T00:00:00Z")
+		if !strings.Contains(query, "($21, $22,") || !strings.HasSuffix(query, "$40) RETURNING id") {
+			t.Errorf("Unexpected multi-row INSERT: %s", query)
+		}
+		if len(args) != 2*forecastInsertColumns || args[forecastInsertColumns] != 2 {
+			t.Errorf("Unexpected arguments: %v", args)
+		}
+
+		repo := NewPostgreSQLForecastRepository(&MockDB{shouldError: true, errorMsg: "insert failed"})
+		if err := repo.CreateBatch(context.Background(), []*Forecast{{CityID: 1}}); err == nil {
+			t.Error("Expected error from database, got nil")
+		}
+	})
+
 	t.Run("Interface Compliance", func(t *testing.T) {
 		var _ Repository[Forecast] = (*PostgreSQLForecastRepository)(nil)
 		var _ ForecastRepository = (*PostgreSQLForecastRepository)(nil)
@@ -196,13 +225,30 @@ func TestRepository(t *testing.T) {
 
 	t.Run("Exec Context", func(t *testing.T) {
 		t.Run("DeleteOldForecasts succeeds with mock", func(t *testing.T) {
-			mockDB := &MockDB{shouldError: false}
+			mockDB := newScalarDB(t, "0") // no partitions to drop
 			repo := NewPostgreSQLForecastRepository(mockDB)
 			ctx := context.Background()
 
-			err := repo.DeleteOldForecasts(ctx, 7)
+			deleted, err := repo.DeleteOldForecasts(ctx, 7, ForecastFilter{})
+			if err != nil || deleted != 1 {
+				t.Errorf("Expected one deleted row, got %d (%v)", deleted, err)
+			}
+		})
+
+		t.Run("DeleteOldForecasts binds its parameters", func(t *testing.T) {
+			db := &execRecorder{}
+			_, err := NewPostgreSQLForecastRepository(db).DeleteOldForecasts(context.Background(), 14, ForecastFilter{CityID: 3, SourceProvider: "NWS"})
 			if err != nil {
-				t.Errorf("Expected successful operation, got error: %v", err)
+				t.Fatalf("DeleteOldForecasts failed: %v", err)
+			}
+			if strings.Contains(db.query, "14") || !strings.Contains(db.query, "make_interval(days => $3)") {
+				t.Errorf("Expected the days to be a bound parameter, got %q", db.query)
+			}
+			if !slices.Equal(db.args, []any{3, "NWS", 14}) {
+				t.Errorf("Expected city, provider and days as arguments, got %v", db.args)
+			}
+			if _, err := NewPostgreSQLForecastRepository(db).DeleteOldForecasts(context.Background(), -1, ForecastFilter{}); err == nil {
+				t.Error("Expected an error for negative days")
 			}
 		})
 
@@ -0,0 +1,88 @@
+package repo
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Rows are mapped to the repository structs by their db tags: columnList renders the
+// SELECT list of a struct in field order and scanInto scans a row selected with it, so
+// a column is added by adding a tagged field (and writing it in INSERT and UPDATE)
+// rather than by editing every scan of the table.
+
+// Column lists of the structs read with scanInto
+var (
+	forecastColumns = columnList[Forecast]("")
+	cityColumns     = columnList[City]("")
+	placeColumns    = columnList[Place]("")
+)
+
+// structColumns are the db-tagged fields of a struct type in declaration order
+type structColumns struct {
+	names  []string
+	fields []int
+}
+
+var columnCache sync.Map // reflect.Type -> *structColumns
+
+func columnsOf(t reflect.Type) *structColumns {
+	if cached, ok := columnCache.Load(t); ok {
+		return cached.(*structColumns)
+	}
+
+	columns := &structColumns{}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if name == "" || name == "-" || !field.IsExported() {
+			continue
+		}
+		columns.names = append(columns.names, name)
+		columns.fields = append(columns.fields, i)
+	}
+	cached, _ := columnCache.LoadOrStore(t, columns)
+	return cached.(*structColumns)
+}
+
+// columnList returns the db columns of T separated by commas, qualified with alias when
+// it is set (e.g. "f" for "f.id, f.city_id, ...")
+func columnList[T any](alias string) string {
+	names := columnsOf(reflect.TypeFor[T]()).names
+	if alias == "" {
+		return strings.Join(names, ", ")
+	}
+	qualified := make([]string, len(names))
+	for i, name := range names {
+		qualified[i] = alias + "." + name
+	}
+	return strings.Join(qualified, ", ")
+}
+
+// scanInto scans a row selected with columnList[T] into dest, followed by any extra
+// columns selected after the list (such as a computed distance) into extra
+func scanInto[T any](row rowScanner, dest *T, extra ...any) error {
+	value := reflect.ValueOf(dest).Elem()
+	columns := columnsOf(value.Type())
+	targets := make([]any, len(columns.fields), len(columns.fields)+len(extra))
+	for i, field := range columns.fields {
+		targets[i] = value.Field(field).Addr().Interface()
+	}
+	return row.Scan(append(targets, extra...)...)
+}
+
+// scanRows scans every row selected with columnList[T] and closes rows
+func scanRows[T any](rows *sql.Rows) ([]*T, error) {
+	defer rows.Close()
+
+	var items []*T
+	for rows.Next() {
+		item := new(T)
+		if err := scanInto(rows, item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
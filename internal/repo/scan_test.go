@@ -0,0 +1,56 @@
+package repo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeRow scans fixed values into pointers to the types the repository structs use
+type fakeRow []any
+
+func (r fakeRow) Scan(dest ...any) error {
+	for i, d := range dest {
+		switch target := d.(type) {
+		case *int:
+			*target = r[i].(int)
+		case *string:
+			*target = r[i].(string)
+		case *float64:
+			*target = r[i].(float64)
+		case *bool:
+			*target = r[i].(bool)
+		}
+	}
+	return nil
+}
+
+func TestColumnList(t *testing.T) {
+	columns := columnList[City]("")
+	if !strings.HasPrefix(columns, "id, name, country, country_code") || !strings.HasSuffix(columns, "created_at, updated_at") {
+		t.Errorf("Expected the db tags in field order, got %q", columns)
+	}
+	if got := columnList[City]("c"); !strings.HasPrefix(got, "c.id, c.name") {
+		t.Errorf("Expected qualified columns, got %q", got)
+	}
+	if n := strings.Count(forecastColumns, ",") + 1; n != len(columnsOf(reflect.TypeFor[Forecast]()).fields) {
+		t.Errorf("Expected one column per tagged field, got %d", n)
+	}
+}
+
+func TestScanInto(t *testing.T) {
+	row := fakeRow{7, "Paris", "France", "FR", "Île-de-France", 48.85, 2.35, 35.0, 2148000,
+		"Europe/Paris", 2988507, true, true, "2025-08-15T12:00:00Z", "2025-08-15T13:00:00Z", 1.5}
+
+	city := &City{}
+	var distance float64
+	if err := scanInto(row, city, &distance); err != nil {
+		t.Fatalf("scanInto failed: %v", err)
+	}
+	if city.ID != 7 || city.Name != "Paris" || city.GeonameID != 2988507 || !city.IsCapital || city.UpdatedAt != "2025-08-15T13:00:00Z" {
+		t.Errorf("Expected every column scanned into its field, got %+v", city)
+	}
+	if distance != 1.5 {
+		t.Errorf("Expected the extra column scanned after the list, got %v", distance)
+	}
+}
@@ -0,0 +1,146 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+const shareLinkColumns = `id, user_id, resource_type, resource_id, expires_at, revoked_at, created_at`
+
+// PostgreSQLShareLinkRepository implements ShareLinkRepository for PostgreSQL
+type PostgreSQLShareLinkRepository struct {
+	db DB
+}
+
+// NewPostgreSQLShareLinkRepository creates a new PostgreSQL share link repository
+func NewPostgreSQLShareLinkRepository(db DB) ShareLinkRepository {
+	return &PostgreSQLShareLinkRepository{db: db}
+}
+
+// Create inserts a new share link
+func (r *PostgreSQLShareLinkRepository) Create(ctx context.Context, link *ShareLink) error {
+	query := `
+		INSERT INTO share_links (user_id, resource_type, resource_id, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		link.UserID, link.ResourceType, link.ResourceID, link.ExpiresAt, now,
+	).Scan(&link.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create share link: %w", classify(err))
+	}
+
+	link.CreatedAt = now
+	return nil
+}
+
+// GetByID retrieves a share link by ID
+func (r *PostgreSQLShareLinkRepository) GetByID(ctx context.Context, id int) (*ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE id = $1`
+
+	link, err := scanShareLink(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("share link with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get share link: %w", err)
+	}
+
+	return link, nil
+}
+
+// ListByUser retrieves a user's share links, newest first
+func (r *PostgreSQLShareLinkRepository) ListByUser(ctx context.Context, userID int) ([]*ShareLink, error) {
+	query := `SELECT ` + shareLinkColumns + ` FROM share_links WHERE user_id = $1 ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list share links: %w", err)
+	}
+	defer rows.Close()
+
+	var links []*ShareLink
+	for rows.Next() {
+		link, err := scanShareLink(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan share link: %w", err)
+		}
+		links = append(links, link)
+	}
+
+	return links, rows.Err()
+}
+
+// Revoke marks a share link as revoked
+func (r *PostgreSQLShareLinkRepository) Revoke(ctx context.Context, id int) error {
+	query := `UPDATE share_links SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, id, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to revoke share link: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("share link with id %d not found", id)
+	}
+
+	return nil
+}
+
+// RevokeByUser revokes every active share link of a user
+func (r *PostgreSQLShareLinkRepository) RevokeByUser(ctx context.Context, userID int) (int64, error) {
+	query := `UPDATE share_links SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2`
+	result, err := r.db.ExecContext(ctx, query, userID, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return 0, fmt.Errorf("failed to revoke share links: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// DeleteExpired removes share links past their expiry
+func (r *PostgreSQLShareLinkRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	query := `DELETE FROM share_links WHERE expires_at <= NOW()`
+	result, err := r.db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired share links: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return rowsAffected, nil
+}
+
+// scanShareLink scans a single share link row, mapping a NULL revocation time to an
+// empty string
+func scanShareLink(row rowScanner) (*ShareLink, error) {
+	link := &ShareLink{}
+	var revokedAt sql.NullString
+	err := row.Scan(
+		&link.ID, &link.UserID, &link.ResourceType, &link.ResourceID,
+		&link.ExpiresAt, &revokedAt, &link.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	link.RevokedAt = revokedAt.String
+	return link, nil
+}
@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// StatsMetrics maps the forecast metrics statistics can be computed over to their
+// columns. Only these names are ever interpolated into SQL.
+var StatsMetrics = map[string]string{
+	"temperature":   "temperature",
+	"feels_like":    "feels_like",
+	"humidity":      "humidity",
+	"pressure":      "pressure",
+	"wind_speed":    "wind_speed",
+	"precipitation": "precipitation",
+	"cloud_cover":   "cloud_cover",
+	"visibility":    "visibility",
+	"uv_index":      "uv_index",
+}
+
+// Statistics buckets
+const (
+	BucketNone  = ""      // one bucket spanning the whole window
+	BucketDay   = "day"   // UTC days
+	BucketWeek  = "week"  // ISO weeks starting Monday, UTC
+	BucketMonth = "month" // UTC calendar months
+)
+
+// StatsPercentiles are the percentiles reported for every bucket
+var StatsPercentiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+
+// MetricStats summarizes one metric over one bucket of time
+type MetricStats struct {
+	Start       string // RFC3339 start of the bucket, or of the window for BucketNone
+	Count       int
+	Min         float64
+	Max         float64
+	Mean        float64
+	Percentiles []float64 // one per StatsPercentiles, interpolated like percentile_cont
+}
+
+// statsMetric returns the column for a metric and the bucket's date_trunc unit
+func statsMetric(metric, bucket string) (string, error) {
+	column, ok := StatsMetrics[metric]
+	if !ok {
+		return "", fmt.Errorf("unknown metric %q", metric)
+	}
+	switch bucket {
+	case BucketNone, BucketDay, BucketWeek, BucketMonth:
+		return column, nil
+	default:
+		return "", fmt.Errorf("unknown bucket %q", bucket)
+	}
+}
+
+// metricValue reads a metric from a forecast
+func metricValue(f *Forecast, metric string) float64 {
+	switch metric {
+	case "temperature":
+		return f.Temperature
+	case "feels_like":
+		return f.FeelsLike
+	case "humidity":
+		return f.Humidity
+	case "pressure":
+		return f.Pressure
+	case "wind_speed":
+		return f.WindSpeed
+	case "precipitation":
+		return f.Precipitation
+	case "cloud_cover":
+		return f.CloudCover
+	case "visibility":
+		return f.Visibility
+	default:
+		return f.UVIndex
+	}
+}
+
+// bucketStart truncates a time to the start of its bucket in UTC
+func bucketStart(t time.Time, bucket string, windowStart time.Time) time.Time {
+	t = t.UTC()
+	switch bucket {
+	case BucketDay:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case BucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case BucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return windowStart.UTC()
+	}
+}
+
+// summarizeMetric computes the statistics of values, which must not be empty
+func summarizeMetric(start time.Time, values []float64) *MetricStats {
+	slices.Sort(values)
+	stats := &MetricStats{
+		Start: start.Format(time.RFC3339),
+		Count: len(values),
+		Min:   values[0],
+		Max:   values[len(values)-1],
+	}
+	for _, v := range values {
+		stats.Mean += v
+	}
+	stats.Mean /= float64(len(values))
+	for _, p := range StatsPercentiles {
+		stats.Percentiles = append(stats.Percentiles, percentile(values, p))
+	}
+	return stats
+}
+
+// percentile interpolates linearly between the closest ranks of sorted values, as
+// PostgreSQL's percentile_cont does
+func percentile(sorted []float64, p float64) float64 {
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower+1 >= len(sorted) {
+		return sorted[lower]
+	}
+	return sorted[lower] + (rank-float64(lower))*(sorted[lower+1]-sorted[lower])
+}
@@ -0,0 +1,93 @@
+package repo
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []float64{1, 2, 3, 4}
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 1},
+		{0.5, 2.5},
+		{0.9, 3.7},
+		{1, 4},
+	}
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile([]float64{7}, 0.9); got != 7 {
+		t.Errorf("Expected a single value to be every percentile, got %v", got)
+	}
+}
+
+func TestForecastRepository_StatsRejectsUnknownMetric(t *testing.T) {
+	repo := NewPostgreSQLForecastRepository(&MockDB{})
+	if _, err := repo.Stats(context.Background(), 1, "temperature; DROP TABLE forecasts", "2025-08-01T00:00:00Z", "2025-08-02T00:00:00Z", ""); err == nil {
+		t.Error("Expected an unknown metric to be rejected before querying")
+	}
+	if _, err := repo.Stats(context.Background(), 1, "temperature", "2025-08-01T00:00:00Z", "2025-08-02T00:00:00Z", "decade"); err == nil {
+		t.Error("Expected an unknown bucket to be rejected before querying")
+	}
+}
+
+func TestFileForecastRepository_Stats(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	forecasts := []*Forecast{
+		// Superseded by the later issue for the same provider and time
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-07-31T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 99},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 10},
+		{CityID: 1, SourceProvider: "Met.no", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: 20},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-02T12:00:00Z", Temperature: 30, Humidity: 50},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-04T12:00:00Z", Temperature: 40},
+		// Outside the window or for another city
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-09-01T12:00:00Z", Temperature: -50},
+		{CityID: 2, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", Temperature: -50},
+	}
+	for _, f := range forecasts {
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	whole, err := engine.Forecasts().Stats(ctx, 1, "temperature", "2025-08-01T00:00:00Z", "2025-08-31T00:00:00Z", BucketNone)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	if len(whole) != 1 {
+		t.Fatalf("Expected one window, got %+v", whole)
+	}
+	stats := whole[0]
+	if stats.Start != "2025-08-01T00:00:00Z" || stats.Count != 4 || stats.Min != 10 || stats.Max != 40 || stats.Mean != 25 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if len(stats.Percentiles) != len(StatsPercentiles) || stats.Percentiles[2] != 25 {
+		t.Errorf("Expected the median to be 25, got %v", stats.Percentiles)
+	}
+
+	weekly, err := engine.Forecasts().Stats(ctx, 1, "temperature", "2025-08-01T00:00:00Z", "2025-08-31T00:00:00Z", BucketWeek)
+	if err != nil {
+		t.Fatalf("Stats failed: %v", err)
+	}
+	// 2025-08-01 is a Friday: the 1st and 2nd fall in the week of July 28th
+	if len(weekly) != 2 || weekly[0].Start != "2025-07-28T00:00:00Z" || weekly[0].Count != 3 || weekly[1].Start != "2025-08-04T00:00:00Z" {
+		t.Errorf("Unexpected weekly stats %+v", weekly)
+	}
+
+	humidity, _ := engine.Forecasts().Stats(ctx, 1, "humidity", "2025-08-02T00:00:00Z", "2025-08-03T00:00:00Z", BucketDay)
+	if len(humidity) != 1 || humidity[0].Max != 50 {
+		t.Errorf("Expected daily humidity stats, got %+v", humidity)
+	}
+}
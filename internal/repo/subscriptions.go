@@ -0,0 +1,238 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const alertSubscriptionColumns = `id, user_id, email, latitude, longitude, radius_km, min_severity, mode, token,
+		   last_notified_at, created_at`
+
+// PostgreSQLAlertSubscriptionRepository implements AlertSubscriptionRepository for PostgreSQL
+type PostgreSQLAlertSubscriptionRepository struct {
+	db DB
+}
+
+// NewPostgreSQLAlertSubscriptionRepository creates a new PostgreSQL alert subscription repository
+func NewPostgreSQLAlertSubscriptionRepository(db DB) AlertSubscriptionRepository {
+	return &PostgreSQLAlertSubscriptionRepository{db: db}
+}
+
+// Create inserts a new subscription
+func (r *PostgreSQLAlertSubscriptionRepository) Create(ctx context.Context, subscription *AlertSubscription) error {
+	query := `
+		INSERT INTO alert_subscriptions (user_id, email, latitude, longitude, radius_km, min_severity, mode, token, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id`
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		subscription.UserID, subscription.Email, subscription.Latitude, subscription.Longitude,
+		subscription.RadiusKm, subscription.MinSeverity, subscription.Mode, subscription.Token, now,
+	).Scan(&subscription.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create alert subscription: %w", classify(err))
+	}
+
+	subscription.CreatedAt = now
+	return nil
+}
+
+// GetByID retrieves a subscription by ID
+func (r *PostgreSQLAlertSubscriptionRepository) GetByID(ctx context.Context, id int) (*AlertSubscription, error) {
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM alert_subscriptions WHERE id = $1`
+
+	subscription, err := scanAlertSubscription(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("alert subscription with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get alert subscription: %w", err)
+	}
+
+	return subscription, nil
+}
+
+// ListByUser retrieves a user's subscriptions, newest first
+func (r *PostgreSQLAlertSubscriptionRepository) ListByUser(ctx context.Context, userID int) ([]*AlertSubscription, error) {
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM alert_subscriptions WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC`
+
+	return r.list(ctx, "failed to list alert subscriptions", query, userID)
+}
+
+// Delete removes a subscription; its queued notifications cascade
+func (r *PostgreSQLAlertSubscriptionRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete alert subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("alert subscription with id %d not found", id)
+	}
+
+	return nil
+}
+
+// DeleteByToken removes the subscription with an unsubscribe token
+func (r *PostgreSQLAlertSubscriptionRepository) DeleteByToken(ctx context.Context, token string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM alert_subscriptions WHERE token = $1`, token)
+	if err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("alert subscription not found")
+	}
+
+	return nil
+}
+
+// ListMatching retrieves the subscriptions whose radius contains a point
+//
+//	Uses the haversine formula to calculate distance
+func (r *PostgreSQLAlertSubscriptionRepository) ListMatching(ctx context.Context, lat, lon float64, severities []string) ([]*AlertSubscription, error) {
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM alert_subscriptions
+		WHERE min_severity = ANY($3)
+		  AND (6371 * acos(LEAST(1, cos(radians($1)) * cos(radians(latitude)) *
+			  cos(radians(longitude) - radians($2)) + sin(radians($1)) *
+			  sin(radians(latitude))))) <= radius_km
+		ORDER BY id`
+
+	return r.list(ctx, "failed to list matching alert subscriptions", query, lat, lon, pq.Array(severities))
+}
+
+// Queue records an alert to be sent to subscriptions, ignoring ones already queued
+func (r *PostgreSQLAlertSubscriptionRepository) Queue(ctx context.Context, alertID int, subscriptionIDs []int) error {
+	if len(subscriptionIDs) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, len(subscriptionIDs))
+	for i, id := range subscriptionIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		INSERT INTO alert_notifications (subscription_id, alert_id, created_at)
+		SELECT unnest($2::int[]), $1, $3
+		ON CONFLICT (subscription_id, alert_id) DO NOTHING`
+
+	_, err := r.db.ExecContext(ctx, query, alertID, pq.Array(ids), time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to queue alert notifications: %w", classify(err))
+	}
+
+	return nil
+}
+
+// ListDue retrieves up to limit subscriptions with unsent notifications that are due
+func (r *PostgreSQLAlertSubscriptionRepository) ListDue(ctx context.Context, digestBefore string, limit int) ([]*AlertSubscription, error) {
+	query := `SELECT ` + alertSubscriptionColumns + ` FROM alert_subscriptions s
+		WHERE EXISTS (SELECT 1 FROM alert_notifications n WHERE n.subscription_id = s.id AND n.sent_at IS NULL)
+		  AND (mode = 'immediate' OR COALESCE(last_notified_at, created_at) <= $1)
+		ORDER BY id LIMIT $2`
+
+	return r.list(ctx, "failed to list due alert subscriptions", query, digestBefore, limit)
+}
+
+// ListPending retrieves the alerts queued for a subscription and not sent yet
+func (r *PostgreSQLAlertSubscriptionRepository) ListPending(ctx context.Context, subscriptionID int) ([]*Alert, error) {
+	query := `SELECT ` + alertColumns + ` FROM alerts
+		WHERE id IN (SELECT alert_id FROM alert_notifications WHERE subscription_id = $1 AND sent_at IS NULL)
+		ORDER BY created_at, id`
+
+	rows, err := r.db.QueryContext(ctx, query, subscriptionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending alerts: %w", err)
+	}
+	defer rows.Close()
+
+	return scanAlerts(rows)
+}
+
+// MarkSent records that alerts were sent to a subscription at sentAt. Both updates run
+// in one statement so a digest's clock only moves when its notifications do.
+func (r *PostgreSQLAlertSubscriptionRepository) MarkSent(ctx context.Context, subscriptionID int, alertIDs []int, sentAt string) error {
+	ids := make([]int64, len(alertIDs))
+	for i, id := range alertIDs {
+		ids[i] = int64(id)
+	}
+
+	query := `
+		WITH sent AS (
+			UPDATE alert_notifications SET sent_at = $3
+			WHERE subscription_id = $1 AND alert_id = ANY($2) AND sent_at IS NULL
+		)
+		UPDATE alert_subscriptions SET last_notified_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, subscriptionID, pq.Array(ids), sentAt)
+	if err != nil {
+		return fmt.Errorf("failed to mark alert notifications sent: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("alert subscription with id %d not found", subscriptionID)
+	}
+
+	return nil
+}
+
+func (r *PostgreSQLAlertSubscriptionRepository) list(ctx context.Context, failure, query string, args ...any) ([]*AlertSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+	defer rows.Close()
+
+	var subscriptions []*AlertSubscription
+	for rows.Next() {
+		subscription, err := scanAlertSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan alert subscription: %w", err)
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// scanAlertSubscription scans a single subscription row, mapping a NULL last notification
+// time to an empty string
+func scanAlertSubscription(row rowScanner) (*AlertSubscription, error) {
+	subscription := &AlertSubscription{}
+	var lastNotifiedAt sql.NullString
+	err := row.Scan(
+		&subscription.ID, &subscription.UserID, &subscription.Email,
+		&subscription.Latitude, &subscription.Longitude, &subscription.RadiusKm,
+		&subscription.MinSeverity, &subscription.Mode, &subscription.Token,
+		&lastNotifiedAt, &subscription.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription.LastNotifiedAt = lastNotifiedAt.String
+	return subscription, nil
+}
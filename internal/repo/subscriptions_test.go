@@ -0,0 +1,127 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAlertSubscriptionRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ AlertSubscriptionRepository = (*PostgreSQLAlertSubscriptionRepository)(nil)
+
+		if NewPostgreSQLAlertSubscriptionRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLAlertSubscriptionRepository returned nil")
+		}
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		repo := NewPostgreSQLAlertSubscriptionRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if err := repo.Queue(context.Background(), 1, nil); err != nil {
+			t.Errorf("Expected queueing no subscriptions to skip the database, got %v", err)
+		}
+		if err := repo.Queue(context.Background(), 1, []int{1, 2}); err == nil {
+			t.Error("Expected error from Queue, got nil")
+		}
+	})
+
+	t.Run("MarkSent", func(t *testing.T) {
+		repo := NewPostgreSQLAlertSubscriptionRepository(&MockDB{})
+		if err := repo.MarkSent(context.Background(), 1, []int{1}, "2025-08-15T12:00:00Z"); err != nil {
+			t.Errorf("Expected successful operation, got error: %v", err)
+		}
+	})
+}
+
+func TestFileAlertSubscriptionRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+	subscriptions := engine.AlertSubscriptions()
+
+	chicago := &AlertSubscription{UserID: 1, Email: "a@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 50, MinSeverity: "Severe", Mode: SubscriptionImmediate, Token: "t1"}
+	digest := &AlertSubscription{UserID: 1, Email: "a@example.com", Latitude: 41.88, Longitude: -87.63, RadiusKm: 50, MinSeverity: "Minor", Mode: SubscriptionDigest, Token: "t2"}
+	denver := &AlertSubscription{UserID: 2, Email: "b@example.com", Latitude: 39.74, Longitude: -104.99, RadiusKm: 50, MinSeverity: "Minor", Mode: SubscriptionImmediate, Token: "t3"}
+	for _, subscription := range []*AlertSubscription{chicago, digest, denver} {
+		if err := subscriptions.Create(ctx, subscription); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+	if err := subscriptions.Create(ctx, &AlertSubscription{UserID: 3, Token: "t1"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate for a reused token, got %v", err)
+	}
+
+	listed, err := subscriptions.ListByUser(ctx, 1)
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("Expected 2 subscriptions for user 1, got %d (%v)", len(listed), err)
+	}
+
+	matching, err := subscriptions.ListMatching(ctx, 41.9, -87.7, []string{"Unknown", "Minor"})
+	if err != nil {
+		t.Fatalf("ListMatching failed: %v", err)
+	}
+	if len(matching) != 1 || matching[0].ID != digest.ID {
+		t.Errorf("Expected only the nearby Minor subscription to match, got %+v", matching)
+	}
+
+	alert := &Alert{SourceProvider: "NWS", ProviderAlertID: "a1", Title: "Tornado Warning", Severity: "Extreme", Latitude: 41.9, Longitude: -87.7}
+	if err := engine.Alerts().Create(ctx, alert); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 {
+		if err := subscriptions.Queue(ctx, alert.ID, []int{chicago.ID, digest.ID}); err != nil {
+			t.Fatalf("Queue failed: %v", err)
+		}
+	}
+	if err := subscriptions.Queue(ctx, alert.ID, []int{99}); !errors.Is(err, ErrConstraint) {
+		t.Errorf("Expected ErrConstraint queueing for a missing subscription, got %v", err)
+	}
+
+	now := time.Now().UTC()
+	due, err := subscriptions.ListDue(ctx, now.Add(-24*time.Hour).Format(time.RFC3339), 10)
+	if err != nil {
+		t.Fatalf("ListDue failed: %v", err)
+	}
+	if len(due) != 1 || due[0].ID != chicago.ID {
+		t.Errorf("Expected only the immediate subscription to be due, got %+v", due)
+	}
+	due, _ = subscriptions.ListDue(ctx, now.Add(time.Hour).Format(time.RFC3339), 10)
+	if len(due) != 2 {
+		t.Errorf("Expected the digest to be due once its period passed, got %+v", due)
+	}
+
+	pending, err := subscriptions.ListPending(ctx, chicago.ID)
+	if err != nil || len(pending) != 1 || pending[0].ID != alert.ID {
+		t.Fatalf("Expected the alert to be pending once despite queueing twice, got %+v (%v)", pending, err)
+	}
+	if err := subscriptions.MarkSent(ctx, chicago.ID, []int{alert.ID}, now.Format(time.RFC3339)); err != nil {
+		t.Fatalf("MarkSent failed: %v", err)
+	}
+	if pending, _ := subscriptions.ListPending(ctx, chicago.ID); len(pending) != 0 {
+		t.Errorf("Expected nothing pending after MarkSent, got %+v", pending)
+	}
+	stored, _ := subscriptions.GetByID(ctx, chicago.ID)
+	if stored.LastNotifiedAt != now.Format(time.RFC3339) {
+		t.Errorf("Expected LastNotifiedAt to be recorded, got %q", stored.LastNotifiedAt)
+	}
+
+	if err := subscriptions.DeleteByToken(ctx, "t2"); err != nil {
+		t.Fatalf("DeleteByToken failed: %v", err)
+	}
+	if err := subscriptions.DeleteByToken(ctx, "t2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a used token, got %v", err)
+	}
+	if due, _ := subscriptions.ListDue(ctx, now.Add(time.Hour).Format(time.RFC3339), 10); len(due) != 0 {
+		t.Errorf("Expected no due subscriptions after unsubscribing, got %+v", due)
+	}
+	if err := subscriptions.Delete(ctx, denver.ID); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if _, err := subscriptions.GetByID(ctx, denver.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after Delete, got %v", err)
+	}
+}
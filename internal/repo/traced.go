@@ -0,0 +1,108 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"stormlightlabs.org/weather_api/internal/timing"
+	"stormlightlabs.org/weather_api/internal/tracing"
+)
+
+// tracedDB records a client span for every query made through the wrapped DB, its
+// duration in the request's timing breakdown, and its outcome in the query statistics
+type tracedDB struct {
+	db DB
+}
+
+// NewTracedDB wraps db so queries are recorded in the caller's trace. Close is passed
+// through when db implements io.Closer.
+func NewTracedDB(db DB) DB {
+	return &tracedDB{db: db}
+}
+
+func (t *tracedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer timing.Track(ctx, timing.DB)()
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	started := time.Now()
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	recordQuery(ctx, started, err)
+	recordSpanError(span, err)
+	return rows, err
+}
+
+// QueryRowContext records the query round trip; scan errors surface later and are
+// not attributed to the span
+func (t *tracedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer timing.Track(ctx, timing.DB)()
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	started := time.Now()
+	row := t.db.QueryRowContext(ctx, query, args...)
+	var err error
+	if row != nil {
+		err = row.Err()
+	}
+	recordQuery(ctx, started, err)
+	return row
+}
+
+func (t *tracedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer timing.Track(ctx, timing.DB)()
+	ctx, span := startQuerySpan(ctx, query)
+	defer span.End()
+	started := time.Now()
+	result, err := t.db.ExecContext(ctx, query, args...)
+	recordQuery(ctx, started, err)
+	recordSpanError(span, err)
+	return result, err
+}
+
+// Reader traces the queries of the wrapped DB's reader (see ReplicatedDB)
+func (t *tracedDB) Reader() DB {
+	return &tracedDB{db: reader(t.db)}
+}
+
+// Close closes the wrapped DB
+func (t *tracedDB) Close() error {
+	if closer, ok := t.db.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// startQuerySpan starts a span named after the statement's SQL operation. Queries are
+// parameterized, so their text holds no user data and is recorded as is.
+func startQuerySpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation := sqlOperation(query)
+	return tracing.Tracer().Start(ctx, operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+		attribute.String("db.system.name", "postgresql"),
+		attribute.String("db.operation.name", operation),
+		attribute.String("db.query.text", strings.TrimSpace(query)),
+	))
+}
+
+// recordSpanError marks the span as failed with err. Nil errors are ignored.
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// sqlOperation returns the statement's leading keyword, e.g. SELECT
+func sqlOperation(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return "QUERY"
+	}
+	return strings.ToUpper(fields[0])
+}
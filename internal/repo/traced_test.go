@@ -0,0 +1,60 @@
+package repo
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"stormlightlabs.org/weather_api/internal/tracing"
+)
+
+func TestTracedDB(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	db := NewTracedDB(&MockDB{shouldError: true, errorMsg: "connection refused"})
+	forecasts := NewPostgreSQLForecastRepository(db)
+
+	ctx, request := tracing.Tracer().Start(context.Background(), "GET /forecasts")
+	_, _ = forecasts.List(ctx, 10, 0)
+	_ = forecasts.Delete(ctx, 1)
+	request.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected 2 query spans and the request span, got %d", len(spans))
+	}
+	for i, operation := range []string{"SELECT", "DELETE"} {
+		span := spans[i]
+		attrs := map[string]string{}
+		for _, kv := range span.Attributes() {
+			attrs[string(kv.Key)] = kv.Value.Emit()
+		}
+		if span.Name() != operation || attrs["db.operation.name"] != operation || attrs["db.system.name"] != "postgresql" {
+			t.Errorf("Expected a %s span, got %s %v", operation, span.Name(), attrs)
+		}
+		if span.Parent().SpanID() != request.SpanContext().SpanID() || span.Status().Code != codes.Error || span.Status().Description != "connection refused" {
+			t.Errorf("Expected a failed child of the request span, got parent %s status %v", span.Parent().SpanID(), span.Status())
+		}
+	}
+}
+
+func TestSQLOperation(t *testing.T) {
+	tests := map[string]string{
+		"SELECT id FROM cities":                "SELECT",
+		"\n\t\tinsert into forecasts (id) ...": "INSERT",
+		"WITH moved AS (DELETE ...)":           "WITH",
+		"   ":                                  "QUERY",
+	}
+	for query, want := range tests {
+		if got := sqlOperation(query); got != want {
+			t.Errorf("sqlOperation(%q) = %q, want %q", query, got, want)
+		}
+	}
+}
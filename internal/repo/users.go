@@ -0,0 +1,133 @@
+package repo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// defaultUserRole is the role of users created without one
+const defaultUserRole = "user"
+
+// defaultUserUnits is the unit system of users created without one, as in the schema
+const defaultUserUnits = "metric"
+
+const userColumns = `id, COALESCE(github_id, 0), username, email, COALESCE(api_key_hash, ''), role, is_active,
+		   created_at, updated_at, preferred_units`
+
+// PostgreSQLUserRepository implements UserRepository for PostgreSQL
+type PostgreSQLUserRepository struct {
+	db DB
+}
+
+// NewPostgreSQLUserRepository creates a new PostgreSQL user repository
+func NewPostgreSQLUserRepository(db DB) UserRepository {
+	return &PostgreSQLUserRepository{db: db}
+}
+
+// Create inserts a new user
+func (r *PostgreSQLUserRepository) Create(ctx context.Context, user *User) error {
+	query := `
+		INSERT INTO users (github_id, username, email, api_key_hash, role, is_active, created_at, updated_at, preferred_units)
+		VALUES (NULLIF($1, 0), $2, $3, NULLIF($4, ''), $5, $6, $7, $7, $8)
+		RETURNING id`
+
+	if user.Role == "" {
+		user.Role = defaultUserRole
+	}
+	if user.PreferredUnits == "" {
+		user.PreferredUnits = defaultUserUnits
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	err := r.db.QueryRowContext(ctx, query,
+		user.GitHubID, user.Username, user.Email, user.APIKeyHash, user.Role, user.IsActive, now, user.PreferredUnits,
+	).Scan(&user.ID)
+
+	if err != nil {
+		return fmt.Errorf("failed to create user: %w", classify(err))
+	}
+
+	user.CreatedAt = now
+	user.UpdatedAt = now
+	return nil
+}
+
+// GetByID retrieves a user by ID
+func (r *PostgreSQLUserRepository) GetByID(ctx context.Context, id int) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("user with id %d not found", id)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByUsername retrieves a user by username
+func (r *PostgreSQLUserRepository) GetByUsername(ctx context.Context, username string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, username))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("user %q not found", username)
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// GetByAPIKeyHash retrieves the user with a hashed API key
+func (r *PostgreSQLUserRepository) GetByAPIKeyHash(ctx context.Context, hash string) (*User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE api_key_hash = $1`
+
+	user, err := scanUser(r.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, notFound("no user with this API key")
+		}
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetRole changes a user's role
+func (r *PostgreSQLUserRepository) SetRole(ctx context.Context, id int, role string) error {
+	query := `UPDATE users SET role = $2, updated_at = $3 WHERE id = $1`
+
+	result, err := r.db.ExecContext(ctx, query, id, role, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to set user role: %w", classify(err))
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return notFound("user with id %d not found", id)
+	}
+
+	return nil
+}
+
+func scanUser(row rowScanner) (*User, error) {
+	user := &User{}
+	err := row.Scan(
+		&user.ID, &user.GitHubID, &user.Username, &user.Email, &user.APIKeyHash,
+		&user.Role, &user.IsActive, &user.CreatedAt, &user.UpdatedAt, &user.PreferredUnits,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
@@ -0,0 +1,72 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	t.Run("Interface Compliance", func(t *testing.T) {
+		var _ UserRepository = (*PostgreSQLUserRepository)(nil)
+
+		if NewPostgreSQLUserRepository(&MockDB{}) == nil {
+			t.Error("NewPostgreSQLUserRepository returned nil")
+		}
+	})
+
+	t.Run("SetRole", func(t *testing.T) {
+		repo := NewPostgreSQLUserRepository(&MockDB{shouldError: true, errorMsg: "database connection error"})
+		if err := repo.SetRole(context.Background(), 1, "admin"); err == nil {
+			t.Error("Expected error from SetRole, got nil")
+		}
+	})
+}
+
+func TestFileUserRepository(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+	users := engine.Users()
+
+	alice := &User{Username: "alice", Email: "alice@example.com", APIKeyHash: "hash-a", IsActive: true}
+	if err := users.Create(ctx, alice); err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if alice.ID == 0 || alice.Role != "user" {
+		t.Errorf("Expected an ID and the default role, got %+v", alice)
+	}
+	if err := users.Create(ctx, &User{Username: "alice"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate for a reused username, got %v", err)
+	}
+	if err := users.Create(ctx, &User{Username: "bob", APIKeyHash: "hash-a"}); !errors.Is(err, ErrDuplicate) {
+		t.Errorf("Expected ErrDuplicate for a reused API key, got %v", err)
+	}
+
+	if got, err := users.GetByAPIKeyHash(ctx, "hash-a"); err != nil || got.ID != alice.ID {
+		t.Errorf("Expected alice by API key, got %+v (%v)", got, err)
+	}
+	if _, err := users.GetByAPIKeyHash(ctx, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for an empty hash, got %v", err)
+	}
+
+	if err := users.SetRole(ctx, alice.ID, "admin"); err != nil {
+		t.Fatalf("SetRole failed: %v", err)
+	}
+	if got, _ := users.GetByUsername(ctx, "alice"); got.Role != "admin" {
+		t.Errorf("Expected the admin role after SetRole, got %q", got.Role)
+	}
+	if err := users.SetRole(ctx, 99, "admin"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing user, got %v", err)
+	}
+
+	if err := engine.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetByID(ctx, alice.ID); err != nil {
+		t.Errorf("Expected users to survive Reset, got %v", err)
+	}
+}
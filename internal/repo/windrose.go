@@ -0,0 +1,35 @@
+package repo
+
+import "math"
+
+// WindRoseSectors is the number of compass sectors in a wind rose, each 22.5° wide
+// and centered on a 16-point compass direction
+const WindRoseSectors = 16
+
+// CalmWindSpeed is the speed in m/s below which wind is counted as calm rather than
+// binned by direction, matching Beaufort force 0
+const CalmWindSpeed = 0.5
+
+// WindRose aggregates a city's forecast winds by direction
+type WindRose struct {
+	// Total counts every forecast hour in the window, including calm ones
+	Total int
+	// Calm counts hours with wind below CalmWindSpeed, which have no direction
+	Calm int
+	// Sectors starts at north and proceeds clockwise
+	Sectors [WindRoseSectors]WindRoseSector
+}
+
+// WindRoseSector is one direction bin of a wind rose
+type WindRoseSector struct {
+	Count     int
+	MeanSpeed float64 // m/s
+}
+
+// windSector returns the sector a direction in degrees falls in, with north
+// spanning 348.75° to 11.25°
+func windSector(direction float64) int {
+	width := 360.0 / WindRoseSectors
+	shifted := math.Mod(math.Mod(direction+width/2, 360)+360, 360)
+	return int(shifted / width)
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWindSector(t *testing.T) {
+	tests := []struct {
+		direction float64
+		want      int
+	}{
+		{0, 0},
+		{11.2, 0},
+		{11.25, 1},
+		{348.75, 0},
+		{348.7, 15},
+		{90, 4},
+		{180, 8},
+		{270, 12},
+		{360, 0},
+		{-22.5, 15},
+	}
+	for _, tt := range tests {
+		if got := windSector(tt.direction); got != tt.want {
+			t.Errorf("windSector(%v) = %d, want %d", tt.direction, got, tt.want)
+		}
+	}
+}
+
+func TestFileForecastRepository_WindRose(t *testing.T) {
+	ctx := context.Background()
+	engine, err := OpenFileEngine("")
+	if err != nil {
+		t.Fatalf("OpenFileEngine failed: %v", err)
+	}
+	defer engine.Close()
+
+	forecasts := []*Forecast{
+		// Superseded by the later issue for the same provider and hour
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T00:00:00Z", ValidTime: "2025-08-01T12:00:00Z", WindSpeed: 9, WindDirection: 180},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T12:00:00Z", WindSpeed: 4, WindDirection: 5},
+		{CityID: 1, SourceProvider: "Met.no", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T12:00:00Z", WindSpeed: 6, WindDirection: 355},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T13:00:00Z", WindSpeed: 3, WindDirection: 92},
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T14:00:00Z", WindSpeed: 0.2, WindDirection: 270},
+		// Outside the window or for another city
+		{CityID: 1, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-02T00:00:00Z", WindSpeed: 5, WindDirection: 90},
+		{CityID: 2, SourceProvider: "NOAA", ForecastTime: "2025-08-01T06:00:00Z", ValidTime: "2025-08-01T12:00:00Z", WindSpeed: 5, WindDirection: 90},
+	}
+	for _, f := range forecasts {
+		if err := engine.Forecasts().Create(ctx, f); err != nil {
+			t.Fatalf("Create failed: %v", err)
+		}
+	}
+
+	rose, err := engine.Forecasts().WindRose(ctx, 1, "2025-08-01T00:00:00Z", "2025-08-02T00:00:00Z")
+	if err != nil {
+		t.Fatalf("WindRose failed: %v", err)
+	}
+	if rose.Total != 4 || rose.Calm != 1 {
+		t.Errorf("Expected 4 hours with 1 calm, got %d with %d calm", rose.Total, rose.Calm)
+	}
+	if north := rose.Sectors[0]; north.Count != 2 || north.MeanSpeed != 5 {
+		t.Errorf("Expected 2 northerly hours averaging 5 m/s, got %+v", north)
+	}
+	if east := rose.Sectors[4]; east.Count != 1 || east.MeanSpeed != 3 {
+		t.Errorf("Expected 1 easterly hour at 3 m/s, got %+v", east)
+	}
+	if south := rose.Sectors[8]; south.Count != 0 {
+		t.Errorf("Expected the superseded southerly issue to be ignored, got %+v", south)
+	}
+
+	if _, err := engine.Forecasts().WindRose(ctx, 1, "yesterday", "2025-08-02T00:00:00Z"); err == nil {
+		t.Error("Expected an error for an invalid start time")
+	}
+}
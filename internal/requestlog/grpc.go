@@ -0,0 +1,84 @@
+package requestlog
+
+import (
+	"context"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataKey carries request IDs in gRPC metadata, which uses lowercase keys
+const metadataKey = "x-request-id"
+
+// UnaryServerInterceptor assigns request IDs to gRPC calls and logs their method,
+// status code and latency through logger, as Middleware does for HTTP requests
+func UnaryServerInterceptor(logger *log.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, scoped, id := startCall(ctx, logger)
+		_ = grpc.SetHeader(ctx, metadata.Pairs(metadataKey, id))
+		resp, err := handler(ctx, req)
+		logCall(scoped, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls
+func StreamServerInterceptor(logger *log.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, scoped, id := startCall(ss.Context(), logger)
+		_ = ss.SetHeader(metadata.Pairs(metadataKey, id))
+		err := handler(srv, &scopedStream{ServerStream: ss, ctx: ctx})
+		logCall(scoped, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// startCall returns ctx carrying the call's request ID and a logger tagged with it,
+// reusing the caller's x-request-id when it is well formed
+func startCall(ctx context.Context, logger *log.Logger) (context.Context, *log.Logger, string) {
+	var id string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(metadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+	if !validID(id) {
+		id = newID()
+	}
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("http.request.id", id))
+
+	scoped := logger.With("request_id", id)
+	return log.WithContext(WithID(ctx, id), scoped), scoped, id
+}
+
+// logCall logs a finished call. Codes reporting server faults are logged at error
+// level, everything else at info.
+func logCall(logger *log.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	level := log.InfoLevel
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss:
+		level = log.ErrorLevel
+	}
+	logger.Log(level, "call",
+		"method", method,
+		"code", code.String(),
+		"duration", time.Since(start),
+	)
+}
+
+// scopedStream replaces a server stream's context with the call's scoped one
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *scopedStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,44 @@
+package requestlog
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	logger, buf := newTestLogger()
+	interceptor := UnaryServerInterceptor(logger)
+	info := &grpc.UnaryServerInfo{FullMethod: "/weather.v1.WeatherService/GetCity"}
+
+	var seen string
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(metadataKey, "edge-1234"))
+	_, err := interceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		seen = ID(ctx)
+		return nil, status.Error(codes.Internal, "boom")
+	})
+	if status.Code(err) != codes.Internal || seen != "edge-1234" {
+		t.Errorf("Expected the incoming request ID and the handler's error, got %q, %v", seen, err)
+	}
+
+	line := strings.TrimSpace(buf.String())
+	for _, want := range []string{"level=error", "request_id=edge-1234", "method=/weather.v1.WeatherService/GetCity", "code=Internal"} {
+		if !strings.Contains(line, want) {
+			t.Errorf("Expected %q in the log line %q", want, line)
+		}
+	}
+
+	buf.Reset()
+	_, _ = interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		seen = ID(ctx)
+		return nil, nil
+	})
+	if len(seen) != 32 || !strings.Contains(buf.String(), "level=info") {
+		t.Errorf("Expected a generated ID logged at info, got %q and %q", seen, buf.String())
+	}
+}
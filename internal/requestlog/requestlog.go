@@ -0,0 +1,167 @@
+// Package requestlog logs every HTTP request and correlates log entries by request ID.
+//
+// Middleware assigns each request an ID, reusing the caller's X-Request-ID when it is
+// well formed, echoes it in the response and stores it in the request context along
+// with a logger carrying it as request_id. Controllers and providers log through
+// Logger(ctx) so their entries can be matched with the access log line.
+package requestlog
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Header carries request IDs in requests and responses
+const Header = "X-Request-ID"
+
+// maxIDLength bounds incoming request IDs so clients cannot bloat every log line
+const maxIDLength = 128
+
+type contextKey struct{}
+
+// WithID returns ctx carrying a request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// ID returns the request ID in ctx, or an empty string
+func ID(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Logger returns the request-scoped logger in ctx, falling back to the default logger
+// outside of requests
+func Logger(ctx context.Context) *log.Logger {
+	return log.FromContext(ctx)
+}
+
+// Middleware assigns request IDs and logs the method, path, status, latency and
+// response size of every request through logger. Server errors are logged at error
+// level, everything else at info.
+func Middleware(logger *log.Logger, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(Header)
+		if !validID(id) {
+			id = newID()
+		}
+		w.Header().Set(Header, id)
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String("http.request.id", id))
+
+		scoped := logger.With("request_id", id)
+		ctx := log.WithContext(WithID(r.Context(), id), scoped)
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		level := log.InfoLevel
+		if rec.status >= http.StatusInternalServerError {
+			level = log.ErrorLevel
+		}
+		scoped.Log(level, "request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"duration", time.Since(start),
+			"bytes", rec.bytes,
+		)
+	})
+}
+
+// validID reports whether an incoming request ID can be reused: non-empty, bounded and
+// limited to characters that cannot forge log fields
+func validID(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	return strings.IndexFunc(id, func(c rune) bool {
+		return !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:/+=", c))
+	}) < 0
+}
+
+// newID returns a random 128-bit request ID
+func newID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id) // never fails on supported platforms
+	return hex.EncodeToString(id)
+}
+
+// responseRecorder captures the status and size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += int64(n)
+	return n, err
+}
+
+// Flush lets streaming handlers flush through the recorder
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (r *responseRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Transport is an http.RoundTripper logging outgoing requests through the logger of
+// the request that caused them, so provider calls appear under the caller's request ID
+type Transport struct {
+	// Provider names the upstream provider in log entries
+	Provider string
+
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a logging transport for the named provider
+func NewTransport(provider string, base http.RoundTripper) *Transport {
+	return &Transport{Provider: provider, Base: base}
+}
+
+// RoundTrip logs the request at debug level, or at warn level when it fails or the
+// provider answers with a server error. Query strings, which may carry API keys, are
+// not logged.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	logger := Logger(req.Context())
+	fields := []any{"provider", t.Provider, "method", req.Method, "host", req.URL.Host, "path", req.URL.Path}
+	switch {
+	case err != nil:
+		logger.Warn("provider request failed", append(fields, "duration", time.Since(start), "error", err)...)
+	case resp.StatusCode >= http.StatusInternalServerError:
+		logger.Warn("provider request failed", append(fields, "duration", time.Since(start), "status", resp.StatusCode)...)
+	default:
+		logger.Debug("provider request", append(fields, "duration", time.Since(start), "status", resp.StatusCode)...)
+	}
+	return resp, err
+}
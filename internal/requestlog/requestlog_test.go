@@ -0,0 +1,106 @@
+package requestlog
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+func newTestLogger() (*log.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return log.NewWithOptions(&buf, log.Options{Formatter: log.LogfmtFormatter}), &buf
+}
+
+func TestMiddleware(t *testing.T) {
+	logger, buf := newTestLogger()
+	var seen string
+	handler := Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = ID(r.Context())
+		Logger(r.Context()).Info("looking up city")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte("missing"))
+	}))
+
+	req := httptest.NewRequest("GET", "/cities/7?units=imperial", nil)
+	req.Header.Set(Header, "edge-1234")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if seen != "edge-1234" || w.Header().Get(Header) != "edge-1234" {
+		t.Errorf("Expected the incoming request ID to be reused, got %q and %q", seen, w.Header().Get(Header))
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected a controller line and an access line, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], "request_id=edge-1234") {
+		t.Errorf("Expected the controller line to carry the request ID, got %q", lines[0])
+	}
+	for _, field := range []string{"level=info", "request_id=edge-1234", "method=GET", "path=/cities/7", "status=404", "bytes=7", "duration="} {
+		if !strings.Contains(lines[1], field) {
+			t.Errorf("Expected %q in access line %q", field, lines[1])
+		}
+	}
+}
+
+func TestMiddleware_GeneratedID(t *testing.T) {
+	logger, buf := newTestLogger()
+	handler := Middleware(logger, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+
+	for _, incoming := range []string{"", "bad id\nlevel=fatal", strings.Repeat("x", maxIDLength+1)} {
+		buf.Reset()
+		req := httptest.NewRequest("POST", "/forecasts", nil)
+		req.Header.Set(Header, incoming)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		id := w.Header().Get(Header)
+		if len(id) != 32 || id == incoming {
+			t.Errorf("Expected a generated ID for %q, got %q", incoming, id)
+		}
+		if !strings.Contains(buf.String(), "level=error") || strings.Contains(buf.String(), "level=fatal") {
+			t.Errorf("Expected one error-level access line, got %q", buf.String())
+		}
+	}
+}
+
+func TestLogger_OutsideRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	if Logger(req.Context()) == nil || ID(req.Context()) != "" {
+		t.Error("Expected the default logger and no ID outside the middleware")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer upstream.Close()
+
+	logger, buf := newTestLogger()
+	ctx := log.WithContext(WithID(t.Context(), "req-1"), logger.With("request_id", "req-1"))
+	req, _ := http.NewRequestWithContext(ctx, "GET", upstream.URL+"/points?key=secret", nil)
+	client := &http.Client{Transport: NewTransport("NWS", nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	line := buf.String()
+	for _, field := range []string{"level=warn", "request_id=req-1", "provider=NWS", "path=/points", "status=502"} {
+		if !strings.Contains(line, field) {
+			t.Errorf("Expected %q in %q", field, line)
+		}
+	}
+	if strings.Contains(line, "secret") {
+		t.Errorf("Expected the query string to be left out, got %q", line)
+	}
+}
@@ -0,0 +1,112 @@
+package retry
+
+import (
+	"expvar"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// registry holds the retry policy in effect, the providers' budget overrides and their
+// retry counts
+var registry = struct {
+	sync.Mutex
+	policy   Policy
+	budgets  map[string]time.Duration
+	counters map[string]*counters
+}{policy: DefaultPolicy(), budgets: map[string]time.Duration{}, counters: map[string]*counters{}}
+
+func init() {
+	expvar.Publish("provider_retries", expvar.Func(func() any { return All() }))
+}
+
+// lookup returns the policy of a provider, with its budget override applied, and its
+// counters
+func lookup(provider string) (Policy, *counters) {
+	registry.Lock()
+	defer registry.Unlock()
+	policy := registry.policy
+	if budget, ok := registry.budgets[provider]; ok {
+		policy.Budget = budget
+	}
+	c, ok := registry.counters[provider]
+	if !ok {
+		c = &counters{}
+		registry.counters[provider] = c
+	}
+	return policy, c
+}
+
+// PolicyFor returns the policy requests to a provider are retried with
+func PolicyFor(provider string) Policy {
+	policy, _ := lookup(provider)
+	return policy
+}
+
+// Configure validates policy and applies it to every provider, with budgets replacing
+// the policy's budget for the providers they name
+func Configure(policy Policy, budgets map[string]time.Duration) error {
+	if err := policy.Validate(); err != nil {
+		return err
+	}
+	for provider, budget := range budgets {
+		if budget < 0 {
+			return fmt.Errorf("retry budget of %s must not be negative, got %s", provider, budget)
+		}
+	}
+	registry.Lock()
+	defer registry.Unlock()
+	registry.policy = policy
+	registry.budgets = maps.Clone(budgets)
+	if registry.budgets == nil {
+		registry.budgets = map[string]time.Duration{}
+	}
+	return nil
+}
+
+// ParseBudgets parses per-provider retry budgets of the form "provider=duration", e.g.
+// "NWS=20s" or "Census=0s" to stop retrying geocodes
+func ParseBudgets(specs []string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, spec := range specs {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		provider, value, ok := strings.Cut(spec, "=")
+		provider = strings.TrimSpace(provider)
+		if !ok || provider == "" {
+			return nil, fmt.Errorf("invalid retry budget %q: expected provider=duration", spec)
+		}
+		budget, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry budget %q: %w", spec, err)
+		}
+		if budget < 0 {
+			return nil, fmt.Errorf("invalid retry budget %q: must not be negative", spec)
+		}
+		budgets[provider] = budget
+	}
+	return budgets, nil
+}
+
+// All returns the retry counts of every provider that made requests, ordered by
+// provider
+func All() []Stats {
+	registry.Lock()
+	defer registry.Unlock()
+	stats := make([]Stats, 0, len(registry.counters))
+	for _, provider := range slices.Sorted(maps.Keys(registry.counters)) {
+		c := registry.counters[provider]
+		stats = append(stats, Stats{
+			Provider:  provider,
+			Requests:  c.requests.Load(),
+			Retries:   c.retries.Load(),
+			Exhausted: c.exhausted.Load(),
+		})
+	}
+	return stats
+}
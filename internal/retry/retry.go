@@ -0,0 +1,202 @@
+// Package retry repeats provider requests that failed transiently.
+//
+// Every provider's HTTP client passes its requests through a Transport (see
+// NewTransport) that retries network errors, 429 and 5xx responses of idempotent
+// requests with exponential backoff and full jitter. A Retry-After hint from the
+// provider replaces the computed backoff. Retries stop after Policy.Attempts attempts,
+// once the waits of a request would exceed the provider's budget, or when the
+// request's context would expire first; the last response or error is returned as is.
+//
+// Retry counts are published as the "provider_retries" expvar.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+)
+
+// Policy sets how often and how long provider requests are retried
+type Policy struct {
+	// Attempts is the most requests made for one call, the first included; 1 disables
+	// retries
+	Attempts int
+
+	// BaseDelay is the backoff ceiling before the first retry; it doubles with every
+	// retry up to MaxDelay, and the wait is drawn uniformly below it
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+
+	// Budget is the most time one call may spend waiting between attempts
+	Budget time.Duration
+}
+
+// DefaultPolicy makes up to 3 attempts, backing off from 250ms up to 5s, and waits at
+// most 10 seconds in total
+func DefaultPolicy() Policy {
+	return Policy{Attempts: 3, BaseDelay: 250 * time.Millisecond, MaxDelay: 5 * time.Second, Budget: 10 * time.Second}
+}
+
+// Validate reports settings that would never make a request or never wait
+func (p Policy) Validate() error {
+	switch {
+	case p.Attempts <= 0:
+		return fmt.Errorf("retry attempts must be positive, got %d", p.Attempts)
+	case p.BaseDelay <= 0:
+		return fmt.Errorf("retry base delay must be positive, got %s", p.BaseDelay)
+	case p.MaxDelay < p.BaseDelay:
+		return fmt.Errorf("retry max delay must be at least the base delay, got %s", p.MaxDelay)
+	case p.Budget < 0:
+		return fmt.Errorf("retry budget must not be negative, got %s", p.Budget)
+	}
+	return nil
+}
+
+// backoff returns the wait before the given retry (from 1): the provider's retryAfter
+// hint when it gave one, otherwise a random duration below the exponential ceiling
+func (p Policy) backoff(retry int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	ceiling := p.MaxDelay
+	if shift := retry - 1; shift < 32 && p.BaseDelay<<shift < p.MaxDelay {
+		ceiling = p.BaseDelay << shift
+	}
+	return rand.N(ceiling) + 1
+}
+
+// Stats counts the retries of one provider
+type Stats struct {
+	Provider  string `json:"provider"`
+	Requests  int64  `json:"requests"`  // calls made through the transport
+	Retries   int64  `json:"retries"`   // repeated attempts
+	Exhausted int64  `json:"exhausted"` // calls still failing when retries ran out
+}
+
+// counters accumulate a provider's Stats
+type counters struct {
+	requests, retries, exhausted atomic.Int64
+}
+
+// Transport retries the requests of a provider according to its policy
+type Transport struct {
+	Provider string
+
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport retrying with the policy of the named provider
+func NewTransport(provider string, base http.RoundTripper) *Transport {
+	return &Transport{Provider: provider, Base: base}
+}
+
+// RoundTrip makes the request, repeating it while it fails transiently and the policy
+// allows
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	policy, stats := lookup(t.Provider)
+	stats.requests.Add(1)
+
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		resp, err := base.RoundTrip(req)
+		if !retryable(req, resp, err) {
+			return resp, err
+		}
+		wait := policy.backoff(attempt, retryAfter(resp))
+		if attempt >= policy.Attempts || waited+wait > policy.Budget || expiresWithin(req.Context(), wait) {
+			stats.exhausted.Add(1)
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		waited += wait
+		stats.retries.Add(1)
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// retryable reports whether a request may be repeated: it must be idempotent and able
+// to resend its body, and have failed with a network error, 429 or 5xx. Requests the
+// caller gave up on, those that would outlive their deadline and those short-circuited
+// by an open breaker are not retried.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	if err != nil {
+		return req.Context().Err() == nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, breaker.ErrOpen)
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// expiresWithin reports whether ctx's deadline falls within wait, leaving no time for
+// another attempt
+func expiresWithin(ctx context.Context, wait time.Duration) bool {
+	deadline, ok := ctx.Deadline()
+	return ok && time.Until(deadline) <= wait
+}
+
+// retryAfter returns a response's Retry-After hint, or 0 when it gave none
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	return ParseRetryAfter(resp.Header.Get("Retry-After"))
+}
+
+// ParseRetryAfter parses a Retry-After header given in seconds or as an HTTP date
+func ParseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := time.Until(at); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
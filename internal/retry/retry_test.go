@@ -0,0 +1,231 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/breaker"
+)
+
+// fastPolicy retries quickly enough for tests
+var fastPolicy = Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 4 * time.Millisecond, Budget: time.Second}
+
+// statusServer answers with the given statuses in turn, then 200, counting requests
+func statusServer(t *testing.T, header http.Header, statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := int(calls.Add(1))
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		if call <= len(statuses) {
+			w.WriteHeader(statuses[call-1])
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func TestPolicyValidate(t *testing.T) {
+	if err := DefaultPolicy().Validate(); err != nil {
+		t.Errorf("Expected the default policy to be valid, got %v", err)
+	}
+	for name, policy := range map[string]Policy{
+		"attempts":   {Attempts: 0, BaseDelay: time.Second, MaxDelay: time.Second},
+		"base delay": {Attempts: 3, MaxDelay: time.Second},
+		"max delay":  {Attempts: 3, BaseDelay: time.Second, MaxDelay: time.Millisecond},
+		"budget":     {Attempts: 3, BaseDelay: time.Second, MaxDelay: time.Second, Budget: -time.Second},
+	} {
+		if err := policy.Validate(); err == nil {
+			t.Errorf("Expected an invalid %s to be rejected", name)
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	policy := Policy{Attempts: 10, BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Budget: time.Minute}
+	for retry, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 400 * time.Millisecond, 8: time.Second, 80: time.Second} {
+		for range 50 {
+			if wait := policy.backoff(retry, 0); wait <= 0 || wait > ceiling {
+				t.Fatalf("Expected retry %d to wait up to %s, got %s", retry, ceiling, wait)
+			}
+		}
+	}
+	if wait := policy.backoff(1, 30*time.Second); wait != 30*time.Second {
+		t.Errorf("Expected the Retry-After hint to replace the backoff, got %s", wait)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := ParseRetryAfter("30"); got != 30*time.Second {
+		t.Errorf("expected 30s, got %s", got)
+	}
+	date := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := ParseRetryAfter(date); got <= 0 || got > time.Minute {
+		t.Errorf("expected about a minute for %q, got %s", date, got)
+	}
+	if got := ParseRetryAfter("soon"); got != 0 {
+		t.Errorf("expected 0 for an invalid hint, got %s", got)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	if err := Configure(fastPolicy, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure(DefaultPolicy(), nil)
+
+	get := func(t *testing.T, provider, url string) int {
+		client := &http.Client{Transport: NewTransport(provider, nil)}
+		resp, err := client.Get(url)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("retries transient failures", func(t *testing.T) {
+		server, calls := statusServer(t, nil, http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		if status := get(t, "Transient", server.URL); status != http.StatusOK || calls.Load() != 3 {
+			t.Errorf("Expected success on the third attempt, got %d after %d", status, calls.Load())
+		}
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		before := statsOf("Exhausted")
+		server, calls := statusServer(t, nil, 502, 502, 502, 502)
+		if status := get(t, "Exhausted", server.URL); status != http.StatusBadGateway || calls.Load() != 3 {
+			t.Errorf("Expected the third 502 returned, got %d after %d", status, calls.Load())
+		}
+		after := statsOf("Exhausted")
+		if after.Requests-before.Requests != 1 || after.Retries-before.Retries != 2 || after.Exhausted-before.Exhausted != 1 {
+			t.Errorf("Expected 1 request, 2 retries and 1 exhausted, went from %+v to %+v", before, after)
+		}
+	})
+
+	t.Run("leaves other statuses alone", func(t *testing.T) {
+		server, calls := statusServer(t, nil, http.StatusNotFound, http.StatusNotImplemented)
+		if status := get(t, "Permanent", server.URL); status != http.StatusNotFound || calls.Load() != 1 {
+			t.Errorf("Expected a single attempt for a 404, got %d after %d", status, calls.Load())
+		}
+	})
+
+	t.Run("stops at the budget", func(t *testing.T) {
+		server, calls := statusServer(t, http.Header{"Retry-After": {"60"}}, http.StatusTooManyRequests)
+		if status := get(t, "Throttled", server.URL); status != http.StatusTooManyRequests || calls.Load() != 1 {
+			t.Errorf("Expected a Retry-After beyond the budget not to be waited out, got %d after %d", status, calls.Load())
+		}
+
+		if err := Configure(fastPolicy, map[string]time.Duration{"Unbudgeted": 0}); err != nil {
+			t.Fatal(err)
+		}
+		server, calls = statusServer(t, nil, http.StatusServiceUnavailable)
+		if status := get(t, "Unbudgeted", server.URL); status != http.StatusServiceUnavailable || calls.Load() != 1 {
+			t.Errorf("Expected a zero budget to disable retries, got %d after %d", status, calls.Load())
+		}
+		if PolicyFor("Unbudgeted").Budget != 0 || PolicyFor("Other").Budget != time.Second {
+			t.Error("Expected the budget override to apply to its provider only")
+		}
+	})
+
+	t.Run("only retries idempotent requests", func(t *testing.T) {
+		server, calls := statusServer(t, nil, http.StatusServiceUnavailable)
+		client := &http.Client{Transport: NewTransport("Post", nil)}
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if calls.Load() != 1 {
+			t.Errorf("Expected a POST not to be retried, got %d attempts", calls.Load())
+		}
+	})
+}
+
+// statsOf returns the retry counts of a provider
+func statsOf(provider string) Stats {
+	for _, stats := range All() {
+		if stats.Provider == provider {
+			return stats
+		}
+	}
+	return Stats{Provider: provider}
+}
+
+// failingTransport fails every request with err, or answers resp when err is nil
+type failingTransport struct {
+	err   error
+	resp  *http.Response
+	calls int
+}
+
+func (f *failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.resp, nil
+}
+
+func TestTransportErrors(t *testing.T) {
+	if err := Configure(fastPolicy, nil); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure(DefaultPolicy(), nil)
+
+	for name, tt := range map[string]struct {
+		err   error
+		calls int
+	}{
+		"network error": {errors.New("connection refused"), 3},
+		"open breaker":  {&breaker.OpenError{Provider: "Errors", RetryAfter: time.Second}, 1},
+		"deadline":      {fmt.Errorf("rate limit would delay the request past its deadline: %w", context.DeadlineExceeded), 1},
+	} {
+		base := &failingTransport{err: tt.err}
+		req, _ := http.NewRequest("GET", "http://provider.invalid", nil)
+		if _, err := NewTransport("Errors", base).RoundTrip(req); !errors.Is(err, tt.err) {
+			t.Errorf("%s: expected the last error back, got %v", name, err)
+		}
+		if base.calls != tt.calls {
+			t.Errorf("%s: expected %d attempts, got %d", name, tt.calls, base.calls)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	base := &failingTransport{resp: &http.Response{
+		StatusCode: http.StatusServiceUnavailable,
+		Header:     http.Header{"Retry-After": {"1"}},
+		Body:       http.NoBody,
+	}}
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://provider.invalid", nil)
+	resp, err := NewTransport("Errors", base).RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || base.calls != 1 {
+		t.Errorf("Expected no retry past the request deadline, got %d attempts and %v", base.calls, err)
+	}
+}
+
+func TestParseBudgets(t *testing.T) {
+	budgets, err := ParseBudgets([]string{"NWS=20s", " Census = 0s "})
+	if err != nil {
+		t.Fatalf("ParseBudgets failed: %v", err)
+	}
+	if budgets["NWS"] != 20*time.Second || budgets["Census"] != 0 || len(budgets) != 2 {
+		t.Errorf("Unexpected budgets %v", budgets)
+	}
+	for _, spec := range []string{"NWS", "=5s", "NWS=soon", "NWS=-1s"} {
+		if _, err := ParseBudgets([]string{spec}); err == nil {
+			t.Errorf("Expected %q to be rejected", spec)
+		}
+	}
+}
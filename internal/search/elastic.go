@@ -0,0 +1,201 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ElasticIndex stores documents in an Elasticsearch (or OpenSearch) index through its
+// REST API. Matching uses ASCII folding and AUTO fuzziness; scores are boosted by
+// log1p(weight).
+type ElasticIndex struct {
+	BaseURL    string
+	Index      string
+	HTTPClient *http.Client
+}
+
+// NewElasticIndex creates an index client for baseURL (e.g. http://localhost:9200)
+func NewElasticIndex(baseURL, index string) *ElasticIndex {
+	return &ElasticIndex{
+		BaseURL:    strings.TrimRight(baseURL, "/"),
+		Index:      index,
+		HTTPClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Name returns BackendElastic
+func (e *ElasticIndex) Name() string { return BackendElastic }
+
+// elasticMapping folds case and diacritics on names, so "Zürich" matches "zurich"
+const elasticMapping = `{
+	"settings": {
+		"analysis": {
+			"analyzer": {
+				"folded": {"tokenizer": "standard", "filter": ["lowercase", "asciifolding"]}
+			}
+		}
+	},
+	"mappings": {
+		"properties": {
+			"kind": {"type": "keyword"},
+			"id": {"type": "integer"},
+			"name": {"type": "text", "analyzer": "folded"},
+			"names": {"type": "text", "analyzer": "folded"},
+			"weight": {"type": "double"}
+		}
+	}
+}`
+
+// EnsureIndex creates the index with its mapping unless it already exists
+func (e *ElasticIndex) EnsureIndex(ctx context.Context) error {
+	status, body, err := e.do(ctx, http.MethodPut, "/"+url.PathEscape(e.Index), []byte(elasticMapping))
+	if err != nil {
+		return err
+	}
+	if status >= 300 && !bytes.Contains(body, []byte("resource_already_exists_exception")) {
+		return fmt.Errorf("failed to create search index %s: %d %s", e.Index, status, truncate(body))
+	}
+	return nil
+}
+
+// elasticDoc is the stored form of a Document
+type elasticDoc struct {
+	Kind   string   `json:"kind"`
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Names  []string `json:"names"`
+	Weight float64  `json:"weight"`
+}
+
+func (e *ElasticIndex) docPath(kind string, id int) string {
+	return "/" + url.PathEscape(e.Index) + "/_doc/" + url.PathEscape(kind+"-"+strconv.Itoa(id))
+}
+
+// Put indexes a document
+func (e *ElasticIndex) Put(ctx context.Context, doc Document) error {
+	stored := elasticDoc{Kind: doc.Kind, ID: doc.ID, Names: doc.Names, Weight: doc.Weight}
+	if len(doc.Names) > 0 {
+		stored.Name = doc.Names[0]
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	status, body, err := e.do(ctx, http.MethodPut, e.docPath(doc.Kind, doc.ID), data)
+	if err != nil {
+		return err
+	}
+	if status >= 300 {
+		return fmt.Errorf("failed to index %s %d: %d %s", doc.Kind, doc.ID, status, truncate(body))
+	}
+	return nil
+}
+
+// Delete removes a document
+func (e *ElasticIndex) Delete(ctx context.Context, kind string, id int) error {
+	status, body, err := e.do(ctx, http.MethodDelete, e.docPath(kind, id), nil)
+	if err != nil {
+		return err
+	}
+	if status >= 300 && status != http.StatusNotFound {
+		return fmt.Errorf("failed to remove %s %d from the index: %d %s", kind, id, status, truncate(body))
+	}
+	return nil
+}
+
+// Search runs a fuzzy multi_match query on the names of kind
+func (e *ElasticIndex) Search(ctx context.Context, kind, query string, limit int) ([]Hit, error) {
+	request := map[string]any{
+		"size":    limit,
+		"_source": []string{"id"},
+		"query": map[string]any{
+			"function_score": map[string]any{
+				"query": map[string]any{
+					"bool": map[string]any{
+						"filter": []any{map[string]any{"term": map[string]any{"kind": kind}}},
+						"must": map[string]any{
+							"multi_match": map[string]any{
+								"query":     query,
+								"fields":    []string{"name^3", "names"},
+								"fuzziness": "AUTO",
+								"operator":  "and",
+							},
+						},
+					},
+				},
+				"field_value_factor": map[string]any{"field": "weight", "modifier": "log1p", "missing": 0},
+				"boost_mode":         "sum",
+			},
+		},
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	status, body, err := e.do(ctx, http.MethodPost, "/"+url.PathEscape(e.Index)+"/_search", data)
+	if err != nil {
+		return nil, err
+	}
+	if status >= 300 {
+		return nil, fmt.Errorf("search failed: %d %s", status, truncate(body))
+	}
+
+	var response struct {
+		Hits struct {
+			Hits []struct {
+				Score  float64 `json:"_score"`
+				Source struct {
+					ID int `json:"id"`
+				} `json:"_source"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to decode search response: %w", err)
+	}
+	hits := make([]Hit, 0, len(response.Hits.Hits))
+	for _, hit := range response.Hits.Hits {
+		hits = append(hits, Hit{Kind: kind, ID: hit.Source.ID, Score: hit.Score})
+	}
+	return hits, nil
+}
+
+// do sends a JSON request and returns the status and body
+func (e *ElasticIndex) do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, e.BaseURL+path, reader)
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := e.HTTPClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("search backend unreachable: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	return resp.StatusCode, data, err
+}
+
+// truncate shortens an error body for messages
+func truncate(body []byte) string {
+	const limit = 200
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
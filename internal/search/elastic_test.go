@@ -0,0 +1,102 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestElasticIndex(t *testing.T) {
+	var requests []string
+	var lastBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		data, _ := io.ReadAll(r.Body)
+		lastBody = nil
+		_ = json.Unmarshal(data, &lastBody)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/weather":
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":{"type":"resource_already_exists_exception"}}`))
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/weather/_search":
+			_, _ = w.Write([]byte(`{"hits":{"hits":[{"_score":4.2,"_source":{"id":3}},{"_score":1.5,"_source":{"id":9}}]}}`))
+		default:
+			w.WriteHeader(http.StatusCreated)
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	index := NewElasticIndex(server.URL+"/", "weather")
+	if err := index.EnsureIndex(ctx); err != nil {
+		t.Errorf("Expected an existing index to be accepted, got %v", err)
+	}
+	if err := index.Put(ctx, Document{Kind: KindCity, ID: 3, Names: []string{"Zürich", "Zurich"}, Weight: 420_000}); err != nil {
+		t.Fatal(err)
+	}
+	if lastBody["name"] != "Zürich" || lastBody["kind"] != KindCity {
+		t.Errorf("Unexpected document %v", lastBody)
+	}
+	if err := index.Delete(ctx, KindCity, 3); err != nil {
+		t.Errorf("Expected deleting a missing document to succeed, got %v", err)
+	}
+
+	hits, err := index.Search(ctx, KindCity, "zurih", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hits) != 2 || hits[0].ID != 3 || hits[0].Score != 4.2 || hits[1].Kind != KindCity {
+		t.Errorf("Unexpected hits %+v", hits)
+	}
+	query, _ := json.Marshal(lastBody)
+	if !strings.Contains(string(query), `"fuzziness":"AUTO"`) || !strings.Contains(string(query), `"kind":"city"`) {
+		t.Errorf("Expected a fuzzy query filtered by kind, got %s", query)
+	}
+
+	want := []string{"PUT /weather", "PUT /weather/_doc/city-3", "DELETE /weather/_doc/city-3", "POST /weather/_search"}
+	if strings.Join(requests, ", ") != strings.Join(want, ", ") {
+		t.Errorf("Expected requests %v, got %v", want, requests)
+	}
+}
+
+func TestElasticIndex_Errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	index := NewElasticIndex(server.URL, "weather")
+	if err := index.EnsureIndex(context.Background()); err == nil {
+		t.Error("Expected EnsureIndex to fail")
+	}
+	if _, err := index.Search(context.Background(), KindCity, "x", 5); err == nil {
+		t.Error("Expected Search to fail")
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("WEATHER_API_SEARCH_BACKEND", "")
+	if config, err := LoadConfig(); err != nil || config.Backend != BackendSQL {
+		t.Errorf("Expected SQL search by default, got %+v, %v", config, err)
+	}
+	t.Setenv("WEATHER_API_SEARCH_BACKEND", "Elasticsearch")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected elasticsearch without a URL to fail")
+	}
+	t.Setenv("WEATHER_API_SEARCH_URL", "http://localhost:9200/")
+	t.Setenv("WEATHER_API_SEARCH_LANGUAGES", "EN, de,")
+	config, err := LoadConfig()
+	if err != nil || config.URL != "http://localhost:9200" || strings.Join(config.Languages, ",") != "en,de" {
+		t.Errorf("Unexpected config %+v, %v", config, err)
+	}
+	t.Setenv("WEATHER_API_SEARCH_BACKEND", "bleve")
+	if _, err := LoadConfig(); err == nil {
+		t.Error("Expected an unknown backend to fail")
+	}
+}
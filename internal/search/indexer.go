@@ -0,0 +1,238 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync/atomic"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// rebuildPageSize is the number of rows read per page while rebuilding
+const rebuildPageSize = 500
+
+// Indexer keeps an Index in sync with the city and place repositories of an engine
+type Indexer struct {
+	index     Index
+	cities    repo.CityRepository
+	places    repo.PlaceRepository
+	languages []string
+	onError   func(error)
+	ready     atomic.Bool
+}
+
+// NewIndexer creates an indexer reading from engine. City documents include the
+// localized names in languages. onError receives failures to update the index, which
+// never fail the write that triggered them.
+func NewIndexer(index Index, engine repo.Engine, languages []string, onError func(error)) *Indexer {
+	if onError == nil {
+		onError = func(error) {}
+	}
+	return &Indexer{
+		index:     index,
+		cities:    engine.Cities(),
+		places:    engine.Places(),
+		languages: languages,
+		onError:   onError,
+	}
+}
+
+// Index returns the underlying index
+func (x *Indexer) Index() Index { return x.index }
+
+// Ready reports whether the initial Rebuild has completed, so searches can use the index
+func (x *Indexer) Ready() bool { return x.ready.Load() }
+
+// Register subscribes the indexer to city and place writes
+func (x *Indexer) Register(hooks *repo.EngineHooks) {
+	hooks.Cities.Register(repo.Hook[repo.City]{
+		Name: "search",
+		After: func(ctx context.Context, m repo.Mutation[repo.City]) {
+			var err error
+			if m.Op == repo.OpDelete {
+				err = x.index.Delete(ctx, KindCity, m.ID)
+			} else {
+				// Reloaded rather than taken from the mutation: localized name changes
+				// carry no entity, and the document needs every name
+				err = x.indexCity(ctx, m.ID)
+			}
+			if err != nil {
+				x.onError(fmt.Errorf("failed to index city %d: %w", m.ID, err))
+			}
+		},
+	})
+	hooks.Places.Register(repo.Hook[repo.Place]{
+		Name: "search",
+		After: func(ctx context.Context, m repo.Mutation[repo.Place]) {
+			var err error
+			switch {
+			case m.Op == repo.OpDelete:
+				err = x.index.Delete(ctx, KindPlace, m.ID)
+			case m.Entity != nil:
+				err = x.index.Put(ctx, placeDocument(m.Entity))
+			}
+			if err != nil {
+				x.onError(fmt.Errorf("failed to index place %d: %w", m.ID, err))
+			}
+		},
+	})
+}
+
+// Rebuild indexes every city and place, then marks the index ready. Writes made
+// meanwhile are indexed by the hooks, so a rebuild can run while serving.
+func (x *Indexer) Rebuild(ctx context.Context) error {
+	for offset := 0; ; offset += rebuildPageSize {
+		cities, err := x.cities.List(ctx, rebuildPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list cities: %w", err)
+		}
+		ids := make([]int, len(cities))
+		for i, city := range cities {
+			ids[i] = city.ID
+		}
+		names, err := x.cities.GetLocalizedNames(ctx, ids, x.languages)
+		if err != nil {
+			return fmt.Errorf("failed to load localized names: %w", err)
+		}
+		for _, city := range cities {
+			if err := x.index.Put(ctx, x.cityDocument(city, names[city.ID])); err != nil {
+				return err
+			}
+		}
+		if len(cities) < rebuildPageSize {
+			break
+		}
+	}
+
+	for offset := 0; ; offset += rebuildPageSize {
+		places, err := x.places.List(ctx, rebuildPageSize, offset)
+		if err != nil {
+			return fmt.Errorf("failed to list places: %w", err)
+		}
+		for _, place := range places {
+			if err := x.index.Put(ctx, placeDocument(place)); err != nil {
+				return err
+			}
+		}
+		if len(places) < rebuildPageSize {
+			break
+		}
+	}
+
+	x.ready.Store(true)
+	return nil
+}
+
+// indexCity loads a city with its localized names and indexes it
+func (x *Indexer) indexCity(ctx context.Context, id int) error {
+	city, err := x.cities.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	names, err := x.cities.GetLocalizedNames(ctx, []int{id}, x.languages)
+	if err != nil {
+		return err
+	}
+	return x.index.Put(ctx, x.cityDocument(city, names[id]))
+}
+
+// cityDocument indexes a city under its name followed by its localized names in the
+// indexer's language order
+func (x *Indexer) cityDocument(city *repo.City, localized map[string]string) Document {
+	doc := Document{Kind: KindCity, ID: city.ID, Names: []string{city.Name}, Weight: float64(city.Population)}
+	for _, lang := range x.languages {
+		if name := localized[lang]; name != "" && !slices.Contains(doc.Names, name) {
+			doc.Names = append(doc.Names, name)
+		}
+	}
+	return doc
+}
+
+// placeDocument indexes a place under its display name, first address line and city
+func placeDocument(place *repo.Place) Document {
+	doc := Document{Kind: KindPlace, ID: place.ID, Weight: place.Confidence}
+	for _, name := range []string{place.DisplayName, place.AddressLine1, place.City} {
+		if name != "" && !slices.Contains(doc.Names, name) {
+			doc.Names = append(doc.Names, name)
+		}
+	}
+	return doc
+}
+
+// indexedEngine serves city and place searches from an index
+type indexedEngine struct {
+	repo.Engine
+	indexer *Indexer
+}
+
+// NewEngine wraps engine so city and place searches use the indexer's index once it
+// is ready, falling back to the repositories' SQL search while it is rebuilding or
+// when it fails
+func NewEngine(engine repo.Engine, indexer *Indexer) repo.Engine {
+	return &indexedEngine{Engine: engine, indexer: indexer}
+}
+
+// Cities returns the city repository with indexed search
+func (e *indexedEngine) Cities() repo.CityRepository {
+	return &indexedCityRepository{CityRepository: e.Engine.Cities(), indexer: e.indexer}
+}
+
+// Places returns the place repository with indexed search
+func (e *indexedEngine) Places() repo.PlaceRepository {
+	return &indexedPlaceRepository{PlaceRepository: e.Engine.Places(), indexer: e.indexer}
+}
+
+// search returns the index hits for query, or false when SQL search must be used
+func (x *Indexer) search(ctx context.Context, kind, query string, limit int) ([]Hit, bool) {
+	if !x.Ready() {
+		return nil, false
+	}
+	hits, err := x.index.Search(ctx, kind, query, limit)
+	if err != nil {
+		x.onError(fmt.Errorf("%s search failed, falling back to SQL: %w", x.index.Name(), err))
+		return nil, false
+	}
+	return hits, true
+}
+
+type indexedCityRepository struct {
+	repo.CityRepository
+	indexer *Indexer
+}
+
+// Search returns the cities ranked by the index. Hits for rows deleted since they were
+// indexed are skipped.
+func (r *indexedCityRepository) Search(ctx context.Context, query string, limit int) ([]*repo.City, error) {
+	hits, ok := r.indexer.search(ctx, KindCity, query, limit)
+	if !ok {
+		return r.CityRepository.Search(ctx, query, limit)
+	}
+	cities := make([]*repo.City, 0, len(hits))
+	for _, hit := range hits {
+		if city, err := r.GetByID(ctx, hit.ID); err == nil {
+			cities = append(cities, city)
+		}
+	}
+	return cities, nil
+}
+
+type indexedPlaceRepository struct {
+	repo.PlaceRepository
+	indexer *Indexer
+}
+
+// Search returns the places ranked by the index
+func (r *indexedPlaceRepository) Search(ctx context.Context, query string, limit int) ([]*repo.Place, error) {
+	hits, ok := r.indexer.search(ctx, KindPlace, query, limit)
+	if !ok {
+		return r.PlaceRepository.Search(ctx, query, limit)
+	}
+	places := make([]*repo.Place, 0, len(hits))
+	for _, hit := range hits {
+		if place, err := r.GetByID(ctx, hit.ID); err == nil {
+			places = append(places, place)
+		}
+	}
+	return places, nil
+}
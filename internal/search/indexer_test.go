@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// failingIndex returns an error from every operation
+type failingIndex struct{}
+
+func (failingIndex) Name() string                                      { return "failing" }
+func (failingIndex) Put(ctx context.Context, doc Document) error       { return errors.New("down") }
+func (failingIndex) Delete(ctx context.Context, k string, i int) error { return errors.New("down") }
+func (failingIndex) Search(ctx context.Context, kind, query string, limit int) ([]Hit, error) {
+	return nil, errors.New("down")
+}
+
+func TestIndexer(t *testing.T) {
+	ctx := context.Background()
+	file, _ := repo.OpenFileEngine("")
+	_ = file.Cities().Create(ctx, &repo.City{Name: "Amsterdam", Population: 870_000})
+	_ = file.Places().Create(ctx, &repo.Place{DisplayName: "Rijksmuseum", City: "Amsterdam", Confidence: 0.9})
+
+	hooks := &repo.EngineHooks{}
+	indexer := NewIndexer(NewMemoryIndex(), repo.NewHookedEngine(file, hooks), []string{"en", "de"}, nil)
+	indexer.Register(hooks)
+	engine := NewEngine(repo.NewHookedEngine(file, hooks), indexer)
+
+	// Before the rebuild, searches use the repositories' LIKE search
+	if cities, _ := engine.Cities().Search(ctx, "amstredam", 10); len(cities) != 0 {
+		t.Errorf("Expected SQL search before the rebuild, got %+v", cities)
+	}
+	if err := indexer.Rebuild(ctx); err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if !indexer.Ready() {
+		t.Fatal("Expected the indexer to be ready after a rebuild")
+	}
+	if cities, _ := engine.Cities().Search(ctx, "amstredam", 10); len(cities) != 1 || cities[0].Name != "Amsterdam" {
+		t.Errorf("Expected a fuzzy match from the index, got %+v", cities)
+	}
+	if places, _ := engine.Places().Search(ctx, "rijksmuzeum", 10); len(places) != 1 {
+		t.Errorf("Expected a fuzzy place match, got %+v", places)
+	}
+
+	// Writes through the hooked engine update the index
+	munich := &repo.City{Name: "München", Population: 1_500_000}
+	if err := engine.Cities().Create(ctx, munich); err != nil {
+		t.Fatal(err)
+	}
+	if cities, _ := engine.Cities().Search(ctx, "munchen", 10); len(cities) != 1 {
+		t.Errorf("Expected a created city to be indexed, got %+v", cities)
+	}
+	_ = engine.Cities().SetLocalizedName(ctx, &repo.CityName{CityID: munich.ID, Language: "en", Name: "Munich"})
+	if cities, _ := engine.Cities().Search(ctx, "munich", 10); len(cities) != 1 {
+		t.Errorf("Expected a localized name to be indexed, got %+v", cities)
+	}
+	_ = engine.Cities().SetLocalizedName(ctx, &repo.CityName{CityID: munich.ID, Language: "ja", Name: "ミュンヘン"})
+	if cities, _ := engine.Cities().Search(ctx, "ミュンヘン", 10); len(cities) != 0 {
+		t.Errorf("Expected names outside the configured languages to be skipped, got %+v", cities)
+	}
+	_ = engine.Cities().Delete(ctx, munich.ID)
+	if cities, _ := engine.Cities().Search(ctx, "munchen", 10); len(cities) != 0 {
+		t.Errorf("Expected a deleted city to leave the index, got %+v", cities)
+	}
+}
+
+func TestIndexer_Fallback(t *testing.T) {
+	ctx := context.Background()
+	file, _ := repo.OpenFileEngine("")
+	_ = file.Cities().Create(ctx, &repo.City{Name: "Amsterdam"})
+
+	var errs []error
+	hooks := &repo.EngineHooks{}
+	indexer := NewIndexer(failingIndex{}, file, nil, func(err error) { errs = append(errs, err) })
+	indexer.Register(hooks)
+	indexer.ready.Store(true)
+	engine := NewEngine(repo.NewHookedEngine(file, hooks), indexer)
+
+	if err := engine.Cities().Create(ctx, &repo.City{Name: "Rotterdam"}); err != nil {
+		t.Errorf("Expected index failures not to fail writes, got %v", err)
+	}
+	cities, err := engine.Cities().Search(ctx, "amster", 10)
+	if err != nil || len(cities) != 1 {
+		t.Errorf("Expected SQL search when the index fails, got %+v, %v", cities, err)
+	}
+	if len(errs) != 2 {
+		t.Errorf("Expected the write and search failures to be reported, got %v", errs)
+	}
+}
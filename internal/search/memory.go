@@ -0,0 +1,278 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// minSimilarity is the weakest name match returned by MemoryIndex
+const minSimilarity = 0.5
+
+// docKey identifies a document across kinds
+type docKey struct {
+	kind string
+	id   int
+}
+
+// memoryDoc is an indexed document with its names normalized
+type memoryDoc struct {
+	Document
+	names [][]string // tokens of each normalized name
+	flat  []string   // each normalized name joined by spaces
+}
+
+// MemoryIndex is an embedded index for single-instance deployments. Candidates are
+// found through a trigram inverted index, then ranked by exact, prefix and
+// edit-distance matches of their names, boosted by weight.
+type MemoryIndex struct {
+	mu       sync.RWMutex
+	docs     map[docKey]*memoryDoc
+	trigrams map[string]map[docKey]struct{}
+}
+
+// NewMemoryIndex creates an empty in-memory index
+func NewMemoryIndex() *MemoryIndex {
+	return &MemoryIndex{
+		docs:     make(map[docKey]*memoryDoc),
+		trigrams: make(map[string]map[docKey]struct{}),
+	}
+}
+
+// Name returns BackendMemory
+func (m *MemoryIndex) Name() string { return BackendMemory }
+
+// Put adds or replaces a document
+func (m *MemoryIndex) Put(ctx context.Context, doc Document) error {
+	indexed := &memoryDoc{Document: doc}
+	for _, name := range doc.Names {
+		normalized := normalize(name)
+		if normalized == "" {
+			continue
+		}
+		indexed.flat = append(indexed.flat, normalized)
+		indexed.names = append(indexed.names, strings.Fields(normalized))
+	}
+
+	key := docKey{doc.Kind, doc.ID}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(key)
+	m.docs[key] = indexed
+	for _, name := range indexed.names {
+		for _, token := range name {
+			for _, gram := range trigrams(token) {
+				postings, ok := m.trigrams[gram]
+				if !ok {
+					postings = make(map[docKey]struct{})
+					m.trigrams[gram] = postings
+				}
+				postings[key] = struct{}{}
+			}
+		}
+	}
+	return nil
+}
+
+// Delete removes a document
+func (m *MemoryIndex) Delete(ctx context.Context, kind string, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(docKey{kind, id})
+	return nil
+}
+
+// remove drops a document and its postings; callers hold the write lock
+func (m *MemoryIndex) remove(key docKey) {
+	doc, ok := m.docs[key]
+	if !ok {
+		return
+	}
+	delete(m.docs, key)
+	for _, name := range doc.names {
+		for _, token := range name {
+			for _, gram := range trigrams(token) {
+				delete(m.trigrams[gram], key)
+				if len(m.trigrams[gram]) == 0 {
+					delete(m.trigrams, gram)
+				}
+			}
+		}
+	}
+}
+
+// Search ranks the documents of kind sharing a trigram with query
+func (m *MemoryIndex) Search(ctx context.Context, kind, query string, limit int) ([]Hit, error) {
+	normalized := normalize(query)
+	if normalized == "" || limit <= 0 {
+		return []Hit{}, nil
+	}
+	tokens := strings.Fields(normalized)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	candidates := make(map[docKey]struct{})
+	for _, token := range tokens {
+		for _, gram := range trigrams(token) {
+			for key := range m.trigrams[gram] {
+				if key.kind == kind {
+					candidates[key] = struct{}{}
+				}
+			}
+		}
+	}
+
+	hits := []Hit{}
+	for key := range candidates {
+		doc := m.docs[key]
+		similarity := 0.0
+		for i, name := range doc.names {
+			similarity = math.Max(similarity, nameSimilarity(normalized, tokens, doc.flat[i], name))
+		}
+		if similarity < minSimilarity {
+			continue
+		}
+		score := similarity * (1 + 0.05*math.Log1p(math.Max(doc.Weight, 0)))
+		hits = append(hits, Hit{Kind: kind, ID: key.id, Score: score})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// nameSimilarity scores a normalized query against one normalized name from 0 to 1:
+// 1 for an exact match, 0.9 for a prefix, otherwise the average over query tokens of
+// their best token match, where typos within the allowed edit distance still count
+func nameSimilarity(query string, queryTokens []string, name string, nameTokens []string) float64 {
+	switch {
+	case query == name:
+		return 1
+	case strings.HasPrefix(name, query):
+		return 0.9
+	}
+
+	total := 0.0
+	for _, q := range queryTokens {
+		best := 0.0
+		for _, t := range nameTokens {
+			best = math.Max(best, tokenSimilarity(q, t))
+		}
+		total += best
+	}
+	return 0.85 * total / float64(len(queryTokens))
+}
+
+// tokenSimilarity compares two tokens, tolerating one edit in tokens of four or more
+// characters and two from eight
+func tokenSimilarity(q, t string) float64 {
+	if q == t {
+		return 1
+	}
+	if len([]rune(q)) >= 3 && strings.HasPrefix(t, q) {
+		return 0.9
+	}
+
+	qr, tr := []rune(q), []rune(t)
+	allowed := 0
+	switch n := min(len(qr), len(tr)); {
+	case n >= 8:
+		allowed = 2
+	case n >= 4:
+		allowed = 1
+	}
+	// A typo in a prefix still counts, e.g. "amsterdma" for "amsterdam-zuid"
+	if len(tr) > len(qr)+allowed {
+		tr = tr[:len(qr)+allowed]
+	}
+	distance := editDistance(qr, tr)
+	if distance > allowed {
+		return 0
+	}
+	return 1 - float64(distance)/float64(max(len(qr), len(tr)))
+}
+
+// editDistance is the optimal string alignment distance: insertions, deletions,
+// substitutions and transpositions of adjacent characters each cost one
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+// trigrams returns the padded character trigrams of a token, so short tokens and
+// word boundaries still produce grams
+func trigrams(token string) []string {
+	runes := []rune(" " + token + " ")
+	grams := make([]string, 0, len(runes))
+	for i := 0; i+3 <= len(runes); i++ {
+		grams = append(grams, string(runes[i:i+3]))
+	}
+	return grams
+}
+
+// folds maps Latin letters with diacritics to their base letters, so "Zürich" matches
+// "zurich" and "São Paulo" matches "sao paulo"
+var folds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'ā': "a", 'ą': "a", 'ă': "a",
+	'æ': "ae", 'ç': "c", 'ć': "c", 'č': "c", 'ď': "d", 'đ': "d",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e", 'ē': "e", 'ę': "e", 'ě': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i", 'ī': "i", 'ı': "i",
+	'ł': "l", 'ñ': "n", 'ń': "n", 'ň': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'ō': "o", 'ő': "o", 'œ': "oe",
+	'ř': "r", 'ś': "s", 'š': "s", 'ş': "s", 'ș': "s", 'ß': "ss", 'ť': "t", 'ţ': "t", 'ț': "t",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u", 'ū': "u", 'ů': "u", 'ű': "u",
+	'ý': "y", 'ÿ': "y", 'ź': "z", 'ż': "z", 'ž': "z", 'þ': "th", 'ð': "d",
+}
+
+// normalize lowercases, folds diacritics and reduces punctuation to single spaces.
+// Letters of other scripts are kept as is.
+func normalize(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case folds[r] != "":
+			b.WriteString(folds[r])
+			space = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+			space = false
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left by decomposed input
+		case !space:
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
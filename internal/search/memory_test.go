@@ -0,0 +1,81 @@
+package search
+
+import (
+	"context"
+	"testing"
+)
+
+func TestMemoryIndex_Search(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex()
+	docs := []Document{
+		{Kind: KindCity, ID: 1, Names: []string{"London", "Londres", "Londra"}, Weight: 8_900_000},
+		{Kind: KindCity, ID: 2, Names: []string{"London"}, Weight: 400_000}, // Ontario
+		{Kind: KindCity, ID: 3, Names: []string{"Zürich"}, Weight: 420_000},
+		{Kind: KindCity, ID: 4, Names: []string{"São Paulo"}, Weight: 12_300_000},
+		{Kind: KindCity, ID: 5, Names: []string{"Amsterdam"}, Weight: 870_000},
+		{Kind: KindCity, ID: 6, Names: []string{"München", "Munich"}, Weight: 1_500_000},
+		{Kind: KindPlace, ID: 1, Names: []string{"London Bridge"}},
+	}
+	for _, doc := range docs {
+		if err := index.Put(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []int
+	}{
+		{"exact, ranked by weight", "london", []int{1, 2}},
+		{"typo", "londno", []int{1, 2}},
+		{"transposition in a long name", "amstredam", []int{5}},
+		{"diacritics folded", "zurich", []int{3}},
+		{"multiple words", "sao paolo", []int{4}},
+		{"prefix", "ams", []int{5}},
+		{"localized name", "Londres", []int{1}},
+		{"alternate name", "munich", []int{6}},
+		{"unrelated", "tokyo", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hits, err := index.Search(ctx, KindCity, test.query, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(hits) != len(test.want) {
+				t.Fatalf("Expected %v for %q, got %+v", test.want, test.query, hits)
+			}
+			for i, id := range test.want {
+				if hits[i].ID != id || hits[i].Kind != KindCity {
+					t.Errorf("Expected %v for %q, got %+v", test.want, test.query, hits)
+				}
+			}
+		})
+	}
+
+	if hits, _ := index.Search(ctx, KindCity, "london", 1); len(hits) != 1 {
+		t.Errorf("Expected the limit to apply, got %d hits", len(hits))
+	}
+}
+
+func TestMemoryIndex_PutDelete(t *testing.T) {
+	ctx := context.Background()
+	index := NewMemoryIndex()
+	_ = index.Put(ctx, Document{Kind: KindCity, ID: 1, Names: []string{"Portland"}})
+	_ = index.Put(ctx, Document{Kind: KindCity, ID: 1, Names: []string{"Seattle"}})
+
+	if hits, _ := index.Search(ctx, KindCity, "portland", 10); len(hits) != 0 {
+		t.Errorf("Expected a replaced document to drop its old names, got %+v", hits)
+	}
+	if hits, _ := index.Search(ctx, KindCity, "seattle", 10); len(hits) != 1 {
+		t.Errorf("Expected the new name to match, got %+v", hits)
+	}
+
+	_ = index.Delete(ctx, KindCity, 1)
+	_ = index.Delete(ctx, KindCity, 1)
+	if hits, _ := index.Search(ctx, KindCity, "seattle", 10); len(hits) != 0 || len(index.trigrams) != 0 {
+		t.Errorf("Expected the document and its postings to be removed, got %+v", hits)
+	}
+}
@@ -0,0 +1,126 @@
+// Package search keeps an optional full-text index of cities and places, giving the
+// city and place Search endpoints fuzzy, typo-tolerant, multi-language matching with
+// relevance ranking instead of SQL LIKE patterns.
+//
+// The index is fed through repository hooks (see repo.NewHookedEngine) and rebuilt
+// from storage at startup. Until that rebuild completes, and whenever the index fails,
+// searches fall back to the repositories' SQL search.
+package search
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Backend names accepted by WEATHER_API_SEARCH_BACKEND
+const (
+	BackendSQL     = "sql" // no index; repositories search with LIKE
+	BackendMemory  = "memory"
+	BackendElastic = "elasticsearch"
+)
+
+// Document kinds
+const (
+	KindCity  = "city"
+	KindPlace = "place"
+)
+
+// Document is one searchable city or place
+type Document struct {
+	Kind  string
+	ID    int
+	Names []string // primary name first, then alternates such as localized names
+
+	// Weight ranks otherwise similar matches, e.g. a city's population. Boosts grow
+	// logarithmically, so a metropolis does not drown out an exact match.
+	Weight float64
+}
+
+// Hit is a ranked search result
+type Hit struct {
+	Kind  string
+	ID    int
+	Score float64
+}
+
+// Index stores documents and ranks them against free-text queries
+type Index interface {
+	// Name returns the backend name
+	Name() string
+
+	// Put adds or replaces a document
+	Put(ctx context.Context, doc Document) error
+
+	// Delete removes a document; deleting a missing document is not an error
+	Delete(ctx context.Context, kind string, id int) error
+
+	// Search returns up to limit documents of kind matching query, best first
+	Search(ctx context.Context, kind, query string, limit int) ([]Hit, error)
+}
+
+// DefaultLanguages are the localized city names indexed when
+// WEATHER_API_SEARCH_LANGUAGES is not set
+var DefaultLanguages = []string{"en", "de", "fr", "es", "it", "pt", "nl", "pl", "ru", "ja", "zh", "ko", "ar"}
+
+// Config selects and configures the search backend
+type Config struct {
+	Backend   string
+	URL       string // Elasticsearch endpoint
+	IndexName string // Elasticsearch index
+	Languages []string
+}
+
+// LoadConfig reads WEATHER_API_SEARCH_BACKEND (sql, memory or elasticsearch),
+// WEATHER_API_SEARCH_URL, WEATHER_API_SEARCH_INDEX and the comma-separated
+// WEATHER_API_SEARCH_LANGUAGES
+func LoadConfig() (Config, error) {
+	config := Config{
+		Backend:   strings.ToLower(strings.TrimSpace(os.Getenv("WEATHER_API_SEARCH_BACKEND"))),
+		URL:       strings.TrimRight(strings.TrimSpace(os.Getenv("WEATHER_API_SEARCH_URL")), "/"),
+		IndexName: strings.TrimSpace(os.Getenv("WEATHER_API_SEARCH_INDEX")),
+		Languages: DefaultLanguages,
+	}
+	if config.Backend == "" {
+		config.Backend = BackendSQL
+	}
+	if config.IndexName == "" {
+		config.IndexName = "weather-api"
+	}
+	if value := os.Getenv("WEATHER_API_SEARCH_LANGUAGES"); value != "" {
+		config.Languages = nil
+		for _, lang := range strings.Split(value, ",") {
+			if lang = strings.ToLower(strings.TrimSpace(lang)); lang != "" {
+				config.Languages = append(config.Languages, lang)
+			}
+		}
+	}
+
+	switch config.Backend {
+	case BackendSQL, BackendMemory:
+	case BackendElastic:
+		if config.URL == "" {
+			return config, fmt.Errorf("WEATHER_API_SEARCH_URL is required for the %s search backend", BackendElastic)
+		}
+	default:
+		return config, fmt.Errorf("unknown search backend %q (supported: %s, %s, %s)", config.Backend, BackendSQL, BackendMemory, BackendElastic)
+	}
+	return config, nil
+}
+
+// Open creates the configured index, or returns nil for BackendSQL
+func Open(ctx context.Context, config Config) (Index, error) {
+	switch config.Backend {
+	case BackendMemory:
+		return NewMemoryIndex(), nil
+	case BackendElastic:
+		index := NewElasticIndex(config.URL, config.IndexName)
+		if err := index.EnsureIndex(ctx); err != nil {
+			return nil, err
+		}
+		return index, nil
+	default:
+		return nil, nil
+	}
+}
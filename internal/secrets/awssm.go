@@ -0,0 +1,59 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+)
+
+// awsSecretsManagerBackend reads an AWS Secrets Manager secret string
+type awsSecretsManagerBackend struct {
+	client   *secretsmanager.Client
+	secretID string
+}
+
+// newAWSSecretsManagerBackend creates a client from the default AWS configuration, so
+// the region, credentials and endpoint come from the usual environment variables,
+// shared config files, SSO or instance roles. A ?region= in the URI takes precedence.
+// The SDK's own HTTP client is used so AWS_CA_BUNDLE and similar settings apply.
+func newAWSSecretsManagerBackend(location string) (*awsSecretsManagerBackend, error) {
+	secretID, query, _ := strings.Cut(location, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil || secretID == "" {
+		return nil, fmt.Errorf("invalid awssm secrets URI: expected awssm://<secret id>[?region=<region>]")
+	}
+
+	options := []func(*config.LoadOptions) error{config.WithHTTPClient(awshttp.NewBuildableClient().WithTimeout(backendTimeout))}
+	if region := params.Get("region"); region != "" {
+		options = append(options, config.WithRegion(region))
+	}
+	cfg, err := config.LoadDefaultConfig(context.Background(), options...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("AWS_REGION (or ?region=) is required for awssm:// secrets")
+	}
+	return &awsSecretsManagerBackend{client: secretsmanager.NewFromConfig(cfg), secretID: secretID}, nil
+}
+
+// Name returns "awssm"
+func (b *awsSecretsManagerBackend) Name() string { return "awssm" }
+
+// Fetch reads the current version of the secret, which must be a JSON object string
+func (b *awsSecretsManagerBackend) Fetch(ctx context.Context) (map[string]string, error) {
+	secret, err := b.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{SecretId: aws.String(b.secretID)})
+	if err != nil {
+		return nil, err
+	}
+	if secret.SecretString == nil {
+		return nil, fmt.Errorf("secret %s has no string value; binary secrets are not supported", b.secretID)
+	}
+	return decodeSecretValues([]byte(*secret.SecretString))
+}
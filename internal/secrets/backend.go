@@ -0,0 +1,125 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backendTimeout bounds the startup fetch from a secrets backend
+const backendTimeout = 10 * time.Second
+
+// Backend is an external secrets store holding environment variables, such as
+// DATABASE_URL and provider API keys, as one secret of name/value pairs
+type Backend interface {
+	// Name returns the backend name (vault, awssm)
+	Name() string
+
+	// Fetch returns the variables stored in the secret
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// OpenBackend returns the backend for a secrets URI:
+//
+//   - vault://<mount>/<path> reads a KV version 2 secret from VAULT_ADDR with
+//     VAULT_TOKEN (and VAULT_NAMESPACE when set)
+//   - awssm://<secret id or ARN> reads an AWS Secrets Manager secret in AWS_REGION
+//     (or ?region=) with the default AWS credential chain
+func OpenBackend(uri string) (Backend, error) {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok || rest == "" {
+		return nil, fmt.Errorf("invalid secrets URI %q: expected vault://<mount>/<path> or awssm://<secret id>", uri)
+	}
+	client := &http.Client{Timeout: backendTimeout}
+
+	switch scheme {
+	case "vault":
+		return newVaultBackend(client, rest)
+	case "awssm":
+		return newAWSSecretsManagerBackend(rest)
+	default:
+		return nil, fmt.Errorf("unsupported secrets URI scheme %q (supported: vault, awssm)", scheme)
+	}
+}
+
+var (
+	backendOnce sync.Once
+	backendErr  error
+)
+
+// loadBackend applies the secret named by WEATHER_API_SECRETS_URL to the environment
+// once per process, so every later os.Getenv sees it
+func loadBackend() error {
+	backendOnce.Do(func() {
+		uri := strings.TrimSpace(os.Getenv("WEATHER_API_SECRETS_URL"))
+		if uri == "" {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), backendTimeout)
+		defer cancel()
+		backendErr = applyBackend(ctx, uri)
+	})
+	return backendErr
+}
+
+// applyBackend sets the variables held by the secret at uri. Variables already set in
+// the environment are left alone, so a value can still be overridden locally.
+func applyBackend(ctx context.Context, uri string) error {
+	backend, err := OpenBackend(uri)
+	if err != nil {
+		return err
+	}
+	values, err := backend.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read secrets from %s: %w", backend.Name(), err)
+	}
+
+	for name, value := range values {
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		if err := os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed to set %s from %s: %w", name, backend.Name(), err)
+		}
+	}
+	return nil
+}
+
+// decodeSecretValues decodes a secret holding a JSON object of variables. Values that
+// are not strings are kept in their JSON form.
+func decodeSecretValues(data []byte) (map[string]string, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("secret must be a JSON object of variables: %w", err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for name, value := range raw {
+		var s string
+		if err := json.Unmarshal(value, &s); err != nil {
+			s = string(value)
+		}
+		values[name] = s
+	}
+	return values, nil
+}
+
+// readResponse returns the body of a successful response, or an error carrying the
+// status and the start of the body
+func readResponse(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s: %.200s", resp.Status, body)
+	}
+	return body, nil
+}
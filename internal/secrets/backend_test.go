@@ -0,0 +1,139 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenBackend(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "https://vault.example.com")
+	t.Setenv("VAULT_TOKEN", "token")
+	t.Setenv("AWS_REGION", "us-east-1")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	tests := []struct {
+		uri  string
+		name string
+	}{
+		{"vault://secret/weather", "vault"},
+		{"awssm://weather/prod?region=eu-west-1", "awssm"},
+		{"awssm://arn:aws:secretsmanager:us-east-1:123456789012:secret:weather", "awssm"},
+		{"vault://secret", ""},
+		{"consul://weather", ""},
+		{"weather", ""},
+	}
+	for _, tt := range tests {
+		backend, err := OpenBackend(tt.uri)
+		if tt.name == "" {
+			if err == nil {
+				t.Errorf("OpenBackend(%q): expected an error", tt.uri)
+			}
+			continue
+		}
+		if err != nil || backend.Name() != tt.name {
+			t.Errorf("OpenBackend(%q) = %v, %v; want %s", tt.uri, backend, err, tt.name)
+		}
+	}
+
+	t.Setenv("VAULT_TOKEN", "")
+	if _, err := OpenBackend("vault://secret/weather"); err == nil {
+		t.Error("Expected an error without VAULT_TOKEN")
+	}
+}
+
+func TestVaultBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/secret/data/weather/prod" || r.Header.Get("X-Vault-Token") != "token" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"DATABASE_URL":"postgres://vault","PORT":8080},"metadata":{"version":3}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+
+	backend, err := OpenBackend("vault://secret/weather/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := backend.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if values["DATABASE_URL"] != "postgres://vault" || values["PORT"] != "8080" {
+		t.Errorf("Unexpected values %v", values)
+	}
+
+	t.Setenv("VAULT_TOKEN", "wrong")
+	backend, _ = OpenBackend("vault://secret/weather/prod")
+	if _, err := backend.Fetch(context.Background()); err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Expected the status in the error, got %v", err)
+	}
+}
+
+func TestAWSSecretsManagerBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var request struct{ SecretId string }
+		json.Unmarshal(body, &request)
+		auth := r.Header.Get("Authorization")
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" || request.SecretId != "weather/prod" ||
+			!strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/") || !strings.Contains(auth, "/us-west-2/secretsmanager/aws4_request") ||
+			r.Header.Get("X-Amz-Security-Token") != "session" {
+			http.Error(w, `{"__type":"AccessDeniedException"}`, http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"NWS_AGENT":"from-aws"}`})
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_ENDPOINT_URL_SECRETS_MANAGER", server.URL)
+	t.Setenv("AWS_REGION", "us-west-2")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	backend, err := OpenBackend("awssm://weather/prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := backend.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if values["NWS_AGENT"] != "from-aws" {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+func TestApplyBackend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"data":{"WEATHER_API_TEST_FROM_VAULT":"vault","WEATHER_API_TEST_LOCAL":"vault"}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+	t.Setenv("VAULT_TOKEN", "token")
+	t.Setenv("WEATHER_API_TEST_LOCAL", "local")
+	t.Setenv("WEATHER_API_TEST_FROM_VAULT", "")
+	os.Unsetenv("WEATHER_API_TEST_FROM_VAULT")
+
+	if err := applyBackend(context.Background(), "vault://secret/weather"); err != nil {
+		t.Fatal(err)
+	}
+	if got := os.Getenv("WEATHER_API_TEST_FROM_VAULT"); got != "vault" {
+		t.Errorf("Expected the secret to be applied, got %q", got)
+	}
+	if got := os.Getenv("WEATHER_API_TEST_LOCAL"); got != "local" {
+		t.Errorf("Expected the environment to take precedence, got %q", got)
+	}
+}
@@ -0,0 +1,166 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted value formats. Every format encrypts with AES-256-GCM under a key derived
+// from the encryption key and a random salt; the format fixes the derivation and its
+// parameters. Values are hex encoded: salt:nonce:ciphertext for FormatScrypt, which
+// predates versioning, and v<format>:salt:nonce:ciphertext since.
+const (
+	// FormatScrypt derives keys with scrypt (N=32768, r=8, p=1). Values in it still
+	// decrypt, but new values use CurrentFormat.
+	FormatScrypt = 1
+
+	// FormatArgon2id derives keys with Argon2id (3 passes over 64 MiB, 4 lanes) and
+	// authenticates the version prefix, so a value cannot be downgraded
+	FormatArgon2id = 2
+
+	// CurrentFormat is the format EncryptValue writes
+	CurrentFormat = FormatArgon2id
+)
+
+// deriveKey derives the AES-256 key of a format from the encryption key and salt
+func deriveKey(format int, key string, salt []byte) ([]byte, error) {
+	switch format {
+	case FormatScrypt:
+		return scrypt.Key([]byte(key), salt, 32768, 8, 1, 32)
+	case FormatArgon2id:
+		return argon2.IDKey([]byte(key), salt, 3, 64*1024, 4, 32), nil
+	default:
+		return nil, fmt.Errorf("unsupported encrypted value format v%d", format)
+	}
+}
+
+// EncryptValue encrypts a single value using the provided key in CurrentFormat
+func EncryptValue(value, key string) (string, error) {
+	return encryptValue(value, key, CurrentFormat)
+}
+
+func encryptValue(value, key string, format int) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	aesGCM, err := newGCM(format, key, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aesGCM.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	encoded := hex.EncodeToString(salt) + ":" + hex.EncodeToString(nonce) + ":" +
+		hex.EncodeToString(aesGCM.Seal(nil, nonce, []byte(value), associatedData(format)))
+	if format == FormatScrypt {
+		return encoded, nil
+	}
+	return versionPrefix(format) + encoded, nil
+}
+
+// DecryptValue decrypts a single value using the provided key, in any supported format.
+// Values that are not in an encrypted format are returned unchanged.
+func DecryptValue(encryptedValue, key string) (string, error) {
+	format, salt, nonce, ciphertext, ok := parseEncrypted(encryptedValue)
+	if !ok {
+		return encryptedValue, nil
+	}
+
+	aesGCM, err := newGCM(format, key, salt)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, associatedData(format))
+	if err != nil {
+		return "", fmt.Errorf("decryption failed: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// IsEncrypted checks if a value appears to be encrypted
+func IsEncrypted(value string) bool {
+	_, _, _, _, ok := parseEncrypted(value)
+	return ok
+}
+
+// FormatOf returns the format of an encrypted value, or 0 for values that are not
+// encrypted
+func FormatOf(value string) int {
+	format, _, _, _, ok := parseEncrypted(value)
+	if !ok {
+		return 0
+	}
+	return format
+}
+
+// parseEncrypted splits an encrypted value into its format and decoded parts
+func parseEncrypted(value string) (format int, salt, nonce, ciphertext []byte, ok bool) {
+	parts := strings.Split(value, ":")
+	format = FormatScrypt
+	if len(parts) == 4 {
+		version, found := strings.CutPrefix(parts[0], "v")
+		n, err := strconv.Atoi(version)
+		if !found || err != nil || n <= FormatScrypt {
+			return 0, nil, nil, nil, false
+		}
+		format, parts = n, parts[1:]
+	}
+	if len(parts) != 3 {
+		return 0, nil, nil, nil, false
+	}
+
+	decoded := make([][]byte, 3)
+	for i, part := range parts {
+		b, err := hex.DecodeString(part)
+		if err != nil {
+			return 0, nil, nil, nil, false
+		}
+		decoded[i] = b
+	}
+	return format, decoded[0], decoded[1], decoded[2], true
+}
+
+func newGCM(format int, key string, salt []byte) (cipher.AEAD, error) {
+	derivedKey, err := deriveKey(format, key, salt)
+	if err != nil {
+		return nil, fmt.Errorf("key derivation failed: %w", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aesGCM, nil
+}
+
+func versionPrefix(format int) string {
+	return "v" + strconv.Itoa(format) + ":"
+}
+
+// associatedData binds versioned values to their format
+func associatedData(format int) []byte {
+	if format == FormatScrypt {
+		return nil
+	}
+	return []byte(versionPrefix(format))
+}
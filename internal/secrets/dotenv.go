@@ -0,0 +1,211 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// EnvironmentVariable names the deployment environment (e.g. production, staging) whose
+// .env.<environment> file is loaded
+const EnvironmentVariable = "WEATHER_API_ENV"
+
+// DotenvFiles returns the dotenv files loaded for an environment, lowest precedence
+// first: .env holds shared defaults, .env.<environment> the settings of one deployment
+// and .env.local the overrides of one machine, which is also the file the encrypt and
+// decrypt commands work on
+func DotenvFiles(environment string) []string {
+	files := []string{".env"}
+	if environment != "" {
+		files = append(files, ".env."+environment)
+	}
+	return append(files, ".env.local")
+}
+
+var (
+	dotenvOnce sync.Once
+	dotenvErr  error
+)
+
+// loadDotenv applies the dotenv files of the working directory to the environment once
+// per process
+func loadDotenv() error {
+	dotenvOnce.Do(func() {
+		dotenvErr = LoadDotenv(".", os.Getenv(EnvironmentVariable), os.Getenv("WEATHER_API_ENCRYPTION_KEY"))
+	})
+	return dotenvErr
+}
+
+// LoadDotenv sets the variables of the dotenv files in dir (see DotenvFiles), a later
+// file overriding an earlier one. Variables already set in the environment are left
+// alone. Encrypted values are decrypted with key; without a key they are skipped with a
+// warning, so commands such as decrypt still run.
+func LoadDotenv(dir, environment, key string) error {
+	if strings.ContainsAny(environment, `/\`) || strings.HasPrefix(environment, ".") {
+		return fmt.Errorf("invalid %s %q", EnvironmentVariable, environment)
+	}
+
+	values := map[string]string{}
+	sources := map[string]string{}
+	for _, name := range DotenvFiles(environment) {
+		path := filepath.Join(dir, name)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		parsed, err := ParseDotenv(data)
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		for variable, value := range parsed {
+			values[variable] = value
+			sources[variable] = path
+		}
+	}
+
+	names := make([]string, 0, len(values))
+	for variable := range values {
+		names = append(names, variable)
+	}
+	slices.Sort(names)
+
+	for _, variable := range names {
+		if _, set := os.LookupEnv(variable); set {
+			continue
+		}
+		value := values[variable]
+		if IsEncrypted(value) {
+			if key == "" {
+				fmt.Fprintf(os.Stderr, "Warning: WEATHER_API_ENCRYPTION_KEY is not set; skipping encrypted %s from %s\n", variable, sources[variable])
+				continue
+			}
+			decrypted, err := DecryptValue(value, key)
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s from %s: %w", variable, sources[variable], err)
+			}
+			value = decrypted
+		}
+		if err := os.Setenv(variable, value); err != nil {
+			return fmt.Errorf("failed to set %s: %w", variable, err)
+		}
+	}
+	return nil
+}
+
+// ParseDotenv parses dotenv content: NAME=value lines with an optional export prefix,
+// blank lines and # comments. Values may be single quoted (literal) or double quoted
+// (with \n, \t, \" and \\ escapes); unquoted values end at a " #" comment.
+func ParseDotenv(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for number := 1; scanner.Scan(); number++ {
+		entry, ok, err := parseDotenvLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
+		}
+		if ok {
+			values[entry.name] = entry.value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// dotenvEntry is one NAME=value line of a dotenv file, split so that the value can be
+// replaced while the rest of the line is kept as written
+type dotenvEntry struct {
+	head  string // indentation, export prefix, name and = with the spaces around them
+	name  string
+	raw   string // the value as written, quotes included
+	value string // the value after unquoting
+	tail  string // spaces and comment after the value
+}
+
+// quote returns the quote character of the value as written, or 0 when it is unquoted
+func (e dotenvEntry) quote() byte {
+	if e.raw != "" && (e.raw[0] == '\'' || e.raw[0] == '"') {
+		return e.raw[0]
+	}
+	return 0
+}
+
+// parseDotenvLine parses one line of dotenv content without its line ending. It
+// reports false for blank and comment lines.
+func parseDotenvLine(line string) (dotenvEntry, bool, error) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return dotenvEntry{}, false, nil
+	}
+
+	before, after, ok := strings.Cut(line, "=")
+	name := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(before), "export "))
+	if !ok || !validVariableName(name) {
+		return dotenvEntry{}, false, errors.New("expected NAME=value")
+	}
+	rest := strings.TrimLeft(after, " \t")
+	entry := dotenvEntry{head: line[:len(line)-len(rest)], name: name}
+
+	if rest != "" && (rest[0] == '\'' || rest[0] == '"') {
+		quote := rest[0]
+		end := strings.LastIndexByte(rest, quote)
+		if end == 0 {
+			return dotenvEntry{}, false, fmt.Errorf("unterminated %c quote", quote)
+		}
+		entry.raw, entry.tail = rest[:end+1], rest[end+1:]
+		if comment := strings.TrimSpace(entry.tail); comment != "" && !strings.HasPrefix(comment, "#") {
+			return dotenvEntry{}, false, fmt.Errorf("unexpected %q after quoted value", comment)
+		}
+		entry.value = entry.raw[1:end]
+		if quote == '"' {
+			entry.value = strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(entry.value)
+		}
+		return entry, true, nil
+	}
+
+	entry.raw = rest
+	if i := strings.Index(rest, " #"); i >= 0 {
+		entry.raw = rest[:i]
+	}
+	entry.raw = strings.TrimRight(entry.raw, " \t")
+	entry.tail = rest[len(entry.raw):]
+	entry.value = entry.raw
+	return entry, true, nil
+}
+
+// quoteDotenvValue writes value so that parseDotenvLine reads it back, in the quotes
+// it was written with where they can hold it
+func quoteDotenvValue(value string, quote byte) string {
+	switch {
+	case quote == '\'' && !strings.ContainsAny(value, "'\n"):
+		return "'" + value + "'"
+	case quote == 0 && value == strings.TrimSpace(value) && !strings.Contains(value, " #") &&
+		!strings.ContainsAny(value, "\n\r") && !strings.HasPrefix(value, "'") && !strings.HasPrefix(value, `"`):
+		return value
+	default:
+		return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(value) + `"`
+	}
+}
+
+func validVariableName(name string) bool {
+	if name == "" || (name[0] >= '0' && name[0] <= '9') {
+		return false
+	}
+	for _, r := range name {
+		if r != '_' && (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') && (r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
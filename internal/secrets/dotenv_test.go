@@ -0,0 +1,99 @@
+package secrets
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseDotenv(t *testing.T) {
+	values, err := ParseDotenv([]byte(`# comment
+DATABASE_URL=postgres://localhost/weather # local database
+export NWS_AGENT="weather-api/1.0 (ops@example.com)"
+GREETING="line one\nline two"
+LITERAL='a \n b # not a comment'
+EMPTY=
+`))
+	if err != nil {
+		t.Fatalf("ParseDotenv failed: %v", err)
+	}
+
+	expected := map[string]string{
+		"DATABASE_URL": "postgres://localhost/weather",
+		"NWS_AGENT":    "weather-api/1.0 (ops@example.com)",
+		"GREETING":     "line one\nline two",
+		"LITERAL":      `a \n b # not a comment`,
+		"EMPTY":        "",
+	}
+	if len(values) != len(expected) {
+		t.Errorf("expected %d values, got %v", len(expected), values)
+	}
+	for name, want := range expected {
+		if got := values[name]; got != want {
+			t.Errorf("expected %s=%q, got %q", name, want, got)
+		}
+	}
+
+	for _, invalid := range []string{"NO_EQUALS", "1NAME=x", `QUOTED="unterminated`, `QUOTED="a" b`} {
+		if _, err := ParseDotenv([]byte(invalid)); err == nil {
+			t.Errorf("expected an error parsing %q", invalid)
+		}
+	}
+}
+
+func TestLoadDotenv(t *testing.T) {
+	key := "TestKey123Valid"
+	encrypted, err := EncryptValue("postgres://prod/weather", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		".env":            "DOTENV_SHARED=base\nDOTENV_STAGE=base\nDOTENV_LOCAL=base\nDOTENV_EXPORTED=base\n",
+		".env.production": "DOTENV_STAGE=production\nDOTENV_LOCAL=production\nDOTENV_SECRET=" + encrypted + "\n",
+		".env.staging":    "DOTENV_STAGE=staging\n",
+		".env.local":      "DOTENV_LOCAL=local\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, name := range []string{"DOTENV_SHARED", "DOTENV_STAGE", "DOTENV_LOCAL", "DOTENV_SECRET"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("DOTENV_EXPORTED", "exported")
+
+	if err := LoadDotenv(dir, "production", key); err != nil {
+		t.Fatalf("LoadDotenv failed: %v", err)
+	}
+	expected := map[string]string{
+		"DOTENV_SHARED":   "base",
+		"DOTENV_STAGE":    "production",
+		"DOTENV_LOCAL":    "local",
+		"DOTENV_EXPORTED": "exported",
+		"DOTENV_SECRET":   "postgres://prod/weather",
+	}
+	for name, want := range expected {
+		if got := os.Getenv(name); got != want {
+			t.Errorf("expected %s=%q, got %q", name, want, got)
+		}
+	}
+
+	os.Unsetenv("DOTENV_SECRET")
+	if err := LoadDotenv(dir, "production", ""); err != nil {
+		t.Errorf("expected encrypted values to be skipped without a key, got %v", err)
+	}
+	if _, set := os.LookupEnv("DOTENV_SECRET"); set {
+		t.Error("expected the encrypted value to stay unset without a key")
+	}
+	if err := LoadDotenv(dir, "production", "WrongKey123Valid"); err == nil {
+		t.Error("expected an error decrypting with the wrong key")
+	}
+	if err := LoadDotenv(dir, "../production", key); err == nil {
+		t.Error("expected an error for an environment naming another directory")
+	}
+}
@@ -0,0 +1,143 @@
+package secrets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EnvValueFunc returns the replacement for the value of one NAME=value entry of an env
+// file. The value is passed as ParseDotenv reads it, without quotes or comment.
+type EnvValueFunc func(name, value string) (string, error)
+
+// TransformEnv streams env file content from r to w, replacing the value of each
+// NAME=value entry with the result of fn. Entries are read with the rules of
+// ParseDotenv; a replaced value keeps its quotes where they can hold it and is quoted
+// where it has to be, and export prefixes, comments, other lines and line endings are
+// copied as they are. It returns how many values fn changed.
+func TransformEnv(w io.Writer, r io.Reader, fn EnvValueFunc) (int, error) {
+	reader := bufio.NewReader(r)
+	changed := 0
+	for number := 1; ; number++ {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return changed, fmt.Errorf("failed to read env file: %w", readErr)
+		}
+
+		body := strings.TrimRight(line, "\r\n")
+		entry, ok, err := parseDotenvLine(body)
+		if err != nil {
+			return changed, fmt.Errorf("line %d: %w", number, err)
+		}
+		if ok {
+			replaced, err := fn(entry.name, entry.value)
+			if err != nil {
+				return changed, err
+			}
+			if replaced != entry.value {
+				changed++
+				line = entry.head + quoteDotenvValue(replaced, entry.quote()) + entry.tail + line[len(body):]
+			}
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			return changed, fmt.Errorf("failed to write env file: %w", err)
+		}
+
+		if readErr != nil {
+			return changed, nil
+		}
+	}
+}
+
+// EncryptEnv streams env file content from r to w with every value encrypted with key.
+// Values already encrypted are left as they are, so a file can be encrypted again after
+// plain values are added to it.
+func EncryptEnv(w io.Writer, r io.Reader, key string) (int, error) {
+	return TransformEnv(w, r, func(name, value string) (string, error) {
+		if IsEncrypted(value) {
+			return value, nil
+		}
+		encrypted, err := EncryptValue(value, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to encrypt value for %s: %w", name, err)
+		}
+		return encrypted, nil
+	})
+}
+
+// DecryptEnv streams env file content from r to w with every encrypted value decrypted
+// with key
+func DecryptEnv(w io.Writer, r io.Reader, key string) (int, error) {
+	return TransformEnv(w, r, func(name, value string) (string, error) {
+		decrypted, err := DecryptValue(value, key)
+		if err != nil {
+			return "", fmt.Errorf("failed to decrypt value for %s: %w", name, err)
+		}
+		return decrypted, nil
+	})
+}
+
+// EncryptFile encrypts the values of an env file in place (see EncryptEnv and
+// RewriteFile)
+func EncryptFile(path, key string) (int, error) {
+	return RewriteFile(path, func(w io.Writer, r io.Reader) (int, error) {
+		return EncryptEnv(w, r, key)
+	})
+}
+
+// DecryptFile decrypts the values of an env file in place (see DecryptEnv and
+// RewriteFile)
+func DecryptFile(path, key string) (int, error) {
+	return RewriteFile(path, func(w io.Writer, r io.Reader) (int, error) {
+		return DecryptEnv(w, r, key)
+	})
+}
+
+// RewriteFile streams a file through transform into a temporary file beside it, with
+// the same permissions, and renames that over the original once it is flushed to disk.
+// The original is left untouched when transform fails. It returns the count from
+// transform.
+func RewriteFile(path string, transform func(w io.Writer, r io.Reader) (int, error)) (int, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(temp.Name()) // fails harmlessly once renamed
+
+	out := bufio.NewWriter(temp)
+	count, err := transform(out, in)
+	if err == nil {
+		err = out.Flush()
+	}
+	if err == nil {
+		err = temp.Chmod(info.Mode().Perm())
+	}
+	if err == nil {
+		err = temp.Sync()
+	}
+	if closeErr := temp.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to write temporary file: %w", closeErr)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.Rename(temp.Name(), path); err != nil {
+		return 0, fmt.Errorf("failed to replace file: %w", err)
+	}
+	return count, nil
+}
@@ -0,0 +1,147 @@
+package secrets
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecryptEnv(t *testing.T) {
+	key := "TestKey123Valid"
+	original := "# Weather API\r\nDATABASE_URL=postgres://user:pass@db/weather\r\n\r\nNWS_AGENT=agent=1\nEMPTY="
+
+	var encrypted bytes.Buffer
+	count, err := EncryptEnv(&encrypted, strings.NewReader(original), key)
+	if err != nil || count != 3 {
+		t.Fatalf("expected 3 encrypted values, got %d (%v)", count, err)
+	}
+	lines := strings.Split(encrypted.String(), "\n")
+	if len(lines) != 5 || lines[0] != "# Weather API\r" || lines[2] != "\r" {
+		t.Errorf("expected comments, blank lines and line endings to be kept, got %q", encrypted.String())
+	}
+	for _, line := range []string{lines[1], lines[3], lines[4]} {
+		if _, value, _ := strings.Cut(strings.TrimSuffix(line, "\r"), "="); !IsEncrypted(value) {
+			t.Errorf("expected an encrypted value, got %q", line)
+		}
+	}
+
+	var again bytes.Buffer
+	if count, err := EncryptEnv(&again, bytes.NewReader(encrypted.Bytes()), key); err != nil || count != 0 || again.String() != encrypted.String() {
+		t.Errorf("expected encrypted values to be left alone, got %d changes (%v)", count, err)
+	}
+
+	var decrypted bytes.Buffer
+	if count, err := DecryptEnv(&decrypted, bytes.NewReader(encrypted.Bytes()), key); err != nil || count != 3 {
+		t.Fatalf("expected 3 decrypted values, got %d (%v)", count, err)
+	}
+	if decrypted.String() != original {
+		t.Errorf("expected the round trip to restore the file, got %q", decrypted.String())
+	}
+
+	if _, err := DecryptEnv(io.Discard, bytes.NewReader(encrypted.Bytes()), "WrongKey123Valid"); err == nil || !strings.Contains(err.Error(), "DATABASE_URL") {
+		t.Errorf("expected a decryption error naming the variable, got %v", err)
+	}
+}
+
+func TestEncryptEnvDotenvSyntax(t *testing.T) {
+	key := "TestKey123Valid"
+	original := "export NWS_AGENT=\"weather-api/1.0 (ops@example.com)\" # contact\n" +
+		"PASSWORD='p#ss word'\n" +
+		"DATABASE_URL=postgres://localhost/weather # local database\n" +
+		"  GREETING = \"line one\\nline two\"\n"
+	want, err := ParseDotenv([]byte(original))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var encrypted bytes.Buffer
+	if count, err := EncryptEnv(&encrypted, strings.NewReader(original), key); err != nil || count != 4 {
+		t.Fatalf("expected 4 encrypted values, got %d (%v)", count, err)
+	}
+	for _, kept := range []string{"export NWS_AGENT=\"", "\" # contact\n", "PASSWORD='", " # local database\n", "  GREETING = \""} {
+		if !strings.Contains(encrypted.String(), kept) {
+			t.Errorf("expected %q to be kept, got %q", kept, encrypted.String())
+		}
+	}
+	values, err := ParseDotenv(encrypted.Bytes())
+	if err != nil {
+		t.Fatalf("expected the encrypted file to parse, got %v", err)
+	}
+	for name, value := range values {
+		if decrypted, err := DecryptValue(value, key); err != nil || decrypted != want[name] {
+			t.Errorf("expected %s to decrypt to %q, got %q (%v)", name, want[name], decrypted, err)
+		}
+	}
+
+	var decrypted bytes.Buffer
+	if count, err := DecryptEnv(&decrypted, bytes.NewReader(encrypted.Bytes()), key); err != nil || count != 4 {
+		t.Fatalf("expected 4 decrypted values, got %d (%v)", count, err)
+	}
+	if decrypted.String() != original {
+		t.Errorf("expected the round trip to restore the file, got %q", decrypted.String())
+	}
+
+	var quoted bytes.Buffer
+	if _, err := DecryptEnv(&quoted, strings.NewReader("NOTE="+mustEncrypt(t, "it's # here", key)+"\n"), key); err != nil || quoted.String() != "NOTE=\"it's # here\"\n" {
+		t.Errorf("expected a decrypted value needing quotes to be quoted, got %q (%v)", quoted.String(), err)
+	}
+
+	if _, err := EncryptEnv(io.Discard, strings.NewReader("VALID=1\nNO_EQUALS\n"), key); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error naming the invalid line, got %v", err)
+	}
+}
+
+func mustEncrypt(t *testing.T, value, key string) string {
+	t.Helper()
+	encrypted, err := EncryptValue(value, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return encrypted
+}
+
+func TestEncryptDecryptFile(t *testing.T) {
+	key := "TestKey123Valid"
+	original := "DATABASE_URL=postgres://db/weather\nNWS_AGENT=agent\n"
+	path := filepath.Join(t.TempDir(), ".env.local")
+	if err := os.WriteFile(path, []byte(original), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if count, err := EncryptFile(path, key); err != nil || count != 2 {
+		t.Fatalf("expected 2 encrypted values, got %d (%v)", count, err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions to be kept, got %v", info.Mode().Perm())
+	}
+	encrypted, _ := os.ReadFile(path)
+
+	if _, err := DecryptFile(path, "WrongKey123Valid"); err == nil {
+		t.Error("expected an error decrypting with the wrong key")
+	}
+	if content, _ := os.ReadFile(path); !bytes.Equal(content, encrypted) {
+		t.Error("expected a failed decryption to leave the file alone")
+	}
+
+	if count, err := DecryptFile(path, key); err != nil || count != 2 {
+		t.Fatalf("expected 2 decrypted values, got %d (%v)", count, err)
+	}
+	if content, _ := os.ReadFile(path); string(content) != original {
+		t.Errorf("expected the original content, got %q", content)
+	}
+	if matches, _ := filepath.Glob(path + ".tmp-*"); len(matches) != 0 {
+		t.Errorf("expected no temporary files left behind, got %v", matches)
+	}
+
+	if _, err := RewriteFile(filepath.Join(t.TempDir(), "missing"), func(io.Writer, io.Reader) (int, error) { return 0, nil }); err == nil {
+		t.Error("expected an error rewriting a missing file")
+	}
+	failure := errors.New("transform failed")
+	if _, err := RewriteFile(path, func(io.Writer, io.Reader) (int, error) { return 0, failure }); !errors.Is(err, failure) {
+		t.Errorf("expected the transform error, got %v", err)
+	}
+}
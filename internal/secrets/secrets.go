@@ -1,10 +1,7 @@
 package secrets
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
 	"crypto/rand"
-	"encoding/hex"
 	"fmt"
 	"io"
 	"math/big"
@@ -13,14 +10,36 @@ import (
 	"strings"
 	"syscall"
 
-	"golang.org/x/crypto/scrypt"
 	"golang.org/x/term"
+
+	"stormlightlabs.org/weather_api/internal/share"
 )
 
 // Config holds all application secrets
 type Config struct {
 	DatabaseURL string
 	NWSAgent    string
+	ECLanguage  string // language of Environment Canada forecasts: "en" (default) or "fr"
+	AdminToken  string // shared secret for the admin UI and admin-only endpoints
+	ShareSecret string // HMAC key signing public share links; sharing is disabled when empty
+
+	// TomorrowIOAPIKey enables the Tomorrow.io provider and its minutely nowcasts
+	TomorrowIOAPIKey string
+
+	// MapboxAccessToken enables geocoding with Mapbox after the Census geocoder
+	MapboxAccessToken string
+
+	// GoogleGeocodingAPIKey enables geocoding with Google after Census and Mapbox,
+	// restricted to GoogleGeocodingCountry (ISO 3166-1 alpha-2) and biased towards
+	// GoogleGeocodingBounds ("south,west|north,east") when they are set
+	GoogleGeocodingAPIKey  string
+	GoogleGeocodingCountry string
+	GoogleGeocodingBounds  string
+
+	// StorageEngine selects the repository backend: "postgres" (default) or "file"
+	// for embedded deployments that can't run PostgreSQL
+	StorageEngine string
+	StoragePath   string // data file used by the "file" engine
 }
 
 // KeyValidator validates encryption keys
@@ -86,154 +105,118 @@ func (kv *KeyValidator) ValidateKey(key string) error {
 //
 //	Priority order: CLI arg -> ENV var -> prompt
 func GetEncryptionKey(cliKey string) (string, error) {
-	validator := NewKeyValidator()
-	var key string
-
-	if cliKey != "" {
-		key = cliKey
-	} else if envKey := os.Getenv("WEATHER_API_ENCRYPTION_KEY"); envKey != "" {
-		key = envKey
-	} else {
-		var err error
-		key, err = promptForKey("Enter encryption key: ")
-		if err != nil {
-			return "", fmt.Errorf("failed to read key: %w", err)
-		}
+	key, err := ReadEncryptionKey(cliKey, "Enter encryption key: ")
+	if err != nil {
+		return "", err
 	}
 
-	if err := validator.ValidateKey(key); err != nil {
+	if err := NewKeyValidator().ValidateKey(key); err != nil {
 		return "", fmt.Errorf("key validation failed: %w", err)
 	}
 
 	return key, nil
 }
 
-// LoadConfig loads the application configuration from environment or encrypted file
-func LoadConfig() (*Config, error) {
-	config := &Config{
-		DatabaseURL: os.Getenv("DATABASE_URL"),
-		NWSAgent:    os.Getenv("NWS_AGENT"),
+// ReadEncryptionKey retrieves the encryption key like GetEncryptionKey, prompting with
+// prompt, but without validating it: keys chosen before validation still decrypt.
+func ReadEncryptionKey(cliKey, prompt string) (string, error) {
+	if cliKey != "" {
+		return cliKey, nil
 	}
-
-	if config.NWSAgent == "" {
-		config.NWSAgent = "weather-api/1.0 (https://github.com/stormlight-labs/weather-api)"
+	if envKey := os.Getenv("WEATHER_API_ENCRYPTION_KEY"); envKey != "" {
+		return envKey, nil
 	}
 
-	return config, nil
+	key, err := PromptForKey(prompt)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key: %w", err)
+	}
+	return key, nil
 }
 
-// ValidateConfig validates the loaded configuration
-func (c *Config) ValidateConfig() error {
-	if c.DatabaseURL == "" {
-		return fmt.Errorf("DATABASE_URL is required")
+// LoadConfig loads the application configuration from environment or encrypted file.
+// The dotenv files of the working directory (see LoadDotenv) are applied first, then
+// when WEATHER_API_SECRETS_URL names a secrets backend (see OpenBackend), its variables
+// are added; each is read once per process.
+func LoadConfig() (*Config, error) {
+	if err := loadDotenv(); err != nil {
+		return nil, err
 	}
-
-	if !strings.HasPrefix(c.DatabaseURL, "postgres://") && !strings.HasPrefix(c.DatabaseURL, "postgresql://") {
-		return fmt.Errorf("DATABASE_URL must be a valid PostgreSQL connection string")
+	if err := loadBackend(); err != nil {
+		return nil, err
 	}
 
-	if c.NWSAgent == "" {
-		return fmt.Errorf("NWS_AGENT is required")
-	}
+	config := &Config{
+		DatabaseURL: os.Getenv("DATABASE_URL"),
+		NWSAgent:    os.Getenv("NWS_AGENT"),
+		ECLanguage:  os.Getenv("EC_LANGUAGE"),
+		AdminToken:  os.Getenv("WEATHER_API_ADMIN_TOKEN"),
+		ShareSecret: os.Getenv("WEATHER_API_SHARE_SECRET"),
 
-	return nil
-}
+		TomorrowIOAPIKey:  os.Getenv("TOMORROW_IO_API_KEY"),
+		MapboxAccessToken: os.Getenv("MAPBOX_ACCESS_TOKEN"),
 
-// EncryptValue encrypts a single value using the provided key
-func EncryptValue(value, key string) (string, error) {
-	salt := make([]byte, 16)
-	if _, err := rand.Read(salt); err != nil {
-		return "", fmt.Errorf("failed to generate salt: %w", err)
-	}
+		GoogleGeocodingAPIKey:  os.Getenv("GOOGLE_GEOCODING_API_KEY"),
+		GoogleGeocodingCountry: os.Getenv("GOOGLE_GEOCODING_COUNTRY"),
+		GoogleGeocodingBounds:  os.Getenv("GOOGLE_GEOCODING_BOUNDS"),
 
-	derivedKey, err := scrypt.Key([]byte(key), salt, 32768, 8, 1, 32)
-	if err != nil {
-		return "", fmt.Errorf("key derivation failed: %w", err)
+		StorageEngine: os.Getenv("WEATHER_API_STORAGE_ENGINE"),
+		StoragePath:   os.Getenv("WEATHER_API_STORAGE_PATH"),
 	}
 
-	block, err := aes.NewCipher(derivedKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+	if config.StorageEngine == "" {
+		config.StorageEngine = "postgres"
 	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
-	}
-
-	nonce := make([]byte, aesGCM.NonceSize())
-	if _, err := rand.Read(nonce); err != nil {
-		return "", fmt.Errorf("failed to generate nonce: %w", err)
-	}
-
-	ciphertext := aesGCM.Seal(nil, nonce, []byte(value), nil)
-
-	// Format: salt:nonce:ciphertext (all hex encoded)
-	return fmt.Sprintf("%s:%s:%s",
-		hex.EncodeToString(salt),
-		hex.EncodeToString(nonce),
-		hex.EncodeToString(ciphertext)), nil
-}
-
-// DecryptValue decrypts a single value using the provided key
-func DecryptValue(encryptedValue, key string) (string, error) {
-	parts := strings.Split(encryptedValue, ":")
-	if len(parts) != 3 {
-		return encryptedValue, nil
+	if config.StoragePath == "" {
+		config.StoragePath = "weather-data.json"
 	}
 
-	salt, err := hex.DecodeString(parts[0])
-	if err != nil {
-		return encryptedValue, nil
+	if config.NWSAgent == "" {
+		config.NWSAgent = "weather-api/1.0 (https://github.com/stormlight-labs/weather-api)"
 	}
-
-	nonce, err := hex.DecodeString(parts[1])
-	if err != nil {
-		return encryptedValue, nil
+	if config.ECLanguage == "" {
+		config.ECLanguage = "en"
 	}
 
-	ciphertext, err := hex.DecodeString(parts[2])
-	if err != nil {
-		return encryptedValue, nil
-	}
+	return config, nil
+}
 
-	derivedKey, err := scrypt.Key([]byte(key), salt, 32768, 8, 1, 32)
-	if err != nil {
-		return "", fmt.Errorf("key derivation failed: %w", err)
-	}
+// ValidateConfig validates the loaded configuration
+func (c *Config) ValidateConfig() error {
+	switch c.StorageEngine {
+	case "", "postgres":
+		if c.DatabaseURL == "" {
+			return fmt.Errorf("DATABASE_URL is required")
+		}
 
-	block, err := aes.NewCipher(derivedKey)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
+		if !strings.HasPrefix(c.DatabaseURL, "postgres://") && !strings.HasPrefix(c.DatabaseURL, "postgresql://") {
+			return fmt.Errorf("DATABASE_URL must be a valid PostgreSQL connection string")
+		}
+	case "file":
+		if c.StoragePath == "" {
+			return fmt.Errorf("WEATHER_API_STORAGE_PATH is required for the file storage engine")
+		}
+	default:
+		return fmt.Errorf("unknown storage engine %q (supported: postgres, file)", c.StorageEngine)
 	}
 
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+	if c.NWSAgent == "" {
+		return fmt.Errorf("NWS_AGENT is required")
 	}
 
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
-	if err != nil {
-		return "", fmt.Errorf("decryption failed: %w", err)
+	if c.ECLanguage != "" && c.ECLanguage != "en" && c.ECLanguage != "fr" {
+		return fmt.Errorf("EC_LANGUAGE must be en or fr, got %q", c.ECLanguage)
 	}
 
-	return string(plaintext), nil
-}
-
-// IsEncrypted checks if a value appears to be encrypted
-func IsEncrypted(value string) bool {
-	parts := strings.Split(value, ":")
-	if len(parts) != 3 {
-		return false
+	if c.GoogleGeocodingCountry != "" && len(c.GoogleGeocodingCountry) != 2 {
+		return fmt.Errorf("GOOGLE_GEOCODING_COUNTRY must be a 2-letter country code, got %q", c.GoogleGeocodingCountry)
 	}
 
-	for _, part := range parts {
-		if _, err := hex.DecodeString(part); err != nil {
-			return false
-		}
+	if c.ShareSecret != "" && len(c.ShareSecret) < share.MinSecretLength {
+		return fmt.Errorf("WEATHER_API_SHARE_SECRET must be at least %d characters", share.MinSecretLength)
 	}
 
-	return true
+	return nil
 }
 
 // GenerateSecureKey generates a cryptographically secure key that passes validation
@@ -376,7 +359,8 @@ func ShuffleString(s string) string {
 	return string(runes)
 }
 
-func promptForKey(prompt string) (string, error) {
+// PromptForKey reads a key from the terminal without echoing it
+func PromptForKey(prompt string) (string, error) {
 	fmt.Print(prompt)
 	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 	if err != nil {
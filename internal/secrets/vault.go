@@ -0,0 +1,84 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// vaultBackend reads a HashiCorp Vault KV version 2 secret
+type vaultBackend struct {
+	client    *http.Client
+	address   string
+	token     string
+	namespace string
+	mount     string
+	path      string
+}
+
+func newVaultBackend(client *http.Client, location string) (*vaultBackend, error) {
+	mount, path, ok := strings.Cut(strings.Trim(location, "/"), "/")
+	if !ok || mount == "" || path == "" {
+		return nil, fmt.Errorf("invalid vault secrets URI: expected vault://<mount>/<path>")
+	}
+	backend := &vaultBackend{
+		client:    client,
+		address:   strings.TrimRight(os.Getenv("VAULT_ADDR"), "/"),
+		token:     os.Getenv("VAULT_TOKEN"),
+		namespace: os.Getenv("VAULT_NAMESPACE"),
+		mount:     mount,
+		path:      path,
+	}
+	if backend.address == "" || backend.token == "" {
+		return nil, fmt.Errorf("VAULT_ADDR and VAULT_TOKEN are required for vault:// secrets")
+	}
+	return backend, nil
+}
+
+// Name returns "vault"
+func (b *vaultBackend) Name() string { return "vault" }
+
+// Fetch reads the latest version of the secret
+func (b *vaultBackend) Fetch(ctx context.Context) (map[string]string, error) {
+	endpoint := b.address + "/v1/" + url.PathEscape(b.mount) + "/data/" + escapePath(b.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", b.token)
+	if b.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", b.namespace)
+	}
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	var secret struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &secret); err != nil || len(secret.Data.Data) == 0 {
+		return nil, fmt.Errorf("unexpected KV v2 response for %s/%s", b.mount, b.path)
+	}
+	return decodeSecretValues(secret.Data.Data)
+}
+
+// escapePath escapes each segment of a slash-separated path
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
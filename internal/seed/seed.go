@@ -0,0 +1,250 @@
+// Package seed populates a storage engine with the embedded development fixtures: a
+// set of world cities, well-known places and synthetic forecasts for each city, so
+// local and demo environments start from the same reproducible data.
+package seed
+
+import (
+	"context"
+	"embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/synth"
+)
+
+//go:embed fixtures
+var fixtures embed.FS
+
+// Options controls a seed run
+type Options struct {
+	Reset         bool      // empty the engine before loading
+	ForecastHours int       // hours of synthetic forecasts per seeded city, 0 for none
+	Seed          uint64    // synthetic generator seed
+	Now           time.Time // issue time of the forecasts; zero means the current hour
+}
+
+// Result counts the rows a seed run created. Fixtures already present are skipped.
+type Result struct {
+	Cities    int
+	Places    int
+	Forecasts int
+	Skipped   int
+}
+
+// Run loads the fixtures into engine. Cities are matched on their GeoNames ID and
+// places on their source place ID, so running it twice without Reset adds nothing;
+// forecasts are only generated for newly created cities.
+func Run(ctx context.Context, engine repo.Engine, opts Options) (*Result, error) {
+	cities, err := Cities()
+	if err != nil {
+		return nil, err
+	}
+	places, err := Places()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Reset {
+		if err := engine.Reset(ctx); err != nil {
+			return nil, err
+		}
+	}
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now().UTC()
+	}
+
+	result := &Result{}
+	gen := synth.NewGenerator(opts.Seed)
+	for _, city := range cities {
+		if _, err := engine.Cities().GetByGeonameID(ctx, city.GeonameID); err == nil {
+			result.Skipped++
+			continue
+		}
+		repoCity := toRepoCity(city)
+		if err := engine.Cities().Create(ctx, repoCity); err != nil {
+			return result, fmt.Errorf("failed to seed city %s: %w", city.Name, err)
+		}
+		result.Cities++
+
+		if opts.ForecastHours <= 0 {
+			continue
+		}
+		city.ID = repoCity.ID
+		generated := gen.Forecasts(city, now, opts.ForecastHours)
+		forecasts := make([]*repo.Forecast, len(generated))
+		for i, f := range generated {
+			forecasts[i] = toRepoForecast(f)
+		}
+		if err := engine.Forecasts().CreateBatch(ctx, forecasts); err != nil {
+			return result, fmt.Errorf("failed to seed forecasts for %s: %w", city.Name, err)
+		}
+		result.Forecasts += len(forecasts)
+	}
+
+	for _, place := range places {
+		if _, err := engine.Places().GetBySourcePlaceID(ctx, place.Source, place.SourcePlaceID); err == nil {
+			result.Skipped++
+			continue
+		}
+		if err := engine.Places().Create(ctx, toRepoPlace(place)); err != nil {
+			return result, fmt.Errorf("failed to seed place %s: %w", place.DisplayName, err)
+		}
+		result.Places++
+	}
+	return result, nil
+}
+
+// Cities returns the validated city fixtures from fixtures/cities.json
+func Cities() ([]*models.City, error) {
+	data, err := fixtures.ReadFile("fixtures/cities.json")
+	if err != nil {
+		return nil, err
+	}
+	var cities []*models.City
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("invalid cities fixture: %w", err)
+	}
+	for i, city := range cities {
+		city.IsActive = true
+		if err := city.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid city fixture %d (%s): %w", i, city.Name, err)
+		}
+	}
+	return cities, nil
+}
+
+// Places returns the validated place fixtures from fixtures/places.csv, whose header
+// names the columns by their JSON field names
+func Places() ([]*models.Place, error) {
+	file, err := fixtures.Open("fixtures/places.csv")
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid places fixture: %w", err)
+	}
+
+	var places []*models.Place
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid places fixture: %w", err)
+		}
+
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			row[column] = record[i]
+		}
+		place, err := parsePlace(row)
+		if err == nil {
+			err = place.Validate()
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid place fixture on line %d: %w", line, err)
+		}
+		places = append(places, place)
+	}
+	return places, nil
+}
+
+func parsePlace(row map[string]string) (*models.Place, error) {
+	place := &models.Place{
+		DisplayName:   row["display_name"],
+		AddressLine1:  row["address_line1"],
+		AddressLine2:  row["address_line2"],
+		City:          row["city"],
+		Region:        row["region"],
+		PostalCode:    row["postal_code"],
+		Country:       row["country"],
+		CountryCode:   row["country_code"],
+		PlaceType:     row["place_type"],
+		Source:        row["source"],
+		SourcePlaceID: row["source_place_id"],
+	}
+
+	var err error
+	for column, dst := range map[string]*float64{
+		"latitude":   &place.Latitude,
+		"longitude":  &place.Longitude,
+		"confidence": &place.Confidence,
+	} {
+		if *dst, err = strconv.ParseFloat(row[column], 64); err != nil {
+			return nil, fmt.Errorf("%s must be a number", column)
+		}
+	}
+	return place, nil
+}
+
+func toRepoCity(c *models.City) *repo.City {
+	return &repo.City{
+		Name:        c.Name,
+		Country:     c.Country,
+		CountryCode: c.CountryCode,
+		Region:      c.Region,
+		Latitude:    c.Latitude,
+		Longitude:   c.Longitude,
+		Elevation:   c.Elevation,
+		Population:  c.Population,
+		Timezone:    c.Timezone,
+		GeonameID:   c.GeonameID,
+		IsCapital:   c.IsCapital,
+		IsActive:    c.IsActive,
+	}
+}
+
+func toRepoPlace(p *models.Place) *repo.Place {
+	return &repo.Place{
+		DisplayName:   p.DisplayName,
+		AddressLine1:  p.AddressLine1,
+		AddressLine2:  p.AddressLine2,
+		City:          p.City,
+		Region:        p.Region,
+		PostalCode:    p.PostalCode,
+		Country:       p.Country,
+		CountryCode:   p.CountryCode,
+		Latitude:      p.Latitude,
+		Longitude:     p.Longitude,
+		PlaceType:     p.PlaceType,
+		Confidence:    p.Confidence,
+		Source:        p.Source,
+		SourcePlaceID: p.SourcePlaceID,
+	}
+}
+
+func toRepoForecast(f *models.Forecast) *repo.Forecast {
+	return &repo.Forecast{
+		CityID:          f.CityID,
+		SourceProvider:  f.SourceProvider,
+		ForecastTime:    f.ForecastTime.UTC().Format(time.RFC3339),
+		ValidTime:       f.ValidTime.UTC().Format(time.RFC3339),
+		Temperature:     f.Temperature,
+		FeelsLike:       f.FeelsLike,
+		Humidity:        f.Humidity,
+		Pressure:        f.Pressure,
+		StationPressure: f.StationPressure,
+		WindSpeed:       f.WindSpeed,
+		WindDirection:   f.WindDirection,
+		WindGust:        f.WindGust,
+		Visibility:      f.Visibility,
+		CloudCover:      f.CloudCover,
+		Precipitation:   f.Precipitation,
+		WeatherCode:     f.WeatherCode,
+		Description:     f.Description,
+		UVIndex:         f.UVIndex,
+	}
+}
@@ -0,0 +1,89 @@
+package seed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+	"stormlightlabs.org/weather_api/internal/synth"
+)
+
+func TestFixtures(t *testing.T) {
+	cities, err := Cities()
+	if err != nil {
+		t.Fatalf("Cities failed: %v", err)
+	}
+	places, err := Places()
+	if err != nil {
+		t.Fatalf("Places failed: %v", err)
+	}
+	if len(cities) == 0 || len(places) == 0 {
+		t.Fatalf("expected fixtures, got %d cities and %d places", len(cities), len(places))
+	}
+
+	geonames := make(map[int]bool)
+	for _, c := range cities {
+		if c.GeonameID == 0 || geonames[c.GeonameID] {
+			t.Errorf("expected a unique geoname_id for %s", c.Name)
+		}
+		geonames[c.GeonameID] = true
+	}
+	if p := places[0]; p.Latitude == 0 || p.Source != "seed" || p.SourcePlaceID == "" {
+		t.Errorf("unexpected parsed place %+v", p)
+	}
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	engine, err := repo.OpenFileEngine("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cities, _ := Cities()
+	places, _ := Places()
+	opts := Options{ForecastHours: 6, Seed: 1, Now: time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)}
+
+	result, err := Run(ctx, engine, opts)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if result.Cities != len(cities) || result.Places != len(places) || result.Forecasts != len(cities)*6 {
+		t.Fatalf("unexpected result %+v", result)
+	}
+
+	forecasts, err := engine.Forecasts().List(ctx, 1, 0)
+	if err != nil || len(forecasts) != 1 || forecasts[0].SourceProvider != synth.SourceProvider {
+		t.Fatalf("expected synthetic forecasts, got %+v (%v)", forecasts, err)
+	}
+
+	t.Run("idempotent", func(t *testing.T) {
+		result, err := Run(ctx, engine, opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Cities+result.Places+result.Forecasts != 0 || result.Skipped != len(cities)+len(places) {
+			t.Errorf("expected every fixture to be skipped, got %+v", result)
+		}
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		opts := opts
+		opts.Reset = true
+		result, err := Run(ctx, engine, opts)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		if result.Cities != len(cities) || result.Skipped != 0 {
+			t.Errorf("expected a fresh load after reset, got %+v", result)
+		}
+		count, _ := engine.Forecasts().Count(ctx)
+		if count != len(cities)*6 {
+			t.Errorf("expected reset to drop the earlier forecasts, got %d", count)
+		}
+		city, err := engine.Cities().GetByID(ctx, 1)
+		if err != nil || city.Name != cities[0].Name {
+			t.Errorf("expected IDs to restart after reset, got %+v (%v)", city, err)
+		}
+	})
+}
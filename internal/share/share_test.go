@@ -0,0 +1,54 @@
+package share
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "0123456789abcdef0123456789abcdef"
+
+func TestSigner(t *testing.T) {
+	signer, err := NewSigner(testSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	token := signer.Sign(42, now.Add(time.Hour))
+
+	id, err := signer.Verify(token, now)
+	if err != nil || id != 42 {
+		t.Fatalf("Verify = %d, %v", id, err)
+	}
+
+	if _, err := signer.Verify(token, now.Add(time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired at the expiry time, got %v", err)
+	}
+
+	other, _ := NewSigner(strings.Repeat("x", MinSecretLength))
+	if _, err := other.Verify(token, now); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected tokens from another secret to be rejected, got %v", err)
+	}
+}
+
+func TestSigner_Tampering(t *testing.T) {
+	signer, _ := NewSigner(testSecret)
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	token := signer.Sign(7, now.Add(time.Hour))
+	signature := token[strings.LastIndex(token, ".")+1:]
+
+	extended := signer.Sign(7, now.Add(48*time.Hour))
+	forged := strings.Join([]string{"8", strings.Split(extended, ".")[1], signature}, ".")
+	for _, bad := range []string{"", "7", "7.abc", forged, strings.Replace(token, "7.", "70.", 1), token + "x", "0." + token[2:]} {
+		if _, err := signer.Verify(bad, now); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected %q to be rejected, got %v", bad, err)
+		}
+	}
+}
+
+func TestNewSigner_ShortSecret(t *testing.T) {
+	if _, err := NewSigner("short"); err == nil {
+		t.Error("Expected short secrets to be rejected")
+	}
+}
@@ -0,0 +1,203 @@
+// Package synth generates synthetic but physically plausible forecasts for seeding,
+// sandbox mode and load tests. Temperatures follow the seasonal cycle for the latitude
+// and a diurnal cycle in local solar time; humidity, cloud cover, pressure, wind and
+// precipitation move together with a slowly varying weather regime instead of as
+// independent noise. Output is deterministic for a given seed.
+package synth
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/calc"
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/units"
+)
+
+// SourceProvider is the source_provider recorded on generated forecasts, so synthetic
+// rows can be told apart from (and deleted separately from) real provider data
+const SourceProvider = "synthetic"
+
+// Weather codes assigned to generated forecasts
+const (
+	CodeClear        = "clear"
+	CodePartlyCloudy = "partly_cloudy"
+	CodeCloudy       = "cloudy"
+	CodeRain         = "rain"
+	CodeSnow         = "snow"
+)
+
+var descriptions = map[string]string{
+	CodeClear:        "Clear",
+	CodePartlyCloudy: "Partly cloudy",
+	CodeCloudy:       "Cloudy",
+	CodeRain:         "Rain",
+	CodeSnow:         "Snow",
+}
+
+const (
+	// regimePersistence is the hour-to-hour autocorrelation of the weather regime; with
+	// 0.97 a front takes roughly a day to move through
+	regimePersistence = 0.97
+	// warmestDay is the day of the year of peak seasonal temperature in the northern
+	// hemisphere, about a month after the solstice
+	warmestDay = 200
+	// warmestHour is the local solar hour of the diurnal maximum
+	warmestHour = 15
+)
+
+// Generator produces synthetic forecasts. A Generator is not safe for concurrent use.
+type Generator struct {
+	rng *rand.Rand
+}
+
+// NewGenerator creates a generator; the same seed always yields the same forecasts
+func NewGenerator(seed uint64) *Generator {
+	return &Generator{rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+// Forecasts returns hourly forecasts for city, issued at start and valid for the
+// following hours hours. The regime carries over between hours, so consecutive hours
+// change gradually; each call starts a fresh regime.
+func (g *Generator) Forecasts(city *models.City, start time.Time, hours int) []*models.Forecast {
+	start = start.UTC().Truncate(time.Hour)
+	forecasts := make([]*models.Forecast, 0, hours)
+
+	regime := g.rng.NormFloat64() * 0.5
+	direction := g.rng.Float64() * 360
+	for i := range hours {
+		regime = clamp(regimePersistence*regime+g.rng.NormFloat64()*0.12, -1, 1)
+		direction = math.Mod(direction+g.rng.NormFloat64()*10+360, 360)
+
+		f := g.forecast(city, start, start.Add(time.Duration(i)*time.Hour), regime)
+		f.WindDirection = math.Mod(math.Round(direction), 360)
+		forecasts = append(forecasts, f)
+	}
+	return forecasts
+}
+
+// forecast derives one hour's measurements from the climate of the location and the
+// current regime, which runs from -1 (high pressure, clear and dry) to 1 (low pressure,
+// overcast and wet)
+func (g *Generator) forecast(city *models.City, issued, valid time.Time, regime float64) *models.Forecast {
+	solarHour := localSolarHour(valid, city.Longitude)
+
+	cloud := clamp(50+50*regime+g.rng.NormFloat64()*10, 0, 100)
+
+	// Clouds damp the diurnal range: overcast nights are warmer and overcast days cooler
+	diurnalRange := 10 * (1 - 0.6*cloud/100)
+	diurnal := diurnalRange / 2 * math.Cos(2*math.Pi*(solarHour-warmestHour)/24)
+	temperature := seasonalMean(city.Latitude, city.Elevation, valid) + diurnal + g.rng.NormFloat64()*0.8
+
+	// Humidity follows cloud cover and falls as the day warms
+	humidity := clamp(60+0.35*(cloud-50)-2.5*diurnal+g.rng.NormFloat64()*4, 5, 100)
+
+	var precipitation float64
+	if chance := (cloud - 70) / 40; g.rng.Float64() < chance {
+		precipitation = g.rng.ExpFloat64() * 1.5 * (cloud / 100)
+		humidity = math.Max(humidity, 85)
+	}
+
+	windSpeed := math.Max(0, 3+4*math.Abs(regime)+g.rng.NormFloat64()*1.2)
+	var windGust float64
+	if windSpeed >= 5 {
+		windGust = windSpeed * (1.3 + g.rng.Float64()*0.3)
+	}
+
+	pressure := 1013 - 18*regime + g.rng.NormFloat64()*1.5
+
+	visibility := 20.0
+	switch {
+	case precipitation > 0:
+		visibility = clamp(10-3*precipitation, 1, 10)
+	case humidity >= 97:
+		visibility = 2
+	}
+
+	code := weatherCode(cloud, precipitation, temperature)
+
+	f := &models.Forecast{
+		CityID:         city.ID,
+		SourceProvider: SourceProvider,
+		ForecastTime:   issued,
+		ValidTime:      valid,
+		Temperature:    round(temperature, 1),
+		FeelsLike:      round(calc.FeelsLike(temperature, humidity, windSpeed), 1),
+		Humidity:       math.Round(humidity),
+		Pressure:       round(pressure, 1),
+		WindSpeed:      round(windSpeed, 1),
+		WindGust:       round(windGust, 1),
+		Visibility:     round(visibility, 1),
+		CloudCover:     math.Round(cloud),
+		Precipitation:  round(precipitation, 1),
+		WeatherCode:    code,
+		Description:    descriptions[code],
+		UVIndex:        round(uvIndex(city.Latitude, valid, solarHour, cloud), 1),
+	}
+	if f.WindGust < f.WindSpeed {
+		f.WindGust = 0
+	}
+	if city.Elevation > 0 {
+		f.StationPressure = round(units.SeaLevelToStationPressure(pressure, city.Elevation, temperature), 1)
+	}
+	return f
+}
+
+// seasonalMean is the daily mean temperature in °C for a latitude, elevation and date:
+// warm and nearly constant at the equator, colder with a larger seasonal swing toward
+// the poles, mirrored between hemispheres and lowered with the standard lapse rate
+func seasonalMean(latitude, elevation float64, t time.Time) float64 {
+	absLat := math.Abs(latitude)
+	annual := 27 - 0.55*absLat
+	amplitude := 0.3 * absLat
+
+	season := math.Cos(2 * math.Pi * float64(t.YearDay()-warmestDay) / 365)
+	if latitude < 0 {
+		season = -season
+	}
+	return annual + amplitude*season - 6.5*elevation/1000
+}
+
+// localSolarHour is the hour of day at the longitude by the sun, in [0, 24)
+func localSolarHour(t time.Time, longitude float64) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60 + longitude/15
+	return math.Mod(hour+48, 24)
+}
+
+// uvIndex estimates the UV index from the solar elevation, reduced under cloud
+func uvIndex(latitude float64, t time.Time, solarHour, cloud float64) float64 {
+	toRad := math.Pi / 180
+	declination := 23.44 * math.Sin(2*math.Pi*float64(t.YearDay()-81)/365)
+	hourAngle := 15 * (solarHour - 12)
+
+	sinElevation := math.Sin(latitude*toRad)*math.Sin(declination*toRad) +
+		math.Cos(latitude*toRad)*math.Cos(declination*toRad)*math.Cos(hourAngle*toRad)
+	if sinElevation <= 0 {
+		return 0
+	}
+	return 12 * math.Pow(sinElevation, 1.5) * (1 - 0.7*cloud/100)
+}
+
+func weatherCode(cloud, precipitation, temperature float64) string {
+	switch {
+	case precipitation > 0 && temperature <= 0:
+		return CodeSnow
+	case precipitation > 0:
+		return CodeRain
+	case cloud >= 75:
+		return CodeCloudy
+	case cloud >= 25:
+		return CodePartlyCloudy
+	default:
+		return CodeClear
+	}
+}
+
+func clamp(value, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, value))
+}
+
+func round(value float64, places int) float64 {
+	return units.Round(value, places)
+}
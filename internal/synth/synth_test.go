@@ -0,0 +1,148 @@
+package synth
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+)
+
+var (
+	boston    = &models.City{ID: 1, Name: "Boston", Latitude: 42.36, Longitude: -71.06}
+	singapore = &models.City{ID: 2, Name: "Singapore", Latitude: 1.35, Longitude: 103.82}
+	sydney    = &models.City{ID: 3, Name: "Sydney", Latitude: -33.87, Longitude: 151.21}
+	denver    = &models.City{ID: 4, Name: "Denver", Latitude: 39.74, Longitude: -104.99, Elevation: 1609}
+)
+
+func TestGenerator_Deterministic(t *testing.T) {
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	a := NewGenerator(42).Forecasts(boston, start, 48)
+	b := NewGenerator(42).Forecasts(boston, start, 48)
+	if !reflect.DeepEqual(a, b) {
+		t.Error("expected identical forecasts for the same seed")
+	}
+	if c := NewGenerator(43).Forecasts(boston, start, 48); reflect.DeepEqual(a, c) {
+		t.Error("expected different forecasts for a different seed")
+	}
+}
+
+func TestGenerator_Valid(t *testing.T) {
+	start := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	gen := NewGenerator(1)
+	for _, city := range []*models.City{boston, singapore, sydney, denver} {
+		forecasts := gen.Forecasts(city, start, 24*14)
+		if len(forecasts) != 24*14 {
+			t.Fatalf("expected %d forecasts, got %d", 24*14, len(forecasts))
+		}
+		for i, f := range forecasts {
+			if err := f.Validate(); err != nil {
+				t.Fatalf("%s hour %d failed validation: %v (%+v)", city.Name, i, err, f)
+			}
+			if f.CityID != city.ID || f.SourceProvider != SourceProvider || !f.ForecastTime.Equal(start) {
+				t.Fatalf("unexpected forecast metadata %+v", f)
+			}
+			if !f.ValidTime.Equal(start.Add(time.Duration(i) * time.Hour)) {
+				t.Fatalf("expected hourly valid times, got %v at %d", f.ValidTime, i)
+			}
+			if f.Description == "" {
+				t.Fatalf("expected a description for %s", f.WeatherCode)
+			}
+		}
+	}
+}
+
+func TestGenerator_Patterns(t *testing.T) {
+	january := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	july := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	gen := NewGenerator(7)
+	days := 30 * 24
+
+	t.Run("seasons by latitude", func(t *testing.T) {
+		bostonJan := meanTemperature(gen.Forecasts(boston, january, days))
+		bostonJul := meanTemperature(gen.Forecasts(boston, july, days))
+		sydneyJan := meanTemperature(gen.Forecasts(sydney, january, days))
+		sydneyJul := meanTemperature(gen.Forecasts(sydney, july, days))
+		singaporeJan := meanTemperature(gen.Forecasts(singapore, january, days))
+
+		if bostonJul-bostonJan < 10 {
+			t.Errorf("expected Boston summers much warmer than winters, got %.1f vs %.1f", bostonJul, bostonJan)
+		}
+		if sydneyJan <= sydneyJul {
+			t.Errorf("expected southern hemisphere seasons reversed, got Jan %.1f Jul %.1f", sydneyJan, sydneyJul)
+		}
+		if singaporeJan <= bostonJan {
+			t.Errorf("expected the tropics warmer than Boston in January")
+		}
+	})
+
+	t.Run("diurnal cycle", func(t *testing.T) {
+		// Boston's solar afternoon (15h) is about 20h UTC, pre-dawn (5h) about 10h UTC and
+		// solar midnight about 5h UTC
+		forecasts := gen.Forecasts(boston, july, days)
+		var afternoon, predawn float64
+		for _, f := range forecasts {
+			switch f.ValidTime.Hour() {
+			case 20:
+				afternoon += f.Temperature
+			case 10:
+				predawn += f.Temperature
+			}
+			if f.ValidTime.Hour() == 5 && f.UVIndex != 0 {
+				t.Fatalf("expected no UV at night, got %v", f.UVIndex)
+			}
+		}
+		if afternoon <= predawn {
+			t.Errorf("expected afternoons warmer than pre-dawn hours")
+		}
+	})
+
+	t.Run("correlated fields", func(t *testing.T) {
+		var cloudy, clear []*models.Forecast
+		for _, f := range gen.Forecasts(boston, july, days) {
+			switch {
+			case f.CloudCover >= 80:
+				cloudy = append(cloudy, f)
+			case f.CloudCover <= 20:
+				clear = append(clear, f)
+			}
+		}
+		if len(cloudy) == 0 || len(clear) == 0 {
+			t.Fatalf("expected both cloudy and clear hours, got %d and %d", len(cloudy), len(clear))
+		}
+		if mean(cloudy, humidity) <= mean(clear, humidity) {
+			t.Error("expected cloudy hours more humid than clear hours")
+		}
+		if mean(cloudy, pressure) >= mean(clear, pressure) {
+			t.Error("expected lower pressure under cloud")
+		}
+		for _, f := range clear {
+			if f.Precipitation > 0 {
+				t.Fatalf("expected no precipitation under clear skies, got %+v", f)
+			}
+		}
+	})
+
+	t.Run("station pressure at elevation", func(t *testing.T) {
+		for _, f := range gen.Forecasts(denver, july, 24) {
+			if f.StationPressure <= 0 || f.StationPressure >= f.Pressure-100 {
+				t.Fatalf("expected Denver station pressure well below sea level, got %v / %v", f.StationPressure, f.Pressure)
+			}
+		}
+	})
+}
+
+func humidity(f *models.Forecast) float64 { return f.Humidity }
+func pressure(f *models.Forecast) float64 { return f.Pressure }
+
+func meanTemperature(forecasts []*models.Forecast) float64 {
+	return mean(forecasts, func(f *models.Forecast) float64 { return f.Temperature })
+}
+
+func mean(forecasts []*models.Forecast, field func(*models.Forecast) float64) float64 {
+	var sum float64
+	for _, f := range forecasts {
+		sum += field(f)
+	}
+	return sum / float64(len(forecasts))
+}
@@ -0,0 +1,208 @@
+package timing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header enables the breakdown for a request when set to "1" or "true"
+const Header = "X-Debug"
+
+// Middleware records timings for requests carrying the debug header that authorized
+// accepts, typically those with admin credentials; the header is ignored for everyone
+// else so the breakdown reveals nothing to the public.
+//
+// Debug responses are buffered so the breakdown can be added: JSON objects gain
+// meta.timings (merged into an existing meta object), and every response gets a
+// Server-Timing header. Event streams are passed through without timings.
+func Middleware(authorized func(*http.Request) bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !enabled(r.Header.Get(Header)) || !authorized(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		recorder := NewRecorder()
+		buffer := &bufferedWriter{ResponseWriter: w, header: http.Header{}, status: http.StatusOK}
+		next.ServeHTTP(buffer, r.WithContext(WithRecorder(r.Context(), recorder)))
+		if buffer.streaming {
+			return
+		}
+
+		timings := recorder.Snapshot()
+		body := buffer.body.Bytes()
+		if isJSON(buffer.header.Get("Content-Type")) {
+			if merged, ok := withTimings(body, timings); ok {
+				body = merged
+				buffer.header.Del("Content-Length")
+				buffer.header.Del("ETag") // the body no longer matches a cached copy
+			}
+		}
+		for name, values := range buffer.header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Server-Timing", serverTiming(timings))
+		w.Header().Set("Cache-Control", "no-store")
+		w.Header().Add("Vary", Header)
+		w.WriteHeader(buffer.status)
+		_, _ = w.Write(body)
+	})
+}
+
+// enabled reports whether a debug header value turns timings on
+func enabled(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// isJSON reports whether a Content-Type is JSON, including +json types
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// withTimings adds timings as meta.timings to a JSON object body. Other bodies, such
+// as arrays, are left alone and reported through Server-Timing only.
+func withTimings(body []byte, timings Timings) ([]byte, bool) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(body, &object); err != nil || object == nil {
+		return nil, false
+	}
+	meta := map[string]json.RawMessage{}
+	if existing, ok := object["meta"]; ok {
+		if err := json.Unmarshal(existing, &meta); err != nil || meta == nil {
+			return nil, false
+		}
+	}
+	encoded, err := json.Marshal(timings)
+	if err != nil {
+		return nil, false
+	}
+	meta["timings"] = encoded
+	if object["meta"], err = json.Marshal(meta); err != nil {
+		return nil, false
+	}
+	merged, err := json.Marshal(object)
+	if err != nil {
+		return nil, false
+	}
+	return append(merged, '\n'), true
+}
+
+// serverTiming formats timings as a Server-Timing header, which browser developer
+// tools display alongside the request
+func serverTiming(timings Timings) string {
+	entries := []string{
+		"total;dur=" + formatMS(timings.TotalMS),
+		"db;dur=" + formatMS(timings.DBMS),
+		"provider;dur=" + formatMS(timings.ProviderMS),
+		"geocode;dur=" + formatMS(timings.GeocodeMS),
+	}
+	for _, category := range slices.Sorted(maps.Keys(timings.Other)) {
+		entries = append(entries, category+";dur="+formatMS(timings.Other[category]))
+	}
+	entries = append(entries, fmt.Sprintf(`cache;desc="%d hits, %d misses"`, timings.CacheHits, timings.CacheMisses))
+	return strings.Join(entries, ", ")
+}
+
+func formatMS(ms float64) string {
+	return strconv.FormatFloat(ms, 'f', -1, 64)
+}
+
+// bufferedWriter holds a debug response until its timings are known. Event streams
+// cannot wait, so the first Flush sends what was buffered and streams the rest.
+type bufferedWriter struct {
+	http.ResponseWriter
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+	streaming   bool
+}
+
+func (b *bufferedWriter) Header() http.Header {
+	if b.streaming {
+		return b.ResponseWriter.Header()
+	}
+	return b.header
+}
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.streaming {
+		b.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	if b.streaming {
+		return b.ResponseWriter.Write(p)
+	}
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// Flush switches to streaming: the buffered status, headers and body are sent and
+// later writes go straight through
+func (b *bufferedWriter) Flush() {
+	if !b.streaming {
+		b.streaming = true
+		for name, values := range b.header {
+			b.ResponseWriter.Header()[name] = values
+		}
+		b.ResponseWriter.WriteHeader(b.status)
+		_, _ = b.ResponseWriter.Write(b.body.Bytes())
+		b.body.Reset()
+	}
+	if f, ok := b.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (b *bufferedWriter) Unwrap() http.ResponseWriter { return b.ResponseWriter }
+
+// Transport is an http.RoundTripper recording the time of outgoing requests under a
+// category, e.g. Geocode for a geocoder's client
+type Transport struct {
+	Category string
+
+	// Base performs the requests; nil means http.DefaultTransport
+	Base http.RoundTripper
+}
+
+// NewTransport creates a transport recording requests under category
+func NewTransport(category string, base http.RoundTripper) *Transport {
+	return &Transport{Category: category, Base: base}
+}
+
+// RoundTrip times the request until the response headers arrive
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	recorder := FromContext(req.Context())
+	if recorder == nil {
+		return base.RoundTrip(req)
+	}
+	start := time.Now()
+	resp, err := base.RoundTrip(req)
+	recorder.Add(t.Category, time.Since(start))
+	return resp, err
+}
@@ -0,0 +1,115 @@
+package timing
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMiddleware(t *testing.T) {
+	body := `{"data": {"id": 1}, "meta": {"page": 2}}`
+	handler := Middleware(func(r *http.Request) bool {
+		return r.Header.Get("Authorization") == "Bearer admin"
+	}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Add(DB, 3*time.Millisecond)
+		FromContext(r.Context()).Cache(true)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", `W/"abc"`)
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(body))
+	}))
+
+	serve := func(headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/v1/cities/1", nil)
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for name, headers := range map[string]map[string]string{
+		"no debug header": {"Authorization": "Bearer admin"},
+		"not an admin":    {"X-Debug": "1"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			w := serve(headers)
+			if w.Body.String() != body || w.Header().Get("Server-Timing") != "" {
+				t.Errorf("Expected the response untouched, got %q %v", w.Body.String(), w.Header())
+			}
+		})
+	}
+
+	w := serve(map[string]string{"X-Debug": "1", "Authorization": "Bearer admin"})
+	var response struct {
+		Data map[string]any `json:"data"`
+		Meta struct {
+			Page    int     `json:"page"`
+			Timings Timings `json:"timings"`
+		} `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Invalid JSON %q: %v", w.Body.String(), err)
+	}
+	if w.Code != http.StatusCreated || response.Data["id"] != 1.0 || response.Meta.Page != 2 {
+		t.Errorf("Expected the response to be preserved, got %d %s", w.Code, w.Body.String())
+	}
+	if response.Meta.Timings.DBMS != 3 || response.Meta.Timings.CacheHits != 1 || response.Meta.Timings.Calls[DB] != 1 {
+		t.Errorf("Unexpected timings %+v", response.Meta.Timings)
+	}
+	if !strings.Contains(w.Header().Get("Server-Timing"), "db;dur=3") || w.Header().Get("ETag") != "" || w.Header().Get("Cache-Control") != "no-store" {
+		t.Errorf("Unexpected headers %v", w.Header())
+	}
+}
+
+func TestMiddleware_NonObjects(t *testing.T) {
+	allow := func(*http.Request) bool { return true }
+	array := Middleware(allow, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte("[1,2]"))
+	}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Debug", "true")
+	w := httptest.NewRecorder()
+	array.ServeHTTP(w, req)
+	if w.Body.String() != "[1,2]" || !strings.HasPrefix(w.Header().Get("Server-Timing"), "total;dur=") {
+		t.Errorf("Expected arrays to get the header only, got %q %v", w.Body.String(), w.Header())
+	}
+
+	stream := Middleware(allow, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("data: 1\n\n"))
+		w.(http.Flusher).Flush()
+		_, _ = w.Write([]byte("data: 2\n\n"))
+	}))
+	w = httptest.NewRecorder()
+	stream.ServeHTTP(w, req)
+	if w.Body.String() != "data: 1\n\ndata: 2\n\n" || !w.Flushed || w.Header().Get("Content-Type") != "text/event-stream" {
+		t.Errorf("Expected streams to pass through, got %q %v", w.Body.String(), w.Header())
+	}
+}
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(2 * time.Millisecond)
+	}))
+	defer server.Close()
+
+	recorder := NewRecorder()
+	client := &http.Client{Transport: NewTransport(Geocode, nil)}
+	req, _ := http.NewRequestWithContext(WithRecorder(t.Context(), recorder), "GET", server.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	timings := recorder.Snapshot()
+	if timings.Calls[Geocode] != 1 || timings.GeocodeMS < 2 {
+		t.Errorf("Expected the request to be recorded, got %+v", timings)
+	}
+}
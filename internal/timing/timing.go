@@ -0,0 +1,133 @@
+// Package timing breaks a request's latency down by where it was spent, for support
+// requests about slow responses.
+//
+// Middleware attaches a Recorder to requests from admins carrying "X-Debug: 1".
+// Instrumented layers (database queries, provider HTTP calls, cache reads) report into
+// the Recorder found in their context, and the totals are returned as meta.timings in
+// JSON object responses and as a Server-Timing header. Requests without a Recorder pay
+// only for a context lookup.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Categories of recorded time
+const (
+	DB       = "db"
+	Geocode  = "geocode"
+	Provider = "provider"
+)
+
+// Recorder accumulates the time spent per category and cache outcomes for one request.
+// It is safe for concurrent use, as providers may be queried in parallel. A nil
+// Recorder records nothing.
+type Recorder struct {
+	mu          sync.Mutex
+	start       time.Time
+	durations   map[string]time.Duration
+	counts      map[string]int
+	cacheHits   int
+	cacheMisses int
+}
+
+// NewRecorder creates a recorder measuring from now
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now(), durations: map[string]time.Duration{}, counts: map[string]int{}}
+}
+
+type contextKey struct{}
+
+// WithRecorder returns ctx carrying recorder
+func WithRecorder(ctx context.Context, recorder *Recorder) context.Context {
+	return context.WithValue(ctx, contextKey{}, recorder)
+}
+
+// FromContext returns the recorder in ctx, or nil
+func FromContext(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(contextKey{}).(*Recorder)
+	return recorder
+}
+
+// Add records one operation of category taking d
+func (r *Recorder) Add(category string, d time.Duration) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.durations[category] += d
+	r.counts[category]++
+}
+
+// Cache records a cache read and whether it hit
+func (r *Recorder) Cache(hit bool) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if hit {
+		r.cacheHits++
+	} else {
+		r.cacheMisses++
+	}
+}
+
+// Track starts timing an operation of category in the recorder of ctx and returns the
+// function ending it, for use with defer
+func Track(ctx context.Context, category string) func() {
+	recorder := FromContext(ctx)
+	if recorder == nil {
+		return func() {}
+	}
+	start := time.Now()
+	return func() { recorder.Add(category, time.Since(start)) }
+}
+
+// Timings is the breakdown returned to clients. Durations are milliseconds; time spent
+// in concurrent operations is summed, so categories may add up to more than TotalMS.
+type Timings struct {
+	TotalMS     float64            `json:"total_ms"`
+	GeocodeMS   float64            `json:"geocode_ms"`
+	ProviderMS  float64            `json:"provider_ms"`
+	DBMS        float64            `json:"db_ms"`
+	Calls       map[string]int     `json:"calls"`
+	CacheHits   int                `json:"cache_hits"`
+	CacheMisses int                `json:"cache_misses"`
+	Other       map[string]float64 `json:"other_ms,omitempty"`
+}
+
+// Snapshot returns the totals recorded so far
+func (r *Recorder) Snapshot() Timings {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	timings := Timings{
+		TotalMS:     milliseconds(time.Since(r.start)),
+		GeocodeMS:   milliseconds(r.durations[Geocode]),
+		ProviderMS:  milliseconds(r.durations[Provider]),
+		DBMS:        milliseconds(r.durations[DB]),
+		Calls:       make(map[string]int, len(r.counts)),
+		CacheHits:   r.cacheHits,
+		CacheMisses: r.cacheMisses,
+	}
+	for category, count := range r.counts {
+		timings.Calls[category] = count
+		switch category {
+		case DB, Geocode, Provider:
+		default:
+			if timings.Other == nil {
+				timings.Other = map[string]float64{}
+			}
+			timings.Other[category] = milliseconds(r.durations[category])
+		}
+	}
+	return timings
+}
+
+// milliseconds rounds d to microsecond precision in milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
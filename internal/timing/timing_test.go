@@ -0,0 +1,51 @@
+package timing
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder()
+	ctx := WithRecorder(context.Background(), recorder)
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			recorder.Add(Provider, 10*time.Millisecond)
+		}()
+	}
+	wg.Wait()
+	recorder.Add(Geocode, 1500*time.Microsecond)
+	recorder.Add("search", 2*time.Millisecond)
+	recorder.Cache(true)
+	recorder.Cache(false)
+	recorder.Cache(false)
+	end := Track(ctx, DB)
+	end()
+
+	timings := recorder.Snapshot()
+	if timings.ProviderMS != 40 || timings.GeocodeMS != 1.5 || timings.Other["search"] != 2 {
+		t.Errorf("Unexpected durations %+v", timings)
+	}
+	if timings.Calls[Provider] != 4 || timings.Calls[DB] != 1 || timings.CacheHits != 1 || timings.CacheMisses != 2 {
+		t.Errorf("Unexpected counts %+v", timings)
+	}
+	if _, ok := timings.Other[DB]; ok {
+		t.Error("Expected known categories to stay out of other_ms")
+	}
+}
+
+func TestNilRecorder(t *testing.T) {
+	ctx := context.Background()
+	if FromContext(ctx) != nil {
+		t.Fatal("Expected no recorder outside debug requests")
+	}
+	FromContext(ctx).Add(DB, time.Second)
+	FromContext(ctx).Cache(true)
+	Track(ctx, DB)()
+}
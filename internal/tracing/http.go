@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware records a server span for every request, continuing the caller's trace
+// when the request carries a traceparent header. Spans are named after the matched
+// route pattern when next is (or wraps) a ServeMux.
+func Middleware(next http.Handler) http.Handler {
+	return otelhttp.NewHandler(next, "http.server", otelhttp.WithSpanNameFormatter(serverSpanName))
+}
+
+// serverSpanName returns "GET /cities/{id}" for a request routed by a ServeMux, whose
+// patterns may be prefixed with a method and host, and the bare method otherwise
+func serverSpanName(_ string, r *http.Request) string {
+	if i := strings.Index(r.Pattern, "/"); i >= 0 {
+		return r.Method + " " + r.Pattern[i:]
+	}
+	return r.Method
+}
+
+// NewTransport returns an http.RoundTripper recording a client span named after the
+// provider for every outgoing request and propagating the trace to the remote service.
+// A nil base means http.DefaultTransport. URLs are recorded without their query
+// strings, which may carry API keys.
+func NewTransport(provider string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(redactingTransport{base},
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string { return r.Method }),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("provider.name", provider))),
+	)
+}
+
+// redactingTransport replaces the full URL otelhttp records on the client span with
+// one stripped of credentials and query string
+type redactingTransport struct {
+	base http.RoundTripper
+}
+
+func (t redactingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if span := trace.SpanFromContext(req.Context()); span.IsRecording() {
+		u := *req.URL
+		u.User = nil
+		u.RawQuery = ""
+		u.ForceQuery = false
+		u.Fragment = ""
+		span.SetAttributes(semconv.URLFull(u.String()))
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,116 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// installRecorder installs a tracer provider recording ended spans for the test
+func installRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(provider)
+		otel.SetTextMapPropagator(propagator)
+	})
+	return recorder
+}
+
+// spanNamed returns the ended span called name, or nil
+func spanNamed(recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	return nil
+}
+
+// attributeOf returns the value recorded under key, or an invalid value
+func attributeOf(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := installRecorder(t)
+
+	var handlerSpan trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /cities/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerSpan = trace.SpanFromContext(r.Context()).SpanContext()
+		w.WriteHeader(http.StatusNotFound)
+		w.(http.Flusher).Flush()
+	})
+
+	req := httptest.NewRequest("GET", "/cities/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	Middleware(mux).ServeHTTP(httptest.NewRecorder(), req)
+
+	span := spanNamed(recorder, "GET /cities/{id}")
+	if span == nil || span.SpanContext().SpanID() != handlerSpan.SpanID() {
+		t.Fatal("Expected the handler to see a server span named after its route")
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || span.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Error("Expected the caller's trace to continue")
+	}
+	if span.SpanKind() != trace.SpanKindServer || attributeOf(span, "http.response.status_code").AsInt64() != http.StatusNotFound {
+		t.Errorf("Unexpected span %v %v", span.SpanKind(), span.Attributes())
+	}
+	if span.Status().Code == codes.Error {
+		t.Error("Expected client errors not to fail the span")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	recorder := installRecorder(t)
+
+	var traceparent string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: NewTransport("NWS", nil)}
+	ctx, parent := Tracer().Start(context.Background(), "fetch")
+	req, _ := http.NewRequestWithContext(ctx, "GET", upstream.URL+"/points/1,2?key=secret", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	span := spanNamed(recorder, "GET")
+	if span == nil || span.SpanKind() != trace.SpanKindClient || span.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatal("Expected a client span under the caller's span")
+	}
+	want := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	if traceparent != want {
+		t.Errorf("Expected upstream traceparent %q, got %q", want, traceparent)
+	}
+	if attributeOf(span, "provider.name").AsString() != "NWS" || attributeOf(span, "url.full").AsString() != upstream.URL+"/points/1,2" {
+		t.Errorf("Expected the provider and a URL without its query, got %v", span.Attributes())
+	}
+	if span.Status().Code != codes.Error || req.Header.Get("traceparent") != "" {
+		t.Error("Expected a failed span and the caller's request left untouched")
+	}
+}
@@ -0,0 +1,109 @@
+// Package tracing sets up OpenTelemetry tracing and exports traces to a collector over
+// OTLP/HTTP.
+//
+// Setup installs the global tracer provider and the W3C Trace Context propagator, so
+// the server, provider HTTP clients and repository queries of one request share a
+// trace. Until Setup runs, the global provider records nothing, so instrumented code
+// needs no checks of its own.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// DefaultServiceName is reported when OTEL_SERVICE_NAME is not set
+const DefaultServiceName = "weather-api"
+
+// instrumentationScope names this package's tracer in exported spans
+const instrumentationScope = "stormlightlabs.org/weather_api/internal/tracing"
+
+// Config selects where traces are exported. It is read from the standard
+// OpenTelemetry environment variables.
+type Config struct {
+	// Endpoint is the OTLP/HTTP traces URL; tracing is disabled when empty
+	Endpoint string
+
+	ServiceName string
+}
+
+// Enabled reports whether traces should be exported
+func (c Config) Enabled() bool { return c.Endpoint != "" }
+
+// LoadConfig reads the tracing configuration from the environment:
+//
+//	OTEL_TRACES_EXPORTER                 "otlp" (default) or "none"
+//	OTEL_EXPORTER_OTLP_TRACES_ENDPOINT   traces URL, used as is
+//	OTEL_EXPORTER_OTLP_ENDPOINT          collector base URL; "/v1/traces" is appended
+//	OTEL_SERVICE_NAME                    defaults to DefaultServiceName
+//
+// The exporter reads the remaining OTEL_EXPORTER_OTLP_* variables, such as headers,
+// compression and timeouts, itself.
+func LoadConfig() (Config, error) {
+	cfg := Config{ServiceName: os.Getenv("OTEL_SERVICE_NAME")}
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = DefaultServiceName
+	}
+
+	switch exporter := os.Getenv("OTEL_TRACES_EXPORTER"); exporter {
+	case "", "otlp":
+	case "none":
+		return cfg, nil
+	default:
+		return cfg, fmt.Errorf("unsupported OTEL_TRACES_EXPORTER %q", exporter)
+	}
+
+	if endpoint := os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"); endpoint != "" {
+		cfg.Endpoint = endpoint
+	} else if base := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT"); base != "" {
+		cfg.Endpoint = strings.TrimSuffix(base, "/") + "/v1/traces"
+	}
+	if cfg.Endpoint != "" {
+		if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return cfg, fmt.Errorf("invalid OTLP endpoint %q", cfg.Endpoint)
+		}
+	}
+	return cfg, nil
+}
+
+// Setup installs a tracer provider exporting batches of spans to the configured
+// collector, and the propagator carrying traces across services as traceparent
+// headers. onError, when not nil, is called with export failures. It returns nil when
+// tracing is disabled; callers shut the provider down to flush the remaining spans.
+func Setup(cfg Config, onError func(error)) (*sdktrace.TracerProvider, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	exporter, err := otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(cfg.ServiceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to describe the service: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	if onError != nil {
+		otel.SetErrorHandler(otel.ErrorHandlerFunc(onError))
+	}
+	return provider, nil
+}
+
+// Tracer returns the tracer of the application's own spans, such as repository queries
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationScope)
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("disabled by default", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "")
+		cfg, err := LoadConfig()
+		if err != nil || cfg.Enabled() || cfg.ServiceName != DefaultServiceName {
+			t.Errorf("Expected tracing disabled, got %+v (%v)", cfg, err)
+		}
+	})
+
+	t.Run("base endpoint", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318/")
+		t.Setenv("OTEL_SERVICE_NAME", "weather-api-staging")
+		cfg, err := LoadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.Endpoint != "http://collector:4318/v1/traces" || cfg.ServiceName != "weather-api-staging" {
+			t.Errorf("Unexpected config %+v", cfg)
+		}
+	})
+
+	t.Run("exporter none", func(t *testing.T) {
+		t.Setenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT", "http://collector:4318/v1/traces")
+		t.Setenv("OTEL_TRACES_EXPORTER", "none")
+		if cfg, err := LoadConfig(); err != nil || cfg.Enabled() {
+			t.Errorf("Expected tracing disabled, got %+v (%v)", cfg, err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for name, value := range map[string]string{
+			"OTEL_TRACES_EXPORTER":               "zipkin",
+			"OTEL_EXPORTER_OTLP_TRACES_ENDPOINT": "collector:4318",
+		} {
+			t.Run(name, func(t *testing.T) {
+				t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "http://collector:4318")
+				t.Setenv(name, value)
+				if _, err := LoadConfig(); err == nil {
+					t.Errorf("Expected %s=%q to be rejected", name, value)
+				}
+			})
+		}
+	})
+}
+
+func TestSetup(t *testing.T) {
+	if provider, err := Setup(Config{ServiceName: DefaultServiceName}, nil); provider != nil || err != nil {
+		t.Fatalf("Expected no provider while disabled, got %v (%v)", provider, err)
+	}
+
+	previous, propagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	t.Cleanup(func() {
+		otel.SetTracerProvider(previous)
+		otel.SetTextMapPropagator(propagator)
+	})
+	provider, err := Setup(Config{Endpoint: "http://127.0.0.1:1/v1/traces", ServiceName: "weather-api-test"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otel.GetTracerProvider() != provider {
+		t.Error("Expected the provider to be installed globally")
+	}
+	if fields := otel.GetTextMapPropagator().Fields(); !slices.Contains(fields, "traceparent") {
+		t.Errorf("Expected the W3C Trace Context propagator, got %v", fields)
+	}
+	// Nothing was recorded, so shutting down does not reach the unreachable collector
+	if err := provider.Shutdown(context.Background()); err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
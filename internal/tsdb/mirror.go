@@ -0,0 +1,266 @@
+package tsdb
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// ForecastFields are the forecast values mirrored, named like their repository columns
+var ForecastFields = []string{
+	"temperature", "feels_like", "humidity", "pressure", "station_pressure", "wind_speed",
+	"wind_direction", "wind_gust", "visibility", "cloud_cover", "precipitation", "uv_index",
+}
+
+// ObservationFields are the METAR values mirrored, named like their repository columns
+var ObservationFields = []string{
+	"temperature", "dewpoint", "wind_direction", "wind_speed", "wind_gust", "visibility", "pressure",
+}
+
+// Mirror copies forecast and observation writes to a sink. Points are queued and
+// written in batches from a background goroutine; when the queue is full, new points
+// are dropped rather than slowing writes down.
+type Mirror struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	onError       func(error)
+
+	queue   chan Point
+	flush   chan chan struct{}
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+	dropped atomic.Int64
+}
+
+// NewMirror starts a mirror writing to sink. onError, when not nil, is called with
+// write failures.
+func NewMirror(sink Sink, config Config, onError func(error)) *Mirror {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 500
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 10 * time.Second
+	}
+	m := &Mirror{
+		sink:          sink,
+		batchSize:     config.BatchSize,
+		flushInterval: config.FlushInterval,
+		onError:       onError,
+		queue:         make(chan Point, 10*config.BatchSize),
+		flush:         make(chan chan struct{}),
+		done:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// Register subscribes the mirror to forecast and aviation report writes. Deletes are
+// not mirrored: the time-series database keeps history beyond the primary's retention.
+func (m *Mirror) Register(hooks *repo.EngineHooks) {
+	hooks.Forecasts.Register(repo.Hook[repo.Forecast]{
+		Name: "tsdb",
+		After: func(ctx context.Context, mutation repo.Mutation[repo.Forecast]) {
+			if mutation.Entity == nil {
+				return
+			}
+			if point, ok := ForecastPoint(mutation.Entity); ok {
+				m.Enqueue(point)
+			}
+		},
+	})
+	hooks.Aviation.Register(repo.Hook[repo.AviationReport]{
+		Name: "tsdb",
+		After: func(ctx context.Context, mutation repo.Mutation[repo.AviationReport]) {
+			if mutation.Entity == nil {
+				return
+			}
+			if point, ok := ObservationPoint(mutation.Entity); ok {
+				m.Enqueue(point)
+			}
+		},
+	})
+}
+
+// Dropped returns how many points were discarded because the queue was full
+func (m *Mirror) Dropped() int64 { return m.dropped.Load() }
+
+// Enqueue queues points for writing
+func (m *Mirror) Enqueue(points ...Point) {
+	for _, point := range points {
+		select {
+		case <-m.done:
+			m.dropped.Add(1)
+			continue
+		default:
+		}
+		select {
+		case m.queue <- point:
+		default:
+			m.dropped.Add(1)
+		}
+	}
+}
+
+// Flush writes every queued point
+func (m *Mirror) Flush(ctx context.Context) error {
+	ack := make(chan struct{})
+	select {
+	case m.flush <- ack:
+	case <-m.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close writes the remaining points, stops the mirror and closes the sink
+func (m *Mirror) Close(ctx context.Context) error {
+	m.once.Do(func() { close(m.done) })
+	select {
+	case <-m.stopped:
+		return m.sink.Close()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *Mirror) run() {
+	defer close(m.stopped)
+	ticker := time.NewTicker(m.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Point, 0, m.batchSize)
+	write := func() {
+		if len(batch) == 0 {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := m.sink.Write(ctx, batch); err != nil && m.onError != nil {
+			m.onError(err)
+		}
+		batch = make([]Point, 0, m.batchSize)
+	}
+	drain := func() {
+		for {
+			select {
+			case point := <-m.queue:
+				if batch = append(batch, point); len(batch) == m.batchSize {
+					write()
+				}
+			default:
+				write()
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case point := <-m.queue:
+			if batch = append(batch, point); len(batch) == m.batchSize {
+				write()
+			}
+		case <-ticker.C:
+			write()
+		case ack := <-m.flush:
+			drain()
+			close(ack)
+		case <-m.done:
+			drain()
+			return
+		}
+	}
+}
+
+// ForecastPoint converts a forecast to a point at its valid time, tagged with its city,
+// provider and issue time. Gusts and station pressure are omitted when not reported.
+func ForecastPoint(forecast *repo.Forecast) (Point, bool) {
+	valid, err := time.Parse(time.RFC3339, forecast.ValidTime)
+	if err != nil {
+		return Point{}, false
+	}
+	issued := valid
+	if t, err := time.Parse(time.RFC3339, forecast.ForecastTime); err == nil {
+		issued = t
+	}
+
+	fields := map[string]float64{
+		"temperature":    forecast.Temperature,
+		"feels_like":     forecast.FeelsLike,
+		"humidity":       forecast.Humidity,
+		"pressure":       forecast.Pressure,
+		"wind_speed":     forecast.WindSpeed,
+		"wind_direction": forecast.WindDirection,
+		"visibility":     forecast.Visibility,
+		"cloud_cover":    forecast.CloudCover,
+		"precipitation":  forecast.Precipitation,
+		"uv_index":       forecast.UVIndex,
+	}
+	if forecast.WindGust != 0 {
+		fields["wind_gust"] = forecast.WindGust
+	}
+	if forecast.StationPressure != 0 {
+		fields["station_pressure"] = forecast.StationPressure
+	}
+	return Point{
+		Measurement: MeasurementForecast,
+		Tags: map[string]string{
+			"city_id":   strconv.Itoa(forecast.CityID),
+			"provider":  forecast.SourceProvider,
+			"issued_at": issued.UTC().Format(time.RFC3339),
+		},
+		Fields: fields,
+		Time:   valid,
+	}, true
+}
+
+// ObservationPoint converts a METAR to a point at its observation time, tagged with its
+// station and provider. TAFs are forecasts, not observations, and are skipped.
+func ObservationPoint(report *repo.AviationReport) (Point, bool) {
+	if report.ReportType != models.ReportTypeMETAR {
+		return Point{}, false
+	}
+	observed, err := time.Parse(time.RFC3339, report.ObservedAt)
+	if err != nil {
+		return Point{}, false
+	}
+
+	fields := map[string]float64{}
+	for name, value := range map[string]*float64{
+		"temperature":    report.Temperature,
+		"dewpoint":       report.Dewpoint,
+		"wind_direction": report.WindDirection,
+		"wind_speed":     report.WindSpeed,
+		"wind_gust":      report.WindGust,
+		"visibility":     report.Visibility,
+		"pressure":       report.Pressure,
+	} {
+		if value != nil {
+			fields[name] = *value
+		}
+	}
+	if len(fields) == 0 {
+		return Point{}, false
+	}
+	return Point{
+		Measurement: MeasurementObservation,
+		Tags:        map[string]string{"station_id": report.StationID, "provider": report.SourceProvider},
+		Fields:      fields,
+		Time:        observed,
+	}, true
+}
@@ -0,0 +1,113 @@
+package tsdb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/models"
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// memorySink collects written batches
+type memorySink struct {
+	mu      sync.Mutex
+	batches [][]Point
+	err     error
+	closed  bool
+}
+
+func (s *memorySink) Name() string { return "memory" }
+
+func (s *memorySink) Write(ctx context.Context, points []Point) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.batches = append(s.batches, points)
+	return s.err
+}
+
+func (s *memorySink) Close() error {
+	s.closed = true
+	return nil
+}
+
+func (s *memorySink) points() []Point {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var all []Point
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+func TestMirror(t *testing.T) {
+	ctx := context.Background()
+	sink := &memorySink{}
+	mirror := NewMirror(sink, Config{BatchSize: 2, FlushInterval: time.Hour}, nil)
+
+	file, _ := repo.OpenFileEngine("")
+	hooks := &repo.EngineHooks{}
+	mirror.Register(hooks)
+	engine := repo.NewHookedEngine(file, hooks)
+
+	valid := time.Now().UTC().Truncate(time.Second)
+	forecasts := []*repo.Forecast{
+		{CityID: 1, SourceProvider: "nws", ValidTime: valid.Format(time.RFC3339), Temperature: 10},
+		{CityID: 2, SourceProvider: "nws", ValidTime: valid.Format(time.RFC3339), Temperature: 20, WindGust: 15},
+		{CityID: 3, SourceProvider: "nws", ValidTime: "not a time"},
+	}
+	if err := engine.Forecasts().CreateBatch(ctx, forecasts); err != nil {
+		t.Fatal(err)
+	}
+	temperature := 4.0
+	_ = engine.Aviation().Upsert(ctx, &repo.AviationReport{StationID: "KPDX", ReportType: models.ReportTypeMETAR, ObservedAt: valid.Format(time.RFC3339), Temperature: &temperature})
+	_ = engine.Aviation().Upsert(ctx, &repo.AviationReport{StationID: "KPDX", ReportType: models.ReportTypeTAF, ObservedAt: valid.Format(time.RFC3339), Temperature: &temperature})
+	_ = engine.Forecasts().Delete(ctx, forecasts[0].ID)
+
+	if err := mirror.Flush(ctx); err != nil {
+		t.Fatal(err)
+	}
+	points := sink.points()
+	if len(points) != 3 {
+		t.Fatalf("Expected two forecasts and one METAR, got %+v", points)
+	}
+	if len(sink.batches[0]) != 2 {
+		t.Errorf("Expected a full batch to be written before the flush, got %d points", len(sink.batches[0]))
+	}
+	if points[0].Tags["city_id"] != "1" || !points[0].Time.Equal(valid) || points[0].Tags["issued_at"] != valid.Format(time.RFC3339) {
+		t.Errorf("Unexpected forecast point %+v", points[0])
+	}
+	if _, ok := points[0].Fields["wind_gust"]; ok {
+		t.Error("Expected an unreported gust to be omitted")
+	}
+	if points[1].Fields["wind_gust"] != 15 {
+		t.Errorf("Expected a reported gust, got %+v", points[1].Fields)
+	}
+	if points[2].Measurement != MeasurementObservation || points[2].Fields["temperature"] != 4 || len(points[2].Fields) != 1 {
+		t.Errorf("Unexpected observation point %+v", points[2])
+	}
+
+	if err := mirror.Close(ctx); err != nil || !sink.closed {
+		t.Errorf("Expected Close to close the sink, got %v", err)
+	}
+	mirror.Enqueue(points[0])
+	if mirror.Dropped() != 1 {
+		t.Errorf("Expected points enqueued after Close to be dropped, got %d", mirror.Dropped())
+	}
+}
+
+func TestMirror_Errors(t *testing.T) {
+	sink := &memorySink{err: errors.New("unreachable")}
+	var errs []error
+	mirror := NewMirror(sink, Config{BatchSize: 10, FlushInterval: time.Hour}, func(err error) { errs = append(errs, err) })
+	mirror.Enqueue(Point{Measurement: MeasurementForecast, Fields: map[string]float64{"temperature": 1}})
+	if err := mirror.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected the write failure to be reported, got %v", errs)
+	}
+}
@@ -0,0 +1,173 @@
+package tsdb
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"stormlightlabs.org/weather_api/internal/repo"
+)
+
+// column is a tag column of a hypertable
+type column struct {
+	name    string
+	sqlType string
+}
+
+// series maps a measurement to its hypertable. Rows are unique per key, so a point
+// written again (e.g. an updated forecast) replaces its fields.
+type series struct {
+	table  string
+	tags   []column
+	fields []string
+	key    []string
+}
+
+// hypertables are the tables written by TimescaleSink, one per measurement
+var hypertables = map[string]series{
+	MeasurementForecast: {
+		table:  "forecast_series",
+		tags:   []column{{"city_id", "INTEGER NOT NULL"}, {"provider", "TEXT NOT NULL"}, {"issued_at", "TIMESTAMPTZ NOT NULL"}},
+		fields: ForecastFields,
+		key:    []string{"city_id", "provider", "issued_at", "time"},
+	},
+	MeasurementObservation: {
+		table:  "observation_series",
+		tags:   []column{{"station_id", "TEXT NOT NULL"}, {"provider", "TEXT NOT NULL"}},
+		fields: ObservationFields,
+		key:    []string{"station_id", "provider", "time"},
+	},
+}
+
+// TimescaleSink writes points into TimescaleDB hypertables, one per measurement, with a
+// column per tag and field so dashboards can aggregate with time_bucket directly
+type TimescaleSink struct {
+	db repo.DB
+}
+
+// NewTimescaleSink creates a sink writing through db
+func NewTimescaleSink(db repo.DB) *TimescaleSink {
+	return &TimescaleSink{db: db}
+}
+
+// Name returns BackendTimescale
+func (s *TimescaleSink) Name() string { return BackendTimescale }
+
+// EnsureSchema creates the hypertables and their unique indexes unless they exist. The
+// timescaledb extension must already be installed in the database.
+func (s *TimescaleSink) EnsureSchema(ctx context.Context) error {
+	for _, measurement := range []string{MeasurementForecast, MeasurementObservation} {
+		series := hypertables[measurement]
+		columns := []string{"time TIMESTAMPTZ NOT NULL"}
+		for _, tag := range series.tags {
+			columns = append(columns, tag.name+" "+tag.sqlType)
+		}
+		for _, field := range series.fields {
+			columns = append(columns, field+" DOUBLE PRECISION")
+		}
+		statements := []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (%s)", series.table, strings.Join(columns, ", ")),
+			fmt.Sprintf("SELECT create_hypertable('%s', 'time', if_not_exists => TRUE)", series.table),
+			fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s_key ON %s (%s)", series.table, series.table, strings.Join(series.key, ", ")),
+		}
+		for _, statement := range statements {
+			if _, err := s.db.ExecContext(ctx, statement); err != nil {
+				return fmt.Errorf("failed to create hypertable %s: %w", series.table, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Write inserts the points of each measurement in one statement. Points of unknown
+// measurements are skipped.
+func (s *TimescaleSink) Write(ctx context.Context, points []Point) error {
+	byMeasurement := make(map[string][]Point)
+	for _, point := range points {
+		if _, ok := hypertables[point.Measurement]; ok {
+			byMeasurement[point.Measurement] = append(byMeasurement[point.Measurement], point)
+		}
+	}
+	for _, measurement := range []string{MeasurementForecast, MeasurementObservation} {
+		if len(byMeasurement[measurement]) == 0 {
+			continue
+		}
+		query, args := upsertQuery(hypertables[measurement], byMeasurement[measurement])
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to write %s points: %w", measurement, err)
+		}
+	}
+	return nil
+}
+
+// Close closes the database when the sink owns it
+func (s *TimescaleSink) Close() error {
+	if closer, ok := s.db.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// upsertQuery builds a multi-row INSERT ... ON CONFLICT DO UPDATE for points. Points
+// sharing a key are reduced to the last, since one statement cannot update a row twice.
+func upsertQuery(series series, points []Point) (string, []any) {
+	columns := []string{"time"}
+	for _, tag := range series.tags {
+		columns = append(columns, tag.name)
+	}
+	columns = append(columns, series.fields...)
+
+	latest := make(map[string]int)
+	order := []string{}
+	for i, point := range points {
+		key := seriesKey(series, point)
+		if _, ok := latest[key]; !ok {
+			order = append(order, key)
+		}
+		latest[key] = i
+	}
+
+	var rows []string
+	var args []any
+	for _, key := range order {
+		point := points[latest[key]]
+		placeholders := make([]string, len(columns))
+		args = append(args, point.Time.UTC().Format(time.RFC3339))
+		for _, tag := range series.tags {
+			args = append(args, point.Tags[tag.name])
+		}
+		for _, field := range series.fields {
+			if value, ok := point.Fields[field]; ok {
+				args = append(args, value)
+			} else {
+				args = append(args, nil)
+			}
+		}
+		for i := range placeholders {
+			placeholders[i] = fmt.Sprintf("$%d", len(args)-len(columns)+i+1)
+		}
+		rows = append(rows, "("+strings.Join(placeholders, ", ")+")")
+	}
+
+	updates := make([]string, len(series.fields))
+	for i, field := range series.fields {
+		updates[i] = field + " = EXCLUDED." + field
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s ON CONFLICT (%s) DO UPDATE SET %s",
+		series.table, strings.Join(columns, ", "), strings.Join(rows, ", "),
+		strings.Join(series.key, ", "), strings.Join(updates, ", "))
+	return query, args
+}
+
+// seriesKey identifies the row a point is written to
+func seriesKey(series series, point Point) string {
+	parts := []string{point.Time.UTC().Format(time.RFC3339)}
+	for _, name := range series.key {
+		if name != "time" {
+			parts = append(parts, point.Tags[name])
+		}
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,81 @@
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingDB records executed statements
+type recordingDB struct {
+	statements []string
+	args       [][]any
+	err        error
+}
+
+func (d *recordingDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (d *recordingDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+func (d *recordingDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	d.statements = append(d.statements, query)
+	d.args = append(d.args, args)
+	return nil, d.err
+}
+
+func TestTimescaleSink_EnsureSchema(t *testing.T) {
+	db := &recordingDB{}
+	if err := NewTimescaleSink(db).EnsureSchema(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	all := strings.Join(db.statements, ";\n")
+	for _, want := range []string{
+		"CREATE TABLE IF NOT EXISTS forecast_series (time TIMESTAMPTZ NOT NULL, city_id INTEGER NOT NULL",
+		"SELECT create_hypertable('forecast_series', 'time', if_not_exists => TRUE)",
+		"CREATE UNIQUE INDEX IF NOT EXISTS observation_series_key ON observation_series (station_id, provider, time)",
+	} {
+		if !strings.Contains(all, want) {
+			t.Errorf("Expected schema to contain %q, got\n%s", want, all)
+		}
+	}
+
+	db.err = errors.New(`function create_hypertable does not exist`)
+	if err := NewTimescaleSink(db).EnsureSchema(context.Background()); err == nil {
+		t.Error("Expected a missing extension to fail")
+	}
+}
+
+func TestTimescaleSink_Write(t *testing.T) {
+	db := &recordingDB{}
+	at := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	points := []Point{
+		{Measurement: MeasurementObservation, Tags: map[string]string{"station_id": "KPDX", "provider": "adds"}, Fields: map[string]float64{"temperature": 4}, Time: at},
+		{Measurement: MeasurementObservation, Tags: map[string]string{"station_id": "KPDX", "provider": "adds"}, Fields: map[string]float64{"temperature": 5, "pressure": 1013}, Time: at},
+		{Measurement: MeasurementObservation, Tags: map[string]string{"station_id": "KSEA", "provider": "adds"}, Fields: map[string]float64{"dewpoint": -1}, Time: at},
+		{Measurement: "unknown", Time: at},
+	}
+	if err := NewTimescaleSink(db).Write(context.Background(), points); err != nil {
+		t.Fatal(err)
+	}
+	if len(db.statements) != 1 {
+		t.Fatalf("Expected one statement for one measurement, got %d", len(db.statements))
+	}
+
+	query, args := db.statements[0], db.args[0]
+	if !strings.HasPrefix(query, "INSERT INTO observation_series (time, station_id, provider, temperature, dewpoint,") ||
+		!strings.Contains(query, "($1, $2, $3, $4, $5, $6, $7, $8, $9, $10), ($11,") ||
+		!strings.Contains(query, "ON CONFLICT (station_id, provider, time) DO UPDATE SET temperature = EXCLUDED.temperature") {
+		t.Errorf("Unexpected query %s", query)
+	}
+	// Two rows of time, two tags and seven fields; the repeated KPDX point keeps the last
+	if len(args) != 20 || args[3] != 5.0 || args[4] != nil || args[9] != 1013.0 || args[11] != "KSEA" {
+		t.Errorf("Unexpected args %v", args)
+	}
+}
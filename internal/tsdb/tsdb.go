@@ -0,0 +1,147 @@
+// Package tsdb mirrors forecasts and observations into a time-series database for
+// long-range analytical queries and Grafana dashboards.
+//
+// The mirror is fed through repository hooks (see repo.NewHookedEngine) and writes in
+// batches from a background goroutine, so the primary database stays the source of
+// truth and a slow or unreachable time-series backend never slows writes down.
+package tsdb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// Backend names
+const (
+	BackendTimescale = "timescaledb"
+	BackendInflux    = "influxdb"
+)
+
+// Measurements written by the mirror
+const (
+	MeasurementForecast    = "forecast"
+	MeasurementObservation = "observation"
+)
+
+// Point is one timestamped sample. Tags identify the series and Fields hold the
+// values; fields missing from a sample are stored as NULL, not zero.
+type Point struct {
+	Measurement string
+	Tags        map[string]string
+	Fields      map[string]float64
+	Time        time.Time
+}
+
+// Sink stores points
+type Sink interface {
+	// Name returns the backend name
+	Name() string
+
+	// Write stores a batch of points
+	Write(ctx context.Context, points []Point) error
+
+	// Close releases the backend connection
+	Close() error
+}
+
+// Config selects the time-series backend
+type Config struct {
+	// URL is the backend connection string; the sink is disabled when empty.
+	// postgres:// and postgresql:// select TimescaleDB; influx:// and influxs://
+	// select InfluxDB 2.x (see ParseInfluxURL).
+	URL string
+
+	// BatchSize is the largest number of points written at once
+	BatchSize int
+
+	// FlushInterval is the longest time a point waits before it is written
+	FlushInterval time.Duration
+}
+
+// Enabled reports whether a sink is configured
+func (c Config) Enabled() bool { return c.URL != "" }
+
+// LoadConfig reads WEATHER_API_TSDB_URL, WEATHER_API_TSDB_BATCH_SIZE (default 500) and
+// WEATHER_API_TSDB_FLUSH_INTERVAL (default 10s)
+func LoadConfig() (Config, error) {
+	config := Config{
+		URL:           os.Getenv("WEATHER_API_TSDB_URL"),
+		BatchSize:     500,
+		FlushInterval: 10 * time.Second,
+	}
+	if value := os.Getenv("WEATHER_API_TSDB_BATCH_SIZE"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil || size <= 0 {
+			return config, fmt.Errorf("invalid WEATHER_API_TSDB_BATCH_SIZE %q: must be a positive integer", value)
+		}
+		config.BatchSize = size
+	}
+	if value := os.Getenv("WEATHER_API_TSDB_FLUSH_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			return config, fmt.Errorf("invalid WEATHER_API_TSDB_FLUSH_INTERVAL %q: must be a positive duration", value)
+		}
+		config.FlushInterval = interval
+	}
+	if config.URL != "" {
+		if _, err := backend(config.URL); err != nil {
+			return config, err
+		}
+	}
+	return config, nil
+}
+
+// backend returns the backend selected by a connection string's scheme
+func backend(connection string) (string, error) {
+	u, err := url.Parse(connection)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("invalid WEATHER_API_TSDB_URL: expected postgres://, influx:// or influxs://")
+	}
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return BackendTimescale, nil
+	case "influx", "influxs":
+		return BackendInflux, nil
+	default:
+		return "", fmt.Errorf("unsupported WEATHER_API_TSDB_URL scheme %q (supported: postgres, influx, influxs)", u.Scheme)
+	}
+}
+
+// Open connects to the configured backend and prepares it for writes, or returns nil
+// when no sink is configured
+func Open(ctx context.Context, config Config) (Sink, error) {
+	if !config.Enabled() {
+		return nil, nil
+	}
+	name, err := backend(config.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch name {
+	case BackendTimescale:
+		db, err := sql.Open("postgres", config.URL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open TimescaleDB: %w", err)
+		}
+		sink := NewTimescaleSink(db)
+		if err := sink.EnsureSchema(ctx); err != nil {
+			db.Close()
+			return nil, err
+		}
+		return sink, nil
+	default:
+		sink, err := ParseInfluxURL(config.URL)
+		if err != nil {
+			return nil, err
+		}
+		return sink, nil
+	}
+}
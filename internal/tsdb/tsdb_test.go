@@ -0,0 +1,43 @@
+package tsdb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("WEATHER_API_TSDB_URL", "")
+	config, err := LoadConfig()
+	if err != nil || config.Enabled() || config.BatchSize != 500 || config.FlushInterval != 10*time.Second {
+		t.Errorf("Expected a disabled sink with defaults, got %+v, %v", config, err)
+	}
+	if sink, err := Open(context.Background(), config); sink != nil || err != nil {
+		t.Errorf("Expected no sink when disabled, got %v, %v", sink, err)
+	}
+
+	t.Setenv("WEATHER_API_TSDB_URL", "influx://token@localhost:8086/weather?org=acme")
+	t.Setenv("WEATHER_API_TSDB_BATCH_SIZE", "50")
+	t.Setenv("WEATHER_API_TSDB_FLUSH_INTERVAL", "2s")
+	config, err = LoadConfig()
+	if err != nil || !config.Enabled() || config.BatchSize != 50 || config.FlushInterval != 2*time.Second {
+		t.Errorf("Unexpected config %+v, %v", config, err)
+	}
+	if sink, err := Open(context.Background(), config); err != nil || sink.Name() != BackendInflux {
+		t.Errorf("Expected an InfluxDB sink, got %v, %v", sink, err)
+	}
+
+	invalid := map[string]string{
+		"WEATHER_API_TSDB_URL":            "mysql://localhost/weather",
+		"WEATHER_API_TSDB_BATCH_SIZE":     "0",
+		"WEATHER_API_TSDB_FLUSH_INTERVAL": "soon",
+	}
+	for name, value := range invalid {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			if _, err := LoadConfig(); err == nil {
+				t.Errorf("Expected %s=%q to be rejected", name, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+// Package ttl decides how long cached data stays fresh.
+//
+// A Policy assigns lifetimes by data type and, for forecasts, by horizon: a forecast
+// for the next few hours is revised far more often than one for next week, so it is
+// cached for less time. Callers ask the policy instead of keeping their own durations,
+// and operators can tune every lifetime from one file (see Load).
+package ttl
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
+
+// Policy holds the cache lifetimes of each data type. The zero value is not useful;
+// start from Default or Load.
+type Policy struct {
+	// CurrentTTL is the lifetime of current conditions and observations
+	CurrentTTL Duration `json:"current"`
+	// GeocodeTTL is the lifetime of address and coordinate lookups
+	GeocodeTTL Duration `json:"geocode"`
+	// ForecastTiers lists lifetimes by horizon, shortest horizon first. The last tier has no
+	// Within and applies to every horizon beyond the others.
+	ForecastTiers []Tier `json:"forecast"`
+}
+
+// Tier is the lifetime of forecasts valid within a horizon from now
+type Tier struct {
+	Within Duration `json:"within,omitempty"`
+	TTL    Duration `json:"ttl"`
+}
+
+// Default returns the built-in policy: current conditions 5 minutes, forecasts for
+// the next day 30 minutes, up to three days out 2 hours and beyond that (through day 7)
+// 6 hours, and geocodes 30 days
+func Default() *Policy {
+	return &Policy{
+		CurrentTTL: Duration(5 * time.Minute),
+		GeocodeTTL: Duration(30 * 24 * time.Hour),
+		ForecastTiers: []Tier{
+			{Within: Duration(24 * time.Hour), TTL: Duration(30 * time.Minute)},
+			{Within: Duration(72 * time.Hour), TTL: Duration(2 * time.Hour)},
+			{TTL: Duration(6 * time.Hour)},
+		},
+	}
+}
+
+// Load reads a JSON policy file. Durations are Go duration strings ("5m", "720h") and
+// fields left out keep their Default values, so a file may override a single lifetime.
+// A forecast list replaces the default tiers as a whole:
+//
+//	{"current": "2m", "forecast": [{"within": "6h", "ttl": "10m"}, {"ttl": "3h"}]}
+//
+// An empty path returns Default.
+func Load(path string) (*Policy, error) {
+	policy := Default()
+	if path == "" {
+		return policy, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read TTL policy: %w", err)
+	}
+	// Decoding into the default tiers would merge them element by element
+	tiers := policy.ForecastTiers
+	policy.ForecastTiers = nil
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to decode TTL policy %s: %w", path, err)
+	}
+	if policy.ForecastTiers == nil {
+		policy.ForecastTiers = tiers
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid TTL policy %s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Validate checks that every lifetime is positive and that forecast tiers are ordered
+// by horizon and end with a catch-all tier
+func (p *Policy) Validate() error {
+	var errs []error
+	if p.CurrentTTL <= 0 {
+		errs = append(errs, errors.New("current must be positive"))
+	}
+	if p.GeocodeTTL <= 0 {
+		errs = append(errs, errors.New("geocode must be positive"))
+	}
+	if len(p.ForecastTiers) == 0 {
+		errs = append(errs, errors.New("forecast needs at least one tier"))
+	}
+	for i, tier := range p.ForecastTiers {
+		last := i == len(p.ForecastTiers)-1
+		switch {
+		case tier.TTL <= 0:
+			errs = append(errs, fmt.Errorf("forecast tier %d: ttl must be positive", i+1))
+		case last && tier.Within != 0:
+			errs = append(errs, errors.New("the last forecast tier must not set within"))
+		case !last && tier.Within <= 0:
+			errs = append(errs, fmt.Errorf("forecast tier %d: within must be positive", i+1))
+		case i > 0 && !last && tier.Within <= p.ForecastTiers[i-1].Within:
+			errs = append(errs, fmt.Errorf("forecast tier %d: within must be longer than the previous tier's", i+1))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Current returns the lifetime of current conditions
+func (p *Policy) Current() time.Duration {
+	return time.Duration(p.CurrentTTL)
+}
+
+// Geocode returns the lifetime of geocoding results
+func (p *Policy) Geocode() time.Duration {
+	return time.Duration(p.GeocodeTTL)
+}
+
+// ForecastAt returns the lifetime of a forecast valid at validTime, judged from now.
+// Forecasts already in effect count as the shortest horizon.
+func (p *Policy) ForecastAt(validTime, now time.Time) time.Duration {
+	return p.ForecastWithin(validTime.Sub(now))
+}
+
+// ForecastWithin returns the lifetime of a forecast horizon ahead
+func (p *Policy) ForecastWithin(horizon time.Duration) time.Duration {
+	i := slices.IndexFunc(p.ForecastTiers, func(tier Tier) bool {
+		return tier.Within == 0 || horizon <= time.Duration(tier.Within)
+	})
+	if i < 0 {
+		return time.Duration(p.ForecastTiers[len(p.ForecastTiers)-1].TTL)
+	}
+	return time.Duration(p.ForecastTiers[i].TTL)
+}
+
+// Duration is a time.Duration written as a Go duration string in JSON
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string such as "1h30m0s"
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON parses a duration string such as "90m"
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string such as \"30m\": %w", err)
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
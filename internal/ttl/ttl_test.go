@@ -0,0 +1,97 @@
+package ttl
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDefault(t *testing.T) {
+	policy := Default()
+	if err := policy.Validate(); err != nil {
+		t.Fatalf("Default policy is invalid: %v", err)
+	}
+	if policy.Current() != 5*time.Minute {
+		t.Errorf("Expected current lifetime 5m, got %v", policy.Current())
+	}
+	if policy.Geocode() != 30*24*time.Hour {
+		t.Errorf("Expected geocode lifetime 720h, got %v", policy.Geocode())
+	}
+
+	now := time.Date(2025, time.August, 11, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		validAt  time.Time
+		expected time.Duration
+	}{
+		{"already in effect", now.Add(-time.Hour), 30 * time.Minute},
+		{"later today", now.Add(6 * time.Hour), 30 * time.Minute},
+		{"end of the first tier", now.Add(24 * time.Hour), 30 * time.Minute},
+		{"day 2", now.Add(36 * time.Hour), 2 * time.Hour},
+		{"day 7", now.Add(7 * 24 * time.Hour), 6 * time.Hour},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := policy.ForecastAt(test.validAt, now); got != test.expected {
+				t.Errorf("Expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	write := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "ttl.json")
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		return path
+	}
+
+	t.Run("empty path", func(t *testing.T) {
+		policy, err := Load("")
+		if err != nil || policy.Current() != Default().Current() {
+			t.Errorf("Expected the default policy, got %+v (%v)", policy, err)
+		}
+	})
+
+	t.Run("overrides keep other defaults", func(t *testing.T) {
+		policy, err := Load(write(t, `{"current": "2m", "forecast": [{"within": "6h", "ttl": "10m"}, {"ttl": "3h"}]}`))
+		if err != nil {
+			t.Fatalf("Load failed: %v", err)
+		}
+		if policy.Current() != 2*time.Minute {
+			t.Errorf("Expected current lifetime 2m, got %v", policy.Current())
+		}
+		if policy.Geocode() != Default().Geocode() {
+			t.Errorf("Expected the default geocode lifetime, got %v", policy.Geocode())
+		}
+		if got := policy.ForecastWithin(12 * time.Hour); got != 3*time.Hour {
+			t.Errorf("Expected 3h beyond the first tier, got %v", got)
+		}
+	})
+
+	t.Run("invalid policies", func(t *testing.T) {
+		tests := map[string]string{
+			`{"current": 300}`:   "duration must be a string",
+			`{"geocode": "-1h"}`: "geocode must be positive",
+			`{"forecast": []}`:   "at least one tier",
+			`{"forecast": [{"within": "6h", "ttl": "10m"}]}`:                                               "must not set within",
+			`{"forecast": [{"ttl": "10m"}, {"ttl": "1h"}]}`:                                                "within must be positive",
+			`{"forecast": [{"within": "6h", "ttl": "10m"}, {"within": "2h", "ttl": "1h"}, {"ttl": "3h"}]}`: "longer than the previous",
+		}
+		for content, expected := range tests {
+			if _, err := Load(write(t, content)); err == nil || !strings.Contains(err.Error(), expected) {
+				t.Errorf("Load(%s): expected error containing %q, got %v", content, expected, err)
+			}
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Error("Expected an error for a missing file")
+		}
+	})
+}
@@ -0,0 +1,36 @@
+package units
+
+import "math"
+
+// Pressure reduction between station level and mean sea level.
+//
+// Stations report the pressure measured at their elevation; forecasts and charts use
+// the pressure reduced to mean sea level so that sites at different elevations can be
+// compared. The reduction below is the barometric formula for the ICAO standard
+// atmosphere lapse rate (6.5 K/km), corrected with the observed temperature.
+
+const (
+	// standardLapseRate is the ICAO standard atmosphere temperature lapse rate in K/m
+	standardLapseRate = 0.0065
+	// barometricExponent is g·M/(R·L) for dry air with standardLapseRate
+	barometricExponent = 5.257
+)
+
+// reductionFactor returns station/sea-level pressure for a station elevation in meters
+// and a station temperature in °C
+func reductionFactor(elevation, temperature float64) float64 {
+	lapse := standardLapseRate * elevation
+	return math.Pow(1-lapse/(temperature+lapse+273.15), barometricExponent)
+}
+
+// StationToSeaLevelPressure reduces a station pressure in hPa measured at elevation
+// meters, with temperature °C at the station, to mean sea-level pressure in hPa
+func StationToSeaLevelPressure(station, elevation, temperature float64) float64 {
+	return station / reductionFactor(elevation, temperature)
+}
+
+// SeaLevelToStationPressure converts a mean sea-level pressure in hPa to the pressure
+// expected at a station elevation meters above sea level with temperature °C
+func SeaLevelToStationPressure(seaLevel, elevation, temperature float64) float64 {
+	return seaLevel * reductionFactor(elevation, temperature)
+}
@@ -0,0 +1,117 @@
+// Package units converts weather measurements between unit systems.
+//
+// Everything is stored in metric (Celsius, m/s, km, hPa, mm); conversion only ever
+// happens on the way out so stored data stays canonical.
+package units
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// System identifies a unit system
+type System string
+
+const (
+	// Metric is the storage system: °C, m/s, km, hPa, mm
+	Metric System = "metric"
+	// Imperial uses °F, mph, miles, inHg and inches
+	Imperial System = "imperial"
+)
+
+// ParseSystem parses a unit system name; an empty string means Metric
+func ParseSystem(value string) (System, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "metric", "si":
+		return Metric, nil
+	case "imperial", "us":
+		return Imperial, nil
+	default:
+		return "", fmt.Errorf("units must be 'metric' or 'imperial'")
+	}
+}
+
+// imperialRegions are the countries whose locales default to Imperial units
+var imperialRegions = map[string]bool{"us": true, "lr": true, "mm": true}
+
+// ForLocale returns the conventional unit system for a BCP 47 language tag such as
+// "en-US": Imperial for the United States, Liberia and Myanmar, Metric everywhere else
+// including tags without a region
+func ForLocale(tag string) System {
+	parts := strings.Split(strings.ToLower(strings.ReplaceAll(tag, "_", "-")), "-")
+	for _, part := range parts[1:] {
+		// The region subtag is the first two-letter subtag after the language
+		if len(part) == 2 {
+			if imperialRegions[part] {
+				return Imperial
+			}
+			break
+		}
+	}
+	return Metric
+}
+
+// Labels names the unit of each converted quantity for a system
+type Labels struct {
+	Temperature   string `json:"temperature"`
+	WindSpeed     string `json:"wind_speed"`
+	Visibility    string `json:"visibility"`
+	Pressure      string `json:"pressure"`
+	Precipitation string `json:"precipitation"`
+}
+
+// Labels returns the unit labels for the system
+func (s System) Labels() Labels {
+	if s == Imperial {
+		return Labels{Temperature: "°F", WindSpeed: "mph", Visibility: "mi", Pressure: "inHg", Precipitation: "in"}
+	}
+	return Labels{Temperature: "°C", WindSpeed: "m/s", Visibility: "km", Pressure: "hPa", Precipitation: "mm"}
+}
+
+// CelsiusToFahrenheit converts a temperature
+func CelsiusToFahrenheit(c float64) float64 {
+	return c*9/5 + 32
+}
+
+// MetersPerSecondToMilesPerHour converts a speed
+func MetersPerSecondToMilesPerHour(ms float64) float64 {
+	return ms * 2.2369362920544
+}
+
+// KilometersToMiles converts a distance
+func KilometersToMiles(km float64) float64 {
+	return km * 0.62137119223733
+}
+
+// HectopascalsToInchesOfMercury converts a pressure
+func HectopascalsToInchesOfMercury(hpa float64) float64 {
+	return hpa * 0.029529983071445
+}
+
+// MillimetersToInches converts a precipitation depth
+func MillimetersToInches(mm float64) float64 {
+	return mm / 25.4
+}
+
+// Round rounds to the given number of decimal places so converted values don't carry
+// spurious precision
+func Round(value float64, places int) float64 {
+	factor := math.Pow(10, float64(places))
+	return math.Round(value*factor) / factor
+}
+
+type preferenceKey struct{}
+
+// WithPreference returns a context carrying the caller's preferred unit system, e.g. from
+// an authenticated user's PreferredUnits
+func WithPreference(ctx context.Context, system System) context.Context {
+	return context.WithValue(ctx, preferenceKey{}, system)
+}
+
+// PreferenceFromContext returns the preferred unit system stored in ctx, if any
+func PreferenceFromContext(ctx context.Context) (System, bool) {
+	system, ok := ctx.Value(preferenceKey{}).(System)
+	return system, ok && system != ""
+}
@@ -0,0 +1,184 @@
+package units
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestParseSystem(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected System
+		wantErr  bool
+	}{
+		{"", Metric, false},
+		{"metric", Metric, false},
+		{"Imperial", Imperial, false},
+		{" us ", Imperial, false},
+		{"kelvin", "", true},
+	}
+
+	for _, test := range tests {
+		system, err := ParseSystem(test.input)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected error", test.input)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.input, err)
+		}
+		if system != test.expected {
+			t.Errorf("%q: expected %s, got %s", test.input, test.expected, system)
+		}
+	}
+}
+
+func TestConversions(t *testing.T) {
+	tests := []struct {
+		name     string
+		got      float64
+		expected float64
+	}{
+		{"freezing", CelsiusToFahrenheit(0), 32},
+		{"boiling", CelsiusToFahrenheit(100), 212},
+		{"minus forty", CelsiusToFahrenheit(-40), -40},
+		{"wind", MetersPerSecondToMilesPerHour(10), 22.369362920544},
+		{"visibility", KilometersToMiles(1.609344), 1},
+		{"standard pressure", HectopascalsToInchesOfMercury(1013.25), 29.9212},
+		{"rain", MillimetersToInches(25.4), 1},
+	}
+
+	for _, test := range tests {
+		if math.Abs(test.got-test.expected) > 0.001 {
+			t.Errorf("%s: expected %f, got %f", test.name, test.expected, test.got)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	if got := Round(29.921252, 2); got != 29.92 {
+		t.Errorf("expected 29.92, got %f", got)
+	}
+}
+
+func TestForLocale(t *testing.T) {
+	tests := map[string]System{
+		"en-US":      Imperial,
+		"en_us":      Imperial,
+		"es-US":      Imperial,
+		"en-LR":      Imperial,
+		"en":         Metric,
+		"en-GB":      Metric,
+		"zh-Hant-TW": Metric,
+		"":           Metric,
+	}
+
+	for tag, expected := range tests {
+		if got := ForLocale(tag); got != expected {
+			t.Errorf("ForLocale(%q) = %s, expected %s", tag, got, expected)
+		}
+	}
+}
+
+func TestPreference(t *testing.T) {
+	if _, ok := PreferenceFromContext(context.Background()); ok {
+		t.Error("expected no preference in empty context")
+	}
+
+	ctx := WithPreference(context.Background(), Imperial)
+	system, ok := PreferenceFromContext(ctx)
+	if !ok || system != Imperial {
+		t.Errorf("expected imperial preference, got %s (%v)", system, ok)
+	}
+}
+
+func TestLabels(t *testing.T) {
+	if Imperial.Labels().Temperature != "°F" || Metric.Labels().Pressure != "hPa" {
+		t.Error("unexpected unit labels")
+	}
+}
+
+func TestParseWindUnit(t *testing.T) {
+	tests := map[string]WindUnit{
+		"":         "",
+		"ms":       MetersPerSecond,
+		"KMH":      KilometersPerHour,
+		"mph":      MilesPerHour,
+		"kt":       Knots,
+		"knots":    Knots,
+		"beaufort": Beaufort,
+	}
+	for value, expected := range tests {
+		unit, err := ParseWindUnit(value)
+		if err != nil {
+			t.Errorf("ParseWindUnit(%q) failed: %v", value, err)
+		}
+		if unit != expected {
+			t.Errorf("ParseWindUnit(%q) = %q, expected %q", value, unit, expected)
+		}
+	}
+
+	if _, err := ParseWindUnit("furlongs"); err == nil {
+		t.Error("Expected error for unknown wind unit")
+	}
+}
+
+func TestConvertWindSpeed(t *testing.T) {
+	tests := []struct {
+		unit     WindUnit
+		expected float64
+	}{
+		{MetersPerSecond, 10},
+		{KilometersPerHour, 36},
+		{MilesPerHour, 22.4},
+		{Knots, 19.4},
+		{Beaufort, 5},
+	}
+	for _, test := range tests {
+		if got := ConvertWindSpeed(10, test.unit); got != test.expected {
+			t.Errorf("ConvertWindSpeed(10, %s) = %f, expected %f", test.unit, got, test.expected)
+		}
+	}
+}
+
+func TestBeaufort(t *testing.T) {
+	tests := []struct {
+		ms          float64
+		force       int
+		description string
+	}{
+		{0, 0, "Calm"},
+		{0.5, 1, "Light air"},
+		{5, 3, "Gentle breeze"},
+		{10, 5, "Fresh breeze"},
+		{18, 8, "Gale"},
+		{32.6, 11, "Violent storm"},
+		{40, 12, "Hurricane force"},
+	}
+	for _, test := range tests {
+		force := BeaufortForce(test.ms)
+		if force != test.force {
+			t.Errorf("BeaufortForce(%f) = %d, expected %d", test.ms, force, test.force)
+		}
+		if got := BeaufortDescription(force); got != test.description {
+			t.Errorf("BeaufortDescription(%d) = %q, expected %q", force, got, test.description)
+		}
+	}
+}
+
+func TestPressureReduction(t *testing.T) {
+	// Denver: 840 hPa at 1609 m and 15 °C is close to standard sea-level pressure
+	msl := StationToSeaLevelPressure(840, 1609, 15)
+	if math.Abs(msl-1013) > 1 {
+		t.Errorf("expected about 1013 hPa at sea level, got %f", msl)
+	}
+	if back := SeaLevelToStationPressure(msl, 1609, 15); math.Abs(back-840) > 0.001 {
+		t.Errorf("expected round trip to 840 hPa, got %f", back)
+	}
+	if got := StationToSeaLevelPressure(1013.25, 0, 15); got != 1013.25 {
+		t.Errorf("expected no reduction at sea level, got %f", got)
+	}
+}
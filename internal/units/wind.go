@@ -0,0 +1,136 @@
+package units
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WindUnit identifies the unit wind speeds are reported in, independent of the System
+type WindUnit string
+
+const (
+	// MetersPerSecond is the storage unit
+	MetersPerSecond WindUnit = "ms"
+	// KilometersPerHour is common in metric forecasts
+	KilometersPerHour WindUnit = "kmh"
+	// MilesPerHour is the Imperial default
+	MilesPerHour WindUnit = "mph"
+	// Knots is used in marine and aviation reports
+	Knots WindUnit = "knots"
+	// Beaufort reports the Beaufort force number (0-12) instead of a speed
+	Beaufort WindUnit = "beaufort"
+)
+
+// ParseWindUnit parses a wind unit name; an empty string returns "" so the caller can
+// fall back to the unit system's default
+func ParseWindUnit(value string) (WindUnit, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "":
+		return "", nil
+	case "ms", "m/s", "mps":
+		return MetersPerSecond, nil
+	case "kmh", "km/h", "kph":
+		return KilometersPerHour, nil
+	case "mph":
+		return MilesPerHour, nil
+	case "knots", "kn", "kt", "kts":
+		return Knots, nil
+	case "beaufort", "bft":
+		return Beaufort, nil
+	default:
+		return "", fmt.Errorf("wind_units must be one of 'ms', 'kmh', 'mph', 'knots' or 'beaufort'")
+	}
+}
+
+// WindUnit returns the system's default wind unit
+func (s System) WindUnit() WindUnit {
+	if s == Imperial {
+		return MilesPerHour
+	}
+	return MetersPerSecond
+}
+
+// Label returns the display label for the unit
+func (u WindUnit) Label() string {
+	switch u {
+	case KilometersPerHour:
+		return "km/h"
+	case MilesPerHour:
+		return "mph"
+	case Knots:
+		return "kn"
+	case Beaufort:
+		return "Bft"
+	default:
+		return "m/s"
+	}
+}
+
+// ConvertWindSpeed converts a speed in m/s to the unit, rounded to one decimal place.
+// For Beaufort the result is the force number.
+func ConvertWindSpeed(ms float64, unit WindUnit) float64 {
+	switch unit {
+	case KilometersPerHour:
+		return Round(MetersPerSecondToKilometersPerHour(ms), 1)
+	case MilesPerHour:
+		return Round(MetersPerSecondToMilesPerHour(ms), 1)
+	case Knots:
+		return Round(MetersPerSecondToKnots(ms), 1)
+	case Beaufort:
+		return float64(BeaufortForce(ms))
+	default:
+		return Round(ms, 1)
+	}
+}
+
+// MetersPerSecondToKilometersPerHour converts a speed
+func MetersPerSecondToKilometersPerHour(ms float64) float64 {
+	return ms * 3.6
+}
+
+// MetersPerSecondToKnots converts a speed
+func MetersPerSecondToKnots(ms float64) float64 {
+	return ms * 1.9438444924406
+}
+
+// beaufortScale holds the upper bound in m/s (exclusive) and descriptor of each force
+// below 12, per the WMO table
+var beaufortScale = []struct {
+	limit       float64
+	description string
+}{
+	{0.5, "Calm"},
+	{1.6, "Light air"},
+	{3.4, "Light breeze"},
+	{5.5, "Gentle breeze"},
+	{8.0, "Moderate breeze"},
+	{10.8, "Fresh breeze"},
+	{13.9, "Strong breeze"},
+	{17.2, "Near gale"},
+	{20.8, "Gale"},
+	{24.5, "Strong gale"},
+	{28.5, "Storm"},
+	{32.7, "Violent storm"},
+}
+
+// BeaufortForce returns the Beaufort force (0-12) for a speed in m/s
+func BeaufortForce(ms float64) int {
+	for force, band := range beaufortScale {
+		if ms < band.limit {
+			return force
+		}
+	}
+	return len(beaufortScale)
+}
+
+// BeaufortDescription returns the descriptor for a Beaufort force, e.g. "Gentle breeze"
+func BeaufortDescription(force int) string {
+	switch {
+	case force < 0:
+		return ""
+	case force < len(beaufortScale):
+		return beaufortScale[force].description
+	default:
+		return "Hurricane force"
+	}
+}
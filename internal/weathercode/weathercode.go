@@ -0,0 +1,360 @@
+// Package weathercode maps the weather codes of each provider onto one canonical set.
+//
+// Providers describe the weather in their own vocabularies: NWS in icon names (skc,
+// tsra_hi), MET Norway in symbol codes (lightrainshowers_day), Open-Meteo in WMO code
+// numbers (61) and Tomorrow.io in four-digit code numbers (4200). Forecasts store the provider's code as it came; responses carry
+// the canonical Code next to it, along with an icon name and a description, so clients
+// handle one set whichever provider a forecast came from.
+package weathercode
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Canonical weather codes
+const (
+	Clear            = "clear"
+	MostlyClear      = "mostly_clear"
+	PartlyCloudy     = "partly_cloudy"
+	MostlyCloudy     = "mostly_cloudy"
+	Cloudy           = "cloudy"
+	Fog              = "fog"
+	Haze             = "haze"
+	Smoke            = "smoke"
+	Dust             = "dust"
+	Windy            = "windy"
+	Drizzle          = "drizzle"
+	FreezingDrizzle  = "freezing_drizzle"
+	Rain             = "rain"
+	RainShowers      = "rain_showers"
+	FreezingRain     = "freezing_rain"
+	Sleet            = "sleet"
+	RainSnow         = "rain_snow"
+	Snow             = "snow"
+	SnowShowers      = "snow_showers"
+	Blizzard         = "blizzard"
+	Thunderstorm     = "thunderstorm"
+	ThunderstormHail = "thunderstorm_hail"
+	Tornado          = "tornado"
+	TropicalStorm    = "tropical_storm"
+	Hurricane        = "hurricane"
+	Hot              = "hot"
+	Cold             = "cold"
+)
+
+// Condition is a weather code in the canonical set
+type Condition struct {
+	Code        string // one of the canonical codes, e.g. "rain_showers"
+	Icon        string // icon name, shared by codes drawn alike, e.g. "showers"
+	Description string // human description, e.g. "Light rain showers"
+}
+
+// canonical holds the icon and default description of each canonical code
+var canonical = map[string]Condition{
+	Clear:            {Icon: "clear", Description: "Clear"},
+	MostlyClear:      {Icon: "mostly-clear", Description: "Mostly clear"},
+	PartlyCloudy:     {Icon: "partly-cloudy", Description: "Partly cloudy"},
+	MostlyCloudy:     {Icon: "mostly-cloudy", Description: "Mostly cloudy"},
+	Cloudy:           {Icon: "cloudy", Description: "Cloudy"},
+	Fog:              {Icon: "fog", Description: "Fog"},
+	Haze:             {Icon: "haze", Description: "Haze"},
+	Smoke:            {Icon: "smoke", Description: "Smoke"},
+	Dust:             {Icon: "dust", Description: "Dust"},
+	Windy:            {Icon: "wind", Description: "Windy"},
+	Drizzle:          {Icon: "drizzle", Description: "Drizzle"},
+	FreezingDrizzle:  {Icon: "sleet", Description: "Freezing drizzle"},
+	Rain:             {Icon: "rain", Description: "Rain"},
+	RainShowers:      {Icon: "showers", Description: "Rain showers"},
+	FreezingRain:     {Icon: "sleet", Description: "Freezing rain"},
+	Sleet:            {Icon: "sleet", Description: "Sleet"},
+	RainSnow:         {Icon: "rain-snow", Description: "Rain and snow"},
+	Snow:             {Icon: "snow", Description: "Snow"},
+	SnowShowers:      {Icon: "snow", Description: "Snow showers"},
+	Blizzard:         {Icon: "blizzard", Description: "Blizzard"},
+	Thunderstorm:     {Icon: "thunderstorm", Description: "Thunderstorm"},
+	ThunderstormHail: {Icon: "thunderstorm", Description: "Thunderstorm with hail"},
+	Tornado:          {Icon: "tornado", Description: "Tornado"},
+	TropicalStorm:    {Icon: "tropical-storm", Description: "Tropical storm"},
+	Hurricane:        {Icon: "hurricane", Description: "Hurricane"},
+	Hot:              {Icon: "hot", Description: "Hot"},
+	Cold:             {Icon: "cold", Description: "Cold"},
+}
+
+// condition returns the Condition of a canonical code, with description overriding the
+// default one when it is set
+func condition(code, description string) Condition {
+	c := canonical[code]
+	c.Code = code
+	if description != "" {
+		c.Description = description
+	}
+	return c
+}
+
+// Normalize maps a stored weather code of any provider onto the canonical set: a WMO
+// code number, a Tomorrow.io code number, an NWS icon name, a MET Norway symbol code or
+// a canonical code itself. WMO numbers stop at 99 and Tomorrow.io's start at 1000, and
+// the named vocabularies agree wherever their names overlap, so the code alone
+// identifies it. ok is false for empty and unknown codes.
+func Normalize(code string) (Condition, bool) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return Condition{}, false
+	}
+	if number, err := strconv.Atoi(code); err == nil {
+		if number >= 1000 {
+			return FromTomorrowIO(number)
+		}
+		return FromWMO(number)
+	}
+	if _, ok := canonical[code]; ok {
+		return condition(code, ""), true
+	}
+	if c, ok := FromNWSIcon(code); ok {
+		return c, true
+	}
+	return FromMetNo(code)
+}
+
+// wmoCodes are the WMO 4677 present weather codes Open-Meteo reports (a subset)
+var wmoCodes = map[int]Condition{
+	0:  {Code: Clear, Description: "Clear sky"},
+	1:  {Code: MostlyClear, Description: "Mainly clear"},
+	2:  {Code: PartlyCloudy},
+	3:  {Code: Cloudy, Description: "Overcast"},
+	45: {Code: Fog},
+	48: {Code: Fog, Description: "Depositing rime fog"},
+	51: {Code: Drizzle, Description: "Light drizzle"},
+	53: {Code: Drizzle, Description: "Moderate drizzle"},
+	55: {Code: Drizzle, Description: "Dense drizzle"},
+	56: {Code: FreezingDrizzle, Description: "Light freezing drizzle"},
+	57: {Code: FreezingDrizzle, Description: "Dense freezing drizzle"},
+	61: {Code: Rain, Description: "Light rain"},
+	63: {Code: Rain, Description: "Moderate rain"},
+	65: {Code: Rain, Description: "Heavy rain"},
+	66: {Code: FreezingRain, Description: "Light freezing rain"},
+	67: {Code: FreezingRain, Description: "Heavy freezing rain"},
+	71: {Code: Snow, Description: "Light snow"},
+	73: {Code: Snow, Description: "Moderate snow"},
+	75: {Code: Snow, Description: "Heavy snow"},
+	77: {Code: Snow, Description: "Snow grains"},
+	80: {Code: RainShowers, Description: "Light rain showers"},
+	81: {Code: RainShowers, Description: "Moderate rain showers"},
+	82: {Code: RainShowers, Description: "Violent rain showers"},
+	85: {Code: SnowShowers, Description: "Light snow showers"},
+	86: {Code: SnowShowers, Description: "Heavy snow showers"},
+	95: {Code: Thunderstorm},
+	96: {Code: ThunderstormHail, Description: "Thunderstorm with light hail"},
+	99: {Code: ThunderstormHail, Description: "Thunderstorm with heavy hail"},
+}
+
+// FromWMO maps a WMO weather interpretation code, as reported by Open-Meteo
+func FromWMO(code int) (Condition, bool) {
+	c, ok := wmoCodes[code]
+	if !ok {
+		return Condition{}, false
+	}
+	return condition(c.Code, c.Description), true
+}
+
+// tomorrowIOCodes are the Tomorrow.io weatherCode values
+var tomorrowIOCodes = map[int]Condition{
+	1000: {Code: Clear},
+	1100: {Code: MostlyClear},
+	1101: {Code: PartlyCloudy},
+	1102: {Code: MostlyCloudy},
+	1001: {Code: Cloudy},
+	2000: {Code: Fog},
+	2100: {Code: Fog, Description: "Light fog"},
+	4000: {Code: Drizzle},
+	4001: {Code: Rain},
+	4200: {Code: Rain, Description: "Light rain"},
+	4201: {Code: Rain, Description: "Heavy rain"},
+	5000: {Code: Snow},
+	5001: {Code: SnowShowers, Description: "Flurries"},
+	5100: {Code: Snow, Description: "Light snow"},
+	5101: {Code: Snow, Description: "Heavy snow"},
+	6000: {Code: FreezingDrizzle},
+	6001: {Code: FreezingRain},
+	6200: {Code: FreezingRain, Description: "Light freezing rain"},
+	6201: {Code: FreezingRain, Description: "Heavy freezing rain"},
+	7000: {Code: Sleet, Description: "Ice pellets"},
+	7101: {Code: Sleet, Description: "Heavy ice pellets"},
+	7102: {Code: Sleet, Description: "Light ice pellets"},
+	8000: {Code: Thunderstorm},
+}
+
+// FromTomorrowIO maps a Tomorrow.io weather code such as 4200 (light rain)
+func FromTomorrowIO(code int) (Condition, bool) {
+	c, ok := tomorrowIOCodes[code]
+	if !ok {
+		return Condition{}, false
+	}
+	return condition(c.Code, c.Description), true
+}
+
+// nwsIcons are the NWS icon names, the condition part of forecast icon URLs such as
+// https://api.weather.gov/icons/land/day/tsra_hi,40
+var nwsIcons = map[string]Condition{
+	"skc":             {Code: Clear},
+	"few":             {Code: MostlyClear, Description: "A few clouds"},
+	"sct":             {Code: PartlyCloudy},
+	"bkn":             {Code: MostlyCloudy},
+	"ovc":             {Code: Cloudy, Description: "Overcast"},
+	"wind_skc":        {Code: Windy, Description: "Clear and windy"},
+	"wind_few":        {Code: Windy, Description: "A few clouds and windy"},
+	"wind_sct":        {Code: Windy, Description: "Partly cloudy and windy"},
+	"wind_bkn":        {Code: Windy, Description: "Mostly cloudy and windy"},
+	"wind_ovc":        {Code: Windy, Description: "Overcast and windy"},
+	"snow":            {Code: Snow},
+	"rain_snow":       {Code: RainSnow},
+	"rain_sleet":      {Code: Sleet, Description: "Rain and sleet"},
+	"snow_sleet":      {Code: Sleet, Description: "Snow and sleet"},
+	"fzra":            {Code: FreezingRain},
+	"rain_fzra":       {Code: FreezingRain, Description: "Rain and freezing rain"},
+	"snow_fzra":       {Code: FreezingRain, Description: "Snow and freezing rain"},
+	"sleet":           {Code: Sleet},
+	"rain":            {Code: Rain},
+	"rain_showers":    {Code: RainShowers},
+	"rain_showers_hi": {Code: RainShowers, Description: "Isolated rain showers"},
+	"tsra":            {Code: Thunderstorm},
+	"tsra_sct":        {Code: Thunderstorm, Description: "Scattered thunderstorms"},
+	"tsra_hi":         {Code: Thunderstorm, Description: "Isolated thunderstorms"},
+	"tornado":         {Code: Tornado},
+	"hurricane":       {Code: Hurricane},
+	"tropical_storm":  {Code: TropicalStorm},
+	"dust":            {Code: Dust},
+	"smoke":           {Code: Smoke},
+	"haze":            {Code: Haze},
+	"hot":             {Code: Hot},
+	"cold":            {Code: Cold},
+	"blizzard":        {Code: Blizzard},
+	"fog":             {Code: Fog},
+}
+
+// FromNWSIcon maps an NWS icon name such as "tsra_hi"
+func FromNWSIcon(icon string) (Condition, bool) {
+	c, ok := nwsIcons[icon]
+	if !ok {
+		return Condition{}, false
+	}
+	return condition(c.Code, c.Description), true
+}
+
+// metNoSkies are the MET Norway symbol codes without precipitation
+var metNoSkies = map[string]Condition{
+	"clearsky":     {Code: Clear, Description: "Clear sky"},
+	"fair":         {Code: MostlyClear, Description: "Fair"},
+	"partlycloudy": {Code: PartlyCloudy},
+	"cloudy":       {Code: Cloudy},
+	"fog":          {Code: Fog},
+}
+
+// FromMetNo maps a MET Norway symbol code such as "lightrainshowersandthunder_day". The
+// codes are built from an optional intensity (light, heavy), a precipitation type
+// (rain, sleet, snow), an optional "showers" and an optional "andthunder", followed by
+// a time of day for the codes whose symbol shows the sun or moon.
+func FromMetNo(symbol string) (Condition, bool) {
+	for _, suffix := range []string{"_day", "_night", "_polartwilight"} {
+		symbol = strings.TrimSuffix(symbol, suffix)
+	}
+	if c, ok := metNoSkies[symbol]; ok {
+		return condition(c.Code, c.Description), true
+	}
+
+	// MET Norway spells two of its codes "lightssleet..." and "lightssnow..."
+	rest := strings.Replace(symbol, "lightss", "lights", 1)
+	intensity := ""
+	for _, prefix := range []string{"light", "heavy"} {
+		if trimmed, ok := strings.CutPrefix(rest, prefix); ok {
+			rest, intensity = trimmed, prefix
+			break
+		}
+	}
+	rest, thunder := strings.CutSuffix(rest, "andthunder")
+	precipitation, showers := strings.CutSuffix(rest, "showers")
+
+	var code string
+	switch {
+	case precipitation == "rain" && showers:
+		code = RainShowers
+	case precipitation == "snow" && showers:
+		code = SnowShowers
+	case precipitation == "rain" || precipitation == "sleet" || precipitation == "snow":
+		code = precipitation
+	default:
+		return Condition{}, false
+	}
+	if thunder {
+		code = Thunderstorm
+	}
+
+	description := precipitation
+	if showers {
+		description += " showers"
+	}
+	if thunder {
+		description += " and thunder"
+	}
+	if intensity != "" {
+		description = intensity + " " + description
+	}
+	return condition(code, strings.ToUpper(description[:1])+description[1:]), true
+}
+
+// ecIcons are the Environment Canada icon codes of citypage forecasts. Codes 32 to 39
+// are the night versions of 02 to 09.
+var ecIcons = map[int]Condition{
+	0:  {Code: Clear, Description: "Sunny"},
+	1:  {Code: MostlyClear, Description: "Mainly sunny"},
+	2:  {Code: PartlyCloudy},
+	3:  {Code: MostlyCloudy},
+	4:  {Code: MostlyCloudy, Description: "Increasing cloudiness"},
+	5:  {Code: PartlyCloudy, Description: "Clearing"},
+	6:  {Code: RainShowers, Description: "Light rain shower"},
+	7:  {Code: RainSnow, Description: "Rain showers or flurries"},
+	8:  {Code: SnowShowers, Description: "Flurries"},
+	9:  {Code: Thunderstorm, Description: "Showers with thunder"},
+	10: {Code: Cloudy},
+	11: {Code: Rain, Description: "Precipitation"},
+	12: {Code: Rain},
+	13: {Code: Rain, Description: "Heavy rain"},
+	14: {Code: FreezingRain},
+	15: {Code: RainSnow},
+	16: {Code: Snow, Description: "Light snow"},
+	17: {Code: Snow},
+	18: {Code: Snow, Description: "Heavy snow"},
+	19: {Code: Thunderstorm},
+	23: {Code: Haze},
+	24: {Code: Fog},
+	25: {Code: Blizzard, Description: "Drifting snow"},
+	26: {Code: Snow, Description: "Ice crystals"},
+	27: {Code: Sleet, Description: "Ice pellets"},
+	28: {Code: Drizzle},
+	30: {Code: Clear},
+	31: {Code: MostlyClear, Description: "Mainly clear"},
+	40: {Code: Blizzard, Description: "Blowing snow"},
+	41: {Code: Tornado, Description: "Funnel cloud"},
+	42: {Code: Tornado},
+	43: {Code: Windy},
+	44: {Code: Smoke},
+	45: {Code: Dust, Description: "Dust storm"},
+	46: {Code: ThunderstormHail},
+	47: {Code: Thunderstorm, Description: "Thunderstorm with dust storm"},
+	48: {Code: Tornado, Description: "Waterspout"},
+}
+
+// FromECIcon maps an Environment Canada icon code such as 12 (rain) or 36 (light rain
+// shower at night). The codes are numbers like WMO codes but mean other things, so
+// Normalize cannot tell them apart; providers store the canonical code instead.
+func FromECIcon(code int) (Condition, bool) {
+	c, ok := ecIcons[code]
+	if !ok && code >= 32 && code <= 39 {
+		c, ok = ecIcons[code-30]
+	}
+	if !ok {
+		return Condition{}, false
+	}
+	return condition(c.Code, c.Description), true
+}
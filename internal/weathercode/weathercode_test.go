@@ -0,0 +1,95 @@
+package weathercode
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		code        string
+		want        string
+		icon        string
+		description string
+	}{
+		{"0", Clear, "clear", "Clear sky"},
+		{"63", Rain, "rain", "Moderate rain"},
+		{"82", RainShowers, "showers", "Violent rain showers"},
+		{"99", ThunderstormHail, "thunderstorm", "Thunderstorm with heavy hail"},
+		{"4200", Rain, "rain", "Light rain"},
+		{"1102", MostlyCloudy, "mostly-cloudy", "Mostly cloudy"},
+		{"tsra_hi", Thunderstorm, "thunderstorm", "Isolated thunderstorms"},
+		{"ovc", Cloudy, "cloudy", "Overcast"},
+		{"wind_skc", Windy, "wind", "Clear and windy"},
+		{"clearsky_day", Clear, "clear", "Clear sky"},
+		{"lightrainshowers_night", RainShowers, "showers", "Light rain showers"},
+		{"heavysnowandthunder", Thunderstorm, "thunderstorm", "Heavy snow and thunder"},
+		{"lightssleetshowersandthunder_day", Thunderstorm, "thunderstorm", "Light sleet showers and thunder"},
+		{"sleetshowers_polartwilight", Sleet, "sleet", "Sleet showers"},
+		{"heavysnow", Snow, "snow", "Heavy snow"},
+		{"partly_cloudy", PartlyCloudy, "partly-cloudy", "Partly cloudy"},
+		{" Snow ", Snow, "snow", "Snow"},
+	}
+	for _, tt := range tests {
+		got, ok := Normalize(tt.code)
+		if !ok || got.Code != tt.want || got.Icon != tt.icon || got.Description != tt.description {
+			t.Errorf("Normalize(%q) = %+v, %v; want %s (%s, %q)", tt.code, got, ok, tt.want, tt.icon, tt.description)
+		}
+	}
+
+	for _, code := range []string{"", "42", "9999", "drizzly", "lightfog"} {
+		if got, ok := Normalize(code); ok {
+			t.Errorf("Normalize(%q) = %+v, expected an unknown code", code, got)
+		}
+	}
+}
+
+func TestCanonicalCodesHaveIcons(t *testing.T) {
+	for code, c := range canonical {
+		if c.Icon == "" || c.Description == "" {
+			t.Errorf("Expected an icon and description for %s, got %+v", code, c)
+		}
+	}
+	for number, c := range wmoCodes {
+		if _, ok := canonical[c.Code]; !ok {
+			t.Errorf("WMO code %d maps to unknown code %q", number, c.Code)
+		}
+	}
+	for icon, c := range nwsIcons {
+		if _, ok := canonical[c.Code]; !ok {
+			t.Errorf("NWS icon %s maps to unknown code %q", icon, c.Code)
+		}
+	}
+	for number, c := range tomorrowIOCodes {
+		if _, ok := canonical[c.Code]; !ok {
+			t.Errorf("Tomorrow.io code %d maps to unknown code %q", number, c.Code)
+		}
+	}
+	for number, c := range ecIcons {
+		if _, ok := canonical[c.Code]; !ok {
+			t.Errorf("Environment Canada icon %d maps to unknown code %q", number, c.Code)
+		}
+	}
+}
+
+func TestFromECIcon(t *testing.T) {
+	tests := []struct {
+		code        int
+		want        string
+		description string
+	}{
+		{0, Clear, "Sunny"},
+		{30, Clear, "Clear"},
+		{36, RainShowers, "Light rain shower"},
+		{12, Rain, "Rain"},
+		{46, ThunderstormHail, "Thunderstorm with hail"},
+	}
+	for _, tt := range tests {
+		got, ok := FromECIcon(tt.code)
+		if !ok || got.Code != tt.want || got.Description != tt.description {
+			t.Errorf("FromECIcon(%d) = %+v, %v; want %s (%q)", tt.code, got, ok, tt.want, tt.description)
+		}
+	}
+	for _, code := range []int{-1, 20, 29, 49} {
+		if got, ok := FromECIcon(code); ok {
+			t.Errorf("FromECIcon(%d) = %+v, expected an unknown code", code, got)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+// Package webhook signs and delivers JSON payloads to subscriber URLs.
+//
+// Every delivery carries a SignatureHeader of the form "t=<unix seconds>,v1=<hex>",
+// where the hex value is the HMAC-SHA256 of "<t>.<body>" keyed with the subscription's
+// secret. Receivers recompute it to check the sender and reject deliveries whose
+// timestamp is too old to rule out replays.
+//
+// Subscribers choose the URLs, so deliveries only go to public addresses over https:
+// the address is checked when each connection is dialed, after DNS resolution, and
+// redirects are not followed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Delivery headers
+const (
+	SignatureHeader = "X-Weather-Signature"
+	EventHeader     = "X-Weather-Event"
+	DeliveryHeader  = "X-Weather-Delivery" // unique per delivery, repeated across retries
+)
+
+// SecretLength is the length in bytes of generated secrets
+const SecretLength = 32
+
+// ErrInvalidSignature is returned by Verify for malformed, mismatched and expired
+// signatures
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// ErrForbiddenDestination is returned for webhook URLs that are not https or that
+// reach a loopback, private, link-local or unspecified address
+var ErrForbiddenDestination = errors.New("webhook destination not allowed")
+
+// Destinations decides where webhooks may be delivered. The zero value only allows
+// https URLs of public addresses.
+type Destinations struct {
+	// AllowPrivate also allows http and non-public addresses, for local development
+	AllowPrivate bool
+}
+
+// CheckURL reports whether raw is an absolute URL deliveries may be sent to. Host
+// names are only resolved when dialing, where Client checks the address again.
+func (d Destinations) CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("%w: %q is not an absolute http(s) URL", ErrForbiddenDestination, raw)
+	}
+	if d.AllowPrivate {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("%w: use https", ErrForbiddenDestination)
+	}
+	if addr, err := netip.ParseAddr(strings.Trim(u.Hostname(), "[]")); err == nil && !publicAddr(addr) {
+		return fmt.Errorf("%w: %s is not a public address", ErrForbiddenDestination, addr)
+	}
+	return nil
+}
+
+// control is the net.Dialer Control of Client connections, refusing non-public
+// addresses once DNS has resolved them
+func (d Destinations) control(network, address string, _ syscall.RawConn) error {
+	if d.AllowPrivate {
+		return nil
+	}
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrForbiddenDestination, err)
+	}
+	if !publicAddr(addr.Addr()) {
+		return fmt.Errorf("%w: %s is not a public address", ErrForbiddenDestination, addr.Addr())
+	}
+	return nil
+}
+
+// publicAddr reports whether addr may be reached by webhooks
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return !(addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsUnspecified() || addr.IsMulticast())
+}
+
+// GenerateSecret returns a new random signing secret, hex encoded
+func GenerateSecret() (string, error) {
+	secret := make([]byte, SecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks a SignatureHeader value against body. Signatures older than
+// tolerance are rejected; a zero tolerance accepts any age.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t, v1 string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			v1 = value
+		}
+	}
+	seconds, err := strconv.ParseInt(t, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	signature, err := hex.DecodeString(v1)
+	if err != nil || !hmac.Equal(signature, mac(secret, t, body)) {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 && now.Sub(time.Unix(seconds, 0)).Abs() > tolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp + "."))
+	h.Write(body)
+	return h.Sum(nil)
+}
+
+// Delivery is one payload to send
+type Delivery struct {
+	ID     string // sent in DeliveryHeader so receivers can drop retried duplicates
+	URL    string
+	Secret string
+	Event  string
+	Body   []byte
+}
+
+// Client delivers webhooks, retrying network errors, 429 and 5xx responses with
+// exponential backoff. Other responses outside 2xx, redirects included, are not
+// retried.
+type Client struct {
+	HTTPClient   *http.Client
+	Attempts     int           // total attempts per delivery
+	Backoff      time.Duration // wait before the first retry, doubled for each later one
+	Destinations Destinations
+}
+
+// NewClient creates a client delivering to destinations, making up to 3 attempts, 2 and
+// 4 seconds apart. Its connections bypass proxies so the dialed address is the
+// receiver's.
+func NewClient(destinations Destinations) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{
+		Timeout:   5 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   destinations.control,
+	}).DialContext
+
+	return &Client{
+		HTTPClient: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Attempts:     3,
+		Backoff:      2 * time.Second,
+		Destinations: destinations,
+	}
+}
+
+// Deliver sends d and returns the number of attempts made. Each attempt is signed
+// afresh, so retries carry a current timestamp.
+func (c *Client) Deliver(ctx context.Context, d Delivery) (int, error) {
+	attempts := max(c.Attempts, 1)
+	wait := c.Backoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		var retry bool
+		retry, err = c.send(ctx, d)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry || attempt == attempts {
+			return attempt, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("%w (retry canceled: %v)", err, ctx.Err())
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// send makes one attempt, reporting whether a failure is worth retrying
+func (c *Client) send(ctx context.Context, d Delivery) (bool, error) {
+	if err := c.Destinations.CheckURL(d.URL); err != nil {
+		return false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(d.Body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "weather-api-webhooks/1.0")
+	req.Header.Set(SignatureHeader, Sign(d.Secret, time.Now(), d.Body))
+	req.Header.Set(EventHeader, d.Event)
+	req.Header.Set(DeliveryHeader, d.ID)
+
+	resp, err := c.HTTPClient.Do(req)
+	if errors.Is(err, ErrForbiddenDestination) {
+		return false, err
+	}
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook endpoint returned %s", resp.Status)
+}
@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"city_id":42}`)
+	now := time.Unix(1754900000, 0)
+	header := Sign("secret", now, body)
+
+	if err := Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Errorf("Expected a valid signature, got %v", err)
+	}
+	if err := Verify("other", header, body, now, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a wrong secret to fail, got %v", err)
+	}
+	if err := Verify("secret", header, []byte(`{"city_id":43}`), now, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a modified body to fail, got %v", err)
+	}
+	if err := Verify("secret", header, body, now.Add(time.Hour), 5*time.Minute); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected an old signature to fail, got %v", err)
+	}
+	if err := Verify("secret", "garbage", body, now, 0); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected a malformed header to fail, got %v", err)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret failed: %v", err)
+	}
+	b, _ := GenerateSecret()
+	if len(a) != 2*SecretLength || a == b {
+		t.Errorf("Expected distinct %d-character secrets, got %q and %q", 2*SecretLength, a, b)
+	}
+}
+
+func TestClientDeliver(t *testing.T) {
+	newClient := func() *Client {
+		client := NewClient(Destinations{AllowPrivate: true})
+		client.Backoff = time.Millisecond
+		return client
+	}
+
+	t.Run("Signs the body", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if err := Verify("secret", r.Header.Get(SignatureHeader), body, time.Now(), time.Minute); err != nil {
+				t.Errorf("Expected a verifiable signature, got %v", err)
+			}
+			if r.Header.Get(EventHeader) != "forecast.digest" || r.Header.Get(DeliveryHeader) != "7" {
+				t.Errorf("Unexpected headers %v", r.Header)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		attempts, err := newClient().Deliver(context.Background(), Delivery{
+			ID: "7", URL: server.URL, Secret: "secret", Event: "forecast.digest", Body: []byte(`{}`),
+		})
+		if err != nil || attempts != 1 {
+			t.Errorf("Expected one successful attempt, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("Retries server errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			}
+		}))
+		defer server.Close()
+
+		attempts, err := newClient().Deliver(context.Background(), Delivery{URL: server.URL, Secret: "secret"})
+		if err != nil || attempts != 3 {
+			t.Errorf("Expected success on the third attempt, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("Gives up on client errors", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer server.Close()
+
+		attempts, err := newClient().Deliver(context.Background(), Delivery{URL: server.URL, Secret: "secret"})
+		if err == nil || attempts != 1 || calls.Load() != 1 {
+			t.Errorf("Expected one failed attempt, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("Stops after the last attempt", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		attempts, err := newClient().Deliver(context.Background(), Delivery{URL: server.URL, Secret: "secret"})
+		if err == nil || attempts != 3 {
+			t.Errorf("Expected 3 failed attempts, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("Does not follow redirects", func(t *testing.T) {
+		var redirected atomic.Bool
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			redirected.Store(true)
+		}))
+		defer target.Close()
+		server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+		defer server.Close()
+
+		attempts, err := newClient().Deliver(context.Background(), Delivery{URL: server.URL, Secret: "secret"})
+		if err == nil || attempts != 1 || redirected.Load() {
+			t.Errorf("Expected the redirect to fail the delivery, got %d, %v", attempts, err)
+		}
+	})
+
+	t.Run("Refuses private addresses when dialing", func(t *testing.T) {
+		var calls atomic.Int32
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+		}))
+		defer server.Close()
+
+		url := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+		attempts, err := NewClient(Destinations{}).Deliver(context.Background(), Delivery{URL: url, Secret: "secret"})
+		if !errors.Is(err, ErrForbiddenDestination) || attempts != 1 || calls.Load() != 0 {
+			t.Errorf("Expected the loopback receiver to be refused, got %d, %v", attempts, err)
+		}
+	})
+}
+
+func TestDestinationsCheckURL(t *testing.T) {
+	for _, tc := range []struct {
+		url          string
+		allowPrivate bool
+		allowed      bool
+	}{
+		{url: "https://hooks.example.com/digest", allowed: true},
+		{url: "https://93.184.216.34/hook", allowed: true},
+		{url: "http://hooks.example.com/digest"},
+		{url: "ftp://hooks.example.com/digest"},
+		{url: "/digest"},
+		{url: "https://127.0.0.1/hook"},
+		{url: "https://10.0.0.8/hook"},
+		{url: "https://169.254.169.254/latest/meta-data"},
+		{url: "https://[::1]/hook"},
+		{url: "https://[::ffff:192.168.1.1]/hook"},
+		{url: "https://0.0.0.0/hook"},
+		{url: "http://127.0.0.1:8080/hook", allowPrivate: true, allowed: true},
+		{url: "ftp://127.0.0.1/hook", allowPrivate: true},
+	} {
+		err := Destinations{AllowPrivate: tc.allowPrivate}.CheckURL(tc.url)
+		if tc.allowed && err != nil {
+			t.Errorf("Expected %s to be allowed, got %v", tc.url, err)
+		}
+		if !tc.allowed && !errors.Is(err, ErrForbiddenDestination) {
+			t.Errorf("Expected %s to be refused, got %v", tc.url, err)
+		}
+	}
+}
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS forecasts;
+DROP TABLE IF EXISTS places;
+DROP TABLE IF EXISTS cities;
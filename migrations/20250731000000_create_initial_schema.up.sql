@@ -0,0 +1,89 @@
+-- The core tables the later migrations build on. Deployments created before these
+-- migrations already have them, so every statement is a no-op there.
+CREATE TABLE IF NOT EXISTS cities (
+    id           SERIAL PRIMARY KEY,
+    name         VARCHAR(255)     NOT NULL,
+    country      VARCHAR(100)     NOT NULL DEFAULT '',
+    country_code CHAR(2)          NOT NULL DEFAULT '',
+    region       VARCHAR(100)     NOT NULL DEFAULT '',
+    latitude     DOUBLE PRECISION NOT NULL,
+    longitude    DOUBLE PRECISION NOT NULL,
+    elevation    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    population   INTEGER          NOT NULL DEFAULT 0,
+    timezone     VARCHAR(64)      NOT NULL DEFAULT '',
+    geoname_id   INTEGER          UNIQUE,
+    is_capital   BOOLEAN          NOT NULL DEFAULT FALSE,
+    is_active    BOOLEAN          NOT NULL DEFAULT TRUE,
+    created_at   TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_cities_name ON cities (LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_cities_country_code ON cities (country_code, population DESC);
+CREATE INDEX IF NOT EXISTS idx_cities_location ON cities (latitude, longitude);
+
+CREATE TABLE IF NOT EXISTS places (
+    id              SERIAL PRIMARY KEY,
+    display_name    TEXT             NOT NULL,
+    address_line1   VARCHAR(255)     NOT NULL DEFAULT '',
+    address_line2   VARCHAR(255)     NOT NULL DEFAULT '',
+    city            VARCHAR(255)     NOT NULL DEFAULT '',
+    region          VARCHAR(100)     NOT NULL DEFAULT '',
+    postal_code     VARCHAR(20)      NOT NULL DEFAULT '',
+    country         VARCHAR(100)     NOT NULL DEFAULT '',
+    country_code    CHAR(2)          NOT NULL DEFAULT '',
+    latitude        DOUBLE PRECISION NOT NULL,
+    longitude       DOUBLE PRECISION NOT NULL,
+    place_type      VARCHAR(50)      NOT NULL DEFAULT '',
+    confidence      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    source          VARCHAR(50)      NOT NULL DEFAULT '',
+    source_place_id VARCHAR(255)     NOT NULL DEFAULT '',
+    bounding_box    TEXT             NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_places_location ON places (latitude, longitude);
+CREATE INDEX IF NOT EXISTS idx_places_source ON places (source, source_place_id);
+
+CREATE TABLE IF NOT EXISTS forecasts (
+    id              SERIAL PRIMARY KEY,
+    city_id         INTEGER          NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    source_provider VARCHAR(50)      NOT NULL,
+    forecast_time   TIMESTAMPTZ      NOT NULL,
+    valid_time      TIMESTAMPTZ      NOT NULL,
+    temperature     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    feels_like      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    humidity        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pressure        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_speed      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_direction  DOUBLE PRECISION NOT NULL DEFAULT 0,
+    visibility      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cloud_cover     DOUBLE PRECISION NOT NULL DEFAULT 0,
+    precipitation   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    weather_code    VARCHAR(50)      NOT NULL DEFAULT '',
+    description     TEXT             NOT NULL DEFAULT '',
+    uv_index        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecasts_city_id ON forecasts (city_id);
+CREATE INDEX IF NOT EXISTS idx_forecasts_source_provider ON forecasts (source_provider);
+CREATE INDEX IF NOT EXISTS idx_forecasts_created_at ON forecasts (created_at DESC);
+
+CREATE TABLE IF NOT EXISTS users (
+    id                 SERIAL PRIMARY KEY,
+    github_id          INTEGER      UNIQUE,
+    username           VARCHAR(50)  NOT NULL UNIQUE,
+    email              VARCHAR(254) NOT NULL,
+    avatar_url         TEXT         NOT NULL DEFAULT '',
+    preferred_units    VARCHAR(10)  NOT NULL DEFAULT 'metric',
+    preferred_language VARCHAR(10)  NOT NULL DEFAULT 'en',
+    default_city_id    INTEGER      REFERENCES cities(id) ON DELETE SET NULL,
+    api_key_hash       VARCHAR(64),
+    is_active          BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_login_at      TIMESTAMPTZ
+);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS alerts (
+    id                SERIAL PRIMARY KEY,
+    source_provider   VARCHAR(50)  NOT NULL,
+    provider_alert_id VARCHAR(255) NOT NULL,
+    city_id           INTEGER REFERENCES cities(id) ON DELETE SET NULL,
+    latitude          DOUBLE PRECISION NOT NULL DEFAULT 0,
+    longitude         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    title             VARCHAR(255) NOT NULL,
+    description       TEXT         NOT NULL DEFAULT '',
+    severity          VARCHAR(20)  NOT NULL DEFAULT '',
+    urgency           VARCHAR(20)  NOT NULL DEFAULT '',
+    category          VARCHAR(20)  NOT NULL DEFAULT '',
+    area_desc         TEXT         NOT NULL DEFAULT '',
+    start_time        TIMESTAMPTZ,
+    end_time          TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (source_provider, provider_alert_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_city_id ON alerts (city_id);
+CREATE INDEX IF NOT EXISTS idx_alerts_end_time ON alerts (end_time);
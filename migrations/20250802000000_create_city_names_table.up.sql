@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS city_names (
+    city_id      INTEGER      NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    language     VARCHAR(16)  NOT NULL,
+    name         VARCHAR(255) NOT NULL,
+    is_preferred BOOLEAN      NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (city_id, language)
+);
@@ -0,0 +1,6 @@
+-- Local version vector for reference-data replication: one counter per deployment
+CREATE TABLE IF NOT EXISTS replication_vector (
+    node_id    VARCHAR(64) PRIMARY KEY,
+    counter    BIGINT      NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
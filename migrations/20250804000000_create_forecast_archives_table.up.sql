@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS forecast_archives (
+    city_id    INTEGER     NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    day        DATE        NOT NULL,
+    row_count  INTEGER     NOT NULL,
+    min_id     INTEGER     NOT NULL,
+    max_id     INTEGER     NOT NULL,
+    payload    BYTEA       NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (city_id, day)
+);
+
+CREATE INDEX IF NOT EXISTS idx_forecast_archives_day ON forecast_archives (day);
+CREATE INDEX IF NOT EXISTS idx_forecast_archives_ids ON forecast_archives (min_id, max_id);
@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS aviation_reports (
+    id              SERIAL PRIMARY KEY,
+    station_id      VARCHAR(4)   NOT NULL,
+    report_type     VARCHAR(5)   NOT NULL,
+    source_provider VARCHAR(50)  NOT NULL,
+    raw_text        TEXT         NOT NULL,
+    observed_at     TIMESTAMPTZ  NOT NULL,
+    valid_from      TIMESTAMPTZ,
+    valid_to        TIMESTAMPTZ,
+    latitude        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    longitude       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    temperature     DOUBLE PRECISION,
+    dewpoint        DOUBLE PRECISION,
+    wind_direction  DOUBLE PRECISION,
+    wind_speed      DOUBLE PRECISION,
+    wind_gust       DOUBLE PRECISION,
+    visibility      DOUBLE PRECISION,
+    pressure        DOUBLE PRECISION,
+    flight_category VARCHAR(4)   NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (station_id, report_type, observed_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_aviation_reports_station ON aviation_reports (station_id, report_type, observed_at DESC);
@@ -0,0 +1,2 @@
+COMMENT ON COLUMN forecasts.pressure IS NULL;
+ALTER TABLE forecasts DROP COLUMN IF EXISTS station_pressure;
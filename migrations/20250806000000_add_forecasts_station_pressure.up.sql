@@ -0,0 +1,4 @@
+ALTER TABLE forecasts ADD COLUMN IF NOT EXISTS station_pressure DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN forecasts.pressure IS 'Mean sea-level pressure (hPa)';
+COMMENT ON COLUMN forecasts.station_pressure IS 'Pressure at station elevation (hPa), 0 when unknown';
@@ -0,0 +1 @@
+ALTER TABLE forecasts DROP COLUMN IF EXISTS wind_gust;
@@ -0,0 +1,3 @@
+ALTER TABLE forecasts ADD COLUMN IF NOT EXISTS wind_gust DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN forecasts.wind_gust IS 'Wind gust (m/s), 0 when not reported';
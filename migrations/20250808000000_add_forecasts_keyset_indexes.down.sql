@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_forecasts_city_valid_time_id;
+DROP INDEX IF EXISTS idx_forecasts_valid_time_id;
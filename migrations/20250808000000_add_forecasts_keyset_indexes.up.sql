@@ -0,0 +1,3 @@
+-- Keyset pagination pages by (valid_time, id), newest first
+CREATE INDEX IF NOT EXISTS idx_forecasts_valid_time_id ON forecasts (valid_time DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_forecasts_city_valid_time_id ON forecasts (city_id, valid_time DESC, id DESC);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS share_links (
+    id            SERIAL PRIMARY KEY,
+    user_id       INTEGER      NOT NULL,
+    resource_type VARCHAR(10)  NOT NULL CHECK (resource_type IN ('forecast', 'export')),
+    resource_id   VARCHAR(64)  NOT NULL,
+    expires_at    TIMESTAMPTZ  NOT NULL,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_share_links_user ON share_links (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_share_links_expires ON share_links (expires_at);
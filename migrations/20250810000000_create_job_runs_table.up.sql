@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS job_runs (
+    id           SERIAL PRIMARY KEY,
+    job_name     VARCHAR(64)  NOT NULL,
+    triggered_by VARCHAR(10)  NOT NULL CHECK (triggered_by IN ('schedule', 'manual', 'retry')),
+    retry_of     INTEGER      REFERENCES job_runs(id) ON DELETE SET NULL,
+    status       VARCHAR(10)  NOT NULL CHECK (status IN ('running', 'succeeded', 'partial', 'failed')),
+    started_at   TIMESTAMPTZ  NOT NULL,
+    finished_at  TIMESTAMPTZ,
+    duration_ms  BIGINT       NOT NULL DEFAULT 0,
+    processed    INTEGER      NOT NULL DEFAULT 0,
+    skipped      JSONB        NOT NULL DEFAULT '[]',
+    error        TEXT         NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_runs_job ON job_runs (job_name, started_at DESC);
+CREATE INDEX IF NOT EXISTS idx_job_runs_running ON job_runs (status) WHERE status = 'running';
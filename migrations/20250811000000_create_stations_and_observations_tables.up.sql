@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS stations (
+    id              SERIAL PRIMARY KEY,
+    station_id      VARCHAR(10)  NOT NULL UNIQUE,
+    name            VARCHAR(255) NOT NULL DEFAULT '',
+    source_provider VARCHAR(50)  NOT NULL,
+    latitude        DOUBLE PRECISION NOT NULL,
+    longitude       DOUBLE PRECISION NOT NULL,
+    elevation       DOUBLE PRECISION,
+    time_zone       VARCHAR(64)  NOT NULL DEFAULT '',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS observations (
+    id               SERIAL PRIMARY KEY,
+    station_id       VARCHAR(10)  NOT NULL REFERENCES stations(station_id) ON DELETE CASCADE,
+    source_provider  VARCHAR(50)  NOT NULL,
+    observed_at      TIMESTAMPTZ  NOT NULL,
+    temperature      DOUBLE PRECISION,
+    dewpoint         DOUBLE PRECISION,
+    humidity         DOUBLE PRECISION,
+    wind_direction   DOUBLE PRECISION,
+    wind_speed       DOUBLE PRECISION,
+    wind_gust        DOUBLE PRECISION,
+    pressure         DOUBLE PRECISION,
+    station_pressure DOUBLE PRECISION,
+    visibility       DOUBLE PRECISION,
+    description      TEXT         NOT NULL DEFAULT '',
+    qc_flags         JSONB        NOT NULL DEFAULT '{}',
+    created_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (station_id, observed_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_observations_station ON observations (station_id, observed_at DESC);
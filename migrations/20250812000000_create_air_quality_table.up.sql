@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS air_quality (
+    id              SERIAL PRIMARY KEY,
+    latitude        DOUBLE PRECISION NOT NULL,
+    longitude       DOUBLE PRECISION NOT NULL,
+    source_provider VARCHAR(50)  NOT NULL,
+    observed_at     TIMESTAMPTZ  NOT NULL,
+    aqi             INTEGER      NOT NULL CHECK (aqi >= 0),
+    pm2_5           DOUBLE PRECISION,
+    pm10            DOUBLE PRECISION,
+    ozone           DOUBLE PRECISION,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    UNIQUE (latitude, longitude, observed_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_air_quality_point ON air_quality (latitude, longitude, observed_at DESC);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS digests (
+    id          SERIAL PRIMARY KEY,
+    user_id     INTEGER      NOT NULL,
+    city_id     INTEGER      NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    url         TEXT         NOT NULL,
+    secret      VARCHAR(128) NOT NULL,
+    local_time  CHAR(5)      NOT NULL,
+    timezone    VARCHAR(64)  NOT NULL,
+    days        INTEGER      NOT NULL CHECK (days BETWEEN 1 AND 7),
+    next_run_at TIMESTAMPTZ  NOT NULL,
+    last_run_at TIMESTAMPTZ,
+    last_status VARCHAR(10)  NOT NULL DEFAULT '',
+    last_error  TEXT         NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_digests_user ON digests (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_digests_next_run ON digests (next_run_at);
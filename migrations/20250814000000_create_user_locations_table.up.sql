@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS user_locations (
+    id         SERIAL PRIMARY KEY,
+    user_id    INTEGER          NOT NULL,
+    name       VARCHAR(64)      NOT NULL,
+    city_id    INTEGER          REFERENCES cities(id) ON DELETE SET NULL,
+    latitude   DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude  DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    is_default BOOLEAN          NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_locations_name ON user_locations (user_id, LOWER(name));
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS alert_subscriptions (
+    id               SERIAL PRIMARY KEY,
+    user_id          INTEGER          NOT NULL,
+    email            VARCHAR(254)     NOT NULL,
+    latitude         DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude        DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    radius_km        DOUBLE PRECISION NOT NULL CHECK (radius_km > 0),
+    min_severity     VARCHAR(10)      NOT NULL CHECK (min_severity IN ('Unknown', 'Minor', 'Moderate', 'Severe', 'Extreme')),
+    mode             VARCHAR(10)      NOT NULL CHECK (mode IN ('immediate', 'digest')),
+    token            VARCHAR(64)      NOT NULL UNIQUE,
+    last_notified_at TIMESTAMPTZ,
+    created_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_subscriptions_user ON alert_subscriptions (user_id, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS alert_notifications (
+    id              SERIAL PRIMARY KEY,
+    subscription_id INTEGER     NOT NULL REFERENCES alert_subscriptions(id) ON DELETE CASCADE,
+    alert_id        INTEGER     NOT NULL REFERENCES alerts(id) ON DELETE CASCADE,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    sent_at         TIMESTAMPTZ,
+    UNIQUE (subscription_id, alert_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_notifications_pending ON alert_notifications (subscription_id) WHERE sent_at IS NULL;
@@ -0,0 +1,22 @@
+-- The users table predates these migrations on existing deployments; create it for
+-- new ones so roles can be added either way
+CREATE TABLE IF NOT EXISTS users (
+    id                 SERIAL PRIMARY KEY,
+    github_id          INTEGER      UNIQUE,
+    username           VARCHAR(50)  NOT NULL UNIQUE,
+    email              VARCHAR(254) NOT NULL,
+    avatar_url         TEXT         NOT NULL DEFAULT '',
+    preferred_units    VARCHAR(10)  NOT NULL DEFAULT 'metric',
+    preferred_language VARCHAR(10)  NOT NULL DEFAULT 'en',
+    default_city_id    INTEGER      REFERENCES cities(id) ON DELETE SET NULL,
+    api_key_hash       VARCHAR(64),
+    is_active          BOOLEAN      NOT NULL DEFAULT TRUE,
+    created_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    last_login_at      TIMESTAMPTZ
+);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS role VARCHAR(10) NOT NULL DEFAULT 'user'
+    CHECK (role IN ('admin', 'user', 'readonly'));
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_api_key_hash ON users (api_key_hash) WHERE api_key_hash IS NOT NULL;
@@ -0,0 +1,48 @@
+ALTER TABLE forecasts RENAME TO forecasts_partitioned;
+ALTER INDEX IF EXISTS forecasts_pkey RENAME TO forecasts_partitioned_pkey;
+ALTER SEQUENCE forecasts_id_seq OWNED BY NONE;
+
+CREATE TABLE forecasts (
+    id               INTEGER          PRIMARY KEY DEFAULT nextval('forecasts_id_seq'),
+    city_id          INTEGER          NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    source_provider  VARCHAR(50)      NOT NULL,
+    forecast_time    TIMESTAMPTZ      NOT NULL,
+    valid_time       TIMESTAMPTZ      NOT NULL,
+    temperature      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    feels_like       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    humidity         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pressure         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_speed       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_direction   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    visibility       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cloud_cover      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    precipitation    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    weather_code     VARCHAR(50)      NOT NULL DEFAULT '',
+    description      TEXT             NOT NULL DEFAULT '',
+    uv_index         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    station_pressure DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_gust        DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+INSERT INTO forecasts SELECT
+    id, city_id, source_provider, forecast_time, valid_time, temperature, feels_like,
+    humidity, pressure, wind_speed, wind_direction, visibility, cloud_cover,
+    precipitation, weather_code, description, uv_index, created_at, updated_at,
+    station_pressure, wind_gust
+FROM forecasts_partitioned;
+
+DROP TABLE forecasts_partitioned;
+DROP FUNCTION IF EXISTS create_forecast_partition(DATE);
+DROP FUNCTION IF EXISTS drop_forecast_partitions(TIMESTAMPTZ);
+ALTER SEQUENCE forecasts_id_seq OWNED BY forecasts.id;
+
+COMMENT ON COLUMN forecasts.pressure IS 'Mean sea-level pressure (hPa)';
+COMMENT ON COLUMN forecasts.station_pressure IS 'Pressure at station elevation (hPa), 0 when unknown';
+
+CREATE INDEX idx_forecasts_city_id ON forecasts (city_id);
+CREATE INDEX idx_forecasts_source_provider ON forecasts (source_provider);
+CREATE INDEX idx_forecasts_created_at ON forecasts (created_at DESC);
+CREATE INDEX idx_forecasts_valid_time_id ON forecasts (valid_time DESC, id DESC);
+CREATE INDEX idx_forecasts_city_valid_time_id ON forecasts (city_id, valid_time DESC, id DESC);
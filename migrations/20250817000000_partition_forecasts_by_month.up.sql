@@ -0,0 +1,117 @@
+-- Forecasts are range partitioned by the month of valid_time into forecasts_pYYYYMM
+-- tables, so retention drops whole months instead of deleting them row by row. Rows of
+-- a month without a partition land in forecasts_default until the month is created.
+ALTER TABLE forecasts RENAME TO forecasts_unpartitioned;
+ALTER INDEX IF EXISTS forecasts_pkey RENAME TO forecasts_unpartitioned_pkey;
+ALTER SEQUENCE forecasts_id_seq OWNED BY NONE;
+
+CREATE TABLE forecasts (
+    id               INTEGER          NOT NULL DEFAULT nextval('forecasts_id_seq'),
+    city_id          INTEGER          NOT NULL REFERENCES cities(id) ON DELETE CASCADE,
+    source_provider  VARCHAR(50)      NOT NULL,
+    forecast_time    TIMESTAMPTZ      NOT NULL,
+    valid_time       TIMESTAMPTZ      NOT NULL,
+    temperature      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    feels_like       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    humidity         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    pressure         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_speed       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_direction   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    visibility       DOUBLE PRECISION NOT NULL DEFAULT 0,
+    cloud_cover      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    precipitation    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    weather_code     VARCHAR(50)      NOT NULL DEFAULT '',
+    description      TEXT             NOT NULL DEFAULT '',
+    uv_index         DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMPTZ      NOT NULL DEFAULT NOW(),
+    station_pressure DOUBLE PRECISION NOT NULL DEFAULT 0,
+    wind_gust        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    PRIMARY KEY (id, valid_time)
+) PARTITION BY RANGE (valid_time);
+
+ALTER SEQUENCE forecasts_id_seq OWNED BY forecasts.id;
+
+COMMENT ON COLUMN forecasts.pressure IS 'Mean sea-level pressure (hPa)';
+COMMENT ON COLUMN forecasts.station_pressure IS 'Pressure at station elevation (hPa), 0 when unknown';
+
+CREATE TABLE forecasts_default PARTITION OF forecasts DEFAULT;
+
+-- create_forecast_partition creates the partition of the month of target (UTC), moving
+-- the month's rows out of forecasts_default, and reports whether it had to
+CREATE OR REPLACE FUNCTION create_forecast_partition(target DATE) RETURNS BOOLEAN
+LANGUAGE plpgsql AS $$
+DECLARE
+    first_day      DATE        := date_trunc('month', target::TIMESTAMP)::DATE;
+    lower_bound    TIMESTAMPTZ := first_day::TIMESTAMP AT TIME ZONE 'UTC';
+    upper_bound    TIMESTAMPTZ := (first_day + INTERVAL '1 month') AT TIME ZONE 'UTC';
+    partition_name TEXT        := 'forecasts_p' || to_char(first_day, 'YYYYMM');
+BEGIN
+    -- Replicas running the partition job together create each month once
+    PERFORM pg_advisory_xact_lock(hashtext('create_forecast_partition'));
+    IF to_regclass(partition_name) IS NOT NULL THEN
+        RETURN FALSE;
+    END IF;
+
+    EXECUTE format('CREATE TABLE %I (LIKE forecasts INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', partition_name);
+    EXECUTE format('WITH moved AS (DELETE FROM forecasts_default WHERE valid_time >= $1 AND valid_time < $2 RETURNING *)
+        INSERT INTO %I SELECT * FROM moved', partition_name) USING lower_bound, upper_bound;
+    EXECUTE format('ALTER TABLE forecasts ATTACH PARTITION %I FOR VALUES FROM (%L) TO (%L)', partition_name, lower_bound, upper_bound);
+    RETURN TRUE;
+END $$;
+
+-- drop_forecast_partitions drops the monthly partitions ending at or before cutoff and
+-- returns the number of rows they held
+CREATE OR REPLACE FUNCTION drop_forecast_partitions(cutoff TIMESTAMPTZ) RETURNS BIGINT
+LANGUAGE plpgsql AS $$
+DECLARE
+    partition_name TEXT;
+    partition_rows BIGINT;
+    dropped        BIGINT := 0;
+BEGIN
+    FOR partition_name IN
+        SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'forecasts'::REGCLASS AND c.relname ~ '^forecasts_p[0-9]{6}$'
+        ORDER BY c.relname
+    LOOP
+        IF (to_date(substr(partition_name, 12), 'YYYYMM') + INTERVAL '1 month') AT TIME ZONE 'UTC' <= cutoff THEN
+            EXECUTE format('SELECT COUNT(*) FROM %I', partition_name) INTO partition_rows;
+            EXECUTE format('DROP TABLE %I', partition_name);
+            dropped := dropped + partition_rows;
+        END IF;
+    END LOOP;
+    RETURN dropped;
+END $$;
+
+-- Partition the last year of existing forecasts and the next two months; anything
+-- older stays in forecasts_default until retention deletes it
+SELECT create_forecast_partition(month_start::DATE)
+FROM generate_series(
+    date_trunc('month', GREATEST(
+        COALESCE((SELECT MIN(valid_time) FROM forecasts_unpartitioned), NOW()),
+        NOW() - INTERVAL '1 year'
+    ) AT TIME ZONE 'UTC'),
+    date_trunc('month', NOW() AT TIME ZONE 'UTC') + INTERVAL '2 months',
+    INTERVAL '1 month'
+) AS months(month_start);
+
+INSERT INTO forecasts (
+    id, city_id, source_provider, forecast_time, valid_time, temperature, feels_like,
+    humidity, pressure, wind_speed, wind_direction, visibility, cloud_cover,
+    precipitation, weather_code, description, uv_index, created_at, updated_at,
+    station_pressure, wind_gust
+)
+SELECT
+    id, city_id, source_provider, forecast_time, valid_time, temperature, feels_like,
+    humidity, pressure, wind_speed, wind_direction, visibility, cloud_cover,
+    precipitation, weather_code, description, uv_index, created_at, updated_at,
+    station_pressure, wind_gust
+FROM forecasts_unpartitioned;
+
+DROP TABLE forecasts_unpartitioned;
+
+CREATE INDEX idx_forecasts_city_id ON forecasts (city_id);
+CREATE INDEX idx_forecasts_source_provider ON forecasts (source_provider);
+CREATE INDEX idx_forecasts_created_at ON forecasts (created_at DESC);
+CREATE INDEX idx_forecasts_valid_time_id ON forecasts (valid_time DESC, id DESC);
+CREATE INDEX idx_forecasts_city_valid_time_id ON forecasts (city_id, valid_time DESC, id DESC);
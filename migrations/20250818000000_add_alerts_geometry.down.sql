@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_alerts_geometry_bounds;
+ALTER TABLE alerts
+    DROP COLUMN IF EXISTS max_longitude,
+    DROP COLUMN IF EXISTS max_latitude,
+    DROP COLUMN IF EXISTS min_longitude,
+    DROP COLUMN IF EXISTS min_latitude,
+    DROP COLUMN IF EXISTS geometry;
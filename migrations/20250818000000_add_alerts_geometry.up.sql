@@ -0,0 +1,12 @@
+ALTER TABLE alerts
+    ADD COLUMN IF NOT EXISTS geometry      JSONB,
+    ADD COLUMN IF NOT EXISTS min_latitude  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS min_longitude DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS max_latitude  DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS max_longitude DOUBLE PRECISION;
+
+COMMENT ON COLUMN alerts.geometry IS 'GeoJSON Polygon or MultiPolygon of the alert area, NULL when the provider sends none';
+COMMENT ON COLUMN alerts.min_latitude IS 'Bounding box of geometry, written with it to prefilter point lookups';
+
+CREATE INDEX IF NOT EXISTS idx_alerts_geometry_bounds ON alerts (min_latitude, max_latitude)
+    WHERE geometry IS NOT NULL;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS kv_entries (
+    key        TEXT        PRIMARY KEY,
+    value      BYTEA       NOT NULL,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_kv_entries_expires ON kv_entries (expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,13 @@
+// Package migrations embeds the SQL schema migrations so the binary can migrate a
+// database without the migrations directory on disk.
+//
+// Files are named <version>_<name>.<up|down>.sql, versions being UTC timestamps
+// (20060102150405) applied in order by golang-migrate.
+package migrations
+
+import "embed"
+
+// FS holds every migration file of this directory
+//
+//go:embed *.sql
+var FS embed.FS
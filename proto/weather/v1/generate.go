@@ -0,0 +1,4 @@
+// Package weatherv1 holds the Go messages and gRPC stubs generated from weather.proto.
+package weatherv1
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative weather/v1/weather.proto
@@ -0,0 +1,1611 @@
+// The weather API's gRPC service, served on --grpc-port. Messages mirror the REST API's
+// JSON: measurements are metric and times are RFC 3339 strings.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: weather/v1/weather.proto
+
+package weatherv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type City struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Country       string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	CountryCode   string                 `protobuf:"bytes,4,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	Region        string                 `protobuf:"bytes,5,opt,name=region,proto3" json:"region,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,6,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,7,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Elevation     float64                `protobuf:"fixed64,8,opt,name=elevation,proto3" json:"elevation,omitempty"`
+	Population    int64                  `protobuf:"varint,9,opt,name=population,proto3" json:"population,omitempty"`
+	Timezone      string                 `protobuf:"bytes,10,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	GeonameId     int64                  `protobuf:"varint,11,opt,name=geoname_id,json=geonameId,proto3" json:"geoname_id,omitempty"`
+	IsCapital     bool                   `protobuf:"varint,12,opt,name=is_capital,json=isCapital,proto3" json:"is_capital,omitempty"`
+	IsActive      bool                   `protobuf:"varint,13,opt,name=is_active,json=isActive,proto3" json:"is_active,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,14,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,15,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *City) Reset() {
+	*x = City{}
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *City) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*City) ProtoMessage() {}
+
+func (x *City) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use City.ProtoReflect.Descriptor instead.
+func (*City) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *City) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *City) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *City) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *City) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *City) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *City) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *City) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *City) GetElevation() float64 {
+	if x != nil {
+		return x.Elevation
+	}
+	return 0
+}
+
+func (x *City) GetPopulation() int64 {
+	if x != nil {
+		return x.Population
+	}
+	return 0
+}
+
+func (x *City) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *City) GetGeonameId() int64 {
+	if x != nil {
+		return x.GeonameId
+	}
+	return 0
+}
+
+func (x *City) GetIsCapital() bool {
+	if x != nil {
+		return x.IsCapital
+	}
+	return false
+}
+
+func (x *City) GetIsActive() bool {
+	if x != nil {
+		return x.IsActive
+	}
+	return false
+}
+
+func (x *City) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *City) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type Place struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	DisplayName   string                 `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	AddressLine1  string                 `protobuf:"bytes,3,opt,name=address_line1,json=addressLine1,proto3" json:"address_line1,omitempty"`
+	AddressLine2  string                 `protobuf:"bytes,4,opt,name=address_line2,json=addressLine2,proto3" json:"address_line2,omitempty"`
+	City          string                 `protobuf:"bytes,5,opt,name=city,proto3" json:"city,omitempty"`
+	Region        string                 `protobuf:"bytes,6,opt,name=region,proto3" json:"region,omitempty"`
+	PostalCode    string                 `protobuf:"bytes,7,opt,name=postal_code,json=postalCode,proto3" json:"postal_code,omitempty"`
+	Country       string                 `protobuf:"bytes,8,opt,name=country,proto3" json:"country,omitempty"`
+	CountryCode   string                 `protobuf:"bytes,9,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	Latitude      float64                `protobuf:"fixed64,10,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,11,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	PlaceType     string                 `protobuf:"bytes,12,opt,name=place_type,json=placeType,proto3" json:"place_type,omitempty"`
+	Confidence    float64                `protobuf:"fixed64,13,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	Source        string                 `protobuf:"bytes,14,opt,name=source,proto3" json:"source,omitempty"`
+	SourcePlaceId string                 `protobuf:"bytes,15,opt,name=source_place_id,json=sourcePlaceId,proto3" json:"source_place_id,omitempty"`
+	BoundingBox   string                 `protobuf:"bytes,16,opt,name=bounding_box,json=boundingBox,proto3" json:"bounding_box,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,17,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     string                 `protobuf:"bytes,18,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Place) Reset() {
+	*x = Place{}
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Place) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Place) ProtoMessage() {}
+
+func (x *Place) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Place.ProtoReflect.Descriptor instead.
+func (*Place) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Place) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Place) GetDisplayName() string {
+	if x != nil {
+		return x.DisplayName
+	}
+	return ""
+}
+
+func (x *Place) GetAddressLine1() string {
+	if x != nil {
+		return x.AddressLine1
+	}
+	return ""
+}
+
+func (x *Place) GetAddressLine2() string {
+	if x != nil {
+		return x.AddressLine2
+	}
+	return ""
+}
+
+func (x *Place) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Place) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *Place) GetPostalCode() string {
+	if x != nil {
+		return x.PostalCode
+	}
+	return ""
+}
+
+func (x *Place) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *Place) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *Place) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Place) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Place) GetPlaceType() string {
+	if x != nil {
+		return x.PlaceType
+	}
+	return ""
+}
+
+func (x *Place) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *Place) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Place) GetSourcePlaceId() string {
+	if x != nil {
+		return x.SourcePlaceId
+	}
+	return ""
+}
+
+func (x *Place) GetBoundingBox() string {
+	if x != nil {
+		return x.BoundingBox
+	}
+	return ""
+}
+
+func (x *Place) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Place) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type Forecast struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	CityId          int64                  `protobuf:"varint,2,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	SourceProvider  string                 `protobuf:"bytes,3,opt,name=source_provider,json=sourceProvider,proto3" json:"source_provider,omitempty"`
+	ForecastTime    string                 `protobuf:"bytes,4,opt,name=forecast_time,json=forecastTime,proto3" json:"forecast_time,omitempty"`
+	ValidTime       string                 `protobuf:"bytes,5,opt,name=valid_time,json=validTime,proto3" json:"valid_time,omitempty"`
+	Temperature     float64                `protobuf:"fixed64,6,opt,name=temperature,proto3" json:"temperature,omitempty"`
+	FeelsLike       float64                `protobuf:"fixed64,7,opt,name=feels_like,json=feelsLike,proto3" json:"feels_like,omitempty"`
+	Humidity        float64                `protobuf:"fixed64,8,opt,name=humidity,proto3" json:"humidity,omitempty"`
+	Pressure        float64                `protobuf:"fixed64,9,opt,name=pressure,proto3" json:"pressure,omitempty"`
+	StationPressure float64                `protobuf:"fixed64,10,opt,name=station_pressure,json=stationPressure,proto3" json:"station_pressure,omitempty"`
+	WindSpeed       float64                `protobuf:"fixed64,11,opt,name=wind_speed,json=windSpeed,proto3" json:"wind_speed,omitempty"`
+	WindDirection   float64                `protobuf:"fixed64,12,opt,name=wind_direction,json=windDirection,proto3" json:"wind_direction,omitempty"`
+	WindGust        float64                `protobuf:"fixed64,13,opt,name=wind_gust,json=windGust,proto3" json:"wind_gust,omitempty"`
+	Visibility      float64                `protobuf:"fixed64,14,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	CloudCover      float64                `protobuf:"fixed64,15,opt,name=cloud_cover,json=cloudCover,proto3" json:"cloud_cover,omitempty"`
+	Precipitation   float64                `protobuf:"fixed64,16,opt,name=precipitation,proto3" json:"precipitation,omitempty"`
+	WeatherCode     string                 `protobuf:"bytes,17,opt,name=weather_code,json=weatherCode,proto3" json:"weather_code,omitempty"`
+	Description     string                 `protobuf:"bytes,18,opt,name=description,proto3" json:"description,omitempty"`
+	UvIndex         float64                `protobuf:"fixed64,19,opt,name=uv_index,json=uvIndex,proto3" json:"uv_index,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,20,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string                 `protobuf:"bytes,21,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Forecast) Reset() {
+	*x = Forecast{}
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Forecast) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Forecast) ProtoMessage() {}
+
+func (x *Forecast) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Forecast.ProtoReflect.Descriptor instead.
+func (*Forecast) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Forecast) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Forecast) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *Forecast) GetSourceProvider() string {
+	if x != nil {
+		return x.SourceProvider
+	}
+	return ""
+}
+
+func (x *Forecast) GetForecastTime() string {
+	if x != nil {
+		return x.ForecastTime
+	}
+	return ""
+}
+
+func (x *Forecast) GetValidTime() string {
+	if x != nil {
+		return x.ValidTime
+	}
+	return ""
+}
+
+func (x *Forecast) GetTemperature() float64 {
+	if x != nil {
+		return x.Temperature
+	}
+	return 0
+}
+
+func (x *Forecast) GetFeelsLike() float64 {
+	if x != nil {
+		return x.FeelsLike
+	}
+	return 0
+}
+
+func (x *Forecast) GetHumidity() float64 {
+	if x != nil {
+		return x.Humidity
+	}
+	return 0
+}
+
+func (x *Forecast) GetPressure() float64 {
+	if x != nil {
+		return x.Pressure
+	}
+	return 0
+}
+
+func (x *Forecast) GetStationPressure() float64 {
+	if x != nil {
+		return x.StationPressure
+	}
+	return 0
+}
+
+func (x *Forecast) GetWindSpeed() float64 {
+	if x != nil {
+		return x.WindSpeed
+	}
+	return 0
+}
+
+func (x *Forecast) GetWindDirection() float64 {
+	if x != nil {
+		return x.WindDirection
+	}
+	return 0
+}
+
+func (x *Forecast) GetWindGust() float64 {
+	if x != nil {
+		return x.WindGust
+	}
+	return 0
+}
+
+func (x *Forecast) GetVisibility() float64 {
+	if x != nil {
+		return x.Visibility
+	}
+	return 0
+}
+
+func (x *Forecast) GetCloudCover() float64 {
+	if x != nil {
+		return x.CloudCover
+	}
+	return 0
+}
+
+func (x *Forecast) GetPrecipitation() float64 {
+	if x != nil {
+		return x.Precipitation
+	}
+	return 0
+}
+
+func (x *Forecast) GetWeatherCode() string {
+	if x != nil {
+		return x.WeatherCode
+	}
+	return ""
+}
+
+func (x *Forecast) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Forecast) GetUvIndex() float64 {
+	if x != nil {
+		return x.UvIndex
+	}
+	return 0
+}
+
+func (x *Forecast) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Forecast) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type Alert struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SourceProvider  string                 `protobuf:"bytes,2,opt,name=source_provider,json=sourceProvider,proto3" json:"source_provider,omitempty"`
+	ProviderAlertId string                 `protobuf:"bytes,3,opt,name=provider_alert_id,json=providerAlertId,proto3" json:"provider_alert_id,omitempty"`
+	CityId          int64                  `protobuf:"varint,4,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	Latitude        float64                `protobuf:"fixed64,5,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude       float64                `protobuf:"fixed64,6,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	Title           string                 `protobuf:"bytes,7,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string                 `protobuf:"bytes,8,opt,name=description,proto3" json:"description,omitempty"`
+	Severity        string                 `protobuf:"bytes,9,opt,name=severity,proto3" json:"severity,omitempty"`
+	Urgency         string                 `protobuf:"bytes,10,opt,name=urgency,proto3" json:"urgency,omitempty"`
+	Category        string                 `protobuf:"bytes,11,opt,name=category,proto3" json:"category,omitempty"`
+	AreaDesc        string                 `protobuf:"bytes,12,opt,name=area_desc,json=areaDesc,proto3" json:"area_desc,omitempty"`
+	StartTime       string                 `protobuf:"bytes,13,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	EndTime         string                 `protobuf:"bytes,14,opt,name=end_time,json=endTime,proto3" json:"end_time,omitempty"`
+	CreatedAt       string                 `protobuf:"bytes,15,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       string                 `protobuf:"bytes,16,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Alert) Reset() {
+	*x = Alert{}
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Alert) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Alert) ProtoMessage() {}
+
+func (x *Alert) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Alert.ProtoReflect.Descriptor instead.
+func (*Alert) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Alert) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Alert) GetSourceProvider() string {
+	if x != nil {
+		return x.SourceProvider
+	}
+	return ""
+}
+
+func (x *Alert) GetProviderAlertId() string {
+	if x != nil {
+		return x.ProviderAlertId
+	}
+	return ""
+}
+
+func (x *Alert) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *Alert) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Alert) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *Alert) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Alert) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Alert) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Alert) GetUrgency() string {
+	if x != nil {
+		return x.Urgency
+	}
+	return ""
+}
+
+func (x *Alert) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *Alert) GetAreaDesc() string {
+	if x != nil {
+		return x.AreaDesc
+	}
+	return ""
+}
+
+func (x *Alert) GetStartTime() string {
+	if x != nil {
+		return x.StartTime
+	}
+	return ""
+}
+
+func (x *Alert) GetEndTime() string {
+	if x != nil {
+		return x.EndTime
+	}
+	return ""
+}
+
+func (x *Alert) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Alert) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+type GetCityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCityRequest) Reset() {
+	*x = GetCityRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCityRequest) ProtoMessage() {}
+
+func (x *GetCityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCityRequest.ProtoReflect.Descriptor instead.
+func (*GetCityRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetCityRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ListCitiesRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Search      string                 `protobuf:"bytes,1,opt,name=search,proto3" json:"search,omitempty"`
+	CountryCode string                 `protobuf:"bytes,2,opt,name=country_code,json=countryCode,proto3" json:"country_code,omitempty"`
+	// Defaults to the REST page size and is capped at its maximum
+	PageSize      int32 `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	Offset        int32 `protobuf:"varint,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCitiesRequest) Reset() {
+	*x = ListCitiesRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCitiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCitiesRequest) ProtoMessage() {}
+
+func (x *ListCitiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCitiesRequest.ProtoReflect.Descriptor instead.
+func (*ListCitiesRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListCitiesRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+func (x *ListCitiesRequest) GetCountryCode() string {
+	if x != nil {
+		return x.CountryCode
+	}
+	return ""
+}
+
+func (x *ListCitiesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCitiesRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type ListCitiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cities        []*City                `protobuf:"bytes,1,rep,name=cities,proto3" json:"cities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCitiesResponse) Reset() {
+	*x = ListCitiesResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCitiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCitiesResponse) ProtoMessage() {}
+
+func (x *ListCitiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCitiesResponse.ProtoReflect.Descriptor instead.
+func (*ListCitiesResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListCitiesResponse) GetCities() []*City {
+	if x != nil {
+		return x.Cities
+	}
+	return nil
+}
+
+type GetPlaceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPlaceRequest) Reset() {
+	*x = GetPlaceRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPlaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPlaceRequest) ProtoMessage() {}
+
+func (x *GetPlaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPlaceRequest.ProtoReflect.Descriptor instead.
+func (*GetPlaceRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetPlaceRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type SearchPlacesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPlacesRequest) Reset() {
+	*x = SearchPlacesRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPlacesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPlacesRequest) ProtoMessage() {}
+
+func (x *SearchPlacesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPlacesRequest.ProtoReflect.Descriptor instead.
+func (*SearchPlacesRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *SearchPlacesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchPlacesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type SearchPlacesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Places        []*Place               `protobuf:"bytes,1,rep,name=places,proto3" json:"places,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchPlacesResponse) Reset() {
+	*x = SearchPlacesResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchPlacesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchPlacesResponse) ProtoMessage() {}
+
+func (x *SearchPlacesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchPlacesResponse.ProtoReflect.Descriptor instead.
+func (*SearchPlacesResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SearchPlacesResponse) GetPlaces() []*Place {
+	if x != nil {
+		return x.Places
+	}
+	return nil
+}
+
+type GetForecastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetForecastRequest) Reset() {
+	*x = GetForecastRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetForecastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetForecastRequest) ProtoMessage() {}
+
+func (x *GetForecastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetForecastRequest.ProtoReflect.Descriptor instead.
+func (*GetForecastRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetForecastRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type GetLatestForecastRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CityId        int64                  `protobuf:"varint,1,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetLatestForecastRequest) Reset() {
+	*x = GetLatestForecastRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetLatestForecastRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetLatestForecastRequest) ProtoMessage() {}
+
+func (x *GetLatestForecastRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetLatestForecastRequest.ProtoReflect.Descriptor instead.
+func (*GetLatestForecastRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetLatestForecastRequest) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+type ListForecastsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// All cities when unset
+	CityId   int64 `protobuf:"varint,1,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListForecastsRequest) Reset() {
+	*x = ListForecastsRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListForecastsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListForecastsRequest) ProtoMessage() {}
+
+func (x *ListForecastsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListForecastsRequest.ProtoReflect.Descriptor instead.
+func (*ListForecastsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListForecastsRequest) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *ListForecastsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListForecastsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListForecastsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Forecasts []*Forecast            `protobuf:"bytes,1,rep,name=forecasts,proto3" json:"forecasts,omitempty"`
+	// Empty on the last page
+	NextPageToken string `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListForecastsResponse) Reset() {
+	*x = ListForecastsResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListForecastsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListForecastsResponse) ProtoMessage() {}
+
+func (x *ListForecastsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListForecastsResponse.ProtoReflect.Descriptor instead.
+func (*ListForecastsResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListForecastsResponse) GetForecasts() []*Forecast {
+	if x != nil {
+		return x.Forecasts
+	}
+	return nil
+}
+
+func (x *ListForecastsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type StreamForecastsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// All cities when unset
+	CityId        int64  `protobuf:"varint,1,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	PageToken     string `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamForecastsRequest) Reset() {
+	*x = StreamForecastsRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamForecastsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamForecastsRequest) ProtoMessage() {}
+
+func (x *StreamForecastsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamForecastsRequest.ProtoReflect.Descriptor instead.
+func (*StreamForecastsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *StreamForecastsRequest) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+func (x *StreamForecastsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListActiveAlertsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CityId        int64                  `protobuf:"varint,1,opt,name=city_id,json=cityId,proto3" json:"city_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveAlertsRequest) Reset() {
+	*x = ListActiveAlertsRequest{}
+	mi := &file_weather_v1_weather_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveAlertsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveAlertsRequest) ProtoMessage() {}
+
+func (x *ListActiveAlertsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveAlertsRequest.ProtoReflect.Descriptor instead.
+func (*ListActiveAlertsRequest) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListActiveAlertsRequest) GetCityId() int64 {
+	if x != nil {
+		return x.CityId
+	}
+	return 0
+}
+
+type ListActiveAlertsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Alerts        []*Alert               `protobuf:"bytes,1,rep,name=alerts,proto3" json:"alerts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListActiveAlertsResponse) Reset() {
+	*x = ListActiveAlertsResponse{}
+	mi := &file_weather_v1_weather_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListActiveAlertsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListActiveAlertsResponse) ProtoMessage() {}
+
+func (x *ListActiveAlertsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_weather_v1_weather_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListActiveAlertsResponse.ProtoReflect.Descriptor instead.
+func (*ListActiveAlertsResponse) Descriptor() ([]byte, []int) {
+	return file_weather_v1_weather_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ListActiveAlertsResponse) GetAlerts() []*Alert {
+	if x != nil {
+		return x.Alerts
+	}
+	return nil
+}
+
+var File_weather_v1_weather_proto protoreflect.FileDescriptor
+
+const file_weather_v1_weather_proto_rawDesc = "" +
+	"\n" +
+	"\x18weather/v1/weather.proto\x12\n" +
+	"weather.v1\"\xac\x03\n" +
+	"\x04City\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12!\n" +
+	"\fcountry_code\x18\x04 \x01(\tR\vcountryCode\x12\x16\n" +
+	"\x06region\x18\x05 \x01(\tR\x06region\x12\x1a\n" +
+	"\blatitude\x18\x06 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\a \x01(\x01R\tlongitude\x12\x1c\n" +
+	"\televation\x18\b \x01(\x01R\televation\x12\x1e\n" +
+	"\n" +
+	"population\x18\t \x01(\x03R\n" +
+	"population\x12\x1a\n" +
+	"\btimezone\x18\n" +
+	" \x01(\tR\btimezone\x12\x1d\n" +
+	"\n" +
+	"geoname_id\x18\v \x01(\x03R\tgeonameId\x12\x1d\n" +
+	"\n" +
+	"is_capital\x18\f \x01(\bR\tisCapital\x12\x1b\n" +
+	"\tis_active\x18\r \x01(\bR\bisActive\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0e \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x0f \x01(\tR\tupdatedAt\"\xa8\x04\n" +
+	"\x05Place\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12!\n" +
+	"\fdisplay_name\x18\x02 \x01(\tR\vdisplayName\x12#\n" +
+	"\raddress_line1\x18\x03 \x01(\tR\faddressLine1\x12#\n" +
+	"\raddress_line2\x18\x04 \x01(\tR\faddressLine2\x12\x12\n" +
+	"\x04city\x18\x05 \x01(\tR\x04city\x12\x16\n" +
+	"\x06region\x18\x06 \x01(\tR\x06region\x12\x1f\n" +
+	"\vpostal_code\x18\a \x01(\tR\n" +
+	"postalCode\x12\x18\n" +
+	"\acountry\x18\b \x01(\tR\acountry\x12!\n" +
+	"\fcountry_code\x18\t \x01(\tR\vcountryCode\x12\x1a\n" +
+	"\blatitude\x18\n" +
+	" \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\v \x01(\x01R\tlongitude\x12\x1d\n" +
+	"\n" +
+	"place_type\x18\f \x01(\tR\tplaceType\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\r \x01(\x01R\n" +
+	"confidence\x12\x16\n" +
+	"\x06source\x18\x0e \x01(\tR\x06source\x12&\n" +
+	"\x0fsource_place_id\x18\x0f \x01(\tR\rsourcePlaceId\x12!\n" +
+	"\fbounding_box\x18\x10 \x01(\tR\vboundingBox\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x11 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x12 \x01(\tR\tupdatedAt\"\xac\x05\n" +
+	"\bForecast\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\acity_id\x18\x02 \x01(\x03R\x06cityId\x12'\n" +
+	"\x0fsource_provider\x18\x03 \x01(\tR\x0esourceProvider\x12#\n" +
+	"\rforecast_time\x18\x04 \x01(\tR\fforecastTime\x12\x1d\n" +
+	"\n" +
+	"valid_time\x18\x05 \x01(\tR\tvalidTime\x12 \n" +
+	"\vtemperature\x18\x06 \x01(\x01R\vtemperature\x12\x1d\n" +
+	"\n" +
+	"feels_like\x18\a \x01(\x01R\tfeelsLike\x12\x1a\n" +
+	"\bhumidity\x18\b \x01(\x01R\bhumidity\x12\x1a\n" +
+	"\bpressure\x18\t \x01(\x01R\bpressure\x12)\n" +
+	"\x10station_pressure\x18\n" +
+	" \x01(\x01R\x0fstationPressure\x12\x1d\n" +
+	"\n" +
+	"wind_speed\x18\v \x01(\x01R\twindSpeed\x12%\n" +
+	"\x0ewind_direction\x18\f \x01(\x01R\rwindDirection\x12\x1b\n" +
+	"\twind_gust\x18\r \x01(\x01R\bwindGust\x12\x1e\n" +
+	"\n" +
+	"visibility\x18\x0e \x01(\x01R\n" +
+	"visibility\x12\x1f\n" +
+	"\vcloud_cover\x18\x0f \x01(\x01R\n" +
+	"cloudCover\x12$\n" +
+	"\rprecipitation\x18\x10 \x01(\x01R\rprecipitation\x12!\n" +
+	"\fweather_code\x18\x11 \x01(\tR\vweatherCode\x12 \n" +
+	"\vdescription\x18\x12 \x01(\tR\vdescription\x12\x19\n" +
+	"\buv_index\x18\x13 \x01(\x01R\auvIndex\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x14 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x15 \x01(\tR\tupdatedAt\"\xde\x03\n" +
+	"\x05Alert\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12'\n" +
+	"\x0fsource_provider\x18\x02 \x01(\tR\x0esourceProvider\x12*\n" +
+	"\x11provider_alert_id\x18\x03 \x01(\tR\x0fproviderAlertId\x12\x17\n" +
+	"\acity_id\x18\x04 \x01(\x03R\x06cityId\x12\x1a\n" +
+	"\blatitude\x18\x05 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x06 \x01(\x01R\tlongitude\x12\x14\n" +
+	"\x05title\x18\a \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\b \x01(\tR\vdescription\x12\x1a\n" +
+	"\bseverity\x18\t \x01(\tR\bseverity\x12\x18\n" +
+	"\aurgency\x18\n" +
+	" \x01(\tR\aurgency\x12\x1a\n" +
+	"\bcategory\x18\v \x01(\tR\bcategory\x12\x1b\n" +
+	"\tarea_desc\x18\f \x01(\tR\bareaDesc\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\r \x01(\tR\tstartTime\x12\x19\n" +
+	"\bend_time\x18\x0e \x01(\tR\aendTime\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0f \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x10 \x01(\tR\tupdatedAt\" \n" +
+	"\x0eGetCityRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"\x83\x01\n" +
+	"\x11ListCitiesRequest\x12\x16\n" +
+	"\x06search\x18\x01 \x01(\tR\x06search\x12!\n" +
+	"\fcountry_code\x18\x02 \x01(\tR\vcountryCode\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x05R\x06offset\">\n" +
+	"\x12ListCitiesResponse\x12(\n" +
+	"\x06cities\x18\x01 \x03(\v2\x10.weather.v1.CityR\x06cities\"!\n" +
+	"\x0fGetPlaceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"H\n" +
+	"\x13SearchPlacesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\"A\n" +
+	"\x14SearchPlacesResponse\x12)\n" +
+	"\x06places\x18\x01 \x03(\v2\x11.weather.v1.PlaceR\x06places\"$\n" +
+	"\x12GetForecastRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"3\n" +
+	"\x18GetLatestForecastRequest\x12\x17\n" +
+	"\acity_id\x18\x01 \x01(\x03R\x06cityId\"k\n" +
+	"\x14ListForecastsRequest\x12\x17\n" +
+	"\acity_id\x18\x01 \x01(\x03R\x06cityId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"s\n" +
+	"\x15ListForecastsResponse\x122\n" +
+	"\tforecasts\x18\x01 \x03(\v2\x14.weather.v1.ForecastR\tforecasts\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"P\n" +
+	"\x16StreamForecastsRequest\x12\x17\n" +
+	"\acity_id\x18\x01 \x01(\x03R\x06cityId\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\"2\n" +
+	"\x17ListActiveAlertsRequest\x12\x17\n" +
+	"\acity_id\x18\x01 \x01(\x03R\x06cityId\"E\n" +
+	"\x18ListActiveAlertsResponse\x12)\n" +
+	"\x06alerts\x18\x01 \x03(\v2\x11.weather.v1.AlertR\x06alerts2\xbf\x05\n" +
+	"\x0eWeatherService\x127\n" +
+	"\aGetCity\x12\x1a.weather.v1.GetCityRequest\x1a\x10.weather.v1.City\x12K\n" +
+	"\n" +
+	"ListCities\x12\x1d.weather.v1.ListCitiesRequest\x1a\x1e.weather.v1.ListCitiesResponse\x12:\n" +
+	"\bGetPlace\x12\x1b.weather.v1.GetPlaceRequest\x1a\x11.weather.v1.Place\x12Q\n" +
+	"\fSearchPlaces\x12\x1f.weather.v1.SearchPlacesRequest\x1a .weather.v1.SearchPlacesResponse\x12C\n" +
+	"\vGetForecast\x12\x1e.weather.v1.GetForecastRequest\x1a\x14.weather.v1.Forecast\x12O\n" +
+	"\x11GetLatestForecast\x12$.weather.v1.GetLatestForecastRequest\x1a\x14.weather.v1.Forecast\x12T\n" +
+	"\rListForecasts\x12 .weather.v1.ListForecastsRequest\x1a!.weather.v1.ListForecastsResponse\x12M\n" +
+	"\x0fStreamForecasts\x12\".weather.v1.StreamForecastsRequest\x1a\x14.weather.v1.Forecast0\x01\x12]\n" +
+	"\x10ListActiveAlerts\x12#.weather.v1.ListActiveAlertsRequest\x1a$.weather.v1.ListActiveAlertsResponseB;Z9stormlightlabs.org/weather_api/proto/weather/v1;weatherv1b\x06proto3"
+
+var (
+	file_weather_v1_weather_proto_rawDescOnce sync.Once
+	file_weather_v1_weather_proto_rawDescData []byte
+)
+
+func file_weather_v1_weather_proto_rawDescGZIP() []byte {
+	file_weather_v1_weather_proto_rawDescOnce.Do(func() {
+		file_weather_v1_weather_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_weather_v1_weather_proto_rawDesc), len(file_weather_v1_weather_proto_rawDesc)))
+	})
+	return file_weather_v1_weather_proto_rawDescData
+}
+
+var file_weather_v1_weather_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_weather_v1_weather_proto_goTypes = []any{
+	(*City)(nil),                     // 0: weather.v1.City
+	(*Place)(nil),                    // 1: weather.v1.Place
+	(*Forecast)(nil),                 // 2: weather.v1.Forecast
+	(*Alert)(nil),                    // 3: weather.v1.Alert
+	(*GetCityRequest)(nil),           // 4: weather.v1.GetCityRequest
+	(*ListCitiesRequest)(nil),        // 5: weather.v1.ListCitiesRequest
+	(*ListCitiesResponse)(nil),       // 6: weather.v1.ListCitiesResponse
+	(*GetPlaceRequest)(nil),          // 7: weather.v1.GetPlaceRequest
+	(*SearchPlacesRequest)(nil),      // 8: weather.v1.SearchPlacesRequest
+	(*SearchPlacesResponse)(nil),     // 9: weather.v1.SearchPlacesResponse
+	(*GetForecastRequest)(nil),       // 10: weather.v1.GetForecastRequest
+	(*GetLatestForecastRequest)(nil), // 11: weather.v1.GetLatestForecastRequest
+	(*ListForecastsRequest)(nil),     // 12: weather.v1.ListForecastsRequest
+	(*ListForecastsResponse)(nil),    // 13: weather.v1.ListForecastsResponse
+	(*StreamForecastsRequest)(nil),   // 14: weather.v1.StreamForecastsRequest
+	(*ListActiveAlertsRequest)(nil),  // 15: weather.v1.ListActiveAlertsRequest
+	(*ListActiveAlertsResponse)(nil), // 16: weather.v1.ListActiveAlertsResponse
+}
+var file_weather_v1_weather_proto_depIdxs = []int32{
+	0,  // 0: weather.v1.ListCitiesResponse.cities:type_name -> weather.v1.City
+	1,  // 1: weather.v1.SearchPlacesResponse.places:type_name -> weather.v1.Place
+	2,  // 2: weather.v1.ListForecastsResponse.forecasts:type_name -> weather.v1.Forecast
+	3,  // 3: weather.v1.ListActiveAlertsResponse.alerts:type_name -> weather.v1.Alert
+	4,  // 4: weather.v1.WeatherService.GetCity:input_type -> weather.v1.GetCityRequest
+	5,  // 5: weather.v1.WeatherService.ListCities:input_type -> weather.v1.ListCitiesRequest
+	7,  // 6: weather.v1.WeatherService.GetPlace:input_type -> weather.v1.GetPlaceRequest
+	8,  // 7: weather.v1.WeatherService.SearchPlaces:input_type -> weather.v1.SearchPlacesRequest
+	10, // 8: weather.v1.WeatherService.GetForecast:input_type -> weather.v1.GetForecastRequest
+	11, // 9: weather.v1.WeatherService.GetLatestForecast:input_type -> weather.v1.GetLatestForecastRequest
+	12, // 10: weather.v1.WeatherService.ListForecasts:input_type -> weather.v1.ListForecastsRequest
+	14, // 11: weather.v1.WeatherService.StreamForecasts:input_type -> weather.v1.StreamForecastsRequest
+	15, // 12: weather.v1.WeatherService.ListActiveAlerts:input_type -> weather.v1.ListActiveAlertsRequest
+	0,  // 13: weather.v1.WeatherService.GetCity:output_type -> weather.v1.City
+	6,  // 14: weather.v1.WeatherService.ListCities:output_type -> weather.v1.ListCitiesResponse
+	1,  // 15: weather.v1.WeatherService.GetPlace:output_type -> weather.v1.Place
+	9,  // 16: weather.v1.WeatherService.SearchPlaces:output_type -> weather.v1.SearchPlacesResponse
+	2,  // 17: weather.v1.WeatherService.GetForecast:output_type -> weather.v1.Forecast
+	2,  // 18: weather.v1.WeatherService.GetLatestForecast:output_type -> weather.v1.Forecast
+	13, // 19: weather.v1.WeatherService.ListForecasts:output_type -> weather.v1.ListForecastsResponse
+	2,  // 20: weather.v1.WeatherService.StreamForecasts:output_type -> weather.v1.Forecast
+	16, // 21: weather.v1.WeatherService.ListActiveAlerts:output_type -> weather.v1.ListActiveAlertsResponse
+	13, // [13:22] is the sub-list for method output_type
+	4,  // [4:13] is the sub-list for method input_type
+	4,  // [4:4] is the sub-list for extension type_name
+	4,  // [4:4] is the sub-list for extension extendee
+	0,  // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_weather_v1_weather_proto_init() }
+func file_weather_v1_weather_proto_init() {
+	if File_weather_v1_weather_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_weather_v1_weather_proto_rawDesc), len(file_weather_v1_weather_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_weather_v1_weather_proto_goTypes,
+		DependencyIndexes: file_weather_v1_weather_proto_depIdxs,
+		MessageInfos:      file_weather_v1_weather_proto_msgTypes,
+	}.Build()
+	File_weather_v1_weather_proto = out.File
+	file_weather_v1_weather_proto_goTypes = nil
+	file_weather_v1_weather_proto_depIdxs = nil
+}
@@ -0,0 +1,442 @@
+// The weather API's gRPC service, served on --grpc-port. Messages mirror the REST API's
+// JSON: measurements are metric and times are RFC 3339 strings.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: weather/v1/weather.proto
+
+package weatherv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WeatherService_GetCity_FullMethodName           = "/weather.v1.WeatherService/GetCity"
+	WeatherService_ListCities_FullMethodName        = "/weather.v1.WeatherService/ListCities"
+	WeatherService_GetPlace_FullMethodName          = "/weather.v1.WeatherService/GetPlace"
+	WeatherService_SearchPlaces_FullMethodName      = "/weather.v1.WeatherService/SearchPlaces"
+	WeatherService_GetForecast_FullMethodName       = "/weather.v1.WeatherService/GetForecast"
+	WeatherService_GetLatestForecast_FullMethodName = "/weather.v1.WeatherService/GetLatestForecast"
+	WeatherService_ListForecasts_FullMethodName     = "/weather.v1.WeatherService/ListForecasts"
+	WeatherService_StreamForecasts_FullMethodName   = "/weather.v1.WeatherService/StreamForecasts"
+	WeatherService_ListActiveAlerts_FullMethodName  = "/weather.v1.WeatherService/ListActiveAlerts"
+)
+
+// WeatherServiceClient is the client API for WeatherService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type WeatherServiceClient interface {
+	GetCity(ctx context.Context, in *GetCityRequest, opts ...grpc.CallOption) (*City, error)
+	// Cities by name when search is set, in a country when country_code is set, or all
+	ListCities(ctx context.Context, in *ListCitiesRequest, opts ...grpc.CallOption) (*ListCitiesResponse, error)
+	GetPlace(ctx context.Context, in *GetPlaceRequest, opts ...grpc.CallOption) (*Place, error)
+	SearchPlaces(ctx context.Context, in *SearchPlacesRequest, opts ...grpc.CallOption) (*SearchPlacesResponse, error)
+	GetForecast(ctx context.Context, in *GetForecastRequest, opts ...grpc.CallOption) (*Forecast, error)
+	// The forecast of a city valid latest
+	GetLatestForecast(ctx context.Context, in *GetLatestForecastRequest, opts ...grpc.CallOption) (*Forecast, error)
+	// One page of forecasts, latest valid time first
+	ListForecasts(ctx context.Context, in *ListForecastsRequest, opts ...grpc.CallOption) (*ListForecastsResponse, error)
+	// Every stored forecast from page_token on, latest valid time first
+	StreamForecasts(ctx context.Context, in *StreamForecastsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Forecast], error)
+	// Alerts in effect for a city
+	ListActiveAlerts(ctx context.Context, in *ListActiveAlertsRequest, opts ...grpc.CallOption) (*ListActiveAlertsResponse, error)
+}
+
+type weatherServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWeatherServiceClient(cc grpc.ClientConnInterface) WeatherServiceClient {
+	return &weatherServiceClient{cc}
+}
+
+func (c *weatherServiceClient) GetCity(ctx context.Context, in *GetCityRequest, opts ...grpc.CallOption) (*City, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(City)
+	err := c.cc.Invoke(ctx, WeatherService_GetCity_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) ListCities(ctx context.Context, in *ListCitiesRequest, opts ...grpc.CallOption) (*ListCitiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCitiesResponse)
+	err := c.cc.Invoke(ctx, WeatherService_ListCities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) GetPlace(ctx context.Context, in *GetPlaceRequest, opts ...grpc.CallOption) (*Place, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Place)
+	err := c.cc.Invoke(ctx, WeatherService_GetPlace_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) SearchPlaces(ctx context.Context, in *SearchPlacesRequest, opts ...grpc.CallOption) (*SearchPlacesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchPlacesResponse)
+	err := c.cc.Invoke(ctx, WeatherService_SearchPlaces_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) GetForecast(ctx context.Context, in *GetForecastRequest, opts ...grpc.CallOption) (*Forecast, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Forecast)
+	err := c.cc.Invoke(ctx, WeatherService_GetForecast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) GetLatestForecast(ctx context.Context, in *GetLatestForecastRequest, opts ...grpc.CallOption) (*Forecast, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Forecast)
+	err := c.cc.Invoke(ctx, WeatherService_GetLatestForecast_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) ListForecasts(ctx context.Context, in *ListForecastsRequest, opts ...grpc.CallOption) (*ListForecastsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListForecastsResponse)
+	err := c.cc.Invoke(ctx, WeatherService_ListForecasts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *weatherServiceClient) StreamForecasts(ctx context.Context, in *StreamForecastsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Forecast], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &WeatherService_ServiceDesc.Streams[0], WeatherService_StreamForecasts_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamForecastsRequest, Forecast]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_StreamForecastsClient = grpc.ServerStreamingClient[Forecast]
+
+func (c *weatherServiceClient) ListActiveAlerts(ctx context.Context, in *ListActiveAlertsRequest, opts ...grpc.CallOption) (*ListActiveAlertsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListActiveAlertsResponse)
+	err := c.cc.Invoke(ctx, WeatherService_ListActiveAlerts_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WeatherServiceServer is the server API for WeatherService service.
+// All implementations must embed UnimplementedWeatherServiceServer
+// for forward compatibility.
+type WeatherServiceServer interface {
+	GetCity(context.Context, *GetCityRequest) (*City, error)
+	// Cities by name when search is set, in a country when country_code is set, or all
+	ListCities(context.Context, *ListCitiesRequest) (*ListCitiesResponse, error)
+	GetPlace(context.Context, *GetPlaceRequest) (*Place, error)
+	SearchPlaces(context.Context, *SearchPlacesRequest) (*SearchPlacesResponse, error)
+	GetForecast(context.Context, *GetForecastRequest) (*Forecast, error)
+	// The forecast of a city valid latest
+	GetLatestForecast(context.Context, *GetLatestForecastRequest) (*Forecast, error)
+	// One page of forecasts, latest valid time first
+	ListForecasts(context.Context, *ListForecastsRequest) (*ListForecastsResponse, error)
+	// Every stored forecast from page_token on, latest valid time first
+	StreamForecasts(*StreamForecastsRequest, grpc.ServerStreamingServer[Forecast]) error
+	// Alerts in effect for a city
+	ListActiveAlerts(context.Context, *ListActiveAlertsRequest) (*ListActiveAlertsResponse, error)
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+// UnimplementedWeatherServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWeatherServiceServer struct{}
+
+func (UnimplementedWeatherServiceServer) GetCity(context.Context, *GetCityRequest) (*City, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCity not implemented")
+}
+func (UnimplementedWeatherServiceServer) ListCities(context.Context, *ListCitiesRequest) (*ListCitiesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCities not implemented")
+}
+func (UnimplementedWeatherServiceServer) GetPlace(context.Context, *GetPlaceRequest) (*Place, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPlace not implemented")
+}
+func (UnimplementedWeatherServiceServer) SearchPlaces(context.Context, *SearchPlacesRequest) (*SearchPlacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchPlaces not implemented")
+}
+func (UnimplementedWeatherServiceServer) GetForecast(context.Context, *GetForecastRequest) (*Forecast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetForecast not implemented")
+}
+func (UnimplementedWeatherServiceServer) GetLatestForecast(context.Context, *GetLatestForecastRequest) (*Forecast, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLatestForecast not implemented")
+}
+func (UnimplementedWeatherServiceServer) ListForecasts(context.Context, *ListForecastsRequest) (*ListForecastsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListForecasts not implemented")
+}
+func (UnimplementedWeatherServiceServer) StreamForecasts(*StreamForecastsRequest, grpc.ServerStreamingServer[Forecast]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamForecasts not implemented")
+}
+func (UnimplementedWeatherServiceServer) ListActiveAlerts(context.Context, *ListActiveAlertsRequest) (*ListActiveAlertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListActiveAlerts not implemented")
+}
+func (UnimplementedWeatherServiceServer) mustEmbedUnimplementedWeatherServiceServer() {}
+func (UnimplementedWeatherServiceServer) testEmbeddedByValue()                        {}
+
+// UnsafeWeatherServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WeatherServiceServer will
+// result in compilation errors.
+type UnsafeWeatherServiceServer interface {
+	mustEmbedUnimplementedWeatherServiceServer()
+}
+
+func RegisterWeatherServiceServer(s grpc.ServiceRegistrar, srv WeatherServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWeatherServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WeatherService_ServiceDesc, srv)
+}
+
+func _WeatherService_GetCity_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetCity(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetCity_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetCity(ctx, req.(*GetCityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_ListCities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCitiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).ListCities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_ListCities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).ListCities(ctx, req.(*ListCitiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_GetPlace_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetPlaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetPlace(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetPlace_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetPlace(ctx, req.(*GetPlaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_SearchPlaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchPlacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).SearchPlaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_SearchPlaces_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).SearchPlaces(ctx, req.(*SearchPlacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_GetForecast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetForecastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetForecast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetForecast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetForecast(ctx, req.(*GetForecastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_GetLatestForecast_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLatestForecastRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).GetLatestForecast(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_GetLatestForecast_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).GetLatestForecast(ctx, req.(*GetLatestForecastRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_ListForecasts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListForecastsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).ListForecasts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_ListForecasts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).ListForecasts(ctx, req.(*ListForecastsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WeatherService_StreamForecasts_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamForecastsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(WeatherServiceServer).StreamForecasts(m, &grpc.GenericServerStream[StreamForecastsRequest, Forecast]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type WeatherService_StreamForecastsServer = grpc.ServerStreamingServer[Forecast]
+
+func _WeatherService_ListActiveAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListActiveAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WeatherServiceServer).ListActiveAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WeatherService_ListActiveAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WeatherServiceServer).ListActiveAlerts(ctx, req.(*ListActiveAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WeatherService_ServiceDesc is the grpc.ServiceDesc for WeatherService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WeatherService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "weather.v1.WeatherService",
+	HandlerType: (*WeatherServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetCity",
+			Handler:    _WeatherService_GetCity_Handler,
+		},
+		{
+			MethodName: "ListCities",
+			Handler:    _WeatherService_ListCities_Handler,
+		},
+		{
+			MethodName: "GetPlace",
+			Handler:    _WeatherService_GetPlace_Handler,
+		},
+		{
+			MethodName: "SearchPlaces",
+			Handler:    _WeatherService_SearchPlaces_Handler,
+		},
+		{
+			MethodName: "GetForecast",
+			Handler:    _WeatherService_GetForecast_Handler,
+		},
+		{
+			MethodName: "GetLatestForecast",
+			Handler:    _WeatherService_GetLatestForecast_Handler,
+		},
+		{
+			MethodName: "ListForecasts",
+			Handler:    _WeatherService_ListForecasts_Handler,
+		},
+		{
+			MethodName: "ListActiveAlerts",
+			Handler:    _WeatherService_ListActiveAlerts_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamForecasts",
+			Handler:       _WeatherService_StreamForecasts_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "weather/v1/weather.proto",
+}